@@ -0,0 +1,1069 @@
+// Package app assembles the claims service from its repository, services,
+// handlers and routes. cmd/server serves it over HTTP; tests can mount the
+// same handler on an httptest server.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/acord"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/approval"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/estimate"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/notifications"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/realtime"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/reinsurance"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/scan"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/taxonomy"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/webhooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/health"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/retention"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the settings needed to assemble the service
+type Config struct {
+	DataPath         string
+	FeatureAPIKey    string
+	EventHistorySize int
+	SSEHeartbeat     time.Duration
+	WSSendBuffer     int
+	PolicyServiceURL string // enables grace checks on new claims and policy checks in the consistency report
+
+	// ClaimTypesFile is the claim taxonomy: the claim types and sub-types
+	// each policy type accepts. When empty claim-types.json in DataPath is
+	// used, and without that file the built-in accident, theft and damage.
+	ClaimTypesFile string
+
+	// ClaimNumberFormat is the format of new claim numbers, e.g.
+	// "CLM-{year}-{seq:6}"; see services.ParseClaimNumberFormat. Empty uses
+	// services.DefaultClaimNumberFormat.
+	ClaimNumberFormat string
+
+	// NotificationTemplatesFile is the subject and body of each claim
+	// notification per event type and locale. When empty
+	// notification-templates.json in DataPath is used, and without that
+	// file a built-in English template per event type.
+	NotificationTemplatesFile string
+
+	// ApprovalPolicy decides new claims: approval.NameThreshold, the
+	// claims.autoApproval flags, approval.NameRules, the rules in
+	// ApprovalRulesFile, or approval.NameEngine, the claim rules of the
+	// business rules engine. Empty uses the threshold. When
+	// ApprovalRulesFile is empty approval-rules.json in DataPath is used;
+	// the rules policy needs the file.
+	ApprovalPolicy    string
+	ApprovalRulesFile string
+
+	// BusinessRulesFile holds the expressions of the business rules
+	// engine. When empty business-rules.json in DataPath is used, and
+	// without that file the engine starts with no rules until they are set
+	// through the admin API. BusinessRulesReloadInterval is how often the
+	// file is checked for changes; 0 loads it once at startup.
+	BusinessRulesFile           string
+	BusinessRulesReloadInterval time.Duration
+
+	// ReinsuranceFile is the excess of loss treaty of each policy type.
+	// When empty reinsurance.json in DataPath is used, and without that
+	// file nothing is ceded.
+	ReinsuranceFile string
+
+	// AcordMappingFile is how claims translate to ACORD codes in the
+	// XML and EDI exports. When empty acord.json in DataPath is used, and
+	// without that file acord.Default.
+	AcordMappingFile string
+
+	// RetentionFile is how long decided claims stay live before they are
+	// archived. When empty retention.json in DataPath is used, and without
+	// that file nothing is archived. ArchiveInterval is how often the
+	// archival job runs; 0 disables the background job.
+	RetentionFile   string
+	ArchiveInterval time.Duration
+
+	// ShapingFile holds the field visibility rules of the responses, on
+	// top of their shape tags. When empty response-shaping.json in
+	// DataPath is used, and without that file the tags alone apply.
+	ShapingFile string
+
+	// DamageEstimator prices claims from their photos when one is
+	// attached: estimate.NameStub, or empty to leave claims unestimated
+	DamageEstimator string
+
+	// DocumentScanner scans uploaded documents for malware before they can
+	// be downloaded: scan.NameClamAV or scan.NameICAP, at
+	// DocumentScannerAddress, or empty for scan.NameNoop, which marks
+	// documents clean without scanning them. DocumentScanTimeout bounds one
+	// scan; 0 uses scan.DefaultTimeout.
+	DocumentScanner        string
+	DocumentScannerAddress string
+	DocumentScanTimeout    time.Duration
+
+	// Blobs keeps the content of uploaded documents in a shared blob
+	// store, which also signs links to download them. An empty backend
+	// keeps content in the claims store, without links.
+	Blobs blobstore.Config
+
+	// PaymentsServiceURL enables payout entries in claim timelines
+	PaymentsServiceURL string
+
+	// HoldRecheckInterval is how often claims held pending payment are
+	// rechecked against policy-service; 0 disables the background recheck
+	HoldRecheckInterval time.Duration
+
+	// PersistDir holds the write-ahead log and snapshot that keep changes
+	// across restarts. When empty changes are kept in memory only.
+	// PersistFlushInterval is how often the log is folded into the snapshot.
+	PersistDir           string
+	PersistFlushInterval time.Duration
+
+	// StorageBackend selects where claims are kept: "memory" (the default)
+	// or "redis", which shares state between instances through the Redis
+	// at RedisURL. PersistDir only applies to the memory backend.
+	StorageBackend string
+	RedisURL       string
+
+	// WebhookURLs receive every claim event as a JSON POST, signed with each
+	// of WebhookSecrets, so receivers can rotate theirs. Failed deliveries are retried up to
+	// WebhookMaxAttempts times, backing off from WebhookRetryBackoff, and
+	// then parked in the dead-letter queue for replay.
+	WebhookURLs         []string
+	WebhookSecrets      signing.Keys
+	WebhookMaxAttempts  int
+	WebhookRetryBackoff time.Duration
+
+	// AlertsFile holds the thresholds alerts are raised on, such as the
+	// dead-letter queue depth. When empty alerts.json in DataPath is used,
+	// and without that file no alerts are raised. AlertInterval is how
+	// often they are evaluated; 0 disables evaluation. Alerts are POSTed
+	// to AlertWebhookURLs, signed with each of AlertWebhookSecrets.
+	AlertsFile          string
+	AlertInterval       time.Duration
+	AlertWebhookURLs    []string
+	AlertWebhookSecrets signing.Keys
+
+	// EmailIntakeToken enables the inbound email webhook at
+	// POST /intake/email, which queues claim emails as drafts for agents to
+	// confirm. Requests must carry it in X-Intake-Token or as the basic
+	// auth password. When empty the webhook is not served.
+	EmailIntakeToken string
+
+	// AuthUsername and AuthPassword are the account POST /auth/login signs
+	// in, demo@insurancestack.com and demo123 when empty, and AuthRole its
+	// role. LoginLockout locks a username out after repeated failed
+	// sign-ins; its zero value never locks. Accounts with a role in
+	// TwoFactorRoles must sign in with a TOTP second factor; other accounts
+	// may enroll themselves.
+	AuthUsername   string
+	AuthPassword   string
+	AuthRole       string
+	LoginLockout   services.LockoutPolicy
+	TwoFactorRoles []string
+
+	// ServiceClientsFile registers the services that may get service tokens
+	// from POST /auth/token with the client credentials grant, with their
+	// secrets and scopes; service-clients.json in DataPath by default. Service
+	// tokens are valid for ServiceTokenTTL, services.DefaultServiceTokenTTL
+	// when 0.
+	ServiceClientsFile string
+	ServiceTokenTTL    time.Duration
+
+	// Maintenance is the maintenance mode the service starts in
+	Maintenance maintenance.Config
+	// Faults is whether the service may inject faults for resilience
+	// testing; the rules are set at /admin/faults
+	Faults faults.Config
+
+	// SlowRequestThreshold logs requests that take at least this long as
+	// warnings; 0 disables the warning
+	SlowRequestThreshold time.Duration
+
+	// AccessLog receives one structured entry per request; when nil they
+	// are written to the service log
+	AccessLog *logrus.Logger
+	// ErrorReporter receives handler panics, such as a Sentry hub; nil only
+	// logs them
+	ErrorReporter telemetry.ErrorReporter
+
+	// MaxBodyBytes and HandlerTimeout limit every request; 0 disables the
+	// limit. RouteLimits overrides them for single routes, keyed by method
+	// and route template such as "POST /claims", on top of the built-in
+	// overrides in defaultRouteLimits.
+	MaxBodyBytes   int64
+	HandlerTimeout time.Duration
+	RouteLimits    map[string]middleware.RouteLimits
+
+	// CircuitBreaker trips calls to policy-service and payments-service
+	// per route and tenant once they keep failing. Fallbacks is what each
+	// operation in fallbackOperations does when its downstream cannot
+	// answer; operations not in it fail open.
+	CircuitBreaker breaker.Config
+	Fallbacks      map[string]breaker.Fallback
+}
+
+// fallbackOperations are the operations whose fallback can be configured:
+// the grace check of claim intake and the payouts of claim timelines
+var fallbackOperations = []string{"claim_intake", "claim_timeline"}
+
+// documentLimits is what the blob store accepts as claim documents: photos
+// and videos of the damage, scanned and office documents, and emails
+var documentLimits = blobstore.Limits{
+	MaxSize: models.MaxDocumentSize,
+	ContentTypes: []string{
+		"image/*", "video/*", "audio/*", "text/plain", "text/csv", "message/rfc822", "application/pdf",
+		"application/msword", "application/vnd.ms-excel",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	},
+}
+
+// defaultRouteLimits lets document uploads and claim emails through the body
+// limit and takes the timeout off routes that hold their connection open or
+// bound their own time
+var defaultRouteLimits = map[string]middleware.RouteLimits{
+	"POST /claims/{id}/documents":     {MaxBodyBytes: models.MaxDocumentSize + 1<<20, Timeout: -1},
+	"POST /intake/email":              {MaxBodyBytes: models.MaxEmailSize + 1<<20, Timeout: -1},
+	"GET /claims/stream":              {Timeout: -1},
+	"GET /claims/{id}/events":         {Timeout: -1},
+	"GET /ws/adjusters":               {Timeout: -1},
+	"POST /admin/claims/held/recheck": {Timeout: -1},
+}
+
+// App is an assembled claims service
+type App struct {
+	Handler http.Handler
+	Flags   *features.Flags
+
+	streams       *handlers.EventsHandler
+	stopHub       lifecycle.StopFunc
+	stopRecheck   lifecycle.StopFunc
+	stopArchive   lifecycle.StopFunc
+	stopAlerts    lifecycle.StopFunc
+	stopEstimates lifecycle.StopFunc
+	stopScans     lifecycle.StopFunc
+	stopHooks     lifecycle.StopFunc
+	stopRules     lifecycle.StopFunc
+	closeStore    func() error
+	logger        *logrus.Logger
+}
+
+// New wires the service together and loads its data from cfg.DataPath
+func New(cfg Config, logger *logrus.Logger) (*App, error) {
+	if err := checkFallbacks(cfg.Fallbacks); err != nil {
+		return nil, err
+	}
+
+	// Initialize CloudBees Feature Management
+	flags, err := features.Initialize(cfg.FeatureAPIKey, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize feature management: %w", err)
+	}
+
+	// Load the claim taxonomy, business rules, approval policy, number
+	// format, notification templates, reinsurance treaties, ACORD mapping,
+	// retention policy, response shaping, damage estimator, document
+	// scanner and service
+	// clients before anything needs stopping
+	claimTypes, err := loadClaimTypes(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	businessRules, err := loadBusinessRules(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	approvals, err := loadApprovalPolicy(cfg, flags, businessRules, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	templates, err := loadNotificationTemplates(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	treaties, err := loadReinsurance(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	acordMapping, err := loadAcordMapping(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	policy, err := loadRetention(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	shaper, err := loadShaping(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	estimator, err := estimate.New(cfg.DamageEstimator)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	scanner, err := scan.New(scan.Config{Name: cfg.DocumentScanner, Address: cfg.DocumentScannerAddress, Timeout: cfg.DocumentScanTimeout})
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	blobs, err := blobstore.Open(cfg.Blobs, documentLimits)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	serviceClients, err := loadServiceClients(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	var claimNumbers *services.ClaimNumberFormat
+	if cfg.ClaimNumberFormat != "" {
+		if claimNumbers, err = services.ParseClaimNumberFormat(cfg.ClaimNumberFormat); err != nil {
+			features.Shutdown()
+			return nil, err
+		}
+	}
+
+	// Initialize repository
+	repo, closeStore, err := newStore(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	if blobs != nil {
+		repo = repository.WithBlobs(repo, blobs, cfg.Blobs.URLTTL)
+		logger.WithField("backend", cfg.Blobs.Backend).Info("Document content kept in the blob store")
+	}
+
+	// Sign-ins and the sessions their tokens belong to
+	sessionService, err := newSessionService(cfg, repo, logger)
+	if err != nil {
+		closeStore()
+		features.Shutdown()
+		return nil, err
+	}
+
+	// Initialize the internal event bus used for real-time claim updates
+	bus := events.NewBus(cfg.EventHistorySize, logger)
+
+	// Start the WebSocket hub for adjuster dashboards
+	hub := realtime.NewHub(bus, cfg.WSSendBuffer, logger)
+	stopHub := lifecycle.Go(hub.Run)
+
+	// Deliver claim events to configured webhook endpoints
+	hooks := webhooks.NewDispatcher(bus, webhooks.Config{
+		URLs:         cfg.WebhookURLs,
+		Secrets:      cfg.WebhookSecrets,
+		MaxAttempts:  cfg.WebhookMaxAttempts,
+		RetryBackoff: cfg.WebhookRetryBackoff,
+	}, logger)
+	stopHooks := lifecycle.Go(hooks.Run)
+
+	// Initialize services
+	breakers := breaker.New(cfg.CircuitBreaker, logger)
+	var policyLookup services.PolicyLookup
+	if cfg.PolicyServiceURL != "" {
+		policyLookup = clients.NewPolicyClient(cfg.PolicyServiceURL, 5*time.Second, breakers)
+	}
+	claimService := services.NewClaimService(repo, flags, approvals, policyLookup, cfg.Fallbacks["claim_intake"], bus, claimTypes, claimNumbers, logger)
+	consistencyChecker := services.NewConsistencyChecker(repo, policyLookup, logger)
+	// Service tokens, for registered clients and for this service's own
+	// calls: payments-service only takes payouts with the payments:write scope
+	serviceTokenTTL := cfg.ServiceTokenTTL
+	if serviceTokenTTL == 0 {
+		serviceTokenTTL = services.DefaultServiceTokenTTL
+	}
+	serviceTokens := services.NewServiceTokenService(middleware.NewJWTManager(logger), serviceClients, serviceTokenTTL, logger)
+	var payoutLookup services.PayoutLookup
+	var payoutCreator services.PayoutCreator
+	if cfg.PaymentsServiceURL != "" {
+		payments := clients.NewPaymentsClient(cfg.PaymentsServiceURL, 5*time.Second, func() (string, error) {
+			token, err := serviceTokens.Issue(services.ScopePaymentsWrite)
+			if err != nil {
+				return "", err
+			}
+			return token.Token, nil
+		}, breakers)
+		payoutLookup, payoutCreator = payments, payments
+	}
+	timelineService := services.NewTimelineService(repo, payoutLookup, cfg.Fallbacks["claim_timeline"], logger)
+	payoutService := services.NewPayoutService(repo, payoutCreator, logger)
+	agingService := services.NewAgingService(repo, logger)
+	reinsuranceService := services.NewReinsuranceService(repo, treaties, logger)
+	acordService := services.NewAcordService(repo, acordMapping, logger)
+	archiveService := services.NewArchiveService(repo, policy, logger)
+	commentService := services.NewCommentService(repo, logger)
+	draftService := services.NewDraftService(repo, claimService, logger)
+	fnolService := services.NewFNOLService(repo, claimService, logger)
+
+	// Raise alerts on the dead-letter queue and the claim backlog
+	alerts, err := loadAlerts(cfg, map[string]alerting.Metric{
+		"webhook_dlq_depth": func() (float64, error) { return float64(hooks.Stats().DLQDepth), nil },
+		"open_claims":       func() (float64, error) { return float64(agingService.Report(time.Now()).Claims.Count), nil },
+	}, logger)
+	if err != nil {
+		stopHooks(context.Background())
+		stopHub(context.Background())
+		closeStore()
+		features.Shutdown()
+		return nil, err
+	}
+	var stopAlerts lifecycle.StopFunc
+	if cfg.AlertInterval > 0 {
+		stopAlerts = lifecycle.Go(func(ctx context.Context) {
+			alerts.Run(ctx, cfg.AlertInterval)
+		})
+	}
+
+	// Release held claims once their policy is reinstated or lapses
+	var stopRecheck lifecycle.StopFunc
+	if policyLookup != nil && cfg.HoldRecheckInterval > 0 {
+		stopRecheck = lifecycle.Go(func(ctx context.Context) {
+			claimService.RunHoldRecheck(ctx, cfg.HoldRecheckInterval)
+		})
+	}
+
+	// Move decided claims past retention to the archive
+	var stopArchive lifecycle.StopFunc
+	if cfg.ArchiveInterval > 0 {
+		stopArchive = lifecycle.Go(func(ctx context.Context) {
+			archiveService.Run(ctx, cfg.ArchiveInterval)
+		})
+	}
+
+	// Price claims from their photos as they are uploaded
+	var stopEstimates lifecycle.StopFunc
+	if estimator != nil {
+		estimateService := services.NewEstimateService(repo, estimator, bus, logger)
+		stopEstimates = lifecycle.Go(estimateService.Run)
+	} else {
+		logger.Info("No damage estimator configured, claims will not be estimated from their photos")
+	}
+
+	// Scan documents for malware as they are uploaded; until scanned they
+	// cannot be downloaded
+	if scanner.Name() == scan.NameNoop {
+		logger.Warn("No document scanner configured, documents will be marked clean without being scanned for malware")
+	}
+	scanService := services.NewScanService(repo, scanner, bus, logger)
+	stopScans := lifecycle.Go(scanService.Run)
+
+	// Pick up changes to the business rules file
+	var stopRules lifecycle.StopFunc
+	if cfg.BusinessRulesReloadInterval > 0 {
+		stopRules = lifecycle.Go(func(ctx context.Context) {
+			businessRules.Watch(ctx, cfg.BusinessRulesReloadInterval, func() {
+				logger.WithField("version", businessRules.Version()).Info("Reloaded business rules")
+			})
+		})
+	}
+
+	// Initialize handlers
+	maintenanceMode := maintenance.New(cfg.Maintenance, logger)
+	faultInjection := faults.New(cfg.Faults, logger)
+	healthHandler := health.NewHandler("claims-service", health.Combine(flags, maintenanceMode, faultInjection))
+	claimHandler := handlers.NewClaimHandler(claimService, logger)
+	eventsHandler := handlers.NewEventsHandler(claimService, bus, cfg.SSEHeartbeat, logger)
+	adjusterSocketHandler := handlers.NewAdjusterSocketHandler(hub, logger)
+	consistencyHandler := handlers.NewConsistencyHandler(consistencyChecker, logger)
+	agingHandler := handlers.NewAgingHandler(agingService, logger)
+	reinsuranceHandler := handlers.NewReinsuranceHandler(reinsuranceService, logger)
+	acordHandler := handlers.NewAcordHandler(acordService, logger)
+	impressionsHandler := handlers.NewImpressionsHandler(flags.Impressions(), logger)
+	holdRecheckHandler := handlers.NewHoldRecheckHandler(claimService, logger)
+	archiveHandler := handlers.NewArchiveHandler(archiveService, logger)
+	timelineHandler := handlers.NewTimelineHandler(timelineService, logger)
+	commentHandler := handlers.NewCommentHandler(commentService, logger)
+	draftHandler := handlers.NewDraftHandler(draftService, cfg.EmailIntakeToken, logger)
+	fnolHandler := handlers.NewFNOLHandler(fnolService, logger)
+	webhookHandler := handlers.NewWebhookHandler(hooks, logger)
+	notificationHandler := handlers.NewNotificationHandler(templates, logger)
+	offerHandler := handlers.NewOfferHandler(claimService, logger)
+	myClaimsHandler := handlers.NewMyClaimsHandler(claimService, logger)
+	authHandler := handlers.NewAuthHandler(sessionService, logger)
+	tokenHandler := handlers.NewTokenHandler(serviceTokens, logger)
+	payoutHandler := handlers.NewPayoutHandler(payoutService, logger)
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Apply global middleware
+	requestMetrics := telemetry.NewRegistry(nil)
+	panics := telemetry.NewPanicCounter()
+	router.Use(middleware.LoggingMiddleware(logger, middleware.LoggingOptions{
+		Metrics:       requestMetrics,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		AccessLog:     cfg.AccessLog,
+	}))
+	// Error messages are translated into the caller's Accept-Language,
+	// including those of recovered panics
+	router.Use(i18n.Middleware(i18n.Default()))
+	router.Use(middleware.RecoveryMiddleware(logger, middleware.RecoveryOptions{
+		Panics:   panics,
+		Reporter: cfg.ErrorReporter,
+	}))
+	// Turn callers away during maintenance before they are authenticated
+	router.Use(maintenanceMode.Middleware)
+	// Inject test faults ahead of authentication too, so every caller is
+	// faulted the same way
+	router.Use(faultInjection.Middleware)
+	router.Use(middleware.AuthMiddleware(logger))
+	// Tokens of ended sessions are refused before any route sees them
+	router.Use(middleware.Sessions(sessionService, logger))
+	router.Use(middleware.Limits(logger, limitsConfig(cfg)))
+	// Staff-only claim fields are left out for customers
+	router.Use(shaping.Middleware(shaper, func(r *http.Request) shaping.Viewer {
+		return shaping.Viewer{Role: middleware.GetRole(r)}
+	}))
+
+	// Setup CORS
+	corsHandler := middleware.NewCORS()
+
+	// Register routes
+	router.Handle("/healthz", healthHandler).Methods("GET")
+	router.Handle("/labels", i18n.LabelsHandler(i18n.Default())).Methods("GET")
+	router.Handle("/metrics", telemetry.Handler(requestMetrics, panics, webhookHandler, agingService, businessRules, alerts, breakers, faultInjection)).Methods("GET")
+	router.HandleFunc("/auth/login", authHandler.Login).Methods("POST")
+	router.HandleFunc("/auth/token", tokenHandler.IssueToken).Methods("POST")
+	router.HandleFunc("/auth/login/verify", authHandler.VerifyLogin).Methods("POST")
+	router.HandleFunc("/auth/2fa", authHandler.GetTwoFactor).Methods("GET")
+	router.HandleFunc("/auth/2fa/enroll", authHandler.EnrollTwoFactor).Methods("POST")
+	router.HandleFunc("/auth/2fa/confirm", authHandler.ConfirmTwoFactor).Methods("POST")
+	router.HandleFunc("/auth/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes).Methods("POST")
+	router.HandleFunc("/auth/sessions", authHandler.GetSessions).Methods("GET")
+	router.HandleFunc("/auth/sessions/{id}", authHandler.RevokeSession).Methods("DELETE")
+	// Claim reads are scoped to the customer; staff tokens see every claim
+	identify := middleware.IdentifyRole(logger)
+	router.Handle("/claims", identify(http.HandlerFunc(claimHandler.GetClaims))).Methods("GET")
+	router.HandleFunc("/claims/stream", eventsHandler.StreamClaims).Methods("GET")
+	router.HandleFunc("/claims/stats", claimHandler.GetClaimStats).Methods("GET")
+	router.Handle("/claims/{id}", identify(http.HandlerFunc(claimHandler.GetClaimByID))).Methods("GET")
+	router.Handle("/claims/{id}/events", identify(http.HandlerFunc(eventsHandler.StreamClaimEvents))).Methods("GET")
+	// Claim tracking for customers, without the fields adjusters work with
+	router.HandleFunc("/my/claims", myClaimsHandler.GetMyClaims).Methods("GET")
+	router.HandleFunc("/my/claims/{id}", myClaimsHandler.GetMyClaim).Methods("GET")
+	router.HandleFunc("/claims/{id}/timeline", timelineHandler.GetTimeline).Methods("GET")
+	router.HandleFunc("/claims/{id}/documents", claimHandler.GetDocuments).Methods("GET")
+	router.HandleFunc("/claims/{id}/documents/{documentId}", claimHandler.GetDocument).Methods("GET")
+	router.HandleFunc("/claims/{id}/documents/{documentId}/link", claimHandler.GetDocumentLink).Methods("GET")
+	// Signed links of the local blob store are served here; the signature
+	// stands in for credentials
+	if local, ok := blobs.(*blobstore.Local); ok {
+		router.PathPrefix(local.PathPrefix()).Handler(local).Methods("GET", "HEAD")
+	}
+	router.Handle("/claims/{id}/reinsurance", middleware.RequireRole(logger, "admin", "adjuster")(http.HandlerFunc(reinsuranceHandler.GetCession))).Methods("GET")
+	router.Handle("/claims/{id}/acord", middleware.RequireRole(logger, "admin", "adjuster")(http.HandlerFunc(acordHandler.ExportClaim))).Methods("GET")
+	router.HandleFunc("/claims", claimHandler.CreateClaim).Methods("POST")
+	router.HandleFunc("/claims/status/bulk", claimHandler.BulkUpdateClaimStatus).Methods("POST")
+	router.HandleFunc("/claims/{id}", claimHandler.UpdateClaim).Methods("PUT")
+	router.HandleFunc("/claims/{id}/status", claimHandler.UpdateClaimStatus).Methods("PUT")
+	router.HandleFunc("/claims/{id}/assignment", claimHandler.AssignClaim).Methods("PUT")
+	router.HandleFunc("/claims/{id}/escalate", claimHandler.EscalateClaim).Methods("POST")
+	router.HandleFunc("/claims/{id}/catastrophe", claimHandler.TagClaimCatastrophe).Methods("PUT")
+	router.HandleFunc("/claims/{id}/documents", claimHandler.UploadDocument).Methods("POST")
+
+	// Comment threads, shared by customers and staff
+	router.Handle("/claims/{id}/comments", identify(http.HandlerFunc(commentHandler.GetComments))).Methods("GET")
+	router.Handle("/claims/{id}/comments", identify(http.HandlerFunc(commentHandler.AddComment))).Methods("POST")
+	router.Handle("/claims/{id}/comments/{commentId}", identify(http.HandlerFunc(commentHandler.UpdateComment))).Methods("PUT")
+
+	// Settlement offers, made by staff and answered by the customer
+	router.Handle("/claims/{id}/offers", identify(http.HandlerFunc(offerHandler.GetOffers))).Methods("GET")
+	router.Handle("/claims/{id}/offers", identify(http.HandlerFunc(offerHandler.MakeOffer))).Methods("POST")
+	router.Handle("/claims/{id}/offers/{offerId}/accept", identify(http.HandlerFunc(offerHandler.AcceptOffer))).Methods("POST")
+	router.Handle("/claims/{id}/offers/{offerId}/reject", identify(http.HandlerFunc(offerHandler.RejectOffer))).Methods("POST")
+	// Payouts of accepted offers, made by staff through payments-service
+	router.Handle("/claims/{id}/payouts", identify(http.HandlerFunc(payoutHandler.CreatePayout))).Methods("POST")
+
+	// Guided first notice of loss, filled in by customers or by agents for them
+	router.Handle("/fnol", identify(http.HandlerFunc(fnolHandler.GetFNOLs))).Methods("GET")
+	router.Handle("/fnol", identify(http.HandlerFunc(fnolHandler.StartFNOL))).Methods("POST")
+	router.Handle("/fnol/{id}", identify(http.HandlerFunc(fnolHandler.GetFNOL))).Methods("GET")
+	router.Handle("/fnol/{id}/step/{n}", identify(http.HandlerFunc(fnolHandler.SaveStep))).Methods("PUT")
+	router.Handle("/fnol/{id}/submit", identify(http.HandlerFunc(fnolHandler.SubmitFNOL))).Methods("POST")
+
+	router.HandleFunc("/claim-types", claimHandler.GetClaimTypes).Methods("GET")
+	router.HandleFunc("/catastrophes", claimHandler.GetCatastrophes).Methods("GET")
+	router.HandleFunc("/catastrophes", claimHandler.CreateCatastrophe).Methods("POST")
+	router.HandleFunc("/catastrophes/{id}", claimHandler.GetCatastropheByID).Methods("GET")
+	router.HandleFunc("/catastrophes/{id}/exposure", claimHandler.GetCatastropheExposure).Methods("GET")
+	router.Handle("/ws/adjusters", adjusterSocketHandler).Methods("GET")
+
+	// Inbound email webhook, authorized by the shared intake token
+	if cfg.EmailIntakeToken != "" {
+		router.HandleFunc("/intake/email", draftHandler.IngestEmail).Methods("POST")
+	}
+
+	// Back-office routes for staff, authorized by JWT role
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(logger, "admin", "adjuster"))
+	admin.Handle("/consistency-report", consistencyHandler).Methods("GET")
+	admin.Handle("/flags/impressions/summary", impressionsHandler).Methods("GET")
+	admin.Handle("/claims/held/recheck", holdRecheckHandler).Methods("POST")
+	admin.Handle("/claims/archive", archiveHandler).Methods("POST")
+	admin.HandleFunc("/dlq", webhookHandler.ListDeadLetters).Methods("GET")
+	admin.Handle("/alerts", alerts.Handler()).Methods("GET")
+	admin.HandleFunc("/dlq/{id}/replay", webhookHandler.ReplayDeadLetter).Methods("POST")
+	admin.HandleFunc("/claims/drafts", draftHandler.GetDrafts).Methods("GET")
+	admin.HandleFunc("/claims/drafts/{id}", draftHandler.GetDraft).Methods("GET")
+	admin.HandleFunc("/claims/drafts/{id}/attachments/{attachmentId}", draftHandler.GetDraftAttachment).Methods("GET")
+	admin.Handle("/claims/drafts/{id}/confirm", identify(http.HandlerFunc(draftHandler.ConfirmDraft))).Methods("POST")
+	admin.Handle("/claims/drafts/{id}/discard", identify(http.HandlerFunc(draftHandler.DiscardDraft))).Methods("POST")
+	admin.HandleFunc("/notifications/templates/{eventType}/preview", notificationHandler.PreviewTemplate).Methods("POST")
+	admin.Handle("/maintenance", middleware.RequireRole(logger, "admin")(maintenanceMode.Handler())).Methods("GET", "PUT")
+	admin.Handle("/faults", middleware.RequireRole(logger, "admin")(faultInjection.Handler())).Methods("GET", "PUT")
+	admin.Handle("/rules", middleware.RequireRole(logger, "admin")(businessRules.Handler())).Methods("GET", "PUT")
+	admin.Handle("/rules/test", businessRules.TestHandler()).Methods("POST")
+	admin.Handle("/auth/login-attempts", middleware.RequireRole(logger, "admin")(http.HandlerFunc(authHandler.GetLoginAttempts))).Methods("GET")
+
+	// Backlog and reinsurance reports for staff
+	reports := router.PathPrefix("/reports").Subrouter()
+	reports.Use(middleware.RequireRole(logger, "admin", "adjuster"))
+	reports.Handle("/claims-aging", agingHandler).Methods("GET")
+	reports.HandleFunc("/reinsurance", reinsuranceHandler.Report).Methods("GET")
+
+	// Claim exports for reinsurers and regulators, for staff
+	exports := router.PathPrefix("/exports").Subrouter()
+	exports.Use(middleware.RequireRole(logger, "admin", "adjuster"))
+	exports.HandleFunc("/acord", acordHandler.ExportClaims).Methods("GET")
+
+	// Wrap router with CORS
+	return &App{
+		Handler:       corsHandler.Handler(router),
+		Flags:         flags,
+		streams:       eventsHandler,
+		stopHub:       stopHub,
+		stopRecheck:   stopRecheck,
+		stopArchive:   stopArchive,
+		stopAlerts:    stopAlerts,
+		stopEstimates: stopEstimates,
+		stopScans:     stopScans,
+		stopHooks:     stopHooks,
+		stopRules:     stopRules,
+		closeStore:    closeStore,
+		logger:        logger,
+	}, nil
+}
+
+// serverDrainTimeout is how long requests in flight get to finish on
+// shutdown
+const serverDrainTimeout = 30 * time.Second
+
+// RegisterShutdown registers the service's components with m in the order
+// they stop. Event streams and adjuster dashboards go first: they never
+// finish on their own, and http.Server.Shutdown does not track hijacked
+// WebSocket connections. server, when given, drains next, while the hold
+// recheck, claim archival, alert evaluation, damage estimates, document
+// scans, webhook deliveries and storage still serve the requests in
+// flight; those stop
+// last, storage after the work that writes to it.
+func (a *App) RegisterShutdown(m *lifecycle.Manager, server lifecycle.StopFunc) {
+	m.Register("event streams", 5*time.Second, func(ctx context.Context) error {
+		a.streams.Close()
+		return nil
+	})
+	m.Register("adjuster dashboards", 5*time.Second, a.stopHub)
+	if server != nil {
+		m.Register("http server", serverDrainTimeout, server)
+	}
+	if a.stopRecheck != nil {
+		m.Register("hold recheck", 10*time.Second, a.stopRecheck)
+	}
+	if a.stopArchive != nil {
+		m.Register("claim archival", 10*time.Second, a.stopArchive)
+	}
+	if a.stopAlerts != nil {
+		m.Register("alert evaluation", 5*time.Second, a.stopAlerts)
+	}
+	if a.stopRules != nil {
+		m.Register("business rules reload", 5*time.Second, a.stopRules)
+	}
+	// An estimate in progress is cancelled; the claim keeps its last one
+	if a.stopEstimates != nil {
+		m.Register("damage estimates", 5*time.Second, a.stopEstimates)
+	}
+	// A scan in progress is cancelled; the document stays pending and is
+	// scanned on the next start
+	m.Register("document scans", 5*time.Second, a.stopScans)
+	// Deliveries still queued or retrying are dead-lettered for replay
+	m.Register("webhook dispatcher", 15*time.Second, a.stopHooks)
+	m.Register("storage", 10*time.Second, func(ctx context.Context) error {
+		return a.closeStore()
+	})
+	m.Register("feature flags", 5*time.Second, func(ctx context.Context) error {
+		features.Shutdown()
+		return nil
+	})
+}
+
+// Close stops the service's components without an HTTP server, writing a
+// final snapshot of persisted state
+func (a *App) Close() {
+	m := lifecycle.New(a.logger)
+	a.RegisterShutdown(m, nil)
+	m.Shutdown(context.Background())
+}
+
+// newSessionService signs in the configured account with tokens signed
+// with JWT_SECRET, keeping two-factor enrollments in store
+func newSessionService(cfg Config, store repository.TwoFactorStore, logger *logrus.Logger) (*services.SessionService, error) {
+	username := cfg.AuthUsername
+	if username == "" {
+		username = "demo@insurancestack.com"
+	}
+	password := cfg.AuthPassword
+	if password == "" {
+		password = "demo123"
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	account := services.Credentials{UserID: "user-001", Email: username, Name: "Demo User", Role: cfg.AuthRole, PasswordHash: hash}
+	twoFactor := services.TwoFactorPolicy{Store: store, Roles: cfg.TwoFactorRoles}
+	return services.NewSessionService(middleware.NewJWTManager(logger), account, cfg.LoginLockout, twoFactor, logger), nil
+}
+
+// loadClaimTypes reads the claim taxonomy. A configured file must load; the
+// default file may be missing.
+func loadClaimTypes(cfg Config, logger *logrus.Logger) (*taxonomy.Taxonomy, error) {
+	path := cfg.ClaimTypesFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "claim-types.json")
+	}
+	types, err := taxonomy.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ClaimTypesFile == "" {
+		logger.Warnf("No claim types in %s, using accident, theft and damage for every policy type", path)
+		return taxonomy.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load claim types: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version":     types.Version,
+		"policyTypes": len(types.PolicyTypes),
+	}).Infof("Loaded claim types from %s", path)
+	return types, nil
+}
+
+// loadBusinessRules reads the claim rules of the business rules engine. A
+// configured file must load; the default file may be missing.
+func loadBusinessRules(cfg Config, logger *logrus.Logger) (*rules.Engine, error) {
+	path := cfg.BusinessRulesFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "business-rules.json")
+	}
+	engine := rules.New(rules.Config{
+		Path:     path,
+		Domains:  []string{rules.DomainClaim},
+		Validate: approval.ValidateRule,
+	}, logger)
+	err := engine.Load()
+	if errors.Is(err, os.ErrNotExist) && cfg.BusinessRulesFile == "" {
+		logger.Warnf("No business rules in %s, starting without claim rules", path)
+		return engine, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load business rules: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": engine.Version(),
+		"rules":   engine.Len(),
+	}).Infof("Loaded business rules from %s", path)
+	return engine, nil
+}
+
+// loadApprovalPolicy selects the policy that decides new claims, reading
+// the rules file for the rules policy
+func loadApprovalPolicy(cfg Config, flags *features.Flags, businessRules *rules.Engine, logger *logrus.Logger) (services.ApprovalPolicy, error) {
+	switch cfg.ApprovalPolicy {
+	case "", approval.NameThreshold:
+		logger.Info("Deciding new claims by the auto-approval threshold")
+		return approval.NewThreshold(flags), nil
+	case approval.NameEngine:
+		logger.WithField("rules", businessRules.Len()).Info("Deciding new claims by the claim rules of the business rules engine")
+		return approval.NewEngine(businessRules), nil
+	case approval.NameRules:
+	default:
+		return nil, fmt.Errorf("unknown approval policy %q (want %s, %s or %s)", cfg.ApprovalPolicy, approval.NameThreshold, approval.NameRules, approval.NameEngine)
+	}
+
+	path := cfg.ApprovalRulesFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "approval-rules.json")
+	}
+	rules, err := approval.LoadRules(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load approval rules: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": rules.Version,
+		"rules":   len(rules.Rules),
+	}).Infof("Deciding new claims by the approval rules in %s", path)
+	return rules, nil
+}
+
+// loadNotificationTemplates reads the notification templates. A configured
+// file must load; the default file may be missing.
+func loadNotificationTemplates(cfg Config, logger *logrus.Logger) (*notifications.Templates, error) {
+	path := cfg.NotificationTemplatesFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "notification-templates.json")
+	}
+	templates, err := notifications.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.NotificationTemplatesFile == "" {
+		logger.Warnf("No notification templates in %s, using the built-in English templates", path)
+		return notifications.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version":    templates.Version,
+		"eventTypes": len(templates.Events),
+	}).Infof("Loaded notification templates from %s", path)
+	return templates, nil
+}
+
+// loadReinsurance reads the reinsurance treaties. A configured file must
+// load; the default file may be missing.
+func loadReinsurance(cfg Config, logger *logrus.Logger) (*reinsurance.Treaties, error) {
+	path := cfg.ReinsuranceFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "reinsurance.json")
+	}
+	treaties, err := reinsurance.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ReinsuranceFile == "" {
+		logger.Warnf("No reinsurance treaties in %s, claims are retained in full", path)
+		return reinsurance.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reinsurance treaties: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version":  treaties.Version,
+		"treaties": len(treaties.Treaties),
+	}).Infof("Loaded reinsurance treaties from %s", path)
+	return treaties, nil
+}
+
+// loadAcordMapping reads the ACORD code mapping. A configured file must
+// load; the default file may be missing.
+func loadAcordMapping(cfg Config, logger *logrus.Logger) (*acord.Mapping, error) {
+	path := cfg.AcordMappingFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "acord.json")
+	}
+	mapping, err := acord.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.AcordMappingFile == "" {
+		logger.Warnf("No ACORD mapping in %s, using the built-in codes", path)
+		return acord.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ACORD mapping: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": mapping.Version,
+		"sender":  mapping.Sender,
+	}).Infof("Loaded ACORD mapping from %s", path)
+	return mapping, nil
+}
+
+// loadShaping reads the response shaping policy and checks it, and the
+// shape tags, against the claim responses. A configured file must load;
+// the default file may be missing.
+func loadShaping(cfg Config, logger *logrus.Logger) (*shaping.Shaper, error) {
+	path := cfg.ShapingFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "response-shaping.json")
+	}
+	policy, err := shaping.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ShapingFile == "" {
+		logger.Warnf("No response shaping policy in %s, shape tags alone apply", path)
+		policy = shaping.Default()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load response shaping policy: %w", err)
+	} else {
+		logger.WithField("version", policy.Version).Infof("Loaded response shaping policy from %s", path)
+	}
+
+	shaper := shaping.New(policy, "claims-service")
+	if err := shaper.Check(models.Claim{}); err != nil {
+		return nil, fmt.Errorf("invalid response shaping: %w", err)
+	}
+	return shaper, nil
+}
+
+// loadRetention reads the retention policy. A configured file must load;
+// the default file may be missing.
+func loadRetention(cfg Config, logger *logrus.Logger) (*retention.Policy, error) {
+	path := cfg.RetentionFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "retention.json")
+	}
+	policy, err := retention.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.RetentionFile == "" {
+		logger.Warnf("No retention policy in %s, claims are never archived", path)
+		return retention.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retention policy: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": policy.Version,
+		"rules":   len(policy.Rules),
+	}).Infof("Loaded retention policy from %s", path)
+	return policy, nil
+}
+
+// loadAlerts reads the alert thresholds of the service's metrics. A
+// configured file must load; without the default file no alerts are
+// raised.
+func loadAlerts(cfg Config, metrics map[string]alerting.Metric, logger *logrus.Logger) (*alerting.Evaluator, error) {
+	path := cfg.AlertsFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "alerts.json")
+	}
+	var notifier alerting.Notifier
+	if len(cfg.AlertWebhookURLs) > 0 {
+		notifier = &alerting.Webhooks{URLs: cfg.AlertWebhookURLs, Signer: cfg.AlertWebhookSecrets}
+	}
+	alerts := alerting.New(alerting.Config{Service: "claims", Path: path, Metrics: metrics, Notifier: notifier}, logger)
+	err := alerts.Load()
+	if errors.Is(err, os.ErrNotExist) && cfg.AlertsFile == "" {
+		logger.Infof("No alert thresholds in %s, no alerts are raised", path)
+		return alerts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert thresholds: %w", err)
+	}
+	logger.WithField("rules", alerts.Len()).Infof("Loaded alert thresholds from %s", path)
+	return alerts, nil
+}
+
+// loadServiceClients reads the clients of the client credentials grant. A
+// configured file must load; without the default file no client can get
+// service tokens.
+func loadServiceClients(cfg Config, logger *logrus.Logger) ([]auth.Client, error) {
+	path := cfg.ServiceClientsFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "service-clients.json")
+	}
+	clients, err := auth.LoadClients(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ServiceClientsFile == "" {
+		logger.Infof("No service clients in %s, POST /auth/token grants no tokens", path)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load service clients: %w", err)
+	}
+	logger.WithField("clients", len(clients)).Infof("Loaded service clients from %s", path)
+	return clients, nil
+}
+
+// limitsConfig merges the configured route limits over the defaults
+// checkFallbacks refuses fallbacks for operations that have none, so a
+// misspelt operation does not silently fail open
+func checkFallbacks(fallbacks map[string]breaker.Fallback) error {
+	for operation := range fallbacks {
+		known := false
+		for _, name := range fallbackOperations {
+			known = known || operation == name
+		}
+		if !known {
+			return fmt.Errorf("unknown circuit breaker fallback operation %q (must be one of %s)", operation, strings.Join(fallbackOperations, ", "))
+		}
+	}
+	return nil
+}
+
+func limitsConfig(cfg Config) middleware.LimitsConfig {
+	routes := make(map[string]middleware.RouteLimits, len(defaultRouteLimits)+len(cfg.RouteLimits))
+	for route, limits := range defaultRouteLimits {
+		routes[route] = limits
+	}
+	for route, limits := range cfg.RouteLimits {
+		routes[route] = limits
+	}
+	return middleware.LimitsConfig{
+		Default: middleware.RouteLimits{MaxBodyBytes: cfg.MaxBodyBytes, Timeout: cfg.HandlerTimeout},
+		Routes:  routes,
+	}
+}
+
+// newStore opens the storage backend selected by cfg. closeStore flushes
+// and releases it.
+func newStore(cfg Config, logger *logrus.Logger) (store repository.Store, closeStore func() error, err error) {
+	switch cfg.StorageBackend {
+	case "", "memory":
+		return newMemoryStore(cfg, logger)
+	case "redis":
+		if cfg.PersistDir != "" {
+			logger.Warn("PersistDir is ignored with the redis storage backend")
+		}
+		repo, err := repository.NewRedisRepository(cfg.RedisURL, cfg.DataPath, logger)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize repository: %w", err)
+		}
+		return repo, repo.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q (must be memory or redis)", cfg.StorageBackend)
+	}
+}
+
+// newMemoryStore loads the JSON seed data into memory, recovering and
+// journaling changes when cfg.PersistDir is set
+func newMemoryStore(cfg Config, logger *logrus.Logger) (repository.Store, func() error, error) {
+	repo, err := repository.NewRepository(cfg.DataPath, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+	if cfg.PersistDir == "" {
+		return repo, func() error { return nil }, nil
+	}
+
+	journal, err := persist.Open(cfg.PersistDir, "claims-service", cfg.PersistFlushInterval, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open persisted state: %w", err)
+	}
+	if err := repo.Persist(journal); err != nil {
+		journal.Close()
+		return nil, nil, fmt.Errorf("failed to restore persisted state: %w", err)
+	}
+	return repo, journal.Close, nil
+}
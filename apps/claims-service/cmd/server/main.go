@@ -7,36 +7,43 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/features"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/scan"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
-	"github.com/gorilla/mux"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/logging"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	// Initialize logger from LOG_FORMAT, LOG_LEVEL, LOG_LEVELS and sampling settings
+	logger := logging.NewFromEnv()
 
-	// Configure log level from environment
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-	level, err := logrus.ParseLevel(logLevel)
+	logger.Info("Starting Claims Service...")
+
+	// Secrets come from SECRETS_PROVIDER: the environment by default, or
+	// mounted files, Vault or AWS Secrets Manager. Startup fails when one
+	// of SECRETS_REQUIRED does not resolve.
+	secretStore, err := secrets.Open(secrets.ConfigFromEnv(logger), logger)
 	if err != nil {
-		logger.Warnf("Invalid log level '%s', defaulting to info", logLevel)
-		level = logrus.InfoLevel
+		logger.WithError(err).Fatal("Failed to resolve secrets")
 	}
-	logger.SetLevel(level)
-
-	logger.Info("Starting Claims Service...")
+	secrets.SetDefault(secretStore)
+	logger.WithField("provider", secretStore.Source()).Info("Secrets resolved")
 
 	// Get configuration from environment
 	port := os.Getenv("PORT")
@@ -50,75 +57,511 @@ func main() {
 		dataPath = filepath.Join("..", "..", "data", "seed")
 	}
 
-	cloudBeesAPIKey := os.Getenv("CLOUDBEES_FM_API_KEY")
+	// Claim types and sub-types per policy type; defaults to
+	// claim-types.json in DATA_PATH
+	claimTypesFile := os.Getenv("CLAIM_TYPES_FILE")
+
+	// Policy deciding new claims: threshold (default), rules, read from
+	// APPROVAL_RULES_FILE or approval-rules.json in DATA_PATH, or engine, the
+	// claim rules of the business rules engine
+	approvalPolicy := os.Getenv("APPROVAL_POLICY")
+	approvalRulesFile := os.Getenv("APPROVAL_RULES_FILE")
+
+	// Business rules engine expressions; defaults to business-rules.json in
+	// DATA_PATH, checked for changes every BUSINESS_RULES_RELOAD_INTERVAL
+	businessRulesFile := os.Getenv("BUSINESS_RULES_FILE")
+	businessRulesReloadInterval := 30 * time.Second
+	if v := os.Getenv("BUSINESS_RULES_RELOAD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			businessRulesReloadInterval = d
+		} else {
+			logger.Warnf("Invalid BUSINESS_RULES_RELOAD_INTERVAL '%s', defaulting to %s", v, businessRulesReloadInterval)
+		}
+	}
+
+	// Format of new claim numbers; defaults to CLM-{year}-{seq:6}
+	claimNumberFormat := os.Getenv("CLAIM_NUMBER_FORMAT")
+
+	// Notification subjects and bodies per event type and locale; defaults
+	// to notification-templates.json in DATA_PATH
+	notificationTemplatesFile := os.Getenv("NOTIFICATION_TEMPLATES_FILE")
+
+	// Excess of loss treaty per policy type; defaults to reinsurance.json in
+	// DATA_PATH
+	reinsuranceFile := os.Getenv("REINSURANCE_FILE")
+
+	// ACORD codes of policy types, claim types and statuses; defaults to
+	// acord.json in DATA_PATH
+	acordMappingFile := os.Getenv("ACORD_MAPPING_FILE")
+
+	cloudBeesAPIKey := secretStore.Lookup("CLOUDBEES_FM_API_KEY", "")
 	if cloudBeesAPIKey == "" {
 		logger.Warn("CLOUDBEES_FM_API_KEY not set, feature flags will use defaults")
 		// Use a placeholder for development
 		cloudBeesAPIKey = "dev-mode"
 	}
 
-	// Initialize CloudBees Feature Management
-	flags, err := features.Initialize(cloudBeesAPIKey, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize feature management")
+	// Real-time update settings
+	eventHistorySize := 1000
+	if v := os.Getenv("EVENT_HISTORY_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			eventHistorySize = n
+		} else {
+			logger.Warnf("Invalid EVENT_HISTORY_SIZE '%s', defaulting to %d", v, eventHistorySize)
+		}
 	}
-	defer features.Shutdown()
 
-	// Initialize repository
-	repo, err := repository.NewRepository(dataPath, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize repository")
+	sseHeartbeat := 15 * time.Second
+	if v := os.Getenv("SSE_HEARTBEAT_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			sseHeartbeat = d
+		} else {
+			logger.Warnf("Invalid SSE_HEARTBEAT_INTERVAL '%s', defaulting to %s", v, sseHeartbeat)
+		}
+	}
+
+	wsSendBuffer := 32
+	if v := os.Getenv("WS_SEND_BUFFER"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			wsSendBuffer = n
+		} else {
+			logger.Warnf("Invalid WS_SEND_BUFFER '%s', defaulting to %d", v, wsSendBuffer)
+		}
+	}
+
+	// Other services, used for grace checks and the consistency report
+	policyServiceURL := os.Getenv("POLICY_SERVICE_URL")
+	if policyServiceURL == "" {
+		logger.Warn("POLICY_SERVICE_URL not set, claims will not be held for policies in grace and consistency report will skip policy checks")
+	}
+	paymentsServiceURL := os.Getenv("PAYMENTS_SERVICE_URL")
+	if paymentsServiceURL == "" {
+		logger.Warn("PAYMENTS_SERVICE_URL not set, claim timelines will not include payouts")
+	}
+
+	holdRecheckInterval := 5 * time.Minute
+	if v := os.Getenv("CLAIMS_HOLD_RECHECK_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			holdRecheckInterval = d
+		} else {
+			logger.Warnf("Invalid CLAIMS_HOLD_RECHECK_INTERVAL '%s', defaulting to %s", v, holdRecheckInterval)
+		}
+	}
+
+	// How long decided claims stay live; defaults to retention.json in
+	// DATA_PATH. The archival job runs every ARCHIVE_INTERVAL.
+	retentionFile := os.Getenv("RETENTION_FILE")
+	archiveInterval := 24 * time.Hour
+	if v := os.Getenv("ARCHIVE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			archiveInterval = d
+		} else {
+			logger.Warnf("Invalid ARCHIVE_INTERVAL '%s', defaulting to %s", v, archiveInterval)
+		}
+	}
+
+	// Prices claims from their photos as they are uploaded: stub, or unset
+	// to leave claims unestimated
+	damageEstimator := os.Getenv("DAMAGE_ESTIMATOR")
+
+	// Scans uploaded documents for malware: clamav, with the clamd
+	// host:port in DOCUMENT_SCANNER_ADDRESS, icap, with the ICAP service
+	// URL, or noop, the default, to mark documents clean unscanned
+	documentScanner := os.Getenv("DOCUMENT_SCANNER")
+	documentScannerAddress := os.Getenv("DOCUMENT_SCANNER_ADDRESS")
+	documentScanTimeout := scan.DefaultTimeout
+	if v := os.Getenv("DOCUMENT_SCAN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			documentScanTimeout = d
+		} else {
+			logger.Warnf("Invalid DOCUMENT_SCAN_TIMEOUT '%s', defaulting to %s", v, documentScanTimeout)
+		}
+	}
+
+	// Keeps document content in a shared blob store: local, under
+	// BLOBSTORE_DIR, or s3, in BLOBSTORE_S3_BUCKET. Unset keeps it in the
+	// claims store.
+	blobConfig := blobstore.ConfigFromEnv(logger)
+	blobConfig.Local.SigningKey = secretStore.Lookup("BLOBSTORE_SIGNING_KEY", "")
+
+	// Field visibility rules on top of the shape tags; defaults to
+	// response-shaping.json in DATA_PATH
+	shapingFile := os.Getenv("SHAPING_FILE")
+
+	// Storage backend; redis lets several instances share claims
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	redisURL := os.Getenv("REDIS_URL")
+	if redisURL == "" {
+		redisURL = "redis://localhost:6379/0"
+	}
+
+	// Persisted state, so changes survive restarts
+	persistDir := os.Getenv("PERSIST_DIR")
+	if persistDir == "" && storageBackend != "redis" {
+		logger.Warn("PERSIST_DIR not set, changes will be lost on restart")
+	}
+	persistFlushInterval := time.Minute
+	if v := os.Getenv("PERSIST_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			persistFlushInterval = d
+		} else {
+			logger.Warnf("Invalid PERSIST_FLUSH_INTERVAL '%s', defaulting to %s", v, persistFlushInterval)
+		}
+	}
+
+	// Webhook endpoints for claim events, comma-separated
+	var webhookURLs []string
+	for _, u := range strings.Split(os.Getenv("WEBHOOK_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			webhookURLs = append(webhookURLs, u)
+		}
+	}
+	// Comma-separated, newest first: deliveries are signed with each, so
+	// receivers can rotate their secret
+	webhookSecrets := signing.ParseKeys(secretStore.Lookup("WEBHOOK_SECRET", ""))
+	if len(webhookURLs) > 0 && len(webhookSecrets) == 0 {
+		logger.Warn("WEBHOOK_SECRET not set, webhook deliveries will be unsigned")
+	}
+	webhookMaxAttempts := 5
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			webhookMaxAttempts = n
+		} else {
+			logger.Warnf("Invalid WEBHOOK_MAX_ATTEMPTS '%s', defaulting to %d", v, webhookMaxAttempts)
+		}
+	}
+	webhookRetryBackoff := time.Second
+	if v := os.Getenv("WEBHOOK_RETRY_BACKOFF"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			webhookRetryBackoff = d
+		} else {
+			logger.Warnf("Invalid WEBHOOK_RETRY_BACKOFF '%s', defaulting to %s", v, webhookRetryBackoff)
+		}
 	}
 
-	// Initialize services
-	claimService := services.NewClaimService(repo, flags, logger)
+	// Alerts on business thresholds, from ALERTS_FILE or alerts.json in
+	// DATA_PATH, evaluated every ALERT_EVALUATION_INTERVAL and POSTed to
+	// ALERT_WEBHOOK_URLS
+	alertInterval := alerting.DefaultInterval
+	if v := os.Getenv("ALERT_EVALUATION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			alertInterval = d
+		} else {
+			logger.Warnf("Invalid ALERT_EVALUATION_INTERVAL '%s', defaulting to %s", v, alertInterval)
+		}
+	}
+	var alertWebhookURLs []string
+	for _, u := range strings.Split(os.Getenv("ALERT_WEBHOOK_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			alertWebhookURLs = append(alertWebhookURLs, u)
+		}
+	}
+	alertWebhookSecrets := signing.ParseKeys(secretStore.Lookup("ALERT_WEBHOOK_SECRET", ""))
 
-	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	claimHandler := handlers.NewClaimHandler(claimService, logger)
+	// Shared secret the inbound email webhook is authorized by
+	emailIntakeToken := secretStore.Lookup("EMAIL_INTAKE_TOKEN", "")
+	if emailIntakeToken == "" {
+		logger.Info("EMAIL_INTAKE_TOKEN not set, email claim intake disabled")
+	}
 
-	// Setup router
-	router := mux.NewRouter()
+	// Usernames are locked out after LOGIN_MAX_FAILURES failed sign-ins in
+	// a row (0 never locks), for LOGIN_LOCKOUT_DURATION
+	loginLockout := services.LockoutPolicy{
+		MaxFailures: services.DefaultMaxLoginFailures,
+		Duration:    services.DefaultLockoutDuration,
+	}
+	if v := os.Getenv("LOGIN_MAX_FAILURES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			loginLockout.MaxFailures = n
+		} else {
+			logger.Warnf("Invalid LOGIN_MAX_FAILURES '%s', defaulting to %d", v, loginLockout.MaxFailures)
+		}
+	}
+	if v := os.Getenv("LOGIN_LOCKOUT_DURATION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			loginLockout.Duration = d
+		} else {
+			logger.Warnf("Invalid LOGIN_LOCKOUT_DURATION '%s', defaulting to %s", v, loginLockout.Duration)
+		}
+	}
+
+	// Accounts with a role in TWO_FACTOR_ROLES, comma-separated, must sign
+	// in with a second factor; set it empty to require it of none
+	twoFactorRoles := []string{"admin", "adjuster"}
+	if v, ok := os.LookupEnv("TWO_FACTOR_ROLES"); ok {
+		twoFactorRoles = nil
+		for _, role := range strings.Split(v, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				twoFactorRoles = append(twoFactorRoles, role)
+			}
+		}
+	}
+
+	// Service tokens of POST /auth/token last SERVICE_TOKEN_TTL
+	var serviceTokenTTL time.Duration
+	if v := os.Getenv("SERVICE_TOKEN_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			serviceTokenTTL = d
+		} else {
+			logger.Warnf("Invalid SERVICE_TOKEN_TTL '%s', defaulting to %s", v, services.DefaultServiceTokenTTL)
+		}
+	}
+
+	// Requests at least this slow are logged as warnings
+	slowRequestThreshold := time.Second
+	if v := os.Getenv("HTTP_SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			slowRequestThreshold = d
+		} else {
+			logger.Warnf("Invalid HTTP_SLOW_REQUEST_THRESHOLD '%s', defaulting to %s", v, slowRequestThreshold)
+		}
+	}
 
-	// Apply global middleware
-	router.Use(middleware.LoggingMiddleware(logger))
-	router.Use(middleware.AuthMiddleware(logger))
+	// Request body and handler time limits, with per-route overrides
+	maxBodyBytes := int64(1 << 20)
+	if v := os.Getenv("HTTP_MAX_BODY_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n >= 0 {
+			maxBodyBytes = n
+		} else {
+			logger.Warnf("Invalid HTTP_MAX_BODY_BYTES '%s', defaulting to %d", v, maxBodyBytes)
+		}
+	}
+	handlerTimeout := 10 * time.Second
+	if v := os.Getenv("HTTP_HANDLER_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			handlerTimeout = d
+		} else {
+			logger.Warnf("Invalid HTTP_HANDLER_TIMEOUT '%s', defaulting to %s", v, handlerTimeout)
+		}
+	}
+	var routeLimits map[string]middleware.RouteLimits
+	if v := os.Getenv("HTTP_ROUTE_LIMITS"); v != "" {
+		if parsed, err := middleware.ParseRouteLimits([]byte(v)); err == nil {
+			routeLimits = parsed
+		} else {
+			logger.WithError(err).Warn("Invalid HTTP_ROUTE_LIMITS, using the default route limits")
+		}
+	}
 
-	// Setup CORS
-	corsHandler := middleware.NewCORS()
+	// Circuit breakers on calls to policy-service and payments-service,
+	// with per-route overrides, and the fallback of each operation
+	circuitBreaker := breaker.Config{Default: breaker.Settings{
+		FailureThreshold: breaker.DefaultFailureThreshold,
+		OpenTimeout:      breaker.DefaultOpenTimeout,
+		HalfOpenProbes:   breaker.DefaultHalfOpenProbes,
+	}}
+	if v := os.Getenv("CIRCUIT_BREAKER_FAILURE_THRESHOLD"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			circuitBreaker.Default.FailureThreshold = n
+		} else {
+			logger.Warnf("Invalid CIRCUIT_BREAKER_FAILURE_THRESHOLD '%s', defaulting to %d", v, circuitBreaker.Default.FailureThreshold)
+		}
+	}
+	if v := os.Getenv("CIRCUIT_BREAKER_OPEN_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			circuitBreaker.Default.OpenTimeout = d
+		} else {
+			logger.Warnf("Invalid CIRCUIT_BREAKER_OPEN_TIMEOUT '%s', defaulting to %s", v, circuitBreaker.Default.OpenTimeout)
+		}
+	}
+	if v := os.Getenv("CIRCUIT_BREAKER_HALF_OPEN_PROBES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			circuitBreaker.Default.HalfOpenProbes = n
+		} else {
+			logger.Warnf("Invalid CIRCUIT_BREAKER_HALF_OPEN_PROBES '%s', defaulting to %d", v, circuitBreaker.Default.HalfOpenProbes)
+		}
+	}
+	if v := os.Getenv("CIRCUIT_BREAKER_ROUTES"); v != "" {
+		if parsed, err := breaker.ParseRoutes([]byte(v)); err == nil {
+			circuitBreaker.Routes = parsed
+		} else {
+			logger.WithError(err).Warn("Invalid CIRCUIT_BREAKER_ROUTES, using the default settings on every route")
+		}
+	}
+	var fallbacks map[string]breaker.Fallback
+	if v := os.Getenv("CIRCUIT_BREAKER_FALLBACKS"); v != "" {
+		if parsed, err := breaker.ParseFallbacks(v); err == nil {
+			fallbacks = parsed
+		} else {
+			logger.WithError(err).Warn("Invalid CIRCUIT_BREAKER_FALLBACKS, failing open everywhere")
+		}
+	}
 
-	// Register routes
-	router.Handle("/healthz", healthHandler).Methods("GET")
-	router.HandleFunc("/claims", claimHandler.GetClaims).Methods("GET")
-	router.HandleFunc("/claims/{id}", claimHandler.GetClaimByID).Methods("GET")
-	router.HandleFunc("/claims", claimHandler.CreateClaim).Methods("POST")
-	router.HandleFunc("/claims/{id}", claimHandler.UpdateClaim).Methods("PUT")
-	router.HandleFunc("/claims/{id}/status", claimHandler.UpdateClaimStatus).Methods("PUT")
+	// Access entries go to the service log unless ACCESS_LOG names a
+	// separate stream: stdout, stderr or a file path
+	accessLog, closeAccessLog, err := telemetry.OpenAccessLog(os.Getenv("ACCESS_LOG"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open access log")
+	}
+	defer closeAccessLog()
 
-	// Wrap router with CORS
-	handler := corsHandler.Handler(router)
+	// Assemble the service
+	application, err := app.New(app.Config{
+		DataPath:                    dataPath,
+		FeatureAPIKey:               cloudBeesAPIKey,
+		EventHistorySize:            eventHistorySize,
+		SSEHeartbeat:                sseHeartbeat,
+		WSSendBuffer:                wsSendBuffer,
+		PolicyServiceURL:            policyServiceURL,
+		ClaimTypesFile:              claimTypesFile,
+		ApprovalPolicy:              approvalPolicy,
+		ApprovalRulesFile:           approvalRulesFile,
+		BusinessRulesFile:           businessRulesFile,
+		BusinessRulesReloadInterval: businessRulesReloadInterval,
+		ClaimNumberFormat:           claimNumberFormat,
+		NotificationTemplatesFile:   notificationTemplatesFile,
+		ReinsuranceFile:             reinsuranceFile,
+		AcordMappingFile:            acordMappingFile,
+		RetentionFile:               retentionFile,
+		ArchiveInterval:             archiveInterval,
+		ShapingFile:                 shapingFile,
+		DamageEstimator:             damageEstimator,
+		DocumentScanner:             documentScanner,
+		DocumentScannerAddress:      documentScannerAddress,
+		DocumentScanTimeout:         documentScanTimeout,
+		Blobs:                       blobConfig,
+		PaymentsServiceURL:          paymentsServiceURL,
+		HoldRecheckInterval:         holdRecheckInterval,
+		PersistDir:                  persistDir,
+		PersistFlushInterval:        persistFlushInterval,
+		StorageBackend:              storageBackend,
+		RedisURL:                    redisURL,
+		WebhookURLs:                 webhookURLs,
+		WebhookSecrets:              webhookSecrets,
+		WebhookMaxAttempts:          webhookMaxAttempts,
+		WebhookRetryBackoff:         webhookRetryBackoff,
+		AlertsFile:                  os.Getenv("ALERTS_FILE"),
+		AlertInterval:               alertInterval,
+		AlertWebhookURLs:            alertWebhookURLs,
+		AlertWebhookSecrets:         alertWebhookSecrets,
+		Maintenance:                 maintenance.ConfigFromEnv(logger),
+		Faults:                      faults.ConfigFromEnv(logger),
+		EmailIntakeToken:            emailIntakeToken,
+		AuthUsername:                os.Getenv("AUTH_USERNAME"),
+		AuthPassword:                os.Getenv("AUTH_PASSWORD"),
+		AuthRole:                    os.Getenv("AUTH_ROLE"),
+		LoginLockout:                loginLockout,
+		TwoFactorRoles:              twoFactorRoles,
+		ServiceClientsFile:          os.Getenv("SERVICE_CLIENTS_FILE"),
+		ServiceTokenTTL:             serviceTokenTTL,
+		SlowRequestThreshold:        slowRequestThreshold,
+		AccessLog:                   accessLog,
+		MaxBodyBytes:                maxBodyBytes,
+		HandlerTimeout:              handlerTimeout,
+		RouteLimits:                 routeLimits,
+		CircuitBreaker:              circuitBreaker,
+		Fallbacks:                   fallbacks,
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize service")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      handler,
+		Handler:      application.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Drain requests in flight and stop the service's components in order
+	conns := lifecycle.TrackConnections(server)
+	shutdown := lifecycle.New(logger)
+	application.RegisterShutdown(shutdown, lifecycle.DrainServer(server, conns))
+
 	// Start server in a goroutine
 	go func() {
 		logger.Infof("Server listening on port %s", port)
 		logger.Info("API Endpoints:")
 		logger.Info("  GET /healthz - Health check")
+		logger.Info("  GET /labels - Status and type labels in the Accept-Language language")
+		logger.Info("  GET /metrics - Request latency by route, webhook deliveries and dead-letter queue depth (Prometheus)")
+		logger.Info("  POST /auth/login - Sign in, starting a session or a second-factor challenge")
+		logger.Info("  POST /auth/token - Issue a service token with the client credentials grant")
+		logger.Info("  POST /auth/login/verify - Finish a sign-in with a TOTP or recovery code")
+		logger.Info("  GET /auth/2fa - The signed-in user's two-factor status")
+		logger.Info("  POST /auth/2fa/enroll - Start enrolling an authenticator app")
+		logger.Info("  POST /auth/2fa/confirm - Confirm an enrollment, issuing recovery codes")
+		logger.Info("  POST /auth/2fa/recovery-codes - Replace the recovery codes")
+		logger.Info("  GET /auth/sessions - The signed-in user's sessions")
+		logger.Info("  DELETE /auth/sessions/{id} - Sign a session out")
 		logger.Info("  GET /claims - List claims with optional filters")
-		logger.Info("    Query params: policyId, customerId, status, type")
+		logger.Info("    Query params: policyId, customerId, status, type, subType, catastropheId, incidentFrom, incidentTo, submittedFrom, submittedTo, includeArchived (admin/adjuster JWT)")
+		logger.Info("  GET /claims/stream - Stream claim status changes (SSE)")
+		logger.Info("    Query params: customerId")
+		logger.Info("  GET /claims/stats - Claim counts by status, type and rejection reason")
 		logger.Info("  GET /claims/{id} - Get claim by ID")
+		logger.Info("    Query params: includeArchived (admin/adjuster JWT)")
+		logger.Info("  GET /claims/{id}/events - Stream status changes for a claim (SSE)")
+		logger.Info("  GET /my/claims - The caller's own claims, without internal fields")
+		logger.Info("    Query params: policyId, status")
+		logger.Info("  GET /my/claims/{id} - One of the caller's own claims, without internal fields")
 		logger.Info("  POST /claims - Submit new claim")
+		logger.Info("    Note: Likely duplicates return 409 unless force=true")
+		logger.Info("    Note: Claims on policies in grace are held as pending_payment")
 		logger.Info("  PUT /claims/{id} - Update claim")
 		logger.Info("  PUT /claims/{id}/status - Change claim status (approval workflow)")
-		logger.Info("    Note: Auto-approval enabled by claims.autoApproval feature flag")
+		logger.Info("    Note: Auto-approval enabled by claims.autoApproval feature flag, up to claims.autoApprovalThreshold")
+		logger.Info("    Note: Rejections require rejectionCodes")
+		logger.Info("  POST /claims/status/bulk - Change the status of several claims, each at an expected version")
+		logger.Info("  PUT /claims/{id}/assignment - Assign claim to an adjuster")
+		logger.Info("  POST /claims/{id}/escalate - Escalate claim for senior review")
+		logger.Info("  PUT /claims/{id}/catastrophe - Tag claim to a catastrophe event")
+		logger.Info("  GET /claims/{id}/offers - List settlement offers on a claim")
+		logger.Info("  POST /claims/{id}/offers - Offer an amount and expiry to settle an approved claim (admin/adjuster JWT)")
+		logger.Info("  POST /claims/{id}/offers/{offerId}/accept - Accept a settlement offer (claim's customer)")
+		logger.Info("  POST /claims/{id}/offers/{offerId}/reject - Reject a settlement offer (claim's customer)")
+		logger.Info("  POST /claims/{id}/payouts - Pay out an accepted offer through payments-service (admin/adjuster JWT)")
+		logger.Info("  POST /fnol - Start a guided first notice of loss")
+		logger.Info("  GET /fnol - List your first notice of loss reports")
+		logger.Info("  GET /fnol/{id} - Get a first notice of loss report")
+		logger.Info("  PUT /fnol/{id}/step/{n} - Fill step 1 (incident), 2 (parties) or 3 (damage)")
+		logger.Info("  POST /fnol/{id}/submit - File a complete report as a claim")
+		logger.Info("  GET /claim-types - Claim types and sub-types per policy type")
+		logger.Info("    Query params: policyType")
+		logger.Info("  GET /catastrophes - List catastrophe events")
+		logger.Info("  POST /catastrophes - Declare catastrophe event (tags matching claims)")
+		logger.Info("  GET /catastrophes/{id} - Get catastrophe event by ID")
+		logger.Info("  GET /catastrophes/{id}/exposure - Aggregate exposure for a catastrophe event")
+		logger.Info("  GET /ws/adjusters - Live adjuster dashboard updates (WebSocket)")
+		logger.Info("    Query params: queues, token")
+		logger.Info("  GET /claims/{id}/reinsurance - Ceded and retained amounts of an approved claim (admin/adjuster JWT)")
+		logger.Info("  GET /claims/{id}/acord - Claim as an ACORD XML or EDI notification (admin/adjuster JWT)")
+		logger.Info("    Query params: format")
+		logger.Info("  GET /reports/claims-aging - Open claims by days in current status (admin/adjuster JWT)")
+		logger.Info("  GET /reports/reinsurance - Ceded and retained amounts of approved claims by policy type (admin/adjuster JWT)")
+		logger.Info("    Query params: policyType, approvedFrom, approvedTo")
+		logger.Info("  GET /exports/acord - Claims as one validated ACORD XML or EDI document (admin/adjuster JWT)")
+		logger.Info("    Query params: GET /claims filters, format, skipInvalid")
+		logger.Info("  GET /admin/consistency-report - Cross-service reference check (admin/adjuster JWT)")
+		logger.Info("  GET /admin/flags/impressions/summary - Feature flag exposure by variant (admin/adjuster JWT)")
+		logger.Info("    Query params: flag")
+		logger.Info("  POST /admin/claims/held/recheck - Release or reject claims held pending payment (admin/adjuster JWT)")
+		logger.Info("  POST /admin/claims/archive - Archive decided claims past retention (admin/adjuster JWT)")
+		logger.Info("  GET /admin/dlq - Webhook deliveries that exhausted their retries (admin/adjuster JWT)")
+		logger.Info("    Query params: eventType")
+		logger.Info("  POST /admin/dlq/{id}/replay - Retry a dead-lettered webhook delivery (admin/adjuster JWT)")
+		logger.Info("  GET /admin/claims/drafts - Claim emails waiting for confirmation (admin/adjuster JWT)")
+		logger.Info("    Query params: status")
+		logger.Info("  GET /admin/claims/drafts/{id} - Get claim draft by ID (admin/adjuster JWT)")
+		logger.Info("  GET /admin/claims/drafts/{id}/attachments/{attachmentId} - Download a draft attachment (admin/adjuster JWT)")
+		logger.Info("  POST /admin/claims/drafts/{id}/confirm - File a draft as a claim, with corrections (admin/adjuster JWT)")
+		logger.Info("  POST /admin/claims/drafts/{id}/discard - Drop a draft without filing it (admin/adjuster JWT)")
+		logger.Info("  POST /admin/notifications/templates/{eventType}/preview - Render a notification template against a sample (admin/adjuster JWT)")
+		logger.Info("    Query params: locale")
+		logger.Info("  GET  /admin/maintenance - Maintenance mode (admin JWT)")
+		logger.Info("  PUT  /admin/maintenance - Turn maintenance mode on or off (admin JWT)")
+		logger.Info("  GET  /admin/faults - Fault injection rules, when enabled (admin JWT)")
+		logger.Info("  PUT  /admin/faults - Replace the fault injection rules (admin JWT)")
+		logger.Info("  GET  /admin/rules - Business rules and their evaluation counts (admin JWT)")
+		logger.Info("  PUT  /admin/rules - Replace the business rules (admin JWT)")
+		logger.Info("  POST /admin/rules/test - Evaluate the claim rules against a claim (admin/adjuster JWT)")
+		logger.Info("  GET  /admin/auth/login-attempts - Failed sign-ins and lockouts (admin JWT)")
+		logger.Info("    Query params: username")
+		if emailIntakeToken != "" {
+			logger.Info("  POST /intake/email - Inbound claim email webhook (X-Intake-Token)")
+		}
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Fatal("Server failed to start")
@@ -130,14 +573,16 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	open := conns.Connections()
+	logger.WithFields(logrus.Fields{
+		"active":   open.Active,
+		"idle":     open.Idle,
+		"hijacked": open.Hijacked,
+	}).Info("Shutting down server...")
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
+	if err := shutdown.Shutdown(context.Background()).Err(); err != nil {
+		logger.WithError(err).Error("Shutdown incomplete")
+		return
 	}
 
 	logger.Info("Server stopped gracefully")
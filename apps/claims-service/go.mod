@@ -3,14 +3,60 @@ module github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service
 go 1.21
 
 require (
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/faults v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/health v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/logging v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/persist v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/retention v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/rules v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/signing v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry v0.0.0
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/gorilla/mux v1.8.1
+	github.com/gorilla/websocket v1.5.3
+	github.com/redis/go-redis/v9 v9.7.3
 	github.com/rs/cors v1.10.1
 	github.com/sirupsen/logrus v1.9.3
 	golang.org/x/crypto v0.17.0
 )
 
 require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/expr-lang/expr v1.16.9 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
 )
+
+replace (
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting => ../../pkg/alerting
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore => ../../pkg/blobstore
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts => ../../pkg/contracts
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/faults => ../../pkg/faults
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/health => ../../pkg/health
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks => ../../pkg/hooks
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n => ../../pkg/i18n
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle => ../../pkg/lifecycle
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/logging => ../../pkg/logging
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance => ../../pkg/maintenance
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate => ../../pkg/migrate
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/persist => ../../pkg/persist
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/retention => ../../pkg/retention
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/rules => ../../pkg/rules
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets => ../../pkg/secrets
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping => ../../pkg/shaping
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/signing => ../../pkg/signing
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting => ../../pkg/targeting
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry => ../../pkg/telemetry
+)
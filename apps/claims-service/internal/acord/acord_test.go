@@ -0,0 +1,197 @@
+package acord
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+var exportedAt = time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC)
+
+func sampleClaim() *models.Claim {
+	incident := time.Date(2026, 9, 12, 18, 0, 0, 0, time.UTC)
+	return &models.Claim{
+		ID:            "claim-001",
+		ClaimNumber:   "CLM-2026-00001",
+		PolicyID:      "pol-001",
+		CustomerID:    "cust-001",
+		Type:          "damage",
+		SubType:       "windshield",
+		Status:        "approved",
+		Amount:        850,
+		Description:   "Cracked windshield; stone from a truck",
+		IncidentDate:  &incident,
+		LossLocation:  &models.LossLocation{City: "Portland", State: "OR", Country: "us"},
+		CatastropheID: "cat-2026-001",
+		Offers:        []models.SettlementOffer{{ID: "offer-001", Amount: 800, Status: models.OfferAccepted}},
+		SubmittedDate: time.Date(2026, 9, 13, 8, 0, 0, 0, time.UTC),
+		Version:       3,
+	}
+}
+
+func TestLoadSeedMapping(t *testing.T) {
+	m, err := Load(filepath.Join("..", "..", "..", "..", "data", "seed", "acord.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		claimType, subType, want string
+	}{
+		{"damage", "windshield", "glass"},
+		{"damage", "storm", "windstorm"},
+		{"damage", "", "comprehensive"},
+		{"accident", "parking", "collision"},
+		{"terminal_illness", "", "illness"},
+		{"marine", "", ""},
+	}
+	for _, tt := range tests {
+		if got := m.LossCause(tt.claimType, tt.subType); got != tt.want {
+			t.Errorf("LossCause(%q, %q) = %q, want %q", tt.claimType, tt.subType, got, tt.want)
+		}
+	}
+	if m.LOB("auto") != "AUTOP" || m.Status("pending_payment") != "open" {
+		t.Errorf("Unexpected seed codes: %+v", m)
+	}
+}
+
+func TestLoadRejectsInvalidMappings(t *testing.T) {
+	valid := `"sender": "MT", "currency": "USD", "lineOfBusiness": {"auto": "AUTOP"}, "lossCauses": {"theft": "theft"}, "statuses": {"approved": "closed"}`
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no sender", `{"currency": "USD"}`, "no sender"},
+		{"bad currency", `{"sender": "MT", "currency": "usd"}`, `currency "usd" is not an ISO 4217 code`},
+		{"no statuses", `{"sender": "MT", "currency": "USD", "lineOfBusiness": {"auto": "AUTOP"}, "lossCauses": {"theft": "theft"}}`, "no statuses"},
+		{"unknown code", `{"sender": "MT", "currency": "USD", "lineOfBusiness": {"auto": "CAR"}}`, `lineOfBusiness auto: "CAR" is not an ACORD code`},
+		{"no delimiters", `{` + valid + `}`, "edi needs an element separator and a segment terminator"},
+		{"same delimiters", `{` + valid + `, "edi": {"elementSeparator": "|", "segmentTerminator": "|"}}`, "must differ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "acord.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write mapping: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNotificationMapsTheClaim(t *testing.T) {
+	m := Default()
+	m.LossCauses["damage/windshield"] = "glass"
+	n := NewNotification(m, sampleClaim(), "AUTO-2026-000123", "auto", exportedAt)
+
+	if problems := Validate(n); len(problems) > 0 {
+		t.Fatalf("Expected a valid notification, got %v", problems)
+	}
+	o := n.Occurrence
+	if n.Policy != (Policy{PolicyNumber: "AUTO-2026-000123", LOBCd: "AUTOP"}) || n.CurCd != "USD" {
+		t.Errorf("Unexpected policy %+v in %s", n.Policy, n.CurCd)
+	}
+	if o.ItemIdInfo.InsurerId != "CLM-2026-00001" || o.LossCauseCd != "glass" || o.ClaimStatusCd != "closed" {
+		t.Errorf("Unexpected occurrence %+v", o)
+	}
+	if o.LossDt != "2026-09-12" || o.ReportedDt != "2026-09-13" || o.Addr.CountryCd != "US" {
+		t.Errorf("Unexpected dates or address %+v", o)
+	}
+	if o.TotalClaimAmt.Amt != "850.00" || o.SettlementAmt == nil || o.SettlementAmt.Amt != "800.00" {
+		t.Errorf("Unexpected amounts %+v, %+v", o.TotalClaimAmt, o.SettlementAmt)
+	}
+
+	// The same claim version is always sent under the same RqUID
+	again := NewNotification(m, sampleClaim(), "AUTO-2026-000123", "auto", exportedAt.Add(time.Hour))
+	changed := sampleClaim()
+	changed.Version++
+	if again.RqUID != n.RqUID || NewNotification(m, changed, "AUTO-2026-000123", "auto", exportedAt).RqUID == n.RqUID {
+		t.Errorf("RqUID should follow the claim version: %s, %s", n.RqUID, again.RqUID)
+	}
+}
+
+func TestValidateReportsSchemaProblems(t *testing.T) {
+	claim := sampleClaim()
+	claim.Type, claim.SubType = "marine", ""
+	claim.Status = "reopened_by_hand"
+	claim.Amount = -5
+	claim.Description = strings.Repeat("x", MaxDescriptionLength+1)
+	claim.LossLocation = &models.LossLocation{Country: "United States"}
+	late := claim.SubmittedDate.Add(48 * time.Hour)
+	claim.IncidentDate = &late
+
+	n := NewNotification(Default(), claim, "", "boat", exportedAt)
+	n.Parties = nil
+	want := []string{
+		"Policy/PolicyNumber is required",
+		"Policy/LOBCd is required",
+		"ClaimsOccurrence/LossDt 2026-09-15 is after ReportedDt 2026-09-13",
+		"ClaimsOccurrence/ClaimStatusCd is required",
+		"ClaimsOccurrence/LossCauseCd is required",
+		"ClaimsOccurrence/IncidentDesc is 1001 characters, more than 1000",
+		"ClaimsOccurrence/Addr/City is required",
+		`ClaimsOccurrence/Addr/CountryCd "UNITED STATES" is not an ISO 3166 code`,
+		`ClaimsOccurrence/TotalClaimAmt/Amt "-5.00" is not a positive amount`,
+		"ClaimsParty with ClaimsPartyRoleCd Insured is required",
+	}
+	if got := Validate(n); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Validate:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	n = NewNotification(Default(), sampleClaim(), "AUTO-1", "auto", exportedAt)
+	n.Policy.LOBCd = "CAR"
+	n.RqUID = "claim-001"
+	if got := strings.Join(Validate(n), "; "); got != `RqUID "claim-001" is not a UUID; Policy/LOBCd "CAR" is not an ACORD code` {
+		t.Errorf("Validate: got %s", got)
+	}
+}
+
+func TestDocumentEncodings(t *testing.T) {
+	m := Default()
+	m.EDI = EDIFormat{ElementSeparator: "|", SegmentTerminator: "'"}
+	claim := sampleClaim()
+	claim.Description = "Chip | then crack's spread"
+	doc := NewDocument(m, []Notification{NewNotification(m, claim, "AUTO-2026-000123", "auto", exportedAt)}, exportedAt)
+
+	body, err := doc.XML()
+	if err != nil {
+		t.Fatalf("XML failed: %v", err)
+	}
+	var decoded Document
+	if err := xml.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("The XML does not read back: %v", err)
+	}
+	if !strings.HasPrefix(string(body), xml.Header) || decoded.Xmlns != Namespace ||
+		len(decoded.Service.Notifications) != 1 || decoded.Service.Notifications[0].Occurrence.ItemIdInfo.SystemId != "claim-001" {
+		t.Errorf("Unexpected XML document:\n%s", body)
+	}
+
+	rqUID := doc.Service.Notifications[0].RqUID
+	want := "HDR|MTINSURANCE|default|2026-10-01T09:30:00Z|" + doc.Service.RqUID + "|1'" +
+		"CLM|CLM-2026-00001|claim-001|" + rqUID + "|closed|other'" +
+		"POL|AUTO-2026-000123|AUTOP'" +
+		"DTM|RPT|2026-09-13'" +
+		"DTM|LOS|2026-09-12'" +
+		"AMT|TOT|850.00|USD'" +
+		"AMT|STL|800.00|USD'" +
+		"CAT|cat-2026-001'" +
+		"LOC||Portland|OR||US'" +
+		"DSC|Chip   then crack s spread'" +
+		"PTY|Insured|cust-001'" +
+		"TRL|1|12'"
+	if got := string(doc.EDI()); got != want {
+		t.Errorf("EDI:\n%s\nwant:\n%s", got, want)
+	}
+
+	empty := NewDocument(Default(), nil, exportedAt)
+	if got := string(empty.EDI()); !strings.HasSuffix(got, "TRL*0*2~\n") {
+		t.Errorf("Unexpected empty EDI document %q", got)
+	}
+}
@@ -0,0 +1,299 @@
+package acord
+
+import (
+	"crypto/sha1"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// Namespace is the ACORD P&C XML namespace of the documents
+const Namespace = "http://www.ACORD.org/standards/PC_Surety/ACORD1/xml/"
+
+// MaxDescriptionLength is the longest IncidentDesc the schema accepts
+const MaxDescriptionLength = 1000
+
+// Date formats of the schema
+const (
+	dateFormat     = "2006-01-02"
+	dateTimeFormat = time.RFC3339
+)
+
+var (
+	rqUID       = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+	amount      = regexp.MustCompile(`^\d+\.\d{2}$`)
+	countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// Document is an ACORD document carrying claims notifications
+type Document struct {
+	XMLName xml.Name `xml:"ACORD"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Signon  Signon   `xml:"SignonRq"`
+	Service Service  `xml:"ClaimsSvcRq"`
+
+	format EDIFormat // the mapping's, for EDI
+}
+
+// Signon identifies the sender of a document
+type Signon struct {
+	ClientDt     string    `xml:"ClientDt"`
+	CustLangPref string    `xml:"CustLangPref"`
+	ClientApp    ClientApp `xml:"ClientApp"`
+}
+
+// ClientApp is the application that produced a document
+type ClientApp struct {
+	Org     string `xml:"Org"`
+	Name    string `xml:"Name"`
+	Version string `xml:"Version"` // of the mapping
+}
+
+// Service holds the notifications of a document
+type Service struct {
+	RqUID         string         `xml:"RqUID"`
+	Notifications []Notification `xml:"ClaimsNotificationAddRq"`
+}
+
+// Notification is one claim, with its policy and insured
+type Notification struct {
+	RqUID                string     `xml:"RqUID"`
+	TransactionRequestDt string     `xml:"TransactionRequestDt"`
+	CurCd                string     `xml:"CurCd"`
+	Policy               Policy     `xml:"Policy"`
+	Occurrence           Occurrence `xml:"ClaimsOccurrence"`
+	Parties              []Party    `xml:"ClaimsParty"`
+}
+
+// Policy is the policy a claim was filed on
+type Policy struct {
+	PolicyNumber string `xml:"PolicyNumber"`
+	LOBCd        string `xml:"LOBCd"`
+}
+
+// Occurrence is the loss a claim is for
+type Occurrence struct {
+	ItemIdInfo    ItemIdInfo `xml:"ItemIdInfo"` // InsurerId is the claim number, SystemId the claim ID
+	LossDt        string     `xml:"LossDt,omitempty"`
+	ReportedDt    string     `xml:"ReportedDt"`
+	ClaimStatusCd string     `xml:"ClaimStatusCd"`
+	LossCauseCd   string     `xml:"LossCauseCd"`
+	CatastropheCd string     `xml:"CatastropheCd,omitempty"`
+	IncidentDesc  string     `xml:"IncidentDesc,omitempty"`
+	Addr          *Addr      `xml:"Addr,omitempty"`
+	TotalClaimAmt Amount     `xml:"TotalClaimAmt"`
+	SettlementAmt *Amount    `xml:"SettlementAmt,omitempty"` // the settlement offer the customer accepted
+}
+
+// ItemIdInfo identifies a claim or party
+type ItemIdInfo struct {
+	InsurerId string `xml:"InsurerId"`
+	SystemId  string `xml:"SystemId,omitempty"`
+}
+
+// Addr is where a loss occurred
+type Addr struct {
+	Addr1       string `xml:"Addr1,omitempty"`
+	City        string `xml:"City"`
+	StateProvCd string `xml:"StateProvCd,omitempty"`
+	PostalCode  string `xml:"PostalCode,omitempty"`
+	CountryCd   string `xml:"CountryCd"`
+}
+
+// Amount is an amount in the notification's currency
+type Amount struct {
+	Amt string `xml:"Amt"`
+}
+
+// Party is a party to a claim
+type Party struct {
+	ItemIdInfo ItemIdInfo `xml:"ItemIdInfo"` // InsurerId is the customer ID
+	PartyInfo  PartyInfo  `xml:"ClaimsPartyInfo"`
+}
+
+// PartyInfo is a party's role in a claim
+type PartyInfo struct {
+	ClaimsPartyRoleCd string `xml:"ClaimsPartyRoleCd"`
+}
+
+// RoleInsured is the ClaimsPartyRoleCd of the policyholder
+const RoleInsured = "Insured"
+
+// NewNotification maps a claim filed on the policy numbered policyNumber,
+// of policyType. Codes the mapping has none for are left empty, for
+// Validate to report. The notification's RqUID is derived from the claim
+// and its version, so an unchanged claim is always sent under the same one.
+func NewNotification(m *Mapping, claim *models.Claim, policyNumber, policyType string, now time.Time) Notification {
+	n := Notification{
+		RqUID:                uuidFrom(fmt.Sprintf("%s/%s/%d", m.Sender, claim.ID, claim.Version)),
+		TransactionRequestDt: now.UTC().Format(dateTimeFormat),
+		CurCd:                m.Currency,
+		Policy: Policy{
+			PolicyNumber: policyNumber,
+			LOBCd:        m.LOB(policyType),
+		},
+		Occurrence: Occurrence{
+			ItemIdInfo:    ItemIdInfo{InsurerId: claim.ClaimNumber, SystemId: claim.ID},
+			ReportedDt:    claim.SubmittedDate.UTC().Format(dateFormat),
+			ClaimStatusCd: m.Status(claim.Status),
+			LossCauseCd:   m.LossCause(claim.Type, claim.SubType),
+			CatastropheCd: claim.CatastropheID,
+			IncidentDesc:  claim.Description,
+			TotalClaimAmt: newAmount(claim.Amount),
+		},
+		Parties: []Party{{
+			ItemIdInfo: ItemIdInfo{InsurerId: claim.CustomerID},
+			PartyInfo:  PartyInfo{ClaimsPartyRoleCd: RoleInsured},
+		}},
+	}
+	if claim.IncidentDate != nil {
+		n.Occurrence.LossDt = claim.IncidentDate.UTC().Format(dateFormat)
+	}
+	if loc := claim.LossLocation; loc != nil {
+		n.Occurrence.Addr = &Addr{
+			Addr1:       loc.Street,
+			City:        loc.City,
+			StateProvCd: loc.State,
+			PostalCode:  loc.PostalCode,
+			CountryCd:   strings.ToUpper(loc.Country),
+		}
+	}
+	if offer := claim.AcceptedOffer(); offer != nil {
+		settled := newAmount(offer.Amount)
+		n.Occurrence.SettlementAmt = &settled
+	}
+	return n
+}
+
+// NewDocument wraps notifications in a document from the mapping's sender
+func NewDocument(m *Mapping, notifications []Notification, now time.Time) *Document {
+	ids := make([]string, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.RqUID
+	}
+	if notifications == nil {
+		notifications = []Notification{}
+	}
+	return &Document{
+		Xmlns:  Namespace,
+		format: m.EDI,
+		Signon: Signon{
+			ClientDt:     now.UTC().Format(dateTimeFormat),
+			CustLangPref: "en-US",
+			ClientApp:    ClientApp{Org: m.Sender, Name: "claims-service", Version: m.Version},
+		},
+		Service: Service{
+			RqUID:         uuidFrom(now.UTC().Format(time.RFC3339Nano) + "/" + strings.Join(ids, ",")),
+			Notifications: notifications,
+		},
+	}
+}
+
+// Validate checks a notification against the schema and returns its
+// problems, each naming the element at fault. A notification without
+// problems is valid.
+func Validate(n Notification) []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	required := func(path, value string) bool {
+		if strings.TrimSpace(value) == "" {
+			add("%s is required", path)
+			return false
+		}
+		return true
+	}
+	code := func(path, value string, codes []string) {
+		if required(path, value) && !contains(codes, value) {
+			add("%s %q is not an ACORD code", path, value)
+		}
+	}
+
+	if !rqUID.MatchString(n.RqUID) {
+		add("RqUID %q is not a UUID", n.RqUID)
+	}
+	if _, err := time.Parse(dateTimeFormat, n.TransactionRequestDt); err != nil {
+		add("TransactionRequestDt %q is not a date-time", n.TransactionRequestDt)
+	}
+	if !currencyCode.MatchString(n.CurCd) {
+		add("CurCd %q is not an ISO 4217 code", n.CurCd)
+	}
+
+	required("Policy/PolicyNumber", n.Policy.PolicyNumber)
+	code("Policy/LOBCd", n.Policy.LOBCd, LOBCodes)
+
+	o := n.Occurrence
+	required("ClaimsOccurrence/ItemIdInfo/InsurerId", o.ItemIdInfo.InsurerId)
+	reported, err := time.Parse(dateFormat, o.ReportedDt)
+	if err != nil {
+		add("ClaimsOccurrence/ReportedDt %q is not a date", o.ReportedDt)
+	}
+	if o.LossDt != "" {
+		loss, lossErr := time.Parse(dateFormat, o.LossDt)
+		switch {
+		case lossErr != nil:
+			add("ClaimsOccurrence/LossDt %q is not a date", o.LossDt)
+		case err == nil && loss.After(reported):
+			add("ClaimsOccurrence/LossDt %s is after ReportedDt %s", o.LossDt, o.ReportedDt)
+		}
+	}
+	code("ClaimsOccurrence/ClaimStatusCd", o.ClaimStatusCd, ClaimStatusCodes)
+	code("ClaimsOccurrence/LossCauseCd", o.LossCauseCd, LossCauseCodes)
+	if length := utf8.RuneCountInString(o.IncidentDesc); length > MaxDescriptionLength {
+		add("ClaimsOccurrence/IncidentDesc is %d characters, more than %d", length, MaxDescriptionLength)
+	}
+	if a := o.Addr; a != nil {
+		required("ClaimsOccurrence/Addr/City", a.City)
+		if !countryCode.MatchString(a.CountryCd) {
+			add("ClaimsOccurrence/Addr/CountryCd %q is not an ISO 3166 code", a.CountryCd)
+		}
+	}
+	if !amount.MatchString(o.TotalClaimAmt.Amt) {
+		add("ClaimsOccurrence/TotalClaimAmt/Amt %q is not a positive amount", o.TotalClaimAmt.Amt)
+	}
+	if o.SettlementAmt != nil && !amount.MatchString(o.SettlementAmt.Amt) {
+		add("ClaimsOccurrence/SettlementAmt/Amt %q is not a positive amount", o.SettlementAmt.Amt)
+	}
+
+	insured := false
+	for i, p := range n.Parties {
+		required(fmt.Sprintf("ClaimsParty[%d]/ItemIdInfo/InsurerId", i), p.ItemIdInfo.InsurerId)
+		if p.PartyInfo.ClaimsPartyRoleCd == RoleInsured {
+			insured = true
+		}
+	}
+	if !insured {
+		add("ClaimsParty with ClaimsPartyRoleCd %s is required", RoleInsured)
+	}
+	return problems
+}
+
+// XML encodes the document, with the XML declaration
+func (d *Document) XML() ([]byte, error) {
+	body, err := xml.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+// newAmount formats an amount to the cent. Negative amounts keep their
+// sign, so Validate refuses them.
+func newAmount(value float64) Amount {
+	return Amount{Amt: strconv.FormatFloat(value, 'f', 2, 64)}
+}
+
+// uuidFrom returns the name-based (version 5 style) UUID of name
+func uuidFrom(name string) string {
+	sum := sha1.Sum([]byte(name))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
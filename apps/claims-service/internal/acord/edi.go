@@ -0,0 +1,73 @@
+package acord
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EDI encodes the document as segments delimited as the mapping's EDI
+// format says, for partners that do not read XML. A document is a header,
+// each notification's segments and a trailer counting the notifications
+// and every segment:
+//
+//	HDR sender, mapping version, ClientDt, RqUID, notifications
+//	CLM claim number, claim ID, RqUID, ClaimStatusCd, LossCauseCd
+//	POL PolicyNumber, LOBCd
+//	DTM RPT ReportedDt, and DTM LOS LossDt when known
+//	AMT TOT TotalClaimAmt and CurCd, and AMT STL SettlementAmt when settled
+//	CAT CatastropheCd, LOC the loss address and DSC IncidentDesc, when known
+//	PTY ClaimsPartyRoleCd and party ID, per party
+//	TRL notifications, segments including the header and trailer
+func (d *Document) EDI() []byte {
+	f := d.format
+	var b strings.Builder
+	segments := 0
+	segment := func(elements ...string) {
+		for i, e := range elements {
+			if i > 0 {
+				b.WriteString(f.ElementSeparator)
+			}
+			b.WriteString(f.clean(e))
+		}
+		b.WriteString(f.SegmentTerminator)
+		if f.Newlines {
+			b.WriteByte('\n')
+		}
+		segments++
+	}
+
+	notifications := d.Service.Notifications
+	segment("HDR", d.Signon.ClientApp.Org, d.Signon.ClientApp.Version, d.Signon.ClientDt, d.Service.RqUID, strconv.Itoa(len(notifications)))
+	for _, n := range notifications {
+		o := n.Occurrence
+		segment("CLM", o.ItemIdInfo.InsurerId, o.ItemIdInfo.SystemId, n.RqUID, o.ClaimStatusCd, o.LossCauseCd)
+		segment("POL", n.Policy.PolicyNumber, n.Policy.LOBCd)
+		segment("DTM", "RPT", o.ReportedDt)
+		if o.LossDt != "" {
+			segment("DTM", "LOS", o.LossDt)
+		}
+		segment("AMT", "TOT", o.TotalClaimAmt.Amt, n.CurCd)
+		if o.SettlementAmt != nil {
+			segment("AMT", "STL", o.SettlementAmt.Amt, n.CurCd)
+		}
+		if o.CatastropheCd != "" {
+			segment("CAT", o.CatastropheCd)
+		}
+		if a := o.Addr; a != nil {
+			segment("LOC", a.Addr1, a.City, a.StateProvCd, a.PostalCode, a.CountryCd)
+		}
+		if o.IncidentDesc != "" {
+			segment("DSC", o.IncidentDesc)
+		}
+		for _, p := range n.Parties {
+			segment("PTY", p.PartyInfo.ClaimsPartyRoleCd, p.ItemIdInfo.InsurerId)
+		}
+	}
+	segment("TRL", strconv.Itoa(len(notifications)), strconv.Itoa(segments+1))
+	return []byte(b.String())
+}
+
+// clean replaces the delimiters and line breaks in a value with spaces
+func (f EDIFormat) clean(value string) string {
+	return strings.NewReplacer(f.ElementSeparator, " ", f.SegmentTerminator, " ", "\r", " ", "\n", " ").Replace(value)
+}
@@ -0,0 +1,176 @@
+// Package acord maps claims to the ACORD P&C claims notification reinsurers
+// and regulators exchange, as XML or as a flat EDI document. How policy
+// types, claim types and statuses translate to ACORD codes is
+// configuration, loaded from acord.json, so partners can be onboarded
+// without a release. Documents are checked against the schema rules of the
+// notification before they are handed out.
+package acord
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ACORD code lists the mapping may translate to
+var (
+	LOBCodes         = []string{"AUTOP", "AUTOB", "HOME", "DFIRE", "INMRP", "UMBRP", "LIFE"}
+	ClaimStatusCodes = []string{"open", "closed", "reopened"}
+	LossCauseCodes   = []string{
+		"collision", "comprehensive", "glass", "theft", "vandalism", "fire", "water",
+		"flood", "hail", "windstorm", "earthquake", "subsidence", "liability",
+		"bodilyinjury", "death", "illness", "other",
+	}
+)
+
+// currencyCode is an ISO 4217 currency code
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Mapping is how claims translate to ACORD codes
+type Mapping struct {
+	Version  string `json:"version"`
+	Sender   string `json:"sender"`   // the carrier's code, as partners know it
+	Currency string `json:"currency"` // ISO 4217, of every amount
+
+	// LineOfBusiness is the LOBCd of each policy type
+	LineOfBusiness map[string]string `json:"lineOfBusiness"`
+
+	// LossCauses is the LossCauseCd of each claim type, keyed "type" or,
+	// for a sub-type, "type/subType"; the sub-type key wins
+	LossCauses map[string]string `json:"lossCauses"`
+
+	// Statuses is the ClaimStatusCd of each claim status
+	Statuses map[string]string `json:"statuses"`
+
+	EDI EDIFormat `json:"edi"`
+}
+
+// EDIFormat is the delimiters of the EDI document. Values holding a
+// delimiter have it replaced by a space.
+type EDIFormat struct {
+	ElementSeparator  string `json:"elementSeparator"`
+	SegmentTerminator string `json:"segmentTerminator"`
+	Newlines          bool   `json:"newlines"` // end each segment with a line break too
+}
+
+// Default returns the mapping used when none is configured
+func Default() *Mapping {
+	return &Mapping{
+		Version:        "default",
+		Sender:         "MTINSURANCE",
+		Currency:       "USD",
+		LineOfBusiness: map[string]string{"auto": "AUTOP", "home": "HOME", "life": "LIFE"},
+		LossCauses: map[string]string{
+			"accident":         "collision",
+			"theft":            "theft",
+			"damage":           "other",
+			"liability":        "liability",
+			"death":            "death",
+			"terminal_illness": "illness",
+		},
+		Statuses: map[string]string{
+			"submitted":       "open",
+			"under_review":    "open",
+			"pending_payment": "open",
+			"approved":        "closed",
+			"rejected":        "closed",
+		},
+		EDI: EDIFormat{ElementSeparator: "*", SegmentTerminator: "~", Newlines: true},
+	}
+}
+
+// Load reads and validates a mapping file
+func Load(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Mapping
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid ACORD mapping in %s: %w", path, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid ACORD mapping in %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// validate checks the mapping only translates to ACORD codes and that the
+// EDI delimiters can be told apart
+func (m *Mapping) validate() error {
+	if m.Sender == "" {
+		return fmt.Errorf("no sender")
+	}
+	if !currencyCode.MatchString(m.Currency) {
+		return fmt.Errorf("currency %q is not an ISO 4217 code", m.Currency)
+	}
+	codeLists := []struct {
+		name  string
+		codes map[string]string
+		valid []string
+	}{
+		{"lineOfBusiness", m.LineOfBusiness, LOBCodes},
+		{"lossCauses", m.LossCauses, LossCauseCodes},
+		{"statuses", m.Statuses, ClaimStatusCodes},
+	}
+	for _, list := range codeLists {
+		if len(list.codes) == 0 {
+			return fmt.Errorf("no %s", list.name)
+		}
+		for _, key := range sortedKeys(list.codes) {
+			if !contains(list.valid, list.codes[key]) {
+				return fmt.Errorf("%s %s: %q is not an ACORD code (one of %s)", list.name, key, list.codes[key], strings.Join(list.valid, ", "))
+			}
+		}
+	}
+	if m.EDI.ElementSeparator == "" || m.EDI.SegmentTerminator == "" {
+		return fmt.Errorf("edi needs an element separator and a segment terminator")
+	}
+	if m.EDI.ElementSeparator == m.EDI.SegmentTerminator {
+		return fmt.Errorf("edi element separator and segment terminator must differ")
+	}
+	return nil
+}
+
+// LOB returns the LOBCd of a policy type, "" when it has none
+func (m *Mapping) LOB(policyType string) string {
+	return m.LineOfBusiness[policyType]
+}
+
+// LossCause returns the LossCauseCd of a claim type and sub-type, ""
+// when neither has one
+func (m *Mapping) LossCause(claimType, subType string) string {
+	if subType != "" {
+		if code, ok := m.LossCauses[claimType+"/"+subType]; ok {
+			return code
+		}
+	}
+	return m.LossCauses[claimType]
+}
+
+// Status returns the ClaimStatusCd of a claim status, "" when it has none
+func (m *Mapping) Status(status string) string {
+	return m.Statuses[status]
+}
+
+func sortedKeys(codes map[string]string) []string {
+	keys := make([]string, 0, len(codes))
+	for key := range codes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
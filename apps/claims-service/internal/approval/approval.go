@@ -0,0 +1,99 @@
+// Package approval decides what happens to a new claim as it is filed:
+// approved straight away, sent to an adjuster for review, or rejected.
+// Carriers differ in how much they approve without an adjuster, so the
+// decision is made by a policy chosen in configuration: Threshold, the
+// claims.autoApproval flags, Rules, loaded from approval-rules.json, or
+// Engine, the claim rules of the business rules engine.
+package approval
+
+import (
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+)
+
+// Decision is where a new claim goes
+type Decision string
+
+// Decisions
+const (
+	Approve Decision = "approve"
+	Review  Decision = "review"
+	Reject  Decision = "reject"
+)
+
+// Valid reports whether d is one of the decisions
+func (d Decision) Valid() bool {
+	return d == Approve || d == Review || d == Reject
+}
+
+// Verdict is a decision and why it was made
+type Verdict struct {
+	Decision      Decision
+	Reason        string
+	RejectionCode string // one of models.RejectionCodes; set when Decision is Reject
+}
+
+// Policy is what is known of the insurance policy a claim is filed on
+type Policy struct {
+	ID     string
+	Type   string // policy line, such as auto; empty when the policy is unknown
+	Status string // from policy-service; empty when it was not asked
+}
+
+// Customer is what is known of the customer filing a claim
+type Customer struct {
+	ID           string
+	RecentClaims int // claims filed in the year before this one; see HistoryReader
+}
+
+// HistoryReader is implemented by policies that read Customer.RecentClaims.
+// Counting a customer's claims scans the store, so it is skipped for
+// policies that do not implement it or report false.
+type HistoryReader interface {
+	ReadsClaimHistory() bool
+}
+
+// Names of the policies that can be selected in configuration
+const (
+	NameThreshold = "threshold"
+	NameRules     = "rules"
+	NameEngine    = "engine"
+)
+
+// Flags is the part of the claims feature flags the threshold policy reads
+type Flags interface {
+	IsAutoApprovalEnabledFor(ctx targeting.Context) bool
+	AutoApprovalThreshold() float64
+}
+
+// Threshold approves claims below claims.autoApprovalThreshold while
+// claims.autoApproval is on for the customer and policy line, and sends
+// every other claim to review. It never rejects a claim.
+type Threshold struct {
+	flags Flags
+}
+
+// NewThreshold creates the threshold policy
+func NewThreshold(flags Flags) *Threshold {
+	return &Threshold{flags: flags}
+}
+
+// Decide implements the approval policy
+func (t *Threshold) Decide(claim *models.Claim, policy Policy, customer Customer) Verdict {
+	enabled := t.flags.IsAutoApprovalEnabledFor(targeting.Context{
+		UserID:     customer.ID,
+		Attributes: map[string]string{targeting.AttributePolicyType: policy.Type},
+	})
+	threshold := t.flags.AutoApprovalThreshold()
+
+	switch {
+	case !enabled:
+		return Verdict{Decision: Review, Reason: "auto-approval is off"}
+	case claim.Amount < threshold:
+		return Verdict{Decision: Approve, Reason: fmt.Sprintf("amount below the auto-approval threshold of %.2f", threshold)}
+	default:
+		return Verdict{Decision: Review, Reason: fmt.Sprintf("amount at or above the auto-approval threshold of %.2f", threshold)}
+	}
+}
@@ -0,0 +1,176 @@
+package approval
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/sirupsen/logrus"
+)
+
+func TestDecideSeedRules(t *testing.T) {
+	rules, err := LoadRules(filepath.Join("..", "..", "..", "..", "data", "seed", "approval-rules.json"))
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	if !rules.ReadsClaimHistory() {
+		t.Error("Expected the frequent-claimant rule to read claim history")
+	}
+
+	auto := Policy{ID: "pol-001", Type: "auto", Status: "active"}
+	customer := Customer{ID: "cust-001"}
+	tests := []struct {
+		name     string
+		claim    models.Claim
+		policy   Policy
+		customer Customer
+		want     Decision
+		code     string
+	}{
+		{"windshield", models.Claim{Type: "damage", SubType: "windshield", Amount: 1200}, auto, customer, Approve, ""},
+		{"windshield at the limit", models.Claim{Type: "damage", SubType: "windshield", Amount: 1500}, auto, customer, Review, ""},
+		{"small accident", models.Claim{Type: "accident", Amount: 499.99}, auto, customer, Approve, ""},
+		{"small theft", models.Claim{Type: "theft", Amount: 200}, auto, customer, Review, ""},
+		{"catastrophe", models.Claim{Type: "damage", Amount: 200, CatastropheID: "cat-001"}, auto, customer, Review, ""},
+		{"frequent claimant", models.Claim{Type: "damage", Amount: 200}, auto, Customer{ID: "cust-002", RecentClaims: 3}, Review, ""},
+		{"lapsed policy", models.Claim{Type: "damage", Amount: 200}, Policy{Type: "auto", Status: "lapsed"}, customer, Reject, models.RejectionPolicyLapsed},
+		{"large claim", models.Claim{Type: "damage", Amount: 25000}, auto, customer, Review, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules.Decide(&tt.claim, tt.policy, tt.customer)
+			if got.Decision != tt.want || got.RejectionCode != tt.code || got.Reason == "" {
+				t.Errorf("Decide = %+v, want %s %q with a reason", got, tt.want, tt.code)
+			}
+		})
+	}
+}
+
+func TestLoadRulesRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no rules", `{"rules": []}`, "no rules"},
+		{"no name", `{"rules": [{"decision": "approve"}]}`, "rule 1 has no name"},
+		{"duplicate", `{"rules": [{"name": "a", "decision": "approve"}, {"name": "a", "decision": "review"}]}`, "duplicate rule a"},
+		{"unknown decision", `{"rules": [{"name": "a", "decision": "escalate"}]}`, `rule a has unknown decision "escalate"`},
+		{"reject without code", `{"rules": [{"name": "a", "decision": "reject"}]}`, "rule a must reject with one of the rejection codes"},
+		{"code without reject", `{"rules": [{"name": "a", "decision": "review", "rejectionCode": "duplicate"}]}`, "rule a has a rejection code but does not reject"},
+		{"amounts reversed", `{"rules": [{"name": "a", "when": {"minAmount": 500, "maxAmount": 100}, "decision": "approve"}]}`, "rule a maxAmount must be above minAmount"},
+		{"negative bound", `{"rules": [{"name": "a", "when": {"minRecentClaims": -1}, "decision": "review"}]}`, "rule a has a negative bound"},
+		{"bad default", `{"rules": [{"name": "a", "decision": "review"}], "default": {"decision": "reject"}}`, "default must reject with one of the rejection codes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "approval-rules.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write rules: %v", err)
+			}
+			if _, err := LoadRules(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRulesDefaultToReview(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "approval-rules.json")
+	if err := os.WriteFile(path, []byte(`{"rules": [{"name": "home-only", "when": {"policyTypes": ["home"]}, "decision": "approve"}]}`), 0o644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	claim := &models.Claim{Type: "damage", Amount: 100}
+	if got := rules.Decide(claim, Policy{Type: "home"}, Customer{}); got != (Verdict{Decision: Approve, Reason: "rule home-only"}) {
+		t.Errorf("Expected the rule name as the reason, got %+v", got)
+	}
+	if got := rules.Decide(claim, Policy{Type: "auto"}, Customer{}); got != (Verdict{Decision: Review, Reason: "no rule matched"}) {
+		t.Errorf("Expected review by default, got %+v", got)
+	}
+}
+
+// fixedFlags is auto-approval set for one policy line
+type fixedFlags struct {
+	policyType string
+	threshold  float64
+}
+
+func (f fixedFlags) IsAutoApprovalEnabledFor(ctx targeting.Context) bool {
+	return ctx.Attributes[targeting.AttributePolicyType] == f.policyType
+}
+
+func (f fixedFlags) AutoApprovalThreshold() float64 {
+	return f.threshold
+}
+
+func TestThresholdFollowsTheFlags(t *testing.T) {
+	threshold := NewThreshold(fixedFlags{policyType: "auto", threshold: 1000})
+	customer := Customer{ID: "cust-001"}
+
+	tests := []struct {
+		policyType string
+		amount     float64
+		want       Decision
+	}{
+		{"auto", 999.99, Approve},
+		{"auto", 1000, Review},
+		{"home", 10, Review},
+	}
+	for _, tt := range tests {
+		got := threshold.Decide(&models.Claim{Amount: tt.amount}, Policy{Type: tt.policyType}, customer)
+		if got.Decision != tt.want || got.Reason == "" {
+			t.Errorf("Decide(%s, %.2f) = %+v, want %s", tt.policyType, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestEngineDecidesByTheClaimRules(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	engine := rules.New(rules.Config{
+		Path:     filepath.Join("..", "..", "..", "..", "data", "seed", "business-rules.json"),
+		Domains:  []string{rules.DomainClaim},
+		Validate: ValidateRule,
+	}, logger)
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	policy := NewEngine(engine)
+
+	if !policy.ReadsClaimHistory() {
+		t.Error("Expected the frequent claimant rule to read claim history")
+	}
+	auto := Policy{ID: "pol-001", Type: "auto", Status: "active"}
+	tests := []struct {
+		name     string
+		claim    models.Claim
+		policy   Policy
+		customer Customer
+		want     Verdict
+	}{
+		{"windshield", models.Claim{Type: "damage", SubType: "windshield", Amount: 1200}, auto, Customer{}, Verdict{Decision: Approve, Reason: "Windshield repair under 1500"}},
+		{"frequent claimant", models.Claim{Type: "damage", Amount: 200}, auto, Customer{RecentClaims: 4}, Verdict{Decision: Review, Reason: "Three or more claims in the past year"}},
+		{"lapsed policy", models.Claim{Type: "damage", Amount: 200}, Policy{Type: "auto", Status: "lapsed"}, Customer{}, Verdict{Decision: Reject, Reason: "The policy was not in force", RejectionCode: models.RejectionPolicyLapsed}},
+		{"large claim", models.Claim{Type: "damage", Amount: 25000}, auto, Customer{}, Verdict{Decision: Review, Reason: "no rule matched"}},
+	}
+	for _, tt := range tests {
+		if got := policy.Decide(&tt.claim, tt.policy, tt.customer); got != tt.want {
+			t.Errorf("%s: Decide = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	err := engine.Replace(rules.Set{Rules: []rules.Rule{{Name: "all", Domain: rules.DomainClaim, When: "true", Action: rules.ActionReject}}})
+	if err == nil || !strings.Contains(err.Error(), "rule all must reject with one of the rejection codes") {
+		t.Errorf("Expected a rejection without a code to be refused, got %v", err)
+	}
+}
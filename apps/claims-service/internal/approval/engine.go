@@ -0,0 +1,53 @@
+package approval
+
+import (
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+)
+
+// Engine decides claims by the claim rules of the business rules engine:
+// the first rule whose expression is true, and review when none is. The
+// rules can be replaced through the admin API and reload when their file
+// changes, so the policy follows them as they are.
+type Engine struct {
+	rules *rules.Engine
+}
+
+// NewEngine creates the policy over the claim rules of engine
+func NewEngine(engine *rules.Engine) *Engine {
+	return &Engine{rules: engine}
+}
+
+// Decide implements the approval policy
+func (e *Engine) Decide(claim *models.Claim, policy Policy, customer Customer) Verdict {
+	match, ok := e.rules.First(rules.ClaimContext{
+		Type:         claim.Type,
+		SubType:      claim.SubType,
+		Amount:       claim.Amount,
+		Catastrophe:  claim.CatastropheID != "",
+		PolicyType:   policy.Type,
+		PolicyStatus: policy.Status,
+		CustomerID:   customer.ID,
+		RecentClaims: customer.RecentClaims,
+	})
+	if !ok {
+		return Verdict{Decision: Review, Reason: "no rule matched"}
+	}
+	verdict := Verdict{Decision: Decision(match.Action), Reason: match.Reason}
+	if verdict.Decision == Reject {
+		verdict.RejectionCode = match.Code
+	}
+	return verdict
+}
+
+// ReadsClaimHistory reports whether any claim rule reads recentClaims
+func (e *Engine) ReadsClaimHistory() bool {
+	return e.rules.Reads(rules.DomainClaim, "recentClaims")
+}
+
+// ValidateRule checks a claim rule can be carried out: rejections need one
+// of the rejection codes, and only rejections take one. Give it to the
+// engine as rules.Config.Validate.
+func ValidateRule(rule rules.Rule) error {
+	return Outcome{Decision: Decision(rule.Action), RejectionCode: rule.Code}.validate()
+}
@@ -0,0 +1,174 @@
+package approval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// Rules decides claims by the first rule whose conditions they meet, and
+// by Default when none matches. Rules are configuration, loaded from
+// approval-rules.json, so a carrier can change what is approved without a
+// release. Rules do not read the claims.autoApproval flags.
+type Rules struct {
+	Version string  `json:"version"`
+	Rules   []Rule  `json:"rules"`
+	Default Outcome `json:"default"`
+}
+
+// Rule is a decision and the claims it applies to
+type Rule struct {
+	Name string    `json:"name"`
+	When Condition `json:"when"`
+	Outcome
+}
+
+// Outcome is the decision a rule makes
+type Outcome struct {
+	Decision      Decision `json:"decision"`
+	Reason        string   `json:"reason,omitempty"`
+	RejectionCode string   `json:"rejectionCode,omitempty"` // required to reject
+}
+
+// Condition is what a claim must meet for a rule to apply. Empty fields
+// match every claim; lists match a claim whose value is in them.
+type Condition struct {
+	PolicyTypes     []string `json:"policyTypes,omitempty"`
+	PolicyStatuses  []string `json:"policyStatuses,omitempty"`
+	ClaimTypes      []string `json:"claimTypes,omitempty"`
+	SubTypes        []string `json:"subTypes,omitempty"`
+	MinAmount       float64  `json:"minAmount,omitempty"` // inclusive
+	MaxAmount       float64  `json:"maxAmount,omitempty"` // exclusive
+	Catastrophe     *bool    `json:"catastrophe,omitempty"`
+	MinRecentClaims int      `json:"minRecentClaims,omitempty"`
+}
+
+// LoadRules reads and validates a rules file
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Rules
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid approval rules in %s: %w", path, err)
+	}
+	if err := r.validate(); err != nil {
+		return nil, fmt.Errorf("invalid approval rules in %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// validate checks every rule is named, has a usable condition and makes a
+// decision it can carry out. A missing default sends claims to review.
+func (r *Rules) validate() error {
+	if len(r.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	seen := make(map[string]bool, len(r.Rules))
+	for i, rule := range r.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("duplicate rule %s", rule.Name)
+		}
+		seen[rule.Name] = true
+		when := rule.When
+		if when.MinAmount < 0 || when.MaxAmount < 0 || when.MinRecentClaims < 0 {
+			return fmt.Errorf("rule %s has a negative bound", rule.Name)
+		}
+		if when.MaxAmount > 0 && when.MaxAmount <= when.MinAmount {
+			return fmt.Errorf("rule %s maxAmount must be above minAmount", rule.Name)
+		}
+		if err := rule.Outcome.validate(); err != nil {
+			return fmt.Errorf("rule %s %w", rule.Name, err)
+		}
+	}
+	if r.Default.Decision == "" {
+		r.Default.Decision = Review
+	}
+	if err := r.Default.validate(); err != nil {
+		return fmt.Errorf("default %w", err)
+	}
+	return nil
+}
+
+func (o Outcome) validate() error {
+	if !o.Decision.Valid() {
+		return fmt.Errorf("has unknown decision %q (want approve, review or reject)", o.Decision)
+	}
+	if o.Decision == Reject && !models.ValidateRejectionCode(o.RejectionCode) {
+		return fmt.Errorf("must reject with one of the rejection codes, got %q", o.RejectionCode)
+	}
+	if o.Decision != Reject && o.RejectionCode != "" {
+		return fmt.Errorf("has a rejection code but does not reject")
+	}
+	return nil
+}
+
+// Decide implements the approval policy
+func (r *Rules) Decide(claim *models.Claim, policy Policy, customer Customer) Verdict {
+	for _, rule := range r.Rules {
+		if rule.When.matches(claim, policy, customer) {
+			return rule.verdict(rule.Name)
+		}
+	}
+	return r.Default.verdict("")
+}
+
+// ReadsClaimHistory reports whether any rule counts the customer's recent
+// claims, so callers only count them when they are read
+func (r *Rules) ReadsClaimHistory() bool {
+	for _, rule := range r.Rules {
+		if rule.When.MinRecentClaims > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// verdict is the outcome as a verdict, explained by the rule when it gives
+// no reason of its own
+func (o Outcome) verdict(rule string) Verdict {
+	reason := o.Reason
+	switch {
+	case reason != "":
+	case rule != "":
+		reason = "rule " + rule
+	default:
+		reason = "no rule matched"
+	}
+	return Verdict{Decision: o.Decision, Reason: reason, RejectionCode: o.RejectionCode}
+}
+
+func (c Condition) matches(claim *models.Claim, policy Policy, customer Customer) bool {
+	switch {
+	case !oneOf(c.PolicyTypes, policy.Type),
+		!oneOf(c.PolicyStatuses, policy.Status),
+		!oneOf(c.ClaimTypes, claim.Type),
+		!oneOf(c.SubTypes, claim.SubType),
+		claim.Amount < c.MinAmount,
+		c.MaxAmount > 0 && claim.Amount >= c.MaxAmount,
+		c.Catastrophe != nil && *c.Catastrophe != (claim.CatastropheID != ""),
+		customer.RecentClaims < c.MinRecentClaims:
+		return false
+	}
+	return true
+}
+
+// oneOf reports whether value is in values, or values is empty
+func oneOf(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
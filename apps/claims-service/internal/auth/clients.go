@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Client is a service registered for the client credentials grant. Its
+// secret is stored as a bcrypt hash, like passwords.
+type Client struct {
+	ID         string   `json:"clientId"`
+	SecretHash string   `json:"secretHash"`
+	Scopes     []string `json:"scopes"` // the scopes it may be granted
+}
+
+// LoadClients reads the registered clients from a JSON array of clients
+func LoadClients(path string) ([]Client, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var clients []Client
+	if err := json.Unmarshal(data, &clients); err != nil {
+		return nil, fmt.Errorf("invalid clients file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(clients))
+	for i, client := range clients {
+		switch {
+		case client.ID == "":
+			return nil, fmt.Errorf("client %d: clientId is required", i)
+		case seen[client.ID]:
+			return nil, fmt.Errorf("client %s: registered twice", client.ID)
+		case client.SecretHash == "":
+			return nil, fmt.Errorf("client %s: secretHash is required", client.ID)
+		case len(client.Scopes) == 0:
+			return nil, fmt.Errorf("client %s: at least one scope is required", client.ID)
+		}
+		seen[client.ID] = true
+	}
+	return clients, nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,17 +17,37 @@ var (
 type Claims struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
+	Role   string `json:"role,omitempty"`
+	// Scope is the space-separated scopes of a service token, such as
+	// "payments:write"; user tokens have none
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasScope reports whether the token was granted scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // JWTManager manages JWT token creation and validation
 type JWTManager struct {
-	secretKey     string
+	secretKey     func() string
 	tokenDuration time.Duration
 }
 
 // NewJWTManager creates a new JWT manager
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+	return NewRotatingJWTManager(func() string { return secretKey }, tokenDuration)
+}
+
+// NewRotatingJWTManager creates a JWT manager that looks its secret up for
+// every token, so a rotated secret is used without a restart
+func NewRotatingJWTManager(secretKey func() string, tokenDuration time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
 		tokenDuration: tokenDuration,
@@ -35,10 +56,23 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 
 // Generate creates a new JWT token for a user
 func (manager *JWTManager) Generate(userID, email string) (string, error) {
+	return manager.GenerateWithRole(userID, email, "")
+}
+
+// GenerateWithRole creates a new JWT token for a user carrying a role claim
+func (manager *JWTManager) GenerateWithRole(userID, email, role string) (string, error) {
+	return manager.GenerateForSession(userID, email, role, "")
+}
+
+// GenerateForSession creates a new JWT token for a user that names the
+// session it belongs to in its jti claim
+func (manager *JWTManager) GenerateForSession(userID, email, role, sessionID string) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        sessionID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(manager.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -46,7 +80,31 @@ func (manager *JWTManager) Generate(userID, email string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(manager.secretKey))
+	return token.SignedString([]byte(manager.secretKey()))
+}
+
+// GenerateForClient creates a service token for a client of the client
+// credentials grant, valid for ttl and carrying scopes
+func (manager *JWTManager) GenerateForClient(clientID string, scopes []string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		UserID: clientID,
+		Scope:  strings.Join(scopes, " "),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clientID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(manager.secretKey()))
+}
+
+// TokenDuration is how long the tokens it generates are valid
+func (manager *JWTManager) TokenDuration() time.Duration {
+	return manager.tokenDuration
 }
 
 // Verify validates a JWT token and returns the claims
@@ -59,7 +117,7 @@ func (manager *JWTManager) Verify(tokenString string) (*Claims, error) {
 			if !ok {
 				return nil, ErrInvalidToken
 			}
-			return []byte(manager.secretKey), nil
+			return []byte(manager.secretKey()), nil
 		},
 	)
 
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTOTPMatchesRFC6238(t *testing.T) {
+	// The SHA-1 test vectors of RFC 6238, truncated to six digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	for _, tc := range []struct {
+		at   int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	} {
+		if code, err := TOTPCode(secret, TOTPCounter(time.Unix(tc.at, 0))); err != nil || code != tc.code {
+			t.Errorf("At %d: expected %s, got %s, %v", tc.at, tc.code, code, err)
+		}
+	}
+
+	now := time.Unix(1234567890, 0)
+	if counter, ok := VerifyTOTP(secret, "005924", now.Add(TOTPPeriod)); !ok || counter != TOTPCounter(now) {
+		t.Errorf("Expected a code from the previous period to be accepted, got %d, %v", counter, ok)
+	}
+	if _, ok := VerifyTOTP(secret, "005924", now.Add(3*TOTPPeriod)); ok {
+		t.Error("Expected a stale code to be refused")
+	}
+}
+
+func TestRecoveryCodesCompareAsTyped(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil || len(codes) != 10 || len(codes[0]) != 9 || codes[0][4] != '-' {
+		t.Fatalf("Unexpected recovery codes %v, %v", codes, err)
+	}
+	typed := strings.ToLower(strings.Replace(codes[0], "-", " ", 1))
+	if HashRecoveryCode(typed) != HashRecoveryCode(codes[0]) {
+		t.Errorf("Expected %q to match %q", typed, codes[0])
+	}
+	if HashRecoveryCode(codes[1]) == HashRecoveryCode(codes[0]) {
+		t.Error("Expected different codes to hash differently")
+	}
+}
@@ -0,0 +1,424 @@
+// Package breaker trips calls to another service once they keep failing, so
+// requests that depend on a slow or failing downstream are answered at once
+// instead of each waiting out its timeout. Each downstream route has its own
+// breaker per tenant: a tenant whose calls fail does not cut off the others.
+// An open breaker lets a probe through after a cool-off, and closes again
+// once the probes succeed.
+package breaker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/sirupsen/logrus"
+)
+
+// State is whether a breaker lets calls through
+type State string
+
+// Breaker states
+const (
+	Closed   State = "closed"    // calls go through
+	Open     State = "open"      // calls are refused with ErrOpen
+	HalfOpen State = "half_open" // probes go through; the rest are refused
+)
+
+// states in the order they are reported in metrics
+var states = []State{Closed, Open, HalfOpen}
+
+// ErrOpen is returned for a call the breaker refused without sending it
+var ErrOpen = errors.New("circuit open")
+
+// Defaults for routes without settings of their own
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenTimeout      = 30 * time.Second
+	DefaultHalfOpenProbes   = 1
+)
+
+// Settings tune one breaker. A zero field falls back to the default.
+type Settings struct {
+	FailureThreshold int           // failures in a row that open the breaker
+	OpenTimeout      time.Duration // how long it stays open before a probe
+	HalfOpenProbes   int           // probes let through at once, and needed to close it
+}
+
+// Config holds the default settings and per-route overrides, keyed by
+// downstream, method and route template, such as
+// "policy-service GET /policies/{id}"
+type Config struct {
+	Default Settings
+	Routes  map[string]Settings
+}
+
+// For returns the settings that apply to a downstream route
+func (c Config) For(route string) Settings {
+	settings := c.Default
+	if override, ok := c.Routes[route]; ok {
+		if override.FailureThreshold != 0 {
+			settings.FailureThreshold = override.FailureThreshold
+		}
+		if override.OpenTimeout != 0 {
+			settings.OpenTimeout = override.OpenTimeout
+		}
+		if override.HalfOpenProbes != 0 {
+			settings.HalfOpenProbes = override.HalfOpenProbes
+		}
+	}
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultFailureThreshold
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = DefaultOpenTimeout
+	}
+	if settings.HalfOpenProbes <= 0 {
+		settings.HalfOpenProbes = DefaultHalfOpenProbes
+	}
+	return settings
+}
+
+// ParseRoutes reads per-route overrides from JSON such as
+//
+//	{"policy-service GET /policies/{id}": {"failureThreshold": 3, "openTimeout": "10s", "halfOpenProbes": 2}}
+//
+// Keys are a downstream, a method and a route template separated by
+// spaces.
+func ParseRoutes(data []byte) (map[string]Settings, error) {
+	var raw map[string]struct {
+		FailureThreshold int    `json:"failureThreshold"`
+		OpenTimeout      string `json:"openTimeout"`
+		HalfOpenProbes   int    `json:"halfOpenProbes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid circuit breaker routes: %w", err)
+	}
+
+	routes := make(map[string]Settings, len(raw))
+	for key, entry := range raw {
+		fields := strings.Fields(key)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "/") {
+			return nil, fmt.Errorf("invalid route %q (must be downstream METHOD /template)", key)
+		}
+		if entry.FailureThreshold < 0 || entry.HalfOpenProbes < 0 {
+			return nil, fmt.Errorf("invalid settings for %q: counts cannot be negative", key)
+		}
+		settings := Settings{FailureThreshold: entry.FailureThreshold, HalfOpenProbes: entry.HalfOpenProbes}
+		if entry.OpenTimeout != "" {
+			timeout, err := time.ParseDuration(entry.OpenTimeout)
+			if err != nil || timeout < 0 {
+				return nil, fmt.Errorf("invalid openTimeout for %q", key)
+			}
+			settings.OpenTimeout = timeout
+		}
+		routes[fields[0]+" "+strings.ToUpper(fields[1])+" "+fields[2]] = settings
+	}
+	return routes, nil
+}
+
+// Fallback is what an operation does when its downstream cannot answer,
+// whether its breaker is open or the call failed
+type Fallback string
+
+// Fallbacks
+const (
+	// FailOpen carries on without the downstream's answer
+	FailOpen Fallback = "open"
+	// FailClosed refuses the operation
+	FailClosed Fallback = "closed"
+)
+
+// ParseFallbacks reads the fallback of each operation from a
+// comma-separated list such as "claim_intake=closed,claim_timeline=open"
+func ParseFallbacks(s string) (map[string]Fallback, error) {
+	fallbacks := make(map[string]Fallback)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		operation, value, ok := strings.Cut(entry, "=")
+		fallback := Fallback(strings.ToLower(strings.TrimSpace(value)))
+		if !ok || strings.TrimSpace(operation) == "" || (fallback != FailOpen && fallback != FailClosed) {
+			return nil, fmt.Errorf("invalid fallback %q (must be operation=open or operation=closed)", entry)
+		}
+		fallbacks[strings.TrimSpace(operation)] = fallback
+	}
+	return fallbacks, nil
+}
+
+// key names one breaker
+type key struct {
+	route  string // downstream, method and route template
+	tenant string
+}
+
+// breaker is the state of one downstream route for one tenant
+type breaker struct {
+	state     State
+	failures  int // in a row while closed
+	openedAt  time.Time
+	probes    int // in flight while half-open
+	successes int // probes that succeeded while half-open
+}
+
+// routeStats counts one downstream route's calls across tenants
+type routeStats struct {
+	failures    int64
+	rejected    int64
+	transitions map[State]int64
+}
+
+// Status is one breaker's state
+type Status struct {
+	Route    string // downstream, method and route template
+	Tenant   string // empty for calls without a tenant
+	State    State
+	Failures int       // in a row while closed
+	OpenedAt time.Time // zero while closed
+}
+
+// Breakers are the circuit breakers of a service's downstream calls. It is
+// safe for concurrent use; a nil *Breakers sends every call.
+type Breakers struct {
+	cfg    Config
+	logger *logrus.Logger
+	now    func() time.Time
+
+	mu       sync.Mutex
+	breakers map[key]*breaker
+	stats    map[string]*routeStats
+}
+
+// New creates the breakers of a service's downstream calls
+func New(cfg Config, logger *logrus.Logger) *Breakers {
+	return &Breakers{
+		cfg:      cfg,
+		logger:   logger,
+		now:      time.Now,
+		breakers: make(map[key]*breaker),
+		stats:    make(map[string]*routeStats),
+	}
+}
+
+// Do sends a call to downstream's method and route template through its
+// breaker for the tenant in ctx. A refused call returns ErrOpen without
+// sending. Transport errors and 5xx responses count as failures; other
+// responses, including 4xx answers, count as successes. A call whose ctx
+// was cancelled by the caller counts as neither.
+func (b *Breakers) Do(ctx context.Context, downstream, method, template string, send func() (*http.Response, error)) (*http.Response, error) {
+	if b == nil {
+		return send()
+	}
+
+	k := key{route: downstream + " " + method + " " + template, tenant: targeting.TenantFromContext(ctx)}
+	if !b.allow(k) {
+		return nil, fmt.Errorf("%s %s %s: %w", downstream, method, template, ErrOpen)
+	}
+
+	resp, err := send()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		b.release(k)
+	case err != nil || resp.StatusCode >= http.StatusInternalServerError:
+		b.record(k, false)
+	default:
+		b.record(k, true)
+	}
+	return resp, err
+}
+
+// allow reports whether a call may be sent, moving an open breaker whose
+// cool-off has passed to half-open
+func (b *Breakers) allow(k key) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.breakers[k]
+	if br == nil {
+		br = &breaker{state: Closed}
+		b.breakers[k] = br
+	}
+	settings := b.cfg.For(k.route)
+
+	if br.state == Open && b.now().Sub(br.openedAt) >= settings.OpenTimeout {
+		b.transition(k, br, HalfOpen)
+	}
+	switch br.state {
+	case Open:
+		b.statsFor(k.route).rejected++
+		return false
+	case HalfOpen:
+		if br.probes >= settings.HalfOpenProbes {
+			b.statsFor(k.route).rejected++
+			return false
+		}
+		br.probes++
+	}
+	return true
+}
+
+// release forgets a call that ended without an outcome
+func (b *Breakers) release(k key) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if br := b.breakers[k]; br != nil && br.state == HalfOpen && br.probes > 0 {
+		br.probes--
+	}
+}
+
+// record counts a call's outcome
+func (b *Breakers) record(k key, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	br := b.breakers[k]
+	settings := b.cfg.For(k.route)
+	if !success {
+		b.statsFor(k.route).failures++
+	}
+
+	switch br.state {
+	case Closed:
+		if success {
+			br.failures = 0
+			return
+		}
+		br.failures++
+		if br.failures >= settings.FailureThreshold {
+			b.transition(k, br, Open)
+		}
+	case HalfOpen:
+		if br.probes > 0 {
+			br.probes--
+		}
+		if !success {
+			b.transition(k, br, Open)
+			return
+		}
+		br.successes++
+		if br.successes >= settings.HalfOpenProbes {
+			b.transition(k, br, Closed)
+		}
+	case Open:
+		// A call sent before the breaker opened; its outcome changes nothing
+	}
+}
+
+// transition moves a breaker to state, logging and counting the change
+func (b *Breakers) transition(k key, br *breaker, state State) {
+	br.state = state
+	br.probes, br.successes = 0, 0
+	if state == Open {
+		br.openedAt = b.now()
+	}
+	if state == Closed {
+		br.failures = 0
+	}
+	b.statsFor(k.route).transitions[state]++
+
+	entry := b.logger.WithFields(logrus.Fields{
+		"route":  k.route,
+		"tenant": k.tenant,
+		"state":  state,
+	})
+	if state == Open {
+		entry.WithField("failures", br.failures).Warn("Circuit breaker opened")
+	} else {
+		entry.Info("Circuit breaker state changed")
+	}
+}
+
+func (b *Breakers) statsFor(route string) *routeStats {
+	stats := b.stats[route]
+	if stats == nil {
+		stats = &routeStats{transitions: make(map[State]int64)}
+		b.stats[route] = stats
+	}
+	return stats
+}
+
+// Statuses returns the state of every breaker that has seen a call, by
+// route and tenant
+func (b *Breakers) Statuses() []Status {
+	if b == nil {
+		return []Status{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make([]Status, 0, len(b.breakers))
+	for k, br := range b.breakers {
+		status := Status{Route: k.route, Tenant: k.tenant, State: br.state, Failures: br.failures}
+		if br.state != Closed {
+			status.OpenedAt = br.openedAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Route != statuses[j].Route {
+			return statuses[i].Route < statuses[j].Route
+		}
+		return statuses[i].Tenant < statuses[j].Tenant
+	})
+	return statuses
+}
+
+// WritePrometheus writes breaker states and counts for GET /metrics in the
+// Prometheus text exposition format
+func (b *Breakers) WritePrometheus(w io.Writer) {
+	statuses := b.Statuses()
+
+	fmt.Fprint(w, "# HELP claims_circuit_breaker_state Circuit breaker state per downstream route and tenant, 1 for the current state.\n")
+	fmt.Fprint(w, "# TYPE claims_circuit_breaker_state gauge\n")
+	for _, status := range statuses {
+		downstream, route, _ := strings.Cut(status.Route, " ")
+		for _, state := range states {
+			value := 0
+			if status.State == state {
+				value = 1
+			}
+			fmt.Fprintf(w, "claims_circuit_breaker_state{downstream=%q,route=%q,tenant=%q,state=%q} %d\n", downstream, route, status.Tenant, state, value)
+		}
+	}
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	routes := make([]string, 0, len(b.stats))
+	for route := range b.stats {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	fmt.Fprint(w, "# HELP claims_circuit_breaker_transitions_total Circuit breaker state changes per downstream route.\n")
+	fmt.Fprint(w, "# TYPE claims_circuit_breaker_transitions_total counter\n")
+	for _, r := range routes {
+		downstream, route, _ := strings.Cut(r, " ")
+		for _, state := range states {
+			fmt.Fprintf(w, "claims_circuit_breaker_transitions_total{downstream=%q,route=%q,to=%q} %d\n", downstream, route, state, b.stats[r].transitions[state])
+		}
+	}
+	fmt.Fprint(w, "# HELP claims_circuit_breaker_failures_total Failed downstream calls per route.\n")
+	fmt.Fprint(w, "# TYPE claims_circuit_breaker_failures_total counter\n")
+	for _, r := range routes {
+		downstream, route, _ := strings.Cut(r, " ")
+		fmt.Fprintf(w, "claims_circuit_breaker_failures_total{downstream=%q,route=%q} %d\n", downstream, route, b.stats[r].failures)
+	}
+	fmt.Fprint(w, "# HELP claims_circuit_breaker_rejected_total Downstream calls refused by an open breaker per route.\n")
+	fmt.Fprint(w, "# TYPE claims_circuit_breaker_rejected_total counter\n")
+	for _, r := range routes {
+		downstream, route, _ := strings.Cut(r, " ")
+		fmt.Fprintf(w, "claims_circuit_breaker_rejected_total{downstream=%q,route=%q} %d\n", downstream, route, b.stats[r].rejected)
+	}
+}
@@ -0,0 +1,160 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestBreakers(cfg Config) (*Breakers, *time.Time) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	b := New(cfg, logger)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func respond(status int) func() (*http.Response, error) {
+	return func() (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: http.NoBody}, nil
+	}
+}
+
+func statusOf(b *Breakers, route, tenant string) Status {
+	for _, status := range b.Statuses() {
+		if status.Route == route && status.Tenant == tenant {
+			return status
+		}
+	}
+	return Status{}
+}
+
+func TestBreakerOpensAndProbesHalfOpen(t *testing.T) {
+	b, now := newTestBreakers(Config{Default: Settings{FailureThreshold: 3, OpenTimeout: 10 * time.Second}})
+	ctx := context.Background()
+	call := func(send func() (*http.Response, error)) error {
+		_, err := b.Do(ctx, "policy-service", "GET", "/policies/{id}", send)
+		return err
+	}
+
+	// A 404 is an answer, not a failure
+	for i := 0; i < 5; i++ {
+		call(respond(http.StatusNotFound))
+	}
+	for i := 0; i < 3; i++ {
+		if err := call(respond(http.StatusBadGateway)); err != nil {
+			t.Fatalf("Expected call %d to be sent, got %v", i, err)
+		}
+	}
+
+	sent := false
+	err := call(func() (*http.Response, error) { sent = true; return respond(http.StatusOK)() })
+	if !errors.Is(err, ErrOpen) || sent {
+		t.Fatalf("Expected the open breaker to refuse the call, got %v (sent %v)", err, sent)
+	}
+
+	// After the cool-off a failed probe opens it again
+	*now = now.Add(10 * time.Second)
+	if err := call(func() (*http.Response, error) { return nil, errors.New("connection refused") }); err == nil || errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected the probe to be sent, got %v", err)
+	}
+	if err := call(respond(http.StatusOK)); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected the failed probe to reopen the breaker, got %v", err)
+	}
+
+	*now = now.Add(10 * time.Second)
+	if err := call(respond(http.StatusOK)); err != nil {
+		t.Fatalf("Expected the probe to be sent, got %v", err)
+	}
+	if state := b.Statuses()[0].State; state != Closed {
+		t.Fatalf("Expected a successful probe to close the breaker, got %s", state)
+	}
+
+	var metrics strings.Builder
+	b.WritePrometheus(&metrics)
+	for _, line := range []string{
+		`claims_circuit_breaker_state{downstream="policy-service",route="GET /policies/{id}",tenant="",state="closed"} 1`,
+		`claims_circuit_breaker_transitions_total{downstream="policy-service",route="GET /policies/{id}",to="open"} 2`,
+		`claims_circuit_breaker_failures_total{downstream="policy-service",route="GET /policies/{id}"} 4`,
+		`claims_circuit_breaker_rejected_total{downstream="policy-service",route="GET /policies/{id}"} 2`,
+	} {
+		if !strings.Contains(metrics.String(), line+"\n") {
+			t.Errorf("Expected metrics to contain %s, got\n%s", line, metrics.String())
+		}
+	}
+}
+
+func TestBreakersAreSeparatePerTenantAndRoute(t *testing.T) {
+	routes, err := ParseRoutes([]byte(`{"payments-service post /payouts": {"failureThreshold": 1, "halfOpenProbes": 2}}`))
+	if err != nil {
+		t.Fatalf("ParseRoutes failed: %v", err)
+	}
+	b, now := newTestBreakers(Config{Default: Settings{FailureThreshold: 5}, Routes: routes})
+
+	acme := targeting.WithTenant(context.Background(), "acme")
+	globex := targeting.WithTenant(context.Background(), "globex")
+	b.Do(acme, "payments-service", "POST", "/payouts", respond(http.StatusServiceUnavailable))
+
+	if _, err := b.Do(acme, "payments-service", "POST", "/payouts", respond(http.StatusCreated)); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected the route's threshold of 1 to open acme's breaker, got %v", err)
+	}
+	if _, err := b.Do(globex, "payments-service", "POST", "/payouts", respond(http.StatusCreated)); err != nil {
+		t.Errorf("Expected globex's breaker to stay closed, got %v", err)
+	}
+	if _, err := b.Do(acme, "payments-service", "GET", "/payments", respond(http.StatusOK)); err != nil {
+		t.Errorf("Expected acme's other routes to stay closed, got %v", err)
+	}
+
+	// Two probes must succeed before it closes; a cancelled one counts as neither
+	*now = now.Add(DefaultOpenTimeout)
+	cancelled, cancel := context.WithCancel(acme)
+	cancel()
+	b.Do(cancelled, "payments-service", "POST", "/payouts", func() (*http.Response, error) { return nil, context.Canceled })
+	b.Do(acme, "payments-service", "POST", "/payouts", respond(http.StatusCreated))
+	if status := statusOf(b, "payments-service POST /payouts", "acme"); status.State != HalfOpen {
+		t.Fatalf("Expected acme's breaker to stay half-open after one probe, got %+v", status)
+	}
+	b.Do(acme, "payments-service", "POST", "/payouts", respond(http.StatusCreated))
+	if status := statusOf(b, "payments-service POST /payouts", "acme"); status.State != Closed {
+		t.Errorf("Expected two probes to close acme's breaker, got %+v", status)
+	}
+}
+
+func TestNilBreakersSendEveryCall(t *testing.T) {
+	var b *Breakers
+	if _, err := b.Do(context.Background(), "policy-service", "GET", "/policies/{id}", respond(http.StatusOK)); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	for _, input := range []string{
+		`{"GET /policies/{id}": {}}`,
+		`{"policy-service GET policies": {}}`,
+		`{"policy-service GET /policies/{id}": {"openTimeout": "soon"}}`,
+		`{"policy-service GET /policies/{id}": {"failureThreshold": -1}}`,
+		`[]`,
+	} {
+		if _, err := ParseRoutes([]byte(input)); err == nil {
+			t.Errorf("Expected %s to be rejected", input)
+		}
+	}
+
+	fallbacks, err := ParseFallbacks(" claim_intake=closed, claim_timeline=OPEN ")
+	if err != nil || fallbacks["claim_intake"] != FailClosed || fallbacks["claim_timeline"] != FailOpen {
+		t.Errorf("Unexpected fallbacks %v, %v", fallbacks, err)
+	}
+	for _, input := range []string{"claim_intake", "claim_intake=maybe", "=closed"} {
+		if _, err := ParseFallbacks(input); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+)
+
+// These tests are the consumer half of the contracts in pkg/contracts. The
+// provider half runs in policy-service and payments-service.
+
+func TestPolicyClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("claims-service", "policy-service"))
+	client := NewPolicyClient(mock.URL, 5*time.Second, nil)
+	ctx := context.Background()
+
+	policy, err := client.GetPolicy(ctx, "pol-001", "cust-001")
+	if err != nil {
+		t.Fatalf("GetPolicy failed: %v", err)
+	}
+	if policy.CustomerID != "cust-001" || policy.Type != "auto" {
+		t.Errorf("Unexpected policy: %+v", policy)
+	}
+	if !policy.StartDate.Before(policy.EndDate) {
+		t.Errorf("Policy term not decoded: %v - %v", policy.StartDate, policy.EndDate)
+	}
+
+	if _, err := client.GetPolicy(ctx, "pol-002", "cust-001"); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+
+	if _, err := client.GetPolicy(ctx, "pol-999", "cust-001"); err == nil || err.Error() != "policy not found" {
+		t.Errorf("Expected policy not found error, got %v", err)
+	}
+}
+
+func TestPaymentsClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("claims-service", "payments-service"))
+	client := NewPaymentsClient(mock.URL, 5*time.Second, func() (string, error) { return "service-token", nil }, nil)
+	ctx := context.Background()
+
+	payout, err := client.CreatePayout(ctx, "claim-001", "cust-001", 4500)
+	if err != nil {
+		t.Fatalf("CreatePayout failed: %v", err)
+	}
+	if payout.Type != "payout" || payout.ClaimID != "claim-001" || payout.ID == "" {
+		t.Errorf("Unexpected payout: %+v", payout)
+	}
+
+	if _, err := client.CreatePayout(ctx, "claim-001", "cust-001", 0); err == nil {
+		t.Error("Expected error for payout with a zero amount")
+	}
+
+	existing, err := client.GetPayout(ctx, "pay-002")
+	if err != nil {
+		t.Fatalf("GetPayout failed: %v", err)
+	}
+	if existing.Status != "completed" {
+		t.Errorf("Status mismatch: got %s, want completed", existing.Status)
+	}
+
+	if _, err := client.GetPayout(ctx, "pay-999"); err == nil || err.Error() != "payment not found" {
+		t.Errorf("Expected payment not found error, got %v", err)
+	}
+
+	payouts, err := client.ListPayouts(ctx, "claim-001")
+	if err != nil {
+		t.Fatalf("ListPayouts failed: %v", err)
+	}
+	if len(payouts) != 1 || payouts[0].ID != "pay-002" || payouts[0].CreatedAt.IsZero() {
+		t.Errorf("Unexpected payouts: %+v", payouts)
+	}
+}
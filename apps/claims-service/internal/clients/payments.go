@@ -0,0 +1,174 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+)
+
+// Payout is the subset of a payments-service payment the claims service reads
+type Payout struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	ClaimID       string     `json:"claimId"`
+	CustomerID    string     `json:"customerId"`
+	Amount        float64    `json:"amount"`
+	Status        string     `json:"status"`
+	ProcessedDate *time.Time `json:"processedDate,omitempty"` // set once the payout settles
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// payoutRequest is the body of POST /payouts
+type payoutRequest struct {
+	ClaimID    string  `json:"claimId"`
+	CustomerID string  `json:"customerId"`
+	Amount     float64 `json:"amount"`
+}
+
+// TokenSource returns a bearer token for a request to another service
+type TokenSource func() (string, error)
+
+// PaymentsClient calls payments-service
+type PaymentsClient struct {
+	baseURL    string
+	httpClient *http.Client
+	token      TokenSource
+	breakers   *breaker.Breakers
+}
+
+// NewPaymentsClient creates a new payments-service client. Requests carry
+// a bearer token from token, such as a service token with the
+// payments:write scope that payouts require; nil sends none. Requests go
+// through breakers, which refuse them while payments-service keeps
+// failing; nil sends every request.
+func NewPaymentsClient(baseURL string, timeout time.Duration, token TokenSource, breakers *breaker.Breakers) *PaymentsClient {
+	return &PaymentsClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+		token:      token,
+		breakers:   breakers,
+	}
+}
+
+// do sends req with the client's token through the breaker of its route
+// template
+func (c *PaymentsClient) do(req *http.Request, template string) (*http.Response, error) {
+	if c.token != nil {
+		token, err := c.token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get token for payments-service: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.breakers.Do(req.Context(), "payments-service", req.Method, template, func() (*http.Response, error) {
+		return c.httpClient.Do(req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("payments-service request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// CreatePayout requests a payout for an approved claim
+func (c *PaymentsClient) CreatePayout(ctx context.Context, claimID, customerID string, amount float64) (*Payout, error) {
+	body, err := json.Marshal(payoutRequest{
+		ClaimID:    claimID,
+		CustomerID: customerID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/payouts", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req, "/payouts")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusBadRequest:
+		return nil, fmt.Errorf("payout rejected by payments-service")
+	case http.StatusConflict:
+		// The claim's accepted offer does not cover the payout
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return nil, fmt.Errorf("payout refused by payments-service: %s", body.Error)
+	default:
+		return nil, fmt.Errorf("payments-service returned status %d", resp.StatusCode)
+	}
+
+	var payout Payout
+	if err := json.NewDecoder(resp.Body).Decode(&payout); err != nil {
+		return nil, fmt.Errorf("failed to decode payout: %w", err)
+	}
+	return &payout, nil
+}
+
+// GetPayout fetches a payout to check its settlement status
+func (c *PaymentsClient) GetPayout(ctx context.Context, paymentID string) (*Payout, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/payments/"+url.PathEscape(paymentID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, "/payments/{id}")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("payment not found")
+	default:
+		return nil, fmt.Errorf("payments-service returned status %d", resp.StatusCode)
+	}
+
+	var payout Payout
+	if err := json.NewDecoder(resp.Body).Decode(&payout); err != nil {
+		return nil, fmt.Errorf("failed to decode payout: %w", err)
+	}
+	return &payout, nil
+}
+
+// ListPayouts fetches every payout made against a claim, oldest first
+func (c *PaymentsClient) ListPayouts(ctx context.Context, claimID string) ([]Payout, error) {
+	endpoint := c.baseURL + "/payments?" + url.Values{"claimId": {claimID}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req, "/payments")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("payments-service returned status %d", resp.StatusCode)
+	}
+
+	var payouts []Payout
+	if err := json.NewDecoder(resp.Body).Decode(&payouts); err != nil {
+		return nil, fmt.Errorf("failed to decode payouts: %w", err)
+	}
+	return payouts, nil
+}
@@ -0,0 +1,77 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+)
+
+// Policy is the subset of a policy-service policy the claims service reads
+type Policy struct {
+	ID           string     `json:"id"`
+	CustomerID   string     `json:"customerId"`
+	PolicyNumber string     `json:"policyNumber"`
+	Type         string     `json:"type"`
+	Status       string     `json:"status"`
+	StartDate    time.Time  `json:"startDate"`
+	EndDate      time.Time  `json:"endDate"`
+	GraceEndsAt  *time.Time `json:"graceEndsAt,omitempty"` // set while the policy is in grace
+}
+
+// PolicyClient calls policy-service
+type PolicyClient struct {
+	baseURL    string
+	httpClient *http.Client
+	breakers   *breaker.Breakers
+}
+
+// NewPolicyClient creates a new policy-service client. Requests go through
+// breakers, which refuse them while policy-service keeps failing; nil sends
+// every request.
+func NewPolicyClient(baseURL string, timeout time.Duration, breakers *breaker.Breakers) *PolicyClient {
+	return &PolicyClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+		breakers:   breakers,
+	}
+}
+
+// GetPolicy fetches a policy on behalf of the customer filing a claim.
+// policy-service only returns policies the caller owns, so a claimant
+// referencing someone else's policy gets "unauthorized".
+func (c *PolicyClient) GetPolicy(ctx context.Context, policyID, customerID string) (*Policy, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/policies/"+url.PathEscape(policyID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.breakers.Do(ctx, "policy-service", http.MethodGet, "/policies/{id}", func() (*http.Response, error) {
+		return c.httpClient.Do(req)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("policy-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return nil, fmt.Errorf("unauthorized")
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("policy not found")
+	default:
+		return nil, fmt.Errorf("policy-service returned status %d", resp.StatusCode)
+	}
+
+	var policy Policy
+	if err := json.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+	return &policy, nil
+}
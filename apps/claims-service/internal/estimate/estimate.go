@@ -0,0 +1,100 @@
+// Package estimate prices the damage on a claim from its photos, so
+// adjusters can compare what was claimed against what the damage looks
+// like it costs to repair. The pricing is done by an Estimator, the
+// integration point for a damage estimation provider, chosen in
+// configuration. Stub stands in for one in development and tests.
+package estimate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// Image is a photo attached to a claim
+type Image struct {
+	DocumentID  string
+	FileName    string
+	ContentType string // image/jpeg, image/png and so on
+	Content     []byte
+}
+
+// IsImage reports whether a document of contentType can be estimated from
+func IsImage(contentType string) bool {
+	return strings.HasPrefix(contentType, "image/")
+}
+
+// Request is what an estimator is given: the claim as filed and every
+// photo attached to it so far
+type Request struct {
+	Claim  *models.Claim
+	Images []Image
+}
+
+// Result is an estimator's price for the damage
+type Result struct {
+	Amount     float64 // estimated repair cost, in the currency of the claimed amount
+	Confidence float64 // from 0, a guess, to 1, certain
+}
+
+// Estimator prices the damage on a claim. Estimate may take as long as a
+// remote model needs; it should return when ctx is done.
+type Estimator interface {
+	Name() string
+	Estimate(ctx context.Context, req *Request) (*Result, error)
+}
+
+// Names of the estimators that can be selected in configuration
+const (
+	NameStub = "stub"
+)
+
+// New returns the estimator called name. An empty name returns nil:
+// claims are not estimated.
+func New(name string) (Estimator, error) {
+	switch name {
+	case "":
+		return nil, nil
+	case NameStub:
+		return Stub{}, nil
+	default:
+		return nil, fmt.Errorf("unknown damage estimator %q (want %s)", name, NameStub)
+	}
+}
+
+// Stub estimates without looking at the photos. The estimate is the
+// claimed amount moved up or down by at most 20%, by a fraction taken
+// from the photos' content, so the same photos always get the same
+// estimate. Confidence grows with the number of photos, up to 0.8.
+type Stub struct{}
+
+// Name returns NameStub
+func (Stub) Name() string {
+	return NameStub
+}
+
+// Estimate prices req from the claimed amount and its photos
+func (Stub) Estimate(ctx context.Context, req *Request) (*Result, error) {
+	if len(req.Images) == 0 {
+		return nil, fmt.Errorf("no photos to estimate from")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	for _, image := range req.Images {
+		hash.Write(image.Content)
+	}
+	fraction := float64(binary.BigEndian.Uint16(hash.Sum(nil))) / math.MaxUint16
+	amount := req.Claim.Amount * (0.8 + 0.4*fraction)
+	return &Result{
+		Amount:     math.Round(amount*100) / 100,
+		Confidence: math.Min(0.4+0.1*float64(len(req.Images)), 0.8),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package estimate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+func TestStubEstimatesFromThePhotos(t *testing.T) {
+	claim := &models.Claim{ID: "claim-001", Amount: 1000}
+	photos := []Image{{DocumentID: "doc-1", ContentType: "image/jpeg", Content: []byte("\xff\xd8\xff\xe0 bumper")}}
+
+	first, err := Stub{}.Estimate(context.Background(), &Request{Claim: claim, Images: photos})
+	if err != nil {
+		t.Fatalf("Estimate failed: %v", err)
+	}
+	if first.Amount < 800 || first.Amount > 1200 || first.Confidence != 0.5 {
+		t.Errorf("Unexpected estimate %+v", first)
+	}
+	again, _ := Stub{}.Estimate(context.Background(), &Request{Claim: claim, Images: photos})
+	if *again != *first {
+		t.Errorf("The same photos should get the same estimate: %+v, then %+v", first, again)
+	}
+
+	// Confidence grows with the photos, up to 0.8
+	for i := 0; i < 5; i++ {
+		photos = append(photos, Image{Content: []byte{byte(i)}})
+	}
+	more, _ := Stub{}.Estimate(context.Background(), &Request{Claim: claim, Images: photos})
+	if more.Confidence != 0.8 {
+		t.Errorf("Expected confidence 0.8 from %d photos, got %v", len(photos), more.Confidence)
+	}
+
+	if _, err := (Stub{}).Estimate(context.Background(), &Request{Claim: claim}); err == nil || err.Error() != "no photos to estimate from" {
+		t.Errorf("Expected a claim without photos to be refused, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if estimator, err := New(""); estimator != nil || err != nil {
+		t.Errorf("No name should mean no estimator, got %v, %v", estimator, err)
+	}
+	if estimator, err := New(NameStub); err != nil || estimator.Name() != NameStub {
+		t.Errorf("Expected the stub, got %v, %v", estimator, err)
+	}
+	if _, err := New("vision"); err == nil || err.Error() != `unknown damage estimator "vision" (want stub)` {
+		t.Errorf("Expected an unknown estimator error, got %v", err)
+	}
+}
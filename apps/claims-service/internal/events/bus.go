@@ -0,0 +1,178 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Event types published on the bus
+const (
+	ClaimCreated        = "claim.created"
+	ClaimStatusChanged  = "claim.status_changed"
+	ClaimAssigned       = "claim.assigned"
+	ClaimEscalated      = "claim.escalated"
+	DocumentUploaded    = "claim.document_uploaded"
+	DocumentQuarantined = "claim.document_quarantined"
+	OfferMade           = "claim.offer_made"
+	OfferAccepted       = "claim.offer_accepted"
+	OfferRejected       = "claim.offer_rejected"
+)
+
+// Event represents a claim lifecycle event
+type Event struct {
+	ID             int64     `json:"id"`
+	Type           string    `json:"type"`
+	ClaimID        string    `json:"claimId"`
+	ClaimNumber    string    `json:"claimNumber,omitempty"`
+	PolicyID       string    `json:"policyId,omitempty"`
+	CustomerID     string    `json:"customerId"`
+	OldStatus      string    `json:"oldStatus,omitempty"`
+	NewStatus      string    `json:"newStatus"`
+	Queue          string    `json:"queue,omitempty"`
+	AssignedTo     string    `json:"assignedTo,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	RejectionCodes []string  `json:"rejectionCodes,omitempty"` // set when a claim is rejected
+	DuplicateOf    string    `json:"duplicateOf,omitempty"`    // set when a likely duplicate was filed with force
+	DocumentID     string    `json:"documentId,omitempty"`     // set when a document is uploaded or quarantined
+	FileName       string    `json:"fileName,omitempty"`
+	OfferID        string    `json:"offerId,omitempty"` // set on settlement offer events
+	Amount         float64   `json:"amount,omitempty"`  // the offer amount
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// Filter decides whether a subscriber receives an event
+type Filter func(Event) bool
+
+// Subscription is a live feed of events matching a filter
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	id     int
+	filter Filter
+	bus    *Bus
+}
+
+// Close removes the subscription from the bus
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s.id)
+}
+
+// Bus is an in-process publish/subscribe event bus with a bounded history
+// so that reconnecting consumers can resume from the last event they saw
+type Bus struct {
+	history     []Event
+	historySize int
+	nextEventID int64
+	subscribers map[int]*Subscription
+	nextSubID   int
+	mu          sync.RWMutex
+	logger      *logrus.Logger
+}
+
+// NewBus creates a new event bus retaining up to historySize events for replay
+func NewBus(historySize int, logger *logrus.Logger) *Bus {
+	if historySize <= 0 {
+		historySize = 1000
+	}
+	return &Bus{
+		history:     make([]Event, 0, historySize),
+		historySize: historySize,
+		nextEventID: 1,
+		subscribers: make(map[int]*Subscription),
+		logger:      logger,
+	}
+}
+
+// Publish assigns an ID and timestamp to the event, records it in the
+// history and delivers it to all matching subscribers
+func (b *Bus) Publish(evt Event) Event {
+	if b == nil {
+		return evt
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	evt.ID = b.nextEventID
+	b.nextEventID++
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+
+	if len(b.history) == b.historySize {
+		copy(b.history, b.history[1:])
+		b.history = b.history[:len(b.history)-1]
+	}
+	b.history = append(b.history, evt)
+
+	for _, sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(evt) {
+			continue
+		}
+		select {
+		case sub.ch <- evt:
+		default:
+			// Slow consumers miss live events but can resume from history
+			b.logger.WithFields(logrus.Fields{
+				"subscriptionId": sub.id,
+				"eventId":        evt.ID,
+			}).Warn("Subscriber buffer full, dropping event")
+		}
+	}
+
+	return evt
+}
+
+// Subscribe registers a new subscription. Events published after lastEventID
+// that are still in the history are returned for replay; the replay and the
+// live subscription are established atomically so no event is missed.
+func (b *Bus) Subscribe(filter Filter, lastEventID int64, bufferSize int) ([]Event, *Subscription) {
+	if bufferSize <= 0 {
+		bufferSize = 64
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var replay []Event
+	if lastEventID > 0 {
+		for _, evt := range b.history {
+			if evt.ID > lastEventID && (filter == nil || filter(evt)) {
+				replay = append(replay, evt)
+			}
+		}
+	}
+
+	ch := make(chan Event, bufferSize)
+	sub := &Subscription{
+		C:      ch,
+		ch:     ch,
+		id:     b.nextSubID,
+		filter: filter,
+		bus:    b,
+	}
+	b.subscribers[sub.id] = sub
+	b.nextSubID++
+
+	return replay, sub
+}
+
+// unsubscribe removes a subscription and closes its channel
+func (b *Bus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if sub, exists := b.subscribers[id]; exists {
+		delete(b.subscribers, id)
+		close(sub.ch)
+	}
+}
+
+// SubscriberCount returns the number of active subscriptions
+func (b *Bus) SubscriberCount() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers)
+}
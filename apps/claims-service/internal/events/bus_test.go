@@ -0,0 +1,132 @@
+package events
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestBus(historySize int) *Bus {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewBus(historySize, logger)
+}
+
+func TestBusPublishAssignsSequentialIDs(t *testing.T) {
+	bus := newTestBus(10)
+
+	for i := int64(1); i <= 3; i++ {
+		evt := bus.Publish(Event{Type: ClaimCreated, ClaimID: "claim-001"})
+		if evt.ID != i {
+			t.Errorf("Event ID mismatch: got %d, want %d", evt.ID, i)
+		}
+		if evt.Timestamp.IsZero() {
+			t.Error("Published event should have a timestamp")
+		}
+	}
+}
+
+func TestBusSubscribeReceivesMatchingEvents(t *testing.T) {
+	bus := newTestBus(10)
+
+	_, sub := bus.Subscribe(func(evt Event) bool {
+		return evt.CustomerID == "cust-001"
+	}, 0, 10)
+	defer sub.Close()
+
+	bus.Publish(Event{Type: ClaimCreated, ClaimID: "claim-001", CustomerID: "cust-002"})
+	bus.Publish(Event{Type: ClaimCreated, ClaimID: "claim-002", CustomerID: "cust-001"})
+
+	select {
+	case evt := <-sub.C:
+		if evt.ClaimID != "claim-002" {
+			t.Errorf("Received unexpected event for claim %s", evt.ClaimID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+
+	select {
+	case evt := <-sub.C:
+		t.Errorf("Received event that should have been filtered: %+v", evt)
+	default:
+	}
+}
+
+func TestBusSubscribeReplaysFromLastEventID(t *testing.T) {
+	tests := []struct {
+		name        string
+		historySize int
+		published   int
+		lastEventID int64
+		wantFirstID int64
+		wantCount   int
+	}{
+		{"no resume", 10, 5, 0, 0, 0},
+		{"resume mid stream", 10, 5, 2, 3, 3},
+		{"resume at head", 10, 5, 5, 0, 0},
+		{"resume past evicted history", 3, 5, 1, 3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := newTestBus(tt.historySize)
+			for i := 0; i < tt.published; i++ {
+				bus.Publish(Event{Type: ClaimStatusChanged, ClaimID: "claim-001"})
+			}
+
+			replay, sub := bus.Subscribe(nil, tt.lastEventID, 10)
+			defer sub.Close()
+
+			if len(replay) != tt.wantCount {
+				t.Fatalf("Replay count mismatch: got %d, want %d", len(replay), tt.wantCount)
+			}
+			if tt.wantCount > 0 && replay[0].ID != tt.wantFirstID {
+				t.Errorf("First replayed ID mismatch: got %d, want %d", replay[0].ID, tt.wantFirstID)
+			}
+		})
+	}
+}
+
+func TestBusCloseUnsubscribes(t *testing.T) {
+	bus := newTestBus(10)
+
+	_, sub := bus.Subscribe(nil, 0, 10)
+	if bus.SubscriberCount() != 1 {
+		t.Fatalf("Subscriber count mismatch: got %d, want 1", bus.SubscriberCount())
+	}
+
+	sub.Close()
+	if bus.SubscriberCount() != 0 {
+		t.Errorf("Subscriber count mismatch after close: got %d, want 0", bus.SubscriberCount())
+	}
+
+	if _, ok := <-sub.C; ok {
+		t.Error("Subscription channel should be closed")
+	}
+
+	// Publishing after close must not panic or block
+	bus.Publish(Event{Type: ClaimCreated, ClaimID: "claim-001"})
+}
+
+func TestBusDropsEventsForFullSubscriber(t *testing.T) {
+	bus := newTestBus(10)
+
+	_, sub := bus.Subscribe(nil, 0, 1)
+	defer sub.Close()
+
+	done := make(chan struct{})
+	go func() {
+		bus.Publish(Event{Type: ClaimCreated, ClaimID: "claim-001"})
+		bus.Publish(Event{Type: ClaimCreated, ClaimID: "claim-002"})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+}
@@ -4,15 +4,25 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
 	"github.com/sirupsen/logrus"
 )
 
+// KeyAutoApproval is the flag key recorded in impressions
+const KeyAutoApproval = "claims.autoApproval"
+
 // Flags holds all feature flags for the application
 type Flags struct {
 	autoApproval bool
+	values       map[string]string // non-boolean flags, see values.go
 	mu           sync.RWMutex
 	logger       *logrus.Logger
+	stopRefresh  chan struct{}
+
+	impressions     *targeting.Recorder
+	stopImpressions func()
 }
 
 var flags *Flags
@@ -22,6 +32,7 @@ var flags *Flags
 // See the integration guide at the bottom of this file
 func Initialize(apiKey string, logger *logrus.Logger) (*Flags, error) {
 	flags = &Flags{
+		values: make(map[string]string),
 		logger: logger,
 	}
 
@@ -35,8 +46,30 @@ func Initialize(apiKey string, logger *logrus.Logger) (*Flags, error) {
 		}
 	}
 
+	// claims.autoApprovalThreshold (default: 1000) - maximum amount auto-approved
+	if threshold := os.Getenv("FEATURE_AUTO_APPROVAL_THRESHOLD"); threshold != "" {
+		flags.values[KeyAutoApprovalThreshold] = threshold
+	}
+
+	// Flags in FEATURE_FLAGS_FILE override the environment and are re-read
+	// while the service runs
+	if path := os.Getenv("FEATURE_FLAGS_FILE"); path != "" {
+		interval := DefaultRefreshInterval
+		if v := os.Getenv("FEATURE_REFRESH_INTERVAL"); v != "" {
+			if d, err := time.ParseDuration(v); err == nil && d > 0 {
+				interval = d
+			} else {
+				logger.Warnf("Invalid FEATURE_REFRESH_INTERVAL '%s', defaulting to %s", v, interval)
+			}
+		}
+		flags.watchFile(path, interval)
+	}
+
+	flags.impressions, flags.stopImpressions = startImpressions(logger)
+
 	logger.WithFields(logrus.Fields{
-		"autoApproval": flags.autoApproval,
+		"autoApproval":          flags.autoApproval,
+		"autoApprovalThreshold": flags.AutoApprovalThreshold(),
 	}).Info("Feature flags initialized")
 
 	if apiKey != "" && apiKey != "dev-mode" {
@@ -61,6 +94,22 @@ func (f *Flags) IsAutoApprovalEnabled() bool {
 	return f.autoApproval
 }
 
+// IsAutoApprovalEnabledFor returns whether auto-approval applies to a claim
+// filed by the customer described by ctx, and records the impression
+func (f *Flags) IsAutoApprovalEnabledFor(ctx targeting.Context) bool {
+	enabled := f.IsAutoApprovalEnabled()
+	if f != nil {
+		f.impressions.Record(targeting.Impression{
+			Flag:       KeyAutoApproval,
+			Variant:    targeting.BoolVariant(enabled),
+			UserID:     ctx.UserID,
+			TenantID:   ctx.TenantID,
+			Attributes: ctx.Attributes,
+		})
+	}
+	return enabled
+}
+
 // SetAutoApproval sets the auto approval flag (for testing/admin purposes)
 func (f *Flags) SetAutoApproval(enabled bool) {
 	if f == nil {
@@ -72,9 +121,26 @@ func (f *Flags) SetAutoApproval(enabled bool) {
 	f.logger.WithField("autoApproval", enabled).Info("Feature flag updated")
 }
 
+// EnabledFlags returns the keys of the boolean flags that are on, for the
+// health check
+func (f *Flags) EnabledFlags() []string {
+	var enabled []string
+	if f.IsAutoApprovalEnabled() {
+		enabled = append(enabled, KeyAutoApproval)
+	}
+	return enabled
+}
+
 // Shutdown gracefully shuts down the feature management system
 func Shutdown() {
 	if flags != nil {
+		if flags.stopRefresh != nil {
+			close(flags.stopRefresh)
+			flags.stopRefresh = nil
+		}
+		if flags.stopImpressions != nil {
+			flags.stopImpressions()
+		}
 		flags.logger.Info("Feature management shutdown complete")
 	}
 }
@@ -126,6 +192,9 @@ To integrate with CloudBees Feature Management (Rox SDK), follow these steps:
        return flags, nil
    }
 
+   Non-boolean flags are registered the same way, with their defaults:
+       flags.AutoApprovalThreshold = model.NewRoxDouble(1000, []float64{500, 1000, 2500})
+
 5. Update IsAutoApprovalEnabled:
    func (f *Flags) IsAutoApprovalEnabled() bool {
        if f == nil || f.AutoApproval == nil {
@@ -134,6 +203,14 @@ To integrate with CloudBees Feature Management (Rox SDK), follow these steps:
        return f.AutoApproval.IsEnabled(nil)
    }
 
+   and AutoApprovalThreshold, which the dashboard can change without a restart:
+   func (f *Flags) AutoApprovalThreshold() float64 {
+       if f == nil || f.AutoApprovalThreshold == nil {
+           return DefaultAutoApprovalThreshold
+       }
+       return f.AutoApprovalThreshold.GetValue(nil)
+   }
+
 6. Update Shutdown:
    func Shutdown() {
        if flags != nil {
@@ -0,0 +1,162 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Non-boolean flag keys
+const (
+	// KeyAutoApprovalThreshold is the maximum claim amount, in dollars, that
+	// claims.autoApproval approves without review
+	KeyAutoApprovalThreshold = "claims.autoApprovalThreshold"
+)
+
+const (
+	// DefaultAutoApprovalThreshold applies when claims.autoApprovalThreshold
+	// is unset or invalid
+	DefaultAutoApprovalThreshold = 1000.0
+
+	// DefaultRefreshInterval is how often FEATURE_FLAGS_FILE is checked for changes
+	DefaultRefreshInterval = 30 * time.Second
+)
+
+// AutoApprovalThreshold returns the current claims.autoApprovalThreshold.
+// Negative or unparseable values fall back to the default.
+func (f *Flags) AutoApprovalThreshold() float64 {
+	threshold := f.Number(KeyAutoApprovalThreshold, DefaultAutoApprovalThreshold)
+	if threshold < 0 {
+		f.logger.WithField("threshold", threshold).Warn("Negative auto-approval threshold, using default")
+		return DefaultAutoApprovalThreshold
+	}
+	return threshold
+}
+
+// String returns a string flag value, or def when the flag is unset
+func (f *Flags) String(key, def string) string {
+	if f == nil {
+		return def
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if value, ok := f.values[key]; ok {
+		return value
+	}
+	return def
+}
+
+// Number returns a numeric flag value, or def when the flag is unset or
+// not a number
+func (f *Flags) Number(key string, def float64) float64 {
+	raw := f.String(key, "")
+	if raw == "" {
+		return def
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		f.logger.WithFields(logrus.Fields{
+			"flag":  key,
+			"value": raw,
+		}).Warn("Invalid numeric feature flag, using default")
+		return def
+	}
+	return value
+}
+
+// SetValue sets a non-boolean flag (for testing/admin purposes)
+func (f *Flags) SetValue(key, value string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.setValueLocked(key, value)
+}
+
+func (f *Flags) setValueLocked(key, value string) {
+	if old, ok := f.values[key]; ok && old == value {
+		return
+	}
+	f.values[key] = value
+	f.logger.WithField(key, value).Info("Feature flag updated")
+}
+
+// LoadFile applies the flags in a JSON file of flag keys to values, e.g.
+// {"claims.autoApproval": true, "claims.autoApprovalThreshold": 2500}.
+// Keys missing from the file keep their current values.
+func (f *Flags) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read flags file: %w", err)
+	}
+
+	var file map[string]interface{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse flags file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for key, raw := range file {
+		switch value := raw.(type) {
+		case bool:
+			if key == "claims.autoApproval" {
+				if f.autoApproval != value {
+					f.autoApproval = value
+					f.logger.WithField("autoApproval", value).Info("Feature flag updated")
+				}
+				continue
+			}
+			f.setValueLocked(key, strconv.FormatBool(value))
+		case float64:
+			f.setValueLocked(key, strconv.FormatFloat(value, 'f', -1, 64))
+		case string:
+			f.setValueLocked(key, value)
+		default:
+			f.logger.WithField("flag", key).Warn("Unsupported feature flag value in flags file, ignoring")
+		}
+	}
+	return nil
+}
+
+// watchFile loads the flags file and keeps reloading it whenever it
+// changes, until Shutdown
+func (f *Flags) watchFile(path string, interval time.Duration) {
+	var lastMod time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastMod = info.ModTime()
+	}
+	if err := f.LoadFile(path); err != nil {
+		f.logger.WithError(err).Warn("Failed to load feature flags file, using environment values")
+	}
+
+	f.stopRefresh = make(chan struct{})
+	go f.refreshFromFile(path, lastMod, interval, f.stopRefresh)
+}
+
+// refreshFromFile reloads the flags file when its modification time moves
+// past lastMod, until stop is closed
+func (f *Flags) refreshFromFile(path string, lastMod time.Time, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(path)
+			if err != nil || !info.ModTime().After(lastMod) {
+				continue
+			}
+			lastMod = info.ModTime()
+			if err := f.LoadFile(path); err != nil {
+				f.logger.WithError(err).Warn("Failed to refresh feature flags, keeping current values")
+			}
+		}
+	}
+}
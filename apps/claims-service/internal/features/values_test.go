@@ -0,0 +1,72 @@
+package features
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFlagsFileRefresh(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	path := filepath.Join(t.TempDir(), "flags.json")
+	write := func(content string, mod time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write flags file: %v", err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatalf("Failed to set flags file time: %v", err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write(`{"claims.autoApproval": true, "claims.autoApprovalThreshold": 2500}`, start)
+
+	t.Setenv("FEATURE_AUTO_APPROVAL", "false")
+	t.Setenv("FEATURE_AUTO_APPROVAL_THRESHOLD", "750")
+	t.Setenv("FEATURE_FLAGS_FILE", path)
+	t.Setenv("FEATURE_REFRESH_INTERVAL", "10ms")
+	flags, err := Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	t.Cleanup(Shutdown)
+
+	if !flags.IsAutoApprovalEnabled() || flags.AutoApprovalThreshold() != 2500 {
+		t.Fatalf("File did not override environment: enabled=%v threshold=%v", flags.IsAutoApprovalEnabled(), flags.AutoApprovalThreshold())
+	}
+
+	write(`{"claims.autoApprovalThreshold": "500"}`, start.Add(time.Minute))
+	deadline := time.Now().Add(2 * time.Second)
+	for flags.AutoApprovalThreshold() != 500 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Threshold not refreshed: got %v, want 500", flags.AutoApprovalThreshold())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !flags.IsAutoApprovalEnabled() {
+		t.Error("Flag missing from the refreshed file should keep its value")
+	}
+}
+
+func TestNumberFallsBackToDefault(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	flags := &Flags{values: map[string]string{}, logger: logger}
+
+	for _, value := range []string{"", "abc", "-10"} {
+		flags.SetValue(KeyAutoApprovalThreshold, value)
+		if got := flags.AutoApprovalThreshold(); got != DefaultAutoApprovalThreshold {
+			t.Errorf("Threshold for %q: got %v, want default", value, got)
+		}
+	}
+
+	var unset *Flags
+	if got := unset.AutoApprovalThreshold(); got != DefaultAutoApprovalThreshold {
+		t.Errorf("Threshold on nil flags: got %v, want default", got)
+	}
+}
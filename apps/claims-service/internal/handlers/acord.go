@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// AcordService exports claims as ACORD documents.
+// *services.AcordService is the production implementation.
+type AcordService interface {
+	ExportClaim(claimID string, now time.Time) (*services.AcordExport, error)
+	ExportClaims(filters *models.ClaimFilters, now time.Time) *services.AcordExport
+}
+
+var _ AcordService = (*services.AcordService)(nil)
+
+// AcordHandler serves ACORD exports of claims to staff
+type AcordHandler struct {
+	service AcordService
+	logger  *logrus.Logger
+}
+
+// NewAcordHandler creates a new ACORD export handler
+func NewAcordHandler(service AcordService, logger *logrus.Logger) *AcordHandler {
+	return &AcordHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// acordRejectedResponse lists the claims that failed schema validation
+type acordRejectedResponse struct {
+	Error    string                  `json:"error"`
+	Rejected []models.AcordRejection `json:"rejected"`
+}
+
+// ExportClaim handles GET /claims/{id}/acord
+// Supports query parameters:
+// - format: xml (default) or edi
+func (h *AcordHandler) ExportClaim(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+	format, err := acordFormat(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	export, err := h.service.ExportClaim(claimID, time.Now())
+	if err != nil {
+		if err.Error() == "claim not found" {
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+			return
+		}
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to export claim")
+		h.respondError(w, http.StatusInternalServerError, "Failed to export claim")
+		return
+	}
+	if len(export.Rejected) > 0 {
+		h.respondJSON(w, http.StatusUnprocessableEntity, acordRejectedResponse{
+			Error:    "Claim does not pass ACORD schema validation",
+			Rejected: export.Rejected,
+		})
+		return
+	}
+
+	h.respondDocument(w, export, format, claimID)
+}
+
+// ExportClaims handles GET /exports/acord
+// Accepts the same filters as GET /claims, and:
+// - format: xml (default) or edi
+// - skipInvalid: true to export the valid claims and leave out the others
+func (h *AcordHandler) ExportClaims(w http.ResponseWriter, r *http.Request) {
+	format, err := acordFormat(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	filters := &models.ClaimFilters{
+		PolicyID:      query.Get("policyId"),
+		CustomerID:    query.Get("customerId"),
+		Status:        query.Get("status"),
+		Type:          query.Get("type"),
+		SubType:       query.Get("subType"),
+		CatastropheID: query.Get("catastropheId"),
+
+		IncludeArchived: query.Get("includeArchived") == "true",
+	}
+	if err := parseDateFilters(query, filters); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	export := h.service.ExportClaims(filters, time.Now())
+	if len(export.Rejected) > 0 {
+		if query.Get("skipInvalid") != "true" {
+			h.respondJSON(w, http.StatusUnprocessableEntity, acordRejectedResponse{
+				Error:    fmt.Sprintf("%d claims do not pass ACORD schema validation; pass skipInvalid=true to export the others", len(export.Rejected)),
+				Rejected: export.Rejected,
+			})
+			return
+		}
+		h.logger.WithField("rejected", len(export.Rejected)).Warn("Left claims failing ACORD schema validation out of the export")
+	}
+
+	w.Header().Set("X-Acord-Rejected", strconv.Itoa(len(export.Rejected)))
+	h.respondDocument(w, export, format, "claims-"+time.Now().UTC().Format("20060102"))
+}
+
+// acordFormat reads the format parameter
+func acordFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", models.AcordFormatXML:
+		return models.AcordFormatXML, nil
+	case models.AcordFormatEDI:
+		return format, nil
+	default:
+		return "", fmt.Errorf("format must be %s or %s", models.AcordFormatXML, models.AcordFormatEDI)
+	}
+}
+
+// respondDocument sends the export's document as an attachment named name
+func (h *AcordHandler) respondDocument(w http.ResponseWriter, export *services.AcordExport, format, name string) {
+	body, contentType := export.Document.EDI(), "application/edi-consent"
+	if format == models.AcordFormatXML {
+		var err error
+		if body, err = export.Document.XML(); err != nil {
+			h.logger.WithError(err).Error("Failed to encode ACORD document")
+			h.respondError(w, http.StatusInternalServerError, "Failed to encode ACORD document")
+			return
+		}
+		contentType = "application/xml"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-acord.%s"`, name, format))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body); err != nil {
+		h.logger.WithError(err).Error("Failed to write ACORD document")
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *AcordHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *AcordHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
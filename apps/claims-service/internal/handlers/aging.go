@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// AgingReporter builds the claims aging report.
+// *services.AgingService is the production implementation.
+type AgingReporter interface {
+	Report(now time.Time) *models.ClaimsAgingReport
+}
+
+var _ AgingReporter = (*services.AgingService)(nil)
+
+// AgingHandler serves the claims aging report
+type AgingHandler struct {
+	reporter AgingReporter
+	logger   *logrus.Logger
+}
+
+// NewAgingHandler creates a new claims aging report handler
+func NewAgingHandler(reporter AgingReporter, logger *logrus.Logger) *AgingHandler {
+	return &AgingHandler{
+		reporter: reporter,
+		logger:   logger,
+	}
+}
+
+// ServeHTTP handles GET /reports/claims-aging
+func (h *AgingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := h.reporter.Report(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// ClaimArchiver moves claims past retention to the archive.
+// *services.ArchiveService is the production implementation.
+type ClaimArchiver interface {
+	Archive(now time.Time) *models.ArchiveResult
+}
+
+var _ ClaimArchiver = (*services.ArchiveService)(nil)
+
+// ArchiveHandler runs the claim archival job on demand
+type ArchiveHandler struct {
+	archiver ClaimArchiver
+	logger   *logrus.Logger
+}
+
+// NewArchiveHandler creates a new claim archival handler
+func NewArchiveHandler(archiver ClaimArchiver, logger *logrus.Logger) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiver: archiver,
+		logger:   logger,
+	}
+}
+
+// ServeHTTP handles POST /admin/claims/archive
+func (h *ArchiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := h.archiver.Archive(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -2,54 +2,48 @@ package handlers
 
 import (
 	"encoding/json"
+	"math"
 	"net/http"
-	"os"
+	"strconv"
 	"time"
 
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// SessionManager signs users in and manages their sessions.
+// *services.SessionService is the production implementation.
+type SessionManager interface {
+	Login(username, password, device, ip string) (*services.Login, error)
+	VerifyLogin(challengeID, code string, recovery bool, device, ip string) (*services.Login, error)
+	LockedUntil(username string) time.Time
+	ChallengeUserID(challengeID string) (string, error)
+	Enroll(userID string) (*services.Enrollment, error)
+	ConfirmEnrollment(userID, code string) ([]string, error)
+	CompleteEnrollment(challengeID, code, device, ip string) (*services.Login, []string, error)
+	RegenerateRecoveryCodes(userID, code string) ([]string, error)
+	TwoFactorStatus(userID, role string) (*models.TwoFactorStatus, error)
+	Sessions(userID, currentID string) []*models.Session
+	Revoke(sessionID, userID, role string) error
+	LoginAttempts(username string) []models.LoginAttempt
+}
+
+var _ SessionManager = (*services.SessionService)(nil)
+
 // AuthHandler handles authentication requests
 type AuthHandler struct {
-	jwtManager *auth.JWTManager
-	logger     *logrus.Logger
-	// In production, these would come from a database
-	validUsername string
-	validPassword string
+	sessions SessionManager
+	logger   *logrus.Logger
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(logger *logrus.Logger) *AuthHandler {
-	// Get JWT secret from environment
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "dev-secret-key-change-in-production"
-		logger.Warn("JWT_SECRET not set, using default (not secure for production)")
-	}
-
-	// Get credentials from environment
-	username := os.Getenv("AUTH_USERNAME")
-	if username == "" {
-		username = "demo@insurancestack.com"
-	}
-
-	password := os.Getenv("AUTH_PASSWORD")
-	if password == "" {
-		password = "demo123"
-	}
-
-	// Hash the password for comparison
-	hashedPassword, err := auth.HashPassword(password)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to hash password")
-	}
-
+func NewAuthHandler(sessions SessionManager, logger *logrus.Logger) *AuthHandler {
 	return &AuthHandler{
-		jwtManager:    auth.NewJWTManager(jwtSecret, 24*time.Hour),
-		logger:        logger,
-		validUsername: username,
-		validPassword: hashedPassword,
+		sessions: sessions,
+		logger:   logger,
 	}
 }
 
@@ -63,9 +57,47 @@ type LoginRequest struct {
 type LoginResponse struct {
 	Token     string `json:"token"`
 	ExpiresIn int    `json:"expiresIn"` // seconds
+	SessionID string `json:"sessionId"`
 	User      User   `json:"user"`
 }
 
+// ChallengeResponse is the response to a correct password when the user
+// must also give a second factor
+type ChallengeResponse struct {
+	TwoFactorRequired  bool   `json:"twoFactorRequired"`
+	EnrollmentRequired bool   `json:"enrollmentRequired"`
+	Challenge          string `json:"challenge"`
+	ExpiresIn          int    `json:"expiresIn"` // seconds
+}
+
+// VerifyLoginRequest is the second factor of a sign-in: a code from the
+// authenticator app or one of the user's recovery codes
+type VerifyLoginRequest struct {
+	Challenge    string `json:"challenge"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// TwoFactorRequest carries a code from the authenticator app, and the
+// challenge of a sign-in enrolling before it gets a session
+type TwoFactorRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+// EnrollmentResponse is a TOTP secret to add to an authenticator app
+type EnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauthUrl"` // for the app to render as a QR code
+}
+
+// RecoveryCodesResponse shows recovery codes the one time they are issued.
+// Confirming an enrollment during sign-in also signs the user in.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+	*LoginResponse
+}
+
 // User represents basic user info
 type User struct {
 	ID    string `json:"id"`
@@ -73,53 +105,295 @@ type User struct {
 	Name  string `json:"name"`
 }
 
-// Login handles user login
+// Login handles POST /auth/login - signs a user in, starting a session for
+// the device. A user who must give a second factor gets a challenge instead,
+// for POST /auth/login/verify or, while they have to enroll, POST
+// /auth/2fa/enroll and /auth/2fa/confirm.
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode login request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request")
 		return
 	}
 
-	var req LoginRequest
+	login, err := h.sessions.Login(req.Username, req.Password, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		switch err.Error() {
+		case "invalid credentials":
+			h.respondError(w, http.StatusUnauthorized, "Invalid credentials")
+		case "account locked":
+			h.respondLocked(w, req.Username)
+		default:
+			h.logger.WithError(err).Error("Failed to sign in")
+			h.respondError(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	if login.Challenge != nil {
+		h.respondJSON(w, http.StatusOK, ChallengeResponse{
+			TwoFactorRequired:  true,
+			EnrollmentRequired: login.Challenge.EnrollmentRequired,
+			Challenge:          login.Challenge.ID,
+			ExpiresIn:          int(time.Until(login.Challenge.ExpiresAt).Seconds()),
+		})
+		return
+	}
+	h.respondJSON(w, http.StatusOK, loginResponse(login))
+}
+
+// VerifyLogin handles POST /auth/login/verify - finishes a sign-in with its
+// second factor, starting its session
+func (h *AuthHandler) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var req VerifyLoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.WithError(err).Error("Failed to decode login request")
-		http.Error(w, "Invalid request", http.StatusBadRequest)
+		h.respondError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Challenge == "" || (req.Code == "") == (req.RecoveryCode == "") {
+		h.respondError(w, http.StatusBadRequest, "challenge and one of code or recoveryCode are required")
+		return
+	}
+
+	code, recovery := req.Code, req.RecoveryCode != ""
+	if recovery {
+		code = req.RecoveryCode
+	}
+	login, err := h.sessions.VerifyLogin(req.Challenge, code, recovery, r.UserAgent(), middleware.ClientIP(r))
+	if err != nil {
+		h.respondTwoFactorError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, loginResponse(login))
+}
+
+// GetTwoFactor handles GET /auth/2fa - whether the signed-in user has
+// two-factor authentication on, whether their role requires it, and how many
+// recovery codes they have left
+func (h *AuthHandler) GetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	session := h.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	status, err := h.sessions.TwoFactorStatus(session.UserID, session.Role)
+	if err != nil {
+		h.respondTwoFactorError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, status)
+}
+
+// EnrollTwoFactor handles POST /auth/2fa/enroll - starts enrolling the
+// signed-in user, or the user of a sign-in challenge that requires it, with a
+// new TOTP secret
+func (h *AuthHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req TwoFactorRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid request")
+			return
+		}
+	}
+	userID := h.enrollingUser(w, r, req.Challenge)
+	if userID == "" {
 		return
 	}
+	enrollment, err := h.sessions.Enroll(userID)
+	if err != nil {
+		h.respondTwoFactorError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, EnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURL: enrollment.OTPAuthURL,
+	})
+}
 
-	// Validate credentials
-	if req.Username != h.validUsername {
-		h.logger.WithField("username", req.Username).Warn("Invalid username")
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+// ConfirmTwoFactor handles POST /auth/2fa/confirm - turns two-factor
+// authentication on with a code from the secret being enrolled and issues
+// recovery codes. Confirming with a sign-in challenge also signs in.
+func (h *AuthHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Code == "" {
+		h.respondError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	if req.Challenge != "" {
+		login, codes, err := h.sessions.CompleteEnrollment(req.Challenge, req.Code, r.UserAgent(), middleware.ClientIP(r))
+		if err != nil {
+			h.respondTwoFactorError(w, err)
+			return
+		}
+		response := loginResponse(login)
+		h.respondJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes, LoginResponse: &response})
 		return
 	}
 
-	if err := auth.VerifyPassword(h.validPassword, req.Password); err != nil {
-		h.logger.WithField("username", req.Username).Warn("Invalid password")
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+	session := h.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	codes, err := h.sessions.ConfirmEnrollment(session.UserID, req.Code)
+	if err != nil {
+		h.respondTwoFactorError(w, err)
 		return
 	}
+	h.respondJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
 
-	// Generate JWT token
-	token, err := h.jwtManager.Generate("user-001", req.Username)
+// RegenerateRecoveryCodes handles POST /auth/2fa/recovery-codes - replaces
+// the signed-in user's recovery codes, given a code from their authenticator
+// app
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	session := h.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	var req TwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request")
+		return
+	}
+	if req.Code == "" {
+		h.respondError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+	codes, err := h.sessions.RegenerateRecoveryCodes(session.UserID, req.Code)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to generate token")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondTwoFactorError(w, err)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// GetSessions handles GET /auth/sessions - the signed-in user's sessions,
+// marking the one making the request as current
+func (h *AuthHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	session := h.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"sessions": h.sessions.Sessions(session.UserID, session.ID),
+	})
+}
+
+// RevokeSession handles DELETE /auth/sessions/{id} - signs a session out,
+// such as one on a lost device. Admins may end anyone's session.
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	session := h.requireSession(w, r)
+	if session == nil {
+		return
+	}
+	if err := h.sessions.Revoke(mux.Vars(r)["id"], session.UserID, session.Role); err != nil {
+		h.respondError(w, http.StatusNotFound, "Session not found")
 		return
 	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetLoginAttempts handles GET /admin/auth/login-attempts - the failed
+// sign-ins of the login audit, newest first
+// Supports query parameters:
+// - username: only the attempts for this username
+func (h *AuthHandler) GetLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, map[string]interface{}{
+		"attempts": h.sessions.LoginAttempts(r.URL.Query().Get("username")),
+	})
+}
+
+// enrollingUser returns the user enrolling: the user of the sign-in
+// challenge when one is given, else the signed-in user. It responds and
+// returns "" when there is neither.
+func (h *AuthHandler) enrollingUser(w http.ResponseWriter, r *http.Request, challenge string) string {
+	if challenge != "" {
+		userID, err := h.sessions.ChallengeUserID(challenge)
+		if err != nil {
+			h.respondTwoFactorError(w, err)
+			return ""
+		}
+		return userID
+	}
+	if session := h.requireSession(w, r); session != nil {
+		return session.UserID
+	}
+	return ""
+}
 
-	response := LoginResponse{
-		Token:     token,
-		ExpiresIn: 86400, // 24 hours in seconds
+// loginResponse is the response to a finished sign-in
+func loginResponse(login *services.Login) LoginResponse {
+	return LoginResponse{
+		Token:     login.Token,
+		ExpiresIn: int(time.Until(login.Session.ExpiresAt).Seconds()),
+		SessionID: login.Session.ID,
 		User: User{
-			ID:    "user-001",
-			Email: req.Username,
-			Name:  "Demo User",
+			ID:    login.Session.UserID,
+			Email: login.Session.Email,
+			Name:  login.Name,
 		},
 	}
+}
 
+// respondLocked refuses a sign-in for a locked-out username, saying when
+// to retry
+func (h *AuthHandler) respondLocked(w http.ResponseWriter, username string) {
+	if until := h.sessions.LockedUntil(username); !until.IsZero() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(until).Seconds()))))
+	}
+	h.respondError(w, http.StatusTooManyRequests, "Too many failed sign-ins, try again later")
+}
+
+// respondTwoFactorError maps the session service's second-factor errors to
+// responses
+func (h *AuthHandler) respondTwoFactorError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "login challenge not found":
+		h.respondError(w, http.StatusUnauthorized, "Sign-in expired, sign in again")
+	case "invalid code":
+		h.respondError(w, http.StatusUnauthorized, "Invalid code")
+	case "account locked":
+		// The challenge does not say which username it is for
+		h.respondError(w, http.StatusTooManyRequests, "Too many failed sign-ins, try again later")
+	case "two-factor enrollment required":
+		h.respondError(w, http.StatusForbidden, "Two-factor enrollment required, enroll with POST /auth/2fa/enroll")
+	case "two-factor authentication already enabled":
+		h.respondError(w, http.StatusConflict, "Two-factor authentication is already enabled")
+	case "no two-factor enrollment in progress":
+		h.respondError(w, http.StatusConflict, "No enrollment in progress, start one with POST /auth/2fa/enroll")
+	case "two-factor authentication not enabled":
+		h.respondError(w, http.StatusConflict, "Two-factor authentication is not enabled")
+	default:
+		h.logger.WithError(err).Error("Failed two-factor request")
+		h.respondError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// requireSession returns the request's session, responding 401 and
+// returning nil when its token was not issued by POST /auth/login
+func (h *AuthHandler) requireSession(w http.ResponseWriter, r *http.Request) *models.Session {
+	session := middleware.GetSession(r)
+	if session == nil {
+		h.respondError(w, http.StatusUnauthorized, "Sign in with POST /auth/login first")
+	}
+	return session
+}
+
+// respondJSON sends a JSON response
+func (h *AuthHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
 
-	h.logger.WithField("username", req.Username).Info("User logged in successfully")
+// respondError sends an error response
+func (h *AuthHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
 }
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateCatastrophe handles POST /catastrophes
+func (h *ClaimHandler) CreateCatastrophe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	var req models.CreateCatastropheRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	cat, tagged, err := h.service.CreateCatastrophe(&req)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create catastrophe")
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"catastropheId": cat.ID,
+		"taggedClaims":  tagged,
+		"userId":        userID,
+	}).Info("Catastrophe declared via API")
+
+	h.respondJSON(w, http.StatusCreated, map[string]interface{}{
+		"catastrophe":  cat,
+		"taggedClaims": tagged,
+	})
+}
+
+// GetCatastrophes handles GET /catastrophes
+func (h *ClaimHandler) GetCatastrophes(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, h.service.GetCatastrophes())
+}
+
+// GetCatastropheByID handles GET /catastrophes/{id}
+func (h *ClaimHandler) GetCatastropheByID(w http.ResponseWriter, r *http.Request) {
+	catastropheID := mux.Vars(r)["id"]
+
+	cat, err := h.service.GetCatastropheByID(catastropheID)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "Catastrophe not found")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, cat)
+}
+
+// GetCatastropheExposure handles GET /catastrophes/{id}/exposure
+func (h *ClaimHandler) GetCatastropheExposure(w http.ResponseWriter, r *http.Request) {
+	catastropheID := mux.Vars(r)["id"]
+
+	exposure, err := h.service.GetCatastropheExposure(catastropheID)
+	if err != nil {
+		h.logger.WithError(err).WithField("catastropheId", catastropheID).Error("Failed to compute catastrophe exposure")
+		if err.Error() == "catastrophe not found" {
+			h.respondError(w, http.StatusNotFound, "Catastrophe not found")
+			return
+		}
+		h.respondError(w, http.StatusInternalServerError, "Failed to compute exposure")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, exposure)
+}
+
+// TagClaimCatastrophe handles PUT /claims/{id}/catastrophe
+func (h *ClaimHandler) TagClaimCatastrophe(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	claimID := mux.Vars(r)["id"]
+
+	var req models.TagClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	claim, err := h.service.TagClaimCatastrophe(claimID, &req)
+	if err != nil {
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to tag claim")
+		switch err.Error() {
+		case "claim not found":
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+		case "catastrophe not found":
+			h.respondError(w, http.StatusBadRequest, "Catastrophe not found")
+		default:
+			h.respondError(w, http.StatusInternalServerError, "Failed to tag claim")
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"claimId":       claim.ID,
+		"catastropheId": claim.CatastropheID,
+		"userId":        userID,
+	}).Info("Claim catastrophe tag set via API")
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claim))
+}
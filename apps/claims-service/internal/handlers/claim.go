@@ -1,36 +1,75 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// ClaimService is the business logic the claim handlers depend on.
+// *services.ClaimService is the production implementation.
+type ClaimService interface {
+	GetClaimByID(claimID string, viewer models.ClaimViewer) (*models.Claim, error)
+	GetClaimIncludingArchived(claimID string, viewer models.ClaimViewer) (*models.Claim, error)
+	GetClaims(filters *models.ClaimFilters, viewer models.ClaimViewer) ([]*models.Claim, error)
+	GetClaimStats(filters *models.ClaimFilters) (*models.ClaimStats, error)
+	CreateClaim(ctx context.Context, req *models.CreateClaimRequest) (*models.Claim, error)
+	UpdateClaim(claimID string, req *models.UpdateClaimRequest) (*models.Claim, error)
+	UpdateClaimStatus(claimID string, req *models.UpdateClaimStatusRequest) (*models.Claim, error)
+	BulkUpdateClaimStatus(updates []models.BulkStatusUpdate) (*models.BulkStatusResponse, error)
+	AssignClaim(claimID string, req *models.AssignClaimRequest) (*models.Claim, error)
+	EscalateClaim(claimID string, req *models.EscalateClaimRequest) (*models.Claim, error)
+	CreateCatastrophe(req *models.CreateCatastropheRequest) (*models.Catastrophe, int, error)
+	GetCatastrophes() []*models.Catastrophe
+	GetCatastropheByID(catastropheID string) (*models.Catastrophe, error)
+	GetCatastropheExposure(catastropheID string) (*models.CatastropheExposure, error)
+	TagClaimCatastrophe(claimID string, req *models.TagClaimRequest) (*models.Claim, error)
+	UploadDocument(claimID, uploadedBy, fileName, contentType string, content []byte) (*models.ClaimDocument, error)
+	GetDocuments(claimID string) ([]*models.ClaimDocument, error)
+	GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error)
+	GetDocumentLink(claimID, documentID string) (*models.DocumentLink, error)
+	GetClaimTypes(policyType string) (*models.ClaimTypesResponse, error)
+}
+
+var _ ClaimService = (*services.ClaimService)(nil)
+
 // ClaimHandler handles claim-related HTTP requests
 type ClaimHandler struct {
-	service *services.ClaimService
+	service ClaimService
 	logger  *logrus.Logger
 }
 
 // NewClaimHandler creates a new claim handler
-func NewClaimHandler(service *services.ClaimService, logger *logrus.Logger) *ClaimHandler {
+func NewClaimHandler(service ClaimService, logger *logrus.Logger) *ClaimHandler {
 	return &ClaimHandler{
 		service: service,
 		logger:  logger,
 	}
 }
 
-// GetClaims handles GET /claims
+// GetClaims handles GET /claims. Customers get the claims on their own
+// policies; staff get every claim.
 // Supports query parameters:
 // - policyId: filter by policy ID
 // - customerId: filter by customer ID
-// - status: filter by status (submitted/under_review/approved/rejected)
-// - type: filter by type (accident/theft/damage)
+// - status: filter by status (submitted/under_review/pending_payment/approved/rejected)
+// - type: filter by claim type (see GET /claim-types)
+// - subType: filter by claim sub-type
+// - catastropheId: filter by catastrophe event
+// - incidentFrom, incidentTo: incident date range (YYYY-MM-DD or RFC 3339, inclusive)
+// - submittedFrom, submittedTo: submission date range (same formats)
+// - includeArchived: true to also return archived claims (staff only)
 func (h *ClaimHandler) GetClaims(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserID(r)
@@ -44,24 +83,67 @@ func (h *ClaimHandler) GetClaims(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
 	filters := &models.ClaimFilters{
-		PolicyID:   query.Get("policyId"),
-		CustomerID: query.Get("customerId"),
-		Status:     query.Get("status"),
-		Type:       query.Get("type"),
+		PolicyID:      query.Get("policyId"),
+		CustomerID:    query.Get("customerId"),
+		Status:        query.Get("status"),
+		Type:          query.Get("type"),
+		SubType:       query.Get("subType"),
+		CatastropheID: query.Get("catastropheId"),
+
+		IncludeArchived: query.Get("includeArchived") == "true",
+	}
+	if err := parseDateFilters(query, filters); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	// Get claims with filters
-	claims, err := h.service.GetClaims(filters)
+	claims, err := h.service.GetClaims(filters, claimViewer(r))
 	if err != nil {
+		if err.Error() == "unauthorized" {
+			h.respondError(w, http.StatusForbidden, "You do not have access to this customer's claims")
+			return
+		}
+		if err.Error() == "archived claims are only available to staff" {
+			h.respondError(w, http.StatusForbidden, "Archived claims are only available to staff")
+			return
+		}
 		h.logger.WithError(err).Error("Failed to retrieve claims")
 		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve claims")
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, claims)
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claims))
+}
+
+// GetClaimStats handles GET /claims/stats
+// Accepts the same filters as GET /claims except status
+func (h *ClaimHandler) GetClaimStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters := &models.ClaimFilters{
+		PolicyID:   query.Get("policyId"),
+		CustomerID: query.Get("customerId"),
+		Type:       query.Get("type"),
+	}
+	if err := parseDateFilters(query, filters); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := h.service.GetClaimStats(filters)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to compute claim stats")
+		h.respondError(w, http.StatusInternalServerError, "Failed to compute claim stats")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, stats)
 }
 
-// GetClaimByID handles GET /claims/{id}
+// GetClaimByID handles GET /claims/{id}. Customers may only read claims on
+// their own policies. With includeArchived=true staff may also read claims
+// that were moved to the archive.
 func (h *ClaimHandler) GetClaimByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	claimID := vars["id"]
@@ -71,14 +153,28 @@ func (h *ClaimHandler) GetClaimByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claim, err := h.service.GetClaimByID(claimID)
+	var claim *models.Claim
+	var err error
+	if r.URL.Query().Get("includeArchived") == "true" {
+		claim, err = h.service.GetClaimIncludingArchived(claimID, claimViewer(r))
+	} else {
+		claim, err = h.service.GetClaimByID(claimID, claimViewer(r))
+	}
 	if err != nil {
+		if err.Error() == "unauthorized" {
+			h.respondError(w, http.StatusForbidden, "You do not have access to this claim")
+			return
+		}
+		if err.Error() == "archived claims are only available to staff" {
+			h.respondError(w, http.StatusForbidden, "Archived claims are only available to staff")
+			return
+		}
 		h.logger.WithError(err).WithField("claimId", claimID).Warn("Claim not found")
 		h.respondError(w, http.StatusNotFound, "Claim not found")
 		return
 	}
 
-	h.respondJSON(w, http.StatusOK, claim)
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claim))
 }
 
 // CreateClaim handles POST /claims
@@ -94,7 +190,8 @@ func (h *ClaimHandler) CreateClaim(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateClaimRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Warn("Invalid request body")
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
 		return
 	}
 
@@ -120,8 +217,29 @@ func (h *ClaimHandler) CreateClaim(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claim, err := h.service.CreateClaim(&req)
+	// force may also be given as a query parameter
+	if r.URL.Query().Get("force") == "true" {
+		req.Force = true
+	}
+
+	claim, err := h.service.CreateClaim(r.Context(), &req)
 	if err != nil {
+		var duplicate *services.DuplicateClaimError
+		if errors.As(err, &duplicate) {
+			h.respondJSON(w, http.StatusConflict, map[string]interface{}{
+				"error":               err.Error(),
+				"existingClaimId":     duplicate.Existing.ID,
+				"existingClaimNumber": duplicate.Existing.ClaimNumber,
+				"existingStatus":      duplicate.Existing.Status,
+				"hint":                "resubmit with force=true if this is a separate incident",
+			})
+			return
+		}
+		if err.Error() == "policy-service unavailable" {
+			h.logger.WithError(err).Error("Failed to create claim")
+			h.respondError(w, http.StatusServiceUnavailable, "Policy service unavailable")
+			return
+		}
 		h.logger.WithError(err).Error("Failed to create claim")
 		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
@@ -141,6 +259,9 @@ func (h *ClaimHandler) CreateClaim(w http.ResponseWriter, r *http.Request) {
 		"status":      claim.Status,
 		"message":     "Claim submitted successfully",
 	}
+	if claim.DuplicateOf != "" {
+		response["duplicateOf"] = claim.DuplicateOf
+	}
 
 	h.respondJSON(w, http.StatusCreated, response)
 }
@@ -166,7 +287,8 @@ func (h *ClaimHandler) UpdateClaim(w http.ResponseWriter, r *http.Request) {
 	var req models.UpdateClaimRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Warn("Invalid request body")
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
 		return
 	}
 
@@ -182,7 +304,7 @@ func (h *ClaimHandler) UpdateClaim(w http.ResponseWriter, r *http.Request) {
 		"userId":  userID,
 	}).Info("Claim updated via API")
 
-	h.respondJSON(w, http.StatusOK, claim)
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claim))
 }
 
 // UpdateClaimStatus handles PUT /claims/{id}/status
@@ -206,7 +328,8 @@ func (h *ClaimHandler) UpdateClaimStatus(w http.ResponseWriter, r *http.Request)
 	var req models.UpdateClaimStatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Warn("Invalid request body")
-		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
 		return
 	}
 
@@ -219,7 +342,11 @@ func (h *ClaimHandler) UpdateClaimStatus(w http.ResponseWriter, r *http.Request)
 	claim, err := h.service.UpdateClaimStatus(claimID, &req)
 	if err != nil {
 		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to update claim status")
-		h.respondError(w, http.StatusBadRequest, err.Error())
+		status := http.StatusBadRequest
+		if err.Error() == "claim version conflict" {
+			status = http.StatusConflict
+		}
+		h.respondError(w, status, err.Error())
 		return
 	}
 
@@ -229,7 +356,158 @@ func (h *ClaimHandler) UpdateClaimStatus(w http.ResponseWriter, r *http.Request)
 		"userId":    userID,
 	}).Info("Claim status updated via API")
 
-	h.respondJSON(w, http.StatusOK, claim)
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claim))
+}
+
+// BulkUpdateClaimStatus handles POST /claims/status/bulk. The body is a
+// list of {claimId, version, status, notes, rejectionCodes} changes; each
+// is applied on its own and reported in the results, so the response is
+// 200 even when some changes fail.
+func (h *ClaimHandler) BulkUpdateClaimStatus(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		h.logger.Warn("User ID not found in context")
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var updates []models.BulkStatusUpdate
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	response, err := h.service.BulkUpdateClaimStatus(updates)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"succeeded": response.Succeeded,
+		"failed":    response.Failed,
+		"userId":    userID,
+	}).Info("Bulk claim status update via API")
+
+	h.respondJSON(w, http.StatusOK, response)
+}
+
+// AssignClaim handles PUT /claims/{id}/assignment
+func (h *ClaimHandler) AssignClaim(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	claimID := mux.Vars(r)["id"]
+
+	var req models.AssignClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	if req.AdjusterID == "" {
+		h.respondError(w, http.StatusBadRequest, "adjusterId is required")
+		return
+	}
+
+	claim, err := h.service.AssignClaim(claimID, &req)
+	if err != nil {
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to assign claim")
+		if err.Error() == "claim not found" {
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"claimId":    claim.ID,
+		"adjusterId": claim.AssignedTo,
+		"userId":     userID,
+	}).Info("Claim assigned via API")
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claim))
+}
+
+// claimViewer identifies who is reading claims: staff by their verified
+// token, everyone else as the customer named by X-User-ID
+func claimViewer(r *http.Request) models.ClaimViewer {
+	return models.ClaimViewer{ID: middleware.GetUserID(r), Role: middleware.GetRole(r)}
+}
+
+// parseDateFilters reads the incident and submission date range parameters.
+// A date-only upper bound covers the whole day.
+func parseDateFilters(query url.Values, filters *models.ClaimFilters) error {
+	bounds := []struct {
+		param string
+		dest  *time.Time
+		end   bool
+	}{
+		{"incidentFrom", &filters.IncidentFrom, false},
+		{"incidentTo", &filters.IncidentTo, true},
+		{"submittedFrom", &filters.SubmittedFrom, false},
+		{"submittedTo", &filters.SubmittedTo, true},
+	}
+
+	for _, b := range bounds {
+		value := query.Get(b.param)
+		if value == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			*b.dest = t
+			continue
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp", b.param)
+		}
+		if b.end {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		*b.dest = t
+	}
+	return nil
+}
+
+// EscalateClaim handles POST /claims/{id}/escalate
+func (h *ClaimHandler) EscalateClaim(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	claimID := mux.Vars(r)["id"]
+
+	var req models.EscalateClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	if req.Reason == "" {
+		h.respondError(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	claim, err := h.service.EscalateClaim(claimID, &req)
+	if err != nil {
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to escalate claim")
+		if err.Error() == "claim not found" {
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"claimId": claim.ID,
+		"userId":  userID,
+	}).Info("Claim escalated via API")
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, claim))
 }
 
 // respondJSON sends a JSON response
@@ -241,6 +519,15 @@ func (h *ClaimHandler) respondJSON(w http.ResponseWriter, status int, data inter
 	}
 }
 
+// decodeError maps a failure to decode a request body to a response. A
+// body cut off at the route's size limit gets 413.
+func decodeError(err error) (int, string) {
+	if limit, ok := middleware.BodyTooLarge(err); ok {
+		return http.StatusRequestEntityTooLarge, middleware.TooLargeMessage(limit)
+	}
+	return http.StatusBadRequest, "Invalid request body"
+}
+
 // respondError sends an error response
 func (h *ClaimHandler) respondError(w http.ResponseWriter, status int, message string) {
 	h.respondJSON(w, status, map[string]string{"error": message})
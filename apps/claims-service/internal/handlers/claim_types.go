@@ -0,0 +1,15 @@
+package handlers
+
+import "net/http"
+
+// GetClaimTypes handles GET /claim-types, the claim types and sub-types
+// each policy type accepts. The policyType query parameter narrows it to
+// one policy type.
+func (h *ClaimHandler) GetClaimTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.service.GetClaimTypes(r.URL.Query().Get("policyType"))
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.respondJSON(w, http.StatusOK, types)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// CommentService is the business logic the comment handlers depend on.
+// *services.CommentService is the production implementation.
+type CommentService interface {
+	AddComment(claimID string, author models.CommentAuthor, req *models.CreateCommentRequest) (*models.Comment, error)
+	GetComments(claimID string, author models.CommentAuthor) ([]*models.Comment, error)
+	UpdateComment(claimID, commentID string, author models.CommentAuthor, req *models.UpdateCommentRequest) (*models.Comment, error)
+}
+
+var _ CommentService = (*services.CommentService)(nil)
+
+// CommentHandler handles comment threads on claims. Routes must be wrapped
+// in middleware.IdentifyRole so staff can be told apart from customers.
+type CommentHandler struct {
+	service CommentService
+	logger  *logrus.Logger
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(service CommentService, logger *logrus.Logger) *CommentHandler {
+	return &CommentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetComments handles GET /claims/{id}/comments
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	comments, err := h.service.GetComments(claimID, commentAuthor(r))
+	if err != nil {
+		h.respondServiceError(w, claimID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, comments)
+}
+
+// AddComment handles POST /claims/{id}/comments
+func (h *CommentHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	comment, err := h.service.AddComment(claimID, commentAuthor(r), &req)
+	if err != nil {
+		h.respondServiceError(w, claimID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment handles PUT /claims/{id}/comments/{commentId}
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	claimID := vars["id"]
+
+	var req models.UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	comment, err := h.service.UpdateComment(claimID, vars["commentId"], commentAuthor(r), &req)
+	if err != nil {
+		h.respondServiceError(w, claimID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, comment)
+}
+
+// commentAuthor identifies who is reading or writing comments: staff by
+// their verified token, everyone else as a customer
+func commentAuthor(r *http.Request) models.CommentAuthor {
+	role := middleware.GetRole(r)
+	if role != "admin" && role != "adjuster" {
+		role = models.CommentRoleCustomer
+	}
+	return models.CommentAuthor{ID: middleware.GetUserID(r), Role: role}
+}
+
+// respondServiceError maps comment service errors to HTTP statuses
+func (h *CommentHandler) respondServiceError(w http.ResponseWriter, claimID string, err error) {
+	switch {
+	case err.Error() == "claim not found":
+		h.respondError(w, http.StatusNotFound, "Claim not found")
+	case err.Error() == "comment not found":
+		h.respondError(w, http.StatusNotFound, "Comment not found")
+	case err.Error() == "unauthorized":
+		h.respondError(w, http.StatusForbidden, "You do not have access to this comment")
+	case err.Error() == "only staff can write internal comments":
+		h.respondError(w, http.StatusForbidden, err.Error())
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to save comment")
+		h.respondError(w, http.StatusInternalServerError, "Failed to save comment")
+	default:
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *CommentHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *CommentHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
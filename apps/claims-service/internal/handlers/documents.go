@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// maxUploadBody allows for the multipart framing around a document of the
+// maximum size
+const maxUploadBody = models.MaxDocumentSize + 1<<20
+
+// UploadDocument handles POST /claims/{id}/documents. The document is sent
+// as the "file" part of a multipart/form-data body.
+func (h *ClaimHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		h.logger.Warn("User ID not found in context")
+		h.respondError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBody)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondError(w, http.StatusRequestEntityTooLarge, "document exceeds the upload limit")
+			return
+		}
+		h.logger.WithError(err).Warn("Invalid document upload")
+		h.respondError(w, http.StatusBadRequest, "multipart form with a file part is required")
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, models.MaxDocumentSize+1))
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to read uploaded document")
+		h.respondError(w, http.StatusBadRequest, "Failed to read document")
+		return
+	}
+
+	doc, err := h.service.UploadDocument(claimID, userID, header.Filename, header.Header.Get("Content-Type"), content)
+	if err != nil {
+		if err.Error() == "claim not found" {
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+			return
+		}
+		h.logger.WithError(err).WithField("claimId", claimID).Warn("Failed to upload document")
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"claimId":    claimID,
+		"documentId": doc.ID,
+		"userId":     userID,
+	}).Info("Claim document uploaded via API")
+
+	h.respondJSON(w, http.StatusCreated, doc)
+}
+
+// GetDocuments handles GET /claims/{id}/documents
+func (h *ClaimHandler) GetDocuments(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	docs, err := h.service.GetDocuments(claimID)
+	if err != nil {
+		h.logger.WithError(err).WithField("claimId", claimID).Warn("Claim not found")
+		h.respondError(w, http.StatusNotFound, "Claim not found")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, docs)
+}
+
+// GetDocument handles GET /claims/{id}/documents/{documentId} and returns
+// the document content, once it has been scanned clean
+func (h *ClaimHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	claimID, documentID := vars["id"], vars["documentId"]
+
+	doc, content, err := h.service.GetDocument(claimID, documentID)
+	if err != nil {
+		switch err.Error() {
+		case "document has not been scanned":
+			w.Header().Set("Retry-After", "5")
+			h.respondError(w, http.StatusConflict, "Document is still being scanned for malware")
+			return
+		case "document is quarantined":
+			h.respondError(w, http.StatusForbidden, "Document failed its malware scan and is quarantined")
+			return
+		}
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"claimId":    claimID,
+			"documentId": documentID,
+		}).Warn("Document not found")
+		h.respondError(w, http.StatusNotFound, "Document not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(content); err != nil {
+		h.logger.WithError(err).WithField("documentId", documentID).Error("Failed to write document")
+	}
+}
+
+// GetDocumentLink handles GET /claims/{id}/documents/{documentId}/link and
+// returns a signed URL that downloads the document without credentials
+func (h *ClaimHandler) GetDocumentLink(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	claimID, documentID := vars["id"], vars["documentId"]
+
+	link, err := h.service.GetDocumentLink(claimID, documentID)
+	if err != nil {
+		switch err.Error() {
+		case "document not found":
+			h.respondError(w, http.StatusNotFound, "Document not found")
+		case "document has not been scanned":
+			w.Header().Set("Retry-After", "5")
+			h.respondError(w, http.StatusConflict, "Document is still being scanned for malware")
+		case "document is quarantined":
+			h.respondError(w, http.StatusForbidden, "Document failed its malware scan and is quarantined")
+		case "document links need a blob store":
+			h.respondError(w, http.StatusNotImplemented, "Document links need BLOBSTORE_BACKEND to be set")
+		default:
+			h.logger.WithError(err).WithFields(logrus.Fields{
+				"claimId":    claimID,
+				"documentId": documentID,
+			}).Error("Failed to sign document link")
+			h.respondError(w, http.StatusInternalServerError, "Failed to sign document link")
+		}
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, link)
+}
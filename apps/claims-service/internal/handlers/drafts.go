@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HeaderIntakeToken carries the shared secret on inbound claim emails.
+// Providers that cannot set headers may send it as the basic auth password
+// instead.
+const HeaderIntakeToken = "X-Intake-Token"
+
+// Form fields email providers post the raw message in when they send
+// multipart/form-data rather than message/rfc822
+var rawEmailFields = []string{"body-mime", "email"}
+
+// DraftService is the business logic the draft handlers depend on.
+// *services.DraftService is the production implementation.
+type DraftService interface {
+	IngestEmail(raw io.Reader) (*models.ClaimDraft, bool, error)
+	GetDrafts(status string) ([]*models.ClaimDraft, error)
+	GetDraft(draftID string) (*models.ClaimDraft, error)
+	GetDraftAttachment(draftID, attachmentID string) (*models.DraftAttachment, []byte, error)
+	ConfirmDraft(ctx context.Context, draftID, reviewedBy string, req *models.ConfirmDraftRequest) (*models.ClaimDraft, *models.Claim, error)
+	DiscardDraft(draftID, reviewedBy string, req *models.DiscardDraftRequest) (*models.ClaimDraft, error)
+}
+
+var _ DraftService = (*services.DraftService)(nil)
+
+// DraftHandler accepts claim emails from the inbound email webhook and
+// serves the draft queue agents confirm them from. Review routes must be
+// wrapped in middleware.IdentifyRole so reviews are attributed to the
+// agent's token.
+type DraftHandler struct {
+	service     DraftService
+	intakeToken string
+	logger      *logrus.Logger
+}
+
+// NewDraftHandler creates a new draft handler. Inbound emails must carry
+// intakeToken.
+func NewDraftHandler(service DraftService, intakeToken string, logger *logrus.Logger) *DraftHandler {
+	return &DraftHandler{
+		service:     service,
+		intakeToken: intakeToken,
+		logger:      logger,
+	}
+}
+
+// IngestEmail handles POST /intake/email. The body is the raw message,
+// either as is or in the body-mime (Mailgun) or email (SendGrid) field of
+// a form. A new draft returns 202; a redelivered message returns 200 with
+// the draft it already created.
+func (h *DraftHandler) IngestEmail(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		h.logger.WithField("remoteAddr", r.RemoteAddr).Warn("Rejected inbound email with invalid intake token")
+		h.respondError(w, http.StatusUnauthorized, "Invalid intake token")
+		return
+	}
+
+	raw, err := rawEmail(r)
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid inbound email request")
+		status, message := decodeError(err)
+		if status == http.StatusBadRequest {
+			message = "raw email is required"
+		}
+		h.respondError(w, status, message)
+		return
+	}
+
+	draft, created, err := h.service.IngestEmail(raw)
+	if err != nil {
+		if limit, ok := middleware.BodyTooLarge(err); ok {
+			h.respondError(w, http.StatusRequestEntityTooLarge, middleware.TooLargeMessage(limit))
+			return
+		}
+		if strings.HasPrefix(err.Error(), "failed to") {
+			h.logger.WithError(err).Error("Failed to queue claim email")
+			h.respondError(w, http.StatusInternalServerError, "Failed to queue claim email")
+			return
+		}
+		h.logger.WithError(err).Warn("Rejected unreadable claim email")
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := http.StatusAccepted
+	if !created {
+		status = http.StatusOK
+	}
+	h.respondJSON(w, status, draft)
+}
+
+// GetDrafts handles GET /admin/claims/drafts. The status query parameter
+// defaults to draft, the drafts waiting for review.
+func (h *DraftHandler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = models.DraftPending
+	}
+
+	drafts, err := h.service.GetDrafts(status)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, drafts)
+}
+
+// GetDraft handles GET /admin/claims/drafts/{id}
+func (h *DraftHandler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	draftID := mux.Vars(r)["id"]
+
+	draft, err := h.service.GetDraft(draftID)
+	if err != nil {
+		h.respondServiceError(w, draftID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, draft)
+}
+
+// GetDraftAttachment handles
+// GET /admin/claims/drafts/{id}/attachments/{attachmentId} and returns the
+// attachment content
+func (h *DraftHandler) GetDraftAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	draftID, attachmentID := vars["id"], vars["attachmentId"]
+
+	attachment, content, err := h.service.GetDraftAttachment(draftID, attachmentID)
+	if err != nil {
+		h.respondServiceError(w, draftID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(content); err != nil {
+		h.logger.WithError(err).WithField("attachmentId", attachmentID).Error("Failed to write attachment")
+	}
+}
+
+// ConfirmDraft handles POST /admin/claims/drafts/{id}/confirm. The body is
+// optional and holds corrections to the parsed claim.
+func (h *DraftHandler) ConfirmDraft(w http.ResponseWriter, r *http.Request) {
+	draftID := mux.Vars(r)["id"]
+
+	var req models.ConfirmDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+	if r.URL.Query().Get("force") == "true" {
+		req.Force = true
+	}
+
+	draft, claim, err := h.service.ConfirmDraft(r.Context(), draftID, middleware.GetUserID(r), &req)
+	if err != nil {
+		var duplicate *services.DuplicateClaimError
+		if errors.As(err, &duplicate) {
+			h.respondJSON(w, http.StatusConflict, map[string]interface{}{
+				"error":               err.Error(),
+				"existingClaimId":     duplicate.Existing.ID,
+				"existingClaimNumber": duplicate.Existing.ClaimNumber,
+				"existingStatus":      duplicate.Existing.Status,
+				"hint":                "confirm with force=true if this is a separate incident",
+			})
+			return
+		}
+		h.respondServiceError(w, draftID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, shaping.Apply(r, map[string]interface{}{
+		"draft": draft,
+		"claim": claim,
+	}))
+}
+
+// DiscardDraft handles POST /admin/claims/drafts/{id}/discard
+func (h *DraftHandler) DiscardDraft(w http.ResponseWriter, r *http.Request) {
+	draftID := mux.Vars(r)["id"]
+
+	var req models.DiscardDraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	draft, err := h.service.DiscardDraft(draftID, middleware.GetUserID(r), &req)
+	if err != nil {
+		h.respondServiceError(w, draftID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, draft)
+}
+
+// authorized reports whether an inbound email carries the intake token
+func (h *DraftHandler) authorized(r *http.Request) bool {
+	token := r.Header.Get(HeaderIntakeToken)
+	if token == "" {
+		_, token, _ = r.BasicAuth()
+	}
+	return token != "" && signing.Equal(token, h.intakeToken)
+}
+
+// rawEmail returns the raw message of an inbound email request
+func rawEmail(r *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	if err := r.ParseMultipartForm(models.MaxEmailSize); err != nil {
+		return nil, err
+	}
+	for _, field := range rawEmailFields {
+		if value := r.FormValue(field); value != "" {
+			return strings.NewReader(value), nil
+		}
+		if file, _, err := r.FormFile(field); err == nil {
+			return file, nil
+		}
+	}
+	return nil, errors.New("form has no raw email field")
+}
+
+// respondServiceError maps draft service errors to HTTP statuses
+func (h *DraftHandler) respondServiceError(w http.ResponseWriter, draftID string, err error) {
+	switch {
+	case err.Error() == "draft not found":
+		h.respondError(w, http.StatusNotFound, "Draft not found")
+	case err.Error() == "attachment not found":
+		h.respondError(w, http.StatusNotFound, "Attachment not found")
+	case strings.HasPrefix(err.Error(), "draft is already"):
+		h.respondError(w, http.StatusConflict, err.Error())
+	case err.Error() == "policy-service unavailable":
+		h.respondError(w, http.StatusServiceUnavailable, "Policy service unavailable")
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("draftId", draftID).Error("Failed to save draft")
+		h.respondError(w, http.StatusInternalServerError, "Failed to save draft")
+	default:
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *DraftHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *DraftHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
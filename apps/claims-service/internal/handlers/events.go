@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// EventsHandler streams claim status changes to clients using Server-Sent Events
+type EventsHandler struct {
+	service   ClaimService
+	bus       *events.Bus
+	heartbeat time.Duration
+	logger    *logrus.Logger
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+// NewEventsHandler creates a new SSE events handler
+func NewEventsHandler(service ClaimService, bus *events.Bus, heartbeat time.Duration, logger *logrus.Logger) *EventsHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &EventsHandler{
+		service:   service,
+		bus:       bus,
+		heartbeat: heartbeat,
+		logger:    logger,
+		closing:   make(chan struct{}),
+	}
+}
+
+// Close ends every open stream. Streams never finish on their own, so
+// they are closed before the server drains; clients reconnect with
+// Last-Event-ID and resume where they left off.
+func (h *EventsHandler) Close() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// StreamClaimEvents handles GET /claims/{id}/events
+func (h *EventsHandler) StreamClaimEvents(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	if _, err := h.service.GetClaimByID(claimID, claimViewer(r)); err != nil {
+		if err.Error() == "unauthorized" {
+			h.respondError(w, http.StatusForbidden, "You do not have access to this claim")
+			return
+		}
+		h.logger.WithError(err).WithField("claimId", claimID).Warn("Claim not found")
+		h.respondError(w, http.StatusNotFound, "Claim not found")
+		return
+	}
+
+	h.stream(w, r, func(evt events.Event) bool {
+		return evt.ClaimID == claimID
+	}, logrus.Fields{"claimId": claimID})
+}
+
+// StreamClaims handles GET /claims/stream
+// Supports query parameters:
+// - customerId: only stream events for claims belonging to this customer
+func (h *EventsHandler) StreamClaims(w http.ResponseWriter, r *http.Request) {
+	customerID := r.URL.Query().Get("customerId")
+
+	var filter events.Filter
+	if customerID != "" {
+		filter = func(evt events.Event) bool {
+			return evt.CustomerID == customerID
+		}
+	}
+
+	h.stream(w, r, filter, logrus.Fields{"customerId": customerID})
+}
+
+// stream writes matching events to the client until it disconnects
+func (h *EventsHandler) stream(w http.ResponseWriter, r *http.Request, filter events.Filter, fields logrus.Fields) {
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	// Streams outlive the server's write timeout, so clear the deadline
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WithError(err).Debug("Unable to clear write deadline for event stream")
+	}
+
+	replay, sub := h.bus.Subscribe(filter, lastEventID, 0)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Advise clients how long to wait before reconnecting
+	fmt.Fprintf(w, "retry: %d\n\n", 5000)
+
+	for _, evt := range replay {
+		if err := writeEvent(w, evt); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.WithError(err).Error("Streaming not supported by response writer")
+		return
+	}
+
+	h.logger.WithFields(fields).WithFields(logrus.Fields{
+		"lastEventId": lastEventID,
+		"replayed":    len(replay),
+	}).Info("Event stream opened")
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			h.logger.WithFields(fields).Info("Event stream closed")
+			return
+		case <-h.closing:
+			h.logger.WithFields(fields).Info("Event stream closed for shutdown")
+			return
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, evt); err != nil {
+				return
+			}
+			rc.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			rc.Flush()
+		}
+	}
+}
+
+// writeEvent writes a single SSE frame
+func writeEvent(w http.ResponseWriter, evt events.Event) error {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+	return err
+}
+
+// parseLastEventID reads the resume position from the Last-Event-ID header,
+// falling back to a lastEventId query parameter for clients that cannot set headers
+func parseLastEventID(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// respondError sends an error response
+func (h *EventsHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// FNOLService is the business logic the FNOL handlers depend on.
+// *services.FNOLService is the production implementation.
+type FNOLService interface {
+	StartFNOL(reporter models.FNOLReporter, req *models.StartFNOLRequest) (*models.FNOL, error)
+	GetFNOL(reportID string, reporter models.FNOLReporter) (*models.FNOL, error)
+	GetFNOLs(customerID string, reporter models.FNOLReporter) ([]*models.FNOL, error)
+	SaveIncident(reportID string, reporter models.FNOLReporter, incident *models.FNOLIncident) (*models.FNOL, error)
+	SaveParties(reportID string, reporter models.FNOLReporter, parties *models.FNOLParties) (*models.FNOL, error)
+	SaveDamage(reportID string, reporter models.FNOLReporter, damage *models.FNOLDamage) (*models.FNOL, error)
+	SubmitFNOL(ctx context.Context, reportID string, reporter models.FNOLReporter, force bool) (*models.FNOL, *models.Claim, error)
+}
+
+var _ FNOLService = (*services.FNOLService)(nil)
+
+// FNOLHandler serves guided first notice of loss intake. Routes must be
+// wrapped in middleware.IdentifyRole so agents reporting for a customer
+// can be told apart from the customer.
+type FNOLHandler struct {
+	service FNOLService
+	logger  *logrus.Logger
+}
+
+// NewFNOLHandler creates a new FNOL handler
+func NewFNOLHandler(service FNOLService, logger *logrus.Logger) *FNOLHandler {
+	return &FNOLHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetFNOLs handles GET /fnol, listing the caller's reports. Staff list a
+// customer's reports with the customerId query parameter.
+func (h *FNOLHandler) GetFNOLs(w http.ResponseWriter, r *http.Request) {
+	reports, err := h.service.GetFNOLs(r.URL.Query().Get("customerId"), fnolReporter(r))
+	if err != nil {
+		h.respondServiceError(w, "", err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, reports)
+}
+
+// StartFNOL handles POST /fnol
+func (h *FNOLHandler) StartFNOL(w http.ResponseWriter, r *http.Request) {
+	var req models.StartFNOLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	report, err := h.service.StartFNOL(fnolReporter(r), &req)
+	if err != nil {
+		h.respondServiceError(w, "", err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, report)
+}
+
+// GetFNOL handles GET /fnol/{id}
+func (h *FNOLHandler) GetFNOL(w http.ResponseWriter, r *http.Request) {
+	reportID := mux.Vars(r)["id"]
+
+	report, err := h.service.GetFNOL(reportID, fnolReporter(r))
+	if err != nil {
+		h.respondServiceError(w, reportID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, report)
+}
+
+// SaveStep handles PUT /fnol/{id}/step/{n}. The body is the step's
+// section: the incident for step 1, the parties for step 2 and the damage
+// for step 3.
+func (h *FNOLHandler) SaveStep(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	reportID := vars["id"]
+	reporter := fnolReporter(r)
+
+	step, err := strconv.Atoi(vars["n"])
+	if err != nil || step < models.FNOLStepIncident || step > models.FNOLSteps {
+		h.respondError(w, http.StatusNotFound, "Step not found")
+		return
+	}
+
+	var report *models.FNOL
+	switch step {
+	case models.FNOLStepIncident:
+		var incident models.FNOLIncident
+		if !h.decode(w, r, &incident) {
+			return
+		}
+		report, err = h.service.SaveIncident(reportID, reporter, &incident)
+	case models.FNOLStepParties:
+		var parties models.FNOLParties
+		if !h.decode(w, r, &parties) {
+			return
+		}
+		report, err = h.service.SaveParties(reportID, reporter, &parties)
+	case models.FNOLStepDamage:
+		var damage models.FNOLDamage
+		if !h.decode(w, r, &damage) {
+			return
+		}
+		report, err = h.service.SaveDamage(reportID, reporter, &damage)
+	}
+	if err != nil {
+		h.respondServiceError(w, reportID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, report)
+}
+
+// SubmitFNOL handles POST /fnol/{id}/submit
+func (h *FNOLHandler) SubmitFNOL(w http.ResponseWriter, r *http.Request) {
+	reportID := mux.Vars(r)["id"]
+	force := r.URL.Query().Get("force") == "true"
+
+	report, claim, err := h.service.SubmitFNOL(r.Context(), reportID, fnolReporter(r), force)
+	if err != nil {
+		var duplicate *services.DuplicateClaimError
+		if errors.As(err, &duplicate) {
+			h.respondJSON(w, http.StatusConflict, map[string]interface{}{
+				"error":               err.Error(),
+				"existingClaimId":     duplicate.Existing.ID,
+				"existingClaimNumber": duplicate.Existing.ClaimNumber,
+				"existingStatus":      duplicate.Existing.Status,
+				"hint":                "resubmit with force=true if this is a separate incident",
+			})
+			return
+		}
+		h.respondServiceError(w, reportID, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"reportId": report.ID,
+		"claimId":  claim.ID,
+		"userId":   middleware.GetUserID(r),
+	}).Info("FNOL report submitted via API")
+
+	h.respondJSON(w, http.StatusCreated, shaping.Apply(r, map[string]interface{}{
+		"report": report,
+		"claim":  claim,
+	}))
+}
+
+// decode reads a step's section, responding with an error when it cannot
+func (h *FNOLHandler) decode(w http.ResponseWriter, r *http.Request, section interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(section); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return false
+	}
+	return true
+}
+
+// fnolReporter identifies who is filling in a report: staff by their
+// verified token, everyone else as the customer named by X-User-ID
+func fnolReporter(r *http.Request) models.FNOLReporter {
+	return models.FNOLReporter{ID: middleware.GetUserID(r), Role: middleware.GetRole(r)}
+}
+
+// respondServiceError maps FNOL service errors to HTTP statuses. A step
+// that fails validation returns every problem found.
+func (h *FNOLHandler) respondServiceError(w http.ResponseWriter, reportID string, err error) {
+	var invalid *services.FNOLStepError
+	switch {
+	case errors.As(err, &invalid):
+		h.respondJSON(w, http.StatusBadRequest, map[string]interface{}{
+			"error":    err.Error(),
+			"step":     invalid.Step,
+			"problems": invalid.Problems,
+		})
+	case err.Error() == "report not found":
+		h.respondError(w, http.StatusNotFound, "Report not found")
+	case err.Error() == "unauthorized":
+		h.respondError(w, http.StatusForbidden, "You do not have access to this report")
+	case err.Error() == "report is already submitted" || strings.HasPrefix(err.Error(), "complete step"):
+		h.respondError(w, http.StatusConflict, err.Error())
+	case err.Error() == "policy-service unavailable":
+		h.respondError(w, http.StatusServiceUnavailable, "Policy service unavailable")
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("reportId", reportID).Error("Failed to save report")
+		h.respondError(w, http.StatusInternalServerError, "Failed to save report")
+	default:
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *FNOLHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *FNOLHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
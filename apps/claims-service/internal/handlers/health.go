@@ -1,26 +0,0 @@
-package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-)
-
-// HealthHandler handles health check requests
-type HealthHandler struct{}
-
-// NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
-}
-
-// ServeHTTP implements http.Handler interface
-func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"status":  "healthy",
-		"service": "claims-service",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
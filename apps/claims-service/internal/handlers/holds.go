@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// holdRecheckTimeout bounds how long a recheck may spend calling policy-service
+const holdRecheckTimeout = 30 * time.Second
+
+// HeldClaimChecker rechecks claims held pending payment.
+// *services.ClaimService is the production implementation.
+type HeldClaimChecker interface {
+	RecheckHeldClaims(ctx context.Context) *models.HoldRecheckResult
+}
+
+var _ HeldClaimChecker = (*services.ClaimService)(nil)
+
+// HoldRecheckHandler releases held claims on demand
+type HoldRecheckHandler struct {
+	checker HeldClaimChecker
+	logger  *logrus.Logger
+}
+
+// NewHoldRecheckHandler creates a new held claim recheck handler
+func NewHoldRecheckHandler(checker HeldClaimChecker, logger *logrus.Logger) *HoldRecheckHandler {
+	return &HoldRecheckHandler{
+		checker: checker,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP handles POST /admin/claims/held/recheck
+func (h *HoldRecheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), holdRecheckTimeout)
+	defer cancel()
+
+	result := h.checker.RecheckHeldClaims(ctx)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// MyClaimsService is the business logic the customer claim tracking
+// handlers depend on. *services.ClaimService is the production
+// implementation.
+type MyClaimsService interface {
+	GetClaims(filters *models.ClaimFilters, viewer models.ClaimViewer) ([]*models.Claim, error)
+	GetClaimByID(claimID string, viewer models.ClaimViewer) (*models.Claim, error)
+}
+
+var _ MyClaimsService = (*services.ClaimService)(nil)
+
+// MyClaimsHandler serves customers the claims on their own policies as
+// models.CustomerClaim, without the fields adjusters work with. The caller
+// is always read as a customer, so a staff token gets no wider view here.
+type MyClaimsHandler struct {
+	service MyClaimsService
+	logger  *logrus.Logger
+}
+
+// NewMyClaimsHandler creates a new customer claim tracking handler
+func NewMyClaimsHandler(service MyClaimsService, logger *logrus.Logger) *MyClaimsHandler {
+	return &MyClaimsHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetMyClaims handles GET /my/claims, most recent first.
+// Supports query parameters:
+// - policyId: filter by policy ID
+// - status: filter by status
+func (h *MyClaimsHandler) GetMyClaims(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := &models.ClaimFilters{
+		PolicyID: query.Get("policyId"),
+		Status:   query.Get("status"),
+	}
+
+	claims, err := h.service.GetClaims(filters, customerViewer(r))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to retrieve customer claims")
+		h.respondError(w, http.StatusInternalServerError, "Failed to retrieve claims")
+		return
+	}
+
+	views := make([]*models.CustomerClaim, 0, len(claims))
+	for _, claim := range claims {
+		views = append(views, models.NewCustomerClaim(claim))
+	}
+	h.respondJSON(w, http.StatusOK, views)
+}
+
+// GetMyClaim handles GET /my/claims/{id}. Another customer's claim is
+// answered as not found, so claim IDs cannot be probed.
+func (h *MyClaimsHandler) GetMyClaim(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	claim, err := h.service.GetClaimByID(claimID, customerViewer(r))
+	if err != nil {
+		if err.Error() != "claim not found" && err.Error() != "unauthorized" {
+			h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to retrieve customer claim")
+			h.respondError(w, http.StatusInternalServerError, "Failed to retrieve claim")
+			return
+		}
+		h.respondError(w, http.StatusNotFound, "Claim not found")
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, models.NewCustomerClaim(claim))
+}
+
+// customerViewer identifies the caller as the customer named by X-User-ID,
+// whatever role their token carries
+func customerViewer(r *http.Request) models.ClaimViewer {
+	return models.ClaimViewer{ID: middleware.GetUserID(r)}
+}
+
+// respondJSON sends a JSON response
+func (h *MyClaimsHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *MyClaimsHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
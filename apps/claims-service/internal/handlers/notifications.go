@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/notifications"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// TemplateRenderer writes notifications from their templates.
+// *notifications.Templates is the production implementation.
+type TemplateRenderer interface {
+	Render(eventType, locale string, data notifications.Data) (*notifications.Rendered, error)
+}
+
+var _ TemplateRenderer = (*notifications.Templates)(nil)
+
+// NotificationHandler serves previews of the notification templates
+type NotificationHandler struct {
+	templates TemplateRenderer
+	logger    *logrus.Logger
+}
+
+// NewNotificationHandler creates a new notification template handler
+func NewNotificationHandler(templates TemplateRenderer, logger *logrus.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		templates: templates,
+		logger:    logger,
+	}
+}
+
+// PreviewTemplate handles POST /admin/notifications/templates/{eventType}/preview.
+// The body is the event, claim and customer to render, as
+// notifications.Data; without a body the sample data is used. The locale
+// query parameter picks the translation.
+func (h *NotificationHandler) PreviewTemplate(w http.ResponseWriter, r *http.Request) {
+	eventType := mux.Vars(r)["eventType"]
+
+	var data notifications.Data
+	if err := json.NewDecoder(r.Body).Decode(&data); errors.Is(err, io.EOF) {
+		data = notifications.Sample(eventType)
+	} else if err != nil {
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	rendered, err := h.templates.Render(eventType, r.URL.Query().Get("locale"), data)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "no notification templates") {
+			h.respondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	h.respondJSON(w, http.StatusOK, rendered)
+}
+
+// respondJSON sends a JSON response
+func (h *NotificationHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *NotificationHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// OfferService is the business logic the settlement offer handlers depend
+// on. *services.ClaimService is the production implementation.
+type OfferService interface {
+	MakeOffer(claimID string, viewer models.ClaimViewer, req *models.CreateOfferRequest) (*models.SettlementOffer, error)
+	GetOffers(claimID string, viewer models.ClaimViewer) ([]models.SettlementOffer, error)
+	RespondToOffer(claimID, offerID string, viewer models.ClaimViewer, accept bool, req *models.RespondToOfferRequest) (*models.SettlementOffer, error)
+}
+
+var _ OfferService = (*services.ClaimService)(nil)
+
+// OfferHandler handles settlement offers on claims. Staff make offers and
+// the customer who owns the claim accepts or rejects them. Routes must be
+// wrapped in middleware.IdentifyRole so staff can be told apart from
+// customers.
+type OfferHandler struct {
+	service OfferService
+	logger  *logrus.Logger
+}
+
+// NewOfferHandler creates a new settlement offer handler
+func NewOfferHandler(service OfferService, logger *logrus.Logger) *OfferHandler {
+	return &OfferHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetOffers handles GET /claims/{id}/offers
+func (h *OfferHandler) GetOffers(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	offers, err := h.service.GetOffers(claimID, claimViewer(r))
+	if err != nil {
+		h.respondServiceError(w, claimID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, offers))
+}
+
+// MakeOffer handles POST /claims/{id}/offers
+func (h *OfferHandler) MakeOffer(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	var req models.CreateOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		status, message := decodeError(err)
+		h.respondError(w, status, message)
+		return
+	}
+
+	offer, err := h.service.MakeOffer(claimID, claimViewer(r), &req)
+	if err != nil {
+		h.respondServiceError(w, claimID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, shaping.Apply(r, offer))
+}
+
+// AcceptOffer handles POST /claims/{id}/offers/{offerId}/accept
+func (h *OfferHandler) AcceptOffer(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, true)
+}
+
+// RejectOffer handles POST /claims/{id}/offers/{offerId}/reject. The body,
+// which may be empty, can give the customer's reason.
+func (h *OfferHandler) RejectOffer(w http.ResponseWriter, r *http.Request) {
+	h.respond(w, r, false)
+}
+
+// respond records the customer's answer to an offer
+func (h *OfferHandler) respond(w http.ResponseWriter, r *http.Request, accept bool) {
+	vars := mux.Vars(r)
+	claimID := vars["id"]
+
+	var req models.RespondToOfferRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.WithError(err).Warn("Invalid request body")
+			status, message := decodeError(err)
+			h.respondError(w, status, message)
+			return
+		}
+	}
+
+	offer, err := h.service.RespondToOffer(claimID, vars["offerId"], claimViewer(r), accept, &req)
+	if err != nil {
+		h.respondServiceError(w, claimID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, offer))
+}
+
+// respondServiceError maps settlement offer errors to HTTP statuses
+func (h *OfferHandler) respondServiceError(w http.ResponseWriter, claimID string, err error) {
+	switch {
+	case err.Error() == "claim not found":
+		h.respondError(w, http.StatusNotFound, "Claim not found")
+	case err.Error() == "offer not found":
+		h.respondError(w, http.StatusNotFound, "Offer not found")
+	case err.Error() == "unauthorized":
+		h.respondError(w, http.StatusForbidden, "You do not have access to this offer")
+	case err.Error() == "only staff can make settlement offers":
+		h.respondError(w, http.StatusForbidden, err.Error())
+	case strings.HasPrefix(err.Error(), "cannot make an offer"),
+		err.Error() == "claim already has an accepted offer",
+		err.Error() == "offer has expired",
+		strings.HasPrefix(err.Error(), "offer is no longer open"):
+		h.respondError(w, http.StatusConflict, err.Error())
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to save offer")
+		h.respondError(w, http.StatusInternalServerError, "Failed to save offer")
+	default:
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *OfferHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *OfferHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
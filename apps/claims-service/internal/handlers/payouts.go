@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// PayoutService is the business logic the payout handler depends on.
+// *services.PayoutService is the production implementation.
+type PayoutService interface {
+	CreatePayout(ctx context.Context, claimID string, viewer models.ClaimViewer, req *models.CreatePayoutRequest) (*clients.Payout, error)
+}
+
+var _ PayoutService = (*services.PayoutService)(nil)
+
+// PayoutHandler pays out claims through payments-service. Routes must be
+// wrapped in middleware.IdentifyRole so staff can be told apart from
+// customers.
+type PayoutHandler struct {
+	service PayoutService
+	logger  *logrus.Logger
+}
+
+// NewPayoutHandler creates a new payout handler
+func NewPayoutHandler(service PayoutService, logger *logrus.Logger) *PayoutHandler {
+	return &PayoutHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// CreatePayout handles POST /claims/{id}/payouts - pays out the claim's
+// accepted settlement offer, or the amount given of it
+func (h *PayoutHandler) CreatePayout(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	var req models.CreatePayoutRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.logger.WithError(err).Warn("Invalid request body")
+			status, message := decodeError(err)
+			h.respondError(w, status, message)
+			return
+		}
+	}
+
+	payout, err := h.service.CreatePayout(r.Context(), claimID, claimViewer(r), &req)
+	if err != nil {
+		switch {
+		case err.Error() == "claim not found":
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+		case err.Error() == "only staff can pay out claims":
+			h.respondError(w, http.StatusForbidden, err.Error())
+		case err.Error() == "claim has no accepted settlement offer",
+			strings.HasPrefix(err.Error(), "payout refused by payments-service"):
+			h.respondError(w, http.StatusConflict, err.Error())
+		case strings.HasPrefix(err.Error(), "payments-service"),
+			strings.HasPrefix(err.Error(), "failed to"):
+			h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to pay out claim")
+			h.respondError(w, http.StatusServiceUnavailable, "Payments service unavailable")
+		default:
+			h.respondError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, payout)
+}
+
+// respondJSON sends a JSON response
+func (h *PayoutHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *PayoutHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
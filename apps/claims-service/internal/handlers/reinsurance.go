@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ReinsuranceService works out reinsurance cessions.
+// *services.ReinsuranceService is the production implementation.
+type ReinsuranceService interface {
+	GetCession(claimID string) (*models.ReinsuranceCession, error)
+	Report(filters models.ReinsuranceFilters, now time.Time) *models.ReinsuranceReport
+}
+
+var _ ReinsuranceService = (*services.ReinsuranceService)(nil)
+
+// ReinsuranceHandler serves claim cessions and the cession report to staff
+type ReinsuranceHandler struct {
+	service ReinsuranceService
+	logger  *logrus.Logger
+}
+
+// NewReinsuranceHandler creates a new reinsurance handler
+func NewReinsuranceHandler(service ReinsuranceService, logger *logrus.Logger) *ReinsuranceHandler {
+	return &ReinsuranceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetCession handles GET /claims/{id}/reinsurance
+func (h *ReinsuranceHandler) GetCession(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	cession, err := h.service.GetCession(claimID)
+	if err != nil {
+		switch {
+		case err.Error() == "claim not found":
+			h.respondError(w, http.StatusNotFound, "Claim not found")
+		case strings.HasPrefix(err.Error(), "reinsurance is only calculated"):
+			h.respondError(w, http.StatusConflict, err.Error())
+		default:
+			h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to calculate cession")
+			h.respondError(w, http.StatusInternalServerError, "Failed to calculate cession")
+		}
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, cession)
+}
+
+// Report handles GET /reports/reinsurance
+func (h *ReinsuranceHandler) Report(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := models.ReinsuranceFilters{PolicyType: query.Get("policyType")}
+
+	bounds := []struct {
+		param string
+		dest  *time.Time
+		end   bool
+	}{
+		{"approvedFrom", &filters.ApprovedFrom, false},
+		{"approvedTo", &filters.ApprovedTo, true},
+	}
+	for _, b := range bounds {
+		value := query.Get(b.param)
+		if value == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			*b.dest = t
+			continue
+		}
+		t, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp", b.param))
+			return
+		}
+		if b.end {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		*b.dest = t
+	}
+
+	h.respondJSON(w, http.StatusOK, h.service.Report(filters, time.Now()))
+}
+
+// respondJSON sends a JSON response
+func (h *ReinsuranceHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *ReinsuranceHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
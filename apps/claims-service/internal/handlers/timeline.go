@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ClaimTimelines builds claim timelines.
+// *services.TimelineService is the production implementation.
+type ClaimTimelines interface {
+	GetTimeline(ctx context.Context, claimID string) (*models.ClaimTimeline, error)
+}
+
+var _ ClaimTimelines = (*services.TimelineService)(nil)
+
+// TimelineHandler serves claim timelines for the adjuster UI
+type TimelineHandler struct {
+	timelines ClaimTimelines
+	logger    *logrus.Logger
+}
+
+// NewTimelineHandler creates a new claim timeline handler
+func NewTimelineHandler(timelines ClaimTimelines, logger *logrus.Logger) *TimelineHandler {
+	return &TimelineHandler{
+		timelines: timelines,
+		logger:    logger,
+	}
+}
+
+// GetTimeline handles GET /claims/{id}/timeline
+func (h *TimelineHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	claimID := mux.Vars(r)["id"]
+
+	timeline, err := h.timelines.GetTimeline(r.Context(), claimID)
+	if err != nil && err.Error() == "payments-service unavailable" {
+		h.logger.WithError(err).WithField("claimId", claimID).Error("Failed to build timeline")
+		h.respondJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "Payments service unavailable"})
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("claimId", claimID).Warn("Claim not found")
+		h.respondJSON(w, http.StatusNotFound, map[string]string{"error": "Claim not found"})
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, timeline)
+}
+
+// respondJSON sends a JSON response
+func (h *TimelineHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceTokenIssuer grants service tokens to registered clients.
+// *services.ServiceTokenService is the production implementation.
+type ServiceTokenIssuer interface {
+	Grant(clientID, secret, scope string) (*services.ServiceToken, error)
+}
+
+var _ ServiceTokenIssuer = (*services.ServiceTokenService)(nil)
+
+// TokenHandler is the OAuth 2.0 token endpoint of the client credentials
+// grant (RFC 6749 section 4.4), through which services get tokens for their
+// calls to other services
+type TokenHandler struct {
+	issuer ServiceTokenIssuer
+	logger *logrus.Logger
+}
+
+// NewTokenHandler creates a new token handler
+func NewTokenHandler(issuer ServiceTokenIssuer, logger *logrus.Logger) *TokenHandler {
+	return &TokenHandler{
+		issuer: issuer,
+		logger: logger,
+	}
+}
+
+// TokenResponse is a granted service token
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // seconds
+	Scope       string `json:"scope"`
+}
+
+// IssueToken handles POST /auth/token - the client credentials grant. The
+// form-encoded body has grant_type=client_credentials and an optional
+// space-separated scope. The client authenticates with HTTP basic auth or
+// client_id and client_secret in the body.
+func (h *TokenHandler) IssueToken(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 64<<10)
+	if err := r.ParseForm(); err != nil {
+		h.respondError(w, http.StatusBadRequest, "invalid_request", "The body must be form-encoded")
+		return
+	}
+	if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+		h.respondError(w, http.StatusBadRequest, "unsupported_grant_type", "Only grant_type=client_credentials is supported")
+		return
+	}
+
+	clientID, secret, basic := r.BasicAuth()
+	if !basic {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID == "" || secret == "" {
+		h.respondError(w, http.StatusBadRequest, "invalid_request", "Client credentials are required")
+		return
+	}
+
+	token, err := h.issuer.Grant(clientID, secret, r.PostForm.Get("scope"))
+	if err != nil {
+		switch {
+		case err.Error() == "invalid client":
+			if basic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="claims-service"`)
+			}
+			h.respondError(w, http.StatusUnauthorized, "invalid_client", "Unknown client or wrong secret")
+		case strings.HasPrefix(err.Error(), "invalid scope"):
+			h.respondError(w, http.StatusBadRequest, "invalid_scope", "The client may not be granted "+strings.TrimPrefix(err.Error(), "invalid scope "))
+		default:
+			h.logger.WithError(err).Error("Failed to grant service token")
+			h.respondError(w, http.StatusInternalServerError, "server_error", "Internal server error")
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"clientId": token.ClientID,
+		"scope":    strings.Join(token.Scopes, " "),
+	}).Info("Granted service token")
+	h.respondJSON(w, http.StatusOK, TokenResponse{
+		AccessToken: token.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(token.ExpiresAt).Seconds()),
+		Scope:       strings.Join(token.Scopes, " "),
+	})
+}
+
+// respondJSON sends a JSON response that caches never keep
+func (h *TokenHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error in the OAuth 2.0 shape, {"error": code,
+// "error_description": message}
+func (h *TokenHandler) respondError(w http.ResponseWriter, status int, code, description string) {
+	h.respondJSON(w, status, map[string]string{"error": code, "error_description": description})
+}
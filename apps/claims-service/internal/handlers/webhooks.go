@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/webhooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// replayTimeout bounds one replay attempt against a webhook endpoint
+const replayTimeout = 15 * time.Second
+
+// DeadLetterReplayer inspects and replays failed webhook deliveries.
+// *webhooks.Dispatcher is the production implementation.
+type DeadLetterReplayer interface {
+	DeadLetters(eventType string) *models.DeadLetterList
+	Replay(ctx context.Context, id string) (*models.ReplayResult, error)
+	Stats() webhooks.Stats
+}
+
+var _ DeadLetterReplayer = (*webhooks.Dispatcher)(nil)
+
+var _ telemetry.Collector = (*WebhookHandler)(nil)
+
+// WebhookHandler serves the webhook dead-letter queue and delivery metrics
+type WebhookHandler struct {
+	replayer DeadLetterReplayer
+	logger   *logrus.Logger
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(replayer DeadLetterReplayer, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		replayer: replayer,
+		logger:   logger,
+	}
+}
+
+// ListDeadLetters handles GET /admin/dlq
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	h.respondJSON(w, http.StatusOK, h.replayer.DeadLetters(r.URL.Query().Get("eventType")))
+}
+
+// ReplayDeadLetter handles POST /admin/dlq/{id}/replay. A delivered replay
+// returns 200; one the endpoint rejected returns 502 with the entry as it
+// now stands in the queue.
+func (h *WebhookHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), replayTimeout)
+	defer cancel()
+
+	result, err := h.replayer.Replay(ctx, id)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "dead letter not found" {
+			status = http.StatusNotFound
+		}
+		h.respondError(w, status, err.Error())
+		return
+	}
+
+	if !result.Delivered {
+		h.respondJSON(w, http.StatusBadGateway, result)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, result)
+}
+
+// WritePrometheus writes webhook delivery metrics for GET /metrics in the
+// Prometheus text exposition format
+func (h *WebhookHandler) WritePrometheus(w io.Writer) {
+	stats := h.replayer.Stats()
+
+	fmt.Fprint(w, "# HELP claims_webhook_dlq_depth Webhook deliveries waiting in the dead-letter queue.\n")
+	fmt.Fprint(w, "# TYPE claims_webhook_dlq_depth gauge\n")
+	fmt.Fprintf(w, "claims_webhook_dlq_depth %d\n", stats.DLQDepth)
+	fmt.Fprint(w, "# HELP claims_webhook_deliveries_total Webhook delivery attempts by result.\n")
+	fmt.Fprint(w, "# TYPE claims_webhook_deliveries_total counter\n")
+	fmt.Fprintf(w, "claims_webhook_deliveries_total{result=\"delivered\"} %d\n", stats.Delivered)
+	fmt.Fprintf(w, "claims_webhook_deliveries_total{result=\"failed\"} %d\n", stats.FailedAttempts)
+	fmt.Fprint(w, "# HELP claims_webhook_dead_lettered_total Webhook deliveries moved to the dead-letter queue.\n")
+	fmt.Fprint(w, "# TYPE claims_webhook_dead_lettered_total counter\n")
+	fmt.Fprintf(w, "claims_webhook_dead_lettered_total %d\n", stats.DeadLettered)
+	fmt.Fprint(w, "# HELP claims_webhook_dlq_dropped_total Dead letters evicted because the queue was full.\n")
+	fmt.Fprint(w, "# TYPE claims_webhook_dlq_dropped_total counter\n")
+	fmt.Fprintf(w, "claims_webhook_dlq_dropped_total %d\n", stats.DLQDropped)
+	fmt.Fprint(w, "# HELP claims_webhook_replays_total Dead-letter replays by result.\n")
+	fmt.Fprint(w, "# TYPE claims_webhook_replays_total counter\n")
+	fmt.Fprintf(w, "claims_webhook_replays_total{result=\"delivered\"} %d\n", stats.Replayed)
+	fmt.Fprintf(w, "claims_webhook_replays_total{result=\"failed\"} %d\n", stats.ReplayFailed)
+}
+
+// respondJSON sends a JSON response
+func (h *WebhookHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *WebhookHandler) respondError(w http.ResponseWriter, status int, message string) {
+	h.respondJSON(w, status, map[string]string{"error": message})
+}
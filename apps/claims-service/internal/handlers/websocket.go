@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/realtime"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// adjusterRoles are the token roles allowed to open a dashboard connection
+var adjusterRoles = map[string]bool{
+	"adjuster": true,
+	"admin":    true,
+}
+
+// AdjusterSocketHandler upgrades authenticated adjuster connections to WebSockets
+type AdjusterSocketHandler struct {
+	hub        *realtime.Hub
+	jwtManager *auth.JWTManager
+	upgrader   websocket.Upgrader
+	logger     *logrus.Logger
+}
+
+// NewAdjusterSocketHandler creates a new WebSocket handler for adjuster dashboards
+func NewAdjusterSocketHandler(hub *realtime.Hub, logger *logrus.Logger) *AdjusterSocketHandler {
+	return &AdjusterSocketHandler{
+		hub:        hub,
+		jwtManager: middleware.NewJWTManager(logger),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Origins are governed by the same policy as the CORS middleware
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		logger: logger,
+	}
+}
+
+// ServeHTTP handles GET /ws/adjusters
+// Supports query parameters:
+// - queues: comma-separated adjuster queues to follow (default: all queues)
+// - token: JWT for browsers that cannot set an Authorization header on upgrade
+func (h *AdjusterSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := bearerToken(r)
+	if token == "" {
+		h.respondError(w, http.StatusUnauthorized, "Missing authentication token")
+		return
+	}
+
+	claims, err := h.jwtManager.Verify(token)
+	if err != nil {
+		h.logger.WithError(err).Warn("Rejected WebSocket upgrade with invalid token")
+		h.respondError(w, http.StatusUnauthorized, "Invalid authentication token")
+		return
+	}
+
+	if !adjusterRoles[claims.Role] {
+		h.logger.WithFields(logrus.Fields{
+			"userId": claims.UserID,
+			"role":   claims.Role,
+		}).Warn("Rejected WebSocket upgrade for non-adjuster role")
+		h.respondError(w, http.StatusForbidden, "Adjuster or admin role required")
+		return
+	}
+
+	var queues []string
+	if q := r.URL.Query().Get("queues"); q != "" {
+		for _, queue := range strings.Split(q, ",") {
+			if queue = strings.TrimSpace(queue); queue != "" {
+				queues = append(queues, queue)
+			}
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error response
+		h.logger.WithError(err).Warn("WebSocket upgrade failed")
+		return
+	}
+
+	h.hub.Serve(conn, claims.UserID, queues)
+}
+
+// bearerToken extracts a token from the Authorization header or token query parameter
+func bearerToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
+
+// respondError sends an error response
+func (h *AdjusterSocketHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,463 @@
+// Package intake reads claims that arrive through inbound channels rather
+// than the API. ParseEmail turns a structured claim email into the fields
+// of a claim request, which the draft service queues for an agent to
+// confirm.
+package intake
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// Email is a claim email read by ParseEmail
+type Email struct {
+	From        string
+	Subject     string
+	MessageID   string
+	Date        time.Time // zero when the Date header is missing or unreadable
+	Request     models.CreateClaimRequest
+	Attachments []Attachment
+	Issues      []string // what could not be read, in the order it was found
+}
+
+// Attachment is a file that came with a claim email
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+// maxPartDepth bounds how deeply multipart bodies are nested before the
+// rest is ignored
+const maxPartDepth = 5
+
+// fieldNames maps the labels accepted in a claim email, lowercased with
+// spaces, hyphens and underscores removed, to the field they fill
+var fieldNames = map[string]string{
+	"policy":          "policyId",
+	"policyid":        "policyId",
+	"policynumber":    "policyId",
+	"customer":        "customerId",
+	"customerid":      "customerId",
+	"type":            "type",
+	"claimtype":       "type",
+	"subtype":         "subType",
+	"claimsubtype":    "subType",
+	"amount":          "amount",
+	"claimamount":     "amount",
+	"estimatedamount": "amount",
+	"incidentdate":    "incidentDate",
+	"dateofloss":      "incidentDate",
+	"lossdate":        "incidentDate",
+	"description":     "description",
+	"details":         "description",
+	"street":          "street",
+	"city":            "city",
+	"state":           "state",
+	"postalcode":      "postalCode",
+	"zip":             "postalCode",
+	"zipcode":         "postalCode",
+	"country":         "country",
+	"location":        "location",
+}
+
+// dateLayouts are the incident date formats accepted, tried in order
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "01/02/2006", "January 2, 2006", "2 January 2006"}
+
+// ParseEmail reads a claim from a raw RFC 5322 message. The claim is taken
+// from "Label: value" lines in the plain text body, such as
+//
+//	Policy ID: pol-001
+//	Customer ID: cust-001
+//	Claim Type: accident
+//	Amount: $1,250.00
+//	Incident Date: 2024-11-02
+//	City: Springfield
+//	Country: US
+//	Description: Rear-ended at a stop light.
+//
+// A description may run over several lines, up to the next label; without
+// one, the body's unlabelled text is used. A JSON attachment holding a
+// claim request, as sent by web forms, fills the fields the body leaves
+// blank. Other attachments are returned to be filed as claim documents.
+// Only an unreadable message is an error; missing or invalid fields are
+// reported in Issues. Claim types are checked against types, as for a
+// policy of unknown line, since the policy's own line is checked when the
+// draft is filed; nil accepts any type.
+func ParseEmail(r io.Reader, types ClaimTypes) (*Email, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+
+	email := &Email{
+		Subject:   decodeHeader(msg.Header.Get("Subject")),
+		MessageID: strings.TrimSpace(msg.Header.Get("Message-Id")),
+	}
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		email.From = strings.ToLower(from.Address)
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		email.Date = date
+	}
+
+	p := &parser{email: email, types: types}
+	if err := p.walk(msg.Header, msg.Body, 0); err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+	if p.text == "" {
+		email.Issues = append(email.Issues, "email has no plain text body")
+	}
+	p.readFields()
+	p.mergeForm()
+	p.checkRequired()
+
+	return email, nil
+}
+
+// ClaimTypes checks claim types and sub-types against the claim taxonomy.
+// *taxonomy.Taxonomy is the production implementation.
+type ClaimTypes interface {
+	Check(policyType, claimType, subType string) error
+}
+
+// header is the part of a MIME header the parser reads; mail.Header and
+// textproto.MIMEHeader both provide it
+type header interface {
+	Get(key string) string
+}
+
+// parser collects the body text, attachments and form of an email
+type parser struct {
+	email  *Email
+	text   string                     // the first plain text body
+	form   *models.CreateClaimRequest // a claim request sent as a JSON attachment
+	fields map[string]string
+	types  ClaimTypes
+}
+
+// known reports whether a claim type, and sub-type when given, is in the
+// taxonomy
+func (p *parser) known(claimType, subType string) bool {
+	return claimType != "" && (p.types == nil || p.types.Check("", claimType, subType) == nil)
+}
+
+// walk reads one MIME entity, descending into multipart bodies
+func (p *parser) walk(h header, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxPartDepth {
+			return nil
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = p.walk(part.Header, part, depth+1)
+			part.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransfer(h.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return err
+	}
+
+	fileName := attachmentName(h)
+	if fileName == "" && mediaType == "text/plain" {
+		if p.text == "" {
+			p.text = string(content)
+		}
+		return nil
+	}
+	if fileName == "" && strings.HasPrefix(mediaType, "text/") {
+		// An HTML alternative of the plain text body
+		return nil
+	}
+	if fileName == "" {
+		fileName = "attachment"
+	}
+
+	if p.form == nil && (mediaType == "application/json" || strings.EqualFold(filepath.Ext(fileName), ".json")) {
+		var form models.CreateClaimRequest
+		if json.Unmarshal(content, &form) == nil && form.PolicyID != "" {
+			p.form = &form
+			return nil
+		}
+	}
+
+	switch {
+	case len(content) == 0:
+		p.email.Issues = append(p.email.Issues, fmt.Sprintf("attachment %s is empty", fileName))
+	case len(content) > models.MaxDocumentSize:
+		p.email.Issues = append(p.email.Issues, fmt.Sprintf("attachment %s exceeds the %d MiB document limit", fileName, models.MaxDocumentSize>>20))
+	default:
+		p.email.Attachments = append(p.email.Attachments, Attachment{
+			FileName:    fileName,
+			ContentType: mediaType,
+			Content:     content,
+		})
+	}
+	return nil
+}
+
+// readFields fills the request from the labelled lines of the body text
+func (p *parser) readFields() {
+	p.fields = make(map[string]string)
+	var free []string
+	current := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(p.text))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "--" || line == "-- " {
+			break // signature
+		}
+		if strings.HasPrefix(line, ">") {
+			continue // quoted reply
+		}
+
+		if label, value, ok := strings.Cut(line, ":"); ok {
+			if field, known := fieldNames[normalizeLabel(label)]; known {
+				current = field
+				p.fields[field] = strings.TrimSpace(value)
+				continue
+			}
+		}
+		if current == "description" {
+			p.fields["description"] += "\n" + line
+			continue
+		}
+		current = ""
+		if strings.TrimSpace(line) != "" {
+			free = append(free, strings.TrimSpace(line))
+		}
+	}
+
+	req := &p.email.Request
+	req.PolicyID = p.fields["policyId"]
+	req.CustomerID = p.fields["customerId"]
+	req.Description = strings.TrimSpace(p.fields["description"])
+	if _, labelled := p.fields["description"]; !labelled {
+		req.Description = strings.Join(free, "\n")
+	}
+
+	if claimType := claimCode(p.fields["type"]); claimType != "" {
+		if p.known(claimType, "") {
+			req.Type = claimType
+		} else {
+			p.email.Issues = append(p.email.Issues, fmt.Sprintf("unknown claim type %q", p.fields["type"]))
+		}
+	}
+	if subType := claimCode(p.fields["subType"]); subType != "" && req.Type != "" {
+		if p.known(req.Type, subType) {
+			req.SubType = subType
+		} else {
+			p.email.Issues = append(p.email.Issues, fmt.Sprintf("unknown claim sub-type %q", p.fields["subType"]))
+		}
+	}
+	if value := p.fields["amount"]; value != "" {
+		if amount, err := parseAmount(value); err == nil {
+			req.Amount = amount
+		} else {
+			p.email.Issues = append(p.email.Issues, fmt.Sprintf("amount %q is not a number", value))
+		}
+	}
+	if value := p.fields["incidentDate"]; value != "" {
+		if date, ok := parseDate(value); ok {
+			req.IncidentDate = &date
+		} else {
+			p.email.Issues = append(p.email.Issues, fmt.Sprintf("incident date %q is not a date", value))
+		}
+	}
+
+	location := models.LossLocation{
+		Street:      p.fields["street"],
+		City:        p.fields["city"],
+		State:       p.fields["state"],
+		PostalCode:  p.fields["postalCode"],
+		Country:     p.fields["country"],
+		Description: p.fields["location"],
+	}
+	if location != (models.LossLocation{}) {
+		req.LossLocation = &location
+	}
+}
+
+// mergeForm fills the fields the body left blank from a JSON form
+func (p *parser) mergeForm() {
+	if p.form == nil {
+		return
+	}
+	req := &p.email.Request
+	if req.PolicyID == "" {
+		req.PolicyID = p.form.PolicyID
+	}
+	if req.CustomerID == "" {
+		req.CustomerID = p.form.CustomerID
+	}
+	if req.Type == "" && p.known(p.form.Type, "") {
+		req.Type = p.form.Type
+		if p.known(p.form.Type, p.form.SubType) {
+			req.SubType = p.form.SubType
+		}
+	}
+	if req.Amount == 0 {
+		req.Amount = p.form.Amount
+	}
+	if req.Description == "" {
+		req.Description = p.form.Description
+	}
+	if req.IncidentDate == nil {
+		req.IncidentDate = p.form.IncidentDate
+	}
+	if req.LossLocation == nil {
+		req.LossLocation = p.form.LossLocation
+	}
+}
+
+// checkRequired reports the fields a claim needs that are still missing
+func (p *parser) checkRequired() {
+	req := &p.email.Request
+	if req.PolicyID == "" {
+		p.email.Issues = append(p.email.Issues, "policyId is missing")
+	}
+	if req.CustomerID == "" {
+		p.email.Issues = append(p.email.Issues, "customerId is missing")
+	}
+	if req.Type == "" {
+		p.email.Issues = append(p.email.Issues, "type is missing")
+	}
+	if req.Amount < 0 {
+		p.email.Issues = append(p.email.Issues, "amount must be greater than 0")
+	} else if req.Amount == 0 {
+		p.email.Issues = append(p.email.Issues, "amount is missing")
+	}
+	if req.Description == "" {
+		p.email.Issues = append(p.email.Issues, "description is missing")
+	}
+}
+
+// attachmentName returns the file name of an attached part, or "" for
+// parts that are not attachments
+func attachmentName(h header) string {
+	disposition, params, err := mime.ParseMediaType(h.Get("Content-Disposition"))
+	if err == nil {
+		if name := decodeHeader(params["filename"]); name != "" {
+			return filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+		}
+		if disposition == "attachment" {
+			return "attachment"
+		}
+	}
+	if _, params, err := mime.ParseMediaType(h.Get("Content-Type")); err == nil {
+		if name := decodeHeader(params["name"]); name != "" {
+			return filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+		}
+	}
+	return ""
+}
+
+// decodeTransfer undoes a part's Content-Transfer-Encoding. Multipart
+// readers already decode quoted-printable parts and drop the header.
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &lineStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+// lineStripper drops the line breaks base64 bodies are wrapped with
+type lineStripper struct {
+	r io.Reader
+}
+
+func (s *lineStripper) Read(p []byte) (int, error) {
+	for {
+		n, err := s.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+				p[kept] = b
+				kept++
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// decodeHeader decodes RFC 2047 encoded words, keeping the raw value when
+// it cannot be decoded
+func decodeHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// claimCode turns a claim type or sub-type as written, such as "Water
+// Damage", into its code
+func claimCode(value string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(value)))
+}
+
+// normalizeLabel lowercases a field label and drops its separators
+func normalizeLabel(label string) string {
+	var b bytes.Buffer
+	for _, r := range strings.ToLower(strings.TrimSpace(label)) {
+		if r != ' ' && r != '-' && r != '_' && r != '\t' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// parseAmount reads an amount such as "$1,250.00" or "1250 USD"
+func parseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.ToUpper(value), "USD"))
+	value = strings.NewReplacer("$", "", ",", "", " ", "").Replace(value)
+	return strconv.ParseFloat(value, 64)
+}
+
+// parseDate reads an incident date in one of dateLayouts
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,194 @@
+package intake
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/taxonomy"
+)
+
+const claimEmail = "From: \"Jane Doe\" <Jane.Doe@example.com>\r\n" +
+	"To: claims@insurancestack.example\r\n" +
+	"Subject: =?UTF-8?Q?Claim_=E2=80=93_rear-ended?=\r\n" +
+	"Message-ID: <msg-001@example.com>\r\n" +
+	"Date: Mon, 04 Nov 2024 09:30:00 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"outer\"\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"inner\"\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Hello, please file the claim below.\r\n" +
+	"\r\n" +
+	"Policy ID: pol-001\r\n" +
+	"Customer ID: cust-001\r\n" +
+	"Claim Type: Accident\r\n" +
+	"Amount: $1,250.00\r\n" +
+	"Incident Date: 2024-11-02\r\n" +
+	"City: Springfield\r\n" +
+	"Postal Code: 62701\r\n" +
+	"Country: US\r\n" +
+	"Description: Rear-ended at a stop light.\r\n" +
+	"Bumper and tail lights need replacing.\r\n" +
+	"\r\n" +
+	"-- \r\n" +
+	"Jane Doe\r\n" +
+	"Policy ID: ignored in the signature\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"\r\n" +
+	"<p>Policy ID: pol-999</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: image/jpeg; name=\"bumper.jpg\"\r\n" +
+	"Content-Disposition: attachment; filename=\"bumper.jpg\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"/9j/4AAQSkZJRgAB\r\n" +
+	"AQEASABIAAD/\r\n" +
+	"--outer--\r\n"
+
+func TestParseEmailReadsStructuredBody(t *testing.T) {
+	email, err := ParseEmail(strings.NewReader(claimEmail), taxonomy.Default())
+	if err != nil {
+		t.Fatalf("ParseEmail failed: %v", err)
+	}
+
+	if email.From != "jane.doe@example.com" || email.Subject != "Claim – rear-ended" || email.MessageID != "<msg-001@example.com>" {
+		t.Errorf("Unexpected headers: %q %q %q", email.From, email.Subject, email.MessageID)
+	}
+	if !email.Date.Equal(time.Date(2024, 11, 4, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected date %s", email.Date)
+	}
+
+	req := email.Request
+	if req.PolicyID != "pol-001" || req.CustomerID != "cust-001" || req.Type != "accident" || req.Amount != 1250 {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+	if req.Description != "Rear-ended at a stop light.\nBumper and tail lights need replacing." {
+		t.Errorf("Unexpected description %q", req.Description)
+	}
+	if req.IncidentDate == nil || !req.IncidentDate.Equal(time.Date(2024, 11, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected incident date %v", req.IncidentDate)
+	}
+	if loc := req.LossLocation; loc == nil || loc.City != "Springfield" || loc.PostalCode != "62701" || loc.Country != "US" {
+		t.Errorf("Unexpected loss location %+v", loc)
+	}
+	if len(email.Issues) != 0 {
+		t.Errorf("Expected no issues, got %v", email.Issues)
+	}
+
+	if len(email.Attachments) != 1 {
+		t.Fatalf("Expected the photo as the only attachment, got %d", len(email.Attachments))
+	}
+	photo := email.Attachments[0]
+	if photo.FileName != "bumper.jpg" || photo.ContentType != "image/jpeg" || len(photo.Content) != 21 {
+		t.Errorf("Unexpected attachment %s %s with %d bytes", photo.FileName, photo.ContentType, len(photo.Content))
+	}
+}
+
+func TestParseEmailReportsIssues(t *testing.T) {
+	raw := "From: someone@example.com\r\n" +
+		"Subject: my car\r\n" +
+		"\r\n" +
+		"Policy: pol-002\r\n" +
+		"Type: flood\r\n" +
+		"Amount: about a thousand\r\n" +
+		"Date of loss: last Tuesday\r\n" +
+		"Somebody hit my car in the parking lot.\r\n"
+
+	email, err := ParseEmail(strings.NewReader(raw), taxonomy.Default())
+	if err != nil {
+		t.Fatalf("ParseEmail failed: %v", err)
+	}
+
+	if email.Request.PolicyID != "pol-002" || email.Request.Description != "Somebody hit my car in the parking lot." {
+		t.Errorf("Unexpected request: %+v", email.Request)
+	}
+	expected := []string{
+		`unknown claim type "flood"`,
+		`amount "about a thousand" is not a number`,
+		`incident date "last Tuesday" is not a date`,
+		"customerId is missing",
+		"type is missing",
+		"amount is missing",
+	}
+	if !reflect.DeepEqual(email.Issues, expected) {
+		t.Errorf("Unexpected issues:\n got %q\nwant %q", email.Issues, expected)
+	}
+}
+
+func TestParseEmailUsesJSONForm(t *testing.T) {
+	raw := "From: forms@example.com\r\n" +
+		"Content-Type: multipart/mixed; boundary=b\r\n" +
+		"\r\n" +
+		"--b\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Amount: 300\r\n" +
+		"--b\r\n" +
+		"Content-Type: application/json\r\n" +
+		"Content-Disposition: attachment; filename=claim.json\r\n" +
+		"\r\n" +
+		`{"policyId":"pol-003","customerId":"cust-003","type":"theft","amount":900,"description":"Bike stolen from the garage"}` + "\r\n" +
+		"--b--\r\n"
+
+	email, err := ParseEmail(strings.NewReader(raw), taxonomy.Default())
+	if err != nil {
+		t.Fatalf("ParseEmail failed: %v", err)
+	}
+
+	req := email.Request
+	if req.PolicyID != "pol-003" || req.CustomerID != "cust-003" || req.Type != "theft" || req.Description != "Bike stolen from the garage" {
+		t.Errorf("Expected the form to fill the blank fields, got %+v", req)
+	}
+	if req.Amount != 300 {
+		t.Errorf("Expected the body's amount to win over the form's, got %v", req.Amount)
+	}
+	if len(email.Attachments) != 0 || len(email.Issues) != 0 {
+		t.Errorf("Expected the form to be read rather than attached, got %d attachments and issues %v", len(email.Attachments), email.Issues)
+	}
+}
+
+func TestParseEmailReadsSubType(t *testing.T) {
+	types := &taxonomy.Taxonomy{PolicyTypes: map[string][]models.ClaimType{
+		"home": {{Code: "damage", SubTypes: []models.ClaimSubType{{Code: "water_damage"}}}},
+	}}
+	parse := func(subType string) *Email {
+		t.Helper()
+		raw := "From: someone@example.com\r\n" +
+			"\r\n" +
+			"Policy: pol-002\r\n" +
+			"Customer: cust-002\r\n" +
+			"Claim Type: Damage\r\n" +
+			"Sub-Type: " + subType + "\r\n" +
+			"Amount: 1800\r\n" +
+			"Description: Burst pipe under the sink\r\n"
+		email, err := ParseEmail(strings.NewReader(raw), types)
+		if err != nil {
+			t.Fatalf("ParseEmail failed: %v", err)
+		}
+		return email
+	}
+
+	if email := parse("Water Damage"); email.Request.Type != "damage" || email.Request.SubType != "water_damage" || len(email.Issues) != 0 {
+		t.Errorf("Expected damage/water_damage, got %+v with issues %v", email.Request, email.Issues)
+	}
+	email := parse("Windshield")
+	if email.Request.SubType != "" || !reflect.DeepEqual(email.Issues, []string{`unknown claim sub-type "Windshield"`}) {
+		t.Errorf("Expected an unknown sub-type issue, got %+v with issues %v", email.Request, email.Issues)
+	}
+}
+
+func TestParseEmailRejectsUnreadableMessage(t *testing.T) {
+	if _, err := ParseEmail(strings.NewReader("not an email"), nil); err == nil || !strings.HasPrefix(err.Error(), "invalid email:") {
+		t.Errorf("Expected an invalid email error, got %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+// Package lifecycle defines the claim status state machine: the statuses a
+// claim can be in, the moves allowed between them, the rules a move must
+// satisfy, the fields a status sets when it is entered, and hooks that run
+// once a move has been saved.
+package lifecycle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// Claim statuses
+const (
+	Submitted      = "submitted"
+	UnderReview    = "under_review"
+	PendingPayment = "pending_payment"
+	Approved       = "approved"
+	Rejected       = "rejected"
+)
+
+// Triggers record what caused a status change
+const (
+	TriggerIntake  = "intake"  // a new claim leaving Submitted as it is filed
+	TriggerReview  = "review"  // a status change requested through the API
+	TriggerRecheck = "recheck" // a held claim's policy was rechecked
+)
+
+// Change is a request to move a claim to another status
+type Change struct {
+	To             string
+	Trigger        string
+	RejectionCodes []string
+	Notes          string
+	At             time.Time // when the change was made; defaults to now
+}
+
+// State describes one claim status. Enter, when set, validates a change
+// into the status and sets the claim fields that come with it.
+type State struct {
+	Name     string
+	Terminal bool // a claim never leaves a terminal status
+	Enter    func(claim *models.Claim, change *Change) error
+}
+
+// Transition allows a claim to move From one status To another. Guard,
+// when set, can refuse the move.
+type Transition struct {
+	From  string
+	To    string
+	Guard func(claim *models.Claim, change *Change) error
+}
+
+// Hook runs after a status change has been saved. from is the status the
+// claim left.
+type Hook func(claim *models.Claim, from string, change Change)
+
+// Machine applies status changes to claims according to a declared set of
+// statuses and transitions. Hooks are registered while the service is set
+// up and are not safe to add concurrently with Fire.
+type Machine struct {
+	initial     string
+	states      map[string]State
+	transitions map[string]map[string]Transition
+	hooks       []Hook
+}
+
+// New creates a machine whose claims start in initial. It panics if a
+// transition names a status that is not declared, since the definition is
+// part of the program rather than input.
+func New(initial string, states []State, transitions []Transition) *Machine {
+	m := &Machine{
+		initial:     initial,
+		states:      make(map[string]State, len(states)),
+		transitions: make(map[string]map[string]Transition),
+	}
+	for _, state := range states {
+		m.states[state.Name] = state
+	}
+	if _, ok := m.states[initial]; !ok {
+		panic(fmt.Sprintf("lifecycle: initial status %q is not declared", initial))
+	}
+	for _, t := range transitions {
+		if _, ok := m.states[t.From]; !ok {
+			panic(fmt.Sprintf("lifecycle: transition from undeclared status %q", t.From))
+		}
+		if _, ok := m.states[t.To]; !ok {
+			panic(fmt.Sprintf("lifecycle: transition to undeclared status %q", t.To))
+		}
+		if m.states[t.From].Terminal {
+			panic(fmt.Sprintf("lifecycle: transition out of terminal status %q", t.From))
+		}
+		if m.transitions[t.From] == nil {
+			m.transitions[t.From] = make(map[string]Transition)
+		}
+		m.transitions[t.From][t.To] = t
+	}
+	return m
+}
+
+// Claims returns the claim lifecycle. New claims are Submitted and leave it
+// as they are filed: held as PendingPayment when the policy is in grace,
+// auto-Approved when eligible, and otherwise UnderReview. A held claim
+// goes back to review before it can be approved. Approved and Rejected are
+// final.
+func Claims() *Machine {
+	return New(Submitted, []State{
+		{Name: Submitted, Enter: enterOpen},
+		{Name: UnderReview, Enter: enterOpen},
+		{Name: PendingPayment, Enter: enterOpen},
+		{Name: Approved, Terminal: true, Enter: enterApproved},
+		{Name: Rejected, Terminal: true, Enter: enterRejected},
+	}, []Transition{
+		{From: Submitted, To: UnderReview},
+		{From: Submitted, To: PendingPayment, Guard: onlyAtIntake},
+		{From: Submitted, To: Approved},
+		{From: Submitted, To: Rejected},
+		{From: UnderReview, To: Approved},
+		{From: UnderReview, To: Rejected},
+		{From: PendingPayment, To: UnderReview},
+		{From: PendingPayment, To: Rejected},
+	})
+}
+
+// Initial returns the status new claims start in
+func (m *Machine) Initial() string {
+	return m.initial
+}
+
+// IsTerminal reports whether claims in status can no longer change status
+func (m *Machine) IsTerminal(status string) bool {
+	return m.states[status].Terminal
+}
+
+// OnTransition registers a hook to run after every saved status change
+func (m *Machine) OnTransition(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Fire moves claim to change.To, then calls save and, if it succeeds, the
+// hooks. A refused change leaves claim untouched; a failed save returns the
+// save error with claim already updated, so callers should pass a copy they
+// can discard.
+func (m *Machine) Fire(claim *models.Claim, change Change, save func(claim *models.Claim) error) error {
+	from := claim.Status
+	if err := m.apply(claim, &change); err != nil {
+		return err
+	}
+	if err := save(claim); err != nil {
+		return err
+	}
+	for _, hook := range m.hooks {
+		hook(claim, from, change)
+	}
+	return nil
+}
+
+// apply validates a change against the definition and updates claim
+func (m *Machine) apply(claim *models.Claim, change *Change) error {
+	state, ok := m.states[change.To]
+	if !ok {
+		return fmt.Errorf("invalid claim status: %s", change.To)
+	}
+	if m.IsTerminal(claim.Status) {
+		return fmt.Errorf("cannot change status of finalized claim (current status: %s)", claim.Status)
+	}
+	transition, ok := m.transitions[claim.Status][change.To]
+	if !ok {
+		return fmt.Errorf("cannot change claim status from %s to %s", claim.Status, change.To)
+	}
+	if transition.Guard != nil {
+		if err := transition.Guard(claim, change); err != nil {
+			return err
+		}
+	}
+
+	if change.At.IsZero() {
+		change.At = time.Now()
+	}
+	if state.Enter != nil {
+		if err := state.Enter(claim, change); err != nil {
+			return err
+		}
+	}
+	claim.Status = change.To
+	claim.StatusSince = &change.At
+	claim.UpdatedAt = change.At
+	return nil
+}
+
+// onlyAtIntake limits a transition to claims being filed
+func onlyAtIntake(claim *models.Claim, change *Change) error {
+	if change.Trigger != TriggerIntake {
+		return fmt.Errorf("claims are only held pending payment when filed on a policy in grace")
+	}
+	return nil
+}
+
+// enterOpen clears rejection codes on a claim that is still being decided
+func enterOpen(claim *models.Claim, change *Change) error {
+	if len(change.RejectionCodes) > 0 {
+		return fmt.Errorf("rejection codes can only be set when rejecting a claim")
+	}
+	claim.RejectionCodes = nil
+	return nil
+}
+
+// enterApproved records when the claim was decided
+func enterApproved(claim *models.Claim, change *Change) error {
+	if err := enterOpen(claim, change); err != nil {
+		return err
+	}
+	claim.ReviewedDate = &change.At
+	return nil
+}
+
+// enterRejected requires at least one valid reason code, stores the codes
+// without duplicates and records when the claim was decided
+func enterRejected(claim *models.Claim, change *Change) error {
+	if len(change.RejectionCodes) == 0 {
+		return fmt.Errorf("at least one rejection code is required (one of: %s)", strings.Join(models.RejectionCodes, ", "))
+	}
+
+	codes := make([]string, 0, len(change.RejectionCodes))
+	seen := make(map[string]bool, len(change.RejectionCodes))
+	for _, code := range change.RejectionCodes {
+		if !models.ValidateRejectionCode(code) {
+			return fmt.Errorf("invalid rejection code: %s", code)
+		}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	claim.RejectionCodes = codes
+	claim.ReviewedDate = &change.At
+	return nil
+}
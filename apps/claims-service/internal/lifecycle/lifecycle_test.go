@@ -0,0 +1,111 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+func saveOK(*models.Claim) error { return nil }
+
+func TestClaimTransitions(t *testing.T) {
+	tests := []struct {
+		from    string
+		change  Change
+		wantErr string
+	}{
+		{Submitted, Change{To: UnderReview, Trigger: TriggerIntake}, ""},
+		{Submitted, Change{To: PendingPayment, Trigger: TriggerIntake}, ""},
+		{Submitted, Change{To: PendingPayment, Trigger: TriggerReview}, "claims are only held pending payment when filed on a policy in grace"},
+		{UnderReview, Change{To: Approved, Trigger: TriggerReview}, ""},
+		{UnderReview, Change{To: UnderReview, Trigger: TriggerReview}, "cannot change claim status from under_review to under_review"},
+		{UnderReview, Change{To: Submitted, Trigger: TriggerReview}, "cannot change claim status from under_review to submitted"},
+		{UnderReview, Change{To: "closed", Trigger: TriggerReview}, "invalid claim status: closed"},
+		{PendingPayment, Change{To: UnderReview, Trigger: TriggerRecheck}, ""},
+		{PendingPayment, Change{To: Approved, Trigger: TriggerReview}, "cannot change claim status from pending_payment to approved"},
+		{Approved, Change{To: Rejected, Trigger: TriggerReview, RejectionCodes: []string{models.RejectionDuplicate}}, "cannot change status of finalized claim (current status: approved)"},
+		{UnderReview, Change{To: Rejected, Trigger: TriggerReview}, "at least one rejection code is required (one of: not_covered, late_filing, fraud_suspected, insufficient_docs, duplicate, policy_lapsed)"},
+		{UnderReview, Change{To: Rejected, Trigger: TriggerReview, RejectionCodes: []string{"too_expensive"}}, "invalid rejection code: too_expensive"},
+		{UnderReview, Change{To: Approved, Trigger: TriggerReview, RejectionCodes: []string{models.RejectionDuplicate}}, "rejection codes can only be set when rejecting a claim"},
+	}
+
+	machine := Claims()
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.change.To, func(t *testing.T) {
+			claim := &models.Claim{ID: "claim-001", Status: tt.from}
+			err := machine.Fire(claim, tt.change, saveOK)
+			if tt.wantErr == "" {
+				if err != nil || claim.Status != tt.change.To {
+					t.Errorf("Fire: got status %s, %v", claim.Status, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Fire: got %v, want %q", err, tt.wantErr)
+			}
+			if claim.Status != tt.from {
+				t.Errorf("Refused change moved claim to %s", claim.Status)
+			}
+		})
+	}
+}
+
+func TestFireSetsDecisionFields(t *testing.T) {
+	machine := Claims()
+
+	rejected := &models.Claim{Status: UnderReview}
+	change := Change{To: Rejected, RejectionCodes: []string{models.RejectionNotCovered, models.RejectionNotCovered, models.RejectionLateFiling}}
+	if err := machine.Fire(rejected, change, saveOK); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	if rejected.ReviewedDate == nil || rejected.UpdatedAt.IsZero() || !rejected.UpdatedAt.Equal(*rejected.ReviewedDate) {
+		t.Errorf("Decision dates: reviewed %v, updated %v", rejected.ReviewedDate, rejected.UpdatedAt)
+	}
+	if len(rejected.RejectionCodes) != 2 {
+		t.Errorf("Rejection codes should be deduplicated: %v", rejected.RejectionCodes)
+	}
+
+	released := &models.Claim{Status: PendingPayment}
+	if err := machine.Fire(released, Change{To: UnderReview, Trigger: TriggerRecheck}, saveOK); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	if released.ReviewedDate != nil {
+		t.Error("A claim returned to review should not have a reviewed date")
+	}
+	if released.StatusSince == nil || !released.StatusSince.Equal(released.UpdatedAt) {
+		t.Errorf("Expected the time the status was entered, got %v", released.StatusSince)
+	}
+}
+
+func TestFireRunsHooksAfterSave(t *testing.T) {
+	machine := Claims()
+	var calls []string
+	machine.OnTransition(func(claim *models.Claim, from string, change Change) {
+		calls = append(calls, from+">"+claim.Status+":"+change.Trigger)
+	})
+
+	claim := &models.Claim{Status: machine.Initial()}
+	if err := machine.Fire(claim, Change{To: UnderReview, Trigger: TriggerIntake}, saveOK); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+
+	saveErr := errors.New("claim version conflict")
+	err := machine.Fire(claim, Change{To: Approved, Trigger: TriggerReview}, func(*models.Claim) error { return saveErr })
+	if err != saveErr {
+		t.Errorf("Fire should return the save error: got %v", err)
+	}
+
+	if len(calls) != 1 || calls[0] != "submitted>under_review:intake" {
+		t.Errorf("Hooks: got %v", calls)
+	}
+}
+
+func TestNewRejectsUndeclaredStatus(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected New to panic on a transition to an undeclared status")
+		}
+	}()
+	New(Submitted, []State{{Name: Submitted}}, []Transition{{From: Submitted, To: Approved}})
+}
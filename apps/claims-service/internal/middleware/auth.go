@@ -4,13 +4,18 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey contextKey = "userID"
+	roleKey   contextKey = "role"
+)
 
 // AuthMiddleware extracts user ID from X-User-ID header (simplified for demo)
 func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
@@ -24,6 +29,7 @@ func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
 
 			// Extract user ID from X-User-ID header (demo purposes)
 			userID := r.Header.Get("X-User-ID")
+			telemetry.SetIdentity(r.Context(), userID, "")
 			if userID == "" {
 				userID = "user-001" // Default for demo
 			}
@@ -31,6 +37,12 @@ func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
 			// Add user ID to request context
 			ctx := context.WithValue(r.Context(), userIDKey, userID)
 
+			// Partner channels identify themselves, and get their own circuit
+			// breakers on downstream calls
+			if tenantID := r.Header.Get("X-Tenant-ID"); tenantID != "" {
+				ctx = targeting.WithTenant(ctx, tenantID)
+			}
+
 			logger.WithField("userId", userID).Debug("User authenticated")
 
 			next.ServeHTTP(w, r.WithContext(ctx))
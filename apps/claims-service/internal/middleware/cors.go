@@ -20,7 +20,9 @@ func NewCORS() *cors.Cors {
 			"Authorization",
 			"Content-Type",
 			"X-CSRF-Token",
+			"Last-Event-ID",
 			"X-User-ID",
+			"X-Tenant-ID",
 		},
 		ExposedHeaders: []string{
 			"Link",
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RouteLimits bounds the request body and handler time of a route. A zero
+// field falls back to the default limits; a negative one removes the limit.
+type RouteLimits struct {
+	MaxBodyBytes int64
+	Timeout      time.Duration
+}
+
+// LimitsConfig holds the default limits and per-route overrides, keyed by
+// method and route template, such as "POST /claims/{id}/documents"
+type LimitsConfig struct {
+	Default RouteLimits
+	Routes  map[string]RouteLimits
+}
+
+// For returns the limits that apply to a method and route template
+func (c LimitsConfig) For(method, route string) RouteLimits {
+	limits := c.Default
+	if override, ok := c.Routes[method+" "+route]; ok {
+		if override.MaxBodyBytes != 0 {
+			limits.MaxBodyBytes = override.MaxBodyBytes
+		}
+		if override.Timeout != 0 {
+			limits.Timeout = override.Timeout
+		}
+	}
+	return limits
+}
+
+// timeoutBody is sent with the 503 of a handler that runs out of time
+const timeoutBody = `{"error":"request timed out"}` + "\n"
+
+// Limits rejects request bodies over the route's size limit with 413 and
+// answers 503 when the route's handler runs past its timeout. Bodies that
+// declare their size up front are rejected before the handler runs; others
+// fail while the handler reads them, which handlers should report with
+// BodyTooLarge. Timeouts have http.TimeoutHandler semantics: the response
+// is buffered and discarded if the deadline passes, and the request
+// context is cancelled. Streaming routes need a negative Timeout, as a
+// buffered response cannot be flushed or hijacked. It must be installed
+// with Router.Use so the matched route is known.
+func Limits(logger *logrus.Logger, cfg LimitsConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := routeTemplate(r)
+			limits := cfg.For(r.Method, route)
+
+			if limits.MaxBodyBytes > 0 {
+				if r.ContentLength > limits.MaxBodyBytes {
+					logger.WithFields(logrus.Fields{
+						"route":         route,
+						"contentLength": r.ContentLength,
+						"limit":         limits.MaxBodyBytes,
+					}).Warn("Rejected oversized request body")
+					respondError(w, http.StatusRequestEntityTooLarge, TooLargeMessage(limits.MaxBodyBytes))
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBodyBytes)
+			}
+
+			if limits.Timeout > 0 {
+				http.TimeoutHandler(next, limits.Timeout, timeoutBody).ServeHTTP(&timeoutResponse{ResponseWriter: w}, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BodyTooLarge reports whether err came from reading past the route's body
+// limit, and that limit
+func BodyTooLarge(err error) (limit int64, ok bool) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return tooLarge.Limit, true
+	}
+	return 0, false
+}
+
+// TooLargeMessage is the error message for a body over limit bytes
+func TooLargeMessage(limit int64) string {
+	return fmt.Sprintf("request body exceeds the %d byte limit", limit)
+}
+
+// ParseRouteLimits reads per-route overrides from JSON such as
+//
+//	{"POST /claims": {"maxBodyBytes": 65536, "timeout": "5s"}}
+//
+// Keys are a method and a route template separated by a space.
+func ParseRouteLimits(data []byte) (map[string]RouteLimits, error) {
+	var raw map[string]struct {
+		MaxBodyBytes int64  `json:"maxBodyBytes"`
+		Timeout      string `json:"timeout"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid route limits: %w", err)
+	}
+
+	routes := make(map[string]RouteLimits, len(raw))
+	for key, entry := range raw {
+		method, route, ok := strings.Cut(key, " ")
+		if !ok || method == "" || !strings.HasPrefix(route, "/") {
+			return nil, fmt.Errorf("invalid route %q (must be METHOD /template)", key)
+		}
+		limits := RouteLimits{MaxBodyBytes: entry.MaxBodyBytes}
+		if entry.Timeout != "" {
+			timeout, err := time.ParseDuration(entry.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timeout for %q: %w", key, err)
+			}
+			limits.Timeout = timeout
+		}
+		routes[strings.ToUpper(method)+" "+route] = limits
+	}
+	return routes, nil
+}
+
+// timeoutResponse marks the timeout body as JSON. http.TimeoutHandler
+// copies the handler's own headers before writing a normal response, so
+// only the timeout response lacks a content type here.
+type timeoutResponse struct {
+	http.ResponseWriter
+}
+
+func (t *timeoutResponse) WriteHeader(code int) {
+	if code == http.StatusServiceUnavailable && t.Header().Get("Content-Type") == "" {
+		t.Header().Set("Content-Type", "application/json")
+	}
+	t.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+func newLimitsRouter(t *testing.T, cfg LimitsConfig) *mux.Router {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	router := mux.NewRouter()
+	router.Use(Limits(logger, cfg))
+	router.HandleFunc("/claims", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			if limit, ok := BodyTooLarge(err); ok {
+				respondError(w, http.StatusRequestEntityTooLarge, TooLargeMessage(limit))
+				return
+			}
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}).Methods("POST")
+	router.HandleFunc("/claims/{id}", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+		}
+	}).Methods("GET")
+	return router
+}
+
+func TestLimitsRejectsOversizedBodies(t *testing.T) {
+	router := newLimitsRouter(t, LimitsConfig{Default: RouteLimits{MaxBodyBytes: 64}})
+	large := `{"description":"` + strings.Repeat("x", 100) + `"}`
+
+	tests := []struct {
+		name       string
+		body       io.Reader
+		wantStatus int
+	}{
+		{"within the limit", strings.NewReader(`{"policyId":"pol-001"}`), http.StatusCreated},
+		{"declared length over the limit", strings.NewReader(large), http.StatusRequestEntityTooLarge},
+		// A reader the request cannot measure is sent without a length and
+		// only fails once the handler reads past the limit
+		{"streamed body over the limit", io.MultiReader(strings.NewReader(large)), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/claims", tt.body)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusRequestEntityTooLarge {
+				var body map[string]string
+				json.NewDecoder(rec.Body).Decode(&body)
+				if body["error"] != "request body exceeds the 64 byte limit" {
+					t.Errorf("Error message mismatch: got %q", body["error"])
+				}
+			}
+		})
+	}
+}
+
+func TestLimitsTimesOutSlowHandlers(t *testing.T) {
+	cfg := LimitsConfig{
+		Default: RouteLimits{Timeout: 20 * time.Millisecond},
+		Routes: map[string]RouteLimits{
+			"GET /claims/{id}": {Timeout: -1},
+		},
+	}
+
+	rec := httptest.NewRecorder()
+	newLimitsRouter(t, cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/claims/claim-001", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Route without a timeout should complete: got %d", rec.Code)
+	}
+
+	cfg.Routes = nil
+	rec = httptest.NewRecorder()
+	newLimitsRouter(t, cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/claims/claim-001", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Status mismatch: got %d, want 503", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content type mismatch: got %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), `"request timed out"`) {
+		t.Errorf("Unexpected body: %s", rec.Body.String())
+	}
+}
+
+func TestParseRouteLimits(t *testing.T) {
+	routes, err := ParseRouteLimits([]byte(`{"post /claims": {"maxBodyBytes": 65536, "timeout": "5s"}, "GET /claims/stream": {"timeout": "-1s"}}`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if got := routes["POST /claims"]; got.MaxBodyBytes != 65536 || got.Timeout != 5*time.Second {
+		t.Errorf("POST /claims mismatch: got %+v", got)
+	}
+	if got := routes["GET /claims/stream"]; got.Timeout >= 0 {
+		t.Errorf("Negative timeout should disable the limit: got %+v", got)
+	}
+
+	for _, invalid := range []string{`{"/claims": {}}`, `{"POST /claims": {"timeout": "soon"}}`, `[]`} {
+		if _, err := ParseRouteLimits([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %s", invalid)
+		}
+	}
+}
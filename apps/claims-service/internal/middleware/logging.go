@@ -1,17 +1,25 @@
 package middleware
 
 import (
+	"bufio"
+	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
-// responseWriter wraps http.ResponseWriter to capture status code
+// responseWriter wraps http.ResponseWriter to capture status code and the
+// number of body bytes written
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int
 	written    bool
+	streaming  bool // set once the response is flushed or hijacked
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
@@ -26,34 +34,136 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	if !rw.written {
 		rw.WriteHeader(http.StatusOK)
 	}
-	return rw.ResponseWriter.Write(b)
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += n
+	return n, err
 }
 
-// LoggingMiddleware logs HTTP requests and responses
-func LoggingMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
+// Flush implements http.Flusher so streaming responses work through the middleware
+func (rw *responseWriter) Flush() {
+	rw.streaming = true
+	if !rw.written {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker so WebSocket upgrades work through the middleware
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	rw.written = true
+	rw.streaming = true
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// LoggingOptions configures LoggingMiddleware
+type LoggingOptions struct {
+	// Metrics records request latency per route; nil records nothing
+	Metrics *telemetry.Registry
+	// SlowThreshold logs requests that take at least this long as warnings
+	// in the service log; 0 disables the warning
+	SlowThreshold time.Duration
+	// AccessLog receives one entry per request; nil writes them to the
+	// service log
+	AccessLog *logrus.Logger
+}
+
+// LoggingMiddleware writes one structured access entry per request with its
+// route, status, size, duration, caller, request ID and trace IDs. Latency
+// is recorded by route template so that /claims/{id} is one series rather
+// than one per claim. It must be installed with Router.Use, ahead of the
+// authentication middleware, so the matched route and the caller are known.
+func LoggingMiddleware(logger *logrus.Logger, opts LoggingOptions) func(http.Handler) http.Handler {
+	access := opts.AccessLog
+	if access == nil {
+		access = logger
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			trace := telemetry.FromRequest(r)
+			requestID := telemetry.RequestID(r)
+			route := routeTemplate(r)
+			ctx, identity := telemetry.NewIdentityContext(telemetry.NewContext(r.Context(), trace))
 
-			// Wrap the response writer to capture status code
+			// Wrap the response writer to capture status code and size
 			rw := &responseWriter{
 				ResponseWriter: w,
 				statusCode:     http.StatusOK,
 			}
+			rw.Header().Set(telemetry.HeaderRequestID, requestID)
 
 			// Call the next handler
-			next.ServeHTTP(rw, r)
+			next.ServeHTTP(rw, r.WithContext(ctx))
 
 			// Log request details
 			duration := time.Since(start)
-			logger.WithFields(logrus.Fields{
-				"method":     r.Method,
-				"path":       r.URL.Path,
-				"status":     rw.statusCode,
-				"duration":   duration.String(),
-				"remote":     r.RemoteAddr,
-				"user_agent": r.UserAgent(),
-			}).Info("HTTP request")
+			fields := logrus.Fields{
+				"method":      r.Method,
+				"path":        r.URL.Path,
+				"route":       route,
+				"status":      rw.statusCode,
+				"bytes":       rw.bytes,
+				"duration":    duration.String(),
+				"duration_ms": float64(duration.Microseconds()) / 1000,
+				"remote":      r.RemoteAddr,
+				"user_agent":  r.UserAgent(),
+				"request_id":  requestID,
+				"trace_id":    trace.TraceID,
+				"span_id":     trace.SpanID,
+			}
+			if trace.ParentSpanID != "" {
+				fields["parent_span_id"] = trace.ParentSpanID
+			}
+			if userID, role := identity.Get(); userID != "" {
+				fields["user_id"] = userID
+				if role != "" {
+					fields["role"] = role
+				}
+			}
+
+			// Streams and WebSockets stay open for as long as the client
+			// listens, so their duration says nothing about latency
+			if rw.streaming {
+				access.WithFields(fields).Info("HTTP stream closed")
+				return
+			}
+
+			if opts.Metrics != nil {
+				opts.Metrics.Observe(r.Method, route, rw.statusCode, duration)
+			}
+			if opts.SlowThreshold <= 0 || duration < opts.SlowThreshold {
+				access.WithFields(fields).Info("HTTP request")
+				return
+			}
+			fields["slow_threshold"] = opts.SlowThreshold.String()
+			if access != logger {
+				access.WithFields(fields).Info("HTTP request")
+			}
+			logger.WithFields(fields).Warn("Slow HTTP request")
 		})
 	}
 }
+
+// routeTemplate returns the template of the route that matched r, such as
+// /claims/{id}
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
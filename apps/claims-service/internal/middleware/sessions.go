@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const sessionKey contextKey = "session"
+
+// SessionTracker keeps the sessions tokens issued by POST /auth/login
+// belong to. *services.SessionService is the production implementation.
+type SessionTracker interface {
+	Touch(sessionID, ip string) (*models.Session, error)
+}
+
+// Sessions refuses tokens whose session has been ended and records when
+// and from where the others were last used. Tokens that name no session,
+// such as those minted for other services, pass through unchanged; routes
+// still check their signature and role themselves. Handlers read the
+// request's session with GetSession.
+func Sessions(tracker SessionTracker, logger *logrus.Logger) func(http.Handler) http.Handler {
+	jwtManager := NewJWTManager(logger)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if !strings.HasPrefix(header, "Bearer ") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			claims, err := jwtManager.Verify(strings.TrimPrefix(header, "Bearer "))
+			if err != nil || claims.ID == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			session, err := tracker.Touch(claims.ID, ClientIP(r))
+			if err != nil {
+				logger.WithFields(logrus.Fields{
+					"sessionId": claims.ID,
+					"userId":    claims.UserID,
+				}).Warn("Rejected token of an ended session")
+				respondError(w, http.StatusUnauthorized, "Session has ended, sign in again")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionKey, session)))
+		})
+	}
+}
+
+// GetSession returns the session of the request's token, or nil when it
+// carried none
+func GetSession(r *http.Request) *models.Session {
+	session, _ := r.Context().Value(sessionKey).(*models.Session)
+	return session
+}
+
+// ClientIP returns the address a request came from, without its port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
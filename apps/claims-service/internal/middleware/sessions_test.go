@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// liveSessions knows the sessions in it
+type liveSessions map[string]bool
+
+func (s liveSessions) Touch(sessionID, ip string) (*models.Session, error) {
+	if !s[sessionID] {
+		return nil, fmt.Errorf("session not found")
+	}
+	return &models.Session{ID: sessionID, LastSeenIP: ip}, nil
+}
+
+func TestSessionsRefuseTokensOfEndedSessions(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	handler := Sessions(liveSessions{"sess-live": true}, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if session := GetSession(r); session != nil {
+			w.Header().Set("X-Session", session.ID+" "+session.LastSeenIP)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	manager := auth.NewJWTManager("test-secret", time.Hour)
+	live, _ := manager.GenerateForSession("user-001", "staff@example.com", "", "sess-live")
+	ended, _ := manager.GenerateForSession("user-001", "staff@example.com", "", "sess-ended")
+	service, _ := manager.GenerateWithRole("search-service", "", "admin")
+
+	for _, tc := range []struct {
+		name    string
+		token   string
+		status  int
+		session string
+	}{
+		{"live session", live, http.StatusOK, "sess-live 192.0.2.1"},
+		{"ended session", ended, http.StatusUnauthorized, ""},
+		{"token without a session", service, http.StatusOK, ""},
+		{"no token", "", http.StatusOK, ""},
+	} {
+		req := httptest.NewRequest("GET", "/claims", nil)
+		req.RemoteAddr = "192.0.2.1:51234"
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.status || rec.Header().Get("X-Session") != tc.session {
+			t.Errorf("%s: got %d with session %q", tc.name, rec.Code, rec.Header().Get("X-Session"))
+		}
+	}
+}
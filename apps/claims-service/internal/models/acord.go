@@ -0,0 +1,15 @@
+package models
+
+// ACORD export formats
+const (
+	AcordFormatXML = "xml"
+	AcordFormatEDI = "edi"
+)
+
+// AcordRejection is a claim left out of an ACORD export because its
+// notification failed schema validation
+type AcordRejection struct {
+	ClaimID     string   `json:"claimId"`
+	ClaimNumber string   `json:"claimNumber"`
+	Problems    []string `json:"problems"` // each naming the element at fault
+}
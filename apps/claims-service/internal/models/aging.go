@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// AgingBucket is a range of whole days a claim has been in its current
+// status. MaxDays is 0 for the last, open-ended bucket.
+type AgingBucket struct {
+	Name    string
+	MinDays int
+	MaxDays int
+}
+
+// AgingBuckets lists the aging report's buckets, youngest first
+var AgingBuckets = []AgingBucket{
+	{Name: "0-7", MinDays: 0, MaxDays: 7},
+	{Name: "8-30", MinDays: 8, MaxDays: 30},
+	{Name: "31-90", MinDays: 31, MaxDays: 90},
+	{Name: "90+", MinDays: 91},
+}
+
+// AgingBucketFor returns the name of the bucket for a number of days
+func AgingBucketFor(days int) string {
+	for _, bucket := range AgingBuckets {
+		if bucket.MaxDays == 0 || days <= bucket.MaxDays {
+			return bucket.Name
+		}
+	}
+	return AgingBuckets[len(AgingBuckets)-1].Name
+}
+
+// AgingTotals counts claims and sums their amounts
+type AgingTotals struct {
+	Count       int     `json:"count"`
+	TotalAmount float64 `json:"totalAmount"`
+}
+
+// Add counts a claim
+func (t *AgingTotals) Add(claim *Claim) {
+	t.Count++
+	t.TotalAmount += claim.Amount
+}
+
+// AgingBreakdown is one group of claims split into the aging buckets
+type AgingBreakdown struct {
+	AgingTotals
+	Buckets map[string]*AgingTotals `json:"buckets"` // by AgingBucket name
+}
+
+// NewAgingBreakdown creates a breakdown with every bucket at zero
+func NewAgingBreakdown() *AgingBreakdown {
+	b := &AgingBreakdown{Buckets: make(map[string]*AgingTotals, len(AgingBuckets))}
+	for _, bucket := range AgingBuckets {
+		b.Buckets[bucket.Name] = &AgingTotals{}
+	}
+	return b
+}
+
+// Add counts a claim in the bucket for days
+func (b *AgingBreakdown) Add(claim *Claim, days int) {
+	b.AgingTotals.Add(claim)
+	b.Buckets[AgingBucketFor(days)].Add(claim)
+}
+
+// ClaimsAgingReport groups the open claims by how long they have been in
+// their current status, overall and per assigned adjuster
+type ClaimsAgingReport struct {
+	GeneratedAt time.Time                  `json:"generatedAt"`
+	Claims      *AgingBreakdown            `json:"claims"`
+	ByAdjuster  map[string]*AgingBreakdown `json:"byAdjuster"` // by adjuster ID; "unassigned" for claims without one
+}
+
+// UnassignedAdjuster groups claims no adjuster is assigned to in
+// ClaimsAgingReport.ByAdjuster
+const UnassignedAdjuster = "unassigned"
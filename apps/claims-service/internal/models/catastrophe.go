@@ -0,0 +1,102 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Catastrophe is a declared event, such as a storm, that causes a surge of
+// related claims. Claims are tagged to it so exposure can be tracked as one.
+type Catastrophe struct {
+	ID          string    `json:"id"`
+	Code        string    `json:"code"` // e.g. CAT-2024-001
+	Name        string    `json:"name"`
+	Peril       string    `json:"peril"`       // see Perils
+	Region      string    `json:"region"`      // human-readable area, e.g. "Central Illinois"
+	PostalCodes []string  `json:"postalCodes"` // ZIP codes or prefixes, e.g. "627" covers 62701-62799
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Covers reports whether a loss on the given date and postal code falls
+// within the event's date range and region. Dates are inclusive.
+func (c *Catastrophe) Covers(incident time.Time, postalCode string) bool {
+	if incident.Before(c.StartDate) || incident.After(c.EndDate) {
+		return false
+	}
+
+	postalCode = strings.TrimSpace(postalCode)
+	if postalCode == "" {
+		return false
+	}
+	for _, prefix := range c.PostalCodes {
+		if strings.HasPrefix(postalCode, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateCatastropheRequest represents a request to declare a catastrophe event
+type CreateCatastropheRequest struct {
+	Name        string    `json:"name"`
+	Peril       string    `json:"peril"`
+	Region      string    `json:"region"`
+	PostalCodes []string  `json:"postalCodes"`
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate"`
+}
+
+// TagClaimRequest represents a request to tag a claim to a catastrophe
+// event manually. An empty CatastropheID removes the tag.
+type TagClaimRequest struct {
+	CatastropheID string `json:"catastropheId"`
+}
+
+// CatastropheExposure aggregates the claims tagged to a catastrophe event
+type CatastropheExposure struct {
+	Catastrophe    *Catastrophe `json:"catastrophe"`
+	Claims         *ClaimStats  `json:"claims"`
+	OpenAmount     float64      `json:"openAmount"`     // submitted, under review or held
+	ApprovedAmount float64      `json:"approvedAmount"` // approved for payout
+	AutoTagged     int          `json:"autoTagged"`
+	ManuallyTagged int          `json:"manuallyTagged"`
+}
+
+// Catastrophe perils
+const (
+	PerilWindstorm   = "windstorm"
+	PerilHail        = "hail"
+	PerilFlood       = "flood"
+	PerilWildfire    = "wildfire"
+	PerilEarthquake  = "earthquake"
+	PerilWinterStorm = "winter_storm"
+)
+
+// Perils lists every valid catastrophe peril
+var Perils = []string{
+	PerilWindstorm,
+	PerilHail,
+	PerilFlood,
+	PerilWildfire,
+	PerilEarthquake,
+	PerilWinterStorm,
+}
+
+// ValidatePeril checks if the catastrophe peril is valid
+func ValidatePeril(peril string) bool {
+	for _, valid := range Perils {
+		if peril == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// How a claim was tagged to a catastrophe event
+const (
+	CatastropheTagAuto   = "auto"
+	CatastropheTagManual = "manual"
+)
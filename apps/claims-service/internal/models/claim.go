@@ -2,28 +2,96 @@ package models
 
 import "time"
 
-// Claim represents an insurance claim
+// Claim represents an insurance claim. Fields tagged shape:"roles=..."
+// are for staff and left out of customers' responses.
 type Claim struct {
-	ID            string     `json:"id"`
-	PolicyID      string     `json:"policyId"`
-	CustomerID    string     `json:"customerId"`
-	ClaimNumber   string     `json:"claimNumber"`
-	Type          string     `json:"type"`          // accident, theft, damage
-	Status        string     `json:"status"`        // submitted, under_review, approved, rejected
-	Amount        float64    `json:"amount"`
-	Description   string     `json:"description"`
-	SubmittedDate time.Time  `json:"submittedDate"`
-	ReviewedDate  *time.Time `json:"reviewedDate"`
-	CreatedAt     time.Time  `json:"createdAt"`
-	UpdatedAt     time.Time  `json:"updatedAt"`
-}
-
-// ClaimFilters represents filters for claim queries
+	ID             string            `json:"id"`
+	PolicyID       string            `json:"policyId"`
+	CustomerID     string            `json:"customerId"`
+	ClaimNumber    string            `json:"claimNumber"`
+	Type           string            `json:"type"`              // one of the policy type's claim types, see GET /claim-types
+	SubType        string            `json:"subType,omitempty"` // narrows Type, such as water_damage or windshield
+	Status         string            `json:"status"`            // submitted, under_review, pending_payment, approved, rejected
+	Amount         float64           `json:"amount"`
+	Description    string            `json:"description"`
+	Queue          string            `json:"queue,omitempty" shape:"roles=admin|adjuster"`      // adjuster work queue (policy line)
+	AssignedTo     string            `json:"assignedTo,omitempty" shape:"roles=admin|adjuster"` // adjuster user ID
+	Escalated      bool              `json:"escalated,omitempty" shape:"roles=admin|adjuster"`
+	RejectionCodes []string          `json:"rejectionCodes,omitempty"`                              // set when rejected, see RejectionCodes
+	DuplicateOf    string            `json:"duplicateOf,omitempty"`                                 // filed with force despite matching this claim
+	ApprovalReason string            `json:"approvalReason,omitempty" shape:"roles=admin|adjuster"` // why intake approved, held, rejected or sent the claim to review
+	IncidentDate   *time.Time        `json:"incidentDate,omitempty"`                                // when the loss occurred
+	LossLocation   *LossLocation     `json:"lossLocation,omitempty"`                                // where the loss occurred
+	CatastropheID  string            `json:"catastropheId,omitempty"`                               // catastrophe event the loss belongs to
+	CatastropheTag string            `json:"catastropheTag,omitempty" shape:"roles=admin|adjuster"` // auto or manual, see CatastropheTagAuto
+	Offers         []SettlementOffer `json:"offers,omitempty"`                                      // settlement offers, oldest first
+	DamageEstimate *DamageEstimate   `json:"damageEstimate,omitempty" shape:"roles=admin|adjuster"` // priced from the claim's photos, see DamageEstimate
+	SubmittedDate  time.Time         `json:"submittedDate"`
+	ReviewedDate   *time.Time        `json:"reviewedDate"`
+	StatusSince    *time.Time        `json:"statusSince,omitempty"` // when the claim entered its status
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+	ArchivedAt     *time.Time        `json:"archivedAt,omitempty"` // when the retention policy moved the claim to the archive
+	Version        int               `json:"version"`              // incremented on every change, see BulkStatusUpdate
+}
+
+// EnteredStatusAt returns when the claim entered its current status. Claims
+// loaded from seed data do not record it, so their review date, or else
+// their submission date, is used instead.
+func (c *Claim) EnteredStatusAt() time.Time {
+	switch {
+	case c.StatusSince != nil:
+		return *c.StatusSince
+	case c.ReviewedDate != nil:
+		return *c.ReviewedDate
+	default:
+		return c.SubmittedDate
+	}
+}
+
+// LossLocation describes where a loss occurred
+type LossLocation struct {
+	Street      string `json:"street,omitempty"`
+	City        string `json:"city"`
+	State       string `json:"state,omitempty"`
+	PostalCode  string `json:"postalCode,omitempty"`
+	Country     string `json:"country"`
+	Description string `json:"description,omitempty"` // e.g. "Highway 101 northbound near exit 12"
+}
+
+// ClaimFilters represents filters for claim queries. Zero times leave the
+// corresponding bound open; bounds are inclusive.
 type ClaimFilters struct {
-	PolicyID   string
-	CustomerID string
-	Status     string
-	Type       string
+	PolicyID      string
+	CustomerID    string
+	Status        string
+	Type          string
+	SubType       string
+	CatastropheID string
+	IncidentFrom  time.Time
+	IncidentTo    time.Time
+	SubmittedFrom time.Time
+	SubmittedTo   time.Time
+
+	// IncludeArchived also returns the claims the retention policy moved to
+	// the archive. Only staff may ask for them.
+	IncludeArchived bool
+}
+
+// IsEmpty reports whether no filter is set
+func (f *ClaimFilters) IsEmpty() bool {
+	return f == nil || *f == ClaimFilters{}
+}
+
+// ClaimViewer identifies who is reading claims
+type ClaimViewer struct {
+	ID   string
+	Role string // "admin" or "adjuster" for staff, "" for customers
+}
+
+// IsStaff reports whether the viewer may read every customer's claims
+func (v ClaimViewer) IsStaff() bool {
+	return v.Role == "admin" || v.Role == "adjuster"
 }
 
 // Matches checks if a claim matches the given filters
@@ -48,47 +116,171 @@ func (c *Claim) Matches(filters *ClaimFilters) bool {
 		return false
 	}
 
+	// Sub-type filter
+	if filters.SubType != "" && c.SubType != filters.SubType {
+		return false
+	}
+
+	// Catastrophe event filter
+	if filters.CatastropheID != "" && c.CatastropheID != filters.CatastropheID {
+		return false
+	}
+
+	// Incident date range; claims without an incident date never match
+	if !filters.IncidentFrom.IsZero() || !filters.IncidentTo.IsZero() {
+		if c.IncidentDate == nil || !inRange(*c.IncidentDate, filters.IncidentFrom, filters.IncidentTo) {
+			return false
+		}
+	}
+
+	// Submission date range
+	if !inRange(c.SubmittedDate, filters.SubmittedFrom, filters.SubmittedTo) {
+		return false
+	}
+
+	return true
+}
+
+// inRange checks t against inclusive bounds, ignoring zero bounds
+func inRange(t, from, to time.Time) bool {
+	if !from.IsZero() && t.Before(from) {
+		return false
+	}
+	if !to.IsZero() && t.After(to) {
+		return false
+	}
 	return true
 }
 
 // CreateClaimRequest represents a request to create a new claim
 type CreateClaimRequest struct {
-	PolicyID    string  `json:"policyId"`
-	CustomerID  string  `json:"customerId"`
-	Type        string  `json:"type"`
-	Amount      float64 `json:"amount"`
-	Description string  `json:"description"`
+	PolicyID     string        `json:"policyId"`
+	CustomerID   string        `json:"customerId"`
+	Type         string        `json:"type"`
+	SubType      string        `json:"subType,omitempty"`
+	Amount       float64       `json:"amount"`
+	Description  string        `json:"description"`
+	Force        bool          `json:"force,omitempty"`        // file even if it looks like a duplicate
+	IncidentDate *time.Time    `json:"incidentDate,omitempty"` // must fall within the policy term
+	LossLocation *LossLocation `json:"lossLocation,omitempty"`
 }
 
 // UpdateClaimRequest represents a request to update a claim
 type UpdateClaimRequest struct {
-	Amount      *float64 `json:"amount,omitempty"`
-	Description *string  `json:"description,omitempty"`
+	Amount       *float64      `json:"amount,omitempty"`
+	Description  *string       `json:"description,omitempty"`
+	IncidentDate *time.Time    `json:"incidentDate,omitempty"`
+	LossLocation *LossLocation `json:"lossLocation,omitempty"`
 }
 
 // UpdateClaimStatusRequest represents a request to update claim status
 type UpdateClaimStatusRequest struct {
-	Status string `json:"status"`
-	Notes  string `json:"notes,omitempty"`
+	Status         string   `json:"status"`
+	Notes          string   `json:"notes,omitempty"`
+	RejectionCodes []string `json:"rejectionCodes,omitempty"` // required when status is rejected
 }
 
-// ValidateClaimType checks if the claim type is valid
-func ValidateClaimType(claimType string) bool {
-	validTypes := map[string]bool{
-		"accident": true,
-		"theft":    true,
-		"damage":   true,
-	}
-	return validTypes[claimType]
+// MaxBulkStatusUpdates is the most status changes one bulk request may hold
+const MaxBulkStatusUpdates = 100
+
+// Bulk status update failure codes
+const (
+	BulkNotFound        = "not_found"
+	BulkVersionConflict = "version_conflict"
+	BulkInvalid         = "invalid"
+)
+
+// BulkStatusUpdate is one status change in a bulk update. Version is the
+// claim version the change was decided on; the change only applies if the
+// claim has not changed since.
+type BulkStatusUpdate struct {
+	ClaimID string `json:"claimId"`
+	Version int    `json:"version"`
+	UpdateClaimStatusRequest
+}
+
+// BulkStatusResult is the outcome of one status change in a bulk update
+type BulkStatusResult struct {
+	ClaimID string `json:"claimId"`
+	Success bool   `json:"success"`
+	Claim   *Claim `json:"claim,omitempty"` // the updated claim, on success
+	Error   string `json:"error,omitempty"`
+	Code    string `json:"code,omitempty"` // BulkNotFound, BulkVersionConflict or BulkInvalid, on failure
+}
+
+// BulkStatusResponse reports every change in a bulk update, in request order
+type BulkStatusResponse struct {
+	Results   []BulkStatusResult `json:"results"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+}
+
+// ClaimStats summarizes a set of claims
+type ClaimStats struct {
+	Total       int            `json:"total"`
+	TotalAmount float64        `json:"totalAmount"`
+	ByStatus    map[string]int `json:"byStatus"`
+	ByType      map[string]int `json:"byType"`
+	// RejectionReasons counts rejected claims per code; a claim rejected
+	// for several reasons counts once under each
+	RejectionReasons map[string]int `json:"rejectionReasons"`
+}
+
+// HoldRecheckResult summarizes one pass over the claims held pending payment
+type HoldRecheckResult struct {
+	Checked   int       `json:"checked"`
+	Released  []string  `json:"released"`  // policy reinstated, moved to under_review
+	Rejected  []string  `json:"rejected"`  // policy lapsed or cancelled
+	StillHeld []string  `json:"stillHeld"` // policy still in grace, or lookup failed
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// ArchiveResult summarizes one pass of the retention policy over the claims
+type ArchiveResult struct {
+	PolicyVersion string    `json:"policyVersion"`
+	Checked       int       `json:"checked"`
+	Archived      []string  `json:"archived"` // closed long enough ago, moved to the archive
+	Failed        []string  `json:"failed"`   // due, but changed or unwritable; retried on the next pass
+	ArchivedAt    time.Time `json:"archivedAt"`
+}
+
+// AssignClaimRequest represents a request to assign a claim to an adjuster
+type AssignClaimRequest struct {
+	AdjusterID string `json:"adjusterId"`
+	Queue      string `json:"queue,omitempty"`
+}
+
+// EscalateClaimRequest represents a request to escalate a claim
+type EscalateClaimRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Rejection reason codes
+const (
+	RejectionNotCovered       = "not_covered"
+	RejectionLateFiling       = "late_filing"
+	RejectionFraudSuspected   = "fraud_suspected"
+	RejectionInsufficientDocs = "insufficient_docs"
+	RejectionDuplicate        = "duplicate"
+	RejectionPolicyLapsed     = "policy_lapsed"
+)
+
+// RejectionCodes lists every valid rejection reason code
+var RejectionCodes = []string{
+	RejectionNotCovered,
+	RejectionLateFiling,
+	RejectionFraudSuspected,
+	RejectionInsufficientDocs,
+	RejectionDuplicate,
+	RejectionPolicyLapsed,
 }
 
-// ValidateClaimStatus checks if the claim status is valid
-func ValidateClaimStatus(status string) bool {
-	validStatuses := map[string]bool{
-		"submitted":    true,
-		"under_review": true,
-		"approved":     true,
-		"rejected":     true,
+// ValidateRejectionCode checks if the rejection reason code is valid
+func ValidateRejectionCode(code string) bool {
+	for _, valid := range RejectionCodes {
+		if code == valid {
+			return true
+		}
 	}
-	return validStatuses[status]
+	return false
 }
@@ -0,0 +1,22 @@
+package models
+
+// ClaimType is a kind of loss claims may be filed for, with the sub-types
+// that narrow it down
+type ClaimType struct {
+	Code     string         `json:"code"`
+	Label    string         `json:"label"`
+	SubTypes []ClaimSubType `json:"subTypes"`
+}
+
+// ClaimSubType narrows a claim type, such as water_damage or windshield
+type ClaimSubType struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+}
+
+// ClaimTypesResponse represents the response for GET /claim-types: the
+// claim types of each policy type, or of the one asked for
+type ClaimTypesResponse struct {
+	Version     string                 `json:"version"`
+	PolicyTypes map[string][]ClaimType `json:"policyTypes"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Comment visibilities
+const (
+	CommentInternal = "internal" // staff only
+	CommentCustomer = "customer" // also shown to the claimant
+)
+
+// CommentRoleCustomer is the author role of comments left by customers.
+// Staff comments carry the role from their token (adjuster or admin).
+const CommentRoleCustomer = "customer"
+
+// MaxCommentLength is the longest comment body accepted, in bytes
+const MaxCommentLength = 5000
+
+// Comment is a note left on a claim
+type Comment struct {
+	ID         string        `json:"id"`
+	ClaimID    string        `json:"claimId"`
+	AuthorID   string        `json:"authorId"`
+	AuthorRole string        `json:"authorRole"` // customer, adjuster or admin
+	Body       string        `json:"body"`
+	Visibility string        `json:"visibility"` // internal or customer
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+	Edits      []CommentEdit `json:"edits,omitempty"` // earlier versions, oldest first
+}
+
+// CommentEdit is an earlier version of an edited comment
+type CommentEdit struct {
+	Body       string    `json:"body"`
+	Visibility string    `json:"visibility"`
+	EditedAt   time.Time `json:"editedAt"` // when this version was replaced
+}
+
+// CommentAuthor identifies who is reading or writing comments
+type CommentAuthor struct {
+	ID   string
+	Role string // CommentRoleCustomer or a staff role
+}
+
+// IsStaff reports whether the author may see and write internal comments
+func (a CommentAuthor) IsStaff() bool {
+	return a.Role != CommentRoleCustomer
+}
+
+// CreateCommentRequest represents a request to comment on a claim.
+// Visibility defaults to internal for staff and customer for customers.
+type CreateCommentRequest struct {
+	Body       string `json:"body"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// UpdateCommentRequest represents an edit to a comment. An empty visibility
+// keeps the current one.
+type UpdateCommentRequest struct {
+	Body       string `json:"body"`
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// ValidateCommentVisibility checks if a comment visibility is valid
+func ValidateCommentVisibility(visibility string) bool {
+	return visibility == CommentInternal || visibility == CommentCustomer
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Consistency issue severities
+const (
+	// SeverityError marks a broken reference, such as a claim filed against a
+	// policy that does not exist
+	SeverityError = "error"
+	// SeverityWarning marks a reference that resolves but disagrees with the
+	// referenced record, such as a loss dated outside the policy term
+	SeverityWarning = "warning"
+	// SeverityInfo marks a check that could not run
+	SeverityInfo = "info"
+)
+
+// ConsistencyIssue is one finding of a referential integrity check
+type ConsistencyIssue struct {
+	Severity  string `json:"severity"`
+	Check     string `json:"check"`               // e.g. claim.policy_exists
+	EntityID  string `json:"entityId,omitempty"`  // record holding the reference
+	Reference string `json:"reference,omitempty"` // referenced record ID
+	Message   string `json:"message"`
+}
+
+// ConsistencyReport lists the references in this service's data that do not
+// resolve, or disagree with, the services that own them
+type ConsistencyReport struct {
+	Service     string             `json:"service"`
+	GeneratedAt time.Time          `json:"generatedAt"`
+	Checked     int                `json:"checked"` // records examined
+	Summary     map[string]int     `json:"summary"` // issue count per severity
+	Issues      []ConsistencyIssue `json:"issues"`
+}
+
+// NewConsistencyReport creates an empty report for a service
+func NewConsistencyReport(service string) *ConsistencyReport {
+	return &ConsistencyReport{
+		Service:     service,
+		GeneratedAt: time.Now(),
+		Summary: map[string]int{
+			SeverityError:   0,
+			SeverityWarning: 0,
+			SeverityInfo:    0,
+		},
+		Issues: []ConsistencyIssue{},
+	}
+}
+
+// Add records an issue and counts it in the summary
+func (r *ConsistencyReport) Add(issue ConsistencyIssue) {
+	r.Issues = append(r.Issues, issue)
+	r.Summary[issue.Severity]++
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// CustomerClaim is the claimant's own view of a claim, served under
+// /my/claims. It names the fields a customer may see rather than leaving
+// out the internal ones, so anything added to Claim for staff, such as
+// queues, assignments or intake decisions, stays hidden until it is added
+// here.
+type CustomerClaim struct {
+	ID             string          `json:"id"`
+	ClaimNumber    string          `json:"claimNumber"`
+	PolicyID       string          `json:"policyId"`
+	Type           string          `json:"type"`
+	SubType        string          `json:"subType,omitempty"`
+	Status         string          `json:"status"`
+	Amount         float64         `json:"amount"`
+	Description    string          `json:"description"`
+	RejectionCodes []string        `json:"rejectionCodes,omitempty"` // fraud_suspected is left out
+	IncidentDate   *time.Time      `json:"incidentDate,omitempty"`
+	LossLocation   *LossLocation   `json:"lossLocation,omitempty"`
+	Offers         []CustomerOffer `json:"offers,omitempty"`
+	SubmittedDate  time.Time       `json:"submittedDate"`
+	ReviewedDate   *time.Time      `json:"reviewedDate,omitempty"`
+	StatusSince    time.Time       `json:"statusSince"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// CustomerOffer is a settlement offer as the claimant sees it, without the
+// staff member who made it
+type CustomerOffer struct {
+	ID              string     `json:"id"`
+	Amount          float64    `json:"amount"`
+	Status          string     `json:"status"`
+	Notes           string     `json:"notes,omitempty"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	RespondedAt     *time.Time `json:"respondedAt,omitempty"`
+	RejectionReason string     `json:"rejectionReason,omitempty"`
+}
+
+// NewCustomerClaim projects a claim onto what its claimant may see.
+// Suspected fraud is an investigation matter, so it is not among the
+// rejection reasons shown.
+func NewCustomerClaim(c *Claim) *CustomerClaim {
+	view := &CustomerClaim{
+		ID:            c.ID,
+		ClaimNumber:   c.ClaimNumber,
+		PolicyID:      c.PolicyID,
+		Type:          c.Type,
+		SubType:       c.SubType,
+		Status:        c.Status,
+		Amount:        c.Amount,
+		Description:   c.Description,
+		IncidentDate:  c.IncidentDate,
+		LossLocation:  c.LossLocation,
+		SubmittedDate: c.SubmittedDate,
+		ReviewedDate:  c.ReviewedDate,
+		StatusSince:   c.EnteredStatusAt(),
+		UpdatedAt:     c.UpdatedAt,
+	}
+	for _, code := range c.RejectionCodes {
+		if code != RejectionFraudSuspected {
+			view.RejectionCodes = append(view.RejectionCodes, code)
+		}
+	}
+	for _, offer := range c.Offers {
+		view.Offers = append(view.Offers, CustomerOffer{
+			ID:              offer.ID,
+			Amount:          offer.Amount,
+			Status:          offer.Status,
+			Notes:           offer.Notes,
+			ExpiresAt:       offer.ExpiresAt,
+			CreatedAt:       offer.CreatedAt,
+			RespondedAt:     offer.RespondedAt,
+			RejectionReason: offer.RejectionReason,
+		})
+	}
+	return view
+}
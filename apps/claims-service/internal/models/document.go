@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// MaxDocumentSize is the largest document that can be attached to a claim
+const MaxDocumentSize = 10 << 20
+
+// Document scan statuses. Documents uploaded before scanning was added
+// have none and are scanned as if pending.
+const (
+	ScanPending = "pending"     // waiting for the malware scanner
+	ScanClean   = "clean"       // scanned and found clean; may be downloaded
+	ScanFailed  = "scan_failed" // malware found, or the scan failed; quarantined
+)
+
+// ClaimDocument describes a file attached to a claim, such as a photo of
+// the damage or a repair estimate. The content is served separately, once
+// the document has been scanned clean.
+type ClaimDocument struct {
+	ID          string     `json:"id"`
+	ClaimID     string     `json:"claimId"`
+	FileName    string     `json:"fileName"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`                 // bytes
+	UploadedBy  string     `json:"uploadedBy,omitempty"` // user ID of the uploader
+	UploadedAt  time.Time  `json:"uploadedAt"`
+	ScanStatus  string     `json:"scanStatus,omitempty"` // pending, clean or scan_failed
+	ScanThreat  string     `json:"scanThreat,omitempty"` // the malware found, when quarantined for it
+	ScanError   string     `json:"scanError,omitempty"`  // why the scan failed, when it could not finish
+	ScannedBy   string     `json:"scannedBy,omitempty"`  // the scanner's name
+	ScannedAt   *time.Time `json:"scannedAt,omitempty"`
+}
+
+// DocumentLink is a signed URL that downloads a document's content without
+// credentials until it expires
+type DocumentLink struct {
+	DocumentID string    `json:"documentId"`
+	URL        string    `json:"url"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+// Scanned reports whether the document has been through the malware
+// scanner, whatever it found
+func (d *ClaimDocument) Scanned() bool {
+	return d.ScanStatus == ScanClean || d.ScanStatus == ScanFailed
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Draft statuses. Drafts wait in the draft status until an agent confirms
+// them, filing a claim, or discards them.
+const (
+	DraftPending   = "draft"
+	DraftConfirmed = "confirmed"
+	DraftDiscarded = "discarded"
+)
+
+// DraftSourceEmail marks drafts parsed from an inbound claim email
+const DraftSourceEmail = "email"
+
+// MaxEmailSize is the largest inbound claim email accepted, in bytes. It
+// leaves room for a document-sized attachment after base64 encoding.
+const MaxEmailSize = 2 * MaxDocumentSize
+
+// ClaimDraft is a claim parsed from an inbound channel, such as an email,
+// that an agent has to confirm before it is filed. Request holds what could
+// be read from the message; Issues lists what could not, for the agent to
+// fill in when confirming.
+type ClaimDraft struct {
+	ID            string             `json:"id"`
+	Status        string             `json:"status"` // draft, confirmed or discarded
+	Source        string             `json:"source"`
+	From          string             `json:"from,omitempty"`      // sender address
+	Subject       string             `json:"subject,omitempty"`   // original subject line
+	MessageID     string             `json:"messageId,omitempty"` // Message-ID header, used to ignore redelivered emails
+	ReceivedAt    time.Time          `json:"receivedAt"`
+	Request       CreateClaimRequest `json:"request"`
+	Attachments   []DraftAttachment  `json:"attachments"`
+	Issues        []string           `json:"issues,omitempty"`
+	ClaimID       string             `json:"claimId,omitempty"` // the claim filed on confirmation
+	ReviewedBy    string             `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time         `json:"reviewedAt,omitempty"`
+	DiscardReason string             `json:"discardReason,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+}
+
+// DraftAttachment describes a file that came with a draft. It is attached
+// to the claim as a document when the draft is confirmed.
+type DraftAttachment struct {
+	ID          string `json:"id"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"` // bytes
+}
+
+// ConfirmDraftRequest files a draft as a claim. Fields that are set
+// replace what was parsed from the draft, so an agent can correct or
+// complete it in the same step.
+type ConfirmDraftRequest struct {
+	PolicyID     *string       `json:"policyId,omitempty"`
+	CustomerID   *string       `json:"customerId,omitempty"`
+	Type         *string       `json:"type,omitempty"`
+	SubType      *string       `json:"subType,omitempty"`
+	Amount       *float64      `json:"amount,omitempty"`
+	Description  *string       `json:"description,omitempty"`
+	IncidentDate *time.Time    `json:"incidentDate,omitempty"`
+	LossLocation *LossLocation `json:"lossLocation,omitempty"`
+	Force        bool          `json:"force,omitempty"` // file even if it looks like a duplicate
+}
+
+// DiscardDraftRequest represents a request to drop a draft without filing
+// it
+type DiscardDraftRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ValidateDraftStatus checks if a draft status is valid
+func ValidateDraftStatus(status string) bool {
+	return status == DraftPending || status == DraftConfirmed || status == DraftDiscarded
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DamageEstimate is a damage estimator's price for the repairs on a claim,
+// made from the photos attached to it, for adjusters to compare with the
+// claimed amount. It is replaced each time another photo is attached.
+type DamageEstimate struct {
+	Amount        float64   `json:"amount"`        // estimated repair cost
+	Confidence    float64   `json:"confidence"`    // from 0 to 1
+	ClaimedAmount float64   `json:"claimedAmount"` // the claim's amount when it was estimated
+	Estimator     string    `json:"estimator"`     // which estimator priced it, e.g. stub
+	DocumentIDs   []string  `json:"documentIds"`   // the photos it was made from
+	EstimatedAt   time.Time `json:"estimatedAt"`
+}
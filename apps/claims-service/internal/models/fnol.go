@@ -0,0 +1,124 @@
+package models
+
+import "time"
+
+// FNOL statuses
+const (
+	FNOLInProgress = "in_progress"
+	FNOLSubmitted  = "submitted"
+)
+
+// FNOL steps, filled in this order
+const (
+	FNOLStepIncident = 1
+	FNOLStepParties  = 2
+	FNOLStepDamage   = 3
+
+	// FNOLSteps is the number of steps a report has
+	FNOLSteps = 3
+)
+
+// MaxFNOLParties is the most parties one report may list
+const MaxFNOLParties = 20
+
+// FNOL is a first notice of loss filled in step by step, such as from the
+// mobile app, and submitted as a claim once every step is complete.
+// CompletedSteps lists the steps whose section passed validation; NextStep
+// is the first step still to fill, or 0 when the report can be submitted.
+type FNOL struct {
+	ID             string       `json:"id"`
+	CustomerID     string       `json:"customerId"`
+	Status         string       `json:"status"` // in_progress or submitted
+	Incident       FNOLIncident `json:"incident"`
+	Parties        *FNOLParties `json:"parties,omitempty"`
+	Damage         *FNOLDamage  `json:"damage,omitempty"`
+	CompletedSteps []int        `json:"completedSteps"`
+	NextStep       int          `json:"nextStep"`
+	ClaimID        string       `json:"claimId,omitempty"` // the claim filed on submission
+	StartedBy      string       `json:"startedBy"`         // the customer, or the agent reporting for them
+	CreatedAt      time.Time    `json:"createdAt"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+	SubmittedAt    *time.Time   `json:"submittedAt,omitempty"`
+}
+
+// FNOLIncident is step 1: what happened, when and where
+type FNOLIncident struct {
+	PolicyID     string        `json:"policyId"`
+	Type         string        `json:"type"`              // a claim type of the policy's type
+	SubType      string        `json:"subType,omitempty"` // optional, one of the type's sub-types
+	IncidentDate *time.Time    `json:"incidentDate,omitempty"`
+	LossLocation *LossLocation `json:"lossLocation,omitempty"`
+	Description  string        `json:"description"`
+}
+
+// FNOL party roles
+const (
+	PartyDriver        = "driver"
+	PartyPassenger     = "passenger"
+	PartyPedestrian    = "pedestrian"
+	PartyOtherDriver   = "other_driver"
+	PartyPropertyOwner = "property_owner"
+	PartyWitness       = "witness"
+)
+
+// FNOLParties is step 2: who was involved. A theft or a single-vehicle
+// loss may list no parties.
+type FNOLParties struct {
+	Parties            []FNOLParty `json:"parties"`
+	PoliceReportNumber string      `json:"policeReportNumber,omitempty"`
+}
+
+// FNOLParty is a person involved in the incident
+type FNOLParty struct {
+	Role         string `json:"role"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone,omitempty"`
+	Email        string `json:"email,omitempty"`
+	VehiclePlate string `json:"vehiclePlate,omitempty"`
+	Insurer      string `json:"insurer,omitempty"` // the party's own insurer, for other drivers
+}
+
+// FNOLDamage is step 3: what was damaged and what it will cost. Without an
+// estimated amount the items' costs are added up.
+type FNOLDamage struct {
+	EstimatedAmount float64          `json:"estimatedAmount"`
+	Items           []FNOLDamageItem `json:"items,omitempty"`
+	Injuries        bool             `json:"injuries"`
+	VehicleDrivable *bool            `json:"vehicleDrivable,omitempty"`
+}
+
+// FNOLDamageItem is one damaged item with its estimated repair or
+// replacement cost
+type FNOLDamageItem struct {
+	Description   string  `json:"description"`
+	EstimatedCost float64 `json:"estimatedCost"`
+}
+
+// StartFNOLRequest starts a report with whatever incident details are
+// already known; they are checked when step 1 is filled. Staff starting a
+// report for a customer must give the customer's ID.
+type StartFNOLRequest struct {
+	CustomerID string `json:"customerId,omitempty"`
+	FNOLIncident
+}
+
+// FNOLReporter identifies who is filling in a report
+type FNOLReporter struct {
+	ID   string
+	Role string // "admin" or "adjuster" for staff, "" for customers
+}
+
+// IsStaff reports whether the reporter may fill in reports for any
+// customer
+func (r FNOLReporter) IsStaff() bool {
+	return r.Role == "admin" || r.Role == "adjuster"
+}
+
+// ValidatePartyRole checks if an FNOL party role is valid
+func ValidatePartyRole(role string) bool {
+	switch role {
+	case PartyDriver, PartyPassenger, PartyPedestrian, PartyOtherDriver, PartyPropertyOwner, PartyWitness:
+		return true
+	}
+	return false
+}
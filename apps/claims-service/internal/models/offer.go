@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// Settlement offer statuses
+const (
+	OfferPending   = "pending"   // waiting for the customer
+	OfferAccepted  = "accepted"  // the claim can be paid out up to its amount
+	OfferRejected  = "rejected"  // declined by the customer
+	OfferExpired   = "expired"   // not answered before expiresAt
+	OfferWithdrawn = "withdrawn" // replaced by a later offer
+)
+
+// DefaultOfferValidity is how long an offer made without an expiry stays
+// open
+const DefaultOfferValidity = 14 * 24 * time.Hour
+
+// SettlementOffer is an amount the carrier offers to settle an approved
+// claim for. The customer accepts or rejects it; payments-service only
+// pays out a claim with an accepted offer.
+type SettlementOffer struct {
+	ID              string     `json:"id"`
+	Amount          float64    `json:"amount"`
+	Status          string     `json:"status"` // see Offer status constants
+	Notes           string     `json:"notes,omitempty"`
+	MadeBy          string     `json:"madeBy,omitempty" shape:"roles=admin|adjuster"` // staff user ID
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	RespondedAt     *time.Time `json:"respondedAt,omitempty"`     // when the customer accepted or rejected it
+	RejectionReason string     `json:"rejectionReason,omitempty"` // the customer's reason for rejecting it
+}
+
+// ExpireOffers marks pending offers whose expiry has passed as expired,
+// reporting whether any was. The offers are copied first, since claims
+// read from the store share their slices with the stored claim.
+func (c *Claim) ExpireOffers(now time.Time) bool {
+	expired := false
+	offers := make([]SettlementOffer, len(c.Offers))
+	for i, offer := range c.Offers {
+		if offer.Status == OfferPending && !now.Before(offer.ExpiresAt) {
+			offer.Status = OfferExpired
+			expired = true
+		}
+		offers[i] = offer
+	}
+	if expired {
+		c.Offers = offers
+	}
+	return expired
+}
+
+// AcceptedOffer returns the claim's accepted offer, or nil
+func (c *Claim) AcceptedOffer() *SettlementOffer {
+	for i := range c.Offers {
+		if c.Offers[i].Status == OfferAccepted {
+			return &c.Offers[i]
+		}
+	}
+	return nil
+}
+
+// CreateOfferRequest represents a settlement offer on a claim. A nil
+// ExpiresAt keeps the offer open for DefaultOfferValidity.
+type CreateOfferRequest struct {
+	Amount    float64    `json:"amount"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Notes     string     `json:"notes,omitempty"`
+}
+
+// RespondToOfferRequest is the customer's answer to an offer. Reason is
+// only kept for rejections.
+type RespondToOfferRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// CreatePayoutRequest pays out a claim's accepted settlement offer. A zero
+// Amount pays the whole offer.
+type CreatePayoutRequest struct {
+	Amount float64 `json:"amount,omitempty"`
+}
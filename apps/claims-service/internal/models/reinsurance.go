@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Amounts a claim's reinsurance cession is worked out on
+const (
+	CessionBasisOffer = "accepted_offer" // the settlement offer the customer accepted
+	CessionBasisClaim = "claim_amount"   // the amount claimed, until an offer is accepted
+)
+
+// UnknownPolicyType groups claims whose policy is unknown to this service
+const UnknownPolicyType = "unknown"
+
+// ReinsuranceCession is an approved claim's amount split between the carrier
+// and the reinsurer of its policy type's treaty
+type ReinsuranceCession struct {
+	ClaimID        string    `json:"claimId"`
+	ClaimNumber    string    `json:"claimNumber"`
+	PolicyType     string    `json:"policyType"`
+	Treaty         string    `json:"treaty,omitempty"` // empty when no treaty covers the policy type
+	Retention      float64   `json:"retention"`
+	Limit          float64   `json:"limit"`
+	Basis          string    `json:"basis"` // accepted_offer or claim_amount
+	GrossAmount    float64   `json:"grossAmount"`
+	CededAmount    float64   `json:"cededAmount"`
+	RetainedAmount float64   `json:"retainedAmount"`
+	ApprovedAt     time.Time `json:"approvedAt"`
+}
+
+// CessionTotals adds up the cessions of a group of approved claims
+type CessionTotals struct {
+	PolicyType     string  `json:"policyType"`
+	Treaty         string  `json:"treaty,omitempty"`
+	Claims         int     `json:"claims"`
+	CededClaims    int     `json:"cededClaims"` // claims with part of their amount ceded
+	GrossAmount    float64 `json:"grossAmount"`
+	CededAmount    float64 `json:"cededAmount"`
+	RetainedAmount float64 `json:"retainedAmount"`
+}
+
+// Add counts a cession
+func (t *CessionTotals) Add(cession *ReinsuranceCession) {
+	t.Claims++
+	if cession.CededAmount > 0 {
+		t.CededClaims++
+	}
+	t.GrossAmount += cession.GrossAmount
+	t.CededAmount += cession.CededAmount
+	t.RetainedAmount += cession.RetainedAmount
+}
+
+// ReinsuranceFilters narrows the cession report. Zero times leave the
+// corresponding bound open; bounds are inclusive.
+type ReinsuranceFilters struct {
+	PolicyType   string
+	ApprovedFrom time.Time
+	ApprovedTo   time.Time
+}
+
+// ReinsuranceReport is what approved claims cede to reinsurers, per policy
+// type and overall
+type ReinsuranceReport struct {
+	GeneratedAt     time.Time       `json:"generatedAt"`
+	TreatiesVersion string          `json:"treatiesVersion"`
+	ByPolicyType    []CessionTotals `json:"byPolicyType"` // ordered by policy type
+	Total           CessionTotals   `json:"total"`
+}
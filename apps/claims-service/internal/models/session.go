@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Session is a signed-in session: a token issued by POST /auth/login and
+// the device it was issued to. Tokens name their session in the jti claim,
+// so a revoked session's token stops being accepted.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	Email      string    `json:"email"`
+	Role       string    `json:"role,omitempty"`
+	Device     string    `json:"device"` // the User-Agent it signed in with
+	IP         string    `json:"ip"`
+	IssuedAt   time.Time `json:"issuedAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	LastSeenIP string    `json:"lastSeenIp"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	// Current is set on the session of the request listing them
+	Current bool `json:"current,omitempty"`
+}
+
+// Login attempt outcomes
+const (
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginLocked             = "locked"
+	LoginInvalidCode        = "invalid_code" // a wrong second factor
+)
+
+// LoginAttempt is a failed sign-in or second factor, kept for the login
+// audit
+type LoginAttempt struct {
+	Username string    `json:"username"`
+	Outcome  string    `json:"outcome"`
+	Device   string    `json:"device"`
+	IP       string    `json:"ip"`
+	At       time.Time `json:"at"`
+	// Failures is how many sign-ins in a row have failed for the username,
+	// this one included
+	Failures int `json:"failures"`
+	// LockedUntil is set on the failure that locked the username and on
+	// attempts refused while it is locked
+	LockedUntil *time.Time `json:"lockedUntil,omitempty"`
+}
+
+// TwoFactor is a user's TOTP two-factor enrollment. It holds secrets and is
+// never returned by the API.
+type TwoFactor struct {
+	UserID string `json:"userId"`
+	// Secret is the confirmed TOTP secret, set once Enabled
+	Secret string `json:"secret,omitempty"`
+	// PendingSecret is a secret being enrolled, until a code from it is
+	// confirmed
+	PendingSecret string     `json:"pendingSecret,omitempty"`
+	Enabled       bool       `json:"enabled"`
+	EnabledAt     *time.Time `json:"enabledAt,omitempty"`
+	// RecoveryCodes are the SHA-256 hashes of the unused recovery codes
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+	// LastCounter is the TOTP period of the last code accepted, so a code
+	// cannot be used twice
+	LastCounter int64 `json:"lastCounter,omitempty"`
+}
+
+// TwoFactorStatus is what a user sees of their two-factor enrollment
+type TwoFactorStatus struct {
+	Enabled bool `json:"enabled"`
+	// Required is set when the user's role must sign in with a second factor
+	Required          bool       `json:"required"`
+	EnabledAt         *time.Time `json:"enabledAt,omitempty"`
+	RecoveryCodesLeft int        `json:"recoveryCodesLeft"`
+}
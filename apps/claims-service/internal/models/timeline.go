@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Services a timeline entry can come from
+const (
+	TimelineSourceClaims   = "claims-service"
+	TimelineSourcePayments = "payments-service"
+)
+
+// Timeline entry types. Claim entries use the claim event types
+// (claim.created, claim.status_changed, ...); payout entries are derived
+// from payments-service records.
+const (
+	TimelinePayoutRequested = "payout.requested"
+	TimelinePayoutCompleted = "payout.completed"
+	TimelinePayoutFailed    = "payout.failed"
+)
+
+// TimelineEntry is one thing that happened to a claim
+type TimelineEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Type          string    `json:"type"`
+	Source        string    `json:"source"` // claims-service or payments-service
+	ClaimID       string    `json:"claimId"`
+	Summary       string    `json:"summary"`
+	OldStatus     string    `json:"oldStatus,omitempty"`
+	NewStatus     string    `json:"newStatus,omitempty"`
+	Queue         string    `json:"queue,omitempty"`
+	AssignedTo    string    `json:"assignedTo,omitempty"`
+	Reason        string    `json:"reason,omitempty"`
+	DocumentID    string    `json:"documentId,omitempty"`
+	FileName      string    `json:"fileName,omitempty"`
+	OfferID       string    `json:"offerId,omitempty"`
+	PaymentID     string    `json:"paymentId,omitempty"`
+	Amount        float64   `json:"amount,omitempty"`
+	PaymentStatus string    `json:"paymentStatus,omitempty"`
+}
+
+// ClaimTimeline is a claim's history across services, oldest entry first.
+// Warnings name the sources that could not be read, in which case their
+// entries are missing from the feed.
+type ClaimTimeline struct {
+	ClaimID     string          `json:"claimId"`
+	ClaimNumber string          `json:"claimNumber"`
+	Entries     []TimelineEntry `json:"entries"`
+	Warnings    []string        `json:"warnings,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetter is a webhook delivery that exhausted its retries. The payload
+// is kept exactly as it was sent so a replay delivers the same body.
+type DeadLetter struct {
+	ID            string          `json:"id"`
+	Endpoint      string          `json:"endpoint"`
+	EventID       int64           `json:"eventId"`
+	EventType     string          `json:"eventType"`
+	ClaimID       string          `json:"claimId"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"lastError"`
+	LastStatus    int             `json:"lastStatus,omitempty"` // HTTP status of the last attempt, if one was received
+	DeadAt        time.Time       `json:"deadAt"`
+	LastAttemptAt time.Time       `json:"lastAttemptAt"`
+	Replays       int             `json:"replays"`
+}
+
+// DeadLetterList is the body returned by GET /admin/dlq, oldest entry first
+type DeadLetterList struct {
+	Depth   int          `json:"depth"`
+	Entries []DeadLetter `json:"entries"`
+}
+
+// ReplayResult reports one replay. A delivered entry is removed from the
+// queue; a failed one stays with its attempt recorded.
+type ReplayResult struct {
+	Delivered  bool       `json:"delivered"`
+	DeadLetter DeadLetter `json:"deadLetter"`
+}
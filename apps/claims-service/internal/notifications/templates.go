@@ -0,0 +1,321 @@
+// Package notifications holds the templates claim notifications are written
+// from. Each claim event type has a subject and body per locale, in Go
+// text/template syntax over the event, its claim and the customer. The
+// templates are configuration, loaded from notification-templates.json, so
+// wording and translations change without a release.
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// DefaultLocale is the locale used when a file names none
+const DefaultLocale = "en"
+
+// EventTypes are the event types templates can be written for
+var EventTypes = []string{
+	events.ClaimCreated,
+	events.ClaimStatusChanged,
+	events.ClaimAssigned,
+	events.ClaimEscalated,
+	events.DocumentUploaded,
+}
+
+// funcs are the helpers available to every template
+var funcs = template.FuncMap{
+	"money": func(amount float64) string { return fmt.Sprintf("%.2f", amount) },
+	"date": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	},
+	"upper": strings.ToUpper,
+	"title": func(s string) string {
+		s = strings.ReplaceAll(s, "_", " ")
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+}
+
+// Template is the wording of one notification in one locale
+type Template struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Customer is the customer a notification is addressed to
+type Customer struct {
+	ID        string `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email,omitempty"`
+}
+
+// Data is what templates are rendered against: {{.Event.NewStatus}},
+// {{.Claim.ClaimNumber}}, {{.Customer.FirstName}}
+type Data struct {
+	Event    events.Event  `json:"event"`
+	Claim    *models.Claim `json:"claim"`
+	Customer *Customer     `json:"customer"`
+}
+
+// Rendered is a notification written from a template
+type Rendered struct {
+	EventType string `json:"eventType"`
+	Locale    string `json:"locale"` // the locale whose template was used
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+}
+
+// Sample returns the data a template is previewed against when the preview
+// brings none: an approved auto claim of the seed customer
+func Sample(eventType string) Data {
+	submitted := time.Date(2024, 12, 1, 9, 30, 0, 0, time.UTC)
+	reviewed := time.Date(2024, 12, 3, 14, 0, 0, 0, time.UTC)
+	return Data{
+		Event: events.Event{
+			Type:        eventType,
+			ClaimID:     "claim-001",
+			ClaimNumber: "CLM-2024-000001",
+			PolicyID:    "pol-001",
+			CustomerID:  "cust-001",
+			OldStatus:   "under_review",
+			NewStatus:   "approved",
+			Queue:       "auto",
+			AssignedTo:  "adjuster-001",
+			FileName:    "repair-estimate.pdf",
+			Timestamp:   reviewed,
+		},
+		Claim: &models.Claim{
+			ID:            "claim-001",
+			PolicyID:      "pol-001",
+			CustomerID:    "cust-001",
+			ClaimNumber:   "CLM-2024-000001",
+			Type:          "accident",
+			SubType:       "collision",
+			Status:        "approved",
+			Amount:        2450,
+			Description:   "Rear-ended at a traffic light",
+			SubmittedDate: submitted,
+			ReviewedDate:  &reviewed,
+		},
+		Customer: &Customer{
+			ID:        "cust-001",
+			FirstName: "Demo",
+			LastName:  "User",
+			Email:     "demo@insurancestack.com",
+		},
+	}
+}
+
+// Templates is the notification templates of each event type, by locale
+type Templates struct {
+	Version       string                         `json:"version"`
+	DefaultLocale string                         `json:"defaultLocale"`
+	Events        map[string]map[string]Template `json:"events"`
+
+	parsed map[string]map[string]*parsedTemplate
+}
+
+// parsedTemplate is a Template ready to execute
+type parsedTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// Default returns the templates used when none are configured: a short
+// English notification for every event type
+func Default() *Templates {
+	t := &Templates{
+		Version:       "default",
+		DefaultLocale: DefaultLocale,
+		Events: map[string]map[string]Template{
+			events.ClaimCreated: {DefaultLocale: {
+				Subject: "Claim {{.Claim.ClaimNumber}} received",
+				Body:    "Your claim {{.Claim.ClaimNumber}} has been received and is {{title .Claim.Status}}.",
+			}},
+			events.ClaimStatusChanged: {DefaultLocale: {
+				Subject: "Claim {{.Claim.ClaimNumber}} is {{title .Event.NewStatus}}",
+				Body:    "Your claim {{.Claim.ClaimNumber}} has moved from {{title .Event.OldStatus}} to {{title .Event.NewStatus}}.",
+			}},
+			events.ClaimAssigned: {DefaultLocale: {
+				Subject: "Claim {{.Claim.ClaimNumber}} assigned",
+				Body:    "Your claim {{.Claim.ClaimNumber}} has been assigned to an adjuster.",
+			}},
+			events.ClaimEscalated: {DefaultLocale: {
+				Subject: "Claim {{.Claim.ClaimNumber}} under senior review",
+				Body:    "Your claim {{.Claim.ClaimNumber}} has been passed to a senior adjuster.",
+			}},
+			events.DocumentUploaded: {DefaultLocale: {
+				Subject: "Document added to claim {{.Claim.ClaimNumber}}",
+				Body:    "{{.Event.FileName}} has been added to your claim {{.Claim.ClaimNumber}}.",
+			}},
+		},
+	}
+	if err := t.validate(); err != nil {
+		panic(fmt.Sprintf("invalid default notification templates: %v", err))
+	}
+	return t
+}
+
+// Load reads and parses a templates file
+func Load(path string) (*Templates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Templates
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid notification templates in %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("invalid notification templates in %s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// validate checks every template is for a known event type and renders the
+// sample data, and that every event type with templates has one in the
+// default locale
+func (t *Templates) validate() error {
+	if t.DefaultLocale == "" {
+		t.DefaultLocale = DefaultLocale
+	}
+	t.DefaultLocale = normalizeLocale(t.DefaultLocale)
+	if len(t.Events) == 0 {
+		return fmt.Errorf("no event types")
+	}
+
+	t.parsed = make(map[string]map[string]*parsedTemplate, len(t.Events))
+	for eventType, locales := range t.Events {
+		if !knownEventType(eventType) {
+			return fmt.Errorf("unknown event type %s (must be %s)", eventType, strings.Join(EventTypes, ", "))
+		}
+		t.parsed[eventType] = make(map[string]*parsedTemplate, len(locales))
+		for locale, tmpl := range locales {
+			name := eventType + "/" + locale
+			if tmpl.Subject == "" || tmpl.Body == "" {
+				return fmt.Errorf("%s needs a subject and a body", name)
+			}
+			subject, err := template.New(name + " subject").Funcs(funcs).Option("missingkey=error").Parse(tmpl.Subject)
+			if err != nil {
+				return err
+			}
+			body, err := template.New(name + " body").Funcs(funcs).Option("missingkey=error").Parse(tmpl.Body)
+			if err != nil {
+				return err
+			}
+			key := normalizeLocale(locale)
+			if _, repeated := t.parsed[eventType][key]; repeated {
+				return fmt.Errorf("%s locale %s is listed twice", eventType, key)
+			}
+			parsed := &parsedTemplate{subject: subject, body: body}
+			if err := parsed.check(eventType); err != nil {
+				return err
+			}
+			t.parsed[eventType][key] = parsed
+		}
+		if _, ok := t.parsed[eventType][t.DefaultLocale]; !ok {
+			return fmt.Errorf("%s has no template in the default locale %s", eventType, t.DefaultLocale)
+		}
+	}
+	return nil
+}
+
+// Render writes the notification for an event type in locale. A locale
+// without a template of its own falls back to its language, so "fr-CA"
+// uses "fr", and then to the default locale.
+func (t *Templates) Render(eventType, locale string, data Data) (*Rendered, error) {
+	locales, ok := t.parsed[eventType]
+	if !ok {
+		return nil, fmt.Errorf("no notification templates for event type %s", eventType)
+	}
+
+	resolved := t.resolve(locales, locale)
+	tmpl := locales[resolved]
+	if data.Event.Type == "" {
+		data.Event.Type = eventType
+	}
+	if data.Claim == nil {
+		data.Claim = &models.Claim{}
+	}
+	if data.Customer == nil {
+		data.Customer = &Customer{}
+	}
+
+	subject, err := execute(tmpl.subject, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render notification: %w", err)
+	}
+	body, err := execute(tmpl.body, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render notification: %w", err)
+	}
+	return &Rendered{
+		EventType: eventType,
+		Locale:    resolved,
+		Subject:   strings.TrimSpace(subject),
+		Body:      body,
+	}, nil
+}
+
+// resolve picks the locale whose template renders a request for locale
+func (t *Templates) resolve(locales map[string]*parsedTemplate, locale string) string {
+	locale = normalizeLocale(locale)
+	if _, ok := locales[locale]; ok {
+		return locale
+	}
+	if i := strings.Index(locale, "-"); i > 0 {
+		if _, ok := locales[locale[:i]]; ok {
+			return locale[:i]
+		}
+	}
+	return t.DefaultLocale
+}
+
+// check renders the template against the sample data, so that a field the
+// data does not have is found at startup rather than when a notification
+// is due
+func (p *parsedTemplate) check(eventType string) error {
+	sample := Sample(eventType)
+	if _, err := execute(p.subject, sample); err != nil {
+		return err
+	}
+	_, err := execute(p.body, sample)
+	return err
+}
+
+func execute(tmpl *template.Template, data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// normalizeLocale lowercases a locale and writes "pt_BR" as "pt-br"
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func knownEventType(eventType string) bool {
+	for _, known := range EventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
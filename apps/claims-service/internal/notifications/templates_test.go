@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+)
+
+func TestRenderSeedTemplates(t *testing.T) {
+	templates, err := Load(filepath.Join("..", "..", "..", "..", "data", "seed", "notification-templates.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		eventType, locale string
+		wantLocale        string
+		wantSubject       string
+	}{
+		{events.ClaimStatusChanged, "", "en", "Your claim CLM-2024-000001 is now Approved"},
+		{events.ClaimStatusChanged, "es", "es", "Su reclamación CLM-2024-000001 ha cambiado de estado"},
+		{events.ClaimStatusChanged, "es_MX", "es", "Su reclamación CLM-2024-000001 ha cambiado de estado"},
+		{events.ClaimEscalated, "es", "en", "Claim CLM-2024-000001 is under senior review"},
+		{events.ClaimCreated, "de-DE", "en", "We received your claim CLM-2024-000001"},
+	}
+	for _, tt := range tests {
+		rendered, err := templates.Render(tt.eventType, tt.locale, Sample(tt.eventType))
+		if err != nil {
+			t.Fatalf("Render(%s, %q) failed: %v", tt.eventType, tt.locale, err)
+		}
+		if rendered.Locale != tt.wantLocale || rendered.Subject != tt.wantSubject {
+			t.Errorf("Render(%s, %q) = %s %q, want %s %q", tt.eventType, tt.locale, rendered.Locale, rendered.Subject, tt.wantLocale, tt.wantSubject)
+		}
+	}
+
+	rendered, _ := templates.Render(events.ClaimStatusChanged, "en", Sample(events.ClaimStatusChanged))
+	if want := "Hi Demo,\n\nYour claim CLM-2024-000001 has moved from Under review to Approved. A payout of 2450.00 is on its way.\n"; rendered.Body != want {
+		t.Errorf("Unexpected body %q", rendered.Body)
+	}
+
+	if _, err := templates.Render("claim.deleted", "en", Data{}); err == nil || err.Error() != "no notification templates for event type claim.deleted" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestLoadRejectsInvalidTemplates(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no events", `{"events": {}}`, "no event types"},
+		{"unknown event", `{"events": {"claim.deleted": {"en": {"subject": "s", "body": "b"}}}}`, "unknown event type claim.deleted"},
+		{"no body", `{"events": {"claim.created": {"en": {"subject": "s"}}}}`, "claim.created/en needs a subject and a body"},
+		{"bad syntax", `{"events": {"claim.created": {"en": {"subject": "{{.Claim.ClaimNumber", "body": "b"}}}}`, "unclosed action"},
+		{"unknown field", `{"events": {"claim.created": {"en": {"subject": "{{.Claim.Number}}", "body": "b"}}}}`, "can't evaluate field Number"},
+		{"no default locale", `{"defaultLocale": "fr", "events": {"claim.created": {"en": {"subject": "s", "body": "b"}}}}`, "claim.created has no template in the default locale fr"},
+		{"repeated locale", `{"events": {"claim.created": {"en": {"subject": "s", "body": "b"}, "EN": {"subject": "s", "body": "b"}}}}`, "claim.created locale en is listed twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notification-templates.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write templates: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDefaultCoversEveryEventType(t *testing.T) {
+	templates := Default()
+	for _, eventType := range EventTypes {
+		if _, err := templates.Render(eventType, "fr", Data{}); err != nil {
+			t.Errorf("Render(%s) without data failed: %v", eventType, err)
+		}
+	}
+}
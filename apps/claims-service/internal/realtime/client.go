@@ -0,0 +1,124 @@
+package realtime
+
+import (
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// writeWait is the time allowed to write a message to the peer
+	writeWait = 10 * time.Second
+
+	// pongWait is the time allowed to read the next pong message from the peer
+	pongWait = 60 * time.Second
+
+	// pingPeriod sends pings to the peer with this period (must be less than pongWait)
+	pingPeriod = (pongWait * 9) / 10
+
+	// maxMessageSize is the maximum message size allowed from the peer
+	maxMessageSize = 512
+)
+
+// Client is a single adjuster dashboard connection
+type Client struct {
+	hub    *Hub
+	conn   *websocket.Conn
+	send   chan []byte
+	userID string
+	queues map[string]bool
+}
+
+func newClient(hub *Hub, conn *websocket.Conn, userID string, queues []string) *Client {
+	client := &Client{
+		hub:    hub,
+		conn:   conn,
+		send:   make(chan []byte, hub.sendBuffer),
+		userID: userID,
+		queues: make(map[string]bool, len(queues)),
+	}
+	for _, queue := range queues {
+		client.queues[queue] = true
+	}
+	return client
+}
+
+// wants reports whether the event belongs to one of the client's queues.
+// Claims assigned to the adjuster are always delivered; an empty queue set
+// subscribes to every queue.
+func (c *Client) wants(evt events.Event) bool {
+	if evt.AssignedTo != "" && evt.AssignedTo == c.userID {
+		return true
+	}
+	if len(c.queues) == 0 {
+		return true
+	}
+	return c.queues[evt.Queue]
+}
+
+// queueList returns the client's queues in a stable order for logging
+func (c *Client) queueList() []string {
+	queues := make([]string, 0, len(c.queues))
+	for queue := range c.queues {
+		queues = append(queues, queue)
+	}
+	sort.Strings(queues)
+	return queues
+}
+
+// readPump reads from the connection so control frames (pong, close) are
+// processed. Dashboards do not send application messages.
+func (c *Client) readPump() {
+	defer func() {
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.done:
+		}
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// writePump pumps messages from the hub to the connection
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				// The hub closed the channel
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// dashboardEvents are the event types pushed to adjuster dashboards
+var dashboardEvents = map[string]bool{
+	events.ClaimCreated:       true,
+	events.ClaimAssigned:      true,
+	events.ClaimEscalated:     true,
+	events.ClaimStatusChanged: true,
+	events.OfferAccepted:      true,
+	events.OfferRejected:      true,
+}
+
+// Hub fans claim events out to connected adjuster dashboards
+type Hub struct {
+	bus        *events.Bus
+	clients    map[*Client]struct{}
+	register   chan *Client
+	unregister chan *Client
+	done       chan struct{}
+	sendBuffer int
+	connected  int64
+	dropped    int64
+	logger     *logrus.Logger
+}
+
+// NewHub creates a new hub. sendBuffer bounds the number of messages queued
+// per client; clients that fall further behind are disconnected.
+func NewHub(bus *events.Bus, sendBuffer int, logger *logrus.Logger) *Hub {
+	if sendBuffer <= 0 {
+		sendBuffer = 32
+	}
+	return &Hub{
+		bus:        bus,
+		clients:    make(map[*Client]struct{}),
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		done:       make(chan struct{}),
+		sendBuffer: sendBuffer,
+		logger:     logger,
+	}
+}
+
+// Run subscribes to the event bus and dispatches events until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) {
+	_, sub := h.bus.Subscribe(func(evt events.Event) bool {
+		return dashboardEvents[evt.Type]
+	}, 0, 256)
+	defer sub.Close()
+	defer close(h.done)
+
+	h.logger.Info("Adjuster WebSocket hub started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			for client := range h.clients {
+				h.remove(client)
+			}
+			h.logger.Info("Adjuster WebSocket hub stopped")
+			return
+
+		case client := <-h.register:
+			h.clients[client] = struct{}{}
+			atomic.AddInt64(&h.connected, 1)
+			h.logger.WithFields(logrus.Fields{
+				"userId": client.userID,
+				"queues": client.queueList(),
+			}).Info("Adjuster connected")
+
+		case client := <-h.unregister:
+			if _, ok := h.clients[client]; ok {
+				h.remove(client)
+				h.logger.WithField("userId", client.userID).Info("Adjuster disconnected")
+			}
+
+		case evt, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			h.broadcast(evt)
+		}
+	}
+}
+
+// broadcast delivers an event to every client interested in it
+func (h *Hub) broadcast(evt events.Event) {
+	message, err := json.Marshal(evt)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to encode event for WebSocket clients")
+		return
+	}
+
+	for client := range h.clients {
+		if !client.wants(evt) {
+			continue
+		}
+		select {
+		case client.send <- message:
+		default:
+			// Backpressure: a client that cannot keep up is disconnected
+			// rather than stalling delivery to everyone else
+			atomic.AddInt64(&h.dropped, 1)
+			h.remove(client)
+			h.logger.WithFields(logrus.Fields{
+				"userId":  client.userID,
+				"eventId": evt.ID,
+			}).Warn("Adjuster connection too slow, disconnecting")
+		}
+	}
+}
+
+// remove drops a client from the hub and stops its writer
+func (h *Hub) remove(client *Client) {
+	delete(h.clients, client)
+	close(client.send)
+	atomic.AddInt64(&h.connected, -1)
+}
+
+// Serve registers an upgraded connection and starts its read/write pumps
+func (h *Hub) Serve(conn *websocket.Conn, userID string, queues []string) {
+	client := newClient(h, conn, userID, queues)
+	select {
+	case h.register <- client:
+	case <-h.done:
+		conn.Close()
+		return
+	}
+
+	go client.writePump()
+	go client.readPump()
+}
+
+// Stats returns the number of connected clients and slow-consumer disconnects
+func (h *Hub) Stats() (connected, dropped int64) {
+	return atomic.LoadInt64(&h.connected), atomic.LoadInt64(&h.dropped)
+}
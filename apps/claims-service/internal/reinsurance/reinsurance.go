@@ -0,0 +1,99 @@
+// Package reinsurance splits large claims between the carrier and its
+// reinsurers. Each policy type is covered by an excess of loss treaty: the
+// carrier keeps each claim up to the treaty's retention, and the reinsurer
+// pays the part above it up to the treaty's limit. The treaties are
+// configuration, loaded from reinsurance.json, so they can be renewed
+// without a release.
+package reinsurance
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// Treaty is the excess of loss cover of one policy type
+type Treaty struct {
+	Name      string  `json:"name"`
+	Retention float64 `json:"retention"` // kept by the carrier on each claim
+	Limit     float64 `json:"limit"`     // most ceded on a claim above the retention
+}
+
+// Treaties is the treaty covering each policy type
+type Treaties struct {
+	Version  string            `json:"version"`
+	Treaties map[string]Treaty `json:"treaties"` // by policy type
+}
+
+// Split is a claim amount divided between the carrier and the reinsurer
+type Split struct {
+	Treaty   string  // empty when no treaty covers the policy type
+	Ceded    float64 // paid by the reinsurer
+	Retained float64 // paid by the carrier
+}
+
+// Default returns the treaties used when none are configured: none, so
+// every claim is retained
+func Default() *Treaties {
+	return &Treaties{Version: "none", Treaties: map[string]Treaty{}}
+}
+
+// Load reads and validates a treaties file
+func Load(path string) (*Treaties, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Treaties
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid reinsurance treaties in %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("invalid reinsurance treaties in %s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// validate checks every treaty is named and has a usable retention and limit
+func (t *Treaties) validate() error {
+	if len(t.Treaties) == 0 {
+		return fmt.Errorf("no treaties")
+	}
+	for policyType, treaty := range t.Treaties {
+		if treaty.Name == "" {
+			return fmt.Errorf("%s treaty has no name", policyType)
+		}
+		if treaty.Retention < 0 {
+			return fmt.Errorf("%s treaty retention cannot be negative", policyType)
+		}
+		if treaty.Limit <= 0 {
+			return fmt.Errorf("%s treaty limit must be greater than zero", policyType)
+		}
+	}
+	return nil
+}
+
+// Treaty returns the treaty covering a policy type, and whether there is one
+func (t *Treaties) Treaty(policyType string) (Treaty, bool) {
+	treaty, ok := t.Treaties[policyType]
+	return treaty, ok
+}
+
+// Cede splits a claim amount on a policy type. The reinsurer pays the part
+// above the retention up to the limit; the carrier keeps the rest,
+// including anything above the limit. Amounts are rounded to the cent.
+func (t *Treaties) Cede(policyType string, amount float64) Split {
+	treaty, ok := t.Treaties[policyType]
+	if !ok {
+		return Split{Retained: amount}
+	}
+	ceded := math.Min(math.Max(amount-treaty.Retention, 0), treaty.Limit)
+	ceded = math.Round(ceded*100) / 100
+	return Split{
+		Treaty:   treaty.Name,
+		Ceded:    ceded,
+		Retained: math.Round((amount-ceded)*100) / 100,
+	}
+}
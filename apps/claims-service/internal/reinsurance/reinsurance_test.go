@@ -0,0 +1,61 @@
+package reinsurance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCedeSeedTreaties(t *testing.T) {
+	treaties, err := Load(filepath.Join("..", "..", "..", "..", "data", "seed", "reinsurance.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		policyType string
+		amount     float64
+		want       Split
+	}{
+		{"auto", 4500, Split{Treaty: "AUTO-XL-2025", Ceded: 0, Retained: 4500}},
+		{"auto", 5000, Split{Treaty: "AUTO-XL-2025", Ceded: 0, Retained: 5000}},
+		{"auto", 8500.55, Split{Treaty: "AUTO-XL-2025", Ceded: 3500.55, Retained: 5000}},
+		{"auto", 40000, Split{Treaty: "AUTO-XL-2025", Ceded: 20000, Retained: 20000}},
+		{"home", 18500, Split{Treaty: "PROPERTY-XL-2025", Ceded: 8500, Retained: 10000}},
+		{"", 18500, Split{Retained: 18500}},
+		{"marine", 18500, Split{Retained: 18500}},
+	}
+	for _, tt := range tests {
+		if got := treaties.Cede(tt.policyType, tt.amount); got != tt.want {
+			t.Errorf("Cede(%q, %.2f) = %+v, want %+v", tt.policyType, tt.amount, got, tt.want)
+		}
+	}
+
+	if got := Default().Cede("auto", 1e6); got.Ceded != 0 || got.Retained != 1e6 {
+		t.Errorf("Expected the default to retain everything, got %+v", got)
+	}
+}
+
+func TestLoadRejectsInvalidTreaties(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no treaties", `{"treaties": {}}`, "no treaties"},
+		{"no name", `{"treaties": {"auto": {"retention": 10, "limit": 100}}}`, "auto treaty has no name"},
+		{"negative retention", `{"treaties": {"auto": {"name": "XL", "retention": -1, "limit": 100}}}`, "auto treaty retention cannot be negative"},
+		{"no limit", `{"treaties": {"auto": {"name": "XL", "retention": 10}}}`, "auto treaty limit must be greater than zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "reinsurance.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write treaties: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
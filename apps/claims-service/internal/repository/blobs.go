@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+)
+
+// blobTimeout bounds each read or write of a document's content in the
+// blob store
+const blobTimeout = 30 * time.Second
+
+// DocumentLinker signs download links to documents' content, returning
+// the link and when it expires. A store returned by WithBlobs is one.
+type DocumentLinker interface {
+	DocumentURL(claimID, documentID, fileName string) (string, time.Time, error)
+}
+
+// blobDocuments keeps the content of claim documents in a blob store, and
+// their descriptions in the Store it wraps
+type blobDocuments struct {
+	Store
+	blobs  blobstore.Store
+	urlTTL time.Duration
+}
+
+// WithBlobs returns store with the content of documents uploaded from now
+// on kept in blobs, at claims/<claimID>/<documentID>. Documents uploaded
+// before are still read from store. The blob store's limits apply to
+// every upload, and its signed links are valid for urlTTL.
+func WithBlobs(store Store, blobs blobstore.Store, urlTTL time.Duration) Store {
+	return &blobDocuments{Store: store, blobs: blobs, urlTTL: urlTTL}
+}
+
+var _ DocumentLinker = (*blobDocuments)(nil)
+
+// documentBlobKey is where a document's content is kept in the blob store
+func documentBlobKey(claimID, documentID string) string {
+	return "claims/" + claimID + "/" + documentID
+}
+
+// AddDocument writes the content to the blob store, then stores the
+// document without it. A new document the store refuses has its content
+// deleted again; one it already holds keeps its content.
+func (b *blobDocuments) AddDocument(doc *models.ClaimDocument, content []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), blobTimeout)
+	defer cancel()
+
+	key := documentBlobKey(doc.ClaimID, doc.ID)
+	if _, _, err := b.Store.GetDocument(doc.ClaimID, doc.ID); err == nil {
+		return fmt.Errorf("document already exists")
+	}
+	if err := b.blobs.Put(ctx, key, doc.ContentType, content); err != nil {
+		return err
+	}
+	if err := b.Store.AddDocument(doc, nil); err != nil {
+		b.blobs.Delete(ctx, key)
+		return err
+	}
+	return nil
+}
+
+// GetDocument returns a claim document with its content from the blob
+// store, or from the wrapped store for documents uploaded before it
+func (b *blobDocuments) GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error) {
+	doc, stored, err := b.Store.GetDocument(claimID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), blobTimeout)
+	defer cancel()
+	_, content, err := b.blobs.Get(ctx, documentBlobKey(claimID, documentID))
+	if errors.Is(err, blobstore.ErrNotFound) && len(stored) > 0 {
+		return doc, stored, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read content of document %s: %w", documentID, err)
+	}
+	return doc, content, nil
+}
+
+// DocumentURL signs a link that downloads a document's content as
+// fileName. Documents uploaded before the blob store, whose content is
+// still in the wrapped store, have no link.
+func (b *blobDocuments) DocumentURL(claimID, documentID, fileName string) (string, time.Time, error) {
+	if _, stored, err := b.Store.GetDocument(claimID, documentID); err != nil {
+		return "", time.Time{}, err
+	} else if len(stored) > 0 {
+		return "", time.Time{}, fmt.Errorf("document was uploaded before the blob store and has no link")
+	}
+
+	expiresAt := time.Now().Add(b.urlTTL)
+	link, err := b.blobs.SignedURL(documentBlobKey(claimID, documentID), b.urlTTL, fileName)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign document link: %w", err)
+	}
+	return link, expiresAt, nil
+}
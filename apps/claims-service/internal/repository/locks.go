@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// policyLockStripes is how many mutexes policies share. Policies hashing
+// to the same stripe wait on each other, which is harmless: the sections
+// they guard are short.
+const policyLockStripes = 64
+
+// PolicyLocks serializes write sections per policy within one process. It
+// holds a fixed set of striped mutexes, so it does not grow with the
+// number of policies. The zero value is ready to use.
+type PolicyLocks struct {
+	stripes [policyLockStripes]sync.Mutex
+}
+
+// Lock blocks until no other section holds policyID's stripe and returns
+// the function that releases it
+func (l *PolicyLocks) Lock(policyID string) func() {
+	h := fnv.New32a()
+	h.Write([]byte(policyID))
+	m := &l.stripes[h.Sum32()%policyLockStripes]
+	m.Lock()
+	return m.Unlock
+}
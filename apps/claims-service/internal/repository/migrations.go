@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/sirupsen/logrus"
+)
+
+// Migrations of the data files this service owns, oldest first. Append one
+// to a file's set when a change to its model would misread the files
+// written before it. policies.json belongs to policy-service, which migrates
+// it; this service only reads it with migrate.Read.
+var (
+	claimMigrations       = migrate.Set{}
+	catastropheMigrations = migrate.Set{}
+)
+
+// logMigration records an upgrade of the data file at path
+func logMigration(logger *logrus.Logger, path string, result migrate.Result) {
+	if !result.Migrated() {
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"from":   result.From,
+		"to":     result.To,
+		"backup": result.Backup,
+	}).Infof("Migrated %s: %s", path, strings.Join(result.Applied, "; "))
+}
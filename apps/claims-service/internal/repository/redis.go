@@ -0,0 +1,1173 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Redis key layout. Every entity is a hash holding its JSON under "data";
+// claims also hold the fields they are indexed by, so an update can move
+// the claim out of the index sets it no longer belongs to.
+const (
+	redisPrefix       = "claims-service:"
+	redisSeededKey    = redisPrefix + "seeded"
+	redisClaimsKey    = redisPrefix + "claims"         // set of claim IDs
+	redisCatsKey      = redisPrefix + "catastrophes"   // set of catastrophe IDs
+	redisDraftsKey    = redisPrefix + "drafts"         // set of claim draft IDs
+	redisFNOLsKey     = redisPrefix + "fnols"          // set of first notice of loss IDs
+	redisArchivedKey  = redisPrefix + "archive:claims" // set of archived claim IDs
+	redisMaxTxRetries = 10
+
+	// A policy lock expires after redisPolicyLockTTL, so a crashed instance
+	// cannot hold it forever. Waiters poll every redisPolicyLockPoll and
+	// give up after redisPolicyLockWait.
+	redisPolicyLockTTL  = 10 * time.Second
+	redisPolicyLockWait = 5 * time.Second
+	redisPolicyLockPoll = 20 * time.Millisecond
+)
+
+// claimIndexes are the claim fields with a secondary index set, matching
+// the equality filters in models.ClaimFilters
+var claimIndexes = []string{"policyId", "customerId", "status", "type", "catastropheId"}
+
+func claimKey(id string) string            { return redisPrefix + "claim:" + id }
+func claimIndexKey(field, v string) string { return redisPrefix + "claims:" + field + ":" + v }
+func archivedClaimKey(id string) string    { return redisPrefix + "archive:claim:" + id }
+func claimTimelineKey(id string) string    { return redisPrefix + "claim:" + id + ":timeline" }
+func claimDocumentsKey(id string) string   { return redisPrefix + "claim:" + id + ":documents" }
+func claimCommentsKey(id string) string    { return redisPrefix + "claim:" + id + ":comments" }
+func policyKey(id string) string           { return redisPrefix + "policy:" + id }
+func customerPoliciesKey(id string) string { return redisPrefix + "customer:" + id + ":policies" }
+func catastropheKey(id string) string      { return redisPrefix + "catastrophe:" + id }
+func documentKey(id string) string         { return redisPrefix + "document:" + id }
+func commentKey(id string) string          { return redisPrefix + "comment:" + id }
+func draftKey(id string) string            { return redisPrefix + "draft:" + id }
+func fnolKey(id string) string             { return redisPrefix + "fnol:" + id }
+func twoFactorKey(userID string) string    { return redisPrefix + "twofactor:" + userID }
+
+// claimNumberKey holds the ID of the claim with a claim number, so numbers
+// stay unique across instances
+func claimNumberKey(number string) string { return redisPrefix + "claim-number:" + number }
+
+// policyLockKey holds the token of the instance in a write section for a
+// policy's claims
+func policyLockKey(id string) string { return redisPrefix + "policy:" + id + ":lock" }
+
+// unlockScript deletes a policy lock only if it still holds the caller's
+// token, so a lock that expired and was taken by another instance is left
+// alone
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// claimSequenceKey holds the last claim number sequence drawn in a year
+func claimSequenceKey(year int) string { return redisPrefix + "claim-seq:" + strconv.Itoa(year) }
+
+// draftContentField is the field of a draft's hash holding an attachment's
+// content
+func draftContentField(attachmentID string) string { return "content:" + attachmentID }
+
+// RedisRepository provides data access for claims stored in Redis, so every
+// claims-service instance pointed at the same Redis shares one state. It is
+// seeded from the JSON files once, by the first instance to start against
+// an empty Redis. Hooks only see the writes made through this instance.
+type RedisRepository struct {
+	hooks.Hooks
+
+	client *redis.Client
+	logger *logrus.Logger
+}
+
+var (
+	_ ClaimStore   = (*RedisRepository)(nil)
+	_ CommentStore = (*RedisRepository)(nil)
+	_ DraftStore   = (*RedisRepository)(nil)
+	_ FNOLStore    = (*RedisRepository)(nil)
+)
+
+// NewRedisRepository connects to the Redis at redisURL
+// (redis://[:password@]host:port/db) and seeds it from dataPath if no
+// instance has yet
+func NewRedisRepository(redisURL, dataPath string, logger *logrus.Logger) (*RedisRepository, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %s: %w", opts.Addr, err)
+	}
+
+	repo := &RedisRepository{
+		client: client,
+		logger: logger,
+	}
+	if err := repo.seed(dataPath); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to seed redis: %w", err)
+	}
+
+	return repo, nil
+}
+
+// Close closes the connection to Redis
+func (r *RedisRepository) Close() error {
+	return r.client.Close()
+}
+
+// announce runs the hooks of a write if err shows it was stored, and
+// returns err
+func (r *RedisRepository) announce(err error, op, collection, key string, value interface{}) error {
+	if err == nil {
+		r.Notify(hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+	}
+	return err
+}
+
+// seed loads the JSON files into Redis unless another instance already has.
+// The seed is written in one transaction, so instances starting together
+// never see it half written.
+func (r *RedisRepository) seed(dataPath string) error {
+	ctx := context.Background()
+
+	var policies []*Policy
+	if _, err := migrate.Read(filepath.Join(dataPath, "policies.json"), &policies); err != nil {
+		r.logger.Warnf("Failed to load policies: %v", err)
+	}
+	var claims []*models.Claim
+	if err := r.readSeedFile(filepath.Join(dataPath, "claims.json"), claimMigrations, &claims); err != nil {
+		r.logger.Warnf("Failed to load claims: %v", err)
+	}
+	var catastrophes []*models.Catastrophe
+	if err := r.readSeedFile(filepath.Join(dataPath, "catastrophes.json"), catastropheMigrations, &catastrophes); err != nil {
+		r.logger.Warnf("Failed to load catastrophes: %v", err)
+	}
+
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		seeded, err := tx.Exists(ctx, redisSeededKey).Result()
+		if err != nil {
+			return err
+		}
+		if seeded > 0 {
+			r.logger.Info("Redis already seeded, skipping seed data")
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, pol := range policies {
+				data, err := json.Marshal(pol)
+				if err != nil {
+					return err
+				}
+				pipe.HSet(ctx, policyKey(pol.ID), "data", data)
+				pipe.SAdd(ctx, customerPoliciesKey(pol.CustomerID), pol.ID)
+			}
+			for _, claim := range claims {
+				// Seed claims start at the first version
+				if claim.Version == 0 {
+					claim.Version = 1
+				}
+				if err := queueClaim(ctx, pipe, claim, nil); err != nil {
+					return err
+				}
+			}
+			for _, cat := range catastrophes {
+				if err := queueCatastrophe(ctx, pipe, cat); err != nil {
+					return err
+				}
+			}
+			pipe.Set(ctx, redisSeededKey, time.Now().UTC().Format(time.RFC3339), 0)
+			return nil
+		})
+		if err == nil {
+			r.logger.Infof("Seeded redis with %d policies, %d claims and %d catastrophes from %s", len(policies), len(claims), len(catastrophes), dataPath)
+		}
+		return err
+	}, redisSeededKey)
+	if errors.Is(err, redis.TxFailedErr) {
+		// Another instance seeded first
+		return nil
+	}
+	return err
+}
+
+// readSeedFile upgrades a JSON seed file with set and decodes it into v
+func (r *RedisRepository) readSeedFile(filePath string, set migrate.Set, v interface{}) error {
+	result, err := migrate.Load(filePath, set, v)
+	if err != nil {
+		return err
+	}
+	logMigration(r.logger, filePath, result)
+	return nil
+}
+
+// watch runs fn in an optimistic transaction on keys, retrying when another
+// instance changes one of them first
+func (r *RedisRepository) watch(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error {
+	for attempt := 0; attempt < redisMaxTxRetries; attempt++ {
+		err := r.client.Watch(ctx, fn, keys...)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to update %s: too many concurrent changes", keys[0])
+}
+
+// claimIndexValues returns the indexed fields of a claim
+func claimIndexValues(claim *models.Claim) map[string]string {
+	return map[string]string{
+		"policyId":      claim.PolicyID,
+		"customerId":    claim.CustomerID,
+		"status":        claim.Status,
+		"type":          claim.Type,
+		"catastropheId": claim.CatastropheID,
+	}
+}
+
+// queueClaim queues the commands storing claim, moving it between index
+// sets and claim numbers when previous holds the fields it was last stored
+// with
+func queueClaim(ctx context.Context, pipe redis.Pipeliner, claim *models.Claim, previous map[string]string) error {
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return fmt.Errorf("failed to encode claim %s: %w", claim.ID, err)
+	}
+
+	values := claimIndexValues(claim)
+	fields := map[string]interface{}{"data": data}
+	for _, field := range claimIndexes {
+		if old := previous[field]; old != "" && old != values[field] {
+			pipe.SRem(ctx, claimIndexKey(field, old), claim.ID)
+		}
+		if values[field] != "" {
+			pipe.SAdd(ctx, claimIndexKey(field, values[field]), claim.ID)
+		}
+		fields[field] = values[field]
+	}
+	if old := previous["claimNumber"]; old != "" && old != claim.ClaimNumber {
+		pipe.Del(ctx, claimNumberKey(old))
+	}
+	if claim.ClaimNumber != "" {
+		pipe.Set(ctx, claimNumberKey(claim.ClaimNumber), claim.ID, 0)
+	}
+	fields["claimNumber"] = claim.ClaimNumber
+	fields["version"] = claim.Version
+	pipe.HSet(ctx, claimKey(claim.ID), fields)
+	pipe.SAdd(ctx, redisClaimsKey, claim.ID)
+	return nil
+}
+
+// queueCatastrophe queues the commands storing a catastrophe event
+func queueCatastrophe(ctx context.Context, pipe redis.Pipeliner, cat *models.Catastrophe) error {
+	data, err := json.Marshal(cat)
+	if err != nil {
+		return fmt.Errorf("failed to encode catastrophe %s: %w", cat.ID, err)
+	}
+	pipe.HSet(ctx, catastropheKey(cat.ID), "data", data)
+	pipe.SAdd(ctx, redisCatsKey, cat.ID)
+	return nil
+}
+
+// getData decodes the "data" field of the hash at key into v. It returns
+// false when the hash does not exist.
+func (r *RedisRepository) getData(ctx context.Context, key string, v interface{}) (bool, error) {
+	data, err := r.client.HGet(ctx, key, "data").Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// loadAll decodes the "data" field of each hash in keys, skipping hashes
+// that no longer exist. decode is called with each hash's data in order.
+func (r *RedisRepository) loadAll(ctx context.Context, keys []string, decode func(data []byte) error) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	if _, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HGet(ctx, key, "data")
+		}
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("failed to read records: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", keys[i], err)
+		}
+		if err := decode(data); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", keys[i], err)
+		}
+	}
+	return nil
+}
+
+// loadClaims returns the claims with the given IDs, stored at key(id)
+func (r *RedisRepository) loadClaims(ctx context.Context, ids []string, key func(string) string) []*models.Claim {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = key(id)
+	}
+
+	claims := make([]*models.Claim, 0, len(ids))
+	if err := r.loadAll(ctx, keys, func(data []byte) error {
+		var claim models.Claim
+		if err := json.Unmarshal(data, &claim); err != nil {
+			return err
+		}
+		claims = append(claims, &claim)
+		return nil
+	}); err != nil {
+		r.logger.WithError(err).Error("Failed to load claims from redis")
+	}
+	return claims
+}
+
+// GetClaimByID retrieves a claim by ID
+func (r *RedisRepository) GetClaimByID(claimID string) (*models.Claim, error) {
+	var claim models.Claim
+	found, err := r.getData(context.Background(), claimKey(claimID), &claim)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("claim not found")
+	}
+	return &claim, nil
+}
+
+// GetAllClaims returns all claims
+func (r *RedisRepository) GetAllClaims() []*models.Claim {
+	ctx := context.Background()
+
+	ids, err := r.client.SMembers(ctx, redisClaimsKey).Result()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list claims from redis")
+		return []*models.Claim{}
+	}
+	return r.loadClaims(ctx, ids, claimKey)
+}
+
+// GetClaimsByFilter retrieves claims matching the given filters. Equality
+// filters are answered from the index sets; date ranges are checked on the
+// claims they return.
+func (r *RedisRepository) GetClaimsByFilter(filters *models.ClaimFilters) []*models.Claim {
+	ctx := context.Background()
+
+	wanted := claimIndexValues(&models.Claim{
+		PolicyID:      filters.PolicyID,
+		CustomerID:    filters.CustomerID,
+		Status:        filters.Status,
+		Type:          filters.Type,
+		CatastropheID: filters.CatastropheID,
+	})
+	var sets []string
+	for _, field := range claimIndexes {
+		if wanted[field] != "" {
+			sets = append(sets, claimIndexKey(field, wanted[field]))
+		}
+	}
+
+	var ids []string
+	var err error
+	if len(sets) == 0 {
+		ids, err = r.client.SMembers(ctx, redisClaimsKey).Result()
+	} else {
+		ids, err = r.client.SInter(ctx, sets...).Result()
+	}
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to query claims from redis")
+		return nil
+	}
+
+	var filtered []*models.Claim
+	for _, claim := range r.loadClaims(ctx, ids, claimKey) {
+		if claim.Matches(filters) {
+			filtered = append(filtered, claim)
+		}
+	}
+	return filtered
+}
+
+// CreateClaim creates a new claim at its first version. It is refused if
+// another claim already has its claim number.
+func (r *RedisRepository) CreateClaim(claim *models.Claim) error {
+	claim.Version = 1
+	return r.announce(r.saveClaim(claim, false), hooks.OpCreate, "claims", claim.ID, claim)
+}
+
+// UpdateClaim updates an existing claim and advances its version. The
+// update is refused if the claim was changed since claim was read, by this
+// instance or another.
+func (r *RedisRepository) UpdateClaim(claim *models.Claim) error {
+	return r.announce(r.saveClaim(claim, true), hooks.OpUpdate, "claims", claim.ID, claim)
+}
+
+// saveClaim stores claim and updates its index sets, refusing a claim
+// number held by another claim
+func (r *RedisRepository) saveClaim(claim *models.Claim, update bool) error {
+	return r.commit(context.Background(), []redisWrite{claimWrite(claim, update)})
+}
+
+// claimWrite is the write of saveClaim
+func claimWrite(claim *models.Claim, update bool) redisWrite {
+	key := claimKey(claim.ID)
+	keys := []string{key}
+	if claim.ClaimNumber != "" {
+		keys = append(keys, claimNumberKey(claim.ClaimNumber))
+	}
+
+	return redisWrite{keys: keys, check: func(ctx context.Context, tx *redis.Tx) (*redisQueued, error) {
+		previous, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read claim %s: %w", claim.ID, err)
+		}
+		if claim.ClaimNumber != "" {
+			owner, err := tx.Get(ctx, claimNumberKey(claim.ClaimNumber)).Result()
+			if err != nil && !errors.Is(err, redis.Nil) {
+				return nil, fmt.Errorf("failed to read claim number %s: %w", claim.ClaimNumber, err)
+			}
+			if owner != "" && owner != claim.ID {
+				return nil, fmt.Errorf("claim number already exists")
+			}
+		}
+
+		stored := *claim
+		if update {
+			if len(previous) == 0 {
+				return nil, fmt.Errorf("claim not found")
+			}
+			if previous["version"] != strconv.Itoa(claim.Version) {
+				return nil, fmt.Errorf("claim version conflict")
+			}
+			stored.Version++
+		}
+
+		return &redisQueued{
+			queue: func(pipe redis.Pipeliner) error { return queueClaim(ctx, pipe, &stored, previous) },
+			done:  func() { claim.Version = stored.Version },
+		}, nil
+	}}
+}
+
+// NextClaimSequence draws the next claim number sequence of a year. The
+// counter is shared by every instance, so no two draw the same value.
+func (r *RedisRepository) NextClaimSequence(year int) (int64, error) {
+	seq, err := r.client.Incr(context.Background(), claimSequenceKey(year)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to draw claim number sequence: %w", err)
+	}
+	return seq, nil
+}
+
+// LockPolicy starts a write section for policyID's claims. The lock is a
+// key in Redis, so sections for the same policy never overlap across
+// instances either.
+func (r *RedisRepository) LockPolicy(policyID string) (func(), error) {
+	ctx := context.Background()
+	key := policyLockKey(policyID)
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate lock token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+
+	deadline := time.Now().Add(redisPolicyLockWait)
+	for {
+		acquired, err := r.client.SetNX(ctx, key, token, redisPolicyLockTTL).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock policy %s: %w", policyID, err)
+		}
+		if acquired {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the lock on policy %s", policyID)
+		}
+		time.Sleep(redisPolicyLockPoll)
+	}
+
+	return func() {
+		if err := unlockScript.Run(ctx, r.client, []string{key}, token).Err(); err != nil {
+			r.logger.WithError(err).WithField("policyId", policyID).Warn("Failed to release policy lock, it will expire")
+		}
+	}, nil
+}
+
+// ArchiveClaim moves a claim to the archive and advances its version. It
+// is refused if the claim was changed since claim was read, by this
+// instance or another. The claim keeps its claim number.
+func (r *RedisRepository) ArchiveClaim(claim *models.Claim) error {
+	ctx := context.Background()
+	key := claimKey(claim.ID)
+
+	stored := *claim
+	stored.Version++
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		return fmt.Errorf("failed to encode claim %s: %w", claim.ID, err)
+	}
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		previous, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read claim %s: %w", claim.ID, err)
+		}
+		if len(previous) == 0 {
+			return fmt.Errorf("claim not found")
+		}
+		if previous["version"] != strconv.Itoa(claim.Version) {
+			return fmt.Errorf("claim version conflict")
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, field := range claimIndexes {
+				if previous[field] != "" {
+					pipe.SRem(ctx, claimIndexKey(field, previous[field]), claim.ID)
+				}
+			}
+			pipe.SRem(ctx, redisClaimsKey, claim.ID)
+			pipe.Del(ctx, key)
+			pipe.HSet(ctx, archivedClaimKey(claim.ID), "data", data)
+			pipe.SAdd(ctx, redisArchivedKey, claim.ID)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		return err
+	}
+
+	claim.Version = stored.Version
+	r.Notify(
+		hooks.Change{Op: hooks.OpDelete, Collection: "claims", Key: claim.ID},
+		hooks.Change{Op: hooks.OpCreate, Collection: "archivedClaims", Key: claim.ID, Value: claim},
+	)
+	return nil
+}
+
+// GetArchivedClaim retrieves an archived claim by ID
+func (r *RedisRepository) GetArchivedClaim(claimID string) (*models.Claim, error) {
+	var claim models.Claim
+	found, err := r.getData(context.Background(), archivedClaimKey(claimID), &claim)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("claim not found")
+	}
+	return &claim, nil
+}
+
+// GetArchivedClaims retrieves the archived claims matching the given
+// filters. The archive is not indexed, so every archived claim is read.
+func (r *RedisRepository) GetArchivedClaims(filters *models.ClaimFilters) []*models.Claim {
+	ctx := context.Background()
+
+	ids, err := r.client.SMembers(ctx, redisArchivedKey).Result()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list archived claims from redis")
+		return nil
+	}
+
+	var filtered []*models.Claim
+	for _, claim := range r.loadClaims(ctx, ids, archivedClaimKey) {
+		if claim.Matches(filters) {
+			filtered = append(filtered, claim)
+		}
+	}
+	return filtered
+}
+
+// GetPolicyByID retrieves a policy by ID
+func (r *RedisRepository) GetPolicyByID(policyID string) (*Policy, error) {
+	var policy Policy
+	found, err := r.getData(context.Background(), policyKey(policyID), &policy)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("policy not found")
+	}
+	return &policy, nil
+}
+
+// GetPolicyIDsByCustomerID retrieves all policy IDs for a given customer
+func (r *RedisRepository) GetPolicyIDsByCustomerID(customerID string) []string {
+	ids, err := r.client.SMembers(context.Background(), customerPoliciesKey(customerID)).Result()
+	if err != nil {
+		r.logger.WithError(err).WithField("customerId", customerID).Error("Failed to list customer policies from redis")
+		return nil
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+// GetCatastropheByID retrieves a catastrophe event by ID
+func (r *RedisRepository) GetCatastropheByID(catastropheID string) (*models.Catastrophe, error) {
+	var cat models.Catastrophe
+	found, err := r.getData(context.Background(), catastropheKey(catastropheID), &cat)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("catastrophe not found")
+	}
+	return &cat, nil
+}
+
+// GetAllCatastrophes returns all catastrophe events
+func (r *RedisRepository) GetAllCatastrophes() []*models.Catastrophe {
+	ctx := context.Background()
+
+	catastrophes := []*models.Catastrophe{}
+	ids, err := r.client.SMembers(ctx, redisCatsKey).Result()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list catastrophes from redis")
+		return catastrophes
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = catastropheKey(id)
+	}
+	if err := r.loadAll(ctx, keys, func(data []byte) error {
+		var cat models.Catastrophe
+		if err := json.Unmarshal(data, &cat); err != nil {
+			return err
+		}
+		catastrophes = append(catastrophes, &cat)
+		return nil
+	}); err != nil {
+		r.logger.WithError(err).Error("Failed to load catastrophes from redis")
+	}
+	return catastrophes
+}
+
+// CreateCatastrophe creates a new catastrophe event
+func (r *RedisRepository) CreateCatastrophe(cat *models.Catastrophe) error {
+	ctx := context.Background()
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		return queueCatastrophe(ctx, pipe, cat)
+	})
+	return r.announce(err, hooks.OpCreate, "catastrophes", cat.ID, cat)
+}
+
+// AddTimelineEntry records something that happened to a claim
+func (r *RedisRepository) AddTimelineEntry(entry models.TimelineEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode timeline entry: %w", err)
+	}
+	err = r.client.RPush(context.Background(), claimTimelineKey(entry.ClaimID), data).Err()
+	return r.announce(err, hooks.OpCreate, "timelines", entry.ClaimID, entry)
+}
+
+// GetTimelineEntries returns the entries recorded for a claim, in the order
+// they were recorded
+func (r *RedisRepository) GetTimelineEntries(claimID string) []models.TimelineEntry {
+	items, err := r.client.LRange(context.Background(), claimTimelineKey(claimID), 0, -1).Result()
+	if err != nil {
+		r.logger.WithError(err).WithField("claimId", claimID).Error("Failed to load timeline from redis")
+		return nil
+	}
+
+	var entries []models.TimelineEntry
+	for _, item := range items {
+		var entry models.TimelineEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			r.logger.WithError(err).WithField("claimId", claimID).Error("Skipping undecodable timeline entry")
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// AddDocument stores a document attached to a claim
+func (r *RedisRepository) AddDocument(doc *models.ClaimDocument, content []byte) error {
+	ctx := context.Background()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+	key := documentKey(doc.ID)
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read document %s: %w", doc.ID, err)
+		}
+		if exists > 0 {
+			return fmt.Errorf("document already exists")
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "data", data, "content", content)
+			pipe.RPush(ctx, claimDocumentsKey(doc.ClaimID), doc.ID)
+			return nil
+		})
+		return err
+	}, key)
+	return r.announce(err, hooks.OpCreate, "documents", doc.ID, doc)
+}
+
+// GetDocuments returns the documents attached to a claim in upload order
+func (r *RedisRepository) GetDocuments(claimID string) []*models.ClaimDocument {
+	ctx := context.Background()
+
+	docs := []*models.ClaimDocument{}
+	ids, err := r.client.LRange(ctx, claimDocumentsKey(claimID), 0, -1).Result()
+	if err != nil {
+		r.logger.WithError(err).WithField("claimId", claimID).Error("Failed to list documents from redis")
+		return docs
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = documentKey(id)
+	}
+	if err := r.loadAll(ctx, keys, func(data []byte) error {
+		var doc models.ClaimDocument
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+		docs = append(docs, &doc)
+		return nil
+	}); err != nil {
+		r.logger.WithError(err).WithField("claimId", claimID).Error("Failed to load documents from redis")
+	}
+	return docs
+}
+
+// GetDocument returns a claim document and its content
+func (r *RedisRepository) GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error) {
+	values, err := r.client.HMGet(context.Background(), documentKey(documentID), "data", "content").Result()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read document %s: %w", documentID, err)
+	}
+	data, ok := values[0].(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("document not found")
+	}
+
+	var doc models.ClaimDocument
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode document %s: %w", documentID, err)
+	}
+	if doc.ClaimID != claimID {
+		return nil, nil, fmt.Errorf("document not found")
+	}
+
+	content, _ := values[1].(string)
+	return &doc, []byte(content), nil
+}
+
+// UpdateDocument replaces the description of a claim document, such as
+// with the outcome of its malware scan. The content is unchanged.
+func (r *RedisRepository) UpdateDocument(doc *models.ClaimDocument) error {
+	ctx := context.Background()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+	key := documentKey(doc.ID)
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		current, err := tx.HGet(ctx, key, "data").Result()
+		if errors.Is(err, redis.Nil) {
+			return fmt.Errorf("document not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read document %s: %w", doc.ID, err)
+		}
+		var stored models.ClaimDocument
+		if err := json.Unmarshal([]byte(current), &stored); err != nil {
+			return fmt.Errorf("failed to decode document %s: %w", doc.ID, err)
+		}
+		if stored.ClaimID != doc.ClaimID {
+			return fmt.Errorf("document not found")
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "data", data)
+			return nil
+		})
+		return err
+	}, key)
+	return r.announce(err, hooks.OpUpdate, "documents", doc.ID, doc)
+}
+
+// CreateComment stores a new comment
+func (r *RedisRepository) CreateComment(comment *models.Comment) error {
+	ctx := context.Background()
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return fmt.Errorf("failed to encode comment: %w", err)
+	}
+	key := commentKey(comment.ID)
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read comment %s: %w", comment.ID, err)
+		}
+		if exists > 0 {
+			return fmt.Errorf("comment already exists")
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "data", data)
+			pipe.SAdd(ctx, claimCommentsKey(comment.ClaimID), comment.ID)
+			return nil
+		})
+		return err
+	}, key)
+	return r.announce(err, hooks.OpCreate, "comments", comment.ID, comment)
+}
+
+// GetComments returns the comments on a claim, oldest first
+func (r *RedisRepository) GetComments(claimID string) []*models.Comment {
+	ctx := context.Background()
+
+	comments := []*models.Comment{}
+	ids, err := r.client.SMembers(ctx, claimCommentsKey(claimID)).Result()
+	if err != nil {
+		r.logger.WithError(err).WithField("claimId", claimID).Error("Failed to list comments from redis")
+		return comments
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = commentKey(id)
+	}
+	if err := r.loadAll(ctx, keys, func(data []byte) error {
+		var comment models.Comment
+		if err := json.Unmarshal(data, &comment); err != nil {
+			return err
+		}
+		comments = append(comments, &comment)
+		return nil
+	}); err != nil {
+		r.logger.WithError(err).WithField("claimId", claimID).Error("Failed to load comments from redis")
+	}
+
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+	return comments
+}
+
+// GetComment returns a comment
+func (r *RedisRepository) GetComment(commentID string) (*models.Comment, error) {
+	var comment models.Comment
+	found, err := r.getData(context.Background(), commentKey(commentID), &comment)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("comment not found")
+	}
+	return &comment, nil
+}
+
+// UpdateComment replaces a stored comment
+func (r *RedisRepository) UpdateComment(comment *models.Comment) error {
+	ctx := context.Background()
+	data, err := json.Marshal(comment)
+	if err != nil {
+		return fmt.Errorf("failed to encode comment: %w", err)
+	}
+	key := commentKey(comment.ID)
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read comment %s: %w", comment.ID, err)
+		}
+		if exists == 0 {
+			return fmt.Errorf("comment not found")
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "data", data)
+			return nil
+		})
+		return err
+	}, key)
+	return r.announce(err, hooks.OpUpdate, "comments", comment.ID, comment)
+}
+
+// CreateDraft stores a new claim draft with its attachment content
+func (r *RedisRepository) CreateDraft(draft *models.ClaimDraft, contents [][]byte) error {
+	ctx := context.Background()
+	if len(contents) != len(draft.Attachments) {
+		return fmt.Errorf("draft has %d attachments but %d contents", len(draft.Attachments), len(contents))
+	}
+	data, err := json.Marshal(draft)
+	if err != nil {
+		return fmt.Errorf("failed to encode draft: %w", err)
+	}
+	key := draftKey(draft.ID)
+
+	fields := []interface{}{"data", data}
+	for i, attachment := range draft.Attachments {
+		fields = append(fields, draftContentField(attachment.ID), contents[i])
+	}
+
+	err = r.watch(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read draft %s: %w", draft.ID, err)
+		}
+		if exists > 0 {
+			return fmt.Errorf("draft already exists")
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, fields...)
+			pipe.SAdd(ctx, redisDraftsKey, draft.ID)
+			return nil
+		})
+		return err
+	}, key)
+	return r.announce(err, hooks.OpCreate, "drafts", draft.ID, draft)
+}
+
+// GetDraft returns a claim draft
+func (r *RedisRepository) GetDraft(draftID string) (*models.ClaimDraft, error) {
+	var draft models.ClaimDraft
+	found, err := r.getData(context.Background(), draftKey(draftID), &draft)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("draft not found")
+	}
+	return &draft, nil
+}
+
+// GetDrafts returns every claim draft, oldest first
+func (r *RedisRepository) GetDrafts() []*models.ClaimDraft {
+	ctx := context.Background()
+
+	drafts := []*models.ClaimDraft{}
+	ids, err := r.client.SMembers(ctx, redisDraftsKey).Result()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list drafts from redis")
+		return drafts
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = draftKey(id)
+	}
+	if err := r.loadAll(ctx, keys, func(data []byte) error {
+		var draft models.ClaimDraft
+		if err := json.Unmarshal(data, &draft); err != nil {
+			return err
+		}
+		drafts = append(drafts, &draft)
+		return nil
+	}); err != nil {
+		r.logger.WithError(err).Error("Failed to load drafts from redis")
+	}
+
+	sortDrafts(drafts)
+	return drafts
+}
+
+// GetDraftAttachment returns the content of a draft's attachment
+func (r *RedisRepository) GetDraftAttachment(draftID, attachmentID string) ([]byte, error) {
+	content, err := r.client.HGet(context.Background(), draftKey(draftID), draftContentField(attachmentID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", attachmentID, err)
+	}
+	return content, nil
+}
+
+// UpdateDraft replaces a stored claim draft. Its attachments are kept.
+func (r *RedisRepository) UpdateDraft(draft *models.ClaimDraft) error {
+	err := r.commit(context.Background(), []redisWrite{draftWrite(draft)})
+	return r.announce(err, hooks.OpUpdate, "drafts", draft.ID, draft)
+}
+
+// draftWrite is the write of UpdateDraft
+func draftWrite(draft *models.ClaimDraft) redisWrite {
+	data, err := json.Marshal(draft)
+	key := draftKey(draft.ID)
+
+	return redisWrite{keys: []string{key}, check: func(ctx context.Context, tx *redis.Tx) (*redisQueued, error) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode draft: %w", err)
+		}
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read draft %s: %w", draft.ID, err)
+		}
+		if exists == 0 {
+			return nil, fmt.Errorf("draft not found")
+		}
+
+		return &redisQueued{queue: func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "data", data)
+			return nil
+		}}, nil
+	}}
+}
+
+// CreateFNOL stores a new first notice of loss
+func (r *RedisRepository) CreateFNOL(report *models.FNOL) error {
+	return r.announce(r.saveFNOL(report, false), hooks.OpCreate, "fnol", report.ID, report)
+}
+
+// UpdateFNOL replaces a stored first notice of loss
+func (r *RedisRepository) UpdateFNOL(report *models.FNOL) error {
+	return r.announce(r.saveFNOL(report, true), hooks.OpUpdate, "fnol", report.ID, report)
+}
+
+// saveFNOL writes a report, which must already exist when update is set
+// and must not otherwise
+func (r *RedisRepository) saveFNOL(report *models.FNOL, update bool) error {
+	return r.commit(context.Background(), []redisWrite{fnolWrite(report, update)})
+}
+
+// fnolWrite is the write of saveFNOL
+func fnolWrite(report *models.FNOL, update bool) redisWrite {
+	data, err := json.Marshal(report)
+	key := fnolKey(report.ID)
+
+	return redisWrite{keys: []string{key}, check: func(ctx context.Context, tx *redis.Tx) (*redisQueued, error) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode report: %w", err)
+		}
+		exists, err := tx.Exists(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report %s: %w", report.ID, err)
+		}
+		if update && exists == 0 {
+			return nil, fmt.Errorf("report not found")
+		}
+		if !update && exists > 0 {
+			return nil, fmt.Errorf("report already exists")
+		}
+
+		return &redisQueued{queue: func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, "data", data)
+			pipe.SAdd(ctx, redisFNOLsKey, report.ID)
+			return nil
+		}}, nil
+	}}
+}
+
+// GetFNOL returns a first notice of loss
+func (r *RedisRepository) GetFNOL(reportID string) (*models.FNOL, error) {
+	var report models.FNOL
+	found, err := r.getData(context.Background(), fnolKey(reportID), &report)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("report not found")
+	}
+	return &report, nil
+}
+
+// GetFNOLs returns every first notice of loss, oldest first
+func (r *RedisRepository) GetFNOLs() []*models.FNOL {
+	ctx := context.Background()
+
+	reports := []*models.FNOL{}
+	ids, err := r.client.SMembers(ctx, redisFNOLsKey).Result()
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list reports from redis")
+		return reports
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = fnolKey(id)
+	}
+	if err := r.loadAll(ctx, keys, func(data []byte) error {
+		var report models.FNOL
+		if err := json.Unmarshal(data, &report); err != nil {
+			return err
+		}
+		reports = append(reports, &report)
+		return nil
+	}); err != nil {
+		r.logger.WithError(err).Error("Failed to load reports from redis")
+	}
+
+	sortFNOLs(reports)
+	return reports
+}
+
+// GetTwoFactor returns a user's two-factor enrollment
+func (r *RedisRepository) GetTwoFactor(userID string) (*models.TwoFactor, error) {
+	var tf models.TwoFactor
+	found, err := r.getData(context.Background(), twoFactorKey(userID), &tf)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("two-factor enrollment not found")
+	}
+	return &tf, nil
+}
+
+// SaveTwoFactor stores a user's two-factor enrollment, replacing any before
+func (r *RedisRepository) SaveTwoFactor(tf *models.TwoFactor) error {
+	data, err := json.Marshal(tf)
+	if err != nil {
+		return fmt.Errorf("failed to encode two-factor enrollment: %w", err)
+	}
+	if err := r.client.HSet(context.Background(), twoFactorKey(tf.UserID), "data", data).Err(); err != nil {
+		return fmt.Errorf("failed to save two-factor enrollment: %w", err)
+	}
+	return nil
+}
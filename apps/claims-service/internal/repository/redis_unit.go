@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisWrite is a write checked and queued in a Redis transaction. check
+// reads the watched keys and returns the commands to queue, or why the
+// write is refused.
+type redisWrite struct {
+	keys  []string // keys check reads, watched until the transaction runs
+	check func(ctx context.Context, tx *redis.Tx) (*redisQueued, error)
+}
+
+// redisQueued is a checked write, ready to queue
+type redisQueued struct {
+	queue func(pipe redis.Pipeliner) error
+	done  func() // run once the transaction succeeded, if set
+}
+
+// commit checks writes and stores them in one MULTI/EXEC transaction,
+// watching every key the checks read. If another instance changes one of
+// them first, the checks run again.
+func (r *RedisRepository) commit(ctx context.Context, writes []redisWrite) error {
+	var keys []string
+	for _, write := range writes {
+		keys = append(keys, write.keys...)
+	}
+
+	var queued []*redisQueued
+	err := r.watch(ctx, func(tx *redis.Tx) error {
+		queued = queued[:0]
+		for _, write := range writes {
+			q, err := write.check(ctx, tx)
+			if err != nil {
+				return err
+			}
+			queued = append(queued, q)
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, q := range queued {
+				if err := q.queue(pipe); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+	if err != nil {
+		return err
+	}
+
+	for _, q := range queued {
+		if q.done != nil {
+			q.done()
+		}
+	}
+	return nil
+}
+
+// redisUnitOfWork stages writes for one Redis transaction, so they are
+// stored together or not at all on every instance
+type redisUnitOfWork struct {
+	repo      *RedisRepository
+	writes    []redisWrite
+	changes   []hooks.Change
+	committed bool
+}
+
+// Begin starts a unit of work
+func (r *RedisRepository) Begin() UnitOfWork {
+	return &redisUnitOfWork{repo: r}
+}
+
+// CreateClaim stages the creation of a claim at its first version
+func (u *redisUnitOfWork) CreateClaim(claim *models.Claim) {
+	claim.Version = 1
+	u.stage(claimWrite(claim, false), hooks.OpCreate, "claims", claim.ID, claim)
+}
+
+// UpdateFNOL stages the replacement of a stored first notice of loss
+func (u *redisUnitOfWork) UpdateFNOL(report *models.FNOL) {
+	u.stage(fnolWrite(report, true), hooks.OpUpdate, "fnol", report.ID, report)
+}
+
+// UpdateDraft stages the replacement of a stored claim draft
+func (u *redisUnitOfWork) UpdateDraft(draft *models.ClaimDraft) {
+	u.stage(draftWrite(draft), hooks.OpUpdate, "drafts", draft.ID, draft)
+}
+
+// stage adds a write and the change its hooks are told of
+func (u *redisUnitOfWork) stage(write redisWrite, op, collection, key string, value interface{}) {
+	u.writes = append(u.writes, write)
+	u.changes = append(u.changes, hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+}
+
+// Commit stores the staged writes in one transaction, then runs their hooks
+func (u *redisUnitOfWork) Commit() error {
+	if u.committed {
+		return fmt.Errorf("unit of work already committed")
+	}
+	u.committed = true
+
+	if err := u.repo.commit(context.Background(), u.writes); err != nil {
+		return err
+	}
+	u.repo.Notify(u.changes...)
+	return nil
+}
@@ -1,36 +1,82 @@
 package repository
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
 	"github.com/sirupsen/logrus"
 )
 
-// Policy represents an insurance policy (minimal structure needed for filtering)
+// Policy represents an insurance policy (minimal structure needed for
+// filtering, routing, incident date validation and ACORD exports)
 type Policy struct {
-	ID         string `json:"id"`
-	CustomerID string `json:"customerId"`
+	ID           string    `json:"id"`
+	CustomerID   string    `json:"customerId"`
+	PolicyNumber string    `json:"policyNumber"`
+	Type         string    `json:"type"`
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate"`
 }
 
-// Repository provides data access for claims
+// Covers reports whether t falls within the policy term. Policies without
+// recorded dates are treated as covering any date.
+func (p *Policy) Covers(t time.Time) bool {
+	if p.StartDate.IsZero() || p.EndDate.IsZero() {
+		return true
+	}
+	return !t.Before(p.StartDate) && !t.After(p.EndDate)
+}
+
+// Repository provides data access for claims. Register hooks with
+// OnCreate and OnUpdate to follow its writes.
 type Repository struct {
-	claims   map[string]*models.Claim
-	policies map[string]*Policy // policyID -> Policy
-	mu       sync.RWMutex
-	logger   *logrus.Logger
+	hooks.Hooks
+
+	claims       map[string]*models.Claim
+	archived     map[string]*models.Claim // claims moved out by the retention policy
+	policies     map[string]*Policy       // policyID -> Policy
+	catastrophes map[string]*models.Catastrophe
+	timelines    map[string][]models.TimelineEntry  // claimID -> entries in the order recorded
+	documents    map[string][]*models.ClaimDocument // claimID -> documents in upload order
+	contents     map[string][]byte                  // documentID -> document content
+	comments     map[string]*models.Comment
+	drafts       map[string]*models.ClaimDraft
+	fnols        map[string]*models.FNOL
+	twoFactors   map[string]*models.TwoFactor
+	numbers      map[string]string // claim number -> claimID
+	sequences    map[int]int64     // year -> last claim number sequence drawn
+	journal      *persist.Journal  // nil unless Persist is called
+	policyLocks  PolicyLocks
+	pending      []hooks.Change // written under mu, announced by unlock
+	mu           sync.RWMutex
+	logger       *logrus.Logger
 }
 
 // NewRepository creates a new repository and loads data from JSON files
 func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error) {
 	repo := &Repository{
-		claims:   make(map[string]*models.Claim),
-		policies: make(map[string]*Policy),
-		logger:   logger,
+		claims:       make(map[string]*models.Claim),
+		archived:     make(map[string]*models.Claim),
+		policies:     make(map[string]*Policy),
+		catastrophes: make(map[string]*models.Catastrophe),
+		timelines:    make(map[string][]models.TimelineEntry),
+		documents:    make(map[string][]*models.ClaimDocument),
+		contents:     make(map[string][]byte),
+		comments:     make(map[string]*models.Comment),
+		drafts:       make(map[string]*models.ClaimDraft),
+		fnols:        make(map[string]*models.FNOL),
+		twoFactors:   make(map[string]*models.TwoFactor),
+		numbers:      make(map[string]string),
+		sequences:    make(map[int]int64),
+		logger:       logger,
 	}
 
 	// Load policies first (needed for customer filtering)
@@ -45,20 +91,148 @@ func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error)
 		logger.Warnf("Failed to load claims: %v", err)
 	}
 
-	logger.Infof("Loaded %d policies and %d claims from %s", len(repo.policies), len(repo.claims), dataPath)
+	// Load declared catastrophe events
+	if err := repo.loadCatastrophes(filepath.Join(dataPath, "catastrophes.json")); err != nil {
+		// Log warning but continue - no events may have been declared yet
+		logger.Warnf("Failed to load catastrophes: %v", err)
+	}
+
+	logger.Infof("Loaded %d policies, %d claims and %d catastrophes from %s", len(repo.policies), len(repo.claims), len(repo.catastrophes), dataPath)
 
 	return repo, nil
 }
 
-// loadPolicies loads policies from a JSON file
-func (r *Repository) loadPolicies(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+// Persist replaces the seed data with the state recovered from journal and
+// records every later change in it. Call it before the repository is used.
+// Policies are a read-only copy of policy-service's seed data and are not
+// persisted.
+func (r *Repository) Persist(journal *persist.Journal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := persist.Restore(journal, "claims", func(id string, claim *models.Claim) {
+		if claim.Version == 0 {
+			claim.Version = 1
+		}
+		r.claims[id] = claim
+	}, func(id string) {
+		delete(r.claims, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "archivedClaims", func(id string, claim *models.Claim) {
+		r.archived[id] = claim
+	}, func(id string) {
+		delete(r.archived, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "catastrophes", func(id string, cat *models.Catastrophe) {
+		r.catastrophes[id] = cat
+	}, func(id string) {
+		delete(r.catastrophes, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "timelines", func(claimID string, entries []models.TimelineEntry) {
+		r.timelines[claimID] = entries
+	}, func(claimID string) {
+		delete(r.timelines, claimID)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "documents", func(claimID string, docs []*models.ClaimDocument) {
+		r.documents[claimID] = docs
+	}, func(claimID string) {
+		delete(r.documents, claimID)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "contents", func(documentID string, content []byte) {
+		r.contents[documentID] = content
+	}, func(documentID string) {
+		delete(r.contents, documentID)
+	}); err != nil {
 		return err
 	}
+	if err := persist.Restore(journal, "comments", func(id string, comment *models.Comment) {
+		r.comments[id] = comment
+	}, func(id string) {
+		delete(r.comments, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "drafts", func(id string, draft *models.ClaimDraft) {
+		r.drafts[id] = draft
+	}, func(id string) {
+		delete(r.drafts, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "fnol", func(id string, report *models.FNOL) {
+		r.fnols[id] = report
+	}, func(id string) {
+		delete(r.fnols, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "twoFactor", func(userID string, tf *models.TwoFactor) {
+		r.twoFactors[userID] = tf
+	}, func(userID string) {
+		delete(r.twoFactors, userID)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "sequences", func(year string, seq int64) {
+		if y, err := strconv.Atoi(year); err == nil {
+			r.sequences[y] = seq
+		}
+	}, func(year string) {
+		if y, err := strconv.Atoi(year); err == nil {
+			delete(r.sequences, y)
+		}
+	}); err != nil {
+		return err
+	}
+
+	// Archiving writes the archived copy before deleting the live claim, so
+	// a crash in between can restore both; the archived copy is the later
+	for id := range r.archived {
+		delete(r.claims, id)
+	}
+
+	// The claim number index follows the restored claims, archived or not
+	r.numbers = make(map[string]string, len(r.claims)+len(r.archived))
+	for _, claims := range []map[string]*models.Claim{r.claims, r.archived} {
+		for id, claim := range claims {
+			if claim.ClaimNumber != "" {
+				r.numbers[claim.ClaimNumber] = id
+			}
+		}
+	}
+
+	r.journal = journal
+	return nil
+}
 
+// changed records a write made under the lock, for unlock to announce
+func (r *Repository) changed(op, collection, key string, value interface{}) {
+	r.pending = append(r.pending, hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+}
+
+// unlock releases the write lock and then runs the hooks of the writes
+// made under it, so hooks can use the repository
+func (r *Repository) unlock() {
+	changes := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	r.Notify(changes...)
+}
+
+// loadPolicies loads policies from policy-service's JSON file
+func (r *Repository) loadPolicies(filePath string) error {
 	var policies []*Policy
-	if err := json.Unmarshal(data, &policies); err != nil {
+	if _, err := migrate.Read(filePath, &policies); err != nil {
 		return err
 	}
 
@@ -74,27 +248,51 @@ func (r *Repository) loadPolicies(filePath string) error {
 
 // loadClaims loads claims from a JSON file
 func (r *Repository) loadClaims(filePath string) error {
-	data, err := os.ReadFile(filePath)
+	var claims []*models.Claim
+	result, err := migrate.Load(filePath, claimMigrations, &claims)
 	if err != nil {
 		return err
 	}
+	logMigration(r.logger, filePath, result)
 
-	var claims []*models.Claim
-	if err := json.Unmarshal(data, &claims); err != nil {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, claim := range claims {
+		// Seed claims start at the first version
+		if claim.Version == 0 {
+			claim.Version = 1
+		}
+		r.claims[claim.ID] = claim
+		if claim.ClaimNumber != "" {
+			r.numbers[claim.ClaimNumber] = claim.ID
+		}
+	}
+
+	return nil
+}
+
+// loadCatastrophes loads catastrophe events from a JSON file
+func (r *Repository) loadCatastrophes(filePath string) error {
+	var catastrophes []*models.Catastrophe
+	result, err := migrate.Load(filePath, catastropheMigrations, &catastrophes)
+	if err != nil {
 		return err
 	}
+	logMigration(r.logger, filePath, result)
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for _, claim := range claims {
-		r.claims[claim.ID] = claim
+	for _, cat := range catastrophes {
+		r.catastrophes[cat.ID] = cat
 	}
 
 	return nil
 }
 
-// GetClaimByID retrieves a claim by ID
+// GetClaimByID retrieves a copy of a claim by ID. Changes only take effect
+// through UpdateClaim, so its version check sees every concurrent change.
 func (r *Repository) GetClaimByID(claimID string) (*models.Claim, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -104,22 +302,37 @@ func (r *Repository) GetClaimByID(claimID string) (*models.Claim, error) {
 		return nil, fmt.Errorf("claim not found")
 	}
 
-	return claim, nil
+	copied := *claim
+	return &copied, nil
 }
 
-// GetAllClaims returns all claims
+// GetAllClaims returns copies of all claims
 func (r *Repository) GetAllClaims() []*models.Claim {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	claims := make([]*models.Claim, 0, len(r.claims))
 	for _, claim := range r.claims {
-		claims = append(claims, claim)
+		copied := *claim
+		claims = append(claims, &copied)
 	}
 
 	return claims
 }
 
+// GetPolicyByID retrieves a policy by ID
+func (r *Repository) GetPolicyByID(policyID string) (*Policy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, exists := r.policies[policyID]
+	if !exists {
+		return nil, fmt.Errorf("policy not found")
+	}
+
+	return policy, nil
+}
+
 // GetPolicyIDsByCustomerID retrieves all policy IDs for a given customer
 func (r *Repository) GetPolicyIDsByCustomerID(customerID string) []string {
 	r.mu.RLock()
@@ -135,7 +348,8 @@ func (r *Repository) GetPolicyIDsByCustomerID(customerID string) []string {
 	return policyIDs
 }
 
-// GetClaimsByFilter retrieves claims matching the given filters
+// GetClaimsByFilter retrieves copies of the claims matching the given
+// filters
 func (r *Repository) GetClaimsByFilter(filters *models.ClaimFilters) []*models.Claim {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -143,31 +357,580 @@ func (r *Repository) GetClaimsByFilter(filters *models.ClaimFilters) []*models.C
 	var filtered []*models.Claim
 	for _, claim := range r.claims {
 		if claim.Matches(filters) {
-			filtered = append(filtered, claim)
+			copied := *claim
+			filtered = append(filtered, &copied)
 		}
 	}
 
 	return filtered
 }
 
-// CreateClaim creates a new claim
+// CreateClaim creates a new claim at its first version. It is refused if
+// another claim already has its claim number.
 func (r *Repository) CreateClaim(claim *models.Claim) error {
+	work := r.Begin()
+	work.CreateClaim(claim)
+	return work.Commit()
+}
+
+// createClaim makes a staged CreateClaim under the write lock
+func (r *Repository) createClaim(claim *models.Claim, batch *persist.Batch) (func(), error) {
+	if owner, taken := r.numbers[claim.ClaimNumber]; taken && owner != claim.ID {
+		return nil, fmt.Errorf("claim number already exists")
+	}
+	claim.Version = 1
+	batch.Put("claims", claim.ID, claim)
+
+	previous, existed := r.claims[claim.ID]
+	owner, numbered := r.numbers[claim.ClaimNumber]
+	stored := *claim
+	r.claims[claim.ID] = &stored
+	if claim.ClaimNumber != "" {
+		r.numbers[claim.ClaimNumber] = claim.ID
+	}
+	r.changed(hooks.OpCreate, "claims", claim.ID, claim)
+	return func() {
+		restore(r.claims, claim.ID, previous, existed)
+		restore(r.numbers, claim.ClaimNumber, owner, numbered)
+	}, nil
+}
+
+// UpdateClaim updates an existing claim and advances its version. The
+// update is refused if the claim was changed since claim was read.
+func (r *Repository) UpdateClaim(claim *models.Claim) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
-	r.claims[claim.ID] = claim
+	existing, exists := r.claims[claim.ID]
+	if !exists {
+		return fmt.Errorf("claim not found")
+	}
+	if existing.Version != claim.Version {
+		return fmt.Errorf("claim version conflict")
+	}
+	if owner, taken := r.numbers[claim.ClaimNumber]; taken && owner != claim.ID {
+		return fmt.Errorf("claim number already exists")
+	}
+
+	stored := *claim
+	stored.Version++
+	if err := r.journal.Put("claims", claim.ID, &stored); err != nil {
+		return err
+	}
+	r.claims[claim.ID] = &stored
+	if existing.ClaimNumber != claim.ClaimNumber {
+		delete(r.numbers, existing.ClaimNumber)
+		if claim.ClaimNumber != "" {
+			r.numbers[claim.ClaimNumber] = claim.ID
+		}
+	}
+	claim.Version = stored.Version
+	r.changed(hooks.OpUpdate, "claims", claim.ID, claim)
 	return nil
 }
 
-// UpdateClaim updates an existing claim
-func (r *Repository) UpdateClaim(claim *models.Claim) error {
+// NextClaimSequence draws the next claim number sequence of a year. Each
+// year's sequence starts at 1 and never hands out the same value twice.
+func (r *Repository) NextClaimSequence(year int) (int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.claims[claim.ID]; !exists {
+	seq := r.sequences[year] + 1
+	if err := r.journal.Put("sequences", strconv.Itoa(year), seq); err != nil {
+		return 0, err
+	}
+	r.sequences[year] = seq
+	return seq, nil
+}
+
+// LockPolicy starts a write section for policyID's claims. Sections for
+// the same policy run one at a time; the lock is held in this process only.
+func (r *Repository) LockPolicy(policyID string) (func(), error) {
+	return r.policyLocks.Lock(policyID), nil
+}
+
+// ArchiveClaim moves a claim to the archive and advances its version. It
+// is refused if the claim was changed since claim was read. The claim
+// keeps its claim number.
+func (r *Repository) ArchiveClaim(claim *models.Claim) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	existing, exists := r.claims[claim.ID]
+	if !exists {
 		return fmt.Errorf("claim not found")
 	}
+	if existing.Version != claim.Version {
+		return fmt.Errorf("claim version conflict")
+	}
+
+	stored := *claim
+	stored.Version++
+	if err := r.journal.Put("archivedClaims", claim.ID, &stored); err != nil {
+		return err
+	}
+	if err := r.journal.Delete("claims", claim.ID); err != nil {
+		return err
+	}
+	r.archived[claim.ID] = &stored
+	delete(r.claims, claim.ID)
+	claim.Version = stored.Version
+	r.changed(hooks.OpDelete, "claims", claim.ID, nil)
+	r.changed(hooks.OpCreate, "archivedClaims", claim.ID, claim)
+	return nil
+}
+
+// GetArchivedClaim retrieves a copy of an archived claim by ID
+func (r *Repository) GetArchivedClaim(claimID string) (*models.Claim, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	claim, exists := r.archived[claimID]
+	if !exists {
+		return nil, fmt.Errorf("claim not found")
+	}
+
+	copied := *claim
+	return &copied, nil
+}
+
+// GetArchivedClaims retrieves copies of the archived claims matching the
+// given filters
+func (r *Repository) GetArchivedClaims(filters *models.ClaimFilters) []*models.Claim {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var filtered []*models.Claim
+	for _, claim := range r.archived {
+		if claim.Matches(filters) {
+			copied := *claim
+			filtered = append(filtered, &copied)
+		}
+	}
+
+	return filtered
+}
+
+// GetCatastropheByID retrieves a catastrophe event by ID
+func (r *Repository) GetCatastropheByID(catastropheID string) (*models.Catastrophe, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cat, exists := r.catastrophes[catastropheID]
+	if !exists {
+		return nil, fmt.Errorf("catastrophe not found")
+	}
+
+	return cat, nil
+}
+
+// GetAllCatastrophes returns all catastrophe events
+func (r *Repository) GetAllCatastrophes() []*models.Catastrophe {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	catastrophes := make([]*models.Catastrophe, 0, len(r.catastrophes))
+	for _, cat := range r.catastrophes {
+		catastrophes = append(catastrophes, cat)
+	}
+
+	return catastrophes
+}
+
+// CreateCatastrophe creates a new catastrophe event
+func (r *Repository) CreateCatastrophe(cat *models.Catastrophe) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("catastrophes", cat.ID, cat); err != nil {
+		return err
+	}
+	r.catastrophes[cat.ID] = cat
+	r.changed(hooks.OpCreate, "catastrophes", cat.ID, cat)
+	return nil
+}
+
+// AddTimelineEntry records something that happened to a claim
+func (r *Repository) AddTimelineEntry(entry models.TimelineEntry) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	entries := append(append([]models.TimelineEntry(nil), r.timelines[entry.ClaimID]...), entry)
+	if err := r.journal.Put("timelines", entry.ClaimID, entries); err != nil {
+		return err
+	}
+	r.timelines[entry.ClaimID] = entries
+	r.changed(hooks.OpCreate, "timelines", entry.ClaimID, entry)
+	return nil
+}
+
+// GetTimelineEntries returns the entries recorded for a claim, in the order
+// they were recorded
+func (r *Repository) GetTimelineEntries(claimID string) []models.TimelineEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]models.TimelineEntry(nil), r.timelines[claimID]...)
+}
+
+// AddDocument stores a document attached to a claim
+func (r *Repository) AddDocument(doc *models.ClaimDocument, content []byte) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.contents[doc.ID]; exists {
+		return fmt.Errorf("document already exists")
+	}
 
-	r.claims[claim.ID] = claim
+	docs := append(append([]*models.ClaimDocument(nil), r.documents[doc.ClaimID]...), doc)
+	if err := r.journal.Put("contents", doc.ID, content); err != nil {
+		return err
+	}
+	if err := r.journal.Put("documents", doc.ClaimID, docs); err != nil {
+		return err
+	}
+	r.documents[doc.ClaimID] = docs
+	r.contents[doc.ID] = content
+	r.changed(hooks.OpCreate, "documents", doc.ID, doc)
 	return nil
 }
+
+// GetDocuments returns the documents attached to a claim in upload order
+func (r *Repository) GetDocuments(claimID string) []*models.ClaimDocument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]*models.ClaimDocument{}, r.documents[claimID]...)
+}
+
+// GetDocument returns a claim document and its content
+func (r *Repository) GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, doc := range r.documents[claimID] {
+		if doc.ID == documentID {
+			return doc, r.contents[doc.ID], nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("document not found")
+}
+
+// UpdateDocument replaces the description of a claim document, such as
+// with the outcome of its malware scan. The content is unchanged.
+func (r *Repository) UpdateDocument(doc *models.ClaimDocument) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	docs := append([]*models.ClaimDocument(nil), r.documents[doc.ClaimID]...)
+	found := false
+	for i := range docs {
+		if docs[i].ID == doc.ID {
+			docs[i], found = doc, true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("document not found")
+	}
+
+	if err := r.journal.Put("documents", doc.ClaimID, docs); err != nil {
+		return err
+	}
+	r.documents[doc.ClaimID] = docs
+	r.changed(hooks.OpUpdate, "documents", doc.ID, doc)
+	return nil
+}
+
+// CreateComment stores a new comment
+func (r *Repository) CreateComment(comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.comments[comment.ID]; exists {
+		return fmt.Errorf("comment already exists")
+	}
+
+	if err := r.journal.Put("comments", comment.ID, comment); err != nil {
+		return err
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	r.changed(hooks.OpCreate, "comments", comment.ID, comment)
+	return nil
+}
+
+// GetComments returns copies of the comments on a claim, oldest first
+func (r *Repository) GetComments(claimID string) []*models.Comment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []*models.Comment{}
+	for _, comment := range r.comments {
+		if comment.ClaimID == claimID {
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+
+	return comments
+}
+
+// GetComment returns a copy of a comment
+func (r *Repository) GetComment(commentID string) (*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, exists := r.comments[commentID]
+	if !exists {
+		return nil, fmt.Errorf("comment not found")
+	}
+
+	copied := *comment
+	return &copied, nil
+}
+
+// UpdateComment replaces a stored comment
+func (r *Repository) UpdateComment(comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.comments[comment.ID]; !exists {
+		return fmt.Errorf("comment not found")
+	}
+
+	if err := r.journal.Put("comments", comment.ID, comment); err != nil {
+		return err
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	r.changed(hooks.OpUpdate, "comments", comment.ID, comment)
+	return nil
+}
+
+// CreateDraft stores a new claim draft with its attachment content
+func (r *Repository) CreateDraft(draft *models.ClaimDraft, contents [][]byte) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.drafts[draft.ID]; exists {
+		return fmt.Errorf("draft already exists")
+	}
+	if len(contents) != len(draft.Attachments) {
+		return fmt.Errorf("draft has %d attachments but %d contents", len(draft.Attachments), len(contents))
+	}
+
+	for i, attachment := range draft.Attachments {
+		if err := r.journal.Put("contents", attachment.ID, contents[i]); err != nil {
+			return err
+		}
+	}
+	if err := r.journal.Put("drafts", draft.ID, draft); err != nil {
+		return err
+	}
+	for i, attachment := range draft.Attachments {
+		r.contents[attachment.ID] = contents[i]
+	}
+	r.drafts[draft.ID] = copyDraft(draft)
+	r.changed(hooks.OpCreate, "drafts", draft.ID, draft)
+	return nil
+}
+
+// GetDraft returns a copy of a claim draft
+func (r *Repository) GetDraft(draftID string) (*models.ClaimDraft, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	draft, exists := r.drafts[draftID]
+	if !exists {
+		return nil, fmt.Errorf("draft not found")
+	}
+	return copyDraft(draft), nil
+}
+
+// GetDrafts returns copies of every claim draft, oldest first
+func (r *Repository) GetDrafts() []*models.ClaimDraft {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	drafts := make([]*models.ClaimDraft, 0, len(r.drafts))
+	for _, draft := range r.drafts {
+		drafts = append(drafts, copyDraft(draft))
+	}
+	sortDrafts(drafts)
+	return drafts
+}
+
+// GetDraftAttachment returns the content of a draft's attachment
+func (r *Repository) GetDraftAttachment(draftID, attachmentID string) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if draft, exists := r.drafts[draftID]; exists {
+		for _, attachment := range draft.Attachments {
+			if attachment.ID == attachmentID {
+				return r.contents[attachment.ID], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("attachment not found")
+}
+
+// UpdateDraft replaces a stored claim draft. Its attachments are kept.
+func (r *Repository) UpdateDraft(draft *models.ClaimDraft) error {
+	work := r.Begin()
+	work.UpdateDraft(draft)
+	return work.Commit()
+}
+
+// updateDraft makes a staged UpdateDraft under the write lock
+func (r *Repository) updateDraft(draft *models.ClaimDraft, batch *persist.Batch) (func(), error) {
+	previous, exists := r.drafts[draft.ID]
+	if !exists {
+		return nil, fmt.Errorf("draft not found")
+	}
+
+	batch.Put("drafts", draft.ID, draft)
+	r.drafts[draft.ID] = copyDraft(draft)
+	r.changed(hooks.OpUpdate, "drafts", draft.ID, draft)
+	return func() { r.drafts[draft.ID] = previous }, nil
+}
+
+// CreateFNOL stores a new first notice of loss
+func (r *Repository) CreateFNOL(report *models.FNOL) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.fnols[report.ID]; exists {
+		return fmt.Errorf("report already exists")
+	}
+
+	if err := r.journal.Put("fnol", report.ID, report); err != nil {
+		return err
+	}
+	r.fnols[report.ID] = copyFNOL(report)
+	r.changed(hooks.OpCreate, "fnol", report.ID, report)
+	return nil
+}
+
+// GetFNOL returns a copy of a first notice of loss
+func (r *Repository) GetFNOL(reportID string) (*models.FNOL, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report, exists := r.fnols[reportID]
+	if !exists {
+		return nil, fmt.Errorf("report not found")
+	}
+	return copyFNOL(report), nil
+}
+
+// GetFNOLs returns copies of every first notice of loss, oldest first
+func (r *Repository) GetFNOLs() []*models.FNOL {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reports := make([]*models.FNOL, 0, len(r.fnols))
+	for _, report := range r.fnols {
+		reports = append(reports, copyFNOL(report))
+	}
+	sortFNOLs(reports)
+	return reports
+}
+
+// UpdateFNOL replaces a stored first notice of loss
+func (r *Repository) UpdateFNOL(report *models.FNOL) error {
+	work := r.Begin()
+	work.UpdateFNOL(report)
+	return work.Commit()
+}
+
+// updateFNOL makes a staged UpdateFNOL under the write lock
+func (r *Repository) updateFNOL(report *models.FNOL, batch *persist.Batch) (func(), error) {
+	previous, exists := r.fnols[report.ID]
+	if !exists {
+		return nil, fmt.Errorf("report not found")
+	}
+
+	batch.Put("fnol", report.ID, report)
+	r.fnols[report.ID] = copyFNOL(report)
+	r.changed(hooks.OpUpdate, "fnol", report.ID, report)
+	return func() { r.fnols[report.ID] = previous }, nil
+}
+
+// GetTwoFactor returns a copy of a user's two-factor enrollment
+func (r *Repository) GetTwoFactor(userID string) (*models.TwoFactor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tf, exists := r.twoFactors[userID]
+	if !exists {
+		return nil, fmt.Errorf("two-factor enrollment not found")
+	}
+	return copyTwoFactor(tf), nil
+}
+
+// SaveTwoFactor stores a user's two-factor enrollment, replacing any before
+func (r *Repository) SaveTwoFactor(tf *models.TwoFactor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.journal.Put("twoFactor", tf.UserID, tf); err != nil {
+		return err
+	}
+	r.twoFactors[tf.UserID] = copyTwoFactor(tf)
+	return nil
+}
+
+// copyTwoFactor copies an enrollment and its recovery codes, so stored
+// enrollments are not changed through the copies handed out
+func copyTwoFactor(tf *models.TwoFactor) *models.TwoFactor {
+	copied := *tf
+	copied.RecoveryCodes = append([]string(nil), tf.RecoveryCodes...)
+	return &copied
+}
+
+// copyFNOL copies a report and its completed steps, so stored reports are
+// not changed through the copies handed out. Sections are replaced rather
+// than edited in place.
+func copyFNOL(report *models.FNOL) *models.FNOL {
+	copied := *report
+	copied.CompletedSteps = append([]int{}, report.CompletedSteps...)
+	return &copied
+}
+
+// sortFNOLs orders reports by when they were started, oldest first
+func sortFNOLs(reports []*models.FNOL) {
+	sort.Slice(reports, func(i, j int) bool {
+		if !reports[i].CreatedAt.Equal(reports[j].CreatedAt) {
+			return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+		}
+		return reports[i].ID < reports[j].ID
+	})
+}
+
+// copyDraft copies a draft and its slices, so stored drafts are not changed
+// through the copies handed out
+func copyDraft(draft *models.ClaimDraft) *models.ClaimDraft {
+	copied := *draft
+	copied.Attachments = append([]models.DraftAttachment{}, draft.Attachments...)
+	copied.Issues = append([]string(nil), draft.Issues...)
+	return &copied
+}
+
+// sortDrafts orders drafts by when they were received, oldest first
+func sortDrafts(drafts []*models.ClaimDraft) {
+	sort.Slice(drafts, func(i, j int) bool {
+		if !drafts[i].ReceivedAt.Equal(drafts[j].ReceivedAt) {
+			return drafts[i].ReceivedAt.Before(drafts[j].ReceivedAt)
+		}
+		return drafts[i].ID < drafts[j].ID
+	})
+}
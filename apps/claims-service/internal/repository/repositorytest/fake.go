@@ -0,0 +1,703 @@
+// Package repositorytest provides an in-memory ClaimStore for unit tests
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+)
+
+// FakeStore is an in-memory ClaimStore. Set Err to make every claim lookup
+// and write fail, e.g. to exercise a handler's error path.
+type FakeStore struct {
+	Err error
+
+	mu           sync.Mutex
+	claims       map[string]*models.Claim
+	archived     map[string]*models.Claim
+	policies     map[string]*repository.Policy
+	catastrophes map[string]*models.Catastrophe
+	timelines    map[string][]models.TimelineEntry
+	documents    map[string][]*models.ClaimDocument
+	contents     map[string][]byte
+	comments     map[string]*models.Comment
+	drafts       map[string]*models.ClaimDraft
+	fnols        map[string]*models.FNOL
+	twoFactors   map[string]*models.TwoFactor
+	sequences    map[int]int64
+	policyLocks  repository.PolicyLocks
+}
+
+var (
+	_ repository.ClaimStore     = (*FakeStore)(nil)
+	_ repository.CommentStore   = (*FakeStore)(nil)
+	_ repository.DraftStore     = (*FakeStore)(nil)
+	_ repository.FNOLStore      = (*FakeStore)(nil)
+	_ repository.TwoFactorStore = (*FakeStore)(nil)
+)
+
+// NewFakeStore creates a fake holding the given claims and no policies
+func NewFakeStore(claims ...*models.Claim) *FakeStore {
+	f := &FakeStore{
+		claims:       make(map[string]*models.Claim),
+		archived:     make(map[string]*models.Claim),
+		policies:     make(map[string]*repository.Policy),
+		catastrophes: make(map[string]*models.Catastrophe),
+		timelines:    make(map[string][]models.TimelineEntry),
+		documents:    make(map[string][]*models.ClaimDocument),
+		contents:     make(map[string][]byte),
+		comments:     make(map[string]*models.Comment),
+		drafts:       make(map[string]*models.ClaimDraft),
+		fnols:        make(map[string]*models.FNOL),
+		twoFactors:   make(map[string]*models.TwoFactor),
+		sequences:    make(map[int]int64),
+	}
+	for _, claim := range claims {
+		f.claims[claim.ID] = claim
+	}
+	return f
+}
+
+// AddPolicy makes a policy known to the fake, e.g. so new claims are routed
+// to the queue for its line
+func (f *FakeStore) AddPolicy(policy *repository.Policy) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.policies[policy.ID] = policy
+}
+
+// GetClaimByID returns the stored claim
+func (f *FakeStore) GetClaimByID(claimID string) (*models.Claim, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	claim, exists := f.claims[claimID]
+	if !exists {
+		return nil, fmt.Errorf("claim not found")
+	}
+	return claim, nil
+}
+
+// GetAllClaims returns every claim ordered by ID
+func (f *FakeStore) GetAllClaims() []*models.Claim {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted(nil)
+}
+
+// GetClaimsByFilter returns the claims matching filters ordered by ID
+func (f *FakeStore) GetClaimsByFilter(filters *models.ClaimFilters) []*models.Claim {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted(filters)
+}
+
+// CreateClaim stores a new claim, refusing a claim number another claim
+// has
+func (f *FakeStore) CreateClaim(claim *models.Claim) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if f.numberTaken(claim) {
+		return fmt.Errorf("claim number already exists")
+	}
+	f.claims[claim.ID] = claim
+	return nil
+}
+
+// NextClaimSequence draws the next claim number sequence of a year
+func (f *FakeStore) NextClaimSequence(year int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return 0, f.Err
+	}
+	f.sequences[year]++
+	return f.sequences[year], nil
+}
+
+// LockPolicy starts a write section for policyID's claims
+func (f *FakeStore) LockPolicy(policyID string) (func(), error) {
+	f.mu.Lock()
+	err := f.Err
+	f.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return f.policyLocks.Lock(policyID), nil
+}
+
+// numberTaken reports whether another claim has claim's claim number
+func (f *FakeStore) numberTaken(claim *models.Claim) bool {
+	if claim.ClaimNumber == "" {
+		return false
+	}
+	for _, claims := range []map[string]*models.Claim{f.claims, f.archived} {
+		for id, other := range claims {
+			if id != claim.ID && other.ClaimNumber == claim.ClaimNumber {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UpdateClaim replaces a stored claim and advances its version, refusing
+// the update if the stored claim is at another version
+func (f *FakeStore) UpdateClaim(claim *models.Claim) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	existing, exists := f.claims[claim.ID]
+	if !exists {
+		return fmt.Errorf("claim not found")
+	}
+	if existing.Version != claim.Version {
+		return fmt.Errorf("claim version conflict")
+	}
+	if f.numberTaken(claim) {
+		return fmt.Errorf("claim number already exists")
+	}
+	claim.Version++
+	f.claims[claim.ID] = claim
+	return nil
+}
+
+// ArchiveClaim moves a stored claim to the archive and advances its
+// version, refusing it if the stored claim is at another version
+func (f *FakeStore) ArchiveClaim(claim *models.Claim) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	existing, exists := f.claims[claim.ID]
+	if !exists {
+		return fmt.Errorf("claim not found")
+	}
+	if existing.Version != claim.Version {
+		return fmt.Errorf("claim version conflict")
+	}
+	claim.Version++
+	f.archived[claim.ID] = claim
+	delete(f.claims, claim.ID)
+	return nil
+}
+
+// GetArchivedClaim returns an archived claim
+func (f *FakeStore) GetArchivedClaim(claimID string) (*models.Claim, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	claim, exists := f.archived[claimID]
+	if !exists {
+		return nil, fmt.Errorf("claim not found")
+	}
+	return claim, nil
+}
+
+// GetArchivedClaims returns the archived claims matching filters ordered
+// by ID
+func (f *FakeStore) GetArchivedClaims(filters *models.ClaimFilters) []*models.Claim {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	claims := make([]*models.Claim, 0, len(f.archived))
+	for _, claim := range f.archived {
+		if claim.Matches(filters) {
+			claims = append(claims, claim)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].ID < claims[j].ID })
+	return claims
+}
+
+// GetPolicyByID returns a policy added with AddPolicy
+func (f *FakeStore) GetPolicyByID(policyID string) (*repository.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	policy, exists := f.policies[policyID]
+	if !exists {
+		return nil, fmt.Errorf("policy not found")
+	}
+	return policy, nil
+}
+
+// GetPolicyIDsByCustomerID returns the IDs of the customer's policies
+func (f *FakeStore) GetPolicyIDsByCustomerID(customerID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var policyIDs []string
+	for _, policy := range f.policies {
+		if policy.CustomerID == customerID {
+			policyIDs = append(policyIDs, policy.ID)
+		}
+	}
+	sort.Strings(policyIDs)
+	return policyIDs
+}
+
+// GetCatastropheByID returns the stored catastrophe event
+func (f *FakeStore) GetCatastropheByID(catastropheID string) (*models.Catastrophe, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	cat, exists := f.catastrophes[catastropheID]
+	if !exists {
+		return nil, fmt.Errorf("catastrophe not found")
+	}
+	return cat, nil
+}
+
+// GetAllCatastrophes returns every catastrophe event ordered by ID
+func (f *FakeStore) GetAllCatastrophes() []*models.Catastrophe {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	catastrophes := make([]*models.Catastrophe, 0, len(f.catastrophes))
+	for _, cat := range f.catastrophes {
+		catastrophes = append(catastrophes, cat)
+	}
+	sort.Slice(catastrophes, func(i, j int) bool { return catastrophes[i].ID < catastrophes[j].ID })
+	return catastrophes
+}
+
+// CreateCatastrophe stores a new catastrophe event
+func (f *FakeStore) CreateCatastrophe(cat *models.Catastrophe) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	f.catastrophes[cat.ID] = cat
+	return nil
+}
+
+// AddTimelineEntry records a timeline entry for its claim
+func (f *FakeStore) AddTimelineEntry(entry models.TimelineEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	f.timelines[entry.ClaimID] = append(f.timelines[entry.ClaimID], entry)
+	return nil
+}
+
+// GetTimelineEntries returns the entries recorded for a claim
+func (f *FakeStore) GetTimelineEntries(claimID string) []models.TimelineEntry {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]models.TimelineEntry(nil), f.timelines[claimID]...)
+}
+
+// AddDocument stores a claim document and its content
+func (f *FakeStore) AddDocument(doc *models.ClaimDocument, content []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	f.documents[doc.ClaimID] = append(f.documents[doc.ClaimID], doc)
+	f.contents[doc.ID] = content
+	return nil
+}
+
+// GetDocuments returns the documents attached to a claim in upload order
+func (f *FakeStore) GetDocuments(claimID string) []*models.ClaimDocument {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*models.ClaimDocument{}, f.documents[claimID]...)
+}
+
+// GetDocument returns a stored claim document and its content
+func (f *FakeStore) GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, nil, f.Err
+	}
+	for _, doc := range f.documents[claimID] {
+		if doc.ID == documentID {
+			return doc, f.contents[doc.ID], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("document not found")
+}
+
+// UpdateDocument replaces a stored claim document's description
+func (f *FakeStore) UpdateDocument(doc *models.ClaimDocument) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	docs := f.documents[doc.ClaimID]
+	for i := range docs {
+		if docs[i].ID == doc.ID {
+			docs[i] = doc
+			return nil
+		}
+	}
+	return fmt.Errorf("document not found")
+}
+
+// CreateComment stores a copy of a new comment
+func (f *FakeStore) CreateComment(comment *models.Comment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	stored := *comment
+	f.comments[comment.ID] = &stored
+	return nil
+}
+
+// GetComments returns copies of the comments on a claim ordered by creation
+// time
+func (f *FakeStore) GetComments(claimID string) []*models.Comment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	comments := []*models.Comment{}
+	for _, comment := range f.comments {
+		if comment.ClaimID == claimID {
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+	return comments
+}
+
+// GetComment returns a copy of the stored comment
+func (f *FakeStore) GetComment(commentID string) (*models.Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	comment, exists := f.comments[commentID]
+	if !exists {
+		return nil, fmt.Errorf("comment not found")
+	}
+	copied := *comment
+	return &copied, nil
+}
+
+// UpdateComment replaces the stored comment
+func (f *FakeStore) UpdateComment(comment *models.Comment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.comments[comment.ID]; !exists {
+		return fmt.Errorf("comment not found")
+	}
+	stored := *comment
+	f.comments[comment.ID] = &stored
+	return nil
+}
+
+// GetTwoFactor returns a copy of a user's two-factor enrollment
+func (f *FakeStore) GetTwoFactor(userID string) (*models.TwoFactor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	tf, exists := f.twoFactors[userID]
+	if !exists {
+		return nil, fmt.Errorf("two-factor enrollment not found")
+	}
+	copied := *tf
+	copied.RecoveryCodes = append([]string(nil), tf.RecoveryCodes...)
+	return &copied, nil
+}
+
+// SaveTwoFactor stores a copy of a user's two-factor enrollment
+func (f *FakeStore) SaveTwoFactor(tf *models.TwoFactor) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	stored := *tf
+	stored.RecoveryCodes = append([]string(nil), tf.RecoveryCodes...)
+	f.twoFactors[tf.UserID] = &stored
+	return nil
+}
+
+// CreateDraft stores a copy of a new claim draft and its attachment content
+func (f *FakeStore) CreateDraft(draft *models.ClaimDraft, contents [][]byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	for i, attachment := range draft.Attachments {
+		f.contents[attachment.ID] = contents[i]
+	}
+	stored := *draft
+	f.drafts[draft.ID] = &stored
+	return nil
+}
+
+// GetDraft returns a copy of the stored claim draft
+func (f *FakeStore) GetDraft(draftID string) (*models.ClaimDraft, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	draft, exists := f.drafts[draftID]
+	if !exists {
+		return nil, fmt.Errorf("draft not found")
+	}
+	copied := *draft
+	return &copied, nil
+}
+
+// GetDrafts returns copies of every claim draft ordered by receipt
+func (f *FakeStore) GetDrafts() []*models.ClaimDraft {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	drafts := []*models.ClaimDraft{}
+	for _, draft := range f.drafts {
+		copied := *draft
+		drafts = append(drafts, &copied)
+	}
+	sort.Slice(drafts, func(i, j int) bool { return drafts[i].ReceivedAt.Before(drafts[j].ReceivedAt) })
+	return drafts
+}
+
+// GetDraftAttachment returns the content of a draft's attachment
+func (f *FakeStore) GetDraftAttachment(draftID, attachmentID string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if draft, exists := f.drafts[draftID]; exists {
+		for _, attachment := range draft.Attachments {
+			if attachment.ID == attachmentID {
+				return f.contents[attachment.ID], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("attachment not found")
+}
+
+// UpdateDraft replaces the stored claim draft
+func (f *FakeStore) UpdateDraft(draft *models.ClaimDraft) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.drafts[draft.ID]; !exists {
+		return fmt.Errorf("draft not found")
+	}
+	stored := *draft
+	f.drafts[draft.ID] = &stored
+	return nil
+}
+
+// CreateFNOL stores a copy of a new first notice of loss
+func (f *FakeStore) CreateFNOL(report *models.FNOL) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	stored := *report
+	f.fnols[report.ID] = &stored
+	return nil
+}
+
+// GetFNOL returns a copy of the stored first notice of loss
+func (f *FakeStore) GetFNOL(reportID string) (*models.FNOL, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	report, exists := f.fnols[reportID]
+	if !exists {
+		return nil, fmt.Errorf("report not found")
+	}
+	copied := *report
+	copied.CompletedSteps = append([]int{}, report.CompletedSteps...)
+	return &copied, nil
+}
+
+// GetFNOLs returns copies of every first notice of loss ordered by creation
+// time
+func (f *FakeStore) GetFNOLs() []*models.FNOL {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reports := []*models.FNOL{}
+	for _, report := range f.fnols {
+		copied := *report
+		reports = append(reports, &copied)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].CreatedAt.Before(reports[j].CreatedAt) })
+	return reports
+}
+
+// UpdateFNOL replaces the stored first notice of loss
+func (f *FakeStore) UpdateFNOL(report *models.FNOL) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.fnols[report.ID]; !exists {
+		return fmt.Errorf("report not found")
+	}
+	stored := *report
+	f.fnols[report.ID] = &stored
+	return nil
+}
+
+func (f *FakeStore) sorted(filters *models.ClaimFilters) []*models.Claim {
+	claims := make([]*models.Claim, 0, len(f.claims))
+	for _, claim := range f.claims {
+		if filters == nil || claim.Matches(filters) {
+			claims = append(claims, claim)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].ID < claims[j].ID })
+	return claims
+}
+
+// fakeWrite is a write staged in a fake unit of work. check runs for every
+// write before any apply, so a refused write stores nothing.
+type fakeWrite struct {
+	check func() error
+	apply func()
+}
+
+// fakeUnitOfWork stages writes to a FakeStore
+type fakeUnitOfWork struct {
+	store     *FakeStore
+	writes    []fakeWrite
+	committed bool
+}
+
+// Begin starts a unit of work
+func (f *FakeStore) Begin() repository.UnitOfWork {
+	return &fakeUnitOfWork{store: f}
+}
+
+// CreateClaim stages the creation of a claim
+func (u *fakeUnitOfWork) CreateClaim(claim *models.Claim) {
+	f := u.store
+	u.writes = append(u.writes, fakeWrite{
+		check: func() error {
+			if f.numberTaken(claim) {
+				return fmt.Errorf("claim number already exists")
+			}
+			return nil
+		},
+		apply: func() { f.claims[claim.ID] = claim },
+	})
+}
+
+// UpdateFNOL stages the replacement of a stored first notice of loss
+func (u *fakeUnitOfWork) UpdateFNOL(report *models.FNOL) {
+	f := u.store
+	stored := *report
+	u.writes = append(u.writes, fakeWrite{
+		check: func() error {
+			if _, exists := f.fnols[report.ID]; !exists {
+				return fmt.Errorf("report not found")
+			}
+			return nil
+		},
+		apply: func() { f.fnols[report.ID] = &stored },
+	})
+}
+
+// UpdateDraft stages the replacement of a stored claim draft
+func (u *fakeUnitOfWork) UpdateDraft(draft *models.ClaimDraft) {
+	f := u.store
+	stored := *draft
+	u.writes = append(u.writes, fakeWrite{
+		check: func() error {
+			if _, exists := f.drafts[draft.ID]; !exists {
+				return fmt.Errorf("draft not found")
+			}
+			return nil
+		},
+		apply: func() { f.drafts[draft.ID] = &stored },
+	})
+}
+
+// Commit stores every staged write, or none if Err is set or one is refused
+func (u *fakeUnitOfWork) Commit() error {
+	if u.committed {
+		return fmt.Errorf("unit of work already committed")
+	}
+	u.committed = true
+
+	f := u.store
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	for _, write := range u.writes {
+		if err := write.check(); err != nil {
+			return err
+		}
+	}
+	for _, write := range u.writes {
+		write.apply()
+	}
+	return nil
+}
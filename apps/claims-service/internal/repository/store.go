@@ -0,0 +1,127 @@
+package repository
+
+import "github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+
+// ClaimStore is the data access the claim service depends on. Repository is
+// the JSON-backed implementation and RedisRepository shares state between
+// instances; repositorytest provides an in-memory fake for unit tests.
+// Claim numbers are unique: CreateClaim and UpdateClaim refuse a number
+// another claim has with "claim number already exists".
+//
+// LockPolicy starts a write section for a policy's claims and returns the
+// function that ends it. Sections for the same policy never overlap, so a
+// check of the policy's claims followed by a create, such as the duplicate
+// check, cannot be raced by another submission for the policy.
+//
+// ArchiveClaim moves a claim from the live claims to the archive, refusing
+// it with "claim version conflict" if the claim changed since it was read.
+// Archived claims are only returned by GetArchivedClaim and
+// GetArchivedClaims, and keep their claim numbers taken. Their timeline,
+// documents and comments stay where they are.
+//
+// Begin starts a unit of work for a claim and the records created with it,
+// which are stored together or not at all.
+type ClaimStore interface {
+	GetClaimByID(claimID string) (*models.Claim, error)
+	GetAllClaims() []*models.Claim
+	GetClaimsByFilter(filters *models.ClaimFilters) []*models.Claim
+	CreateClaim(claim *models.Claim) error
+	UpdateClaim(claim *models.Claim) error
+	NextClaimSequence(year int) (int64, error)
+	LockPolicy(policyID string) (unlock func(), err error)
+	ArchiveClaim(claim *models.Claim) error
+	GetArchivedClaim(claimID string) (*models.Claim, error)
+	GetArchivedClaims(filters *models.ClaimFilters) []*models.Claim
+	GetPolicyByID(policyID string) (*Policy, error)
+	GetPolicyIDsByCustomerID(customerID string) []string
+	GetCatastropheByID(catastropheID string) (*models.Catastrophe, error)
+	GetAllCatastrophes() []*models.Catastrophe
+	CreateCatastrophe(cat *models.Catastrophe) error
+	AddTimelineEntry(entry models.TimelineEntry) error
+	GetTimelineEntries(claimID string) []models.TimelineEntry
+	AddDocument(doc *models.ClaimDocument, content []byte) error
+	GetDocuments(claimID string) []*models.ClaimDocument
+	GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error)
+	UpdateDocument(doc *models.ClaimDocument) error
+	Begin() UnitOfWork
+}
+
+var _ ClaimStore = (*Repository)(nil)
+
+// UnitOfWork stages writes to several records so a multi-step operation,
+// such as filing a claim from a report and marking the report submitted,
+// stores all of them or none. Staged writes are not seen by anyone until
+// Commit, which checks each as the store's own write method would and
+// refuses them all, with that method's error, if any is refused. Hooks run
+// once the writes are stored. A unit is committed at most once; one
+// dropped without Commit stores nothing.
+type UnitOfWork interface {
+	CreateClaim(claim *models.Claim)
+	UpdateFNOL(report *models.FNOL)
+	UpdateDraft(draft *models.ClaimDraft)
+	Commit() error
+}
+
+// CommentStore is the data access the comment service depends on.
+// Repository is the JSON-backed implementation and RedisRepository shares
+// state between instances; repositorytest provides an in-memory fake for
+// unit tests.
+type CommentStore interface {
+	GetClaimByID(claimID string) (*models.Claim, error)
+	CreateComment(comment *models.Comment) error
+	GetComments(claimID string) []*models.Comment
+	GetComment(commentID string) (*models.Comment, error)
+	UpdateComment(comment *models.Comment) error
+}
+
+var _ CommentStore = (*Repository)(nil)
+
+// DraftStore is the data access the draft service depends on. Attachment
+// content is stored with the draft and read back one attachment at a time.
+type DraftStore interface {
+	CreateDraft(draft *models.ClaimDraft, contents [][]byte) error // contents[i] belongs to draft.Attachments[i]
+	GetDraft(draftID string) (*models.ClaimDraft, error)
+	GetDrafts() []*models.ClaimDraft
+	GetDraftAttachment(draftID, attachmentID string) ([]byte, error)
+	UpdateDraft(draft *models.ClaimDraft) error
+}
+
+var _ DraftStore = (*Repository)(nil)
+
+// FNOLStore is the data access the FNOL service depends on. Policies are
+// read to check the incident step against the policy's owner and term.
+type FNOLStore interface {
+	GetPolicyByID(policyID string) (*Policy, error)
+	CreateFNOL(report *models.FNOL) error
+	GetFNOL(reportID string) (*models.FNOL, error)
+	GetFNOLs() []*models.FNOL
+	UpdateFNOL(report *models.FNOL) error
+}
+
+var _ FNOLStore = (*Repository)(nil)
+
+// TwoFactorStore keeps users' two-factor enrollments, which must outlive
+// restarts. GetTwoFactor fails with "two-factor enrollment not found" for a
+// user who never enrolled. Enrollments hold secrets, so their writes are not
+// announced to hooks.
+type TwoFactorStore interface {
+	GetTwoFactor(userID string) (*models.TwoFactor, error)
+	SaveTwoFactor(tf *models.TwoFactor) error
+}
+
+var _ TwoFactorStore = (*Repository)(nil)
+
+// Store is all the data access the service needs, so the storage backend
+// can be chosen at startup
+type Store interface {
+	ClaimStore
+	CommentStore
+	DraftStore
+	FNOLStore
+	TwoFactorStore
+}
+
+var (
+	_ Store = (*Repository)(nil)
+	_ Store = (*RedisRepository)(nil)
+)
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+)
+
+// stagedWrite makes one write of a unit of work on the locked repository,
+// adding its journal records to batch, and returns how to take it back
+type stagedWrite func(batch *persist.Batch) (undo func(), err error)
+
+// unitOfWork stages writes to the repository until Commit
+type unitOfWork struct {
+	repo      *Repository
+	writes    []stagedWrite
+	committed bool
+}
+
+// Begin starts a unit of work
+func (r *Repository) Begin() UnitOfWork {
+	return &unitOfWork{repo: r}
+}
+
+// CreateClaim stages the creation of a claim at its first version
+func (u *unitOfWork) CreateClaim(claim *models.Claim) {
+	u.writes = append(u.writes, func(batch *persist.Batch) (func(), error) {
+		return u.repo.createClaim(claim, batch)
+	})
+}
+
+// UpdateFNOL stages the replacement of a stored first notice of loss
+func (u *unitOfWork) UpdateFNOL(report *models.FNOL) {
+	u.writes = append(u.writes, func(batch *persist.Batch) (func(), error) {
+		return u.repo.updateFNOL(report, batch)
+	})
+}
+
+// UpdateDraft stages the replacement of a stored claim draft
+func (u *unitOfWork) UpdateDraft(draft *models.ClaimDraft) {
+	u.writes = append(u.writes, func(batch *persist.Batch) (func(), error) {
+		return u.repo.updateDraft(draft, batch)
+	})
+}
+
+// Commit makes the staged writes in order under one hold of the write
+// lock, so no reader sees some of them without the rest. If one is refused,
+// or the journal cannot record them, the writes already made are undone in
+// reverse and their hooks dropped. The journal records them as one batch.
+func (u *unitOfWork) Commit() error {
+	if u.committed {
+		return fmt.Errorf("unit of work already committed")
+	}
+	u.committed = true
+
+	r := u.repo
+	r.mu.Lock()
+	defer r.unlock()
+
+	announced := len(r.pending)
+	var batch persist.Batch
+	undos := make([]func(), 0, len(u.writes))
+	rollback := func() {
+		for i := len(undos) - 1; i >= 0; i-- {
+			undos[i]()
+		}
+		r.pending = r.pending[:announced]
+	}
+
+	for _, write := range u.writes {
+		undo, err := write(&batch)
+		if err != nil {
+			rollback()
+			return err
+		}
+		undos = append(undos, undo)
+	}
+	if err := r.journal.Commit(&batch); err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
+
+// restore puts back the value a map held under key before a write, or
+// removes the key if it had none
+func restore[K comparable, V any](m map[K]V, key K, previous V, existed bool) {
+	if existed {
+		m[key] = previous
+		return
+	}
+	delete(m, key)
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/sirupsen/logrus"
+)
+
+// newJournaledRepository creates an empty repository persisted in dir
+func newJournaledRepository(t *testing.T, dir string) (*Repository, *persist.Journal) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := NewRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	journal, err := persist.Open(dir, "claims", 0, logger)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := repo.Persist(journal); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	return repo, journal
+}
+
+func TestUnitOfWorkStoresAllOrNothing(t *testing.T) {
+	dir := t.TempDir()
+	repo, journal := newJournaledRepository(t, dir)
+	report := &models.FNOL{ID: "fnol-001", Status: models.FNOLInProgress, CreatedAt: time.Now()}
+	if err := repo.CreateFNOL(report); err != nil {
+		t.Fatalf("CreateFNOL failed: %v", err)
+	}
+
+	var changes []string
+	repo.OnCreate(hooks.AnyCollection, func(c hooks.Change) { changes = append(changes, c.Op+" "+c.Collection) })
+	repo.OnUpdate(hooks.AnyCollection, func(c hooks.Change) {
+		// Hooks run once every write is stored, and may read the repository
+		if stored, err := repo.GetFNOL(c.Key); err != nil || stored.ClaimID != "clm-001" {
+			t.Errorf("Hook ran before the report was stored: %+v, %v", stored, err)
+		}
+		changes = append(changes, c.Op+" "+c.Collection)
+	})
+
+	// A refused second write takes the claim back with it
+	work := repo.Begin()
+	work.CreateClaim(&models.Claim{ID: "clm-001", ClaimNumber: "CLM-2024-000001"})
+	work.UpdateFNOL(&models.FNOL{ID: "fnol-404"})
+	if err := work.Commit(); err == nil || err.Error() != "report not found" {
+		t.Fatalf("Expected the missing report to refuse the unit, got %v", err)
+	}
+	if _, err := repo.GetClaimByID("clm-001"); err == nil {
+		t.Errorf("The claim of a refused unit was stored")
+	}
+	if len(changes) != 0 {
+		t.Errorf("Hooks ran for a refused unit: %v", changes)
+	}
+	if err := work.Commit(); err == nil || err.Error() != "unit of work already committed" {
+		t.Errorf("Expected a second commit to be refused, got %v", err)
+	}
+
+	// The claim number was released, so a later claim can take it
+	submitted := *report
+	submitted.Status = models.FNOLSubmitted
+	submitted.ClaimID = "clm-001"
+	work = repo.Begin()
+	work.CreateClaim(&models.Claim{ID: "clm-001", ClaimNumber: "CLM-2024-000001"})
+	work.UpdateFNOL(&submitted)
+	if err := work.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if claim, err := repo.GetClaimByID("clm-001"); err != nil || claim.Version != 1 {
+		t.Errorf("Unexpected stored claim %+v, %v", claim, err)
+	}
+	if len(changes) != 2 || changes[0] != "create claims" || changes[1] != "update fnol" {
+		t.Errorf("Unexpected hooks %v", changes)
+	}
+
+	// Only the committed unit was journaled
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	recovered, _ := newJournaledRepository(t, dir)
+	if claims := recovered.GetAllClaims(); len(claims) != 1 || claims[0].ClaimNumber != "CLM-2024-000001" {
+		t.Errorf("Unexpected recovered claims %+v", claims)
+	}
+	if stored, err := recovered.GetFNOL("fnol-001"); err != nil || stored.Status != models.FNOLSubmitted {
+		t.Errorf("Unexpected recovered report %+v, %v", stored, err)
+	}
+}
@@ -0,0 +1,80 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamAVChunk is the most content sent to clamd in one INSTREAM chunk
+const clamAVChunk = 64 * 1024
+
+// ClamAV scans with a clamd daemon over TCP, streaming the content with
+// the INSTREAM command. Documents must fit in clamd's StreamMaxLength,
+// 25 MiB by default and above MaxDocumentSize.
+type ClamAV struct {
+	Address string // clamd's host:port
+	Timeout time.Duration
+}
+
+// Name returns NameClamAV
+func (c *ClamAV) Name() string {
+	return NameClamAV
+}
+
+// Scan streams content to clamd and reads back its verdict
+func (c *ClamAV) Scan(ctx context.Context, fileName string, content []byte) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline(ctx, c.Timeout)); err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+
+	// zINSTREAM: the command, then chunks each prefixed with its length
+	// as a 4-byte big-endian integer, ended by a zero-length chunk
+	w := bufio.NewWriter(conn)
+	w.WriteString("zINSTREAM\x00")
+	length := make([]byte, 4)
+	for start := 0; start < len(content); start += clamAVChunk {
+		end := start + clamAVChunk
+		if end > len(content) {
+			end = len(content)
+		}
+		binary.BigEndian.PutUint32(length, uint32(end-start))
+		w.Write(length)
+		w.Write(content[start:end])
+	}
+	binary.BigEndian.PutUint32(length, 0)
+	w.Write(length)
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to send document to clamd: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return nil, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+	return parseClamAVReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamAVReply reads clamd's verdict on a stream: "stream: OK",
+// "stream: <threat> FOUND", or a message ending in ERROR
+func parseClamAVReply(reply string) (*Result, error) {
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return &Result{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return &Result{Threat: strings.TrimSuffix(verdict, " FOUND")}, nil
+	default:
+		return nil, fmt.Errorf("clamd failed to scan the document: %s", reply)
+	}
+}
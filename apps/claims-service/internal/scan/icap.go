@@ -0,0 +1,119 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICAP scans with an ICAP antivirus service (RFC 3507), such as c-icap
+// with its virus_scan module, by sending the content as the body of a
+// RESPMOD request. The service answering 204 No Content means the
+// document is clean; any other 2xx means it was blocked, and the threat is
+// taken from the X-Infection-Found or X-Virus-ID header.
+type ICAP struct {
+	Service *url.URL // icap://host[:port]/service
+	Timeout time.Duration
+}
+
+// Name returns NameICAP
+func (c *ICAP) Name() string {
+	return NameICAP
+}
+
+// Scan sends content to the ICAP service and reads back its verdict
+func (c *ICAP) Scan(ctx context.Context, fileName string, content []byte) (*Result, error) {
+	address := c.Service.Host
+	if c.Service.Port() == "" {
+		address = net.JoinHostPort(c.Service.Hostname(), "1344")
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ICAP service: %w", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline(ctx, c.Timeout)); err != nil {
+		return nil, fmt.Errorf("failed to connect to ICAP service: %w", err)
+	}
+
+	// The encapsulated HTTP exchange the service is asked to check: a
+	// download of the document and the response carrying it
+	reqHdr := fmt.Sprintf("GET /documents/%s HTTP/1.1\r\nHost: claims-service\r\n\r\n", url.PathEscape(fileName))
+	resHdr := fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\nContent-Length: %d\r\n\r\n", len(content))
+
+	w := bufio.NewWriter(conn)
+	fmt.Fprintf(w, "RESPMOD %s ICAP/1.0\r\n", c.Service.String())
+	fmt.Fprintf(w, "Host: %s\r\n", c.Service.Host)
+	w.WriteString("Allow: 204\r\n")
+	fmt.Fprintf(w, "Encapsulated: req-hdr=0, res-hdr=%d, res-body=%d\r\n\r\n", len(reqHdr), len(reqHdr)+len(resHdr))
+	w.WriteString(reqHdr)
+	w.WriteString(resHdr)
+	if len(content) > 0 {
+		fmt.Fprintf(w, "%x\r\n", len(content))
+		w.Write(content)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("0\r\n\r\n")
+	if err := w.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to send document to ICAP service: %w", err)
+	}
+
+	reply := textproto.NewReader(bufio.NewReader(conn))
+	status, err := reply.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ICAP reply: %w", err)
+	}
+	code, err := icapStatus(status)
+	if err != nil {
+		return nil, err
+	}
+	header, err := reply.ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ICAP reply: %w", err)
+	}
+
+	switch {
+	case code == 204:
+		return &Result{Clean: true}, nil
+	case code >= 200 && code < 300:
+		return &Result{Threat: icapThreat(header)}, nil
+	default:
+		return nil, fmt.Errorf("ICAP service failed to scan the document: %s", status)
+	}
+}
+
+// icapStatus is the status code of an ICAP status line, such as
+// "ICAP/1.0 204 No Content"
+func icapStatus(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "ICAP/") {
+		return 0, fmt.Errorf("malformed ICAP reply %q", line)
+	}
+	code, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, fmt.Errorf("malformed ICAP reply %q", line)
+	}
+	return code, nil
+}
+
+// icapThreat names the malware an ICAP service blocked a document for,
+// from X-Infection-Found ("Type=0; Resolution=2; Threat=Eicar-Signature;")
+// or X-Virus-ID
+func icapThreat(header textproto.MIMEHeader) string {
+	for _, param := range strings.Split(header.Get("X-Infection-Found"), ";") {
+		if name, value, ok := strings.Cut(strings.TrimSpace(param), "="); ok && name == "Threat" && value != "" {
+			return value
+		}
+	}
+	if id := strings.TrimSpace(header.Get("X-Virus-ID")); id != "" {
+		return id
+	}
+	return "unknown"
+}
@@ -0,0 +1,102 @@
+// Package scan checks the documents attached to claims for malware before
+// they can be downloaded. The check is done by a Scanner, the integration
+// point for an antivirus engine, chosen in configuration: ClamAV talks to
+// a clamd daemon and ICAP to an ICAP antivirus service. Noop stands in for
+// one in development and tests.
+package scan
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultTimeout bounds one scan when the configuration sets none
+const DefaultTimeout = time.Minute
+
+// Result is a scanner's verdict on a document
+type Result struct {
+	Clean  bool
+	Threat string // the malware found, when not clean
+}
+
+// Scanner checks a document's content for malware. Scan returns an error
+// when the document could not be scanned, not when malware is found; it
+// should return when ctx is done.
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, fileName string, content []byte) (*Result, error)
+}
+
+// Names of the scanners that can be selected in configuration
+const (
+	NameNoop   = "noop"
+	NameClamAV = "clamav"
+	NameICAP   = "icap"
+)
+
+// Config selects and addresses a scanner
+type Config struct {
+	Name string
+	// Address is clamd's host:port for clamav, or the ICAP service URL,
+	// such as icap://av.internal:1344/avscan, for icap
+	Address string
+	Timeout time.Duration // 0 uses DefaultTimeout
+}
+
+// New returns the scanner cfg names. An empty name returns Noop: documents
+// are marked clean without being scanned.
+func New(cfg Config) (Scanner, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	switch cfg.Name {
+	case "", NameNoop:
+		return Noop{}, nil
+	case NameClamAV:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("clamav scanner needs the clamd address")
+		}
+		return &ClamAV{Address: cfg.Address, Timeout: timeout}, nil
+	case NameICAP:
+		if cfg.Address == "" {
+			return nil, fmt.Errorf("icap scanner needs the ICAP service URL")
+		}
+		service, err := url.Parse(cfg.Address)
+		if err != nil || service.Scheme != "icap" || service.Host == "" {
+			return nil, fmt.Errorf("invalid ICAP service URL %q (want icap://host[:port]/service)", cfg.Address)
+		}
+		return &ICAP{Service: service, Timeout: timeout}, nil
+	default:
+		return nil, fmt.Errorf("unknown document scanner %q (want %s, %s or %s)", cfg.Name, NameNoop, NameClamAV, NameICAP)
+	}
+}
+
+// Noop marks every document clean without looking at it
+type Noop struct{}
+
+// Name returns NameNoop
+func (Noop) Name() string {
+	return NameNoop
+}
+
+// Scan reports content clean
+func (Noop) Scan(ctx context.Context, fileName string, content []byte) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Result{Clean: true}, nil
+}
+
+// deadline is when a scan started now with timeout must end, or ctx's
+// deadline if that is sooner
+func deadline(ctx context.Context, timeout time.Duration) time.Time {
+	end := time.Now().Add(timeout)
+	if ctxEnd, ok := ctx.Deadline(); ok && ctxEnd.Before(end) {
+		return ctxEnd
+	}
+	return end
+}
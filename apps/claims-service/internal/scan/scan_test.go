@@ -0,0 +1,167 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+var eicar = []byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`)
+
+// serve answers each connection to a local listener with handle
+func serve(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestClamAVScansOverINSTREAM(t *testing.T) {
+	address := serve(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		if command, _ := r.ReadString(0); command != "zINSTREAM\x00" {
+			conn.Write([]byte("UNKNOWN COMMAND\x00"))
+			return
+		}
+		var content []byte
+		length := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(r, length); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(length)
+			if n == 0 {
+				break
+			}
+			chunk := make([]byte, n)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return
+			}
+			content = append(content, chunk...)
+		}
+		switch {
+		case bytes.Contains(content, eicar):
+			conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+		case len(content) > 100*1024:
+			conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+		default:
+			conn.Write([]byte("stream: OK\x00"))
+		}
+	})
+	scanner := &ClamAV{Address: address, Timeout: 5 * time.Second}
+
+	if result, err := scanner.Scan(context.Background(), "bumper.jpg", []byte("\xff\xd8\xff\xe0 bumper")); err != nil || !result.Clean {
+		t.Errorf("Expected a clean photo, got %+v, %v", result, err)
+	}
+	if result, err := scanner.Scan(context.Background(), "invoice.pdf", eicar); err != nil || result.Clean || result.Threat != "Eicar-Signature" {
+		t.Errorf("Expected the test virus to be found, got %+v, %v", result, err)
+	}
+	// Content larger than a chunk is streamed in several
+	if _, err := scanner.Scan(context.Background(), "scan.pdf", make([]byte, 3*clamAVChunk)); err == nil || !strings.Contains(err.Error(), "size limit exceeded") {
+		t.Errorf("Expected clamd's error to be returned, got %v", err)
+	}
+
+	unreachable := &ClamAV{Address: "127.0.0.1:1", Timeout: time.Second}
+	if _, err := unreachable.Scan(context.Background(), "bumper.jpg", []byte("x")); err == nil || !strings.HasPrefix(err.Error(), "failed to connect to clamd") {
+		t.Errorf("Expected a connection error, got %v", err)
+	}
+}
+
+func TestICAPScansOverRESPMOD(t *testing.T) {
+	address := serve(t, func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		request := textproto.NewReader(r)
+		line, err := request.ReadLine()
+		if err != nil {
+			return
+		}
+		header, err := request.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		if !strings.HasPrefix(line, "RESPMOD icap://") || header.Get("Encapsulated") == "" {
+			conn.Write([]byte("ICAP/1.0 400 Bad Request\r\n\r\n"))
+			return
+		}
+		// Skip the encapsulated request and response headers to the body
+		for blank := 0; blank < 2; {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if line == "\r\n" {
+				blank++
+			}
+		}
+		body, err := io.ReadAll(httputil.NewChunkedReader(r))
+		if err != nil {
+			return
+		}
+		switch {
+		case bytes.Contains(body, eicar):
+			conn.Write([]byte("ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\nEncapsulated: null-body=0\r\n\r\n"))
+		case bytes.Contains(body, []byte("broken")):
+			conn.Write([]byte("ICAP/1.0 500 Server Error\r\n\r\n"))
+		default:
+			conn.Write([]byte("ICAP/1.0 204 No Content\r\n\r\n"))
+		}
+	})
+	scanner, err := New(Config{Name: NameICAP, Address: "icap://" + address + "/avscan"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if result, err := scanner.Scan(context.Background(), "police report.pdf", []byte("%PDF-1.7 report")); err != nil || !result.Clean {
+		t.Errorf("Expected a clean report, got %+v, %v", result, err)
+	}
+	if result, err := scanner.Scan(context.Background(), "invoice.pdf", eicar); err != nil || result.Clean || result.Threat != "Eicar-Test-Signature" {
+		t.Errorf("Expected the test virus to be found, got %+v, %v", result, err)
+	}
+	if _, err := scanner.Scan(context.Background(), "broken.pdf", []byte("broken")); err == nil || err.Error() != "ICAP service failed to scan the document: ICAP/1.0 500 Server Error" {
+		t.Errorf("Expected the service's error to be returned, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if scanner, err := New(Config{}); err != nil || scanner.Name() != NameNoop {
+		t.Errorf("No name should mean the no-op scanner, got %v, %v", scanner, err)
+	}
+	if scanner, err := New(Config{Name: NameClamAV, Address: "clamd:3310"}); err != nil || scanner.(*ClamAV).Timeout != DefaultTimeout {
+		t.Errorf("Expected clamav with the default timeout, got %v, %v", scanner, err)
+	}
+	for _, cfg := range []Config{
+		{Name: NameClamAV},
+		{Name: NameICAP, Address: "http://av:1344/avscan"},
+		{Name: "virustotal"},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("Expected %+v to be refused", cfg)
+		}
+	}
+	if result, err := (Noop{}).Scan(context.Background(), "bumper.jpg", eicar); err != nil || !result.Clean {
+		t.Errorf("Expected the no-op scanner to pass everything, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/acord"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// AcordExport is an ACORD document of the claims whose notifications are
+// valid, and the claims left out because theirs are not
+type AcordExport struct {
+	Document *acord.Document
+	Rejected []models.AcordRejection
+}
+
+// AcordService maps claims to ACORD notifications for reinsurers and
+// regulators
+type AcordService struct {
+	repo    repository.ClaimStore
+	mapping *acord.Mapping
+	logger  *logrus.Logger
+}
+
+// NewAcordService creates a new ACORD export service. mapping nil uses
+// acord.Default.
+func NewAcordService(repo repository.ClaimStore, mapping *acord.Mapping, logger *logrus.Logger) *AcordService {
+	if mapping == nil {
+		mapping = acord.Default()
+	}
+	return &AcordService{
+		repo:    repo,
+		mapping: mapping,
+		logger:  logger,
+	}
+}
+
+// ExportClaim exports one claim, live or archived
+func (s *AcordService) ExportClaim(claimID string, now time.Time) (*AcordExport, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil && err.Error() == "claim not found" {
+		claim, err = s.repo.GetArchivedClaim(claimID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.export([]*models.Claim{claim}, now), nil
+}
+
+// ExportClaims exports the claims matching filters, oldest submission
+// first. Archived claims are included when filters ask for them.
+func (s *AcordService) ExportClaims(filters *models.ClaimFilters, now time.Time) *AcordExport {
+	claims := s.repo.GetClaimsByFilter(filters)
+	if filters.IncludeArchived {
+		claims = append(claims, s.repo.GetArchivedClaims(filters)...)
+	}
+	sort.Slice(claims, func(i, j int) bool {
+		if !claims[i].SubmittedDate.Equal(claims[j].SubmittedDate) {
+			return claims[i].SubmittedDate.Before(claims[j].SubmittedDate)
+		}
+		return claims[i].ID < claims[j].ID
+	})
+
+	export := s.export(claims, now)
+	s.logger.WithFields(logrus.Fields{
+		"exported": len(export.Document.Service.Notifications),
+		"rejected": len(export.Rejected),
+	}).Debug("Built ACORD export")
+	return export
+}
+
+// export maps claims to notifications and validates each
+func (s *AcordService) export(claims []*models.Claim, now time.Time) *AcordExport {
+	var valid []acord.Notification
+	rejected := []models.AcordRejection{}
+	for _, claim := range claims {
+		var policyNumber, policyType string
+		if policy, err := s.repo.GetPolicyByID(claim.PolicyID); err == nil {
+			policyNumber, policyType = policy.PolicyNumber, policy.Type
+		}
+
+		notification := acord.NewNotification(s.mapping, claim, policyNumber, policyType, now)
+		if problems := acord.Validate(notification); len(problems) > 0 {
+			rejected = append(rejected, models.AcordRejection{
+				ClaimID:     claim.ID,
+				ClaimNumber: claim.ClaimNumber,
+				Problems:    problems,
+			})
+			continue
+		}
+		valid = append(valid, notification)
+	}
+	return &AcordExport{
+		Document: acord.NewDocument(s.mapping, valid, now),
+		Rejected: rejected,
+	}
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAcordExports(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	submitted := func(day int) time.Time {
+		return time.Date(2026, 9, day, 9, 0, 0, 0, time.UTC)
+	}
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-late", ClaimNumber: "CLM-2026-00003", PolicyID: "pol-auto", CustomerID: "cust-001", Type: "theft", Status: "approved", Amount: 1200, SubmittedDate: submitted(20)},
+		&models.Claim{ID: "claim-early", ClaimNumber: "CLM-2026-00001", PolicyID: "pol-home", CustomerID: "cust-002", Type: "damage", Status: "under_review", Amount: 5400, SubmittedDate: submitted(2)},
+		&models.Claim{ID: "claim-orphan", ClaimNumber: "CLM-2026-00002", PolicyID: "pol-404", CustomerID: "cust-003", Type: "accident", Status: "submitted", Amount: 300, SubmittedDate: submitted(10)},
+		&models.Claim{ID: "claim-old", ClaimNumber: "CLM-2025-00009", PolicyID: "pol-auto", CustomerID: "cust-001", Type: "accident", Status: "rejected", Amount: 700, SubmittedDate: submitted(1).AddDate(-1, 0, 0), Version: 1},
+	)
+	store.AddPolicy(&repository.Policy{ID: "pol-auto", PolicyNumber: "AUTO-2026-000001", Type: "auto"})
+	store.AddPolicy(&repository.Policy{ID: "pol-home", PolicyNumber: "HOME-2026-000001", Type: "home"})
+	old, _ := store.GetClaimByID("claim-old")
+	if err := store.ArchiveClaim(old); err != nil {
+		t.Fatalf("ArchiveClaim failed: %v", err)
+	}
+
+	service := NewAcordService(store, nil, logger)
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	export := service.ExportClaims(&models.ClaimFilters{}, now)
+	notifications := export.Document.Service.Notifications
+	if len(notifications) != 2 || notifications[0].Occurrence.ItemIdInfo.SystemId != "claim-early" || notifications[1].Occurrence.ItemIdInfo.SystemId != "claim-late" {
+		t.Fatalf("Expected the valid live claims oldest first, got %+v", notifications)
+	}
+	if notifications[0].Policy.PolicyNumber != "HOME-2026-000001" || notifications[0].Policy.LOBCd != "HOME" || notifications[1].Occurrence.LossCauseCd != "theft" {
+		t.Errorf("Unexpected notifications %+v", notifications)
+	}
+	if len(export.Rejected) != 1 || export.Rejected[0].ClaimID != "claim-orphan" || export.Rejected[0].Problems[0] != "Policy/PolicyNumber is required" {
+		t.Errorf("Expected the claim on an unknown policy to be rejected, got %+v", export.Rejected)
+	}
+
+	archived := service.ExportClaims(&models.ClaimFilters{PolicyID: "pol-auto", IncludeArchived: true}, now)
+	if got := archived.Document.Service.Notifications; len(got) != 2 || got[0].Occurrence.ItemIdInfo.SystemId != "claim-old" || len(archived.Rejected) != 0 {
+		t.Errorf("Expected the archived claim first, got %+v, rejected %+v", got, archived.Rejected)
+	}
+
+	single, err := service.ExportClaim("claim-old", now)
+	if err != nil || len(single.Document.Service.Notifications) != 1 || single.Document.Service.Notifications[0].Occurrence.ClaimStatusCd != "closed" {
+		t.Errorf("Expected an archived claim to export on its own, got %+v, %v", single, err)
+	}
+	if orphan, err := service.ExportClaim("claim-orphan", now); err != nil || len(orphan.Rejected) != 1 || len(orphan.Document.Service.Notifications) != 0 {
+		t.Errorf("Expected the orphan to be rejected, got %+v, %v", orphan, err)
+	}
+	if _, err := service.ExportClaim("claim-404", now); err == nil || err.Error() != "claim not found" {
+		t.Errorf("Expected an unknown claim, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// AgingService reports the backlog of open claims by how long each has
+// waited in its current status. Approved and rejected claims are decided
+// and left out.
+type AgingService struct {
+	repo      repository.ClaimStore
+	lifecycle *lifecycle.Machine
+	logger    *logrus.Logger
+}
+
+// NewAgingService creates a new claims aging service
+func NewAgingService(repo repository.ClaimStore, logger *logrus.Logger) *AgingService {
+	return &AgingService{
+		repo:      repo,
+		lifecycle: lifecycle.Claims(),
+		logger:    logger,
+	}
+}
+
+// Report buckets the open claims by whole days in their current status at
+// now
+func (s *AgingService) Report(now time.Time) *models.ClaimsAgingReport {
+	report := &models.ClaimsAgingReport{
+		GeneratedAt: now,
+		Claims:      models.NewAgingBreakdown(),
+		ByAdjuster:  make(map[string]*models.AgingBreakdown),
+	}
+
+	for _, claim := range s.repo.GetAllClaims() {
+		if s.lifecycle.IsTerminal(claim.Status) {
+			continue
+		}
+		days := 0
+		if waited := now.Sub(claim.EnteredStatusAt()); waited > 0 {
+			days = int(waited / (24 * time.Hour))
+		}
+
+		adjuster := claim.AssignedTo
+		if adjuster == "" {
+			adjuster = models.UnassignedAdjuster
+		}
+		if report.ByAdjuster[adjuster] == nil {
+			report.ByAdjuster[adjuster] = models.NewAgingBreakdown()
+		}
+
+		report.Claims.Add(claim, days)
+		report.ByAdjuster[adjuster].Add(claim, days)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"open":      report.Claims.Count,
+		"adjusters": len(report.ByAdjuster),
+	}).Debug("Built claims aging report")
+
+	return report
+}
+
+// WritePrometheus writes the open claims and their amounts per aging bucket
+// for GET /metrics in the Prometheus text exposition format, so backlog
+// growth can be alerted on
+func (s *AgingService) WritePrometheus(w io.Writer) {
+	report := s.Report(time.Now())
+
+	fmt.Fprint(w, "# HELP claims_aging_open_claims Open claims by days in their current status.\n")
+	fmt.Fprint(w, "# TYPE claims_aging_open_claims gauge\n")
+	for _, bucket := range models.AgingBuckets {
+		fmt.Fprintf(w, "claims_aging_open_claims{bucket=%q} %d\n", bucket.Name, report.Claims.Buckets[bucket.Name].Count)
+	}
+	fmt.Fprint(w, "# HELP claims_aging_open_amount Amount claimed on open claims by days in their current status.\n")
+	fmt.Fprint(w, "# TYPE claims_aging_open_amount gauge\n")
+	for _, bucket := range models.AgingBuckets {
+		fmt.Fprintf(w, "claims_aging_open_amount{bucket=%q} %.2f\n", bucket.Name, report.Claims.Buckets[bucket.Name].TotalAmount)
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestAgingReportBucketsOpenClaims(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	now := time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-new", Status: "submitted", Amount: 100, SubmittedDate: *daysAgo(0)},
+		&models.Claim{ID: "claim-week", Status: "under_review", Amount: 200, AssignedTo: "adj-001", SubmittedDate: *daysAgo(40), StatusSince: daysAgo(7)},
+		&models.Claim{ID: "claim-month", Status: "under_review", Amount: 300, AssignedTo: "adj-001", SubmittedDate: *daysAgo(8)},
+		&models.Claim{ID: "claim-held", Status: "pending_payment", Amount: 400, AssignedTo: "adj-002", SubmittedDate: *daysAgo(90)},
+		&models.Claim{ID: "claim-stale", Status: "under_review", Amount: 500, SubmittedDate: *daysAgo(91)},
+		&models.Claim{ID: "claim-approved", Status: "approved", Amount: 900, SubmittedDate: *daysAgo(120), ReviewedDate: daysAgo(100)},
+	)
+
+	report := NewAgingService(store, logger).Report(now)
+
+	if report.Claims.Count != 5 || report.Claims.TotalAmount != 1500 {
+		t.Errorf("Expected the 5 open claims totalling 1500, got %+v", report.Claims.AgingTotals)
+	}
+	for bucket, want := range map[string]models.AgingTotals{
+		"0-7":   {Count: 2, TotalAmount: 300},
+		"8-30":  {Count: 1, TotalAmount: 300},
+		"31-90": {Count: 1, TotalAmount: 400},
+		"90+":   {Count: 1, TotalAmount: 500},
+	} {
+		if got := *report.Claims.Buckets[bucket]; got != want {
+			t.Errorf("Bucket %s: got %+v, want %+v", bucket, got, want)
+		}
+	}
+
+	adjuster := report.ByAdjuster["adj-001"]
+	if adjuster == nil || adjuster.Count != 2 || adjuster.Buckets["0-7"].Count != 1 || adjuster.Buckets["8-30"].Count != 1 {
+		t.Errorf("Unexpected adj-001 breakdown %+v", adjuster)
+	}
+	if unassigned := report.ByAdjuster[models.UnassignedAdjuster]; unassigned == nil || unassigned.Count != 2 || unassigned.Buckets["90+"].TotalAmount != 500 {
+		t.Errorf("Unexpected unassigned breakdown %+v", unassigned)
+	}
+}
+
+func TestAgingWritePrometheus(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	old := time.Now().AddDate(0, 0, -45)
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-001", Status: "under_review", Amount: 1250.5, SubmittedDate: old},
+	)
+
+	var metrics bytes.Buffer
+	NewAgingService(store, logger).WritePrometheus(&metrics)
+	for _, want := range []string{
+		`claims_aging_open_claims{bucket="0-7"} 0`,
+		`claims_aging_open_claims{bucket="31-90"} 1`,
+		`claims_aging_open_amount{bucket="31-90"} 1250.50`,
+		`claims_aging_open_amount{bucket="90+"} 0.00`,
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("Metrics missing %q:\n%s", want, metrics.String())
+		}
+	}
+}
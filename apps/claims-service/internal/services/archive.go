@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/retention"
+	"github.com/sirupsen/logrus"
+)
+
+// ArchiveService moves the claims the retention policy no longer keeps
+// live to the archive. Only decided claims are archived, whatever statuses
+// the policy lists, and the years count from when the claim was decided.
+type ArchiveService struct {
+	repo      repository.ClaimStore
+	policy    *retention.Policy
+	lifecycle *lifecycle.Machine
+	logger    *logrus.Logger
+}
+
+// NewArchiveService creates a new claim archival service
+func NewArchiveService(repo repository.ClaimStore, policy *retention.Policy, logger *logrus.Logger) *ArchiveService {
+	return &ArchiveService{
+		repo:      repo,
+		policy:    policy,
+		lifecycle: lifecycle.Claims(),
+		logger:    logger,
+	}
+}
+
+// Archive moves every claim due for archiving at now. A claim that changes
+// while it is being archived is left live and reported as failed; the next
+// pass picks it up if it is still due.
+func (s *ArchiveService) Archive(now time.Time) *models.ArchiveResult {
+	result := &models.ArchiveResult{
+		PolicyVersion: s.policy.Version,
+		Archived:      []string{},
+		Failed:        []string{},
+		ArchivedAt:    now,
+	}
+
+	rule, ok := s.policy.Rule(retention.Claims)
+	if !ok {
+		return result
+	}
+
+	claims := s.repo.GetAllClaims()
+	sort.Slice(claims, func(i, j int) bool { return claims[i].ID < claims[j].ID })
+	for _, claim := range claims {
+		result.Checked++
+		if !s.lifecycle.IsTerminal(claim.Status) || !rule.Due(claim.Status, claim.EnteredStatusAt(), now) {
+			continue
+		}
+
+		archivedAt := now
+		claim.ArchivedAt = &archivedAt
+		if err := s.repo.ArchiveClaim(claim); err != nil {
+			s.logger.WithError(err).WithField("claimId", claim.ID).Warn("Failed to archive claim")
+			result.Failed = append(result.Failed, claim.ID)
+			continue
+		}
+		result.Archived = append(result.Archived, claim.ID)
+	}
+
+	if len(result.Archived) > 0 || len(result.Failed) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"checked":  result.Checked,
+			"archived": len(result.Archived),
+			"failed":   len(result.Failed),
+			"policy":   result.PolicyVersion,
+		}).Info("Claims archived")
+	}
+
+	return result
+}
+
+// Run archives due claims every interval until ctx is cancelled
+func (s *ArchiveService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Archive(time.Now())
+		}
+	}
+}
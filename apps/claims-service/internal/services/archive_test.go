@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/retention"
+	"github.com/sirupsen/logrus"
+)
+
+func TestArchiveClaimsPastRetention(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	yearsAgo := func(years int) *time.Time {
+		d := now.AddDate(-years, 0, -1)
+		return &d
+	}
+	service, store, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-approved", CustomerID: "cust-001", Status: "approved", SubmittedDate: *yearsAgo(9), StatusSince: yearsAgo(8), Version: 1},
+		&models.Claim{ID: "claim-rejected", CustomerID: "cust-001", Status: "rejected", SubmittedDate: *yearsAgo(8), ReviewedDate: yearsAgo(7), Version: 1},
+		&models.Claim{ID: "claim-recent", CustomerID: "cust-001", Status: "approved", SubmittedDate: *yearsAgo(9), StatusSince: yearsAgo(2), Version: 1},
+		&models.Claim{ID: "claim-open", CustomerID: "cust-001", Status: "under_review", SubmittedDate: *yearsAgo(10), Version: 1},
+		&models.Claim{ID: "claim-dup", CustomerID: "cust-001", Status: "submitted", DuplicateOf: "claim-approved", SubmittedDate: now, Version: 1},
+	)
+	policy := &retention.Policy{Version: "test", Rules: map[string]retention.Rule{
+		retention.Claims: {ArchiveAfterYears: 7, Statuses: []string{"approved", "rejected", "under_review"}},
+	}}
+
+	result := NewArchiveService(store, policy, logger).Archive(now)
+	if want := []string{"claim-approved", "claim-rejected"}; !reflect.DeepEqual(result.Archived, want) || result.Checked != 5 {
+		t.Errorf("Archived %v of %d, want %v of 5", result.Archived, result.Checked, want)
+	}
+	if _, err := store.GetClaimByID("claim-open"); err != nil {
+		t.Error("Expected an open claim to stay live whatever the policy lists")
+	}
+	archived, err := store.GetArchivedClaim("claim-approved")
+	if err != nil || archived.ArchivedAt == nil || !archived.ArchivedAt.Equal(now) || archived.Version != 2 {
+		t.Errorf("Expected the claim in the archive at version 2 stamped %s, got %+v (%v)", now, archived, err)
+	}
+
+	// Archived claims are only listed for staff who ask for them
+	staff := models.ClaimViewer{ID: "adj-001", Role: "adjuster"}
+	customer := models.ClaimViewer{ID: "cust-001"}
+	claims, err := service.GetClaims(&models.ClaimFilters{CustomerID: "cust-001"}, staff)
+	if err != nil || len(claims) != 3 {
+		t.Errorf("Expected the 3 live claims, got %d (%v)", len(claims), err)
+	}
+	claims, err = service.GetClaims(&models.ClaimFilters{CustomerID: "cust-001", IncludeArchived: true}, staff)
+	if err != nil || len(claims) != 5 {
+		t.Errorf("Expected the live and archived claims, got %d (%v)", len(claims), err)
+	}
+	if _, err := service.GetClaims(&models.ClaimFilters{IncludeArchived: true}, customer); err == nil || err.Error() != "archived claims are only available to staff" {
+		t.Errorf("Expected customers to be refused the archive, got %v", err)
+	}
+
+	if _, err := service.GetClaimByID("claim-approved", staff); err == nil || err.Error() != "claim not found" {
+		t.Errorf("Expected an archived claim to be gone from the live claims, got %v", err)
+	}
+	if claim, err := service.GetClaimIncludingArchived("claim-approved", staff); err != nil || claim.ID != "claim-approved" {
+		t.Errorf("Expected staff to retrieve the archived claim, got %v", err)
+	}
+	if claim, err := service.GetClaimIncludingArchived("claim-open", staff); err != nil || claim.ID != "claim-open" {
+		t.Errorf("Expected live claims to be found with includeArchived too, got %v", err)
+	}
+	if _, err := service.GetClaimIncludingArchived("claim-approved", customer); err == nil {
+		t.Error("Expected customers to be refused the archived claim")
+	}
+
+	// A claim marked as a duplicate of an archived claim still refers to it
+	report := NewConsistencyChecker(store, nil, logger).Check(context.Background())
+	for _, issue := range report.Issues {
+		if issue.Check == "claim.duplicate_of_exists" {
+			t.Errorf("Expected the archived original to count as existing, got %+v", issue)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateCatastrophe declares a catastrophe event and tags the claims already
+// filed for losses inside its region and date range. It returns the event
+// and the number of claims tagged.
+func (s *ClaimService) CreateCatastrophe(req *models.CreateCatastropheRequest) (*models.Catastrophe, int, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, 0, fmt.Errorf("catastrophe name is required")
+	}
+	if !models.ValidatePeril(req.Peril) {
+		return nil, 0, fmt.Errorf("invalid peril: %s (must be one of: %s)", req.Peril, strings.Join(models.Perils, ", "))
+	}
+	if req.StartDate.IsZero() || req.EndDate.IsZero() {
+		return nil, 0, fmt.Errorf("startDate and endDate are required")
+	}
+	if req.EndDate.Before(req.StartDate) {
+		return nil, 0, fmt.Errorf("endDate cannot be before startDate")
+	}
+
+	postalCodes := make([]string, 0, len(req.PostalCodes))
+	for _, code := range req.PostalCodes {
+		if code = strings.TrimSpace(code); code != "" {
+			postalCodes = append(postalCodes, code)
+		}
+	}
+	if len(postalCodes) == 0 {
+		return nil, 0, fmt.Errorf("at least one postal code is required")
+	}
+
+	now := time.Now()
+	cat := &models.Catastrophe{
+		ID:          fmt.Sprintf("cat-%d", now.UnixNano()),
+		Code:        s.generateCatastropheCode(req.StartDate.Year()),
+		Name:        strings.TrimSpace(req.Name),
+		Peril:       req.Peril,
+		Region:      req.Region,
+		PostalCodes: postalCodes,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.CreateCatastrophe(cat); err != nil {
+		return nil, 0, fmt.Errorf("failed to create catastrophe: %w", err)
+	}
+
+	// Events are usually declared after the first claims arrive
+	tagged := 0
+	for _, claim := range s.repo.GetAllClaims() {
+		if claim.CatastropheTag != "" || !coveredBy(cat, claim) {
+			continue
+		}
+		claim.CatastropheID = cat.ID
+		claim.CatastropheTag = models.CatastropheTagAuto
+		claim.UpdatedAt = now
+		if err := s.repo.UpdateClaim(claim); err != nil {
+			s.logger.WithError(err).WithField("claimId", claim.ID).Error("Failed to tag claim to catastrophe")
+			continue
+		}
+		tagged++
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"catastropheId": cat.ID,
+		"code":          cat.Code,
+		"peril":         cat.Peril,
+		"postalCodes":   cat.PostalCodes,
+		"taggedClaims":  tagged,
+	}).Info("Catastrophe declared")
+
+	return cat, tagged, nil
+}
+
+// GetCatastrophes returns every declared event, most recent first
+func (s *ClaimService) GetCatastrophes() []*models.Catastrophe {
+	catastrophes := s.repo.GetAllCatastrophes()
+	sort.Slice(catastrophes, func(i, j int) bool {
+		return catastrophes[i].StartDate.After(catastrophes[j].StartDate)
+	})
+	return catastrophes
+}
+
+// GetCatastropheByID retrieves a catastrophe event by ID
+func (s *ClaimService) GetCatastropheByID(catastropheID string) (*models.Catastrophe, error) {
+	return s.repo.GetCatastropheByID(catastropheID)
+}
+
+// TagClaimCatastrophe tags a claim to a catastrophe event by hand, or removes
+// its tag when no event is given. Manual decisions are never overridden by
+// automatic tagging.
+func (s *ClaimService) TagClaimCatastrophe(claimID string, req *models.TagClaimRequest) (*models.Claim, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CatastropheID != "" {
+		if _, err := s.repo.GetCatastropheByID(req.CatastropheID); err != nil {
+			return nil, err
+		}
+	}
+
+	previous := claim.CatastropheID
+	claim.CatastropheID = req.CatastropheID
+	claim.CatastropheTag = models.CatastropheTagManual
+	claim.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateClaim(claim); err != nil {
+		return nil, fmt.Errorf("failed to tag claim: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":             claim.ID,
+		"claimNumber":         claim.ClaimNumber,
+		"catastropheId":       claim.CatastropheID,
+		"previousCatastrophe": previous,
+	}).Info("Claim catastrophe tag changed")
+
+	return claim, nil
+}
+
+// GetCatastropheExposure aggregates the claims tagged to a catastrophe event
+func (s *ClaimService) GetCatastropheExposure(catastropheID string) (*models.CatastropheExposure, error) {
+	cat, err := s.repo.GetCatastropheByID(catastropheID)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := &models.ClaimFilters{CatastropheID: cat.ID}
+	stats, err := s.GetClaimStats(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	exposure := &models.CatastropheExposure{Catastrophe: cat, Claims: stats}
+	for _, claim := range s.repo.GetClaimsByFilter(filters) {
+		switch claim.Status {
+		case "submitted", "under_review", "pending_payment":
+			exposure.OpenAmount += claim.Amount
+		case "approved":
+			exposure.ApprovedAmount += claim.Amount
+		}
+		if claim.CatastropheTag == models.CatastropheTagManual {
+			exposure.ManuallyTagged++
+		} else {
+			exposure.AutoTagged++
+		}
+	}
+
+	return exposure, nil
+}
+
+// autoTagCatastrophe tags a claim to the first declared event covering its
+// loss, unless an adjuster has already decided the claim's tag
+func (s *ClaimService) autoTagCatastrophe(claim *models.Claim) {
+	if claim.CatastropheTag == models.CatastropheTagManual {
+		return
+	}
+
+	claim.CatastropheID = ""
+	claim.CatastropheTag = ""
+
+	catastrophes := s.repo.GetAllCatastrophes()
+	sort.Slice(catastrophes, func(i, j int) bool { return catastrophes[i].ID < catastrophes[j].ID })
+	for _, cat := range catastrophes {
+		if coveredBy(cat, claim) {
+			claim.CatastropheID = cat.ID
+			claim.CatastropheTag = models.CatastropheTagAuto
+			return
+		}
+	}
+}
+
+// coveredBy reports whether the claim's loss falls inside the event. Claims
+// without an incident date and postal code cannot be matched.
+func coveredBy(cat *models.Catastrophe, claim *models.Claim) bool {
+	if claim.IncidentDate == nil || claim.LossLocation == nil {
+		return false
+	}
+	return cat.Covers(*claim.IncidentDate, claim.LossLocation.PostalCode)
+}
+
+// generateCatastropheCode numbers events sequentially within a year
+func (s *ClaimService) generateCatastropheCode(year int) string {
+	count := 0
+	for _, cat := range s.repo.GetAllCatastrophes() {
+		if cat.StartDate.Year() == year {
+			count++
+		}
+	}
+	return fmt.Sprintf("CAT-%d-%03d", year, count+1)
+}
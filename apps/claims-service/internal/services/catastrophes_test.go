@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+func TestCatastropheCovers(t *testing.T) {
+	cat := &models.Catastrophe{
+		PostalCodes: []string{"627", "62901"},
+		StartDate:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 5, 3, 23, 59, 59, 0, time.UTC),
+	}
+	during := time.Date(2024, 5, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		incident   time.Time
+		postalCode string
+		want       bool
+	}{
+		{"prefix match", during, "62704", true},
+		{"exact match", during, "62901", true},
+		{"outside region", during, "60601", false},
+		{"before event", cat.StartDate.Add(-time.Minute), "62704", false},
+		{"after event", cat.EndDate.Add(time.Minute), "62704", false},
+		{"no postal code", during, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cat.Covers(tt.incident, tt.postalCode); got != tt.want {
+				t.Errorf("Covers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCatastropheTagging(t *testing.T) {
+	incident := time.Now().Add(-24 * time.Hour)
+	inRegion := &models.LossLocation{City: "Springfield", PostalCode: "62704", Country: "US"}
+	existing := &models.Claim{ID: "claim-early", Status: "under_review", Amount: 8000, IncidentDate: &incident, LossLocation: inRegion}
+	elsewhere := &models.Claim{ID: "claim-elsewhere", Status: "approved", Amount: 500, IncidentDate: &incident,
+		LossLocation: &models.LossLocation{City: "Chicago", PostalCode: "60601", Country: "US"}}
+	service, _, _ := newTestService(t, false, existing, elsewhere)
+
+	cat, tagged, err := service.CreateCatastrophe(&models.CreateCatastropheRequest{
+		Name:        "Springfield hailstorm",
+		Peril:       models.PerilHail,
+		PostalCodes: []string{"627"},
+		StartDate:   incident.Add(-time.Hour),
+		EndDate:     incident.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateCatastrophe failed: %v", err)
+	}
+	if tagged != 1 || existing.CatastropheID != cat.ID || existing.CatastropheTag != models.CatastropheTagAuto {
+		t.Errorf("Existing claim not tagged on declaration: tagged=%d claim=%+v", tagged, existing)
+	}
+	if elsewhere.CatastropheID != "" {
+		t.Errorf("Claim outside the region was tagged: %+v", elsewhere)
+	}
+
+	// New claims for the same loss are tagged on submission
+	filed, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+		PolicyID: "pol-009", CustomerID: "cust-009", Type: "damage", Amount: 3000,
+		Description: "Hail dented car roof", IncidentDate: &incident, LossLocation: inRegion,
+	})
+	if err != nil {
+		t.Fatalf("CreateClaim failed: %v", err)
+	}
+	if filed.CatastropheID != cat.ID {
+		t.Errorf("New claim not tagged: got %q, want %q", filed.CatastropheID, cat.ID)
+	}
+
+	// An adjuster moves the unrelated claim into the event by hand
+	if _, err := service.TagClaimCatastrophe(elsewhere.ID, &models.TagClaimRequest{CatastropheID: cat.ID}); err != nil {
+		t.Fatalf("TagClaimCatastrophe failed: %v", err)
+	}
+	if _, err := service.TagClaimCatastrophe(elsewhere.ID, &models.TagClaimRequest{CatastropheID: "cat-missing"}); err == nil {
+		t.Error("Expected error tagging to unknown catastrophe")
+	}
+
+	exposure, err := service.GetCatastropheExposure(cat.ID)
+	if err != nil {
+		t.Fatalf("GetCatastropheExposure failed: %v", err)
+	}
+	if exposure.Claims.Total != 3 || exposure.Claims.TotalAmount != 11500 {
+		t.Errorf("Exposure totals mismatch: %+v", exposure.Claims)
+	}
+	if exposure.OpenAmount != 11000 || exposure.ApprovedAmount != 500 {
+		t.Errorf("Exposure amounts mismatch: open %.2f, approved %.2f", exposure.OpenAmount, exposure.ApprovedAmount)
+	}
+	if exposure.AutoTagged != 2 || exposure.ManuallyTagged != 1 {
+		t.Errorf("Tag counts mismatch: auto %d, manual %d", exposure.AutoTagged, exposure.ManuallyTagged)
+	}
+
+	// Automatic tags follow a corrected location
+	newCity := &models.LossLocation{City: "Peoria", PostalCode: "61602", Country: "US"}
+	if _, err := service.UpdateClaim(filed.ID, &models.UpdateClaimRequest{LossLocation: newCity}); err != nil {
+		t.Fatalf("UpdateClaim failed: %v", err)
+	}
+	if filed.CatastropheID != "" {
+		t.Errorf("Auto tag should follow the corrected location, got %q", filed.CatastropheID)
+	}
+}
+
+func TestCreateCatastropheValidation(t *testing.T) {
+	service, _, _ := newTestService(t, false)
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  models.CreateCatastropheRequest
+	}{
+		{"missing name", models.CreateCatastropheRequest{Peril: models.PerilFlood, PostalCodes: []string{"1"}, StartDate: start, EndDate: start}},
+		{"invalid peril", models.CreateCatastropheRequest{Name: "x", Peril: "meteor", PostalCodes: []string{"1"}, StartDate: start, EndDate: start}},
+		{"no postal codes", models.CreateCatastropheRequest{Name: "x", Peril: models.PerilFlood, PostalCodes: []string{" "}, StartDate: start, EndDate: start}},
+		{"end before start", models.CreateCatastropheRequest{Name: "x", Peril: models.PerilFlood, PostalCodes: []string{"1"}, StartDate: start, EndDate: start.Add(-time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := service.CreateCatastrophe(&tt.req); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+
+	cat, _, err := service.CreateCatastrophe(&models.CreateCatastropheRequest{Name: "Spring floods", Peril: models.PerilFlood, PostalCodes: []string{"70"}, StartDate: start, EndDate: start})
+	if err != nil {
+		t.Fatalf("CreateCatastrophe failed: %v", err)
+	}
+	if cat.Code != "CAT-2024-001" {
+		t.Errorf("Code mismatch: got %s, want CAT-2024-001", cat.Code)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+)
+
+// DefaultClaimNumberFormat numbers claims CLM-2024-000001, CLM-2024-000002
+// and so on, starting again each year
+const DefaultClaimNumberFormat = "CLM-{year}-{seq:6}"
+
+// maxClaimNumberAttempts bounds how many sequences are drawn for one claim
+// when the numbers drawn are already taken, e.g. by seed claims
+const maxClaimNumberAttempts = 10
+
+// maxClaimSequenceWidth is the widest zero padding {seq:N} accepts
+const maxClaimSequenceWidth = 12
+
+// ClaimNumberFormat renders claim numbers from the year a claim is filed
+// and the store's sequence for that year. The format is literal text with
+// the tokens {year} (2024), {yy} (24), {seq} and {seq:N} (the sequence
+// zero-padded to N digits).
+type ClaimNumberFormat struct {
+	format string
+	parts  []claimNumberPart
+}
+
+// claimNumberPart is a literal run of a format or one of its tokens
+type claimNumberPart struct {
+	literal string
+	token   string // "year", "yy" or "seq"; empty for a literal
+	width   int    // zero padding of a seq token
+}
+
+// ParseClaimNumberFormat parses a claim number format. The format must
+// contain {seq} once and {year} or {yy}, since sequences restart each year.
+func ParseClaimNumberFormat(format string) (*ClaimNumberFormat, error) {
+	f := &ClaimNumberFormat{format: format}
+	seqs, years := 0, 0
+	rest := format
+	for rest != "" {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			f.parts = append(f.parts, claimNumberPart{literal: rest})
+			break
+		}
+		if open > 0 {
+			f.parts = append(f.parts, claimNumberPart{literal: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid claim number format %q: unclosed token", format)
+		}
+		token := rest[open+1 : open+end]
+		rest = rest[open+end+1:]
+
+		part, err := parseClaimNumberToken(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid claim number format %q: %v", format, err)
+		}
+		switch part.token {
+		case "seq":
+			seqs++
+		default:
+			years++
+		}
+		f.parts = append(f.parts, part)
+	}
+
+	if seqs != 1 {
+		return nil, fmt.Errorf("invalid claim number format %q: must contain {seq} once", format)
+	}
+	if years == 0 {
+		return nil, fmt.Errorf("invalid claim number format %q: must contain {year} or {yy}", format)
+	}
+	return f, nil
+}
+
+// parseClaimNumberToken parses the text between a token's braces
+func parseClaimNumberToken(token string) (claimNumberPart, error) {
+	switch token {
+	case "year", "yy", "seq":
+		return claimNumberPart{token: token}, nil
+	}
+	if digits, ok := strings.CutPrefix(token, "seq:"); ok {
+		width, err := strconv.Atoi(digits)
+		if err != nil || width < 1 || width > maxClaimSequenceWidth {
+			return claimNumberPart{}, fmt.Errorf("{%s} must pad to 1-%d digits", token, maxClaimSequenceWidth)
+		}
+		return claimNumberPart{token: "seq", width: width}, nil
+	}
+	return claimNumberPart{}, fmt.Errorf("unknown token {%s}", token)
+}
+
+// String returns the format as written
+func (f *ClaimNumberFormat) String() string {
+	return f.format
+}
+
+// Format renders the claim number for sequence seq of year. A sequence
+// wider than its padding is written in full.
+func (f *ClaimNumberFormat) Format(year int, seq int64) string {
+	var b strings.Builder
+	for _, part := range f.parts {
+		switch part.token {
+		case "year":
+			fmt.Fprintf(&b, "%04d", year)
+		case "yy":
+			fmt.Fprintf(&b, "%02d", year%100)
+		case "seq":
+			fmt.Fprintf(&b, "%0*d", part.width, seq)
+		default:
+			b.WriteString(part.literal)
+		}
+	}
+	return b.String()
+}
+
+// defaultClaimNumbers is DefaultClaimNumberFormat parsed
+func defaultClaimNumbers() *ClaimNumberFormat {
+	f, err := ParseClaimNumberFormat(DefaultClaimNumberFormat)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// nextClaimNumber draws the next claim number of the year at from the
+// store's sequence
+func (s *ClaimService) nextClaimNumber(at time.Time) (string, error) {
+	seq, err := s.repo.NextClaimSequence(at.Year())
+	if err != nil {
+		return "", fmt.Errorf("failed to assign claim number: %w", err)
+	}
+	return s.numbers.Format(at.Year(), seq), nil
+}
+
+// createWithNumber stores a new claim in a unit of work with the writes
+// with stages, drawing another number while the one it has is already
+// taken
+func (s *ClaimService) createWithNumber(claim *models.Claim, at time.Time, with func(*models.Claim, repository.UnitOfWork)) error {
+	for attempt := 1; ; attempt++ {
+		work := s.repo.Begin()
+		work.CreateClaim(claim)
+		if with != nil {
+			with(claim, work)
+		}
+		err := work.Commit()
+		if err == nil || err.Error() != "claim number already exists" || attempt == maxClaimNumberAttempts {
+			return err
+		}
+		s.logger.WithField("claimNumber", claim.ClaimNumber).Warn("Claim number already taken, drawing another")
+		if claim.ClaimNumber, err = s.nextClaimNumber(at); err != nil {
+			return err
+		}
+	}
+}
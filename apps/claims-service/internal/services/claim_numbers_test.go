@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+)
+
+func TestParseClaimNumberFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string // sequence 42 of 2024
+		wantErr string
+	}{
+		{DefaultClaimNumberFormat, "CLM-2024-000042", ""},
+		{"{yy}/{seq}", "24/42", ""},
+		{"CLAIM{year}{seq:3}X", "CLAIM2024042X", ""},
+		{"CLM-{seq:6}", "", "must contain {year} or {yy}"},
+		{"CLM-{year}", "", "must contain {seq} once"},
+		{"{year}-{seq}-{seq}", "", "must contain {seq} once"},
+		{"CLM-{year}-{month}-{seq}", "", "unknown token {month}"},
+		{"CLM-{year}-{seq:0}", "", "{seq:0} must pad to 1-12 digits"},
+		{"CLM-{year}-{seq", "", "unclosed token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			f, err := ParseClaimNumberFormat(tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseClaimNumberFormat failed: %v", err)
+			}
+			if got := f.Format(2024, 42); got != tt.want {
+				t.Errorf("Format mismatch: got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateClaimNumbersAreUniqueUnderConcurrency(t *testing.T) {
+	service, store, _ := newTestService(t, false)
+	store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "auto"})
+
+	const submissions = 50
+	numbers := make(chan string, submissions)
+	var wg sync.WaitGroup
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{PolicyID: "pol-001", CustomerID: "cust-001", Type: "accident", Amount: 500, Force: true})
+			if err != nil {
+				t.Errorf("CreateClaim failed: %v", err)
+				return
+			}
+			numbers <- claim.ClaimNumber
+		}()
+	}
+	wg.Wait()
+	close(numbers)
+
+	seen := make(map[string]bool)
+	for number := range numbers {
+		if seen[number] {
+			t.Errorf("Claim number %s issued twice", number)
+		}
+		seen[number] = true
+	}
+	if len(seen) != submissions {
+		t.Errorf("Expected %d claim numbers, got %d", submissions, len(seen))
+	}
+}
+
+func TestCreateClaimSkipsTakenNumbers(t *testing.T) {
+	year := time.Now().Year()
+	seeded := &models.Claim{ID: "claim-001", PolicyID: "pol-001", CustomerID: "cust-001", ClaimNumber: defaultClaimNumbers().Format(year, 1), Status: "approved"}
+	service, store, _ := newTestService(t, false, seeded)
+	store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "auto"})
+
+	claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{PolicyID: "pol-001", CustomerID: "cust-001", Type: "accident", Amount: 500, Force: true})
+	if err != nil {
+		t.Fatalf("CreateClaim failed: %v", err)
+	}
+	if want := defaultClaimNumbers().Format(year, 2); claim.ClaimNumber != want {
+		t.Errorf("Expected the taken number to be skipped, got %s, want %s", claim.ClaimNumber, want)
+	}
+}
+
+func TestClaimNumberSequenceRestartsEachYear(t *testing.T) {
+	service, _, _ := newTestService(t, false)
+
+	for _, tt := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2024, 12, 31, 23, 0, 0, 0, time.UTC), "CLM-2024-000001"},
+		{time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC), "CLM-2024-000002"},
+		{time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC), "CLM-2025-000001"},
+	} {
+		got, err := service.nextClaimNumber(tt.at)
+		if err != nil {
+			t.Fatalf("nextClaimNumber failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Claim number mismatch at %s: got %s, want %s", tt.at, got, tt.want)
+		}
+	}
+}
@@ -1,72 +1,211 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/approval"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/lifecycle"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/taxonomy"
 	"github.com/sirupsen/logrus"
 )
 
-const (
-	// AutoApprovalThreshold is the maximum amount for automatic approval (in dollars)
-	AutoApprovalThreshold = 1000.0
-)
+// ApprovalPolicy decides whether a new claim is approved, sent to review or
+// rejected as it is filed. See the approval package for the policies
+// carriers can choose between.
+type ApprovalPolicy interface {
+	Decide(claim *models.Claim, policy approval.Policy, customer approval.Customer) approval.Verdict
+}
 
 // ClaimService handles business logic for claims
 type ClaimService struct {
-	repo   *repository.Repository
-	flags  *features.Flags
-	logger *logrus.Logger
+	repo      repository.ClaimStore
+	flags     *features.Flags
+	approvals ApprovalPolicy
+	policies  PolicyLookup
+	fallback  breaker.Fallback // when policy-service cannot answer at intake
+	events    *events.Bus
+	lifecycle *lifecycle.Machine
+	types     *taxonomy.Taxonomy
+	numbers   *ClaimNumberFormat
+	logger    *logrus.Logger
 }
 
-// NewClaimService creates a new claim service
-func NewClaimService(repo *repository.Repository, flags *features.Flags, logger *logrus.Logger) *ClaimService {
-	return &ClaimService{
-		repo:   repo,
-		flags:  flags,
-		logger: logger,
+// NewClaimService creates a new claim service. policies may be nil when
+// policy-service is not configured; claims are then filed without checking
+// whether the policy is in its grace period. types is the claim taxonomy
+// new claims are checked against; nil uses taxonomy.Default. numbers is the
+// format of new claim numbers; nil uses DefaultClaimNumberFormat. approvals
+// decides new claims; nil uses the claims.autoApproval threshold. fallback
+// is what intake does when policy-service cannot answer: breaker.FailOpen,
+// or empty, files the claim without the grace check, and breaker.FailClosed
+// refuses it.
+func NewClaimService(repo repository.ClaimStore, flags *features.Flags, approvals ApprovalPolicy, policies PolicyLookup, fallback breaker.Fallback, bus *events.Bus, types *taxonomy.Taxonomy, numbers *ClaimNumberFormat, logger *logrus.Logger) *ClaimService {
+	if approvals == nil {
+		approvals = approval.NewThreshold(flags)
+	}
+	if types == nil {
+		types = taxonomy.Default()
+	}
+	if numbers == nil {
+		numbers = defaultClaimNumbers()
 	}
+	s := &ClaimService{
+		repo:      repo,
+		flags:     flags,
+		approvals: approvals,
+		policies:  policies,
+		fallback:  fallback,
+		events:    bus,
+		lifecycle: lifecycle.Claims(),
+		types:     types,
+		numbers:   numbers,
+		logger:    logger,
+	}
+	s.lifecycle.OnTransition(s.publishTransition)
+	return s
 }
 
-// GetClaimByID retrieves a claim by ID
-func (s *ClaimService) GetClaimByID(claimID string) (*models.Claim, error) {
-	return s.repo.GetClaimByID(claimID)
+// GetClaimByID retrieves a claim the viewer may read. Customers may only
+// read claims on their own policies; staff may read any claim. Offers past
+// their expiry are shown as expired.
+func (s *ClaimService) GetClaimByID(claimID string, viewer models.ClaimViewer) (*models.Claim, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !viewer.IsStaff() {
+		if owner := s.ownerOf(claim, nil); owner != viewer.ID {
+			s.logger.WithFields(logrus.Fields{
+				"claimId":    claimID,
+				"customerId": viewer.ID,
+				"ownerId":    owner,
+			}).Warn("Unauthorized claim access attempt")
+			return nil, fmt.Errorf("unauthorized")
+		}
+	}
+	claim.ExpireOffers(time.Now())
+	return claim, nil
+}
+
+// GetClaimIncludingArchived retrieves a claim whether it is live or was
+// moved to the archive, for legal retrievals. Only staff may read the
+// archive.
+func (s *ClaimService) GetClaimIncludingArchived(claimID string, viewer models.ClaimViewer) (*models.Claim, error) {
+	if !viewer.IsStaff() {
+		return nil, fmt.Errorf("archived claims are only available to staff")
+	}
+
+	claim, err := s.GetClaimByID(claimID, viewer)
+	if err == nil || err.Error() != "claim not found" {
+		return claim, err
+	}
+	return s.repo.GetArchivedClaim(claimID)
+}
+
+// GetClaims retrieves the claims matching filters that the viewer may
+// read, most recent first. Customers only see claims on their own
+// policies, and may not ask for another customer's claims. Archived claims
+// are only included for staff who ask for them.
+func (s *ClaimService) GetClaims(filters *models.ClaimFilters, viewer models.ClaimViewer) ([]*models.Claim, error) {
+	if !viewer.IsStaff() && filters != nil && filters.CustomerID != "" && filters.CustomerID != viewer.ID {
+		s.logger.WithFields(logrus.Fields{
+			"customerId":        viewer.ID,
+			"requestCustomerId": filters.CustomerID,
+		}).Warn("Unauthorized claim listing attempt")
+		return nil, fmt.Errorf("unauthorized")
+	}
+	if !viewer.IsStaff() && filters != nil && filters.IncludeArchived {
+		return nil, fmt.Errorf("archived claims are only available to staff")
+	}
+
+	claims := s.findClaims(filters)
+	if !viewer.IsStaff() {
+		owners := make(map[string]string)
+		visible := make([]*models.Claim, 0, len(claims))
+		for _, claim := range claims {
+			if s.ownerOf(claim, owners) == viewer.ID {
+				visible = append(visible, claim)
+			}
+		}
+		claims = visible
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"count":   len(claims),
+		"filters": filters,
+		"viewer":  viewer.ID,
+	}).Info("Retrieved claims")
+
+	return claims, nil
 }
 
-// GetClaims retrieves claims with optional filters
-func (s *ClaimService) GetClaims(filters *models.ClaimFilters) ([]*models.Claim, error) {
+// findClaims returns every claim matching filters, most recent first
+func (s *ClaimService) findClaims(filters *models.ClaimFilters) []*models.Claim {
 	var claims []*models.Claim
 
-	if filters == nil || (filters.PolicyID == "" && filters.CustomerID == "" && filters.Status == "" && filters.Type == "") {
+	if filters.IsEmpty() {
 		// No filters - return all claims
 		claims = s.repo.GetAllClaims()
 	} else {
 		// Apply filters
 		claims = s.repo.GetClaimsByFilter(filters)
 	}
+	if filters != nil && filters.IncludeArchived {
+		claims = append(claims, s.repo.GetArchivedClaims(filters)...)
+	}
 
 	// Sort by submission date descending (most recent first)
 	sort.Slice(claims, func(i, j int) bool {
 		return claims[i].SubmittedDate.After(claims[j].SubmittedDate)
 	})
+	return claims
+}
 
-	s.logger.WithFields(logrus.Fields{
-		"count":   len(claims),
-		"filters": filters,
-	}).Info("Retrieved claims")
+// ownerOf returns the customer who owns a claim: the holder of the policy
+// it is filed against, or the claimant recorded on the claim when the
+// policy is unknown to this service. owners, when given, caches policy
+// holders across calls.
+func (s *ClaimService) ownerOf(claim *models.Claim, owners map[string]string) string {
+	holder, ok := owners[claim.PolicyID]
+	if !ok {
+		if policy, err := s.repo.GetPolicyByID(claim.PolicyID); err == nil {
+			holder = policy.CustomerID
+		}
+		if owners != nil {
+			owners[claim.PolicyID] = holder
+		}
+	}
+	if holder == "" {
+		return claim.CustomerID
+	}
+	return holder
+}
 
-	return claims, nil
+// CreateClaim creates a new claim with governance rules applied. Claims
+// against a policy in its grace period are held as pending_payment until
+// the policy is reinstated or lapses.
+func (s *ClaimService) CreateClaim(ctx context.Context, req *models.CreateClaimRequest) (*models.Claim, error) {
+	return s.CreateClaimWith(ctx, req, nil)
 }
 
-// CreateClaim creates a new claim with governance rules applied
-func (s *ClaimService) CreateClaim(req *models.CreateClaimRequest) (*models.Claim, error) {
-	// Validate claim type
-	if !models.ValidateClaimType(req.Type) {
-		return nil, fmt.Errorf("invalid claim type: %s (must be accident, theft, or damage)", req.Type)
+// CreateClaimWith creates a claim as CreateClaim does, calling with, when
+// set, to stage the writes of the record the claim is filed from in the
+// same unit of work. The claim and those writes are stored together or
+// not at all; with may run more than once if the claim number is redrawn.
+func (s *ClaimService) CreateClaimWith(ctx context.Context, req *models.CreateClaimRequest, with func(claim *models.Claim, work repository.UnitOfWork)) (*models.Claim, error) {
+	// Validate claim type against the policy's line
+	if err := s.types.Check(s.policyType(req.PolicyID), req.Type, req.SubType); err != nil {
+		return nil, err
 	}
 
 	// Validate amount
@@ -74,60 +213,121 @@ func (s *ClaimService) CreateClaim(req *models.CreateClaimRequest) (*models.Clai
 		return nil, fmt.Errorf("claim amount must be greater than 0")
 	}
 
-	// Generate claim number
-	claimNumber := s.generateClaimNumber()
-
-	// Determine initial status based on auto-approval feature flag
-	status := "under_review"
-	autoApprovalEnabled := s.flags.IsAutoApprovalEnabled()
+	// Validate the loss happened while the policy was in force
+	now := time.Now()
+	live, err := s.livePolicy(ctx, req.PolicyID, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.IncidentDate != nil {
+		if err := s.validateIncidentDate(req.PolicyID, *req.IncidentDate, now, live); err != nil {
+			return nil, err
+		}
+	}
 
-	// Apply governance rule: auto-approve low-value claims if feature flag is enabled
-	if autoApprovalEnabled && req.Amount < AutoApprovalThreshold {
-		status = "approved"
-		s.logger.WithFields(logrus.Fields{
-			"claimNumber": claimNumber,
-			"amount":      req.Amount,
-			"threshold":   AutoApprovalThreshold,
-		}).Info("Auto-approved low-value claim")
-	} else {
+	// Hold the policy's write section from the duplicate check until the
+	// claim is stored, so two submissions of one incident cannot both pass
+	unlock, err := s.repo.LockPolicy(req.PolicyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock policy: %w", err)
+	}
+	defer unlock()
+
+	// Guard against the same incident being submitted twice
+	duplicateOf := ""
+	if existing := s.findDuplicate(req, now); existing != nil {
+		if !req.Force {
+			s.logger.WithFields(logrus.Fields{
+				"policyId":        req.PolicyID,
+				"existingClaimId": existing.ID,
+			}).Info("Rejected likely duplicate claim submission")
+			return nil, &DuplicateClaimError{Existing: existing}
+		}
+		duplicateOf = existing.ID
 		s.logger.WithFields(logrus.Fields{
-			"claimNumber":       claimNumber,
-			"amount":            req.Amount,
-			"autoApprovalFlag":  autoApprovalEnabled,
-			"threshold":         AutoApprovalThreshold,
-		}).Info("Claim requires manual review")
+			"policyId":        req.PolicyID,
+			"existingClaimId": existing.ID,
+		}).Warn("Likely duplicate claim filed with force")
 	}
 
-	now := time.Now()
+	// Draw the claim number from the year's sequence
+	claimNumber, err := s.nextClaimNumber(now)
+	if err != nil {
+		return nil, err
+	}
+
+	queue := s.queueForPolicy(req.PolicyID)
 	claim := &models.Claim{
 		ID:            s.generateClaimID(),
 		PolicyID:      req.PolicyID,
 		CustomerID:    req.CustomerID,
 		ClaimNumber:   claimNumber,
 		Type:          req.Type,
-		Status:        status,
+		SubType:       req.SubType,
+		Status:        s.lifecycle.Initial(),
 		Amount:        req.Amount,
 		Description:   req.Description,
+		Queue:         queue,
+		DuplicateOf:   duplicateOf,
+		IncidentDate:  req.IncidentDate,
+		LossLocation:  req.LossLocation,
 		SubmittedDate: now,
 		ReviewedDate:  nil,
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
 
-	// If auto-approved, set reviewed date
-	if status == "approved" {
-		claim.ReviewedDate = &now
+	// Group losses from a declared catastrophe event
+	s.autoTagCatastrophe(claim)
+
+	// Decide where the claim goes when it leaves submitted. Premium is
+	// overdue on policies in grace, so those claims wait for payment
+	// whatever the approval policy decides.
+	verdict := s.approvals.Decide(claim, s.approvalPolicy(req.PolicyID, live), s.approvalCustomer(req.CustomerID, now))
+	claim.ApprovalReason = verdict.Reason
+	intake := lifecycle.Change{Trigger: lifecycle.TriggerIntake, At: now}
+	fields := logrus.Fields{
+		"claimNumber": claimNumber,
+		"amount":      req.Amount,
+		"reason":      verdict.Reason,
+	}
+	switch {
+	case live != nil && live.Status == policyStatusGrace:
+		intake.To = lifecycle.PendingPayment
+		claim.ApprovalReason = "policy is in grace, premium is overdue"
+		s.logger.WithFields(logrus.Fields{
+			"claimNumber": claimNumber,
+			"policyId":    req.PolicyID,
+			"graceEndsAt": live.GraceEndsAt,
+		}).Info("Claim held pending payment, policy is in grace")
+	case verdict.Decision == approval.Approve:
+		intake.To = lifecycle.Approved
+		s.logger.WithFields(fields).Info("Auto-approved claim")
+	case verdict.Decision == approval.Reject:
+		intake.To = lifecycle.Rejected
+		intake.RejectionCodes = []string{verdict.RejectionCode}
+		s.logger.WithFields(fields).Info("Auto-rejected claim")
+	default:
+		intake.To = lifecycle.UnderReview
+		s.logger.WithFields(fields).Info("Claim requires manual review")
 	}
 
-	if err := s.repo.CreateClaim(claim); err != nil {
-		return nil, fmt.Errorf("failed to create claim: %w", err)
+	err = s.lifecycle.Fire(claim, intake, func(claim *models.Claim) error {
+		if err := s.createWithNumber(claim, now, with); err != nil {
+			return fmt.Errorf("failed to create claim: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	s.logger.WithFields(logrus.Fields{
-		"claimId":     claim.ID,
-		"claimNumber": claim.ClaimNumber,
-		"status":      claim.Status,
-		"amount":      claim.Amount,
+		"claimId":       claim.ID,
+		"claimNumber":   claim.ClaimNumber,
+		"status":        claim.Status,
+		"amount":        claim.Amount,
+		"catastropheId": claim.CatastropheID,
 	}).Info("Claim created successfully")
 
 	return claim, nil
@@ -141,8 +341,8 @@ func (s *ClaimService) UpdateClaim(claimID string, req *models.UpdateClaimReques
 		return nil, err
 	}
 
-	// Only allow updates to claims that are in submitted or under_review status
-	if claim.Status == "approved" || claim.Status == "rejected" {
+	// Decided claims can no longer be edited
+	if s.lifecycle.IsTerminal(claim.Status) {
 		return nil, fmt.Errorf("cannot update claim with status: %s", claim.Status)
 	}
 
@@ -158,6 +358,22 @@ func (s *ClaimService) UpdateClaim(claimID string, req *models.UpdateClaimReques
 		claim.Description = *req.Description
 	}
 
+	if req.IncidentDate != nil {
+		if err := s.validateIncidentDate(claim.PolicyID, *req.IncidentDate, claim.SubmittedDate, nil); err != nil {
+			return nil, err
+		}
+		claim.IncidentDate = req.IncidentDate
+	}
+
+	if req.LossLocation != nil {
+		claim.LossLocation = req.LossLocation
+	}
+
+	// A corrected date or location can move the loss in or out of an event
+	if req.IncidentDate != nil || req.LossLocation != nil {
+		s.autoTagCatastrophe(claim)
+	}
+
 	claim.UpdatedAt = time.Now()
 
 	if err := s.repo.UpdateClaim(claim); err != nil {
@@ -180,48 +396,394 @@ func (s *ClaimService) UpdateClaimStatus(claimID string, req *models.UpdateClaim
 		return nil, err
 	}
 
-	// Validate new status
-	if !models.ValidateClaimStatus(req.Status) {
-		return nil, fmt.Errorf("invalid claim status: %s", req.Status)
+	return s.changeStatus(claim, req)
+}
+
+// BulkUpdateClaimStatus applies a batch of status changes. Each change is
+// validated and applied on its own, and only if the claim is still at the
+// version it was decided on; a failed change is reported in its result
+// without stopping the rest of the batch.
+func (s *ClaimService) BulkUpdateClaimStatus(updates []models.BulkStatusUpdate) (*models.BulkStatusResponse, error) {
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("at least one status update is required")
+	}
+	if len(updates) > models.MaxBulkStatusUpdates {
+		return nil, fmt.Errorf("at most %d status updates are allowed per request", models.MaxBulkStatusUpdates)
 	}
 
-	// Prevent status changes on already finalized claims
-	if claim.Status == "approved" || claim.Status == "rejected" {
-		return nil, fmt.Errorf("cannot change status of finalized claim (current status: %s)", claim.Status)
+	response := &models.BulkStatusResponse{Results: make([]models.BulkStatusResult, 0, len(updates))}
+	for i := range updates {
+		result := s.applyBulkStatusUpdate(&updates[i])
+		if result.Success {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
 	}
 
+	s.logger.WithFields(logrus.Fields{
+		"succeeded": response.Succeeded,
+		"failed":    response.Failed,
+	}).Info("Bulk claim status update processed")
+
+	return response, nil
+}
+
+// applyBulkStatusUpdate applies one change of a bulk update
+func (s *ClaimService) applyBulkStatusUpdate(update *models.BulkStatusUpdate) models.BulkStatusResult {
+	result := models.BulkStatusResult{ClaimID: update.ClaimID}
+	fail := func(code, message string) models.BulkStatusResult {
+		result.Code = code
+		result.Error = message
+		return result
+	}
+
+	if update.ClaimID == "" {
+		return fail(models.BulkInvalid, "claimId is required")
+	}
+	if update.Version <= 0 {
+		return fail(models.BulkInvalid, "version is required")
+	}
+
+	claim, err := s.repo.GetClaimByID(update.ClaimID)
+	if err != nil {
+		if err.Error() == "claim not found" {
+			return fail(models.BulkNotFound, "claim not found")
+		}
+		return fail(models.BulkInvalid, err.Error())
+	}
+	if claim.Version != update.Version {
+		return fail(models.BulkVersionConflict, fmt.Sprintf("claim is at version %d", claim.Version))
+	}
+
+	updated, err := s.changeStatus(claim, &update.UpdateClaimStatusRequest)
+	if err != nil {
+		if err.Error() == "claim version conflict" {
+			// Changed between the read and the write
+			if current, getErr := s.repo.GetClaimByID(update.ClaimID); getErr == nil {
+				return fail(models.BulkVersionConflict, fmt.Sprintf("claim is at version %d", current.Version))
+			}
+			return fail(models.BulkVersionConflict, err.Error())
+		}
+		return fail(models.BulkInvalid, err.Error())
+	}
+
+	result.Success = true
+	result.Claim = updated
+	return result
+}
+
+// changeStatus moves claim to the requested status through the claim
+// lifecycle. A claim changed by someone else since it was read is refused
+// with "claim version conflict".
+func (s *ClaimService) changeStatus(claim *models.Claim, req *models.UpdateClaimStatusRequest) (*models.Claim, error) {
 	oldStatus := claim.Status
-	claim.Status = req.Status
+	change := lifecycle.Change{
+		To:             req.Status,
+		Trigger:        lifecycle.TriggerReview,
+		RejectionCodes: req.RejectionCodes,
+		Notes:          req.Notes,
+	}
+	err := s.lifecycle.Fire(claim, change, func(claim *models.Claim) error {
+		if err := s.repo.UpdateClaim(claim); err != nil {
+			if err.Error() == "claim version conflict" {
+				return err
+			}
+			return fmt.Errorf("failed to update claim status: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":        claim.ID,
+		"claimNumber":    claim.ClaimNumber,
+		"oldStatus":      oldStatus,
+		"newStatus":      claim.Status,
+		"notes":          req.Notes,
+		"rejectionCodes": claim.RejectionCodes,
+	}).Info("Claim status updated")
+
+	return claim, nil
+}
+
+// GetClaimStats summarizes the claims matching the filters, including how
+// often each rejection reason is used
+func (s *ClaimService) GetClaimStats(filters *models.ClaimFilters) (*models.ClaimStats, error) {
+	claims := s.findClaims(filters)
+
+	stats := &models.ClaimStats{
+		ByStatus:         make(map[string]int),
+		ByType:           make(map[string]int),
+		RejectionReasons: make(map[string]int),
+	}
+	for _, code := range models.RejectionCodes {
+		stats.RejectionReasons[code] = 0
+	}
+
+	for _, claim := range claims {
+		stats.Total++
+		stats.TotalAmount += claim.Amount
+		stats.ByStatus[claim.Status]++
+		stats.ByType[claim.Type]++
+		for _, code := range claim.RejectionCodes {
+			stats.RejectionReasons[code]++
+		}
+	}
+
+	return stats, nil
+}
+
+// AssignClaim assigns a claim to an adjuster, optionally moving it to another queue
+func (s *ClaimService) AssignClaim(claimID string, req *models.AssignClaimRequest) (*models.Claim, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.AdjusterID == "" {
+		return nil, fmt.Errorf("adjusterId is required")
+	}
+
+	previousAdjuster := claim.AssignedTo
+	claim.AssignedTo = req.AdjusterID
+	if req.Queue != "" {
+		claim.Queue = req.Queue
+	}
 	claim.UpdatedAt = time.Now()
 
-	// If approving or rejecting, set reviewed date
-	if req.Status == "approved" || req.Status == "rejected" {
-		now := time.Now()
-		claim.ReviewedDate = &now
+	if err := s.repo.UpdateClaim(claim); err != nil {
+		return nil, fmt.Errorf("failed to assign claim: %w", err)
 	}
 
+	s.logger.WithFields(logrus.Fields{
+		"claimId":          claim.ID,
+		"claimNumber":      claim.ClaimNumber,
+		"adjusterId":       claim.AssignedTo,
+		"previousAdjuster": previousAdjuster,
+		"queue":            claim.Queue,
+	}).Info("Claim assigned")
+
+	s.publish(events.Event{
+		Type:        events.ClaimAssigned,
+		ClaimID:     claim.ID,
+		ClaimNumber: claim.ClaimNumber,
+		PolicyID:    claim.PolicyID,
+		CustomerID:  claim.CustomerID,
+		NewStatus:   claim.Status,
+		Queue:       claim.Queue,
+		AssignedTo:  claim.AssignedTo,
+	})
+
+	return claim, nil
+}
+
+// EscalateClaim flags a claim for senior review
+func (s *ClaimService) EscalateClaim(claimID string, req *models.EscalateClaimRequest) (*models.Claim, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.lifecycle.IsTerminal(claim.Status) {
+		return nil, fmt.Errorf("cannot escalate finalized claim (current status: %s)", claim.Status)
+	}
+
+	claim.Escalated = true
+	claim.UpdatedAt = time.Now()
+
 	if err := s.repo.UpdateClaim(claim); err != nil {
-		return nil, fmt.Errorf("failed to update claim status: %w", err)
+		return nil, fmt.Errorf("failed to escalate claim: %w", err)
 	}
 
 	s.logger.WithFields(logrus.Fields{
 		"claimId":     claim.ID,
 		"claimNumber": claim.ClaimNumber,
-		"oldStatus":   oldStatus,
-		"newStatus":   req.Status,
-		"notes":       req.Notes,
-	}).Info("Claim status updated")
+		"queue":       claim.Queue,
+		"reason":      req.Reason,
+	}).Warn("Claim escalated")
+
+	s.publish(events.Event{
+		Type:        events.ClaimEscalated,
+		ClaimID:     claim.ID,
+		ClaimNumber: claim.ClaimNumber,
+		PolicyID:    claim.PolicyID,
+		CustomerID:  claim.CustomerID,
+		NewStatus:   claim.Status,
+		Queue:       claim.Queue,
+		AssignedTo:  claim.AssignedTo,
+		Reason:      req.Reason,
+	})
 
 	return claim, nil
 }
 
+// publishTransition publishes a saved status change: a claim filed through
+// intake as claim.created, any later change as claim.status_changed
+func (s *ClaimService) publishTransition(claim *models.Claim, from string, change lifecycle.Change) {
+	if change.Trigger == lifecycle.TriggerIntake {
+		s.publish(events.Event{
+			Type:        events.ClaimCreated,
+			ClaimID:     claim.ID,
+			ClaimNumber: claim.ClaimNumber,
+			PolicyID:    claim.PolicyID,
+			CustomerID:  claim.CustomerID,
+			NewStatus:   claim.Status,
+			Queue:       claim.Queue,
+			DuplicateOf: claim.DuplicateOf,
+		})
+		return
+	}
+
+	s.publish(events.Event{
+		Type:           events.ClaimStatusChanged,
+		ClaimID:        claim.ID,
+		ClaimNumber:    claim.ClaimNumber,
+		PolicyID:       claim.PolicyID,
+		CustomerID:     claim.CustomerID,
+		OldStatus:      from,
+		NewStatus:      claim.Status,
+		Queue:          claim.Queue,
+		AssignedTo:     claim.AssignedTo,
+		RejectionCodes: claim.RejectionCodes,
+	})
+}
+
+// publish sends a claim event to subscribers and records it on the claim's
+// timeline. Failing to record the entry does not fail the change itself.
+func (s *ClaimService) publish(evt events.Event) {
+	evt = s.events.Publish(evt)
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+
+	if err := s.repo.AddTimelineEntry(timelineEntryFor(evt)); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"claimId":   evt.ClaimID,
+			"eventType": evt.Type,
+		}).Warn("Failed to record timeline entry")
+	}
+}
+
+// validateIncidentDate checks the loss date is not after the claim was
+// submitted and falls within the policy term. Policies unknown to this
+// service cannot be checked against a term. When live shows the policy in
+// grace, losses up to the end of the grace period are accepted too.
+func (s *ClaimService) validateIncidentDate(policyID string, incident, submitted time.Time, live *clients.Policy) error {
+	if incident.After(submitted) {
+		return fmt.Errorf("incident date cannot be after the claim was submitted")
+	}
+
+	policy, err := s.repo.GetPolicyByID(policyID)
+	if err != nil {
+		s.logger.WithField("policyId", policyID).Debug("Policy unknown, skipping incident date term check")
+		return nil
+	}
+	if live != nil && live.Status == policyStatusGrace && live.GraceEndsAt != nil &&
+		!incident.Before(policy.StartDate) && !incident.After(*live.GraceEndsAt) {
+		return nil
+	}
+	if !policy.Covers(incident) {
+		return fmt.Errorf("incident date %s is outside the policy term (%s to %s)",
+			incident.Format("2006-01-02"), policy.StartDate.Format("2006-01-02"), policy.EndDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// livePolicy fetches the policy's current standing from policy-service. It
+// returns nil when policy-service is not configured or does not return the
+// policy. When policy-service cannot answer, including while its breaker is
+// open, it returns nil failing open, so an outage never blocks claim
+// intake, and "policy-service unavailable" failing closed.
+func (s *ClaimService) livePolicy(ctx context.Context, policyID, customerID string) (*clients.Policy, error) {
+	if s.policies == nil {
+		return nil, nil
+	}
+	policy, err := s.policies.GetPolicy(ctx, policyID, customerID)
+	if err == nil {
+		return policy, nil
+	}
+	answered := err.Error() == "unauthorized" || err.Error() == "policy not found"
+	if !answered && s.fallback == breaker.FailClosed {
+		s.logger.WithError(err).WithField("policyId", policyID).Warn("Policy lookup failed, refusing claim")
+		return nil, fmt.Errorf("policy-service unavailable")
+	}
+	s.logger.WithError(err).WithField("policyId", policyID).Warn("Policy lookup failed, filing claim without grace check")
+	return nil, nil
+}
+
+// ClaimTypes returns the claim taxonomy new claims are checked against
+func (s *ClaimService) ClaimTypes() *taxonomy.Taxonomy {
+	return s.types
+}
+
+// GetClaimTypes returns the claim types of every policy type, or only of
+// policyType when it is given
+func (s *ClaimService) GetClaimTypes(policyType string) (*models.ClaimTypesResponse, error) {
+	response := &models.ClaimTypesResponse{
+		Version:     s.types.Version,
+		PolicyTypes: s.types.PolicyTypes,
+	}
+	if policyType != "" {
+		types, ok := s.types.Types(policyType)
+		if !ok {
+			return nil, fmt.Errorf("unknown policy type: %s", policyType)
+		}
+		response.PolicyTypes = map[string][]models.ClaimType{policyType: types}
+	}
+	return response, nil
+}
+
+// policyType returns the policy line a policy belongs to, or "" when the
+// policy is unknown to this service
+func (s *ClaimService) policyType(policyID string) string {
+	policy, err := s.repo.GetPolicyByID(policyID)
+	if err != nil {
+		return ""
+	}
+	return policy.Type
+}
+
+// approvalPolicy describes the policy a new claim is filed on to the
+// approval policy. live is its standing in policy-service, when known.
+func (s *ClaimService) approvalPolicy(policyID string, live *clients.Policy) approval.Policy {
+	policy := approval.Policy{ID: policyID, Type: s.policyType(policyID)}
+	if live != nil {
+		policy.Status = live.Status
+		if policy.Type == "" {
+			policy.Type = live.Type
+		}
+	}
+	return policy
+}
+
+// approvalCustomer describes the customer filing a claim to the approval
+// policy, counting the claims they filed in the past year when the policy
+// reads them
+func (s *ClaimService) approvalCustomer(customerID string, now time.Time) approval.Customer {
+	customer := approval.Customer{ID: customerID}
+	if history, ok := s.approvals.(approval.HistoryReader); ok && history.ReadsClaimHistory() {
+		customer.RecentClaims = len(s.repo.GetClaimsByFilter(&models.ClaimFilters{
+			CustomerID:    customerID,
+			SubmittedFrom: now.AddDate(-1, 0, 0),
+		}))
+	}
+	return customer
+}
+
+// queueForPolicy routes new claims to the adjuster queue for the policy line
+func (s *ClaimService) queueForPolicy(policyID string) string {
+	policy, err := s.repo.GetPolicyByID(policyID)
+	if err != nil || policy.Type == "" {
+		return "general"
+	}
+	return policy.Type
+}
+
 // generateClaimID generates a unique claim ID
 func (s *ClaimService) generateClaimID() string {
 	return fmt.Sprintf("claim-%d", time.Now().UnixNano())
 }
-
-// generateClaimNumber generates a human-readable claim number
-func (s *ClaimService) generateClaimNumber() string {
-	now := time.Now()
-	return fmt.Sprintf("CLM-%d-%06d", now.Year(), now.Unix()%1000000)
-}
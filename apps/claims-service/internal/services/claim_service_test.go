@@ -0,0 +1,769 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/approval"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/taxonomy"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestService(t *testing.T, autoApproval bool, claims ...*models.Claim) (*ClaimService, *repositorytest.FakeStore, *events.Bus) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	flags := newTestFlags(t, autoApproval, logger)
+	store := repositorytest.NewFakeStore(claims...)
+	bus := events.NewBus(10, logger)
+	return NewClaimService(store, flags, nil, nil, "", bus, nil, nil, logger), store, bus
+}
+
+// newTestFlags returns flags with only auto-approval set, ignoring the
+// environment
+func newTestFlags(tb testing.TB, autoApproval bool, logger *logrus.Logger) *features.Flags {
+	tb.Helper()
+	tb.Setenv("FEATURE_AUTO_APPROVAL", "false")
+	tb.Setenv("FEATURE_AUTO_APPROVAL_THRESHOLD", "")
+	tb.Setenv("FEATURE_FLAGS_FILE", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		tb.Fatalf("Failed to initialize flags: %v", err)
+	}
+	flags.SetAutoApproval(autoApproval)
+	return flags
+}
+
+// staffViewer reads claims as an adjuster
+var staffViewer = models.ClaimViewer{ID: "adj-001", Role: "adjuster"}
+
+func TestCreateClaimAutoApproval(t *testing.T) {
+	tests := []struct {
+		name         string
+		autoApproval bool
+		amount       float64
+		wantStatus   string
+	}{
+		{"flag off", false, 500, "under_review"},
+		{"flag on below threshold", true, 500, "approved"},
+		{"flag on at threshold", true, features.DefaultAutoApprovalThreshold, "under_review"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store, _ := newTestService(t, tt.autoApproval)
+			store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "home"})
+
+			claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{PolicyID: "pol-001", CustomerID: "cust-001", Type: "damage", Amount: tt.amount})
+			if err != nil {
+				t.Fatalf("CreateClaim failed: %v", err)
+			}
+			if claim.Status != tt.wantStatus {
+				t.Errorf("Status mismatch: got %s, want %s", claim.Status, tt.wantStatus)
+			}
+			if (claim.ReviewedDate != nil) != (tt.wantStatus == "approved") {
+				t.Errorf("Reviewed date set incorrectly for %s claim", claim.Status)
+			}
+			if claim.Queue != "home" {
+				t.Errorf("Queue mismatch: got %s, want home", claim.Queue)
+			}
+		})
+	}
+}
+
+func TestCreateClaimUsesCurrentThreshold(t *testing.T) {
+	service, store, _ := newTestService(t, true)
+	store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "auto"})
+	create := func(amount float64) string {
+		claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{PolicyID: "pol-001", CustomerID: "cust-001", Type: "accident", Amount: amount, Force: true})
+		if err != nil {
+			t.Fatalf("CreateClaim failed: %v", err)
+		}
+		return claim.Status
+	}
+
+	if status := create(1500); status != "under_review" {
+		t.Errorf("Status above default threshold: got %s, want under_review", status)
+	}
+
+	// Threshold changes apply to the next submission without a restart
+	service.flags.SetValue(features.KeyAutoApprovalThreshold, "2500")
+	if status := create(1500); status != "approved" {
+		t.Errorf("Status below raised threshold: got %s, want approved", status)
+	}
+
+	service.flags.SetValue(features.KeyAutoApprovalThreshold, "lots")
+	if status := create(1500); status != "under_review" {
+		t.Errorf("Status with invalid threshold: got %s, want under_review", status)
+	}
+}
+
+// rejectFrequent is an approval policy rejecting customers with recent
+// claims, recording what it was asked
+type rejectFrequent struct {
+	policies  []approval.Policy
+	customers []approval.Customer
+}
+
+func (p *rejectFrequent) ReadsClaimHistory() bool { return true }
+
+func (p *rejectFrequent) Decide(claim *models.Claim, policy approval.Policy, customer approval.Customer) approval.Verdict {
+	p.policies = append(p.policies, policy)
+	p.customers = append(p.customers, customer)
+	if customer.RecentClaims > 0 {
+		return approval.Verdict{Decision: approval.Reject, Reason: "repeat claimant", RejectionCode: models.RejectionFraudSuspected}
+	}
+	return approval.Verdict{Decision: approval.Approve, Reason: "first claim"}
+}
+
+func TestCreateClaimFollowsApprovalPolicy(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore()
+	store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "home"})
+	policy := &rejectFrequent{}
+	service := NewClaimService(store, newTestFlags(t, false, logger), policy, nil, "", events.NewBus(10, logger), nil, nil, logger)
+	create := func(amount float64) *models.Claim {
+		claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{PolicyID: "pol-001", CustomerID: "cust-001", Type: "damage", Amount: amount, Force: true})
+		if err != nil {
+			t.Fatalf("CreateClaim failed: %v", err)
+		}
+		return claim
+	}
+
+	first := create(5000)
+	if first.Status != "approved" || first.ApprovalReason != "first claim" {
+		t.Errorf("Expected the first claim approved by the policy, got %s (%q)", first.Status, first.ApprovalReason)
+	}
+	second := create(100)
+	if second.Status != "rejected" || second.ApprovalReason != "repeat claimant" || len(second.RejectionCodes) != 1 || second.RejectionCodes[0] != models.RejectionFraudSuspected {
+		t.Errorf("Expected the second claim rejected as fraud_suspected, got %s %v (%q)", second.Status, second.RejectionCodes, second.ApprovalReason)
+	}
+
+	if got := policy.policies[0]; got != (approval.Policy{ID: "pol-001", Type: "home"}) {
+		t.Errorf("Unexpected policy passed to the approval policy: %+v", got)
+	}
+	if got := policy.customers[1]; got != (approval.Customer{ID: "cust-001", RecentClaims: 1}) {
+		t.Errorf("Unexpected customer passed to the approval policy: %+v", got)
+	}
+}
+
+func TestCreateClaimValidation(t *testing.T) {
+	service, store, _ := newTestService(t, false)
+
+	if _, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{Type: "flood", Amount: 100}); err == nil {
+		t.Error("Expected error for invalid claim type")
+	}
+	if _, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{Type: "theft", Amount: 0}); err == nil {
+		t.Error("Expected error for zero amount")
+	}
+	if n := len(store.GetAllClaims()); n != 0 {
+		t.Errorf("Invalid requests stored %d claims", n)
+	}
+}
+
+func TestCreateClaimChecksTypeAgainstPolicyLine(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+	store := repositorytest.NewFakeStore()
+	store.AddPolicy(&repository.Policy{ID: "pol-auto", CustomerID: "cust-001", Type: "auto"})
+	store.AddPolicy(&repository.Policy{ID: "pol-home", CustomerID: "cust-001", Type: "home"})
+	types := &taxonomy.Taxonomy{
+		Version: "test",
+		PolicyTypes: map[string][]models.ClaimType{
+			"auto": {
+				{Code: "accident", SubTypes: []models.ClaimSubType{{Code: "collision"}}},
+				{Code: "damage", SubTypes: []models.ClaimSubType{{Code: "windshield"}}},
+			},
+			"home": {
+				{Code: "damage", SubTypes: []models.ClaimSubType{{Code: "water_damage"}}},
+			},
+		},
+	}
+	service := NewClaimService(store, flags, nil, nil, "", events.NewBus(10, logger), types, nil, logger)
+
+	tests := []struct {
+		name     string
+		policyID string
+		claim    string
+		subType  string
+		wantErr  string
+	}{
+		{"type of the policy line", "pol-home", "damage", "", ""},
+		{"sub-type of the type", "pol-home", "damage", "water_damage", ""},
+		{"type of another line", "pol-home", "accident", "", "invalid claim type: accident for home policies (must be damage)"},
+		{"sub-type of another line", "pol-home", "damage", "windshield", "invalid claim sub-type: windshield (must be water_damage)"},
+		{"unknown policy takes any line's types", "pol-unknown", "damage", "windshield", ""},
+		{"unknown policy and type", "pol-unknown", "flood", "", "invalid claim type: flood (must be accident or damage)"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+				PolicyID:    tt.policyID,
+				CustomerID:  "cust-001",
+				Type:        tt.claim,
+				SubType:     tt.subType,
+				Amount:      float64(1000 + i),
+				Description: tt.name,
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Expected %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateClaim failed: %v", err)
+			}
+			if claim.Type != tt.claim || claim.SubType != tt.subType {
+				t.Errorf("Got %s/%s, want %s/%s", claim.Type, claim.SubType, tt.claim, tt.subType)
+			}
+		})
+	}
+
+	home, err := service.GetClaimTypes("home")
+	if err != nil || len(home.PolicyTypes) != 1 || home.PolicyTypes["home"][0].Code != "damage" {
+		t.Errorf("Unexpected home claim types %+v (%v)", home, err)
+	}
+	if _, err := service.GetClaimTypes("marine"); err == nil {
+		t.Error("Expected an unknown policy type to fail")
+	}
+}
+
+func TestUpdateClaimStatusPublishesChange(t *testing.T) {
+	service, _, bus := newTestService(t, false, &models.Claim{ID: "claim-001", Status: "under_review"})
+	history, sub := bus.Subscribe(nil, 0, 1)
+	defer sub.Close()
+	if len(history) != 0 {
+		t.Fatalf("Unexpected history: %+v", history)
+	}
+
+	claim, err := service.UpdateClaimStatus("claim-001", &models.UpdateClaimStatusRequest{Status: "approved"})
+	if err != nil {
+		t.Fatalf("UpdateClaimStatus failed: %v", err)
+	}
+	if claim.ReviewedDate == nil {
+		t.Error("Approved claim should have a reviewed date")
+	}
+
+	evt := <-sub.C
+	if evt.Type != events.ClaimStatusChanged || evt.OldStatus != "under_review" || evt.NewStatus != "approved" {
+		t.Errorf("Unexpected event: %+v", evt)
+	}
+
+	if _, err := service.UpdateClaimStatus("claim-001", &models.UpdateClaimStatusRequest{Status: "rejected"}); err == nil {
+		t.Error("Finalized claim should not change status")
+	}
+}
+
+func TestBulkUpdateClaimStatus(t *testing.T) {
+	service, store, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-001", Status: "under_review", Version: 1},
+		&models.Claim{ID: "claim-002", Status: "under_review", Version: 3},
+		&models.Claim{ID: "claim-003", Status: "approved", Version: 2},
+	)
+
+	response, err := service.BulkUpdateClaimStatus([]models.BulkStatusUpdate{
+		{ClaimID: "claim-001", Version: 1, UpdateClaimStatusRequest: models.UpdateClaimStatusRequest{Status: "approved", Notes: "Small loss"}},
+		{ClaimID: "claim-002", Version: 2, UpdateClaimStatusRequest: models.UpdateClaimStatusRequest{Status: "approved"}},
+		{ClaimID: "claim-404", Version: 1, UpdateClaimStatusRequest: models.UpdateClaimStatusRequest{Status: "approved"}},
+		{ClaimID: "claim-003", Version: 2, UpdateClaimStatusRequest: models.UpdateClaimStatusRequest{Status: "rejected"}},
+		{ClaimID: "claim-002", UpdateClaimStatusRequest: models.UpdateClaimStatusRequest{Status: "approved"}},
+	})
+	if err != nil {
+		t.Fatalf("BulkUpdateClaimStatus failed: %v", err)
+	}
+	if response.Succeeded != 1 || response.Failed != 4 || len(response.Results) != 5 {
+		t.Fatalf("Unexpected totals: %+v", response)
+	}
+
+	want := []struct {
+		success bool
+		code    string
+	}{
+		{true, ""},
+		{false, models.BulkVersionConflict},
+		{false, models.BulkNotFound},
+		{false, models.BulkInvalid},
+		{false, models.BulkInvalid},
+	}
+	for i, result := range response.Results {
+		if result.Success != want[i].success || result.Code != want[i].code {
+			t.Errorf("Result %d: got %+v, want success=%v code=%q", i, result, want[i].success, want[i].code)
+		}
+	}
+	if claim := response.Results[0].Claim; claim == nil || claim.Status != "approved" || claim.Version != 2 {
+		t.Errorf("Updated claim: got %+v", claim)
+	}
+	if response.Results[1].Error != "claim is at version 3" {
+		t.Errorf("Conflict message: %q", response.Results[1].Error)
+	}
+	if claim, _ := store.GetClaimByID("claim-002"); claim.Status != "under_review" {
+		t.Errorf("Conflicting change was applied: %+v", claim)
+	}
+
+	if _, err := service.BulkUpdateClaimStatus(nil); err == nil {
+		t.Error("Expected an empty batch to be rejected")
+	}
+	if _, err := service.BulkUpdateClaimStatus(make([]models.BulkStatusUpdate, models.MaxBulkStatusUpdates+1)); err == nil {
+		t.Error("Expected an oversized batch to be rejected")
+	}
+}
+
+func TestGetClaimsSortsMostRecentFirst(t *testing.T) {
+	service, _, _ := newTestService(t, false)
+	for _, amount := range []float64{100, 200, 300} {
+		if _, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{Type: "theft", Amount: amount}); err != nil {
+			t.Fatalf("CreateClaim failed: %v", err)
+		}
+	}
+
+	claims, err := service.GetClaims(nil, staffViewer)
+	if err != nil {
+		t.Fatalf("GetClaims failed: %v", err)
+	}
+	for i := 1; i < len(claims); i++ {
+		if claims[i].SubmittedDate.After(claims[i-1].SubmittedDate) {
+			t.Fatalf("Claims not sorted by submission date: %v after %v", claims[i].SubmittedDate, claims[i-1].SubmittedDate)
+		}
+	}
+}
+
+func TestRejectionRequiresReasonCodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		codes     []string
+		wantErr   bool
+		wantCodes []string
+	}{
+		{"rejected without code", "rejected", nil, true, nil},
+		{"rejected with unknown code", "rejected", []string{"not_covered", "bad_vibes"}, true, nil},
+		{"rejected with codes", "rejected", []string{"late_filing", "insufficient_docs", "late_filing"}, false, []string{"late_filing", "insufficient_docs"}},
+		{"approved with code", "approved", []string{"duplicate"}, true, nil},
+		{"approved without code", "approved", nil, false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store, _ := newTestService(t, false, &models.Claim{ID: "claim-001", Status: "under_review"})
+
+			claim, err := service.UpdateClaimStatus("claim-001", &models.UpdateClaimStatusRequest{Status: tt.status, RejectionCodes: tt.codes})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected validation error")
+				}
+				if stored, _ := store.GetClaimByID("claim-001"); stored.Status != "under_review" {
+					t.Errorf("Rejected request changed status to %s", stored.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateClaimStatus failed: %v", err)
+			}
+			if len(claim.RejectionCodes) != len(tt.wantCodes) {
+				t.Fatalf("Codes mismatch: got %v, want %v", claim.RejectionCodes, tt.wantCodes)
+			}
+			for i := range tt.wantCodes {
+				if claim.RejectionCodes[i] != tt.wantCodes[i] {
+					t.Errorf("Codes mismatch: got %v, want %v", claim.RejectionCodes, tt.wantCodes)
+				}
+			}
+		})
+	}
+}
+
+func TestGetClaimStatsCountsRejectionReasons(t *testing.T) {
+	service, _, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-001", Type: "theft", Status: "rejected", Amount: 100, RejectionCodes: []string{"fraud_suspected", "insufficient_docs"}},
+		&models.Claim{ID: "claim-002", Type: "theft", Status: "rejected", Amount: 200, RejectionCodes: []string{"fraud_suspected"}},
+		&models.Claim{ID: "claim-003", Type: "damage", Status: "approved", Amount: 300},
+	)
+
+	stats, err := service.GetClaimStats(nil)
+	if err != nil {
+		t.Fatalf("GetClaimStats failed: %v", err)
+	}
+	if stats.Total != 3 || stats.TotalAmount != 600 || stats.ByStatus["rejected"] != 2 || stats.ByType["theft"] != 2 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	if stats.RejectionReasons["fraud_suspected"] != 2 || stats.RejectionReasons["insufficient_docs"] != 1 {
+		t.Errorf("Unexpected rejection reasons: %v", stats.RejectionReasons)
+	}
+	if n, ok := stats.RejectionReasons["duplicate"]; !ok || n != 0 {
+		t.Errorf("Unused codes should be reported as zero, got %v", stats.RejectionReasons)
+	}
+}
+
+func TestCreateClaimDetectsDuplicates(t *testing.T) {
+	recent := time.Now().Add(-48 * time.Hour)
+	existing := func() *models.Claim {
+		return &models.Claim{
+			ID:            "claim-001",
+			ClaimNumber:   "CLM-2024-000001",
+			PolicyID:      "pol-001",
+			Type:          "accident",
+			Status:        "under_review",
+			Amount:        3200,
+			Description:   "Rear-end collision at traffic light on Main Street",
+			SubmittedDate: recent,
+		}
+	}
+
+	base := models.CreateClaimRequest{
+		PolicyID:    "pol-001",
+		CustomerID:  "cust-001",
+		Type:        "accident",
+		Amount:      3300,
+		Description: "Rear-end collision at the traffic light, Main Street",
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(req *models.CreateClaimRequest, c *models.Claim)
+		wantError bool
+	}{
+		{"same incident", func(req *models.CreateClaimRequest, c *models.Claim) {}, true},
+		{"different policy", func(req *models.CreateClaimRequest, c *models.Claim) { req.PolicyID = "pol-002" }, false},
+		{"different type", func(req *models.CreateClaimRequest, c *models.Claim) { req.Type = "theft" }, false},
+		{"different amount", func(req *models.CreateClaimRequest, c *models.Claim) { req.Amount = 9000 }, false},
+		{"different description", func(req *models.CreateClaimRequest, c *models.Claim) {
+			req.Description = "Side mirror clipped in car park"
+		}, false},
+		{"outside window", func(req *models.CreateClaimRequest, c *models.Claim) {
+			c.SubmittedDate = time.Now().Add(-DuplicateWindow - time.Hour)
+		}, false},
+		{"earlier claim rejected", func(req *models.CreateClaimRequest, c *models.Claim) { c.Status = "rejected" }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := existing()
+			req := base
+			tt.modify(&req, claim)
+			service, _, _ := newTestService(t, false, claim)
+
+			_, err := service.CreateClaim(context.Background(), &req)
+			var duplicate *DuplicateClaimError
+			if got := errors.As(err, &duplicate); got != tt.wantError {
+				t.Fatalf("Duplicate detection mismatch: got error %v", err)
+			}
+			if tt.wantError && duplicate.Existing.ID != "claim-001" {
+				t.Errorf("Wrong existing claim: %s", duplicate.Existing.ID)
+			}
+		})
+	}
+}
+
+func TestCreateClaimForceRecordsDuplicate(t *testing.T) {
+	service, _, bus := newTestService(t, false, &models.Claim{
+		ID:            "claim-001",
+		PolicyID:      "pol-001",
+		Type:          "theft",
+		Status:        "submitted",
+		Amount:        800,
+		Description:   "Bicycle stolen from garage overnight",
+		SubmittedDate: time.Now().Add(-time.Hour),
+	})
+	_, sub := bus.Subscribe(nil, 0, 1)
+	defer sub.Close()
+
+	claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+		PolicyID:    "pol-001",
+		Type:        "theft",
+		Amount:      800,
+		Description: "Bicycle stolen from the garage overnight",
+		Force:       true,
+	})
+	if err != nil {
+		t.Fatalf("Forced CreateClaim failed: %v", err)
+	}
+	if claim.DuplicateOf != "claim-001" {
+		t.Errorf("DuplicateOf mismatch: got %q, want claim-001", claim.DuplicateOf)
+	}
+	if evt := <-sub.C; evt.Type != events.ClaimCreated || evt.DuplicateOf != "claim-001" {
+		t.Errorf("Override not recorded on event: %+v", evt)
+	}
+}
+
+// slowFilterStore stalls the duplicate check, so submissions that are not
+// serialized would all pass it before any of them is stored
+type slowFilterStore struct {
+	*repositorytest.FakeStore
+}
+
+func (s slowFilterStore) GetClaimsByFilter(filters *models.ClaimFilters) []*models.Claim {
+	claims := s.FakeStore.GetClaimsByFilter(filters)
+	time.Sleep(5 * time.Millisecond)
+	return claims
+}
+
+func TestCreateClaimDetectsConcurrentDuplicates(t *testing.T) {
+	service, store, _ := newTestService(t, false)
+	store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "auto"})
+	service.repo = slowFilterStore{store}
+
+	const submissions = 20
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created, duplicates := 0, 0
+	for i := 0; i < submissions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+				PolicyID:    "pol-001",
+				CustomerID:  "cust-001",
+				Type:        "accident",
+				Amount:      1200,
+				Description: "Scraped bumper reversing out of the driveway",
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			var duplicate *DuplicateClaimError
+			switch {
+			case err == nil:
+				created++
+			case errors.As(err, &duplicate):
+				duplicates++
+			default:
+				t.Errorf("CreateClaim failed: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if created != 1 || duplicates != submissions-1 {
+		t.Errorf("Expected 1 claim and %d duplicates, got %d and %d", submissions-1, created, duplicates)
+	}
+	if claims := store.GetClaimsByFilter(&models.ClaimFilters{PolicyID: "pol-001"}); len(claims) != 1 {
+		t.Errorf("Expected 1 stored claim, got %d", len(claims))
+	}
+}
+
+func TestCreateClaimValidatesIncidentDate(t *testing.T) {
+	termStart := time.Now().AddDate(0, -6, 0)
+	termEnd := time.Now().AddDate(0, 6, 0)
+
+	tests := []struct {
+		name     string
+		policyID string
+		incident time.Time
+		wantErr  bool
+	}{
+		{"within term", "pol-001", time.Now().AddDate(0, 0, -2), false},
+		{"in the future", "pol-001", time.Now().Add(48 * time.Hour), true},
+		{"before term start", "pol-001", termStart.AddDate(0, 0, -1), true},
+		{"unknown policy skips term check", "pol-404", termStart.AddDate(-1, 0, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store, _ := newTestService(t, false)
+			store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "auto", StartDate: termStart, EndDate: termEnd})
+
+			incident := tt.incident
+			claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+				PolicyID:     tt.policyID,
+				CustomerID:   "cust-001",
+				Type:         "accident",
+				Amount:       1200,
+				IncidentDate: &incident,
+				LossLocation: &models.LossLocation{City: "Springfield", State: "IL"},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Error mismatch: got %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if claim.IncidentDate == nil || !claim.IncidentDate.Equal(incident) {
+				t.Errorf("Incident date not stored: got %v, want %v", claim.IncidentDate, incident)
+			}
+			if claim.LossLocation == nil || claim.LossLocation.City != "Springfield" {
+				t.Errorf("Loss location not stored: %+v", claim.LossLocation)
+			}
+		})
+	}
+}
+
+func TestGetClaimsFiltersByIncidentDate(t *testing.T) {
+	early := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	service, _, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-early", SubmittedDate: early, IncidentDate: &early},
+		&models.Claim{ID: "claim-late", SubmittedDate: late, IncidentDate: &late},
+		&models.Claim{ID: "claim-undated", SubmittedDate: late},
+	)
+
+	claims, err := service.GetClaims(&models.ClaimFilters{IncidentFrom: early.AddDate(0, 1, 0)}, staffViewer)
+	if err != nil {
+		t.Fatalf("GetClaims failed: %v", err)
+	}
+	if len(claims) != 1 || claims[0].ID != "claim-late" {
+		t.Errorf("Incident filter mismatch: got %+v", claims)
+	}
+
+	claims, err = service.GetClaims(&models.ClaimFilters{SubmittedTo: early}, staffViewer)
+	if err != nil {
+		t.Fatalf("GetClaims failed: %v", err)
+	}
+	if len(claims) != 1 || claims[0].ID != "claim-early" {
+		t.Errorf("Submitted filter mismatch: got %+v", claims)
+	}
+}
+
+func TestClaimReadsAreScopedToTheCustomer(t *testing.T) {
+	service, store, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-own", PolicyID: "pol-001", CustomerID: "cust-001"},
+		&models.Claim{ID: "claim-other", PolicyID: "pol-002", CustomerID: "cust-002"},
+		// Filed under cust-001's ID against cust-002's policy: the policy holder wins
+		&models.Claim{ID: "claim-mismatch", PolicyID: "pol-002", CustomerID: "cust-001"},
+		// The policy is unknown, so the claim's own customer ID is the owner
+		&models.Claim{ID: "claim-unknown-policy", PolicyID: "pol-999", CustomerID: "cust-001"},
+	)
+	store.AddPolicy(&repository.Policy{ID: "pol-001", CustomerID: "cust-001", Type: "home"})
+	store.AddPolicy(&repository.Policy{ID: "pol-002", CustomerID: "cust-002", Type: "auto"})
+	customer := models.ClaimViewer{ID: "cust-001"}
+
+	claims, err := service.GetClaims(nil, customer)
+	if err != nil {
+		t.Fatalf("GetClaims failed: %v", err)
+	}
+	got := map[string]bool{}
+	for _, claim := range claims {
+		got[claim.ID] = true
+	}
+	if len(got) != 2 || !got["claim-own"] || !got["claim-unknown-policy"] {
+		t.Errorf("Customer saw the wrong claims: %v", got)
+	}
+
+	if _, err := service.GetClaims(&models.ClaimFilters{CustomerID: "cust-002"}, customer); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Expected unauthorized filtering by another customer, got %v", err)
+	}
+	if claims, err := service.GetClaims(&models.ClaimFilters{CustomerID: "cust-002"}, staffViewer); err != nil || len(claims) != 1 {
+		t.Errorf("Expected staff to filter by any customer, got %d claims (%v)", len(claims), err)
+	}
+	if claims, err := service.GetClaims(nil, staffViewer); err != nil || len(claims) != 4 {
+		t.Errorf("Expected staff to see every claim, got %d claims (%v)", len(claims), err)
+	}
+
+	if _, err := service.GetClaimByID("claim-own", customer); err != nil {
+		t.Errorf("Expected the customer to read their own claim, got %v", err)
+	}
+	for _, id := range []string{"claim-other", "claim-mismatch"} {
+		if _, err := service.GetClaimByID(id, customer); err == nil || err.Error() != "unauthorized" {
+			t.Errorf("Expected unauthorized reading %s, got %v", id, err)
+		}
+	}
+	if _, err := service.GetClaimByID("claim-other", staffViewer); err != nil {
+		t.Errorf("Expected staff to read any claim, got %v", err)
+	}
+}
+
+// newBenchService runs the service on the in-memory repository the server
+// uses, holding claims already filed across benchPolicies policies
+func newBenchService(b *testing.B, claims int) *ClaimService {
+	b.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	repo, err := repository.NewRepository(b.TempDir(), logger)
+	if err != nil {
+		b.Fatalf("NewRepository failed: %v", err)
+	}
+	service := NewClaimService(repo, newTestFlags(b, false, logger), nil, nil, "", events.NewBus(10, logger), nil, nil, logger)
+	for i := 0; i < claims; i++ {
+		if _, err := service.CreateClaim(context.Background(), benchClaimRequest(i)); err != nil {
+			b.Fatalf("CreateClaim failed: %v", err)
+		}
+	}
+	return service
+}
+
+const benchPolicies = 500
+
+// benchClaimRequest is the n-th of a stream of distinct claims, none a
+// duplicate of another
+func benchClaimRequest(n int) *models.CreateClaimRequest {
+	policy := n % benchPolicies
+	return &models.CreateClaimRequest{
+		PolicyID:    fmt.Sprintf("pol-%04d", policy),
+		CustomerID:  fmt.Sprintf("cust-%04d", policy),
+		Type:        "accident",
+		Amount:      250 + float64(n%40)*25,
+		Description: fmt.Sprintf("Collision report%d", n),
+	}
+}
+
+func BenchmarkCreateClaim(b *testing.B) {
+	service := newBenchService(b, 5000)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := service.CreateClaim(context.Background(), benchClaimRequest(5000+i)); err != nil {
+			b.Fatalf("CreateClaim failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkCreateClaimParallel(b *testing.B) {
+	service := newBenchService(b, 5000)
+	var next int64 = 5000
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n := int(atomic.AddInt64(&next, 1))
+			if _, err := service.CreateClaim(context.Background(), benchClaimRequest(n)); err != nil {
+				b.Errorf("CreateClaim failed: %v", err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkGetClaims(b *testing.B) {
+	service := newBenchService(b, 5000)
+	benchmarks := []struct {
+		name    string
+		filters *models.ClaimFilters
+		viewer  models.ClaimViewer
+	}{
+		{"staff all", nil, staffViewer},
+		{"staff by status", &models.ClaimFilters{Status: "under_review"}, staffViewer},
+		{"customer own", &models.ClaimFilters{CustomerID: "cust-0007"}, models.ClaimViewer{ID: "cust-0007"}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := service.GetClaims(bm.filters, bm.viewer); err != nil {
+					b.Fatalf("GetClaims failed: %v", err)
+				}
+			}
+		})
+	}
+}
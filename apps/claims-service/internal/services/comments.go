@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// CommentService manages comment threads on claims. Staff see and write
+// every comment; customers only see customer-visible comments on their own
+// claims and cannot write internal ones.
+type CommentService struct {
+	repo   repository.CommentStore
+	logger *logrus.Logger
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(repo repository.CommentStore, logger *logrus.Logger) *CommentService {
+	return &CommentService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// AddComment adds a comment to a claim's thread
+func (s *CommentService) AddComment(claimID string, author models.CommentAuthor, req *models.CreateCommentRequest) (*models.Comment, error) {
+	claim, err := s.claimFor(claimID, author)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = models.CommentCustomer
+		if author.IsStaff() {
+			visibility = models.CommentInternal
+		}
+	}
+	if err := checkVisibility(visibility, author); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	comment := &models.Comment{
+		ID:         fmt.Sprintf("cmt-%d", now.UnixNano()),
+		ClaimID:    claim.ID,
+		AuthorID:   author.ID,
+		AuthorRole: author.Role,
+		Body:       body,
+		Visibility: visibility,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.CreateComment(comment); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":    claim.ID,
+		"commentId":  comment.ID,
+		"authorId":   author.ID,
+		"visibility": visibility,
+	}).Info("Claim comment added")
+
+	return comment, nil
+}
+
+// GetComments returns the comments on a claim the author may see, oldest
+// first
+func (s *CommentService) GetComments(claimID string, author models.CommentAuthor) ([]*models.Comment, error) {
+	if _, err := s.claimFor(claimID, author); err != nil {
+		return nil, err
+	}
+
+	comments := s.repo.GetComments(claimID)
+	if author.IsStaff() {
+		return comments, nil
+	}
+
+	visible := []*models.Comment{}
+	for _, comment := range comments {
+		if comment.Visibility == models.CommentCustomer {
+			visible = append(visible, comment)
+		}
+	}
+	return visible, nil
+}
+
+// UpdateComment edits a comment, keeping the version it replaces in the
+// comment's edit history. Only the author of a comment may edit it.
+func (s *CommentService) UpdateComment(claimID, commentID string, author models.CommentAuthor, req *models.UpdateCommentRequest) (*models.Comment, error) {
+	if _, err := s.claimFor(claimID, author); err != nil {
+		return nil, err
+	}
+
+	comment, err := s.repo.GetComment(commentID)
+	if err != nil || comment.ClaimID != claimID {
+		return nil, fmt.Errorf("comment not found")
+	}
+	if comment.AuthorID != author.ID {
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = comment.Visibility
+	}
+	if err := checkVisibility(visibility, author); err != nil {
+		return nil, err
+	}
+	if body == comment.Body && visibility == comment.Visibility {
+		return comment, nil
+	}
+
+	now := time.Now()
+	comment.Edits = append(append([]models.CommentEdit{}, comment.Edits...), models.CommentEdit{
+		Body:       comment.Body,
+		Visibility: comment.Visibility,
+		EditedAt:   now,
+	})
+	comment.Body = body
+	comment.Visibility = visibility
+	comment.UpdatedAt = now
+	if err := s.repo.UpdateComment(comment); err != nil {
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":    claimID,
+		"commentId":  commentID,
+		"authorId":   author.ID,
+		"visibility": visibility,
+		"edits":      len(comment.Edits),
+	}).Info("Claim comment edited")
+
+	return comment, nil
+}
+
+// claimFor returns the claim if the author may access its comments
+func (s *CommentService) claimFor(claimID string, author models.CommentAuthor) (*models.Claim, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+	if !author.IsStaff() && claim.CustomerID != author.ID {
+		s.logger.WithFields(logrus.Fields{
+			"claimId":    claimID,
+			"customerId": author.ID,
+			"ownerId":    claim.CustomerID,
+		}).Warn("Unauthorized comment access attempt")
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return claim, nil
+}
+
+// commentBody validates a comment body and returns it trimmed
+func commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("comment body is required")
+	}
+	if len(body) > models.MaxCommentLength {
+		return "", fmt.Errorf("comment exceeds %d characters", models.MaxCommentLength)
+	}
+	return body, nil
+}
+
+// checkVisibility validates a comment visibility for the author
+func checkVisibility(visibility string, author models.CommentAuthor) error {
+	if !models.ValidateCommentVisibility(visibility) {
+		return fmt.Errorf("invalid visibility: %s (must be internal or customer)", visibility)
+	}
+	if visibility == models.CommentInternal && !author.IsStaff() {
+		return fmt.Errorf("only staff can write internal comments")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestCommentVisibility(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(&models.Claim{ID: "claim-001", CustomerID: "cust-001"})
+	service := NewCommentService(store, logger)
+
+	adjuster := models.CommentAuthor{ID: "adj-001", Role: "adjuster"}
+	claimant := models.CommentAuthor{ID: "cust-001", Role: models.CommentRoleCustomer}
+	stranger := models.CommentAuthor{ID: "cust-002", Role: models.CommentRoleCustomer}
+
+	// Staff notes default to internal, customer comments to customer-visible
+	note, err := service.AddComment("claim-001", adjuster, &models.CreateCommentRequest{Body: "Photos look consistent with the report"})
+	if err != nil || note.Visibility != models.CommentInternal {
+		t.Fatalf("Adjuster note: got %+v, %v", note, err)
+	}
+	reply, err := service.AddComment("claim-001", claimant, &models.CreateCommentRequest{Body: "  Repair shop quote attached  "})
+	if err != nil || reply.Visibility != models.CommentCustomer || reply.Body != "Repair shop quote attached" {
+		t.Fatalf("Claimant comment: got %+v, %v", reply, err)
+	}
+
+	if _, err := service.AddComment("claim-001", claimant, &models.CreateCommentRequest{Body: "x", Visibility: models.CommentInternal}); err == nil || err.Error() != "only staff can write internal comments" {
+		t.Errorf("Customer internal comment: got %v", err)
+	}
+	if _, err := service.AddComment("claim-001", adjuster, &models.CreateCommentRequest{Body: " "}); err == nil || err.Error() != "comment body is required" {
+		t.Errorf("Empty comment: got %v", err)
+	}
+	if _, err := service.AddComment("claim-001", stranger, &models.CreateCommentRequest{Body: "hello"}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Comment on someone else's claim: got %v", err)
+	}
+
+	staffView, err := service.GetComments("claim-001", adjuster)
+	if err != nil || len(staffView) != 2 {
+		t.Errorf("Staff should see both comments, got %d, %v", len(staffView), err)
+	}
+	customerView, err := service.GetComments("claim-001", claimant)
+	if err != nil || len(customerView) != 1 || customerView[0].ID != reply.ID {
+		t.Errorf("Claimant should only see their comment, got %+v, %v", customerView, err)
+	}
+	if _, err := service.GetComments("claim-404", adjuster); err == nil || err.Error() != "claim not found" {
+		t.Errorf("Unknown claim: got %v", err)
+	}
+}
+
+func TestUpdateCommentKeepsEditHistory(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(&models.Claim{ID: "claim-001", CustomerID: "cust-001"})
+	service := NewCommentService(store, logger)
+	adjuster := models.CommentAuthor{ID: "adj-001", Role: "adjuster"}
+
+	comment, err := service.AddComment("claim-001", adjuster, &models.CreateCommentRequest{Body: "Awaiting police report"})
+	if err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+
+	edited, err := service.UpdateComment("claim-001", comment.ID, adjuster, &models.UpdateCommentRequest{Body: "Police report received", Visibility: models.CommentCustomer})
+	if err != nil {
+		t.Fatalf("UpdateComment failed: %v", err)
+	}
+	if edited.Body != "Police report received" || edited.Visibility != models.CommentCustomer || len(edited.Edits) != 1 {
+		t.Fatalf("Unexpected edited comment: %+v", edited)
+	}
+	if previous := edited.Edits[0]; previous.Body != "Awaiting police report" || previous.Visibility != models.CommentInternal {
+		t.Errorf("Edit history lost the original: %+v", previous)
+	}
+
+	// Saving the same text is not an edit
+	if again, err := service.UpdateComment("claim-001", comment.ID, adjuster, &models.UpdateCommentRequest{Body: "Police report received"}); err != nil || len(again.Edits) != 1 {
+		t.Errorf("Unchanged edit recorded: %+v, %v", again, err)
+	}
+
+	other := models.CommentAuthor{ID: "adj-002", Role: "adjuster"}
+	if _, err := service.UpdateComment("claim-001", comment.ID, other, &models.UpdateCommentRequest{Body: "mine now"}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Edit by another adjuster: got %v", err)
+	}
+	if _, err := service.UpdateComment("claim-001", "cmt-404", adjuster, &models.UpdateCommentRequest{Body: "x"}); err == nil || err.Error() != "comment not found" {
+		t.Errorf("Unknown comment: got %v", err)
+	}
+}
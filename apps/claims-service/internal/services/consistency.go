@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyLookup reads policies from policy-service. *clients.PolicyClient is
+// the production implementation.
+type PolicyLookup interface {
+	GetPolicy(ctx context.Context, policyID, customerID string) (*clients.Policy, error)
+}
+
+var _ PolicyLookup = (*clients.PolicyClient)(nil)
+
+// ConsistencyChecker cross-validates the references held by claims against
+// the services that own the referenced records
+type ConsistencyChecker struct {
+	repo     repository.ClaimStore
+	policies PolicyLookup
+	logger   *logrus.Logger
+}
+
+// NewConsistencyChecker creates a checker. policies may be nil when
+// policy-service is not configured; those checks are then reported as skipped.
+func NewConsistencyChecker(repo repository.ClaimStore, policies PolicyLookup, logger *logrus.Logger) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		repo:     repo,
+		policies: policies,
+		logger:   logger,
+	}
+}
+
+// Check builds a consistency report for every stored claim
+func (c *ConsistencyChecker) Check(ctx context.Context) *models.ConsistencyReport {
+	report := models.NewConsistencyReport("claims-service")
+
+	claims := c.repo.GetAllClaims()
+	sort.Slice(claims, func(i, j int) bool { return claims[i].ID < claims[j].ID })
+	report.Checked = len(claims)
+
+	// Claims may still refer to claims that were archived
+	known := make(map[string]bool, len(claims))
+	for _, claim := range claims {
+		known[claim.ID] = true
+	}
+	for _, claim := range c.repo.GetArchivedClaims(&models.ClaimFilters{}) {
+		known[claim.ID] = true
+	}
+
+	// References within this service
+	for _, claim := range claims {
+		if claim.DuplicateOf != "" && !known[claim.DuplicateOf] {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityWarning,
+				Check:     "claim.duplicate_of_exists",
+				EntityID:  claim.ID,
+				Reference: claim.DuplicateOf,
+				Message:   "claim is marked as a duplicate of a claim that does not exist",
+			})
+		}
+		if claim.CatastropheID != "" {
+			if _, err := c.repo.GetCatastropheByID(claim.CatastropheID); err != nil {
+				report.Add(models.ConsistencyIssue{
+					Severity:  models.SeverityWarning,
+					Check:     "claim.catastrophe_exists",
+					EntityID:  claim.ID,
+					Reference: claim.CatastropheID,
+					Message:   "claim is tagged to a catastrophe event that does not exist",
+				})
+			}
+		}
+	}
+
+	// References owned by policy-service
+	if c.policies == nil {
+		report.Add(models.ConsistencyIssue{
+			Severity: models.SeverityInfo,
+			Check:    "claim.policy_exists",
+			Message:  "policy-service is not configured; policy references were not checked",
+		})
+	} else {
+		c.checkPolicies(ctx, claims, report)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"checked":  report.Checked,
+		"errors":   report.Summary[models.SeverityError],
+		"warnings": report.Summary[models.SeverityWarning],
+	}).Info("Consistency check completed")
+
+	return report
+}
+
+// checkPolicies resolves each claim's policy as the claimant would. The
+// check stops at the first transport failure rather than reporting every
+// claim as orphaned.
+func (c *ConsistencyChecker) checkPolicies(ctx context.Context, claims []*models.Claim, report *models.ConsistencyReport) {
+	for _, claim := range claims {
+		policy, err := c.policies.GetPolicy(ctx, claim.PolicyID, claim.CustomerID)
+		if err != nil {
+			switch err.Error() {
+			case "policy not found":
+				report.Add(models.ConsistencyIssue{
+					Severity:  models.SeverityError,
+					Check:     "claim.policy_exists",
+					EntityID:  claim.ID,
+					Reference: claim.PolicyID,
+					Message:   "claim references a policy that does not exist",
+				})
+			case "unauthorized":
+				report.Add(models.ConsistencyIssue{
+					Severity:  models.SeverityError,
+					Check:     "claim.policy_owner",
+					EntityID:  claim.ID,
+					Reference: claim.PolicyID,
+					Message:   fmt.Sprintf("claimant %s does not own the policy", claim.CustomerID),
+				})
+			default:
+				c.logger.WithError(err).Warn("Policy lookup failed, skipping remaining policy checks")
+				report.Add(models.ConsistencyIssue{
+					Severity: models.SeverityInfo,
+					Check:    "claim.policy_exists",
+					Message:  fmt.Sprintf("policy-service unavailable, remaining policy references not checked: %v", err),
+				})
+				return
+			}
+			continue
+		}
+
+		term := &repository.Policy{StartDate: policy.StartDate, EndDate: policy.EndDate}
+		if claim.IncidentDate != nil && !term.Covers(*claim.IncidentDate) {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityWarning,
+				Check:     "claim.incident_within_term",
+				EntityID:  claim.ID,
+				Reference: claim.PolicyID,
+				Message: fmt.Sprintf("incident date %s is outside the policy term (%s to %s)",
+					claim.IncidentDate.Format("2006-01-02"), policy.StartDate.Format("2006-01-02"), policy.EndDate.Format("2006-01-02")),
+			})
+		}
+	}
+}
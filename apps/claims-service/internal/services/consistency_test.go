@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubPolicies answers policy lookups from a map keyed by policy ID
+type stubPolicies struct {
+	policies map[string]*clients.Policy
+	err      error
+}
+
+func (s *stubPolicies) GetPolicy(ctx context.Context, policyID, customerID string) (*clients.Policy, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	policy, ok := s.policies[policyID]
+	if !ok {
+		return nil, errors.New("policy not found")
+	}
+	if policy.CustomerID != customerID {
+		return nil, errors.New("unauthorized")
+	}
+	return policy, nil
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := start.AddDate(2, 0, 0)
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-ok", PolicyID: "pol-001", CustomerID: "cust-001"},
+		&models.Claim{ID: "claim-orphan", PolicyID: "pol-404", CustomerID: "cust-001"},
+		&models.Claim{ID: "claim-owner", PolicyID: "pol-001", CustomerID: "cust-002"},
+		&models.Claim{ID: "claim-late", PolicyID: "pol-001", CustomerID: "cust-001", IncidentDate: &late},
+		&models.Claim{ID: "claim-dup", PolicyID: "pol-001", CustomerID: "cust-001", DuplicateOf: "claim-gone", CatastropheID: "cat-gone"},
+	)
+	policies := &stubPolicies{policies: map[string]*clients.Policy{
+		"pol-001": {ID: "pol-001", CustomerID: "cust-001", StartDate: start, EndDate: start.AddDate(1, 0, 0)},
+	}}
+
+	report := NewConsistencyChecker(store, policies, logger).Check(context.Background())
+
+	if report.Checked != 5 {
+		t.Errorf("Checked mismatch: got %d, want 5", report.Checked)
+	}
+	want := map[string]string{
+		"claim-orphan": "claim.policy_exists",
+		"claim-owner":  "claim.policy_owner",
+		"claim-late":   "claim.incident_within_term",
+	}
+	found := map[string]bool{}
+	for _, issue := range report.Issues {
+		if check, ok := want[issue.EntityID]; ok && issue.Check == check {
+			found[issue.EntityID] = true
+		}
+		if issue.EntityID == "claim-ok" {
+			t.Errorf("Consistent claim reported: %+v", issue)
+		}
+	}
+	for id := range want {
+		if !found[id] {
+			t.Errorf("Missing issue for %s in %+v", id, report.Issues)
+		}
+	}
+	if report.Summary[models.SeverityError] != 2 || report.Summary[models.SeverityWarning] != 3 {
+		t.Errorf("Summary mismatch: %v", report.Summary)
+	}
+}
+
+func TestConsistencyCheckWithoutPolicyService(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-001", PolicyID: "pol-001", CustomerID: "cust-001"},
+		&models.Claim{ID: "claim-002", PolicyID: "pol-002", CustomerID: "cust-002"},
+	)
+
+	for name, policies := range map[string]PolicyLookup{
+		"not configured": nil,
+		"unreachable":    &stubPolicies{err: errors.New("policy-service request failed: connection refused")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			report := NewConsistencyChecker(store, policies, logger).Check(context.Background())
+			if len(report.Issues) != 1 || report.Issues[0].Severity != models.SeverityInfo {
+				t.Errorf("Expected a single skipped-check notice, got %+v", report.Issues)
+			}
+		})
+	}
+}
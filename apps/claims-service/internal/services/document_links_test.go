@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/sirupsen/logrus"
+)
+
+func TestDocumentsInTheBlobStoreHaveLinks(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	blobs := &blobstore.Local{
+		Dir:        t.TempDir(),
+		BaseURL:    "http://claims.test/blobs",
+		SigningKey: "test-key",
+		Limits:     blobstore.Limits{MaxSize: 1 << 10, ContentTypes: []string{"image/*", "application/pdf"}},
+	}
+	fake := repositorytest.NewFakeStore(&models.Claim{ID: "claim-001", ClaimNumber: "CLM-2024-000001", CustomerID: "cust-001", Status: "submitted"})
+	store := repository.WithBlobs(fake, blobs, 10*time.Minute)
+	claims := NewClaimService(store, newTestFlags(t, false, logger), nil, nil, "", events.NewBus(10, logger), nil, nil, logger)
+
+	// Uploaded before the blob store: content kept in the claims store
+	if err := fake.AddDocument(&models.ClaimDocument{ID: "doc-legacy", ClaimID: "claim-001", FileName: "estimate.pdf", ScanStatus: models.ScanClean}, []byte("%PDF-1.4")); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	photo, err := claims.UploadDocument("claim-001", "cust-001", "bumper.jpg", "image/jpeg", []byte("\xff\xd8\xff\xe0 jpeg"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if _, stored, _ := fake.GetDocument("claim-001", photo.ID); len(stored) != 0 {
+		t.Errorf("Expected the content in the blob store only, got %q in the claims store", stored)
+	}
+
+	if _, err := claims.GetDocumentLink("claim-001", photo.ID); err == nil || err.Error() != "document has not been scanned" {
+		t.Errorf("Expected no link before the scan, got %v", err)
+	}
+	scanned := *photo
+	scanned.ScanStatus = models.ScanClean
+	if err := store.UpdateDocument(&scanned); err != nil {
+		t.Fatalf("UpdateDocument failed: %v", err)
+	}
+
+	if _, content, err := claims.GetDocument("claim-001", photo.ID); err != nil || string(content) != "\xff\xd8\xff\xe0 jpeg" {
+		t.Errorf("Expected the photo to be read from the blob store, got %q, %v", content, err)
+	}
+	if _, content, err := claims.GetDocument("claim-001", "doc-legacy"); err != nil || string(content) != "%PDF-1.4" {
+		t.Errorf("Expected the legacy document to be read from the claims store, got %q, %v", content, err)
+	}
+
+	link, err := claims.GetDocumentLink("claim-001", photo.ID)
+	if err != nil {
+		t.Fatalf("GetDocumentLink failed: %v", err)
+	}
+	if link.DocumentID != photo.ID || time.Until(link.ExpiresAt) < 9*time.Minute {
+		t.Errorf("Unexpected link %+v", link)
+	}
+	recorder := httptest.NewRecorder()
+	blobs.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, link.URL, nil))
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "\xff\xd8\xff\xe0 jpeg" || recorder.Header().Get("Content-Disposition") != `attachment; filename=bumper.jpg` {
+		t.Errorf("Expected the link to download the photo, got %d %q %v", recorder.Code, recorder.Body.String(), recorder.Header())
+	}
+
+	if _, err := claims.GetDocumentLink("claim-001", "doc-legacy"); err == nil {
+		t.Error("Expected no link to a document uploaded before the blob store")
+	}
+	if _, err := claims.GetDocumentLink("claim-001", "doc-404"); err == nil || err.Error() != "document not found" {
+		t.Errorf("Expected an unknown document to be not found, got %v", err)
+	}
+
+	var limitErr *blobstore.LimitError
+	if _, err := claims.UploadDocument("claim-001", "cust-001", "notes.html", "text/html", []byte("<p>notes</p>")); !errors.As(err, &limitErr) {
+		t.Errorf("Expected a content type outside the limits to be refused, got %v", err)
+	}
+	if docs := fake.GetDocuments("claim-001"); len(docs) != 2 {
+		t.Errorf("Expected the refused upload not to be stored, got %d documents", len(docs))
+	}
+
+	unlinked, _, _ := newTestService(t, false, &models.Claim{ID: "claim-001", CustomerID: "cust-001", Status: "submitted"})
+	if _, err := unlinked.GetDocumentLink("claim-001", photo.ID); err == nil || err.Error() != "document links need a blob store" {
+		t.Errorf("Expected links to need a blob store, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// UploadDocument attaches a document to a claim. Without a content type
+// the type is detected from the content. The document cannot be downloaded
+// until ScanService has scanned it clean.
+func (s *ClaimService) UploadDocument(claimID, uploadedBy, fileName, contentType string, content []byte) (*models.ClaimDocument, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep only the base name; browsers on Windows may send the full path
+	fileName = filepath.Base(strings.ReplaceAll(strings.TrimSpace(fileName), `\`, "/"))
+	if fileName == "." || fileName == "/" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+	if len(content) > models.MaxDocumentSize {
+		return nil, fmt.Errorf("document exceeds the %d MiB limit", models.MaxDocumentSize>>20)
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(content)
+	}
+
+	doc := &models.ClaimDocument{
+		ID:          fmt.Sprintf("doc-%d", time.Now().UnixNano()),
+		ClaimID:     claim.ID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		UploadedBy:  uploadedBy,
+		UploadedAt:  time.Now(),
+		ScanStatus:  models.ScanPending,
+	}
+	if err := s.repo.AddDocument(doc, content); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":    claim.ID,
+		"documentId": doc.ID,
+		"fileName":   doc.FileName,
+		"size":       doc.Size,
+	}).Info("Claim document uploaded")
+
+	s.publish(events.Event{
+		Type:        events.DocumentUploaded,
+		ClaimID:     claim.ID,
+		ClaimNumber: claim.ClaimNumber,
+		PolicyID:    claim.PolicyID,
+		CustomerID:  claim.CustomerID,
+		NewStatus:   claim.Status,
+		Queue:       claim.Queue,
+		AssignedTo:  claim.AssignedTo,
+		DocumentID:  doc.ID,
+		FileName:    doc.FileName,
+		Timestamp:   doc.UploadedAt,
+	})
+
+	return doc, nil
+}
+
+// GetDocuments lists the documents attached to a claim in upload order
+func (s *ClaimService) GetDocuments(claimID string) ([]*models.ClaimDocument, error) {
+	if _, err := s.repo.GetClaimByID(claimID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetDocuments(claimID), nil
+}
+
+// GetDocument returns a claim document and its content. Documents not yet
+// scanned, and those quarantined by their scan, are refused.
+func (s *ClaimService) GetDocument(claimID, documentID string) (*models.ClaimDocument, []byte, error) {
+	doc, content, err := s.repo.GetDocument(claimID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := downloadable(doc); err != nil {
+		return nil, nil, err
+	}
+	return doc, content, nil
+}
+
+// GetDocumentLink signs a link that downloads a claim document's content
+// without credentials, for sharing with a repair shop or an expert. It
+// needs the document content in a blob store, and the same scan as
+// GetDocument.
+func (s *ClaimService) GetDocumentLink(claimID, documentID string) (*models.DocumentLink, error) {
+	linker, ok := s.repo.(repository.DocumentLinker)
+	if !ok {
+		return nil, fmt.Errorf("document links need a blob store")
+	}
+
+	var doc *models.ClaimDocument
+	for _, candidate := range s.repo.GetDocuments(claimID) {
+		if candidate.ID == documentID {
+			doc = candidate
+			break
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("document not found")
+	}
+	if err := downloadable(doc); err != nil {
+		return nil, err
+	}
+
+	url, expiresAt, err := linker.DocumentURL(claimID, documentID, doc.FileName)
+	if err != nil {
+		return nil, err
+	}
+	return &models.DocumentLink{DocumentID: doc.ID, URL: url, ExpiresAt: expiresAt}, nil
+}
+
+// downloadable refuses documents not yet scanned, and those quarantined by
+// their scan
+func downloadable(doc *models.ClaimDocument) error {
+	switch doc.ScanStatus {
+	case models.ScanClean:
+		return nil
+	case models.ScanFailed:
+		return fmt.Errorf("document is quarantined")
+	default:
+		return fmt.Errorf("document has not been scanned")
+	}
+}
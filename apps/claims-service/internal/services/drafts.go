@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/intake"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/taxonomy"
+	"github.com/sirupsen/logrus"
+)
+
+// ClaimFiler files confirmed drafts as claims. *ClaimService is the
+// production implementation, so confirmed drafts go through the same
+// validation, duplicate check and routing as claims filed through the API.
+type ClaimFiler interface {
+	CreateClaim(ctx context.Context, req *models.CreateClaimRequest) (*models.Claim, error)
+	CreateClaimWith(ctx context.Context, req *models.CreateClaimRequest, with func(claim *models.Claim, work repository.UnitOfWork)) (*models.Claim, error)
+	ClaimTypes() *taxonomy.Taxonomy
+	UploadDocument(claimID, uploadedBy, fileName, contentType string, content []byte) (*models.ClaimDocument, error)
+}
+
+var _ ClaimFiler = (*ClaimService)(nil)
+
+// DraftService queues claims that arrive by email as drafts until an agent
+// confirms them, filing the claim, or discards them
+type DraftService struct {
+	repo   repository.DraftStore
+	claims ClaimFiler
+	logger *logrus.Logger
+
+	// mu serializes reviews, so a draft confirmed by two agents at once is
+	// only filed once
+	mu sync.Mutex
+}
+
+// NewDraftService creates a new draft service
+func NewDraftService(repo repository.DraftStore, claims ClaimFiler, logger *logrus.Logger) *DraftService {
+	return &DraftService{
+		repo:   repo,
+		claims: claims,
+		logger: logger,
+	}
+}
+
+// IngestEmail parses a raw claim email into a draft. An email whose
+// Message-ID was already ingested returns the existing draft with created
+// false, so providers that redeliver on timeouts do not queue it twice.
+func (s *DraftService) IngestEmail(raw io.Reader) (draft *models.ClaimDraft, created bool, err error) {
+	email, err := intake.ParseEmail(raw, s.claims.ClaimTypes())
+	if err != nil {
+		return nil, false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if email.MessageID != "" {
+		for _, existing := range s.repo.GetDrafts() {
+			if existing.MessageID == email.MessageID {
+				s.logger.WithFields(logrus.Fields{
+					"draftId":   existing.ID,
+					"messageId": email.MessageID,
+				}).Info("Ignored redelivered claim email")
+				return existing, false, nil
+			}
+		}
+	}
+
+	now := time.Now()
+	receivedAt := email.Date
+	if receivedAt.IsZero() {
+		receivedAt = now
+	}
+	draft = &models.ClaimDraft{
+		ID:          fmt.Sprintf("draft-%d", now.UnixNano()),
+		Status:      models.DraftPending,
+		Source:      models.DraftSourceEmail,
+		From:        email.From,
+		Subject:     email.Subject,
+		MessageID:   email.MessageID,
+		ReceivedAt:  receivedAt,
+		Request:     email.Request,
+		Attachments: []models.DraftAttachment{},
+		Issues:      email.Issues,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	contents := make([][]byte, len(email.Attachments))
+	for i, attachment := range email.Attachments {
+		draft.Attachments = append(draft.Attachments, models.DraftAttachment{
+			ID:          fmt.Sprintf("att-%d-%d", now.UnixNano(), i),
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Size:        int64(len(attachment.Content)),
+		})
+		contents[i] = attachment.Content
+	}
+	if err := s.repo.CreateDraft(draft, contents); err != nil {
+		return nil, false, fmt.Errorf("failed to create draft: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"draftId":     draft.ID,
+		"from":        draft.From,
+		"policyId":    draft.Request.PolicyID,
+		"attachments": len(draft.Attachments),
+		"issues":      len(draft.Issues),
+	}).Info("Claim email queued as draft")
+
+	return draft, true, nil
+}
+
+// GetDrafts returns the drafts in a status, oldest first
+func (s *DraftService) GetDrafts(status string) ([]*models.ClaimDraft, error) {
+	if !models.ValidateDraftStatus(status) {
+		return nil, fmt.Errorf("invalid status: %s (must be draft, confirmed or discarded)", status)
+	}
+
+	drafts := []*models.ClaimDraft{}
+	for _, draft := range s.repo.GetDrafts() {
+		if draft.Status == status {
+			drafts = append(drafts, draft)
+		}
+	}
+	return drafts, nil
+}
+
+// GetDraft returns a draft
+func (s *DraftService) GetDraft(draftID string) (*models.ClaimDraft, error) {
+	return s.repo.GetDraft(draftID)
+}
+
+// GetDraftAttachment returns a draft's attachment and its content
+func (s *DraftService) GetDraftAttachment(draftID, attachmentID string) (*models.DraftAttachment, []byte, error) {
+	draft, err := s.repo.GetDraft(draftID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range draft.Attachments {
+		if draft.Attachments[i].ID == attachmentID {
+			content, err := s.repo.GetDraftAttachment(draftID, attachmentID)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &draft.Attachments[i], content, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("attachment not found")
+}
+
+// ConfirmDraft files a draft as a claim, applying the agent's corrections
+// first, and attaches its attachments to the claim as documents. The
+// claim is created as if submitted through the API, so a likely duplicate
+// fails with a *DuplicateClaimError unless req.Force is set. The claim and
+// the confirmed draft are stored together, so a draft is never left
+// pending with a claim already filed from it.
+func (s *DraftService) ConfirmDraft(ctx context.Context, draftID, reviewedBy string, req *models.ConfirmDraftRequest) (*models.ClaimDraft, *models.Claim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, err := s.pendingDraft(draftID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claimReq := draft.Request
+	applyCorrections(&claimReq, req)
+	if err := checkDraftRequest(&claimReq); err != nil {
+		return nil, nil, err
+	}
+
+	confirmed := *draft
+	claim, err := s.claims.CreateClaimWith(ctx, &claimReq, func(claim *models.Claim, work repository.UnitOfWork) {
+		now := time.Now()
+		confirmed.Status = models.DraftConfirmed
+		confirmed.Request = claimReq
+		confirmed.ClaimID = claim.ID
+		confirmed.ReviewedBy = reviewedBy
+		confirmed.ReviewedAt = &now
+		confirmed.UpdatedAt = now
+		work.UpdateDraft(&confirmed)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	draft = &confirmed
+
+	// The claim is filed; a document that cannot be attached is logged
+	// rather than failing the confirmation
+	for _, attachment := range draft.Attachments {
+		content, err := s.repo.GetDraftAttachment(draft.ID, attachment.ID)
+		if err == nil {
+			_, err = s.claims.UploadDocument(claim.ID, draft.From, attachment.FileName, attachment.ContentType, content)
+		}
+		if err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"draftId":      draft.ID,
+				"claimId":      claim.ID,
+				"attachmentId": attachment.ID,
+			}).Error("Failed to attach draft attachment to claim")
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"draftId":     draft.ID,
+		"claimId":     claim.ID,
+		"claimNumber": claim.ClaimNumber,
+		"reviewedBy":  reviewedBy,
+	}).Info("Claim draft confirmed")
+
+	return draft, claim, nil
+}
+
+// DiscardDraft drops a draft without filing a claim, such as spam or an
+// email that was not a claim
+func (s *DraftService) DiscardDraft(draftID, reviewedBy string, req *models.DiscardDraftRequest) (*models.ClaimDraft, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	draft, err := s.pendingDraft(draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	draft.Status = models.DraftDiscarded
+	draft.DiscardReason = req.Reason
+	draft.ReviewedBy = reviewedBy
+	draft.ReviewedAt = &now
+	draft.UpdatedAt = now
+	if err := s.repo.UpdateDraft(draft); err != nil {
+		return nil, fmt.Errorf("failed to update draft: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"draftId":    draft.ID,
+		"reviewedBy": reviewedBy,
+		"reason":     req.Reason,
+	}).Info("Claim draft discarded")
+
+	return draft, nil
+}
+
+// pendingDraft returns a draft that is still waiting for review
+func (s *DraftService) pendingDraft(draftID string) (*models.ClaimDraft, error) {
+	draft, err := s.repo.GetDraft(draftID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != models.DraftPending {
+		return nil, fmt.Errorf("draft is already %s", draft.Status)
+	}
+	return draft, nil
+}
+
+// applyCorrections replaces the parsed fields the agent set
+func applyCorrections(claimReq *models.CreateClaimRequest, req *models.ConfirmDraftRequest) {
+	if req == nil {
+		return
+	}
+	if req.PolicyID != nil {
+		claimReq.PolicyID = *req.PolicyID
+	}
+	if req.CustomerID != nil {
+		claimReq.CustomerID = *req.CustomerID
+	}
+	if req.Type != nil {
+		claimReq.Type = *req.Type
+	}
+	if req.SubType != nil {
+		claimReq.SubType = *req.SubType
+	}
+	if req.Amount != nil {
+		claimReq.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		claimReq.Description = *req.Description
+	}
+	if req.IncidentDate != nil {
+		claimReq.IncidentDate = req.IncidentDate
+	}
+	if req.LossLocation != nil {
+		claimReq.LossLocation = req.LossLocation
+	}
+	claimReq.Force = req.Force
+}
+
+// checkDraftRequest checks the fields POST /claims requires are present
+func checkDraftRequest(req *models.CreateClaimRequest) error {
+	switch {
+	case req.PolicyID == "":
+		return fmt.Errorf("policyId is required")
+	case req.CustomerID == "":
+		return fmt.Errorf("customerId is required")
+	case req.Type == "":
+		return fmt.Errorf("type is required")
+	case req.Description == "":
+		return fmt.Errorf("description is required")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+const draftEmail = "From: jane.doe@example.com\r\n" +
+	"Subject: New claim\r\n" +
+	"Message-ID: <msg-001@example.com>\r\n" +
+	"Content-Type: multipart/mixed; boundary=b\r\n" +
+	"\r\n" +
+	"--b\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Policy ID: pol-001\r\n" +
+	"Type: damage\r\n" +
+	"Amount: 2400\r\n" +
+	"Description: Hail damage to the roof\r\n" +
+	"--b\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"Content-Disposition: attachment; filename=estimate.txt\r\n" +
+	"\r\n" +
+	"Roof repair estimate: $2,400\r\n" +
+	"--b--\r\n"
+
+func newTestDraftService(t *testing.T) (*DraftService, *ClaimService) {
+	t.Helper()
+	claims, store, _ := newTestService(t, false)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewDraftService(store, claims, logger), claims
+}
+
+func TestConfirmDraftFilesClaim(t *testing.T) {
+	drafts, claims := newTestDraftService(t)
+
+	draft, created, err := drafts.IngestEmail(strings.NewReader(draftEmail))
+	if err != nil || !created {
+		t.Fatalf("IngestEmail: got %v, %v", created, err)
+	}
+	if draft.Status != models.DraftPending || draft.Request.PolicyID != "pol-001" || len(draft.Attachments) != 1 {
+		t.Fatalf("Unexpected draft: %+v", draft)
+	}
+	if len(draft.Issues) != 1 || draft.Issues[0] != "customerId is missing" {
+		t.Errorf("Expected the missing customer to be flagged, got %v", draft.Issues)
+	}
+
+	// A provider redelivering the same message gets the same draft back
+	again, created, err := drafts.IngestEmail(strings.NewReader(draftEmail))
+	if err != nil || created || again.ID != draft.ID {
+		t.Errorf("Redelivered email: got %s, %v, %v", again.ID, created, err)
+	}
+
+	// The customer has to be filled in before the draft can be filed
+	if _, _, err := drafts.ConfirmDraft(context.Background(), draft.ID, "adj-001", nil); err == nil || err.Error() != "customerId is required" {
+		t.Fatalf("Confirm without customer: got %v", err)
+	}
+
+	customerID := "cust-001"
+	confirmed, claim, err := drafts.ConfirmDraft(context.Background(), draft.ID, "adj-001", &models.ConfirmDraftRequest{CustomerID: &customerID})
+	if err != nil {
+		t.Fatalf("ConfirmDraft failed: %v", err)
+	}
+	if confirmed.Status != models.DraftConfirmed || confirmed.ClaimID != claim.ID || confirmed.ReviewedBy != "adj-001" {
+		t.Errorf("Unexpected confirmed draft: %+v", confirmed)
+	}
+	if claim.CustomerID != "cust-001" || claim.Amount != 2400 || claim.Type != "damage" {
+		t.Errorf("Unexpected claim: %+v", claim)
+	}
+
+	docs, err := claims.GetDocuments(claim.ID)
+	if err != nil || len(docs) != 1 || docs[0].FileName != "estimate.txt" || docs[0].UploadedBy != "jane.doe@example.com" {
+		t.Errorf("Expected the attachment filed as a document, got %+v, %v", docs, err)
+	}
+
+	if _, _, err := drafts.ConfirmDraft(context.Background(), draft.ID, "adj-002", &models.ConfirmDraftRequest{CustomerID: &customerID}); err == nil || err.Error() != "draft is already confirmed" {
+		t.Errorf("Second confirmation: got %v", err)
+	}
+	pending, err := drafts.GetDrafts(models.DraftPending)
+	if err != nil || len(pending) != 0 {
+		t.Errorf("Expected an empty queue, got %d, %v", len(pending), err)
+	}
+}
+
+func TestConfirmDraftKeepsDuplicateCheck(t *testing.T) {
+	drafts, claims := newTestDraftService(t)
+
+	if _, err := claims.CreateClaim(context.Background(), &models.CreateClaimRequest{
+		PolicyID: "pol-001", CustomerID: "cust-001", Type: "damage", Amount: 2400, Description: "Hail damage to the roof",
+	}); err != nil {
+		t.Fatalf("CreateClaim failed: %v", err)
+	}
+	draft, _, err := drafts.IngestEmail(strings.NewReader(draftEmail))
+	if err != nil {
+		t.Fatalf("IngestEmail failed: %v", err)
+	}
+
+	customerID := "cust-001"
+	_, _, err = drafts.ConfirmDraft(context.Background(), draft.ID, "adj-001", &models.ConfirmDraftRequest{CustomerID: &customerID})
+	var duplicate *DuplicateClaimError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("Expected a duplicate claim error, got %v", err)
+	}
+	if still, _ := drafts.GetDraft(draft.ID); still.Status != models.DraftPending {
+		t.Errorf("Expected the draft to stay queued, got %s", still.Status)
+	}
+
+	if _, _, err := drafts.ConfirmDraft(context.Background(), draft.ID, "adj-001", &models.ConfirmDraftRequest{CustomerID: &customerID, Force: true}); err != nil {
+		t.Errorf("Forced confirmation failed: %v", err)
+	}
+}
+
+func TestDiscardDraft(t *testing.T) {
+	drafts, _ := newTestDraftService(t)
+
+	draft, _, err := drafts.IngestEmail(strings.NewReader(draftEmail))
+	if err != nil {
+		t.Fatalf("IngestEmail failed: %v", err)
+	}
+
+	discarded, err := drafts.DiscardDraft(draft.ID, "adj-001", &models.DiscardDraftRequest{Reason: "Sent to the wrong insurer"})
+	if err != nil || discarded.Status != models.DraftDiscarded || discarded.DiscardReason != "Sent to the wrong insurer" {
+		t.Fatalf("DiscardDraft: got %+v, %v", discarded, err)
+	}
+	if _, _, err := drafts.ConfirmDraft(context.Background(), draft.ID, "adj-001", nil); err == nil || err.Error() != "draft is already discarded" {
+		t.Errorf("Confirm after discard: got %v", err)
+	}
+
+	listed, err := drafts.GetDrafts(models.DraftDiscarded)
+	if err != nil || len(listed) != 1 || listed[0].ID != draft.ID {
+		t.Errorf("Expected the draft listed as discarded, got %+v, %v", listed, err)
+	}
+	if _, err := drafts.GetDrafts("pending"); err == nil {
+		t.Error("Expected an invalid status to be rejected")
+	}
+	if _, err := drafts.DiscardDraft("draft-404", "adj-001", &models.DiscardDraftRequest{}); err == nil || err.Error() != "draft not found" {
+		t.Errorf("Unknown draft: got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+const (
+	// DuplicateWindow is how far back to look for an earlier claim for the same incident
+	DuplicateWindow = 14 * 24 * time.Hour
+
+	// DuplicateAmountTolerance is the relative difference under which two amounts count as similar
+	DuplicateAmountTolerance = 0.10
+
+	// DuplicateDescriptionOverlap is the share of description tokens two claims must have in common
+	DuplicateDescriptionOverlap = 0.5
+)
+
+// DuplicateClaimError is returned by CreateClaim when the submission looks
+// like a repeat of an existing claim. Resubmit with Force to file it anyway.
+type DuplicateClaimError struct {
+	Existing *models.Claim
+}
+
+func (e *DuplicateClaimError) Error() string {
+	return fmt.Sprintf("likely duplicate of claim %s (%s)", e.Existing.ID, e.Existing.ClaimNumber)
+}
+
+// findDuplicate returns the most recent open or decided claim on the same
+// policy that matches the request by type, amount and description, or nil.
+// Rejected claims are ignored so a customer can resubmit with better evidence.
+func (s *ClaimService) findDuplicate(req *models.CreateClaimRequest, now time.Time) *models.Claim {
+	candidates := s.repo.GetClaimsByFilter(&models.ClaimFilters{PolicyID: req.PolicyID, Type: req.Type})
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].SubmittedDate.After(candidates[j].SubmittedDate)
+	})
+
+	tokens := descriptionTokens(req.Description)
+	for _, existing := range candidates {
+		if existing.Status == "rejected" {
+			continue
+		}
+		if now.Sub(existing.SubmittedDate) > DuplicateWindow {
+			continue
+		}
+		if !similarAmount(existing.Amount, req.Amount) {
+			continue
+		}
+		if tokenOverlap(tokens, descriptionTokens(existing.Description)) < DuplicateDescriptionOverlap {
+			continue
+		}
+		return existing
+	}
+	return nil
+}
+
+func similarAmount(a, b float64) bool {
+	larger := math.Max(a, b)
+	if larger <= 0 {
+		return false
+	}
+	return math.Abs(a-b)/larger <= DuplicateAmountTolerance
+}
+
+// descriptionStopWords carry no information about the incident
+var descriptionStopWords = map[string]bool{
+	"the": true, "and": true, "was": true, "were": true, "for": true, "with": true,
+	"from": true, "into": true, "onto": true, "that": true, "this": true, "had": true,
+	"has": true, "have": true, "but": true, "our": true, "after": true, "while": true,
+}
+
+// descriptionTokens normalizes a description into a set of lowercase words,
+// dropping punctuation, short words and stop words
+func descriptionTokens(description string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make(map[string]bool, len(words))
+	for _, word := range words {
+		if len(word) < 3 || descriptionStopWords[word] {
+			continue
+		}
+		tokens[word] = true
+	}
+	return tokens
+}
+
+// tokenOverlap is the Jaccard similarity of two token sets
+func tokenOverlap(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/estimate"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// estimateTimeout bounds one request to the damage estimator
+const estimateTimeout = 30 * time.Second
+
+// maxEstimateAttempts is how many times an estimate is stored against a
+// claim that keeps changing under it
+const maxEstimateAttempts = 3
+
+// EstimateService asks the damage estimator to price a claim each time a
+// photo is attached to it, in the background, and stores the estimate on
+// the claim for adjusters to compare with the claimed amount
+type EstimateService struct {
+	repo      repository.ClaimStore
+	estimator estimate.Estimator
+	bus       *events.Bus
+	logger    *logrus.Logger
+}
+
+// NewEstimateService creates a new damage estimate service
+func NewEstimateService(repo repository.ClaimStore, estimator estimate.Estimator, bus *events.Bus, logger *logrus.Logger) *EstimateService {
+	return &EstimateService{
+		repo:      repo,
+		estimator: estimator,
+		bus:       bus,
+		logger:    logger,
+	}
+}
+
+// Run estimates claims as photos are uploaded until ctx is done. Uploads
+// are estimated one at a time, in the order they were made; an estimate
+// that fails is logged and the claim keeps its previous estimate.
+func (s *EstimateService) Run(ctx context.Context) {
+	_, sub := s.bus.Subscribe(func(evt events.Event) bool {
+		return evt.Type == events.DocumentUploaded
+	}, 0, 256)
+	defer sub.Close()
+
+	s.logger.WithField("estimator", s.estimator.Name()).Info("Damage estimates started")
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Damage estimates stopped")
+			return
+		case evt := <-sub.C:
+			s.estimateUpload(ctx, evt)
+		}
+	}
+}
+
+// estimateUpload estimates the claim of an uploaded document, if the
+// document is a photo
+func (s *EstimateService) estimateUpload(ctx context.Context, evt events.Event) {
+	fields := logrus.Fields{
+		"claimId":    evt.ClaimID,
+		"documentId": evt.DocumentID,
+	}
+	doc, _, err := s.repo.GetDocument(evt.ClaimID, evt.DocumentID)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("Uploaded document not found for damage estimate")
+		return
+	}
+	if !estimate.IsImage(doc.ContentType) {
+		return
+	}
+
+	result, err := s.Estimate(ctx, evt.ClaimID)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("Failed to estimate damage")
+		return
+	}
+	s.logger.WithFields(fields).WithFields(logrus.Fields{
+		"estimate":   result.Amount,
+		"confidence": result.Confidence,
+		"claimed":    result.ClaimedAmount,
+	}).Info("Damage estimated")
+}
+
+// Estimate prices a claim from every photo attached to it and stores the
+// estimate on the claim, replacing any earlier one
+func (s *EstimateService) Estimate(ctx context.Context, claimID string) (*models.DamageEstimate, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &estimate.Request{Claim: claim}
+	for _, doc := range s.repo.GetDocuments(claimID) {
+		// Photos quarantined by their malware scan are never read
+		if !estimate.IsImage(doc.ContentType) || doc.ScanStatus == models.ScanFailed {
+			continue
+		}
+		_, content, err := s.repo.GetDocument(claimID, doc.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read photo %s: %w", doc.ID, err)
+		}
+		req.Images = append(req.Images, estimate.Image{
+			DocumentID:  doc.ID,
+			FileName:    doc.FileName,
+			ContentType: doc.ContentType,
+			Content:     content,
+		})
+	}
+	if len(req.Images) == 0 {
+		return nil, fmt.Errorf("claim has no photos")
+	}
+
+	estimateCtx, cancel := context.WithTimeout(ctx, estimateTimeout)
+	defer cancel()
+	result, err := s.estimator.Estimate(estimateCtx, req)
+	if err != nil {
+		return nil, fmt.Errorf("%s estimator failed: %w", s.estimator.Name(), err)
+	}
+
+	damage := &models.DamageEstimate{
+		Amount:      result.Amount,
+		Confidence:  result.Confidence,
+		Estimator:   s.estimator.Name(),
+		DocumentIDs: make([]string, len(req.Images)),
+		EstimatedAt: time.Now(),
+	}
+	for i, image := range req.Images {
+		damage.DocumentIDs[i] = image.DocumentID
+	}
+
+	// The claim may have changed while the estimator ran, by an adjuster or
+	// another upload; the estimate is stored on the claim as it is now
+	for attempt := 1; ; attempt++ {
+		damage.ClaimedAmount = claim.Amount
+		claim.DamageEstimate = damage
+		err := s.repo.UpdateClaim(claim)
+		if err == nil {
+			return damage, nil
+		}
+		if err.Error() != "claim version conflict" || attempt == maxEstimateAttempts {
+			return nil, fmt.Errorf("failed to save damage estimate: %w", err)
+		}
+		if claim, err = s.repo.GetClaimByID(claimID); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/estimate"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// stubEstimator records the photos it was asked to price
+type stubEstimator struct {
+	requests chan *estimate.Request
+	result   *estimate.Result
+	err      error
+}
+
+func (e *stubEstimator) Name() string { return "test" }
+
+func (e *stubEstimator) Estimate(ctx context.Context, req *estimate.Request) (*estimate.Result, error) {
+	e.requests <- req
+	return e.result, e.err
+}
+
+func TestEstimatesFollowPhotoUploads(t *testing.T) {
+	claims, store, bus := newTestService(t, false, &models.Claim{ID: "claim-001", CustomerID: "cust-001", Amount: 1500, Status: "submitted", Version: 1})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	estimator := &stubEstimator{requests: make(chan *estimate.Request, 4), result: &estimate.Result{Amount: 1200, Confidence: 0.7}}
+	service := NewEstimateService(store, estimator, bus, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	// Wait for Run to subscribe before uploading
+	for bus.SubscriberCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Documents other than photos are not estimated
+	if _, err := claims.UploadDocument("claim-001", "cust-001", "invoice.pdf", "application/pdf", []byte("%PDF-1.4")); err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	photo, err := claims.UploadDocument("claim-001", "cust-001", "bumper.jpg", "", []byte("\xff\xd8\xff\xe0 jpeg"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+
+	select {
+	case req := <-estimator.requests:
+		if req.Claim.ID != "claim-001" || len(req.Images) != 1 || req.Images[0].DocumentID != photo.ID || req.Images[0].ContentType != "image/jpeg" {
+			t.Errorf("Unexpected estimate request %+v", req)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The photo was not estimated")
+	}
+
+	// The estimate is stored once the estimator answers
+	var claim *models.Claim
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if claim, _ = store.GetClaimByID("claim-001"); claim.DamageEstimate != nil {
+			break
+		}
+	}
+	got := claim.DamageEstimate
+	if got == nil || got.Amount != 1200 || got.Confidence != 0.7 || got.ClaimedAmount != 1500 || got.Estimator != "test" ||
+		len(got.DocumentIDs) != 1 || got.DocumentIDs[0] != photo.ID || claim.Version != 2 {
+		t.Errorf("Unexpected estimate %+v on claim version %d", got, claim.Version)
+	}
+	if len(estimator.requests) != 0 {
+		t.Errorf("Only the photo should have been estimated")
+	}
+}
+
+func TestEstimateKeepsThePreviousEstimateOnFailure(t *testing.T) {
+	previous := &models.DamageEstimate{Amount: 900, Estimator: "test"}
+	claims, store, bus := newTestService(t, false, &models.Claim{ID: "claim-001", Amount: 1500, Version: 1, DamageEstimate: previous})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	estimator := &stubEstimator{requests: make(chan *estimate.Request, 1), err: errors.New("model unavailable")}
+	service := NewEstimateService(store, estimator, bus, logger)
+
+	if _, err := service.Estimate(context.Background(), "claim-001"); err == nil || err.Error() != "claim has no photos" {
+		t.Errorf("Expected a claim without photos to be refused, got %v", err)
+	}
+	if _, err := claims.UploadDocument("claim-001", "cust-001", "bumper.png", "image/png", []byte("\x89PNG")); err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if _, err := service.Estimate(context.Background(), "claim-001"); err == nil || err.Error() != "test estimator failed: model unavailable" {
+		t.Errorf("Expected the estimator's error, got %v", err)
+	}
+	if claim, _ := store.GetClaimByID("claim-001"); claim.DamageEstimate != previous || claim.Version != 1 {
+		t.Errorf("A failed estimate should leave the claim alone: %+v", claim)
+	}
+	if _, err := service.Estimate(context.Background(), "claim-404"); err == nil || err.Error() != "claim not found" {
+		t.Errorf("Expected an unknown claim, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// FNOLStepError is returned when a step's section fails validation. It
+// lists every problem found, so a form can show them together.
+type FNOLStepError struct {
+	Step     int
+	Problems []string
+}
+
+func (e *FNOLStepError) Error() string {
+	return fmt.Sprintf("step %d is invalid: %s", e.Step, strings.Join(e.Problems, "; "))
+}
+
+// FNOLService runs guided first notice of loss intake: a report is started
+// with what is known, filled in one step at a time with each step checked
+// as it is saved, and submitted as a claim once every step is complete.
+// Customers fill in their own reports; staff may report for any customer.
+type FNOLService struct {
+	repo   repository.FNOLStore
+	claims ClaimFiler
+	logger *logrus.Logger
+
+	// mu serializes changes to reports, so a report submitted twice at once
+	// is only filed once
+	mu sync.Mutex
+}
+
+// NewFNOLService creates a new FNOL service
+func NewFNOLService(repo repository.FNOLStore, claims ClaimFiler, logger *logrus.Logger) *FNOLService {
+	return &FNOLService{
+		repo:   repo,
+		claims: claims,
+		logger: logger,
+	}
+}
+
+// StartFNOL starts a report. The incident details given are kept as they
+// are; they are checked when step 1 is filled.
+func (s *FNOLService) StartFNOL(reporter models.FNOLReporter, req *models.StartFNOLRequest) (*models.FNOL, error) {
+	customerID := req.CustomerID
+	if !reporter.IsStaff() {
+		if customerID != "" && customerID != reporter.ID {
+			return nil, fmt.Errorf("unauthorized")
+		}
+		customerID = reporter.ID
+	}
+	if customerID == "" {
+		return nil, fmt.Errorf("customerId is required")
+	}
+
+	now := time.Now()
+	report := &models.FNOL{
+		ID:             fmt.Sprintf("fnol-%d", now.UnixNano()),
+		CustomerID:     customerID,
+		Status:         models.FNOLInProgress,
+		Incident:       req.FNOLIncident,
+		CompletedSteps: []int{},
+		NextStep:       models.FNOLStepIncident,
+		StartedBy:      reporter.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.repo.CreateFNOL(report); err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"reportId":   report.ID,
+		"customerId": customerID,
+		"startedBy":  reporter.ID,
+	}).Info("FNOL report started")
+
+	return report, nil
+}
+
+// GetFNOL returns a report the reporter may access
+func (s *FNOLService) GetFNOL(reportID string, reporter models.FNOLReporter) (*models.FNOL, error) {
+	return s.reportFor(reportID, reporter)
+}
+
+// GetFNOLs returns the reports of a customer, oldest first. Customers only
+// see their own reports.
+func (s *FNOLService) GetFNOLs(customerID string, reporter models.FNOLReporter) ([]*models.FNOL, error) {
+	if !reporter.IsStaff() {
+		if customerID != "" && customerID != reporter.ID {
+			return nil, fmt.Errorf("unauthorized")
+		}
+		customerID = reporter.ID
+	}
+	if customerID == "" {
+		return nil, fmt.Errorf("customerId is required")
+	}
+
+	reports := []*models.FNOL{}
+	for _, report := range s.repo.GetFNOLs() {
+		if report.CustomerID == customerID {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+// SaveIncident fills step 1. The policy must belong to the customer and
+// cover the incident date when this service knows the policy.
+func (s *FNOLService) SaveIncident(reportID string, reporter models.FNOLReporter, incident *models.FNOLIncident) (*models.FNOL, error) {
+	return s.saveStep(reportID, reporter, models.FNOLStepIncident, func(report *models.FNOL) []string {
+		problems := s.checkIncident(report.CustomerID, incident, time.Now())
+		if len(problems) == 0 {
+			report.Incident = *incident
+		}
+		return problems
+	})
+}
+
+// SaveParties fills step 2
+func (s *FNOLService) SaveParties(reportID string, reporter models.FNOLReporter, parties *models.FNOLParties) (*models.FNOL, error) {
+	return s.saveStep(reportID, reporter, models.FNOLStepParties, func(report *models.FNOL) []string {
+		problems := checkParties(parties)
+		if len(problems) == 0 {
+			if parties.Parties == nil {
+				parties.Parties = []models.FNOLParty{}
+			}
+			report.Parties = parties
+		}
+		return problems
+	})
+}
+
+// SaveDamage fills step 3. Without an estimated amount the items' costs
+// are added up.
+func (s *FNOLService) SaveDamage(reportID string, reporter models.FNOLReporter, damage *models.FNOLDamage) (*models.FNOL, error) {
+	return s.saveStep(reportID, reporter, models.FNOLStepDamage, func(report *models.FNOL) []string {
+		problems := checkDamage(damage)
+		if len(problems) == 0 {
+			report.Damage = damage
+		}
+		return problems
+	})
+}
+
+// SubmitFNOL files a complete report as a claim. The claim is created as
+// if submitted through POST /claims, so a likely duplicate fails with a
+// *DuplicateClaimError unless force is set. The claim and the submitted
+// report are stored together, so a report cannot be filed twice.
+func (s *FNOLService) SubmitFNOL(ctx context.Context, reportID string, reporter models.FNOLReporter, force bool) (*models.FNOL, *models.Claim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report, err := s.openReport(reportID, reporter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if report.NextStep != 0 {
+		return nil, nil, fmt.Errorf("complete step %d first", report.NextStep)
+	}
+
+	submitted := *report
+	claim, err := s.claims.CreateClaimWith(ctx, &models.CreateClaimRequest{
+		PolicyID:     report.Incident.PolicyID,
+		CustomerID:   report.CustomerID,
+		Type:         report.Incident.Type,
+		SubType:      report.Incident.SubType,
+		Amount:       report.Damage.EstimatedAmount,
+		Description:  report.Incident.Description,
+		Force:        force,
+		IncidentDate: report.Incident.IncidentDate,
+		LossLocation: report.Incident.LossLocation,
+	}, func(claim *models.Claim, work repository.UnitOfWork) {
+		now := time.Now()
+		submitted.Status = models.FNOLSubmitted
+		submitted.ClaimID = claim.ID
+		submitted.SubmittedAt = &now
+		submitted.UpdatedAt = now
+		work.UpdateFNOL(&submitted)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	report = &submitted
+
+	s.logger.WithFields(logrus.Fields{
+		"reportId":    report.ID,
+		"claimId":     claim.ID,
+		"claimNumber": claim.ClaimNumber,
+		"parties":     len(report.Parties.Parties),
+		"injuries":    report.Damage.Injuries,
+	}).Info("FNOL report submitted")
+
+	return report, claim, nil
+}
+
+// saveStep checks and applies one step's section. Steps are filled in
+// order, so a step can only be saved once the steps before it are
+// complete; completed steps can be saved again to correct them.
+func (s *FNOLService) saveStep(reportID string, reporter models.FNOLReporter, step int, apply func(report *models.FNOL) []string) (*models.FNOL, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report, err := s.openReport(reportID, reporter)
+	if err != nil {
+		return nil, err
+	}
+	for earlier := models.FNOLStepIncident; earlier < step; earlier++ {
+		if !stepCompleted(report, earlier) {
+			return nil, fmt.Errorf("complete step %d first", earlier)
+		}
+	}
+
+	if problems := apply(report); len(problems) > 0 {
+		return nil, &FNOLStepError{Step: step, Problems: problems}
+	}
+	if !stepCompleted(report, step) {
+		report.CompletedSteps = append(report.CompletedSteps, step)
+		sort.Ints(report.CompletedSteps)
+	}
+	report.NextStep = nextStep(report)
+	report.UpdatedAt = time.Now()
+	if err := s.repo.UpdateFNOL(report); err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"reportId": report.ID,
+		"step":     step,
+		"nextStep": report.NextStep,
+	}).Info("FNOL step saved")
+
+	return report, nil
+}
+
+// openReport returns a report the reporter may still change
+func (s *FNOLService) openReport(reportID string, reporter models.FNOLReporter) (*models.FNOL, error) {
+	report, err := s.reportFor(reportID, reporter)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == models.FNOLSubmitted {
+		return nil, fmt.Errorf("report is already submitted")
+	}
+	return report, nil
+}
+
+// reportFor returns a report if the reporter may access it
+func (s *FNOLService) reportFor(reportID string, reporter models.FNOLReporter) (*models.FNOL, error) {
+	report, err := s.repo.GetFNOL(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if !reporter.IsStaff() && report.CustomerID != reporter.ID {
+		s.logger.WithFields(logrus.Fields{
+			"reportId":   reportID,
+			"customerId": reporter.ID,
+			"ownerId":    report.CustomerID,
+		}).Warn("Unauthorized FNOL access attempt")
+		return nil, fmt.Errorf("unauthorized")
+	}
+	return report, nil
+}
+
+// checkIncident validates step 1 for a customer's report
+func (s *FNOLService) checkIncident(customerID string, incident *models.FNOLIncident, now time.Time) []string {
+	var policy *repository.Policy
+	policyType := ""
+	if incident.PolicyID != "" {
+		if found, err := s.repo.GetPolicyByID(incident.PolicyID); err == nil {
+			policy, policyType = found, found.Type
+		}
+	}
+
+	var problems []string
+	if incident.PolicyID == "" {
+		problems = append(problems, "policyId is required")
+	}
+	if incident.Type == "" {
+		problems = append(problems, "type is required")
+	} else if err := s.claims.ClaimTypes().Check(policyType, incident.Type, incident.SubType); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if incident.IncidentDate == nil {
+		problems = append(problems, "incidentDate is required")
+	} else if incident.IncidentDate.After(now) {
+		problems = append(problems, "incidentDate cannot be in the future")
+	}
+	if loc := incident.LossLocation; loc == nil {
+		problems = append(problems, "lossLocation is required")
+	} else {
+		if loc.City == "" {
+			problems = append(problems, "lossLocation.city is required")
+		}
+		if loc.Country == "" {
+			problems = append(problems, "lossLocation.country is required")
+		}
+	}
+	if strings.TrimSpace(incident.Description) == "" {
+		problems = append(problems, "description is required")
+	}
+
+	if policy != nil {
+		if policy.CustomerID != customerID {
+			problems = append(problems, fmt.Sprintf("policy %s does not belong to the customer", incident.PolicyID))
+		} else if incident.IncidentDate != nil && !policy.Covers(*incident.IncidentDate) {
+			problems = append(problems, fmt.Sprintf("incidentDate %s is outside the policy term (%s to %s)",
+				incident.IncidentDate.Format("2006-01-02"), policy.StartDate.Format("2006-01-02"), policy.EndDate.Format("2006-01-02")))
+		}
+	}
+	return problems
+}
+
+// checkParties validates step 2
+func checkParties(parties *models.FNOLParties) []string {
+	var problems []string
+	if len(parties.Parties) > models.MaxFNOLParties {
+		problems = append(problems, fmt.Sprintf("at most %d parties can be listed", models.MaxFNOLParties))
+	}
+	for i, party := range parties.Parties {
+		if !models.ValidatePartyRole(party.Role) {
+			problems = append(problems, fmt.Sprintf("parties[%d]: invalid role %q", i, party.Role))
+		}
+		if strings.TrimSpace(party.Name) == "" {
+			problems = append(problems, fmt.Sprintf("parties[%d]: name is required", i))
+		}
+		if party.Email != "" && !strings.Contains(party.Email, "@") {
+			problems = append(problems, fmt.Sprintf("parties[%d]: invalid email %q", i, party.Email))
+		}
+	}
+	return problems
+}
+
+// checkDamage validates step 3, filling in the estimated amount from
+// the items when it is not given
+func checkDamage(damage *models.FNOLDamage) []string {
+	var problems []string
+	total := 0.0
+	for i, item := range damage.Items {
+		if strings.TrimSpace(item.Description) == "" {
+			problems = append(problems, fmt.Sprintf("items[%d]: description is required", i))
+		}
+		if item.EstimatedCost < 0 {
+			problems = append(problems, fmt.Sprintf("items[%d]: estimatedCost cannot be negative", i))
+		}
+		total += item.EstimatedCost
+	}
+	switch {
+	case damage.EstimatedAmount < 0:
+		problems = append(problems, "estimatedAmount cannot be negative")
+	case damage.EstimatedAmount == 0 && total <= 0:
+		problems = append(problems, "estimatedAmount or item costs are required")
+	case damage.EstimatedAmount == 0 && len(problems) == 0:
+		damage.EstimatedAmount = total
+	}
+	return problems
+}
+
+// stepCompleted reports whether a report's step passed validation
+func stepCompleted(report *models.FNOL, step int) bool {
+	for _, completed := range report.CompletedSteps {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// nextStep returns the first step still to fill, or 0
+func nextStep(report *models.FNOL) int {
+	for step := models.FNOLStepIncident; step <= models.FNOLSteps; step++ {
+		if !stepCompleted(report, step) {
+			return step
+		}
+	}
+	return 0
+}
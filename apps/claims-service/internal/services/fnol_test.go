@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestFNOLService(t *testing.T) *FNOLService {
+	t.Helper()
+	claims, store, _ := newTestService(t, false)
+	store.AddPolicy(&repository.Policy{
+		ID:         "pol-001",
+		CustomerID: "cust-001",
+		Type:       "auto",
+		StartDate:  time.Now().AddDate(-1, 0, 0),
+		EndDate:    time.Now().AddDate(1, 0, 0),
+	})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewFNOLService(store, claims, logger)
+}
+
+func TestFNOLStepsAndSubmit(t *testing.T) {
+	service := newTestFNOLService(t)
+	customer := models.FNOLReporter{ID: "cust-001"}
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	report, err := service.StartFNOL(customer, &models.StartFNOLRequest{FNOLIncident: models.FNOLIncident{PolicyID: "pol-001"}})
+	if err != nil {
+		t.Fatalf("StartFNOL failed: %v", err)
+	}
+	if report.CustomerID != "cust-001" || report.NextStep != models.FNOLStepIncident || report.Incident.PolicyID != "pol-001" {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	// Steps are filled in order
+	if _, err := service.SaveDamage(report.ID, customer, &models.FNOLDamage{EstimatedAmount: 800}); err == nil || err.Error() != "complete step 1 first" {
+		t.Errorf("Damage before incident: got %v", err)
+	}
+
+	// Every problem with a step is reported at once
+	_, err = service.SaveIncident(report.ID, customer, &models.FNOLIncident{PolicyID: "pol-001", Type: "flood"})
+	var invalid *FNOLStepError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected a step error, got %v", err)
+	}
+	expected := []string{
+		"invalid claim type: flood for auto policies (must be accident, theft, or damage)",
+		"incidentDate is required",
+		"lossLocation is required",
+		"description is required",
+	}
+	if invalid.Step != models.FNOLStepIncident || !reflect.DeepEqual(invalid.Problems, expected) {
+		t.Errorf("Unexpected problems for step %d:\n got %q\nwant %q", invalid.Step, invalid.Problems, expected)
+	}
+
+	incident := &models.FNOLIncident{
+		PolicyID:     "pol-001",
+		Type:         "accident",
+		IncidentDate: &yesterday,
+		LossLocation: &models.LossLocation{City: "Springfield", Country: "US"},
+		Description:  "Rear-ended at a stop light",
+	}
+	if report, err = service.SaveIncident(report.ID, customer, incident); err != nil || report.NextStep != models.FNOLStepParties {
+		t.Fatalf("SaveIncident: got %+v, %v", report, err)
+	}
+
+	if _, _, err := service.SubmitFNOL(context.Background(), report.ID, customer, false); err == nil || err.Error() != "complete step 2 first" {
+		t.Errorf("Submit before every step: got %v", err)
+	}
+
+	parties := &models.FNOLParties{Parties: []models.FNOLParty{{Role: models.PartyOtherDriver, Name: "John Roe", Insurer: "Acme Mutual"}}}
+	if report, err = service.SaveParties(report.ID, customer, parties); err != nil || report.NextStep != models.FNOLStepDamage {
+		t.Fatalf("SaveParties: got %+v, %v", report, err)
+	}
+	damage := &models.FNOLDamage{Items: []models.FNOLDamageItem{
+		{Description: "Rear bumper", EstimatedCost: 650},
+		{Description: "Tail lights", EstimatedCost: 150},
+	}}
+	if report, err = service.SaveDamage(report.ID, customer, damage); err != nil || report.NextStep != 0 {
+		t.Fatalf("SaveDamage: got %+v, %v", report, err)
+	}
+	if report.Damage.EstimatedAmount != 800 {
+		t.Errorf("Expected the item costs added up to 800, got %v", report.Damage.EstimatedAmount)
+	}
+	if !reflect.DeepEqual(report.CompletedSteps, []int{1, 2, 3}) {
+		t.Errorf("Unexpected completed steps %v", report.CompletedSteps)
+	}
+
+	submitted, claim, err := service.SubmitFNOL(context.Background(), report.ID, customer, false)
+	if err != nil {
+		t.Fatalf("SubmitFNOL failed: %v", err)
+	}
+	if submitted.Status != models.FNOLSubmitted || submitted.ClaimID != claim.ID {
+		t.Errorf("Unexpected submitted report: %+v", submitted)
+	}
+	if claim.PolicyID != "pol-001" || claim.CustomerID != "cust-001" || claim.Amount != 800 || claim.LossLocation.City != "Springfield" {
+		t.Errorf("Unexpected claim: %+v", claim)
+	}
+
+	if _, err := service.SaveDamage(report.ID, customer, damage); err == nil || err.Error() != "report is already submitted" {
+		t.Errorf("Change after submit: got %v", err)
+	}
+	if _, _, err := service.SubmitFNOL(context.Background(), report.ID, customer, false); err == nil || err.Error() != "report is already submitted" {
+		t.Errorf("Second submit: got %v", err)
+	}
+}
+
+func TestFNOLChecksOwnership(t *testing.T) {
+	service := newTestFNOLService(t)
+	customer := models.FNOLReporter{ID: "cust-002"}
+	agent := models.FNOLReporter{ID: "adj-001", Role: "adjuster"}
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	if _, err := service.StartFNOL(customer, &models.StartFNOLRequest{CustomerID: "cust-001"}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Customer reporting for someone else: got %v", err)
+	}
+	if _, err := service.StartFNOL(agent, &models.StartFNOLRequest{}); err == nil || err.Error() != "customerId is required" {
+		t.Errorf("Agent without a customer: got %v", err)
+	}
+
+	report, err := service.StartFNOL(customer, &models.StartFNOLRequest{})
+	if err != nil {
+		t.Fatalf("StartFNOL failed: %v", err)
+	}
+	if _, err := service.GetFNOL(report.ID, models.FNOLReporter{ID: "cust-003"}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Another customer's report: got %v", err)
+	}
+	if reports, err := service.GetFNOLs("cust-002", agent); err != nil || len(reports) != 1 {
+		t.Errorf("Agent listing the customer's reports: got %d, %v", len(reports), err)
+	}
+
+	// pol-001 belongs to cust-001
+	_, err = service.SaveIncident(report.ID, customer, &models.FNOLIncident{
+		PolicyID:     "pol-001",
+		Type:         "theft",
+		IncidentDate: &yesterday,
+		LossLocation: &models.LossLocation{City: "Springfield", Country: "US"},
+		Description:  "Bike stolen",
+	})
+	var invalid *FNOLStepError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 1 || invalid.Problems[0] != "policy pol-001 does not belong to the customer" {
+		t.Errorf("Someone else's policy: got %v", err)
+	}
+}
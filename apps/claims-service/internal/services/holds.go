@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Policy statuses reported by policy-service that affect held claims
+const (
+	policyStatusActive    = "active"
+	policyStatusGrace     = "grace"
+	policyStatusLapsed    = "lapsed"
+	policyStatusCancelled = "cancelled"
+	policyStatusExpired   = "expired"
+)
+
+// RecheckHeldClaims looks up the policy behind every claim held pending
+// payment. Claims on reinstated policies go to review, since reinstatement
+// restores coverage for the grace period; claims on policies that lapsed,
+// were cancelled or expired without renewing are rejected. Without
+// policy-service nothing is changed.
+func (s *ClaimService) RecheckHeldClaims(ctx context.Context) *models.HoldRecheckResult {
+	result := &models.HoldRecheckResult{
+		Released:  []string{},
+		Rejected:  []string{},
+		StillHeld: []string{},
+		CheckedAt: time.Now(),
+	}
+
+	held := s.repo.GetClaimsByFilter(&models.ClaimFilters{Status: lifecycle.PendingPayment})
+	for _, claim := range held {
+		result.Checked++
+		if s.policies == nil {
+			result.StillHeld = append(result.StillHeld, claim.ID)
+			continue
+		}
+
+		policy, err := s.policies.GetPolicy(ctx, claim.PolicyID, claim.CustomerID)
+		if err != nil {
+			s.logger.WithError(err).WithField("claimId", claim.ID).Warn("Policy lookup failed, keeping claim held")
+			result.StillHeld = append(result.StillHeld, claim.ID)
+			continue
+		}
+
+		switch policy.Status {
+		case policyStatusActive:
+			if s.releaseHeldClaim(claim, lifecycle.UnderReview, nil) {
+				result.Released = append(result.Released, claim.ID)
+			}
+		case policyStatusLapsed, policyStatusCancelled, policyStatusExpired:
+			if s.releaseHeldClaim(claim, lifecycle.Rejected, []string{models.RejectionPolicyLapsed}) {
+				result.Rejected = append(result.Rejected, claim.ID)
+			}
+		default:
+			result.StillHeld = append(result.StillHeld, claim.ID)
+		}
+	}
+
+	if len(result.Released) > 0 || len(result.Rejected) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"checked":  result.Checked,
+			"released": len(result.Released),
+			"rejected": len(result.Rejected),
+		}).Info("Held claims rechecked")
+	}
+
+	return result
+}
+
+// RunHoldRecheck rechecks held claims every interval until ctx is cancelled
+func (s *ClaimService) RunHoldRecheck(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RecheckHeldClaims(ctx)
+		}
+	}
+}
+
+// releaseHeldClaim moves a held claim to its new status. It reports
+// whether the claim was updated.
+func (s *ClaimService) releaseHeldClaim(claim *models.Claim, status string, rejectionCodes []string) bool {
+	change := lifecycle.Change{To: status, Trigger: lifecycle.TriggerRecheck, RejectionCodes: rejectionCodes}
+	if err := s.lifecycle.Fire(claim, change, s.repo.UpdateClaim); err != nil {
+		s.logger.WithError(err).WithField("claimId", claim.ID).Error("Failed to release held claim")
+		return false
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":     claim.ID,
+		"claimNumber": claim.ClaimNumber,
+		"policyId":    claim.PolicyID,
+		"newStatus":   claim.Status,
+	}).Info("Held claim released")
+
+	return true
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func newHoldingService(t *testing.T, policies *stubPolicies, claims ...*models.Claim) (*ClaimService, *repositorytest.FakeStore) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_AUTO_APPROVAL", "true")
+	t.Setenv("FEATURE_AUTO_APPROVAL_THRESHOLD", "")
+	t.Setenv("FEATURE_FLAGS_FILE", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+
+	store := repositorytest.NewFakeStore(claims...)
+	return NewClaimService(store, flags, nil, policies, "", events.NewBus(10, logger), nil, nil, logger), store
+}
+
+func TestCreateClaimHeldDuringGrace(t *testing.T) {
+	termStart := time.Now().AddDate(-1, 0, -10)
+	termEnd := time.Now().AddDate(0, 0, -10)
+	graceEnds := termEnd.AddDate(0, 0, 30)
+	policies := &stubPolicies{policies: map[string]*clients.Policy{
+		"pol-grace":  {ID: "pol-grace", CustomerID: "cust-001", Status: "grace", StartDate: termStart, EndDate: termEnd, GraceEndsAt: &graceEnds},
+		"pol-active": {ID: "pol-active", CustomerID: "cust-001", Status: "active", StartDate: termStart, EndDate: graceEnds},
+	}}
+	service, store := newHoldingService(t, policies)
+	store.AddPolicy(&repository.Policy{ID: "pol-grace", CustomerID: "cust-001", Type: "auto", StartDate: termStart, EndDate: termEnd})
+
+	// A loss after the term ended but inside the grace period is accepted and held
+	incident := time.Now().AddDate(0, 0, -2)
+	claim, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+		PolicyID: "pol-grace", CustomerID: "cust-001", Type: "accident", Amount: 100, IncidentDate: &incident,
+	})
+	if err != nil {
+		t.Fatalf("CreateClaim failed: %v", err)
+	}
+	if claim.Status != "pending_payment" {
+		t.Errorf("Claim during grace should be held, got %s", claim.Status)
+	}
+
+	// Low-value claims on policies in force are still auto-approved
+	claim, err = service.CreateClaim(context.Background(), &models.CreateClaimRequest{
+		PolicyID: "pol-active", CustomerID: "cust-001", Type: "theft", Amount: 100,
+	})
+	if err != nil {
+		t.Fatalf("CreateClaim failed: %v", err)
+	}
+	if claim.Status != "approved" {
+		t.Errorf("Claim on active policy should be auto-approved, got %s", claim.Status)
+	}
+}
+
+func TestCreateClaimPolicyFallback(t *testing.T) {
+	policies := &stubPolicies{err: fmt.Errorf("policy-service request failed: %w", breaker.ErrOpen)}
+	service, _ := newHoldingService(t, policies)
+	req := &models.CreateClaimRequest{PolicyID: "pol-001", CustomerID: "cust-001", Type: "theft", Amount: 100}
+
+	// Failing open files the claim without the grace check
+	if _, err := service.CreateClaim(context.Background(), req); err != nil {
+		t.Fatalf("Expected the claim to be filed failing open, got %v", err)
+	}
+
+	service.fallback = breaker.FailClosed
+	if _, err := service.CreateClaim(context.Background(), req); err == nil || err.Error() != "policy-service unavailable" {
+		t.Errorf("Expected policy-service unavailable failing closed, got %v", err)
+	}
+
+	// An answer from policy-service is not an outage
+	policies.err = nil
+	if _, err := service.CreateClaim(context.Background(), &models.CreateClaimRequest{PolicyID: "pol-404", CustomerID: "cust-001", Type: "theft", Amount: 100}); err != nil {
+		t.Errorf("Expected an unknown policy to be filed failing closed, got %v", err)
+	}
+}
+
+func TestRecheckHeldClaims(t *testing.T) {
+	held := func(id, policyID string) *models.Claim {
+		return &models.Claim{ID: id, PolicyID: policyID, CustomerID: "cust-001", Status: "pending_payment"}
+	}
+	policies := &stubPolicies{policies: map[string]*clients.Policy{
+		"pol-reinstated": {ID: "pol-reinstated", CustomerID: "cust-001", Status: "active"},
+		"pol-lapsed":     {ID: "pol-lapsed", CustomerID: "cust-001", Status: "lapsed"},
+		"pol-grace":      {ID: "pol-grace", CustomerID: "cust-001", Status: "grace"},
+	}}
+	service, store := newHoldingService(t, policies,
+		held("claim-released", "pol-reinstated"),
+		held("claim-rejected", "pol-lapsed"),
+		held("claim-waiting", "pol-grace"),
+		held("claim-unknown", "pol-404"),
+		&models.Claim{ID: "claim-open", PolicyID: "pol-lapsed", CustomerID: "cust-001", Status: "under_review"},
+	)
+
+	result := service.RecheckHeldClaims(context.Background())
+	if result.Checked != 4 {
+		t.Errorf("Checked mismatch: got %d, want 4", result.Checked)
+	}
+	if len(result.Released) != 1 || result.Released[0] != "claim-released" {
+		t.Errorf("Released mismatch: %v", result.Released)
+	}
+	if len(result.Rejected) != 1 || result.Rejected[0] != "claim-rejected" {
+		t.Errorf("Rejected mismatch: %v", result.Rejected)
+	}
+	if len(result.StillHeld) != 2 {
+		t.Errorf("StillHeld mismatch: %v", result.StillHeld)
+	}
+
+	want := map[string]string{
+		"claim-released": "under_review",
+		"claim-rejected": "rejected",
+		"claim-waiting":  "pending_payment",
+		"claim-unknown":  "pending_payment",
+		"claim-open":     "under_review",
+	}
+	for id, status := range want {
+		claim, err := store.GetClaimByID(id)
+		if err != nil {
+			t.Fatalf("GetClaimByID(%s) failed: %v", id, err)
+		}
+		if claim.Status != status {
+			t.Errorf("%s: status got %s, want %s", id, claim.Status, status)
+		}
+	}
+
+	rejected, _ := store.GetClaimByID("claim-rejected")
+	if len(rejected.RejectionCodes) != 1 || rejected.RejectionCodes[0] != models.RejectionPolicyLapsed {
+		t.Errorf("Rejection codes mismatch: %v", rejected.RejectionCodes)
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// MakeOffer offers the customer an amount to settle an approved claim. A
+// new offer withdraws the pending one it replaces; once the customer has
+// accepted an offer no other can be made.
+func (s *ClaimService) MakeOffer(claimID string, viewer models.ClaimViewer, req *models.CreateOfferRequest) (*models.SettlementOffer, error) {
+	if !viewer.IsStaff() {
+		return nil, fmt.Errorf("only staff can make settlement offers")
+	}
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claim.ExpireOffers(now)
+	if claim.Status != lifecycle.Approved {
+		return nil, fmt.Errorf("cannot make an offer on a claim that is %s", claim.Status)
+	}
+	if claim.AcceptedOffer() != nil {
+		return nil, fmt.Errorf("claim already has an accepted offer")
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+	if req.Amount > claim.Amount {
+		return nil, fmt.Errorf("amount cannot exceed the claimed amount of %.2f", claim.Amount)
+	}
+	expiresAt := now.Add(models.DefaultOfferValidity)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(now) {
+			return nil, fmt.Errorf("expiresAt must be in the future")
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	offers := make([]models.SettlementOffer, 0, len(claim.Offers)+1)
+	for _, offer := range claim.Offers {
+		if offer.Status == models.OfferPending {
+			offer.Status = models.OfferWithdrawn
+		}
+		offers = append(offers, offer)
+	}
+	offer := models.SettlementOffer{
+		ID:        fmt.Sprintf("offer-%d", now.UnixNano()),
+		Amount:    req.Amount,
+		Status:    models.OfferPending,
+		Notes:     req.Notes,
+		MadeBy:    viewer.ID,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	claim.Offers = append(offers, offer)
+	claim.UpdatedAt = now
+
+	if err := s.repo.UpdateClaim(claim); err != nil {
+		return nil, fmt.Errorf("failed to save offer: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":   claim.ID,
+		"offerId":   offer.ID,
+		"amount":    offer.Amount,
+		"madeBy":    offer.MadeBy,
+		"expiresAt": offer.ExpiresAt,
+	}).Info("Settlement offer made")
+
+	s.publishOffer(events.OfferMade, claim, &offer, "")
+	return &offer, nil
+}
+
+// GetOffers returns the settlement offers on a claim the viewer may read,
+// oldest first
+func (s *ClaimService) GetOffers(claimID string, viewer models.ClaimViewer) ([]models.SettlementOffer, error) {
+	claim, err := s.GetClaimByID(claimID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Offers == nil {
+		return []models.SettlementOffer{}, nil
+	}
+	return claim.Offers, nil
+}
+
+// RespondToOffer records the customer's acceptance or rejection of a
+// pending offer. Only the customer who owns the claim may answer.
+func (s *ClaimService) RespondToOffer(claimID, offerID string, viewer models.ClaimViewer, accept bool, req *models.RespondToOfferRequest) (*models.SettlementOffer, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+	if viewer.IsStaff() || s.ownerOf(claim, nil) != viewer.ID {
+		s.logger.WithFields(logrus.Fields{
+			"claimId": claimID,
+			"offerId": offerID,
+			"userId":  viewer.ID,
+		}).Warn("Unauthorized settlement offer response")
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	now := time.Now()
+	claim.ExpireOffers(now)
+	index := -1
+	for i := range claim.Offers {
+		if claim.Offers[i].ID == offerID {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("offer not found")
+	}
+	switch claim.Offers[index].Status {
+	case models.OfferPending:
+	case models.OfferExpired:
+		return nil, fmt.Errorf("offer has expired")
+	default:
+		return nil, fmt.Errorf("offer is no longer open (status: %s)", claim.Offers[index].Status)
+	}
+
+	offers := append([]models.SettlementOffer{}, claim.Offers...)
+	offer := &offers[index]
+	offer.RespondedAt = &now
+	eventType := events.OfferAccepted
+	if accept {
+		offer.Status = models.OfferAccepted
+	} else {
+		offer.Status = models.OfferRejected
+		offer.RejectionReason = req.Reason
+		eventType = events.OfferRejected
+	}
+	claim.Offers = offers
+	claim.UpdatedAt = now
+
+	if err := s.repo.UpdateClaim(claim); err != nil {
+		return nil, fmt.Errorf("failed to save offer: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"claimId":    claim.ID,
+		"offerId":    offer.ID,
+		"amount":     offer.Amount,
+		"status":     offer.Status,
+		"customerId": viewer.ID,
+	}).Info("Settlement offer answered")
+
+	s.publishOffer(eventType, claim, offer, offer.RejectionReason)
+	return offer, nil
+}
+
+// publishOffer publishes a settlement offer event
+func (s *ClaimService) publishOffer(eventType string, claim *models.Claim, offer *models.SettlementOffer, reason string) {
+	s.publish(events.Event{
+		Type:        eventType,
+		ClaimID:     claim.ID,
+		ClaimNumber: claim.ClaimNumber,
+		PolicyID:    claim.PolicyID,
+		CustomerID:  claim.CustomerID,
+		NewStatus:   claim.Status,
+		Queue:       claim.Queue,
+		AssignedTo:  claim.AssignedTo,
+		OfferID:     offer.ID,
+		Amount:      offer.Amount,
+		Reason:      reason,
+	})
+}
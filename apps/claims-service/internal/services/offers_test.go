@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+func TestSettlementOfferFlow(t *testing.T) {
+	service, store, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-001", PolicyID: "pol-001", CustomerID: "cust-001", Status: "approved", Amount: 2500},
+		&models.Claim{ID: "claim-002", PolicyID: "pol-002", CustomerID: "cust-001", Status: "under_review", Amount: 800},
+	)
+	owner := models.ClaimViewer{ID: "cust-001", Role: "customer"}
+	anHourAgo := time.Now().Add(-time.Hour)
+
+	errorTests := []struct {
+		name    string
+		claimID string
+		viewer  models.ClaimViewer
+		req     models.CreateOfferRequest
+		wantErr string
+	}{
+		{"customer", "claim-001", owner, models.CreateOfferRequest{Amount: 100}, "only staff can make settlement offers"},
+		{"claim not approved", "claim-002", staffViewer, models.CreateOfferRequest{Amount: 100}, "cannot make an offer on a claim that is under_review"},
+		{"no amount", "claim-001", staffViewer, models.CreateOfferRequest{}, "amount must be greater than zero"},
+		{"above claimed amount", "claim-001", staffViewer, models.CreateOfferRequest{Amount: 3000}, "amount cannot exceed the claimed amount of 2500.00"},
+		{"expired", "claim-001", staffViewer, models.CreateOfferRequest{Amount: 100, ExpiresAt: &anHourAgo}, "expiresAt must be in the future"},
+		{"unknown claim", "claim-404", staffViewer, models.CreateOfferRequest{Amount: 100}, "claim not found"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.MakeOffer(tt.claimID, tt.viewer, &tt.req); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	first, err := service.MakeOffer("claim-001", staffViewer, &models.CreateOfferRequest{Amount: 1800})
+	if err != nil {
+		t.Fatalf("MakeOffer failed: %v", err)
+	}
+	if first.Status != models.OfferPending || first.MadeBy != "adj-001" || first.ExpiresAt.Sub(first.CreatedAt) != models.DefaultOfferValidity {
+		t.Errorf("Unexpected offer %+v", first)
+	}
+
+	// The customer rejects the first offer; a rejected offer cannot be accepted later
+	rejected, err := service.RespondToOffer("claim-001", first.ID, owner, false, &models.RespondToOfferRequest{Reason: "Too low"})
+	if err != nil || rejected.Status != models.OfferRejected || rejected.RejectionReason != "Too low" || rejected.RespondedAt == nil {
+		t.Fatalf("Unexpected rejection %+v, %v", rejected, err)
+	}
+	if _, err := service.RespondToOffer("claim-001", first.ID, owner, true, &models.RespondToOfferRequest{}); err == nil || err.Error() != "offer is no longer open (status: rejected)" {
+		t.Errorf("Expected a closed offer error, got %v", err)
+	}
+
+	// A new offer withdraws the pending one it replaces
+	second, _ := service.MakeOffer("claim-001", staffViewer, &models.CreateOfferRequest{Amount: 2000})
+	third, err := service.MakeOffer("claim-001", staffViewer, &models.CreateOfferRequest{Amount: 2200, Notes: "Includes towing"})
+	if err != nil {
+		t.Fatalf("MakeOffer failed: %v", err)
+	}
+	if _, err := service.RespondToOffer("claim-001", second.ID, owner, true, &models.RespondToOfferRequest{}); err == nil || err.Error() != "offer is no longer open (status: withdrawn)" {
+		t.Errorf("Expected the replaced offer to be withdrawn, got %v", err)
+	}
+
+	respondTests := []struct {
+		name    string
+		offerID string
+		viewer  models.ClaimViewer
+		wantErr string
+	}{
+		{"staff", third.ID, staffViewer, "unauthorized"},
+		{"other customer", third.ID, models.ClaimViewer{ID: "cust-002", Role: "customer"}, "unauthorized"},
+		{"unknown offer", "offer-404", owner, "offer not found"},
+	}
+	for _, tt := range respondTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.RespondToOffer("claim-001", tt.offerID, tt.viewer, true, &models.RespondToOfferRequest{}); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	accepted, err := service.RespondToOffer("claim-001", third.ID, owner, true, &models.RespondToOfferRequest{})
+	if err != nil || accepted.Status != models.OfferAccepted {
+		t.Fatalf("Unexpected acceptance %+v, %v", accepted, err)
+	}
+	if _, err := service.MakeOffer("claim-001", staffViewer, &models.CreateOfferRequest{Amount: 2400}); err == nil || err.Error() != "claim already has an accepted offer" {
+		t.Errorf("Expected no offers after acceptance, got %v", err)
+	}
+
+	offers, err := service.GetOffers("claim-001", owner)
+	if err != nil || len(offers) != 3 {
+		t.Fatalf("Expected 3 offers, got %+v, %v", offers, err)
+	}
+	wantStatuses := []string{models.OfferRejected, models.OfferWithdrawn, models.OfferAccepted}
+	for i, offer := range offers {
+		if offer.Status != wantStatuses[i] {
+			t.Errorf("Offer %d: got %s, want %s", i, offer.Status, wantStatuses[i])
+		}
+	}
+	if _, err := service.GetOffers("claim-001", models.ClaimViewer{ID: "cust-002", Role: "customer"}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Expected another customer to be refused, got %v", err)
+	}
+
+	var types []string
+	for _, entry := range store.GetTimelineEntries("claim-001") {
+		types = append(types, entry.Type)
+	}
+	wantTypes := []string{events.OfferMade, events.OfferRejected, events.OfferMade, events.OfferMade, events.OfferAccepted}
+	if len(types) != len(wantTypes) {
+		t.Fatalf("Unexpected timeline %v", types)
+	}
+	for i := range wantTypes {
+		if types[i] != wantTypes[i] {
+			t.Errorf("Timeline entry %d: got %s, want %s", i, types[i], wantTypes[i])
+		}
+	}
+}
+
+func TestSettlementOffersExpire(t *testing.T) {
+	service, store, _ := newTestService(t, false,
+		&models.Claim{ID: "claim-001", PolicyID: "pol-001", CustomerID: "cust-001", Status: "approved", Amount: 2500},
+	)
+	owner := models.ClaimViewer{ID: "cust-001", Role: "customer"}
+	inAnHour := time.Now().Add(time.Hour)
+
+	offer, err := service.MakeOffer("claim-001", staffViewer, &models.CreateOfferRequest{Amount: 1500, ExpiresAt: &inAnHour})
+	if err != nil {
+		t.Fatalf("MakeOffer failed: %v", err)
+	}
+
+	// Let the offer run out without waiting an hour
+	claim, _ := store.GetClaimByID("claim-001")
+	claim.Offers[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := service.RespondToOffer("claim-001", offer.ID, owner, true, &models.RespondToOfferRequest{}); err == nil || err.Error() != "offer has expired" {
+		t.Errorf("Expected an expired offer, got %v", err)
+	}
+	read, err := service.GetClaimByID("claim-001", owner)
+	if err != nil || read.Offers[0].Status != models.OfferExpired || read.AcceptedOffer() != nil {
+		t.Errorf("Expected the offer to read as expired, got %+v, %v", read, err)
+	}
+}
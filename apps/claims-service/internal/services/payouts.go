@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// PayoutCreator creates claim payouts in payments-service.
+// *clients.PaymentsClient is the production implementation.
+type PayoutCreator interface {
+	CreatePayout(ctx context.Context, claimID, customerID string, amount float64) (*clients.Payout, error)
+}
+
+var _ PayoutCreator = (*clients.PaymentsClient)(nil)
+
+// PayoutService pays out accepted settlement offers. payments-service only
+// takes payouts from this service, with its payments:write service token, so
+// staff pay claims out here rather than there.
+type PayoutService struct {
+	repo     repository.ClaimStore
+	payments PayoutCreator
+	logger   *logrus.Logger
+}
+
+// NewPayoutService creates a payout service. payments may be nil when
+// payments-service is not configured; payouts then fail.
+func NewPayoutService(repo repository.ClaimStore, payments PayoutCreator, logger *logrus.Logger) *PayoutService {
+	return &PayoutService{
+		repo:     repo,
+		payments: payments,
+		logger:   logger,
+	}
+}
+
+// CreatePayout pays out part or, with a zero amount, all of a claim's
+// accepted settlement offer. payments-service checks the payouts made
+// before, so together they never exceed the offer.
+func (s *PayoutService) CreatePayout(ctx context.Context, claimID string, viewer models.ClaimViewer, req *models.CreatePayoutRequest) (*clients.Payout, error) {
+	if !viewer.IsStaff() {
+		return nil, fmt.Errorf("only staff can pay out claims")
+	}
+	if s.payments == nil {
+		return nil, fmt.Errorf("payments-service is not configured")
+	}
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+	offer := claim.AcceptedOffer()
+	if offer == nil {
+		return nil, fmt.Errorf("claim has no accepted settlement offer")
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = offer.Amount
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+	if amount > offer.Amount {
+		return nil, fmt.Errorf("amount cannot exceed the accepted offer of %.2f", offer.Amount)
+	}
+
+	payout, err := s.payments.CreatePayout(ctx, claim.ID, claim.CustomerID, amount)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.WithFields(logrus.Fields{
+		"claimId":   claim.ID,
+		"paymentId": payout.ID,
+		"amount":    amount,
+		"paidBy":    viewer.ID,
+	}).Info("Claim paid out")
+	return payout, nil
+}
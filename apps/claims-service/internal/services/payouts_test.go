@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// recordingPayouts is a PayoutCreator remembering the payouts it made
+type recordingPayouts struct {
+	made []clients.Payout
+}
+
+func (p *recordingPayouts) CreatePayout(ctx context.Context, claimID, customerID string, amount float64) (*clients.Payout, error) {
+	payout := clients.Payout{ID: "pay-100", ClaimID: claimID, CustomerID: customerID, Amount: amount, Status: "pending"}
+	p.made = append(p.made, payout)
+	return &payout, nil
+}
+
+func TestPayoutsAreLimitedToTheAcceptedOffer(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-001", CustomerID: "cust-001", Status: "approved", Offers: []models.SettlementOffer{
+			{ID: "offer-001", Amount: 1800, Status: models.OfferRejected},
+			{ID: "offer-002", Amount: 2200, Status: models.OfferAccepted},
+		}},
+		&models.Claim{ID: "claim-002", CustomerID: "cust-001", Status: "approved", Offers: []models.SettlementOffer{
+			{ID: "offer-003", Amount: 900, Status: models.OfferPending},
+		}},
+	)
+	payments := &recordingPayouts{}
+	service := NewPayoutService(store, payments, logger)
+
+	tests := []struct {
+		name    string
+		claimID string
+		viewer  models.ClaimViewer
+		amount  float64
+		wantErr string
+	}{
+		{"customer", "claim-001", models.ClaimViewer{ID: "cust-001", Role: "customer"}, 100, "only staff can pay out claims"},
+		{"no accepted offer", "claim-002", staffViewer, 100, "claim has no accepted settlement offer"},
+		{"negative amount", "claim-001", staffViewer, -5, "amount must be greater than zero"},
+		{"above the offer", "claim-001", staffViewer, 2500, "amount cannot exceed the accepted offer of 2200.00"},
+		{"unknown claim", "claim-404", staffViewer, 100, "claim not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreatePayout(context.Background(), tt.claimID, tt.viewer, &models.CreatePayoutRequest{Amount: tt.amount}); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if len(payments.made) != 0 {
+		t.Fatalf("Expected refused payouts not to reach payments-service, got %+v", payments.made)
+	}
+
+	// Without an amount the whole offer is paid out
+	payout, err := service.CreatePayout(context.Background(), "claim-001", staffViewer, &models.CreatePayoutRequest{})
+	if err != nil || payout.Amount != 2200 || payout.CustomerID != "cust-001" {
+		t.Fatalf("Unexpected payout %+v, %v", payout, err)
+	}
+
+	unconfigured := NewPayoutService(store, nil, logger)
+	if _, err := unconfigured.CreatePayout(context.Background(), "claim-001", staffViewer, &models.CreatePayoutRequest{}); err == nil || err.Error() != "payments-service is not configured" {
+		t.Errorf("Expected payouts to need payments-service, got %v", err)
+	}
+}
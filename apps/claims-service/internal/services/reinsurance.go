@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/reinsurance"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// ReinsuranceService works out what approved claims cede to reinsurers
+// under the treaty of their policy type. Cessions are calculated when they
+// are read, so a renewed treaty applies to every claim at once.
+type ReinsuranceService struct {
+	repo     repository.ClaimStore
+	treaties *reinsurance.Treaties
+	logger   *logrus.Logger
+}
+
+// NewReinsuranceService creates a new reinsurance service. treaties nil
+// uses reinsurance.Default, which cedes nothing.
+func NewReinsuranceService(repo repository.ClaimStore, treaties *reinsurance.Treaties, logger *logrus.Logger) *ReinsuranceService {
+	if treaties == nil {
+		treaties = reinsurance.Default()
+	}
+	return &ReinsuranceService{
+		repo:     repo,
+		treaties: treaties,
+		logger:   logger,
+	}
+}
+
+// GetCession returns an approved claim's split between the carrier and
+// its reinsurer
+func (s *ReinsuranceService) GetCession(claimID string) (*models.ReinsuranceCession, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+	if claim.Status != lifecycle.Approved {
+		return nil, fmt.Errorf("reinsurance is only calculated for approved claims (current status: %s)", claim.Status)
+	}
+	return s.cession(claim), nil
+}
+
+// Report adds up the cessions of the approved claims matching filters
+func (s *ReinsuranceService) Report(filters models.ReinsuranceFilters, now time.Time) *models.ReinsuranceReport {
+	groups := make(map[string]*models.CessionTotals)
+	total := models.CessionTotals{PolicyType: "all"}
+
+	for _, claim := range s.repo.GetAllClaims() {
+		if claim.Status != lifecycle.Approved {
+			continue
+		}
+		cession := s.cession(claim)
+		if filters.PolicyType != "" && cession.PolicyType != filters.PolicyType {
+			continue
+		}
+		if !filters.ApprovedFrom.IsZero() && cession.ApprovedAt.Before(filters.ApprovedFrom) {
+			continue
+		}
+		if !filters.ApprovedTo.IsZero() && cession.ApprovedAt.After(filters.ApprovedTo) {
+			continue
+		}
+
+		group := groups[cession.PolicyType]
+		if group == nil {
+			group = &models.CessionTotals{PolicyType: cession.PolicyType, Treaty: cession.Treaty}
+			groups[cession.PolicyType] = group
+		}
+		group.Add(cession)
+		total.Add(cession)
+	}
+
+	report := &models.ReinsuranceReport{
+		GeneratedAt:     now,
+		TreatiesVersion: s.treaties.Version,
+		ByPolicyType:    make([]models.CessionTotals, 0, len(groups)),
+		Total:           roundTotals(total),
+	}
+	for _, group := range groups {
+		report.ByPolicyType = append(report.ByPolicyType, roundTotals(*group))
+	}
+	sort.Slice(report.ByPolicyType, func(i, j int) bool {
+		return report.ByPolicyType[i].PolicyType < report.ByPolicyType[j].PolicyType
+	})
+
+	s.logger.WithFields(logrus.Fields{
+		"claims": report.Total.Claims,
+		"ceded":  report.Total.CededAmount,
+	}).Debug("Built reinsurance report")
+
+	return report
+}
+
+// cession splits an approved claim. Once the customer has accepted a
+// settlement offer, the offer is what the claim costs; until then the
+// claimed amount is.
+func (s *ReinsuranceService) cession(claim *models.Claim) *models.ReinsuranceCession {
+	policyType := models.UnknownPolicyType
+	if policy, err := s.repo.GetPolicyByID(claim.PolicyID); err == nil && policy.Type != "" {
+		policyType = policy.Type
+	}
+
+	gross, basis := claim.Amount, models.CessionBasisClaim
+	if offer := claim.AcceptedOffer(); offer != nil {
+		gross, basis = offer.Amount, models.CessionBasisOffer
+	}
+
+	split := s.treaties.Cede(policyType, gross)
+	treaty, _ := s.treaties.Treaty(policyType)
+	return &models.ReinsuranceCession{
+		ClaimID:        claim.ID,
+		ClaimNumber:    claim.ClaimNumber,
+		PolicyType:     policyType,
+		Treaty:         split.Treaty,
+		Retention:      treaty.Retention,
+		Limit:          treaty.Limit,
+		Basis:          basis,
+		GrossAmount:    gross,
+		CededAmount:    split.Ceded,
+		RetainedAmount: split.Retained,
+		ApprovedAt:     claim.EnteredStatusAt(),
+	}
+}
+
+// roundTotals rounds summed amounts to the cent
+func roundTotals(t models.CessionTotals) models.CessionTotals {
+	t.GrossAmount = math.Round(t.GrossAmount*100) / 100
+	t.CededAmount = math.Round(t.CededAmount*100) / 100
+	t.RetainedAmount = math.Round(t.RetainedAmount*100) / 100
+	return t
+}
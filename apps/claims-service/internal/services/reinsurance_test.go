@@ -0,0 +1,95 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/reinsurance"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestReinsuranceCessions(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	approvedAt := func(day int) *time.Time {
+		at := time.Date(2024, 12, day, 12, 0, 0, 0, time.UTC)
+		return &at
+	}
+	store := repositorytest.NewFakeStore(
+		&models.Claim{ID: "claim-small", PolicyID: "pol-auto", Status: "approved", Amount: 4000, StatusSince: approvedAt(2)},
+		&models.Claim{ID: "claim-large", PolicyID: "pol-auto", Status: "approved", Amount: 30000, StatusSince: approvedAt(10)},
+		&models.Claim{ID: "claim-settled", PolicyID: "pol-home", Status: "approved", Amount: 50000, StatusSince: approvedAt(20),
+			Offers: []models.SettlementOffer{{ID: "offer-001", Amount: 45000, Status: models.OfferAccepted}}},
+		&models.Claim{ID: "claim-unknown", PolicyID: "pol-404", Status: "approved", Amount: 90000, StatusSince: approvedAt(20)},
+		&models.Claim{ID: "claim-open", PolicyID: "pol-auto", Status: "under_review", Amount: 80000},
+	)
+	store.AddPolicy(&repository.Policy{ID: "pol-auto", Type: "auto"})
+	store.AddPolicy(&repository.Policy{ID: "pol-home", Type: "home"})
+
+	service := NewReinsuranceService(store, &reinsurance.Treaties{
+		Version: "test",
+		Treaties: map[string]reinsurance.Treaty{
+			"auto": {Name: "AUTO-XL", Retention: 5000, Limit: 20000},
+			"home": {Name: "HOME-XL", Retention: 10000, Limit: 90000},
+		},
+	}, logger)
+
+	tests := []struct {
+		claimID string
+		want    models.ReinsuranceCession
+	}{
+		{"claim-small", models.ReinsuranceCession{PolicyType: "auto", Treaty: "AUTO-XL", Basis: models.CessionBasisClaim, GrossAmount: 4000, CededAmount: 0, RetainedAmount: 4000}},
+		{"claim-large", models.ReinsuranceCession{PolicyType: "auto", Treaty: "AUTO-XL", Basis: models.CessionBasisClaim, GrossAmount: 30000, CededAmount: 20000, RetainedAmount: 10000}},
+		{"claim-settled", models.ReinsuranceCession{PolicyType: "home", Treaty: "HOME-XL", Basis: models.CessionBasisOffer, GrossAmount: 45000, CededAmount: 35000, RetainedAmount: 10000}},
+		{"claim-unknown", models.ReinsuranceCession{PolicyType: models.UnknownPolicyType, Basis: models.CessionBasisClaim, GrossAmount: 90000, CededAmount: 0, RetainedAmount: 90000}},
+	}
+	for _, tt := range tests {
+		got, err := service.GetCession(tt.claimID)
+		if err != nil {
+			t.Fatalf("GetCession(%s) failed: %v", tt.claimID, err)
+		}
+		if got.PolicyType != tt.want.PolicyType || got.Treaty != tt.want.Treaty || got.Basis != tt.want.Basis ||
+			got.GrossAmount != tt.want.GrossAmount || got.CededAmount != tt.want.CededAmount || got.RetainedAmount != tt.want.RetainedAmount {
+			t.Errorf("GetCession(%s) = %+v, want %+v", tt.claimID, got, tt.want)
+		}
+	}
+	if _, err := service.GetCession("claim-open"); err == nil || err.Error() != "reinsurance is only calculated for approved claims (current status: under_review)" {
+		t.Errorf("Expected open claims to be refused, got %v", err)
+	}
+	if _, err := service.GetCession("claim-404"); err == nil || err.Error() != "claim not found" {
+		t.Errorf("Expected an unknown claim, got %v", err)
+	}
+
+	report := service.Report(models.ReinsuranceFilters{}, time.Now())
+	wantGroups := []models.CessionTotals{
+		{PolicyType: "auto", Treaty: "AUTO-XL", Claims: 2, CededClaims: 1, GrossAmount: 34000, CededAmount: 20000, RetainedAmount: 14000},
+		{PolicyType: "home", Treaty: "HOME-XL", Claims: 1, CededClaims: 1, GrossAmount: 45000, CededAmount: 35000, RetainedAmount: 10000},
+		{PolicyType: models.UnknownPolicyType, Claims: 1, GrossAmount: 90000, RetainedAmount: 90000},
+	}
+	if len(report.ByPolicyType) != len(wantGroups) {
+		t.Fatalf("Unexpected groups %+v", report.ByPolicyType)
+	}
+	for i, want := range wantGroups {
+		if report.ByPolicyType[i] != want {
+			t.Errorf("Group %d = %+v, want %+v", i, report.ByPolicyType[i], want)
+		}
+	}
+	wantTotal := models.CessionTotals{PolicyType: "all", Claims: 4, CededClaims: 2, GrossAmount: 169000, CededAmount: 55000, RetainedAmount: 114000}
+	if report.Total != wantTotal || report.TreatiesVersion != "test" {
+		t.Errorf("Total = %+v (version %s), want %+v", report.Total, report.TreatiesVersion, wantTotal)
+	}
+
+	filtered := service.Report(models.ReinsuranceFilters{
+		PolicyType:   "auto",
+		ApprovedFrom: time.Date(2024, 12, 5, 0, 0, 0, 0, time.UTC),
+		ApprovedTo:   time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+	}, time.Now())
+	if filtered.Total.Claims != 1 || filtered.Total.CededAmount != 20000 || len(filtered.ByPolicyType) != 1 {
+		t.Errorf("Unexpected filtered report %+v", filtered)
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/scan"
+	"github.com/sirupsen/logrus"
+)
+
+// maxScanAttempts is how many times a document is sent to a scanner that
+// fails before the document is quarantined unscanned
+const maxScanAttempts = 3
+
+// scanRetryDelay is the wait between attempts to scan a document
+const scanRetryDelay = 5 * time.Second
+
+// ScanService scans each document attached to a claim for malware, in the
+// background, and records the outcome on the document. Documents the
+// scanner finds malware in, or cannot scan at all, are quarantined: they
+// are marked scan_failed, can no longer be downloaded, and a
+// claim.document_quarantined event is published.
+type ScanService struct {
+	repo       repository.ClaimStore
+	scanner    scan.Scanner
+	bus        *events.Bus
+	logger     *logrus.Logger
+	retryDelay time.Duration
+}
+
+// NewScanService creates a new document scan service
+func NewScanService(repo repository.ClaimStore, scanner scan.Scanner, bus *events.Bus, logger *logrus.Logger) *ScanService {
+	return &ScanService{
+		repo:       repo,
+		scanner:    scanner,
+		bus:        bus,
+		logger:     logger,
+		retryDelay: scanRetryDelay,
+	}
+}
+
+// Run scans documents as they are uploaded until ctx is done. Documents
+// left unscanned when the service last stopped, or uploaded before
+// scanning was added, are scanned first. Documents are scanned one at a
+// time, in the order they were uploaded.
+func (s *ScanService) Run(ctx context.Context) {
+	_, sub := s.bus.Subscribe(func(evt events.Event) bool {
+		return evt.Type == events.DocumentUploaded
+	}, 0, 256)
+	defer sub.Close()
+
+	s.logger.WithField("scanner", s.scanner.Name()).Info("Document scans started")
+	s.scanBacklog(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Document scans stopped")
+			return
+		case evt := <-sub.C:
+			s.scanDocument(ctx, evt.ClaimID, evt.DocumentID)
+		}
+	}
+}
+
+// scanBacklog scans the documents of live claims that are not yet scanned
+func (s *ScanService) scanBacklog(ctx context.Context) {
+	for _, claim := range s.repo.GetAllClaims() {
+		for _, doc := range s.repo.GetDocuments(claim.ID) {
+			if ctx.Err() != nil {
+				return
+			}
+			if !doc.Scanned() {
+				s.scanDocument(ctx, claim.ID, doc.ID)
+			}
+		}
+	}
+}
+
+// scanDocument scans a document not yet scanned and stores the outcome. A
+// scan cut short by ctx leaves the document pending, to be scanned on the
+// next start.
+func (s *ScanService) scanDocument(ctx context.Context, claimID, documentID string) {
+	fields := logrus.Fields{
+		"claimId":    claimID,
+		"documentId": documentID,
+		"scanner":    s.scanner.Name(),
+	}
+	doc, content, err := s.repo.GetDocument(claimID, documentID)
+	if err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("Uploaded document not found for malware scan")
+		return
+	}
+	if doc.Scanned() {
+		return
+	}
+
+	result, err := s.scan(ctx, doc, content)
+	if ctx.Err() != nil {
+		return
+	}
+
+	scanned := *doc
+	now := time.Now()
+	scanned.ScannedBy, scanned.ScannedAt = s.scanner.Name(), &now
+	switch {
+	case err != nil:
+		scanned.ScanStatus, scanned.ScanError = models.ScanFailed, err.Error()
+		s.logger.WithError(err).WithFields(fields).Error("Failed to scan document, quarantining it")
+	case !result.Clean:
+		scanned.ScanStatus, scanned.ScanThreat = models.ScanFailed, result.Threat
+		s.logger.WithFields(fields).WithField("threat", result.Threat).Warn("Malware found in document, quarantining it")
+	default:
+		scanned.ScanStatus = models.ScanClean
+	}
+
+	if err := s.repo.UpdateDocument(&scanned); err != nil {
+		s.logger.WithError(err).WithFields(fields).Error("Failed to save document scan")
+		return
+	}
+	if scanned.ScanStatus == models.ScanFailed {
+		s.quarantined(&scanned)
+		return
+	}
+	s.logger.WithFields(fields).Debug("Document scanned clean")
+}
+
+// scan sends a document to the scanner, retrying a scanner that fails
+func (s *ScanService) scan(ctx context.Context, doc *models.ClaimDocument, content []byte) (*scan.Result, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := s.scanner.Scan(ctx, doc.FileName, content)
+		if err == nil || attempt == maxScanAttempts {
+			return result, err
+		}
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"documentId": doc.ID,
+			"attempt":    attempt,
+		}).Warn("Document scan failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.retryDelay):
+		}
+	}
+}
+
+// quarantined publishes a document's quarantine and records it on the
+// claim's timeline
+func (s *ScanService) quarantined(doc *models.ClaimDocument) {
+	evt := events.Event{
+		Type:       events.DocumentQuarantined,
+		ClaimID:    doc.ClaimID,
+		DocumentID: doc.ID,
+		FileName:   doc.FileName,
+		Reason:     doc.ScanThreat,
+		Timestamp:  *doc.ScannedAt,
+	}
+	if evt.Reason == "" {
+		evt.Reason = "scan failed: " + doc.ScanError
+	}
+	if claim, err := s.repo.GetClaimByID(doc.ClaimID); err == nil {
+		evt.ClaimNumber, evt.PolicyID, evt.CustomerID = claim.ClaimNumber, claim.PolicyID, claim.CustomerID
+		evt.NewStatus, evt.Queue, evt.AssignedTo = claim.Status, claim.Queue, claim.AssignedTo
+	}
+
+	evt = s.bus.Publish(evt)
+	if err := s.repo.AddTimelineEntry(timelineEntryFor(evt)); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"claimId":   evt.ClaimID,
+			"eventType": evt.Type,
+		}).Warn("Failed to record timeline entry")
+	}
+}
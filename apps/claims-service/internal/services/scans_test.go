@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/scan"
+	"github.com/sirupsen/logrus"
+)
+
+// stubScanner finds malware in content containing "EICAR", and fails on
+// content containing "broken"
+type stubScanner struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *stubScanner) Name() string { return "test" }
+
+func (s *stubScanner) Scan(ctx context.Context, fileName string, content []byte) (*scan.Result, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	switch {
+	case strings.Contains(string(content), "broken"):
+		return nil, errors.New("scanner unavailable")
+	case strings.Contains(string(content), "EICAR"):
+		return &scan.Result{Threat: "Eicar-Signature"}, nil
+	}
+	return &scan.Result{Clean: true}, nil
+}
+
+func TestScansQuarantineInfectedDocuments(t *testing.T) {
+	claims, store, bus := newTestService(t, false, &models.Claim{ID: "claim-001", ClaimNumber: "CLM-2024-000001", CustomerID: "cust-001", Status: "submitted"})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	scanner := &stubScanner{}
+	service := NewScanService(store, scanner, bus, logger)
+	service.retryDelay = time.Millisecond
+
+	// Uploaded before scanning was added: no scan status
+	if err := store.AddDocument(&models.ClaimDocument{ID: "doc-legacy", ClaimID: "claim-001", FileName: "estimate.pdf"}, []byte("%PDF-1.4")); err != nil {
+		t.Fatalf("AddDocument failed: %v", err)
+	}
+	photo, err := claims.UploadDocument("claim-001", "cust-001", "bumper.jpg", "", []byte("\xff\xd8\xff\xe0 jpeg"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if photo.ScanStatus != models.ScanPending {
+		t.Errorf("Expected the upload to wait for its scan, got %q", photo.ScanStatus)
+	}
+	if _, _, err := claims.GetDocument("claim-001", photo.ID); err == nil || err.Error() != "document has not been scanned" {
+		t.Errorf("Expected an unscanned document to be refused, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		service.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	for bus.SubscriberCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	infected, err := claims.UploadDocument("claim-001", "cust-001", "invoice.pdf", "application/pdf", []byte("%PDF-1.4 EICAR"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	unscannable, err := claims.UploadDocument("claim-001", "cust-001", "scan.pdf", "application/pdf", []byte("%PDF-1.4 broken"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+
+	status := func(id string) *models.ClaimDocument {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+			if doc, _, _ := store.GetDocument("claim-001", id); doc.Scanned() {
+				return doc
+			}
+		}
+		t.Fatalf("Document %s was not scanned", id)
+		return nil
+	}
+
+	for _, id := range []string{"doc-legacy", photo.ID} {
+		if doc := status(id); doc.ScanStatus != models.ScanClean || doc.ScannedBy != "test" || doc.ScannedAt == nil {
+			t.Errorf("Expected %s to be scanned clean, got %+v", id, doc)
+		}
+	}
+	if _, content, err := claims.GetDocument("claim-001", photo.ID); err != nil || string(content) != "\xff\xd8\xff\xe0 jpeg" {
+		t.Errorf("Expected the clean photo to be served, got %q, %v", content, err)
+	}
+
+	if doc := status(infected.ID); doc.ScanStatus != models.ScanFailed || doc.ScanThreat != "Eicar-Signature" {
+		t.Errorf("Expected the infected document to be quarantined, got %+v", doc)
+	}
+	if doc := status(unscannable.ID); doc.ScanStatus != models.ScanFailed || doc.ScanError != "scanner unavailable" {
+		t.Errorf("Expected a document that cannot be scanned to be quarantined, got %+v", doc)
+	}
+	if _, _, err := claims.GetDocument("claim-001", infected.ID); err == nil || err.Error() != "document is quarantined" {
+		t.Errorf("Expected a quarantined document to be refused, got %v", err)
+	}
+	// Four documents, the unscannable one tried maxScanAttempts times
+	scanner.mu.Lock()
+	calls := scanner.calls
+	scanner.mu.Unlock()
+	if calls != 3+maxScanAttempts {
+		t.Errorf("Expected %d scans, got %d", 3+maxScanAttempts, calls)
+	}
+
+	var quarantined []models.TimelineEntry
+	for _, entry := range store.GetTimelineEntries("claim-001") {
+		if entry.Type == events.DocumentQuarantined {
+			quarantined = append(quarantined, entry)
+		}
+	}
+	if len(quarantined) != 2 || quarantined[0].DocumentID != infected.ID || quarantined[0].Reason != "Eicar-Signature" ||
+		quarantined[1].Reason != "scan failed: scanner unavailable" || quarantined[0].Summary != "Document invoice.pdf quarantined by its malware scan" {
+		t.Errorf("Unexpected quarantine timeline entries %+v", quarantined)
+	}
+}
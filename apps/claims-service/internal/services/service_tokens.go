@@ -0,0 +1,124 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+// Scopes of service tokens
+const (
+	// ScopePaymentsWrite lets a service create payouts in payments-service
+	ScopePaymentsWrite = "payments:write"
+)
+
+// ClaimsServiceClientID is the client this service's own service tokens are
+// issued to
+const ClaimsServiceClientID = "claims-service"
+
+// DefaultServiceTokenTTL is how long service tokens are valid
+const DefaultServiceTokenTTL = 5 * time.Minute
+
+// claimsServiceScopes are the scopes this service grants itself for its
+// calls to other services
+var claimsServiceScopes = []string{ScopePaymentsWrite}
+
+// ServiceToken is a short-lived token for calls between services
+type ServiceToken struct {
+	Token     string
+	ClientID  string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// ServiceTokenService issues service tokens: to registered clients through
+// the client credentials grant, and to this service for its own calls.
+// Service tokens carry scopes instead of a role, so they never pass as a
+// user.
+type ServiceTokenService struct {
+	tokens  *auth.JWTManager
+	clients map[string]auth.Client
+	ttl     time.Duration
+	logger  *logrus.Logger
+}
+
+// NewServiceTokenService creates a service token service for the
+// registered clients. Tokens are valid for ttl.
+func NewServiceTokenService(tokens *auth.JWTManager, clients []auth.Client, ttl time.Duration, logger *logrus.Logger) *ServiceTokenService {
+	registered := make(map[string]auth.Client, len(clients))
+	for _, client := range clients {
+		registered[client.ID] = client
+	}
+	return &ServiceTokenService{
+		tokens:  tokens,
+		clients: registered,
+		ttl:     ttl,
+		logger:  logger,
+	}
+}
+
+// Grant is the client credentials grant. An unknown client or wrong secret
+// fails with "invalid client". scope is space-separated and may name only
+// scopes the client is registered for, failing with "invalid scope" for any
+// other; when empty, the token carries all of them.
+func (s *ServiceTokenService) Grant(clientID, secret, scope string) (*ServiceToken, error) {
+	client, exists := s.clients[clientID]
+	if !exists || auth.VerifyPassword(client.SecretHash, secret) != nil {
+		s.logger.WithField("clientId", clientID).Warn("Refused client credentials")
+		return nil, fmt.Errorf("invalid client")
+	}
+
+	scopes := client.Scopes
+	if requested := strings.Fields(scope); len(requested) > 0 {
+		for _, want := range requested {
+			if !hasScope(client.Scopes, want) {
+				return nil, fmt.Errorf("invalid scope %q", want)
+			}
+		}
+		scopes = requested
+	}
+	return s.issue(clientID, scopes)
+}
+
+// Issue returns a token for this service's own calls, carrying scopes. It
+// may only carry the scopes this service grants itself.
+func (s *ServiceTokenService) Issue(scopes ...string) (*ServiceToken, error) {
+	for _, scope := range scopes {
+		if !hasScope(claimsServiceScopes, scope) {
+			return nil, fmt.Errorf("invalid scope %q", scope)
+		}
+	}
+	return s.issue(ClaimsServiceClientID, scopes)
+}
+
+// issue signs a token for clientID
+func (s *ServiceTokenService) issue(clientID string, scopes []string) (*ServiceToken, error) {
+	now := time.Now()
+	token, err := s.tokens.GenerateForClient(clientID, scopes, s.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	s.logger.WithFields(logrus.Fields{
+		"clientId": clientID,
+		"scope":    strings.Join(scopes, " "),
+	}).Debug("Issued service token")
+	return &ServiceToken{
+		Token:     token,
+		ClientID:  clientID,
+		Scopes:    append([]string(nil), scopes...),
+		ExpiresAt: now.Add(s.ttl),
+	}, nil
+}
+
+// hasScope reports whether scopes holds scope
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+func TestClientCredentialsGrantIssuesScopedTokens(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	hash, err := auth.HashPassword("reporting-secret")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	tokens := auth.NewJWTManager("test-secret", time.Hour)
+	service := NewServiceTokenService(tokens, []auth.Client{
+		{ID: "reporting", SecretHash: hash, Scopes: []string{"claims:read", "reports:write"}},
+	}, time.Minute, logger)
+
+	for _, tc := range []struct {
+		name     string
+		clientID string
+		secret   string
+		scope    string
+		wantErr  string
+	}{
+		{"wrong secret", "reporting", "guess", "", "invalid client"},
+		{"unknown client", "billing", "reporting-secret", "", "invalid client"},
+		{"unregistered scope", "reporting", "reporting-secret", "claims:read payments:write", `invalid scope "payments:write"`},
+	} {
+		if _, err := service.Grant(tc.clientID, tc.secret, tc.scope); err == nil || err.Error() != tc.wantErr {
+			t.Errorf("%s: expected %q, got %v", tc.name, tc.wantErr, err)
+		}
+	}
+
+	// Without a scope the token carries all of the client's
+	token, err := service.Grant("reporting", "reporting-secret", "")
+	if err != nil || time.Until(token.ExpiresAt) > time.Minute {
+		t.Fatalf("Unexpected token %+v, %v", token, err)
+	}
+	claims, err := tokens.Verify(token.Token)
+	if err != nil || claims.UserID != "reporting" || claims.Role != "" || !claims.HasScope("reports:write") || claims.HasScope("payments:write") {
+		t.Fatalf("Unexpected claims %+v, %v", claims, err)
+	}
+	token, _ = service.Grant("reporting", "reporting-secret", "claims:read")
+	if claims, _ := tokens.Verify(token.Token); claims.HasScope("reports:write") {
+		t.Errorf("Expected only the requested scope, got %q", claims.Scope)
+	}
+
+	// This service's own tokens carry only the scopes it grants itself
+	own, err := service.Issue(ScopePaymentsWrite)
+	if err != nil || own.ClientID != ClaimsServiceClientID {
+		t.Fatalf("Issue failed: %+v, %v", own, err)
+	}
+	if _, err := service.Issue("reports:write"); err == nil {
+		t.Error("Expected a scope this service lacks to be refused")
+	}
+}
@@ -0,0 +1,700 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Lockout defaults
+const (
+	DefaultMaxLoginFailures = 5
+	DefaultLockoutDuration  = 15 * time.Minute
+)
+
+// Two-factor settings
+const (
+	// ChallengeDuration is how long a sign-in waits for its second factor
+	ChallengeDuration = 5 * time.Minute
+	// RecoveryCodeCount is how many recovery codes a user is given
+	RecoveryCodeCount = 10
+	// TwoFactorIssuer names the service in authenticator apps
+	TwoFactorIssuer = "InsuranceStack"
+)
+
+// maxLoginAttempts is how many failed sign-ins the login audit keeps; older
+// ones are dropped
+const maxLoginAttempts = 1000
+
+// Credentials is the account that may sign in. In production these would
+// come from a user directory.
+type Credentials struct {
+	UserID       string
+	Email        string
+	Name         string
+	Role         string
+	PasswordHash string // bcrypt
+}
+
+// LockoutPolicy locks a username out after MaxFailures failed sign-ins in a
+// row, for Duration. MaxFailures 0 never locks.
+type LockoutPolicy struct {
+	MaxFailures int
+	Duration    time.Duration
+}
+
+// TwoFactorPolicy is how sign-ins use a second factor. Store keeps users'
+// enrollments; users with a role in Roles must sign in with a second factor,
+// enrolling on their next sign-in if they have not. Other users may enroll
+// themselves.
+type TwoFactorPolicy struct {
+	Store repository.TwoFactorStore
+	Roles []string
+}
+
+// Login is a successful sign-in, or one waiting on its second factor, when
+// only Challenge is set
+type Login struct {
+	Token     string
+	Session   *models.Session
+	Name      string
+	Challenge *Challenge
+}
+
+// Challenge is a sign-in whose password was right, waiting on the second
+// factor. When EnrollmentRequired is set, the user must enroll first.
+type Challenge struct {
+	ID                 string
+	EnrollmentRequired bool
+	ExpiresAt          time.Time
+}
+
+// Enrollment is a TOTP secret being enrolled, for the user to add to an
+// authenticator app by scanning OTPAuthURL as a QR code or typing Secret
+type Enrollment struct {
+	Secret     string
+	OTPAuthURL string
+}
+
+// loginChallenge is the state of a sign-in waiting on its second factor
+type loginChallenge struct {
+	key       string // lower-cased username, for the lockout
+	username  string
+	enroll    bool
+	expiresAt time.Time
+}
+
+// loginFailures counts a username's failed sign-ins since its last success
+type loginFailures struct {
+	count       int
+	lockedUntil time.Time
+}
+
+// SessionService signs users in, with a TOTP second factor where the
+// user enrolled or their role requires it, keeps track of the sessions their
+// tokens belong to so they can be ended remotely, and locks usernames out
+// after repeated failed sign-ins. Sessions and challenges are kept in
+// memory: a restart ends every session. Enrollments are stored.
+type SessionService struct {
+	tokens    *auth.JWTManager
+	account   Credentials
+	lockout   LockoutPolicy
+	twoFactor TwoFactorPolicy
+	logger    *logrus.Logger
+
+	mu         sync.Mutex
+	sessions   map[string]*models.Session
+	challenges map[string]*loginChallenge
+	failures   map[string]*loginFailures // by lower-cased username
+	attempts   []models.LoginAttempt     // oldest first
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(tokens *auth.JWTManager, account Credentials, lockout LockoutPolicy, twoFactor TwoFactorPolicy, logger *logrus.Logger) *SessionService {
+	return &SessionService{
+		tokens:     tokens,
+		account:    account,
+		lockout:    lockout,
+		twoFactor:  twoFactor,
+		logger:     logger,
+		sessions:   make(map[string]*models.Session),
+		challenges: make(map[string]*loginChallenge),
+		failures:   make(map[string]*loginFailures),
+	}
+}
+
+// Login checks a username and password and starts a session for the
+// device and IP signing in. Wrong credentials fail with "invalid
+// credentials"; a locked-out username fails with "account locked", even
+// with the right password, until LockedUntil. A user who must sign in with
+// a second factor gets a Challenge instead of a session, for VerifyLogin or,
+// when they still have to enroll, CompleteEnrollment.
+func (s *SessionService) Login(username, password, device, ip string) (*Login, error) {
+	key := strings.ToLower(strings.TrimSpace(username))
+	if err := s.refuseLocked(key, username, device, ip); err != nil {
+		return nil, err
+	}
+	// bcrypt is slow by design, so passwords are checked outside the lock
+	valid := key == strings.ToLower(s.account.Email) && auth.VerifyPassword(s.account.PasswordHash, password) == nil
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A lockout may have started while the password was checked
+	now := time.Now()
+	failures := s.failures[key]
+	if failures != nil && now.Before(failures.lockedUntil) {
+		return nil, fmt.Errorf("account locked")
+	}
+
+	if !valid {
+		s.fail(key, username, models.LoginInvalidCredentials, device, ip, now)
+		return nil, fmt.Errorf("invalid credentials")
+	}
+
+	tf, err := s.enrollment(s.account.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled := tf != nil && tf.Enabled; enrolled || s.requiresTwoFactor(s.account.Role) {
+		// The failures stand until the second factor is right too
+		id, err := newChallengeID()
+		if err != nil {
+			return nil, err
+		}
+		s.pruneChallenges(now)
+		challenge := &loginChallenge{key: key, username: username, enroll: !enrolled, expiresAt: now.Add(ChallengeDuration)}
+		s.challenges[id] = challenge
+		s.logger.WithFields(logrus.Fields{
+			"userId":             s.account.UserID,
+			"ip":                 ip,
+			"enrollmentRequired": challenge.enroll,
+		}).Info("Password accepted, waiting on second factor")
+		return &Login{Challenge: &Challenge{ID: id, EnrollmentRequired: challenge.enroll, ExpiresAt: challenge.expiresAt}}, nil
+	}
+	delete(s.failures, key)
+	return s.startSession(device, ip, now)
+}
+
+// VerifyLogin finishes a sign-in waiting on its second factor, with a code
+// from the authenticator app or, when recovery is set, one of the user's
+// recovery codes, which is used up. An unknown or expired challenge fails
+// with "login challenge not found" and a wrong code with "invalid code",
+// which counts towards the lockout like a wrong password. A user who still
+// has to enroll fails with "two-factor enrollment required".
+func (s *SessionService) VerifyLogin(challengeID, code string, recovery bool, device, ip string) (*Login, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	challenge, err := s.challenge(challengeID, device, ip, now)
+	if err != nil {
+		return nil, err
+	}
+	tf, err := s.enrollment(s.account.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.enroll || tf == nil || !tf.Enabled {
+		return nil, fmt.Errorf("two-factor enrollment required")
+	}
+
+	var valid bool
+	if recovery {
+		valid = useRecoveryCode(tf, code)
+	} else {
+		valid = useTOTPCode(tf, tf.Secret, code, now)
+	}
+	if !valid {
+		if s.fail(challenge.key, challenge.username, models.LoginInvalidCode, device, ip, now) {
+			delete(s.challenges, challengeID)
+		}
+		return nil, fmt.Errorf("invalid code")
+	}
+	if err := s.twoFactor.Store.SaveTwoFactor(tf); err != nil {
+		return nil, err
+	}
+	if recovery {
+		s.logger.WithFields(logrus.Fields{
+			"userId":            tf.UserID,
+			"ip":                ip,
+			"recoveryCodesLeft": len(tf.RecoveryCodes),
+		}).Warn("Signed in with a recovery code")
+	}
+
+	delete(s.challenges, challengeID)
+	delete(s.failures, challenge.key)
+	return s.startSession(device, ip, now)
+}
+
+// ChallengeUserID returns the user a sign-in waiting on its second factor
+// is for, so they can enroll with it, or fails with "login challenge not
+// found"
+func (s *SessionService) ChallengeUserID(challengeID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, exists := s.challenges[challengeID]
+	if !exists || !time.Now().Before(challenge.expiresAt) {
+		return "", fmt.Errorf("login challenge not found")
+	}
+	return s.account.UserID, nil
+}
+
+// Enroll starts a user's two-factor enrollment with a new TOTP secret,
+// replacing any enrollment in progress. It is confirmed by a code from the
+// secret, with ConfirmEnrollment or CompleteEnrollment. A user already
+// enrolled fails with "two-factor authentication already enabled".
+func (s *SessionService) Enroll(userID string) (*Enrollment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tf, err := s.enrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil {
+		tf = &models.TwoFactor{UserID: userID}
+	}
+	if tf.Enabled {
+		return nil, fmt.Errorf("two-factor authentication already enabled")
+	}
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	tf.PendingSecret = secret
+	if err := s.twoFactor.Store.SaveTwoFactor(tf); err != nil {
+		return nil, err
+	}
+	return &Enrollment{
+		Secret:     secret,
+		OTPAuthURL: auth.ProvisioningURI(TwoFactorIssuer, s.account.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment turns a signed-in user's two-factor authentication on
+// with a code from the secret being enrolled, and returns their recovery
+// codes, which are only ever shown this once. Without an enrollment in
+// progress it fails with "no two-factor enrollment in progress" and with a
+// wrong code with "invalid code".
+func (s *SessionService) ConfirmEnrollment(userID, code string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.confirm(userID, code, time.Now())
+}
+
+// CompleteEnrollment confirms the enrollment of a user signing in through a
+// challenge that requires it, as ConfirmEnrollment does, and finishes the
+// sign-in. A wrong code counts towards the lockout.
+func (s *SessionService) CompleteEnrollment(challengeID, code, device, ip string) (*Login, []string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	challenge, err := s.challenge(challengeID, device, ip, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	codes, err := s.confirm(s.account.UserID, code, now)
+	if err != nil {
+		if err.Error() == "invalid code" && s.fail(challenge.key, challenge.username, models.LoginInvalidCode, device, ip, now) {
+			delete(s.challenges, challengeID)
+		}
+		return nil, nil, err
+	}
+
+	delete(s.challenges, challengeID)
+	delete(s.failures, challenge.key)
+	login, err := s.startSession(device, ip, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return login, codes, nil
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes, such as when
+// they run low, given a code from their authenticator app. A user who is not
+// enrolled fails with "two-factor authentication not enabled" and a wrong
+// code with "invalid code".
+func (s *SessionService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tf, err := s.enrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || !tf.Enabled {
+		return nil, fmt.Errorf("two-factor authentication not enabled")
+	}
+	if !useTOTPCode(tf, tf.Secret, code, time.Now()) {
+		return nil, fmt.Errorf("invalid code")
+	}
+	codes, err := setRecoveryCodes(tf)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactor.Store.SaveTwoFactor(tf); err != nil {
+		return nil, err
+	}
+	s.logger.WithField("userId", userID).Info("Recovery codes regenerated")
+	return codes, nil
+}
+
+// TwoFactorStatus returns whether a user is enrolled and whether their role
+// requires it
+func (s *SessionService) TwoFactorStatus(userID, role string) (*models.TwoFactorStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tf, err := s.enrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &models.TwoFactorStatus{Required: s.requiresTwoFactor(role)}
+	if tf != nil && tf.Enabled {
+		status.Enabled = true
+		status.EnabledAt = tf.EnabledAt
+		status.RecoveryCodesLeft = len(tf.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// confirm turns on the enrollment in progress of userID. Callers hold mu.
+func (s *SessionService) confirm(userID, code string, now time.Time) ([]string, error) {
+	tf, err := s.enrollment(userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf == nil || tf.PendingSecret == "" {
+		return nil, fmt.Errorf("no two-factor enrollment in progress")
+	}
+	if !useTOTPCode(tf, tf.PendingSecret, code, now) {
+		return nil, fmt.Errorf("invalid code")
+	}
+	codes, err := setRecoveryCodes(tf)
+	if err != nil {
+		return nil, err
+	}
+	enabledAt := now
+	tf.Secret, tf.PendingSecret = tf.PendingSecret, ""
+	tf.Enabled = true
+	tf.EnabledAt = &enabledAt
+	if err := s.twoFactor.Store.SaveTwoFactor(tf); err != nil {
+		return nil, err
+	}
+	s.logger.WithField("userId", userID).Info("Two-factor authentication enabled")
+	return codes, nil
+}
+
+// challenge returns a live sign-in challenge, failing with "account
+// locked" while its username is locked out. Callers hold mu.
+func (s *SessionService) challenge(challengeID, device, ip string, now time.Time) (*loginChallenge, error) {
+	challenge, exists := s.challenges[challengeID]
+	if !exists || !now.Before(challenge.expiresAt) {
+		return nil, fmt.Errorf("login challenge not found")
+	}
+	if err := s.lockedOut(challenge.key, challenge.username, device, ip, now); err != nil {
+		delete(s.challenges, challengeID)
+		return nil, err
+	}
+	return challenge, nil
+}
+
+// enrollment returns a user's two-factor enrollment, or nil if they never
+// enrolled
+func (s *SessionService) enrollment(userID string) (*models.TwoFactor, error) {
+	tf, err := s.twoFactor.Store.GetTwoFactor(userID)
+	if err != nil {
+		if err.Error() == "two-factor enrollment not found" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read two-factor enrollment: %w", err)
+	}
+	return tf, nil
+}
+
+// requiresTwoFactor reports whether users with role must sign in with a
+// second factor
+func (s *SessionService) requiresTwoFactor(role string) bool {
+	for _, required := range s.twoFactor.Roles {
+		if role != "" && role == required {
+			return true
+		}
+	}
+	return false
+}
+
+// fail counts a failed sign-in for the username key, locking it out after
+// too many in a row, and audits it. It reports whether the username is now
+// locked out. Callers hold mu.
+func (s *SessionService) fail(key, username, outcome, device, ip string, now time.Time) bool {
+	failures := s.failures[key]
+	if failures == nil || !failures.lockedUntil.IsZero() {
+		// A lockout that has run out starts the count again
+		failures = &loginFailures{}
+		s.failures[key] = failures
+	}
+	failures.count++
+	attempt := models.LoginAttempt{Username: username, Outcome: outcome, Device: device, IP: ip, At: now, Failures: failures.count}
+	fields := logrus.Fields{
+		"username": username,
+		"outcome":  outcome,
+		"ip":       ip,
+		"failures": failures.count,
+	}
+	locked := s.lockout.MaxFailures > 0 && failures.count >= s.lockout.MaxFailures
+	if locked {
+		failures.lockedUntil = now.Add(s.lockout.Duration)
+		until := failures.lockedUntil
+		attempt.LockedUntil = &until
+		fields["lockedUntil"] = until
+		s.logger.WithFields(fields).Warn("Locked out username after repeated failed sign-ins")
+	} else {
+		s.logger.WithFields(fields).Warn("Failed sign-in")
+	}
+	s.audit(attempt)
+	return locked
+}
+
+// startSession starts a session for the account and issues its token.
+// Callers hold mu.
+func (s *SessionService) startSession(device, ip string, now time.Time) (*Login, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	token, err := s.tokens.GenerateForSession(s.account.UserID, s.account.Email, s.account.Role, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	session := &models.Session{
+		ID:         id,
+		UserID:     s.account.UserID,
+		Email:      s.account.Email,
+		Role:       s.account.Role,
+		Device:     device,
+		IP:         ip,
+		IssuedAt:   now,
+		LastSeenAt: now,
+		LastSeenIP: ip,
+		ExpiresAt:  now.Add(s.tokens.TokenDuration()),
+	}
+	s.prune(now)
+	s.sessions[id] = session
+
+	s.logger.WithFields(logrus.Fields{
+		"userId":    session.UserID,
+		"sessionId": id,
+		"ip":        ip,
+	}).Info("User logged in successfully")
+	copied := *session
+	return &Login{Token: token, Session: &copied, Name: s.account.Name}, nil
+}
+
+// refuseLocked fails with "account locked", auditing the attempt, while
+// the username key is locked out
+func (s *SessionService) refuseLocked(key, username, device, ip string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lockedOut(key, username, device, ip, time.Now())
+}
+
+// lockedOut is refuseLocked for callers holding mu
+func (s *SessionService) lockedOut(key, username, device, ip string, now time.Time) error {
+	failures := s.failures[key]
+	if failures == nil || !now.Before(failures.lockedUntil) {
+		return nil
+	}
+	until := failures.lockedUntil
+	s.audit(models.LoginAttempt{Username: username, Outcome: models.LoginLocked, Device: device, IP: ip, At: now, Failures: failures.count, LockedUntil: &until})
+	s.logger.WithFields(logrus.Fields{
+		"username":    username,
+		"ip":          ip,
+		"lockedUntil": until,
+	}).Warn("Refused sign-in for locked-out username")
+	return fmt.Errorf("account locked")
+}
+
+// LockedUntil returns when a username's lockout ends, or the zero time
+// when it is not locked out
+func (s *SessionService) LockedUntil(username string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.failures[strings.ToLower(strings.TrimSpace(username))]
+	if failures == nil || !time.Now().Before(failures.lockedUntil) {
+		return time.Time{}
+	}
+	return failures.lockedUntil
+}
+
+// Touch records a request made by a session's token from ip. A session
+// that was ended, expired or is not known, such as one from before a
+// restart, fails with "session not found".
+func (s *SessionService) Touch(sessionID, ip string) (*models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	now := time.Now()
+	if !exists || !now.Before(session.ExpiresAt) {
+		return nil, fmt.Errorf("session not found")
+	}
+	session.LastSeenAt = now
+	session.LastSeenIP = ip
+	copied := *session
+	return &copied, nil
+}
+
+// Sessions returns a user's live sessions, most recently seen first, with
+// Current set on currentID
+func (s *SessionService) Sessions(userID, currentID string) []*models.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(time.Now())
+	sessions := []*models.Session{}
+	for _, session := range s.sessions {
+		if session.UserID != userID {
+			continue
+		}
+		copied := *session
+		copied.Current = session.ID == currentID
+		sessions = append(sessions, &copied)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions
+}
+
+// Revoke ends a session, so its token is refused from then on. Users may
+// end their own sessions and admins anyone's; any other session is "session
+// not found".
+func (s *SessionService) Revoke(sessionID, userID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, exists := s.sessions[sessionID]
+	if !exists || (session.UserID != userID && role != "admin") {
+		return fmt.Errorf("session not found")
+	}
+	delete(s.sessions, sessionID)
+
+	s.logger.WithFields(logrus.Fields{
+		"sessionId": sessionID,
+		"userId":    session.UserID,
+		"revokedBy": userID,
+	}).Info("Session revoked")
+	return nil
+}
+
+// LoginAttempts returns the failed sign-ins in the login audit, newest
+// first, for one username or, when empty, every username
+func (s *SessionService) LoginAttempts(username string) []models.LoginAttempt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := []models.LoginAttempt{}
+	for i := len(s.attempts) - 1; i >= 0; i-- {
+		if username == "" || strings.EqualFold(s.attempts[i].Username, username) {
+			attempts = append(attempts, s.attempts[i])
+		}
+	}
+	return attempts
+}
+
+// audit adds a failed sign-in to the login audit. Callers hold mu.
+func (s *SessionService) audit(attempt models.LoginAttempt) {
+	s.attempts = append(s.attempts, attempt)
+	if len(s.attempts) > maxLoginAttempts {
+		s.attempts = s.attempts[len(s.attempts)-maxLoginAttempts:]
+	}
+}
+
+// prune drops expired sessions. Callers hold mu.
+func (s *SessionService) prune(now time.Time) {
+	for id, session := range s.sessions {
+		if !now.Before(session.ExpiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// pruneChallenges drops expired sign-in challenges. Callers hold mu.
+func (s *SessionService) pruneChallenges(now time.Time) {
+	for id, challenge := range s.challenges {
+		if !now.Before(challenge.expiresAt) {
+			delete(s.challenges, id)
+		}
+	}
+}
+
+// useTOTPCode checks a code from secret, refusing one already used, and
+// records it as used
+func useTOTPCode(tf *models.TwoFactor, secret, code string, now time.Time) bool {
+	counter, valid := auth.VerifyTOTP(secret, code, now)
+	if !valid || counter <= tf.LastCounter {
+		return false
+	}
+	tf.LastCounter = counter
+	return true
+}
+
+// useRecoveryCode checks a recovery code and uses it up
+func useRecoveryCode(tf *models.TwoFactor, code string) bool {
+	hash := auth.HashRecoveryCode(code)
+	for i, stored := range tf.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			tf.RecoveryCodes = append(tf.RecoveryCodes[:i:i], tf.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// setRecoveryCodes gives an enrollment new recovery codes, replacing the
+// old, and returns them
+func setRecoveryCodes(tf *models.TwoFactor) ([]string, error) {
+	codes, err := auth.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	tf.RecoveryCodes = make([]string, len(codes))
+	for i, code := range codes {
+		tf.RecoveryCodes[i] = auth.HashRecoveryCode(code)
+	}
+	return codes, nil
+}
+
+// newChallengeID returns an unguessable sign-in challenge ID
+func newChallengeID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate challenge ID: %w", err)
+	}
+	return "chal-" + hex.EncodeToString(b), nil
+}
+
+// newSessionID returns an unguessable session ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate session ID: %w", err)
+	}
+	return "sess-" + hex.EncodeToString(b), nil
+}
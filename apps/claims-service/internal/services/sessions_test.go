@@ -0,0 +1,195 @@
+package services
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// newTestSessionService signs in an adjuster, requiring a second factor
+// of the twoFactorRoles
+func newTestSessionService(t *testing.T, lockout LockoutPolicy, store repository.TwoFactorStore, twoFactorRoles ...string) (*SessionService, *auth.JWTManager) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	hash, err := auth.HashPassword("s3cret")
+	if err != nil {
+		t.Fatalf("HashPassword failed: %v", err)
+	}
+	tokens := auth.NewJWTManager("test-secret", time.Hour)
+	account := Credentials{UserID: "user-001", Email: "staff@example.com", Name: "Staff", Role: "adjuster", PasswordHash: hash}
+	return NewSessionService(tokens, account, lockout, TwoFactorPolicy{Store: store, Roles: twoFactorRoles}, logger), tokens
+}
+
+func TestSessionsCanBeListedAndRevoked(t *testing.T) {
+	service, tokens := newTestSessionService(t, LockoutPolicy{}, repositorytest.NewFakeStore())
+
+	laptop, err := service.Login("Staff@example.com", "s3cret", "Firefox", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	claims, err := tokens.Verify(laptop.Token)
+	if err != nil || claims.ID != laptop.Session.ID || claims.UserID != "user-001" {
+		t.Fatalf("Expected the token to name its session, got %+v, %v", claims, err)
+	}
+	phone, err := service.Login("staff@example.com", "s3cret", "Safari", "198.51.100.7")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if _, err := service.Touch(laptop.Session.ID, "192.0.2.99"); err != nil {
+		t.Fatalf("Touch failed: %v", err)
+	}
+
+	sessions := service.Sessions("user-001", phone.Session.ID)
+	if len(sessions) != 2 || sessions[0].ID != laptop.Session.ID || sessions[0].LastSeenIP != "192.0.2.99" || sessions[0].Current || !sessions[1].Current {
+		t.Fatalf("Unexpected sessions %+v", sessions)
+	}
+
+	// Only the owner or an admin may end a session
+	if err := service.Revoke(laptop.Session.ID, "user-002", "adjuster"); err == nil || err.Error() != "session not found" {
+		t.Errorf("Expected another user's session not to be found, got %v", err)
+	}
+	if err := service.Revoke(laptop.Session.ID, "user-001", ""); err != nil {
+		t.Fatalf("Revoke failed: %v", err)
+	}
+	if _, err := service.Touch(laptop.Session.ID, "192.0.2.1"); err == nil || err.Error() != "session not found" {
+		t.Errorf("Expected a revoked session to be refused, got %v", err)
+	}
+	if err := service.Revoke(phone.Session.ID, "adm-001", "admin"); err != nil {
+		t.Errorf("Expected an admin to end any session, got %v", err)
+	}
+	if sessions := service.Sessions("user-001", ""); len(sessions) != 0 {
+		t.Errorf("Expected no sessions left, got %+v", sessions)
+	}
+}
+
+func TestRepeatedFailedLoginsLockTheUsernameOut(t *testing.T) {
+	service, _ := newTestSessionService(t, LockoutPolicy{MaxFailures: 3, Duration: time.Hour}, repositorytest.NewFakeStore())
+
+	// A success clears the failures before it
+	if _, err := service.Login("staff@example.com", "wrong", "curl", "203.0.113.5"); err == nil || err.Error() != "invalid credentials" {
+		t.Fatalf("Expected invalid credentials, got %v", err)
+	}
+	if _, err := service.Login("staff@example.com", "s3cret", "curl", "203.0.113.5"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.Login("STAFF@example.com", "guess", "curl", "203.0.113.5"); err == nil || err.Error() != "invalid credentials" {
+			t.Fatalf("Attempt %d: expected invalid credentials, got %v", i+1, err)
+		}
+	}
+	if _, err := service.Login("staff@example.com", "s3cret", "Firefox", "192.0.2.1"); err == nil || err.Error() != "account locked" {
+		t.Fatalf("Expected the right password to be refused while locked out, got %v", err)
+	}
+	if until := service.LockedUntil("staff@example.com"); time.Until(until) < 59*time.Minute {
+		t.Errorf("Unexpected lockout end %v", until)
+	}
+	if _, err := service.Login("nobody@example.com", "s3cret", "curl", "203.0.113.5"); err == nil || err.Error() != "invalid credentials" {
+		t.Errorf("Expected other usernames not to be locked out, got %v", err)
+	}
+
+	attempts := service.LoginAttempts("staff@example.com")
+	if len(attempts) != 5 {
+		t.Fatalf("Expected 5 failed attempts, got %+v", attempts)
+	}
+	if locked := attempts[0]; locked.Outcome != models.LoginLocked || locked.IP != "192.0.2.1" || locked.LockedUntil == nil {
+		t.Errorf("Unexpected refused attempt %+v", locked)
+	}
+	if locking := attempts[1]; locking.Outcome != models.LoginInvalidCredentials || locking.Failures != 3 || locking.LockedUntil == nil {
+		t.Errorf("Unexpected locking attempt %+v", locking)
+	}
+	if first := attempts[4]; first.Failures != 1 || first.LockedUntil != nil {
+		t.Errorf("Unexpected first attempt %+v", first)
+	}
+	if all := service.LoginAttempts(""); len(all) != 6 {
+		t.Errorf("Expected 6 failed attempts in all, got %d", len(all))
+	}
+}
+
+func TestAdjustersMustSignInWithASecondFactor(t *testing.T) {
+	store := repositorytest.NewFakeStore()
+	service, tokens := newTestSessionService(t, LockoutPolicy{MaxFailures: 3, Duration: time.Hour}, store, "admin", "adjuster")
+
+	// The first sign-in has to enroll before it gets a session
+	login, err := service.Login("staff@example.com", "s3cret", "Firefox", "192.0.2.1")
+	if err != nil || login.Token != "" || login.Challenge == nil || !login.Challenge.EnrollmentRequired {
+		t.Fatalf("Expected a challenge requiring enrollment, got %+v, %v", login, err)
+	}
+	challenge := login.Challenge.ID
+	if _, err := service.VerifyLogin(challenge, "123456", false, "Firefox", "192.0.2.1"); err == nil || err.Error() != "two-factor enrollment required" {
+		t.Fatalf("Expected enrollment to be required, got %v", err)
+	}
+	userID, err := service.ChallengeUserID(challenge)
+	if err != nil || userID != "user-001" {
+		t.Fatalf("Unexpected challenge user %q, %v", userID, err)
+	}
+	enrollment, err := service.Enroll(userID)
+	if err != nil || !strings.HasPrefix(enrollment.OTPAuthURL, "otpauth://totp/InsuranceStack:staff@example.com?") {
+		t.Fatalf("Unexpected enrollment %+v, %v", enrollment, err)
+	}
+
+	now := auth.TOTPCounter(time.Now())
+	stale, _ := auth.TOTPCode(enrollment.Secret, now-10)
+	if _, _, err := service.CompleteEnrollment(challenge, stale, "Firefox", "192.0.2.1"); err == nil || err.Error() != "invalid code" {
+		t.Fatalf("Expected a stale code to be refused, got %v", err)
+	}
+	code, _ := auth.TOTPCode(enrollment.Secret, now)
+	login, recoveryCodes, err := service.CompleteEnrollment(challenge, code, "Firefox", "192.0.2.1")
+	if err != nil || len(recoveryCodes) != RecoveryCodeCount {
+		t.Fatalf("CompleteEnrollment failed: %v, %v", recoveryCodes, err)
+	}
+	if claims, err := tokens.Verify(login.Token); err != nil || claims.Role != "adjuster" || claims.ID != login.Session.ID {
+		t.Fatalf("Expected an adjuster's session token, got %+v, %v", claims, err)
+	}
+
+	// Enrollment outlives the service, and each code signs in once
+	service, _ = newTestSessionService(t, LockoutPolicy{MaxFailures: 3, Duration: time.Hour}, store)
+	login, err = service.Login("staff@example.com", "s3cret", "Safari", "198.51.100.7")
+	if err != nil || login.Challenge == nil || login.Challenge.EnrollmentRequired {
+		t.Fatalf("Expected a second-factor challenge for an enrolled user, got %+v, %v", login, err)
+	}
+	challenge = login.Challenge.ID
+	if _, err := service.VerifyLogin(challenge, code, false, "Safari", "198.51.100.7"); err == nil || err.Error() != "invalid code" {
+		t.Fatalf("Expected a used code to be refused, got %v", err)
+	}
+	if login, err = service.VerifyLogin(challenge, strings.ToLower(recoveryCodes[0]), true, "Safari", "198.51.100.7"); err != nil || login.Token == "" {
+		t.Fatalf("Expected a recovery code to sign in, got %+v, %v", login, err)
+	}
+	if _, err := service.VerifyLogin(challenge, code, false, "Safari", "198.51.100.7"); err == nil || err.Error() != "login challenge not found" {
+		t.Errorf("Expected a finished challenge to be gone, got %v", err)
+	}
+	if status, err := service.TwoFactorStatus("user-001", "adjuster"); err != nil || !status.Enabled || status.Required || status.RecoveryCodesLeft != RecoveryCodeCount-1 {
+		t.Errorf("Unexpected status %+v, %v", status, err)
+	}
+
+	next, _ := auth.TOTPCode(enrollment.Secret, now+1)
+	regenerated, err := service.RegenerateRecoveryCodes("user-001", next)
+	if err != nil || len(regenerated) != RecoveryCodeCount {
+		t.Fatalf("RegenerateRecoveryCodes failed: %v, %v", regenerated, err)
+	}
+
+	// Wrong second factors lock the username out like wrong passwords
+	login, _ = service.Login("staff@example.com", "s3cret", "curl", "203.0.113.5")
+	for i := 0; i < 3; i++ {
+		if _, err := service.VerifyLogin(login.Challenge.ID, recoveryCodes[0], true, "curl", "203.0.113.5"); err == nil || err.Error() != "invalid code" {
+			t.Fatalf("Attempt %d: expected an old recovery code to be refused, got %v", i+1, err)
+		}
+	}
+	if _, err := service.VerifyLogin(login.Challenge.ID, regenerated[0], true, "curl", "203.0.113.5"); err == nil || err.Error() != "login challenge not found" {
+		t.Errorf("Expected the challenge to end with the lockout, got %v", err)
+	}
+	if _, err := service.Login("staff@example.com", "s3cret", "curl", "203.0.113.5"); err == nil || err.Error() != "account locked" {
+		t.Errorf("Expected the username to be locked out, got %v", err)
+	}
+	if attempts := service.LoginAttempts(""); len(attempts) != 5 || attempts[1].Outcome != models.LoginInvalidCode || attempts[1].LockedUntil == nil {
+		t.Errorf("Unexpected login audit %+v", attempts)
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// PayoutLookup reads claim payouts from payments-service.
+// *clients.PaymentsClient is the production implementation.
+type PayoutLookup interface {
+	ListPayouts(ctx context.Context, claimID string) ([]clients.Payout, error)
+}
+
+var _ PayoutLookup = (*clients.PaymentsClient)(nil)
+
+// TimelineService assembles a claim's history from the claim changes
+// recorded by this service and the payouts held by payments-service
+type TimelineService struct {
+	repo     repository.ClaimStore
+	payouts  PayoutLookup
+	fallback breaker.Fallback // when payments-service cannot answer
+	logger   *logrus.Logger
+}
+
+// NewTimelineService creates a timeline service. payouts may be nil when
+// payments-service is not configured; timelines are then returned without
+// payout entries and with a warning saying so. fallback is what a timeline
+// does when payments-service cannot answer: breaker.FailOpen, or empty,
+// leaves payouts out with a warning, and breaker.FailClosed fails it.
+func NewTimelineService(repo repository.ClaimStore, payouts PayoutLookup, fallback breaker.Fallback, logger *logrus.Logger) *TimelineService {
+	return &TimelineService{
+		repo:     repo,
+		payouts:  payouts,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+// GetTimeline returns a claim's timeline, oldest entry first. Claims loaded
+// from seed data have no recorded history, so their filing and review are
+// taken from the claim's dates. A payments-service failure leaves payouts
+// out of the timeline, or fails it with "payments-service unavailable" when
+// the timeline fails closed.
+func (s *TimelineService) GetTimeline(ctx context.Context, claimID string) (*models.ClaimTimeline, error) {
+	claim, err := s.repo.GetClaimByID(claimID)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := s.repo.GetTimelineEntries(claimID)
+	entries := make([]models.TimelineEntry, 0, len(recorded)+2)
+	entries = append(entries, historyBefore(claim, recorded)...)
+	entries = append(entries, recorded...)
+
+	timeline := &models.ClaimTimeline{
+		ClaimID:     claim.ID,
+		ClaimNumber: claim.ClaimNumber,
+	}
+
+	if s.payouts == nil {
+		timeline.Warnings = append(timeline.Warnings, "payouts unavailable: payments-service is not configured")
+	} else if payouts, err := s.payouts.ListPayouts(ctx, claimID); err != nil {
+		if s.fallback == breaker.FailClosed {
+			s.logger.WithError(err).WithField("claimId", claimID).Warn("Failed to fetch payouts, refusing timeline")
+			return nil, fmt.Errorf("payments-service unavailable")
+		}
+		s.logger.WithError(err).WithField("claimId", claimID).Warn("Failed to fetch payouts for timeline")
+		timeline.Warnings = append(timeline.Warnings, "payouts unavailable: payments-service could not be reached")
+	} else {
+		for _, payout := range payouts {
+			entries = append(entries, payoutEntries(payout)...)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	timeline.Entries = entries
+
+	return timeline, nil
+}
+
+// historyBefore returns the entries for a claim's filing and review when
+// they were not recorded, as for claims loaded from seed data
+func historyBefore(claim *models.Claim, recorded []models.TimelineEntry) []models.TimelineEntry {
+	var created, statusChanged bool
+	for _, entry := range recorded {
+		switch entry.Type {
+		case events.ClaimCreated:
+			created = true
+		case events.ClaimStatusChanged:
+			statusChanged = true
+		}
+	}
+	if created {
+		return nil
+	}
+
+	entries := []models.TimelineEntry{{
+		Timestamp: claim.SubmittedDate,
+		Type:      events.ClaimCreated,
+		Source:    models.TimelineSourceClaims,
+		ClaimID:   claim.ID,
+		Summary:   fmt.Sprintf("Claim %s filed", claim.ClaimNumber),
+		NewStatus: "submitted",
+	}}
+	if claim.ReviewedDate != nil && !statusChanged {
+		entries = append(entries, models.TimelineEntry{
+			Timestamp: *claim.ReviewedDate,
+			Type:      events.ClaimStatusChanged,
+			Source:    models.TimelineSourceClaims,
+			ClaimID:   claim.ID,
+			Summary:   fmt.Sprintf("Status changed to %s", claim.Status),
+			NewStatus: claim.Status,
+		})
+	}
+	return entries
+}
+
+// timelineEntryFor converts a published claim event into a timeline entry
+func timelineEntryFor(evt events.Event) models.TimelineEntry {
+	entry := models.TimelineEntry{
+		Timestamp:  evt.Timestamp,
+		Type:       evt.Type,
+		Source:     models.TimelineSourceClaims,
+		ClaimID:    evt.ClaimID,
+		NewStatus:  evt.NewStatus,
+		Queue:      evt.Queue,
+		AssignedTo: evt.AssignedTo,
+		Reason:     evt.Reason,
+	}
+
+	switch evt.Type {
+	case events.ClaimCreated:
+		entry.Summary = fmt.Sprintf("Claim %s filed", evt.ClaimNumber)
+		if evt.Queue != "" {
+			entry.Summary += fmt.Sprintf(" and routed to the %s queue", evt.Queue)
+		}
+	case events.ClaimStatusChanged:
+		entry.OldStatus = evt.OldStatus
+		entry.Summary = fmt.Sprintf("Status changed from %s to %s", evt.OldStatus, evt.NewStatus)
+		if len(evt.RejectionCodes) > 0 {
+			entry.Reason = strings.Join(evt.RejectionCodes, ", ")
+		}
+	case events.ClaimAssigned:
+		entry.Summary = fmt.Sprintf("Assigned to %s", evt.AssignedTo)
+	case events.ClaimEscalated:
+		entry.Summary = "Escalated for senior review"
+	case events.DocumentUploaded:
+		entry.DocumentID = evt.DocumentID
+		entry.FileName = evt.FileName
+		entry.Summary = fmt.Sprintf("Document %s uploaded", evt.FileName)
+	case events.DocumentQuarantined:
+		entry.DocumentID = evt.DocumentID
+		entry.FileName = evt.FileName
+		entry.Summary = fmt.Sprintf("Document %s quarantined by its malware scan", evt.FileName)
+	case events.OfferMade:
+		entry.OfferID, entry.Amount = evt.OfferID, evt.Amount
+		entry.Summary = fmt.Sprintf("Settlement offer of $%.2f made", evt.Amount)
+	case events.OfferAccepted:
+		entry.OfferID, entry.Amount = evt.OfferID, evt.Amount
+		entry.Summary = fmt.Sprintf("Settlement offer of $%.2f accepted", evt.Amount)
+	case events.OfferRejected:
+		entry.OfferID, entry.Amount = evt.OfferID, evt.Amount
+		entry.Summary = fmt.Sprintf("Settlement offer of $%.2f rejected", evt.Amount)
+	default:
+		entry.Summary = evt.Type
+	}
+
+	return entry
+}
+
+// payoutEntries returns the entries for a payout: its request and, once
+// processed, its outcome
+func payoutEntries(payout clients.Payout) []models.TimelineEntry {
+	entries := []models.TimelineEntry{{
+		Timestamp:     payout.CreatedAt,
+		Type:          models.TimelinePayoutRequested,
+		Source:        models.TimelineSourcePayments,
+		ClaimID:       payout.ClaimID,
+		Summary:       fmt.Sprintf("Payout of $%.2f requested", payout.Amount),
+		PaymentID:     payout.ID,
+		Amount:        payout.Amount,
+		PaymentStatus: payout.Status,
+	}}
+	if payout.ProcessedDate == nil {
+		return entries
+	}
+
+	outcome := entries[0]
+	outcome.Timestamp = *payout.ProcessedDate
+	switch payout.Status {
+	case "completed":
+		outcome.Type = models.TimelinePayoutCompleted
+		outcome.Summary = fmt.Sprintf("Payout of $%.2f completed", payout.Amount)
+	case "failed":
+		outcome.Type = models.TimelinePayoutFailed
+		outcome.Summary = fmt.Sprintf("Payout of $%.2f failed", payout.Amount)
+	default:
+		return entries
+	}
+	return append(entries, outcome)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/breaker"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// stubPayouts is a PayoutLookup returning fixed payouts
+type stubPayouts struct {
+	payouts []clients.Payout
+	err     error
+}
+
+func (s *stubPayouts) ListPayouts(ctx context.Context, claimID string) ([]clients.Payout, error) {
+	return s.payouts, s.err
+}
+
+func TestTimelineMergesClaimHistoryAndPayouts(t *testing.T) {
+	submitted := time.Now().Add(-72 * time.Hour)
+	service, store, _ := newTestService(t, false, &models.Claim{ID: "claim-001", ClaimNumber: "CLM-2024-000001", CustomerID: "cust-001", Status: "submitted", SubmittedDate: submitted})
+
+	if _, err := service.AssignClaim("claim-001", &models.AssignClaimRequest{AdjusterID: "adj-001"}); err != nil {
+		t.Fatalf("AssignClaim failed: %v", err)
+	}
+	doc, err := service.UploadDocument("claim-001", "cust-001", `C:\photos\bumper.jpg`, "", []byte("\xff\xd8\xff\xe0 jpeg"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if doc.FileName != "bumper.jpg" || doc.ContentType != "image/jpeg" {
+		t.Errorf("Unexpected document: %+v", doc)
+	}
+	if _, err := service.UpdateClaimStatus("claim-001", &models.UpdateClaimStatusRequest{Status: "approved"}); err != nil {
+		t.Fatalf("UpdateClaimStatus failed: %v", err)
+	}
+
+	// The payout settled after everything recorded above
+	requested, settled := submitted.Add(time.Hour), time.Now().Add(time.Hour)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	timelines := NewTimelineService(store, &stubPayouts{payouts: []clients.Payout{
+		{ID: "pay-002", Type: "payout", ClaimID: "claim-001", Amount: 4500, Status: "completed", CreatedAt: requested, ProcessedDate: &settled},
+	}}, "", logger)
+
+	timeline, err := timelines.GetTimeline(context.Background(), "claim-001")
+	if err != nil {
+		t.Fatalf("GetTimeline failed: %v", err)
+	}
+	want := []string{
+		events.ClaimCreated,
+		models.TimelinePayoutRequested,
+		events.ClaimAssigned,
+		events.DocumentUploaded,
+		events.ClaimStatusChanged,
+		models.TimelinePayoutCompleted,
+	}
+	if len(timeline.Entries) != len(want) || len(timeline.Warnings) != 0 {
+		t.Fatalf("Unexpected timeline: %+v", timeline)
+	}
+	for i, entry := range timeline.Entries {
+		if entry.Type != want[i] {
+			t.Errorf("Entry %d: got %s, want %s", i, entry.Type, want[i])
+		}
+	}
+	if entry := timeline.Entries[3]; entry.DocumentID != doc.ID || entry.Source != models.TimelineSourceClaims {
+		t.Errorf("Unexpected document entry: %+v", entry)
+	}
+	if entry := timeline.Entries[5]; entry.PaymentID != "pay-002" || entry.Source != models.TimelineSourcePayments {
+		t.Errorf("Unexpected payout entry: %+v", entry)
+	}
+}
+
+func TestTimelineWithoutPayments(t *testing.T) {
+	submitted := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	reviewed := submitted.Add(72 * time.Hour)
+	_, store, _ := newTestService(t, false, &models.Claim{ID: "claim-001", ClaimNumber: "CLM-2024-000001", Status: "approved", SubmittedDate: submitted, ReviewedDate: &reviewed})
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// Seed claims have no recorded history, so filing and review come from their dates
+	timeline, err := NewTimelineService(store, &stubPayouts{err: errors.New("connection refused")}, "", logger).GetTimeline(context.Background(), "claim-001")
+	if err != nil {
+		t.Fatalf("GetTimeline failed: %v", err)
+	}
+	if len(timeline.Entries) != 2 || !timeline.Entries[1].Timestamp.Equal(reviewed) || timeline.Entries[1].NewStatus != "approved" {
+		t.Errorf("Unexpected entries: %+v", timeline.Entries)
+	}
+	if len(timeline.Warnings) != 1 {
+		t.Errorf("Expected a payouts warning, got %v", timeline.Warnings)
+	}
+
+	if _, err := NewTimelineService(store, nil, "", logger).GetTimeline(context.Background(), "claim-404"); err == nil || err.Error() != "claim not found" {
+		t.Errorf("Expected claim not found, got %v", err)
+	}
+
+	// Failing closed refuses the timeline instead
+	failing := &stubPayouts{err: fmt.Errorf("payments-service request failed: %w", breaker.ErrOpen)}
+	if _, err := NewTimelineService(store, failing, breaker.FailClosed, logger).GetTimeline(context.Background(), "claim-001"); err == nil || err.Error() != "payments-service unavailable" {
+		t.Errorf("Expected payments-service unavailable, got %v", err)
+	}
+}
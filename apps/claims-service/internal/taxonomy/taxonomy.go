@@ -0,0 +1,205 @@
+// Package taxonomy holds the claim types and sub-types each policy type
+// accepts. The taxonomy is configuration, loaded from claim-types.json, so
+// a new kind of loss does not need a release.
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// codePattern is the form of claim type and sub-type codes
+var codePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Taxonomy is the claim types of each policy type
+type Taxonomy struct {
+	Version     string                        `json:"version"`
+	PolicyTypes map[string][]models.ClaimType `json:"policyTypes"`
+}
+
+// Default returns the taxonomy used when none is configured: accident,
+// theft and damage, without sub-types, for every policy line
+func Default() *Taxonomy {
+	types := []models.ClaimType{
+		{Code: "accident", Label: "Accident", SubTypes: []models.ClaimSubType{}},
+		{Code: "theft", Label: "Theft", SubTypes: []models.ClaimSubType{}},
+		{Code: "damage", Label: "Damage", SubTypes: []models.ClaimSubType{}},
+	}
+	return &Taxonomy{
+		Version: "default",
+		PolicyTypes: map[string][]models.ClaimType{
+			"auto": types,
+			"home": types,
+			"life": types,
+		},
+	}
+}
+
+// Load reads and validates a taxonomy file
+func Load(path string) (*Taxonomy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var t Taxonomy
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid claim types in %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("invalid claim types in %s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// validate checks every policy type has claim types, and that codes are
+// well formed and not repeated
+func (t *Taxonomy) validate() error {
+	if len(t.PolicyTypes) == 0 {
+		return fmt.Errorf("no policy types")
+	}
+	for policyType, types := range t.PolicyTypes {
+		if len(types) == 0 {
+			return fmt.Errorf("policy type %s has no claim types", policyType)
+		}
+		seen := make(map[string]bool, len(types))
+		for i, claimType := range types {
+			if !codePattern.MatchString(claimType.Code) {
+				return fmt.Errorf("%s claim type %q must be lowercase letters, digits and underscores", policyType, claimType.Code)
+			}
+			if seen[claimType.Code] {
+				return fmt.Errorf("%s claim type %s is listed twice", policyType, claimType.Code)
+			}
+			seen[claimType.Code] = true
+
+			subSeen := make(map[string]bool, len(claimType.SubTypes))
+			for _, subType := range claimType.SubTypes {
+				if !codePattern.MatchString(subType.Code) {
+					return fmt.Errorf("%s sub-type %q of %s must be lowercase letters, digits and underscores", policyType, subType.Code, claimType.Code)
+				}
+				if subSeen[subType.Code] {
+					return fmt.Errorf("%s sub-type %s of %s is listed twice", policyType, subType.Code, claimType.Code)
+				}
+				subSeen[subType.Code] = true
+			}
+			if claimType.SubTypes == nil {
+				types[i].SubTypes = []models.ClaimSubType{}
+			}
+		}
+	}
+	return nil
+}
+
+// Types returns the claim types filed against a policy type, and whether
+// the policy type is in the taxonomy
+func (t *Taxonomy) Types(policyType string) ([]models.ClaimType, bool) {
+	types, ok := t.PolicyTypes[policyType]
+	return types, ok
+}
+
+// Check validates a claim type and optional sub-type for a policy type.
+// Claims against a policy of unknown type may use any type in the
+// taxonomy, with the sub-types any policy type gives it.
+func (t *Taxonomy) Check(policyType, claimType, subType string) error {
+	types, ok := t.PolicyTypes[policyType]
+	if !ok {
+		policyType = ""
+		types = t.merged()
+	}
+
+	for _, candidate := range types {
+		if candidate.Code != claimType {
+			continue
+		}
+		if subType == "" {
+			return nil
+		}
+		for _, sub := range candidate.SubTypes {
+			if sub.Code == subType {
+				return nil
+			}
+		}
+		if len(candidate.SubTypes) == 0 {
+			return fmt.Errorf("invalid claim sub-type: %s (%s claims have no sub-types)", subType, claimType)
+		}
+		return fmt.Errorf("invalid claim sub-type: %s (must be %s)", subType, oneOf(subTypeCodes(candidate.SubTypes)))
+	}
+
+	if policyType == "" {
+		return fmt.Errorf("invalid claim type: %s (must be %s)", claimType, oneOf(typeCodes(types)))
+	}
+	return fmt.Errorf("invalid claim type: %s for %s policies (must be %s)", claimType, policyType, oneOf(typeCodes(types)))
+}
+
+// merged returns every claim type in the taxonomy, each with the sub-types
+// of every policy type that has it
+func (t *Taxonomy) merged() []models.ClaimType {
+	policyTypes := make([]string, 0, len(t.PolicyTypes))
+	for policyType := range t.PolicyTypes {
+		policyTypes = append(policyTypes, policyType)
+	}
+	sort.Strings(policyTypes)
+
+	var merged []models.ClaimType
+	index := make(map[string]int)
+	for _, policyType := range policyTypes {
+		for _, claimType := range t.PolicyTypes[policyType] {
+			i, ok := index[claimType.Code]
+			if !ok {
+				index[claimType.Code] = len(merged)
+				merged = append(merged, models.ClaimType{Code: claimType.Code, Label: claimType.Label})
+				i = len(merged) - 1
+			}
+			for _, sub := range claimType.SubTypes {
+				if !hasSubType(merged[i].SubTypes, sub.Code) {
+					merged[i].SubTypes = append(merged[i].SubTypes, sub)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func hasSubType(subTypes []models.ClaimSubType, code string) bool {
+	for _, sub := range subTypes {
+		if sub.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func typeCodes(types []models.ClaimType) []string {
+	codes := make([]string, len(types))
+	for i, claimType := range types {
+		codes[i] = claimType.Code
+	}
+	return codes
+}
+
+func subTypeCodes(subTypes []models.ClaimSubType) []string {
+	codes := make([]string, len(subTypes))
+	for i, sub := range subTypes {
+		codes[i] = sub.Code
+	}
+	return codes
+}
+
+// oneOf lists codes as "a, b, or c"
+func oneOf(codes []string) string {
+	switch len(codes) {
+	case 0:
+		return "none"
+	case 1:
+		return codes[0]
+	case 2:
+		return codes[0] + " or " + codes[1]
+	}
+	return strings.Join(codes[:len(codes)-1], ", ") + ", or " + codes[len(codes)-1]
+}
@@ -0,0 +1,77 @@
+package taxonomy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSeedTaxonomy(t *testing.T) {
+	types, err := Load(filepath.Join("..", "..", "..", "..", "data", "seed", "claim-types.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		policyType, claimType, subType string
+		wantErr                        string
+	}{
+		{"home", "damage", "water_damage", ""},
+		{"auto", "damage", "windshield", ""},
+		{"auto", "accident", "", ""},
+		{"life", "terminal_illness", "", ""},
+		{"home", "damage", "windshield", "invalid claim sub-type: windshield (must be water_damage, fire, storm, hail, flood, subsidence, or vandalism)"},
+		{"life", "terminal_illness", "natural", "invalid claim sub-type: natural (terminal_illness claims have no sub-types)"},
+		{"life", "accident", "", "invalid claim type: accident for life policies (must be death or terminal_illness)"},
+		{"", "liability", "injury_on_premises", ""},
+		{"", "parking", "", "invalid claim type: parking (must be accident, theft, damage, liability, death, or terminal_illness)"},
+	}
+	for _, tt := range tests {
+		err := types.Check(tt.policyType, tt.claimType, tt.subType)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("Check(%q, %q, %q) failed: %v", tt.policyType, tt.claimType, tt.subType, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Errorf("Check(%q, %q, %q) = %v, want %q", tt.policyType, tt.claimType, tt.subType, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoadRejectsInvalidTaxonomies(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no policy types", `{"policyTypes": {}}`, "no policy types"},
+		{"no claim types", `{"policyTypes": {"home": []}}`, "policy type home has no claim types"},
+		{"bad code", `{"policyTypes": {"home": [{"code": "Water Damage"}]}}`, `home claim type "Water Damage" must be lowercase`},
+		{"repeated type", `{"policyTypes": {"home": [{"code": "damage"}, {"code": "damage"}]}}`, "home claim type damage is listed twice"},
+		{"repeated sub-type", `{"policyTypes": {"home": [{"code": "damage", "subTypes": [{"code": "fire"}, {"code": "fire"}]}]}}`, "home sub-type fire of damage is listed twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "claim-types.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write taxonomy: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDefaultKeepsTheOriginalTypes(t *testing.T) {
+	types := Default()
+	for _, policyType := range []string{"auto", "home", "life", ""} {
+		for _, claimType := range []string{"accident", "theft", "damage"} {
+			if err := types.Check(policyType, claimType, ""); err != nil {
+				t.Errorf("Check(%q, %q) failed: %v", policyType, claimType, err)
+			}
+		}
+	}
+	if err := types.Check("auto", "flood", ""); err == nil || err.Error() != "invalid claim type: flood for auto policies (must be accident, theft, or damage)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
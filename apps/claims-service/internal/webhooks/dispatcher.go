@@ -0,0 +1,325 @@
+// Package webhooks delivers claim events to external HTTP endpoints.
+// Deliveries that keep failing are parked in a dead-letter queue, where
+// staff can inspect and replay them.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers sent with every delivery. The signature header holds one
+// HMAC-SHA256 of the body per secret (see pkg/signing), sent when secrets
+// are set.
+const (
+	HeaderEventType = "X-Event-Type"
+	HeaderEventID   = "X-Event-ID"
+	HeaderSignature = signing.Header
+)
+
+// Config configures a Dispatcher
+type Config struct {
+	URLs         []string      // endpoints that receive every claim event
+	Secrets      signing.Keys  // each signs delivery bodies; none sends them unsigned
+	MaxAttempts  int           // attempts per delivery before it is dead-lettered
+	RetryBackoff time.Duration // wait before the first retry, doubled after each
+	Timeout      time.Duration // per attempt
+	Workers      int           // concurrent deliveries
+	QueueSize    int           // deliveries waiting for a worker
+	DLQCapacity  int
+}
+
+// Stats counts deliveries since startup
+type Stats struct {
+	Delivered      int64 // deliveries that succeeded, including after retries
+	FailedAttempts int64 // attempts that failed, whether or not a retry followed
+	DeadLettered   int64
+	DLQDropped     int64 // dead letters evicted because the queue was full
+	Replayed       int64 // replays that succeeded
+	ReplayFailed   int64
+	DLQDepth       int
+}
+
+type delivery struct {
+	endpoint string
+	evt      events.Event
+	payload  []byte
+}
+
+// Dispatcher subscribes to the event bus and POSTs each event to every
+// configured endpoint, retrying with exponential backoff
+type Dispatcher struct {
+	bus    *events.Bus
+	cfg    Config
+	client *http.Client
+	dlq    *DeadLetterQueue
+	jobs   chan delivery
+	done   chan struct{}
+	logger *logrus.Logger
+
+	delivered      int64
+	failedAttempts int64
+	deadLettered   int64
+	dlqDropped     int64
+	replayed       int64
+	replayFailed   int64
+}
+
+// NewDispatcher creates a dispatcher. Missing settings fall back to five
+// attempts, a one second backoff, a five second timeout and four workers.
+func NewDispatcher(bus *events.Bus, cfg Config, logger *logrus.Logger) *Dispatcher {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	return &Dispatcher{
+		bus:    bus,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		dlq:    NewDeadLetterQueue(cfg.DLQCapacity),
+		jobs:   make(chan delivery, cfg.QueueSize),
+		done:   make(chan struct{}),
+		logger: logger,
+	}
+}
+
+// Run delivers events until ctx is cancelled. With no endpoints configured
+// it returns immediately.
+func (d *Dispatcher) Run(ctx context.Context) {
+	defer close(d.done)
+	if len(d.cfg.URLs) == 0 {
+		return
+	}
+
+	_, sub := d.bus.Subscribe(nil, 0, 256)
+	defer sub.Close()
+
+	var workers sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range d.jobs {
+				d.deliver(ctx, job)
+			}
+		}()
+	}
+
+	d.logger.WithField("endpoints", len(d.cfg.URLs)).Info("Webhook dispatcher started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			close(d.jobs)
+			workers.Wait()
+			d.logger.Info("Webhook dispatcher stopped")
+			return
+
+		case evt := <-sub.C:
+			payload, err := json.Marshal(evt)
+			if err != nil {
+				d.logger.WithError(err).WithField("eventId", evt.ID).Error("Failed to encode webhook payload")
+				continue
+			}
+			for _, endpoint := range d.cfg.URLs {
+				job := delivery{endpoint: endpoint, evt: evt, payload: payload}
+				select {
+				case d.jobs <- job:
+				default:
+					// Never block the bus subscription; a backlog this deep
+					// means the endpoints are down, so park the event instead
+					d.deadLetter(job, 0, "delivery queue full", 0)
+				}
+			}
+		}
+	}
+}
+
+// Wait blocks until Run has returned
+func (d *Dispatcher) Wait() {
+	<-d.done
+}
+
+// deliver attempts one delivery until it succeeds, runs out of attempts or
+// ctx is cancelled. Deliveries still retrying at shutdown are dead-lettered
+// so they are not lost silently.
+func (d *Dispatcher) deliver(ctx context.Context, job delivery) {
+	backoff := d.cfg.RetryBackoff
+	var (
+		status int
+		err    error
+	)
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		status, err = d.send(ctx, job.endpoint, job.evt, job.payload)
+		if err == nil {
+			atomic.AddInt64(&d.delivered, 1)
+			return
+		}
+		atomic.AddInt64(&d.failedAttempts, 1)
+		d.logger.WithError(err).WithFields(logrus.Fields{
+			"endpoint": job.endpoint,
+			"eventId":  job.evt.ID,
+			"attempt":  attempt,
+		}).Warn("Webhook delivery failed")
+
+		if attempt == d.cfg.MaxAttempts {
+			d.deadLetter(job, attempt, err.Error(), status)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			d.deadLetter(job, attempt, err.Error(), status)
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send makes one delivery attempt. Any response other than 2xx is a failure;
+// the status is returned when a response was received.
+func (d *Dispatcher) send(ctx context.Context, endpoint string, evt events.Event, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, evt.Type)
+	req.Header.Set(HeaderEventID, strconv.FormatInt(evt.ID, 10))
+	if len(d.cfg.Secrets) > 0 {
+		req.Header.Set(HeaderSignature, d.cfg.Secrets.Sign(payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) deadLetter(job delivery, attempts int, lastError string, lastStatus int) {
+	now := time.Now()
+	entry, dropped := d.dlq.Add(models.DeadLetter{
+		Endpoint:      job.endpoint,
+		EventID:       job.evt.ID,
+		EventType:     job.evt.Type,
+		ClaimID:       job.evt.ClaimID,
+		Payload:       json.RawMessage(job.payload),
+		Attempts:      attempts,
+		LastError:     lastError,
+		LastStatus:    lastStatus,
+		DeadAt:        now,
+		LastAttemptAt: now,
+	})
+	atomic.AddInt64(&d.deadLettered, 1)
+	d.logger.WithFields(logrus.Fields{
+		"deadLetterId": entry.ID,
+		"endpoint":     job.endpoint,
+		"eventId":      job.evt.ID,
+		"attempts":     attempts,
+	}).Error("Webhook delivery dead-lettered")
+
+	if dropped != nil {
+		atomic.AddInt64(&d.dlqDropped, 1)
+		d.logger.WithFields(logrus.Fields{
+			"deadLetterId": dropped.ID,
+			"eventId":      dropped.EventID,
+		}).Warn("Dead-letter queue full, dropped oldest entry")
+	}
+}
+
+// DeadLetters lists the dead-letter queue, oldest first. A non-empty
+// eventType keeps only entries of that type.
+func (d *Dispatcher) DeadLetters(eventType string) *models.DeadLetterList {
+	entries := d.dlq.List()
+	if eventType != "" {
+		filtered := make([]models.DeadLetter, 0, len(entries))
+		for _, entry := range entries {
+			if entry.EventType == eventType {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+	return &models.DeadLetterList{Depth: d.dlq.Depth(), Entries: entries}
+}
+
+// Replay makes one delivery attempt for a dead letter, sending its original
+// payload to its original endpoint. A delivered entry leaves the queue; a
+// failed one stays with the attempt recorded.
+func (d *Dispatcher) Replay(ctx context.Context, id string) (*models.ReplayResult, error) {
+	entry, ok := d.dlq.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("dead letter not found")
+	}
+
+	var evt events.Event
+	if err := json.Unmarshal(entry.Payload, &evt); err != nil {
+		return nil, fmt.Errorf("invalid dead letter payload: %w", err)
+	}
+
+	status, err := d.send(ctx, entry.Endpoint, evt, entry.Payload)
+	entry.Attempts++
+	entry.Replays++
+	entry.LastAttemptAt = time.Now()
+	entry.LastStatus = status
+
+	if err == nil {
+		atomic.AddInt64(&d.replayed, 1)
+		d.dlq.Remove(id)
+		d.logger.WithFields(logrus.Fields{
+			"deadLetterId": id,
+			"eventId":      entry.EventID,
+		}).Info("Dead letter replayed")
+		return &models.ReplayResult{Delivered: true, DeadLetter: entry}, nil
+	}
+
+	atomic.AddInt64(&d.replayFailed, 1)
+	entry.LastError = err.Error()
+	d.dlq.Update(entry)
+	d.logger.WithError(err).WithField("deadLetterId", id).Warn("Dead letter replay failed")
+	return &models.ReplayResult{Delivered: false, DeadLetter: entry}, nil
+}
+
+// Stats returns delivery counters and the current dead-letter queue depth
+func (d *Dispatcher) Stats() Stats {
+	return Stats{
+		Delivered:      atomic.LoadInt64(&d.delivered),
+		FailedAttempts: atomic.LoadInt64(&d.failedAttempts),
+		DeadLettered:   atomic.LoadInt64(&d.deadLettered),
+		DLQDropped:     atomic.LoadInt64(&d.dlqDropped),
+		Replayed:       atomic.LoadInt64(&d.replayed),
+		ReplayFailed:   atomic.LoadInt64(&d.replayFailed),
+		DLQDepth:       d.dlq.Depth(),
+	}
+}
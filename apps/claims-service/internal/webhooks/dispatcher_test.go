@@ -0,0 +1,236 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/events"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// startDispatcher runs a dispatcher and waits until it is subscribed, so
+// events published afterwards are delivered
+func startDispatcher(t *testing.T, bus *events.Bus, cfg Config) *Dispatcher {
+	t.Helper()
+	d := NewDispatcher(bus, cfg, newTestLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	go d.Run(ctx)
+	t.Cleanup(func() {
+		cancel()
+		d.Wait()
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for bus.SubscriberCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Dispatcher did not subscribe to the bus")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return d
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherDeliversSignedEvents(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []*http.Request
+		bodies   [][]byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bus := events.NewBus(10, newTestLogger())
+	d := startDispatcher(t, bus, Config{URLs: []string{server.URL}, Secrets: signing.Keys{"s3cret-2026", "s3cret"}})
+
+	evt := bus.Publish(events.Event{Type: events.ClaimCreated, ClaimID: "claim-001", CustomerID: "cust-001", NewStatus: "submitted"})
+
+	waitFor(t, "delivery", func() bool { return d.Stats().Delivered == 1 })
+
+	mu.Lock()
+	defer mu.Unlock()
+	req, body := received[0], bodies[0]
+	if got := req.Header.Get(HeaderEventType); got != events.ClaimCreated {
+		t.Errorf("Event type header mismatch: got %q, want %q", got, events.ClaimCreated)
+	}
+	if got := req.Header.Get(HeaderEventID); got != "1" {
+		t.Errorf("Event ID header mismatch: got %q, want %q", got, "1")
+	}
+	// Signed with both secrets, so receivers on either verify it
+	if got, want := req.Header.Get(HeaderSignature), signing.Sign("s3cret-2026", body)+","+signing.Sign("s3cret", body); got != want {
+		t.Errorf("Signature mismatch: got %q, want %q", got, want)
+	}
+
+	var delivered events.Event
+	if err := json.Unmarshal(body, &delivered); err != nil {
+		t.Fatalf("Failed to decode delivered body: %v", err)
+	}
+	if delivered.ID != evt.ID || delivered.ClaimID != "claim-001" {
+		t.Errorf("Delivered event mismatch: got %+v", delivered)
+	}
+}
+
+func TestDispatcherDeadLettersAfterRetries(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	bus := events.NewBus(10, newTestLogger())
+	d := startDispatcher(t, bus, Config{
+		URLs:         []string{server.URL},
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+	})
+
+	bus.Publish(events.Event{Type: events.ClaimAssigned, ClaimID: "claim-002"})
+
+	waitFor(t, "dead letter", func() bool { return d.Stats().DLQDepth == 1 })
+
+	if got := atomic.LoadInt64(&calls); got != 3 {
+		t.Errorf("Attempt count mismatch: got %d, want 3", got)
+	}
+	stats := d.Stats()
+	if stats.FailedAttempts != 3 || stats.DeadLettered != 1 || stats.Delivered != 0 {
+		t.Errorf("Stats mismatch: got %+v", stats)
+	}
+
+	list := d.DeadLetters("")
+	entry := list.Entries[0]
+	if entry.ID != "dlq-000001" {
+		t.Errorf("Dead letter ID mismatch: got %q", entry.ID)
+	}
+	if entry.Attempts != 3 || entry.LastStatus != http.StatusServiceUnavailable {
+		t.Errorf("Dead letter attempts mismatch: got %d attempts, last status %d", entry.Attempts, entry.LastStatus)
+	}
+	if entry.EventType != events.ClaimAssigned || entry.ClaimID != "claim-002" || entry.Endpoint != server.URL {
+		t.Errorf("Dead letter mismatch: got %+v", entry)
+	}
+
+	if filtered := d.DeadLetters(events.ClaimCreated); len(filtered.Entries) != 0 || filtered.Depth != 1 {
+		t.Errorf("Filtered list mismatch: got %d entries, depth %d", len(filtered.Entries), filtered.Depth)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	bus := events.NewBus(10, newTestLogger())
+	d := startDispatcher(t, bus, Config{
+		URLs:         []string{server.URL},
+		MaxAttempts:  1,
+		RetryBackoff: time.Millisecond,
+	})
+	bus.Publish(events.Event{Type: events.ClaimEscalated, ClaimID: "claim-003"})
+	waitFor(t, "dead letter", func() bool { return d.Stats().DLQDepth == 1 })
+
+	t.Run("unknown entry", func(t *testing.T) {
+		_, err := d.Replay(context.Background(), "dlq-999999")
+		if err == nil || err.Error() != "dead letter not found" {
+			t.Errorf("Expected dead letter not found, got %v", err)
+		}
+	})
+
+	t.Run("endpoint still failing", func(t *testing.T) {
+		result, err := d.Replay(context.Background(), "dlq-000001")
+		if err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		if result.Delivered {
+			t.Error("Replay should not be delivered while the endpoint fails")
+		}
+		entry, ok := d.dlq.Get("dlq-000001")
+		if !ok {
+			t.Fatal("Failed replay should stay in the queue")
+		}
+		if entry.Attempts != 2 || entry.Replays != 1 || entry.LastStatus != http.StatusInternalServerError {
+			t.Errorf("Failed replay not recorded: got %+v", entry)
+		}
+	})
+
+	t.Run("endpoint recovered", func(t *testing.T) {
+		healthy.Store(true)
+		result, err := d.Replay(context.Background(), "dlq-000001")
+		if err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+		if !result.Delivered {
+			t.Errorf("Replay should be delivered: got %+v", result.DeadLetter)
+		}
+		if depth := d.Stats().DLQDepth; depth != 0 {
+			t.Errorf("Delivered replay should leave the queue: depth %d", depth)
+		}
+		stats := d.Stats()
+		if stats.Replayed != 1 || stats.ReplayFailed != 1 {
+			t.Errorf("Replay stats mismatch: got %+v", stats)
+		}
+	})
+}
+
+func TestDeadLetterQueueDropsOldestWhenFull(t *testing.T) {
+	q := NewDeadLetterQueue(2)
+	for _, claimID := range []string{"claim-001", "claim-002", "claim-003"} {
+		q.Add(deadLetterFor(claimID))
+	}
+
+	list := q.List()
+	if len(list) != 2 {
+		t.Fatalf("Depth mismatch: got %d, want 2", len(list))
+	}
+	if list[0].ClaimID != "claim-002" || list[1].ClaimID != "claim-003" {
+		t.Errorf("Expected the oldest entry dropped, got %s and %s", list[0].ClaimID, list[1].ClaimID)
+	}
+	if !q.Remove(list[0].ID) || q.Remove(list[0].ID) {
+		t.Error("Remove should succeed once")
+	}
+	if q.Depth() != 1 {
+		t.Errorf("Depth mismatch after remove: got %d, want 1", q.Depth())
+	}
+}
+
+func deadLetterFor(claimID string) models.DeadLetter {
+	return models.DeadLetter{EventType: events.ClaimCreated, ClaimID: claimID}
+}
@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/internal/models"
+)
+
+// DefaultDeadLetterCapacity bounds the dead-letter queue when no capacity
+// is configured
+const DefaultDeadLetterCapacity = 10000
+
+// DeadLetterQueue keeps deliveries that exhausted their retries until they
+// are replayed. It is held in memory, so entries do not survive a restart.
+// When full, the oldest entry is dropped to make room.
+type DeadLetterQueue struct {
+	entries  map[string]*models.DeadLetter
+	order    []string // IDs, oldest first
+	nextID   int
+	capacity int
+	mu       sync.RWMutex
+}
+
+// NewDeadLetterQueue creates an empty queue holding up to capacity entries
+func NewDeadLetterQueue(capacity int) *DeadLetterQueue {
+	if capacity <= 0 {
+		capacity = DefaultDeadLetterCapacity
+	}
+	return &DeadLetterQueue{
+		entries:  make(map[string]*models.DeadLetter),
+		nextID:   1,
+		capacity: capacity,
+	}
+}
+
+// Add assigns an ID to entry and queues it. It returns the stored entry and,
+// when the queue was full, the entry dropped to make room.
+func (q *DeadLetterQueue) Add(entry models.DeadLetter) (added models.DeadLetter, dropped *models.DeadLetter) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.order) >= q.capacity {
+		oldest := q.entries[q.order[0]]
+		delete(q.entries, oldest.ID)
+		q.order = q.order[1:]
+		dropped = oldest
+	}
+
+	entry.ID = fmt.Sprintf("dlq-%06d", q.nextID)
+	q.nextID++
+	q.entries[entry.ID] = &entry
+	q.order = append(q.order, entry.ID)
+	return entry, dropped
+}
+
+// List returns the queued entries, oldest first
+func (q *DeadLetterQueue) List() []models.DeadLetter {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	list := make([]models.DeadLetter, 0, len(q.order))
+	for _, id := range q.order {
+		list = append(list, *q.entries[id])
+	}
+	return list
+}
+
+// Get returns one entry
+func (q *DeadLetterQueue) Get(id string) (models.DeadLetter, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	entry, ok := q.entries[id]
+	if !ok {
+		return models.DeadLetter{}, false
+	}
+	return *entry, true
+}
+
+// Update replaces a queued entry; it reports false if the entry is gone
+func (q *DeadLetterQueue) Update(entry models.DeadLetter) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.entries[entry.ID]; !ok {
+		return false
+	}
+	q.entries[entry.ID] = &entry
+	return true
+}
+
+// Remove deletes an entry; it reports false if the entry was not queued
+func (q *DeadLetterQueue) Remove(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.entries[id]; !ok {
+		return false
+	}
+	delete(q.entries, id)
+	for i, queued := range q.order {
+		if queued == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Depth returns the number of queued entries
+func (q *DeadLetterQueue) Depth() int {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return len(q.order)
+}
@@ -0,0 +1,346 @@
+// Package app assembles the customer service from its repository, services,
+// handlers and routes. cmd/server serves it over HTTP; tests can mount the
+// same handler on an httptest server.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/email"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/geocode"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/health"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the settings needed to assemble the service
+type Config struct {
+	DataPath      string
+	FeatureAPIKey string
+
+	// JWTSecret signs email verification tokens and verifies the staff
+	// tokens KYC review requires. JWT_SECRET in the default secret store
+	// takes its place when it resolves, so rotations take effect without a
+	// restart. When neither is set the development default is used.
+	JWTSecret string
+
+	// VerificationURL is the address emailed verification links point at,
+	// normally this service's GET /customers/verify. When empty it is
+	// http://localhost:8004/customers/verify.
+	VerificationURL string
+
+	// EmailSender delivers verification email. When nil messages are logged.
+	EmailSender email.Sender
+
+	// AddressNormalizer selects the normalizer customers' addresses are put
+	// in standard form and located with when saved. When its Normalizer is
+	// empty addresses are kept as given and geocoding runs are refused.
+	AddressNormalizer geocode.Config
+
+	// ActivityWebhookSecrets verify the X-Webhook-Signature of the events
+	// other services report for customers' activity feeds; an event signed
+	// with any of them is taken, so senders can rotate their secret.
+	// Without any, events are accepted unsigned.
+	ActivityWebhookSecrets signing.Keys
+
+	// ShapingFile holds the field visibility rules of the responses, on
+	// top of their shape tags. When empty response-shaping.json in
+	// DataPath is used, and without that file the tags alone apply.
+	ShapingFile string
+
+	// Blobs keeps the content of uploaded KYC documents in a shared blob
+	// store. An empty backend keeps content in the repository.
+	Blobs blobstore.Config
+
+	// PersistDir holds the write-ahead log and snapshot that keep changes
+	// across restarts. When empty changes are kept in memory only.
+	// PersistFlushInterval is how often the log is folded into the snapshot.
+	PersistDir           string
+	PersistFlushInterval time.Duration
+
+	// Maintenance is the maintenance mode the service starts in
+	Maintenance maintenance.Config
+	// Faults is whether the service may inject faults for resilience
+	// testing; the rules are set at /admin/faults
+	Faults faults.Config
+
+	// SlowRequestThreshold logs requests that take at least this long as
+	// warnings; 0 disables the warning
+	SlowRequestThreshold time.Duration
+
+	// AccessLog receives one structured entry per request; when nil they
+	// are written to the service log
+	AccessLog *logrus.Logger
+	// ErrorReporter receives handler panics, such as a Sentry hub; nil only
+	// logs them
+	ErrorReporter telemetry.ErrorReporter
+}
+
+// verificationTokenTTL is how long an emailed verification link stays valid
+const verificationTokenTTL = 24 * time.Hour
+
+// kycLimits is what the blob store accepts as KYC documents: photos and
+// scans of identity documents and proofs of address
+var kycLimits = blobstore.Limits{
+	MaxSize:      models.MaxKYCDocumentSize,
+	ContentTypes: []string{"image/*", "application/pdf"},
+}
+
+// App is an assembled customer service
+type App struct {
+	Handler http.Handler
+	Flags   *features.Flags
+
+	geocoding *services.GeocodeService
+	journal   *persist.Journal
+	logger    *logrus.Logger
+}
+
+// New wires the service together and loads its data from cfg.DataPath
+func New(cfg Config, logger *logrus.Logger) (*App, error) {
+	// Initialize CloudBees Feature Management
+	flags, err := features.Initialize(cfg.FeatureAPIKey, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize feature management: %w", err)
+	}
+
+	normalizer, err := geocode.New(cfg.AddressNormalizer)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	blobs, err := blobstore.Open(cfg.Blobs, kycLimits)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+
+	// Initialize repository
+	repo, err := repository.NewRepository(cfg.DataPath, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	// Load the response shaping policy
+	shaper, err := loadShaping(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+
+	var journal *persist.Journal
+	if cfg.PersistDir != "" {
+		journal, err = persist.Open(cfg.PersistDir, "customer-service", cfg.PersistFlushInterval, logger)
+		if err != nil {
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to open persisted state: %w", err)
+		}
+		if err := repo.Persist(journal); err != nil {
+			journal.Close()
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to restore persisted state: %w", err)
+		}
+	}
+
+	// Initialize services
+	activityService := services.NewActivityService(repo, logger)
+	if err := activityService.Follow(repo); err != nil {
+		journal.Close()
+		features.Shutdown()
+		return nil, err
+	}
+	customerService := services.NewCustomerService(repo, flags, normalizer, logger)
+
+	jwtSecret := cfg.JWTSecret
+	if jwtSecret == "" {
+		jwtSecret = "dev-secret-key-change-in-production"
+		logger.Warn("JWT_SECRET not set, using default (not secure for production)")
+	}
+	jwtKey := secrets.Default().Func("JWT_SECRET", jwtSecret)
+	verificationURL := cfg.VerificationURL
+	if verificationURL == "" {
+		verificationURL = "http://localhost:8004/customers/verify"
+	}
+	sender := cfg.EmailSender
+	if sender == nil {
+		sender = email.NewLogSender(logger)
+	}
+	verificationService := services.NewVerificationService(repo, auth.NewRotatingVerificationTokens(jwtKey, verificationTokenTTL), sender, verificationURL, logger)
+	householdService := services.NewHouseholdService(repo, logger)
+	var kycStore repository.KYCStore = repo
+	if blobs != nil {
+		kycStore = repository.WithBlobs(repo, blobs)
+		logger.WithField("backend", cfg.Blobs.Backend).Info("KYC document content kept in the blob store")
+	}
+	kycService := services.NewKYCService(kycStore, logger)
+	geocodeService := services.NewGeocodeService(repo, normalizer, logger)
+
+	// Initialize handlers
+	maintenanceMode := maintenance.New(cfg.Maintenance, logger)
+	faultInjection := faults.New(cfg.Faults, logger)
+	healthHandler := health.NewHandler("customer-service", health.Combine(flags, maintenanceMode, faultInjection))
+	customerHandler := handlers.NewCustomerHandler(customerService, logger)
+	verificationHandler := handlers.NewVerificationHandler(verificationService, logger)
+	householdHandler := handlers.NewHouseholdHandler(householdService, logger)
+	kycHandler := handlers.NewKYCHandler(kycService, logger)
+	geocodeHandler := handlers.NewGeocodeHandler(geocodeService, logger)
+	activityHandler := handlers.NewActivityHandler(activityService, cfg.ActivityWebhookSecrets, logger)
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Apply global middleware
+	requestMetrics := telemetry.NewRegistry(nil)
+	panics := telemetry.NewPanicCounter()
+	router.Use(middleware.LoggingMiddleware(logger, middleware.LoggingOptions{
+		Metrics:       requestMetrics,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		AccessLog:     cfg.AccessLog,
+	}))
+	// Error messages are translated into the caller's Accept-Language,
+	// including those of recovered panics
+	router.Use(i18n.Middleware(i18n.Default()))
+	router.Use(middleware.RecoveryMiddleware(logger, middleware.RecoveryOptions{
+		Panics:   panics,
+		Reporter: cfg.ErrorReporter,
+	}))
+	// Turn callers away during maintenance before they are authenticated
+	router.Use(maintenanceMode.Middleware)
+	// Inject test faults ahead of authentication too, so every caller is
+	// faulted the same way
+	router.Use(faultInjection.Middleware)
+	router.Use(middleware.AuthMiddleware(logger))
+	// Who reviewed an identity document is left out for customers
+	router.Use(shaping.Middleware(shaper, func(r *http.Request) shaping.Viewer {
+		return shaping.Viewer{Role: middleware.GetRole(r), Flags: shaping.FlagsOn(flags.EnabledFlags())}
+	}))
+
+	// Setup CORS
+	corsHandler := middleware.NewCORS()
+
+	// Register routes
+	router.Handle("/healthz", healthHandler).Methods("GET")
+	router.Handle("/labels", i18n.LabelsHandler(i18n.Default())).Methods("GET")
+	router.Handle("/metrics", telemetry.Handler(requestMetrics, panics, faultInjection)).Methods("GET")
+	router.HandleFunc("/customers", customerHandler.GetCustomers).Methods("GET")
+	router.HandleFunc("/customers/verify", verificationHandler.VerifyEmail).Methods("GET")
+	router.HandleFunc("/customers/{id}", customerHandler.GetCustomerByID).Methods("GET")
+	router.HandleFunc("/customers", customerHandler.CreateCustomer).Methods("POST")
+	router.HandleFunc("/customers/{id}", customerHandler.UpdateCustomer).Methods("PUT")
+	router.HandleFunc("/customers/{id}", customerHandler.DeactivateCustomer).Methods("DELETE")
+	router.HandleFunc("/customers/{id}/preferences", customerHandler.GetPreferences).Methods("GET")
+	router.HandleFunc("/customers/{id}/preferences", customerHandler.UpdatePreferences).Methods("PUT")
+	router.HandleFunc("/customers/{id}/send-verification", verificationHandler.SendVerification).Methods("POST")
+	router.HandleFunc("/customers/{id}/kyc", kycHandler.GetKYC).Methods("GET")
+	router.HandleFunc("/customers/{id}/kyc/documents", kycHandler.UploadDocument).Methods("POST")
+	// Other services report activity signed rather than with a staff token
+	router.HandleFunc("/activity", activityHandler.RecordActivity).Methods("POST")
+	router.HandleFunc("/webhooks/claims", activityHandler.ReceiveClaimEvent).Methods("POST")
+	router.HandleFunc("/households", householdHandler.CreateHousehold).Methods("POST")
+	router.HandleFunc("/households/{id}", householdHandler.GetHousehold).Methods("GET")
+	router.HandleFunc("/households/{id}/members", householdHandler.JoinHousehold).Methods("POST")
+	router.HandleFunc("/households/{id}/members/{customerId}", householdHandler.LeaveHousehold).Methods("DELETE")
+
+	// Identity documents are only opened and reviewed by staff, authorized
+	// by JWT role
+	staffOnly := middleware.RequireRole(jwtKey, logger, "admin", "adjuster")
+	router.Handle("/customers/{id}/kyc/documents/{documentId}", staffOnly(http.HandlerFunc(kycHandler.GetDocument))).Methods("GET")
+	router.Handle("/customers/{id}/kyc/documents/{documentId}/review", staffOnly(http.HandlerFunc(kycHandler.ReviewDocument))).Methods("POST")
+	// So is a customer's activity feed, for the support console
+	router.Handle("/customers/{id}/activity", staffOnly(http.HandlerFunc(activityHandler.GetActivity))).Methods("GET")
+
+	// Maintenance mode is switched by admins only
+	adminOnly := middleware.RequireRole(jwtKey, logger, "admin")
+	router.Handle(maintenance.Path, adminOnly(maintenanceMode.Handler())).Methods("GET", "PUT")
+	router.Handle(faults.Path, adminOnly(faultInjection.Handler())).Methods("GET", "PUT")
+	router.Handle("/admin/customers/geocode", adminOnly(http.HandlerFunc(geocodeHandler.StartGeocoding))).Methods("POST")
+	router.Handle("/admin/customers/geocode/{id}", adminOnly(http.HandlerFunc(geocodeHandler.GetGeocoding))).Methods("GET")
+
+	// Wrap router with CORS
+	return &App{
+		Handler:   corsHandler.Handler(router),
+		Flags:     flags,
+		geocoding: geocodeService,
+		journal:   journal,
+		logger:    logger,
+	}, nil
+}
+
+// loadShaping reads the response shaping policy and checks it, and the
+// shape tags, against the KYC responses. A configured file must load; the
+// default file may be missing.
+func loadShaping(cfg Config, logger *logrus.Logger) (*shaping.Shaper, error) {
+	path := cfg.ShapingFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "response-shaping.json")
+	}
+	policy, err := shaping.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ShapingFile == "" {
+		logger.Warnf("No response shaping policy in %s, shape tags alone apply", path)
+		policy = shaping.Default()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load response shaping policy: %w", err)
+	} else {
+		logger.WithField("version", policy.Version).Infof("Loaded response shaping policy from %s", path)
+	}
+
+	shaper := shaping.New(policy, "customer-service")
+	if err := shaper.Check(models.KYCSummary{}); err != nil {
+		return nil, fmt.Errorf("invalid response shaping: %w", err)
+	}
+	return shaper, nil
+}
+
+// serverDrainTimeout is how long requests in flight get to finish on
+// shutdown
+const serverDrainTimeout = 30 * time.Second
+
+// RegisterShutdown registers the service's components with m in the order
+// they stop. server, when given, drains first, so requests in flight can
+// still write to the journal before it writes its final snapshot; a
+// geocoding run in flight stops next, for the same reason.
+func (a *App) RegisterShutdown(m *lifecycle.Manager, server lifecycle.StopFunc) {
+	if server != nil {
+		m.Register("http server", serverDrainTimeout, server)
+	}
+	m.Register("geocoding runs", 10*time.Second, a.geocoding.Stop)
+	m.Register("persisted state", 10*time.Second, func(ctx context.Context) error {
+		return a.journal.Close()
+	})
+	m.Register("feature flags", 5*time.Second, func(ctx context.Context) error {
+		features.Shutdown()
+		return nil
+	})
+}
+
+// Close stops the service's components without an HTTP server, writing a
+// final snapshot of persisted state
+func (a *App) Close() {
+	m := lifecycle.New(a.logger)
+	a.RegisterShutdown(m, nil)
+	m.Shutdown(context.Background())
+}
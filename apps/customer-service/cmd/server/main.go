@@ -10,34 +10,35 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/features"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/handlers"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/middleware"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
-	"github.com/gorilla/mux"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/geocode"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/logging"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
-
-	// Configure log level from environment
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-	level, err := logrus.ParseLevel(logLevel)
-	if err != nil {
-		logger.Warnf("Invalid log level '%s', defaulting to info", logLevel)
-		level = logrus.InfoLevel
-	}
-	logger.SetLevel(level)
+	// Initialize logger from LOG_FORMAT, LOG_LEVEL, LOG_LEVELS and sampling settings
+	logger := logging.NewFromEnv()
 
 	logger.Info("Starting Customer Service...")
 
+	// Secrets come from SECRETS_PROVIDER: the environment by default, or
+	// mounted files, Vault or AWS Secrets Manager. Startup fails when one
+	// of SECRETS_REQUIRED does not resolve.
+	secretStore, err := secrets.Open(secrets.ConfigFromEnv(logger), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to resolve secrets")
+	}
+	secrets.SetDefault(secretStore)
+	logger.WithField("provider", secretStore.Source()).Info("Secrets resolved")
+
 	// Get configuration from environment
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -50,73 +51,140 @@ func main() {
 		dataPath = filepath.Join("..", "..", "data", "seed")
 	}
 
-	cloudBeesAPIKey := os.Getenv("CLOUDBEES_FM_API_KEY")
+	cloudBeesAPIKey := secretStore.Lookup("CLOUDBEES_FM_API_KEY", "")
 	if cloudBeesAPIKey == "" {
 		logger.Warn("CLOUDBEES_FM_API_KEY not set, feature flags will use defaults")
 		// Use a placeholder for development
 		cloudBeesAPIKey = "dev-mode"
 	}
 
-	// Initialize CloudBees Feature Management
-	flags, err := features.Initialize(cloudBeesAPIKey, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize feature management")
-	}
-	defer features.Shutdown()
+	// Field visibility rules on top of the shape tags; defaults to
+	// response-shaping.json in DATA_PATH
+	shapingFile := os.Getenv("SHAPING_FILE")
 
-	// Initialize repository
-	repo, err := repository.NewRepository(dataPath, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize repository")
+	// Addresses are put in standard form and located when customers are
+	// saved: stub, or nominatim at NOMINATIM_URL
+	addressNormalizer := geocode.Config{
+		Normalizer: os.Getenv("ADDRESS_NORMALIZER"),
+		URL:        os.Getenv("NOMINATIM_URL"),
+		APIKey:     secretStore.Lookup("NOMINATIM_API_KEY", ""),
+	}
+	if addressNormalizer.Normalizer == "" {
+		logger.Warn("ADDRESS_NORMALIZER not set, addresses will be stored as given")
 	}
 
-	// Initialize services
-	customerService := services.NewCustomerService(repo, flags, logger)
-
-	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	customerHandler := handlers.NewCustomerHandler(customerService, logger)
-
-	// Setup router
-	router := mux.NewRouter()
-
-	// Apply global middleware
-	router.Use(middleware.LoggingMiddleware(logger))
-	router.Use(middleware.AuthMiddleware(logger))
+	// Events reported for customers' activity feeds are signed with one of
+	// these, comma-separated while a sender's secret is rotated
+	activityWebhookSecrets := signing.ParseKeys(secretStore.Lookup("ACTIVITY_WEBHOOK_SECRET", ""))
+	if len(activityWebhookSecrets) == 0 {
+		logger.Warn("ACTIVITY_WEBHOOK_SECRET not set, activity events will be accepted unsigned")
+	}
 
-	// Setup CORS
-	corsHandler := middleware.NewCORS()
+	// Persisted state, so changes survive restarts
+	persistDir := os.Getenv("PERSIST_DIR")
+	if persistDir == "" {
+		logger.Warn("PERSIST_DIR not set, changes will be lost on restart")
+	}
+	persistFlushInterval := time.Minute
+	if v := os.Getenv("PERSIST_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			persistFlushInterval = d
+		} else {
+			logger.Warnf("Invalid PERSIST_FLUSH_INTERVAL '%s', defaulting to %s", v, persistFlushInterval)
+		}
+	}
 
-	// Register routes
-	router.Handle("/healthz", healthHandler).Methods("GET")
-	router.HandleFunc("/customers", customerHandler.GetCustomers).Methods("GET")
-	router.HandleFunc("/customers/{id}", customerHandler.GetCustomerByID).Methods("GET")
-	router.HandleFunc("/customers", customerHandler.CreateCustomer).Methods("POST")
-	router.HandleFunc("/customers/{id}", customerHandler.UpdateCustomer).Methods("PUT")
-	router.HandleFunc("/customers/{id}", customerHandler.DeactivateCustomer).Methods("DELETE")
+	// Requests at least this slow are logged as warnings
+	slowRequestThreshold := time.Second
+	if v := os.Getenv("HTTP_SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			slowRequestThreshold = d
+		} else {
+			logger.Warnf("Invalid HTTP_SLOW_REQUEST_THRESHOLD '%s', defaulting to %s", v, slowRequestThreshold)
+		}
+	}
 
-	// Wrap router with CORS
-	handler := corsHandler.Handler(router)
+	// Access entries go to the service log unless ACCESS_LOG names a
+	// separate stream: stdout, stderr or a file path
+	accessLog, closeAccessLog, err := telemetry.OpenAccessLog(os.Getenv("ACCESS_LOG"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open access log")
+	}
+	defer closeAccessLog()
+
+	// Keeps KYC document content in a shared blob store: local, under
+	// BLOBSTORE_DIR, or s3, in BLOBSTORE_S3_BUCKET. Unset keeps it in the
+	// repository.
+	blobConfig := blobstore.ConfigFromEnv(logger)
+
+	// Assemble the service
+	application, err := app.New(app.Config{
+		DataPath:               dataPath,
+		FeatureAPIKey:          cloudBeesAPIKey,
+		JWTSecret:              secretStore.Lookup("JWT_SECRET", ""),
+		VerificationURL:        os.Getenv("EMAIL_VERIFICATION_URL"),
+		ShapingFile:            shapingFile,
+		Blobs:                  blobConfig,
+		AddressNormalizer:      addressNormalizer,
+		ActivityWebhookSecrets: activityWebhookSecrets,
+		Maintenance:            maintenance.ConfigFromEnv(logger),
+		Faults:                 faults.ConfigFromEnv(logger),
+		PersistDir:             persistDir,
+		PersistFlushInterval:   persistFlushInterval,
+		SlowRequestThreshold:   slowRequestThreshold,
+		AccessLog:              accessLog,
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize service")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      handler,
+		Handler:      application.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Drain requests in flight and stop the service's components in order
+	conns := lifecycle.TrackConnections(server)
+	shutdown := lifecycle.New(logger)
+	application.RegisterShutdown(shutdown, lifecycle.DrainServer(server, conns))
+
 	// Start server in a goroutine
 	go func() {
 		logger.Infof("Server listening on port %s", port)
 		logger.Info("API Endpoints:")
 		logger.Info("  GET    /healthz - Health check")
+		logger.Info("  GET    /labels - Status and type labels in the Accept-Language language")
+		logger.Info("  GET    /metrics - Request latency by route (Prometheus)")
 		logger.Info("  GET    /customers - List all customers")
 		logger.Info("  GET    /customers/{id} - Get customer by ID")
+		logger.Info("  GET    /customers/verify?token= - Verify a customer's email address")
 		logger.Info("  POST   /customers - Create new customer")
 		logger.Info("  PUT    /customers/{id} - Update customer")
 		logger.Info("  DELETE /customers/{id} - Deactivate customer")
+		logger.Info("  GET    /customers/{id}/preferences - Get communication preferences and consent")
+		logger.Info("  PUT    /customers/{id}/preferences - Update communication preferences and consent")
+		logger.Info("  POST   /customers/{id}/send-verification - Email a verification link")
+		logger.Info("  GET    /customers/{id}/kyc - Get KYC status and documents")
+		logger.Info("  POST   /customers/{id}/kyc/documents - Upload an identity document")
+		logger.Info("  GET    /customers/{id}/kyc/documents/{documentId} - Download an identity document (staff)")
+		logger.Info("  POST   /customers/{id}/kyc/documents/{documentId}/review - Verify or reject a document (staff)")
+		logger.Info("  GET    /customers/{id}/activity - Customer activity feed (staff)")
+		logger.Info("  POST   /activity - Report an event for a customer's activity feed (signed)")
+		logger.Info("  POST   /webhooks/claims - Receive claims-service webhook events (signed)")
+		logger.Info("  POST   /households - Create a household")
+		logger.Info("  GET    /households/{id} - Get household and members")
+		logger.Info("  POST   /households/{id}/members - Join a household")
+		logger.Info("  DELETE /households/{id}/members/{customerId} - Leave a household")
+		logger.Info("  GET    /admin/maintenance - Maintenance mode (admin JWT)")
+		logger.Info("  PUT    /admin/maintenance - Turn maintenance mode on or off (admin JWT)")
+		logger.Info("  GET    /admin/faults - Fault injection rules, when enabled (admin JWT)")
+		logger.Info("  PUT    /admin/faults - Replace the fault injection rules (admin JWT)")
+		logger.Info("  POST   /admin/customers/geocode - Re-geocode customers' addresses (admin JWT)")
+		logger.Info("  GET    /admin/customers/geocode/{id} - Geocoding run progress (admin JWT)")
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Fatal("Server failed to start")
@@ -128,14 +196,16 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	open := conns.Connections()
+	logger.WithFields(logrus.Fields{
+		"active":   open.Active,
+		"idle":     open.Idle,
+		"hijacked": open.Hijacked,
+	}).Info("Shutting down server...")
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
+	if err := shutdown.Shutdown(context.Background()).Err(); err != nil {
+		logger.WithError(err).Error("Shutdown incomplete")
+		return
 	}
 
 	logger.Info("Server stopped gracefully")
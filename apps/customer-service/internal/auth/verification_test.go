@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerificationTokens(t *testing.T) {
+	tokens := NewVerificationTokens("test-secret-key", time.Hour)
+
+	token, expiresAt, err := tokens.Generate("cust-001", "demo@insurancestack.com")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if time.Until(expiresAt) <= 0 || time.Until(expiresAt) > time.Hour {
+		t.Errorf("Unexpected expiry %v", expiresAt)
+	}
+
+	claims, err := tokens.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if claims.CustomerID != "cust-001" || claims.Email != "demo@insurancestack.com" {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+
+	if _, err := NewVerificationTokens("other-secret", time.Hour).Verify(token); err != ErrInvalidToken {
+		t.Errorf("Token with another secret: got %v, want %v", err, ErrInvalidToken)
+	}
+
+	expired, _, _ := NewVerificationTokens("test-secret-key", -time.Minute).Generate("cust-001", "demo@insurancestack.com")
+	if _, err := tokens.Verify(expired); err != ErrExpiredToken {
+		t.Errorf("Expired token: got %v, want %v", err, ErrExpiredToken)
+	}
+
+	// A login token signed with the same secret is not a verification token
+	login, err := NewJWTManager("test-secret-key", time.Hour).Generate("cust-001", "demo@insurancestack.com")
+	if err != nil {
+		t.Fatalf("Generate login token failed: %v", err)
+	}
+	if _, err := tokens.Verify(login); err != ErrInvalidToken {
+		t.Errorf("Login token: got %v, want %v", err, ErrInvalidToken)
+	}
+}
@@ -0,0 +1,44 @@
+// Package email is the integration point for sending email to customers.
+// LogSender writes messages to the service log until a mail provider is
+// integrated.
+package email
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message is an email to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSender logs messages instead of delivering them
+type LogSender struct {
+	logger *logrus.Logger
+}
+
+var _ Sender = (*LogSender)(nil)
+
+// NewLogSender creates a sender that logs every message
+func NewLogSender(logger *logrus.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.logger.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+		"body":    msg.Body,
+	}).Info("Email not delivered, no mail provider configured")
+	return nil
+}
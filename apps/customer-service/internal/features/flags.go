@@ -168,6 +168,16 @@ func (f *Flags) SetCurrency(currency string) {
 	f.logger.WithField("currency", currency).Info("Feature flag updated")
 }
 
+// EnabledFlags returns the keys of the boolean flags that are on, for the
+// health check
+func (f *Flags) EnabledFlags() []string {
+	var enabled []string
+	if f.ShouldMaskAmounts() {
+		enabled = append(enabled, "api.maskAmounts")
+	}
+	return enabled
+}
+
 // Shutdown gracefully shuts down the feature management system
 func Shutdown() {
 	if flags != nil {
@@ -0,0 +1,53 @@
+// Package geocode is the integration point for address normalization. A
+// Normalizer puts the free-text address a customer gives into standard
+// components and locates it, so territory rating and catastrophe matching
+// compare places rather than spellings. Stub works from tables built into
+// the service; Nominatim asks a Nominatim-compatible geocoding API.
+package geocode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+)
+
+// Normalizer names
+const (
+	NameStub      = "stub"
+	NameNominatim = "nominatim"
+)
+
+// Normalizer normalizes and locates addresses. Normalize returns an error
+// reading "address not found" when the address does not match a place;
+// other errors are failures to ask, worth retrying.
+type Normalizer interface {
+	Name() string
+	Normalize(ctx context.Context, addr models.Address) (*models.NormalizedAddress, error)
+}
+
+// Config selects and configures a normalizer
+type Config struct {
+	// Normalizer is stub, nominatim, or empty for none
+	Normalizer string
+	// URL is the base URL of the nominatim API; when empty the public
+	// OpenStreetMap instance is used
+	URL string
+	// APIKey is sent as the key parameter, for hosted Nominatim-compatible
+	// APIs that require one
+	APIKey string
+}
+
+// New returns the normalizer cfg names, or nil when it names none
+func New(cfg Config) (Normalizer, error) {
+	switch cfg.Normalizer {
+	case "":
+		return nil, nil
+	case NameStub:
+		return Stub{}, nil
+	case NameNominatim:
+		return NewNominatim(cfg.URL, cfg.APIKey), nil
+	default:
+		return nil, fmt.Errorf("unknown address normalizer %q (want stub or nominatim)", cfg.Normalizer)
+	}
+}
@@ -0,0 +1,114 @@
+package geocode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+)
+
+func TestStubNormalizesAndLocatesByRegion(t *testing.T) {
+	tests := []struct {
+		name      string
+		addr      models.Address
+		want      models.NormalizedAddress
+		precision string
+	}{
+		{
+			"us",
+			models.Address{Street: " 123  north Main Street, Apt. 4 ", City: "san francisco", State: "California", ZipCode: "94102 1234", Country: "U.S.A."},
+			models.NormalizedAddress{Street: "123 N MAIN ST APT 4", City: "SAN FRANCISCO", State: "CA", PostalCode: "94102-1234", Country: "US"},
+			models.PrecisionState,
+		},
+		{
+			"us without country",
+			models.Address{Street: "789 Oak Avenue", City: "Austin", State: "tx", ZipCode: "78701"},
+			models.NormalizedAddress{Street: "789 OAK AVE", City: "AUSTIN", State: "TX", PostalCode: "78701", Country: "US"},
+			models.PrecisionState,
+		},
+		{
+			"elsewhere",
+			models.Address{Street: "45 Baker Street", City: "London", ZipCode: "nw1 6xe", Country: "UK"},
+			models.NormalizedAddress{Street: "45 BAKER STREET", City: "LONDON", PostalCode: "NW1 6XE", Country: "GB"},
+			models.PrecisionCountry,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Stub{}.Normalize(context.Background(), tt.addr)
+			if err != nil {
+				t.Fatalf("Normalize failed: %v", err)
+			}
+			if got.Location == nil || got.Location.Precision != tt.precision {
+				t.Fatalf("Unexpected location %+v", got.Location)
+			}
+			got.Location = nil
+			if *got != tt.want {
+				t.Errorf("Got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Stub{}).Normalize(context.Background(), models.Address{City: "Atlantis", Country: "Atlantis"}); err == nil || err.Error() != "address not found" {
+		t.Errorf("Expected an unknown country not to be found, got %v", err)
+	}
+}
+
+func TestNominatimNormalizesTheBestMatch(t *testing.T) {
+	var query map[string]string
+	results := `[{"lat": "37.7793", "lon": "-122.4193", "address": {"house_number": "123", "road": "Main Street", "city": "San Francisco", "state": "California", "ISO3166-2-lvl4": "US-CA", "postcode": "94102", "country_code": "us"}}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = map[string]string{}
+		for key := range r.URL.Query() {
+			query[key] = r.URL.Query().Get(key)
+		}
+		if r.Header.Get("User-Agent") == "" {
+			t.Error("Nominatim requires a User-Agent")
+		}
+		if r.URL.Path != "/search" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(results))
+	}))
+	defer server.Close()
+
+	n := NewNominatim(server.URL+"/", "secret")
+	got, err := n.Normalize(context.Background(), models.Address{Street: "123 Main St.", City: "SF", State: "CA", Country: "US"})
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if query["street"] != "123 MAIN ST" || query["countrycodes"] != "us" || query["key"] != "secret" || query["postalcode"] != "" {
+		t.Errorf("Unexpected search %v", query)
+	}
+	want := models.GeoLocation{Latitude: 37.7793, Longitude: -122.4193, Precision: models.PrecisionAddress}
+	if got.Street != "123 MAIN ST" || got.City != "SAN FRANCISCO" || got.State != "CA" || got.PostalCode != "94102" || *got.Location != want {
+		t.Errorf("Unexpected normalized address %+v at %+v", got, got.Location)
+	}
+
+	results = `[]`
+	if _, err := n.Normalize(context.Background(), models.Address{Street: "1 Nowhere Rd"}); err == nil || err.Error() != "address not found" {
+		t.Errorf("Expected no match to be not found, got %v", err)
+	}
+	results = `{"error": "Unable to geocode"}`
+	if _, err := n.Normalize(context.Background(), models.Address{Street: "1 Nowhere Rd"}); err == nil || err.Error() == "address not found" {
+		t.Errorf("Expected a provider error, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if n, err := New(Config{}); n != nil || err != nil {
+		t.Errorf("Expected no normalizer, got %v, %v", n, err)
+	}
+	if n, err := New(Config{Normalizer: "stub"}); err != nil || n.Name() != NameStub {
+		t.Errorf("Expected the stub, got %v, %v", n, err)
+	}
+	if n, err := New(Config{Normalizer: "nominatim"}); err != nil || n.(*Nominatim).baseURL != DefaultNominatimURL {
+		t.Errorf("Expected the public nominatim, got %v, %v", n, err)
+	}
+	if _, err := New(Config{Normalizer: "google"}); err == nil {
+		t.Error("Expected an unknown normalizer to be refused")
+	}
+}
@@ -0,0 +1,176 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+)
+
+// DefaultNominatimURL is the public OpenStreetMap Nominatim instance. Its
+// usage policy allows one request a second; batch geocoding should use a
+// hosted or self-run instance.
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+
+// nominatimTimeout bounds one search
+const nominatimTimeout = 10 * time.Second
+
+// nominatimUserAgent identifies the service, as Nominatim requires
+const nominatimUserAgent = "InsuranceStack-customer-service/1.0"
+
+// Nominatim normalizes addresses with the structured search of a
+// Nominatim-compatible geocoding API, such as OpenStreetMap's or a hosted
+// one like LocationIQ
+type Nominatim struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+var _ Normalizer = (*Nominatim)(nil)
+
+// NewNominatim creates a normalizer for the API at baseURL, the public
+// instance when empty. apiKey is sent as the key parameter when set.
+func NewNominatim(baseURL, apiKey string) *Nominatim {
+	if baseURL == "" {
+		baseURL = DefaultNominatimURL
+	}
+	return &Nominatim{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: nominatimTimeout},
+	}
+}
+
+// nominatimPlace is a search result, with address details
+type nominatimPlace struct {
+	Lat     string            `json:"lat"`
+	Lon     string            `json:"lon"`
+	Address map[string]string `json:"address"`
+}
+
+// Name returns "nominatim"
+func (n *Nominatim) Name() string {
+	return NameNominatim
+}
+
+// Normalize searches for addr and returns the best match in standard form.
+// Components the match leaves out are kept from addr.
+func (n *Nominatim) Normalize(ctx context.Context, addr models.Address) (*models.NormalizedAddress, error) {
+	given := standardize(addr)
+	query := url.Values{
+		"format":         {"jsonv2"},
+		"addressdetails": {"1"},
+		"limit":          {"1"},
+	}
+	for param, value := range map[string]string{
+		"street":     given.Street,
+		"city":       given.City,
+		"state":      given.State,
+		"postalcode": given.PostalCode,
+	} {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+	if _, ok := lookupCountry(given.Country); ok {
+		query.Set("countrycodes", strings.ToLower(given.Country))
+	} else if given.Country != "" {
+		query.Set("country", given.Country)
+	}
+	if n.apiKey != "" {
+		query.Set("key", n.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", nominatimUserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nominatim returned status %d", resp.StatusCode)
+	}
+
+	var places []nominatimPlace
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return nil, fmt.Errorf("failed to decode nominatim response: %w", err)
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("address not found")
+	}
+	return n.normalized(places[0], given)
+}
+
+// normalized returns the standard form of place, filling in what it leaves
+// out from the address given
+func (n *Nominatim) normalized(place nominatimPlace, given *models.NormalizedAddress) (*models.NormalizedAddress, error) {
+	latitude, err := strconv.ParseFloat(place.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nominatim latitude %q", place.Lat)
+	}
+	longitude, err := strconv.ParseFloat(place.Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nominatim longitude %q", place.Lon)
+	}
+
+	a := place.Address
+	street := strings.TrimSpace(a["house_number"] + " " + a["road"])
+	city := first(a, "city", "town", "village", "hamlet", "municipality")
+	// ISO3166-2-lvl4 is the subdivision code, such as US-CA
+	state := a["state"]
+	if _, code, ok := strings.Cut(a["ISO3166-2-lvl4"], "-"); ok {
+		state = code
+	}
+
+	normalized := standardize(models.Address{
+		Street:  or(street, given.Street),
+		City:    or(city, given.City),
+		State:   or(state, given.State),
+		ZipCode: or(a["postcode"], given.PostalCode),
+		Country: or(a["country_code"], given.Country),
+	})
+	precision := models.PrecisionCountry
+	switch {
+	case a["house_number"] != "":
+		precision = models.PrecisionAddress
+	case a["road"] != "":
+		precision = models.PrecisionStreet
+	case city != "":
+		precision = models.PrecisionCity
+	case a["state"] != "":
+		precision = models.PrecisionState
+	}
+	normalized.Location = &models.GeoLocation{Latitude: latitude, Longitude: longitude, Precision: precision}
+	return normalized, nil
+}
+
+// first returns the first of keys that a holds
+func first(a map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if a[key] != "" {
+			return a[key]
+		}
+	}
+	return ""
+}
+
+// or returns value, or fallback when value is empty
+func or(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,222 @@
+package geocode
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+)
+
+// region is a US state or a country, with its centre
+type region struct {
+	code      string
+	name      string
+	latitude  float64
+	longitude float64
+}
+
+// usStates are the US states, with DC, by USPS code
+var usStates = []region{
+	{"AL", "ALABAMA", 32.806671, -86.791130},
+	{"AK", "ALASKA", 61.370716, -152.404419},
+	{"AZ", "ARIZONA", 33.729759, -111.431221},
+	{"AR", "ARKANSAS", 34.969704, -92.373123},
+	{"CA", "CALIFORNIA", 36.116203, -119.681564},
+	{"CO", "COLORADO", 39.059811, -105.311104},
+	{"CT", "CONNECTICUT", 41.597782, -72.755371},
+	{"DE", "DELAWARE", 39.318523, -75.507141},
+	{"DC", "DISTRICT OF COLUMBIA", 38.897438, -77.026817},
+	{"FL", "FLORIDA", 27.766279, -81.686783},
+	{"GA", "GEORGIA", 33.040619, -83.643074},
+	{"HI", "HAWAII", 21.094318, -157.498337},
+	{"ID", "IDAHO", 44.240459, -114.478828},
+	{"IL", "ILLINOIS", 40.349457, -88.986137},
+	{"IN", "INDIANA", 39.849426, -86.258278},
+	{"IA", "IOWA", 42.011539, -93.210526},
+	{"KS", "KANSAS", 38.526600, -96.726486},
+	{"KY", "KENTUCKY", 37.668140, -84.670067},
+	{"LA", "LOUISIANA", 31.169546, -91.867805},
+	{"ME", "MAINE", 44.693947, -69.381927},
+	{"MD", "MARYLAND", 39.063946, -76.802101},
+	{"MA", "MASSACHUSETTS", 42.230171, -71.530106},
+	{"MI", "MICHIGAN", 43.326618, -84.536095},
+	{"MN", "MINNESOTA", 45.694454, -93.900192},
+	{"MS", "MISSISSIPPI", 32.741646, -89.678696},
+	{"MO", "MISSOURI", 38.456085, -92.288368},
+	{"MT", "MONTANA", 46.921925, -110.454353},
+	{"NE", "NEBRASKA", 41.125370, -98.268082},
+	{"NV", "NEVADA", 38.313515, -117.055374},
+	{"NH", "NEW HAMPSHIRE", 43.452492, -71.563896},
+	{"NJ", "NEW JERSEY", 40.298904, -74.521011},
+	{"NM", "NEW MEXICO", 34.840515, -106.248482},
+	{"NY", "NEW YORK", 42.165726, -74.948051},
+	{"NC", "NORTH CAROLINA", 35.630066, -79.806419},
+	{"ND", "NORTH DAKOTA", 47.528912, -99.784012},
+	{"OH", "OHIO", 40.388783, -82.764915},
+	{"OK", "OKLAHOMA", 35.565342, -96.928917},
+	{"OR", "OREGON", 44.572021, -122.070938},
+	{"PA", "PENNSYLVANIA", 40.590752, -77.209755},
+	{"RI", "RHODE ISLAND", 41.680893, -71.511780},
+	{"SC", "SOUTH CAROLINA", 33.856892, -80.945007},
+	{"SD", "SOUTH DAKOTA", 44.299782, -99.438828},
+	{"TN", "TENNESSEE", 35.747845, -86.692345},
+	{"TX", "TEXAS", 31.054487, -97.563461},
+	{"UT", "UTAH", 40.150032, -111.862434},
+	{"VT", "VERMONT", 44.045876, -72.710686},
+	{"VA", "VIRGINIA", 37.769337, -78.169968},
+	{"WA", "WASHINGTON", 47.400902, -121.490494},
+	{"WV", "WEST VIRGINIA", 38.491226, -80.954453},
+	{"WI", "WISCONSIN", 44.268543, -89.616508},
+	{"WY", "WYOMING", 42.755966, -107.302490},
+}
+
+// countries are the countries customers live in, by ISO 3166-1 alpha-2
+// code
+var countries = []region{
+	{"US", "UNITED STATES", 39.828175, -98.579500},
+	{"CA", "CANADA", 56.130366, -106.346771},
+	{"MX", "MEXICO", 23.634501, -102.552784},
+	{"GB", "UNITED KINGDOM", 54.000000, -2.000000},
+	{"IE", "IRELAND", 53.412910, -8.243890},
+	{"FR", "FRANCE", 46.227638, 2.213749},
+	{"DE", "GERMANY", 51.165691, 10.451526},
+	{"ES", "SPAIN", 40.463667, -3.749220},
+	{"IT", "ITALY", 41.871940, 12.567380},
+	{"NL", "NETHERLANDS", 52.132633, 5.291266},
+	{"JP", "JAPAN", 36.204824, 138.252924},
+	{"AU", "AUSTRALIA", -25.274398, 133.775136},
+}
+
+// countryAliases are other names customers give their country
+var countryAliases = map[string]string{
+	"USA":                      "US",
+	"UNITED STATES OF AMERICA": "US",
+	"AMERICA":                  "US",
+	"UK":                       "GB",
+	"GREAT BRITAIN":            "GB",
+	"BRITAIN":                  "GB",
+	"ENGLAND":                  "GB",
+	"SCOTLAND":                 "GB",
+	"WALES":                    "GB",
+	"NORTHERN IRELAND":         "GB",
+	"DEUTSCHLAND":              "DE",
+	"ESPAÑA":                   "ES",
+	"ESPANA":                   "ES",
+	"ITALIA":                   "IT",
+	"HOLLAND":                  "NL",
+	"THE NETHERLANDS":          "NL",
+	"MÉXICO":                   "MX",
+}
+
+// usStreetWords are the USPS abbreviations of street suffixes, directionals
+// and unit designators
+var usStreetWords = map[string]string{
+	"ALLEY": "ALY", "AVENUE": "AVE", "BOULEVARD": "BLVD", "CIRCLE": "CIR",
+	"COURT": "CT", "DRIVE": "DR", "EXPRESSWAY": "EXPY", "FREEWAY": "FWY",
+	"HIGHWAY": "HWY", "LANE": "LN", "PARKWAY": "PKWY", "PLACE": "PL",
+	"PLAZA": "PLZ", "ROAD": "RD", "SQUARE": "SQ", "STREET": "ST",
+	"TERRACE": "TER", "TRAIL": "TRL", "TURNPIKE": "TPKE",
+	"NORTH": "N", "SOUTH": "S", "EAST": "E", "WEST": "W",
+	"NORTHEAST": "NE", "NORTHWEST": "NW", "SOUTHEAST": "SE", "SOUTHWEST": "SW",
+	"APARTMENT": "APT", "BUILDING": "BLDG", "FLOOR": "FL", "SUITE": "STE",
+}
+
+// lookupState returns the US state given by code or name
+func lookupState(state string) (region, bool) {
+	for _, s := range usStates {
+		if state == s.code || state == s.name {
+			return s, true
+		}
+	}
+	return region{}, false
+}
+
+// lookupCountry returns the country given by code, name or alias
+func lookupCountry(country string) (region, bool) {
+	if code, ok := countryAliases[country]; ok {
+		country = code
+	}
+	for _, c := range countries {
+		if country == c.code || country == c.name {
+			return c, true
+		}
+	}
+	return region{}, false
+}
+
+// standardize puts addr in standard form, without locating it. A country
+// the tables do not know is kept as given, upper-cased.
+func standardize(addr models.Address) *models.NormalizedAddress {
+	normalized := &models.NormalizedAddress{
+		Street:     clean(addr.Street),
+		City:       clean(addr.City),
+		State:      clean(addr.State),
+		PostalCode: clean(addr.ZipCode),
+		Country:    clean(addr.Country),
+	}
+	if country, ok := lookupCountry(normalized.Country); ok {
+		normalized.Country = country.code
+	}
+	// Addresses without a country are taken to be in the US when their
+	// state is one
+	if normalized.Country == "" {
+		if _, ok := lookupState(normalized.State); ok {
+			normalized.Country = "US"
+		}
+	}
+
+	if normalized.Country == "US" {
+		if state, ok := lookupState(normalized.State); ok {
+			normalized.State = state.code
+		}
+		normalized.Street = abbreviateStreet(normalized.Street)
+		normalized.PostalCode = zipCode(normalized.PostalCode)
+	}
+	return normalized
+}
+
+// clean upper-cases s and collapses its whitespace, dropping the periods
+// and commas that free-text addresses are punctuated with
+func clean(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return -1
+		case ',':
+			return ' '
+		}
+		return unicode.ToUpper(r)
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// abbreviateStreet replaces the words of a US street with their USPS
+// abbreviations
+func abbreviateStreet(street string) string {
+	words := strings.Fields(street)
+	for i, word := range words {
+		if abbreviation, ok := usStreetWords[word]; ok {
+			words[i] = abbreviation
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// zipCode formats a US ZIP code as 12345 or ZIP+4 as 12345-6789. Codes of
+// any other length are kept as given.
+func zipCode(zip string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, zip)
+	switch len(digits) {
+	case 5:
+		return digits
+	case 9:
+		return digits[:5] + "-" + digits[5:]
+	default:
+		return zip
+	}
+}
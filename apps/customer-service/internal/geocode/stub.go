@@ -0,0 +1,38 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+)
+
+// Stub normalizes addresses without a provider. US addresses are located
+// at the centre of their state and others at the centre of their country,
+// so territories can be told apart in development; an address in a country
+// it does not know is not found.
+type Stub struct{}
+
+var _ Normalizer = Stub{}
+
+// Name returns "stub"
+func (Stub) Name() string {
+	return NameStub
+}
+
+// Normalize puts addr in standard form and locates it by region
+func (Stub) Normalize(ctx context.Context, addr models.Address) (*models.NormalizedAddress, error) {
+	normalized := standardize(addr)
+	if normalized.Country == "US" {
+		if state, ok := lookupState(normalized.State); ok {
+			normalized.Location = &models.GeoLocation{Latitude: state.latitude, Longitude: state.longitude, Precision: models.PrecisionState}
+			return normalized, nil
+		}
+	}
+	country, ok := lookupCountry(normalized.Country)
+	if !ok {
+		return nil, fmt.Errorf("address not found")
+	}
+	normalized.Location = &models.GeoLocation{Latitude: country.latitude, Longitude: country.longitude, Precision: models.PrecisionCountry}
+	return normalized, nil
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// maxActivityBody bounds the events posted to the activity log
+const maxActivityBody = 1 << 20
+
+// ActivityLog records customers' activity and pages through it.
+// *services.ActivityService is the production implementation.
+type ActivityLog interface {
+	Record(req models.RecordActivityRequest) (*models.Activity, error)
+	RecordClaimEvent(evt models.ClaimEvent) (*models.Activity, error)
+	Feed(customerID string, query models.ActivityQuery) (*models.ActivityPage, error)
+}
+
+var _ ActivityLog = (*services.ActivityService)(nil)
+
+// ActivityHandler serves customers' activity feeds to staff and takes the
+// events other services report for them
+type ActivityHandler struct {
+	activity ActivityLog
+	// secrets verify the X-Webhook-Signature of reported events, which may
+	// be signed with any of them; without any they are accepted unsigned
+	secrets signing.Keys
+	logger  *logrus.Logger
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler(activity ActivityLog, secrets signing.Keys, logger *logrus.Logger) *ActivityHandler {
+	return &ActivityHandler{
+		activity: activity,
+		secrets:  secrets,
+		logger:   logger,
+	}
+}
+
+// GetActivity handles GET /customers/{id}/activity - a page of the
+// customer's activity, newest first
+// Supports query parameters:
+// - category: comma-separated categories (account, policy, claim, payment, login)
+// - type: a single activity type, e.g. claim.status_changed
+// - cursor: the nextCursor of the previous page
+// - limit: page size, 50 by default and at most 200
+func (h *ActivityHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := models.ActivityQuery{
+		Type:   params.Get("type"),
+		Cursor: params.Get("cursor"),
+	}
+	if categories := params.Get("category"); categories != "" {
+		query.Categories = strings.Split(categories, ",")
+	}
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "bad_request",
+				Message: "limit must be a number",
+			})
+			return
+		}
+		query.Limit = n
+	}
+
+	page, err := h.activity.Feed(mux.Vars(r)["id"], query)
+	if err != nil {
+		if err.Error() == "customer not found" {
+			h.respondJSON(w, http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "Customer not found",
+			})
+			return
+		}
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: err.Error(),
+		})
+		return
+	}
+	h.respondJSON(w, http.StatusOK, page)
+}
+
+// RecordActivity handles POST /activity - an event another service reports
+// for a customer's feed, such as a policy change, payment or login
+func (h *ActivityHandler) RecordActivity(w http.ResponseWriter, r *http.Request) {
+	var req models.RecordActivityRequest
+	if !h.decodeSigned(w, r, &req) {
+		return
+	}
+	activity, err := h.activity.Record(req)
+	h.respondRecorded(w, activity, err)
+}
+
+// ReceiveClaimEvent handles POST /webhooks/claims - a claim event delivered
+// by claims-service's webhooks
+func (h *ActivityHandler) ReceiveClaimEvent(w http.ResponseWriter, r *http.Request) {
+	var evt models.ClaimEvent
+	if !h.decodeSigned(w, r, &evt) {
+		return
+	}
+	activity, err := h.activity.RecordClaimEvent(evt)
+	h.respondRecorded(w, activity, err)
+}
+
+// decodeSigned reads a reported event into v once its signature is
+// verified, responding and returning false when it cannot be
+func (h *ActivityHandler) decodeSigned(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxActivityBody))
+	if err != nil {
+		h.respondJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{
+			Error:   "bad_request",
+			Message: "Event is too large",
+		})
+		return false
+	}
+	if len(h.secrets) > 0 && !h.secrets.Verify(body, r.Header.Get(signing.Header)) {
+		h.logger.WithField("path", r.URL.Path).Warn("Rejected activity event with an invalid signature")
+		h.respondJSON(w, http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "Missing or invalid X-Webhook-Signature",
+		})
+		return false
+	}
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(v); err != nil {
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: "Invalid request body",
+		})
+		return false
+	}
+	return true
+}
+
+// respondRecorded answers a reported event. An event already recorded is
+// answered 200 so that the sender's retries succeed.
+func (h *ActivityHandler) respondRecorded(w http.ResponseWriter, activity *models.Activity, err error) {
+	if err == nil {
+		h.respondJSON(w, http.StatusCreated, activity)
+		return
+	}
+	switch err.Error() {
+	case "activity already recorded":
+		h.respondJSON(w, http.StatusOK, map[string]string{"status": "duplicate"})
+	case "customer not found":
+		h.respondJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Customer not found",
+		})
+	default:
+		if strings.HasSuffix(err.Error(), " is required") || strings.HasPrefix(err.Error(), "unknown activity type") {
+			h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "bad_request",
+				Message: err.Error(),
+			})
+			return
+		}
+		h.logger.WithError(err).Error("Failed to record activity")
+		h.respondJSON(w, http.StatusInternalServerError, ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to record activity",
+		})
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *ActivityHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+	"github.com/sirupsen/logrus"
+)
+
+// TestPricingEngineContract verifies customer-service still serves the
+// paperless billing consent pricing-engine checks before discounting a quote.
+// The consumer half lives in pricing-engine.
+func TestPricingEngineContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	// Provider states are checked against the same seed data the service loaded
+	repo, err := repository.NewRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to load seed data: %v", err)
+	}
+	paperlessConsent := func(customerID string, granted bool) error {
+		customer, err := repo.GetCustomerByID(customerID)
+		if err != nil {
+			return err
+		}
+		if customer.Preferences == nil || customer.Preferences.PaperlessBilling.RecordedAt == nil || customer.Preferences.PaperlessBilling.Granted != granted {
+			return fmt.Errorf("%s has preferences %+v", customerID, customer.Preferences)
+		}
+		return nil
+	}
+
+	contracts.VerifyProvider(t, application.Handler, contracts.MustLoad("pricing-engine", "customer-service"), contracts.StateHandlers{
+		"customer cust-001 has consented to paperless billing": func() error {
+			return paperlessConsent("cust-001", true)
+		},
+		"customer cust-002 has withdrawn paperless billing consent": func() error {
+			return paperlessConsent("cust-002", false)
+		},
+		"customer cust-404 does not exist": func() error {
+			if _, err := repo.GetCustomerByID("cust-404"); err == nil {
+				return fmt.Errorf("cust-404 exists in seed data")
+			}
+			return nil
+		},
+	})
+}
+
+// TestPolicyServiceContract verifies customer-service still serves the
+// customer and household fields policy-service reads, including the verified
+// email and KYC status it may require before creating a policy. The consumer
+// half lives in policy-service.
+func TestPolicyServiceContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	repo, err := repository.NewRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to load seed data: %v", err)
+	}
+	emailVerified := func(customerID string, verified bool) error {
+		customer, err := repo.GetCustomerByID(customerID)
+		if err != nil {
+			return err
+		}
+		if customer.EmailVerified != verified {
+			return fmt.Errorf("%s has emailVerified %v", customerID, customer.EmailVerified)
+		}
+		return nil
+	}
+
+	contracts.VerifyProvider(t, application.Handler, contracts.MustLoad("policy-service", "customer-service"), contracts.StateHandlers{
+		"customer cust-001 has a verified email and verified KYC": func() error {
+			if err := emailVerified("cust-001", true); err != nil {
+				return err
+			}
+			customer, err := repo.GetCustomerByID("cust-001")
+			if err != nil {
+				return err
+			}
+			if customer.KYCStatus != "verified" {
+				return fmt.Errorf("cust-001 has kycStatus %q", customer.KYCStatus)
+			}
+			return nil
+		},
+		"customer cust-008 has an unverified email": func() error {
+			return emailVerified("cust-008", false)
+		},
+		"customer cust-005 belongs to household hh-001": func() error {
+			customer, err := repo.GetCustomerByID("cust-005")
+			if err != nil {
+				return err
+			}
+			if customer.HouseholdID != "hh-001" {
+				return fmt.Errorf("cust-005 is in household %q", customer.HouseholdID)
+			}
+			return nil
+		},
+		"household hh-001 has members cust-005 and cust-007": func() error {
+			household, err := repo.GetHouseholdByID("hh-001")
+			if err != nil {
+				return err
+			}
+			if len(household.MemberIDs) != 2 || !household.HasMember("cust-005") || !household.HasMember("cust-007") {
+				return fmt.Errorf("hh-001 has members %v", household.MemberIDs)
+			}
+			return nil
+		},
+		"customer cust-404 does not exist": func() error {
+			if _, err := repo.GetCustomerByID("cust-404"); err == nil {
+				return fmt.Errorf("cust-404 exists in seed data")
+			}
+			return nil
+		},
+	})
+}
@@ -10,14 +10,28 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// CustomerService is the business logic the customer handler depends on.
+// *services.CustomerService is the production implementation.
+type CustomerService interface {
+	GetAllCustomers() ([]*models.Customer, error)
+	GetCustomerByID(customerID string) (*models.Customer, error)
+	CreateCustomer(req models.CreateCustomerRequest) (*models.Customer, error)
+	UpdateCustomer(customerID string, req models.UpdateCustomerRequest) (*models.Customer, error)
+	DeactivateCustomer(customerID string) error
+	GetPreferences(customerID string) (*models.Preferences, error)
+	UpdatePreferences(customerID string, req models.UpdatePreferencesRequest) (*models.Preferences, error)
+}
+
+var _ CustomerService = (*services.CustomerService)(nil)
+
 // CustomerHandler handles customer-related requests
 type CustomerHandler struct {
-	customerService *services.CustomerService
+	customerService CustomerService
 	logger          *logrus.Logger
 }
 
 // NewCustomerHandler creates a new customer handler
-func NewCustomerHandler(customerService *services.CustomerService, logger *logrus.Logger) *CustomerHandler {
+func NewCustomerHandler(customerService CustomerService, logger *logrus.Logger) *CustomerHandler {
 	return &CustomerHandler{
 		customerService: customerService,
 		logger:          logger,
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// AddressGeocoder re-geocodes customers' addresses in batches.
+// *services.GeocodeService is the production implementation.
+type AddressGeocoder interface {
+	Start(opts models.GeocodeOptions) (*models.GeocodeRun, error)
+	Get(runID string) (*models.GeocodeRun, error)
+}
+
+var _ AddressGeocoder = (*services.GeocodeService)(nil)
+
+// GeocodeHandler serves geocoding runs to admins
+type GeocodeHandler struct {
+	geocoder AddressGeocoder
+	logger   *logrus.Logger
+}
+
+// NewGeocodeHandler creates a new geocoding handler
+func NewGeocodeHandler(geocoder AddressGeocoder, logger *logrus.Logger) *GeocodeHandler {
+	return &GeocodeHandler{
+		geocoder: geocoder,
+		logger:   logger,
+	}
+}
+
+// StartGeocoding handles POST /admin/customers/geocode - re-geocodes
+// customers' addresses in the background
+// Supports query parameters:
+// - all: true to re-geocode every customer, not only those without a normalized address
+func (h *GeocodeHandler) StartGeocoding(w http.ResponseWriter, r *http.Request) {
+	run, err := h.geocoder.Start(models.GeocodeOptions{
+		All:       r.URL.Query().Get("all") == "true",
+		StartedBy: middleware.GetUserID(r),
+	})
+	if err != nil {
+		switch err.Error() {
+		case "address normalization not configured":
+			h.respondJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "unavailable",
+				Message: "Addresses cannot be geocoded while no address normalizer is configured",
+			})
+		case "a geocoding run is already running":
+			h.respondJSON(w, http.StatusConflict, ErrorResponse{
+				Error:   "conflict",
+				Message: "A geocoding run is already running",
+			})
+		default:
+			h.logger.WithError(err).Error("Failed to start geocoding run")
+			h.respondJSON(w, http.StatusInternalServerError, ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to start geocoding run",
+			})
+		}
+		return
+	}
+
+	w.Header().Set("Location", "/admin/customers/geocode/"+run.ID)
+	h.respondJSON(w, http.StatusAccepted, run)
+}
+
+// GetGeocoding handles GET /admin/customers/geocode/{id} - the progress of
+// a geocoding run
+func (h *GeocodeHandler) GetGeocoding(w http.ResponseWriter, r *http.Request) {
+	run, err := h.geocoder.Get(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "Geocoding run not found",
+		})
+		return
+	}
+	h.respondJSON(w, http.StatusOK, run)
+}
+
+// respondJSON sends a JSON response
+func (h *GeocodeHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// HouseholdService is the business logic the household handler depends on.
+// *services.HouseholdService is the production implementation.
+type HouseholdService interface {
+	GetHousehold(householdID string) (*models.Household, error)
+	CreateHousehold(req models.CreateHouseholdRequest) (*models.Household, error)
+	JoinHousehold(householdID string, req models.JoinHouseholdRequest) (*models.Household, error)
+	LeaveHousehold(householdID, customerID string) (*models.Household, error)
+}
+
+var _ HouseholdService = (*services.HouseholdService)(nil)
+
+// HouseholdHandler handles household requests
+type HouseholdHandler struct {
+	householdService HouseholdService
+	logger           *logrus.Logger
+}
+
+// NewHouseholdHandler creates a new household handler
+func NewHouseholdHandler(householdService HouseholdService, logger *logrus.Logger) *HouseholdHandler {
+	return &HouseholdHandler{
+		householdService: householdService,
+		logger:           logger,
+	}
+}
+
+// GetHousehold handles GET /households/{id} - returns a household and its
+// members
+func (h *HouseholdHandler) GetHousehold(w http.ResponseWriter, r *http.Request) {
+	householdID := mux.Vars(r)["id"]
+
+	household, err := h.householdService.GetHousehold(householdID)
+	if err != nil {
+		h.respondServiceError(w, householdID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, household)
+}
+
+// CreateHousehold handles POST /households - creates a household for a
+// customer
+func (h *HouseholdHandler) CreateHousehold(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	household, err := h.householdService.CreateHousehold(req)
+	if err != nil {
+		h.respondServiceError(w, "", err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, household)
+}
+
+// JoinHousehold handles POST /households/{id}/members - adds a customer to
+// a household
+func (h *HouseholdHandler) JoinHousehold(w http.ResponseWriter, r *http.Request) {
+	householdID := mux.Vars(r)["id"]
+
+	var req models.JoinHouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	household, err := h.householdService.JoinHousehold(householdID, req)
+	if err != nil {
+		h.respondServiceError(w, householdID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, household)
+}
+
+// LeaveHousehold handles DELETE /households/{id}/members/{customerId} -
+// removes a customer from a household. Removing the last member dissolves
+// the household and returns 204.
+func (h *HouseholdHandler) LeaveHousehold(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	householdID := vars["id"]
+
+	household, err := h.householdService.LeaveHousehold(householdID, vars["customerId"])
+	if err != nil {
+		h.respondServiceError(w, householdID, err)
+		return
+	}
+
+	if len(household.MemberIDs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	h.respondJSON(w, http.StatusOK, household)
+}
+
+// respondServiceError maps household service errors to HTTP statuses
+func (h *HouseholdHandler) respondServiceError(w http.ResponseWriter, householdID string, err error) {
+	status, code, message := http.StatusBadRequest, "bad_request", err.Error()
+	switch {
+	case err.Error() == "household not found":
+		status, code, message = http.StatusNotFound, "not_found", "Household not found"
+	case err.Error() == "customer not found":
+		status, code, message = http.StatusNotFound, "not_found", "Customer not found"
+	case err.Error() == "customer already belongs to a household":
+		status, code = http.StatusConflict, "conflict"
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("householdId", householdID).Error("Household update failed")
+		status, code, message = http.StatusInternalServerError, "internal_error", "Failed to update household"
+	}
+
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
+
+// respondJSON sends a JSON response
+func (h *HouseholdHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
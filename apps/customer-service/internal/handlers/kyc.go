@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// maxKYCUploadBody allows for the multipart framing around a document of
+// the maximum size
+const maxKYCUploadBody = models.MaxKYCDocumentSize + 1<<20
+
+// KYCService is the business logic the KYC handler depends on.
+// *services.KYCService is the production implementation.
+type KYCService interface {
+	UploadDocument(customerID, uploadedBy, documentType, fileName, contentType string, content []byte) (*models.KYCDocument, error)
+	GetKYC(customerID string) (*models.KYCSummary, error)
+	GetDocument(customerID, documentID string) (*models.KYCDocument, []byte, error)
+	ReviewDocument(customerID, documentID, reviewerID string, req *models.ReviewKYCDocumentRequest) (*models.KYCDocument, error)
+}
+
+var _ KYCService = (*services.KYCService)(nil)
+
+// KYCHandler handles KYC document requests. The review route must be
+// wrapped in middleware.RequireRole so the reviewer is a verified staff
+// member.
+type KYCHandler struct {
+	kycService KYCService
+	logger     *logrus.Logger
+}
+
+// NewKYCHandler creates a new KYC handler
+func NewKYCHandler(kycService KYCService, logger *logrus.Logger) *KYCHandler {
+	return &KYCHandler{
+		kycService: kycService,
+		logger:     logger,
+	}
+}
+
+// GetKYC handles GET /customers/{id}/kyc - returns the customer's KYC
+// status and documents
+func (h *KYCHandler) GetKYC(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	summary, err := h.kycService.GetKYC(customerID)
+	if err != nil {
+		h.respondServiceError(w, customerID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, summary))
+}
+
+// UploadDocument handles POST /customers/{id}/kyc/documents. The document
+// is sent as the "file" part of a multipart/form-data body with its type
+// in the "documentType" field.
+func (h *KYCHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxKYCUploadBody)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error:   "bad_request",
+				Message: "document exceeds the upload limit",
+			})
+			return
+		}
+		h.logger.WithError(err).Warn("Invalid KYC document upload")
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: "multipart form with a file part is required",
+		})
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, models.MaxKYCDocumentSize+1))
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to read uploaded KYC document")
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: "Failed to read document",
+		})
+		return
+	}
+
+	doc, err := h.kycService.UploadDocument(customerID, middleware.GetUserID(r), r.FormValue("documentType"), header.Filename, header.Header.Get("Content-Type"), content)
+	if err != nil {
+		h.respondServiceError(w, customerID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, shaping.Apply(r, doc))
+}
+
+// GetDocument handles GET /customers/{id}/kyc/documents/{documentId} and
+// returns the document content
+func (h *KYCHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID, documentID := vars["id"], vars["documentId"]
+
+	doc, content, err := h.kycService.GetDocument(customerID, documentID)
+	if err != nil {
+		h.respondServiceError(w, customerID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(content); err != nil {
+		h.logger.WithError(err).WithField("documentId", documentID).Error("Failed to write KYC document")
+	}
+}
+
+// ReviewDocument handles POST /customers/{id}/kyc/documents/{documentId}/review
+// - verifies or rejects a pending document
+func (h *KYCHandler) ReviewDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	customerID := vars["id"]
+
+	var req models.ReviewKYCDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		h.respondJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "bad_request",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	doc, err := h.kycService.ReviewDocument(customerID, vars["documentId"], middleware.GetUserID(r), &req)
+	if err != nil {
+		h.respondServiceError(w, customerID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, shaping.Apply(r, doc))
+}
+
+// respondServiceError maps KYC service errors to HTTP statuses
+func (h *KYCHandler) respondServiceError(w http.ResponseWriter, customerID string, err error) {
+	status, code, message := http.StatusBadRequest, "bad_request", err.Error()
+	var limitErr *blobstore.LimitError
+	switch {
+	case errors.As(err, &limitErr):
+		message = limitErr.Error()
+	case err.Error() == "customer not found":
+		status, code, message = http.StatusNotFound, "not_found", "Customer not found"
+	case err.Error() == "KYC document not found":
+		status, code, message = http.StatusNotFound, "not_found", "KYC document not found"
+	case err.Error() == "document has already been reviewed":
+		status, code = http.StatusConflict, "conflict"
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("customerId", customerID).Error("KYC update failed")
+		status, code, message = http.StatusInternalServerError, "internal_error", "Failed to update KYC documents"
+	}
+
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
+
+// respondJSON sends a JSON response
+func (h *KYCHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/gorilla/mux"
+)
+
+// GetPreferences handles GET /customers/{id}/preferences - returns a
+// customer's communication preferences and paperless billing consent
+func (h *CustomerHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	preferences, err := h.customerService.GetPreferences(customerID)
+	if err != nil {
+		h.logger.WithError(err).WithField("customerId", customerID).Error("Failed to get preferences")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "not_found",
+			Message: "Customer not found",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(preferences)
+}
+
+// UpdatePreferences handles PUT /customers/{id}/preferences - replaces a
+// customer's communication preferences
+func (h *CustomerHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	var req models.UpdatePreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode request body")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "bad_request",
+			Message: "Invalid request body",
+		})
+		return
+	}
+
+	preferences, err := h.customerService.UpdatePreferences(customerID, req)
+	if err != nil {
+		status, code, message := http.StatusBadRequest, "bad_request", err.Error()
+		switch {
+		case err.Error() == "customer not found":
+			status, code, message = http.StatusNotFound, "not_found", "Customer not found"
+		case strings.HasPrefix(err.Error(), "failed to"):
+			status, code, message = http.StatusInternalServerError, "internal_error", "Failed to update preferences"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(preferences)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// VerificationService is the business logic the verification handler
+// depends on. *services.VerificationService is the production implementation.
+type VerificationService interface {
+	SendVerification(ctx context.Context, customerID string) (*models.VerificationSent, error)
+	VerifyEmail(token string) (*models.Customer, error)
+}
+
+var _ VerificationService = (*services.VerificationService)(nil)
+
+// VerificationHandler handles email verification requests
+type VerificationHandler struct {
+	verificationService VerificationService
+	logger              *logrus.Logger
+}
+
+// NewVerificationHandler creates a new verification handler
+func NewVerificationHandler(verificationService VerificationService, logger *logrus.Logger) *VerificationHandler {
+	return &VerificationHandler{
+		verificationService: verificationService,
+		logger:              logger,
+	}
+}
+
+// SendVerification handles POST /customers/{id}/send-verification - emails
+// the customer a verification link
+func (h *VerificationHandler) SendVerification(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	sent, err := h.verificationService.SendVerification(r.Context(), customerID)
+	if err != nil {
+		h.respondServiceError(w, customerID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(sent)
+}
+
+// VerifyEmail handles GET /customers/verify?token= - marks the email the
+// token was sent to as verified
+func (h *VerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	customer, err := h.verificationService.VerifyEmail(r.URL.Query().Get("token"))
+	if err != nil {
+		h.respondServiceError(w, "", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(customer)
+}
+
+// respondServiceError maps verification service errors to HTTP statuses
+func (h *VerificationHandler) respondServiceError(w http.ResponseWriter, customerID string, err error) {
+	status, code, message := http.StatusBadRequest, "bad_request", err.Error()
+	switch {
+	case err.Error() == "customer not found":
+		status, code, message = http.StatusNotFound, "not_found", "Customer not found"
+	case err.Error() == "email already verified":
+		status, code = http.StatusConflict, "conflict"
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("customerId", customerID).Error("Email verification failed")
+		status, code, message = http.StatusInternalServerError, "internal_error", "Email verification failed"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
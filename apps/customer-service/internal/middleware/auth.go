@@ -4,13 +4,17 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey contextKey = "userID"
+	roleKey   contextKey = "role"
+)
 
 // AuthMiddleware extracts user ID from X-User-ID header (simplified for demo)
 func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
@@ -24,6 +28,7 @@ func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
 
 			// Extract user ID from X-User-ID header (demo purposes)
 			userID := r.Header.Get("X-User-ID")
+			telemetry.SetIdentity(r.Context(), userID, "")
 			if userID == "" {
 				userID = "cust-001" // Default for demo
 			}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Activity categories, the kinds of entry in a customer's activity feed
+const (
+	ActivityAccount = "account" // profile, preferences, KYC and household changes
+	ActivityPolicy  = "policy"
+	ActivityClaim   = "claim"
+	ActivityPayment = "payment"
+	ActivityLogin   = "login"
+)
+
+// ActivityCategories lists the categories in the order the feed documents
+// them
+var ActivityCategories = []string{ActivityAccount, ActivityPolicy, ActivityClaim, ActivityPayment, ActivityLogin}
+
+// activityPrefixes maps the first segment of an activity type, such as
+// claim in claim.status_changed, to its category
+var activityPrefixes = map[string]string{
+	"customer":    ActivityAccount,
+	"preferences": ActivityAccount,
+	"kyc":         ActivityAccount,
+	"household":   ActivityAccount,
+	"policy":      ActivityPolicy,
+	"claim":       ActivityClaim,
+	"payment":     ActivityPayment,
+	"refund":      ActivityPayment,
+	"payout":      ActivityPayment,
+	"login":       ActivityLogin,
+}
+
+// ActivityCategoryOf returns the category of an activity type, or "" when
+// its prefix is not one the feed knows
+func ActivityCategoryOf(activityType string) string {
+	prefix, _, ok := strings.Cut(activityType, ".")
+	if !ok {
+		return ""
+	}
+	return activityPrefixes[prefix]
+}
+
+// Activity is one entry of a customer's activity feed: something the
+// customer did, or that happened to their account, policies, claims or
+// payments
+type Activity struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customerId"`
+	Category   string `json:"category"`
+	// Type is what happened, e.g. claim.status_changed or login.failed
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+	// Source is the service that reported it
+	Source string `json:"source"`
+	// EventID is the source's ID for the event, so an event delivered
+	// twice is recorded once
+	EventID string `json:"eventId,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+	// ResourceID is the record it concerns, such as a claim or policy ID
+	ResourceID string            `json:"resourceId,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	OccurredAt time.Time         `json:"occurredAt"`
+	RecordedAt time.Time         `json:"recordedAt"`
+}
+
+// RecordActivityRequest is an event another service reports for a
+// customer's activity feed
+type RecordActivityRequest struct {
+	EventID    string            `json:"eventId"`
+	CustomerID string            `json:"customerId"`
+	Type       string            `json:"type"`
+	Summary    string            `json:"summary"`
+	Source     string            `json:"source"`
+	Actor      string            `json:"actor,omitempty"`
+	ResourceID string            `json:"resourceId,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	// OccurredAt defaults to when the event is recorded
+	OccurredAt *time.Time `json:"occurredAt,omitempty"`
+}
+
+// ClaimEvent is a claim lifecycle event as claims-service delivers it to
+// its webhook endpoints
+type ClaimEvent struct {
+	ID          int64     `json:"id"`
+	Type        string    `json:"type"`
+	ClaimID     string    `json:"claimId"`
+	ClaimNumber string    `json:"claimNumber,omitempty"`
+	PolicyID    string    `json:"policyId,omitempty"`
+	CustomerID  string    `json:"customerId"`
+	OldStatus   string    `json:"oldStatus,omitempty"`
+	NewStatus   string    `json:"newStatus"`
+	AssignedTo  string    `json:"assignedTo,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	DocumentID  string    `json:"documentId,omitempty"`
+	FileName    string    `json:"fileName,omitempty"`
+	OfferID     string    `json:"offerId,omitempty"`
+	Amount      float64   `json:"amount,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// ActivityQuery selects a page of a customer's activity feed
+type ActivityQuery struct {
+	Categories []string // empty for every category
+	Type       string   // empty for every type
+	Cursor     string   // NextCursor of the previous page, empty for the first
+	Limit      int
+}
+
+// ActivityPage is a page of a customer's activity feed, newest first.
+// NextCursor is empty on the last page.
+type ActivityPage struct {
+	CustomerID string      `json:"customerId"`
+	Activities []*Activity `json:"activities"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
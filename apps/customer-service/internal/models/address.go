@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Location precisions: how closely a normalized address was located
+const (
+	PrecisionAddress = "address" // the building
+	PrecisionStreet  = "street"
+	PrecisionCity    = "city"
+	PrecisionState   = "state"
+	PrecisionCountry = "country"
+)
+
+// NormalizedAddress is a customer's address in standard form, as territory
+// rating and catastrophe matching compare it: components upper-cased with
+// US street suffixes and directionals abbreviated, the state as its USPS
+// code and the country as its ISO 3166-1 alpha-2 code.
+type NormalizedAddress struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postalCode"`
+	Country    string `json:"country"`
+	// Location is nil when the address could be put in standard form but
+	// not located
+	Location *GeoLocation `json:"location,omitempty"`
+	// Provider names the normalizer that produced it, e.g. stub or nominatim
+	Provider     string    `json:"provider"`
+	NormalizedAt time.Time `json:"normalizedAt"`
+}
+
+// GeoLocation is where an address is
+type GeoLocation struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Precision string  `json:"precision"`
+}
+
+// Geocoding run statuses
+const (
+	GeocodeRunning = "running"
+	GeocodeDone    = "done"
+	GeocodeFailed  = "failed" // stopped before every customer was geocoded; see Error
+)
+
+// Geocoding outcomes of one customer
+const (
+	GeocodeNormalized = "normalized"
+	GeocodeUnmatched  = "unmatched" // the normalizer could not find the address
+	GeocodeError      = "error"
+	GeocodeChanged    = "changed" // the address was changed while it was geocoded
+)
+
+// GeocodeOptions are the settings of one geocoding run
+type GeocodeOptions struct {
+	// All re-geocodes every customer; otherwise only customers without a
+	// normalized address are
+	All       bool   `json:"all"`
+	StartedBy string `json:"startedBy,omitempty"`
+}
+
+// GeocodeRun is a batch re-geocoding of customers' addresses and its
+// progress. Runs are made in the background, one at a time.
+type GeocodeRun struct {
+	ID          string           `json:"id"`
+	Status      string           `json:"status"`
+	Provider    string           `json:"provider"`
+	All         bool             `json:"all"`
+	Total       int              `json:"total"`
+	Processed   int              `json:"processed"`
+	Normalized  int              `json:"normalized"`
+	Unmatched   int              `json:"unmatched"`
+	Errors      int              `json:"errors"`
+	Changed     int              `json:"changed"`
+	Failures    []GeocodeOutcome `json:"failures"`
+	Error       string           `json:"error,omitempty"`
+	StartedBy   string           `json:"startedBy,omitempty"`
+	StartedAt   time.Time        `json:"startedAt"`
+	CompletedAt *time.Time       `json:"completedAt,omitempty"`
+}
+
+// GeocodeOutcome is the outcome of geocoding one customer's address
+type GeocodeOutcome struct {
+	CustomerID string `json:"customerId"`
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+}
@@ -23,6 +23,32 @@ type Customer struct {
 	RiskScore   int       `json:"riskScore"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	// Preferences is nil until the customer records their communication
+	// preferences
+	Preferences *Preferences `json:"preferences,omitempty"`
+	// EmailVerified is set once the customer follows a verification link
+	// sent to Email; changing the email clears it
+	EmailVerified   bool       `json:"emailVerified"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+	// HouseholdID names the household the customer belongs to, if any. It
+	// is maintained from the household's member list.
+	HouseholdID string `json:"householdId,omitempty"`
+	// KYCStatus is the outcome of identity document review: verified once
+	// any document is verified, otherwise pending or rejected. It is empty
+	// until the customer submits a document.
+	KYCStatus     string     `json:"kycStatus,omitempty"`
+	KYCVerifiedAt *time.Time `json:"kycVerifiedAt,omitempty"`
+	// NormalizedAddress is Address in standard form with where it is. It
+	// is nil when no normalizer is configured or Address could not be
+	// normalized, until a geocoding run succeeds.
+	NormalizedAddress *NormalizedAddress `json:"normalizedAddress,omitempty"`
+}
+
+// VerificationSent describes a verification email that was sent
+type VerificationSent struct {
+	CustomerID string    `json:"customerId"`
+	Email      string    `json:"email"`
+	ExpiresAt  time.Time `json:"expiresAt"`
 }
 
 // CreateCustomerRequest represents the request body for creating a customer
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Household groups customers who share policies, such as a family. A
+// customer belongs to at most one household, and a household is dissolved
+// when its last member leaves.
+type Household struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	MemberIDs []string  `json:"memberIds"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// HasMember reports whether the customer belongs to the household
+func (h *Household) HasMember(customerID string) bool {
+	for _, id := range h.MemberIDs {
+		if id == customerID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateHouseholdRequest represents the request body for creating a
+// household. CustomerID becomes its first member.
+type CreateHouseholdRequest struct {
+	Name       string `json:"name"`
+	CustomerID string `json:"customerId"`
+}
+
+// JoinHouseholdRequest represents the request body for adding a customer to
+// a household
+type JoinHouseholdRequest struct {
+	CustomerID string `json:"customerId"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// MaxKYCDocumentSize is the largest identity document that can be uploaded
+const MaxKYCDocumentSize = 10 << 20
+
+// KYC document types
+const (
+	KYCDocumentPassport       = "passport"
+	KYCDocumentDriversLicense = "drivers_license"
+	KYCDocumentNationalID     = "national_id"
+	KYCDocumentProofOfAddress = "proof_of_address"
+)
+
+// KYC statuses. Documents are pending until reviewed; a customer's status
+// is derived from their documents, and is empty until they submit one.
+const (
+	KYCPending  = "pending"
+	KYCVerified = "verified"
+	KYCRejected = "rejected"
+)
+
+// ValidateKYCDocumentType checks if a document type is accepted
+func ValidateKYCDocumentType(documentType string) bool {
+	switch documentType {
+	case KYCDocumentPassport, KYCDocumentDriversLicense, KYCDocumentNationalID, KYCDocumentProofOfAddress:
+		return true
+	}
+	return false
+}
+
+// KYCDocument is an identity document a customer uploaded for review. The
+// content is stored separately and served by the download endpoint. Who
+// reviewed it is only shown to staff.
+type KYCDocument struct {
+	ID              string     `json:"id"`
+	CustomerID      string     `json:"customerId"`
+	DocumentType    string     `json:"documentType"`
+	FileName        string     `json:"fileName"`
+	ContentType     string     `json:"contentType"`
+	Size            int64      `json:"size"`
+	Status          string     `json:"status"`
+	UploadedBy      string     `json:"uploadedBy"`
+	UploadedAt      time.Time  `json:"uploadedAt"`
+	ReviewedBy      string     `json:"reviewedBy,omitempty" shape:"roles=admin|adjuster"`
+	ReviewedAt      *time.Time `json:"reviewedAt,omitempty"`
+	RejectionReason string     `json:"rejectionReason,omitempty"`
+}
+
+// ReviewKYCDocumentRequest represents the request body for reviewing a KYC
+// document. Status is verified or rejected; rejections need a reason.
+type ReviewKYCDocumentRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// KYCSummary is a customer's KYC status with the documents it is based on
+type KYCSummary struct {
+	CustomerID string         `json:"customerId"`
+	Status     string         `json:"status"`
+	VerifiedAt *time.Time     `json:"verifiedAt,omitempty"`
+	Documents  []*KYCDocument `json:"documents"`
+}
+
+// KYCStatusOf derives a customer's KYC status from their documents. One
+// verified document is enough; VerifiedAt is when the first was verified.
+func KYCStatusOf(documents []*KYCDocument) (string, *time.Time) {
+	status := ""
+	var verifiedAt *time.Time
+	for _, doc := range documents {
+		switch doc.Status {
+		case KYCVerified:
+			status = KYCVerified
+			if doc.ReviewedAt != nil && (verifiedAt == nil || doc.ReviewedAt.Before(*verifiedAt)) {
+				verifiedAt = doc.ReviewedAt
+			}
+		case KYCPending:
+			if status != KYCVerified {
+				status = KYCPending
+			}
+		case KYCRejected:
+			if status == "" {
+				status = KYCRejected
+			}
+		}
+	}
+	return status, verifiedAt
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Channels through which a customer can give or withdraw consent
+const (
+	ConsentSourceWeb    = "web"
+	ConsentSourceMobile = "mobile"
+	ConsentSourcePhone  = "phone"
+	ConsentSourceAgent  = "agent"
+	ConsentSourcePost   = "post"
+)
+
+// Preferences holds a customer's communication opt-ins and paperless billing
+// consent. Premium payment reminders are service messages rather than
+// marketing, so they are sent unless the customer opts out.
+type Preferences struct {
+	EmailOptIn             bool      `json:"emailOptIn"`
+	SMSOptIn               bool      `json:"smsOptIn"`
+	PostOptIn              bool      `json:"postOptIn"`
+	PaymentRemindersOptOut bool      `json:"paymentRemindersOptOut"`
+	PaperlessBilling       Consent   `json:"paperlessBilling"`
+	UpdatedAt              time.Time `json:"updatedAt"`
+}
+
+// Consent records whether a customer agreed to something, and when and
+// through which channel they last gave or withdrew that agreement.
+// RecordedAt is nil when the customer has never been asked.
+type Consent struct {
+	Granted    bool       `json:"granted"`
+	RecordedAt *time.Time `json:"recordedAt,omitempty"`
+	Source     string     `json:"source,omitempty"`
+}
+
+// UpdatePreferencesRequest represents the request body for updating a
+// customer's preferences. Source is required when paperlessBilling changes
+// the recorded consent.
+type UpdatePreferencesRequest struct {
+	EmailOptIn             bool   `json:"emailOptIn"`
+	SMSOptIn               bool   `json:"smsOptIn"`
+	PostOptIn              bool   `json:"postOptIn"`
+	PaymentRemindersOptOut bool   `json:"paymentRemindersOptOut"`
+	PaperlessBilling       bool   `json:"paperlessBilling"`
+	Source                 string `json:"source,omitempty"`
+}
+
+// ValidateConsentSource checks if a consent source is valid
+func ValidateConsentSource(source string) bool {
+	switch source {
+	case ConsentSourceWeb, ConsentSourceMobile, ConsentSourcePhone, ConsentSourceAgent, ConsentSourcePost:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+)
+
+// MaxActivityPerCustomer is how many entries a customer's activity feed
+// keeps; older ones are dropped as new ones arrive
+const MaxActivityPerCustomer = 500
+
+// insertActivity returns feed with activity inserted in newest-first order
+// and trimmed to MaxActivityPerCustomer. An activity that already happened
+// goes after those that happened at the same time. feed is not changed.
+func insertActivity(feed []*models.Activity, activity *models.Activity) []*models.Activity {
+	at := len(feed)
+	for i, existing := range feed {
+		if existing.OccurredAt.Before(activity.OccurredAt) {
+			at = i
+			break
+		}
+	}
+	updated := make([]*models.Activity, 0, len(feed)+1)
+	updated = append(updated, feed[:at]...)
+	updated = append(updated, activity)
+	updated = append(updated, feed[at:]...)
+	if len(updated) > MaxActivityPerCustomer {
+		updated = updated[:MaxActivityPerCustomer]
+	}
+	return updated
+}
+
+// isDuplicateActivity reports whether feed already holds the event
+// activity was reported from
+func isDuplicateActivity(feed []*models.Activity, activity *models.Activity) bool {
+	if activity.EventID == "" {
+		return false
+	}
+	for _, existing := range feed {
+		if existing.Source == activity.Source && existing.EventID == activity.EventID {
+			return true
+		}
+	}
+	return false
+}
+
+// AddActivity adds an entry to its customer's activity feed. An event
+// already in the feed, by source and event ID, is refused.
+func (r *Repository) AddActivity(activity *models.Activity) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.customers[activity.CustomerID]; !exists {
+		return fmt.Errorf("customer not found")
+	}
+	feed := r.activity[activity.CustomerID]
+	if isDuplicateActivity(feed, activity) {
+		return fmt.Errorf("activity already recorded")
+	}
+
+	copied := *activity
+	feed = insertActivity(feed, &copied)
+	if err := r.journal.Put("activity", activity.CustomerID, feed); err != nil {
+		return err
+	}
+	r.activity[activity.CustomerID] = feed
+	r.changed(hooks.OpCreate, "activity", activity.ID, &copied)
+	return nil
+}
+
+// GetActivity returns copies of a customer's activity feed, newest first
+func (r *Repository) GetActivity(customerID string) []*models.Activity {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	feed := make([]*models.Activity, len(r.activity[customerID]))
+	for i, activity := range r.activity[customerID] {
+		copied := *activity
+		feed[i] = &copied
+	}
+	return feed
+}
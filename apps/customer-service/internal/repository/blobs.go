@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+)
+
+// blobTimeout bounds each read or write of a KYC document's content in the
+// blob store
+const blobTimeout = 30 * time.Second
+
+// blobKYCDocuments keeps the content of KYC documents in a blob store, and
+// their descriptions in the KYCStore it wraps
+type blobKYCDocuments struct {
+	KYCStore
+	blobs blobstore.Store
+}
+
+// WithBlobs returns store with the content of KYC documents uploaded from
+// now on kept in blobs, at kyc/<customerID>/<documentID>. Documents
+// uploaded before are still read from store. The blob store's limits apply
+// to every upload.
+func WithBlobs(store KYCStore, blobs blobstore.Store) KYCStore {
+	return &blobKYCDocuments{KYCStore: store, blobs: blobs}
+}
+
+// kycBlobKey is where a KYC document's content is kept in the blob store
+func kycBlobKey(customerID, documentID string) string {
+	return "kyc/" + customerID + "/" + documentID
+}
+
+// AddKYCDocument writes the content to the blob store, then stores the
+// document without it. A new document the store refuses has its content
+// deleted again; one it already holds keeps its content.
+func (b *blobKYCDocuments) AddKYCDocument(doc *models.KYCDocument, content []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), blobTimeout)
+	defer cancel()
+
+	key := kycBlobKey(doc.CustomerID, doc.ID)
+	if _, _, err := b.KYCStore.GetKYCDocument(doc.CustomerID, doc.ID); err == nil {
+		return fmt.Errorf("KYC document with ID %s already exists", doc.ID)
+	}
+	if err := b.blobs.Put(ctx, key, doc.ContentType, content); err != nil {
+		return err
+	}
+	if err := b.KYCStore.AddKYCDocument(doc, nil); err != nil {
+		b.blobs.Delete(ctx, key)
+		return err
+	}
+	return nil
+}
+
+// GetKYCDocument returns a KYC document with its content from the blob
+// store, or from the wrapped store for documents uploaded before it
+func (b *blobKYCDocuments) GetKYCDocument(customerID, documentID string) (*models.KYCDocument, []byte, error) {
+	doc, stored, err := b.KYCStore.GetKYCDocument(customerID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), blobTimeout)
+	defer cancel()
+	_, content, err := b.blobs.Get(ctx, kycBlobKey(customerID, documentID))
+	if errors.Is(err, blobstore.ErrNotFound) && len(stored) > 0 {
+		return doc, stored, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read content of KYC document %s: %w", documentID, err)
+	}
+	return doc, content, nil
+}
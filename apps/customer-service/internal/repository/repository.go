@@ -1,28 +1,43 @@
 package repository
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
 	"github.com/sirupsen/logrus"
 )
 
-// Repository provides data access for customers
+// Repository provides data access for customers, households, KYC
+// documents and activity feeds. Register hooks with OnCreate, OnUpdate and
+// OnDelete to follow its writes.
 type Repository struct {
-	customers map[string]*models.Customer
-	mu        sync.RWMutex
-	logger    *logrus.Logger
+	hooks.Hooks
+
+	customers    map[string]*models.Customer
+	households   map[string]*models.Household
+	kycDocuments map[string][]*models.KYCDocument // customerID -> documents in upload order
+	kycContents  map[string][]byte                // documentID -> content
+	activity     map[string][]*models.Activity    // customerID -> feed, newest first
+	journal      *persist.Journal                 // nil unless Persist is called
+	pending      []hooks.Change                   // written under mu, announced by unlock
+	mu           sync.RWMutex
+	logger       *logrus.Logger
 }
 
 // NewRepository creates a new repository and loads data from JSON files
 func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error) {
 	repo := &Repository{
-		customers: make(map[string]*models.Customer),
-		logger:    logger,
+		customers:    make(map[string]*models.Customer),
+		households:   make(map[string]*models.Household),
+		kycDocuments: make(map[string][]*models.KYCDocument),
+		kycContents:  make(map[string][]byte),
+		activity:     make(map[string][]*models.Activity),
+		logger:       logger,
 	}
 
 	// Load customers
@@ -34,20 +49,95 @@ func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error)
 		logger.Infof("Loaded %d customers from %s", len(repo.customers), dataPath)
 	}
 
+	// Load households. Customers' household IDs are taken from the member
+	// lists, so households must load after customers.
+	householdsPath := filepath.Join(dataPath, "households.json")
+	if err := repo.loadHouseholds(householdsPath); err != nil {
+		logger.Warnf("Could not load households from %s: %v. Starting with no households.", householdsPath, err)
+	} else {
+		logger.Infof("Loaded %d households from %s", len(repo.households), dataPath)
+	}
+
+	// Load KYC documents. Like households they update the customers they
+	// belong to, so they must load after customers.
+	kycPath := filepath.Join(dataPath, "kyc-documents.json")
+	if err := repo.loadKYCDocuments(kycPath); err != nil {
+		logger.Warnf("Could not load KYC documents from %s: %v. Starting with no KYC documents.", kycPath, err)
+	} else {
+		logger.Infof("Loaded KYC documents for %d customers from %s", len(repo.kycDocuments), dataPath)
+	}
+
 	return repo, nil
 }
 
-// loadCustomers loads customers from a JSON file
-func (r *Repository) loadCustomers(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+// Persist replaces the seed data with the state recovered from journal and
+// records every later change in it. Call it before the repository is used.
+func (r *Repository) Persist(journal *persist.Journal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := persist.Restore(journal, "customers", func(id string, customer *models.Customer) {
+		r.customers[id] = customer
+	}, func(id string) {
+		delete(r.customers, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "households", func(id string, household *models.Household) {
+		r.households[id] = household
+	}, func(id string) {
+		delete(r.households, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "kycDocuments", func(customerID string, documents []*models.KYCDocument) {
+		r.kycDocuments[customerID] = documents
+	}, func(customerID string) {
+		delete(r.kycDocuments, customerID)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "kycContents", func(documentID string, content []byte) {
+		r.kycContents[documentID] = content
+	}, func(documentID string) {
+		delete(r.kycContents, documentID)
+	}); err != nil {
 		return err
 	}
+	if err := persist.Restore(journal, "activity", func(customerID string, feed []*models.Activity) {
+		r.activity[customerID] = feed
+	}, func(customerID string) {
+		delete(r.activity, customerID)
+	}); err != nil {
+		return err
+	}
+
+	r.journal = journal
+	return nil
+}
+
+// changed records a write made under the lock, for unlock to announce
+func (r *Repository) changed(op, collection, key string, value interface{}) {
+	r.pending = append(r.pending, hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+}
+
+// unlock releases the write lock and then runs the hooks of the writes
+// made under it, so hooks can use the repository
+func (r *Repository) unlock() {
+	changes := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	r.Notify(changes...)
+}
 
+// loadCustomers loads customers from a JSON file
+func (r *Repository) loadCustomers(filePath string) error {
 	var customers []*models.Customer
-	if err := json.Unmarshal(data, &customers); err != nil {
+	result, err := migrate.Load(filePath, customerMigrations, &customers)
+	if err != nil {
 		return err
 	}
+	logMigration(r.logger, filePath, result)
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -59,6 +149,64 @@ func (r *Repository) loadCustomers(filePath string) error {
 	return nil
 }
 
+// loadHouseholds loads households from a JSON file and links their members
+func (r *Repository) loadHouseholds(filePath string) error {
+	var households []*models.Household
+	result, err := migrate.Load(filePath, householdMigrations, &households)
+	if err != nil {
+		return err
+	}
+	logMigration(r.logger, filePath, result)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, household := range households {
+		members := make([]string, 0, len(household.MemberIDs))
+		for _, customerID := range household.MemberIDs {
+			customer, exists := r.customers[customerID]
+			if !exists || customer.HouseholdID != "" {
+				r.logger.Warnf("Skipping household %s member %s: unknown customer or already in a household", household.ID, customerID)
+				continue
+			}
+			customer.HouseholdID = household.ID
+			members = append(members, customerID)
+		}
+		household.MemberIDs = members
+		r.households[household.ID] = household
+	}
+
+	return nil
+}
+
+// loadKYCDocuments loads KYC document records from a JSON file and sets
+// their customers' KYC status. Seeded documents have no content.
+func (r *Repository) loadKYCDocuments(filePath string) error {
+	var documents []*models.KYCDocument
+	result, err := migrate.Load(filePath, kycDocumentMigrations, &documents)
+	if err != nil {
+		return err
+	}
+	logMigration(r.logger, filePath, result)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, doc := range documents {
+		if _, exists := r.customers[doc.CustomerID]; !exists {
+			r.logger.Warnf("Skipping KYC document %s: unknown customer %s", doc.ID, doc.CustomerID)
+			continue
+		}
+		r.kycDocuments[doc.CustomerID] = append(r.kycDocuments[doc.CustomerID], doc)
+	}
+	for customerID, docs := range r.kycDocuments {
+		customer := r.customers[customerID]
+		customer.KYCStatus, customer.KYCVerifiedAt = models.KYCStatusOf(docs)
+	}
+
+	return nil
+}
+
 // GetAllCustomers returns all customers
 func (r *Repository) GetAllCustomers() ([]*models.Customer, error) {
 	r.mu.RLock()
@@ -102,43 +250,236 @@ func (r *Repository) GetCustomerByEmail(email string) (*models.Customer, error)
 // CreateCustomer creates a new customer
 func (r *Repository) CreateCustomer(customer *models.Customer) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
 	// Check if customer with same ID already exists
 	if _, exists := r.customers[customer.ID]; exists {
 		return fmt.Errorf("customer with ID %s already exists", customer.ID)
 	}
 
+	if err := r.journal.Put("customers", customer.ID, customer); err != nil {
+		return err
+	}
 	r.customers[customer.ID] = customer
+	r.changed(hooks.OpCreate, "customers", customer.ID, customer)
 	return nil
 }
 
 // UpdateCustomer updates an existing customer
 func (r *Repository) UpdateCustomer(customer *models.Customer) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
 	// Check if customer exists
 	if _, exists := r.customers[customer.ID]; !exists {
 		return fmt.Errorf("customer not found")
 	}
 
+	if err := r.journal.Put("customers", customer.ID, customer); err != nil {
+		return err
+	}
 	r.customers[customer.ID] = customer
+	r.changed(hooks.OpUpdate, "customers", customer.ID, customer)
 	return nil
 }
 
 // DeactivateCustomer deactivates a customer (soft delete)
 func (r *Repository) DeactivateCustomer(customerID string) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
 	// Check if customer exists
-	if _, exists := r.customers[customerID]; !exists {
+	customer, exists := r.customers[customerID]
+	if !exists {
 		return fmt.Errorf("customer not found")
 	}
 
+	// A removed customer no longer shares its household's policies
+	if household, exists := r.households[customer.HouseholdID]; exists {
+		members := []string{}
+		for _, id := range household.MemberIDs {
+			if id != customerID {
+				members = append(members, id)
+			}
+		}
+		if len(members) == 0 {
+			if err := r.journal.Delete("households", household.ID); err != nil {
+				return err
+			}
+			delete(r.households, household.ID)
+			r.changed(hooks.OpDelete, "households", household.ID, nil)
+		} else {
+			updated := copyHousehold(household)
+			updated.MemberIDs = members
+			if err := r.journal.Put("households", household.ID, updated); err != nil {
+				return err
+			}
+			r.households[household.ID] = updated
+			r.changed(hooks.OpUpdate, "households", household.ID, updated)
+		}
+	}
+
 	// In a real implementation, we would set a status field or deletion timestamp
 	// For now, we'll just remove it from the map (hard delete for simplicity)
+	if err := r.journal.Delete("customers", customerID); err != nil {
+		return err
+	}
+	if _, exists := r.activity[customerID]; exists {
+		if err := r.journal.Delete("activity", customerID); err != nil {
+			return err
+		}
+		delete(r.activity, customerID)
+	}
 	delete(r.customers, customerID)
+	r.changed(hooks.OpDelete, "customers", customerID, nil)
+	return nil
+}
+
+// GetHouseholdByID returns a copy of a household
+func (r *Repository) GetHouseholdByID(householdID string) (*models.Household, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	household, exists := r.households[householdID]
+	if !exists {
+		return nil, fmt.Errorf("household not found")
+	}
+
+	return copyHousehold(household), nil
+}
+
+// CreateHousehold stores a new household
+func (r *Repository) CreateHousehold(household *models.Household) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.households[household.ID]; exists {
+		return fmt.Errorf("household with ID %s already exists", household.ID)
+	}
+
+	if err := r.journal.Put("households", household.ID, household); err != nil {
+		return err
+	}
+	r.households[household.ID] = copyHousehold(household)
+	r.changed(hooks.OpCreate, "households", household.ID, household)
+	return nil
+}
+
+// UpdateHousehold replaces a stored household
+func (r *Repository) UpdateHousehold(household *models.Household) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.households[household.ID]; !exists {
+		return fmt.Errorf("household not found")
+	}
+
+	if err := r.journal.Put("households", household.ID, household); err != nil {
+		return err
+	}
+	r.households[household.ID] = copyHousehold(household)
+	r.changed(hooks.OpUpdate, "households", household.ID, household)
+	return nil
+}
+
+// DeleteHousehold removes a household
+func (r *Repository) DeleteHousehold(householdID string) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.households[householdID]; !exists {
+		return fmt.Errorf("household not found")
+	}
+
+	if err := r.journal.Delete("households", householdID); err != nil {
+		return err
+	}
+	delete(r.households, householdID)
+	r.changed(hooks.OpDelete, "households", householdID, nil)
 	return nil
 }
+
+// copyHousehold copies a household so callers cannot change the stored
+// member list
+func copyHousehold(household *models.Household) *models.Household {
+	copied := *household
+	copied.MemberIDs = append([]string{}, household.MemberIDs...)
+	return &copied
+}
+
+// AddKYCDocument stores a KYC document and its content
+func (r *Repository) AddKYCDocument(doc *models.KYCDocument, content []byte) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.customers[doc.CustomerID]; !exists {
+		return fmt.Errorf("customer not found")
+	}
+	if _, exists := r.kycContents[doc.ID]; exists {
+		return fmt.Errorf("KYC document with ID %s already exists", doc.ID)
+	}
+
+	copied := *doc
+	documents := append(append([]*models.KYCDocument{}, r.kycDocuments[doc.CustomerID]...), &copied)
+	if err := r.journal.Put("kycContents", doc.ID, content); err != nil {
+		return err
+	}
+	if err := r.journal.Put("kycDocuments", doc.CustomerID, documents); err != nil {
+		return err
+	}
+	r.kycDocuments[doc.CustomerID] = documents
+	r.kycContents[doc.ID] = content
+	r.changed(hooks.OpCreate, "kycDocuments", doc.ID, doc)
+	return nil
+}
+
+// GetKYCDocuments returns copies of a customer's KYC documents in upload
+// order
+func (r *Repository) GetKYCDocuments(customerID string) []*models.KYCDocument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	documents := make([]*models.KYCDocument, 0, len(r.kycDocuments[customerID]))
+	for _, doc := range r.kycDocuments[customerID] {
+		copied := *doc
+		documents = append(documents, &copied)
+	}
+	return documents
+}
+
+// GetKYCDocument returns a copy of a customer's KYC document and its content
+func (r *Repository) GetKYCDocument(customerID, documentID string) (*models.KYCDocument, []byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, doc := range r.kycDocuments[customerID] {
+		if doc.ID == documentID {
+			copied := *doc
+			return &copied, r.kycContents[doc.ID], nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("KYC document not found")
+}
+
+// UpdateKYCDocument replaces a stored KYC document, keeping its content
+func (r *Repository) UpdateKYCDocument(doc *models.KYCDocument) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	for i, existing := range r.kycDocuments[doc.CustomerID] {
+		if existing.ID == doc.ID {
+			copied := *doc
+			documents := append([]*models.KYCDocument{}, r.kycDocuments[doc.CustomerID]...)
+			documents[i] = &copied
+			if err := r.journal.Put("kycDocuments", doc.CustomerID, documents); err != nil {
+				return err
+			}
+			r.kycDocuments[doc.CustomerID] = documents
+			r.changed(hooks.OpUpdate, "kycDocuments", doc.ID, doc)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("KYC document not found")
+}
@@ -0,0 +1,310 @@
+// Package repositorytest provides an in-memory CustomerStore,
+// HouseholdStore, KYCStore and ActivityStore for unit tests
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+)
+
+// FakeStore is an in-memory CustomerStore. Set Err to make every call fail,
+// e.g. to exercise a handler's error path.
+type FakeStore struct {
+	Err error
+
+	mu           sync.Mutex
+	customers    map[string]*models.Customer
+	households   map[string]*models.Household
+	kycDocuments map[string][]*models.KYCDocument
+	kycContents  map[string][]byte
+	activity     map[string][]*models.Activity
+}
+
+var (
+	_ repository.CustomerStore  = (*FakeStore)(nil)
+	_ repository.HouseholdStore = (*FakeStore)(nil)
+	_ repository.KYCStore       = (*FakeStore)(nil)
+	_ repository.ActivityStore  = (*FakeStore)(nil)
+)
+
+// NewFakeStore creates a fake holding the given customers
+func NewFakeStore(customers ...*models.Customer) *FakeStore {
+	f := &FakeStore{
+		customers:    make(map[string]*models.Customer),
+		households:   make(map[string]*models.Household),
+		kycDocuments: make(map[string][]*models.KYCDocument),
+		kycContents:  make(map[string][]byte),
+		activity:     make(map[string][]*models.Activity),
+	}
+	for _, customer := range customers {
+		f.customers[customer.ID] = customer
+	}
+	return f
+}
+
+// GetAllCustomers returns every customer ordered by ID
+func (f *FakeStore) GetAllCustomers() ([]*models.Customer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	customers := make([]*models.Customer, 0, len(f.customers))
+	for _, customer := range f.customers {
+		customers = append(customers, customer)
+	}
+	sort.Slice(customers, func(i, j int) bool { return customers[i].ID < customers[j].ID })
+	return customers, nil
+}
+
+// GetCustomerByID returns the stored customer
+func (f *FakeStore) GetCustomerByID(customerID string) (*models.Customer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	customer, exists := f.customers[customerID]
+	if !exists {
+		return nil, fmt.Errorf("customer not found")
+	}
+	return customer, nil
+}
+
+// GetCustomerByEmail returns the customer with the given email address
+func (f *FakeStore) GetCustomerByEmail(email string) (*models.Customer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	for _, customer := range f.customers {
+		if customer.Email == email {
+			return customer, nil
+		}
+	}
+	return nil, fmt.Errorf("customer not found")
+}
+
+// CreateCustomer stores a new customer
+func (f *FakeStore) CreateCustomer(customer *models.Customer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.customers[customer.ID]; exists {
+		return fmt.Errorf("customer with ID %s already exists", customer.ID)
+	}
+	f.customers[customer.ID] = customer
+	return nil
+}
+
+// UpdateCustomer replaces a stored customer
+func (f *FakeStore) UpdateCustomer(customer *models.Customer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.customers[customer.ID]; !exists {
+		return fmt.Errorf("customer not found")
+	}
+	f.customers[customer.ID] = customer
+	return nil
+}
+
+// DeactivateCustomer removes a stored customer
+func (f *FakeStore) DeactivateCustomer(customerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.customers[customerID]; !exists {
+		return fmt.Errorf("customer not found")
+	}
+	delete(f.customers, customerID)
+	delete(f.activity, customerID)
+	return nil
+}
+
+// GetHouseholdByID returns a copy of the stored household
+func (f *FakeStore) GetHouseholdByID(householdID string) (*models.Household, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	household, exists := f.households[householdID]
+	if !exists {
+		return nil, fmt.Errorf("household not found")
+	}
+	copied := *household
+	copied.MemberIDs = append([]string{}, household.MemberIDs...)
+	return &copied, nil
+}
+
+// CreateHousehold stores a new household
+func (f *FakeStore) CreateHousehold(household *models.Household) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.households[household.ID]; exists {
+		return fmt.Errorf("household with ID %s already exists", household.ID)
+	}
+	copied := *household
+	copied.MemberIDs = append([]string{}, household.MemberIDs...)
+	f.households[household.ID] = &copied
+	return nil
+}
+
+// UpdateHousehold replaces a stored household
+func (f *FakeStore) UpdateHousehold(household *models.Household) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.households[household.ID]; !exists {
+		return fmt.Errorf("household not found")
+	}
+	copied := *household
+	copied.MemberIDs = append([]string{}, household.MemberIDs...)
+	f.households[household.ID] = &copied
+	return nil
+}
+
+// DeleteHousehold removes a stored household
+func (f *FakeStore) DeleteHousehold(householdID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.households[householdID]; !exists {
+		return fmt.Errorf("household not found")
+	}
+	delete(f.households, householdID)
+	return nil
+}
+
+// AddKYCDocument stores a KYC document and its content
+func (f *FakeStore) AddKYCDocument(doc *models.KYCDocument, content []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.customers[doc.CustomerID]; !exists {
+		return fmt.Errorf("customer not found")
+	}
+	copied := *doc
+	f.kycDocuments[doc.CustomerID] = append(f.kycDocuments[doc.CustomerID], &copied)
+	f.kycContents[doc.ID] = content
+	return nil
+}
+
+// GetKYCDocuments returns copies of a customer's KYC documents in upload
+// order
+func (f *FakeStore) GetKYCDocuments(customerID string) []*models.KYCDocument {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	documents := make([]*models.KYCDocument, 0, len(f.kycDocuments[customerID]))
+	for _, doc := range f.kycDocuments[customerID] {
+		copied := *doc
+		documents = append(documents, &copied)
+	}
+	return documents
+}
+
+// GetKYCDocument returns a copy of a customer's KYC document and its content
+func (f *FakeStore) GetKYCDocument(customerID, documentID string) (*models.KYCDocument, []byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, nil, f.Err
+	}
+	for _, doc := range f.kycDocuments[customerID] {
+		if doc.ID == documentID {
+			copied := *doc
+			return &copied, f.kycContents[doc.ID], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("KYC document not found")
+}
+
+// UpdateKYCDocument replaces a stored KYC document
+func (f *FakeStore) UpdateKYCDocument(doc *models.KYCDocument) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	for i, existing := range f.kycDocuments[doc.CustomerID] {
+		if existing.ID == doc.ID {
+			copied := *doc
+			f.kycDocuments[doc.CustomerID][i] = &copied
+			return nil
+		}
+	}
+	return fmt.Errorf("KYC document not found")
+}
+
+// AddActivity adds an entry to its customer's activity feed, refusing an
+// event already in it
+func (f *FakeStore) AddActivity(activity *models.Activity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.customers[activity.CustomerID]; !exists {
+		return fmt.Errorf("customer not found")
+	}
+	feed := f.activity[activity.CustomerID]
+	for _, existing := range feed {
+		if activity.EventID != "" && existing.Source == activity.Source && existing.EventID == activity.EventID {
+			return fmt.Errorf("activity already recorded")
+		}
+	}
+	copied := *activity
+	feed = append(feed, &copied)
+	sort.SliceStable(feed, func(i, j int) bool { return feed[i].OccurredAt.After(feed[j].OccurredAt) })
+	f.activity[activity.CustomerID] = feed
+	return nil
+}
+
+// GetActivity returns copies of a customer's activity feed, newest first
+func (f *FakeStore) GetActivity(customerID string) []*models.Activity {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	feed := make([]*models.Activity, len(f.activity[customerID]))
+	for i, activity := range f.activity[customerID] {
+		copied := *activity
+		feed[i] = &copied
+	}
+	return feed
+}
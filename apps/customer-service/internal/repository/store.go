@@ -0,0 +1,57 @@
+package repository
+
+import "github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+
+// CustomerStore is the data access the customer service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides an
+// in-memory fake for unit tests.
+type CustomerStore interface {
+	GetAllCustomers() ([]*models.Customer, error)
+	GetCustomerByID(customerID string) (*models.Customer, error)
+	GetCustomerByEmail(email string) (*models.Customer, error)
+	CreateCustomer(customer *models.Customer) error
+	UpdateCustomer(customer *models.Customer) error
+	DeactivateCustomer(customerID string) error
+}
+
+var _ CustomerStore = (*Repository)(nil)
+
+// HouseholdStore is the data access the household service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides an
+// in-memory fake for unit tests.
+type HouseholdStore interface {
+	GetCustomerByID(customerID string) (*models.Customer, error)
+	UpdateCustomer(customer *models.Customer) error
+	GetHouseholdByID(householdID string) (*models.Household, error)
+	CreateHousehold(household *models.Household) error
+	UpdateHousehold(household *models.Household) error
+	DeleteHousehold(householdID string) error
+}
+
+var _ HouseholdStore = (*Repository)(nil)
+
+// KYCStore is the data access the KYC service depends on. Repository is the
+// JSON-backed implementation; repositorytest provides an in-memory fake for
+// unit tests.
+type KYCStore interface {
+	GetCustomerByID(customerID string) (*models.Customer, error)
+	UpdateCustomer(customer *models.Customer) error
+	AddKYCDocument(doc *models.KYCDocument, content []byte) error
+	GetKYCDocuments(customerID string) []*models.KYCDocument
+	GetKYCDocument(customerID, documentID string) (*models.KYCDocument, []byte, error)
+	UpdateKYCDocument(doc *models.KYCDocument) error
+}
+
+var _ KYCStore = (*Repository)(nil)
+
+// ActivityStore is the data access the activity service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides an
+// in-memory fake for unit tests.
+type ActivityStore interface {
+	GetCustomerByID(customerID string) (*models.Customer, error)
+	GetAllCustomers() ([]*models.Customer, error)
+	AddActivity(activity *models.Activity) error
+	GetActivity(customerID string) []*models.Activity
+}
+
+var _ ActivityStore = (*Repository)(nil)
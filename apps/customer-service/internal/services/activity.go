@@ -0,0 +1,433 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/sirupsen/logrus"
+)
+
+// Activity feed page sizes
+const (
+	DefaultActivityLimit = 50
+	MaxActivityLimit     = 200
+)
+
+// Activity sources
+const (
+	SourceCustomerService = "customer-service"
+	SourceClaimsService   = "claims-service"
+)
+
+// ChangeFollower announces a repository's writes; *repository.Repository
+// is the production implementation
+type ChangeFollower interface {
+	OnCreate(collection string, fn hooks.Func)
+	OnUpdate(collection string, fn hooks.Func)
+	OnDelete(collection string, fn hooks.Func)
+}
+
+// ActivityService keeps each customer's activity feed for the support
+// console. Changes made in this service are recorded as the repository
+// announces them; claims-service reports claim events through its
+// webhooks, and other services report theirs with Record.
+type ActivityService struct {
+	repo   repository.ActivityStore
+	logger *logrus.Logger
+
+	mu   sync.Mutex
+	seen map[string]customerState // customerID -> state last recorded
+}
+
+// customerState is what the feed compares to tell what an update changed
+type customerState struct {
+	firstName, lastName, phone, dateOfBirth string
+	email                                   string
+	emailVerified                           bool
+	address                                 models.Address
+	householdID                             string
+	preferencesUpdatedAt                    time.Time
+}
+
+// stateOf returns the state of customer the feed compares
+func stateOf(customer *models.Customer) customerState {
+	state := customerState{
+		firstName:     customer.FirstName,
+		lastName:      customer.LastName,
+		phone:         customer.Phone,
+		dateOfBirth:   customer.DateOfBirth,
+		email:         customer.Email,
+		emailVerified: customer.EmailVerified,
+		address:       customer.Address,
+		householdID:   customer.HouseholdID,
+	}
+	if customer.Preferences != nil {
+		state.preferencesUpdatedAt = customer.Preferences.UpdatedAt
+	}
+	return state
+}
+
+// NewActivityService creates a new activity service
+func NewActivityService(repo repository.ActivityStore, logger *logrus.Logger) *ActivityService {
+	return &ActivityService{
+		repo:   repo,
+		logger: logger,
+		seen:   make(map[string]customerState),
+	}
+}
+
+// Follow records the changes to customers and their KYC documents that
+// repo announces. Call it once the repository has loaded, before it is
+// written to.
+func (s *ActivityService) Follow(repo ChangeFollower) error {
+	customers, err := s.repo.GetAllCustomers()
+	if err != nil {
+		return fmt.Errorf("failed to list customers: %w", err)
+	}
+	s.mu.Lock()
+	for _, customer := range customers {
+		s.seen[customer.ID] = stateOf(customer)
+	}
+	s.mu.Unlock()
+
+	repo.OnCreate("customers", s.customerChanged)
+	repo.OnUpdate("customers", s.customerChanged)
+	repo.OnDelete("customers", s.customerRemoved)
+	repo.OnCreate("kycDocuments", s.kycDocumentChanged)
+	repo.OnUpdate("kycDocuments", s.kycDocumentChanged)
+	return nil
+}
+
+// customerChanged records what a write to a customer changed
+func (s *ActivityService) customerChanged(change hooks.Change) {
+	customer := change.Value.(*models.Customer)
+	state := stateOf(customer)
+
+	s.mu.Lock()
+	previous, known := s.seen[customer.ID]
+	s.seen[customer.ID] = state
+	s.mu.Unlock()
+
+	if change.Op == hooks.OpCreate || !known {
+		s.recordOwn(customer.ID, "customer.created", "Account created", "", nil)
+		return
+	}
+
+	var profile []string
+	for _, field := range []struct {
+		name          string
+		before, after string
+	}{
+		{"firstName", previous.firstName, state.firstName},
+		{"lastName", previous.lastName, state.lastName},
+		{"phone", previous.phone, state.phone},
+		{"dateOfBirth", previous.dateOfBirth, state.dateOfBirth},
+	} {
+		if field.before != field.after {
+			profile = append(profile, field.name)
+		}
+	}
+	if len(profile) > 0 {
+		s.recordOwn(customer.ID, "customer.profile_updated", "Profile updated", "", map[string]string{"fields": strings.Join(profile, ",")})
+	}
+	if previous.email != state.email {
+		s.recordOwn(customer.ID, "customer.email_changed", "Email address changed", "", nil)
+	}
+	if !previous.emailVerified && state.emailVerified {
+		s.recordOwn(customer.ID, "customer.email_verified", "Email address verified", "", nil)
+	}
+	if previous.address != state.address {
+		s.recordOwn(customer.ID, "customer.address_changed", "Address changed", "", nil)
+	}
+	if previous.householdID != state.householdID {
+		if previous.householdID != "" {
+			s.recordOwn(customer.ID, "household.left", "Left household "+previous.householdID, previous.householdID, nil)
+		}
+		if state.householdID != "" {
+			s.recordOwn(customer.ID, "household.joined", "Joined household "+state.householdID, state.householdID, nil)
+		}
+	}
+	if !previous.preferencesUpdatedAt.Equal(state.preferencesUpdatedAt) {
+		s.recordOwn(customer.ID, "preferences.updated", "Communication preferences updated", "", nil)
+	}
+}
+
+// customerRemoved forgets a removed customer, whose feed goes with them
+func (s *ActivityService) customerRemoved(change hooks.Change) {
+	s.mu.Lock()
+	delete(s.seen, change.Key)
+	s.mu.Unlock()
+}
+
+// kycDocumentChanged records an identity document being uploaded or
+// reviewed
+func (s *ActivityService) kycDocumentChanged(change hooks.Change) {
+	doc := change.Value.(*models.KYCDocument)
+	details := map[string]string{"documentType": doc.DocumentType}
+
+	if change.Op == hooks.OpCreate {
+		s.record(&models.Activity{
+			CustomerID: doc.CustomerID,
+			Type:       "kyc.document_uploaded",
+			Summary:    fmt.Sprintf("Identity document %s uploaded for review", doc.FileName),
+			Source:     SourceCustomerService,
+			Actor:      doc.UploadedBy,
+			ResourceID: doc.ID,
+			Details:    details,
+			OccurredAt: doc.UploadedAt,
+		})
+		return
+	}
+	if doc.ReviewedAt == nil {
+		return
+	}
+	if doc.RejectionReason != "" {
+		details["reason"] = doc.RejectionReason
+	}
+	s.record(&models.Activity{
+		CustomerID: doc.CustomerID,
+		Type:       "kyc.document_" + doc.Status,
+		Summary:    fmt.Sprintf("Identity document %s %s", doc.FileName, doc.Status),
+		Source:     SourceCustomerService,
+		Actor:      doc.ReviewedBy,
+		ResourceID: doc.ID,
+		Details:    details,
+		OccurredAt: *doc.ReviewedAt,
+	})
+}
+
+// recordOwn records a change made in this service now
+func (s *ActivityService) recordOwn(customerID, activityType, summary, resourceID string, details map[string]string) {
+	s.record(&models.Activity{
+		CustomerID: customerID,
+		Type:       activityType,
+		Summary:    summary,
+		Source:     SourceCustomerService,
+		ResourceID: resourceID,
+		Details:    details,
+		OccurredAt: time.Now(),
+	})
+}
+
+// record stores a change made in this service. A feed that cannot be
+// written to is logged rather than failing the change.
+func (s *ActivityService) record(activity *models.Activity) {
+	if _, err := s.add(activity); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"customerId": activity.CustomerID,
+			"type":       activity.Type,
+		}).Warn("Failed to record customer activity")
+	}
+}
+
+// add completes activity and stores it in its customer's feed
+func (s *ActivityService) add(activity *models.Activity) (*models.Activity, error) {
+	activity.Category = models.ActivityCategoryOf(activity.Type)
+	activity.ID = fmt.Sprintf("act-%d", time.Now().UnixNano())
+	activity.RecordedAt = time.Now()
+	if activity.OccurredAt.IsZero() {
+		activity.OccurredAt = activity.RecordedAt
+	}
+	if err := s.repo.AddActivity(activity); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// Record adds an event another service reports to a customer's feed. An
+// event already recorded, by source and event ID, is refused with
+// "activity already recorded".
+func (s *ActivityService) Record(req models.RecordActivityRequest) (*models.Activity, error) {
+	switch {
+	case req.CustomerID == "":
+		return nil, fmt.Errorf("customerId is required")
+	case req.Source == "":
+		return nil, fmt.Errorf("source is required")
+	case req.Summary == "":
+		return nil, fmt.Errorf("summary is required")
+	case models.ActivityCategoryOf(req.Type) == "":
+		return nil, fmt.Errorf("unknown activity type %q", req.Type)
+	}
+
+	activity := &models.Activity{
+		CustomerID: req.CustomerID,
+		Type:       req.Type,
+		Summary:    req.Summary,
+		Source:     req.Source,
+		EventID:    req.EventID,
+		Actor:      req.Actor,
+		ResourceID: req.ResourceID,
+		Details:    req.Details,
+	}
+	if req.OccurredAt != nil {
+		activity.OccurredAt = *req.OccurredAt
+	}
+	activity, err := s.add(activity)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId": activity.CustomerID,
+		"type":       activity.Type,
+		"source":     activity.Source,
+	}).Debug("Customer activity recorded")
+	return activity, nil
+}
+
+// RecordClaimEvent adds a claim event delivered by claims-service's
+// webhooks to the feed of the claim's customer
+func (s *ActivityService) RecordClaimEvent(evt models.ClaimEvent) (*models.Activity, error) {
+	if !strings.HasPrefix(evt.Type, "claim.") {
+		return nil, fmt.Errorf("unknown activity type %q", evt.Type)
+	}
+	claim := evt.ClaimNumber
+	if claim == "" {
+		claim = evt.ClaimID
+	}
+
+	var summary string
+	switch evt.Type {
+	case "claim.created":
+		summary = fmt.Sprintf("Claim %s filed", claim)
+	case "claim.status_changed":
+		summary = fmt.Sprintf("Claim %s moved from %s to %s", claim, evt.OldStatus, evt.NewStatus)
+	case "claim.assigned":
+		summary = fmt.Sprintf("Claim %s assigned to %s", claim, evt.AssignedTo)
+	case "claim.escalated":
+		summary = fmt.Sprintf("Claim %s escalated", claim)
+	case "claim.document_uploaded":
+		summary = fmt.Sprintf("Document %s added to claim %s", evt.FileName, claim)
+	case "claim.offer_made":
+		summary = fmt.Sprintf("Settlement offer of %.2f made on claim %s", evt.Amount, claim)
+	case "claim.offer_accepted":
+		summary = fmt.Sprintf("Settlement offer on claim %s accepted", claim)
+	case "claim.offer_rejected":
+		summary = fmt.Sprintf("Settlement offer on claim %s rejected", claim)
+	default:
+		summary = fmt.Sprintf("Claim %s: %s", claim, strings.TrimPrefix(evt.Type, "claim."))
+	}
+
+	details := map[string]string{}
+	for key, value := range map[string]string{
+		"policyId":   evt.PolicyID,
+		"oldStatus":  evt.OldStatus,
+		"newStatus":  evt.NewStatus,
+		"reason":     evt.Reason,
+		"documentId": evt.DocumentID,
+		"offerId":    evt.OfferID,
+	} {
+		if value != "" {
+			details[key] = value
+		}
+	}
+	if evt.Amount != 0 {
+		details["amount"] = strconv.FormatFloat(evt.Amount, 'f', 2, 64)
+	}
+
+	// Event IDs restart with claims-service, so the time tells a redelivery
+	// from a later event with the same ID
+	occurredAt := evt.Timestamp
+	return s.Record(models.RecordActivityRequest{
+		EventID:    fmt.Sprintf("%d@%s", evt.ID, evt.Timestamp.UTC().Format(time.RFC3339Nano)),
+		CustomerID: evt.CustomerID,
+		Type:       evt.Type,
+		Summary:    summary,
+		Source:     SourceClaimsService,
+		Actor:      evt.AssignedTo,
+		ResourceID: evt.ClaimID,
+		Details:    details,
+		OccurredAt: &occurredAt,
+	})
+}
+
+// Feed returns a page of a customer's activity, newest first
+func (s *ActivityService) Feed(customerID string, query models.ActivityQuery) (*models.ActivityPage, error) {
+	if query.Limit == 0 {
+		query.Limit = DefaultActivityLimit
+	}
+	if query.Limit < 1 || query.Limit > MaxActivityLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxActivityLimit)
+	}
+	categories := make(map[string]bool)
+	for _, category := range query.Categories {
+		if !contains(models.ActivityCategories, category) {
+			return nil, fmt.Errorf("unknown category %q", category)
+		}
+		categories[category] = true
+	}
+	if _, err := s.repo.GetCustomerByID(customerID); err != nil {
+		return nil, err
+	}
+
+	feed := s.repo.GetActivity(customerID)
+	start := 0
+	if query.Cursor != "" {
+		var err error
+		if start, err = resume(feed, query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	page := &models.ActivityPage{CustomerID: customerID, Activities: []*models.Activity{}}
+	for _, activity := range feed[start:] {
+		if len(categories) > 0 && !categories[activity.Category] {
+			continue
+		}
+		if query.Type != "" && activity.Type != query.Type {
+			continue
+		}
+		if len(page.Activities) == query.Limit {
+			last := page.Activities[len(page.Activities)-1]
+			page.NextCursor = cursorOf(last)
+			break
+		}
+		page.Activities = append(page.Activities, activity)
+	}
+	return page, nil
+}
+
+// cursorOf returns the cursor of the page after activity: when it
+// happened and its ID
+func cursorOf(activity *models.Activity) string {
+	return strconv.FormatInt(activity.OccurredAt.UnixNano(), 10) + "-" + activity.ID
+}
+
+// resume returns where the page after cursor starts in feed. A cursor
+// whose activity has since been dropped from the feed resumes at the first
+// activity older than it.
+func resume(feed []*models.Activity, cursor string) (int, error) {
+	nanos, id, ok := strings.Cut(cursor, "-")
+	at, err := strconv.ParseInt(nanos, 10, 64)
+	if !ok || err != nil || id == "" {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	for i, activity := range feed {
+		if activity.ID == id {
+			return i + 1, nil
+		}
+	}
+	occurredAt := time.Unix(0, at)
+	for i, activity := range feed {
+		if activity.OccurredAt.Before(occurredAt) {
+			return i, nil
+		}
+	}
+	return len(feed), nil
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// activityTypes returns the types of a page's activities, newest first
+func activityTypes(page *models.ActivityPage) []string {
+	types := []string{}
+	for _, activity := range page.Activities {
+		types = append(types, activity.Type)
+	}
+	return types
+}
+
+func TestActivityFeedRecordsCustomerChanges(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := repository.NewRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	activity := NewActivityService(repo, logger)
+	if err := activity.Follow(repo); err != nil {
+		t.Fatalf("Follow failed: %v", err)
+	}
+	customers := NewCustomerService(repo, nil, nil, logger)
+	kyc := NewKYCService(repo, logger)
+
+	customer, err := customers.CreateCustomer(models.CreateCustomerRequest{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("CreateCustomer failed: %v", err)
+	}
+	if _, err := customers.UpdateCustomer(customer.ID, models.UpdateCustomerRequest{FirstName: "Augusta", LastName: "Lovelace", Email: "augusta@example.com", Address: models.Address{Street: "12 Main St"}}); err != nil {
+		t.Fatalf("UpdateCustomer failed: %v", err)
+	}
+	doc, err := kyc.UploadDocument(customer.ID, customer.ID, models.KYCDocumentPassport, "passport.pdf", "", []byte("%PDF-1.4 passport"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if _, err := kyc.ReviewDocument(customer.ID, doc.ID, "adm-001", &models.ReviewKYCDocumentRequest{Status: models.KYCVerified}); err != nil {
+		t.Fatalf("ReviewDocument failed: %v", err)
+	}
+
+	page, err := activity.Feed(customer.ID, models.ActivityQuery{})
+	if err != nil {
+		t.Fatalf("Feed failed: %v", err)
+	}
+	want := []string{"kyc.document_verified", "kyc.document_uploaded", "customer.address_changed", "customer.email_changed", "customer.profile_updated", "customer.created"}
+	if got := activityTypes(page); len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	} else {
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Expected %v, got %v", want, got)
+			}
+		}
+	}
+	if verified := page.Activities[0]; verified.Actor != "adm-001" || verified.ResourceID != doc.ID || verified.Category != models.ActivityAccount {
+		t.Errorf("Unexpected review activity %+v", verified)
+	}
+	if profile := page.Activities[4]; profile.Details["fields"] != "firstName" {
+		t.Errorf("Expected the changed profile fields, got %+v", profile.Details)
+	}
+
+	// A save that changes nothing the feed follows records nothing
+	if _, err := customers.UpdateCustomer(customer.ID, models.UpdateCustomerRequest{FirstName: "Augusta", LastName: "Lovelace", Email: "augusta@example.com", Address: models.Address{Street: "12 Main St"}}); err != nil {
+		t.Fatalf("UpdateCustomer failed: %v", err)
+	}
+	if page, _ := activity.Feed(customer.ID, models.ActivityQuery{}); len(page.Activities) != len(want) {
+		t.Errorf("Unchanged save recorded %v", activityTypes(page))
+	}
+
+	// A removed customer's feed goes with them
+	if err := customers.DeactivateCustomer(customer.ID); err != nil {
+		t.Fatalf("DeactivateCustomer failed: %v", err)
+	}
+	if feed := repo.GetActivity(customer.ID); len(feed) != 0 {
+		t.Errorf("Expected the feed to be removed, got %d entries", len(feed))
+	}
+}
+
+func TestActivityFeedPagesReportedEvents(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(&models.Customer{ID: "cust-001"})
+	service := NewActivityService(store, logger)
+
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for i, activityType := range []string{"policy.renewed", "payment.succeeded", "login.succeeded", "policy.cancelled", "payment.failed"} {
+		at := start.Add(time.Duration(i) * time.Hour)
+		if _, err := service.Record(models.RecordActivityRequest{
+			EventID:    activityType,
+			CustomerID: "cust-001",
+			Type:       activityType,
+			Summary:    activityType,
+			Source:     "test",
+			OccurredAt: &at,
+		}); err != nil {
+			t.Fatalf("Record %s failed: %v", activityType, err)
+		}
+	}
+
+	// Pages follow each other without gaps or repeats
+	first, err := service.Feed("cust-001", models.ActivityQuery{Limit: 2})
+	if err != nil || len(first.Activities) != 2 || first.Activities[0].Type != "payment.failed" || first.NextCursor == "" {
+		t.Fatalf("Unexpected first page %+v, %v", first, err)
+	}
+	second, err := service.Feed("cust-001", models.ActivityQuery{Limit: 2, Cursor: first.NextCursor})
+	if err != nil || len(second.Activities) != 2 || second.Activities[0].Type != "login.succeeded" {
+		t.Fatalf("Unexpected second page %+v, %v", second, err)
+	}
+	last, err := service.Feed("cust-001", models.ActivityQuery{Limit: 2, Cursor: second.NextCursor})
+	if err != nil || len(last.Activities) != 1 || last.Activities[0].Type != "policy.renewed" || last.NextCursor != "" {
+		t.Fatalf("Unexpected last page %+v, %v", last, err)
+	}
+
+	payments, err := service.Feed("cust-001", models.ActivityQuery{Categories: []string{models.ActivityPayment, models.ActivityLogin}})
+	if got := activityTypes(payments); err != nil || len(got) != 3 || got[0] != "payment.failed" || got[2] != "payment.succeeded" {
+		t.Errorf("Unexpected payment and login activity %v, %v", got, err)
+	}
+
+	// An event delivered twice is recorded once
+	again := start
+	if _, err := service.Record(models.RecordActivityRequest{EventID: "policy.renewed", CustomerID: "cust-001", Type: "policy.renewed", Summary: "again", Source: "test", OccurredAt: &again}); err == nil || err.Error() != "activity already recorded" {
+		t.Errorf("Expected a duplicate to be refused, got %v", err)
+	}
+
+	claim, err := service.RecordClaimEvent(models.ClaimEvent{
+		ID:          7,
+		Type:        "claim.status_changed",
+		ClaimID:     "claim-001",
+		ClaimNumber: "CLM-2026-0001",
+		PolicyID:    "pol-001",
+		CustomerID:  "cust-001",
+		OldStatus:   "submitted",
+		NewStatus:   "under_review",
+		Timestamp:   start.Add(10 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("RecordClaimEvent failed: %v", err)
+	}
+	if claim.Summary != "Claim CLM-2026-0001 moved from submitted to under_review" || claim.Category != models.ActivityClaim || claim.Source != SourceClaimsService || claim.Details["policyId"] != "pol-001" {
+		t.Errorf("Unexpected claim activity %+v", claim)
+	}
+
+	for _, tc := range []struct {
+		query models.ActivityQuery
+		want  string
+	}{
+		{models.ActivityQuery{Categories: []string{"marketing"}}, `unknown category "marketing"`},
+		{models.ActivityQuery{Limit: MaxActivityLimit + 1}, "limit must be between 1 and 200"},
+		{models.ActivityQuery{Cursor: "yesterday"}, "invalid cursor"},
+	} {
+		if _, err := service.Feed("cust-001", tc.query); err == nil || err.Error() != tc.want {
+			t.Errorf("Feed(%+v): expected %q, got %v", tc.query, tc.want, err)
+		}
+	}
+	if _, err := service.Feed("cust-404", models.ActivityQuery{}); err == nil || err.Error() != "customer not found" {
+		t.Errorf("Expected an unknown customer not to be found, got %v", err)
+	}
+	if _, err := service.Record(models.RecordActivityRequest{CustomerID: "cust-001", Type: "weather.changed", Summary: "Rain", Source: "test"}); err == nil || err.Error() != `unknown activity type "weather.changed"` {
+		t.Errorf("Expected an unknown type to be refused, got %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/geocode"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
 	"github.com/sirupsen/logrus"
@@ -12,17 +14,20 @@ import (
 
 // CustomerService handles business logic for customers
 type CustomerService struct {
-	repo   *repository.Repository
-	flags  *features.Flags
-	logger *logrus.Logger
+	repo       repository.CustomerStore
+	flags      *features.Flags
+	normalizer geocode.Normalizer
+	logger     *logrus.Logger
 }
 
-// NewCustomerService creates a new customer service
-func NewCustomerService(repo *repository.Repository, flags *features.Flags, logger *logrus.Logger) *CustomerService {
+// NewCustomerService creates a new customer service. normalizer may be nil,
+// leaving customers' addresses as they were given.
+func NewCustomerService(repo repository.CustomerStore, flags *features.Flags, normalizer geocode.Normalizer, logger *logrus.Logger) *CustomerService {
 	return &CustomerService{
-		repo:   repo,
-		flags:  flags,
-		logger: logger,
+		repo:       repo,
+		flags:      flags,
+		normalizer: normalizer,
+		logger:     logger,
 	}
 }
 
@@ -70,6 +75,7 @@ func (s *CustomerService) CreateCustomer(req models.CreateCustomerRequest) (*mod
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
+	customer.NormalizedAddress = s.normalize(customerID, req.Address)
 
 	if err := s.repo.CreateCustomer(customer); err != nil {
 		s.logger.WithError(err).Error("Failed to create customer")
@@ -93,6 +99,17 @@ func (s *CustomerService) UpdateCustomer(customerID string, req models.UpdateCus
 		return nil, err
 	}
 
+	// A new address has to be verified again
+	if req.Email != existingCustomer.Email {
+		existingCustomer.EmailVerified = false
+		existingCustomer.EmailVerifiedAt = nil
+	}
+
+	// A moved customer is located again
+	if req.Address != existingCustomer.Address || existingCustomer.NormalizedAddress == nil {
+		existingCustomer.NormalizedAddress = s.normalize(customerID, req.Address)
+	}
+
 	// Update the customer fields
 	existingCustomer.FirstName = req.FirstName
 	existingCustomer.LastName = req.LastName
@@ -115,6 +132,21 @@ func (s *CustomerService) UpdateCustomer(customerID string, req models.UpdateCus
 	return existingCustomer, nil
 }
 
+// normalize returns addr normalized, or nil when there is no normalizer or
+// it fails. The customer is saved either way; a geocoding run fills in the
+// addresses it failed on.
+func (s *CustomerService) normalize(customerID string, addr models.Address) *models.NormalizedAddress {
+	if s.normalizer == nil || addr == (models.Address{}) {
+		return nil
+	}
+	normalized, err := normalizeAddress(context.Background(), s.normalizer, addr)
+	if err != nil {
+		s.logger.WithError(err).WithField("customerId", customerID).Warn("Failed to normalize customer address")
+		return nil
+	}
+	return normalized
+}
+
 // DeactivateCustomer deactivates a customer (soft delete)
 func (s *CustomerService) DeactivateCustomer(customerID string) error {
 	// First, check if customer exists
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestService(customers ...*models.Customer) (*CustomerService, *repositorytest.FakeStore) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(customers...)
+	return NewCustomerService(store, nil, nil, logger), store
+}
+
+func TestCreateCustomerAssignsDefaults(t *testing.T) {
+	service, store := newTestService()
+
+	customer, err := service.CreateCustomer(models.CreateCustomerRequest{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"})
+	if err != nil {
+		t.Fatalf("CreateCustomer failed: %v", err)
+	}
+	if customer.ID == "" || customer.RiskScore != 50 || customer.CreatedAt.IsZero() {
+		t.Errorf("Defaults not applied: %+v", customer)
+	}
+	if _, err := store.GetCustomerByEmail("ada@example.com"); err != nil {
+		t.Errorf("Customer not stored: %v", err)
+	}
+}
+
+func TestUpdateCustomer(t *testing.T) {
+	service, _ := newTestService(&models.Customer{ID: "cust-001", FirstName: "Ada", RiskScore: 30})
+
+	updated, err := service.UpdateCustomer("cust-001", models.UpdateCustomerRequest{FirstName: "Augusta", Email: "augusta@example.com"})
+	if err != nil {
+		t.Fatalf("UpdateCustomer failed: %v", err)
+	}
+	if updated.FirstName != "Augusta" || updated.RiskScore != 30 {
+		t.Errorf("Unexpected update result: %+v", updated)
+	}
+
+	if _, err := service.UpdateCustomer("cust-999", models.UpdateCustomerRequest{}); err == nil || err.Error() != "customer not found" {
+		t.Errorf("Expected customer not found, got %v", err)
+	}
+}
+
+func TestDeactivateCustomer(t *testing.T) {
+	service, store := newTestService(&models.Customer{ID: "cust-001"})
+
+	if err := service.DeactivateCustomer("cust-001"); err != nil {
+		t.Fatalf("DeactivateCustomer failed: %v", err)
+	}
+	if _, err := store.GetCustomerByID("cust-001"); err == nil {
+		t.Error("Customer still stored after deactivation")
+	}
+	if err := service.DeactivateCustomer("cust-001"); err == nil {
+		t.Error("Expected error deactivating a missing customer")
+	}
+}
+
+func TestStoreErrorsPropagate(t *testing.T) {
+	service, store := newTestService(&models.Customer{ID: "cust-001"})
+	store.Err = errors.New("disk unavailable")
+
+	if _, err := service.GetAllCustomers(); err == nil {
+		t.Error("Expected store error from GetAllCustomers")
+	}
+	if _, err := service.CreateCustomer(models.CreateCustomerRequest{Email: "x@example.com"}); err == nil {
+		t.Error("Expected store error from CreateCustomer")
+	}
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/geocode"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// normalizeTimeout bounds normalizing one address
+const normalizeTimeout = 10 * time.Second
+
+// normalizeAddress normalizes addr and records which normalizer did so, and
+// when
+func normalizeAddress(ctx context.Context, normalizer geocode.Normalizer, addr models.Address) (*models.NormalizedAddress, error) {
+	ctx, cancel := context.WithTimeout(ctx, normalizeTimeout)
+	defer cancel()
+	normalized, err := normalizer.Normalize(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	normalized.Provider = normalizer.Name()
+	normalized.NormalizedAt = time.Now()
+	return normalized, nil
+}
+
+// GeocodeService re-geocodes customers' addresses in batches: those the
+// normalizer failed on when they were saved, or every customer after the
+// normalizer changes. Runs are made in the background, one at a time, and
+// are kept in memory until the service restarts.
+type GeocodeService struct {
+	repo       repository.CustomerStore
+	normalizer geocode.Normalizer
+	logger     *logrus.Logger
+
+	ctx    context.Context // cancelled by Stop
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	runs    map[string]*models.GeocodeRun
+	nextID  int
+	running bool
+}
+
+// geocodeTarget is a customer's address as it was when a run started
+type geocodeTarget struct {
+	customerID string
+	address    models.Address
+}
+
+// NewGeocodeService creates a geocoding service. normalizer may be nil
+// when none is configured; runs are then refused.
+func NewGeocodeService(repo repository.CustomerStore, normalizer geocode.Normalizer, logger *logrus.Logger) *GeocodeService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &GeocodeService{
+		repo:       repo,
+		normalizer: normalizer,
+		logger:     logger,
+		ctx:        ctx,
+		cancel:     cancel,
+		runs:       make(map[string]*models.GeocodeRun),
+		nextID:     1,
+	}
+}
+
+// Start re-geocodes the customers opts selects in the background
+func (s *GeocodeService) Start(opts models.GeocodeOptions) (*models.GeocodeRun, error) {
+	if s.normalizer == nil {
+		return nil, fmt.Errorf("address normalization not configured")
+	}
+	customers, err := s.repo.GetAllCustomers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	var targets []geocodeTarget
+	for _, customer := range customers {
+		if opts.All || customer.NormalizedAddress == nil {
+			targets = append(targets, geocodeTarget{customerID: customer.ID, address: customer.Address})
+		}
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("a geocoding run is already running")
+	}
+	run := &models.GeocodeRun{
+		ID:        fmt.Sprintf("geo-%03d", s.nextID),
+		Status:    models.GeocodeRunning,
+		Provider:  s.normalizer.Name(),
+		All:       opts.All,
+		Total:     len(targets),
+		Failures:  []models.GeocodeOutcome{},
+		StartedBy: opts.StartedBy,
+		StartedAt: time.Now(),
+	}
+	s.runs[run.ID] = run
+	s.nextID++
+	s.running = true
+	started := copyRun(run)
+	s.mu.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"runId":     run.ID,
+		"customers": len(targets),
+		"all":       opts.All,
+		"startedBy": opts.StartedBy,
+	}).Info("Geocoding run started")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(run, targets)
+	}()
+	return started, nil
+}
+
+// Get returns the progress of a run
+func (s *GeocodeService) Get(runID string) (*models.GeocodeRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, exists := s.runs[runID]
+	if !exists {
+		return nil, fmt.Errorf("geocoding run not found")
+	}
+	return copyRun(run), nil
+}
+
+// Stop interrupts the run in flight and waits for it to stop, so it
+// writes no customer once the repository is closed
+func (s *GeocodeService) Stop(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run geocodes the targets in order. An address the normalizer cannot find
+// or fails on is recorded and the run goes on; it stops at a customer that
+// cannot be saved.
+func (s *GeocodeService) run(run *models.GeocodeRun, targets []geocodeTarget) {
+	for _, target := range targets {
+		if s.ctx.Err() != nil {
+			s.finish(run, "geocoding interrupted by shutdown")
+			return
+		}
+
+		outcome := models.GeocodeOutcome{CustomerID: target.customerID, Outcome: models.GeocodeNormalized}
+		normalized, err := normalizeAddress(s.ctx, s.normalizer, target.address)
+		switch {
+		case err != nil && err.Error() == "address not found":
+			outcome.Outcome = models.GeocodeUnmatched
+		case err != nil:
+			outcome.Outcome, outcome.Error = models.GeocodeError, err.Error()
+		default:
+			// The customer may have moved, or left, while the address was
+			// geocoded; the address they have now is normalized when saved
+			current, err := s.repo.GetCustomerByID(target.customerID)
+			if err != nil || current.Address != target.address {
+				outcome.Outcome = models.GeocodeChanged
+				break
+			}
+			updated := *current
+			updated.NormalizedAddress = normalized
+			if err := s.repo.UpdateCustomer(&updated); err != nil {
+				s.logger.WithError(err).WithField("runId", run.ID).Error("Failed to save geocoded customer, stopping geocoding run")
+				s.finish(run, fmt.Sprintf("failed to save customer %s: %v", target.customerID, err))
+				return
+			}
+		}
+		s.record(run, outcome)
+	}
+	s.finish(run, "")
+}
+
+// record adds a customer's outcome to the run's progress
+func (s *GeocodeService) record(run *models.GeocodeRun, outcome models.GeocodeOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run.Processed++
+	switch outcome.Outcome {
+	case models.GeocodeNormalized:
+		run.Normalized++
+		return
+	case models.GeocodeUnmatched:
+		run.Unmatched++
+	case models.GeocodeError:
+		run.Errors++
+	case models.GeocodeChanged:
+		run.Changed++
+	}
+	run.Failures = append(run.Failures, outcome)
+}
+
+// finish ends a run, as failed when reason is set
+func (s *GeocodeService) finish(run *models.GeocodeRun, reason string) {
+	s.mu.Lock()
+	now := time.Now()
+	run.CompletedAt = &now
+	run.Status, run.Error = models.GeocodeDone, reason
+	if reason != "" {
+		run.Status = models.GeocodeFailed
+	}
+	s.running = false
+	progress := copyRun(run)
+	s.mu.Unlock()
+
+	entry := s.logger.WithFields(logrus.Fields{
+		"runId":      progress.ID,
+		"customers":  progress.Processed,
+		"normalized": progress.Normalized,
+		"unmatched":  progress.Unmatched,
+		"errors":     progress.Errors,
+	})
+	if reason != "" {
+		entry.WithField("reason", reason).Warn("Geocoding run failed")
+		return
+	}
+	entry.Info("Geocoding run completed")
+}
+
+// copyRun returns a copy of run that its goroutine does not write to
+func copyRun(run *models.GeocodeRun) *models.GeocodeRun {
+	copied := *run
+	copied.Failures = append([]models.GeocodeOutcome{}, run.Failures...)
+	return &copied
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubNormalizer upper-cases streets, failing on the streets in errs, and
+// holds each call until release is closed when set
+type stubNormalizer struct {
+	errs    map[string]error
+	release chan struct{}
+	calls   int
+}
+
+func (n *stubNormalizer) Name() string {
+	return "test"
+}
+
+func (n *stubNormalizer) Normalize(ctx context.Context, addr models.Address) (*models.NormalizedAddress, error) {
+	n.calls++
+	if n.release != nil {
+		select {
+		case <-n.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if err := n.errs[addr.Street]; err != nil {
+		return nil, err
+	}
+	return &models.NormalizedAddress{
+		Street:   "NORMALIZED " + addr.Street,
+		Location: &models.GeoLocation{Latitude: 1, Longitude: 2, Precision: models.PrecisionAddress},
+	}, nil
+}
+
+// waitForRun polls a run until it finishes
+func waitForRun(t *testing.T, s *GeocodeService, runID string) *models.GeocodeRun {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		run, err := s.Get(runID)
+		if err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if run.Status != models.GeocodeRunning {
+			return run
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Geocoding run %s did not finish", runID)
+	return nil
+}
+
+func TestCustomersAreNormalizedWhenSaved(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	normalizer := &stubNormalizer{errs: map[string]error{"1 Outage Rd": errors.New("provider unavailable")}}
+	service := NewCustomerService(repositorytest.NewFakeStore(), nil, normalizer, logger)
+
+	customer, err := service.CreateCustomer(models.CreateCustomerRequest{FirstName: "Ada", Email: "ada@example.com", Address: models.Address{Street: "12 Main St"}})
+	if err != nil {
+		t.Fatalf("CreateCustomer failed: %v", err)
+	}
+	normalized := customer.NormalizedAddress
+	if normalized == nil || normalized.Street != "NORMALIZED 12 Main St" || normalized.Provider != "test" || normalized.NormalizedAt.IsZero() {
+		t.Fatalf("Unexpected normalized address %+v", normalized)
+	}
+
+	// An unchanged address is not normalized again
+	update := models.UpdateCustomerRequest{FirstName: "Augusta", Email: "ada@example.com", Address: customer.Address}
+	if updated, err := service.UpdateCustomer(customer.ID, update); err != nil || updated.NormalizedAddress != normalized || normalizer.calls != 1 {
+		t.Errorf("Unchanged address normalized again: %+v, %d calls, %v", updated, normalizer.calls, err)
+	}
+
+	// A customer whose new address cannot be normalized is saved without one
+	update.Address.Street = "1 Outage Rd"
+	if updated, err := service.UpdateCustomer(customer.ID, update); err != nil || updated.NormalizedAddress != nil {
+		t.Errorf("Expected the old normalized address to be dropped, got %+v, %v", updated, err)
+	}
+}
+
+func TestGeocodingRunFillsInMissingAddresses(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	done := &models.NormalizedAddress{Street: "DONE"}
+	store := repositorytest.NewFakeStore(
+		&models.Customer{ID: "cust-001", Address: models.Address{Street: "1 Done St"}, NormalizedAddress: done},
+		&models.Customer{ID: "cust-002", Address: models.Address{Street: "2 Missing St"}},
+		&models.Customer{ID: "cust-003", Address: models.Address{Street: "3 Nowhere St"}},
+		&models.Customer{ID: "cust-004", Address: models.Address{Street: "4 Outage St"}},
+	)
+	normalizer := &stubNormalizer{errs: map[string]error{
+		"3 Nowhere St": errors.New("address not found"),
+		"4 Outage St":  errors.New("provider unavailable"),
+	}}
+	service := NewGeocodeService(store, normalizer, logger)
+
+	started, err := service.Start(models.GeocodeOptions{StartedBy: "admin-1"})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if started.Total != 3 || started.Provider != "test" || started.StartedBy != "admin-1" {
+		t.Errorf("Unexpected run started %+v", started)
+	}
+	run := waitForRun(t, service, started.ID)
+	if run.Status != models.GeocodeDone || run.Normalized != 1 || run.Unmatched != 1 || run.Errors != 1 || len(run.Failures) != 2 {
+		t.Fatalf("Unexpected run %+v", run)
+	}
+	if run.Failures[0].CustomerID != "cust-003" || run.Failures[1].Error != "provider unavailable" {
+		t.Errorf("Unexpected failures %+v", run.Failures)
+	}
+	if customer, _ := store.GetCustomerByID("cust-002"); customer.NormalizedAddress == nil || customer.NormalizedAddress.Street != "NORMALIZED 2 Missing St" {
+		t.Errorf("Missing address not filled in: %+v", customer.NormalizedAddress)
+	}
+	if customer, _ := store.GetCustomerByID("cust-001"); customer.NormalizedAddress != done {
+		t.Errorf("A normalized address was geocoded again: %+v", customer.NormalizedAddress)
+	}
+
+	// One run at a time; a blocked run is stopped by Stop
+	normalizer.release = make(chan struct{})
+	all, err := service.Start(models.GeocodeOptions{All: true})
+	if err != nil || all.Total != 4 {
+		t.Fatalf("Unexpected run %+v, %v", all, err)
+	}
+	if _, err := service.Start(models.GeocodeOptions{}); err == nil || err.Error() != "a geocoding run is already running" {
+		t.Errorf("Expected a second run to be refused, got %v", err)
+	}
+	if err := service.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if run, _ := service.Get(all.ID); run.Status != models.GeocodeFailed || run.Error != "geocoding interrupted by shutdown" {
+		t.Errorf("Expected the run to be interrupted, got %+v", run)
+	}
+
+	if _, err := NewGeocodeService(store, nil, logger).Start(models.GeocodeOptions{}); err == nil || err.Error() != "address normalization not configured" {
+		t.Errorf("Expected runs to be refused without a normalizer, got %v", err)
+	}
+	if _, err := service.Get("geo-404"); err == nil {
+		t.Error("Expected an unknown run not to be found")
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// HouseholdService manages households, the groups of customers who share
+// policies. Membership changes are serialised so a customer can never end
+// up in two households.
+type HouseholdService struct {
+	repo   repository.HouseholdStore
+	mu     sync.Mutex
+	logger *logrus.Logger
+}
+
+// NewHouseholdService creates a new household service
+func NewHouseholdService(repo repository.HouseholdStore, logger *logrus.Logger) *HouseholdService {
+	return &HouseholdService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// GetHousehold retrieves a household and its members
+func (s *HouseholdService) GetHousehold(householdID string) (*models.Household, error) {
+	household, err := s.repo.GetHouseholdByID(householdID)
+	if err != nil {
+		s.logger.WithField("householdId", householdID).Warn("Household not found")
+		return nil, err
+	}
+	return household, nil
+}
+
+// CreateHousehold creates a household with req.CustomerID as its first
+// member
+func (s *HouseholdService) CreateHousehold(req models.CreateHouseholdRequest) (*models.Household, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("household name is required")
+	}
+	if req.CustomerID == "" {
+		return nil, fmt.Errorf("customerId is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, err := s.repo.GetCustomerByID(req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.HouseholdID != "" {
+		return nil, fmt.Errorf("customer already belongs to a household")
+	}
+
+	now := time.Now()
+	household := &models.Household{
+		ID:        fmt.Sprintf("hh-%d", now.UnixNano()),
+		Name:      name,
+		MemberIDs: []string{customer.ID},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateHousehold(household); err != nil {
+		return nil, fmt.Errorf("failed to create household: %w", err)
+	}
+	if err := s.setHousehold(customer, household.ID, now); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"householdId": household.ID,
+		"customerId":  customer.ID,
+	}).Info("Household created")
+
+	return household, nil
+}
+
+// JoinHousehold adds a customer to a household. Joining a household the
+// customer already belongs to changes nothing.
+func (s *HouseholdService) JoinHousehold(householdID string, req models.JoinHouseholdRequest) (*models.Household, error) {
+	if req.CustomerID == "" {
+		return nil, fmt.Errorf("customerId is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	household, err := s.repo.GetHouseholdByID(householdID)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := s.repo.GetCustomerByID(req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.HouseholdID == householdID {
+		return household, nil
+	}
+	if customer.HouseholdID != "" {
+		return nil, fmt.Errorf("customer already belongs to a household")
+	}
+
+	now := time.Now()
+	household.MemberIDs = append(household.MemberIDs, customer.ID)
+	household.UpdatedAt = now
+	if err := s.repo.UpdateHousehold(household); err != nil {
+		return nil, fmt.Errorf("failed to update household: %w", err)
+	}
+	if err := s.setHousehold(customer, household.ID, now); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"householdId": household.ID,
+		"customerId":  customer.ID,
+		"members":     len(household.MemberIDs),
+	}).Info("Customer joined household")
+
+	return household, nil
+}
+
+// LeaveHousehold removes a customer from a household. When the last member
+// leaves the household is deleted and returned with no members.
+func (s *HouseholdService) LeaveHousehold(householdID, customerID string) (*models.Household, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	household, err := s.repo.GetHouseholdByID(householdID)
+	if err != nil {
+		return nil, err
+	}
+	if !household.HasMember(customerID) {
+		return nil, fmt.Errorf("customer is not a member of this household")
+	}
+
+	now := time.Now()
+	members := []string{}
+	for _, id := range household.MemberIDs {
+		if id != customerID {
+			members = append(members, id)
+		}
+	}
+	household.MemberIDs = members
+	household.UpdatedAt = now
+
+	if len(members) == 0 {
+		err = s.repo.DeleteHousehold(householdID)
+	} else {
+		err = s.repo.UpdateHousehold(household)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update household: %w", err)
+	}
+
+	// The customer may have been removed since joining; the household no
+	// longer lists them either way
+	if customer, err := s.repo.GetCustomerByID(customerID); err == nil {
+		if err := s.setHousehold(customer, "", now); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"householdId": householdID,
+		"customerId":  customerID,
+		"members":     len(members),
+	}).Info("Customer left household")
+
+	return household, nil
+}
+
+// setHousehold records the customer's household on their profile
+func (s *HouseholdService) setHousehold(customer *models.Customer, householdID string, now time.Time) error {
+	customer.HouseholdID = householdID
+	customer.UpdatedAt = now
+	if err := s.repo.UpdateCustomer(customer); err != nil {
+		s.logger.WithError(err).WithField("customerId", customer.ID).Error("Failed to update customer household")
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHouseholdMembership(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(&models.Customer{ID: "cust-001"}, &models.Customer{ID: "cust-002"}, &models.Customer{ID: "cust-003"})
+	service := NewHouseholdService(store, logger)
+
+	household, err := service.CreateHousehold(models.CreateHouseholdRequest{Name: " Chen family ", CustomerID: "cust-001"})
+	if err != nil || household.Name != "Chen family" || len(household.MemberIDs) != 1 {
+		t.Fatalf("CreateHousehold: got %+v, %v", household, err)
+	}
+	if _, err := service.CreateHousehold(models.CreateHouseholdRequest{Name: "Second home", CustomerID: "cust-001"}); err == nil || err.Error() != "customer already belongs to a household" {
+		t.Errorf("Second household: got %v", err)
+	}
+
+	joined, err := service.JoinHousehold(household.ID, models.JoinHouseholdRequest{CustomerID: "cust-002"})
+	if err != nil || len(joined.MemberIDs) != 2 {
+		t.Fatalf("JoinHousehold: got %+v, %v", joined, err)
+	}
+	if again, err := service.JoinHousehold(household.ID, models.JoinHouseholdRequest{CustomerID: "cust-002"}); err != nil || len(again.MemberIDs) != 2 {
+		t.Errorf("Rejoining should change nothing: got %+v, %v", again, err)
+	}
+	if customer, _ := store.GetCustomerByID("cust-002"); customer.HouseholdID != household.ID {
+		t.Errorf("Member not linked to household: %+v", customer)
+	}
+	if _, err := service.JoinHousehold("hh-404", models.JoinHouseholdRequest{CustomerID: "cust-003"}); err == nil || err.Error() != "household not found" {
+		t.Errorf("Unknown household: got %v", err)
+	}
+
+	if _, err := service.LeaveHousehold(household.ID, "cust-003"); err == nil || err.Error() != "customer is not a member of this household" {
+		t.Errorf("Leave by non-member: got %v", err)
+	}
+	left, err := service.LeaveHousehold(household.ID, "cust-001")
+	if err != nil || len(left.MemberIDs) != 1 || left.MemberIDs[0] != "cust-002" {
+		t.Fatalf("LeaveHousehold: got %+v, %v", left, err)
+	}
+	if customer, _ := store.GetCustomerByID("cust-001"); customer.HouseholdID != "" {
+		t.Errorf("Former member still linked: %+v", customer)
+	}
+
+	// The last member leaving dissolves the household
+	if dissolved, err := service.LeaveHousehold(household.ID, "cust-002"); err != nil || len(dissolved.MemberIDs) != 0 {
+		t.Fatalf("Last member leaving: got %+v, %v", dissolved, err)
+	}
+	if _, err := service.GetHousehold(household.ID); err == nil {
+		t.Error("Expected the empty household to be deleted")
+	}
+}
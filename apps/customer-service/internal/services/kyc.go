@@ -0,0 +1,176 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// KYCService manages the identity documents customers submit and their
+// review. Every review recomputes the customer's KYC status, which other
+// services read from the customer record. Reviews are serialised so two
+// reviewers cannot race on the same customer's status.
+type KYCService struct {
+	repo   repository.KYCStore
+	mu     sync.Mutex
+	logger *logrus.Logger
+}
+
+// NewKYCService creates a new KYC service
+func NewKYCService(repo repository.KYCStore, logger *logrus.Logger) *KYCService {
+	return &KYCService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// UploadDocument stores an identity document for review. Without a content
+// type the type is detected from the content.
+func (s *KYCService) UploadDocument(customerID, uploadedBy, documentType, fileName, contentType string, content []byte) (*models.KYCDocument, error) {
+	if !models.ValidateKYCDocumentType(documentType) {
+		return nil, fmt.Errorf("invalid document type: %s (must be passport, drivers_license, national_id or proof_of_address)", documentType)
+	}
+
+	// Keep only the base name; browsers on Windows may send the full path
+	fileName = filepath.Base(strings.ReplaceAll(strings.TrimSpace(fileName), `\`, "/"))
+	if fileName == "." || fileName == "/" {
+		return nil, fmt.Errorf("file name is required")
+	}
+	if len(content) == 0 {
+		return nil, fmt.Errorf("document is empty")
+	}
+	if len(content) > models.MaxKYCDocumentSize {
+		return nil, fmt.Errorf("document exceeds the %d MiB limit", models.MaxKYCDocumentSize>>20)
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = http.DetectContentType(content)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, err := s.repo.GetCustomerByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &models.KYCDocument{
+		ID:           fmt.Sprintf("kyc-%d", time.Now().UnixNano()),
+		CustomerID:   customer.ID,
+		DocumentType: documentType,
+		FileName:     fileName,
+		ContentType:  contentType,
+		Size:         int64(len(content)),
+		Status:       models.KYCPending,
+		UploadedBy:   uploadedBy,
+		UploadedAt:   time.Now(),
+	}
+	if err := s.repo.AddKYCDocument(doc, content); err != nil {
+		return nil, fmt.Errorf("failed to store KYC document: %w", err)
+	}
+	if err := s.updateStatus(customer); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId":   customer.ID,
+		"documentId":   doc.ID,
+		"documentType": doc.DocumentType,
+		"size":         doc.Size,
+	}).Info("KYC document uploaded")
+
+	return doc, nil
+}
+
+// GetKYC returns a customer's KYC status and documents
+func (s *KYCService) GetKYC(customerID string) (*models.KYCSummary, error) {
+	customer, err := s.repo.GetCustomerByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := customer.KYCStatus
+	if status == "" {
+		status = "not_submitted"
+	}
+	return &models.KYCSummary{
+		CustomerID: customer.ID,
+		Status:     status,
+		VerifiedAt: customer.KYCVerifiedAt,
+		Documents:  s.repo.GetKYCDocuments(customer.ID),
+	}, nil
+}
+
+// GetDocument returns a KYC document and its content
+func (s *KYCService) GetDocument(customerID, documentID string) (*models.KYCDocument, []byte, error) {
+	return s.repo.GetKYCDocument(customerID, documentID)
+}
+
+// ReviewDocument records a reviewer's decision on a pending document and
+// updates the customer's KYC status
+func (s *KYCService) ReviewDocument(customerID, documentID, reviewerID string, req *models.ReviewKYCDocumentRequest) (*models.KYCDocument, error) {
+	if req.Status != models.KYCVerified && req.Status != models.KYCRejected {
+		return nil, fmt.Errorf("invalid review status: %s (must be verified or rejected)", req.Status)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if req.Status == models.KYCRejected && reason == "" {
+		return nil, fmt.Errorf("a reason is required to reject a document")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	customer, err := s.repo.GetCustomerByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	doc, _, err := s.repo.GetKYCDocument(customerID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Status != models.KYCPending {
+		return nil, fmt.Errorf("document has already been reviewed")
+	}
+
+	now := time.Now()
+	doc.Status = req.Status
+	doc.ReviewedBy = reviewerID
+	doc.ReviewedAt = &now
+	if req.Status == models.KYCRejected {
+		doc.RejectionReason = reason
+	}
+	if err := s.repo.UpdateKYCDocument(doc); err != nil {
+		return nil, fmt.Errorf("failed to update KYC document: %w", err)
+	}
+	if err := s.updateStatus(customer); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId": customer.ID,
+		"documentId": doc.ID,
+		"status":     doc.Status,
+		"reviewedBy": reviewerID,
+		"kycStatus":  customer.KYCStatus,
+	}).Info("KYC document reviewed")
+
+	return doc, nil
+}
+
+// updateStatus recomputes the customer's KYC status from their documents
+func (s *KYCService) updateStatus(customer *models.Customer) error {
+	customer.KYCStatus, customer.KYCVerifiedAt = models.KYCStatusOf(s.repo.GetKYCDocuments(customer.ID))
+	customer.UpdatedAt = time.Now()
+	if err := s.repo.UpdateCustomer(customer); err != nil {
+		s.logger.WithError(err).WithField("customerId", customer.ID).Error("Failed to update KYC status")
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore"
+	"github.com/sirupsen/logrus"
+)
+
+func TestKYCReviewWorkflow(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(&models.Customer{ID: "cust-001"})
+	service := NewKYCService(store, logger)
+
+	summary, err := service.GetKYC("cust-001")
+	if err != nil || summary.Status != "not_submitted" || len(summary.Documents) != 0 {
+		t.Fatalf("Before upload: got %+v, %v", summary, err)
+	}
+
+	license, err := service.UploadDocument("cust-001", "cust-001", models.KYCDocumentDriversLicense, `C:\scans\license.pdf`, "", []byte("%PDF-1.4 license"))
+	if err != nil || license.Status != models.KYCPending || license.FileName != "license.pdf" || license.ContentType != "application/pdf" {
+		t.Fatalf("UploadDocument: got %+v, %v", license, err)
+	}
+	if customer, _ := store.GetCustomerByID("cust-001"); customer.KYCStatus != models.KYCPending {
+		t.Errorf("Customer status after upload: %q", customer.KYCStatus)
+	}
+	if _, err := service.UploadDocument("cust-001", "cust-001", "library_card", "card.png", "", []byte("x")); err == nil {
+		t.Error("Expected unknown document type to be rejected")
+	}
+	if _, err := service.UploadDocument("cust-404", "cust-404", models.KYCDocumentPassport, "passport.pdf", "", []byte("x")); err == nil || err.Error() != "customer not found" {
+		t.Errorf("Unknown customer: got %v", err)
+	}
+
+	if _, err := service.ReviewDocument("cust-001", license.ID, "adm-001", &models.ReviewKYCDocumentRequest{Status: models.KYCRejected}); err == nil || err.Error() != "a reason is required to reject a document" {
+		t.Errorf("Rejection without reason: got %v", err)
+	}
+	rejected, err := service.ReviewDocument("cust-001", license.ID, "adm-001", &models.ReviewKYCDocumentRequest{Status: models.KYCRejected, Reason: "Expired"})
+	if err != nil || rejected.ReviewedBy != "adm-001" || rejected.ReviewedAt == nil || rejected.RejectionReason != "Expired" {
+		t.Fatalf("Reject: got %+v, %v", rejected, err)
+	}
+	if customer, _ := store.GetCustomerByID("cust-001"); customer.KYCStatus != models.KYCRejected {
+		t.Errorf("Customer status after rejection: %q", customer.KYCStatus)
+	}
+	if _, err := service.ReviewDocument("cust-001", license.ID, "adm-002", &models.ReviewKYCDocumentRequest{Status: models.KYCVerified}); err == nil || err.Error() != "document has already been reviewed" {
+		t.Errorf("Second review: got %v", err)
+	}
+
+	passport, err := service.UploadDocument("cust-001", "cust-001", models.KYCDocumentPassport, "passport.png", "image/png", []byte("passport scan"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if _, err := service.ReviewDocument("cust-001", passport.ID, "adm-001", &models.ReviewKYCDocumentRequest{Status: models.KYCVerified}); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	summary, err = service.GetKYC("cust-001")
+	if err != nil || summary.Status != models.KYCVerified || summary.VerifiedAt == nil || len(summary.Documents) != 2 {
+		t.Errorf("After verification: got %+v, %v", summary, err)
+	}
+	if _, content, err := service.GetDocument("cust-001", passport.ID); err != nil || string(content) != "passport scan" {
+		t.Errorf("GetDocument: got %q, %v", content, err)
+	}
+}
+
+func TestKYCDocumentsInTheBlobStore(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	fake := repositorytest.NewFakeStore(&models.Customer{ID: "cust-001"})
+	blobs := &blobstore.Local{Dir: t.TempDir(), Limits: blobstore.Limits{MaxSize: 1 << 10, ContentTypes: []string{"image/*", "application/pdf"}}}
+	service := NewKYCService(repository.WithBlobs(fake, blobs), logger)
+
+	// Uploaded before the blob store: content kept in the repository
+	if err := fake.AddKYCDocument(&models.KYCDocument{ID: "kyc-legacy", CustomerID: "cust-001", DocumentType: models.KYCDocumentPassport, Status: models.KYCPending}, []byte("passport scan")); err != nil {
+		t.Fatalf("AddKYCDocument failed: %v", err)
+	}
+	license, err := service.UploadDocument("cust-001", "cust-001", models.KYCDocumentDriversLicense, "license.pdf", "", []byte("%PDF-1.4 license"))
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if _, stored, _ := fake.GetKYCDocument("cust-001", license.ID); len(stored) != 0 {
+		t.Errorf("Expected the content in the blob store only, got %q in the repository", stored)
+	}
+	if _, content, err := service.GetDocument("cust-001", license.ID); err != nil || string(content) != "%PDF-1.4 license" {
+		t.Errorf("Expected the license to be read from the blob store, got %q, %v", content, err)
+	}
+	if _, content, err := service.GetDocument("cust-001", "kyc-legacy"); err != nil || string(content) != "passport scan" {
+		t.Errorf("Expected the legacy document to be read from the repository, got %q, %v", content, err)
+	}
+
+	var limitErr *blobstore.LimitError
+	if _, err := service.UploadDocument("cust-001", "cust-001", models.KYCDocumentProofOfAddress, "bill.html", "text/html", []byte("<p>bill</p>")); !errors.As(err, &limitErr) {
+		t.Errorf("Expected a content type outside the limits to be refused, got %v", err)
+	}
+	if docs := fake.GetKYCDocuments("cust-001"); len(docs) != 2 {
+		t.Errorf("Expected the refused upload not to be stored, got %d documents", len(docs))
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// GetPreferences retrieves a customer's communication preferences. A
+// customer who has never set them gets every opt-in off, payment reminders
+// on and no paperless consent on record.
+func (s *CustomerService) GetPreferences(customerID string) (*models.Preferences, error) {
+	customer, err := s.repo.GetCustomerByID(customerID)
+	if err != nil {
+		s.logger.WithField("customerId", customerID).Warn("Customer not found")
+		return nil, err
+	}
+
+	if customer.Preferences == nil {
+		return &models.Preferences{}, nil
+	}
+	return customer.Preferences, nil
+}
+
+// UpdatePreferences replaces a customer's communication opt-ins and payment
+// reminder opt-out. Paperless
+// billing consent is only re-recorded, with the time and req.Source, when it
+// changes, so the record always shows when the current consent was given or
+// withdrawn.
+func (s *CustomerService) UpdatePreferences(customerID string, req models.UpdatePreferencesRequest) (*models.Preferences, error) {
+	customer, err := s.repo.GetCustomerByID(customerID)
+	if err != nil {
+		s.logger.WithField("customerId", customerID).Warn("Customer not found for preferences update")
+		return nil, err
+	}
+
+	now := time.Now()
+	preferences := &models.Preferences{}
+	if customer.Preferences != nil {
+		copied := *customer.Preferences
+		preferences = &copied
+	}
+	preferences.EmailOptIn = req.EmailOptIn
+	preferences.SMSOptIn = req.SMSOptIn
+	preferences.PostOptIn = req.PostOptIn
+	preferences.PaymentRemindersOptOut = req.PaymentRemindersOptOut
+	preferences.UpdatedAt = now
+
+	consent := preferences.PaperlessBilling
+	if consent.RecordedAt == nil || consent.Granted != req.PaperlessBilling {
+		if req.Source == "" {
+			return nil, fmt.Errorf("source is required when paperless billing consent changes")
+		}
+		if !models.ValidateConsentSource(req.Source) {
+			return nil, fmt.Errorf("invalid consent source: %s (must be web, mobile, phone, agent or post)", req.Source)
+		}
+		preferences.PaperlessBilling = models.Consent{
+			Granted:    req.PaperlessBilling,
+			RecordedAt: &now,
+			Source:     req.Source,
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"customerId": customerID,
+			"granted":    req.PaperlessBilling,
+			"source":     req.Source,
+		}).Info("Paperless billing consent recorded")
+	}
+
+	customer.Preferences = preferences
+	customer.UpdatedAt = now
+	if err := s.repo.UpdateCustomer(customer); err != nil {
+		s.logger.WithError(err).WithField("customerId", customerID).Error("Failed to update preferences")
+		return nil, fmt.Errorf("failed to update preferences: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId":      customerID,
+		"emailOptIn":      req.EmailOptIn,
+		"smsOptIn":        req.SMSOptIn,
+		"postOptIn":       req.PostOptIn,
+		"remindersOptOut": req.PaymentRemindersOptOut,
+	}).Info("Customer preferences updated")
+
+	return preferences, nil
+}
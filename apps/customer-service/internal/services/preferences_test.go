@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+)
+
+func TestUpdatePreferencesRecordsPaperlessConsent(t *testing.T) {
+	service, _ := newTestService(&models.Customer{ID: "cust-001"})
+
+	preferences, err := service.GetPreferences("cust-001")
+	if err != nil || preferences.EmailOptIn || preferences.PaperlessBilling.RecordedAt != nil {
+		t.Fatalf("Expected empty preferences, got %+v, %v", preferences, err)
+	}
+
+	if _, err := service.UpdatePreferences("cust-001", models.UpdatePreferencesRequest{PaperlessBilling: true}); err == nil {
+		t.Error("Expected error for consent without a source")
+	}
+	if _, err := service.UpdatePreferences("cust-001", models.UpdatePreferencesRequest{PaperlessBilling: true, Source: "fax"}); err == nil {
+		t.Error("Expected error for an unknown consent source")
+	}
+
+	granted, err := service.UpdatePreferences("cust-001", models.UpdatePreferencesRequest{EmailOptIn: true, PaperlessBilling: true, Source: models.ConsentSourceWeb})
+	if err != nil {
+		t.Fatalf("UpdatePreferences failed: %v", err)
+	}
+	consent := granted.PaperlessBilling
+	if !granted.EmailOptIn || !consent.Granted || consent.Source != models.ConsentSourceWeb || consent.RecordedAt == nil {
+		t.Fatalf("Consent not recorded: %+v", granted)
+	}
+	recordedAt := *consent.RecordedAt
+
+	// Changing opt-ins or opting out of reminders leaves the consent record
+	// alone and needs no source
+	updated, err := service.UpdatePreferences("cust-001", models.UpdatePreferencesRequest{SMSOptIn: true, PaymentRemindersOptOut: true, PaperlessBilling: true})
+	if err != nil {
+		t.Fatalf("UpdatePreferences failed: %v", err)
+	}
+	if updated.EmailOptIn || !updated.SMSOptIn || !updated.PaymentRemindersOptOut || !updated.PaperlessBilling.RecordedAt.Equal(recordedAt) || updated.PaperlessBilling.Source != models.ConsentSourceWeb {
+		t.Errorf("Unexpected preferences: %+v", updated)
+	}
+
+	withdrawn, err := service.UpdatePreferences("cust-001", models.UpdatePreferencesRequest{PaperlessBilling: false, Source: models.ConsentSourcePhone})
+	if err != nil || withdrawn.PaperlessBilling.Granted || withdrawn.PaperlessBilling.Source != models.ConsentSourcePhone {
+		t.Errorf("Withdrawal not recorded: %+v, %v", withdrawn, err)
+	}
+
+	if _, err := service.UpdatePreferences("cust-404", models.UpdatePreferencesRequest{}); err == nil || err.Error() != "customer not found" {
+		t.Errorf("Unknown customer: got %v", err)
+	}
+}
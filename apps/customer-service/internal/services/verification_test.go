@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/email"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// outbox records sent email instead of delivering it
+type outbox struct {
+	sent []email.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg email.Message) error {
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+// tokenFrom extracts the verification token from an emailed link
+func tokenFrom(t *testing.T, msg email.Message) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Body) {
+		if link, err := url.Parse(field); err == nil && link.Query().Get("token") != "" {
+			return link.Query().Get("token")
+		}
+	}
+	t.Fatalf("No verification link in %q", msg.Body)
+	return ""
+}
+
+func TestEmailVerification(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(&models.Customer{ID: "cust-001", FirstName: "Ada", Email: "ada@example.com"})
+	mail := &outbox{}
+	tokens := auth.NewVerificationTokens("test-secret", time.Hour)
+	service := NewVerificationService(store, tokens, mail, "http://localhost:8004/customers/verify", logger)
+	customers := NewCustomerService(store, nil, nil, logger)
+
+	sent, err := service.SendVerification(context.Background(), "cust-001")
+	if err != nil {
+		t.Fatalf("SendVerification failed: %v", err)
+	}
+	if len(mail.sent) != 1 || mail.sent[0].To != "ada@example.com" || sent.ExpiresAt.IsZero() {
+		t.Fatalf("Unexpected verification email: %+v, %+v", sent, mail.sent)
+	}
+	token := tokenFrom(t, mail.sent[0])
+
+	if _, err := service.VerifyEmail("not-a-token"); err == nil || err.Error() != "invalid verification token" {
+		t.Errorf("Bad token: got %v", err)
+	}
+
+	verified, err := service.VerifyEmail(token)
+	if err != nil {
+		t.Fatalf("VerifyEmail failed: %v", err)
+	}
+	if !verified.EmailVerified || verified.EmailVerifiedAt == nil {
+		t.Errorf("Email not marked verified: %+v", verified)
+	}
+	if _, err := service.VerifyEmail(token); err != nil {
+		t.Errorf("Verifying twice should succeed, got %v", err)
+	}
+	if _, err := service.SendVerification(context.Background(), "cust-001"); err == nil || err.Error() != "email already verified" {
+		t.Errorf("Resend to verified email: got %v", err)
+	}
+
+	// A new address must be verified again, and old links no longer work
+	if _, err := customers.UpdateCustomer("cust-001", models.UpdateCustomerRequest{FirstName: "Ada", Email: "ada@newmail.example"}); err != nil {
+		t.Fatalf("UpdateCustomer failed: %v", err)
+	}
+	customer, _ := store.GetCustomerByID("cust-001")
+	if customer.EmailVerified || customer.EmailVerifiedAt != nil {
+		t.Errorf("Changed email still verified: %+v", customer)
+	}
+	if _, err := service.VerifyEmail(token); err == nil || err.Error() != "verification token does not match the customer's email" {
+		t.Errorf("Token for old email: got %v", err)
+	}
+
+	expired, _, _ := auth.NewVerificationTokens("test-secret", -time.Minute).Generate("cust-001", "ada@newmail.example")
+	if _, err := service.VerifyEmail(expired); err == nil || err.Error() != "verification token has expired" {
+		t.Errorf("Expired token: got %v", err)
+	}
+}
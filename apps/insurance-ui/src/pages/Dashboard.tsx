@@ -9,13 +9,13 @@ import { useNavigate } from 'react-router-dom';
 export default function Dashboard() {
   const navigate = useNavigate();
 
-  // Fetch policies data
+  // Fetch policies data, including those shared within the household
   const {
     data: policies,
     isLoading: policiesLoading,
   } = useQuery<Policy[]>({
-    queryKey: ['policies'],
-    queryFn: () => api.getPolicies(),
+    queryKey: ['policies', 'household'],
+    queryFn: () => api.getPolicies({ scope: 'household' }),
     refetchInterval: 30000,
   });
 
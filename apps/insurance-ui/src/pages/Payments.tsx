@@ -114,6 +114,7 @@ export default function Payments() {
       case 'completed':
         return <CheckCircle className="w-5 h-5 text-green-600" />;
       case 'pending':
+      case 'processing':
         return <Clock className="w-5 h-5 text-yellow-600" />;
       case 'failed':
         return <XCircle className="w-5 h-5 text-red-600" />;
@@ -3,8 +3,16 @@ import axios, { AxiosInstance, AxiosError } from 'axios';
 import type {
   User,
   Customer,
+  CustomerPreferences,
+  UpdatePreferencesRequest,
+  VerificationSent,
+  KYCDocument,
+  KYCDocumentType,
+  KYCSummary,
+  Household,
   Policy,
   Claim,
+  ClaimTimeline,
   Payment,
   Quote,
 } from '../types';
@@ -113,11 +121,90 @@ class ApiService {
     await apiClient.delete(`customers/${customerId}`);
   }
 
+  async getCustomerPreferences(customerId: string): Promise<CustomerPreferences> {
+    const response = await apiClient.get<CustomerPreferences>(
+      `customers/${customerId}/preferences`
+    );
+    return response.data;
+  }
+
+  async updateCustomerPreferences(
+    customerId: string,
+    preferences: UpdatePreferencesRequest
+  ): Promise<CustomerPreferences> {
+    const response = await apiClient.put<CustomerPreferences>(
+      `customers/${customerId}/preferences`,
+      preferences
+    );
+    return response.data;
+  }
+
+  async sendVerificationEmail(customerId: string): Promise<VerificationSent> {
+    const response = await apiClient.post<VerificationSent>(
+      `customers/${customerId}/send-verification`
+    );
+    return response.data;
+  }
+
+  async verifyEmail(token: string): Promise<Customer> {
+    const response = await apiClient.get<Customer>('customers/verify', {
+      params: { token },
+    });
+    return response.data;
+  }
+
+  async getKyc(customerId: string): Promise<KYCSummary> {
+    const response = await apiClient.get<KYCSummary>(`customers/${customerId}/kyc`);
+    return response.data;
+  }
+
+  async uploadKycDocument(
+    customerId: string,
+    documentType: KYCDocumentType,
+    file: File
+  ): Promise<KYCDocument> {
+    const form = new FormData();
+    form.append('documentType', documentType);
+    form.append('file', file);
+    const response = await apiClient.post<KYCDocument>(
+      `customers/${customerId}/kyc/documents`,
+      form,
+      { headers: { 'Content-Type': 'multipart/form-data' } }
+    );
+    return response.data;
+  }
+
+  async createHousehold(name: string, customerId: string): Promise<Household> {
+    const response = await apiClient.post<Household>('households', {
+      name,
+      customerId,
+    });
+    return response.data;
+  }
+
+  async getHousehold(householdId: string): Promise<Household> {
+    const response = await apiClient.get<Household>(`households/${householdId}`);
+    return response.data;
+  }
+
+  async joinHousehold(householdId: string, customerId: string): Promise<Household> {
+    const response = await apiClient.post<Household>(
+      `households/${householdId}/members`,
+      { customerId }
+    );
+    return response.data;
+  }
+
+  async leaveHousehold(householdId: string, customerId: string): Promise<void> {
+    await apiClient.delete(`households/${householdId}/members/${customerId}`);
+  }
+
   // Policy endpoints
   async getPolicies(params?: {
     customerId?: string;
     policyType?: string;
     status?: string;
+    scope?: 'customer' | 'household';
   }): Promise<Policy[]> {
     const response = await apiClient.get<Policy[]>('policies', {
       params,
@@ -165,6 +252,11 @@ class ApiService {
     return response.data;
   }
 
+  async getClaimTimeline(claimId: string): Promise<ClaimTimeline> {
+    const response = await apiClient.get<ClaimTimeline>(`claims/${claimId}/timeline`);
+    return response.data;
+  }
+
   async createClaim(claimData: Partial<Claim>): Promise<Claim> {
     const response = await apiClient.post<Claim>('claims', claimData);
     return response.data;
@@ -22,16 +22,86 @@ export interface Customer {
     country: string;
   };
   riskScore?: number;
+  emailVerified: boolean;
+  emailVerifiedAt?: string;
+  preferences?: CustomerPreferences;
+  householdId?: string;
+  kycStatus?: KYCStatus; // unset until the customer submits an identity document
+  kycVerifiedAt?: string;
   createdAt: string;
   updatedAt: string;
 }
 
+export type ConsentSource = 'web' | 'mobile' | 'phone' | 'agent' | 'post';
+
+export interface Consent {
+  granted: boolean;
+  recordedAt?: string;
+  source?: ConsentSource;
+}
+
+export interface CustomerPreferences {
+  emailOptIn: boolean;
+  smsOptIn: boolean;
+  postOptIn: boolean;
+  paperlessBilling: Consent;
+  updatedAt?: string;
+}
+
+export interface UpdatePreferencesRequest {
+  emailOptIn: boolean;
+  smsOptIn: boolean;
+  postOptIn: boolean;
+  paperlessBilling: boolean;
+  source?: ConsentSource; // required when paperlessBilling changes the recorded consent
+}
+
+export interface Household {
+  id: string;
+  name: string;
+  memberIds: string[];
+  createdAt: string;
+  updatedAt: string;
+}
+
+export type KYCStatus = 'pending' | 'verified' | 'rejected';
+
+export type KYCDocumentType = 'passport' | 'drivers_license' | 'national_id' | 'proof_of_address';
+
+export interface KYCDocument {
+  id: string;
+  customerId: string;
+  documentType: KYCDocumentType;
+  fileName: string;
+  contentType: string;
+  size: number;
+  status: KYCStatus;
+  uploadedBy: string;
+  uploadedAt: string;
+  reviewedBy?: string;
+  reviewedAt?: string;
+  rejectionReason?: string;
+}
+
+export interface KYCSummary {
+  customerId: string;
+  status: KYCStatus | 'not_submitted';
+  verifiedAt?: string;
+  documents: KYCDocument[];
+}
+
+export interface VerificationSent {
+  customerId: string;
+  email: string;
+  expiresAt: string;
+}
+
 export interface Policy {
   id: string;
   customerId: string;
   policyNumber: string;
   type: 'auto' | 'home' | 'life' | 'health';
-  status: 'active' | 'lapsed' | 'cancelled';
+  status: 'pending' | 'active' | 'grace' | 'lapsed' | 'cancelled' | 'expired';
   premium: number;
   coverage: number;
   deductible?: number;
@@ -39,6 +109,7 @@ export interface Policy {
   startDate: string;
   endDate: string;
   renewalDate?: string;
+  nonRenewing?: boolean;
   createdAt: string;
   updatedAt: string;
 }
@@ -49,13 +120,53 @@ export interface Claim {
   customerId: string;
   claimNumber: string;
   type: string;
-  status: 'submitted' | 'under_review' | 'approved' | 'rejected';
+  status: 'submitted' | 'under_review' | 'pending_payment' | 'approved' | 'rejected';
   amount: number;
   description: string;
+  rejectionCodes?: Array<'not_covered' | 'late_filing' | 'fraud_suspected' | 'insufficient_docs' | 'duplicate'>;
+  incidentDate?: string;
+  lossLocation?: LossLocation;
+  catastropheId?: string;
+  catastropheTag?: 'auto' | 'manual';
   submittedDate: string;
   reviewedDate?: string;
   createdAt: string;
   updatedAt: string;
+  version: number;
+}
+
+export interface ClaimTimelineEntry {
+  timestamp: string;
+  type: string; // claim.created, claim.status_changed, ..., payout.requested, payout.completed
+  source: 'claims-service' | 'payments-service';
+  claimId: string;
+  summary: string;
+  oldStatus?: string;
+  newStatus?: string;
+  queue?: string;
+  assignedTo?: string;
+  reason?: string;
+  documentId?: string;
+  fileName?: string;
+  paymentId?: string;
+  amount?: number;
+  paymentStatus?: string;
+}
+
+export interface ClaimTimeline {
+  claimId: string;
+  claimNumber: string;
+  entries: ClaimTimelineEntry[];
+  warnings?: string[];
+}
+
+export interface LossLocation {
+  street?: string;
+  city: string;
+  state?: string;
+  postalCode?: string;
+  country: string;
+  description?: string;
 }
 
 export interface Payment {
@@ -65,9 +176,12 @@ export interface Payment {
   claimId?: string;
   customerId: string;
   amount: number;
-  status: 'pending' | 'completed' | 'failed';
+  status: 'pending' | 'processing' | 'completed' | 'failed' | 'refunded';
   paymentMethod?: string;
   processedDate?: string;
+  failureReason?: string;
+  refundedDate?: string;
+  refundReason?: string;
   createdAt: string;
   updatedAt: string;
 }
@@ -40,6 +40,11 @@ export default defineConfig({
         changeOrigin: true,
         rewrite: (path) => path.replace(/^\/api\/payments/, ''),
       },
+      '/api/search': {
+        target: process.env.VITE_SEARCH_SERVICE_URL || 'http://localhost:8006',
+        changeOrigin: true,
+        rewrite: (path) => path.replace(/^\/api\/search/, '/search'),
+      },
     },
   },
   build: {
@@ -0,0 +1,569 @@
+// Package app assembles the payments service from its repository, services,
+// handlers and routes. cmd/server serves it over HTTP; tests can mount the
+// same handler on an httptest server.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/notify"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/screening"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/health"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/retention"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the settings needed to assemble the service
+type Config struct {
+	DataPath      string
+	FeatureAPIKey string
+
+	// Base URLs of the services payments reference. Any may be empty, in
+	// which case the consistency report skips that service's checks and
+	// flag targeting cannot use its attributes.
+	PolicyServiceURL   string
+	ClaimsServiceURL   string
+	CustomerServiceURL string
+
+	// JWTSecret signs the staff token payments uses to read claims from
+	// claims-service and lapse policies in policy-service. JWT_SECRET in the
+	// default secret store takes its place when it resolves, so rotations
+	// take effect without a restart.
+	JWTSecret string
+
+	// DefaultCommissionRate applies to agents without a rate of their own;
+	// zero uses services.DefaultCommissionRate
+	DefaultCommissionRate float64
+
+	// BillingPeriodMonths is how many months of cover each premium invoice
+	// is for, and BillingDueDays how many days after its period starts it
+	// is due; zero uses the services package defaults
+	BillingPeriodMonths int
+	BillingDueDays      int
+
+	// LateFees is charged on invoices left unpaid past its grace period, by
+	// a job every LateFeeInterval; 0 disables the background job. The zero
+	// rule charges no late fees.
+	LateFees        services.LateFeeRule
+	LateFeeInterval time.Duration
+
+	// ReminderOffsets are the days from an invoice's due date that payment
+	// reminders are sent, negative before it; nil uses
+	// services.DefaultReminderOffsets. ReminderInterval is how often the
+	// reminder job runs; 0 disables the background job. Reminders are
+	// POSTed to ReminderWebhookURLs, signed with each of
+	// ReminderWebhookSecrets, or written to the service log when there are
+	// none.
+	ReminderOffsets        []int
+	ReminderInterval       time.Duration
+	ReminderWebhookURLs    []string
+	ReminderWebhookSecrets signing.Keys
+
+	// AlertsFile holds the thresholds alerts are raised on, such as the
+	// payout failure rate. When empty alerts.json in DataPath is used, and
+	// without that file no alerts are raised. AlertInterval is how often
+	// they are evaluated; 0 disables evaluation. Alerts are POSTed to
+	// AlertWebhookURLs, signed with each of AlertWebhookSecrets.
+	AlertsFile          string
+	AlertInterval       time.Duration
+	AlertWebhookURLs    []string
+	AlertWebhookSecrets signing.Keys
+
+	// BulkMaxPayments is the most payments POST /payments/bulk takes in
+	// one batch, and BulkConcurrency how many of them are created at once;
+	// zero uses services.DefaultBulkMaxPayments and
+	// services.DefaultBulkConcurrency
+	BulkMaxPayments int
+	BulkConcurrency int
+
+	// GatewayWebhookSecrets verify the payment gateway's callbacks to
+	// POST /webhooks/gateway; a callback signed with any of them is taken,
+	// so the gateway's secret can be rotated. Without any, callbacks are
+	// accepted unsigned.
+	GatewayWebhookSecrets signing.Keys
+
+	// Screener names the sanctions screener payouts are checked with:
+	// screening.NameStub (the default when empty) or screening.NameOFAC.
+	// SanctionsListFile is the OFAC SDN list; when empty sanctions/sdn.csv in
+	// DataPath is used.
+	Screener          string
+	SanctionsListFile string
+
+	// RetentionFile is how long closed payments stay live before they are
+	// archived. When empty retention.json in DataPath is used, and without
+	// that file nothing is archived. ArchiveInterval is how often the
+	// archival job runs; 0 disables the background job.
+	RetentionFile   string
+	ArchiveInterval time.Duration
+
+	// ShapingFile holds the field visibility rules of the responses, on
+	// top of their shape tags. When empty response-shaping.json in
+	// DataPath is used, and without that file the tags alone apply.
+	ShapingFile string
+
+	// PersistDir holds the write-ahead log and snapshot that keep changes
+	// across restarts. When empty changes are kept in memory only.
+	// PersistFlushInterval is how often the log is folded into the snapshot.
+	PersistDir           string
+	PersistFlushInterval time.Duration
+
+	// Maintenance is the maintenance mode the service starts in
+	Maintenance maintenance.Config
+	// Faults is whether the service may inject faults for resilience
+	// testing; the rules are set at /admin/faults
+	Faults faults.Config
+
+	// SlowRequestThreshold logs requests that take at least this long as
+	// warnings; 0 disables the warning
+	SlowRequestThreshold time.Duration
+
+	// AccessLog receives one structured entry per request; when nil they
+	// are written to the service log
+	AccessLog *logrus.Logger
+	// ErrorReporter receives handler panics, such as a Sentry hub; nil only
+	// logs them
+	ErrorReporter telemetry.ErrorReporter
+}
+
+// App is an assembled payments service
+type App struct {
+	Handler http.Handler
+	Flags   *features.Flags
+
+	journal       *persist.Journal
+	stopArchive   lifecycle.StopFunc
+	stopLateFees  lifecycle.StopFunc
+	stopReminders lifecycle.StopFunc
+	stopAlerts    lifecycle.StopFunc
+	logger        *logrus.Logger
+}
+
+// New wires the service together and loads its data from cfg.DataPath
+func New(cfg Config, logger *logrus.Logger) (*App, error) {
+	// Initialize CloudBees Feature Management
+	flags, err := features.Initialize(cfg.FeatureAPIKey, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize feature management: %w", err)
+	}
+
+	// Load the retention policy, sanctions list and response shaping
+	// before anything needs stopping
+	policy, err := loadRetention(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	screener, err := loadScreener(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	shaper, err := loadShaping(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+
+	// Initialize repository
+	repo, err := repository.NewRepository(cfg.DataPath, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	var journal *persist.Journal
+	if cfg.PersistDir != "" {
+		journal, err = persist.Open(cfg.PersistDir, "payments-service", cfg.PersistFlushInterval, logger)
+		if err != nil {
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to open persisted state: %w", err)
+		}
+		if err := repo.Persist(journal); err != nil {
+			journal.Close()
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to restore persisted state: %w", err)
+		}
+	}
+
+	// Initialize services
+	// Claims and policy lapses are for staff, so payments signs its own
+	// staff token for them
+	jwtManager := auth.NewRotatingJWTManager(secrets.Default().Func("JWT_SECRET", cfg.JWTSecret), time.Minute)
+	token := func() (string, error) {
+		return jwtManager.GenerateWithRole("payments-service", "", "admin")
+	}
+	var lookups services.Lookups
+	var policyClient *clients.PolicyClient
+	var preferences services.ReminderPreferences
+	if cfg.PolicyServiceURL != "" {
+		policyClient = clients.NewPolicyClient(cfg.PolicyServiceURL, token, 5*time.Second)
+		lookups.Policies = policyClient
+	}
+	if cfg.ClaimsServiceURL != "" {
+		lookups.Claims = clients.NewClaimsClient(cfg.ClaimsServiceURL, token, 5*time.Second)
+	}
+	if cfg.CustomerServiceURL != "" {
+		customerClient := clients.NewCustomerClient(cfg.CustomerServiceURL, 5*time.Second)
+		lookups.Customers = customerClient
+		preferences = customerClient
+	}
+
+	commissionRate := cfg.DefaultCommissionRate
+	if commissionRate == 0 {
+		commissionRate = services.DefaultCommissionRate
+	}
+	agentService := services.NewAgentService(repo, commissionRate, logger)
+	billingService := services.NewBillingService(repo, lookups.Policies, cfg.BillingPeriodMonths, cfg.BillingDueDays, cfg.LateFees, logger)
+	paymentService := services.NewPaymentService(repo, flags, lookups, agentService, billingService, screener, logger)
+	bulkPaymentService := services.NewBulkPaymentService(paymentService, cfg.BulkMaxPayments, cfg.BulkConcurrency, logger)
+	consistencyChecker := services.NewConsistencyChecker(repo, lookups.Policies, lookups.Claims, lookups.Customers, logger)
+	lossRatioReporter := services.NewLossRatioReporter(repo, lookups.Policies, lookups.Claims, logger)
+	archiveService := services.NewArchiveService(repo, policy, logger)
+	// Lost disputes lapse the policy, when policy-service is configured
+	var lapser services.PolicyLapser
+	if policyClient != nil {
+		lapser = policyClient
+	}
+	disputeService := services.NewDisputeService(repo, paymentService, lapser, logger)
+	var notifier notify.Notifier = notify.NewLog(logger)
+	if len(cfg.ReminderWebhookURLs) > 0 {
+		notifier = notify.NewWebhook(cfg.ReminderWebhookURLs, cfg.ReminderWebhookSecrets, 5*time.Second)
+	}
+	reminderService := services.NewReminderService(repo, billingService, preferences, notifier, cfg.ReminderOffsets, logger)
+
+	// Raise alerts on failing payouts
+	alerts, err := loadAlerts(cfg, map[string]alerting.Metric{
+		"payout_failure_rate": func() (float64, error) {
+			return paymentService.PayoutFailureRate(time.Now().Add(-payoutFailureWindow))
+		},
+	}, logger)
+	if err != nil {
+		journal.Close()
+		features.Shutdown()
+		return nil, err
+	}
+	var stopAlerts lifecycle.StopFunc
+	if cfg.AlertInterval > 0 {
+		stopAlerts = lifecycle.Go(func(ctx context.Context) {
+			alerts.Run(ctx, cfg.AlertInterval)
+		})
+	}
+
+	// Move payments past retention to the archive
+	var stopArchive lifecycle.StopFunc
+	if cfg.ArchiveInterval > 0 {
+		stopArchive = lifecycle.Go(func(ctx context.Context) {
+			archiveService.Run(ctx, cfg.ArchiveInterval)
+		})
+	}
+
+	// Charge late fees on overdue invoices, which needs policy-service for
+	// the invoices
+	var stopLateFees lifecycle.StopFunc
+	if cfg.LateFeeInterval > 0 && cfg.LateFees.Enabled() && cfg.PolicyServiceURL != "" {
+		stopLateFees = lifecycle.Go(func(ctx context.Context) {
+			billingService.RunLateFees(ctx, cfg.LateFeeInterval)
+		})
+	}
+
+	// Remind customers of unpaid premium, which needs policy-service for
+	// the invoices and customer-service for opt-outs
+	var stopReminders lifecycle.StopFunc
+	if cfg.ReminderInterval > 0 && cfg.PolicyServiceURL != "" && cfg.CustomerServiceURL != "" {
+		stopReminders = lifecycle.Go(func(ctx context.Context) {
+			reminderService.Run(ctx, cfg.ReminderInterval)
+		})
+	}
+
+	// Initialize handlers
+	maintenanceMode := maintenance.New(cfg.Maintenance, logger)
+	faultInjection := faults.New(cfg.Faults, logger)
+	healthHandler := health.NewHandler("payments-service", health.Combine(flags, maintenanceMode, faultInjection))
+	paymentHandler := handlers.NewPaymentHandler(paymentService, logger)
+	bulkPaymentHandler := handlers.NewBulkPaymentHandler(bulkPaymentService, logger)
+	agentHandler := handlers.NewAgentHandler(agentService, logger)
+	billingHandler := handlers.NewBillingHandler(billingService, logger)
+	disputeHandler := handlers.NewDisputeHandler(disputeService, logger)
+	reminderHandler := handlers.NewReminderHandler(reminderService, logger)
+	gatewayHandler := handlers.NewGatewayHandler(paymentService, logger)
+	consistencyHandler := handlers.NewConsistencyHandler(consistencyChecker, logger)
+	lossRatioHandler := handlers.NewLossRatioHandler(lossRatioReporter, logger)
+	impressionsHandler := handlers.NewImpressionsHandler(flags.Impressions(), logger)
+	archiveHandler := handlers.NewArchiveHandler(archiveService, logger)
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Apply global middleware
+	requestMetrics := telemetry.NewRegistry(nil)
+	panics := telemetry.NewPanicCounter()
+	router.Use(middleware.LoggingMiddleware(logger, middleware.LoggingOptions{
+		Metrics:       requestMetrics,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		AccessLog:     cfg.AccessLog,
+	}))
+	// Error messages are translated into the caller's Accept-Language,
+	// including those of recovered panics
+	router.Use(i18n.Middleware(i18n.Default()))
+	router.Use(middleware.RecoveryMiddleware(logger, middleware.RecoveryOptions{
+		Panics:   panics,
+		Reporter: cfg.ErrorReporter,
+	}))
+	// Turn callers away during maintenance before they are authenticated
+	router.Use(maintenanceMode.Middleware)
+	// Inject test faults ahead of authentication too, so every caller is
+	// faulted the same way
+	router.Use(faultInjection.Middleware)
+	router.Use(middleware.AuthMiddleware(logger))
+	// Sanctions screening results are left out for customers
+	router.Use(shaping.Middleware(shaper, func(r *http.Request) shaping.Viewer {
+		return shaping.Viewer{Role: middleware.GetRole(r), Flags: shaping.FlagsOn(flags.EnabledFlags())}
+	}))
+
+	// Setup CORS
+	corsHandler := middleware.NewCORS()
+
+	// Back-office staff; customer routes keep using the X-User-ID header
+	staff := middleware.RequireRole(logger, "admin", "adjuster")
+
+	// Register routes
+	router.Handle("/healthz", healthHandler).Methods("GET")
+	router.Handle("/labels", i18n.LabelsHandler(i18n.Default())).Methods("GET")
+	router.Handle("/metrics", telemetry.Handler(requestMetrics, panics, disputeService, alerts, faultInjection)).Methods("GET")
+	// Archived payments are for legal retrievals by staff only
+	router.Handle("/payments", staff(http.HandlerFunc(paymentHandler.GetPayments))).Queries("includeArchived", "true").Methods("GET")
+	router.Handle("/payments/{id}", staff(http.HandlerFunc(paymentHandler.GetPaymentByID))).Queries("includeArchived", "true").Methods("GET")
+	router.HandleFunc("/payments", paymentHandler.GetPayments).Methods("GET")
+	router.HandleFunc("/payments/{id}", paymentHandler.GetPaymentByID).Methods("GET")
+	router.HandleFunc("/payments", paymentHandler.CreatePayment).Methods("POST")
+	router.Handle("/payments/bulk", staff(http.HandlerFunc(bulkPaymentHandler.CreatePayments))).Methods("POST")
+	router.HandleFunc("/refunds", paymentHandler.CreateRefund).Methods("POST")
+	router.HandleFunc("/payments/{id}/process", paymentHandler.ProcessPayment).Methods("PUT")
+	router.HandleFunc("/policies/{id}/balance", billingHandler.GetPolicyBalance).Methods("GET")
+	// The payment gateway reports payment outcomes signed rather than with
+	// a token
+	router.Handle("/webhooks/gateway", signing.Middleware(cfg.GatewayWebhookSecrets, logger)(http.HandlerFunc(gatewayHandler.ReceiveEvent))).Methods("POST")
+
+	// Payouts are made by claims-service for the offers adjusters settle,
+	// with a service token; failing or reversing a settled payment are
+	// decisions for adjusters
+	router.Handle("/payouts", middleware.RequireScope(logger, "payments:write")(http.HandlerFunc(paymentHandler.CreatePayout))).Methods("POST")
+	router.Handle("/payments/{id}/fail", staff(http.HandlerFunc(paymentHandler.FailPayment))).Methods("PUT")
+	router.Handle("/payments/{id}/refund", staff(http.HandlerFunc(paymentHandler.RefundPayment))).Methods("PUT")
+	// Releasing a payout held by sanctions screening is a compliance
+	// decision
+	router.Handle("/payments/{id}/release", middleware.RequireRole(logger, "admin")(http.HandlerFunc(paymentHandler.ReleasePayment))).Methods("PUT")
+	// Disputes the payer raised with their bank are worked by staff
+	router.Handle("/payments/{id}/disputes", staff(http.HandlerFunc(disputeHandler.OpenDispute))).Methods("POST")
+	router.Handle("/payments/{id}/disputes", staff(http.HandlerFunc(disputeHandler.GetPaymentDisputes))).Methods("GET")
+
+	// Agent management and commission statements, for back-office staff
+	agents := router.PathPrefix("/agents").Subrouter()
+	agents.Use(middleware.RequireRole(logger, "admin"))
+	agents.HandleFunc("", agentHandler.GetAgents).Methods("GET")
+	agents.HandleFunc("", agentHandler.CreateAgent).Methods("POST")
+	agents.HandleFunc("/{id}", agentHandler.GetAgentByID).Methods("GET")
+	agents.HandleFunc("/{id}", agentHandler.UpdateAgent).Methods("PUT")
+	agents.HandleFunc("/{id}", agentHandler.DeactivateAgent).Methods("DELETE")
+	agents.HandleFunc("/{id}/commissions", agentHandler.GetCommissions).Methods("GET")
+
+	// Back-office routes
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(staff)
+	admin.Handle("/consistency-report", consistencyHandler).Methods("GET")
+	admin.Handle("/flags/impressions/summary", impressionsHandler).Methods("GET")
+	admin.HandleFunc("/reports/loss-ratio", lossRatioHandler.GetLossRatio).Methods("GET")
+	admin.HandleFunc("/reports/loss-ratio/export", lossRatioHandler.ExportLossRatio).Methods("GET")
+	admin.Handle("/payments/archive", archiveHandler).Methods("POST")
+	admin.HandleFunc("/disputes", disputeHandler.GetDisputes).Methods("GET")
+	admin.HandleFunc("/disputes/metrics", disputeHandler.GetMetrics).Methods("GET")
+	admin.HandleFunc("/disputes/{id}", disputeHandler.GetDispute).Methods("GET")
+	admin.HandleFunc("/disputes/{id}/resolve", disputeHandler.ResolveDispute).Methods("PUT")
+	admin.HandleFunc("/late-fees/run", billingHandler.ChargeLateFees).Methods("POST")
+	admin.HandleFunc("/reminders", reminderHandler.GetReminders).Methods("GET")
+	admin.HandleFunc("/reminders/run", reminderHandler.RunReminders).Methods("POST")
+	admin.Handle("/alerts", alerts.Handler()).Methods("GET")
+	admin.Handle("/maintenance", middleware.RequireRole(logger, "admin")(maintenanceMode.Handler())).Methods("GET", "PUT")
+	admin.Handle("/faults", middleware.RequireRole(logger, "admin")(faultInjection.Handler())).Methods("GET", "PUT")
+
+	// Wrap router with CORS
+	return &App{
+		Handler:       corsHandler.Handler(router),
+		Flags:         flags,
+		journal:       journal,
+		stopArchive:   stopArchive,
+		stopLateFees:  stopLateFees,
+		stopReminders: stopReminders,
+		stopAlerts:    stopAlerts,
+		logger:        logger,
+	}, nil
+}
+
+// serverDrainTimeout is how long requests in flight get to finish on
+// shutdown
+const serverDrainTimeout = 30 * time.Second
+
+// RegisterShutdown registers the service's components with m in the order
+// they stop. server, when given, drains first, so requests in flight and
+// the archival, late fee and reminder jobs can still write to the journal before it
+// writes its final snapshot. Alert evaluation only reads, and stops with them.
+func (a *App) RegisterShutdown(m *lifecycle.Manager, server lifecycle.StopFunc) {
+	if server != nil {
+		m.Register("http server", serverDrainTimeout, server)
+	}
+	if a.stopArchive != nil {
+		m.Register("payment archival", 10*time.Second, a.stopArchive)
+	}
+	if a.stopLateFees != nil {
+		m.Register("late fees", 10*time.Second, a.stopLateFees)
+	}
+	if a.stopReminders != nil {
+		m.Register("payment reminders", 10*time.Second, a.stopReminders)
+	}
+	if a.stopAlerts != nil {
+		m.Register("alert evaluation", 5*time.Second, a.stopAlerts)
+	}
+	m.Register("persisted state", 10*time.Second, func(ctx context.Context) error {
+		return a.journal.Close()
+	})
+	m.Register("feature flags", 5*time.Second, func(ctx context.Context) error {
+		features.Shutdown()
+		return nil
+	})
+}
+
+// Close stops the service's components without an HTTP server, writing a
+// final snapshot of persisted state
+func (a *App) Close() {
+	m := lifecycle.New(a.logger)
+	a.RegisterShutdown(m, nil)
+	m.Shutdown(context.Background())
+}
+
+// loadShaping reads the response shaping policy and checks it, and the
+// shape tags, against the payment responses. A configured file must load;
+// the default file may be missing.
+func loadShaping(cfg Config, logger *logrus.Logger) (*shaping.Shaper, error) {
+	path := cfg.ShapingFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "response-shaping.json")
+	}
+	policy, err := shaping.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ShapingFile == "" {
+		logger.Warnf("No response shaping policy in %s, shape tags alone apply", path)
+		policy = shaping.Default()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load response shaping policy: %w", err)
+	} else {
+		logger.WithField("version", policy.Version).Infof("Loaded response shaping policy from %s", path)
+	}
+
+	shaper := shaping.New(policy, "payments-service")
+	if err := shaper.Check(models.Payment{}); err != nil {
+		return nil, fmt.Errorf("invalid response shaping: %w", err)
+	}
+	return shaper, nil
+}
+
+// loadRetention reads the retention policy. A configured file must load;
+// the default file may be missing.
+func loadRetention(cfg Config, logger *logrus.Logger) (*retention.Policy, error) {
+	path := cfg.RetentionFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "retention.json")
+	}
+	policy, err := retention.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.RetentionFile == "" {
+		logger.Warnf("No retention policy in %s, payments are never archived", path)
+		return retention.Default(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load retention policy: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": policy.Version,
+		"rules":   len(policy.Rules),
+	}).Infof("Loaded retention policy from %s", path)
+	return policy, nil
+}
+
+// payoutFailureWindow is how far back the payout failure rate alerts are
+// raised on looks
+const payoutFailureWindow = time.Hour
+
+// loadAlerts reads the alert thresholds of the service's metrics. A
+// configured file must load; without the default file no alerts are
+// raised.
+func loadAlerts(cfg Config, metrics map[string]alerting.Metric, logger *logrus.Logger) (*alerting.Evaluator, error) {
+	path := cfg.AlertsFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "alerts.json")
+	}
+	var notifier alerting.Notifier
+	if len(cfg.AlertWebhookURLs) > 0 {
+		notifier = &alerting.Webhooks{URLs: cfg.AlertWebhookURLs, Signer: cfg.AlertWebhookSecrets}
+	}
+	alerts := alerting.New(alerting.Config{Service: "payments", Path: path, Metrics: metrics, Notifier: notifier}, logger)
+	err := alerts.Load()
+	if errors.Is(err, os.ErrNotExist) && cfg.AlertsFile == "" {
+		logger.Infof("No alert thresholds in %s, no alerts are raised", path)
+		return alerts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert thresholds: %w", err)
+	}
+	logger.WithField("rules", alerts.Len()).Infof("Loaded alert thresholds from %s", path)
+	return alerts, nil
+}
+
+// loadScreener selects the sanctions screener payouts are checked with,
+// reading the SDN list for the OFAC screener
+func loadScreener(cfg Config, logger *logrus.Logger) (screening.Screener, error) {
+	switch cfg.Screener {
+	case "", screening.NameStub:
+		logger.Warn("Screening payouts with the stub screener, no sanctions list is searched")
+		return screening.Stub{}, nil
+	case screening.NameOFAC:
+	default:
+		return nil, fmt.Errorf("unknown sanctions screener %q (want %s or %s)", cfg.Screener, screening.NameStub, screening.NameOFAC)
+	}
+
+	path := cfg.SanctionsListFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "sanctions", "sdn.csv")
+	}
+	list, err := screening.LoadOFAC(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sanctions list: %w", err)
+	}
+	if cfg.CustomerServiceURL == "" {
+		logger.Warn("CUSTOMER_SERVICE_URL not set, payout recipients cannot be named and every payout will be blocked")
+	}
+	logger.WithField("names", list.Len()).Infof("Screening payouts against the OFAC SDN list in %s", path)
+	return list, nil
+}
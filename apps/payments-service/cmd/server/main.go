@@ -7,36 +7,39 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/handlers"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/middleware"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/app"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
-	"github.com/gorilla/mux"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/logging"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	// Initialize logger from LOG_FORMAT, LOG_LEVEL, LOG_LEVELS and sampling settings
+	logger := logging.NewFromEnv()
 
-	// Configure log level from environment
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-	level, err := logrus.ParseLevel(logLevel)
+	logger.Info("Starting Payments service...")
+
+	// Secrets come from SECRETS_PROVIDER: the environment by default, or
+	// mounted files, Vault or AWS Secrets Manager. Startup fails when one
+	// of SECRETS_REQUIRED does not resolve.
+	secretStore, err := secrets.Open(secrets.ConfigFromEnv(logger), logger)
 	if err != nil {
-		logger.Warnf("Invalid log level '%s', defaulting to info", logLevel)
-		level = logrus.InfoLevel
+		logger.WithError(err).Fatal("Failed to resolve secrets")
 	}
-	logger.SetLevel(level)
-
-	logger.Info("Starting Payments service...")
+	secrets.SetDefault(secretStore)
+	logger.WithField("provider", secretStore.Source()).Info("Secrets resolved")
 
 	// Get configuration from environment
 	port := os.Getenv("PORT")
@@ -50,73 +53,344 @@ func main() {
 		dataPath = filepath.Join("..", "..", "data", "seed")
 	}
 
-	cloudBeesAPIKey := os.Getenv("CLOUDBEES_FM_API_KEY")
+	cloudBeesAPIKey := secretStore.Lookup("CLOUDBEES_FM_API_KEY", "")
 	if cloudBeesAPIKey == "" {
 		logger.Warn("CLOUDBEES_FM_API_KEY not set, feature flags will use defaults")
 		// Use a placeholder for development
 		cloudBeesAPIKey = "dev-mode"
 	}
 
-	// Initialize CloudBees Feature Management
-	flags, err := features.Initialize(cloudBeesAPIKey, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize feature management")
+	// Other services, used by the consistency report. Payouts are only
+	// checked against accepted settlement offers when claims-service is set.
+	policyServiceURL := os.Getenv("POLICY_SERVICE_URL")
+	claimsServiceURL := os.Getenv("CLAIMS_SERVICE_URL")
+	customerServiceURL := os.Getenv("CUSTOMER_SERVICE_URL")
+	if policyServiceURL == "" || claimsServiceURL == "" || customerServiceURL == "" {
+		logger.Warn("POLICY_SERVICE_URL, CLAIMS_SERVICE_URL or CUSTOMER_SERVICE_URL not set, consistency report will skip those checks")
+	}
+	if claimsServiceURL == "" {
+		logger.Warn("CLAIMS_SERVICE_URL not set, payouts will not be checked against settlement offers")
 	}
-	defer features.Shutdown()
 
-	// Initialize repository
-	repo, err := repository.NewRepository(dataPath, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize repository")
+	jwtSecret := secretStore.Lookup("JWT_SECRET", "")
+	if jwtSecret == "" {
+		jwtSecret = "dev-secret-key-change-in-production"
+		logger.Warn("JWT_SECRET not set, using default (not secure for production)")
 	}
 
-	// Initialize services
-	paymentService := services.NewPaymentService(repo, flags, logger)
+	// Commission paid to agents without a rate of their own
+	commissionRate := 0.10
+	if v := os.Getenv("COMMISSION_DEFAULT_RATE"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r > 0 && r < 1 {
+			commissionRate = r
+		} else {
+			logger.Warnf("Invalid COMMISSION_DEFAULT_RATE '%s', defaulting to %.2f", v, commissionRate)
+		}
+	}
 
-	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler("payments-service")
-	paymentHandler := handlers.NewPaymentHandler(paymentService, logger)
+	// Premium billing: each policy's term is invoiced in periods of
+	// BILLING_PERIOD_MONTHS, each due BILLING_DUE_DAYS after it starts
+	billingPeriodMonths := 12
+	if v := os.Getenv("BILLING_PERIOD_MONTHS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 12 {
+			billingPeriodMonths = n
+		} else {
+			logger.Warnf("Invalid BILLING_PERIOD_MONTHS '%s', defaulting to %d", v, billingPeriodMonths)
+		}
+	}
+	billingDueDays := 30
+	if v := os.Getenv("BILLING_DUE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			billingDueDays = n
+		} else {
+			logger.Warnf("Invalid BILLING_DUE_DAYS '%s', defaulting to %d", v, billingDueDays)
+		}
+	}
 
-	// Setup router
-	router := mux.NewRouter()
+	// Late fees on invoices still unpaid LATE_FEE_GRACE_DAYS after they are
+	// due: LATE_FEE_FLAT once, and interest at LATE_FEE_APR accrued daily.
+	// The job runs every LATE_FEE_INTERVAL; neither fee set charges nothing.
+	lateFees := services.LateFeeRule{GraceDays: services.DefaultLateFeeGraceDays}
+	if v := os.Getenv("LATE_FEE_FLAT"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			lateFees.FlatFee = f
+		} else {
+			logger.Warnf("Invalid LATE_FEE_FLAT '%s', no flat late fee is charged", v)
+		}
+	}
+	if v := os.Getenv("LATE_FEE_APR"); v != "" {
+		if r, err := strconv.ParseFloat(v, 64); err == nil && r >= 0 && r < 1 {
+			lateFees.APR = r
+		} else {
+			logger.Warnf("Invalid LATE_FEE_APR '%s', no late interest is charged", v)
+		}
+	}
+	if v := os.Getenv("LATE_FEE_GRACE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			lateFees.GraceDays = n
+		} else {
+			logger.Warnf("Invalid LATE_FEE_GRACE_DAYS '%s', defaulting to %d", v, lateFees.GraceDays)
+		}
+	}
+	lateFeeInterval := time.Hour
+	if v := os.Getenv("LATE_FEE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			lateFeeInterval = d
+		} else {
+			logger.Warnf("Invalid LATE_FEE_INTERVAL '%s', defaulting to %s", v, lateFeeInterval)
+		}
+	}
+	if lateFees.Enabled() && lateFeeInterval > 0 && policyServiceURL == "" {
+		logger.Warn("POLICY_SERVICE_URL not set, late fees will not be charged")
+	}
 
-	// Apply global middleware
-	router.Use(middleware.LoggingMiddleware(logger))
-	router.Use(middleware.AuthMiddleware(logger))
+	// Reminders of unpaid premium at PAYMENT_REMINDER_OFFSETS days from each
+	// invoice's due date, checked every PAYMENT_REMINDER_INTERVAL and sent to
+	// PAYMENT_REMINDER_WEBHOOK_URLS, or to the service log without any
+	reminderOffsets := services.DefaultReminderOffsets
+	if v := os.Getenv("PAYMENT_REMINDER_OFFSETS"); v != "" {
+		var offsets []int
+		for _, field := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				offsets = nil
+				break
+			}
+			offsets = append(offsets, n)
+		}
+		if len(offsets) > 0 {
+			reminderOffsets = offsets
+		} else {
+			logger.Warnf("Invalid PAYMENT_REMINDER_OFFSETS '%s', defaulting to %v", v, reminderOffsets)
+		}
+	}
+	reminderInterval := time.Hour
+	if v := os.Getenv("PAYMENT_REMINDER_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			reminderInterval = d
+		} else {
+			logger.Warnf("Invalid PAYMENT_REMINDER_INTERVAL '%s', defaulting to %s", v, reminderInterval)
+		}
+	}
+	if reminderInterval > 0 && (policyServiceURL == "" || customerServiceURL == "") {
+		logger.Warn("POLICY_SERVICE_URL or CUSTOMER_SERVICE_URL not set, payment reminders will not be sent")
+	}
 
-	// Setup CORS
-	corsHandler := middleware.NewCORS()
+	// Batches of premium payments at POST /payments/bulk
+	bulkMaxPayments := services.DefaultBulkMaxPayments
+	if v := os.Getenv("BULK_PAYMENT_MAX_ITEMS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			bulkMaxPayments = n
+		} else {
+			logger.Warnf("Invalid BULK_PAYMENT_MAX_ITEMS '%s', defaulting to %d", v, bulkMaxPayments)
+		}
+	}
+	bulkConcurrency := services.DefaultBulkConcurrency
+	if v := os.Getenv("BULK_PAYMENT_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			bulkConcurrency = n
+		} else {
+			logger.Warnf("Invalid BULK_PAYMENT_CONCURRENCY '%s', defaulting to %d", v, bulkConcurrency)
+		}
+	}
+	var reminderWebhookURLs []string
+	for _, u := range strings.Split(os.Getenv("PAYMENT_REMINDER_WEBHOOK_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			reminderWebhookURLs = append(reminderWebhookURLs, u)
+		}
+	}
+	// Comma-separated, newest first: reminders are signed with each, so
+	// receivers can rotate their secret
+	reminderWebhookSecrets := signing.ParseKeys(secretStore.Lookup("PAYMENT_REMINDER_WEBHOOK_SECRET", ""))
+	if len(reminderWebhookURLs) == 0 {
+		logger.Info("PAYMENT_REMINDER_WEBHOOK_URLS not set, payment reminders will be written to the log")
+	} else if len(reminderWebhookSecrets) == 0 {
+		logger.Warn("PAYMENT_REMINDER_WEBHOOK_SECRET not set, payment reminders will be unsigned")
+	}
+
+	// Alerts on business thresholds, from ALERTS_FILE or alerts.json in
+	// DATA_PATH, evaluated every ALERT_EVALUATION_INTERVAL and POSTed to
+	// ALERT_WEBHOOK_URLS
+	alertInterval := alerting.DefaultInterval
+	if v := os.Getenv("ALERT_EVALUATION_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			alertInterval = d
+		} else {
+			logger.Warnf("Invalid ALERT_EVALUATION_INTERVAL '%s', defaulting to %s", v, alertInterval)
+		}
+	}
+	var alertWebhookURLs []string
+	for _, u := range strings.Split(os.Getenv("ALERT_WEBHOOK_URLS"), ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			alertWebhookURLs = append(alertWebhookURLs, u)
+		}
+	}
+	alertWebhookSecrets := signing.ParseKeys(secretStore.Lookup("ALERT_WEBHOOK_SECRET", ""))
+
+	// Payment gateway callbacks are signed with one of these, comma-separated
+	// while the gateway's secret is rotated
+	gatewayWebhookSecrets := signing.ParseKeys(secretStore.Lookup("PAYMENT_GATEWAY_WEBHOOK_SECRETS", ""))
+	if len(gatewayWebhookSecrets) == 0 {
+		logger.Warn("PAYMENT_GATEWAY_WEBHOOK_SECRETS not set, payment gateway callbacks will be accepted unsigned")
+	}
+
+	// Sanctions screening of payouts: stub, which clears everyone, or ofac,
+	// the SDN list in SANCTIONS_LIST_FILE or sanctions/sdn.csv in DATA_PATH
+	screener := os.Getenv("SANCTIONS_SCREENER")
+	sanctionsListFile := os.Getenv("SANCTIONS_LIST_FILE")
+
+	// How long closed payments stay live; defaults to retention.json in
+	// DATA_PATH. The archival job runs every ARCHIVE_INTERVAL.
+	retentionFile := os.Getenv("RETENTION_FILE")
+	archiveInterval := 24 * time.Hour
+	if v := os.Getenv("ARCHIVE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			archiveInterval = d
+		} else {
+			logger.Warnf("Invalid ARCHIVE_INTERVAL '%s', defaulting to %s", v, archiveInterval)
+		}
+	}
+
+	// Field visibility rules on top of the shape tags; defaults to
+	// response-shaping.json in DATA_PATH
+	shapingFile := os.Getenv("SHAPING_FILE")
 
-	// Register routes
-	router.Handle("/healthz", healthHandler).Methods("GET")
-	router.HandleFunc("/payments", paymentHandler.GetPayments).Methods("GET")
-	router.HandleFunc("/payments/{id}", paymentHandler.GetPaymentByID).Methods("GET")
-	router.HandleFunc("/payments", paymentHandler.CreatePayment).Methods("POST")
-	router.HandleFunc("/payouts", paymentHandler.CreatePayout).Methods("POST")
-	router.HandleFunc("/payments/{id}/process", paymentHandler.ProcessPayment).Methods("PUT")
+	// Persisted state, so changes survive restarts
+	persistDir := os.Getenv("PERSIST_DIR")
+	if persistDir == "" {
+		logger.Warn("PERSIST_DIR not set, changes will be lost on restart")
+	}
+	persistFlushInterval := time.Minute
+	if v := os.Getenv("PERSIST_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			persistFlushInterval = d
+		} else {
+			logger.Warnf("Invalid PERSIST_FLUSH_INTERVAL '%s', defaulting to %s", v, persistFlushInterval)
+		}
+	}
 
-	// Wrap router with CORS
-	handler := corsHandler.Handler(router)
+	// Requests at least this slow are logged as warnings
+	slowRequestThreshold := time.Second
+	if v := os.Getenv("HTTP_SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			slowRequestThreshold = d
+		} else {
+			logger.Warnf("Invalid HTTP_SLOW_REQUEST_THRESHOLD '%s', defaulting to %s", v, slowRequestThreshold)
+		}
+	}
+
+	// Access entries go to the service log unless ACCESS_LOG names a
+	// separate stream: stdout, stderr or a file path
+	accessLog, closeAccessLog, err := telemetry.OpenAccessLog(os.Getenv("ACCESS_LOG"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open access log")
+	}
+	defer closeAccessLog()
+
+	// Assemble the service
+	application, err := app.New(app.Config{
+		DataPath:               dataPath,
+		FeatureAPIKey:          cloudBeesAPIKey,
+		PolicyServiceURL:       policyServiceURL,
+		ClaimsServiceURL:       claimsServiceURL,
+		CustomerServiceURL:     customerServiceURL,
+		JWTSecret:              jwtSecret,
+		DefaultCommissionRate:  commissionRate,
+		BillingPeriodMonths:    billingPeriodMonths,
+		BillingDueDays:         billingDueDays,
+		LateFees:               lateFees,
+		LateFeeInterval:        lateFeeInterval,
+		ReminderOffsets:        reminderOffsets,
+		ReminderInterval:       reminderInterval,
+		ReminderWebhookURLs:    reminderWebhookURLs,
+		ReminderWebhookSecrets: reminderWebhookSecrets,
+		AlertsFile:             os.Getenv("ALERTS_FILE"),
+		AlertInterval:          alertInterval,
+		AlertWebhookURLs:       alertWebhookURLs,
+		AlertWebhookSecrets:    alertWebhookSecrets,
+		BulkMaxPayments:        bulkMaxPayments,
+		BulkConcurrency:        bulkConcurrency,
+		GatewayWebhookSecrets:  gatewayWebhookSecrets,
+		Screener:               screener,
+		SanctionsListFile:      sanctionsListFile,
+		RetentionFile:          retentionFile,
+		ArchiveInterval:        archiveInterval,
+		ShapingFile:            shapingFile,
+		Maintenance:            maintenance.ConfigFromEnv(logger),
+		Faults:                 faults.ConfigFromEnv(logger),
+		PersistDir:             persistDir,
+		PersistFlushInterval:   persistFlushInterval,
+		SlowRequestThreshold:   slowRequestThreshold,
+		AccessLog:              accessLog,
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize service")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      handler,
+		Handler:      application.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Drain requests in flight and stop the service's components in order
+	conns := lifecycle.TrackConnections(server)
+	shutdown := lifecycle.New(logger)
+	application.RegisterShutdown(shutdown, lifecycle.DrainServer(server, conns))
+
 	// Start server in a goroutine
 	go func() {
 		logger.Infof("Server listening on port %s", port)
 		logger.Info("API Endpoints:")
 		logger.Info("  GET  /healthz - Health check")
+		logger.Info("  GET  /labels - Status and type labels in the Accept-Language language")
+		logger.Info("  GET  /metrics - Request latency by route and disputes by status (Prometheus)")
 		logger.Info("  GET  /payments - List all payments")
+		logger.Info("    Query params: claimId, includeArchived (admin/adjuster JWT)")
 		logger.Info("  GET  /payments/{id} - Get payment by ID")
+		logger.Info("    Query params: includeArchived (admin/adjuster JWT)")
 		logger.Info("  POST /payments - Create premium payment")
-		logger.Info("  POST /payouts - Create claim payout")
+		logger.Info("  POST /payouts - Create claim payout (service token with payments:write)")
+		logger.Info("    Note: Claims need an accepted settlement offer covering the payout")
+		logger.Info("  POST /refunds - Refund unearned premium to a policyholder")
 		logger.Info("  PUT  /payments/{id}/process - Process payment")
+		logger.Info("  PUT  /payments/{id}/fail - Mark a processing or blocked payment as failed (admin/adjuster JWT)")
+		logger.Info("  PUT  /payments/{id}/release - Release a payout blocked by sanctions screening (admin JWT)")
+		logger.Info("  PUT  /payments/{id}/refund - Mark a completed payment as refunded (admin/adjuster JWT)")
+		logger.Info("  POST /payments/{id}/disputes - Open a dispute of a completed premium (admin/adjuster JWT)")
+		logger.Info("  GET  /payments/{id}/disputes - Disputes of a payment (admin/adjuster JWT)")
+		logger.Info("  GET  /policies/{id}/balance - Premium invoiced, paid and overdue on a policy")
+		logger.Info("  POST /webhooks/gateway - Payment gateway callbacks (signed)")
+		logger.Info("  GET  /agents - List agents (admin JWT)")
+		logger.Info("  POST /agents - Register agent (admin JWT)")
+		logger.Info("  GET  /agents/{id} - Get agent by ID (admin JWT)")
+		logger.Info("  PUT  /agents/{id} - Update agent details, rate or status (admin JWT)")
+		logger.Info("  DELETE /agents/{id} - Deactivate agent (admin JWT)")
+		logger.Info("  GET  /agents/{id}/commissions - Monthly commission statement (admin JWT)")
+		logger.Info("    Query params: month (YYYY-MM)")
+		logger.Info("  GET  /admin/consistency-report - Cross-service reference check (admin/adjuster JWT)")
+		logger.Info("  GET  /admin/flags/impressions/summary - Feature flag exposure by variant (admin/adjuster JWT)")
+		logger.Info("    Query params: flag")
+		logger.Info("  GET  /admin/reports/loss-ratio - Loss ratio per policy type and month (admin/adjuster JWT)")
+		logger.Info("    Query params: from, to (YYYY-MM)")
+		logger.Info("  GET  /admin/reports/loss-ratio/export - Loss ratio as CSV (admin/adjuster JWT)")
+		logger.Info("  POST /admin/payments/archive - Archive payments past retention (admin/adjuster JWT)")
+		logger.Info("  GET  /admin/disputes - List disputes (admin/adjuster JWT)")
+		logger.Info("    Query params: status (open, won, lost)")
+		logger.Info("  GET  /admin/disputes/metrics - Dispute counts, amounts, win and dispute rates (admin/adjuster JWT)")
+		logger.Info("  GET  /admin/disputes/{id} - Get dispute by ID (admin/adjuster JWT)")
+		logger.Info("  PUT  /admin/disputes/{id}/resolve - Record a dispute as won or lost (admin/adjuster JWT)")
+		logger.Info("  POST /admin/late-fees/run - Charge the late fees due now on overdue invoices (admin/adjuster JWT)")
+		logger.Info("  GET  /admin/reminders - Payment reminders sent, opted out or failed (admin/adjuster JWT)")
+		logger.Info("    Query params: policyId, status")
+		logger.Info("  POST /admin/reminders/run - Send the payment reminders due now (admin/adjuster JWT)")
+		logger.Info("  GET  /admin/maintenance - Maintenance mode (admin JWT)")
+		logger.Info("  PUT  /admin/maintenance - Turn maintenance mode on or off (admin JWT)")
+		logger.Info("  GET  /admin/faults - Fault injection rules, when enabled (admin JWT)")
+		logger.Info("  PUT  /admin/faults - Replace the fault injection rules (admin JWT)")
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Fatal("Server failed to start")
@@ -128,14 +402,16 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	open := conns.Connections()
+	logger.WithFields(logrus.Fields{
+		"active":   open.Active,
+		"idle":     open.Idle,
+		"hijacked": open.Hijacked,
+	}).Info("Shutting down server...")
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
+	if err := shutdown.Shutdown(context.Background()).Err(); err != nil {
+		logger.WithError(err).Error("Shutdown incomplete")
+		return
 	}
 
 	logger.Info("Server stopped gracefully")
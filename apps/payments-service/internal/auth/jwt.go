@@ -2,6 +2,7 @@ package auth
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,17 +17,37 @@ var (
 type Claims struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
+	Role   string `json:"role,omitempty"`
+	// Scope is the space-separated scopes of a service token, such as
+	// "payments:write"; user tokens have none
+	Scope string `json:"scope,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// HasScope reports whether the token was granted scope
+func (c *Claims) HasScope(scope string) bool {
+	for _, granted := range strings.Fields(c.Scope) {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // JWTManager manages JWT token creation and validation
 type JWTManager struct {
-	secretKey     string
+	secretKey     func() string
 	tokenDuration time.Duration
 }
 
 // NewJWTManager creates a new JWT manager
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+	return NewRotatingJWTManager(func() string { return secretKey }, tokenDuration)
+}
+
+// NewRotatingJWTManager creates a JWT manager that looks its secret up for
+// every token, so a rotated secret is used without a restart
+func NewRotatingJWTManager(secretKey func() string, tokenDuration time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
 		tokenDuration: tokenDuration,
@@ -35,10 +56,34 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 
 // Generate creates a new JWT token for a user
 func (manager *JWTManager) Generate(userID, email string) (string, error) {
+	return manager.GenerateWithRole(userID, email, "")
+}
+
+// GenerateWithRole creates a new JWT token for a user carrying a role claim
+func (manager *JWTManager) GenerateWithRole(userID, email, role string) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(manager.tokenDuration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(manager.secretKey()))
+}
+
+// GenerateForClient creates a service token for clientID carrying scopes,
+// as claims-service issues them with the client credentials grant
+func (manager *JWTManager) GenerateForClient(clientID string, scopes []string) (string, error) {
+	claims := Claims{
+		UserID: clientID,
+		Scope:  strings.Join(scopes, " "),
 		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   clientID,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(manager.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -46,7 +91,7 @@ func (manager *JWTManager) Generate(userID, email string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(manager.secretKey))
+	return token.SignedString([]byte(manager.secretKey()))
 }
 
 // Verify validates a JWT token and returns the claims
@@ -59,7 +104,7 @@ func (manager *JWTManager) Verify(tokenString string) (*Claims, error) {
 			if !ok {
 				return nil, ErrInvalidToken
 			}
-			return []byte(manager.secretKey), nil
+			return []byte(manager.secretKey()), nil
 		},
 	)
 
@@ -23,8 +23,8 @@ func TestNewJWTManager(t *testing.T) {
 			if manager == nil {
 				t.Error("NewJWTManager returned nil")
 			}
-			if manager.secretKey != tt.secretKey {
-				t.Errorf("Secret key mismatch: got %v, want %v", manager.secretKey, tt.secretKey)
+			if manager.secretKey() != tt.secretKey {
+				t.Errorf("Secret key mismatch: got %v, want %v", manager.secretKey(), tt.secretKey)
 			}
 			if manager.tokenDuration != tt.tokenDuration {
 				t.Errorf("Token duration mismatch: got %v, want %v", manager.tokenDuration, tt.tokenDuration)
@@ -141,6 +141,24 @@ func TestJWTManagerVerifyDifferentSecret(t *testing.T) {
 	}
 }
 
+func TestRotatingJWTManagerFollowsTheSecret(t *testing.T) {
+	secret := "secret1"
+	manager := NewRotatingJWTManager(func() string { return secret }, time.Hour)
+
+	before, err := manager.Generate("user-001", "user@example.com")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	secret = "secret2"
+	if _, err := manager.Verify(before); err == nil {
+		t.Error("Expected a token signed with the old secret to be refused")
+	}
+	after, _ := manager.Generate("user-001", "user@example.com")
+	if _, err := NewJWTManager("secret2", time.Hour).Verify(after); err != nil {
+		t.Errorf("Expected new tokens to be signed with the new secret, got %v", err)
+	}
+}
+
 func TestJWTTokenLifecycle(t *testing.T) {
 	manager := NewJWTManager("test-secret", 1*time.Hour)
 
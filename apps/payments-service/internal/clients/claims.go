@@ -0,0 +1,93 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Claim is the subset of a claims-service claim the payments service reads
+type Claim struct {
+	ID         string  `json:"id"`
+	PolicyID   string  `json:"policyId"`
+	CustomerID string  `json:"customerId"`
+	Status     string  `json:"status"`
+	Amount     float64 `json:"amount"`
+	Offers     []Offer `json:"offers,omitempty"`
+}
+
+// Offer is a settlement offer on a claim. Payouts are capped at the amount
+// of the offer the customer accepted.
+type Offer struct {
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+	Status string  `json:"status"` // pending, accepted, rejected, expired or withdrawn
+}
+
+// AcceptedOffer returns the claim's accepted settlement offer, or nil
+func (c *Claim) AcceptedOffer() *Offer {
+	for i := range c.Offers {
+		if c.Offers[i].Status == "accepted" {
+			return &c.Offers[i]
+		}
+	}
+	return nil
+}
+
+// TokenSource returns a bearer token for staff-only reads
+type TokenSource func() (string, error)
+
+// ClaimsClient calls claims-service. Claims are only readable by their
+// customer or by staff, so requests carry a staff token from token.
+type ClaimsClient struct {
+	baseURL    string
+	token      TokenSource
+	httpClient *http.Client
+}
+
+// NewClaimsClient creates a new claims-service client
+func NewClaimsClient(baseURL string, token TokenSource, timeout time.Duration) *ClaimsClient {
+	return &ClaimsClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetClaim fetches the claim a payout settles. Claims the retention policy
+// archived are still found, so old payouts keep resolving their claim.
+func (c *ClaimsClient) GetClaim(ctx context.Context, claimID string) (*Claim, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign claims-service token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/claims/"+url.PathEscape(claimID)+"?includeArchived=true", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("claims-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("claim not found")
+	default:
+		return nil, fmt.Errorf("claims-service returned status %d", resp.StatusCode)
+	}
+
+	var claim Claim
+	if err := json.NewDecoder(resp.Body).Decode(&claim); err != nil {
+		return nil, fmt.Errorf("failed to decode claim: %w", err)
+	}
+	return &claim, nil
+}
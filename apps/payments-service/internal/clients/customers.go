@@ -0,0 +1,111 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Customer is the subset of a customer-service customer the payments service reads
+type Customer struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	RiskScore int    `json:"riskScore"`
+	Address   struct {
+		Country string `json:"country"`
+	} `json:"address"`
+	KYCStatus string `json:"kycStatus,omitempty"`
+}
+
+// CustomerClient calls customer-service
+type CustomerClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewCustomerClient creates a new customer-service client
+func NewCustomerClient(baseURL string, timeout time.Duration) *CustomerClient {
+	return &CustomerClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetCustomer fetches a customer profile
+func (c *CustomerClient) GetCustomer(ctx context.Context, customerID string) (*Customer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/customers/"+url.PathEscape(customerID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("customer-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("customer not found")
+	default:
+		return nil, fmt.Errorf("customer-service returned status %d", resp.StatusCode)
+	}
+
+	var customer Customer
+	if err := json.NewDecoder(resp.Body).Decode(&customer); err != nil {
+		return nil, fmt.Errorf("failed to decode customer: %w", err)
+	}
+	return &customer, nil
+}
+
+// Preferences is the subset of a customer's communication preferences the
+// payments service reads
+type Preferences struct {
+	PaymentRemindersOptOut bool `json:"paymentRemindersOptOut"`
+}
+
+// GetPreferences fetches a customer's communication preferences
+func (c *CustomerClient) GetPreferences(ctx context.Context, customerID string) (*Preferences, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/customers/"+url.PathEscape(customerID)+"/preferences", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("customer-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("customer not found")
+	default:
+		return nil, fmt.Errorf("customer-service returned status %d", resp.StatusCode)
+	}
+
+	var preferences Preferences
+	if err := json.NewDecoder(resp.Body).Decode(&preferences); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences: %w", err)
+	}
+	return &preferences, nil
+}
+
+// RemindersOptedOut reports whether the customer has opted out of premium
+// payment reminders
+func (c *CustomerClient) RemindersOptedOut(ctx context.Context, customerID string) (bool, error) {
+	preferences, err := c.GetPreferences(ctx, customerID)
+	if err != nil {
+		return false, err
+	}
+	return preferences.PaymentRemindersOptOut, nil
+}
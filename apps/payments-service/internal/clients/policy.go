@@ -0,0 +1,125 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Policy is the subset of a policy-service policy the payments service reads
+type Policy struct {
+	ID           string    `json:"id"`
+	CustomerID   string    `json:"customerId"`
+	AgentID      string    `json:"agentId,omitempty"`
+	PolicyNumber string    `json:"policyNumber"`
+	Type         string    `json:"type"`
+	Status       string    `json:"status"`
+	Premium      float64   `json:"premium"` // for a year's cover
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate"`
+	// Cancellation is set once the policy is cancelled
+	Cancellation *PolicyCancellation `json:"cancellation,omitempty"`
+}
+
+// PolicyCancellation is the subset of a policy's cancellation the payments
+// service reads
+type PolicyCancellation struct {
+	EffectiveDate time.Time `json:"effectiveDate"`
+}
+
+// PolicyClient calls policy-service. Policies are read on behalf of their
+// customer; lapsing one is for staff, so those requests carry a staff token
+// from token.
+type PolicyClient struct {
+	baseURL    string
+	token      TokenSource
+	httpClient *http.Client
+}
+
+// NewPolicyClient creates a new policy-service client
+func NewPolicyClient(baseURL string, token TokenSource, timeout time.Duration) *PolicyClient {
+	return &PolicyClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetPolicy fetches a policy on behalf of the customer paying its premium.
+// policy-service only returns policies the caller owns, so a payer
+// referencing someone else's policy gets "unauthorized".
+func (c *PolicyClient) GetPolicy(ctx context.Context, policyID, customerID string) (*Policy, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/policies/"+url.PathEscape(policyID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("policy-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return nil, fmt.Errorf("unauthorized")
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("policy not found")
+	default:
+		return nil, fmt.Errorf("policy-service returned status %d", resp.StatusCode)
+	}
+
+	var policy Policy
+	if err := json.NewDecoder(resp.Body).Decode(&policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// LapsePolicy lapses a policy for non-payment with reason, e.g. after a
+// chargeback on its premium was lost. A policy no longer in force, which
+// cannot lapse, gets an error starting "policy cannot lapse".
+func (c *PolicyClient) LapsePolicy(ctx context.Context, policyID, reason string) error {
+	token, err := c.token()
+	if err != nil {
+		return fmt.Errorf("failed to sign policy-service token: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"reason": reason})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/admin/policies/"+url.PathEscape(policyID)+"/lapse", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("policy-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("policy not found")
+	case http.StatusConflict:
+		var refusal struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&refusal)
+		return fmt.Errorf("policy cannot lapse: %s", refusal.Message)
+	default:
+		return fmt.Errorf("policy-service returned status %d", resp.StatusCode)
+	}
+}
@@ -5,14 +5,23 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
 	"github.com/sirupsen/logrus"
 )
 
+// KeyInstantPayouts is the flag key, also used to salt rollout buckets
+const KeyInstantPayouts = "payments.instantPayouts"
+
 // Flags holds all feature flags for the application
 type Flags struct {
-	instantPayouts bool
-	mu             sync.RWMutex
-	logger         *logrus.Logger
+	instantPayouts        bool
+	instantPayoutsRollout *targeting.Rollout // when set, decides per customer
+	instantPayoutsKYC     bool
+	mu                    sync.RWMutex
+	logger                *logrus.Logger
+
+	impressions     *targeting.Recorder
+	stopImpressions func()
 }
 
 var flags *Flags
@@ -37,8 +46,30 @@ func Initialize(apiKey string, logger *logrus.Logger) (*Flags, error) {
 		}
 	}
 
+	// FEATURE_INSTANT_PAYOUTS_ROLLOUT (optional) - JSON targeting, e.g. {"percentage": 5}
+	if rolloutStr := os.Getenv("FEATURE_INSTANT_PAYOUTS_ROLLOUT"); rolloutStr != "" {
+		rollout, err := targeting.Parse([]byte(rolloutStr))
+		if err != nil {
+			logger.WithError(err).Warn("Invalid FEATURE_INSTANT_PAYOUTS_ROLLOUT, ignoring")
+		} else {
+			flags.instantPayoutsRollout = rollout
+		}
+	}
+
+	// payments.instantPayoutsRequireKYC (default: false) - only pay out
+	// instantly to customers whose identity documents have been verified
+	if v := os.Getenv("FEATURE_INSTANT_PAYOUTS_REQUIRE_KYC"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			flags.instantPayoutsKYC = enabled
+		}
+	}
+
+	flags.impressions, flags.stopImpressions = startImpressions(logger)
+
 	logger.WithFields(logrus.Fields{
-		"instantPayouts": flags.instantPayouts,
+		"instantPayouts":        flags.instantPayouts,
+		"instantPayoutsRollout": flags.instantPayoutsRollout != nil,
+		"instantPayoutsKYC":     flags.instantPayoutsKYC,
 	}).Info("Feature flags initialized")
 
 	if apiKey != "" && apiKey != "dev-mode" {
@@ -53,17 +84,56 @@ func GetFlags() *Flags {
 	return flags
 }
 
-// IsInstantPayoutsEnabled returns whether instant payouts are enabled
+// IsInstantPayoutsEnabled returns whether instant payouts are enabled for
+// everyone. During a rollout this is the rollout's default.
 func (f *Flags) IsInstantPayoutsEnabled() bool {
 	if f == nil {
 		return false
 	}
 	f.mu.RLock()
 	defer f.mu.RUnlock()
+	if f.instantPayoutsRollout != nil {
+		return f.instantPayoutsRollout.Default
+	}
 	return f.instantPayouts
 }
 
-// SetInstantPayouts sets the instant payouts flag (for testing/admin purposes)
+// IsInstantPayoutsEnabledFor returns whether instant payouts are enabled for
+// the customer described by ctx, and records the impression
+func (f *Flags) IsInstantPayoutsEnabledFor(ctx targeting.Context) bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	enabled := f.instantPayouts
+	if f.instantPayoutsRollout != nil {
+		enabled = f.instantPayoutsRollout.Enabled(KeyInstantPayouts, ctx)
+	}
+	f.mu.RUnlock()
+
+	f.impressions.Record(targeting.Impression{
+		Flag:       KeyInstantPayouts,
+		Variant:    targeting.BoolVariant(enabled),
+		UserID:     ctx.UserID,
+		TenantID:   ctx.TenantID,
+		Attributes: ctx.Attributes,
+	})
+	return enabled
+}
+
+// InstantPayoutsRollout returns the active rollout, or nil when the flag is
+// all-or-nothing
+func (f *Flags) InstantPayoutsRollout() *targeting.Rollout {
+	if f == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.instantPayoutsRollout
+}
+
+// SetInstantPayouts sets the instant payouts flag for everyone, ending any
+// rollout (for testing/admin purposes, and as the rollback switch)
 func (f *Flags) SetInstantPayouts(enabled bool) {
 	if f == nil {
 		return
@@ -71,12 +141,68 @@ func (f *Flags) SetInstantPayouts(enabled bool) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 	f.instantPayouts = enabled
+	f.instantPayoutsRollout = nil
 	f.logger.WithField("instantPayouts", enabled).Info("Feature flag updated")
 }
 
+// SetInstantPayoutsRollout targets instant payouts to part of the customer
+// base (for testing/admin purposes)
+func (f *Flags) SetInstantPayoutsRollout(rollout *targeting.Rollout) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.instantPayoutsRollout = rollout
+	f.logger.WithFields(logrus.Fields{
+		"percentage": rollout.Percentage,
+		"rules":      len(rollout.Rules),
+		"tenants":    len(rollout.Tenants),
+	}).Info("Feature flag rollout updated")
+}
+
+// InstantPayoutsRequireKYC returns whether instant payouts are limited to
+// customers with verified KYC; everyone else is queued for batch processing
+func (f *Flags) InstantPayoutsRequireKYC() bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.instantPayoutsKYC
+}
+
+// SetInstantPayoutsRequireKYC sets the instant payouts KYC requirement (for
+// testing/admin purposes)
+func (f *Flags) SetInstantPayoutsRequireKYC(enabled bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.instantPayoutsKYC = enabled
+	f.logger.WithField("instantPayoutsKYC", enabled).Info("Feature flag updated")
+}
+
+// EnabledFlags returns the keys of the boolean flags that are on, for the
+// health check. Instant payouts count as on while a rollout is running.
+func (f *Flags) EnabledFlags() []string {
+	var enabled []string
+	if f.IsInstantPayoutsEnabled() || f.InstantPayoutsRollout() != nil {
+		enabled = append(enabled, KeyInstantPayouts)
+	}
+	if f.InstantPayoutsRequireKYC() {
+		enabled = append(enabled, "payments.instantPayoutsRequireKYC")
+	}
+	return enabled
+}
+
 // Shutdown gracefully shuts down the feature management system
 func Shutdown() {
 	if flags != nil {
+		if flags.stopImpressions != nil {
+			flags.stopImpressions()
+		}
 		flags.logger.Info("Feature management shutdown complete")
 	}
 }
@@ -129,6 +255,14 @@ To integrate with CloudBees Feature Management (Rox SDK), follow these steps:
        return flags, nil
    }
 
+   Percentage rollouts and targeting are configured in the CloudBees
+   dashboard instead of FEATURE_INSTANT_PAYOUTS_ROLLOUT. Pass the customer
+   as Rox context so the dashboard's rules see the same attributes:
+       ctx := context.NewContext(map[string]interface{}{
+           "userId": c.UserID, "tenantId": c.TenantID, "country": c.Attributes["country"],
+       })
+       return f.InstantPayouts.IsEnabled(ctx)
+
 5. Update IsInstantPayoutsEnabled:
    func (f *Flags) IsInstantPayoutsEnabled() bool {
        if f == nil || f.InstantPayouts == nil {
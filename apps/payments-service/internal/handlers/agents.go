@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// AgentService is the business logic the agent handler depends on.
+// *services.AgentService is the production implementation.
+type AgentService interface {
+	GetAllAgents() []*models.Agent
+	GetAgentByID(agentID string) (*models.Agent, error)
+	CreateAgent(req *models.CreateAgentRequest) (*models.Agent, error)
+	UpdateAgent(agentID string, req *models.UpdateAgentRequest) (*models.Agent, error)
+	DeactivateAgent(agentID string) (*models.Agent, error)
+	GetCommissionStatement(agentID, month string) (*models.CommissionStatement, error)
+}
+
+var _ AgentService = (*services.AgentService)(nil)
+
+// AgentHandler handles agent and commission HTTP requests
+type AgentHandler struct {
+	service AgentService
+	logger  *logrus.Logger
+}
+
+// NewAgentHandler creates a new agent handler
+func NewAgentHandler(service AgentService, logger *logrus.Logger) *AgentHandler {
+	return &AgentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetAgents handles GET /agents
+func (h *AgentHandler) GetAgents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetAllAgents())
+}
+
+// GetAgentByID handles GET /agents/{id}
+func (h *AgentHandler) GetAgentByID(w http.ResponseWriter, r *http.Request) {
+	agentID := mux.Vars(r)["id"]
+
+	agent, err := h.service.GetAgentByID(agentID)
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "Agent not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agent)
+}
+
+// CreateAgent handles POST /agents
+func (h *AgentHandler) CreateAgent(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode agent request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	agent, err := h.service.CreateAgent(&req)
+	if err != nil {
+		h.respondAgentError(w, err, "Failed to create agent")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(agent)
+}
+
+// UpdateAgent handles PUT /agents/{id}
+func (h *AgentHandler) UpdateAgent(w http.ResponseWriter, r *http.Request) {
+	agentID := mux.Vars(r)["id"]
+
+	var req models.UpdateAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode agent request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	agent, err := h.service.UpdateAgent(agentID, &req)
+	if err != nil {
+		h.respondAgentError(w, err, "Failed to update agent")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agent)
+}
+
+// DeactivateAgent handles DELETE /agents/{id}. Agents are deactivated
+// rather than removed so their commission history remains.
+func (h *AgentHandler) DeactivateAgent(w http.ResponseWriter, r *http.Request) {
+	agentID := mux.Vars(r)["id"]
+
+	agent, err := h.service.DeactivateAgent(agentID)
+	if err != nil {
+		h.respondAgentError(w, err, "Failed to deactivate agent")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agent)
+}
+
+// GetCommissions handles GET /agents/{id}/commissions?month=YYYY-MM. The
+// month defaults to the current one.
+func (h *AgentHandler) GetCommissions(w http.ResponseWriter, r *http.Request) {
+	agentID := mux.Vars(r)["id"]
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+
+	statement, err := h.service.GetCommissionStatement(agentID, month)
+	if err != nil {
+		h.logger.WithError(err).WithField("agentId", agentID).Error("Failed to get commissions")
+		switch err.Error() {
+		case "agent not found":
+			h.respondError(w, http.StatusNotFound, err.Error())
+		case "month must be in YYYY-MM format":
+			h.respondError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondError(w, http.StatusInternalServerError, "Failed to get commissions")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+// respondAgentError maps agent service errors to HTTP statuses
+func (h *AgentHandler) respondAgentError(w http.ResponseWriter, err error, message string) {
+	h.logger.WithError(err).Error(message)
+
+	var validation *models.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	case err.Error() == "agent not found":
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case err.Error() == "license number already registered":
+		h.respondError(w, http.StatusConflict, err.Error())
+	default:
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// respondError sends an error response
+func (h *AgentHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// Archiver moves payments past retention to the archive.
+// *services.ArchiveService is the production implementation.
+type Archiver interface {
+	Archive(now time.Time) (*models.ArchiveResult, error)
+}
+
+var _ Archiver = (*services.ArchiveService)(nil)
+
+// ArchiveHandler runs the payment archival job on demand
+type ArchiveHandler struct {
+	archiver Archiver
+	logger   *logrus.Logger
+}
+
+// NewArchiveHandler creates a new payment archival handler
+func NewArchiveHandler(archiver Archiver, logger *logrus.Logger) *ArchiveHandler {
+	return &ArchiveHandler{
+		archiver: archiver,
+		logger:   logger,
+	}
+}
+
+// ServeHTTP handles POST /admin/payments/archive
+func (h *ArchiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result, err := h.archiver.Archive(time.Now())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to archive payments")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Internal server error"})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
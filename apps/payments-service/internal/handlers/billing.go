@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// BillingService is the business logic the billing handler depends on.
+// *services.BillingService is the production implementation.
+type BillingService interface {
+	GetPolicyBalance(ctx context.Context, policyID, customerID string) (*models.PolicyBalance, error)
+	ChargeLateFees(ctx context.Context, now time.Time) (*models.LateFeeRun, error)
+}
+
+var _ BillingService = (*services.BillingService)(nil)
+
+// BillingHandler handles premium billing HTTP requests
+type BillingHandler struct {
+	service BillingService
+	logger  *logrus.Logger
+}
+
+// NewBillingHandler creates a new billing handler
+func NewBillingHandler(service BillingService, logger *logrus.Logger) *BillingHandler {
+	return &BillingHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetPolicyBalance handles GET /policies/{id}/balance for the customer
+// holding the policy
+func (h *BillingHandler) GetPolicyBalance(w http.ResponseWriter, r *http.Request) {
+	policyID := mux.Vars(r)["id"]
+
+	balance, err := h.service.GetPolicyBalance(r.Context(), policyID, middleware.GetUserID(r))
+	if err != nil {
+		switch {
+		case err.Error() == "policy not found":
+			h.respondError(w, http.StatusNotFound, "Policy not found")
+		case err.Error() == "unauthorized":
+			h.respondError(w, http.StatusForbidden, "Access denied")
+		case err.Error() == "policy service not configured",
+			strings.HasPrefix(err.Error(), "policy lookup failed"):
+			h.logger.WithError(err).WithField("policyId", policyID).Error("Cannot look up policy for balance")
+			h.respondError(w, http.StatusServiceUnavailable, "Policy service unavailable")
+		default:
+			h.logger.WithError(err).WithField("policyId", policyID).Error("Failed to get policy balance")
+			h.respondError(w, http.StatusInternalServerError, "Failed to get policy balance")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balance)
+}
+
+// ChargeLateFees handles POST /admin/late-fees/run - charges the late fees
+// due now without waiting for the next scheduled run
+func (h *BillingHandler) ChargeLateFees(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.ChargeLateFees(r.Context(), time.Now())
+	if err != nil {
+		if err.Error() == "policy service not configured" {
+			h.respondError(w, http.StatusServiceUnavailable, "Policy service unavailable")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to charge late fees")
+		h.respondError(w, http.StatusInternalServerError, "Failed to charge late fees")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// respondError sends an error response
+func (h *BillingHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
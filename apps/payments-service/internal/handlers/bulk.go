@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/sirupsen/logrus"
+)
+
+// BulkPaymentCreator creates the premium payments of a batch.
+// *services.BulkPaymentService is the production implementation.
+type BulkPaymentCreator interface {
+	CheckBatch(items []models.BulkPaymentItem) error
+	CreatePayments(ctx context.Context, items []models.BulkPaymentItem, emit func(models.BulkPaymentResult)) models.BulkPaymentSummary
+}
+
+var _ BulkPaymentCreator = (*services.BulkPaymentService)(nil)
+
+// BulkPaymentHandler handles batches of premium payments
+type BulkPaymentHandler struct {
+	creator BulkPaymentCreator
+	logger  *logrus.Logger
+}
+
+// NewBulkPaymentHandler creates a new bulk payment handler
+func NewBulkPaymentHandler(creator BulkPaymentCreator, logger *logrus.Logger) *BulkPaymentHandler {
+	return &BulkPaymentHandler{
+		creator: creator,
+		logger:  logger,
+	}
+}
+
+// CreatePayments handles POST /payments/bulk. The response streams one
+// JSON line per item as it completes, in completion order, and a last line
+// with the batch's summary.
+func (h *BulkPaymentHandler) CreatePayments(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode bulk payment request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := h.creator.CheckBatch(req.Payments); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"payments":  len(req.Payments),
+		"createdBy": middleware.GetUserID(r),
+	}).Info("Creating bulk payments")
+
+	rc := http.NewResponseController(w)
+
+	// Large batches outlive the server's write timeout, so clear the deadline
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WithError(err).Debug("Unable to clear write deadline for bulk payments")
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	send := func(line interface{}) {
+		if err := encoder.Encode(line); err != nil {
+			h.logger.WithError(err).Warn("Failed to stream bulk payment result")
+			return
+		}
+		rc.Flush()
+	}
+
+	summary := h.creator.CreatePayments(r.Context(), req.Payments, func(result models.BulkPaymentResult) {
+		if result.Payment != nil {
+			result.Payment = shaping.Apply(r, result.Payment)
+		}
+		send(result)
+	})
+	send(map[string]models.BulkPaymentSummary{"summary": summary})
+}
+
+// respondError sends an error response
+func (h *BulkPaymentHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+	"github.com/sirupsen/logrus"
+)
+
+// TestClaimsServiceContract verifies payments-service still satisfies what
+// claims-service expects. The consumer half lives in claims-service.
+func TestClaimsServiceContract(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	// Provider states are checked against the same seed data the service loaded
+	repo, err := repository.NewRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to load seed data: %v", err)
+	}
+
+	// Payouts are created with claims-service's service token; the contract
+	// covers the payload
+	contracts.VerifyProvider(t, asClaimsService(t, application.Handler), contracts.MustLoad("claims-service", "payments-service"), contracts.StateHandlers{
+		"payout pay-002 exists for claim claim-001": func() error {
+			payment, err := repo.GetPaymentByID("pay-002")
+			if err != nil {
+				return err
+			}
+			if payment.ClaimID != "claim-001" {
+				return fmt.Errorf("pay-002 belongs to claim %s", payment.ClaimID)
+			}
+			return nil
+		},
+		"payment pay-999 does not exist": func() error {
+			if _, err := repo.GetPaymentByID("pay-999"); err == nil {
+				return fmt.Errorf("pay-999 exists in seed data")
+			}
+			return nil
+		},
+	})
+}
+
+// TestPolicyServiceContract verifies payments-service still accepts the
+// refunds policy-service requests on cancellation
+func TestPolicyServiceContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	contracts.VerifyProvider(t, application.Handler, contracts.MustLoad("policy-service", "payments-service"), nil)
+}
+
+// asClaimsService sends every request to handler with claims-service's
+// service token
+func asClaimsService(t *testing.T, handler http.Handler) http.Handler {
+	t.Helper()
+	token, err := auth.NewJWTManager("test-secret", time.Hour).GenerateForClient("claims-service", []string{"payments:write"})
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(w, r)
+	})
+}
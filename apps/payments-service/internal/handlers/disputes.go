@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// DisputeService is the business logic the dispute handler depends on.
+// *services.DisputeService is the production implementation.
+type DisputeService interface {
+	OpenDispute(paymentID string, req *models.OpenDisputeRequest, openedBy string) (*models.Dispute, error)
+	ResolveDispute(ctx context.Context, disputeID string, req *models.ResolveDisputeRequest, resolvedBy string) (*models.Dispute, error)
+	GetDispute(disputeID string) (*models.Dispute, error)
+	GetDisputes(status models.DisputeStatus) []*models.Dispute
+	GetPaymentDisputes(paymentID string) ([]*models.Dispute, error)
+	Metrics() (*models.DisputeMetrics, error)
+}
+
+var _ DisputeService = (*services.DisputeService)(nil)
+
+// DisputeHandler handles payment dispute HTTP requests
+type DisputeHandler struct {
+	service DisputeService
+	logger  *logrus.Logger
+}
+
+// NewDisputeHandler creates a new dispute handler
+func NewDisputeHandler(service DisputeService, logger *logrus.Logger) *DisputeHandler {
+	return &DisputeHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// OpenDispute handles POST /payments/{id}/disputes - records a dispute the
+// payer raised against a completed premium and provisionally reverses it
+func (h *DisputeHandler) OpenDispute(w http.ResponseWriter, r *http.Request) {
+	paymentID := mux.Vars(r)["id"]
+
+	var req models.OpenDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode dispute request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	dispute, err := h.service.OpenDispute(paymentID, &req, middleware.GetUserID(r))
+	if err != nil {
+		h.respondDisputeError(w, err, "Failed to open dispute")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// GetPaymentDisputes handles GET /payments/{id}/disputes
+func (h *DisputeHandler) GetPaymentDisputes(w http.ResponseWriter, r *http.Request) {
+	disputes, err := h.service.GetPaymentDisputes(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondDisputeError(w, err, "Failed to get disputes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(disputes)
+}
+
+// GetDisputes handles GET /admin/disputes. The status query parameter
+// narrows the list to open, won or lost disputes.
+func (h *DisputeHandler) GetDisputes(w http.ResponseWriter, r *http.Request) {
+	status := models.DisputeStatus(r.URL.Query().Get("status"))
+	switch status {
+	case "", models.DisputeStatusOpen, models.DisputeStatusWon, models.DisputeStatusLost:
+	default:
+		h.respondError(w, http.StatusBadRequest, "status must be open, won or lost")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetDisputes(status))
+}
+
+// GetDispute handles GET /admin/disputes/{id}
+func (h *DisputeHandler) GetDispute(w http.ResponseWriter, r *http.Request) {
+	dispute, err := h.service.GetDispute(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "Dispute not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// ResolveDispute handles PUT /admin/disputes/{id}/resolve - records
+// whether the dispute was won or lost. Losing it charges the premium back
+// and lapses the policy.
+func (h *DisputeHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	var req models.ResolveDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode dispute resolution")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	dispute, err := h.service.ResolveDispute(r.Context(), mux.Vars(r)["id"], &req, middleware.GetUserID(r))
+	if err != nil {
+		h.respondDisputeError(w, err, "Failed to resolve dispute")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// GetMetrics handles GET /admin/disputes/metrics
+func (h *DisputeHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := h.service.Metrics()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build dispute metrics")
+		h.respondError(w, http.StatusInternalServerError, "Failed to build dispute metrics")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(metrics)
+}
+
+// respondDisputeError maps dispute service errors to HTTP statuses: 400 for
+// an invalid request, 404 for an unknown payment or dispute and 409 for a
+// dispute already resolved or a change the payment lifecycle refuses
+func (h *DisputeHandler) respondDisputeError(w http.ResponseWriter, err error, message string) {
+	h.logger.WithError(err).Error(message)
+
+	var validation *models.ValidationError
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.As(err, &validation):
+		h.respondError(w, http.StatusBadRequest, err.Error())
+	case err.Error() == "payment not found", err.Error() == "dispute not found":
+		h.respondError(w, http.StatusNotFound, err.Error())
+	case err.Error() == "dispute already resolved", errors.As(err, &transitionErr):
+		h.respondError(w, http.StatusConflict, err.Error())
+	default:
+		h.respondError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// respondError sends an error response
+func (h *DisputeHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
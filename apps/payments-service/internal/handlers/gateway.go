@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// GatewayEvents applies payment gateway callbacks.
+// *services.PaymentService is the production implementation.
+type GatewayEvents interface {
+	ApplyGatewayEvent(evt *models.GatewayEvent) (*models.Payment, error)
+}
+
+var _ GatewayEvents = (*services.PaymentService)(nil)
+
+// GatewayHandler receives the payment gateway's callbacks. The route
+// verifies their signatures before they reach it.
+type GatewayHandler struct {
+	events GatewayEvents
+	logger *logrus.Logger
+}
+
+// NewGatewayHandler creates a new gateway handler
+func NewGatewayHandler(events GatewayEvents, logger *logrus.Logger) *GatewayHandler {
+	return &GatewayHandler{
+		events: events,
+		logger: logger,
+	}
+}
+
+// ReceiveEvent handles POST /webhooks/gateway - a payment's outcome reported
+// by the payment gateway. Answers other than 2xx make the gateway deliver
+// the event again, so only events that can never apply are refused.
+func (h *GatewayHandler) ReceiveEvent(w http.ResponseWriter, r *http.Request) {
+	var evt models.GatewayEvent
+	if err := json.NewDecoder(r.Body).Decode(&evt); err != nil {
+		h.logger.WithError(err).Warn("Failed to decode gateway event")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if err := evt.Validate(); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payment, err := h.events.ApplyGatewayEvent(&evt)
+	if err != nil {
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"eventId":   evt.ID,
+			"paymentId": evt.PaymentID,
+		}).Warn("Refused payment gateway event")
+		var transitionErr *lifecycle.TransitionError
+		switch {
+		case err.Error() == "payment not found":
+			h.respondError(w, http.StatusNotFound, err.Error())
+		case errors.As(err, &transitionErr):
+			h.respondError(w, http.StatusConflict, err.Error())
+		default:
+			h.respondError(w, http.StatusInternalServerError, "Failed to apply gateway event")
+		}
+		return
+	}
+
+	result := models.GatewayEventResult{EventID: evt.ID, Ignored: payment == nil}
+	if payment != nil {
+		result.PaymentID = payment.ID
+		result.Status = payment.Status
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response in the {"error": ...} shape
+func (h *GatewayHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/sirupsen/logrus"
+)
+
+// ImpressionSource summarizes recorded flag evaluations.
+// *targeting.Recorder is the production implementation.
+type ImpressionSource interface {
+	Summary() *targeting.Summary
+}
+
+var _ ImpressionSource = (*targeting.Recorder)(nil)
+
+// ImpressionsHandler serves flag exposure summaries
+type ImpressionsHandler struct {
+	source ImpressionSource
+	logger *logrus.Logger
+}
+
+// NewImpressionsHandler creates a new flag impressions handler
+func NewImpressionsHandler(source ImpressionSource, logger *logrus.Logger) *ImpressionsHandler {
+	return &ImpressionsHandler{
+		source: source,
+		logger: logger,
+	}
+}
+
+// ServeHTTP handles GET /admin/flags/impressions/summary. The optional flag
+// query parameter limits the summary to one flag.
+func (h *ImpressionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	summary := h.source.Summary()
+	if flag := r.URL.Query().Get("flag"); flag != "" {
+		filtered := make(map[string]*targeting.FlagSummary, 1)
+		if fs, ok := summary.Flags[flag]; ok {
+			filtered[flag] = fs
+		}
+		summary.Flags = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
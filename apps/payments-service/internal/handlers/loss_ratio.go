@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// lossRatioTimeout bounds how long a report may spend calling other services
+const lossRatioTimeout = 30 * time.Second
+
+// lossRatioExportColumns is the header row of the CSV export
+var lossRatioExportColumns = []string{"policyType", "month", "premiums", "payouts", "lossRatio"}
+
+// LossRatioReporter computes loss ratio reports.
+// *services.LossRatioReporter is the production implementation.
+type LossRatioReporter interface {
+	Report(ctx context.Context, filters models.LossRatioFilters) (*models.LossRatioReport, error)
+}
+
+var _ LossRatioReporter = (*services.LossRatioReporter)(nil)
+
+// LossRatioHandler serves the loss ratio report and its CSV export
+type LossRatioHandler struct {
+	reporter LossRatioReporter
+	logger   *logrus.Logger
+}
+
+// NewLossRatioHandler creates a new loss ratio report handler
+func NewLossRatioHandler(reporter LossRatioReporter, logger *logrus.Logger) *LossRatioHandler {
+	return &LossRatioHandler{
+		reporter: reporter,
+		logger:   logger,
+	}
+}
+
+// GetLossRatio handles GET /admin/reports/loss-ratio
+// Supports query parameters:
+// - from: first month to include (YYYY-MM)
+// - to: last month to include (YYYY-MM)
+func (h *LossRatioHandler) GetLossRatio(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// ExportLossRatio handles GET /admin/reports/loss-ratio/export - downloads
+// the loss ratio per policy type and month as CSV. Accepts the same filters
+// as GetLossRatio.
+func (h *LossRatioHandler) ExportLossRatio(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="loss-ratio-%s.csv"`, report.GeneratedAt.UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(lossRatioExportColumns)
+	for _, row := range report.Months {
+		ratio := ""
+		if row.LossRatio != nil {
+			ratio = strconv.FormatFloat(*row.LossRatio, 'f', 4, 64)
+		}
+		writer.Write([]string{
+			row.PolicyType,
+			row.Month,
+			strconv.FormatFloat(row.Premiums, 'f', 2, 64),
+			strconv.FormatFloat(row.Payouts, 'f', 2, 64),
+			ratio,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.WithError(err).Error("Failed to write loss ratio export")
+		return
+	}
+
+	h.logger.WithField("rows", len(report.Months)).Info("Exported loss ratio report")
+}
+
+// report reads the filters and builds the report, answering the request
+// itself when it cannot
+func (h *LossRatioHandler) report(w http.ResponseWriter, r *http.Request) (*models.LossRatioReport, bool) {
+	filters, err := parseLossRatioFilters(r.URL.Query())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), lossRatioTimeout)
+	defer cancel()
+
+	report, err := h.reporter.Report(ctx, filters)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build loss ratio report")
+		h.respondError(w, http.StatusInternalServerError, "Failed to build loss ratio report")
+		return nil, false
+	}
+	return report, true
+}
+
+// parseLossRatioFilters reads the month range of the loss ratio report
+func parseLossRatioFilters(query url.Values) (models.LossRatioFilters, error) {
+	filters := models.LossRatioFilters{
+		From: query.Get("from"),
+		To:   query.Get("to"),
+	}
+	for _, name := range []string{"from", "to"} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01", value); err != nil {
+			return filters, fmt.Errorf("invalid %s: must be a month as YYYY-MM", name)
+		}
+	}
+	if filters.From != "" && filters.To != "" && filters.From > filters.To {
+		return filters, fmt.Errorf("invalid month range: from must not be after to")
+	}
+	return filters, nil
+}
+
+// respondError sends an error response
+func (h *LossRatioHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
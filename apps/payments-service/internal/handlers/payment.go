@@ -1,56 +1,102 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/middleware"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// PaymentService is the business logic the payment handler depends on.
+// *services.PaymentService is the production implementation.
+type PaymentService interface {
+	GetAllPayments() ([]*models.Payment, error)
+	GetPaymentsByClaimID(claimID string) ([]*models.Payment, error)
+	GetPaymentByID(paymentID string) (*models.Payment, error)
+	GetArchivedPayments(claimID string) ([]*models.Payment, error)
+	GetArchivedPayment(paymentID string) (*models.Payment, error)
+	CreatePayment(ctx context.Context, policyID, customerID string, amount float64) (*models.Payment, error)
+	CreatePayout(ctx context.Context, claimID, customerID string, amount float64) (*models.Payment, error)
+	CreateRefund(policyID, customerID string, amount float64) (*models.Payment, error)
+	ProcessPayment(ctx context.Context, paymentID string) (*models.Payment, error)
+	FailPayment(paymentID, reason string) (*models.Payment, error)
+	ReleasePayment(paymentID, releasedBy, reason string) (*models.Payment, error)
+	RefundPayment(paymentID, reason string) (*models.Payment, error)
+}
+
+var _ PaymentService = (*services.PaymentService)(nil)
+
 // PaymentHandler handles payment-related HTTP requests
 type PaymentHandler struct {
-	service *services.PaymentService
+	service PaymentService
 	logger  *logrus.Logger
 }
 
 // NewPaymentHandler creates a new payment handler
-func NewPaymentHandler(service *services.PaymentService, logger *logrus.Logger) *PaymentHandler {
+func NewPaymentHandler(service PaymentService, logger *logrus.Logger) *PaymentHandler {
 	return &PaymentHandler{
 		service: service,
 		logger:  logger,
 	}
 }
 
-// GetPayments handles GET /payments
+// GetPayments handles GET /payments. The claimId query parameter narrows
+// the list to the payouts for one claim. includeArchived=true puts the
+// archived payments first; its route requires a staff token.
 func (h *PaymentHandler) GetPayments(w http.ResponseWriter, r *http.Request) {
-	payments, err := h.service.GetAllPayments()
+	query := r.URL.Query()
+	claimID := query.Get("claimId")
+
+	var payments []*models.Payment
+	var err error
+	if claimID != "" {
+		payments, err = h.service.GetPaymentsByClaimID(claimID)
+	} else {
+		payments, err = h.service.GetAllPayments()
+	}
+	if err == nil && query.Get("includeArchived") == "true" {
+		var archived []*models.Payment
+		archived, err = h.service.GetArchivedPayments(claimID)
+		payments = append(archived, payments...)
+	}
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get payments")
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.respondError(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(payments)
+	json.NewEncoder(w).Encode(shaping.Apply(r, payments))
 }
 
-// GetPaymentByID handles GET /payments/{id}
+// GetPaymentByID handles GET /payments/{id}. With includeArchived=true,
+// which requires a staff token, archived payments are found too.
 func (h *PaymentHandler) GetPaymentByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	paymentID := vars["id"]
 
 	payment, err := h.service.GetPaymentByID(paymentID)
+	if err != nil && r.URL.Query().Get("includeArchived") == "true" {
+		payment, err = h.service.GetArchivedPayment(paymentID)
+	}
 	if err != nil {
 		h.logger.WithError(err).WithField("paymentId", paymentID).Error("Failed to get payment")
-		http.Error(w, "Payment not found", http.StatusNotFound)
+		h.respondError(w, http.StatusNotFound, "Payment not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(payment)
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
 }
 
 // CreatePayment handles POST /payments
@@ -58,80 +104,219 @@ func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePaymentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode payment request")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if err := req.Validate(); err != nil {
 		h.logger.WithError(err).Error("Payment request validation failed")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	payment, err := h.service.CreatePayment(req.PolicyID, req.CustomerID, req.Amount)
+	payment, err := h.service.CreatePayment(r.Context(), req.PolicyID, req.CustomerID, req.Amount)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to create payment")
-		http.Error(w, "Failed to create payment", http.StatusInternalServerError)
+		h.respondError(w, http.StatusInternalServerError, "Failed to create payment")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(payment)
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
 }
 
-// CreatePayout handles POST /payouts
+// CreatePayout handles POST /payouts. The route requires a service token
+// with the payments:write scope, whose client is logged as the payout's
+// creator.
 func (h *PaymentHandler) CreatePayout(w http.ResponseWriter, r *http.Request) {
 	var req models.CreatePayoutRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.WithError(err).Error("Failed to decode payout request")
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	if err := req.Validate(); err != nil {
 		h.logger.WithError(err).Error("Payout request validation failed")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payment, err := h.service.CreatePayout(r.Context(), req.ClaimID, req.CustomerID, req.Amount)
+	if err != nil {
+		switch {
+		case err.Error() == "claim not found":
+			h.respondError(w, http.StatusBadRequest, "Claim not found")
+		case err.Error() == "claim has no accepted settlement offer",
+			strings.HasPrefix(err.Error(), "payout exceeds the accepted offer"):
+			h.respondError(w, http.StatusConflict, err.Error())
+		case strings.HasPrefix(err.Error(), "claim lookup failed"):
+			h.logger.WithError(err).WithField("claimId", req.ClaimID).Error("Cannot check settlement offer")
+			h.respondError(w, http.StatusServiceUnavailable, "Claims service unavailable")
+		default:
+			h.logger.WithError(err).Error("Failed to create payout")
+			h.respondError(w, http.StatusInternalServerError, "Failed to create payout")
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"paymentId": payment.ID,
+		"claimId":   payment.ClaimID,
+		"createdBy": middleware.GetUserID(r),
+	}).Info("Payout created via API")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
+}
+
+// CreateRefund handles POST /refunds
+func (h *PaymentHandler) CreateRefund(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateRefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Error("Failed to decode refund request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.logger.WithError(err).Error("Refund request validation failed")
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	payment, err := h.service.CreatePayout(req.ClaimID, req.CustomerID, req.Amount)
+	payment, err := h.service.CreateRefund(req.PolicyID, req.CustomerID, req.Amount)
 	if err != nil {
-		h.logger.WithError(err).Error("Failed to create payout")
-		http.Error(w, "Failed to create payout", http.StatusInternalServerError)
+		h.logger.WithError(err).Error("Failed to create refund")
+		h.respondError(w, http.StatusInternalServerError, "Failed to create refund")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(payment)
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
 }
 
-// ProcessPayment handles PUT /payments/{id}/process
+// ProcessPayment handles PUT /payments/{id}/process. A payout blocked by
+// sanctions screening is returned with status blocked.
 func (h *PaymentHandler) ProcessPayment(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	paymentID := vars["id"]
+	paymentID := mux.Vars(r)["id"]
 
-	payment, err := h.service.ProcessPayment(paymentID)
+	payment, err := h.service.ProcessPayment(r.Context(), paymentID)
 	if err != nil {
-		h.logger.WithError(err).WithField("paymentId", paymentID).Error("Failed to process payment")
+		h.respondStatusError(w, paymentID, "Failed to process payment", err)
+		return
+	}
 
-		// Check if it's a not found error
-		if err.Error() == "payment not found" {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
+}
 
-		// Check if it's a validation error (already processed)
-		if err.Error() == "payment already processed" {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
+// FailPayment handles PUT /payments/{id}/fail - marks a payment stuck in
+// processing, or a blocked payout, as failed. The body must give a reason.
+func (h *PaymentHandler) FailPayment(w http.ResponseWriter, r *http.Request) {
+	paymentID := mux.Vars(r)["id"]
+
+	req, ok := h.decodeStatusRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := h.service.FailPayment(paymentID, req.Reason)
+	if err != nil {
+		h.respondStatusError(w, paymentID, "Failed to fail payment", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
+}
+
+// ReleasePayment handles PUT /payments/{id}/release - returns a payout
+// blocked by sanctions screening to pending once compliance has cleared
+// the recipient. The body must give a reason; the caller is recorded as
+// the one who released it.
+func (h *PaymentHandler) ReleasePayment(w http.ResponseWriter, r *http.Request) {
+	paymentID := mux.Vars(r)["id"]
+
+	req, ok := h.decodeStatusRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := h.service.ReleasePayment(paymentID, middleware.GetUserID(r), req.Reason)
+	if err != nil {
+		h.respondStatusError(w, paymentID, "Failed to release payment", err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"paymentId":  payment.ID,
+		"releasedBy": payment.Screening.ReleasedBy,
+	}).Info("Blocked payout released via API")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
+}
+
+// RefundPayment handles PUT /payments/{id}/refund - marks a completed
+// payment as refunded. The body, with an optional reason, may be omitted.
+func (h *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	paymentID := mux.Vars(r)["id"]
+
+	req, ok := h.decodeStatusRequest(w, r)
+	if !ok {
+		return
+	}
 
-		http.Error(w, "Failed to process payment", http.StatusInternalServerError)
+	payment, err := h.service.RefundPayment(paymentID, req.Reason)
+	if err != nil {
+		h.respondStatusError(w, paymentID, "Failed to refund payment", err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(payment)
+	json.NewEncoder(w).Encode(shaping.Apply(r, payment))
+}
+
+// decodeStatusRequest reads an optional status change body
+func (h *PaymentHandler) decodeStatusRequest(w http.ResponseWriter, r *http.Request) (models.PaymentStatusRequest, bool) {
+	var req models.PaymentStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.logger.WithError(err).Error("Failed to decode payment status request")
+		h.respondError(w, http.StatusBadRequest, "Invalid request body")
+		return req, false
+	}
+	return req, true
+}
+
+// respondStatusError maps status change errors to HTTP statuses: 404 for
+// an unknown payment and 409 for a change the payment lifecycle refuses
+func (h *PaymentHandler) respondStatusError(w http.ResponseWriter, paymentID, message string, err error) {
+	h.logger.WithError(err).WithField("paymentId", paymentID).Error(message)
+
+	if err.Error() == "payment not found" {
+		h.respondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	var transitionErr *lifecycle.TransitionError
+	if errors.As(err, &transitionErr) {
+		h.respondError(w, http.StatusConflict, err.Error())
+		return
+	}
+
+	h.respondError(w, http.StatusInternalServerError, message)
+}
+
+// respondError sends an error response in the same {"error": ...} shape
+// as the role checks
+func (h *PaymentHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
 }
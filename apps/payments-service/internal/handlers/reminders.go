@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// ReminderService is the business logic the reminder handler depends on.
+// *services.ReminderService is the production implementation.
+type ReminderService interface {
+	Remind(ctx context.Context, now time.Time) (*models.ReminderRun, error)
+	GetReminders(policyID string, status models.ReminderStatus) []*models.Reminder
+}
+
+var _ ReminderService = (*services.ReminderService)(nil)
+
+// ReminderHandler handles premium payment reminder HTTP requests
+type ReminderHandler struct {
+	service ReminderService
+	logger  *logrus.Logger
+}
+
+// NewReminderHandler creates a new reminder handler
+func NewReminderHandler(service ReminderService, logger *logrus.Logger) *ReminderHandler {
+	return &ReminderHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RunReminders handles POST /admin/reminders/run - sends the reminders due
+// now without waiting for the next scheduled run
+func (h *ReminderHandler) RunReminders(w http.ResponseWriter, r *http.Request) {
+	run, err := h.service.Remind(r.Context(), time.Now())
+	if err != nil {
+		switch err.Error() {
+		case "customer service not configured", "policy service not configured":
+			h.respondError(w, http.StatusServiceUnavailable, err.Error())
+		default:
+			h.logger.WithError(err).Error("Failed to send payment reminders")
+			h.respondError(w, http.StatusInternalServerError, "Failed to send payment reminders")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// GetReminders handles GET /admin/reminders. The policyId and status query
+// parameters narrow the list.
+func (h *ReminderHandler) GetReminders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	status := models.ReminderStatus(query.Get("status"))
+	switch status {
+	case "", models.ReminderStatusSent, models.ReminderStatusOptedOut, models.ReminderStatusFailed:
+	default:
+		h.respondError(w, http.StatusBadRequest, "status must be sent, opted_out or failed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetReminders(query.Get("policyId"), status))
+}
+
+// respondError sends an error response
+func (h *ReminderHandler) respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
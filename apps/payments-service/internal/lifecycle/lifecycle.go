@@ -0,0 +1,208 @@
+// Package lifecycle defines the payment status state machine: the moves
+// allowed between statuses, the checks a move must pass, the fields a
+// status sets when it is entered, and hooks that run once a move has been
+// saved. Refused moves are reported as *TransitionError.
+package lifecycle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+)
+
+// Change is a request to move a payment to another status
+type Change struct {
+	To     models.PaymentStatus
+	Reason string    // why a payment failed, was refunded, blocked, released or disputed
+	By     string    // who made the change, when a person did
+	At     time.Time // when the change was made; defaults to now
+}
+
+// TransitionError reports a status change the lifecycle refused. Reason is
+// set when the move is allowed in general but a guard refused it for this
+// payment.
+type TransitionError struct {
+	PaymentID string
+	From      models.PaymentStatus
+	To        models.PaymentStatus
+	Reason    string
+}
+
+func (e *TransitionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("payment %s cannot move from %s to %s: %s", e.PaymentID, e.From, e.To, e.Reason)
+	}
+	return fmt.Sprintf("payment %s cannot move from %s to %s", e.PaymentID, e.From, e.To)
+}
+
+// Transition allows a payment to move From one status To another. Guard,
+// when set, returns why the move is refused for a payment, or "".
+type Transition struct {
+	From  models.PaymentStatus
+	To    models.PaymentStatus
+	Guard func(payment *models.Payment, change *Change) string
+}
+
+// Enter sets the payment fields that come with entering a status
+type Enter func(payment *models.Payment, change *Change)
+
+// Hook runs after a status change has been saved. from is the status the
+// payment left.
+type Hook func(payment *models.Payment, from models.PaymentStatus, change Change)
+
+// Machine applies status changes to payments according to a declared set
+// of transitions. Hooks are registered while the service is set up and are
+// not safe to add concurrently with Fire.
+type Machine struct {
+	transitions map[models.PaymentStatus]map[models.PaymentStatus]Transition
+	enter       map[models.PaymentStatus]Enter
+	hooks       []Hook
+}
+
+// New creates a machine from its transitions and the fields each status
+// sets on entry
+func New(transitions []Transition, enter map[models.PaymentStatus]Enter) *Machine {
+	m := &Machine{
+		transitions: make(map[models.PaymentStatus]map[models.PaymentStatus]Transition),
+		enter:       enter,
+	}
+	for _, t := range transitions {
+		if m.transitions[t.From] == nil {
+			m.transitions[t.From] = make(map[models.PaymentStatus]Transition)
+		}
+		m.transitions[t.From][t.To] = t
+	}
+	return m
+}
+
+// Payments returns the payment lifecycle: pending payments are taken into
+// processing, which either completes or fails, and a completed payment can
+// later be refunded. A payout blocked by sanctions screening while it is
+// processed waits until compliance either releases it back to pending or
+// fails it. A completed premium the payer disputes with their bank is
+// disputed until the dispute is won, when it is completed again, or lost,
+// when it is charged back. Failed, refunded and charged back payments are
+// final.
+func Payments() *Machine {
+	return New([]Transition{
+		{From: models.PaymentStatusPending, To: models.PaymentStatusProcessing},
+		{From: models.PaymentStatusProcessing, To: models.PaymentStatusCompleted},
+		{From: models.PaymentStatusProcessing, To: models.PaymentStatusFailed, Guard: requireReason},
+		{From: models.PaymentStatusProcessing, To: models.PaymentStatusBlocked, Guard: screened},
+		{From: models.PaymentStatusBlocked, To: models.PaymentStatusPending, Guard: requireReason},
+		{From: models.PaymentStatusBlocked, To: models.PaymentStatusFailed, Guard: requireReason},
+		{From: models.PaymentStatusCompleted, To: models.PaymentStatusRefunded, Guard: refundable},
+		{From: models.PaymentStatusCompleted, To: models.PaymentStatusDisputed, Guard: disputable},
+		{From: models.PaymentStatusDisputed, To: models.PaymentStatusCompleted},
+		{From: models.PaymentStatusDisputed, To: models.PaymentStatusChargedBack},
+	}, map[models.PaymentStatus]Enter{
+		models.PaymentStatusCompleted: enterCompleted,
+		models.PaymentStatusFailed:    enterFailed,
+		models.PaymentStatusRefunded:  enterRefunded,
+		models.PaymentStatusPending:   enterReleased,
+	})
+}
+
+// OnTransition registers a hook to run after every saved status change
+func (m *Machine) OnTransition(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Fire moves payment to change.To, then calls save and, if it succeeds,
+// the hooks. A refused change returns a *TransitionError and leaves payment
+// untouched.
+func (m *Machine) Fire(payment *models.Payment, change Change, save func(payment *models.Payment) error) error {
+	from := payment.Status
+	transition, ok := m.transitions[from][change.To]
+	if !ok {
+		return &TransitionError{PaymentID: payment.ID, From: from, To: change.To}
+	}
+	if transition.Guard != nil {
+		if reason := transition.Guard(payment, &change); reason != "" {
+			return &TransitionError{PaymentID: payment.ID, From: from, To: change.To, Reason: reason}
+		}
+	}
+
+	if change.At.IsZero() {
+		change.At = time.Now()
+	}
+	if enter := m.enter[change.To]; enter != nil {
+		enter(payment, &change)
+	}
+	payment.Status = change.To
+	payment.UpdatedAt = change.At
+
+	if err := save(payment); err != nil {
+		return err
+	}
+	for _, hook := range m.hooks {
+		hook(payment, from, change)
+	}
+	return nil
+}
+
+// requireReason makes failures explain themselves
+func requireReason(payment *models.Payment, change *Change) string {
+	if change.Reason == "" {
+		return "a reason is required"
+	}
+	return ""
+}
+
+// screened only blocks payouts that were screened
+func screened(payment *models.Payment, change *Change) string {
+	if payment.Screening == nil {
+		return "only screened payouts can be blocked"
+	}
+	return ""
+}
+
+// refundable refuses to refund a refund
+func refundable(payment *models.Payment, change *Change) string {
+	if payment.Type == models.PaymentTypeRefund {
+		return "refund payments cannot be refunded"
+	}
+	return ""
+}
+
+// disputable only lets premiums be disputed, and makes disputes explain
+// themselves
+func disputable(payment *models.Payment, change *Change) string {
+	if payment.Type != models.PaymentTypePremium {
+		return "only premium payments can be disputed"
+	}
+	return requireReason(payment, change)
+}
+
+// enterCompleted records when the payment settled. A premium completed
+// again after a won dispute keeps the date it first settled.
+func enterCompleted(payment *models.Payment, change *Change) {
+	if payment.Status == models.PaymentStatusDisputed {
+		return
+	}
+	payment.ProcessedDate = &change.At
+}
+
+// enterFailed records when and why the payment failed
+func enterFailed(payment *models.Payment, change *Change) {
+	payment.ProcessedDate = &change.At
+	payment.FailureReason = change.Reason
+}
+
+// enterReleased records who released a blocked payout, when and why. Only
+// blocked payouts return to pending.
+func enterReleased(payment *models.Payment, change *Change) {
+	if payment.Screening == nil {
+		return
+	}
+	payment.Screening.ReleasedBy = change.By
+	payment.Screening.ReleasedAt = &change.At
+	payment.Screening.ReleaseReason = change.Reason
+}
+
+// enterRefunded records when and why the payment was refunded
+func enterRefunded(payment *models.Payment, change *Change) {
+	payment.RefundedDate = &change.At
+	payment.RefundReason = change.Reason
+}
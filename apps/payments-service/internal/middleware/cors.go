@@ -21,6 +21,7 @@ func NewCORS() *cors.Cors {
 			"Content-Type",
 			"X-CSRF-Token",
 			"X-User-ID",
+			"X-Tenant-ID",
 		},
 		ExposedHeaders: []string{
 			"Link",
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequireRole(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	manager := auth.NewJWTManager("test-secret", time.Hour)
+	token := func(role string) string {
+		signed, err := manager.GenerateWithRole("adj-001", "staff@example.com", role)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return "Bearer " + signed
+	}
+	forged, _ := auth.NewJWTManager("other-secret", time.Hour).GenerateWithRole("adj-001", "staff@example.com", "admin")
+
+	var gotUser string
+	handler := AuthMiddleware(logger)(RequireRole(logger, "admin", "adjuster")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = GetUserID(r)
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		{"admin", token("admin"), http.StatusCreated, "adj-001"},
+		{"adjuster", token("adjuster"), http.StatusCreated, "adj-001"},
+		{"customer", token(""), http.StatusForbidden, ""},
+		{"wrong secret", "Bearer " + forged, http.StatusUnauthorized, ""},
+		{"missing", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser = ""
+			req := httptest.NewRequest("POST", "/payouts", nil)
+			req.Header.Set("X-User-ID", "cust-001")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus || gotUser != tt.wantUser {
+				t.Errorf("Got %d as %q, want %d as %q", rec.Code, gotUser, tt.wantStatus, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	manager := auth.NewJWTManager("test-secret", time.Hour)
+	scoped, _ := manager.GenerateForClient("claims-service", []string{"claims:read", "payments:write"})
+	unscoped, _ := manager.GenerateForClient("reporting", []string{"claims:read"})
+	adjuster, _ := manager.GenerateWithRole("adj-001", "staff@example.com", "adjuster")
+	forged, _ := auth.NewJWTManager("other-secret", time.Hour).GenerateForClient("claims-service", []string{"payments:write"})
+
+	var gotUser string
+	handler := RequireScope(logger, "payments:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = GetUserID(r)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+		wantUser   string
+	}{
+		{"scoped service token", scoped, http.StatusCreated, "claims-service"},
+		{"other scopes", unscoped, http.StatusForbidden, ""},
+		{"adjuster", adjuster, http.StatusForbidden, ""},
+		{"wrong secret", forged, http.StatusUnauthorized, ""},
+		{"missing", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser = ""
+			req := httptest.NewRequest("POST", "/payouts", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus || gotUser != tt.wantUser {
+				t.Errorf("Got %d as %q, want %d as %q", rec.Code, gotUser, tt.wantStatus, tt.wantUser)
+			}
+		})
+	}
+}
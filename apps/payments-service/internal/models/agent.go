@@ -0,0 +1,108 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// AgentStatus represents whether an agent may earn new commission
+type AgentStatus string
+
+const (
+	AgentStatusActive   AgentStatus = "active"
+	AgentStatusInactive AgentStatus = "inactive"
+)
+
+// Agent is an agent or broker who sells policies on commission
+type Agent struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Email         string      `json:"email,omitempty"`
+	LicenseNumber string      `json:"licenseNumber"`
+	LicenseState  string      `json:"licenseState,omitempty"` // state or region that issued the license
+	Status        AgentStatus `json:"status"`
+	// CommissionRate is the share of each premium paid to the agent, e.g.
+	// 0.12 for 12%. Zero uses the service's default rate.
+	CommissionRate float64   `json:"commissionRate,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// CreateAgentRequest represents a request to register an agent
+type CreateAgentRequest struct {
+	Name           string  `json:"name"`
+	Email          string  `json:"email,omitempty"`
+	LicenseNumber  string  `json:"licenseNumber"`
+	LicenseState   string  `json:"licenseState,omitempty"`
+	CommissionRate float64 `json:"commissionRate,omitempty"`
+}
+
+// UpdateAgentRequest represents a request to update an agent. Nil fields
+// are left unchanged.
+type UpdateAgentRequest struct {
+	Name           *string      `json:"name,omitempty"`
+	Email          *string      `json:"email,omitempty"`
+	LicenseNumber  *string      `json:"licenseNumber,omitempty"`
+	LicenseState   *string      `json:"licenseState,omitempty"`
+	CommissionRate *float64     `json:"commissionRate,omitempty"`
+	Status         *AgentStatus `json:"status,omitempty"`
+}
+
+// Validate validates a CreateAgentRequest
+func (r *CreateAgentRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return &ValidationError{Field: "name", Message: "name is required"}
+	}
+	if strings.TrimSpace(r.LicenseNumber) == "" {
+		return &ValidationError{Field: "licenseNumber", Message: "license number is required"}
+	}
+	return validateCommissionRate(r.CommissionRate)
+}
+
+// Validate validates an UpdateAgentRequest
+func (r *UpdateAgentRequest) Validate() error {
+	if r.Name != nil && strings.TrimSpace(*r.Name) == "" {
+		return &ValidationError{Field: "name", Message: "name cannot be empty"}
+	}
+	if r.LicenseNumber != nil && strings.TrimSpace(*r.LicenseNumber) == "" {
+		return &ValidationError{Field: "licenseNumber", Message: "license number cannot be empty"}
+	}
+	if r.Status != nil && *r.Status != AgentStatusActive && *r.Status != AgentStatusInactive {
+		return &ValidationError{Field: "status", Message: "status must be active or inactive"}
+	}
+	if r.CommissionRate != nil {
+		return validateCommissionRate(*r.CommissionRate)
+	}
+	return nil
+}
+
+// validateCommissionRate checks a rate is a fraction of the premium
+func validateCommissionRate(rate float64) error {
+	if rate < 0 || rate >= 1 {
+		return &ValidationError{Field: "commissionRate", Message: "commission rate must be at least 0 and below 1"}
+	}
+	return nil
+}
+
+// Commission is the share of one premium payment earned by the agent who
+// sold the policy. The rate is recorded so later rate changes do not alter
+// past statements.
+type Commission struct {
+	ID        string    `json:"id"`
+	AgentID   string    `json:"agentId"`
+	PaymentID string    `json:"paymentId"`
+	PolicyID  string    `json:"policyId"`
+	Premium   float64   `json:"premium"`
+	Rate      float64   `json:"rate"`
+	Amount    float64   `json:"amount"`
+	EarnedAt  time.Time `json:"earnedAt"` // when the premium payment completed, or was refunded for a reversal
+}
+
+// CommissionStatement totals an agent's commission for one calendar month
+type CommissionStatement struct {
+	AgentID         string        `json:"agentId"`
+	Month           string        `json:"month"` // YYYY-MM
+	Commissions     []*Commission `json:"commissions"`
+	TotalPremium    float64       `json:"totalPremium"`
+	TotalCommission float64       `json:"totalCommission"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Invoice is the premium due on a policy for one billing period
+type Invoice struct {
+	ID          string    `json:"id"`
+	PolicyID    string    `json:"policyId"`
+	CustomerID  string    `json:"customerId"`
+	Period      int       `json:"period"` // 1 for the period starting on the policy's start date
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	DueDate     time.Time `json:"dueDate"`
+	Amount      float64   `json:"amount"`
+	IssuedAt    time.Time `json:"issuedAt"`
+}
+
+// Allocation is the part of a premium payment applied to an invoice, or to
+// a late fee charged on it when FeeID is set. Refunding the payment records
+// an offsetting negative allocation, so the invoice is due again.
+type Allocation struct {
+	ID          string    `json:"id"`
+	PaymentID   string    `json:"paymentId"`
+	InvoiceID   string    `json:"invoiceId"`
+	FeeID       string    `json:"feeId,omitempty"`
+	PolicyID    string    `json:"policyId"`
+	Amount      float64   `json:"amount"`
+	AllocatedAt time.Time `json:"allocatedAt"`
+}
+
+// LateFeeKind is how a late fee is worked out
+type LateFeeKind string
+
+const (
+	LateFeeKindFlat     LateFeeKind = "flat"     // a fixed fee, charged once per invoice
+	LateFeeKindInterest LateFeeKind = "interest" // accrued daily at an APR on the premium outstanding
+)
+
+// LateFee is charged on an invoice still unpaid once the grace period after
+// its due date has passed. An interest fee grows as it accrues each day;
+// AccruedTo is the day it has been accrued up to.
+type LateFee struct {
+	ID         string      `json:"id"`
+	InvoiceID  string      `json:"invoiceId"`
+	PolicyID   string      `json:"policyId"`
+	CustomerID string      `json:"customerId"`
+	Kind       LateFeeKind `json:"kind"`
+	Amount     float64     `json:"amount"`
+	AccruedTo  *time.Time  `json:"accruedTo,omitempty"`
+	ChargedAt  time.Time   `json:"chargedAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+}
+
+// LateFeeRun is the outcome of one pass of the late fee job
+type LateFeeRun struct {
+	Policies int        `json:"policies"` // policies checked
+	Charged  float64    `json:"charged"`  // late fees charged on this pass
+	Fees     []*LateFee `json:"fees"`     // the fees charged or grown on this pass
+	RunAt    time.Time  `json:"runAt"`
+}
+
+// InvoiceStatus is how far an invoice has been paid
+type InvoiceStatus string
+
+const (
+	InvoiceStatusOpen    InvoiceStatus = "open"    // not fully paid, not yet due
+	InvoiceStatusPaid    InvoiceStatus = "paid"    // fully paid
+	InvoiceStatusOverdue InvoiceStatus = "overdue" // not fully paid by its due date
+)
+
+// InvoiceBalance is an invoice with what has been paid against it. Paid
+// and Outstanding are the premium; late fees are counted apart.
+type InvoiceBalance struct {
+	*Invoice
+	Paid                float64       `json:"paid"`
+	Outstanding         float64       `json:"outstanding"`
+	LateFees            float64       `json:"lateFees"`            // charged on the invoice
+	LateFeesOutstanding float64       `json:"lateFeesOutstanding"` // charged but not yet paid
+	Status              InvoiceStatus `json:"status"`
+}
+
+// PolicyBalance totals the premium invoiced on a policy, and the late fees
+// charged on it, against the premium paid
+type PolicyBalance struct {
+	PolicyID     string            `json:"policyId"`
+	PolicyNumber string            `json:"policyNumber,omitempty"`
+	PolicyStatus string            `json:"policyStatus,omitempty"`
+	Due          float64           `json:"due"`                // invoiced for periods started so far, and late fees
+	LateFees     float64           `json:"lateFees"`           // late fees charged, included in due
+	Paid         float64           `json:"paid"`               // allocated to those invoices and fees
+	Outstanding  float64           `json:"outstanding"`        // due less paid
+	Overdue      float64           `json:"overdue"`            // outstanding on invoices past their due date, and unpaid late fees
+	Credit       float64           `json:"credit"`             // premium paid but not yet allocated, less completed refunds
+	PaidUpTo     *time.Time        `json:"paidUpTo,omitempty"` // end of the last period paid in full with every earlier one
+	Invoices     []*InvoiceBalance `json:"invoices"`
+	AsOf         time.Time         `json:"asOf"`
+}
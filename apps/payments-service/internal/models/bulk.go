@@ -0,0 +1,52 @@
+package models
+
+// Bulk payment result statuses
+const (
+	BulkPaymentCreated  = "created"
+	BulkPaymentExisting = "existing" // created earlier with the same idempotency key
+	BulkPaymentFailed   = "failed"
+)
+
+// BulkPaymentRequest is the body of POST /payments/bulk: the premium
+// payments of a batch, such as a renewal run
+type BulkPaymentRequest struct {
+	Payments []BulkPaymentItem `json:"payments"`
+}
+
+// BulkPaymentItem is one premium payment of a batch. The idempotency key
+// names the payment, so sending the item again, in the same batch resent
+// or in another, returns the payment it created instead of a second one.
+type BulkPaymentItem struct {
+	IdempotencyKey string `json:"idempotencyKey"`
+	CreatePaymentRequest
+}
+
+// Validate validates a BulkPaymentItem
+func (r *BulkPaymentItem) Validate() error {
+	if r.IdempotencyKey == "" {
+		return &ValidationError{Field: "idempotencyKey", Message: "idempotency key is required"}
+	}
+	if len(r.IdempotencyKey) > 255 {
+		return &ValidationError{Field: "idempotencyKey", Message: "idempotency key must be at most 255 characters"}
+	}
+	return r.CreatePaymentRequest.Validate()
+}
+
+// BulkPaymentResult is the outcome of one item of a batch, streamed as it
+// completes. Index is the item's position in the request.
+type BulkPaymentResult struct {
+	Index          int      `json:"index"`
+	IdempotencyKey string   `json:"idempotencyKey"`
+	Status         string   `json:"status"` // created, existing or failed
+	Payment        *Payment `json:"payment,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+// BulkPaymentSummary counts the outcomes of a batch. It follows the last
+// result of the stream.
+type BulkPaymentSummary struct {
+	Total    int `json:"total"`
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+	Failed   int `json:"failed"`
+}
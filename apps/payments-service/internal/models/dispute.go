@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DisputeStatus represents where a dispute of a premium payment stands
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen DisputeStatus = "open" // the premium is provisionally reversed while the dispute is decided
+	DisputeStatusWon  DisputeStatus = "won"  // the payer's bank found for the insurer; the premium stands
+	DisputeStatusLost DisputeStatus = "lost" // the premium was charged back to the payer
+)
+
+// Dispute is a chargeback or other dispute the payer raised with their bank
+// against a premium payment
+type Dispute struct {
+	ID         string        `json:"id"`
+	PaymentID  string        `json:"paymentId"`
+	PolicyID   string        `json:"policyId"`
+	CustomerID string        `json:"customerId"`
+	Amount     float64       `json:"amount"`
+	Reason     string        `json:"reason"`               // why the payer disputes the payment
+	ReasonCode string        `json:"reasonCode,omitempty"` // the card network's reason code, e.g. 13.1
+	Status     DisputeStatus `json:"status"`
+	OpenedBy   string        `json:"openedBy"`
+	OpenedAt   time.Time     `json:"openedAt"`
+	ResolvedBy string        `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time    `json:"resolvedAt,omitempty"`
+	Note       string        `json:"note,omitempty"` // how the dispute was decided
+	// PolicyLapsed is set when a lost dispute lapsed the policy; LapseError
+	// says why it could not be lapsed
+	PolicyLapsed bool   `json:"policyLapsed,omitempty"`
+	LapseError   string `json:"lapseError,omitempty"`
+}
+
+// OpenDisputeRequest represents a request to open a dispute of a payment
+type OpenDisputeRequest struct {
+	Reason     string `json:"reason"`
+	ReasonCode string `json:"reasonCode,omitempty"`
+}
+
+// ResolveDisputeRequest represents a request to record the outcome of a
+// dispute
+type ResolveDisputeRequest struct {
+	Outcome DisputeStatus `json:"outcome"` // won or lost
+	Note    string        `json:"note,omitempty"`
+}
+
+// Validate validates an OpenDisputeRequest
+func (r *OpenDisputeRequest) Validate() error {
+	if strings.TrimSpace(r.Reason) == "" {
+		return &ValidationError{Field: "reason", Message: "reason is required"}
+	}
+	return nil
+}
+
+// Validate validates a ResolveDisputeRequest
+func (r *ResolveDisputeRequest) Validate() error {
+	if r.Outcome != DisputeStatusWon && r.Outcome != DisputeStatusLost {
+		return &ValidationError{Field: "outcome", Message: "outcome must be won or lost"}
+	}
+	return nil
+}
+
+// DisputeMetrics summarizes the disputes of premium payments
+type DisputeMetrics struct {
+	Open            int     `json:"open"`
+	Won             int     `json:"won"`
+	Lost            int     `json:"lost"`
+	OpenAmount      float64 `json:"openAmount"`      // premium provisionally reversed
+	LostAmount      float64 `json:"lostAmount"`      // premium charged back
+	WinRate         float64 `json:"winRate"`         // won out of resolved, 0 when none are
+	DisputeRate     float64 `json:"disputeRate"`     // disputed out of settled premium payments
+	AvgDaysToDecide float64 `json:"avgDaysToDecide"` // from opening to resolution
+	PoliciesLapsed  int     `json:"policiesLapsed"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Gateway event types: the outcomes the payment gateway reports for
+// payments it was sent after they left processing
+const (
+	GatewayPaymentFailed   = "payment.failed"   // declined or bounced; the payment fails
+	GatewayPaymentRefunded = "payment.refunded" // returned to the payer; the payment is refunded
+)
+
+// GatewayEvent is a callback from the payment gateway, signed with one of
+// the gateway's webhook secrets
+type GatewayEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	PaymentID  string    `json:"paymentId"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+// Validate validates a GatewayEvent
+func (e *GatewayEvent) Validate() error {
+	if e.ID == "" {
+		return &ValidationError{Field: "id", Message: "event ID is required"}
+	}
+	if e.Type == "" {
+		return &ValidationError{Field: "type", Message: "event type is required"}
+	}
+	if e.PaymentID == "" {
+		return &ValidationError{Field: "paymentId", Message: "payment ID is required"}
+	}
+	return nil
+}
+
+// GatewayEventResult acknowledges a gateway callback with the payment's
+// status. Ignored is set instead for event types this service does not act
+// on, which are acknowledged too so the gateway stops retrying them.
+type GatewayEventResult struct {
+	EventID   string        `json:"eventId"`
+	Ignored   bool          `json:"ignored,omitempty"`
+	PaymentID string        `json:"paymentId,omitempty"`
+	Status    PaymentStatus `json:"status,omitempty"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// UnknownPolicyType groups payments whose policy type could not be looked
+// up in the loss ratio report
+const UnknownPolicyType = "unknown"
+
+// LossRatioFilters limits the loss ratio report to a range of months, as
+// YYYY-MM. An empty bound leaves that end open; bounds are inclusive.
+type LossRatioFilters struct {
+	From string
+	To   string
+}
+
+// LossRatio compares the claim payouts and premiums settled in a period.
+// Premiums are net of premium refunds; payments that were later refunded
+// are left out of both. LossRatio is payouts divided by premiums, and is
+// null when no premium was collected.
+type LossRatio struct {
+	PolicyType string   `json:"policyType"`
+	Month      string   `json:"month,omitempty"` // YYYY-MM; empty for a total across months
+	Premiums   float64  `json:"premiums"`
+	Payouts    float64  `json:"payouts"`
+	LossRatio  *float64 `json:"lossRatio"`
+}
+
+// Add counts a settled premium, refund or payout
+func (l *LossRatio) Add(payment *Payment) {
+	switch payment.Type {
+	case PaymentTypePremium:
+		l.Premiums += payment.Amount
+	case PaymentTypeRefund:
+		l.Premiums -= payment.Amount
+	case PaymentTypePayout:
+		l.Payouts += payment.Amount
+	}
+}
+
+// Finish computes the ratio once every payment has been added
+func (l *LossRatio) Finish() {
+	l.LossRatio = nil
+	if l.Premiums > 0 {
+		ratio := l.Payouts / l.Premiums
+		l.LossRatio = &ratio
+	}
+}
+
+// LossRatioReport is the loss ratio per policy type and month, per policy
+// type and overall. Warnings say why payments were grouped under
+// UnknownPolicyType.
+type LossRatioReport struct {
+	GeneratedAt  time.Time   `json:"generatedAt"`
+	From         string      `json:"from,omitempty"`
+	To           string      `json:"to,omitempty"`
+	Months       []LossRatio `json:"months"`       // by policy type, then month
+	ByPolicyType []LossRatio `json:"byPolicyType"` // by policy type
+	Total        LossRatio   `json:"total"`
+	Warnings     []string    `json:"warnings,omitempty"`
+}
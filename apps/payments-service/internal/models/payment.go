@@ -8,29 +8,65 @@ type PaymentType string
 const (
 	PaymentTypePremium PaymentType = "premium"
 	PaymentTypePayout  PaymentType = "payout"
+	PaymentTypeRefund  PaymentType = "refund"
 )
 
-// PaymentStatus represents the status of a payment
+// PaymentStatus represents the status of a payment. The moves allowed
+// between statuses are defined in the lifecycle package.
 type PaymentStatus string
 
 const (
-	PaymentStatusPending   PaymentStatus = "pending"
-	PaymentStatusCompleted PaymentStatus = "completed"
-	PaymentStatusFailed    PaymentStatus = "failed"
+	PaymentStatusPending     PaymentStatus = "pending"
+	PaymentStatusProcessing  PaymentStatus = "processing"
+	PaymentStatusCompleted   PaymentStatus = "completed"
+	PaymentStatusFailed      PaymentStatus = "failed"
+	PaymentStatusRefunded    PaymentStatus = "refunded"
+	PaymentStatusBlocked     PaymentStatus = "blocked"      // payout held by sanctions screening
+	PaymentStatusDisputed    PaymentStatus = "disputed"     // premium disputed by the payer, provisionally reversed
+	PaymentStatusChargedBack PaymentStatus = "charged_back" // premium taken back after a lost dispute
 )
 
 // Payment represents a payment or payout in the insurance system
 type Payment struct {
 	ID            string        `json:"id"`
-	Type          PaymentType   `json:"type"`           // premium or payout
-	PolicyID      string        `json:"policyId,omitempty"`      // For premium payments
+	Type          PaymentType   `json:"type"`           // premium, payout or refund
+	PolicyID      string        `json:"policyId,omitempty"`      // For premiums and refunds
 	ClaimID       string        `json:"claimId,omitempty"`       // For claim payouts
 	CustomerID    string        `json:"customerId"`
+	AgentID       string        `json:"agentId,omitempty"`       // Agent credited with commission on premiums
 	Amount        float64       `json:"amount"`
 	Status        PaymentStatus `json:"status"`
-	ProcessedDate *time.Time    `json:"processedDate,omitempty"`
+	ProcessedDate *time.Time    `json:"processedDate,omitempty"` // when the payment completed or failed
+	FailureReason string        `json:"failureReason,omitempty"`
+	RefundedDate  *time.Time    `json:"refundedDate,omitempty"`
+	RefundReason  string        `json:"refundReason,omitempty"`
 	CreatedAt     time.Time     `json:"createdAt"`
 	UpdatedAt     time.Time     `json:"updatedAt"`
+	ArchivedAt    *time.Time    `json:"archivedAt,omitempty"` // when the retention policy moved the payment to the archive
+	Screening     *Screening    `json:"screening,omitempty" shape:"roles=admin|adjuster"` // sanctions screening of a payout's recipient
+}
+
+// ClosedAt returns when the payment reached its current status: when it
+// was refunded or processed, or else when it last changed
+func (p *Payment) ClosedAt() time.Time {
+	switch {
+	case p.Status == PaymentStatusRefunded && p.RefundedDate != nil:
+		return *p.RefundedDate
+	case p.ProcessedDate != nil:
+		return *p.ProcessedDate
+	default:
+		return p.UpdatedAt
+	}
+}
+
+// ArchiveResult summarizes one pass of the retention policy over the
+// payments
+type ArchiveResult struct {
+	PolicyVersion string    `json:"policyVersion"`
+	Checked       int       `json:"checked"`
+	Archived      []string  `json:"archived"` // closed long enough ago, moved to the archive
+	Failed        []string  `json:"failed"`   // due, but could not be written; retried on the next pass
+	ArchivedAt    time.Time `json:"archivedAt"`
 }
 
 // CreatePaymentRequest represents a request to create a premium payment
@@ -47,6 +83,14 @@ type CreatePayoutRequest struct {
 	Amount     float64 `json:"amount"`
 }
 
+// CreateRefundRequest represents a request to refund unearned premium,
+// typically after a policy is cancelled mid-term
+type CreateRefundRequest struct {
+	PolicyID   string  `json:"policyId"`
+	CustomerID string  `json:"customerId"`
+	Amount     float64 `json:"amount"`
+}
+
 // Validate validates a CreatePaymentRequest
 func (r *CreatePaymentRequest) Validate() error {
 	if r.PolicyID == "" {
@@ -75,6 +119,26 @@ func (r *CreatePayoutRequest) Validate() error {
 	return nil
 }
 
+// Validate validates a CreateRefundRequest
+func (r *CreateRefundRequest) Validate() error {
+	if r.PolicyID == "" {
+		return &ValidationError{Field: "policyId", Message: "policy ID is required"}
+	}
+	if r.CustomerID == "" {
+		return &ValidationError{Field: "customerId", Message: "customer ID is required"}
+	}
+	if r.Amount <= 0 {
+		return &ValidationError{Field: "amount", Message: "amount must be greater than 0"}
+	}
+	return nil
+}
+
+// PaymentStatusRequest carries the reason for failing or refunding a
+// payment
+type PaymentStatusRequest struct {
+	Reason string `json:"reason"`
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Field   string
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// ReminderKind says whether a reminder is for a premium still to fall due
+// or one already overdue
+type ReminderKind string
+
+const (
+	ReminderKindUpcoming ReminderKind = "upcoming" // sent on or before the due date
+	ReminderKindOverdue  ReminderKind = "overdue"  // sent after the due date
+)
+
+// ReminderStatus is what became of a reminder
+type ReminderStatus string
+
+const (
+	ReminderStatusSent     ReminderStatus = "sent"
+	ReminderStatusOptedOut ReminderStatus = "opted_out" // the customer opted out of payment reminders
+	ReminderStatusFailed   ReminderStatus = "failed"    // tried again on the next run
+)
+
+// Reminder is one reminder of an unpaid premium invoice, sent OffsetDays
+// from its due date: negative before it, positive after
+type Reminder struct {
+	ID           string         `json:"id"`
+	InvoiceID    string         `json:"invoiceId"`
+	PolicyID     string         `json:"policyId"`
+	PolicyNumber string         `json:"policyNumber,omitempty"`
+	CustomerID   string         `json:"customerId"`
+	Kind         ReminderKind   `json:"kind"`
+	OffsetDays   int            `json:"offsetDays"`
+	DueDate      time.Time      `json:"dueDate"`
+	Amount       float64        `json:"amount"` // outstanding on the invoice
+	Status       ReminderStatus `json:"status"`
+	Error        string         `json:"error,omitempty"`
+	Attempts     int            `json:"attempts"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	SentAt       *time.Time     `json:"sentAt,omitempty"`
+}
+
+// ReminderRun is the result of one pass of the reminder job
+type ReminderRun struct {
+	Policies  int         `json:"policies"` // policies checked
+	Sent      int         `json:"sent"`
+	OptedOut  int         `json:"optedOut"`
+	Failed    int         `json:"failed"`
+	Reminders []*Reminder `json:"reminders"` // the reminders this run acted on
+	RunAt     time.Time   `json:"runAt"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ScreeningStatus is the outcome of screening a payout's recipient
+type ScreeningStatus string
+
+const (
+	ScreeningStatusClear ScreeningStatus = "clear" // no list entry may be the recipient
+	ScreeningStatusHit   ScreeningStatus = "hit"   // one or more entries may be the recipient
+	ScreeningStatusError ScreeningStatus = "error" // the recipient could not be screened
+)
+
+// Screening records how a payout's recipient was screened against
+// sanctions lists before it was paid, and who released it if it was
+// blocked
+type Screening struct {
+	Status        ScreeningStatus `json:"status"`
+	List          string          `json:"list"`           // screener and list searched, such as ofac-sdn
+	Name          string          `json:"name,omitempty"` // recipient name screened
+	Country       string          `json:"country,omitempty"`
+	Hits          []ScreeningHit  `json:"hits,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	ScreenedAt    time.Time       `json:"screenedAt"`
+	ReleasedBy    string          `json:"releasedBy,omitempty"`
+	ReleasedAt    *time.Time      `json:"releasedAt,omitempty"`
+	ReleaseReason string          `json:"releaseReason,omitempty"`
+}
+
+// ScreeningHit is a list entry that may be the payout's recipient
+type ScreeningHit struct {
+	EntryID  string   `json:"entryId"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type,omitempty"` // individual, or empty for entities
+	Programs []string `json:"programs,omitempty"`
+	Alias    string   `json:"alias,omitempty"` // the alias matched, when not the primary name
+}
+
+// Released reports whether compliance released the payout after it was
+// blocked, so it is paid without being screened again
+func (s *Screening) Released() bool {
+	return s != nil && s.ReleasedAt != nil
+}
@@ -0,0 +1,129 @@
+// Package notify sends payments-service events, such as premium payment
+// reminders, on to the notification service. Events are POSTed as JSON to
+// webhook endpoints, signed like claims-service's webhooks, or written to
+// the service log when no endpoint is configured.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+	"github.com/sirupsen/logrus"
+)
+
+// Headers sent with every delivery. The signature header holds one
+// HMAC-SHA256 of the body per secret (see pkg/signing), sent when secrets
+// are set.
+const (
+	HeaderEventType = "X-Event-Type"
+	HeaderEventID   = "X-Event-ID"
+	HeaderSignature = signing.Header
+)
+
+// Event is a notification sent to the notification service. ID is stable
+// for the same notification, so receivers can drop one delivered twice.
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"`
+}
+
+// Notifier delivers events
+type Notifier interface {
+	Notify(ctx context.Context, evt Event) error
+}
+
+var (
+	_ Notifier = (*Webhook)(nil)
+	_ Notifier = (*Log)(nil)
+)
+
+// Webhook POSTs each event to every endpoint. Delivery is attempted once;
+// callers retry failed events later.
+type Webhook struct {
+	urls    []string
+	secrets signing.Keys
+	client  *http.Client
+}
+
+// NewWebhook creates a notifier for the given endpoints. Deliveries are
+// signed with every one of secrets, so receivers can rotate theirs; without
+// secrets they are sent unsigned.
+func NewWebhook(urls []string, secrets signing.Keys, timeout time.Duration) *Webhook {
+	return &Webhook{
+		urls:    urls,
+		secrets: secrets,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Notify delivers evt to every endpoint, returning the first failure. The
+// endpoints that succeeded are sent the event again if it is retried.
+func (w *Webhook) Notify(ctx context.Context, evt Event) error {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var first error
+	for _, endpoint := range w.urls {
+		if err := w.send(ctx, endpoint, evt, payload); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// send makes one delivery. Any response other than 2xx is a failure.
+func (w *Webhook) send(ctx context.Context, endpoint string, evt Event, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid webhook endpoint: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventType, evt.Type)
+	req.Header.Set(HeaderEventID, evt.ID)
+	if len(w.secrets) > 0 {
+		req.Header.Set(HeaderSignature, w.secrets.Sign(payload))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Log writes events to the service log, for running without a
+// notification service
+type Log struct {
+	logger *logrus.Logger
+}
+
+// NewLog creates a notifier that logs events
+func NewLog(logger *logrus.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+// Notify logs evt and never fails
+func (l *Log) Notify(ctx context.Context, evt Event) error {
+	l.logger.WithFields(logrus.Fields{
+		"eventId":   evt.ID,
+		"eventType": evt.Type,
+		"data":      evt.Data,
+	}).Info("Notification")
+	return nil
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/signing"
+)
+
+func TestWebhookSignsAndDeliversToEveryEndpoint(t *testing.T) {
+	var received []Event
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// Receivers still on the old secret verify deliveries signed during
+		// a rotation
+		if got := r.Header.Get(HeaderSignature); !(signing.Keys{"s3cret"}).Verify(body, got) {
+			t.Errorf("Unexpected signature %q", got)
+		}
+		if r.Header.Get(HeaderEventType) != "payment.reminder.upcoming" || r.Header.Get(HeaderEventID) != "rem-001" {
+			t.Errorf("Unexpected headers: %v", r.Header)
+		}
+		var evt Event
+		if err := json.Unmarshal(body, &evt); err != nil {
+			t.Errorf("Invalid body: %v", err)
+		}
+		received = append(received, evt)
+	}))
+	defer ok.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	evt := Event{ID: "rem-001", Type: "payment.reminder.upcoming", OccurredAt: time.Now(), Data: map[string]string{"policyId": "pol-001"}}
+	if err := NewWebhook([]string{ok.URL}, signing.Keys{"s3cret-2026", "s3cret"}, time.Second).Notify(context.Background(), evt); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(received) != 1 || received[0].ID != "rem-001" {
+		t.Fatalf("Unexpected deliveries: %+v", received)
+	}
+
+	// A failing endpoint fails the event, after the others were sent it
+	err := NewWebhook([]string{down.URL, ok.URL}, signing.Keys{"s3cret"}, time.Second).Notify(context.Background(), evt)
+	if err == nil || err.Error() != "webhook endpoint returned 502" {
+		t.Errorf("Expected the failing endpoint's error, got %v", err)
+	}
+	if len(received) != 2 {
+		t.Errorf("Expected the healthy endpoint still delivered to, got %d deliveries", len(received))
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+)
+
+// loadAgents loads agents from a JSON file
+func (r *Repository) loadAgents(filePath string) error {
+	var agents []*models.Agent
+	result, err := migrate.Load(filePath, agentMigrations, &agents)
+	if err != nil {
+		return err
+	}
+	logMigration(r.logger, filePath, result)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, agent := range agents {
+		r.agents[agent.ID] = agent
+	}
+
+	return nil
+}
+
+// GetAllAgents returns every agent ordered by ID
+func (r *Repository) GetAllAgents() []*models.Agent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	agents := make([]*models.Agent, 0, len(r.agents))
+	for _, agent := range r.agents {
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+
+	return agents
+}
+
+// GetAgentByID retrieves an agent by ID
+func (r *Repository) GetAgentByID(agentID string) (*models.Agent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	agent, exists := r.agents[agentID]
+	if !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+
+	return agent, nil
+}
+
+// CreateAgent stores a new agent
+func (r *Repository) CreateAgent(agent *models.Agent) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("agents", agent.ID, agent); err != nil {
+		return err
+	}
+	r.agents[agent.ID] = agent
+	r.changed(hooks.OpCreate, "agents", agent.ID, agent)
+	return nil
+}
+
+// UpdateAgent updates an existing agent
+func (r *Repository) UpdateAgent(agent *models.Agent) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.agents[agent.ID]; !exists {
+		return fmt.Errorf("agent not found")
+	}
+
+	if err := r.journal.Put("agents", agent.ID, agent); err != nil {
+		return err
+	}
+	r.agents[agent.ID] = agent
+	r.changed(hooks.OpUpdate, "agents", agent.ID, agent)
+	return nil
+}
+
+// CreateCommission stores a commission earned on a premium payment
+func (r *Repository) CreateCommission(commission *models.Commission) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("commissions", commission.ID, commission); err != nil {
+		return err
+	}
+	r.commissions[commission.ID] = commission
+	r.changed(hooks.OpCreate, "commissions", commission.ID, commission)
+	return nil
+}
+
+// GetCommissionsByAgent returns an agent's commissions, oldest first
+func (r *Repository) GetCommissionsByAgent(agentID string) []*models.Commission {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var commissions []*models.Commission
+	for _, commission := range r.commissions {
+		if commission.AgentID == agentID {
+			commissions = append(commissions, commission)
+		}
+	}
+	sort.Slice(commissions, func(i, j int) bool { return commissions[i].EarnedAt.Before(commissions[j].EarnedAt) })
+
+	return commissions
+}
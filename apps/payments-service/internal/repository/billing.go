@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+)
+
+// CreateInvoice stores an invoice issued for a policy's billing period
+func (r *Repository) CreateInvoice(invoice *models.Invoice) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("invoices", invoice.ID, invoice); err != nil {
+		return err
+	}
+	r.invoices[invoice.ID] = invoice
+	r.changed(hooks.OpCreate, "invoices", invoice.ID, invoice)
+	return nil
+}
+
+// GetAllInvoices returns every invoice, by policy and then period
+func (r *Repository) GetAllInvoices() []*models.Invoice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invoices := make([]*models.Invoice, 0, len(r.invoices))
+	for _, invoice := range r.invoices {
+		invoices = append(invoices, invoice)
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		if invoices[i].PolicyID != invoices[j].PolicyID {
+			return invoices[i].PolicyID < invoices[j].PolicyID
+		}
+		return invoices[i].Period < invoices[j].Period
+	})
+
+	return invoices
+}
+
+// GetInvoicesByPolicy returns a policy's invoices, earliest period first
+func (r *Repository) GetInvoicesByPolicy(policyID string) []*models.Invoice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var invoices []*models.Invoice
+	for _, invoice := range r.invoices {
+		if invoice.PolicyID == policyID {
+			invoices = append(invoices, invoice)
+		}
+	}
+	sort.Slice(invoices, func(i, j int) bool { return invoices[i].Period < invoices[j].Period })
+
+	return invoices
+}
+
+// CreateAllocation stores the part of a payment applied to an invoice
+func (r *Repository) CreateAllocation(allocation *models.Allocation) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("allocations", allocation.ID, allocation); err != nil {
+		return err
+	}
+	r.allocations[allocation.ID] = allocation
+	r.changed(hooks.OpCreate, "allocations", allocation.ID, allocation)
+	return nil
+}
+
+// GetAllocationsByPolicy returns the allocations made on a policy's
+// invoices, oldest first
+func (r *Repository) GetAllocationsByPolicy(policyID string) []*models.Allocation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var allocations []*models.Allocation
+	for _, allocation := range r.allocations {
+		if allocation.PolicyID == policyID {
+			allocations = append(allocations, allocation)
+		}
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		if !allocations[i].AllocatedAt.Equal(allocations[j].AllocatedAt) {
+			return allocations[i].AllocatedAt.Before(allocations[j].AllocatedAt)
+		}
+		return allocations[i].ID < allocations[j].ID
+	})
+
+	return allocations
+}
+
+// CreateLateFee stores a late fee charged on an invoice
+func (r *Repository) CreateLateFee(fee *models.LateFee) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("lateFees", fee.ID, fee); err != nil {
+		return err
+	}
+	r.lateFees[fee.ID] = fee
+	r.changed(hooks.OpCreate, "lateFees", fee.ID, fee)
+	return nil
+}
+
+// UpdateLateFee replaces a late fee, e.g. as interest accrues
+func (r *Repository) UpdateLateFee(fee *models.LateFee) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.lateFees[fee.ID]; !exists {
+		return fmt.Errorf("late fee not found")
+	}
+	if err := r.journal.Put("lateFees", fee.ID, fee); err != nil {
+		return err
+	}
+	r.lateFees[fee.ID] = fee
+	r.changed(hooks.OpUpdate, "lateFees", fee.ID, fee)
+	return nil
+}
+
+// GetLateFeesByPolicy returns the late fees charged on a policy's invoices,
+// oldest first
+func (r *Repository) GetLateFeesByPolicy(policyID string) []*models.LateFee {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var fees []*models.LateFee
+	for _, fee := range r.lateFees {
+		if fee.PolicyID == policyID {
+			fees = append(fees, fee)
+		}
+	}
+	sort.Slice(fees, func(i, j int) bool {
+		if !fees[i].ChargedAt.Equal(fees[j].ChargedAt) {
+			return fees[i].ChargedAt.Before(fees[j].ChargedAt)
+		}
+		return fees[i].ID < fees[j].ID
+	})
+
+	return fees
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+)
+
+// GetAllDisputes returns every dispute, oldest first
+func (r *Repository) GetAllDisputes() []*models.Dispute {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	disputes := make([]*models.Dispute, 0, len(r.disputes))
+	for _, dispute := range r.disputes {
+		disputes = append(disputes, dispute)
+	}
+	sortDisputes(disputes)
+
+	return disputes
+}
+
+// GetDisputeByID retrieves a dispute by ID
+func (r *Repository) GetDisputeByID(disputeID string) (*models.Dispute, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	dispute, exists := r.disputes[disputeID]
+	if !exists {
+		return nil, fmt.Errorf("dispute not found")
+	}
+
+	return dispute, nil
+}
+
+// GetDisputesByPayment returns the disputes of a payment, oldest first
+func (r *Repository) GetDisputesByPayment(paymentID string) []*models.Dispute {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var disputes []*models.Dispute
+	for _, dispute := range r.disputes {
+		if dispute.PaymentID == paymentID {
+			disputes = append(disputes, dispute)
+		}
+	}
+	sortDisputes(disputes)
+
+	return disputes
+}
+
+// CreateDispute stores a new dispute
+func (r *Repository) CreateDispute(dispute *models.Dispute) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("disputes", dispute.ID, dispute); err != nil {
+		return err
+	}
+	r.disputes[dispute.ID] = dispute
+	r.changed(hooks.OpCreate, "disputes", dispute.ID, dispute)
+	return nil
+}
+
+// UpdateDispute updates an existing dispute
+func (r *Repository) UpdateDispute(dispute *models.Dispute) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.disputes[dispute.ID]; !exists {
+		return fmt.Errorf("dispute not found")
+	}
+
+	if err := r.journal.Put("disputes", dispute.ID, dispute); err != nil {
+		return err
+	}
+	r.disputes[dispute.ID] = dispute
+	r.changed(hooks.OpUpdate, "disputes", dispute.ID, dispute)
+	return nil
+}
+
+// sortDisputes orders disputes by when they were opened
+func sortDisputes(disputes []*models.Dispute) {
+	sort.Slice(disputes, func(i, j int) bool {
+		if !disputes[i].OpenedAt.Equal(disputes[j].OpenedAt) {
+			return disputes[i].OpenedAt.Before(disputes[j].OpenedAt)
+		}
+		return disputes[i].ID < disputes[j].ID
+	})
+}
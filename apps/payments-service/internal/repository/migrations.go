@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/sirupsen/logrus"
+)
+
+// Migrations of the data files, oldest first. Append one to a file's set
+// when a change to its model would misread the files written before it.
+var (
+	paymentMigrations = migrate.Set{}
+	agentMigrations   = migrate.Set{}
+)
+
+// logMigration records an upgrade of the data file at path
+func logMigration(logger *logrus.Logger, path string, result migrate.Result) {
+	if !result.Migrated() {
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"from":   result.From,
+		"to":     result.To,
+		"backup": result.Backup,
+	}).Infof("Migrated %s: %s", path, strings.Join(result.Applied, "; "))
+}
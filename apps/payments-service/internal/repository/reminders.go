@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+)
+
+// GetAllReminders returns every payment reminder, oldest first
+func (r *Repository) GetAllReminders() []*models.Reminder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reminders := make([]*models.Reminder, 0, len(r.reminders))
+	for _, reminder := range r.reminders {
+		reminders = append(reminders, reminder)
+	}
+	sort.Slice(reminders, func(i, j int) bool {
+		if !reminders[i].CreatedAt.Equal(reminders[j].CreatedAt) {
+			return reminders[i].CreatedAt.Before(reminders[j].CreatedAt)
+		}
+		return reminders[i].ID < reminders[j].ID
+	})
+
+	return reminders
+}
+
+// GetReminderByID retrieves a payment reminder by ID
+func (r *Repository) GetReminderByID(reminderID string) (*models.Reminder, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reminder, exists := r.reminders[reminderID]
+	if !exists {
+		return nil, fmt.Errorf("reminder not found")
+	}
+
+	return reminder, nil
+}
+
+// CreateReminder stores a new payment reminder
+func (r *Repository) CreateReminder(reminder *models.Reminder) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if err := r.journal.Put("reminders", reminder.ID, reminder); err != nil {
+		return err
+	}
+	r.reminders[reminder.ID] = reminder
+	r.changed(hooks.OpCreate, "reminders", reminder.ID, reminder)
+	return nil
+}
+
+// UpdateReminder updates an existing payment reminder
+func (r *Repository) UpdateReminder(reminder *models.Reminder) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.reminders[reminder.ID]; !exists {
+		return fmt.Errorf("reminder not found")
+	}
+
+	if err := r.journal.Put("reminders", reminder.ID, reminder); err != nil {
+		return err
+	}
+	r.reminders[reminder.ID] = reminder
+	r.changed(hooks.OpUpdate, "reminders", reminder.ID, reminder)
+	return nil
+}
@@ -1,28 +1,53 @@
 package repository
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
 	"github.com/sirupsen/logrus"
 )
 
-// Repository provides data access for payments
+// Repository provides data access for payments, agents, commissions, the
+// invoices, allocations and late fees of premium billing, payment disputes
+// and payment reminders.
+// Register hooks with OnCreate and OnUpdate to follow its writes.
 type Repository struct {
-	payments map[string]*models.Payment
-	mu       sync.RWMutex
-	logger   *logrus.Logger
+	hooks.Hooks
+
+	payments    map[string]*models.Payment
+	archived    map[string]*models.Payment // payments moved out by the retention policy
+	agents      map[string]*models.Agent
+	commissions map[string]*models.Commission
+	invoices    map[string]*models.Invoice
+	allocations map[string]*models.Allocation
+	lateFees    map[string]*models.LateFee
+	disputes    map[string]*models.Dispute
+	reminders   map[string]*models.Reminder
+	journal     *persist.Journal // nil unless Persist is called
+	pending     []hooks.Change   // written under mu, announced by unlock
+	mu          sync.RWMutex
+	logger      *logrus.Logger
 }
 
 // NewRepository creates a new repository and loads data from JSON files
 func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error) {
 	repo := &Repository{
-		payments: make(map[string]*models.Payment),
-		logger:   logger,
+		payments:    make(map[string]*models.Payment),
+		archived:    make(map[string]*models.Payment),
+		agents:      make(map[string]*models.Agent),
+		commissions: make(map[string]*models.Commission),
+		invoices:    make(map[string]*models.Invoice),
+		allocations: make(map[string]*models.Allocation),
+		lateFees:    make(map[string]*models.LateFee),
+		disputes:    make(map[string]*models.Dispute),
+		reminders:   make(map[string]*models.Reminder),
+		logger:      logger,
 	}
 
 	// Try to load payments if the file exists
@@ -37,20 +62,121 @@ func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error)
 
 	logger.Infof("Loaded %d payments from %s", len(repo.payments), dataPath)
 
+	// Agents are optional; without them every policy is direct business
+	agentsFile := filepath.Join(dataPath, "agents.json")
+	if _, err := os.Stat(agentsFile); err == nil {
+		if err := repo.loadAgents(agentsFile); err != nil {
+			logger.WithError(err).Warn("Failed to load agents, starting with no agents")
+		}
+	}
+
+	logger.Infof("Loaded %d agents from %s", len(repo.agents), dataPath)
+
 	return repo, nil
 }
 
-// loadPayments loads payments from a JSON file
-func (r *Repository) loadPayments(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+// Persist replaces the seed data with the state recovered from journal and
+// records every later change in it. Call it before the repository is used.
+func (r *Repository) Persist(journal *persist.Journal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := persist.Restore(journal, "payments", func(id string, payment *models.Payment) {
+		r.payments[id] = payment
+	}, func(id string) {
+		delete(r.payments, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "archivedPayments", func(id string, payment *models.Payment) {
+		r.archived[id] = payment
+	}, func(id string) {
+		delete(r.archived, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "agents", func(id string, agent *models.Agent) {
+		r.agents[id] = agent
+	}, func(id string) {
+		delete(r.agents, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "commissions", func(id string, commission *models.Commission) {
+		r.commissions[id] = commission
+	}, func(id string) {
+		delete(r.commissions, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "invoices", func(id string, invoice *models.Invoice) {
+		r.invoices[id] = invoice
+	}, func(id string) {
+		delete(r.invoices, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "allocations", func(id string, allocation *models.Allocation) {
+		r.allocations[id] = allocation
+	}, func(id string) {
+		delete(r.allocations, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "lateFees", func(id string, fee *models.LateFee) {
+		r.lateFees[id] = fee
+	}, func(id string) {
+		delete(r.lateFees, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "disputes", func(id string, dispute *models.Dispute) {
+		r.disputes[id] = dispute
+	}, func(id string) {
+		delete(r.disputes, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "reminders", func(id string, reminder *models.Reminder) {
+		r.reminders[id] = reminder
+	}, func(id string) {
+		delete(r.reminders, id)
+	}); err != nil {
 		return err
 	}
 
+	// Archiving writes the archived copy before deleting the live payment,
+	// so a crash in between can restore both; the archived copy is the later
+	for id := range r.archived {
+		delete(r.payments, id)
+	}
+
+	r.journal = journal
+	return nil
+}
+
+// changed records a write made under the lock, for unlock to announce
+func (r *Repository) changed(op, collection, key string, value interface{}) {
+	r.pending = append(r.pending, hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+}
+
+// unlock releases the write lock and then runs the hooks of the writes
+// made under it, so hooks can use the repository
+func (r *Repository) unlock() {
+	changes := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	r.Notify(changes...)
+}
+
+// loadPayments loads payments from a JSON file
+func (r *Repository) loadPayments(filePath string) error {
 	var payments []*models.Payment
-	if err := json.Unmarshal(data, &payments); err != nil {
+	result, err := migrate.Load(filePath, paymentMigrations, &payments)
+	if err != nil {
 		return err
 	}
+	logMigration(r.logger, filePath, result)
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -91,21 +217,77 @@ func (r *Repository) GetPaymentByID(paymentID string) (*models.Payment, error) {
 // CreatePayment creates a new payment
 func (r *Repository) CreatePayment(payment *models.Payment) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
+	if err := r.journal.Put("payments", payment.ID, payment); err != nil {
+		return err
+	}
 	r.payments[payment.ID] = payment
+	r.changed(hooks.OpCreate, "payments", payment.ID, payment)
 	return nil
 }
 
 // UpdatePayment updates an existing payment
 func (r *Repository) UpdatePayment(payment *models.Payment) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
 	if _, exists := r.payments[payment.ID]; !exists {
 		return fmt.Errorf("payment not found")
 	}
 
+	if err := r.journal.Put("payments", payment.ID, payment); err != nil {
+		return err
+	}
 	r.payments[payment.ID] = payment
+	r.changed(hooks.OpUpdate, "payments", payment.ID, payment)
+	return nil
+}
+
+// ArchivePayment moves a payment to the archive
+func (r *Repository) ArchivePayment(payment *models.Payment) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.payments[payment.ID]; !exists {
+		return fmt.Errorf("payment not found")
+	}
+
+	if err := r.journal.Put("archivedPayments", payment.ID, payment); err != nil {
+		return err
+	}
+	if err := r.journal.Delete("payments", payment.ID); err != nil {
+		return err
+	}
+	r.archived[payment.ID] = payment
+	delete(r.payments, payment.ID)
+	r.changed(hooks.OpDelete, "payments", payment.ID, nil)
+	r.changed(hooks.OpCreate, "archivedPayments", payment.ID, payment)
 	return nil
 }
+
+// GetArchivedPayments returns all archived payments
+func (r *Repository) GetArchivedPayments() ([]*models.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payments := make([]*models.Payment, 0, len(r.archived))
+	for _, payment := range r.archived {
+		payments = append(payments, payment)
+	}
+
+	return payments, nil
+}
+
+// GetArchivedPayment retrieves an archived payment by ID
+func (r *Repository) GetArchivedPayment(paymentID string) (*models.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	payment, exists := r.archived[paymentID]
+	if !exists {
+		return nil, fmt.Errorf("payment not found")
+	}
+
+	return payment, nil
+}
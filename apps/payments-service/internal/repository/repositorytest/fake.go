@@ -0,0 +1,425 @@
+// Package repositorytest provides in-memory implementations of the
+// repository's stores for unit tests
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+)
+
+// FakeStore is an in-memory PaymentStore, AgentStore, BillingStore,
+// DisputeStore and ReminderStore. Set Err to make every payment call fail,
+// e.g. to exercise a handler's error path.
+type FakeStore struct {
+	Err error
+
+	mu          sync.Mutex
+	payments    map[string]*models.Payment
+	archived    map[string]*models.Payment
+	agents      map[string]*models.Agent
+	commissions []*models.Commission
+	invoices    []*models.Invoice
+	allocations []*models.Allocation
+	lateFees    map[string]*models.LateFee
+	disputes    map[string]*models.Dispute
+	reminders   map[string]*models.Reminder
+}
+
+var (
+	_ repository.PaymentStore  = (*FakeStore)(nil)
+	_ repository.AgentStore    = (*FakeStore)(nil)
+	_ repository.BillingStore  = (*FakeStore)(nil)
+	_ repository.DisputeStore  = (*FakeStore)(nil)
+	_ repository.ReminderStore = (*FakeStore)(nil)
+)
+
+// NewFakeStore creates a fake holding the given payments
+func NewFakeStore(payments ...*models.Payment) *FakeStore {
+	f := &FakeStore{
+		payments:  make(map[string]*models.Payment),
+		archived:  make(map[string]*models.Payment),
+		agents:    make(map[string]*models.Agent),
+		lateFees:  make(map[string]*models.LateFee),
+		disputes:  make(map[string]*models.Dispute),
+		reminders: make(map[string]*models.Reminder),
+	}
+	for _, payment := range payments {
+		f.payments[payment.ID] = payment
+	}
+	return f
+}
+
+// GetAllPayments returns every payment ordered by ID
+func (f *FakeStore) GetAllPayments() ([]*models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	payments := make([]*models.Payment, 0, len(f.payments))
+	for _, payment := range f.payments {
+		payments = append(payments, payment)
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	return payments, nil
+}
+
+// GetPaymentByID returns the stored payment
+func (f *FakeStore) GetPaymentByID(paymentID string) (*models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	payment, exists := f.payments[paymentID]
+	if !exists {
+		return nil, fmt.Errorf("payment not found")
+	}
+	return payment, nil
+}
+
+// CreatePayment stores a new payment
+func (f *FakeStore) CreatePayment(payment *models.Payment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	f.payments[payment.ID] = payment
+	return nil
+}
+
+// UpdatePayment replaces a stored payment
+func (f *FakeStore) UpdatePayment(payment *models.Payment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.payments[payment.ID]; !exists {
+		return fmt.Errorf("payment not found")
+	}
+	f.payments[payment.ID] = payment
+	return nil
+}
+
+// ArchivePayment moves a stored payment to the archive
+func (f *FakeStore) ArchivePayment(payment *models.Payment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.payments[payment.ID]; !exists {
+		return fmt.Errorf("payment not found")
+	}
+	f.archived[payment.ID] = payment
+	delete(f.payments, payment.ID)
+	return nil
+}
+
+// GetArchivedPayments returns every archived payment ordered by ID
+func (f *FakeStore) GetArchivedPayments() ([]*models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	payments := make([]*models.Payment, 0, len(f.archived))
+	for _, payment := range f.archived {
+		payments = append(payments, payment)
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	return payments, nil
+}
+
+// GetArchivedPayment returns an archived payment
+func (f *FakeStore) GetArchivedPayment(paymentID string) (*models.Payment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	payment, exists := f.archived[paymentID]
+	if !exists {
+		return nil, fmt.Errorf("payment not found")
+	}
+	return payment, nil
+}
+
+// AddAgent stores an agent
+func (f *FakeStore) AddAgent(agent *models.Agent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.agents[agent.ID] = agent
+}
+
+// GetAllAgents returns every agent ordered by ID
+func (f *FakeStore) GetAllAgents() []*models.Agent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	agents := make([]*models.Agent, 0, len(f.agents))
+	for _, agent := range f.agents {
+		agents = append(agents, agent)
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].ID < agents[j].ID })
+	return agents
+}
+
+// GetAgentByID returns the stored agent
+func (f *FakeStore) GetAgentByID(agentID string) (*models.Agent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	agent, exists := f.agents[agentID]
+	if !exists {
+		return nil, fmt.Errorf("agent not found")
+	}
+	return agent, nil
+}
+
+// CreateAgent stores a new agent
+func (f *FakeStore) CreateAgent(agent *models.Agent) error {
+	f.AddAgent(agent)
+	return nil
+}
+
+// UpdateAgent replaces a stored agent
+func (f *FakeStore) UpdateAgent(agent *models.Agent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.agents[agent.ID]; !exists {
+		return fmt.Errorf("agent not found")
+	}
+	f.agents[agent.ID] = agent
+	return nil
+}
+
+// CreateCommission records a commission
+func (f *FakeStore) CreateCommission(commission *models.Commission) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commissions = append(f.commissions, commission)
+	return nil
+}
+
+// GetCommissionsByAgent returns an agent's commissions in the order recorded
+func (f *FakeStore) GetCommissionsByAgent(agentID string) []*models.Commission {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var commissions []*models.Commission
+	for _, commission := range f.commissions {
+		if commission.AgentID == agentID {
+			commissions = append(commissions, commission)
+		}
+	}
+	return commissions
+}
+
+// CreateInvoice records an invoice
+func (f *FakeStore) CreateInvoice(invoice *models.Invoice) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.invoices = append(f.invoices, invoice)
+	return nil
+}
+
+// GetAllInvoices returns every invoice in the order issued
+func (f *FakeStore) GetAllInvoices() []*models.Invoice {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*models.Invoice(nil), f.invoices...)
+}
+
+// GetInvoicesByPolicy returns a policy's invoices, earliest period first
+func (f *FakeStore) GetInvoicesByPolicy(policyID string) []*models.Invoice {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var invoices []*models.Invoice
+	for _, invoice := range f.invoices {
+		if invoice.PolicyID == policyID {
+			invoices = append(invoices, invoice)
+		}
+	}
+	sort.Slice(invoices, func(i, j int) bool { return invoices[i].Period < invoices[j].Period })
+	return invoices
+}
+
+// CreateAllocation records an allocation
+func (f *FakeStore) CreateAllocation(allocation *models.Allocation) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.allocations = append(f.allocations, allocation)
+	return nil
+}
+
+// GetAllocationsByPolicy returns a policy's allocations in the order
+// recorded
+func (f *FakeStore) GetAllocationsByPolicy(policyID string) []*models.Allocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var allocations []*models.Allocation
+	for _, allocation := range f.allocations {
+		if allocation.PolicyID == policyID {
+			allocations = append(allocations, allocation)
+		}
+	}
+	return allocations
+}
+
+// CreateLateFee stores a late fee
+func (f *FakeStore) CreateLateFee(fee *models.LateFee) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lateFees[fee.ID] = fee
+	return nil
+}
+
+// UpdateLateFee replaces a stored late fee
+func (f *FakeStore) UpdateLateFee(fee *models.LateFee) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.lateFees[fee.ID]; !exists {
+		return fmt.Errorf("late fee not found")
+	}
+	f.lateFees[fee.ID] = fee
+	return nil
+}
+
+// GetLateFeesByPolicy returns a policy's late fees, oldest first
+func (f *FakeStore) GetLateFeesByPolicy(policyID string) []*models.LateFee {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var fees []*models.LateFee
+	for _, fee := range f.lateFees {
+		if fee.PolicyID == policyID {
+			fees = append(fees, fee)
+		}
+	}
+	sort.Slice(fees, func(i, j int) bool {
+		if !fees[i].ChargedAt.Equal(fees[j].ChargedAt) {
+			return fees[i].ChargedAt.Before(fees[j].ChargedAt)
+		}
+		return fees[i].ID < fees[j].ID
+	})
+	return fees
+}
+
+// GetAllDisputes returns every dispute ordered by ID
+func (f *FakeStore) GetAllDisputes() []*models.Dispute {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	disputes := make([]*models.Dispute, 0, len(f.disputes))
+	for _, dispute := range f.disputes {
+		disputes = append(disputes, dispute)
+	}
+	sort.Slice(disputes, func(i, j int) bool { return disputes[i].ID < disputes[j].ID })
+	return disputes
+}
+
+// GetDisputeByID returns the stored dispute
+func (f *FakeStore) GetDisputeByID(disputeID string) (*models.Dispute, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	dispute, ok := f.disputes[disputeID]
+	if !ok {
+		return nil, fmt.Errorf("dispute not found")
+	}
+	return dispute, nil
+}
+
+// GetDisputesByPayment returns a payment's disputes ordered by ID
+func (f *FakeStore) GetDisputesByPayment(paymentID string) []*models.Dispute {
+	var disputes []*models.Dispute
+	for _, dispute := range f.GetAllDisputes() {
+		if dispute.PaymentID == paymentID {
+			disputes = append(disputes, dispute)
+		}
+	}
+	return disputes
+}
+
+// CreateDispute stores a dispute
+func (f *FakeStore) CreateDispute(dispute *models.Dispute) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disputes[dispute.ID] = dispute
+	return nil
+}
+
+// UpdateDispute replaces a stored dispute
+func (f *FakeStore) UpdateDispute(dispute *models.Dispute) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.disputes[dispute.ID]; !ok {
+		return fmt.Errorf("dispute not found")
+	}
+	f.disputes[dispute.ID] = dispute
+	return nil
+}
+
+// GetAllReminders returns every reminder ordered by ID
+func (f *FakeStore) GetAllReminders() []*models.Reminder {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reminders := make([]*models.Reminder, 0, len(f.reminders))
+	for _, reminder := range f.reminders {
+		reminders = append(reminders, reminder)
+	}
+	sort.Slice(reminders, func(i, j int) bool { return reminders[i].ID < reminders[j].ID })
+	return reminders
+}
+
+// GetReminderByID returns the stored reminder
+func (f *FakeStore) GetReminderByID(reminderID string) (*models.Reminder, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reminder, ok := f.reminders[reminderID]
+	if !ok {
+		return nil, fmt.Errorf("reminder not found")
+	}
+	return reminder, nil
+}
+
+// CreateReminder stores a reminder
+func (f *FakeStore) CreateReminder(reminder *models.Reminder) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reminders[reminder.ID] = reminder
+	return nil
+}
+
+// UpdateReminder replaces a stored reminder
+func (f *FakeStore) UpdateReminder(reminder *models.Reminder) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.reminders[reminder.ID]; !ok {
+		return fmt.Errorf("reminder not found")
+	}
+	f.reminders[reminder.ID] = reminder
+	return nil
+}
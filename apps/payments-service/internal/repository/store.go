@@ -0,0 +1,81 @@
+package repository
+
+import "github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+
+// PaymentStore is the data access the payment service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides an
+// in-memory fake for unit tests. ArchivePayment moves a payment from the
+// live payments to the archive, after which it is only returned by
+// GetArchivedPayments and GetArchivedPayment.
+type PaymentStore interface {
+	GetAllPayments() ([]*models.Payment, error)
+	GetPaymentByID(paymentID string) (*models.Payment, error)
+	CreatePayment(payment *models.Payment) error
+	UpdatePayment(payment *models.Payment) error
+	ArchivePayment(payment *models.Payment) error
+	GetArchivedPayments() ([]*models.Payment, error)
+	GetArchivedPayment(paymentID string) (*models.Payment, error)
+}
+
+var _ PaymentStore = (*Repository)(nil)
+
+// AgentStore is the data access the agent service depends on. Repository
+// is the JSON-backed implementation; repositorytest provides an in-memory
+// fake for unit tests.
+type AgentStore interface {
+	GetAllAgents() []*models.Agent
+	GetAgentByID(agentID string) (*models.Agent, error)
+	CreateAgent(agent *models.Agent) error
+	UpdateAgent(agent *models.Agent) error
+	CreateCommission(commission *models.Commission) error
+	GetCommissionsByAgent(agentID string) []*models.Commission
+}
+
+var _ AgentStore = (*Repository)(nil)
+
+// BillingStore is the data access the billing service depends on: the
+// invoices issued for policies' billing periods, the late fees charged on
+// them, the allocations of premium payments to both, and the payments
+// themselves. Repository is the JSON-backed implementation; repositorytest
+// provides an in-memory fake for unit tests.
+type BillingStore interface {
+	GetAllPayments() ([]*models.Payment, error)
+	CreateInvoice(invoice *models.Invoice) error
+	GetAllInvoices() []*models.Invoice
+	GetInvoicesByPolicy(policyID string) []*models.Invoice
+	CreateAllocation(allocation *models.Allocation) error
+	GetAllocationsByPolicy(policyID string) []*models.Allocation
+	CreateLateFee(fee *models.LateFee) error
+	UpdateLateFee(fee *models.LateFee) error
+	GetLateFeesByPolicy(policyID string) []*models.LateFee
+}
+
+var _ BillingStore = (*Repository)(nil)
+
+// DisputeStore is the data access the dispute service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides an
+// in-memory fake for unit tests.
+type DisputeStore interface {
+	GetAllDisputes() []*models.Dispute
+	GetDisputeByID(disputeID string) (*models.Dispute, error)
+	GetDisputesByPayment(paymentID string) []*models.Dispute
+	CreateDispute(dispute *models.Dispute) error
+	UpdateDispute(dispute *models.Dispute) error
+}
+
+var _ DisputeStore = (*Repository)(nil)
+
+// ReminderStore is the data access the payment reminder job depends on: the
+// premium payments and invoices that say which policies to check, and the
+// reminders already sent. Repository is the JSON-backed implementation;
+// repositorytest provides an in-memory fake for unit tests.
+type ReminderStore interface {
+	GetAllPayments() ([]*models.Payment, error)
+	GetAllInvoices() []*models.Invoice
+	GetAllReminders() []*models.Reminder
+	GetReminderByID(reminderID string) (*models.Reminder, error)
+	CreateReminder(reminder *models.Reminder) error
+	UpdateReminder(reminder *models.Reminder) error
+}
+
+var _ ReminderStore = (*Repository)(nil)
@@ -0,0 +1,205 @@
+package screening
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+)
+
+// ListOFAC names the list OFAC searches on the payments it screens
+const ListOFAC = "ofac-sdn"
+
+// ofacNull is how the SDN files write an empty field
+const ofacNull = "-0-"
+
+// OFAC screens parties against the Specially Designated Nationals list
+// published by the US Treasury's Office of Foreign Assets Control, in its
+// legacy CSV format: sdn.csv and, when it is next to it, alt.csv with the
+// entries' aliases. Vessels and aircraft are not screened.
+//
+// A party may be an entry when, in any order, every word of the entry's
+// name or of one of its aliases is in the party's name, or every word of
+// the party's name is in the entry's, so a middle name given on one side
+// only still matches. Names of a single word only match the same single
+// word. Possible matches block the payout for a person to review, so the
+// test errs towards blocking.
+type OFAC struct {
+	entries []ofacEntry
+}
+
+// ofacEntry is one name on the list, the primary name or an alias
+type ofacEntry struct {
+	hit    models.ScreeningHit
+	tokens []string
+}
+
+// LoadOFAC reads an SDN list from sdn.csv at path, with the aliases in
+// alt.csv in the same directory when there is one
+func LoadOFAC(path string) (*OFAC, error) {
+	records, err := readOFAC(path)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &OFAC{}
+	listed := make(map[string]models.ScreeningHit)
+	for _, record := range records {
+		if len(record) < 4 {
+			continue
+		}
+		id, name, kind := record[0], record[1], ofacField(record[2])
+		if kind == "vessel" || kind == "aircraft" || name == "" {
+			continue
+		}
+		hit := models.ScreeningHit{
+			EntryID:  id,
+			Name:     name,
+			Type:     kind,
+			Programs: ofacPrograms(record[3]),
+		}
+		listed[id] = hit
+		o.add(hit, name)
+	}
+	if len(listed) == 0 {
+		return nil, fmt.Errorf("no entries in %s", path)
+	}
+
+	aliases, err := readOFAC(filepath.Join(filepath.Dir(path), "alt.csv"))
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range aliases {
+		if len(record) < 4 {
+			continue
+		}
+		hit, ok := listed[record[0]]
+		alias := ofacField(record[3])
+		if !ok || alias == "" {
+			continue
+		}
+		hit.Alias = alias
+		o.add(hit, alias)
+	}
+	return o, nil
+}
+
+// Len returns the number of names screened against, aliases included
+func (o *OFAC) Len() int {
+	return len(o.entries)
+}
+
+// Screen implements Screener. A party without a name cannot be screened.
+func (o *OFAC) Screen(ctx context.Context, party Party) (Result, error) {
+	result := Result{List: ListOFAC}
+	words := tokens(party.Name)
+	if len(words) == 0 {
+		return result, fmt.Errorf("customer %s has no name to screen", party.CustomerID)
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range o.entries {
+		if seen[entry.hit.EntryID] || !matches(entry.tokens, words) {
+			continue
+		}
+		seen[entry.hit.EntryID] = true
+		result.Hits = append(result.Hits, entry.hit)
+	}
+	return result, nil
+}
+
+// add lists a name of an entry
+func (o *OFAC) add(hit models.ScreeningHit, name string) {
+	words := tokens(name)
+	if len(words) == 0 {
+		return
+	}
+	o.entries = append(o.entries, ofacEntry{hit: hit, tokens: words})
+}
+
+// matches reports whether the words of either name are all in the other.
+// A single word only matches itself.
+func matches(entry, party []string) bool {
+	if len(entry) == 1 || len(party) == 1 {
+		return len(entry) == len(party) && entry[0] == party[0]
+	}
+	return contains(party, entry) || contains(entry, party)
+}
+
+// contains reports whether every word of sub is in words
+func contains(words, sub []string) bool {
+	for _, word := range sub {
+		found := false
+		for _, w := range words {
+			if w == word {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// readOFAC reads the records of an SDN list file. The files have no
+// header and may end with a DOS end-of-file marker.
+func readOFAC(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SDN list %s: %w", path, err)
+		}
+		if len(record) == 1 && strings.TrimSpace(strings.Trim(record[0], "\x1a")) == "" {
+			continue
+		}
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ofacField returns a field, or "" for the list's null marker
+func ofacField(value string) string {
+	if value == ofacNull {
+		return ""
+	}
+	return value
+}
+
+// ofacPrograms splits the sanctions programs of an entry, written as
+// "SDGT] [IRGC"
+func ofacPrograms(value string) []string {
+	var programs []string
+	for _, program := range strings.Split(ofacField(value), "] [") {
+		if program = strings.Trim(program, "[] "); program != "" {
+			programs = append(programs, program)
+		}
+	}
+	return programs
+}
@@ -0,0 +1,101 @@
+package screening
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadSeedList(t *testing.T) *OFAC {
+	t.Helper()
+	list, err := LoadOFAC(filepath.Join("..", "..", "..", "..", "data", "seed", "sanctions", "sdn.csv"))
+	if err != nil {
+		t.Fatalf("LoadOFAC failed: %v", err)
+	}
+	return list
+}
+
+func TestOFACScreen(t *testing.T) {
+	list := loadSeedList(t)
+	if list.Len() != 5 {
+		t.Errorf("Expected 3 names and 2 aliases, not vessels, got %d", list.Len())
+	}
+
+	tests := []struct {
+		name      string
+		party     string
+		wantEntry string
+		wantAlias string
+	}{
+		{"primary name in any order", "Anatoly Petrovich Volkov", "9001", ""},
+		{"ignores case and punctuation", "elena ramirez-castellanos", "9003", ""},
+		{"alias", "Lena Maria Castellanos", "9003", "CASTELLANOS, Lena"},
+		{"entity", "Ostara Maritime Holdings Ltd", "9002", ""},
+		{"middle name left out", "Anatoly Volkov", "9001", ""},
+		{"surname alone", "Volkov", "", ""},
+		{"vessels are not screened", "Northern Star", "", ""},
+		{"unlisted", "Sarah Chen", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := list.Screen(context.Background(), Party{CustomerID: "cust-001", Name: tt.party})
+			if err != nil {
+				t.Fatalf("Screen failed: %v", err)
+			}
+			if result.List != ListOFAC {
+				t.Errorf("List mismatch: got %q", result.List)
+			}
+			if tt.wantEntry == "" {
+				if len(result.Hits) != 0 {
+					t.Errorf("Expected no hits, got %+v", result.Hits)
+				}
+				return
+			}
+			if len(result.Hits) != 1 || result.Hits[0].EntryID != tt.wantEntry || result.Hits[0].Alias != tt.wantAlias {
+				t.Errorf("Expected entry %s (alias %q), got %+v", tt.wantEntry, tt.wantAlias, result.Hits)
+			}
+		})
+	}
+}
+
+func TestOFACHitCarriesPrograms(t *testing.T) {
+	result, err := loadSeedList(t).Screen(context.Background(), Party{Name: "OSTARA MARITIME HOLDINGS LTD."})
+	if err != nil || len(result.Hits) != 1 {
+		t.Fatalf("Expected one hit, got %+v, %v", result.Hits, err)
+	}
+	hit := result.Hits[0]
+	if hit.Type != "" || len(hit.Programs) != 2 || hit.Programs[0] != "IRAN" || hit.Programs[1] != "SDGT" {
+		t.Errorf("Unexpected hit: %+v", hit)
+	}
+}
+
+func TestOFACRefusesUnnamedParties(t *testing.T) {
+	if _, err := loadSeedList(t).Screen(context.Background(), Party{CustomerID: "cust-009"}); err == nil || err.Error() != "customer cust-009 has no name to screen" {
+		t.Errorf("Expected an error for a party without a name, got %v", err)
+	}
+}
+
+func TestLoadOFAC(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadOFAC(filepath.Join(dir, "sdn.csv")); !os.IsNotExist(err) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+
+	empty := filepath.Join(dir, "sdn.csv")
+	if err := os.WriteFile(empty, []byte("\x1a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOFAC(empty); err == nil {
+		t.Error("Expected a list without entries to be refused")
+	}
+
+	// A list without alt.csv has only its primary names
+	if err := os.WriteFile(empty, []byte(`1,"DOE, John","individual","SDGT","-0- "`+"\n\x1a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	list, err := LoadOFAC(empty)
+	if err != nil || list.Len() != 1 {
+		t.Fatalf("Expected one name, got %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultCommissionRate is the share of premium paid to agents without a
+// rate of their own
+const DefaultCommissionRate = 0.10
+
+// AgentService manages agents and the commission they earn on premiums
+type AgentService struct {
+	repo        repository.AgentStore
+	defaultRate float64
+	logger      *logrus.Logger
+}
+
+// NewAgentService creates a new agent service. defaultRate applies to agents
+// whose own commission rate is zero.
+func NewAgentService(repo repository.AgentStore, defaultRate float64, logger *logrus.Logger) *AgentService {
+	return &AgentService{
+		repo:        repo,
+		defaultRate: defaultRate,
+		logger:      logger,
+	}
+}
+
+// GetAllAgents returns every agent
+func (s *AgentService) GetAllAgents() []*models.Agent {
+	return s.repo.GetAllAgents()
+}
+
+// GetAgentByID returns an agent by ID
+func (s *AgentService) GetAgentByID(agentID string) (*models.Agent, error) {
+	return s.repo.GetAgentByID(agentID)
+}
+
+// CreateAgent registers a new active agent. License numbers must be unique.
+func (s *AgentService) CreateAgent(req *models.CreateAgentRequest) (*models.Agent, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	licenseNumber := strings.TrimSpace(req.LicenseNumber)
+	if s.licenseTaken(licenseNumber, "") {
+		return nil, fmt.Errorf("license number already registered")
+	}
+
+	now := time.Now()
+	agent := &models.Agent{
+		ID:             fmt.Sprintf("agent-%d", now.UnixNano()),
+		Name:           strings.TrimSpace(req.Name),
+		Email:          req.Email,
+		LicenseNumber:  licenseNumber,
+		LicenseState:   req.LicenseState,
+		Status:         models.AgentStatusActive,
+		CommissionRate: req.CommissionRate,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := s.repo.CreateAgent(agent); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"agentId":       agent.ID,
+		"licenseNumber": agent.LicenseNumber,
+	}).Info("Agent registered")
+
+	return agent, nil
+}
+
+// UpdateAgent changes an agent's details, rate or status. Rate changes only
+// affect commission earned afterwards.
+func (s *AgentService) UpdateAgent(agentID string, req *models.UpdateAgentRequest) (*models.Agent, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetAgentByID(agentID)
+	if err != nil {
+		return nil, err
+	}
+	agent := *existing
+
+	if req.LicenseNumber != nil {
+		licenseNumber := strings.TrimSpace(*req.LicenseNumber)
+		if s.licenseTaken(licenseNumber, agentID) {
+			return nil, fmt.Errorf("license number already registered")
+		}
+		agent.LicenseNumber = licenseNumber
+	}
+	if req.Name != nil {
+		agent.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Email != nil {
+		agent.Email = *req.Email
+	}
+	if req.LicenseState != nil {
+		agent.LicenseState = *req.LicenseState
+	}
+	if req.CommissionRate != nil {
+		agent.CommissionRate = *req.CommissionRate
+	}
+	if req.Status != nil {
+		agent.Status = *req.Status
+	}
+	agent.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateAgent(&agent); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"agentId": agent.ID,
+		"status":  agent.Status,
+	}).Info("Agent updated")
+
+	return &agent, nil
+}
+
+// DeactivateAgent stops an agent earning commission. The agent and past
+// commission are kept for statements.
+func (s *AgentService) DeactivateAgent(agentID string) (*models.Agent, error) {
+	status := models.AgentStatusInactive
+	return s.UpdateAgent(agentID, &models.UpdateAgentRequest{Status: &status})
+}
+
+// RecordCommission credits the agent on a completed premium payment. Other
+// payment types, payments without an agent and payments for inactive or
+// unknown agents earn nothing and return nil.
+func (s *AgentService) RecordCommission(payment *models.Payment) (*models.Commission, error) {
+	if payment.Type != models.PaymentTypePremium || payment.AgentID == "" || payment.Status != models.PaymentStatusCompleted {
+		return nil, nil
+	}
+
+	agent, err := s.repo.GetAgentByID(payment.AgentID)
+	if err != nil {
+		s.logger.WithField("agentId", payment.AgentID).Warn("Premium paid for unknown agent, no commission recorded")
+		return nil, nil
+	}
+	if agent.Status != models.AgentStatusActive {
+		return nil, nil
+	}
+
+	rate := s.rateFor(agent)
+	earnedAt := payment.UpdatedAt
+	if payment.ProcessedDate != nil {
+		earnedAt = *payment.ProcessedDate
+	}
+	commission := &models.Commission{
+		ID:        "com-" + payment.ID,
+		AgentID:   agent.ID,
+		PaymentID: payment.ID,
+		PolicyID:  payment.PolicyID,
+		Premium:   payment.Amount,
+		Rate:      rate,
+		Amount:    roundCents(payment.Amount * rate),
+		EarnedAt:  earnedAt,
+	}
+
+	if err := s.repo.CreateCommission(commission); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"agentId":   agent.ID,
+		"paymentId": payment.ID,
+		"rate":      rate,
+		"amount":    commission.Amount,
+	}).Info("Commission recorded")
+
+	return commission, nil
+}
+
+// ReverseCommission cancels the commission earned on a refunded or
+// charged back premium by recording an offsetting negative commission in the
+// month of the refund or chargeback. Payments that earned no commission, or
+// were already reversed, return nil.
+func (s *AgentService) ReverseCommission(payment *models.Payment) (*models.Commission, error) {
+	if payment.Type != models.PaymentTypePremium || payment.AgentID == "" {
+		return nil, nil
+	}
+	if payment.Status != models.PaymentStatusRefunded && payment.Status != models.PaymentStatusChargedBack {
+		return nil, nil
+	}
+
+	var earned *models.Commission
+	reversalID := "com-" + payment.ID + "-reversal"
+	for _, commission := range s.repo.GetCommissionsByAgent(payment.AgentID) {
+		switch commission.ID {
+		case reversalID:
+			return nil, nil
+		case "com-" + payment.ID:
+			earned = commission
+		}
+	}
+	if earned == nil {
+		return nil, nil
+	}
+
+	reversedAt := payment.UpdatedAt
+	if payment.RefundedDate != nil {
+		reversedAt = *payment.RefundedDate
+	}
+	reversal := &models.Commission{
+		ID:        reversalID,
+		AgentID:   earned.AgentID,
+		PaymentID: payment.ID,
+		PolicyID:  payment.PolicyID,
+		Premium:   -earned.Premium,
+		Rate:      earned.Rate,
+		Amount:    -earned.Amount,
+		EarnedAt:  reversedAt,
+	}
+
+	if err := s.repo.CreateCommission(reversal); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"agentId":   earned.AgentID,
+		"paymentId": payment.ID,
+		"amount":    reversal.Amount,
+	}).Info("Commission reversed")
+
+	return reversal, nil
+}
+
+// GetCommissionStatement totals the commission an agent earned in month,
+// given as YYYY-MM in UTC
+func (s *AgentService) GetCommissionStatement(agentID, month string) (*models.CommissionStatement, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, fmt.Errorf("month must be in YYYY-MM format")
+	}
+	end := start.AddDate(0, 1, 0)
+
+	if _, err := s.repo.GetAgentByID(agentID); err != nil {
+		return nil, err
+	}
+
+	statement := &models.CommissionStatement{
+		AgentID:     agentID,
+		Month:       month,
+		Commissions: []*models.Commission{},
+	}
+	for _, commission := range s.repo.GetCommissionsByAgent(agentID) {
+		if commission.EarnedAt.Before(start) || !commission.EarnedAt.Before(end) {
+			continue
+		}
+		statement.Commissions = append(statement.Commissions, commission)
+		statement.TotalPremium += commission.Premium
+		statement.TotalCommission += commission.Amount
+	}
+	statement.TotalPremium = roundCents(statement.TotalPremium)
+	statement.TotalCommission = roundCents(statement.TotalCommission)
+
+	return statement, nil
+}
+
+// rateFor returns the agent's own rate, or the default when it has none
+func (s *AgentService) rateFor(agent *models.Agent) float64 {
+	if agent.CommissionRate > 0 {
+		return agent.CommissionRate
+	}
+	return s.defaultRate
+}
+
+// licenseTaken reports whether another agent holds the license number
+func (s *AgentService) licenseTaken(licenseNumber, exceptID string) bool {
+	for _, agent := range s.repo.GetAllAgents() {
+		if agent.ID != exceptID && strings.EqualFold(agent.LicenseNumber, licenseNumber) {
+			return true
+		}
+	}
+	return false
+}
+
+// roundCents rounds a money amount to the cent
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func newAgentTestServices(t *testing.T, policies map[string]*clients.Policy) (*PaymentService, *AgentService, *repositorytest.FakeStore) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_INSTANT_PAYOUTS", "false")
+	t.Setenv("FEATURE_INSTANT_PAYOUTS_ROLLOUT", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+
+	store := repositorytest.NewFakeStore()
+	agents := NewAgentService(store, DefaultCommissionRate, logger)
+	payments := NewPaymentService(store, flags, Lookups{Policies: &stubLookups{policies: policies}}, agents, nil, nil, logger)
+	return payments, agents, store
+}
+
+func TestCreateAgentRequiresUniqueLicense(t *testing.T) {
+	_, agents, _ := newAgentTestServices(t, nil)
+
+	agent, err := agents.CreateAgent(&models.CreateAgentRequest{Name: "Dana Broker", LicenseNumber: "IL-0042"})
+	if err != nil {
+		t.Fatalf("CreateAgent failed: %v", err)
+	}
+	if agent.Status != models.AgentStatusActive {
+		t.Errorf("New agent should be active, got %s", agent.Status)
+	}
+
+	if _, err := agents.CreateAgent(&models.CreateAgentRequest{Name: "Other", LicenseNumber: "il-0042"}); err == nil || err.Error() != "license number already registered" {
+		t.Errorf("Duplicate license: got %v", err)
+	}
+	if _, err := agents.CreateAgent(&models.CreateAgentRequest{Name: "Other", LicenseNumber: "IL-0043", CommissionRate: 1.5}); err == nil {
+		t.Error("Expected a commission rate above 1 to be rejected")
+	}
+
+	// Keeping one's own license number is not a conflict
+	name := "Dana Broker-Smith"
+	if _, err := agents.UpdateAgent(agent.ID, &models.UpdateAgentRequest{Name: &name, LicenseNumber: &agent.LicenseNumber}); err != nil {
+		t.Errorf("UpdateAgent failed: %v", err)
+	}
+}
+
+func TestPremiumPaymentsEarnCommission(t *testing.T) {
+	payments, agents, store := newAgentTestServices(t, map[string]*clients.Policy{
+		"pol-agent":  {ID: "pol-agent", CustomerID: "cust-001", AgentID: "agent-001"},
+		"pol-custom": {ID: "pol-custom", CustomerID: "cust-001", AgentID: "agent-002"},
+		"pol-direct": {ID: "pol-direct", CustomerID: "cust-001"},
+	})
+	store.AddAgent(&models.Agent{ID: "agent-001", Name: "Default Rate", LicenseNumber: "L-1", Status: models.AgentStatusActive})
+	store.AddAgent(&models.Agent{ID: "agent-002", Name: "Custom Rate", LicenseNumber: "L-2", Status: models.AgentStatusActive, CommissionRate: 0.15})
+
+	pay := func(policyID string, amount float64) *models.Payment {
+		t.Helper()
+		payment, err := payments.CreatePayment(context.Background(), policyID, "cust-001", amount)
+		if err != nil {
+			t.Fatalf("CreatePayment failed: %v", err)
+		}
+		if _, err := payments.ProcessPayment(context.Background(), payment.ID); err != nil {
+			t.Fatalf("ProcessPayment failed: %v", err)
+		}
+		return payment
+	}
+
+	first := pay("pol-agent", 1250)
+	if first.AgentID != "agent-001" {
+		t.Errorf("Payment should credit the policy's agent, got %q", first.AgentID)
+	}
+	pay("pol-agent", 300.55)
+	pay("pol-custom", 1000)
+	pay("pol-direct", 800)
+
+	// Refunds never earn commission
+	if _, err := payments.CreateRefund("pol-agent", "cust-001", 100); err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+
+	month := time.Now().UTC().Format("2006-01")
+	statement, err := agents.GetCommissionStatement("agent-001", month)
+	if err != nil {
+		t.Fatalf("GetCommissionStatement failed: %v", err)
+	}
+	if len(statement.Commissions) != 2 || statement.TotalPremium != 1550.55 || statement.TotalCommission != 155.06 {
+		t.Errorf("Unexpected statement at the default rate: %+v", statement)
+	}
+
+	statement, err = agents.GetCommissionStatement("agent-002", month)
+	if err != nil {
+		t.Fatalf("GetCommissionStatement failed: %v", err)
+	}
+	if statement.TotalCommission != 150 || statement.Commissions[0].Rate != 0.15 {
+		t.Errorf("Unexpected statement at the agent's rate: %+v", statement)
+	}
+
+	// Other months are empty, deactivated agents stop earning
+	lastYear := time.Now().UTC().AddDate(-1, 0, 0).Format("2006-01")
+	if statement, err := agents.GetCommissionStatement("agent-001", lastYear); err != nil || len(statement.Commissions) != 0 {
+		t.Errorf("Expected an empty statement for %s: %+v, %v", lastYear, statement, err)
+	}
+	if _, err := agents.DeactivateAgent("agent-001"); err != nil {
+		t.Fatalf("DeactivateAgent failed: %v", err)
+	}
+	pay("pol-agent", 500)
+	if statement, _ := agents.GetCommissionStatement("agent-001", month); len(statement.Commissions) != 2 {
+		t.Errorf("Deactivated agent earned commission: %+v", statement)
+	}
+
+	if _, err := agents.GetCommissionStatement("agent-001", "2024-13"); err == nil {
+		t.Error("Expected an invalid month to be rejected")
+	}
+	if _, err := agents.GetCommissionStatement("agent-404", month); err == nil || err.Error() != "agent not found" {
+		t.Errorf("Unknown agent: got %v", err)
+	}
+}
+
+func TestRefundedPremiumReversesCommission(t *testing.T) {
+	payments, agents, store := newAgentTestServices(t, map[string]*clients.Policy{
+		"pol-agent": {ID: "pol-agent", CustomerID: "cust-001", AgentID: "agent-001"},
+	})
+	store.AddAgent(&models.Agent{ID: "agent-001", Name: "Default Rate", LicenseNumber: "L-1", Status: models.AgentStatusActive})
+
+	payment, err := payments.CreatePayment(context.Background(), "pol-agent", "cust-001", 1200)
+	if err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+	if _, err := payments.ProcessPayment(context.Background(), payment.ID); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if _, err := payments.RefundPayment(payment.ID, "Cancelled in cooling-off period"); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+
+	statement, err := agents.GetCommissionStatement("agent-001", time.Now().UTC().Format("2006-01"))
+	if err != nil {
+		t.Fatalf("GetCommissionStatement failed: %v", err)
+	}
+	if len(statement.Commissions) != 2 || statement.TotalPremium != 0 || statement.TotalCommission != 0 {
+		t.Errorf("Refund should cancel the commission: %+v", statement)
+	}
+
+	// Reversing again records nothing
+	if reversal, err := agents.ReverseCommission(payment); reversal != nil || err != nil {
+		t.Errorf("Second reversal: got %+v, %v", reversal, err)
+	}
+}
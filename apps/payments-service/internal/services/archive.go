@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/retention"
+	"github.com/sirupsen/logrus"
+)
+
+// ArchiveService moves the payments the retention policy no longer keeps
+// live to the archive. Payments still pending, processing or blocked are
+// never archived, whatever statuses the policy lists.
+type ArchiveService struct {
+	repo   repository.PaymentStore
+	policy *retention.Policy
+	logger *logrus.Logger
+}
+
+// NewArchiveService creates a new payment archival service
+func NewArchiveService(repo repository.PaymentStore, policy *retention.Policy, logger *logrus.Logger) *ArchiveService {
+	return &ArchiveService{
+		repo:   repo,
+		policy: policy,
+		logger: logger,
+	}
+}
+
+// Archive moves every payment due for archiving at now. A payment that
+// cannot be archived stays live and is reported as failed; the next pass
+// tries it again.
+func (s *ArchiveService) Archive(now time.Time) (*models.ArchiveResult, error) {
+	result := &models.ArchiveResult{
+		PolicyVersion: s.policy.Version,
+		Archived:      []string{},
+		Failed:        []string{},
+		ArchivedAt:    now,
+	}
+
+	rule, ok := s.policy.Rule(retention.Payments)
+	if !ok {
+		return result, nil
+	}
+
+	payments, err := s.repo.GetAllPayments()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	for _, payment := range payments {
+		result.Checked++
+		switch payment.Status {
+		case models.PaymentStatusPending, models.PaymentStatusProcessing, models.PaymentStatusBlocked:
+			continue
+		}
+		if !rule.Due(string(payment.Status), payment.ClosedAt(), now) {
+			continue
+		}
+
+		archived := *payment
+		archivedAt := now
+		archived.ArchivedAt = &archivedAt
+		if err := s.repo.ArchivePayment(&archived); err != nil {
+			s.logger.WithError(err).WithField("paymentId", payment.ID).Warn("Failed to archive payment")
+			result.Failed = append(result.Failed, payment.ID)
+			continue
+		}
+		result.Archived = append(result.Archived, payment.ID)
+	}
+
+	if len(result.Archived) > 0 || len(result.Failed) > 0 {
+		s.logger.WithFields(logrus.Fields{
+			"checked":  result.Checked,
+			"archived": len(result.Archived),
+			"failed":   len(result.Failed),
+			"policy":   result.PolicyVersion,
+		}).Info("Payments archived")
+	}
+
+	return result, nil
+}
+
+// Run archives due payments every interval until ctx is cancelled
+func (s *ArchiveService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Archive(time.Now()); err != nil {
+				s.logger.WithError(err).Error("Failed to archive payments")
+			}
+		}
+	}
+}
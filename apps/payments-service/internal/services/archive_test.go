@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/retention"
+	"github.com/sirupsen/logrus"
+)
+
+func TestArchivePaymentsPastRetention(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	now := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+	yearsAgo := func(years int) *time.Time {
+		d := now.AddDate(-years, 0, -1)
+		return &d
+	}
+	store := repositorytest.NewFakeStore(
+		&models.Payment{ID: "pay-old", ClaimID: "claim-001", Status: models.PaymentStatusCompleted, ProcessedDate: yearsAgo(8), CreatedAt: *yearsAgo(8)},
+		&models.Payment{ID: "pay-failed", Status: models.PaymentStatusFailed, ProcessedDate: yearsAgo(7), CreatedAt: *yearsAgo(7)},
+		// Refunded recently after settling long ago: the refund restarts the clock
+		&models.Payment{ID: "pay-refunded", Status: models.PaymentStatusRefunded, ProcessedDate: yearsAgo(9), RefundedDate: yearsAgo(1)},
+		&models.Payment{ID: "pay-recent", ClaimID: "claim-001", Status: models.PaymentStatusCompleted, ProcessedDate: yearsAgo(2)},
+		&models.Payment{ID: "pay-stuck", Status: models.PaymentStatusPending, UpdatedAt: *yearsAgo(10)},
+	)
+	policy := &retention.Policy{Version: "test", Rules: map[string]retention.Rule{
+		retention.Payments: {ArchiveAfterYears: 7, Statuses: []string{"completed", "failed", "refunded", "pending"}},
+	}}
+
+	result, err := NewArchiveService(store, policy, logger).Archive(now)
+	if err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	if want := []string{"pay-failed", "pay-old"}; !reflect.DeepEqual(result.Archived, want) || result.Checked != 5 {
+		t.Errorf("Archived %v of %d, want %v of 5", result.Archived, result.Checked, want)
+	}
+
+	if _, err := store.GetPaymentByID("pay-old"); err == nil {
+		t.Error("Expected the archived payment to leave the live payments")
+	}
+	archived, err := store.GetArchivedPayment("pay-old")
+	if err != nil || archived.ArchivedAt == nil || !archived.ArchivedAt.Equal(now) {
+		t.Errorf("Expected the payment in the archive stamped %s, got %+v (%v)", now, archived, err)
+	}
+	if _, err := store.GetPaymentByID("pay-stuck"); err != nil {
+		t.Error("Expected a pending payment to stay live whatever the policy lists")
+	}
+
+	service, _ := newTestService(t, false)
+	service.repo = store
+	payouts, err := service.GetArchivedPayments("claim-001")
+	if err != nil || len(payouts) != 1 || payouts[0].ID != "pay-old" {
+		t.Errorf("Expected the claim's archived payout only, got %v (%v)", payouts, err)
+	}
+
+	// Without a payments rule nothing is archived
+	result, err = NewArchiveService(store, retention.Default(), logger).Archive(now)
+	if err != nil || result.Checked != 0 || len(result.Archived) != 0 {
+		t.Errorf("Expected the default policy to archive nothing, got %+v (%v)", result, err)
+	}
+
+	store.Err = errors.New("store down")
+	if _, err := NewArchiveService(store, policy, logger).Archive(now); err == nil {
+		t.Error("Expected a failed payment listing to be reported")
+	}
+}
@@ -0,0 +1,485 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults for the billing schedule: one invoice per year of cover, due a
+// month after its period starts
+const (
+	DefaultBillingPeriodMonths = 12
+	DefaultBillingDueDays      = 30
+)
+
+// BillingService invoices policies for their premium, one invoice per
+// billing period, and allocates completed premium payments to the invoices
+// so a policy's balance shows what is due, paid and overdue.
+//
+// A policy's premium covers its whole term. The term is split into periods
+// of periodMonths from the start date, and each period is invoiced its
+// share of the premium by days of cover, so the invoices add up to the
+// premium. A cancelled policy is invoiced only up to the cancellation's
+// effective date. Invoices are issued once their period has started.
+// Invoices left unpaid past their grace period are charged late fees by
+// lateFees; see ChargeLateFees.
+type BillingService struct {
+	repo         repository.BillingStore
+	policies     PolicyLookup
+	periodMonths int
+	dueDays      int
+	lateFees     LateFeeRule
+	now          func() time.Time
+	mu           sync.Mutex // serializes allocation, so a payment is not applied twice
+	logger       *logrus.Logger
+}
+
+// NewBillingService creates a new billing service. policies may be nil, in
+// which case invoices cannot be issued and balances are refused. Zero
+// periodMonths or dueDays use the defaults. An empty lateFees rule charges
+// no late fees.
+func NewBillingService(repo repository.BillingStore, policies PolicyLookup, periodMonths, dueDays int, lateFees LateFeeRule, logger *logrus.Logger) *BillingService {
+	if periodMonths <= 0 {
+		periodMonths = DefaultBillingPeriodMonths
+	}
+	if dueDays <= 0 {
+		dueDays = DefaultBillingDueDays
+	}
+	if lateFees.GraceDays < 0 {
+		lateFees.GraceDays = 0
+	}
+	return &BillingService{
+		repo:         repo,
+		policies:     policies,
+		periodMonths: periodMonths,
+		dueDays:      dueDays,
+		lateFees:     lateFees,
+		now:          time.Now,
+		logger:       logger,
+	}
+}
+
+// GetPolicyBalance issues a policy's invoices for the periods started so
+// far, allocates any unallocated premium to them, and totals what is due,
+// paid and overdue. The policy is read from policy-service on behalf of
+// customerID, so a customer can only see the balance of their own
+// policies.
+func (s *BillingService) GetPolicyBalance(ctx context.Context, policyID, customerID string) (*models.PolicyBalance, error) {
+	if s.policies == nil {
+		return nil, fmt.Errorf("policy service not configured")
+	}
+	policy, err := s.policies.GetPolicy(ctx, policyID, customerID)
+	if err != nil {
+		if err.Error() == "policy not found" || err.Error() == "unauthorized" {
+			return nil, err
+		}
+		return nil, fmt.Errorf("policy lookup failed: %w", err)
+	}
+
+	now := s.now()
+	if err := s.issueInvoices(policy, now); err != nil {
+		return nil, err
+	}
+	if err := s.Allocate(policyID); err != nil {
+		return nil, err
+	}
+
+	return s.balance(policy, now)
+}
+
+// Allocate applies the policy's completed premium payments that are not
+// yet fully allocated to its unpaid late fees, oldest fee first, and then
+// to its unpaid invoices, earliest first, oldest payment first. Premium
+// left over once every fee and invoice is paid stays as credit for the
+// invoices still to come.
+func (s *BillingService) Allocate(policyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payments, err := s.premiums(policyID)
+	if err != nil {
+		return err
+	}
+	var debts []debt
+	for _, fee := range s.repo.GetLateFeesByPolicy(policyID) {
+		debts = append(debts, debt{invoiceID: fee.InvoiceID, feeID: fee.ID, amount: fee.Amount})
+	}
+	for _, invoice := range s.repo.GetInvoicesByPolicy(policyID) {
+		debts = append(debts, debt{invoiceID: invoice.ID, amount: invoice.Amount})
+	}
+	allocations := s.repo.GetAllocationsByPolicy(policyID)
+	paid := allocatedTo(allocations, debtOf)
+	applied := allocatedTo(allocations, func(a *models.Allocation) string { return a.PaymentID })
+	counts := make(map[string]int)
+	for _, allocation := range allocations {
+		counts[allocation.PaymentID]++
+	}
+
+	for _, payment := range payments {
+		if payment.Type != models.PaymentTypePremium || payment.Status != models.PaymentStatusCompleted {
+			continue
+		}
+		remaining := roundCents(payment.Amount - applied[payment.ID])
+		for _, d := range debts {
+			if remaining <= 0 {
+				break
+			}
+			outstanding := roundCents(d.amount - paid[d.key()])
+			if outstanding <= 0 {
+				continue
+			}
+			amount := outstanding
+			if remaining < amount {
+				amount = remaining
+			}
+
+			counts[payment.ID]++
+			allocation := &models.Allocation{
+				ID:          fmt.Sprintf("alc-%s-%d", payment.ID, counts[payment.ID]),
+				PaymentID:   payment.ID,
+				InvoiceID:   d.invoiceID,
+				FeeID:       d.feeID,
+				PolicyID:    policyID,
+				Amount:      amount,
+				AllocatedAt: s.now(),
+			}
+			if err := s.repo.CreateAllocation(allocation); err != nil {
+				return err
+			}
+			paid[d.key()] = roundCents(paid[d.key()] + amount)
+			remaining = roundCents(remaining - amount)
+
+			fields := logrus.Fields{
+				"paymentId": payment.ID,
+				"invoiceId": d.invoiceID,
+				"amount":    amount,
+			}
+			if d.feeID != "" {
+				fields["feeId"] = d.feeID
+				s.logger.WithFields(fields).Info("Premium allocated to late fee")
+				continue
+			}
+			s.logger.WithFields(fields).Info("Premium allocated to invoice")
+		}
+	}
+	return nil
+}
+
+// debt is an invoice's premium or a late fee on it, for allocation
+type debt struct {
+	invoiceID string
+	feeID     string
+	amount    float64
+}
+
+func (d debt) key() string {
+	if d.feeID != "" {
+		return d.feeID
+	}
+	return d.invoiceID
+}
+
+// debtOf keys allocations by what they paid: the late fee when there is
+// one, otherwise the invoice's premium
+func debtOf(a *models.Allocation) string {
+	return debt{invoiceID: a.InvoiceID, feeID: a.FeeID}.key()
+}
+
+// ReverseAllocations cancels what a refunded or disputed premium paid on
+// its policy's invoices by recording an offsetting negative allocation for
+// each, then reallocates the policy's other premium, so the invoices it paid
+// are due again unless credit covers them. Payments that were never
+// allocated, or were already reversed, are left alone.
+func (s *BillingService) ReverseAllocations(payment *models.Payment) error {
+	if payment.Type != models.PaymentTypePremium || payment.PolicyID == "" {
+		return nil
+	}
+	if payment.Status != models.PaymentStatusRefunded && payment.Status != models.PaymentStatusDisputed {
+		return nil
+	}
+
+	if err := s.reverse(payment); err != nil {
+		return err
+	}
+	return s.Allocate(payment.PolicyID)
+}
+
+// reverse records the offsetting allocations of a refunded or disputed
+// premium
+func (s *BillingService) reverse(payment *models.Payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allocations := s.repo.GetAllocationsByPolicy(payment.PolicyID)
+	reversed := make(map[string]bool)
+	for _, allocation := range allocations {
+		reversed[allocation.ID] = true
+	}
+	reversedAt := payment.UpdatedAt
+	if payment.RefundedDate != nil {
+		reversedAt = *payment.RefundedDate
+	}
+
+	for _, allocation := range allocations {
+		reversalID := allocation.ID + "-reversal"
+		if allocation.PaymentID != payment.ID || allocation.Amount <= 0 || reversed[reversalID] {
+			continue
+		}
+		reversal := &models.Allocation{
+			ID:          reversalID,
+			PaymentID:   payment.ID,
+			InvoiceID:   allocation.InvoiceID,
+			FeeID:       allocation.FeeID,
+			PolicyID:    allocation.PolicyID,
+			Amount:      -allocation.Amount,
+			AllocatedAt: reversedAt,
+		}
+		if err := s.repo.CreateAllocation(reversal); err != nil {
+			return err
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"paymentId": payment.ID,
+			"invoiceId": allocation.InvoiceID,
+			"amount":    reversal.Amount,
+		}).Info("Premium allocation reversed")
+	}
+	return nil
+}
+
+// issueInvoices issues the invoices for the policy's periods that have
+// started by now and are not yet invoiced
+func (s *BillingService) issueInvoices(policy *clients.Policy, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	issued := make(map[int]bool)
+	for _, invoice := range s.repo.GetInvoicesByPolicy(policy.ID) {
+		issued[invoice.Period] = true
+	}
+
+	for _, invoice := range s.schedule(policy) {
+		if invoice.PeriodStart.After(now) {
+			break
+		}
+		if issued[invoice.Period] {
+			continue
+		}
+		invoice.IssuedAt = now
+		if err := s.repo.CreateInvoice(invoice); err != nil {
+			return err
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"invoiceId": invoice.ID,
+			"policyId":  policy.ID,
+			"period":    invoice.Period,
+			"amount":    invoice.Amount,
+		}).Info("Invoice issued")
+	}
+	return nil
+}
+
+// schedule splits the policy's term into billing periods and prices each
+// by its days of cover. Policies without dates or premium have no
+// invoices.
+func (s *BillingService) schedule(policy *clients.Policy) []*models.Invoice {
+	start, end := policy.StartDate, policy.EndDate
+	if start.IsZero() || !end.After(start) || policy.Premium <= 0 {
+		return nil
+	}
+	billedTo := end
+	if policy.Cancellation != nil && policy.Cancellation.EffectiveDate.Before(billedTo) {
+		billedTo = policy.Cancellation.EffectiveDate
+	}
+	termDays := end.Sub(start).Hours() / 24
+	// Each period is priced as the premium earned by its end less that
+	// earned by its start, so rounding to the cent cannot drift
+	earned := func(t time.Time) float64 {
+		return roundCents(policy.Premium * (t.Sub(start).Hours() / 24) / termDays)
+	}
+
+	var invoices []*models.Invoice
+	for period := 1; ; period++ {
+		periodStart := start.AddDate(0, (period-1)*s.periodMonths, 0)
+		if !periodStart.Before(billedTo) {
+			break
+		}
+		periodEnd := start.AddDate(0, period*s.periodMonths, 0)
+		if periodEnd.After(billedTo) {
+			periodEnd = billedTo
+		}
+		invoices = append(invoices, &models.Invoice{
+			ID:          fmt.Sprintf("inv-%s-%d", policy.ID, period),
+			PolicyID:    policy.ID,
+			CustomerID:  policy.CustomerID,
+			Period:      period,
+			PeriodStart: periodStart,
+			PeriodEnd:   periodEnd,
+			DueDate:     periodStart.AddDate(0, 0, s.dueDays),
+			Amount:      roundCents(earned(periodEnd) - earned(periodStart)),
+		})
+	}
+	return invoices
+}
+
+// balance totals the policy's invoices and allocations as of now
+func (s *BillingService) balance(policy *clients.Policy, now time.Time) (*models.PolicyBalance, error) {
+	payments, err := s.premiums(policy.ID)
+	if err != nil {
+		return nil, err
+	}
+	allocations := s.repo.GetAllocationsByPolicy(policy.ID)
+	paid := allocatedTo(allocations, debtOf)
+	applied := allocatedTo(allocations, func(a *models.Allocation) string { return a.PaymentID })
+	fees := make(map[string]float64)
+	feesUnpaid := make(map[string]float64)
+	for _, fee := range s.repo.GetLateFeesByPolicy(policy.ID) {
+		fees[fee.InvoiceID] += fee.Amount
+		feesUnpaid[fee.InvoiceID] += fee.Amount - paid[fee.ID]
+	}
+
+	balance := &models.PolicyBalance{
+		PolicyID:     policy.ID,
+		PolicyNumber: policy.PolicyNumber,
+		PolicyStatus: policy.Status,
+		Invoices:     []*models.InvoiceBalance{},
+		AsOf:         now,
+	}
+	paidUp := true
+	for _, invoice := range s.repo.GetInvoicesByPolicy(policy.ID) {
+		line := &models.InvoiceBalance{
+			Invoice:             invoice,
+			Paid:                paid[invoice.ID],
+			Outstanding:         roundCents(invoice.Amount - paid[invoice.ID]),
+			LateFees:            roundCents(fees[invoice.ID]),
+			LateFeesOutstanding: roundCents(feesUnpaid[invoice.ID]),
+			Status:              models.InvoiceStatusOpen,
+		}
+		switch {
+		case line.Outstanding <= 0 && line.LateFeesOutstanding <= 0:
+			line.Status = models.InvoiceStatusPaid
+		case now.After(invoice.DueDate):
+			line.Status = models.InvoiceStatusOverdue
+			balance.Overdue += line.Outstanding + line.LateFeesOutstanding
+		}
+		if line.Status != models.InvoiceStatusPaid {
+			paidUp = false
+		} else if paidUp {
+			periodEnd := invoice.PeriodEnd
+			balance.PaidUpTo = &periodEnd
+		}
+		balance.Invoices = append(balance.Invoices, line)
+		balance.Due += invoice.Amount + line.LateFees
+		balance.LateFees += line.LateFees
+		balance.Paid += line.Paid + line.LateFees - line.LateFeesOutstanding
+	}
+
+	credit, refunded := 0.0, 0.0
+	for _, payment := range payments {
+		switch {
+		case payment.Type == models.PaymentTypePremium && payment.Status == models.PaymentStatusCompleted:
+			credit += payment.Amount - applied[payment.ID]
+		case payment.Type == models.PaymentTypeRefund && payment.Status == models.PaymentStatusCompleted:
+			refunded += payment.Amount
+		}
+	}
+
+	balance.Due = roundCents(balance.Due)
+	balance.LateFees = roundCents(balance.LateFees)
+	balance.Paid = roundCents(balance.Paid)
+	balance.Outstanding = roundCents(balance.Due - balance.Paid)
+	balance.Overdue = roundCents(balance.Overdue)
+	if credit = roundCents(credit - refunded); credit > 0 {
+		balance.Credit = credit
+	}
+	return balance, nil
+}
+
+// premiums returns the premium and refund payments made on a policy,
+// oldest first
+func (s *BillingService) premiums(policyID string) ([]*models.Payment, error) {
+	payments, err := s.repo.GetAllPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	var policyPayments []*models.Payment
+	for _, payment := range payments {
+		if payment.PolicyID == policyID && payment.Type != models.PaymentTypePayout {
+			policyPayments = append(policyPayments, payment)
+		}
+	}
+	sort.Slice(policyPayments, func(i, j int) bool {
+		if !policyPayments[i].CreatedAt.Equal(policyPayments[j].CreatedAt) {
+			return policyPayments[i].CreatedAt.Before(policyPayments[j].CreatedAt)
+		}
+		return policyPayments[i].ID < policyPayments[j].ID
+	})
+	return policyPayments, nil
+}
+
+// billedPolicy is a policy with a premium payment or invoice on record
+type billedPolicy struct {
+	id         string
+	customerID string
+}
+
+// billedStore is the data billedPolicies reads
+type billedStore interface {
+	GetAllPayments() ([]*models.Payment, error)
+	GetAllInvoices() []*models.Invoice
+}
+
+// billedPolicies returns the policies with a premium payment or invoice on
+// record, each with the customer it is billed to, for the billing jobs
+func billedPolicies(repo billedStore) ([]billedPolicy, error) {
+	payments, err := repo.GetAllPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	customers := make(map[string]string)
+	for _, payment := range payments {
+		if payment.Type == models.PaymentTypePremium && payment.PolicyID != "" {
+			customers[payment.PolicyID] = payment.CustomerID
+		}
+	}
+	for _, invoice := range repo.GetAllInvoices() {
+		customers[invoice.PolicyID] = invoice.CustomerID
+	}
+
+	policies := make([]billedPolicy, 0, len(customers))
+	for id, customerID := range customers {
+		policies = append(policies, billedPolicy{id: id, customerID: customerID})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].id < policies[j].id })
+	return policies, nil
+}
+
+// inForce reports whether a policy in this status is still in force, so
+// its unpaid premium is chased with reminders and late fees
+func inForce(status string) bool {
+	switch status {
+	case "active", "grace", "pending_cancellation":
+		return true
+	}
+	return false
+}
+
+// allocatedTo sums allocations, reversals included, by the key given
+func allocatedTo(allocations []*models.Allocation, key func(*models.Allocation) string) map[string]float64 {
+	totals := make(map[string]float64)
+	for _, allocation := range allocations {
+		totals[key(allocation)] = roundCents(totals[key(allocation)] + allocation.Amount)
+	}
+	return totals
+}
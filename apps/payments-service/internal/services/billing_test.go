@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func newBillingTestServices(t *testing.T, periodMonths int, now time.Time, policies map[string]*clients.Policy) (*PaymentService, *BillingService) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_INSTANT_PAYOUTS", "false")
+	t.Setenv("FEATURE_INSTANT_PAYOUTS_ROLLOUT", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+
+	store := repositorytest.NewFakeStore()
+	lookups := &stubLookups{policies: policies}
+	billing := NewBillingService(store, lookups, periodMonths, 30, LateFeeRule{}, logger)
+	billing.now = func() time.Time { return now }
+	payments := NewPaymentService(store, flags, Lookups{Policies: lookups}, nil, billing, nil, logger)
+	return payments, billing
+}
+
+func TestPolicyBalanceAllocatesPremiumToInvoices(t *testing.T) {
+	policy := &clients.Policy{
+		ID:         "pol-q",
+		CustomerID: "cust-001",
+		Premium:    1200,
+		StartDate:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	now := time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC)
+	payments, billing := newBillingTestServices(t, 3, now, map[string]*clients.Policy{"pol-q": policy})
+
+	pay := func(amount float64) *models.Payment {
+		t.Helper()
+		payment, err := payments.CreatePayment(context.Background(), "pol-q", "cust-001", amount)
+		if err != nil {
+			t.Fatalf("CreatePayment failed: %v", err)
+		}
+		if _, err := payments.ProcessPayment(context.Background(), payment.ID); err != nil {
+			t.Fatalf("ProcessPayment failed: %v", err)
+		}
+		return payment
+	}
+	balance := func() *models.PolicyBalance {
+		t.Helper()
+		balance, err := billing.GetPolicyBalance(context.Background(), "pol-q", "cust-001")
+		if err != nil {
+			t.Fatalf("GetPolicyBalance failed: %v", err)
+		}
+		return balance
+	}
+
+	// Paid before any invoice was issued, so it waits as credit
+	first := pay(400)
+
+	// Two quarters have started: Jan-Mar (90 days) and Apr-Jun (91 days),
+	// the second due on May 1
+	got := balance()
+	if len(got.Invoices) != 2 || got.Invoices[0].Amount != 295.89 || got.Invoices[1].Amount != 299.18 {
+		t.Fatalf("Unexpected invoices: %+v", got.Invoices)
+	}
+	if got.Due != 595.07 || got.Paid != 400 || got.Outstanding != 195.07 || got.Overdue != 195.07 || got.Credit != 0 {
+		t.Errorf("Unexpected totals: %+v", got)
+	}
+	if got.Invoices[0].Status != models.InvoiceStatusPaid || got.Invoices[1].Status != models.InvoiceStatusOverdue || got.Invoices[1].Paid != 104.11 {
+		t.Errorf("Unexpected invoice statuses: %+v, %+v", got.Invoices[0], got.Invoices[1])
+	}
+	if got.PaidUpTo == nil || !got.PaidUpTo.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected paid up to April 1, got %v", got.PaidUpTo)
+	}
+
+	// A premium completed once invoices exist is allocated straight away;
+	// the rest is credit for the quarters to come
+	pay(500)
+	got = balance()
+	if got.Outstanding != 0 || got.Overdue != 0 || got.Credit != 304.93 || got.PaidUpTo == nil || got.PaidUpTo.Month() != time.July {
+		t.Errorf("Unexpected balance after second payment: %+v", got)
+	}
+
+	// Refunding the first premium makes its invoices due again, and the
+	// credit left from the second covers what it can
+	if _, err := payments.RefundPayment(first.ID, "charged twice"); err != nil {
+		t.Fatalf("RefundPayment failed: %v", err)
+	}
+	got = balance()
+	if got.Paid != 500 || got.Outstanding != 95.07 || got.Overdue != 95.07 || got.Credit != 0 {
+		t.Errorf("Unexpected balance after refund: %+v", got)
+	}
+	if got.Invoices[0].Status != models.InvoiceStatusPaid || got.Invoices[1].Paid != 204.11 {
+		t.Errorf("Unexpected invoices after refund: %+v, %+v", got.Invoices[0], got.Invoices[1])
+	}
+
+	// Asking again issues and allocates nothing more
+	if again := balance(); len(again.Invoices) != 2 || again.Paid != got.Paid {
+		t.Errorf("Balance changed on a second look: %+v", again)
+	}
+}
+
+func TestPolicyBalanceStopsAtCancellation(t *testing.T) {
+	policy := &clients.Policy{
+		ID:           "pol-c",
+		CustomerID:   "cust-001",
+		Premium:      1200,
+		StartDate:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:      time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		Cancellation: &clients.PolicyCancellation{EffectiveDate: time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	_, billing := newBillingTestServices(t, 1, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), map[string]*clients.Policy{"pol-c": policy})
+
+	got, err := billing.GetPolicyBalance(context.Background(), "pol-c", "cust-001")
+	if err != nil {
+		t.Fatalf("GetPolicyBalance failed: %v", err)
+	}
+	// January in full, and February until the cancellation took effect
+	if len(got.Invoices) != 2 || !got.Invoices[1].PeriodEnd.Equal(policy.Cancellation.EffectiveDate) {
+		t.Fatalf("Expected two invoices ending at the cancellation, got %+v", got.Invoices)
+	}
+	if got.Due != 147.95 || got.Overdue != 147.95 {
+		t.Errorf("Expected 45 days of premium overdue, got %+v", got)
+	}
+}
+
+func TestPolicyBalanceLookupErrors(t *testing.T) {
+	_, billing := newBillingTestServices(t, 12, time.Now(), map[string]*clients.Policy{
+		"pol-001": {ID: "pol-001", CustomerID: "cust-001"},
+	})
+
+	if _, err := billing.GetPolicyBalance(context.Background(), "pol-001", "cust-002"); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Expected unauthorized for another customer's policy, got %v", err)
+	}
+	if _, err := billing.GetPolicyBalance(context.Background(), "pol-404", "cust-001"); err == nil || err.Error() != "policy not found" {
+		t.Errorf("Expected policy not found, got %v", err)
+	}
+
+	billing.policies = &stubLookups{err: errors.New("connection refused")}
+	if _, err := billing.GetPolicyBalance(context.Background(), "pol-001", "cust-001"); err == nil || err.Error() != "policy lookup failed: connection refused" {
+		t.Errorf("Expected a lookup failure, got %v", err)
+	}
+
+	billing.policies = nil
+	if _, err := billing.GetPolicyBalance(context.Background(), "pol-001", "cust-001"); err == nil || err.Error() != "policy service not configured" {
+		t.Errorf("Expected policy service not configured, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Defaults for bulk payment batches
+const (
+	DefaultBulkMaxPayments = 1000
+	DefaultBulkConcurrency = 8
+)
+
+// BulkPaymentService creates the premium payments of a batch, such as a
+// renewal run, several at a time. Each payment's ID is derived from its
+// item's idempotency key, so an item sent again returns the payment it
+// created.
+type BulkPaymentService struct {
+	payments    *PaymentService
+	maxPayments int
+	concurrency int
+	logger      *logrus.Logger
+
+	mu       sync.Mutex
+	inFlight map[string]bool // payment IDs being created
+}
+
+// NewBulkPaymentService creates a new bulk payment service taking up to
+// maxPayments items a batch and creating up to concurrency payments at
+// once. Zero uses DefaultBulkMaxPayments and DefaultBulkConcurrency.
+func NewBulkPaymentService(payments *PaymentService, maxPayments, concurrency int, logger *logrus.Logger) *BulkPaymentService {
+	if maxPayments <= 0 {
+		maxPayments = DefaultBulkMaxPayments
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+	return &BulkPaymentService{
+		payments:    payments,
+		maxPayments: maxPayments,
+		concurrency: concurrency,
+		logger:      logger,
+		inFlight:    make(map[string]bool),
+	}
+}
+
+// CheckBatch refuses a batch that is empty or larger than the service
+// takes, before any payment is created
+func (s *BulkPaymentService) CheckBatch(items []models.BulkPaymentItem) error {
+	if len(items) == 0 {
+		return fmt.Errorf("batch has no payments")
+	}
+	if len(items) > s.maxPayments {
+		return fmt.Errorf("batch has %d payments, at most %d are allowed", len(items), s.maxPayments)
+	}
+	return nil
+}
+
+// CreatePayments creates the payment of each item, calling emit with each
+// item's result as it completes, one at a time. An invalid item, or one
+// repeating an earlier item's idempotency key, fails without stopping the
+// others. Once ctx is done no further payments are started, and the items
+// left fail. The batch must have passed CheckBatch.
+func (s *BulkPaymentService) CreatePayments(ctx context.Context, items []models.BulkPaymentItem, emit func(models.BulkPaymentResult)) models.BulkPaymentSummary {
+	summary := models.BulkPaymentSummary{Total: len(items)}
+	count := func(result models.BulkPaymentResult) {
+		switch result.Status {
+		case models.BulkPaymentCreated:
+			summary.Created++
+		case models.BulkPaymentExisting:
+			summary.Existing++
+		default:
+			summary.Failed++
+		}
+		emit(result)
+	}
+
+	// An item repeating a key would race the first for the same payment
+	firstWithKey := make(map[string]int, len(items))
+	indexes := make(chan int)
+	results := make(chan models.BulkPaymentResult)
+	go func() {
+		defer close(indexes)
+		for i, item := range items {
+			if first, ok := firstWithKey[item.IdempotencyKey]; ok && item.IdempotencyKey != "" {
+				results <- failedItem(i, item, fmt.Sprintf("idempotencyKey repeats item %d", first))
+				continue
+			}
+			firstWithKey[item.IdempotencyKey] = i
+			if ctx.Err() == nil {
+				select {
+				case indexes <- i:
+					continue
+				case <-ctx.Done():
+				}
+			}
+			results <- failedItem(i, item, "batch cancelled: "+ctx.Err().Error())
+		}
+	}()
+
+	var workers sync.WaitGroup
+	for w := 0; w < s.concurrency && w < len(items); w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range indexes {
+				results <- s.createItem(ctx, i, items[i])
+			}
+		}()
+	}
+
+	for done := 0; done < len(items); done++ {
+		count(<-results)
+	}
+	workers.Wait()
+
+	s.logger.WithFields(logrus.Fields{
+		"total":    summary.Total,
+		"created":  summary.Created,
+		"existing": summary.Existing,
+		"failed":   summary.Failed,
+	}).Info("Bulk payments created")
+	return summary
+}
+
+// createItem creates one item's payment, or returns the payment an earlier
+// request created with its idempotency key
+func (s *BulkPaymentService) createItem(ctx context.Context, index int, item models.BulkPaymentItem) models.BulkPaymentResult {
+	if err := item.Validate(); err != nil {
+		return failedItem(index, item, err.Error())
+	}
+
+	paymentID := bulkPaymentID(item.IdempotencyKey)
+	if !s.reserve(paymentID) {
+		return failedItem(index, item, "a payment with this idempotency key is already being created")
+	}
+	defer s.release(paymentID)
+
+	existing, err := s.payments.GetPaymentByID(paymentID)
+	if err != nil {
+		existing, err = s.payments.GetArchivedPayment(paymentID)
+	}
+	if err == nil {
+		if existing.PolicyID != item.PolicyID || existing.CustomerID != item.CustomerID || existing.Amount != item.Amount {
+			return failedItem(index, item, "idempotency key was already used for a different payment")
+		}
+		return models.BulkPaymentResult{Index: index, IdempotencyKey: item.IdempotencyKey, Status: models.BulkPaymentExisting, Payment: existing}
+	}
+
+	payment, err := s.payments.createPremium(ctx, paymentID, item.PolicyID, item.CustomerID, item.Amount)
+	if err != nil {
+		s.logger.WithError(err).WithField("idempotencyKey", item.IdempotencyKey).Error("Failed to create bulk payment")
+		return failedItem(index, item, "failed to create payment")
+	}
+	return models.BulkPaymentResult{Index: index, IdempotencyKey: item.IdempotencyKey, Status: models.BulkPaymentCreated, Payment: payment}
+}
+
+// reserve claims paymentID for one request at a time, so concurrent
+// batches sending the same key cannot both create it
+func (s *BulkPaymentService) reserve(paymentID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.inFlight[paymentID] {
+		return false
+	}
+	s.inFlight[paymentID] = true
+	return true
+}
+
+func (s *BulkPaymentService) release(paymentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, paymentID)
+}
+
+// bulkPaymentID is the ID of the payment created for an idempotency key
+func bulkPaymentID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "pay-bulk-" + hex.EncodeToString(sum[:10])
+}
+
+func failedItem(index int, item models.BulkPaymentItem, message string) models.BulkPaymentResult {
+	return models.BulkPaymentResult{Index: index, IdempotencyKey: item.IdempotencyKey, Status: models.BulkPaymentFailed, Error: message}
+}
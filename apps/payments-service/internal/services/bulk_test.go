@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// slowPolicies answers policy lookups after a delay, recording the most
+// lookups in flight at once
+type slowPolicies struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (p *slowPolicies) GetPolicy(ctx context.Context, policyID, customerID string) (*clients.Policy, error) {
+	p.mu.Lock()
+	p.inFlight++
+	if p.inFlight > p.peak {
+		p.peak = p.inFlight
+	}
+	p.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	p.mu.Lock()
+	p.inFlight--
+	p.mu.Unlock()
+	return &clients.Policy{ID: policyID, CustomerID: customerID, AgentID: "agent-001"}, nil
+}
+
+func bulkItem(key, policyID string, amount float64) models.BulkPaymentItem {
+	return models.BulkPaymentItem{
+		IdempotencyKey:       key,
+		CreatePaymentRequest: models.CreatePaymentRequest{PolicyID: policyID, CustomerID: "cust-001", Amount: amount},
+	}
+}
+
+func TestCreatePaymentsIsIdempotentPerItem(t *testing.T) {
+	payments, store := newTestService(t, false)
+	policies := &slowPolicies{}
+	payments.lookups.Policies = policies
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	bulk := NewBulkPaymentService(payments, 5, 2, logger)
+
+	items := []models.BulkPaymentItem{
+		bulkItem("renewal-2026-pol-001", "pol-001", 1200),
+		bulkItem("renewal-2026-pol-002", "pol-002", 850),
+		bulkItem("renewal-2026-pol-003", "pol-003", 0),
+		bulkItem("renewal-2026-pol-001", "pol-001", 1200),
+		bulkItem("renewal-2026-pol-004", "pol-004", 430),
+	}
+	if err := bulk.CheckBatch(items); err != nil {
+		t.Fatalf("CheckBatch failed: %v", err)
+	}
+	results := map[int]models.BulkPaymentResult{}
+	summary := bulk.CreatePayments(context.Background(), items, func(result models.BulkPaymentResult) {
+		results[result.Index] = result
+	})
+
+	if summary != (models.BulkPaymentSummary{Total: 5, Created: 3, Failed: 2}) || len(results) != 5 {
+		t.Fatalf("Unexpected summary %+v with %d results", summary, len(results))
+	}
+	if results[2].Error != "amount: amount must be greater than 0" || results[3].Error != "idempotencyKey repeats item 0" {
+		t.Errorf("Unexpected failures %+v, %+v", results[2], results[3])
+	}
+	if policies.peak > 2 {
+		t.Errorf("Expected at most 2 payments created at once, got %d", policies.peak)
+	}
+	created := results[0].Payment
+	if created == nil || created.AgentID != "agent-001" || created.Type != models.PaymentTypePremium {
+		t.Fatalf("Unexpected payment %+v", created)
+	}
+	if _, err := store.GetPaymentByID(created.ID); err != nil {
+		t.Errorf("Expected the payment to be stored: %v", err)
+	}
+
+	// Resending the batch returns the same payments instead of new ones
+	items[2].Amount = 640
+	items[3] = bulkItem("renewal-2026-pol-002", "pol-002", 900)
+	results = map[int]models.BulkPaymentResult{}
+	summary = bulk.CreatePayments(context.Background(), items, func(result models.BulkPaymentResult) {
+		results[result.Index] = result
+	})
+	if summary != (models.BulkPaymentSummary{Total: 5, Created: 1, Existing: 3, Failed: 1}) {
+		t.Errorf("Unexpected summary on resend %+v", summary)
+	}
+	if results[0].Status != models.BulkPaymentExisting || results[0].Payment.ID != created.ID {
+		t.Errorf("Expected the existing payment, got %+v", results[0])
+	}
+	if results[2].Status != models.BulkPaymentCreated {
+		t.Errorf("Expected the corrected item to be created, got %+v", results[2])
+	}
+
+	// A key reused for a different payment is refused
+	reused := []models.BulkPaymentItem{bulkItem("renewal-2026-pol-001", "pol-001", 1300)}
+	bulk.CreatePayments(context.Background(), reused, func(result models.BulkPaymentResult) {
+		if result.Status != models.BulkPaymentFailed || result.Error != "idempotency key was already used for a different payment" {
+			t.Errorf("Expected the reused key to fail, got %+v", result)
+		}
+	})
+
+	all, _ := store.GetAllPayments()
+	if len(all) != 4 {
+		t.Errorf("Expected 4 payments stored, got %d", len(all))
+	}
+}
+
+func TestCheckBatchLimitsItsSize(t *testing.T) {
+	payments, _ := newTestService(t, false)
+	bulk := NewBulkPaymentService(payments, 2, 0, logrus.New())
+
+	if err := bulk.CheckBatch(nil); err == nil || err.Error() != "batch has no payments" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	items := []models.BulkPaymentItem{bulkItem("a", "pol-001", 1), bulkItem("b", "pol-001", 1), bulkItem("c", "pol-001", 1)}
+	if err := bulk.CheckBatch(items); err == nil || err.Error() != "batch has 3 payments, at most 2 are allowed" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestCreatePaymentsStopsWhenCancelled(t *testing.T) {
+	payments, store := newTestService(t, false)
+	bulk := NewBulkPaymentService(payments, 0, 1, logrus.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	summary := bulk.CreatePayments(ctx, []models.BulkPaymentItem{bulkItem("a", "pol-001", 1), bulkItem("b", "pol-001", 1), bulkItem("c", "pol-001", 1)}, func(models.BulkPaymentResult) {})
+	if summary != (models.BulkPaymentSummary{Total: 3, Failed: 3}) {
+		t.Errorf("Expected every item to fail once cancelled, got %+v", summary)
+	}
+	if all, _ := store.GetAllPayments(); len(all) != 0 {
+		t.Errorf("Expected no payments stored, got %d", len(all))
+	}
+}
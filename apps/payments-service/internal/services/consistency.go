@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyLookup reads policies from policy-service
+type PolicyLookup interface {
+	GetPolicy(ctx context.Context, policyID, customerID string) (*clients.Policy, error)
+}
+
+// ClaimLookup reads claims from claims-service
+type ClaimLookup interface {
+	GetClaim(ctx context.Context, claimID string) (*clients.Claim, error)
+}
+
+// CustomerLookup reads customers from customer-service
+type CustomerLookup interface {
+	GetCustomer(ctx context.Context, customerID string) (*clients.Customer, error)
+}
+
+var (
+	_ PolicyLookup   = (*clients.PolicyClient)(nil)
+	_ ClaimLookup    = (*clients.ClaimsClient)(nil)
+	_ CustomerLookup = (*clients.CustomerClient)(nil)
+)
+
+// ConsistencyChecker cross-validates the references held by payments
+// against the services that own the referenced records
+type ConsistencyChecker struct {
+	repo      repository.PaymentStore
+	policies  PolicyLookup
+	claims    ClaimLookup
+	customers CustomerLookup
+	logger    *logrus.Logger
+}
+
+// NewConsistencyChecker creates a checker. Any lookup may be nil when the
+// owning service is not configured; those checks are then reported as skipped.
+func NewConsistencyChecker(repo repository.PaymentStore, policies PolicyLookup, claims ClaimLookup, customers CustomerLookup, logger *logrus.Logger) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		repo:      repo,
+		policies:  policies,
+		claims:    claims,
+		customers: customers,
+		logger:    logger,
+	}
+}
+
+// Check builds a consistency report for every stored payment
+func (c *ConsistencyChecker) Check(ctx context.Context) *models.ConsistencyReport {
+	report := models.NewConsistencyReport("payments-service")
+
+	payments, err := c.repo.GetAllPayments()
+	if err != nil {
+		c.logger.WithError(err).Error("Failed to load payments for consistency check")
+		report.Add(models.ConsistencyIssue{
+			Severity: models.SeverityError,
+			Check:    "payment.load",
+			Message:  fmt.Sprintf("failed to load payments: %v", err),
+		})
+		return report
+	}
+	sort.Slice(payments, func(i, j int) bool { return payments[i].ID < payments[j].ID })
+	report.Checked = len(payments)
+
+	// References within this service
+	for _, payment := range payments {
+		switch payment.Type {
+		case models.PaymentTypePremium, models.PaymentTypeRefund:
+			if payment.PolicyID == "" {
+				report.Add(models.ConsistencyIssue{
+					Severity: models.SeverityError,
+					Check:    string(payment.Type) + ".policy_set",
+					EntityID: payment.ID,
+					Message:  fmt.Sprintf("%s payment has no policy reference", payment.Type),
+				})
+			}
+		case models.PaymentTypePayout:
+			if payment.ClaimID == "" {
+				report.Add(models.ConsistencyIssue{
+					Severity: models.SeverityError,
+					Check:    "payout.claim_set",
+					EntityID: payment.ID,
+					Message:  "payout has no claim reference",
+				})
+			}
+		}
+	}
+
+	// References owned by other services
+	if c.customers == nil {
+		report.Add(skipped("payment.customer_exists", "customer-service", "customer"))
+	} else {
+		c.checkCustomers(ctx, payments, report)
+	}
+	if c.policies == nil {
+		report.Add(skipped("premium.policy_exists", "policy-service", "policy"))
+	} else {
+		c.checkPolicies(ctx, payments, report)
+	}
+	if c.claims == nil {
+		report.Add(skipped("payout.claim_exists", "claims-service", "claim"))
+	} else {
+		c.checkClaims(ctx, payments, report)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"checked":  report.Checked,
+		"errors":   report.Summary[models.SeverityError],
+		"warnings": report.Summary[models.SeverityWarning],
+	}).Info("Consistency check completed")
+
+	return report
+}
+
+// checkCustomers resolves each payer once. Like the other remote checks it
+// stops at the first transport failure rather than reporting every payment
+// as orphaned.
+func (c *ConsistencyChecker) checkCustomers(ctx context.Context, payments []*models.Payment, report *models.ConsistencyReport) {
+	found := make(map[string]bool)
+	for _, payment := range payments {
+		exists, seen := found[payment.CustomerID]
+		if !seen {
+			_, err := c.customers.GetCustomer(ctx, payment.CustomerID)
+			switch {
+			case err == nil:
+				exists = true
+			case err.Error() == "customer not found":
+				exists = false
+			default:
+				report.Add(unavailable("payment.customer_exists", "customer-service", "customer", err, c.logger))
+				return
+			}
+			found[payment.CustomerID] = exists
+		}
+
+		if !exists {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityError,
+				Check:     "payment.customer_exists",
+				EntityID:  payment.ID,
+				Reference: payment.CustomerID,
+				Message:   "payment references a customer that does not exist",
+			})
+		}
+	}
+}
+
+// checkPolicies resolves each premium's and refund's policy as the payer
+// would
+func (c *ConsistencyChecker) checkPolicies(ctx context.Context, payments []*models.Payment, report *models.ConsistencyReport) {
+	for _, payment := range payments {
+		if payment.Type != models.PaymentTypePremium && payment.Type != models.PaymentTypeRefund || payment.PolicyID == "" {
+			continue
+		}
+
+		if _, err := c.policies.GetPolicy(ctx, payment.PolicyID, payment.CustomerID); err != nil {
+			switch err.Error() {
+			case "policy not found":
+				report.Add(models.ConsistencyIssue{
+					Severity:  models.SeverityError,
+					Check:     string(payment.Type) + ".policy_exists",
+					EntityID:  payment.ID,
+					Reference: payment.PolicyID,
+					Message:   fmt.Sprintf("%s payment references a policy that does not exist", payment.Type),
+				})
+			case "unauthorized":
+				report.Add(models.ConsistencyIssue{
+					Severity:  models.SeverityError,
+					Check:     string(payment.Type) + ".policy_owner",
+					EntityID:  payment.ID,
+					Reference: payment.PolicyID,
+					Message:   fmt.Sprintf("payer %s does not own the policy", payment.CustomerID),
+				})
+			default:
+				report.Add(unavailable("premium.policy_exists", "policy-service", "policy", err, c.logger))
+				return
+			}
+		}
+	}
+}
+
+// checkClaims resolves each payout's claim and compares it with the payout
+func (c *ConsistencyChecker) checkClaims(ctx context.Context, payments []*models.Payment, report *models.ConsistencyReport) {
+	for _, payment := range payments {
+		if payment.Type != models.PaymentTypePayout || payment.ClaimID == "" {
+			continue
+		}
+
+		claim, err := c.claims.GetClaim(ctx, payment.ClaimID)
+		if err != nil {
+			if err.Error() == "claim not found" {
+				report.Add(models.ConsistencyIssue{
+					Severity:  models.SeverityError,
+					Check:     "payout.claim_exists",
+					EntityID:  payment.ID,
+					Reference: payment.ClaimID,
+					Message:   "payout references a claim that does not exist",
+				})
+				continue
+			}
+			report.Add(unavailable("payout.claim_exists", "claims-service", "claim", err, c.logger))
+			return
+		}
+
+		if claim.CustomerID != payment.CustomerID {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityError,
+				Check:     "payout.claimant",
+				EntityID:  payment.ID,
+				Reference: payment.ClaimID,
+				Message:   fmt.Sprintf("payout is paid to %s but the claimant is %s", payment.CustomerID, claim.CustomerID),
+			})
+		}
+		if claim.Status != "approved" {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityWarning,
+				Check:     "payout.claim_approved",
+				EntityID:  payment.ID,
+				Reference: payment.ClaimID,
+				Message:   fmt.Sprintf("payout settles a claim with status %s", claim.Status),
+			})
+		}
+		if payment.Amount > claim.Amount {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityWarning,
+				Check:     "payout.amount_within_claim",
+				EntityID:  payment.ID,
+				Reference: payment.ClaimID,
+				Message:   fmt.Sprintf("payout of %.2f exceeds the claimed %.2f", payment.Amount, claim.Amount),
+			})
+		}
+		if payment.PolicyID != "" && payment.PolicyID != claim.PolicyID {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityWarning,
+				Check:     "payout.policy_matches_claim",
+				EntityID:  payment.ID,
+				Reference: payment.PolicyID,
+				Message:   fmt.Sprintf("payout names policy %s but the claim is filed against %s", payment.PolicyID, claim.PolicyID),
+			})
+		}
+	}
+}
+
+// skipped reports a check that could not run because its service is not configured
+func skipped(check, service, entity string) models.ConsistencyIssue {
+	return models.ConsistencyIssue{
+		Severity: models.SeverityInfo,
+		Check:    check,
+		Message:  fmt.Sprintf("%s is not configured; %s references were not checked", service, entity),
+	}
+}
+
+// unavailable reports a check abandoned after a transport failure
+func unavailable(check, service, entity string, err error, logger *logrus.Logger) models.ConsistencyIssue {
+	logger.WithError(err).Warnf("%s lookup failed, skipping remaining %s checks", service, entity)
+	return models.ConsistencyIssue{
+		Severity: models.SeverityInfo,
+		Check:    check,
+		Message:  fmt.Sprintf("%s unavailable, remaining %s references not checked: %v", service, entity, err),
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubLookups answers every cross-service lookup from in-memory maps.
+// Customers in kyc have the given KYC status.
+type stubLookups struct {
+	policies  map[string]*clients.Policy
+	claims    map[string]*clients.Claim
+	customers map[string]bool
+	kyc       map[string]string
+	err       error
+}
+
+func (s *stubLookups) GetPolicy(ctx context.Context, policyID, customerID string) (*clients.Policy, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	policy, ok := s.policies[policyID]
+	if !ok {
+		return nil, errors.New("policy not found")
+	}
+	if policy.CustomerID != customerID {
+		return nil, errors.New("unauthorized")
+	}
+	return policy, nil
+}
+
+func (s *stubLookups) GetClaim(ctx context.Context, claimID string) (*clients.Claim, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	claim, ok := s.claims[claimID]
+	if !ok {
+		return nil, errors.New("claim not found")
+	}
+	return claim, nil
+}
+
+func (s *stubLookups) GetCustomer(ctx context.Context, customerID string) (*clients.Customer, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if !s.customers[customerID] {
+		return nil, errors.New("customer not found")
+	}
+	return &clients.Customer{ID: customerID, KYCStatus: s.kyc[customerID]}, nil
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	premium := models.PaymentTypePremium
+	payout := models.PaymentTypePayout
+	store := repositorytest.NewFakeStore(
+		&models.Payment{ID: "pay-ok", Type: premium, PolicyID: "pol-001", CustomerID: "cust-001", Amount: 1200},
+		&models.Payment{ID: "pay-orphan", Type: premium, PolicyID: "pol-404", CustomerID: "cust-001", Amount: 1200},
+		&models.Payment{ID: "pay-owner", Type: premium, PolicyID: "pol-001", CustomerID: "cust-002", Amount: 1200},
+		&models.Payment{ID: "pay-ghost", Type: premium, PolicyID: "pol-001", CustomerID: "cust-404", Amount: 1200},
+		&models.Payment{ID: "payout-ok", Type: payout, ClaimID: "claim-001", CustomerID: "cust-001", Amount: 4500},
+		&models.Payment{ID: "payout-orphan", Type: payout, ClaimID: "claim-404", CustomerID: "cust-001", Amount: 4500},
+		&models.Payment{ID: "payout-claimant", Type: payout, ClaimID: "claim-001", CustomerID: "cust-002", Amount: 4500},
+		&models.Payment{ID: "payout-pending", Type: payout, ClaimID: "claim-002", CustomerID: "cust-001", Amount: 9000},
+	)
+	lookups := &stubLookups{
+		policies: map[string]*clients.Policy{
+			"pol-001": {ID: "pol-001", CustomerID: "cust-001"},
+		},
+		claims: map[string]*clients.Claim{
+			"claim-001": {ID: "claim-001", PolicyID: "pol-001", CustomerID: "cust-001", Status: "approved", Amount: 4500},
+			"claim-002": {ID: "claim-002", PolicyID: "pol-001", CustomerID: "cust-001", Status: "under_review", Amount: 3000},
+		},
+		customers: map[string]bool{"cust-001": true, "cust-002": true},
+	}
+
+	report := NewConsistencyChecker(store, lookups, lookups, lookups, logger).Check(context.Background())
+
+	if report.Checked != 8 {
+		t.Errorf("Checked mismatch: got %d, want 8", report.Checked)
+	}
+	want := map[string][]string{
+		"pay-orphan":      {"premium.policy_exists"},
+		"pay-owner":       {"premium.policy_owner"},
+		"pay-ghost":       {"payment.customer_exists", "premium.policy_owner"},
+		"payout-orphan":   {"payout.claim_exists"},
+		"payout-claimant": {"payout.claimant"},
+		"payout-pending":  {"payout.claim_approved", "payout.amount_within_claim"},
+	}
+	got := map[string][]string{}
+	for _, issue := range report.Issues {
+		got[issue.EntityID] = append(got[issue.EntityID], issue.Check)
+	}
+	for id, checks := range want {
+		if len(got[id]) != len(checks) {
+			t.Errorf("Issues for %s: got %v, want %v", id, got[id], checks)
+			continue
+		}
+		for i, check := range checks {
+			if got[id][i] != check {
+				t.Errorf("Issues for %s: got %v, want %v", id, got[id], checks)
+				break
+			}
+		}
+	}
+	for _, id := range []string{"pay-ok", "payout-ok"} {
+		if len(got[id]) > 0 {
+			t.Errorf("Consistent payment %s reported: %v", id, got[id])
+		}
+	}
+	if report.Summary[models.SeverityError] != 6 || report.Summary[models.SeverityWarning] != 2 {
+		t.Errorf("Summary mismatch: %v", report.Summary)
+	}
+}
+
+func TestConsistencyCheckWithoutOtherServices(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePremium, PolicyID: "pol-001", CustomerID: "cust-001"},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePayout, ClaimID: "claim-001", CustomerID: "cust-001"},
+	)
+
+	t.Run("not configured", func(t *testing.T) {
+		report := NewConsistencyChecker(store, nil, nil, nil, logger).Check(context.Background())
+		if len(report.Issues) != 3 || report.Summary[models.SeverityInfo] != 3 {
+			t.Errorf("Expected three skipped-check notices, got %+v", report.Issues)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		down := &stubLookups{err: errors.New("connection refused")}
+		report := NewConsistencyChecker(store, down, down, down, logger).Check(context.Background())
+		if len(report.Issues) != 3 || report.Summary[models.SeverityInfo] != 3 {
+			t.Errorf("Expected one notice per unreachable service, got %+v", report.Issues)
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// PolicyLapser lapses a policy in policy-service. *clients.PolicyClient is
+// the production implementation.
+type PolicyLapser interface {
+	LapsePolicy(ctx context.Context, policyID, reason string) error
+}
+
+var _ PolicyLapser = (*clients.PolicyClient)(nil)
+
+// DisputeService handles disputes payers raise with their bank against
+// premium payments. Opening a dispute provisionally reverses the premium;
+// losing it charges the premium back and lapses the policy for non-payment.
+type DisputeService struct {
+	repo     repository.DisputeStore
+	payments *PaymentService
+	lapser   PolicyLapser
+	now      func() time.Time
+	mu       sync.Mutex
+	logger   *logrus.Logger
+}
+
+// NewDisputeService creates a new dispute service. lapser may be nil, in
+// which case lost disputes record that the policy could not be lapsed.
+func NewDisputeService(repo repository.DisputeStore, payments *PaymentService, lapser PolicyLapser, logger *logrus.Logger) *DisputeService {
+	return &DisputeService{
+		repo:     repo,
+		payments: payments,
+		lapser:   lapser,
+		now:      time.Now,
+		logger:   logger,
+	}
+}
+
+// OpenDispute records a dispute of a completed premium payment and marks
+// the payment disputed. A payment the lifecycle cannot dispute, such as a
+// payout or one already under dispute, returns its *lifecycle.TransitionError.
+func (s *DisputeService) OpenDispute(paymentID string, req *models.OpenDisputeRequest, openedBy string) (*models.Dispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payment, err := s.payments.DisputePayment(paymentID, openedBy, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute := &models.Dispute{
+		ID:         fmt.Sprintf("dsp-%s-%d", payment.ID, len(s.repo.GetDisputesByPayment(payment.ID))+1),
+		PaymentID:  payment.ID,
+		PolicyID:   payment.PolicyID,
+		CustomerID: payment.CustomerID,
+		Amount:     payment.Amount,
+		Reason:     req.Reason,
+		ReasonCode: req.ReasonCode,
+		Status:     models.DisputeStatusOpen,
+		OpenedBy:   openedBy,
+		OpenedAt:   s.now(),
+	}
+	if err := s.repo.CreateDispute(dispute); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"disputeId": dispute.ID,
+		"paymentId": payment.ID,
+		"policyId":  payment.PolicyID,
+		"amount":    payment.Amount,
+	}).Info("Payment dispute opened")
+
+	return dispute, nil
+}
+
+// ResolveDispute records the outcome of an open dispute. A won dispute
+// completes the premium again; a lost one charges it back and asks
+// policy-service to lapse the policy for non-payment. A failed lapse is
+// recorded on the dispute rather than returned, as the chargeback stands.
+func (s *DisputeService) ResolveDispute(ctx context.Context, disputeID string, req *models.ResolveDisputeRequest, resolvedBy string) (*models.Dispute, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dispute, err := s.repo.GetDisputeByID(disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != models.DisputeStatusOpen {
+		return nil, fmt.Errorf("dispute already resolved")
+	}
+
+	won := req.Outcome == models.DisputeStatusWon
+	reason := "dispute " + string(req.Outcome)
+	if req.Note != "" {
+		reason += ": " + req.Note
+	}
+	if _, err := s.payments.SettleDisputedPayment(dispute.PaymentID, won, resolvedBy, reason); err != nil {
+		return nil, err
+	}
+
+	resolvedAt := s.now()
+	dispute.Status = req.Outcome
+	dispute.ResolvedBy = resolvedBy
+	dispute.ResolvedAt = &resolvedAt
+	dispute.Note = req.Note
+	if !won {
+		s.lapse(ctx, dispute)
+	}
+	if err := s.repo.UpdateDispute(dispute); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"disputeId":    dispute.ID,
+		"paymentId":    dispute.PaymentID,
+		"outcome":      dispute.Status,
+		"policyLapsed": dispute.PolicyLapsed,
+	}).Info("Payment dispute resolved")
+
+	return dispute, nil
+}
+
+// lapse asks policy-service to lapse the policy of a lost dispute
+func (s *DisputeService) lapse(ctx context.Context, dispute *models.Dispute) {
+	if s.lapser == nil {
+		dispute.LapseError = "policy service not configured"
+		return
+	}
+
+	reason := fmt.Sprintf("premium payment %s charged back", dispute.PaymentID)
+	if err := s.lapser.LapsePolicy(ctx, dispute.PolicyID, reason); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"disputeId": dispute.ID,
+			"policyId":  dispute.PolicyID,
+		}).Error("Failed to lapse policy after lost dispute")
+		dispute.LapseError = err.Error()
+		return
+	}
+	dispute.PolicyLapsed = true
+}
+
+// GetDispute retrieves a dispute by ID
+func (s *DisputeService) GetDispute(disputeID string) (*models.Dispute, error) {
+	return s.repo.GetDisputeByID(disputeID)
+}
+
+// GetDisputes returns the disputes, oldest first, narrowed to status when
+// it is given
+func (s *DisputeService) GetDisputes(status models.DisputeStatus) []*models.Dispute {
+	disputes := []*models.Dispute{}
+	for _, dispute := range s.repo.GetAllDisputes() {
+		if status == "" || dispute.Status == status {
+			disputes = append(disputes, dispute)
+		}
+	}
+	return disputes
+}
+
+// GetPaymentDisputes returns the disputes of a payment, oldest first
+func (s *DisputeService) GetPaymentDisputes(paymentID string) ([]*models.Dispute, error) {
+	if _, err := s.payments.GetPaymentByID(paymentID); err != nil {
+		return nil, err
+	}
+	disputes := s.repo.GetDisputesByPayment(paymentID)
+	if disputes == nil {
+		disputes = []*models.Dispute{}
+	}
+	return disputes, nil
+}
+
+// Metrics summarizes every dispute against the premium payments that could
+// have been disputed
+func (s *DisputeService) Metrics() (*models.DisputeMetrics, error) {
+	payments, err := s.payments.GetAllPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	settled := 0
+	for _, payment := range payments {
+		if payment.Type != models.PaymentTypePremium {
+			continue
+		}
+		switch payment.Status {
+		case models.PaymentStatusCompleted, models.PaymentStatusRefunded,
+			models.PaymentStatusDisputed, models.PaymentStatusChargedBack:
+			settled++
+		}
+	}
+
+	metrics := &models.DisputeMetrics{}
+	disputed := make(map[string]bool)
+	var decidingDays float64
+	for _, dispute := range s.repo.GetAllDisputes() {
+		disputed[dispute.PaymentID] = true
+		switch dispute.Status {
+		case models.DisputeStatusOpen:
+			metrics.Open++
+			metrics.OpenAmount += dispute.Amount
+		case models.DisputeStatusWon:
+			metrics.Won++
+		case models.DisputeStatusLost:
+			metrics.Lost++
+			metrics.LostAmount += dispute.Amount
+		}
+		if dispute.ResolvedAt != nil {
+			decidingDays += dispute.ResolvedAt.Sub(dispute.OpenedAt).Hours() / 24
+		}
+		if dispute.PolicyLapsed {
+			metrics.PoliciesLapsed++
+		}
+	}
+
+	metrics.OpenAmount = roundCents(metrics.OpenAmount)
+	metrics.LostAmount = roundCents(metrics.LostAmount)
+	if resolved := metrics.Won + metrics.Lost; resolved > 0 {
+		metrics.WinRate = roundRate(float64(metrics.Won) / float64(resolved))
+		metrics.AvgDaysToDecide = math.Round(decidingDays/float64(resolved)*10) / 10
+	}
+	if settled > 0 {
+		metrics.DisputeRate = roundRate(float64(len(disputed)) / float64(settled))
+	}
+	return metrics, nil
+}
+
+// WritePrometheus writes the disputes and their amounts by status for
+// GET /metrics in the Prometheus text exposition format, so a rise in
+// chargebacks can be alerted on
+func (s *DisputeService) WritePrometheus(w io.Writer) {
+	counts := make(map[models.DisputeStatus]int)
+	amounts := make(map[models.DisputeStatus]float64)
+	lapsed := 0
+	for _, dispute := range s.repo.GetAllDisputes() {
+		counts[dispute.Status]++
+		amounts[dispute.Status] += dispute.Amount
+		if dispute.PolicyLapsed {
+			lapsed++
+		}
+	}
+	statuses := []models.DisputeStatus{models.DisputeStatusOpen, models.DisputeStatusWon, models.DisputeStatusLost}
+
+	fmt.Fprint(w, "# HELP payment_disputes Premium payment disputes by status.\n")
+	fmt.Fprint(w, "# TYPE payment_disputes gauge\n")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "payment_disputes{status=%q} %d\n", status, counts[status])
+	}
+	fmt.Fprint(w, "# HELP payment_disputes_amount Premium disputed by dispute status.\n")
+	fmt.Fprint(w, "# TYPE payment_disputes_amount gauge\n")
+	for _, status := range statuses {
+		fmt.Fprintf(w, "payment_disputes_amount{status=%q} %.2f\n", status, amounts[status])
+	}
+	fmt.Fprint(w, "# HELP payment_disputes_policies_lapsed Policies lapsed after a lost dispute.\n")
+	fmt.Fprint(w, "# TYPE payment_disputes_policies_lapsed gauge\n")
+	fmt.Fprintf(w, "payment_disputes_policies_lapsed %d\n", lapsed)
+}
+
+// roundRate rounds a ratio to four decimal places
+func roundRate(rate float64) float64 {
+	return math.Round(rate*10000) / 10000
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubLapser records the policies it was asked to lapse
+type stubLapser struct {
+	lapsed map[string]string
+	err    error
+}
+
+func (s *stubLapser) LapsePolicy(ctx context.Context, policyID, reason string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.lapsed[policyID] = reason
+	return nil
+}
+
+type disputeTestServices struct {
+	payments *PaymentService
+	agents   *AgentService
+	billing  *BillingService
+	disputes *DisputeService
+	lapser   *stubLapser
+}
+
+func newDisputeTestServices(t *testing.T) *disputeTestServices {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_INSTANT_PAYOUTS", "false")
+	t.Setenv("FEATURE_INSTANT_PAYOUTS_ROLLOUT", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+
+	store := repositorytest.NewFakeStore()
+	store.AddAgent(&models.Agent{ID: "agent-001", Name: "Dana Broker", LicenseNumber: "L-1", Status: models.AgentStatusActive})
+	lookups := &stubLookups{policies: map[string]*clients.Policy{
+		"pol-001": {
+			ID:         "pol-001",
+			CustomerID: "cust-001",
+			AgentID:    "agent-001",
+			Premium:    1200,
+			StartDate:  time.Now().AddDate(0, -1, 0),
+			EndDate:    time.Now().AddDate(1, -1, 0),
+		},
+	}}
+
+	s := &disputeTestServices{lapser: &stubLapser{lapsed: make(map[string]string)}}
+	s.agents = NewAgentService(store, DefaultCommissionRate, logger)
+	s.billing = NewBillingService(store, lookups, 12, 30, LateFeeRule{}, logger)
+	s.payments = NewPaymentService(store, flags, Lookups{Policies: lookups}, s.agents, s.billing, nil, logger)
+	s.disputes = NewDisputeService(store, s.payments, s.lapser, logger)
+	return s
+}
+
+// pay completes a premium payment of the whole year's premium
+func (s *disputeTestServices) pay(t *testing.T) *models.Payment {
+	t.Helper()
+	payment, err := s.payments.CreatePayment(context.Background(), "pol-001", "cust-001", 1200)
+	if err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+	if _, err := s.payments.ProcessPayment(context.Background(), payment.ID); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	return payment
+}
+
+func (s *disputeTestServices) commission(t *testing.T) float64 {
+	t.Helper()
+	statement, err := s.agents.GetCommissionStatement("agent-001", time.Now().UTC().Format("2006-01"))
+	if err != nil {
+		t.Fatalf("GetCommissionStatement failed: %v", err)
+	}
+	return statement.TotalCommission
+}
+
+func (s *disputeTestServices) outstanding(t *testing.T) float64 {
+	t.Helper()
+	balance, err := s.billing.GetPolicyBalance(context.Background(), "pol-001", "cust-001")
+	if err != nil {
+		t.Fatalf("GetPolicyBalance failed: %v", err)
+	}
+	return balance.Outstanding
+}
+
+func TestWonDisputeRestoresPremium(t *testing.T) {
+	s := newDisputeTestServices(t)
+	payment := s.pay(t)
+	if s.outstanding(t) != 0 || s.commission(t) != 120 {
+		t.Fatalf("Expected the premium paid with commission earned")
+	}
+
+	if _, err := s.disputes.OpenDispute(payment.ID, &models.OpenDisputeRequest{}, "adj-001"); err == nil {
+		t.Error("Expected a dispute without a reason to be rejected")
+	}
+	dispute, err := s.disputes.OpenDispute(payment.ID, &models.OpenDisputeRequest{Reason: "not recognised", ReasonCode: "10.4"}, "adj-001")
+	if err != nil {
+		t.Fatalf("OpenDispute failed: %v", err)
+	}
+	if dispute.Status != models.DisputeStatusOpen || dispute.PolicyID != "pol-001" || dispute.Amount != 1200 {
+		t.Errorf("Unexpected dispute: %+v", dispute)
+	}
+
+	// The premium is provisionally reversed, and cannot be disputed twice
+	if stored, _ := s.payments.GetPaymentByID(payment.ID); stored.Status != models.PaymentStatusDisputed {
+		t.Errorf("Expected the payment disputed, got %s", stored.Status)
+	}
+	if s.outstanding(t) != 1200 {
+		t.Errorf("Expected the invoice due again while disputed")
+	}
+	var transitionErr *lifecycle.TransitionError
+	if _, err := s.disputes.OpenDispute(payment.ID, &models.OpenDisputeRequest{Reason: "again"}, "adj-001"); !errors.As(err, &transitionErr) {
+		t.Errorf("Expected a second dispute to be refused, got %v", err)
+	}
+
+	dispute, err = s.disputes.ResolveDispute(context.Background(), dispute.ID, &models.ResolveDisputeRequest{Outcome: models.DisputeStatusWon, Note: "proof of purchase accepted"}, "adj-002")
+	if err != nil {
+		t.Fatalf("ResolveDispute failed: %v", err)
+	}
+	if dispute.Status != models.DisputeStatusWon || dispute.ResolvedBy != "adj-002" || dispute.ResolvedAt == nil || dispute.PolicyLapsed {
+		t.Errorf("Unexpected resolved dispute: %+v", dispute)
+	}
+
+	// Completed again: reallocated, without earning commission twice
+	if stored, _ := s.payments.GetPaymentByID(payment.ID); stored.Status != models.PaymentStatusCompleted {
+		t.Errorf("Expected the payment completed again, got %s", stored.Status)
+	}
+	if s.outstanding(t) != 0 || s.commission(t) != 120 {
+		t.Errorf("Expected the premium paid once more with commission unchanged, got %v outstanding and %v commission", s.outstanding(t), s.commission(t))
+	}
+	if len(s.lapser.lapsed) != 0 {
+		t.Errorf("A won dispute lapsed the policy: %v", s.lapser.lapsed)
+	}
+
+	if _, err := s.disputes.ResolveDispute(context.Background(), dispute.ID, &models.ResolveDisputeRequest{Outcome: models.DisputeStatusLost}, "adj-002"); err == nil || err.Error() != "dispute already resolved" {
+		t.Errorf("Expected dispute already resolved, got %v", err)
+	}
+}
+
+func TestLostDisputeChargesBackAndLapsesPolicy(t *testing.T) {
+	s := newDisputeTestServices(t)
+	payment := s.pay(t)
+
+	dispute, err := s.disputes.OpenDispute(payment.ID, &models.OpenDisputeRequest{Reason: "fraudulent"}, "adj-001")
+	if err != nil {
+		t.Fatalf("OpenDispute failed: %v", err)
+	}
+	dispute, err = s.disputes.ResolveDispute(context.Background(), dispute.ID, &models.ResolveDisputeRequest{Outcome: models.DisputeStatusLost}, "adj-002")
+	if err != nil {
+		t.Fatalf("ResolveDispute failed: %v", err)
+	}
+
+	if stored, _ := s.payments.GetPaymentByID(payment.ID); stored.Status != models.PaymentStatusChargedBack {
+		t.Errorf("Expected the payment charged back, got %s", stored.Status)
+	}
+	if s.outstanding(t) != 1200 || s.commission(t) != 0 {
+		t.Errorf("Expected the premium due again and commission reversed, got %v outstanding and %v commission", s.outstanding(t), s.commission(t))
+	}
+	if !dispute.PolicyLapsed || s.lapser.lapsed["pol-001"] != "premium payment "+payment.ID+" charged back" {
+		t.Errorf("Expected the policy lapsed, got %+v and %v", dispute, s.lapser.lapsed)
+	}
+
+	// A failed lapse is recorded without undoing the chargeback
+	s.lapser.err = errors.New("policy cannot lapse: policy is cancelled")
+	second := s.pay(t)
+	dispute, err = s.disputes.OpenDispute(second.ID, &models.OpenDisputeRequest{Reason: "fraudulent"}, "adj-001")
+	if err != nil {
+		t.Fatalf("OpenDispute failed: %v", err)
+	}
+	dispute, err = s.disputes.ResolveDispute(context.Background(), dispute.ID, &models.ResolveDisputeRequest{Outcome: models.DisputeStatusLost}, "adj-002")
+	if err != nil {
+		t.Fatalf("ResolveDispute failed: %v", err)
+	}
+	if dispute.PolicyLapsed || dispute.LapseError != "policy cannot lapse: policy is cancelled" {
+		t.Errorf("Expected the lapse failure recorded, got %+v", dispute)
+	}
+
+	metrics, err := s.disputes.Metrics()
+	if err != nil {
+		t.Fatalf("Metrics failed: %v", err)
+	}
+	if metrics.Lost != 2 || metrics.LostAmount != 2400 || metrics.WinRate != 0 || metrics.DisputeRate != 1 || metrics.PoliciesLapsed != 1 {
+		t.Errorf("Unexpected metrics: %+v", metrics)
+	}
+
+	var out bytes.Buffer
+	s.disputes.WritePrometheus(&out)
+	for _, want := range []string{
+		`payment_disputes{status="open"} 0`,
+		`payment_disputes{status="lost"} 2`,
+		`payment_disputes_amount{status="lost"} 2400.00`,
+		`payment_disputes_policies_lapsed 1`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Metrics missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// gatewayStatuses are the statuses the gateway's events move payments to
+var gatewayStatuses = map[string]models.PaymentStatus{
+	models.GatewayPaymentFailed:   models.PaymentStatusFailed,
+	models.GatewayPaymentRefunded: models.PaymentStatusRefunded,
+}
+
+// ApplyGatewayEvent moves the payment a gateway callback names to the
+// status the event reports. The gateway delivers an event again until it is
+// acknowledged, so a payment already in that status is returned unchanged.
+// Event types this service does not act on return a nil payment. A move the
+// payment lifecycle refuses is a *lifecycle.TransitionError.
+func (s *PaymentService) ApplyGatewayEvent(evt *models.GatewayEvent) (*models.Payment, error) {
+	to, known := gatewayStatuses[evt.Type]
+	if !known {
+		s.logger.WithFields(logrus.Fields{
+			"eventId":   evt.ID,
+			"eventType": evt.Type,
+		}).Info("Ignored payment gateway event")
+		return nil, nil
+	}
+
+	payment, err := s.repo.GetPaymentByID(evt.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status == to {
+		return payment, nil
+	}
+
+	reason := evt.Reason
+	if reason == "" {
+		reason = "reported by the payment gateway in event " + evt.ID
+	}
+	change := lifecycle.Change{To: to, Reason: reason, By: "payment-gateway"}
+	if !evt.OccurredAt.IsZero() {
+		change.At = evt.OccurredAt
+	}
+	if err := s.lifecycle.Fire(payment, change, s.repo.UpdatePayment); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"eventId":   evt.ID,
+		"eventType": evt.Type,
+		"paymentId": payment.ID,
+	}).Info("Applied payment gateway event")
+	return payment, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+)
+
+func TestGatewayEventsMovePaymentsOnce(t *testing.T) {
+	service, store := newTestService(t, false,
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePremium, PolicyID: "pol-001", CustomerID: "cust-001", Amount: 150, Status: models.PaymentStatusProcessing},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePremium, PolicyID: "pol-001", CustomerID: "cust-001", Amount: 150, Status: models.PaymentStatusPending},
+	)
+
+	declined := &models.GatewayEvent{ID: "evt-001", Type: models.GatewayPaymentFailed, PaymentID: "pay-001", Reason: "card_declined"}
+	payment, err := service.ApplyGatewayEvent(declined)
+	if err != nil || payment.Status != models.PaymentStatusFailed {
+		t.Fatalf("Expected the payment to fail, got %+v, %v", payment, err)
+	}
+	// A redelivered event changes nothing
+	if payment, err := service.ApplyGatewayEvent(declined); err != nil || payment.Status != models.PaymentStatusFailed {
+		t.Errorf("Expected a redelivery to be acknowledged, got %+v, %v", payment, err)
+	}
+	if stored, _ := store.GetPaymentByID("pay-001"); stored.FailureReason != "card_declined" {
+		t.Errorf("Unexpected failure reason %q", stored.FailureReason)
+	}
+
+	// The lifecycle still decides: a pending payment cannot be refunded
+	var transitionErr *lifecycle.TransitionError
+	if _, err := service.ApplyGatewayEvent(&models.GatewayEvent{ID: "evt-002", Type: models.GatewayPaymentRefunded, PaymentID: "pay-002"}); !errors.As(err, &transitionErr) {
+		t.Errorf("Expected a refused transition, got %v", err)
+	}
+	if payment, err := service.ApplyGatewayEvent(&models.GatewayEvent{ID: "evt-003", Type: "payment.settled", PaymentID: "pay-404"}); payment != nil || err != nil {
+		t.Errorf("Expected an unknown event type to be ignored, got %+v, %v", payment, err)
+	}
+	if _, err := service.ApplyGatewayEvent(&models.GatewayEvent{ID: "evt-004", Type: models.GatewayPaymentFailed, PaymentID: "pay-404"}); err == nil || err.Error() != "payment not found" {
+		t.Errorf("Expected an unknown payment, got %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLateFeeGraceDays is how many days after its due date an invoice
+// can go unpaid before late fees are charged
+const DefaultLateFeeGraceDays = 10
+
+// LateFeeRule is what is charged on an invoice still unpaid GraceDays after
+// it is due: FlatFee once, and interest at APR a year on the premium
+// outstanding, accrued daily from the end of the grace period. Either may
+// be zero; a rule with neither charges no late fees.
+type LateFeeRule struct {
+	FlatFee   float64
+	APR       float64
+	GraceDays int
+}
+
+// Enabled reports whether the rule charges anything
+func (r LateFeeRule) Enabled() bool {
+	return r.FlatFee > 0 || r.APR > 0
+}
+
+// ChargeLateFees charges the late fees due at now on the invoices of every
+// policy in force with a premium payment or invoice on record. Each
+// policy's invoices and allocations are brought up to date first, as for a
+// balance, so premium already paid is not charged for, and credit is
+// allocated to the new fees after.
+func (s *BillingService) ChargeLateFees(ctx context.Context, now time.Time) (*models.LateFeeRun, error) {
+	if s.policies == nil {
+		return nil, fmt.Errorf("policy service not configured")
+	}
+	run := &models.LateFeeRun{Fees: []*models.LateFee{}, RunAt: now}
+	if !s.lateFees.Enabled() {
+		return run, nil
+	}
+
+	policies, err := billedPolicies(s.repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, billed := range policies {
+		policy, err := s.policies.GetPolicy(ctx, billed.id, billed.customerID)
+		if err != nil {
+			s.logger.WithError(err).WithField("policyId", billed.id).Warn("Cannot check policy for late fees")
+			continue
+		}
+		run.Policies++
+		if err := s.issueInvoices(policy, now); err != nil {
+			return nil, err
+		}
+		if err := s.Allocate(policy.ID); err != nil {
+			return nil, err
+		}
+		if !inForce(policy.Status) {
+			continue
+		}
+
+		fees, charged, err := s.charge(policy, now)
+		if err != nil {
+			return nil, err
+		}
+		if len(fees) == 0 {
+			continue
+		}
+		if err := s.Allocate(policy.ID); err != nil {
+			return nil, err
+		}
+		run.Fees = append(run.Fees, fees...)
+		run.Charged = roundCents(run.Charged + charged)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"policies": run.Policies,
+		"fees":     len(run.Fees),
+		"charged":  run.Charged,
+	}).Info("Late fees run")
+
+	return run, nil
+}
+
+// RunLateFees charges due late fees every interval until ctx is cancelled
+func (s *BillingService) RunLateFees(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ChargeLateFees(ctx, time.Now()); err != nil {
+				s.logger.WithError(err).Error("Failed to charge late fees")
+			}
+		}
+	}
+}
+
+// charge charges the late fees due at now on the policy's invoices past
+// their grace period, returning the fees charged or grown and how much
+// was charged
+func (s *BillingService) charge(policy *clients.Policy, now time.Time) ([]*models.LateFee, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paid := allocatedTo(s.repo.GetAllocationsByPolicy(policy.ID), debtOf)
+	existing := make(map[string]*models.LateFee)
+	for _, fee := range s.repo.GetLateFeesByPolicy(policy.ID) {
+		existing[fee.ID] = fee
+	}
+
+	var fees []*models.LateFee
+	charged := 0.0
+	for _, invoice := range s.repo.GetInvoicesByPolicy(policy.ID) {
+		graceEnd := invoice.DueDate.AddDate(0, 0, s.lateFees.GraceDays)
+		if !now.After(graceEnd) {
+			continue
+		}
+		outstanding := roundCents(invoice.Amount - paid[invoice.ID])
+
+		id := fmt.Sprintf("fee-%s-flat", invoice.ID)
+		if s.lateFees.FlatFee > 0 && outstanding > 0 && existing[id] == nil {
+			fee := &models.LateFee{
+				ID:         id,
+				InvoiceID:  invoice.ID,
+				PolicyID:   invoice.PolicyID,
+				CustomerID: invoice.CustomerID,
+				Kind:       models.LateFeeKindFlat,
+				Amount:     roundCents(s.lateFees.FlatFee),
+				ChargedAt:  now,
+				UpdatedAt:  now,
+			}
+			if err := s.repo.CreateLateFee(fee); err != nil {
+				return nil, 0, err
+			}
+			s.logFee(fee, fee.Amount)
+			fees = append(fees, fee)
+			charged += fee.Amount
+		}
+
+		if s.lateFees.APR > 0 {
+			fee, interest, err := s.accrue(existing[fmt.Sprintf("fee-%s-interest", invoice.ID)], invoice, outstanding, graceEnd, now)
+			if err != nil {
+				return nil, 0, err
+			}
+			if interest > 0 {
+				s.logFee(fee, interest)
+				fees = append(fees, fee)
+				charged += interest
+			}
+		}
+	}
+	return fees, roundCents(charged), nil
+}
+
+// accrue adds the interest on outstanding for every whole day from where
+// fee was last accrued to, or from graceEnd for an invoice not yet charged
+// interest. An invoice whose premium is paid accrues nothing; should the
+// payment be reversed, as by a chargeback, the days since are charged then.
+func (s *BillingService) accrue(fee *models.LateFee, invoice *models.Invoice, outstanding float64, graceEnd, now time.Time) (*models.LateFee, float64, error) {
+	if outstanding <= 0 {
+		return fee, 0, nil
+	}
+	from := graceEnd
+	if fee != nil && fee.AccruedTo != nil {
+		from = *fee.AccruedTo
+	}
+	days := int(now.Sub(from).Hours() / 24)
+	if days < 1 {
+		return fee, 0, nil
+	}
+	to := from.AddDate(0, 0, days)
+	interest := roundCents(outstanding * s.lateFees.APR / 365 * float64(days))
+	if interest <= 0 {
+		return fee, 0, nil
+	}
+
+	if fee == nil {
+		fee = &models.LateFee{
+			ID:         fmt.Sprintf("fee-%s-interest", invoice.ID),
+			InvoiceID:  invoice.ID,
+			PolicyID:   invoice.PolicyID,
+			CustomerID: invoice.CustomerID,
+			Kind:       models.LateFeeKindInterest,
+			Amount:     interest,
+			AccruedTo:  &to,
+			ChargedAt:  now,
+			UpdatedAt:  now,
+		}
+		return fee, interest, s.repo.CreateLateFee(fee)
+	}
+
+	fee.Amount = roundCents(fee.Amount + interest)
+	fee.AccruedTo = &to
+	fee.UpdatedAt = now
+	return fee, interest, s.repo.UpdateLateFee(fee)
+}
+
+// logFee logs a late fee charged, or the interest added to one
+func (s *BillingService) logFee(fee *models.LateFee, amount float64) {
+	s.logger.WithFields(logrus.Fields{
+		"feeId":     fee.ID,
+		"invoiceId": fee.InvoiceID,
+		"policyId":  fee.PolicyID,
+		"kind":      fee.Kind,
+		"amount":    amount,
+	}).Info("Late fee charged")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+)
+
+func TestLateFeesChargedAfterGraceAndPaidFirst(t *testing.T) {
+	policy := &clients.Policy{
+		ID:         "pol-q",
+		CustomerID: "cust-001",
+		Status:     "active",
+		Premium:    1200,
+		StartDate:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	now := time.Date(2026, 2, 5, 9, 0, 0, 0, time.UTC)
+	payments, billing := newBillingTestServices(t, 3, now, map[string]*clients.Policy{"pol-q": policy})
+	// 18.25% a year is 0.05% a day
+	billing.lateFees = LateFeeRule{FlatFee: 25, APR: 0.1825, GraceDays: 10}
+	billing.now = func() time.Time { return now }
+
+	charge := func(day int) *models.LateFeeRun {
+		t.Helper()
+		now = time.Date(2026, 2, day, 9, 0, 0, 0, time.UTC)
+		run, err := billing.ChargeLateFees(context.Background(), now)
+		if err != nil {
+			t.Fatalf("ChargeLateFees failed: %v", err)
+		}
+		return run
+	}
+	pay := func(amount float64) {
+		t.Helper()
+		payment, err := payments.CreatePayment(context.Background(), "pol-q", "cust-001", amount)
+		if err != nil {
+			t.Fatalf("CreatePayment failed: %v", err)
+		}
+		if _, err := payments.ProcessPayment(context.Background(), payment.ID); err != nil {
+			t.Fatalf("ProcessPayment failed: %v", err)
+		}
+	}
+	balance := func() *models.PolicyBalance {
+		t.Helper()
+		balance, err := billing.GetPolicyBalance(context.Background(), "pol-q", "cust-001")
+		if err != nil {
+			t.Fatalf("GetPolicyBalance failed: %v", err)
+		}
+		return balance
+	}
+
+	// The first quarter's 295.89 is due on January 31, with grace to
+	// February 10
+	if got := balance(); got.Overdue != 295.89 {
+		t.Fatalf("Expected the first quarter overdue, got %+v", got)
+	}
+	if run := charge(9); run.Policies != 1 || len(run.Fees) != 0 {
+		t.Fatalf("Expected nothing charged within grace, got %+v", run)
+	}
+
+	// Five days past grace: the flat fee and five days' interest
+	run := charge(15)
+	if len(run.Fees) != 2 || run.Charged != 25.74 {
+		t.Fatalf("Expected the flat fee and 0.74 interest, got %+v", run)
+	}
+	got := balance()
+	if line := got.Invoices[0]; line.LateFees != 25.74 || line.LateFeesOutstanding != 25.74 || line.Outstanding != 295.89 {
+		t.Errorf("Unexpected invoice: %+v", line)
+	}
+	if got.Due != 321.63 || got.LateFees != 25.74 || got.Paid != 0 || got.Overdue != 321.63 {
+		t.Errorf("Unexpected totals: %+v", got)
+	}
+
+	// Lapsed policies are no longer chased
+	policy.Status = "lapsed"
+	if run := charge(17); run.Policies != 1 || len(run.Fees) != 0 {
+		t.Errorf("Expected a lapsed policy skipped, got %+v", run)
+	}
+	policy.Status = "active"
+
+	// A payment settles the fees before the premium
+	pay(100)
+	if line := balance().Invoices[0]; line.LateFeesOutstanding != 0 || line.Paid != 74.26 || line.Outstanding != 221.63 {
+		t.Errorf("Expected the fees paid first: %+v", line)
+	}
+
+	// Interest accrues on the premium still outstanding, and the flat fee
+	// is not charged again
+	run = charge(20)
+	if len(run.Fees) != 1 || run.Fees[0].Kind != models.LateFeeKindInterest || run.Charged != 0.55 || run.Fees[0].Amount != 1.29 {
+		t.Fatalf("Expected five more days' interest on 221.63, got %+v", run)
+	}
+
+	// Paid in full, so nothing more is charged
+	pay(222.18)
+	got = balance()
+	if got.Outstanding != 0 || got.Overdue != 0 || got.Invoices[0].Status != models.InvoiceStatusPaid || got.Credit != 0 {
+		t.Errorf("Expected the invoice paid with its fees: %+v", got)
+	}
+	if run := charge(25); len(run.Fees) != 0 {
+		t.Errorf("Expected no fees on a paid invoice, got %+v", run.Fees)
+	}
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// lossRatioMonth is the layout of the months the loss ratio report groups by
+const lossRatioMonth = "2006-01"
+
+// LossRatioReporter computes loss ratios from the premiums and payouts held
+// by this service. Each payment is grouped under the policy type held by
+// policy-service; payouts are first matched to their claim's policy in
+// claims-service.
+type LossRatioReporter struct {
+	repo     repository.PaymentStore
+	policies PolicyLookup
+	claims   ClaimLookup
+	logger   *logrus.Logger
+}
+
+// NewLossRatioReporter creates a loss ratio reporter. Either lookup may be
+// nil when the owning service is not configured; the payments it would
+// have matched are then reported under models.UnknownPolicyType.
+func NewLossRatioReporter(repo repository.PaymentStore, policies PolicyLookup, claims ClaimLookup, logger *logrus.Logger) *LossRatioReporter {
+	return &LossRatioReporter{
+		repo:     repo,
+		policies: policies,
+		claims:   claims,
+		logger:   logger,
+	}
+}
+
+// Report computes the loss ratio of the payments settled in the months
+// filters allows. Only completed payments count; a payment is dated by when
+// it was processed.
+func (r *LossRatioReporter) Report(ctx context.Context, filters models.LossRatioFilters) (*models.LossRatioReport, error) {
+	payments, err := r.repo.GetAllPayments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load payments: %w", err)
+	}
+
+	types := &policyTypes{
+		ctx:      ctx,
+		policies: r.policies,
+		claims:   r.claims,
+		logger:   r.logger,
+		byPolicy: make(map[string]string),
+		byClaim:  make(map[string]string),
+	}
+	months := make(map[[2]string]*models.LossRatio)
+	byType := make(map[string]*models.LossRatio)
+	report := &models.LossRatioReport{
+		GeneratedAt: time.Now(),
+		From:        filters.From,
+		To:          filters.To,
+		Total:       models.LossRatio{PolicyType: "all"},
+	}
+
+	for _, payment := range payments {
+		if payment.Status != models.PaymentStatusCompleted {
+			continue
+		}
+		month := settledAt(payment).UTC().Format(lossRatioMonth)
+		if filters.From != "" && month < filters.From || filters.To != "" && month > filters.To {
+			continue
+		}
+
+		policyType := types.of(payment)
+		key := [2]string{policyType, month}
+		if months[key] == nil {
+			months[key] = &models.LossRatio{PolicyType: policyType, Month: month}
+		}
+		if byType[policyType] == nil {
+			byType[policyType] = &models.LossRatio{PolicyType: policyType}
+		}
+		months[key].Add(payment)
+		byType[policyType].Add(payment)
+		report.Total.Add(payment)
+	}
+
+	report.Months = make([]models.LossRatio, 0, len(months))
+	for _, row := range months {
+		row.Finish()
+		report.Months = append(report.Months, *row)
+	}
+	sort.Slice(report.Months, func(i, j int) bool {
+		if report.Months[i].PolicyType != report.Months[j].PolicyType {
+			return report.Months[i].PolicyType < report.Months[j].PolicyType
+		}
+		return report.Months[i].Month < report.Months[j].Month
+	})
+	report.ByPolicyType = make([]models.LossRatio, 0, len(byType))
+	for _, row := range byType {
+		row.Finish()
+		report.ByPolicyType = append(report.ByPolicyType, *row)
+	}
+	sort.Slice(report.ByPolicyType, func(i, j int) bool {
+		return report.ByPolicyType[i].PolicyType < report.ByPolicyType[j].PolicyType
+	})
+	report.Total.Finish()
+	report.Warnings = types.warnings()
+
+	r.logger.WithFields(logrus.Fields{
+		"months":   len(report.Months),
+		"warnings": len(report.Warnings),
+	}).Info("Loss ratio report completed")
+
+	return report, nil
+}
+
+// settledAt returns when a payment was settled, or when it was created if
+// it records no processing date
+func settledAt(payment *models.Payment) time.Time {
+	if payment.ProcessedDate != nil {
+		return *payment.ProcessedDate
+	}
+	return payment.CreatedAt
+}
+
+// policyTypes resolves the policy type of payments for one report, looking
+// each policy and claim up once. Like the consistency checks it stops
+// calling a service after its first transport failure.
+type policyTypes struct {
+	ctx      context.Context
+	policies PolicyLookup
+	claims   ClaimLookup
+	logger   *logrus.Logger
+
+	byPolicy map[string]string
+	byClaim  map[string]string
+
+	policiesDown bool
+	claimsDown   bool
+	noClaims     bool // a payout needed claims-service while it is not configured
+	unmatched    int  // policies and claims that were not found
+}
+
+// of returns the policy type a settled payment belongs to
+func (t *policyTypes) of(payment *models.Payment) string {
+	if payment.Type != models.PaymentTypePayout {
+		return t.ofPolicy(payment.PolicyID, payment.CustomerID)
+	}
+
+	if policyType, seen := t.byClaim[payment.ClaimID]; seen {
+		return policyType
+	}
+	policyType := models.UnknownPolicyType
+	switch {
+	case t.claims == nil:
+		t.noClaims = true
+		if payment.PolicyID != "" {
+			policyType = t.ofPolicy(payment.PolicyID, payment.CustomerID)
+		}
+	case t.claimsDown:
+	default:
+		claim, err := t.claims.GetClaim(t.ctx, payment.ClaimID)
+		switch {
+		case err == nil:
+			policyType = t.ofPolicy(claim.PolicyID, claim.CustomerID)
+		case err.Error() == "claim not found":
+			t.unmatched++
+		default:
+			t.logger.WithError(err).Warn("claims-service lookup failed, reporting remaining payouts as unknown")
+			t.claimsDown = true
+		}
+	}
+	if !t.claimsDown {
+		t.byClaim[payment.ClaimID] = policyType
+	}
+	return policyType
+}
+
+// ofPolicy returns the type of a policy, read as its customer would
+func (t *policyTypes) ofPolicy(policyID, customerID string) string {
+	if policyType, seen := t.byPolicy[policyID]; seen {
+		return policyType
+	}
+	if t.policies == nil || t.policiesDown || policyID == "" {
+		return models.UnknownPolicyType
+	}
+
+	policy, err := t.policies.GetPolicy(t.ctx, policyID, customerID)
+	switch {
+	case err == nil && policy.Type != "":
+		t.byPolicy[policyID] = policy.Type
+		return policy.Type
+	case err == nil, err.Error() == "policy not found", err.Error() == "unauthorized":
+		t.unmatched++
+		t.byPolicy[policyID] = models.UnknownPolicyType
+	default:
+		t.logger.WithError(err).Warn("policy-service lookup failed, reporting remaining payments as unknown")
+		t.policiesDown = true
+	}
+	return models.UnknownPolicyType
+}
+
+// warnings explains why payments were reported under the unknown policy
+// type
+func (t *policyTypes) warnings() []string {
+	var warnings []string
+	if t.policies == nil {
+		warnings = append(warnings, "policy types unavailable: policy-service is not configured")
+	}
+	if t.policiesDown {
+		warnings = append(warnings, "policy types incomplete: policy-service could not be reached")
+	}
+	if t.noClaims {
+		warnings = append(warnings, "payout policy types unavailable: claims-service is not configured")
+	}
+	if t.claimsDown {
+		warnings = append(warnings, "payout policy types incomplete: claims-service could not be reached")
+	}
+	if t.unmatched > 0 {
+		warnings = append(warnings, fmt.Sprintf("%d policies or claims referenced by payments could not be found", t.unmatched))
+	}
+	return warnings
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestLossRatioReport(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	day := func(month time.Month, d int) *time.Time {
+		t := time.Date(2024, month, d, 12, 0, 0, 0, time.UTC)
+		return &t
+	}
+	completed := models.PaymentStatusCompleted
+	store := repositorytest.NewFakeStore(
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePremium, PolicyID: "pol-auto", CustomerID: "cust-001", Amount: 1000, Status: completed, ProcessedDate: day(11, 3)},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePremium, PolicyID: "pol-auto", CustomerID: "cust-001", Amount: 1000, Status: completed, ProcessedDate: day(12, 3)},
+		&models.Payment{ID: "pay-003", Type: models.PaymentTypeRefund, PolicyID: "pol-auto", CustomerID: "cust-001", Amount: 200, Status: completed, ProcessedDate: day(12, 20)},
+		&models.Payment{ID: "pay-004", Type: models.PaymentTypePremium, PolicyID: "pol-home", CustomerID: "cust-002", Amount: 500, Status: completed, ProcessedDate: day(12, 5)},
+		&models.Payment{ID: "pay-005", Type: models.PaymentTypePremium, PolicyID: "pol-home", CustomerID: "cust-002", Amount: 500, Status: models.PaymentStatusRefunded, ProcessedDate: day(12, 6)},
+		&models.Payment{ID: "pay-006", Type: models.PaymentTypePremium, PolicyID: "pol-home", CustomerID: "cust-002", Amount: 500, Status: models.PaymentStatusPending, CreatedAt: *day(12, 7)},
+		&models.Payment{ID: "payout-001", Type: models.PaymentTypePayout, ClaimID: "claim-auto", CustomerID: "cust-001", Amount: 1200, Status: completed, ProcessedDate: day(12, 10)},
+		&models.Payment{ID: "payout-002", Type: models.PaymentTypePayout, ClaimID: "claim-home", CustomerID: "cust-002", Amount: 250, Status: completed, ProcessedDate: day(11, 10)},
+		&models.Payment{ID: "payout-003", Type: models.PaymentTypePayout, ClaimID: "claim-gone", CustomerID: "cust-003", Amount: 99, Status: completed, ProcessedDate: day(12, 11)},
+	)
+	lookups := &stubLookups{
+		policies: map[string]*clients.Policy{
+			"pol-auto": {ID: "pol-auto", CustomerID: "cust-001", Type: "auto"},
+			"pol-home": {ID: "pol-home", CustomerID: "cust-002", Type: "home"},
+		},
+		claims: map[string]*clients.Claim{
+			"claim-auto": {ID: "claim-auto", PolicyID: "pol-auto", CustomerID: "cust-001"},
+			"claim-home": {ID: "claim-home", PolicyID: "pol-home", CustomerID: "cust-002"},
+		},
+	}
+
+	report, err := NewLossRatioReporter(store, lookups, lookups, logger).Report(context.Background(), models.LossRatioFilters{})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+
+	type row struct {
+		policyType, month string
+		premiums, payouts float64
+		ratio             float64 // -1 for none
+	}
+	want := []row{
+		{"auto", "2024-11", 1000, 0, 0},
+		{"auto", "2024-12", 800, 1200, 1.5},
+		{"home", "2024-11", 0, 250, -1},
+		{"home", "2024-12", 500, 0, 0},
+		{models.UnknownPolicyType, "2024-12", 0, 99, -1},
+	}
+	if len(report.Months) != len(want) {
+		t.Fatalf("Expected %d monthly rows, got %+v", len(want), report.Months)
+	}
+	for i, w := range want {
+		got := report.Months[i]
+		if got.PolicyType != w.policyType || got.Month != w.month || got.Premiums != w.premiums || got.Payouts != w.payouts {
+			t.Errorf("Row %d: got %+v, want %+v", i, got, w)
+		}
+		if w.ratio < 0 && got.LossRatio != nil || w.ratio >= 0 && (got.LossRatio == nil || *got.LossRatio != w.ratio) {
+			t.Errorf("Row %d: got loss ratio %v, want %v", i, got.LossRatio, w.ratio)
+		}
+	}
+
+	if report.Total.Premiums != 2300 || report.Total.Payouts != 1549 {
+		t.Errorf("Unexpected total %+v", report.Total)
+	}
+	if len(report.ByPolicyType) != 3 || report.ByPolicyType[0].PolicyType != "auto" || *report.ByPolicyType[0].LossRatio != 0.6666666666666666 {
+		t.Errorf("Unexpected policy type totals %+v", report.ByPolicyType)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0] != "1 policies or claims referenced by payments could not be found" {
+		t.Errorf("Unexpected warnings %v", report.Warnings)
+	}
+
+	filtered, _ := NewLossRatioReporter(store, lookups, lookups, logger).Report(context.Background(), models.LossRatioFilters{From: "2024-12", To: "2024-12"})
+	if filtered.Total.Premiums != 1300 || filtered.Total.Payouts != 1299 {
+		t.Errorf("Expected only December, got %+v", filtered.Total)
+	}
+}
+
+func TestLossRatioReportWithoutOtherServices(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	store := repositorytest.NewFakeStore(
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePremium, PolicyID: "pol-001", CustomerID: "cust-001", Amount: 1000, Status: models.PaymentStatusCompleted},
+		&models.Payment{ID: "payout-001", Type: models.PaymentTypePayout, ClaimID: "claim-001", CustomerID: "cust-001", Amount: 400, Status: models.PaymentStatusCompleted},
+	)
+
+	report, err := NewLossRatioReporter(store, nil, nil, logger).Report(context.Background(), models.LossRatioFilters{})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if len(report.ByPolicyType) != 1 || report.ByPolicyType[0].PolicyType != models.UnknownPolicyType || *report.ByPolicyType[0].LossRatio != 0.4 {
+		t.Errorf("Expected everything under unknown, got %+v", report.ByPolicyType)
+	}
+	if len(report.Warnings) != 2 {
+		t.Errorf("Expected warnings for both missing services, got %v", report.Warnings)
+	}
+
+	down := &stubLookups{err: errors.New("policy-service request failed: connection refused")}
+	report, _ = NewLossRatioReporter(store, down, down, logger).Report(context.Background(), models.LossRatioFilters{})
+	if len(report.Warnings) != 2 || report.Warnings[0] != "policy types incomplete: policy-service could not be reached" {
+		t.Errorf("Expected unreachable warnings, got %v", report.Warnings)
+	}
+}
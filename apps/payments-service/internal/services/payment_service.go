@@ -1,29 +1,54 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/screening"
 	"github.com/sirupsen/logrus"
 )
 
 // PaymentService handles payment business logic
 type PaymentService struct {
-	repo   *repository.Repository
-	flags  *features.Flags
-	logger *logrus.Logger
+	repo      repository.PaymentStore
+	flags     *features.Flags
+	lookups   Lookups
+	agents    *AgentService
+	billing   *BillingService
+	screener  screening.Screener
+	lifecycle *lifecycle.Machine
+	logger    *logrus.Logger
 }
 
-// NewPaymentService creates a new payment service
-func NewPaymentService(repo *repository.Repository, flags *features.Flags, logger *logrus.Logger) *PaymentService {
-	return &PaymentService{
-		repo:   repo,
-		flags:  flags,
-		logger: logger,
+// NewPaymentService creates a new payment service. lookups supply the
+// customer details instantPayouts rollouts target on and the agent behind
+// each policy. agents may be nil, in which case no commission is recorded,
+// and billing may be nil, in which case premiums are not allocated to
+// invoices. screener checks payout recipients against sanctions lists before they
+// are paid; when nil payouts are not screened.
+func NewPaymentService(repo repository.PaymentStore, flags *features.Flags, lookups Lookups, agents *AgentService, billing *BillingService, screener screening.Screener, logger *logrus.Logger) *PaymentService {
+	s := &PaymentService{
+		repo:      repo,
+		flags:     flags,
+		lookups:   lookups,
+		agents:    agents,
+		billing:   billing,
+		screener:  screener,
+		lifecycle: lifecycle.Payments(),
+		logger:    logger,
 	}
+	s.lifecycle.OnTransition(s.logTransition)
+	s.lifecycle.OnTransition(s.updateCommission)
+	s.lifecycle.OnTransition(s.updateAllocations)
+	return s
 }
 
 // GetAllPayments returns all payments
@@ -31,18 +56,103 @@ func (s *PaymentService) GetAllPayments() ([]*models.Payment, error) {
 	return s.repo.GetAllPayments()
 }
 
+// GetPaymentsByClaimID returns the payouts made against a claim, oldest
+// first
+func (s *PaymentService) GetPaymentsByClaimID(claimID string) ([]*models.Payment, error) {
+	payments, err := s.repo.GetAllPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	claimPayments := []*models.Payment{}
+	for _, payment := range payments {
+		if payment.ClaimID == claimID {
+			claimPayments = append(claimPayments, payment)
+		}
+	}
+	sort.Slice(claimPayments, func(i, j int) bool {
+		if !claimPayments[i].CreatedAt.Equal(claimPayments[j].CreatedAt) {
+			return claimPayments[i].CreatedAt.Before(claimPayments[j].CreatedAt)
+		}
+		return claimPayments[i].ID < claimPayments[j].ID
+	})
+
+	return claimPayments, nil
+}
+
+// PayoutFailureRate returns the share of the payouts processed since
+// since that failed, or 0 when none were processed
+func (s *PaymentService) PayoutFailureRate(since time.Time) (float64, error) {
+	payments, err := s.repo.GetAllPayments()
+	if err != nil {
+		return 0, err
+	}
+
+	processed, failed := 0, 0
+	for _, payment := range payments {
+		if payment.Type != models.PaymentTypePayout || payment.ProcessedDate == nil || payment.ProcessedDate.Before(since) {
+			continue
+		}
+		processed++
+		if payment.Status == models.PaymentStatusFailed {
+			failed++
+		}
+	}
+	if processed == 0 {
+		return 0, nil
+	}
+	return float64(failed) / float64(processed), nil
+}
+
 // GetPaymentByID returns a payment by ID
 func (s *PaymentService) GetPaymentByID(paymentID string) (*models.Payment, error) {
 	return s.repo.GetPaymentByID(paymentID)
 }
 
-// CreatePayment creates a new premium payment
-func (s *PaymentService) CreatePayment(policyID, customerID string, amount float64) (*models.Payment, error) {
+// GetArchivedPayments returns the payments the retention policy moved to
+// the archive, oldest first. A claimID narrows them to the payouts made
+// against that claim.
+func (s *PaymentService) GetArchivedPayments(claimID string) ([]*models.Payment, error) {
+	payments, err := s.repo.GetArchivedPayments()
+	if err != nil {
+		return nil, err
+	}
+
+	archived := []*models.Payment{}
+	for _, payment := range payments {
+		if claimID == "" || payment.ClaimID == claimID {
+			archived = append(archived, payment)
+		}
+	}
+	sort.Slice(archived, func(i, j int) bool {
+		if !archived[i].CreatedAt.Equal(archived[j].CreatedAt) {
+			return archived[i].CreatedAt.Before(archived[j].CreatedAt)
+		}
+		return archived[i].ID < archived[j].ID
+	})
+
+	return archived, nil
+}
+
+// GetArchivedPayment returns an archived payment by ID
+func (s *PaymentService) GetArchivedPayment(paymentID string) (*models.Payment, error) {
+	return s.repo.GetArchivedPayment(paymentID)
+}
+
+// CreatePayment creates a new premium payment, crediting the agent who sold
+// the policy. ctx bounds the policy lookup.
+func (s *PaymentService) CreatePayment(ctx context.Context, policyID, customerID string, amount float64) (*models.Payment, error) {
+	return s.createPremium(ctx, fmt.Sprintf("pay-%d", time.Now().UnixNano()), policyID, customerID, amount)
+}
+
+// createPremium creates the premium payment with ID paymentID
+func (s *PaymentService) createPremium(ctx context.Context, paymentID, policyID, customerID string, amount float64) (*models.Payment, error) {
 	payment := &models.Payment{
-		ID:         fmt.Sprintf("pay-%d", time.Now().UnixNano()),
+		ID:         paymentID,
 		Type:       models.PaymentTypePremium,
 		PolicyID:   policyID,
 		CustomerID: customerID,
+		AgentID:    s.policyAgent(ctx, policyID, customerID),
 		Amount:     amount,
 		Status:     models.PaymentStatusPending,
 		CreatedAt:  time.Now(),
@@ -53,6 +163,7 @@ func (s *PaymentService) CreatePayment(policyID, customerID string, amount float
 		"paymentId":  payment.ID,
 		"policyId":   policyID,
 		"customerId": customerID,
+		"agentId":    payment.AgentID,
 		"amount":     amount,
 	}).Info("Creating premium payment")
 
@@ -63,8 +174,43 @@ func (s *PaymentService) CreatePayment(policyID, customerID string, amount float
 	return payment, nil
 }
 
-// CreatePayout creates a new claim payout
-func (s *PaymentService) CreatePayout(claimID, customerID string, amount float64) (*models.Payment, error) {
+// CreateRefund creates a refund of premium to a policyholder. Refunds are
+// queued like premiums and settled with ProcessPayment.
+func (s *PaymentService) CreateRefund(policyID, customerID string, amount float64) (*models.Payment, error) {
+	payment := &models.Payment{
+		ID:         fmt.Sprintf("pay-%d", time.Now().UnixNano()),
+		Type:       models.PaymentTypeRefund,
+		PolicyID:   policyID,
+		CustomerID: customerID,
+		Amount:     amount,
+		Status:     models.PaymentStatusPending,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"paymentId":  payment.ID,
+		"policyId":   policyID,
+		"customerId": customerID,
+		"amount":     amount,
+	}).Info("Creating premium refund")
+
+	if err := s.repo.CreatePayment(payment); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// CreatePayout creates a new claim payout. The claim's customer must have
+// accepted a settlement offer covering the amount. ctx carries the caller's
+// tenant and bounds the claim lookup and any lookups made for flag
+// targeting.
+func (s *PaymentService) CreatePayout(ctx context.Context, claimID, customerID string, amount float64) (*models.Payment, error) {
+	if err := s.checkSettlement(ctx, claimID, amount); err != nil {
+		return nil, err
+	}
+
 	payment := &models.Payment{
 		ID:         fmt.Sprintf("pay-%d", time.Now().UnixNano()),
 		Type:       models.PaymentTypePayout,
@@ -87,26 +233,109 @@ func (s *PaymentService) CreatePayout(claimID, customerID string, amount float64
 		return nil, err
 	}
 
-	// Check if instant payouts are enabled
-	if s.flags.IsInstantPayoutsEnabled() && payment.Type == models.PaymentTypePayout {
-		s.logger.WithField("paymentId", payment.ID).Info("Instant payouts enabled - processing immediately")
-		return s.ProcessPayment(payment.ID)
+	// Check if instant payouts are enabled for this customer
+	target := s.payoutTarget(ctx, claimID, customerID)
+	if s.flags.IsInstantPayoutsEnabledFor(target) && payment.Type == models.PaymentTypePayout {
+		if s.flags.InstantPayoutsRequireKYC() && !s.kycVerified(ctx, customerID) {
+			s.logger.WithFields(logrus.Fields{
+				"paymentId":  payment.ID,
+				"customerId": customerID,
+			}).Info("Customer KYC not verified - payout queued for batch processing")
+			return payment, nil
+		}
+		s.logger.WithFields(logrus.Fields{
+			"paymentId": payment.ID,
+			"tenantId":  target.TenantID,
+		}).Info("Instant payouts enabled - processing immediately")
+		return s.ProcessPayment(ctx, payment.ID)
 	}
 
-	s.logger.WithField("paymentId", payment.ID).Info("Instant payouts disabled - payout queued for batch processing")
+	s.logger.WithFields(logrus.Fields{
+		"paymentId": payment.ID,
+		"tenantId":  target.TenantID,
+	}).Info("Instant payouts disabled - payout queued for batch processing")
 	return payment, nil
 }
 
-// ProcessPayment processes a pending payment
-func (s *PaymentService) ProcessPayment(paymentID string) (*models.Payment, error) {
+// checkSettlement checks a payout against the claim's accepted settlement
+// offer: together with the claim's earlier payouts that have not failed, it
+// may not exceed the accepted amount. Without claims-service offers cannot
+// be checked, so payouts are let through.
+func (s *PaymentService) checkSettlement(ctx context.Context, claimID string, amount float64) error {
+	if s.lookups.Claims == nil {
+		s.logger.WithField("claimId", claimID).Warn("Claims service not configured, cannot check settlement offer")
+		return nil
+	}
+	claim, err := s.lookups.Claims.GetClaim(ctx, claimID)
+	if err != nil {
+		if err.Error() == "claim not found" {
+			return err
+		}
+		return fmt.Errorf("claim lookup failed: %w", err)
+	}
+	offer := claim.AcceptedOffer()
+	if offer == nil {
+		return fmt.Errorf("claim has no accepted settlement offer")
+	}
+
+	payments, err := s.GetPaymentsByClaimID(claimID)
+	if err != nil {
+		return err
+	}
+	paid := 0.0
+	for _, payment := range payments {
+		if payment.Type == models.PaymentTypePayout && payment.Status != models.PaymentStatusFailed {
+			paid += payment.Amount
+		}
+	}
+	if math.Round((paid+amount)*100) > math.Round(offer.Amount*100) {
+		return fmt.Errorf("payout exceeds the accepted offer of %.2f (%.2f already paid out)", offer.Amount, paid)
+	}
+	return nil
+}
+
+// kycVerified reports whether customer-service has the customer's identity
+// as verified. When it cannot tell, the customer is treated as unverified.
+func (s *PaymentService) kycVerified(ctx context.Context, customerID string) bool {
+	if s.lookups.Customers == nil {
+		s.logger.WithField("customerId", customerID).Warn("Customer service not configured, cannot check KYC")
+		return false
+	}
+	customer, err := s.lookups.Customers.GetCustomer(ctx, customerID)
+	if err != nil {
+		s.logger.WithError(err).WithField("customerId", customerID).Warn("Customer lookup for KYC check failed")
+		return false
+	}
+	return customer.KYCStatus == "verified"
+}
+
+// ProcessPayment takes a pending payment through processing to completed.
+// The payment is saved as processing first, so a second request for the
+// same payment is refused while the first is under way. A payment that is
+// not pending is refused with a *lifecycle.TransitionError.
+//
+// Payouts are screened against sanctions lists while they are processed. A
+// recipient who may be listed, or who could not be screened, leaves the
+// payout blocked instead of completed, and it is returned without an
+// error; see ReleasePayment. ctx bounds the screening.
+func (s *PaymentService) ProcessPayment(ctx context.Context, paymentID string) (*models.Payment, error) {
 	payment, err := s.repo.GetPaymentByID(paymentID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Validate that payment is in pending state
-	if payment.Status != models.PaymentStatusPending {
-		return nil, fmt.Errorf("payment already processed")
+	if err := s.lifecycle.Fire(payment, lifecycle.Change{To: models.PaymentStatusProcessing}, s.repo.UpdatePayment); err != nil {
+		return nil, err
+	}
+
+	if payment.Type == models.PaymentTypePayout {
+		blocked, err := s.screen(ctx, payment)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return payment, nil
+		}
 	}
 
 	// Simulate payment processing
@@ -119,13 +348,7 @@ func (s *PaymentService) ProcessPayment(paymentID string) (*models.Payment, erro
 	// Simulate some processing time
 	time.Sleep(100 * time.Millisecond)
 
-	// Update payment status
-	now := time.Now()
-	payment.Status = models.PaymentStatusCompleted
-	payment.ProcessedDate = &now
-	payment.UpdatedAt = now
-
-	if err := s.repo.UpdatePayment(payment); err != nil {
+	if err := s.lifecycle.Fire(payment, lifecycle.Change{To: models.PaymentStatusCompleted}, s.repo.UpdatePayment); err != nil {
 		return nil, err
 	}
 
@@ -133,3 +356,193 @@ func (s *PaymentService) ProcessPayment(paymentID string) (*models.Payment, erro
 
 	return payment, nil
 }
+
+// FailPayment marks a payment that is being processed as failed, e.g. one
+// left processing when the service stopped part way through, or a blocked
+// payout whose recipient compliance confirmed is sanctioned
+func (s *PaymentService) FailPayment(paymentID, reason string) (*models.Payment, error) {
+	return s.changeStatus(paymentID, lifecycle.Change{To: models.PaymentStatusFailed, Reason: reason})
+}
+
+// ReleasePayment returns a payout blocked by sanctions screening to
+// pending, recording who released it and why, so it can be processed. A
+// released payout is paid without being screened again.
+func (s *PaymentService) ReleasePayment(paymentID, releasedBy, reason string) (*models.Payment, error) {
+	return s.changeStatus(paymentID, lifecycle.Change{To: models.PaymentStatusPending, Reason: reason, By: releasedBy})
+}
+
+// RefundPayment marks a completed payment as refunded. Commission earned on
+// a refunded premium is reversed.
+func (s *PaymentService) RefundPayment(paymentID, reason string) (*models.Payment, error) {
+	return s.changeStatus(paymentID, lifecycle.Change{To: models.PaymentStatusRefunded, Reason: reason})
+}
+
+// DisputePayment marks a completed premium as disputed by the payer, which
+// provisionally reverses what it paid on the policy's invoices
+func (s *PaymentService) DisputePayment(paymentID, disputedBy, reason string) (*models.Payment, error) {
+	return s.changeStatus(paymentID, lifecycle.Change{To: models.PaymentStatusDisputed, Reason: reason, By: disputedBy})
+}
+
+// SettleDisputedPayment records the outcome of a disputed premium: a won
+// dispute completes it again and reallocates it, a lost one charges it back
+// and reverses the commission it earned
+func (s *PaymentService) SettleDisputedPayment(paymentID string, won bool, settledBy, reason string) (*models.Payment, error) {
+	to := models.PaymentStatusChargedBack
+	if won {
+		to = models.PaymentStatusCompleted
+	}
+	return s.changeStatus(paymentID, lifecycle.Change{To: to, Reason: reason, By: settledBy})
+}
+
+// screen checks a payout's recipient against the sanctions list and
+// records the result on the payout, which is saved with its next status.
+// It reports whether the payout was blocked. Payouts released after being
+// blocked are not screened again.
+func (s *PaymentService) screen(ctx context.Context, payment *models.Payment) (bool, error) {
+	if s.screener == nil || payment.Screening.Released() {
+		return false, nil
+	}
+
+	party := s.screeningParty(ctx, payment.CustomerID)
+	result, err := s.screener.Screen(ctx, party)
+	record := &models.Screening{
+		Status:     models.ScreeningStatusClear,
+		List:       result.List,
+		Name:       party.Name,
+		Country:    party.Country,
+		Hits:       result.Hits,
+		ScreenedAt: time.Now(),
+	}
+	reason := ""
+	switch {
+	case err != nil:
+		record.Status = models.ScreeningStatusError
+		record.Error = err.Error()
+		reason = "recipient could not be screened"
+	case len(result.Hits) > 0:
+		record.Status = models.ScreeningStatusHit
+		reason = fmt.Sprintf("recipient may be on the %s list", result.List)
+	}
+	payment.Screening = record
+
+	fields := logrus.Fields{
+		"paymentId":  payment.ID,
+		"customerId": payment.CustomerID,
+		"list":       record.List,
+		"result":     record.Status,
+		"hits":       len(record.Hits),
+	}
+	if reason == "" {
+		s.logger.WithFields(fields).Info("Payout recipient cleared sanctions screening")
+		return false, nil
+	}
+	s.logger.WithFields(fields).WithField("error", record.Error).Warn("Payout blocked by sanctions screening")
+	return true, s.lifecycle.Fire(payment, lifecycle.Change{To: models.PaymentStatusBlocked, Reason: reason}, s.repo.UpdatePayment)
+}
+
+// screeningParty describes a payout's recipient from customer-service.
+// When the customer cannot be looked up the party has no name, and
+// screeners that need one refuse to clear it.
+func (s *PaymentService) screeningParty(ctx context.Context, customerID string) screening.Party {
+	party := screening.Party{CustomerID: customerID}
+	if s.lookups.Customers == nil {
+		return party
+	}
+	customer, err := s.lookups.Customers.GetCustomer(ctx, customerID)
+	if err != nil {
+		s.logger.WithError(err).WithField("customerId", customerID).Warn("Customer lookup for sanctions screening failed")
+		return party
+	}
+	party.Name = strings.TrimSpace(customer.FirstName + " " + customer.LastName)
+	party.Country = customer.Address.Country
+	return party
+}
+
+// changeStatus applies a status change to a stored payment
+func (s *PaymentService) changeStatus(paymentID string, change lifecycle.Change) (*models.Payment, error) {
+	payment, err := s.repo.GetPaymentByID(paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lifecycle.Fire(payment, change, s.repo.UpdatePayment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// logTransition records every saved status change as a
+// payment.status_changed event in the service log
+func (s *PaymentService) logTransition(payment *models.Payment, from models.PaymentStatus, change lifecycle.Change) {
+	s.logger.WithFields(logrus.Fields{
+		"event":      "payment.status_changed",
+		"paymentId":  payment.ID,
+		"type":       payment.Type,
+		"customerId": payment.CustomerID,
+		"amount":     payment.Amount,
+		"oldStatus":  from,
+		"newStatus":  payment.Status,
+		"reason":     change.Reason,
+		"changedBy":  change.By,
+	}).Info("Payment status changed")
+}
+
+// updateCommission keeps the commission ledger in step with premiums:
+// completed premiums earn the selling agent commission and refunded or
+// charged back ones reverse it. A premium completed again by a won dispute
+// keeps the commission it already earned. The payment has already changed
+// status, so a failure here is logged rather than returned.
+func (s *PaymentService) updateCommission(payment *models.Payment, from models.PaymentStatus, change lifecycle.Change) {
+	if s.agents == nil {
+		return
+	}
+
+	var err error
+	switch payment.Status {
+	case models.PaymentStatusCompleted:
+		if from != models.PaymentStatusDisputed {
+			_, err = s.agents.RecordCommission(payment)
+		}
+	case models.PaymentStatusRefunded, models.PaymentStatusChargedBack:
+		_, err = s.agents.ReverseCommission(payment)
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("paymentId", payment.ID).Error("Failed to update commission")
+	}
+}
+
+// updateAllocations keeps the policy's invoices in step with premiums:
+// completed premiums, including those completed again by a won dispute, are
+// allocated to the policy's unpaid invoices, and refunded or disputed ones
+// have their allocations reversed. A charged back premium was reversed when
+// it was disputed. Like updateCommission, a failure is logged rather than
+// returned.
+func (s *PaymentService) updateAllocations(payment *models.Payment, from models.PaymentStatus, change lifecycle.Change) {
+	if s.billing == nil || payment.Type != models.PaymentTypePremium || payment.PolicyID == "" {
+		return
+	}
+
+	var err error
+	switch payment.Status {
+	case models.PaymentStatusCompleted:
+		err = s.billing.Allocate(payment.PolicyID)
+	case models.PaymentStatusRefunded, models.PaymentStatusDisputed:
+		err = s.billing.ReverseAllocations(payment)
+	}
+	if err != nil {
+		s.logger.WithError(err).WithField("paymentId", payment.ID).Error("Failed to update premium allocations")
+	}
+}
+
+// policyAgent returns the agent who sold the policy, or "" for direct
+// business or when policy-service cannot be asked
+func (s *PaymentService) policyAgent(ctx context.Context, policyID, customerID string) string {
+	if s.lookups.Policies == nil {
+		return ""
+	}
+	policy, err := s.lookups.Policies.GetPolicy(ctx, policyID, customerID)
+	if err != nil {
+		s.logger.WithError(err).WithField("policyId", policyID).Warn("Policy lookup for agent failed, no commission will be earned")
+		return ""
+	}
+	return policy.AgentID
+}
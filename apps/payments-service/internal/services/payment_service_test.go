@@ -0,0 +1,440 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/screening"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestService(t *testing.T, instantPayouts bool, payments ...*models.Payment) (*PaymentService, *repositorytest.FakeStore) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_INSTANT_PAYOUTS", "false")
+	t.Setenv("FEATURE_INSTANT_PAYOUTS_ROLLOUT", "")
+	t.Setenv("FEATURE_INSTANT_PAYOUTS_REQUIRE_KYC", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+	flags.SetInstantPayouts(instantPayouts)
+
+	store := repositorytest.NewFakeStore(payments...)
+	return NewPaymentService(store, flags, Lookups{}, nil, nil, nil, logger), store
+}
+
+func TestCreatePayoutRespectsInstantPayouts(t *testing.T) {
+	tests := []struct {
+		name       string
+		instant    bool
+		wantStatus models.PaymentStatus
+	}{
+		{"queued for batch", false, models.PaymentStatusPending},
+		{"instant", true, models.PaymentStatusCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, store := newTestService(t, tt.instant)
+
+			payout, err := service.CreatePayout(context.Background(), "claim-001", "cust-001", 4500)
+			if err != nil {
+				t.Fatalf("CreatePayout failed: %v", err)
+			}
+			if payout.Type != models.PaymentTypePayout || payout.Status != tt.wantStatus {
+				t.Errorf("Unexpected payout: %+v", payout)
+			}
+
+			stored, err := store.GetPaymentByID(payout.ID)
+			if err != nil || stored.Status != tt.wantStatus {
+				t.Errorf("Stored payout mismatch: %+v, %v", stored, err)
+			}
+		})
+	}
+}
+
+func TestCreatePayoutFollowsRollout(t *testing.T) {
+	service, _ := newTestService(t, false)
+	service.lookups = Lookups{
+		Policies: &stubLookups{policies: map[string]*clients.Policy{
+			"pol-001": {ID: "pol-001", CustomerID: "cust-001", Type: "auto"},
+			"pol-002": {ID: "pol-002", CustomerID: "cust-002", Type: "home"},
+		}},
+		Claims: &stubLookups{claims: map[string]*clients.Claim{
+			"claim-001": {ID: "claim-001", PolicyID: "pol-001", CustomerID: "cust-001", Offers: acceptedOffer(1000)},
+			"claim-002": {ID: "claim-002", PolicyID: "pol-002", CustomerID: "cust-002", Offers: acceptedOffer(1000)},
+		}},
+	}
+	service.flags.SetInstantPayoutsRollout(&targeting.Rollout{
+		Percentage: 100,
+		Rules:      []targeting.Rule{{Attribute: targeting.AttributePolicyType, Operator: targeting.OperatorIn, Values: []string{"auto"}}},
+		Tenants:    map[string]bool{"partner-a": true},
+	})
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		claimID    string
+		customerID string
+		wantStatus models.PaymentStatus
+	}{
+		{"targeted policy type", context.Background(), "claim-001", "cust-001", models.PaymentStatusCompleted},
+		{"other policy type", context.Background(), "claim-002", "cust-002", models.PaymentStatusPending},
+		{"tenant override", targeting.WithTenant(context.Background(), "partner-a"), "claim-002", "cust-002", models.PaymentStatusCompleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payout, err := service.CreatePayout(tt.ctx, tt.claimID, tt.customerID, 100)
+			if err != nil {
+				t.Fatalf("CreatePayout failed: %v", err)
+			}
+			if payout.Status != tt.wantStatus {
+				t.Errorf("Status mismatch: got %s, want %s", payout.Status, tt.wantStatus)
+			}
+		})
+	}
+
+	// Setting the flag outright ends the rollout, e.g. to roll back
+	service.flags.SetInstantPayouts(false)
+	payout, err := service.CreatePayout(context.Background(), "claim-001", "cust-001", 100)
+	if err != nil || payout.Status != models.PaymentStatusPending {
+		t.Errorf("Rollback did not stop instant payouts: %+v, %v", payout, err)
+	}
+}
+
+// acceptedOffer is a claim's settlement offers with one accepted for amount
+func acceptedOffer(amount float64) []clients.Offer {
+	return []clients.Offer{
+		{ID: "offer-001", Amount: amount / 2, Status: "rejected"},
+		{ID: "offer-002", Amount: amount, Status: "accepted"},
+	}
+}
+
+func TestCreatePayoutRequiresAcceptedOffer(t *testing.T) {
+	service, _ := newTestService(t, false,
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePayout, ClaimID: "claim-paid", Amount: 600, Status: models.PaymentStatusCompleted},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePayout, ClaimID: "claim-paid", Amount: 400, Status: models.PaymentStatusFailed},
+	)
+	service.lookups.Claims = &stubLookups{claims: map[string]*clients.Claim{
+		"claim-accepted": {ID: "claim-accepted", CustomerID: "cust-001", Offers: acceptedOffer(1000)},
+		"claim-pending":  {ID: "claim-pending", CustomerID: "cust-001", Offers: []clients.Offer{{ID: "offer-003", Amount: 1000, Status: "pending"}}},
+		"claim-none":     {ID: "claim-none", CustomerID: "cust-001"},
+		"claim-paid":     {ID: "claim-paid", CustomerID: "cust-001", Offers: acceptedOffer(1000)},
+	}}
+
+	tests := []struct {
+		name    string
+		claimID string
+		amount  float64
+		wantErr string
+	}{
+		{"above accepted offer", "claim-accepted", 1000.01, "payout exceeds the accepted offer of 1000.00 (0.00 already paid out)"},
+		{"accepted offer", "claim-accepted", 1000, ""},
+		{"offer still pending", "claim-pending", 500, "claim has no accepted settlement offer"},
+		{"no offers", "claim-none", 500, "claim has no accepted settlement offer"},
+		{"beyond a part-paid offer", "claim-paid", 400.5, "payout exceeds the accepted offer of 1000.00 (600.00 already paid out)"},
+		{"rest of a part-paid offer", "claim-paid", 400, ""},
+		{"unknown claim", "claim-404", 100, "claim not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payout, err := service.CreatePayout(context.Background(), tt.claimID, "cust-001", tt.amount)
+			if tt.wantErr == "" {
+				if err != nil || payout.Amount != tt.amount {
+					t.Errorf("Expected a payout of %.2f, got %+v, %v", tt.amount, payout, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Refused payouts are not stored, and a fully paid offer takes no more
+	if payouts, _ := service.GetPaymentsByClaimID("claim-accepted"); len(payouts) != 1 {
+		t.Errorf("Expected only the accepted payout to be stored, got %d", len(payouts))
+	}
+	if _, err := service.CreatePayout(context.Background(), "claim-paid", "cust-001", 0.01); err == nil {
+		t.Error("Expected a fully paid offer to refuse further payouts")
+	}
+
+	service.lookups.Claims = &stubLookups{err: errors.New("claims-service returned status 502")}
+	if _, err := service.CreatePayout(context.Background(), "claim-accepted", "cust-001", 1); err == nil || err.Error() != "claim lookup failed: claims-service returned status 502" {
+		t.Errorf("Expected a lookup failure, got %v", err)
+	}
+}
+
+func TestCreatePayoutRequiresVerifiedKYC(t *testing.T) {
+	service, _ := newTestService(t, true)
+	service.flags.SetInstantPayoutsRequireKYC(true)
+
+	// Without customer-service KYC cannot be checked, so payouts queue
+	payout, err := service.CreatePayout(context.Background(), "claim-001", "cust-001", 100)
+	if err != nil || payout.Status != models.PaymentStatusPending {
+		t.Errorf("Unchecked KYC: got %+v, %v", payout, err)
+	}
+
+	service.lookups.Customers = &stubLookups{
+		customers: map[string]bool{"cust-001": true, "cust-002": true},
+		kyc:       map[string]string{"cust-001": "verified", "cust-002": "pending"},
+	}
+	tests := []struct {
+		customerID string
+		wantStatus models.PaymentStatus
+	}{
+		{"cust-001", models.PaymentStatusCompleted},
+		{"cust-002", models.PaymentStatusPending},
+		{"cust-404", models.PaymentStatusPending},
+	}
+	for _, tt := range tests {
+		payout, err := service.CreatePayout(context.Background(), "claim-001", tt.customerID, 100)
+		if err != nil {
+			t.Fatalf("CreatePayout failed: %v", err)
+		}
+		if payout.Status != tt.wantStatus {
+			t.Errorf("%s: got %s, want %s", tt.customerID, payout.Status, tt.wantStatus)
+		}
+	}
+}
+
+func TestProcessPayment(t *testing.T) {
+	service, _ := newTestService(t, false,
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePremium, Status: models.PaymentStatusPending},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePremium, Status: models.PaymentStatusCompleted},
+	)
+
+	processed, err := service.ProcessPayment(context.Background(), "pay-001")
+	if err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+	if processed.Status != models.PaymentStatusCompleted || processed.ProcessedDate == nil {
+		t.Errorf("Payment not completed: %+v", processed)
+	}
+
+	var transitionErr *lifecycle.TransitionError
+	if _, err := service.ProcessPayment(context.Background(), "pay-002"); !errors.As(err, &transitionErr) || transitionErr.From != models.PaymentStatusCompleted || transitionErr.To != models.PaymentStatusProcessing {
+		t.Errorf("Expected a transition error for an already processed payment, got %v", err)
+	}
+	if _, err := service.ProcessPayment(context.Background(), "pay-999"); err == nil || err.Error() != "payment not found" {
+		t.Errorf("Expected payment not found, got %v", err)
+	}
+}
+
+func TestFailAndRefundPayment(t *testing.T) {
+	service, store := newTestService(t, false,
+		&models.Payment{ID: "pay-stuck", Type: models.PaymentTypePayout, Status: models.PaymentStatusProcessing},
+		&models.Payment{ID: "pay-done", Type: models.PaymentTypePremium, Status: models.PaymentStatusCompleted},
+		&models.Payment{ID: "pay-refund", Type: models.PaymentTypeRefund, Status: models.PaymentStatusCompleted},
+	)
+
+	var transitionErr *lifecycle.TransitionError
+	if _, err := service.FailPayment("pay-stuck", ""); !errors.As(err, &transitionErr) || transitionErr.Reason != "a reason is required" {
+		t.Errorf("Failing without a reason: got %v", err)
+	}
+	failed, err := service.FailPayment("pay-stuck", "Bank declined transfer")
+	if err != nil || failed.Status != models.PaymentStatusFailed || failed.FailureReason != "Bank declined transfer" || failed.ProcessedDate == nil {
+		t.Fatalf("FailPayment: got %+v, %v", failed, err)
+	}
+	if _, err := service.RefundPayment("pay-stuck", ""); !errors.As(err, &transitionErr) || transitionErr.From != models.PaymentStatusFailed {
+		t.Errorf("Refunding a failed payment: got %v", err)
+	}
+
+	refunded, err := service.RefundPayment("pay-done", "Policy cancelled in cooling-off period")
+	if err != nil || refunded.Status != models.PaymentStatusRefunded || refunded.RefundedDate == nil {
+		t.Fatalf("RefundPayment: got %+v, %v", refunded, err)
+	}
+	if _, err := service.RefundPayment("pay-done", ""); !errors.As(err, &transitionErr) {
+		t.Errorf("Second refund: got %v", err)
+	}
+	if _, err := service.RefundPayment("pay-refund", ""); !errors.As(err, &transitionErr) || transitionErr.Reason != "refund payments cannot be refunded" {
+		t.Errorf("Refunding a refund: got %v", err)
+	}
+	if stored, _ := store.GetPaymentByID("pay-refund"); stored.Status != models.PaymentStatusCompleted {
+		t.Errorf("Refused change was saved: %+v", stored)
+	}
+}
+
+// stubScreener reports a hit for the listed names and fails for parties
+// without a name. It records the parties it screened.
+type stubScreener struct {
+	listed   map[string]bool
+	screened []screening.Party
+}
+
+func (s *stubScreener) Screen(ctx context.Context, party screening.Party) (screening.Result, error) {
+	s.screened = append(s.screened, party)
+	result := screening.Result{List: "test-list"}
+	if party.Name == "" {
+		return result, errors.New("no name to screen")
+	}
+	if s.listed[party.Name] {
+		result.Hits = []models.ScreeningHit{{EntryID: "9001", Name: party.Name}}
+	}
+	return result, nil
+}
+
+// namedCustomers answers customer lookups with the given names
+type namedCustomers map[string]string
+
+func (c namedCustomers) GetCustomer(ctx context.Context, customerID string) (*clients.Customer, error) {
+	name, ok := c[customerID]
+	if !ok {
+		return nil, errors.New("customer not found")
+	}
+	return &clients.Customer{ID: customerID, FirstName: name, LastName: "Volkov"}, nil
+}
+
+func TestProcessPaymentScreensPayouts(t *testing.T) {
+	service, store := newTestService(t, false,
+		&models.Payment{ID: "pay-clear", Type: models.PaymentTypePayout, CustomerID: "cust-001", Status: models.PaymentStatusPending},
+		&models.Payment{ID: "pay-listed", Type: models.PaymentTypePayout, CustomerID: "cust-002", Status: models.PaymentStatusPending},
+		&models.Payment{ID: "pay-unknown", Type: models.PaymentTypePayout, CustomerID: "cust-999", Status: models.PaymentStatusPending},
+		&models.Payment{ID: "pay-premium", Type: models.PaymentTypePremium, CustomerID: "cust-002", Status: models.PaymentStatusPending},
+	)
+	screener := &stubScreener{listed: map[string]bool{"Anatoly Volkov": true}}
+	service.screener = screener
+	service.lookups.Customers = namedCustomers{"cust-001": "Irina", "cust-002": "Anatoly"}
+
+	cleared, err := service.ProcessPayment(context.Background(), "pay-clear")
+	if err != nil || cleared.Status != models.PaymentStatusCompleted {
+		t.Fatalf("Cleared payout: got %+v, %v", cleared, err)
+	}
+	if cleared.Screening == nil || cleared.Screening.Status != models.ScreeningStatusClear || cleared.Screening.List != "test-list" || cleared.Screening.Name != "Irina Volkov" {
+		t.Errorf("Clear screening not recorded: %+v", cleared.Screening)
+	}
+
+	blocked, err := service.ProcessPayment(context.Background(), "pay-listed")
+	if err != nil || blocked.Status != models.PaymentStatusBlocked || blocked.ProcessedDate != nil {
+		t.Fatalf("Listed payout: got %+v, %v", blocked, err)
+	}
+	if blocked.Screening.Status != models.ScreeningStatusHit || len(blocked.Screening.Hits) != 1 {
+		t.Errorf("Hit not recorded: %+v", blocked.Screening)
+	}
+	if stored, _ := store.GetPaymentByID("pay-listed"); stored.Status != models.PaymentStatusBlocked || stored.Screening == nil {
+		t.Errorf("Blocked payout not saved: %+v", stored)
+	}
+
+	// A recipient who cannot be screened is blocked, not paid unscreened
+	unscreened, err := service.ProcessPayment(context.Background(), "pay-unknown")
+	if err != nil || unscreened.Status != models.PaymentStatusBlocked || unscreened.Screening.Status != models.ScreeningStatusError || unscreened.Screening.Error != "no name to screen" {
+		t.Errorf("Unscreened payout: got %+v, %v", unscreened, err)
+	}
+
+	if premium, err := service.ProcessPayment(context.Background(), "pay-premium"); err != nil || premium.Status != models.PaymentStatusCompleted || premium.Screening != nil {
+		t.Errorf("Premiums should not be screened: got %+v, %v", premium, err)
+	}
+	if len(screener.screened) != 3 {
+		t.Errorf("Expected 3 payouts screened, got %d", len(screener.screened))
+	}
+}
+
+func TestReleaseBlockedPayout(t *testing.T) {
+	service, _ := newTestService(t, false,
+		&models.Payment{ID: "pay-listed", Type: models.PaymentTypePayout, CustomerID: "cust-002", Status: models.PaymentStatusPending},
+		&models.Payment{ID: "pay-confirmed", Type: models.PaymentTypePayout, CustomerID: "cust-002", Status: models.PaymentStatusPending},
+		&models.Payment{ID: "pay-pending", Type: models.PaymentTypePayout, CustomerID: "cust-002", Status: models.PaymentStatusPending},
+	)
+	screener := &stubScreener{listed: map[string]bool{"Anatoly Volkov": true}}
+	service.screener = screener
+	service.lookups.Customers = namedCustomers{"cust-002": "Anatoly"}
+	for _, id := range []string{"pay-listed", "pay-confirmed"} {
+		if payment, err := service.ProcessPayment(context.Background(), id); err != nil || payment.Status != models.PaymentStatusBlocked {
+			t.Fatalf("Expected %s blocked, got %+v, %v", id, payment, err)
+		}
+	}
+
+	var transitionErr *lifecycle.TransitionError
+	if _, err := service.ReleasePayment("pay-listed", "compliance-1", ""); !errors.As(err, &transitionErr) || transitionErr.Reason != "a reason is required" {
+		t.Errorf("Releasing without a reason: got %v", err)
+	}
+	if _, err := service.ReleasePayment("pay-pending", "compliance-1", "False positive"); !errors.As(err, &transitionErr) || transitionErr.From != models.PaymentStatusPending {
+		t.Errorf("Releasing a payout that is not blocked: got %v", err)
+	}
+
+	released, err := service.ReleasePayment("pay-listed", "compliance-1", "Date of birth differs from the SDN entry")
+	if err != nil || released.Status != models.PaymentStatusPending {
+		t.Fatalf("ReleasePayment: got %+v, %v", released, err)
+	}
+	if !released.Screening.Released() || released.Screening.ReleasedBy != "compliance-1" || released.Screening.ReleaseReason != "Date of birth differs from the SDN entry" {
+		t.Errorf("Release not recorded: %+v", released.Screening)
+	}
+
+	// The released payout is paid without being screened again
+	paid, err := service.ProcessPayment(context.Background(), "pay-listed")
+	if err != nil || paid.Status != models.PaymentStatusCompleted || paid.Screening.Status != models.ScreeningStatusHit {
+		t.Errorf("Released payout: got %+v, %v", paid, err)
+	}
+	if len(screener.screened) != 2 {
+		t.Errorf("Released payout was screened again: %d screenings", len(screener.screened))
+	}
+
+	// A confirmed match is failed instead
+	failed, err := service.FailPayment("pay-confirmed", "Recipient confirmed as SDN entry 9001")
+	if err != nil || failed.Status != models.PaymentStatusFailed {
+		t.Errorf("Failing a blocked payout: got %+v, %v", failed, err)
+	}
+}
+
+func TestGetPaymentsByClaimID(t *testing.T) {
+	now := time.Now()
+	service, _ := newTestService(t, false,
+		&models.Payment{ID: "pay-003", Type: models.PaymentTypePayout, ClaimID: "claim-001", CreatedAt: now},
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePremium, PolicyID: "pol-001", CreatedAt: now.Add(-time.Hour)},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePayout, ClaimID: "claim-001", CreatedAt: now.Add(-time.Minute)},
+	)
+
+	payments, err := service.GetPaymentsByClaimID("claim-001")
+	if err != nil {
+		t.Fatalf("GetPaymentsByClaimID failed: %v", err)
+	}
+	if len(payments) != 2 || payments[0].ID != "pay-002" || payments[1].ID != "pay-003" {
+		t.Errorf("Expected pay-002 then pay-003, got %+v", payments)
+	}
+
+	if payments, err := service.GetPaymentsByClaimID("claim-999"); err != nil || len(payments) != 0 {
+		t.Errorf("Expected no payouts for an unknown claim, got %+v, %v", payments, err)
+	}
+}
+
+func TestPayoutFailureRate(t *testing.T) {
+	now := time.Now()
+	processed := func(ago time.Duration) *time.Time {
+		at := now.Add(-ago)
+		return &at
+	}
+	service, _ := newTestService(t, false,
+		&models.Payment{ID: "pay-001", Type: models.PaymentTypePayout, Status: models.PaymentStatusFailed, ProcessedDate: processed(time.Minute)},
+		&models.Payment{ID: "pay-002", Type: models.PaymentTypePayout, Status: models.PaymentStatusCompleted, ProcessedDate: processed(10 * time.Minute)},
+		&models.Payment{ID: "pay-003", Type: models.PaymentTypePayout, Status: models.PaymentStatusCompleted, ProcessedDate: processed(20 * time.Minute)},
+		&models.Payment{ID: "pay-004", Type: models.PaymentTypePayout, Status: models.PaymentStatusCompleted, ProcessedDate: processed(30 * time.Minute)},
+		// Outside the window, not a payout, or not processed yet
+		&models.Payment{ID: "pay-005", Type: models.PaymentTypePayout, Status: models.PaymentStatusFailed, ProcessedDate: processed(2 * time.Hour)},
+		&models.Payment{ID: "pay-006", Type: models.PaymentTypePremium, Status: models.PaymentStatusFailed, ProcessedDate: processed(time.Minute)},
+		&models.Payment{ID: "pay-007", Type: models.PaymentTypePayout, Status: models.PaymentStatusPending},
+	)
+
+	rate, err := service.PayoutFailureRate(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PayoutFailureRate failed: %v", err)
+	}
+	if rate != 0.25 {
+		t.Errorf("Expected 1 of 4 payouts failed, got %v", rate)
+	}
+
+	if rate, err := service.PayoutFailureRate(now); err != nil || rate != 0 {
+		t.Errorf("Expected 0 without processed payouts, got %v, %v", rate, err)
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/notify"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultReminderOffsets are the days from an invoice's due date that
+// reminders are sent: a week and a day before it, and three days after
+var DefaultReminderOffsets = []int{-7, -1, 3}
+
+// ReminderPreferences reports whether a customer opted out of premium
+// payment reminders. *clients.CustomerClient is the production
+// implementation.
+type ReminderPreferences interface {
+	RemindersOptedOut(ctx context.Context, customerID string) (bool, error)
+}
+
+var _ ReminderPreferences = (*clients.CustomerClient)(nil)
+
+// ReminderService reminds customers of unpaid premium invoices. Each unpaid
+// invoice of a policy in force gets a reminder at each offset from its due
+// date, sent as a payment.reminder.upcoming or payment.reminder.overdue
+// event. An offset missed, e.g. while the service was down, is skipped once
+// a later one is due, so a customer is not sent a burst of reminders.
+type ReminderService struct {
+	repo        repository.ReminderStore
+	billing     *BillingService
+	preferences ReminderPreferences
+	notifier    notify.Notifier
+	offsets     []int
+	mu          sync.Mutex // one pass at a time, so a reminder is not sent twice
+	logger      *logrus.Logger
+}
+
+// NewReminderService creates a new reminder service. preferences may be
+// nil, in which case opt-outs cannot be checked and no reminders are sent.
+// Empty offsets use DefaultReminderOffsets.
+func NewReminderService(repo repository.ReminderStore, billing *BillingService, preferences ReminderPreferences, notifier notify.Notifier, offsets []int, logger *logrus.Logger) *ReminderService {
+	if len(offsets) == 0 {
+		offsets = DefaultReminderOffsets
+	}
+	sorted := append([]int(nil), offsets...)
+	sort.Ints(sorted)
+	return &ReminderService{
+		repo:        repo,
+		billing:     billing,
+		preferences: preferences,
+		notifier:    notifier,
+		offsets:     sorted,
+		logger:      logger,
+	}
+}
+
+// Remind sends the reminders due at now. The invoices of every policy with
+// a premium payment or invoice on record are brought up to date first, as
+// for a balance. Reminders that failed on an earlier pass are tried again.
+func (s *ReminderService) Remind(ctx context.Context, now time.Time) (*models.ReminderRun, error) {
+	if s.preferences == nil {
+		return nil, fmt.Errorf("customer service not configured")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policies, err := billedPolicies(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.ReminderRun{Reminders: []*models.Reminder{}, RunAt: now}
+	for _, policy := range policies {
+		balance, err := s.billing.GetPolicyBalance(ctx, policy.id, policy.customerID)
+		if err != nil {
+			if err.Error() == "policy service not configured" {
+				return nil, err
+			}
+			s.logger.WithError(err).WithField("policyId", policy.id).Warn("Cannot check policy for payment reminders")
+			continue
+		}
+		run.Policies++
+		if !inForce(balance.PolicyStatus) {
+			continue
+		}
+
+		for _, invoice := range balance.Invoices {
+			if invoice.Outstanding <= 0 {
+				continue
+			}
+			offset, due := s.dueOffset(invoice.DueDate, now)
+			if !due {
+				continue
+			}
+			reminder, err := s.send(ctx, balance, invoice, offset, now)
+			if err != nil {
+				return nil, err
+			}
+			if reminder == nil {
+				continue
+			}
+
+			run.Reminders = append(run.Reminders, reminder)
+			switch reminder.Status {
+			case models.ReminderStatusSent:
+				run.Sent++
+			case models.ReminderStatusOptedOut:
+				run.OptedOut++
+			case models.ReminderStatusFailed:
+				run.Failed++
+			}
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"policies": run.Policies,
+		"sent":     run.Sent,
+		"optedOut": run.OptedOut,
+		"failed":   run.Failed,
+	}).Info("Payment reminders run")
+
+	return run, nil
+}
+
+// Run sends due reminders every interval until ctx is cancelled
+func (s *ReminderService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Remind(ctx, time.Now()); err != nil {
+				s.logger.WithError(err).Error("Failed to send payment reminders")
+			}
+		}
+	}
+}
+
+// GetReminders returns the reminders on record, oldest first, narrowed to
+// a policy and status when they are given
+func (s *ReminderService) GetReminders(policyID string, status models.ReminderStatus) []*models.Reminder {
+	reminders := []*models.Reminder{}
+	for _, reminder := range s.repo.GetAllReminders() {
+		if (policyID == "" || reminder.PolicyID == policyID) && (status == "" || reminder.Status == status) {
+			reminders = append(reminders, reminder)
+		}
+	}
+	return reminders
+}
+
+// send sends the reminder of an invoice at offset, unless it was already
+// sent or the customer opted out. It returns nil when there was nothing to
+// do; an error only when the reminder could not be saved.
+func (s *ReminderService) send(ctx context.Context, balance *models.PolicyBalance, invoice *models.InvoiceBalance, offset int, now time.Time) (*models.Reminder, error) {
+	id := fmt.Sprintf("rem-%s-d%+d", invoice.ID, offset)
+	reminder, err := s.repo.GetReminderByID(id)
+	exists := err == nil
+	if exists && reminder.Status != models.ReminderStatusFailed {
+		return nil, nil
+	}
+	if !exists {
+		kind := models.ReminderKindUpcoming
+		if offset > 0 {
+			kind = models.ReminderKindOverdue
+		}
+		reminder = &models.Reminder{
+			ID:           id,
+			InvoiceID:    invoice.ID,
+			PolicyID:     balance.PolicyID,
+			PolicyNumber: balance.PolicyNumber,
+			CustomerID:   invoice.CustomerID,
+			Kind:         kind,
+			OffsetDays:   offset,
+			DueDate:      invoice.DueDate,
+			CreatedAt:    now,
+		}
+	}
+	reminder.Amount = roundCents(invoice.Outstanding + invoice.LateFeesOutstanding)
+	reminder.Attempts++
+	reminder.Error = ""
+
+	fields := logrus.Fields{
+		"reminderId": reminder.ID,
+		"policyId":   reminder.PolicyID,
+		"customerId": reminder.CustomerID,
+		"offsetDays": offset,
+	}
+	optedOut, err := s.preferences.RemindersOptedOut(ctx, reminder.CustomerID)
+	switch {
+	case err != nil:
+		// Without the customer's preferences the opt-out cannot be
+		// honoured, so nothing is sent until they can be read
+		reminder.Status = models.ReminderStatusFailed
+		reminder.Error = fmt.Sprintf("preferences lookup failed: %v", err)
+		s.logger.WithError(err).WithFields(fields).Warn("Cannot check payment reminder opt-out")
+	case optedOut:
+		reminder.Status = models.ReminderStatusOptedOut
+		s.logger.WithFields(fields).Info("Payment reminder skipped, customer opted out")
+	default:
+		evt := notify.Event{
+			ID:         reminder.ID,
+			Type:       "payment.reminder." + string(reminder.Kind),
+			OccurredAt: now,
+			Data:       reminder,
+		}
+		if err := s.notifier.Notify(ctx, evt); err != nil {
+			reminder.Status = models.ReminderStatusFailed
+			reminder.Error = err.Error()
+			s.logger.WithError(err).WithFields(fields).Warn("Failed to send payment reminder")
+			break
+		}
+		sentAt := now
+		reminder.Status = models.ReminderStatusSent
+		reminder.SentAt = &sentAt
+		s.logger.WithFields(fields).Info("Payment reminder sent")
+	}
+
+	if exists {
+		err = s.repo.UpdateReminder(reminder)
+	} else {
+		err = s.repo.CreateReminder(reminder)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// dueOffset returns the latest offset from dueDate that has been reached
+// by now
+func (s *ReminderService) dueOffset(dueDate, now time.Time) (int, bool) {
+	for i := len(s.offsets) - 1; i >= 0; i-- {
+		if !now.Before(dueDate.AddDate(0, 0, s.offsets[i])) {
+			return s.offsets[i], true
+		}
+	}
+	return 0, false
+}
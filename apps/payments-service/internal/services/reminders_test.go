@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/notify"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubNotifier records the events it was given
+type stubNotifier struct {
+	events []notify.Event
+	err    error
+}
+
+func (n *stubNotifier) Notify(ctx context.Context, evt notify.Event) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.events = append(n.events, evt)
+	return nil
+}
+
+// stubPreferences opts out the customers in optedOut
+type stubPreferences struct {
+	optedOut map[string]bool
+	err      error
+}
+
+func (p *stubPreferences) RemindersOptedOut(ctx context.Context, customerID string) (bool, error) {
+	return p.optedOut[customerID], p.err
+}
+
+type reminderTestServices struct {
+	payments    *PaymentService
+	reminders   *ReminderService
+	notifier    *stubNotifier
+	preferences *stubPreferences
+	now         time.Time
+}
+
+// newReminderTestServices bills quarterly policies starting on January 1,
+// so the second quarter's invoice is due on May 1
+func newReminderTestServices(t *testing.T, policies ...*clients.Policy) *reminderTestServices {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_INSTANT_PAYOUTS", "false")
+	t.Setenv("FEATURE_INSTANT_PAYOUTS_ROLLOUT", "")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+
+	lookups := &stubLookups{policies: make(map[string]*clients.Policy)}
+	for _, policy := range policies {
+		policy.Premium = 1200
+		policy.StartDate = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		policy.EndDate = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+		lookups.policies[policy.ID] = policy
+	}
+
+	s := &reminderTestServices{
+		notifier:    &stubNotifier{},
+		preferences: &stubPreferences{optedOut: make(map[string]bool)},
+	}
+	store := repositorytest.NewFakeStore()
+	billing := NewBillingService(store, lookups, 3, 30, LateFeeRule{}, logger)
+	billing.now = func() time.Time { return s.now }
+	s.payments = NewPaymentService(store, flags, Lookups{Policies: lookups}, nil, billing, nil, logger)
+	s.reminders = NewReminderService(store, billing, s.preferences, s.notifier, nil, logger)
+	return s
+}
+
+// pay completes a premium payment on a policy
+func (s *reminderTestServices) pay(t *testing.T, policyID, customerID string, amount float64) {
+	t.Helper()
+	payment, err := s.payments.CreatePayment(context.Background(), policyID, customerID, amount)
+	if err != nil {
+		t.Fatalf("CreatePayment failed: %v", err)
+	}
+	if _, err := s.payments.ProcessPayment(context.Background(), payment.ID); err != nil {
+		t.Fatalf("ProcessPayment failed: %v", err)
+	}
+}
+
+// remind runs the reminder job on the given day in 2026
+func (s *reminderTestServices) remind(t *testing.T, month time.Month, day int) *models.ReminderRun {
+	t.Helper()
+	s.now = time.Date(2026, month, day, 9, 0, 0, 0, time.UTC)
+	run, err := s.reminders.Remind(context.Background(), s.now)
+	if err != nil {
+		t.Fatalf("Remind failed: %v", err)
+	}
+	return run
+}
+
+func TestRemindersFollowDueDateOffsets(t *testing.T) {
+	s := newReminderTestServices(t, &clients.Policy{ID: "pol-001", CustomerID: "cust-001", PolicyNumber: "AUTO-001", Status: "active"})
+
+	// The first quarter is paid, so only the second is reminded of
+	s.now = time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)
+	s.pay(t, "pol-001", "cust-001", 295.89)
+
+	if run := s.remind(t, time.April, 20); run.Policies != 1 || len(run.Reminders) != 0 {
+		t.Fatalf("Expected nothing due eleven days ahead, got %+v", run)
+	}
+
+	run := s.remind(t, time.April, 24)
+	if run.Sent != 1 || len(s.notifier.events) != 1 {
+		t.Fatalf("Expected the week-ahead reminder, got %+v", run)
+	}
+	reminder := run.Reminders[0]
+	if reminder.ID != "rem-inv-pol-001-2-d-7" || reminder.Kind != models.ReminderKindUpcoming || reminder.Amount != 299.18 || reminder.PolicyNumber != "AUTO-001" || reminder.SentAt == nil {
+		t.Errorf("Unexpected reminder: %+v", reminder)
+	}
+	if evt := s.notifier.events[0]; evt.ID != reminder.ID || evt.Type != "payment.reminder.upcoming" {
+		t.Errorf("Unexpected event: %+v", evt)
+	}
+	if run := s.remind(t, time.April, 25); len(run.Reminders) != 0 {
+		t.Errorf("A reminder was sent twice: %+v", run.Reminders)
+	}
+
+	// The day-before reminder was missed, so only the overdue one goes
+	// out, and it is tried again after the notification service fails
+	s.notifier.err = errors.New("webhook endpoint returned 503")
+	run = s.remind(t, time.May, 4)
+	if run.Failed != 1 || run.Reminders[0].Kind != models.ReminderKindOverdue || run.Reminders[0].Error != "webhook endpoint returned 503" {
+		t.Fatalf("Expected the overdue reminder to fail, got %+v", run)
+	}
+	s.notifier.err = nil
+	run = s.remind(t, time.May, 5)
+	if run.Sent != 1 || run.Reminders[0].ID != "rem-inv-pol-001-2-d+3" || run.Reminders[0].Attempts != 2 {
+		t.Fatalf("Expected the overdue reminder sent on retry, got %+v", run)
+	}
+	if len(s.notifier.events) != 2 || s.notifier.events[1].Type != "payment.reminder.overdue" {
+		t.Errorf("Unexpected events: %+v", s.notifier.events)
+	}
+
+	if reminders := s.reminders.GetReminders("pol-001", models.ReminderStatusSent); len(reminders) != 2 {
+		t.Errorf("Expected two sent reminders on record, got %+v", reminders)
+	}
+}
+
+func TestRemindersRespectOptOut(t *testing.T) {
+	s := newReminderTestServices(t,
+		&clients.Policy{ID: "pol-001", CustomerID: "cust-001", Status: "active"},
+		&clients.Policy{ID: "pol-002", CustomerID: "cust-002", Status: "grace"},
+		&clients.Policy{ID: "pol-003", CustomerID: "cust-003", Status: "lapsed"},
+	)
+	s.now = time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)
+	for _, policy := range []string{"pol-001", "pol-002", "pol-003"} {
+		s.pay(t, policy, "cust-00"+policy[len(policy)-1:], 295.89)
+	}
+	s.preferences.optedOut["cust-001"] = true
+
+	run := s.remind(t, time.April, 30)
+	if run.Policies != 3 || run.OptedOut != 1 || run.Sent != 1 || len(run.Reminders) != 2 {
+		t.Fatalf("Expected one opt-out and one reminder, the lapsed policy skipped, got %+v", run)
+	}
+	if len(s.notifier.events) != 1 || s.notifier.events[0].Data.(*models.Reminder).CustomerID != "cust-002" {
+		t.Errorf("Only cust-002 should have been reminded, got %+v", s.notifier.events)
+	}
+
+	// Without the customer's preferences nothing is sent
+	s.preferences.err = errors.New("customer-service request failed")
+	run = s.remind(t, time.May, 4)
+	if run.Failed != 2 || run.Sent != 0 || len(s.notifier.events) != 1 {
+		t.Errorf("Expected reminders held back while preferences cannot be read, got %+v", run)
+	}
+
+	s.reminders.preferences = nil
+	if _, err := s.reminders.Remind(context.Background(), s.now); err == nil || err.Error() != "customer service not configured" {
+		t.Errorf("Expected customer service not configured, got %v", err)
+	}
+}
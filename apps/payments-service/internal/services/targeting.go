@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+)
+
+// Lookups are the services the payment service reads customer and policy
+// details from. Any may be nil when that service is not configured.
+type Lookups struct {
+	Policies  PolicyLookup
+	Claims    ClaimLookup
+	Customers CustomerLookup
+}
+
+// payoutTarget describes a payout's customer for instantPayouts targeting.
+// Attributes are only looked up when the active rollout's rules read them. A
+// failed lookup leaves the attribute unset, which no rule matches, so the
+// customer gets the rollout's default.
+func (s *PaymentService) payoutTarget(ctx context.Context, claimID, customerID string) targeting.Context {
+	target := targeting.Context{
+		UserID:     customerID,
+		TenantID:   targeting.TenantFromContext(ctx),
+		Attributes: make(map[string]string),
+	}
+
+	customerLoaded := false
+	for _, attribute := range s.flags.InstantPayoutsRollout().Attributes() {
+		switch attribute {
+		case targeting.AttributeCountry, targeting.AttributeRiskScore:
+			if customerLoaded || s.lookups.Customers == nil {
+				continue
+			}
+			customerLoaded = true
+			customer, err := s.lookups.Customers.GetCustomer(ctx, customerID)
+			if err != nil {
+				s.logger.WithError(err).WithField("customerId", customerID).Warn("Customer lookup for flag targeting failed")
+				continue
+			}
+			target.Attributes[targeting.AttributeCountry] = customer.Address.Country
+			target.Attributes[targeting.AttributeRiskScore] = strconv.Itoa(customer.RiskScore)
+		case targeting.AttributePolicyType:
+			if s.lookups.Claims == nil || s.lookups.Policies == nil {
+				continue
+			}
+			claim, err := s.lookups.Claims.GetClaim(ctx, claimID)
+			if err != nil {
+				s.logger.WithError(err).WithField("claimId", claimID).Warn("Claim lookup for flag targeting failed")
+				continue
+			}
+			policy, err := s.lookups.Policies.GetPolicy(ctx, claim.PolicyID, customerID)
+			if err != nil {
+				s.logger.WithError(err).WithField("policyId", claim.PolicyID).Warn("Policy lookup for flag targeting failed")
+				continue
+			}
+			target.Attributes[targeting.AttributePolicyType] = policy.Type
+		}
+	}
+	return target
+}
@@ -0,0 +1,371 @@
+// Package app assembles the policy service from its repository, services,
+// handlers and routes. cmd/server serves it over HTTP; tests can mount the
+// same handler on an httptest server.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/health"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the settings needed to assemble the service
+type Config struct {
+	DataPath      string
+	FeatureAPIKey string
+
+	// CustomerServiceURL is the base URL of customer-service. When empty the
+	// consistency report skips its customer checks, policies cannot be
+	// created while verified email is required, and legacy policies cannot
+	// be imported.
+	CustomerServiceURL string
+
+	// PaymentsServiceURL is the base URL of payments-service. When empty
+	// cancellations cannot refund unearned premium.
+	PaymentsServiceURL string
+
+	// PricingServiceURL is the base URL of pricing-engine. When empty bound
+	// quotes are not reported as converted.
+	PricingServiceURL string
+
+	// GracePeriod is how long a policy past its end date stays in grace
+	// before lapsing. GraceSweepInterval is how often policies are swept;
+	// zero disables the scheduled sweep (staff can still trigger one).
+	GracePeriod        time.Duration
+	GraceSweepInterval time.Duration
+
+	// CancellationNotice is how far ahead the insurer must give notice of
+	// cancelling an active policy; zero lets it cancel straight away
+	CancellationNotice time.Duration
+
+	// BusinessRulesFile holds the expressions of the business rules
+	// engine; new policies matched by one of its policy rules are refused.
+	// When empty business-rules.json in DataPath is used, and without that
+	// file every policy is issued until rules are set through the admin
+	// API. BusinessRulesReloadInterval is how often the file is checked for
+	// changes; 0 loads it once at startup.
+	BusinessRulesFile           string
+	BusinessRulesReloadInterval time.Duration
+
+	// ShapingFile holds the field visibility rules of the responses, on
+	// top of their shape tags. When empty response-shaping.json in
+	// DataPath is used, and without that file the tags alone apply.
+	ShapingFile string
+
+	// Maintenance is the maintenance mode the service starts in
+	Maintenance maintenance.Config
+	// Faults is whether the service may inject faults for resilience
+	// testing; the rules are set at /admin/faults
+	Faults faults.Config
+
+	// PersistDir holds the write-ahead log and snapshot that keep changes
+	// across restarts. When empty changes are kept in memory only.
+	// PersistFlushInterval is how often the log is folded into the snapshot.
+	PersistDir           string
+	PersistFlushInterval time.Duration
+
+	// SlowRequestThreshold logs requests that take at least this long as
+	// warnings; 0 disables the warning
+	SlowRequestThreshold time.Duration
+
+	// AccessLog receives one structured entry per request; when nil they
+	// are written to the service log
+	AccessLog *logrus.Logger
+	// ErrorReporter receives handler panics, such as a Sentry hub; nil only
+	// logs them
+	ErrorReporter telemetry.ErrorReporter
+}
+
+// App is an assembled policy service
+type App struct {
+	Handler http.Handler
+	Flags   *features.Flags
+
+	stopSweeper lifecycle.StopFunc
+	stopRules   lifecycle.StopFunc
+	imports     *services.ImportService
+	journal     *persist.Journal
+	logger      *logrus.Logger
+}
+
+// New wires the service together and loads its data from cfg.DataPath
+func New(cfg Config, logger *logrus.Logger) (*App, error) {
+	// Initialize CloudBees Feature Management
+	flags, err := features.Initialize(cfg.FeatureAPIKey, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize feature management: %w", err)
+	}
+
+	// Load the business rules and response shaping before anything needs
+	// stopping
+	businessRules, err := loadBusinessRules(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	shaper, err := loadShaping(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+
+	// Initialize repository
+	repo, err := repository.NewRepository(cfg.DataPath, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	var journal *persist.Journal
+	if cfg.PersistDir != "" {
+		journal, err = persist.Open(cfg.PersistDir, "policy-service", cfg.PersistFlushInterval, logger)
+		if err != nil {
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to open persisted state: %w", err)
+		}
+		if err := repo.Persist(journal); err != nil {
+			journal.Close()
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to restore persisted state: %w", err)
+		}
+	}
+
+	// Initialize services
+	var refunds services.RefundIssuer
+	if cfg.PaymentsServiceURL != "" {
+		refunds = clients.NewPaymentsClient(cfg.PaymentsServiceURL, 5*time.Second)
+	}
+	var quotes services.QuoteConverter
+	if cfg.PricingServiceURL != "" {
+		quotes = clients.NewPricingClient(cfg.PricingServiceURL, 5*time.Second)
+	}
+	var customerLookup services.CustomerLookup
+	if cfg.CustomerServiceURL != "" {
+		customerLookup = clients.NewCustomerClient(cfg.CustomerServiceURL, 5*time.Second)
+	}
+	policyService := services.NewPolicyService(repo, flags, refunds, quotes, customerLookup, businessRules, cfg.CancellationNotice, logger)
+	consistencyChecker := services.NewConsistencyChecker(repo, customerLookup, logger)
+	commentService := services.NewCommentService(repo, logger)
+	importService := services.NewImportService(repo, customerLookup, logger)
+	premiumHistory := services.NewPremiumHistoryService(repo, flags, logger)
+	premiumHistory.Follow(repo)
+
+	// Keep policy statuses in line with their dates on schedule. The first
+	// sweep runs before serving so stale statuses are never returned.
+	graceSweeper := services.NewGraceSweeper(repo, cfg.GracePeriod, logger)
+	var stopSweeper lifecycle.StopFunc
+	if cfg.GraceSweepInterval > 0 {
+		graceSweeper.Sweep(time.Now())
+		stopSweeper = lifecycle.Go(func(ctx context.Context) {
+			graceSweeper.Run(ctx, cfg.GraceSweepInterval)
+		})
+	}
+
+	// Pick up changes to the business rules file
+	var stopRules lifecycle.StopFunc
+	if cfg.BusinessRulesReloadInterval > 0 {
+		stopRules = lifecycle.Go(func(ctx context.Context) {
+			businessRules.Watch(ctx, cfg.BusinessRulesReloadInterval, func() {
+				logger.WithField("version", businessRules.Version()).Info("Reloaded business rules")
+			})
+		})
+	}
+
+	// Initialize handlers
+	maintenanceMode := maintenance.New(cfg.Maintenance, logger)
+	faultInjection := faults.New(cfg.Faults, logger)
+	healthHandler := health.NewHandler("policy-service", health.Combine(flags, maintenanceMode, faultInjection))
+	policyHandler := handlers.NewPolicyHandler(policyService, logger)
+	consistencyHandler := handlers.NewConsistencyHandler(consistencyChecker, logger)
+	graceSweepHandler := handlers.NewGraceSweepHandler(graceSweeper, logger)
+	commentHandler := handlers.NewCommentHandler(commentService, logger)
+	importHandler := handlers.NewImportHandler(importService, logger)
+	premiumHistoryHandler := handlers.NewPremiumHistoryHandler(premiumHistory, logger)
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Apply global middleware
+	requestMetrics := telemetry.NewRegistry(nil)
+	panics := telemetry.NewPanicCounter()
+	router.Use(middleware.LoggingMiddleware(logger, middleware.LoggingOptions{
+		Metrics:       requestMetrics,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		AccessLog:     cfg.AccessLog,
+	}))
+	// Error messages are translated into the caller's Accept-Language,
+	// including those of recovered panics
+	router.Use(i18n.Middleware(i18n.Default()))
+	router.Use(middleware.RecoveryMiddleware(logger, middleware.RecoveryOptions{
+		Panics:   panics,
+		Reporter: cfg.ErrorReporter,
+	}))
+	// Turn callers away during maintenance before they are authenticated
+	router.Use(maintenanceMode.Middleware)
+	// Inject test faults ahead of authentication too, so every caller is
+	// faulted the same way
+	router.Use(faultInjection.Middleware)
+	router.Use(middleware.AuthMiddleware(logger))
+	// Amounts are masked while api.maskAmounts is on
+	router.Use(shaping.Middleware(shaper, func(r *http.Request) shaping.Viewer {
+		return shaping.Viewer{Role: middleware.GetRole(r), Flags: shaping.FlagsOn(flags.EnabledFlags())}
+	}))
+
+	// Setup CORS
+	corsHandler := middleware.NewCORS()
+
+	// Register routes
+	router.Handle("/healthz", healthHandler).Methods("GET")
+	router.Handle("/labels", i18n.LabelsHandler(i18n.Default())).Methods("GET")
+	router.Handle("/metrics", telemetry.Handler(requestMetrics, panics, businessRules, faultInjection)).Methods("GET")
+	router.HandleFunc("/policies", policyHandler.GetPolicies).Methods("GET")
+	router.HandleFunc("/policies/{id}", policyHandler.GetPolicyByID).Methods("GET")
+	router.HandleFunc("/policies", policyHandler.CreatePolicy).Methods("POST")
+	router.HandleFunc("/policies/{id}", policyHandler.UpdatePolicy).Methods("PUT")
+	router.HandleFunc("/policies/{id}/reinstate", policyHandler.ReinstatePolicy).Methods("POST")
+
+	// Comment threads, cancellations and premium histories, shared by
+	// customers and staff
+	identify := middleware.IdentifyRole(logger)
+	router.Handle("/policies/{id}", identify(http.HandlerFunc(policyHandler.DeletePolicy))).Methods("DELETE")
+	router.Handle("/policies/{id}/comments", identify(http.HandlerFunc(commentHandler.GetComments))).Methods("GET")
+	router.Handle("/policies/{id}/comments", identify(http.HandlerFunc(commentHandler.AddComment))).Methods("POST")
+	router.Handle("/policies/{id}/comments/{commentId}", identify(http.HandlerFunc(commentHandler.UpdateComment))).Methods("PUT")
+	router.Handle("/customers/{id}/premium-history", identify(premiumHistoryHandler)).Methods("GET")
+
+	// Back-office routes for staff, authorized by JWT role
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(logger, "admin", "adjuster"))
+	admin.HandleFunc("/policies", policyHandler.ListAllPolicies).Methods("GET")
+	admin.HandleFunc("/policies/export", policyHandler.ExportPolicies).Methods("GET")
+	admin.HandleFunc("/policies/by-number", policyHandler.SearchPolicyNumbers).Methods("GET")
+	admin.HandleFunc("/policies/by-number/{policyNumber}", policyHandler.GetPolicyByNumber).Methods("GET")
+	admin.HandleFunc("/policies/import", importHandler.StartImport).Methods("POST")
+	admin.HandleFunc("/policies/import/{id}", importHandler.GetImport).Methods("GET")
+	admin.HandleFunc("/policies/import/{id}/report", importHandler.GetImportReport).Methods("GET")
+	admin.Handle("/policies/grace-sweep", graceSweepHandler).Methods("POST")
+	admin.HandleFunc("/policies/{id}/lapse", policyHandler.LapsePolicy).Methods("POST")
+	admin.Handle("/consistency-report", consistencyHandler).Methods("GET")
+	admin.Handle("/maintenance", middleware.RequireRole(logger, "admin")(maintenanceMode.Handler())).Methods("GET", "PUT")
+	admin.Handle("/faults", middleware.RequireRole(logger, "admin")(faultInjection.Handler())).Methods("GET", "PUT")
+	admin.Handle("/rules", middleware.RequireRole(logger, "admin")(businessRules.Handler())).Methods("GET", "PUT")
+	admin.Handle("/rules/test", businessRules.TestHandler()).Methods("POST")
+
+	// Wrap router with CORS
+	return &App{
+		Handler:     corsHandler.Handler(router),
+		Flags:       flags,
+		stopSweeper: stopSweeper,
+		stopRules:   stopRules,
+		imports:     importService,
+		journal:     journal,
+		logger:      logger,
+	}, nil
+}
+
+// serverDrainTimeout is how long requests in flight get to finish on
+// shutdown
+const serverDrainTimeout = 30 * time.Second
+
+// RegisterShutdown registers the service's components with m in the order
+// they stop. server, when given, drains first, so requests in flight can
+// still write to the journal; the grace sweeper and policy imports stop
+// before the journal writes its final snapshot.
+func (a *App) RegisterShutdown(m *lifecycle.Manager, server lifecycle.StopFunc) {
+	if server != nil {
+		m.Register("http server", serverDrainTimeout, server)
+	}
+	if a.stopSweeper != nil {
+		m.Register("grace sweeper", 10*time.Second, a.stopSweeper)
+	}
+	if a.stopRules != nil {
+		m.Register("business rules reload", 5*time.Second, a.stopRules)
+	}
+	m.Register("policy imports", 10*time.Second, a.imports.Stop)
+	m.Register("persisted state", 10*time.Second, func(ctx context.Context) error {
+		return a.journal.Close()
+	})
+	m.Register("feature flags", 5*time.Second, func(ctx context.Context) error {
+		features.Shutdown()
+		return nil
+	})
+}
+
+// Close stops the service's components without an HTTP server, writing a
+// final snapshot of persisted state
+func (a *App) Close() {
+	m := lifecycle.New(a.logger)
+	a.RegisterShutdown(m, nil)
+	m.Shutdown(context.Background())
+}
+
+// loadShaping reads the response shaping policy and checks it, and the
+// shape tags, against the policy responses. A configured file must load;
+// the default file may be missing.
+func loadShaping(cfg Config, logger *logrus.Logger) (*shaping.Shaper, error) {
+	path := cfg.ShapingFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "response-shaping.json")
+	}
+	policy, err := shaping.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ShapingFile == "" {
+		logger.Warnf("No response shaping policy in %s, shape tags alone apply", path)
+		policy = shaping.Default()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load response shaping policy: %w", err)
+	} else {
+		logger.WithField("version", policy.Version).Infof("Loaded response shaping policy from %s", path)
+	}
+
+	shaper := shaping.New(policy, "policy-service")
+	if err := shaper.Check(models.PolicyPage{}, models.PremiumHistory{}); err != nil {
+		return nil, fmt.Errorf("invalid response shaping: %w", err)
+	}
+	return shaper, nil
+}
+
+// loadBusinessRules reads the policy rules of the business rules engine. A
+// configured file must load; the default file may be missing.
+func loadBusinessRules(cfg Config, logger *logrus.Logger) (*rules.Engine, error) {
+	path := cfg.BusinessRulesFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "business-rules.json")
+	}
+	engine := rules.New(rules.Config{Path: path, Domains: []string{rules.DomainPolicy}}, logger)
+	err := engine.Load()
+	if errors.Is(err, os.ErrNotExist) && cfg.BusinessRulesFile == "" {
+		logger.Warnf("No business rules in %s, every policy is issued", path)
+		return engine, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load business rules: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": engine.Version(),
+		"rules":   engine.Len(),
+	}).Infof("Loaded business rules from %s", path)
+	return engine, nil
+}
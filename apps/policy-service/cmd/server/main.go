@@ -7,36 +7,35 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/features"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/handlers"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
-	"github.com/gorilla/mux"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/logging"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	// Initialize logger from LOG_FORMAT, LOG_LEVEL, LOG_LEVELS and sampling settings
+	logger := logging.NewFromEnv()
 
-	// Configure log level from environment
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-	level, err := logrus.ParseLevel(logLevel)
+	logger.Info("Starting Policy Service...")
+
+	// Secrets come from SECRETS_PROVIDER: the environment by default, or
+	// mounted files, Vault or AWS Secrets Manager. Startup fails when one
+	// of SECRETS_REQUIRED does not resolve.
+	secretStore, err := secrets.Open(secrets.ConfigFromEnv(logger), logger)
 	if err != nil {
-		logger.Warnf("Invalid log level '%s', defaulting to info", logLevel)
-		level = logrus.InfoLevel
+		logger.WithError(err).Fatal("Failed to resolve secrets")
 	}
-	logger.SetLevel(level)
-
-	logger.Info("Starting Policy Service...")
+	secrets.SetDefault(secretStore)
+	logger.WithField("provider", secretStore.Source()).Info("Secrets resolved")
 
 	// Get configuration from environment
 	port := os.Getenv("PORT")
@@ -50,73 +49,177 @@ func main() {
 		dataPath = filepath.Join("..", "..", "data", "seed")
 	}
 
-	cloudBeesAPIKey := os.Getenv("CLOUDBEES_FM_API_KEY")
+	cloudBeesAPIKey := secretStore.Lookup("CLOUDBEES_FM_API_KEY", "")
 	if cloudBeesAPIKey == "" {
 		logger.Warn("CLOUDBEES_FM_API_KEY not set, feature flags will use defaults")
 		// Use a placeholder for development
 		cloudBeesAPIKey = "dev-mode"
 	}
 
-	// Initialize CloudBees Feature Management
-	flags, err := features.Initialize(cloudBeesAPIKey, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize feature management")
+	// Grace-period settings
+	gracePeriod := 30 * 24 * time.Hour
+	if v := os.Getenv("POLICY_GRACE_PERIOD_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			gracePeriod = time.Duration(n) * 24 * time.Hour
+		} else {
+			logger.Warnf("Invalid POLICY_GRACE_PERIOD_DAYS '%s', defaulting to 30", v)
+		}
 	}
-	defer features.Shutdown()
 
-	// Initialize repository
-	repo, err := repository.NewRepository(dataPath, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize repository")
+	graceSweepInterval := time.Hour
+	if v := os.Getenv("POLICY_GRACE_SWEEP_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			graceSweepInterval = d
+		} else {
+			logger.Warnf("Invalid POLICY_GRACE_SWEEP_INTERVAL '%s', defaulting to %s", v, graceSweepInterval)
+		}
 	}
 
-	// Initialize services
-	policyService := services.NewPolicyService(repo, flags, logger)
+	// Notice the insurer gives of a cancellation
+	cancellationNotice := 10 * 24 * time.Hour
+	if v := os.Getenv("POLICY_CANCELLATION_NOTICE_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cancellationNotice = time.Duration(n) * 24 * time.Hour
+		} else {
+			logger.Warnf("Invalid POLICY_CANCELLATION_NOTICE_DAYS '%s', defaulting to 10", v)
+		}
+	}
 
-	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	policyHandler := handlers.NewPolicyHandler(policyService, logger)
+	// Business rules engine expressions new policies must pass; defaults to
+	// business-rules.json in DATA_PATH, checked for changes every
+	// BUSINESS_RULES_RELOAD_INTERVAL
+	businessRulesFile := os.Getenv("BUSINESS_RULES_FILE")
+	businessRulesReloadInterval := 30 * time.Second
+	if v := os.Getenv("BUSINESS_RULES_RELOAD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			businessRulesReloadInterval = d
+		} else {
+			logger.Warnf("Invalid BUSINESS_RULES_RELOAD_INTERVAL '%s', defaulting to %s", v, businessRulesReloadInterval)
+		}
+	}
 
-	// Setup router
-	router := mux.NewRouter()
+	// Field visibility rules on top of the shape tags; defaults to
+	// response-shaping.json in DATA_PATH
+	shapingFile := os.Getenv("SHAPING_FILE")
 
-	// Apply global middleware
-	router.Use(middleware.LoggingMiddleware(logger))
-	router.Use(middleware.AuthMiddleware(logger))
+	// Other services, used by the consistency report, refunds and quote
+	// conversion tracking
+	customerServiceURL := os.Getenv("CUSTOMER_SERVICE_URL")
+	if customerServiceURL == "" {
+		logger.Warn("CUSTOMER_SERVICE_URL not set, consistency report will skip customer checks")
+	}
 
-	// Setup CORS
-	corsHandler := middleware.NewCORS()
+	paymentsServiceURL := os.Getenv("PAYMENTS_SERVICE_URL")
+	if paymentsServiceURL == "" {
+		logger.Warn("PAYMENTS_SERVICE_URL not set, cancellations cannot refund premium")
+	}
 
-	// Register routes
-	router.Handle("/healthz", healthHandler).Methods("GET")
-	router.HandleFunc("/policies", policyHandler.GetPolicies).Methods("GET")
-	router.HandleFunc("/policies/{id}", policyHandler.GetPolicyByID).Methods("GET")
-	router.HandleFunc("/policies", policyHandler.CreatePolicy).Methods("POST")
-	router.HandleFunc("/policies/{id}", policyHandler.UpdatePolicy).Methods("PUT")
-	router.HandleFunc("/policies/{id}", policyHandler.DeletePolicy).Methods("DELETE")
+	pricingServiceURL := os.Getenv("PRICING_SERVICE_URL")
+	if pricingServiceURL == "" {
+		logger.Warn("PRICING_SERVICE_URL not set, bound quotes will not be reported as converted")
+	}
+
+	// Persisted state, so changes survive restarts
+	persistDir := os.Getenv("PERSIST_DIR")
+	if persistDir == "" {
+		logger.Warn("PERSIST_DIR not set, changes will be lost on restart")
+	}
+	persistFlushInterval := time.Minute
+	if v := os.Getenv("PERSIST_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			persistFlushInterval = d
+		} else {
+			logger.Warnf("Invalid PERSIST_FLUSH_INTERVAL '%s', defaulting to %s", v, persistFlushInterval)
+		}
+	}
+
+	// Requests at least this slow are logged as warnings
+	slowRequestThreshold := time.Second
+	if v := os.Getenv("HTTP_SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			slowRequestThreshold = d
+		} else {
+			logger.Warnf("Invalid HTTP_SLOW_REQUEST_THRESHOLD '%s', defaulting to %s", v, slowRequestThreshold)
+		}
+	}
 
-	// Wrap router with CORS
-	handler := corsHandler.Handler(router)
+	// Access entries go to the service log unless ACCESS_LOG names a
+	// separate stream: stdout, stderr or a file path
+	accessLog, closeAccessLog, err := telemetry.OpenAccessLog(os.Getenv("ACCESS_LOG"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open access log")
+	}
+	defer closeAccessLog()
+
+	// Assemble the service
+	application, err := app.New(app.Config{
+		DataPath:                    dataPath,
+		FeatureAPIKey:               cloudBeesAPIKey,
+		CustomerServiceURL:          customerServiceURL,
+		PaymentsServiceURL:          paymentsServiceURL,
+		PricingServiceURL:           pricingServiceURL,
+		GracePeriod:                 gracePeriod,
+		GraceSweepInterval:          graceSweepInterval,
+		CancellationNotice:          cancellationNotice,
+		BusinessRulesFile:           businessRulesFile,
+		BusinessRulesReloadInterval: businessRulesReloadInterval,
+		ShapingFile:                 shapingFile,
+		Maintenance:                 maintenance.ConfigFromEnv(logger),
+		Faults:                      faults.ConfigFromEnv(logger),
+		PersistDir:                  persistDir,
+		PersistFlushInterval:        persistFlushInterval,
+		SlowRequestThreshold:        slowRequestThreshold,
+		AccessLog:                   accessLog,
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize service")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      handler,
+		Handler:      application.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Drain requests in flight and stop the service's components in order
+	conns := lifecycle.TrackConnections(server)
+	shutdown := lifecycle.New(logger)
+	application.RegisterShutdown(shutdown, lifecycle.DrainServer(server, conns))
+
 	// Start server in a goroutine
 	go func() {
 		logger.Infof("Server listening on port %s", port)
 		logger.Info("API Endpoints:")
 		logger.Info("  GET    /healthz - Health check")
+		logger.Info("  GET    /labels - Status and type labels in the Accept-Language language")
+		logger.Info("  GET    /metrics - Request latency by route (Prometheus)")
 		logger.Info("  GET    /policies - List all policies")
 		logger.Info("  GET    /policies/{id} - Get policy by ID")
 		logger.Info("  POST   /policies - Create new policy")
 		logger.Info("  PUT    /policies/{id} - Update policy")
-		logger.Info("  DELETE /policies/{id} - Cancel policy")
+		logger.Info("  DELETE /policies/{id} - Cancel policy and prorate its premium")
+		logger.Info("         Query params: effectiveDate, reasonCode, reason, refund (staff JWT cancels for the insurer)")
+		logger.Info("  POST   /policies/{id}/reinstate - Reinstate a policy in its grace period")
+		logger.Info("  GET    /admin/policies - List policies across customers (admin/adjuster JWT)")
+		logger.Info("         Query params: type, status, customerId, agentId, expiringBefore, page, pageSize")
+		logger.Info("  GET    /admin/policies/export - Export matching policies as CSV (admin/adjuster JWT)")
+		logger.Info("  POST   /admin/policies/import - Import legacy policies from a CSV file (admin/adjuster JWT)")
+		logger.Info("         Multipart parts: file, mapping; query params: dryRun")
+		logger.Info("  GET    /admin/policies/import/{id} - Policy import progress (admin/adjuster JWT)")
+		logger.Info("  GET    /admin/policies/import/{id}/report - Download a policy import report (admin/adjuster JWT)")
+		logger.Info("  POST   /admin/policies/{id}/lapse - Lapse a policy for non-payment (admin/adjuster JWT)")
+		logger.Info("  POST   /admin/policies/grace-sweep - Apply due policy status changes now (admin/adjuster JWT)")
+		logger.Info("  GET    /admin/consistency-report - Cross-service reference check (admin/adjuster JWT)")
+		logger.Info("  GET    /admin/maintenance - Maintenance mode (admin JWT)")
+		logger.Info("  PUT    /admin/maintenance - Turn maintenance mode on or off (admin JWT)")
+		logger.Info("  GET    /admin/faults - Fault injection rules, when enabled (admin JWT)")
+		logger.Info("  PUT    /admin/faults - Replace the fault injection rules (admin JWT)")
+		logger.Info("  GET    /admin/rules - Business rules and their evaluation counts (admin JWT)")
+		logger.Info("  PUT    /admin/rules - Replace the business rules (admin JWT)")
+		logger.Info("  POST   /admin/rules/test - Evaluate the policy rules against a policy (admin/adjuster JWT)")
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Fatal("Server failed to start")
@@ -128,14 +231,16 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	open := conns.Connections()
+	logger.WithFields(logrus.Fields{
+		"active":   open.Active,
+		"idle":     open.Idle,
+		"hijacked": open.Hijacked,
+	}).Info("Shutting down server...")
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
+	if err := shutdown.Shutdown(context.Background()).Err(); err != nil {
+		logger.WithError(err).Error("Shutdown incomplete")
+		return
 	}
 
 	logger.Info("Server stopped gracefully")
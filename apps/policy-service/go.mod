@@ -3,6 +3,20 @@ module github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service
 go 1.21
 
 require (
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/faults v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/health v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/logging v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/persist v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/rules v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry v0.0.0
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/gorilla/mux v1.8.1
 	github.com/rs/cors v1.10.1
@@ -10,4 +24,24 @@ require (
 	golang.org/x/crypto v0.17.0
 )
 
-require golang.org/x/sys v0.15.0 // indirect
+require (
+	github.com/expr-lang/expr v1.16.9 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+)
+
+replace (
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts => ../../pkg/contracts
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/faults => ../../pkg/faults
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/health => ../../pkg/health
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks => ../../pkg/hooks
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n => ../../pkg/i18n
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle => ../../pkg/lifecycle
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/logging => ../../pkg/logging
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance => ../../pkg/maintenance
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate => ../../pkg/migrate
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/persist => ../../pkg/persist
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/rules => ../../pkg/rules
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets => ../../pkg/secrets
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping => ../../pkg/shaping
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry => ../../pkg/telemetry
+)
@@ -16,17 +16,24 @@ var (
 type Claims struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTManager manages JWT token creation and validation
 type JWTManager struct {
-	secretKey     string
+	secretKey     func() string
 	tokenDuration time.Duration
 }
 
 // NewJWTManager creates a new JWT manager
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
+	return NewRotatingJWTManager(func() string { return secretKey }, tokenDuration)
+}
+
+// NewRotatingJWTManager creates a JWT manager that looks its secret up for
+// every token, so a rotated secret is used without a restart
+func NewRotatingJWTManager(secretKey func() string, tokenDuration time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     secretKey,
 		tokenDuration: tokenDuration,
@@ -35,9 +42,15 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 
 // Generate creates a new JWT token for a user
 func (manager *JWTManager) Generate(userID, email string) (string, error) {
+	return manager.GenerateWithRole(userID, email, "")
+}
+
+// GenerateWithRole creates a new JWT token for a user carrying a role claim
+func (manager *JWTManager) GenerateWithRole(userID, email, role string) (string, error) {
 	claims := Claims{
 		UserID: userID,
 		Email:  email,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(manager.tokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -46,7 +59,7 @@ func (manager *JWTManager) Generate(userID, email string) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(manager.secretKey))
+	return token.SignedString([]byte(manager.secretKey()))
 }
 
 // Verify validates a JWT token and returns the claims
@@ -59,7 +72,7 @@ func (manager *JWTManager) Verify(tokenString string) (*Claims, error) {
 			if !ok {
 				return nil, ErrInvalidToken
 			}
-			return []byte(manager.secretKey), nil
+			return []byte(manager.secretKey()), nil
 		},
 	)
 
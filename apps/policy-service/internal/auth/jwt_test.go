@@ -23,8 +23,8 @@ func TestNewJWTManager(t *testing.T) {
 			if manager == nil {
 				t.Error("NewJWTManager returned nil")
 			}
-			if manager.secretKey != tt.secretKey {
-				t.Errorf("Secret key mismatch: got %v, want %v", manager.secretKey, tt.secretKey)
+			if manager.secretKey() != tt.secretKey {
+				t.Errorf("Secret key mismatch: got %v, want %v", manager.secretKey(), tt.secretKey)
 			}
 			if manager.tokenDuration != tt.tokenDuration {
 				t.Errorf("Token duration mismatch: got %v, want %v", manager.tokenDuration, tt.tokenDuration)
@@ -0,0 +1,76 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+)
+
+// These tests are the consumer half of the contracts in pkg/contracts. The
+// provider halves run in payments-service, pricing-engine and
+// customer-service.
+
+func TestPaymentsClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("policy-service", "payments-service"))
+	client := NewPaymentsClient(mock.URL, 5*time.Second)
+	ctx := context.Background()
+
+	refund, err := client.CreateRefund(ctx, "pol-001", "cust-001", 416.44)
+	if err != nil {
+		t.Fatalf("CreateRefund failed: %v", err)
+	}
+	if refund.Type != "refund" || refund.PolicyID != "pol-001" || refund.ID == "" {
+		t.Errorf("Unexpected refund: %+v", refund)
+	}
+
+	if _, err := client.CreateRefund(ctx, "pol-001", "cust-001", 0); err == nil {
+		t.Error("Expected error for refund with a zero amount")
+	}
+}
+
+func TestPricingClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("policy-service", "pricing-engine"))
+	client := NewPricingClient(mock.URL, 5*time.Second)
+	ctx := context.Background()
+
+	quote, err := client.ConvertQuote(ctx, "Q-1c84e5d0", "pol-101")
+	if err != nil {
+		t.Fatalf("ConvertQuote failed: %v", err)
+	}
+	if !quote.Converted || quote.PolicyID != "pol-101" {
+		t.Errorf("Unexpected quote: %+v", quote)
+	}
+
+	if _, err := client.ConvertQuote(ctx, "Q-00000000", "pol-101"); err == nil || err.Error() != "quote not found" {
+		t.Errorf("Unknown quote: got %v", err)
+	}
+}
+
+func TestCustomerClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("policy-service", "customer-service"))
+	client := NewCustomerClient(mock.URL, 5*time.Second)
+	ctx := context.Background()
+
+	customer, err := client.GetCustomer(ctx, "cust-001")
+	if err != nil {
+		t.Fatalf("GetCustomer failed: %v", err)
+	}
+	if customer.ID != "cust-001" || !customer.EmailVerified || customer.KYCStatus != "verified" {
+		t.Errorf("Unexpected customer: %+v", customer)
+	}
+
+	if unverified, err := client.GetCustomer(ctx, "cust-008"); err != nil || unverified.EmailVerified {
+		t.Errorf("Unverified customer: got %+v, %v", unverified, err)
+	}
+
+	if _, err := client.GetCustomer(ctx, "cust-404"); err == nil || err.Error() != "customer not found" {
+		t.Errorf("Unknown customer: got %v", err)
+	}
+
+	members, err := client.HouseholdMembers(ctx, "cust-005")
+	if err != nil || len(members) != 2 || members[0] != "cust-005" || members[1] != "cust-007" {
+		t.Errorf("Household members: got %v, %v", members, err)
+	}
+}
@@ -0,0 +1,120 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Customer is the subset of a customer-service customer the policy service reads
+type Customer struct {
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	HouseholdID   string `json:"householdId,omitempty"`
+	KYCStatus     string `json:"kycStatus,omitempty"`
+}
+
+// Household is the subset of a customer-service household the policy
+// service reads
+type Household struct {
+	ID        string   `json:"id"`
+	MemberIDs []string `json:"memberIds"`
+}
+
+// CustomerClient calls customer-service
+type CustomerClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewCustomerClient creates a new customer-service client
+func NewCustomerClient(baseURL string, timeout time.Duration) *CustomerClient {
+	return &CustomerClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetCustomer fetches a customer profile
+func (c *CustomerClient) GetCustomer(ctx context.Context, customerID string) (*Customer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/customers/"+url.PathEscape(customerID), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("customer-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("customer not found")
+	default:
+		return nil, fmt.Errorf("customer-service returned status %d", resp.StatusCode)
+	}
+
+	var customer Customer
+	if err := json.NewDecoder(resp.Body).Decode(&customer); err != nil {
+		return nil, fmt.Errorf("failed to decode customer: %w", err)
+	}
+	return &customer, nil
+}
+
+// GetHousehold fetches a household and its members
+func (c *CustomerClient) GetHousehold(ctx context.Context, householdID string) (*Household, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/households/"+url.PathEscape(householdID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("customer-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("household not found")
+	default:
+		return nil, fmt.Errorf("customer-service returned status %d", resp.StatusCode)
+	}
+
+	var household Household
+	if err := json.NewDecoder(resp.Body).Decode(&household); err != nil {
+		return nil, fmt.Errorf("failed to decode household: %w", err)
+	}
+	return &household, nil
+}
+
+// HouseholdMembers returns the IDs of every customer in the customer's
+// household, including the customer. A customer outside any household is
+// its only member.
+func (c *CustomerClient) HouseholdMembers(ctx context.Context, customerID string) ([]string, error) {
+	customer, err := c.GetCustomer(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.HouseholdID == "" {
+		return []string{customerID}, nil
+	}
+
+	household, err := c.GetHousehold(ctx, customer.HouseholdID)
+	if err != nil {
+		// The customer may have left between the two requests
+		if err.Error() == "household not found" {
+			return []string{customerID}, nil
+		}
+		return nil, err
+	}
+	return household.MemberIDs, nil
+}
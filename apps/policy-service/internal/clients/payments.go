@@ -0,0 +1,79 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Refund is the subset of a payments-service payment the policy service reads
+type Refund struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	PolicyID   string  `json:"policyId"`
+	CustomerID string  `json:"customerId"`
+	Amount     float64 `json:"amount"`
+	Status     string  `json:"status"`
+}
+
+// refundRequest is the body of POST /refunds
+type refundRequest struct {
+	PolicyID   string  `json:"policyId"`
+	CustomerID string  `json:"customerId"`
+	Amount     float64 `json:"amount"`
+}
+
+// PaymentsClient calls payments-service
+type PaymentsClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPaymentsClient creates a new payments-service client
+func NewPaymentsClient(baseURL string, timeout time.Duration) *PaymentsClient {
+	return &PaymentsClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// CreateRefund requests a refund of premium to the policyholder
+func (c *PaymentsClient) CreateRefund(ctx context.Context, policyID, customerID string, amount float64) (*Refund, error) {
+	body, err := json.Marshal(refundRequest{
+		PolicyID:   policyID,
+		CustomerID: customerID,
+		Amount:     amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/refunds", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("payments-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusBadRequest:
+		return nil, fmt.Errorf("refund rejected by payments-service")
+	default:
+		return nil, fmt.Errorf("payments-service returned status %d", resp.StatusCode)
+	}
+
+	var refund Refund
+	if err := json.NewDecoder(resp.Body).Decode(&refund); err != nil {
+		return nil, fmt.Errorf("failed to decode refund: %w", err)
+	}
+	return &refund, nil
+}
@@ -0,0 +1,75 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ConvertedQuote is the subset of a pricing-engine quote record the policy
+// service reads
+type ConvertedQuote struct {
+	QuoteID    string `json:"quoteId"`
+	CustomerID string `json:"customerId"`
+	Converted  bool   `json:"converted"`
+	PolicyID   string `json:"policyId"`
+}
+
+// convertQuoteRequest is the body of POST /quote/{id}/convert
+type convertQuoteRequest struct {
+	PolicyID string `json:"policyId"`
+}
+
+// PricingClient calls pricing-engine
+type PricingClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPricingClient creates a new pricing-engine client
+func NewPricingClient(baseURL string, timeout time.Duration) *PricingClient {
+	return &PricingClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// ConvertQuote reports that a quote was bound into a policy
+func (c *PricingClient) ConvertQuote(ctx context.Context, quoteID, policyID string) (*ConvertedQuote, error) {
+	body, err := json.Marshal(convertQuoteRequest{PolicyID: policyID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/quote/"+url.PathEscape(quoteID)+"/convert", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("pricing-engine request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("quote not found")
+	case http.StatusConflict:
+		return nil, fmt.Errorf("quote already converted")
+	default:
+		return nil, fmt.Errorf("pricing-engine returned status %d", resp.StatusCode)
+	}
+
+	var quote ConvertedQuote
+	if err := json.NewDecoder(resp.Body).Decode(&quote); err != nil {
+		return nil, fmt.Errorf("failed to decode quote: %w", err)
+	}
+	return &quote, nil
+}
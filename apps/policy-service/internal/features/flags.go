@@ -10,10 +10,12 @@ import (
 
 // Flags holds all feature flags for the application
 type Flags struct {
-	maskAmounts bool
-	currency    string
-	mu          sync.RWMutex
-	logger      *logrus.Logger
+	maskAmounts          bool
+	currency             string
+	requireVerifiedEmail bool
+	requireVerifiedKYC   bool
+	mu                   sync.RWMutex
+	logger               *logrus.Logger
 }
 
 var flags *Flags
@@ -45,9 +47,27 @@ func Initialize(apiKey string, logger *logrus.Logger) (*Flags, error) {
 	}
 	flags.currency = currency
 
+	// policies.requireVerifiedEmail (default: false) - only customers with a
+	// verified email address can take out policies
+	if v := os.Getenv("FEATURE_REQUIRE_VERIFIED_EMAIL"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			flags.requireVerifiedEmail = enabled
+		}
+	}
+
+	// policies.requireVerifiedKYC (default: false) - only customers whose
+	// identity documents have been verified can take out policies
+	if v := os.Getenv("FEATURE_REQUIRE_VERIFIED_KYC"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			flags.requireVerifiedKYC = enabled
+		}
+	}
+
 	logger.WithFields(logrus.Fields{
-		"maskAmounts": flags.maskAmounts,
-		"currency":    flags.currency,
+		"maskAmounts":          flags.maskAmounts,
+		"currency":             flags.currency,
+		"requireVerifiedEmail": flags.requireVerifiedEmail,
+		"requireVerifiedKYC":   flags.requireVerifiedKYC,
 	}).Info("Feature flags initialized")
 
 	if apiKey != "" && apiKey != "dev-mode" {
@@ -83,6 +103,52 @@ func (f *Flags) SetMaskAmounts(enabled bool) {
 	f.logger.WithField("maskAmounts", enabled).Info("Feature flag updated")
 }
 
+// RequireVerifiedEmail returns whether policy creation requires the customer
+// to have verified their email address
+func (f *Flags) RequireVerifiedEmail() bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.requireVerifiedEmail
+}
+
+// SetRequireVerifiedEmail sets the verified email flag (for testing/admin
+// purposes)
+func (f *Flags) SetRequireVerifiedEmail(enabled bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requireVerifiedEmail = enabled
+	f.logger.WithField("requireVerifiedEmail", enabled).Info("Feature flag updated")
+}
+
+// RequireVerifiedKYC returns whether policy creation requires the customer
+// to have passed KYC identity verification
+func (f *Flags) RequireVerifiedKYC() bool {
+	if f == nil {
+		return false
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.requireVerifiedKYC
+}
+
+// SetRequireVerifiedKYC sets the verified KYC flag (for testing/admin
+// purposes)
+func (f *Flags) SetRequireVerifiedKYC(enabled bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requireVerifiedKYC = enabled
+	f.logger.WithField("requireVerifiedKYC", enabled).Info("Feature flag updated")
+}
+
 // GetCurrency returns the currency code for amounts
 func (f *Flags) GetCurrency() string {
 	if f == nil {
@@ -168,6 +234,22 @@ func (f *Flags) SetCurrency(currency string) {
 	f.logger.WithField("currency", currency).Info("Feature flag updated")
 }
 
+// EnabledFlags returns the keys of the boolean flags that are on, for the
+// health check
+func (f *Flags) EnabledFlags() []string {
+	var enabled []string
+	if f.ShouldMaskAmounts() {
+		enabled = append(enabled, "api.maskAmounts")
+	}
+	if f.RequireVerifiedEmail() {
+		enabled = append(enabled, "policies.requireVerifiedEmail")
+	}
+	if f.RequireVerifiedKYC() {
+		enabled = append(enabled, "policies.requireVerifiedKYC")
+	}
+	return enabled
+}
+
 // Shutdown gracefully shuts down the feature management system
 func Shutdown() {
 	if flags != nil {
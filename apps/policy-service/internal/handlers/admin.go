@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// policyExportColumns is the header row of the CSV export
+var policyExportColumns = []string{
+	"id", "policyNumber", "customerId", "type", "status",
+	"premium", "coverage", "deductible", "currency", "startDate", "endDate",
+}
+
+// ListAllPolicies handles GET /admin/policies - lists policies across all customers
+// Supports query parameters:
+// - type: filter by type (auto/home/life)
+// - status: filter by status (pending/active/grace/lapsed/cancelled/expired/pending_cancellation)
+// - customerId: filter by customer ID
+// - expiringBefore: policies ending before this date (YYYY-MM-DD or RFC 3339)
+// - page: page number, from 1 (default: 1)
+// - pageSize: policies per page (default: 50, max: 500)
+func (h *PolicyHandler) ListAllPolicies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filters, err := parsePolicyFilters(query)
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := intParam(query, "page", 1)
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pageSize, err := intParam(query, "pageSize", services.DefaultPageSize)
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.policyService.ListPoliciesPage(filters, page, pageSize)
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shaping.Apply(r, result))
+}
+
+// GetPolicyByNumber handles GET /admin/policies/by-number/{policyNumber} -
+// finds a policy across all customers by its number, ignoring case, spaces
+// and dashes. A number shared by several policies is a conflict; search
+// lists them all.
+func (h *PolicyHandler) GetPolicyByNumber(w http.ResponseWriter, r *http.Request) {
+	policyNumber := mux.Vars(r)["policyNumber"]
+
+	policy, err := h.policyService.GetPolicyByNumber(policyNumber)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		if err.Error() == "policy not found" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "not_found",
+				Message: "Policy not found",
+			})
+			return
+		}
+		h.logger.WithError(err).WithField("policyNumber", policyNumber).Warn("Policy number matches several policies")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "conflict",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"policyNumber": policyNumber,
+		"policyId":     policy.ID,
+		"lookedUpBy":   middleware.GetUserID(r),
+	}).Info("Policy looked up by number")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
+}
+
+// SearchPolicyNumbers handles GET /admin/policies/by-number - finds
+// policies across all customers by a full or partial policy number
+// Supports query parameters:
+// - q: the policy number or its start, near misses included (required)
+// - limit: most matches returned (default: 10, max: 50)
+func (h *PolicyHandler) SearchPolicyNumbers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit, err := intParam(query, "limit", services.DefaultNumberSearchLimit)
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.policyService.SearchPolicyNumbers(query.Get("q"), limit)
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shaping.Apply(r, result))
+}
+
+// ExportPolicies handles GET /admin/policies/export - downloads every
+// matching policy as CSV. Accepts the same filters as ListAllPolicies.
+func (h *PolicyHandler) ExportPolicies(w http.ResponseWriter, r *http.Request) {
+	filters, err := parsePolicyFilters(r.URL.Query())
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	policies := shaping.Apply(r, h.policyService.ListPolicies(filters))
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="policies-%s.csv"`, time.Now().UTC().Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(policyExportColumns)
+	for _, p := range policies {
+		writer.Write([]string{
+			p.ID,
+			p.PolicyNumber,
+			p.CustomerID,
+			p.Type,
+			p.Status,
+			fmt.Sprint(p.Premium),
+			fmt.Sprint(p.Coverage),
+			strconv.FormatFloat(p.Deductible, 'f', -1, 64),
+			p.Currency,
+			p.StartDate.Format(time.RFC3339),
+			p.EndDate.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.WithError(err).Error("Failed to write policy export")
+		return
+	}
+
+	h.logger.WithField("count", len(policies)).Info("Exported policies")
+}
+
+// parsePolicyFilters reads the back-office listing filters
+func parsePolicyFilters(query url.Values) (models.PolicyFilters, error) {
+	filters := models.PolicyFilters{
+		Type:       query.Get("type"),
+		Status:     query.Get("status"),
+		CustomerID: query.Get("customerId"),
+		AgentID:    query.Get("agentId"),
+	}
+
+	if filters.Type != "" && filters.Type != "auto" && filters.Type != "home" && filters.Type != "life" {
+		return filters, fmt.Errorf("invalid policy type: must be one of auto, home, life")
+	}
+	if filters.Status != "" && !models.ValidatePolicyStatus(filters.Status) {
+		return filters, fmt.Errorf("invalid status: must be one of pending, active, grace, lapsed, cancelled, expired")
+	}
+
+	if value := query.Get("expiringBefore"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return filters, fmt.Errorf("expiringBefore must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+			}
+		}
+		filters.ExpiringBefore = t
+	}
+
+	return filters, nil
+}
+
+// intParam reads an optional integer query parameter
+func intParam(query url.Values, name string, fallback int) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	return n, nil
+}
+
+// respondAdminError writes a bad request in the ErrorResponse shape
+func (h *PolicyHandler) respondAdminError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "bad_request",
+		Message: message,
+	})
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// CommentService is the business logic the comment handlers depend on.
+// *services.CommentService is the production implementation.
+type CommentService interface {
+	AddComment(policyID string, author models.CommentAuthor, req *models.CreateCommentRequest) (*models.Comment, error)
+	GetComments(policyID string, author models.CommentAuthor) ([]*models.Comment, error)
+	UpdateComment(policyID, commentID string, author models.CommentAuthor, req *models.UpdateCommentRequest) (*models.Comment, error)
+}
+
+var _ CommentService = (*services.CommentService)(nil)
+
+// CommentHandler handles comment threads on policies. Routes must be
+// wrapped in middleware.IdentifyRole so staff can be told apart from
+// customers.
+type CommentHandler struct {
+	service CommentService
+	logger  *logrus.Logger
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(service CommentService, logger *logrus.Logger) *CommentHandler {
+	return &CommentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetComments handles GET /policies/{id}/comments
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	policyID := mux.Vars(r)["id"]
+
+	comments, err := h.service.GetComments(policyID, commentAuthor(r))
+	if err != nil {
+		h.respondServiceError(w, policyID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, comments)
+}
+
+// AddComment handles POST /policies/{id}/comments
+func (h *CommentHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	policyID := mux.Vars(r)["id"]
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		h.respondError(w, http.StatusBadRequest, "bad_request", "Invalid request body")
+		return
+	}
+
+	comment, err := h.service.AddComment(policyID, commentAuthor(r), &req)
+	if err != nil {
+		h.respondServiceError(w, policyID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment handles PUT /policies/{id}/comments/{commentId}
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	policyID := vars["id"]
+
+	var req models.UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		h.respondError(w, http.StatusBadRequest, "bad_request", "Invalid request body")
+		return
+	}
+
+	comment, err := h.service.UpdateComment(policyID, vars["commentId"], commentAuthor(r), &req)
+	if err != nil {
+		h.respondServiceError(w, policyID, err)
+		return
+	}
+
+	h.respondJSON(w, http.StatusOK, comment)
+}
+
+// commentAuthor identifies who is reading or writing comments: staff by
+// their verified token, everyone else as a customer
+func commentAuthor(r *http.Request) models.CommentAuthor {
+	role := middleware.GetRole(r)
+	if role != "admin" && role != "adjuster" {
+		role = models.CommentRoleCustomer
+	}
+	return models.CommentAuthor{ID: middleware.GetUserID(r), Role: role}
+}
+
+// respondServiceError maps comment service errors to HTTP statuses
+func (h *CommentHandler) respondServiceError(w http.ResponseWriter, policyID string, err error) {
+	switch {
+	case err.Error() == "policy not found":
+		h.respondError(w, http.StatusNotFound, "not_found", "Policy not found")
+	case err.Error() == "comment not found":
+		h.respondError(w, http.StatusNotFound, "not_found", "Comment not found")
+	case err.Error() == "unauthorized":
+		h.respondError(w, http.StatusForbidden, "forbidden", "You do not have access to this comment")
+	case err.Error() == "only staff can write internal comments":
+		h.respondError(w, http.StatusForbidden, "forbidden", err.Error())
+	case strings.HasPrefix(err.Error(), "failed to"):
+		h.logger.WithError(err).WithField("policyId", policyID).Error("Failed to save comment")
+		h.respondError(w, http.StatusInternalServerError, "internal_error", "Failed to save comment")
+	default:
+		h.respondError(w, http.StatusBadRequest, "bad_request", err.Error())
+	}
+}
+
+// respondJSON sends a JSON response
+func (h *CommentHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an ErrorResponse
+func (h *CommentHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/sirupsen/logrus"
+)
+
+// consistencyTimeout bounds how long a report may spend calling other services
+const consistencyTimeout = 30 * time.Second
+
+// ConsistencyChecker builds referential integrity reports.
+// *services.ConsistencyChecker is the production implementation.
+type ConsistencyChecker interface {
+	Check(ctx context.Context) *models.ConsistencyReport
+}
+
+var _ ConsistencyChecker = (*services.ConsistencyChecker)(nil)
+
+// ConsistencyHandler serves the referential integrity report
+type ConsistencyHandler struct {
+	checker ConsistencyChecker
+	logger  *logrus.Logger
+}
+
+// NewConsistencyHandler creates a new consistency report handler
+func NewConsistencyHandler(checker ConsistencyChecker, logger *logrus.Logger) *ConsistencyHandler {
+	return &ConsistencyHandler{
+		checker: checker,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP handles GET /admin/consistency-report
+func (h *ConsistencyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), consistencyTimeout)
+	defer cancel()
+
+	report := h.checker.Check(ctx)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
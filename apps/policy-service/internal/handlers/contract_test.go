@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+	"github.com/sirupsen/logrus"
+)
+
+// TestClaimsServiceContract verifies policy-service still satisfies what
+// claims-service expects. The consumer half lives in claims-service.
+func TestClaimsServiceContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	// Provider states are checked against the same seed data the service loaded
+	repo, err := repository.NewRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to load seed data: %v", err)
+	}
+
+	owns := func(policyID, customerID string) func() error {
+		return func() error {
+			policy, err := repo.GetPolicyByID(policyID)
+			if err != nil {
+				return err
+			}
+			if policy.CustomerID != customerID {
+				return fmt.Errorf("%s belongs to %s", policyID, policy.CustomerID)
+			}
+			return nil
+		}
+	}
+
+	contracts.VerifyProvider(t, application.Handler, contracts.MustLoad("claims-service", "policy-service"), contracts.StateHandlers{
+		"policy pol-001 belongs to cust-001": owns("pol-001", "cust-001"),
+		"policy pol-002 belongs to cust-002": owns("pol-002", "cust-002"),
+		"policy pol-999 does not exist": func() error {
+			if _, err := repo.GetPolicyByID("pol-999"); err == nil {
+				return fmt.Errorf("pol-999 exists in seed data")
+			}
+			return nil
+		},
+	})
+}
+
+// TestPricingEngineContract verifies policy-service still lists household
+// policies the way pricing-engine reads them for the multi-policy discount.
+// The policy-service under test has no customer-service, so a household is
+// just the customer. The consumer half lives in pricing-engine.
+func TestPricingEngineContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	repo, err := repository.NewRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to load seed data: %v", err)
+	}
+
+	contracts.VerifyProvider(t, application.Handler, contracts.MustLoad("pricing-engine", "policy-service"), contracts.StateHandlers{
+		"customer cust-001 holds active policy pol-001": func() error {
+			policy, err := repo.GetPolicyByID("pol-001")
+			if err != nil {
+				return err
+			}
+			if policy.CustomerID != "cust-001" || policy.Status != "active" {
+				return fmt.Errorf("pol-001 is %s and belongs to %s", policy.Status, policy.CustomerID)
+			}
+			return nil
+		},
+		"customer cust-404 holds no policies": func() error {
+			if policies, _ := repo.GetPoliciesByCustomerID("cust-404"); len(policies) != 0 {
+				return fmt.Errorf("cust-404 holds %d policies in seed data", len(policies))
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// GraceSweeper applies grace-period transitions.
+// *services.GraceSweeper is the production implementation.
+type GraceSweeper interface {
+	Sweep(now time.Time) *models.GraceSweepResult
+}
+
+var _ GraceSweeper = (*services.GraceSweeper)(nil)
+
+// GraceSweepHandler runs a grace-period sweep on demand
+type GraceSweepHandler struct {
+	sweeper GraceSweeper
+	logger  *logrus.Logger
+}
+
+// NewGraceSweepHandler creates a new grace sweep handler
+func NewGraceSweepHandler(sweeper GraceSweeper, logger *logrus.Logger) *GraceSweepHandler {
+	return &GraceSweepHandler{
+		sweeper: sweeper,
+		logger:  logger,
+	}
+}
+
+// ServeHTTP handles POST /admin/policies/grace-sweep
+func (h *GraceSweepHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	result := h.sweeper.Sweep(time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// ReinstatePolicy handles POST /policies/{id}/reinstate - restores cover for
+// a policy in its grace period. The body is optional.
+func (h *PolicyHandler) ReinstatePolicy(w http.ResponseWriter, r *http.Request) {
+	customerID := middleware.GetUserID(r)
+	policyID := mux.Vars(r)["id"]
+
+	var req models.ReinstatePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WithError(err).Warn("Invalid request body")
+		h.respondAdminError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	policy, err := h.policyService.ReinstatePolicy(policyID, customerID, req)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "forbidden",
+				Message: "You do not have access to this policy",
+			})
+			return
+		case "policy is not in grace", "grace period has ended":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "conflict",
+				Message: "Policy cannot be reinstated: " + err.Error(),
+			})
+			return
+		case "endDate must be after the current end date":
+			h.respondAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if h.respondTransitionError(w, err) {
+			return
+		}
+
+		h.logger.WithError(err).WithFields(logrus.Fields{
+			"customerId": customerID,
+			"policyId":   policyID,
+		}).Error("Failed to reinstate policy")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "not_found",
+			Message: "Policy not found",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
+}
+
+// LapsePolicy handles POST /admin/policies/{id}/lapse - lapses a policy in
+// force whose premium was taken back, such as after a lost chargeback. A
+// reason is required.
+func (h *PolicyHandler) LapsePolicy(w http.ResponseWriter, r *http.Request) {
+	policyID := mux.Vars(r)["id"]
+
+	var req models.LapsePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		h.respondAdminError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		h.respondAdminError(w, http.StatusBadRequest, "reason is required")
+		return
+	}
+
+	policy, err := h.policyService.LapsePolicy(policyID, req)
+	if err != nil {
+		if h.respondTransitionError(w, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "not_found",
+			Message: "Policy not found",
+		})
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"policyId": policyID,
+		"lapsedBy": middleware.GetUserID(r),
+	}).Info("Policy lapsed via API")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
+}
@@ -1,41 +0,0 @@
-package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"time"
-)
-
-// HealthHandler handles health check requests
-type HealthHandler struct{}
-
-// NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
-}
-
-// HealthResponse represents the health check response
-type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Service   string    `json:"service"`
-}
-
-// ErrorResponse represents an error response
-type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-}
-
-// ServeHTTP handles GET /healthz
-func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	response := HealthResponse{
-		Status:    "ok",
-		Timestamp: time.Now(),
-		Service:   "policy-service",
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxImportBytes is the largest upload an import accepts, file and mapping
+// together
+const MaxImportBytes = 32 << 20
+
+// importReportColumns is the header row of the CSV import report
+var importReportColumns = []string{"line", "policyNumber", "outcome", "policyId", "errors"}
+
+// PolicyImporter imports legacy policies from CSV files.
+// *services.ImportService is the production implementation.
+type PolicyImporter interface {
+	Start(data []byte, mapping *models.ImportMapping, opts models.ImportOptions) (*models.PolicyImport, error)
+	Get(importID string) (*models.PolicyImport, error)
+	Report(importID string) (*models.ImportReport, error)
+}
+
+var _ PolicyImporter = (*services.ImportService)(nil)
+
+// ImportHandler serves policy imports to staff
+type ImportHandler struct {
+	importer PolicyImporter
+	logger   *logrus.Logger
+}
+
+// NewImportHandler creates a new policy import handler
+func NewImportHandler(importer PolicyImporter, logger *logrus.Logger) *ImportHandler {
+	return &ImportHandler{
+		importer: importer,
+		logger:   logger,
+	}
+}
+
+// StartImport handles POST /admin/policies/import - imports policies from a
+// CSV file in the background. The body is multipart/form-data with:
+// - file: the CSV file, with a header row (required)
+// - mapping: the column mapping as JSON (optional; columns named after the policy fields by default)
+// Supports query parameters:
+// - dryRun: true to validate every row without creating policies
+func (h *ImportHandler) StartImport(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportBytes)
+	if err := r.ParseMultipartForm(MaxImportBytes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondError(w, http.StatusRequestEntityTooLarge, "too_large", fmt.Sprintf("Import files are limited to %d MB", MaxImportBytes>>20))
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, "bad_request", "Expected a multipart/form-data body with a file part")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "bad_request", "file is required")
+		return
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to read import file")
+		h.respondError(w, http.StatusBadRequest, "bad_request", "Failed to read the file")
+		return
+	}
+
+	mapping, err := readImportMapping(r)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	job, err := h.importer.Start(data, mapping, models.ImportOptions{
+		DryRun:    r.URL.Query().Get("dryRun") == "true",
+		FileName:  header.Filename,
+		StartedBy: middleware.GetUserID(r),
+	})
+	if err != nil {
+		if err.Error() == "customer-service not configured" {
+			h.respondError(w, http.StatusServiceUnavailable, "unavailable", "Policies cannot be imported while customer-service is not configured")
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, "bad_request", err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/admin/policies/import/"+job.ID)
+	h.respondJSON(w, http.StatusAccepted, job)
+}
+
+// GetImport handles GET /admin/policies/import/{id} - the progress of an
+// import
+func (h *ImportHandler) GetImport(w http.ResponseWriter, r *http.Request) {
+	job, err := h.importer.Get(mux.Vars(r)["id"])
+	if err != nil {
+		h.respondError(w, http.StatusNotFound, "not_found", "Import not found")
+		return
+	}
+	h.respondJSON(w, http.StatusOK, job)
+}
+
+// GetImportReport handles GET /admin/policies/import/{id}/report -
+// downloads the outcome of every row of a finished import
+// Supports query parameters:
+// - format: csv (default) or json
+func (h *ImportHandler) GetImportReport(w http.ResponseWriter, r *http.Request) {
+	importID := mux.Vars(r)["id"]
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "csv" && format != "json" {
+		h.respondError(w, http.StatusBadRequest, "bad_request", "format must be csv or json")
+		return
+	}
+
+	report, err := h.importer.Report(importID)
+	if err != nil {
+		if err.Error() == "import is still running" {
+			h.respondError(w, http.StatusConflict, "conflict", "The import is still running; its report is ready once it finishes")
+			return
+		}
+		h.respondError(w, http.StatusNotFound, "not_found", "Import not found")
+		return
+	}
+
+	if format == "json" {
+		h.respondJSON(w, http.StatusOK, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-report.csv"`, importID))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	writer.Write(importReportColumns)
+	for _, row := range report.Rows {
+		writer.Write([]string{
+			strconv.Itoa(row.Line),
+			row.PolicyNumber,
+			row.Outcome,
+			row.PolicyID,
+			strings.Join(row.Errors, "; "),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		h.logger.WithError(err).Error("Failed to write import report")
+	}
+}
+
+// readImportMapping reads the optional mapping part, sent as a form value
+// or a file
+func readImportMapping(r *http.Request) (*models.ImportMapping, error) {
+	data := []byte(r.FormValue("mapping"))
+	if file, _, err := r.FormFile("mapping"); err == nil {
+		data, err = io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the mapping")
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+
+	var mapping models.ImportMapping
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("invalid mapping: %v", err)
+	}
+	return &mapping, nil
+}
+
+// respondJSON sends a JSON response
+func (h *ImportHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error in the ErrorResponse shape
+func (h *ImportHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	h.respondJSON(w, status, ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
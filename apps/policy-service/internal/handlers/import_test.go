@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// stubImporter records the import it was asked to start and returns canned
+// results
+type stubImporter struct {
+	data    string
+	mapping *models.ImportMapping
+	opts    models.ImportOptions
+	report  *models.ImportReport
+	err     error
+}
+
+func (s *stubImporter) Start(data []byte, mapping *models.ImportMapping, opts models.ImportOptions) (*models.PolicyImport, error) {
+	s.data, s.mapping, s.opts = string(data), mapping, opts
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.PolicyImport{ID: "imp-001", Status: models.ImportRunning}, nil
+}
+
+func (s *stubImporter) Get(importID string) (*models.PolicyImport, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &s.report.Import, nil
+}
+
+func (s *stubImporter) Report(importID string) (*models.ImportReport, error) {
+	return s.report, s.err
+}
+
+// importRequest builds a multipart upload of file, with mapping when set
+func importRequest(t *testing.T, query, file, mapping string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "legacy.csv")
+	if err != nil {
+		t.Fatalf("Failed to build upload: %v", err)
+	}
+	part.Write([]byte(file))
+	if mapping != "" {
+		writer.WriteField("mapping", mapping)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/admin/policies/import"+query, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestStartImport(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	importer := &stubImporter{}
+	handler := NewImportHandler(importer, logger)
+	rec := httptest.NewRecorder()
+	handler.StartImport(rec, importRequest(t, "?dryRun=true", "policyNumber\nLEG-1\n", `{"columns": {"policyNumber": "POLICY_NO"}, "dateFormat": "DD/MM/YYYY"}`))
+
+	if rec.Code != http.StatusAccepted || rec.Header().Get("Location") != "/admin/policies/import/imp-001" {
+		t.Fatalf("Unexpected response: %d %s", rec.Code, rec.Body.String())
+	}
+	if importer.data != "policyNumber\nLEG-1\n" || !importer.opts.DryRun || importer.opts.FileName != "legacy.csv" {
+		t.Errorf("Unexpected import started: %q %+v", importer.data, importer.opts)
+	}
+	if importer.mapping == nil || importer.mapping.Columns["policyNumber"] != "POLICY_NO" || importer.mapping.DateFormat != "DD/MM/YYYY" {
+		t.Errorf("Unexpected mapping %+v", importer.mapping)
+	}
+
+	tests := []struct {
+		name       string
+		req        *http.Request
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"not multipart", httptest.NewRequest("POST", "/admin/policies/import", strings.NewReader("policyNumber\n")), nil, http.StatusBadRequest, "bad_request"},
+		{"mapping typo", importRequest(t, "", "policyNumber\n", `{"column": {}}`), nil, http.StatusBadRequest, "bad_request"},
+		{"unreadable file", importRequest(t, "", "", ""), errors.New("file is empty"), http.StatusBadRequest, "bad_request"},
+		{"no customer-service", importRequest(t, "", "policyNumber\n", ""), errors.New("customer-service not configured"), http.StatusServiceUnavailable, "unavailable"},
+		{"too large", importRequest(t, "", strings.Repeat("x", MaxImportBytes+1), ""), nil, http.StatusRequestEntityTooLarge, "too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewImportHandler(&stubImporter{err: tt.err}, logger)
+			rec := httptest.NewRecorder()
+			handler.StartImport(rec, tt.req)
+			if rec.Code != tt.wantStatus || !strings.Contains(rec.Body.String(), `"error":"`+tt.wantCode+`"`) {
+				t.Errorf("Got %d %s, want %d %s", rec.Code, rec.Body.String(), tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestGetImportReport(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	report := &models.ImportReport{
+		Import: models.PolicyImport{ID: "imp-001", Status: models.ImportDone},
+		Rows: []models.ImportRow{
+			{Line: 2, PolicyNumber: "LEG-1", Outcome: models.RowImported, PolicyID: "pol-007"},
+			{Line: 3, PolicyNumber: "LEG-2", Outcome: models.RowRejected, Errors: []string{"type is required", "customer cust-404 does not exist"}},
+		},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/policies/import/{id}/report", NewImportHandler(&stubImporter{report: report}, logger).GetImportReport)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/policies/import/imp-001/report", nil))
+	want := "line,policyNumber,outcome,policyId,errors\n" +
+		"2,LEG-1,imported,pol-007,\n" +
+		"3,LEG-2,rejected,,type is required; customer cust-404 does not exist\n"
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" || rec.Body.String() != want {
+		t.Errorf("Unexpected report: %d %s\n%s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+	}{
+		{"json", "?format=json", nil, http.StatusOK},
+		{"bad format", "?format=xlsx", nil, http.StatusBadRequest},
+		{"running", "", errors.New("import is still running"), http.StatusConflict},
+		{"missing", "", errors.New("import not found"), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := mux.NewRouter()
+			router.HandleFunc("/admin/policies/import/{id}/report", NewImportHandler(&stubImporter{report: report, err: tt.err}, logger).GetImportReport)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/policies/import/imp-001/report"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,35 +1,85 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
+// PolicyService is the business logic the policy handler depends on.
+// *services.PolicyService is the production implementation.
+type PolicyService interface {
+	GetPolicyByID(policyID string, customerID string) (*models.PolicyResponse, error)
+	GetPoliciesByCustomerID(customerID string) ([]models.PolicyResponse, error)
+	GetHouseholdPolicies(ctx context.Context, customerID string) ([]models.PolicyResponse, error)
+	CreatePolicy(ctx context.Context, customerID string, req models.CreatePolicyRequest) (*models.PolicyResponse, error)
+	UpdatePolicy(policyID string, customerID string, req models.UpdatePolicyRequest) (*models.PolicyResponse, error)
+	CancelPolicy(ctx context.Context, policyID string, customerID string, req models.CancelPolicyRequest) (*models.PolicyResponse, error)
+	ReinstatePolicy(policyID string, customerID string, req models.ReinstatePolicyRequest) (*models.PolicyResponse, error)
+	LapsePolicy(policyID string, req models.LapsePolicyRequest) (*models.PolicyResponse, error)
+	ListPolicies(filters models.PolicyFilters) []models.PolicyResponse
+	ListPoliciesPage(filters models.PolicyFilters, page, pageSize int) (*models.PolicyPage, error)
+	GetPolicyByNumber(policyNumber string) (*models.PolicyResponse, error)
+	SearchPolicyNumbers(query string, limit int) (*models.PolicyNumberSearch, error)
+}
+
+var _ PolicyService = (*services.PolicyService)(nil)
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
 // PolicyHandler handles policy-related requests
 type PolicyHandler struct {
-	policyService *services.PolicyService
+	policyService PolicyService
 	logger        *logrus.Logger
 }
 
 // NewPolicyHandler creates a new policy handler
-func NewPolicyHandler(policyService *services.PolicyService, logger *logrus.Logger) *PolicyHandler {
+func NewPolicyHandler(policyService PolicyService, logger *logrus.Logger) *PolicyHandler {
 	return &PolicyHandler{
 		policyService: policyService,
 		logger:        logger,
 	}
 }
 
-// GetPolicies handles GET /policies - returns all policies for current customer
+// GetPolicies handles GET /policies - returns all policies for current
+// customer, or with ?scope=household for everyone in their household
 func (h *PolicyHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
 	customerID := middleware.GetUserID(r)
 
-	policies, err := h.policyService.GetPoliciesByCustomerID(customerID)
+	var policies []models.PolicyResponse
+	var err error
+	switch scope := r.URL.Query().Get("scope"); scope {
+	case "", "customer":
+		policies, err = h.policyService.GetPoliciesByCustomerID(customerID)
+	case "household":
+		policies, err = h.policyService.GetHouseholdPolicies(r.Context(), customerID)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "bad_request",
+			Message: "Invalid scope. Must be one of: customer, household",
+		})
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).WithField("customerId", customerID).Error("Failed to get policies")
 		w.Header().Set("Content-Type", "application/json")
@@ -43,7 +93,7 @@ func (h *PolicyHandler) GetPolicies(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(policies)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policies))
 }
 
 // GetPolicyByID handles GET /policies/{id} - returns a specific policy
@@ -90,7 +140,7 @@ func (h *PolicyHandler) GetPolicyByID(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(policy)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
 }
 
 // CreatePolicy handles POST /policies - creates a new policy
@@ -131,7 +181,34 @@ func (h *PolicyHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	policy, err := h.policyService.CreatePolicy(customerID, req)
+	policy, err := h.policyService.CreatePolicy(r.Context(), customerID, req)
+	if err != nil && err.Error() == "email not verified" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "forbidden",
+			Message: "Verify your email address before taking out a policy",
+		})
+		return
+	}
+	if err != nil && err.Error() == "kyc not verified" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "forbidden",
+			Message: "Complete identity verification before taking out a policy",
+		})
+		return
+	}
+	if err != nil && strings.HasPrefix(err.Error(), "policy refused: ") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "policy_refused",
+			Message: strings.TrimPrefix(err.Error(), "policy refused: "),
+		})
+		return
+	}
 	if err != nil {
 		h.logger.WithError(err).WithField("customerId", customerID).Error("Failed to create policy")
 		w.Header().Set("Content-Type", "application/json")
@@ -145,7 +222,7 @@ func (h *PolicyHandler) CreatePolicy(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(policy)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
 }
 
 // UpdatePolicy handles PUT /policies/{id} - updates a policy
@@ -179,12 +256,12 @@ func (h *PolicyHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
 	// Validate status if provided
 	if req.Status != nil {
 		status := *req.Status
-		if status != "active" && status != "lapsed" && status != "cancelled" {
+		if !models.ValidatePolicyStatus(status) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ErrorResponse{
 				Error:   "bad_request",
-				Message: "Invalid status. Must be one of: active, lapsed, cancelled",
+				Message: "Invalid status. Must be one of: pending, active, grace, lapsed, cancelled, expired",
 			})
 			return
 		}
@@ -202,6 +279,10 @@ func (h *PolicyHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if h.respondTransitionError(w, err) {
+			return
+		}
+
 		h.logger.WithError(err).WithFields(logrus.Fields{
 			"customerId": customerID,
 			"policyId":   policyID,
@@ -218,10 +299,13 @@ func (h *PolicyHandler) UpdatePolicy(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(policy)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
 }
 
-// DeletePolicy handles DELETE /policies/{id} - cancels a policy
+// DeletePolicy handles DELETE /policies/{id} - cancels a policy, prorating
+// its premium. Optional query params: effectiveDate, reasonCode, reason,
+// refund. The route is wrapped in middleware.IdentifyRole: staff cancel on
+// behalf of the insurer, everyone else as the customer.
 func (h *PolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 	customerID := middleware.GetUserID(r)
 	vars := mux.Vars(r)
@@ -237,15 +321,19 @@ func (h *PolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create an update request to set status to cancelled
-	cancelled := "cancelled"
-	req := models.UpdatePolicyRequest{
-		Status: &cancelled,
+	req, err := parseCancelRequest(r.URL.Query())
+	if err != nil {
+		h.respondAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if role := middleware.GetRole(r); role == "admin" || role == "adjuster" {
+		req.InitiatedBy = models.InitiatedByInsurer
 	}
 
-	policy, err := h.policyService.UpdatePolicy(policyID, customerID, req)
+	policy, err := h.policyService.CancelPolicy(r.Context(), policyID, customerID, req)
 	if err != nil {
-		if err.Error() == "unauthorized" {
+		switch err.Error() {
+		case "unauthorized":
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusForbidden)
 			json.NewEncoder(w).Encode(ErrorResponse{
@@ -253,6 +341,41 @@ func (h *PolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 				Message: "You do not have access to this policy",
 			})
 			return
+		case "policy already cancelled":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "conflict",
+				Message: "Policy is already cancelled",
+			})
+			return
+		case "cancellation already pending":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "conflict",
+				Message: "Policy already has a pending cancellation",
+			})
+			return
+		case "refunds unavailable":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "unavailable",
+				Message: "Refunds are not available; cancel without refund=true or retry later",
+			})
+			return
+		case "cancellation date must be before the policy end date", "policy has no term to prorate":
+			h.respondAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if strings.HasPrefix(err.Error(), "reasonCode") || strings.HasPrefix(err.Error(), "effectiveDate") {
+			h.respondAdminError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if h.respondTransitionError(w, err) {
+			return
 		}
 
 		h.logger.WithError(err).WithFields(logrus.Fields{
@@ -271,5 +394,50 @@ func (h *PolicyHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(policy)
+	json.NewEncoder(w).Encode(shaping.Apply(r, policy))
+}
+
+// parseCancelRequest reads the optional cancellation query params
+func parseCancelRequest(query url.Values) (models.CancelPolicyRequest, error) {
+	req := models.CancelPolicyRequest{ReasonCode: query.Get("reasonCode"), Reason: query.Get("reason")}
+
+	if req.ReasonCode != "" && !models.ValidateCancelReason(req.ReasonCode) {
+		return req, fmt.Errorf("reasonCode must be one of: customer_request, replaced, non_payment, underwriting, fraud, other")
+	}
+
+	if value := query.Get("effectiveDate"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", value); err != nil {
+				return req, fmt.Errorf("effectiveDate must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+			}
+		}
+		req.EffectiveDate = &t
+	}
+
+	if value := query.Get("refund"); value != "" {
+		refund, err := strconv.ParseBool(value)
+		if err != nil {
+			return req, fmt.Errorf("refund must be true or false")
+		}
+		req.Refund = refund
+	}
+
+	return req, nil
+}
+
+// respondTransitionError writes 409 Conflict when err is a status change the
+// policy lifecycle refused, and reports whether it was
+func (h *PolicyHandler) respondTransitionError(w http.ResponseWriter, err error) bool {
+	var transitionErr *lifecycle.TransitionError
+	if !errors.As(err, &transitionErr) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "conflict",
+		Message: err.Error(),
+	})
+	return true
 }
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// stubPolicyService returns canned results so handler tests cover only the
+// HTTP mapping
+type stubPolicyService struct {
+	policy *models.PolicyResponse
+	err    error
+}
+
+func (s *stubPolicyService) GetPolicyByID(policyID string, customerID string) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) GetPoliciesByCustomerID(customerID string) ([]models.PolicyResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []models.PolicyResponse{*s.policy}, nil
+}
+
+func (s *stubPolicyService) GetHouseholdPolicies(ctx context.Context, customerID string) ([]models.PolicyResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []models.PolicyResponse{*s.policy, *s.policy}, nil
+}
+
+func (s *stubPolicyService) CreatePolicy(ctx context.Context, customerID string, req models.CreatePolicyRequest) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) UpdatePolicy(policyID string, customerID string, req models.UpdatePolicyRequest) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) CancelPolicy(ctx context.Context, policyID string, customerID string, req models.CancelPolicyRequest) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) ReinstatePolicy(policyID string, customerID string, req models.ReinstatePolicyRequest) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) LapsePolicy(policyID string, req models.LapsePolicyRequest) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) ListPolicies(filters models.PolicyFilters) []models.PolicyResponse {
+	return []models.PolicyResponse{*s.policy}
+}
+
+func (s *stubPolicyService) ListPoliciesPage(filters models.PolicyFilters, page, pageSize int) (*models.PolicyPage, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.PolicyPage{Data: []models.PolicyResponse{*s.policy}, Total: 1, Page: page, PageSize: pageSize}, nil
+}
+
+func (s *stubPolicyService) GetPolicyByNumber(policyNumber string) (*models.PolicyResponse, error) {
+	return s.policy, s.err
+}
+
+func (s *stubPolicyService) SearchPolicyNumbers(query string, limit int) (*models.PolicyNumberSearch, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.PolicyNumberSearch{Query: query, Matches: []models.PolicyNumberMatch{{Match: "exact", Policy: *s.policy}}}, nil
+}
+
+func TestGetPolicyByIDStatusMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{"found", nil, http.StatusOK, ""},
+		{"unauthorized", errors.New("unauthorized"), http.StatusForbidden, "forbidden"},
+		{"missing", errors.New("policy not found"), http.StatusNotFound, "not_found"},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubPolicyService{policy: &models.PolicyResponse{ID: "pol-001"}, err: tt.err}
+			handler := NewPolicyHandler(service, logger)
+
+			req := mux.SetURLVars(httptest.NewRequest("GET", "/policies/pol-001", nil), map[string]string{"id": "pol-001"})
+			rec := httptest.NewRecorder()
+			handler.GetPolicyByID(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var body ErrorResponse
+				json.NewDecoder(rec.Body).Decode(&body)
+				if body.Error != tt.wantError {
+					t.Errorf("Error code mismatch: got %q, want %q", body.Error, tt.wantError)
+				}
+			}
+		})
+	}
+}
+
+func TestDeletePolicyStatusMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{"cancelled", "?effectiveDate=2024-07-01&refund=true&reason=moved", nil, http.StatusOK, ""},
+		{"bad date", "?effectiveDate=tomorrow", nil, http.StatusBadRequest, "bad_request"},
+		{"bad refund", "?refund=maybe", nil, http.StatusBadRequest, "bad_request"},
+		{"unknown reason code", "?reasonCode=bored", nil, http.StatusBadRequest, "bad_request"},
+		{"insurer reason code", "?reasonCode=fraud", errors.New("reasonCode fraud cannot be given by the customer"), http.StatusBadRequest, "bad_request"},
+		{"short notice", "?effectiveDate=2030-01-01", errors.New("effectiveDate must give 10 days notice (on or after 2030-01-05)"), http.StatusBadRequest, "bad_request"},
+		{"pending cancellation", "", errors.New("cancellation already pending"), http.StatusConflict, "conflict"},
+		{"unauthorized", "", errors.New("unauthorized"), http.StatusForbidden, "forbidden"},
+		{"already cancelled", "", errors.New("policy already cancelled"), http.StatusConflict, "conflict"},
+		{"refunds unavailable", "?refund=true", errors.New("refunds unavailable"), http.StatusServiceUnavailable, "unavailable"},
+		{"after term", "?effectiveDate=2030-01-01", errors.New("cancellation date must be before the policy end date"), http.StatusBadRequest, "bad_request"},
+		{"lapsed", "", &lifecycle.TransitionError{PolicyID: "pol-001", From: "lapsed", To: "cancelled"}, http.StatusConflict, "conflict"},
+		{"missing", "", errors.New("policy not found"), http.StatusNotFound, "not_found"},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubPolicyService{policy: &models.PolicyResponse{ID: "pol-001"}, err: tt.err}
+			handler := NewPolicyHandler(service, logger)
+
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "/policies/pol-001"+tt.query, nil), map[string]string{"id": "pol-001"})
+			rec := httptest.NewRecorder()
+			handler.DeletePolicy(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var body ErrorResponse
+				json.NewDecoder(rec.Body).Decode(&body)
+				if body.Error != tt.wantError {
+					t.Errorf("Error code mismatch: got %q, want %q", body.Error, tt.wantError)
+				}
+			}
+		})
+	}
+}
+
+func TestCreatePolicyValidation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewPolicyHandler(&stubPolicyService{policy: &models.PolicyResponse{ID: "pol-004"}}, logger)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{"valid", `{"policyNumber":"AUTO-1","type":"auto","premium":900}`, http.StatusCreated},
+		{"missing premium", `{"policyNumber":"AUTO-1","type":"auto"}`, http.StatusBadRequest},
+		{"unknown type", `{"policyNumber":"BOAT-1","type":"boat","premium":900}`, http.StatusBadRequest},
+		{"malformed", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.CreatePolicy(rec, httptest.NewRequest("POST", "/policies", strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCreatePolicyRefusedByRule(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewPolicyHandler(&stubPolicyService{err: errors.New("policy refused: The deductible must be below the coverage")}, logger)
+
+	rec := httptest.NewRecorder()
+	handler.CreatePolicy(rec, httptest.NewRequest("POST", "/policies", strings.NewReader(`{"policyNumber":"AUTO-1","type":"auto","premium":900,"coverage":500,"deductible":1000}`)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Status mismatch: got %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+	var body ErrorResponse
+	json.NewDecoder(rec.Body).Decode(&body)
+	if body.Error != "policy_refused" || body.Message != "The deductible must be below the coverage" {
+		t.Errorf("Expected the rule's reason, got %+v", body)
+	}
+}
+
+func TestListAllPoliciesQueryValidation(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewPolicyHandler(&stubPolicyService{policy: &models.PolicyResponse{ID: "pol-001"}}, logger)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{"no filters", "", http.StatusOK},
+		{"all filters", "?type=home&status=active&customerId=cust-001&expiringBefore=2025-01-01&page=2&pageSize=10", http.StatusOK},
+		{"unknown type", "?type=boat", http.StatusBadRequest},
+		{"unknown status", "?status=suspended", http.StatusBadRequest},
+		{"bad date", "?expiringBefore=next-week", http.StatusBadRequest},
+		{"bad page", "?page=two", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ListAllPolicies(rec, httptest.NewRequest("GET", "/admin/policies"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestExportPoliciesWritesCSV(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := &stubPolicyService{policy: &models.PolicyResponse{ID: "pol-001", PolicyNumber: "AUTO-001", Type: "auto", Premium: 1250.5, Coverage: "***.**"}}
+	handler := NewPolicyHandler(service, logger)
+
+	rec := httptest.NewRecorder()
+	handler.ExportPolicies(rec, httptest.NewRequest("GET", "/admin/policies/export", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("Unexpected response: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "id,policyNumber,") {
+		t.Fatalf("Unexpected CSV: %q", rec.Body.String())
+	}
+	if !strings.HasPrefix(lines[1], "pol-001,AUTO-001,,auto,,1250.5,***.**,") {
+		t.Errorf("Unexpected row: %q", lines[1])
+	}
+}
+
+func TestGetPolicyByNumberStatusMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{"found", nil, http.StatusOK, ""},
+		{"missing", errors.New("policy not found"), http.StatusNotFound, "not_found"},
+		{"shared", errors.New("policy number AUTO-001 is used by 2 policies"), http.StatusConflict, "conflict"},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &stubPolicyService{policy: &models.PolicyResponse{ID: "pol-001", PolicyNumber: "AUTO-001"}, err: tt.err}
+			handler := NewPolicyHandler(service, logger)
+
+			req := mux.SetURLVars(httptest.NewRequest("GET", "/admin/policies/by-number/AUTO-001", nil), map[string]string{"policyNumber": "AUTO-001"})
+			rec := httptest.NewRecorder()
+			handler.GetPolicyByNumber(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var body ErrorResponse
+				json.NewDecoder(rec.Body).Decode(&body)
+				if body.Error != tt.wantError {
+					t.Errorf("Error code mismatch: got %q, want %q", body.Error, tt.wantError)
+				}
+			}
+		})
+	}
+
+	handler := NewPolicyHandler(&stubPolicyService{policy: &models.PolicyResponse{ID: "pol-001"}}, logger)
+	rec := httptest.NewRecorder()
+	handler.SearchPolicyNumbers(rec, httptest.NewRequest("GET", "/admin/policies/by-number?q=AUTO&limit=many", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Invalid limit: got status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
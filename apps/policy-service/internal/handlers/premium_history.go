@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// PremiumHistoryReader returns customers' premium histories.
+// *services.PremiumHistoryService is the production implementation.
+type PremiumHistoryReader interface {
+	GetPremiumHistory(customerID string) (*models.PremiumHistory, error)
+}
+
+var _ PremiumHistoryReader = (*services.PremiumHistoryService)(nil)
+
+// PremiumHistoryHandler serves customers' premium histories. The route
+// must be wrapped in middleware.IdentifyRole so staff can be told apart
+// from customers.
+type PremiumHistoryHandler struct {
+	reader PremiumHistoryReader
+	logger *logrus.Logger
+}
+
+// NewPremiumHistoryHandler creates a new premium history handler
+func NewPremiumHistoryHandler(reader PremiumHistoryReader, logger *logrus.Logger) *PremiumHistoryHandler {
+	return &PremiumHistoryHandler{
+		reader: reader,
+		logger: logger,
+	}
+}
+
+// ServeHTTP handles GET /customers/{id}/premium-history. Staff see any
+// customer's history; customers only their own.
+func (h *PremiumHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	if role := middleware.GetRole(r); role != "admin" && role != "adjuster" && middleware.GetUserID(r) != customerID {
+		h.respondError(w, http.StatusForbidden, "forbidden", "You do not have access to this customer's premium history")
+		return
+	}
+
+	history, err := h.reader.GetPremiumHistory(customerID)
+	if err != nil {
+		h.logger.WithError(err).WithField("customerId", customerID).Error("Failed to get premium history")
+		h.respondError(w, http.StatusInternalServerError, "internal_error", "Failed to retrieve premium history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(shaping.Apply(r, history)); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError sends an error response
+func (h *PremiumHistoryHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: code, Message: message}); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
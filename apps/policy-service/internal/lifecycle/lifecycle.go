@@ -0,0 +1,377 @@
+// Package lifecycle defines the policy status state machine: the moves
+// allowed between statuses, the dates a move must agree with, the fields a
+// status sets when it is entered, and hooks that run once a move has been
+// saved. Due works out which dated change a policy has reached, so a
+// scheduled pass can keep statuses in line with the policy dates. Refused
+// moves are reported as *TransitionError.
+package lifecycle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+)
+
+// Triggers record what caused a status change
+const (
+	TriggerSchedule  = "schedule"  // a date on the policy was reached
+	TriggerRequest   = "request"   // a status change requested through the API
+	TriggerReinstate = "reinstate" // a policy in grace was reinstated
+	TriggerCancel    = "cancel"    // the policy was cancelled
+	// TriggerNonPayment lapses a policy whose premium was taken back, such
+	// as by a chargeback the insurer lost
+	TriggerNonPayment = "non_payment"
+)
+
+// Change is a request to move a policy to another status
+type Change struct {
+	To        string
+	Trigger   string
+	Effective time.Time // when the change took effect; defaults to At
+	At        time.Time // when the change was made; defaults to now
+	// GraceEndsAt is when grace runs out, for changes into grace or lapsed
+	// on a policy that has no graceEndsAt yet
+	GraceEndsAt time.Time
+	// Reason explains a lapse for non-payment
+	Reason string
+}
+
+// TransitionError reports a status change the lifecycle refused. Reason is
+// set when the move is allowed in general but the policy dates do not
+// agree with it.
+type TransitionError struct {
+	PolicyID string
+	From     string
+	To       string
+	Reason   string
+}
+
+func (e *TransitionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("policy %s cannot move from %s to %s: %s", e.PolicyID, e.From, e.To, e.Reason)
+	}
+	return fmt.Sprintf("policy %s cannot move from %s to %s", e.PolicyID, e.From, e.To)
+}
+
+// Transition allows a policy to move From one status To another. Guard,
+// when set, returns why the move is refused for a policy, or "".
+type Transition struct {
+	From  string
+	To    string
+	Guard func(policy *models.Policy, change *Change) string
+}
+
+// Enter sets the policy fields that come with entering a status
+type Enter func(policy *models.Policy, change *Change)
+
+// Hook runs after a status change has been saved. from is the status the
+// policy left.
+type Hook func(policy *models.Policy, from string, change Change)
+
+// Machine applies status changes to policies according to a declared set
+// of transitions. Hooks are registered while the service is set up and are
+// not safe to add concurrently with Fire.
+type Machine struct {
+	transitions map[string]map[string]Transition
+	enter       map[string]Enter
+	hooks       []Hook
+}
+
+// New creates a machine from its transitions and the fields each status
+// sets on entry
+func New(transitions []Transition, enter map[string]Enter) *Machine {
+	m := &Machine{
+		transitions: make(map[string]map[string]Transition),
+		enter:       enter,
+	}
+	for _, t := range transitions {
+		if m.transitions[t.From] == nil {
+			m.transitions[t.From] = make(map[string]Transition)
+		}
+		m.transitions[t.From][t.To] = t
+	}
+	return m
+}
+
+// Policies returns the policy lifecycle. A policy is pending until its
+// start date and active during its term. At the end of the term it expires
+// if it is not renewing, and otherwise enters grace until the renewal
+// premium is paid and it is reinstated, or grace runs out and it lapses.
+// A policy in force whose premium is taken back lapses straight away.
+// Any policy still in force can be cancelled; an active policy cancelled
+// from a later date is pending cancellation until then. Lapsed, cancelled
+// and expired policies are final.
+func Policies() *Machine {
+	return New([]Transition{
+		{From: models.StatusPending, To: models.StatusActive, Guard: inTerm},
+		{From: models.StatusPending, To: models.StatusCancelled},
+		{From: models.StatusActive, To: models.StatusGrace, Guard: enteringGrace},
+		{From: models.StatusActive, To: models.StatusLapsed, Guard: lapsing},
+		{From: models.StatusActive, To: models.StatusExpired, Guard: expiring},
+		{From: models.StatusActive, To: models.StatusCancelled},
+		{From: models.StatusActive, To: models.StatusPendingCancellation, Guard: cancellingLater},
+		{From: models.StatusGrace, To: models.StatusActive, Guard: reinstatable},
+		{From: models.StatusGrace, To: models.StatusLapsed, Guard: lapsing},
+		{From: models.StatusGrace, To: models.StatusExpired, Guard: expiring},
+		{From: models.StatusGrace, To: models.StatusCancelled},
+		{From: models.StatusPendingCancellation, To: models.StatusCancelled, Guard: cancellationDue},
+	}, map[string]Enter{
+		models.StatusActive:    clearGrace,
+		models.StatusGrace:     enterGrace,
+		models.StatusLapsed:    enterLapsed,
+		models.StatusExpired:   clearGrace,
+		models.StatusCancelled: enterCancelled,
+	})
+}
+
+// Initial returns the status a policy starting at start is created in
+func Initial(start, now time.Time) string {
+	if now.Before(start) {
+		return models.StatusPending
+	}
+	return models.StatusActive
+}
+
+// Due returns the change the policy dates call for as of now, if any. A
+// policy past its end date enters grace for gracePeriod, or lapses straight
+// away if that has already run out. Changes are dated when they took
+// effect rather than when they are noticed, so applying Due repeatedly
+// brings a policy up to date however long it was left. A pending
+// cancellation takes effect at its effective date.
+func Due(policy *models.Policy, now time.Time, gracePeriod time.Duration) (Change, bool) {
+	change := Change{Trigger: TriggerSchedule, At: now}
+	switch policy.Status {
+	case models.StatusPending:
+		if now.Before(policy.StartDate) {
+			return change, false
+		}
+		change.To, change.Effective = models.StatusActive, policy.StartDate
+	case models.StatusActive, models.StatusGrace:
+		if now.Before(policy.EndDate) {
+			return change, false
+		}
+		graceEnds := policy.EndDate.Add(gracePeriod)
+		if policy.GraceEndsAt != nil {
+			graceEnds = *policy.GraceEndsAt
+		}
+		switch {
+		case policy.NonRenewing:
+			change.To, change.Effective = models.StatusExpired, policy.EndDate
+		case !now.Before(graceEnds):
+			change.To, change.Effective, change.GraceEndsAt = models.StatusLapsed, graceEnds, graceEnds
+		case policy.Status == models.StatusActive:
+			change.To, change.Effective, change.GraceEndsAt = models.StatusGrace, policy.EndDate, graceEnds
+		default:
+			return change, false
+		}
+	case models.StatusPendingCancellation:
+		if policy.Cancellation == nil || now.Before(policy.Cancellation.EffectiveDate) {
+			return change, false
+		}
+		change.To, change.Effective = models.StatusCancelled, policy.Cancellation.EffectiveDate
+	default:
+		return change, false
+	}
+	return change, true
+}
+
+// OnTransition registers a hook to run after every saved status change
+func (m *Machine) OnTransition(hook Hook) {
+	m.hooks = append(m.hooks, hook)
+}
+
+// Check reports whether Fire would accept change for policy, without
+// changing it. Callers use it before side effects that must not happen
+// for a refused change.
+func (m *Machine) Check(policy *models.Policy, change Change) error {
+	_, err := m.transition(policy, &change)
+	return err
+}
+
+// Fire moves policy to change.To, then calls save and, if it succeeds, the
+// hooks. A refused change returns a *TransitionError and leaves policy
+// untouched; a failed save returns the save error with policy already
+// updated, so callers should pass a copy they can discard.
+func (m *Machine) Fire(policy *models.Policy, change Change, save func(policy *models.Policy) error) error {
+	from := policy.Status
+	if _, err := m.transition(policy, &change); err != nil {
+		return err
+	}
+
+	if enter := m.enter[change.To]; enter != nil {
+		enter(policy, &change)
+	}
+	policy.Status = change.To
+	policy.UpdatedAt = change.At
+
+	if err := save(policy); err != nil {
+		return err
+	}
+	for _, hook := range m.hooks {
+		hook(policy, from, change)
+	}
+	return nil
+}
+
+// transition looks up and guards the move change asks for, filling in its
+// default times
+func (m *Machine) transition(policy *models.Policy, change *Change) (Transition, error) {
+	transition, ok := m.transitions[policy.Status][change.To]
+	if !ok {
+		return transition, &TransitionError{PolicyID: policy.ID, From: policy.Status, To: change.To}
+	}
+
+	if change.At.IsZero() {
+		change.At = time.Now()
+	}
+	if change.Effective.IsZero() {
+		change.Effective = change.At
+	}
+	if transition.Guard != nil {
+		if reason := transition.Guard(policy, change); reason != "" {
+			return transition, &TransitionError{PolicyID: policy.ID, From: policy.Status, To: change.To, Reason: reason}
+		}
+	}
+	return transition, nil
+}
+
+// inTerm only lets a policy be active between its start and end dates
+func inTerm(policy *models.Policy, change *Change) string {
+	if change.Effective.Before(policy.StartDate) {
+		return "policy does not start until " + policy.StartDate.Format("2006-01-02")
+	}
+	if !change.Effective.Before(policy.EndDate) {
+		return "policy term ended on " + policy.EndDate.Format("2006-01-02")
+	}
+	return ""
+}
+
+// termEnded refuses moves that need the policy term to be over
+func termEnded(policy *models.Policy, change *Change) string {
+	if change.Effective.Before(policy.EndDate) {
+		return "policy term runs until " + policy.EndDate.Format("2006-01-02")
+	}
+	return ""
+}
+
+// enteringGrace needs the term to be over and the end of grace to be known
+func enteringGrace(policy *models.Policy, change *Change) string {
+	if reason := termEnded(policy, change); reason != "" {
+		return reason
+	}
+	if policy.NonRenewing {
+		return "policy is not renewing"
+	}
+	if change.GraceEndsAt.IsZero() {
+		return "the end of the grace period is required"
+	}
+	return ""
+}
+
+// graceOver only lets a policy lapse once its grace period has run out
+func graceOver(policy *models.Policy, change *Change) string {
+	graceEnds := graceEnd(policy, change)
+	if graceEnds.IsZero() {
+		return "policy has not been in grace"
+	}
+	if change.Effective.Before(graceEnds) {
+		return "policy is in grace until " + graceEnds.Format("2006-01-02")
+	}
+	return ""
+}
+
+// lapsing lets a policy lapse for non-payment at any time, as long as the
+// reason is given, and otherwise once grace has run out
+func lapsing(policy *models.Policy, change *Change) string {
+	if change.Trigger != TriggerNonPayment {
+		return graceOver(policy, change)
+	}
+	if change.Reason == "" {
+		return "a reason is required to lapse a policy for non-payment"
+	}
+	return ""
+}
+
+// expiring only lets a policy that is not renewing expire, at the end of
+// its term
+func expiring(policy *models.Policy, change *Change) string {
+	if !policy.NonRenewing {
+		return "policy renews at the end of its term"
+	}
+	return termEnded(policy, change)
+}
+
+// reinstatable lets a policy in grace back into force while grace lasts,
+// once its term has been extended
+func reinstatable(policy *models.Policy, change *Change) string {
+	if policy.GraceEndsAt != nil && !change.Effective.Before(*policy.GraceEndsAt) {
+		return "grace period has ended"
+	}
+	return inTerm(policy, change)
+}
+
+// cancellingLater only lets a cancellation be pending when it is requested
+// through a cancellation and takes effect after it is made
+func cancellingLater(policy *models.Policy, change *Change) string {
+	if change.Trigger != TriggerCancel {
+		return "a pending cancellation must be requested by cancelling the policy"
+	}
+	if !change.Effective.After(change.At) {
+		return "a pending cancellation must take effect after it is requested"
+	}
+	return ""
+}
+
+// cancellationDue holds a pending cancellation until its effective date
+func cancellationDue(policy *models.Policy, change *Change) string {
+	if policy.Cancellation != nil && change.Effective.Before(policy.Cancellation.EffectiveDate) {
+		return "cancellation takes effect on " + policy.Cancellation.EffectiveDate.Format("2006-01-02")
+	}
+	return ""
+}
+
+// graceEnd returns when the policy's grace runs out, or the zero time if
+// that is not known
+func graceEnd(policy *models.Policy, change *Change) time.Time {
+	if policy.GraceEndsAt != nil {
+		return *policy.GraceEndsAt
+	}
+	return change.GraceEndsAt
+}
+
+// clearGrace drops the end of grace from a policy leaving grace without
+// lapsing
+func clearGrace(policy *models.Policy, change *Change) {
+	policy.GraceEndsAt = nil
+}
+
+// enterGrace records when grace runs out
+func enterGrace(policy *models.Policy, change *Change) {
+	graceEnds := change.GraceEndsAt
+	policy.GraceEndsAt = &graceEnds
+}
+
+// enterLapsed dates the lapse at the end of grace, or when the premium was
+// taken back for a lapse for non-payment
+func enterLapsed(policy *models.Policy, change *Change) {
+	lapsed := graceEnd(policy, change)
+	if change.Trigger == TriggerNonPayment {
+		lapsed = change.Effective
+		policy.LapseReason = change.Reason
+	}
+	policy.GraceEndsAt = nil
+	policy.LapsedAt = &lapsed
+}
+
+// enterCancelled records when a cancellation took effect. The cancellation
+// is copied so the policy the change started from is left as it was.
+func enterCancelled(policy *models.Policy, change *Change) {
+	if policy.Cancellation == nil || policy.Cancellation.CancelledAt != nil {
+		return
+	}
+	cancellation := *policy.Cancellation
+	cancelledAt := change.At
+	cancellation.CancelledAt = &cancelledAt
+	policy.Cancellation = &cancellation
+}
@@ -0,0 +1,172 @@
+package lifecycle
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+)
+
+func saveOK(*models.Policy) error { return nil }
+
+var (
+	start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func policyIn(status string) *models.Policy {
+	return &models.Policy{ID: "pol-001", Status: status, StartDate: start, EndDate: end}
+}
+
+// cancellingOn returns a policy pending cancellation at effective
+func cancellingOn(effective time.Time) *models.Policy {
+	policy := policyIn(models.StatusPendingCancellation)
+	policy.Cancellation = &models.Cancellation{EffectiveDate: effective, ReasonCode: models.CancelReasonNonPayment}
+	return policy
+}
+
+func TestPolicyTransitions(t *testing.T) {
+	midTerm := start.AddDate(0, 6, 0)
+	afterEnd := end.AddDate(0, 0, 10)
+	graceEnds := end.AddDate(0, 0, 30)
+
+	tests := []struct {
+		name       string
+		policy     *models.Policy
+		change     Change
+		wantReason string
+		wantErr    bool
+	}{
+		{"activate in term", policyIn(models.StatusPending), Change{To: models.StatusActive, At: midTerm}, "", false},
+		{"activate early", policyIn(models.StatusPending), Change{To: models.StatusActive, At: start.AddDate(0, 0, -1)}, "policy does not start until 2024-01-01", true},
+		{"activate after term", policyIn(models.StatusPending), Change{To: models.StatusActive, At: afterEnd}, "policy term ended on 2025-01-01", true},
+		{"grace in term", policyIn(models.StatusActive), Change{To: models.StatusGrace, At: midTerm, GraceEndsAt: graceEnds}, "policy term runs until 2025-01-01", true},
+		{"grace without end", policyIn(models.StatusActive), Change{To: models.StatusGrace, At: afterEnd}, "the end of the grace period is required", true},
+		{"grace", policyIn(models.StatusActive), Change{To: models.StatusGrace, At: afterEnd, GraceEndsAt: graceEnds}, "", false},
+		{"lapse never in grace", policyIn(models.StatusActive), Change{To: models.StatusLapsed, At: afterEnd}, "policy has not been in grace", true},
+		{"lapse during grace", &models.Policy{ID: "pol-001", Status: models.StatusGrace, StartDate: start, EndDate: end, GraceEndsAt: &graceEnds}, Change{To: models.StatusLapsed, At: afterEnd}, "policy is in grace until 2025-01-31", true},
+		{"expire renewing", policyIn(models.StatusActive), Change{To: models.StatusExpired, At: afterEnd}, "policy renews at the end of its term", true},
+		{"cancel pending", policyIn(models.StatusPending), Change{To: models.StatusCancelled}, "", false},
+		{"cancel lapsed", policyIn(models.StatusLapsed), Change{To: models.StatusCancelled}, "", true},
+		{"cancel later", policyIn(models.StatusActive), Change{To: models.StatusPendingCancellation, Trigger: TriggerCancel, At: midTerm, Effective: midTerm.AddDate(0, 0, 10)}, "", false},
+		{"cancel later by request", policyIn(models.StatusActive), Change{To: models.StatusPendingCancellation, Trigger: TriggerRequest, At: midTerm, Effective: midTerm.AddDate(0, 0, 10)}, "a pending cancellation must be requested by cancelling the policy", true},
+		{"cancel later now", policyIn(models.StatusActive), Change{To: models.StatusPendingCancellation, Trigger: TriggerCancel, At: midTerm}, "a pending cancellation must take effect after it is requested", true},
+		{"pending cancellation early", cancellingOn(midTerm), Change{To: models.StatusCancelled, At: midTerm.AddDate(0, 0, -1)}, "cancellation takes effect on 2024-07-01", true},
+		{"pending cancellation due", cancellingOn(midTerm), Change{To: models.StatusCancelled, At: midTerm}, "", false},
+		{"reactivate expired", policyIn(models.StatusExpired), Change{To: models.StatusActive, At: midTerm}, "", true},
+		{"unknown status", policyIn(models.StatusActive), Change{To: "suspended"}, "", true},
+	}
+
+	machine := Policies()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := tt.policy.Status
+			err := machine.Fire(tt.policy, tt.change, saveOK)
+			if !tt.wantErr {
+				if err != nil || tt.policy.Status != tt.change.To {
+					t.Errorf("Fire: got status %s, %v", tt.policy.Status, err)
+				}
+				return
+			}
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) || transitionErr.Reason != tt.wantReason {
+				t.Errorf("Fire: got %v, want reason %q", err, tt.wantReason)
+			}
+			if tt.policy.Status != from {
+				t.Errorf("Refused change moved policy to %s", tt.policy.Status)
+			}
+		})
+	}
+}
+
+func TestDue(t *testing.T) {
+	period := 30 * 24 * time.Hour
+	graceEnds := end.Add(period)
+	nonRenewing := policyIn(models.StatusGrace)
+	nonRenewing.NonRenewing = true
+
+	tests := []struct {
+		name          string
+		policy        *models.Policy
+		now           time.Time
+		wantTo        string
+		wantEffective time.Time
+	}{
+		{"pending before start", policyIn(models.StatusPending), start.AddDate(0, 0, -1), "", time.Time{}},
+		{"pending after start", policyIn(models.StatusPending), start.AddDate(0, 0, 1), models.StatusActive, start},
+		{"active in term", policyIn(models.StatusActive), end.AddDate(0, 0, -1), "", time.Time{}},
+		{"active past end", policyIn(models.StatusActive), end.AddDate(0, 0, 1), models.StatusGrace, end},
+		{"active past grace", policyIn(models.StatusActive), graceEnds.AddDate(0, 0, 1), models.StatusLapsed, graceEnds},
+		{"not renewing", nonRenewing, end.AddDate(0, 0, 1), models.StatusExpired, end},
+		{"cancelled", policyIn(models.StatusCancelled), graceEnds.AddDate(0, 0, 1), "", time.Time{}},
+		{"cancellation pending", cancellingOn(end.AddDate(0, -1, 0)), end.AddDate(0, -2, 0), "", time.Time{}},
+		{"cancellation due", cancellingOn(end.AddDate(0, -1, 0)), end.AddDate(0, 0, 1), models.StatusCancelled, end.AddDate(0, -1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change, ok := Due(tt.policy, tt.now, period)
+			if ok != (tt.wantTo != "") || change.To != tt.wantTo {
+				t.Fatalf("Due: got %q, %v; want %q", change.To, ok, tt.wantTo)
+			}
+			if ok && !change.Effective.Equal(tt.wantEffective) {
+				t.Errorf("Effective: got %v, want %v", change.Effective, tt.wantEffective)
+			}
+		})
+	}
+}
+
+func TestFireSetsDatedFields(t *testing.T) {
+	machine := Policies()
+	now := end.AddDate(0, 2, 0)
+	graceEnds := end.AddDate(0, 1, 0)
+
+	policy := policyIn(models.StatusActive)
+	if err := machine.Fire(policy, Change{To: models.StatusLapsed, At: now, Effective: graceEnds, GraceEndsAt: graceEnds}, saveOK); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	if policy.LapsedAt == nil || !policy.LapsedAt.Equal(graceEnds) || policy.GraceEndsAt != nil || !policy.UpdatedAt.Equal(now) {
+		t.Errorf("Lapse should be dated at the end of grace: %+v", policy)
+	}
+}
+
+func TestFireDatesPendingCancellation(t *testing.T) {
+	machine := Policies()
+	effective := start.AddDate(0, 6, 0)
+	pending := cancellingOn(effective)
+
+	cancelled := *pending
+	if err := machine.Fire(&cancelled, Change{To: models.StatusCancelled, At: effective.AddDate(0, 0, 2), Effective: effective}, saveOK); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+	if c := cancelled.Cancellation; c.CancelledAt == nil || !c.CancelledAt.Equal(effective.AddDate(0, 0, 2)) {
+		t.Errorf("Cancellation should record when it took effect: %+v", c)
+	}
+	if pending.Cancellation.CancelledAt != nil {
+		t.Errorf("The policy the change started from was modified: %+v", pending.Cancellation)
+	}
+}
+
+func TestFireRunsHooksAfterSave(t *testing.T) {
+	machine := Policies()
+	var calls []string
+	machine.OnTransition(func(policy *models.Policy, from string, change Change) {
+		calls = append(calls, from+">"+policy.Status+":"+change.Trigger)
+	})
+
+	policy := policyIn(models.StatusPending)
+	if err := machine.Fire(policy, Change{To: models.StatusActive, Trigger: TriggerSchedule, At: start}, saveOK); err != nil {
+		t.Fatalf("Fire failed: %v", err)
+	}
+
+	saveErr := errors.New("disk unavailable")
+	err := machine.Fire(policy, Change{To: models.StatusCancelled, Trigger: TriggerCancel}, func(*models.Policy) error { return saveErr })
+	if err != saveErr {
+		t.Errorf("Fire should return the save error: got %v", err)
+	}
+
+	if len(calls) != 1 || calls[0] != "pending>active:schedule" {
+		t.Errorf("Hooks: got %v", calls)
+	}
+}
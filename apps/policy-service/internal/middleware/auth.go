@@ -4,13 +4,17 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
 type contextKey string
 
-const customerIDKey contextKey = "customerID"
+const (
+	customerIDKey contextKey = "customerID"
+	roleKey       contextKey = "role"
+)
 
 // AuthMiddleware extracts customer ID from X-User-ID header (simplified for demo)
 func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
@@ -24,6 +28,7 @@ func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
 
 			// Extract customer ID from X-User-ID header (demo purposes)
 			customerID := r.Header.Get("X-User-ID")
+			telemetry.SetIdentity(r.Context(), customerID, "")
 			if customerID == "" {
 				customerID = "cust-001" // Default for demo
 			}
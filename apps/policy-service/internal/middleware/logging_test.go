@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestLoggingMiddlewareLabelsByRouteTemplate(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetOutput(io.Discard)
+	metrics := telemetry.NewRegistry(nil)
+
+	router := mux.NewRouter()
+	router.Use(LoggingMiddleware(logger, LoggingOptions{Metrics: metrics, SlowThreshold: 20 * time.Millisecond}))
+	router.HandleFunc("/policies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := telemetry.FromContext(r.Context()); !ok {
+			t.Error("Handler should see the request's trace")
+		}
+		if mux.Vars(r)["id"] == "pol-slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}).Methods("GET")
+
+	for _, id := range []string{"pol-001", "pol-002", "pol-slow"} {
+		req := httptest.NewRequest(http.MethodGet, "/policies/"+id, nil)
+		req.Header.Set(telemetry.HeaderTraceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	routes := metrics.Routes()
+	if len(routes) != 1 {
+		t.Fatalf("Expected one series for the route template, got %d", len(routes))
+	}
+	if routes[0].Route != "/policies/{id}" || routes[0].Count != 3 {
+		t.Errorf("Series mismatch: got %s with %d requests", routes[0].Route, routes[0].Count)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 log entries, got %d", len(entries))
+	}
+	for _, entry := range entries[:2] {
+		if entry.Level != logrus.InfoLevel {
+			t.Errorf("Fast request logged at %s", entry.Level)
+		}
+	}
+	slow := entries[2]
+	if slow.Level != logrus.WarnLevel || slow.Data["path"] != "/policies/pol-slow" {
+		t.Errorf("Slow request not warned: %s %v", slow.Level, slow.Data["path"])
+	}
+	if slow.Data["trace_id"] != "4bf92f3577b34da6a3ce929d0e0e4736" || slow.Data["parent_span_id"] != "00f067aa0ba902b7" {
+		t.Errorf("Trace fields mismatch: %v", slow.Data)
+	}
+}
+
+func TestLoggingMiddlewareWritesAccessEntries(t *testing.T) {
+	logger, serviceHook := test.NewNullLogger()
+	accessLog, accessHook := test.NewNullLogger()
+
+	router := mux.NewRouter()
+	router.Use(LoggingMiddleware(logger, LoggingOptions{AccessLog: accessLog, SlowThreshold: 20 * time.Millisecond}))
+	router.Use(AuthMiddleware(logger))
+	router.HandleFunc("/policies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["id"] == "pol-slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.Write([]byte(`{"id":"pol-001"}`))
+	}).Methods("GET")
+
+	for _, id := range []string{"pol-001", "pol-slow"} {
+		req := httptest.NewRequest(http.MethodGet, "/policies/"+id, nil)
+		req.Header.Set("X-User-ID", "cust-001")
+		req.Header.Set(telemetry.HeaderRequestID, "req-"+id)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get(telemetry.HeaderRequestID); got != "req-"+id {
+			t.Errorf("Response request ID mismatch: got %q", got)
+		}
+	}
+
+	entries := accessHook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 access entries, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != logrus.InfoLevel || entry.Message != "HTTP request" {
+		t.Errorf("Unexpected access entry: %s %q", entry.Level, entry.Message)
+	}
+	if entry.Data["status"] != http.StatusOK || entry.Data["bytes"] != len(`{"id":"pol-001"}`) {
+		t.Errorf("Status or size mismatch: %v", entry.Data)
+	}
+	if entry.Data["user_id"] != "cust-001" || entry.Data["request_id"] != "req-pol-001" {
+		t.Errorf("Caller or request ID mismatch: %v", entry.Data)
+	}
+
+	// Slow requests are still warned about in the service log
+	warnings := serviceHook.AllEntries()
+	if len(warnings) != 1 || warnings[0].Level != logrus.WarnLevel || warnings[0].Data["request_id"] != "req-pol-slow" {
+		t.Errorf("Expected one slow request warning in the service log, got %v", warnings)
+	}
+}
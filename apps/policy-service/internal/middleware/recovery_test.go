@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+type recordingReporter struct {
+	errs []error
+	tags []map[string]string
+}
+
+func (r *recordingReporter) CaptureException(ctx context.Context, err error, tags map[string]string) {
+	r.errs = append(r.errs, err)
+	r.tags = append(r.tags, tags)
+}
+
+func TestRecoveryMiddlewareAnswersPanicsWith500(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.SetOutput(io.Discard)
+	panics := telemetry.NewPanicCounter()
+	reporter := &recordingReporter{}
+	errNoPremium := errors.New("policy has no premium")
+
+	router := mux.NewRouter()
+	router.Use(LoggingMiddleware(logger, LoggingOptions{}))
+	router.Use(RecoveryMiddleware(logger, RecoveryOptions{Panics: panics, Reporter: reporter}))
+	router.HandleFunc("/policies/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic(errNoPremium)
+	}).Methods("GET")
+
+	req := httptest.NewRequest(http.MethodGet, "/policies/pol-001", nil)
+	req.Header.Set(telemetry.HeaderRequestID, "req-123")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Expected a JSON body: %v", err)
+	}
+	if body["error"] != "internal_error" || body["requestId"] != "req-123" {
+		t.Errorf("Unexpected body: %v", body)
+	}
+	if rec.Header().Get(telemetry.HeaderRequestID) != "req-123" {
+		t.Errorf("Expected the request ID header to be kept")
+	}
+
+	if panics.Total() != 1 {
+		t.Errorf("Expected 1 panic counted, got %d", panics.Total())
+	}
+	if len(reporter.errs) != 1 || !errors.Is(reporter.errs[0], errNoPremium) {
+		t.Fatalf("Expected the panic to be reported, got %v", reporter.errs)
+	}
+	if tags := reporter.tags[0]; tags["request_id"] != "req-123" || tags["route"] != "/policies/{id}" || tags["method"] != "GET" {
+		t.Errorf("Unexpected tags: %v", tags)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected the panic and the request to be logged, got %d entries", len(entries))
+	}
+	if entries[0].Level != logrus.ErrorLevel || !strings.Contains(entries[0].Data["stack"].(string), "recovery_test.go") {
+		t.Errorf("Expected the panic logged as an error with its stack, got %s %v", entries[0].Level, entries[0].Message)
+	}
+	if entries[1].Message != "HTTP request" || entries[1].Data["status"] != http.StatusInternalServerError {
+		t.Errorf("Expected the request logged with 500, got %s %v", entries[1].Message, entries[1].Data["status"])
+	}
+}
+
+func TestRecoveryMiddlewareKeepsResponseUnderWay(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+
+	router := mux.NewRouter()
+	router.Use(LoggingMiddleware(logger, LoggingOptions{}))
+	router.Use(RecoveryMiddleware(logger, RecoveryOptions{}))
+	router.HandleFunc("/policies", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("[{"))
+		panic("encoder failed")
+	}).Methods("GET")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/policies", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "[{" {
+		t.Errorf("Expected the partial response to be left alone, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Cancellation records why and when a policy was cancelled and how much of
+// its premium was earned before then. CancelledAt is nil while the
+// cancellation is pending.
+type Cancellation struct {
+	EffectiveDate   time.Time  `json:"effectiveDate"`
+	ReasonCode      string     `json:"reasonCode"` // see CancelReason constants
+	Reason          string     `json:"reason,omitempty"`
+	InitiatedBy     string     `json:"initiatedBy"` // see InitiatedBy constants
+	TermDays        int        `json:"termDays"`
+	UnusedDays      int        `json:"unusedDays"`
+	EarnedPremium   float64    `json:"earnedPremium"`
+	UnearnedPremium float64    `json:"unearnedPremium"`
+	RefundPaymentID string     `json:"refundPaymentId,omitempty"`
+	RefundStatus    string     `json:"refundStatus,omitempty"` // see RefundStatus constants
+	RequestedAt     time.Time  `json:"requestedAt"`
+	CancelledAt     *time.Time `json:"cancelledAt,omitempty"`
+}
+
+// CancellationResponse represents a cancellation in API responses with
+// optional masking
+type CancellationResponse struct {
+	EffectiveDate   time.Time  `json:"effectiveDate"`
+	ReasonCode      string     `json:"reasonCode"`
+	Reason          string     `json:"reason,omitempty"`
+	InitiatedBy     string     `json:"initiatedBy"`
+	TermDays        int        `json:"termDays"`
+	UnusedDays      int        `json:"unusedDays"`
+	EarnedPremium   any        `json:"earnedPremium" shape:"mask=api.maskAmounts"`   // float64, or a string when masked
+	UnearnedPremium any        `json:"unearnedPremium" shape:"mask=api.maskAmounts"` // float64, or a string when masked
+	RefundPaymentID string     `json:"refundPaymentId,omitempty"`
+	RefundStatus    string     `json:"refundStatus,omitempty"`
+	RequestedAt     time.Time  `json:"requestedAt"`
+	CancelledAt     *time.Time `json:"cancelledAt,omitempty"`
+}
+
+// Why a policy was cancelled
+const (
+	CancelReasonCustomerRequest = "customer_request" // the customer no longer wants the cover
+	CancelReasonReplaced        = "replaced"         // replaced by another policy
+	CancelReasonNonPayment      = "non_payment"
+	CancelReasonUnderwriting    = "underwriting" // the risk no longer meets underwriting rules
+	CancelReasonFraud           = "fraud"
+	CancelReasonOther           = "other"
+)
+
+// Who asked for a cancellation
+const (
+	InitiatedByCustomer = "customer"
+	InitiatedByInsurer  = "insurer" // staff, on behalf of the insurer
+)
+
+// cancelReasons lists the reason codes each initiator may give
+var cancelReasons = map[string][]string{
+	InitiatedByCustomer: {CancelReasonCustomerRequest, CancelReasonReplaced, CancelReasonOther},
+	InitiatedByInsurer:  {CancelReasonNonPayment, CancelReasonUnderwriting, CancelReasonFraud, CancelReasonReplaced, CancelReasonOther},
+}
+
+// ValidateCancelReason checks if the cancellation reason code is valid
+func ValidateCancelReason(code string) bool {
+	switch code {
+	case CancelReasonCustomerRequest, CancelReasonReplaced, CancelReasonNonPayment, CancelReasonUnderwriting, CancelReasonFraud, CancelReasonOther:
+		return true
+	}
+	return false
+}
+
+// CheckCancelReason checks that the initiator may give the reason code,
+// which must be valid
+func CheckCancelReason(initiatedBy, code string) error {
+	for _, allowed := range cancelReasons[initiatedBy] {
+		if allowed == code {
+			return nil
+		}
+	}
+	return fmt.Errorf("reasonCode %s cannot be given by the %s", code, initiatedBy)
+}
+
+// How the refund of unearned premium went
+const (
+	RefundStatusPending = "pending" // refund payment created in payments-service
+	RefundStatusFailed  = "failed"  // payments-service could not be reached or refused it
+)
+
+// CancelPolicyRequest describes a cancellation. A nil EffectiveDate cancels
+// as soon as the notice period allows: immediately for customers, and
+// after the notice period for the insurer. An empty ReasonCode defaults to
+// customer_request for customers; the insurer must give one.
+type CancelPolicyRequest struct {
+	EffectiveDate *time.Time
+	ReasonCode    string
+	Reason        string // free-text details
+	InitiatedBy   string // defaults to InitiatedByCustomer
+	Refund        bool   // refund the unearned premium through payments-service
+}
+
+// Prorate splits the policy's premium at the effective date, pro rata by
+// day. A cancellation before the start date returns the whole premium.
+func (p *Policy) Prorate(effective time.Time) (*Cancellation, error) {
+	start := truncateDay(p.StartDate)
+	end := truncateDay(p.EndDate)
+	effective = truncateDay(effective)
+
+	termDays := int(end.Sub(start).Hours() / 24)
+	if termDays <= 0 {
+		return nil, fmt.Errorf("policy has no term to prorate")
+	}
+	if !effective.Before(end) {
+		return nil, fmt.Errorf("cancellation date must be before the policy end date")
+	}
+
+	unusedDays := termDays
+	if effective.After(start) {
+		unusedDays = int(end.Sub(effective).Hours() / 24)
+	}
+
+	unearned := math.Round(p.Premium*float64(unusedDays)/float64(termDays)*100) / 100
+	return &Cancellation{
+		EffectiveDate:   effective,
+		TermDays:        termDays,
+		UnusedDays:      unusedDays,
+		EarnedPremium:   math.Round((p.Premium-unearned)*100) / 100,
+		UnearnedPremium: unearned,
+	}, nil
+}
+
+// ToResponse converts a Cancellation to CancellationResponse
+func (c *Cancellation) ToResponse() *CancellationResponse {
+	return &CancellationResponse{
+		EffectiveDate:   c.EffectiveDate,
+		ReasonCode:      c.ReasonCode,
+		Reason:          c.Reason,
+		InitiatedBy:     c.InitiatedBy,
+		TermDays:        c.TermDays,
+		UnusedDays:      c.UnusedDays,
+		EarnedPremium:   c.EarnedPremium,
+		UnearnedPremium: c.UnearnedPremium,
+		RefundPaymentID: c.RefundPaymentID,
+		RefundStatus:    c.RefundStatus,
+		RequestedAt:     c.RequestedAt,
+		CancelledAt:     c.CancelledAt,
+	}
+}
+
+// truncateDay drops the time of day, in UTC
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
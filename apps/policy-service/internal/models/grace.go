@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Policy statuses
+const (
+	StatusPending   = "pending" // bound, but before its start date
+	StatusActive    = "active"
+	StatusGrace     = "grace" // past the end date, premium overdue, still reinstatable
+	StatusLapsed    = "lapsed"
+	StatusCancelled = "cancelled"
+	StatusExpired   = "expired" // reached the end date without renewing
+	// StatusPendingCancellation is an active policy whose cancellation
+	// takes effect at a later date; it stays in force until then
+	StatusPendingCancellation = "pending_cancellation"
+)
+
+// ValidatePolicyStatus checks if the policy status is valid
+func ValidatePolicyStatus(status string) bool {
+	switch status {
+	case StatusPending, StatusActive, StatusGrace, StatusLapsed, StatusCancelled, StatusExpired, StatusPendingCancellation:
+		return true
+	}
+	return false
+}
+
+// ReinstatePolicyRequest represents the request body for reinstating a
+// policy in grace. A nil EndDate renews for another term of the same length.
+type ReinstatePolicyRequest struct {
+	EndDate *time.Time `json:"endDate,omitempty"`
+}
+
+// LapsePolicyRequest represents the request body for lapsing a policy
+// whose premium was taken back, such as by a lost chargeback
+type LapsePolicyRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GraceSweepResult reports what one status sweep changed. A policy that
+// went through several statuses in one sweep is listed under each.
+type GraceSweepResult struct {
+	Checked      int       `json:"checked"`
+	Activated    []string  `json:"activated"`    // policy IDs
+	EnteredGrace []string  `json:"enteredGrace"` // policy IDs
+	Lapsed       []string  `json:"lapsed"`       // policy IDs
+	Expired      []string  `json:"expired"`      // policy IDs
+	Cancelled    []string  `json:"cancelled"`    // policy IDs whose pending cancellation took effect
+	SweptAt      time.Time `json:"sweptAt"`
+}
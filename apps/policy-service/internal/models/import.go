@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Policy import statuses
+const (
+	ImportRunning = "running"
+	ImportDone    = "done"
+	ImportFailed  = "failed" // stopped before every row was read; see Error
+)
+
+// Import row outcomes
+const (
+	RowImported = "imported"
+	RowValid    = "valid" // would be imported; dry runs only
+	RowRejected = "rejected"
+)
+
+// Policy fields an import mapping can read. The first seven are required.
+var ImportFields = []string{
+	"policyNumber", "customerId", "type", "premium", "coverage", "startDate", "endDate",
+	"deductible", "agentId", "status",
+}
+
+// ImportMapping says how to read a legacy CSV file as policies. Columns
+// names the header of the column holding each policy field; fields left
+// out are read from a column of the same name, so a policy export imports
+// as it is.
+type ImportMapping struct {
+	Columns map[string]string `json:"columns,omitempty"` // policy field -> CSV header
+	// Values translates the legacy codes of a field, e.g. type {"PA": "auto"}.
+	// Codes are matched ignoring case.
+	Values map[string]map[string]string `json:"values,omitempty"`
+	// DateFormat is the layout of legacy dates, such as DD/MM/YYYY (default:
+	// YYYY-MM-DD). RFC 3339 timestamps are always accepted.
+	DateFormat string `json:"dateFormat,omitempty"`
+	// ThousandsSeparator is stripped from amounts, e.g. "," for 1,200.50
+	ThousandsSeparator string `json:"thousandsSeparator,omitempty"`
+}
+
+// ImportOptions are the settings of one import
+type ImportOptions struct {
+	DryRun    bool   // validate every row without creating policies
+	FileName  string // name of the uploaded file, for the report
+	StartedBy string // staff user ID
+}
+
+// PolicyImport is an import of legacy policies from a CSV file and its
+// progress. Imports run in the background; Imported counts the policies
+// created, or on a dry run those that would be.
+type PolicyImport struct {
+	ID            string     `json:"id"`
+	Status        string     `json:"status"` // running, done, failed
+	DryRun        bool       `json:"dryRun"`
+	FileName      string     `json:"fileName,omitempty"`
+	TotalRows     int        `json:"totalRows"`
+	ProcessedRows int        `json:"processedRows"`
+	Imported      int        `json:"imported"`
+	Rejected      int        `json:"rejected"`
+	Error         string     `json:"error,omitempty"` // why a failed import stopped
+	StartedBy     string     `json:"startedBy,omitempty"`
+	StartedAt     time.Time  `json:"startedAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+}
+
+// ImportRow is the outcome of one row of an import file
+type ImportRow struct {
+	Line         int      `json:"line"` // line of the file the row starts on; the header is line 1
+	PolicyNumber string   `json:"policyNumber,omitempty"`
+	Outcome      string   `json:"outcome"`            // imported, valid, rejected
+	PolicyID     string   `json:"policyId,omitempty"` // the policy created
+	Errors       []string `json:"errors,omitempty"`   // why the row was rejected
+}
+
+// ImportReport is a finished import with the outcome of each row read
+type ImportReport struct {
+	Import PolicyImport `json:"import"`
+	Rows   []ImportRow  `json:"rows"`
+}
@@ -4,63 +4,84 @@ import "time"
 
 // Policy represents an insurance policy in the system
 type Policy struct {
-	ID           string    `json:"id"`
-	CustomerID   string    `json:"customerId"`
-	PolicyNumber string    `json:"policyNumber"`
-	Type         string    `json:"type"`   // auto, home, life
-	Status       string    `json:"status"` // active, lapsed, cancelled
-	Premium      float64   `json:"premium"`
-	Coverage     float64   `json:"coverage"`
-	Deductible   float64   `json:"deductible"`
-	Currency     string    `json:"currency"`
-	StartDate    time.Time `json:"startDate"`
-	EndDate      time.Time `json:"endDate"`
-	RenewalDate  time.Time `json:"renewalDate,omitempty"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID           string        `json:"id"`
+	CustomerID   string        `json:"customerId"`
+	AgentID      string        `json:"agentId,omitempty"` // selling agent; empty for direct business
+	QuoteID      string        `json:"quoteId,omitempty"` // pricing-engine quote the policy was bound from
+	PolicyNumber string        `json:"policyNumber"`
+	Type         string        `json:"type"`   // auto, home, life
+	Status       string        `json:"status"` // pending, active, grace, lapsed, cancelled, expired
+	Premium      float64       `json:"premium"`
+	Coverage     float64       `json:"coverage"`
+	Deductible   float64       `json:"deductible"`
+	Currency     string        `json:"currency"`
+	StartDate    time.Time     `json:"startDate"`
+	EndDate      time.Time     `json:"endDate"`
+	RenewalDate  time.Time     `json:"renewalDate,omitempty"`
+	NonRenewing  bool          `json:"nonRenewing,omitempty"`  // expires at the end date instead of entering grace
+	GraceEndsAt  *time.Time    `json:"graceEndsAt,omitempty"`  // set while in grace
+	LapsedAt     *time.Time    `json:"lapsedAt,omitempty"`     // when the grace period ran out, or the premium was taken back
+	LapseReason  string        `json:"lapseReason,omitempty"`  // set for lapses for non-payment
+	ReinstatedAt *time.Time    `json:"reinstatedAt,omitempty"` // last reinstatement from grace
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
 }
 
 // PolicyResponse represents a policy in API responses with optional masking
 type PolicyResponse struct {
-	ID           string    `json:"id"`
-	CustomerID   string    `json:"customerId"`
-	PolicyNumber string    `json:"policyNumber"`
-	Type         string    `json:"type"`
-	Status       string    `json:"status"`
-	Premium      any       `json:"premium"`   // Can be float64 or string (masked)
-	Coverage     any       `json:"coverage"`  // Can be float64 or string (masked)
-	Deductible   float64   `json:"deductible,omitempty"`
-	Currency     string    `json:"currency"`
-	StartDate    time.Time `json:"startDate"`
-	EndDate      time.Time `json:"endDate"`
-	RenewalDate  time.Time `json:"renewalDate,omitempty"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID           string                `json:"id"`
+	CustomerID   string                `json:"customerId"`
+	AgentID      string                `json:"agentId,omitempty"`
+	QuoteID      string                `json:"quoteId,omitempty"`
+	PolicyNumber string                `json:"policyNumber"`
+	Type         string                `json:"type"`
+	Status       string                `json:"status"`
+	Premium      any                   `json:"premium" shape:"mask=api.maskAmounts"`  // float64, or a string when masked
+	Coverage     any                   `json:"coverage" shape:"mask=api.maskAmounts"` // float64, or a string when masked
+	Deductible   float64               `json:"deductible,omitempty"`
+	Currency     string                `json:"currency"`
+	StartDate    time.Time             `json:"startDate"`
+	EndDate      time.Time             `json:"endDate"`
+	RenewalDate  time.Time             `json:"renewalDate,omitempty"`
+	NonRenewing  bool                  `json:"nonRenewing,omitempty"`
+	GraceEndsAt  *time.Time            `json:"graceEndsAt,omitempty"`
+	LapsedAt     *time.Time            `json:"lapsedAt,omitempty"`
+	LapseReason  string                `json:"lapseReason,omitempty"`
+	ReinstatedAt *time.Time            `json:"reinstatedAt,omitempty"`
+	Cancellation *CancellationResponse `json:"cancellation,omitempty"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	UpdatedAt    time.Time             `json:"updatedAt"`
 }
 
-// ToResponse converts a Policy to PolicyResponse with optional masking and currency override
-func (p *Policy) ToResponse(maskAmounts bool, currency string) PolicyResponse {
+// ToResponse converts a Policy to PolicyResponse with a currency override.
+// Amounts are masked when the response is shaped for the caller.
+func (p *Policy) ToResponse(currency string) PolicyResponse {
 	resp := PolicyResponse{
 		ID:           p.ID,
 		CustomerID:   p.CustomerID,
+		AgentID:      p.AgentID,
+		QuoteID:      p.QuoteID,
 		PolicyNumber: p.PolicyNumber,
 		Type:         p.Type,
 		Status:       p.Status,
+		Premium:      p.Premium,
+		Coverage:     p.Coverage,
 		Currency:     currency, // Use feature flag currency
 		Deductible:   p.Deductible,
 		StartDate:    p.StartDate,
 		EndDate:      p.EndDate,
 		RenewalDate:  p.RenewalDate,
+		NonRenewing:  p.NonRenewing,
+		GraceEndsAt:  p.GraceEndsAt,
+		LapsedAt:     p.LapsedAt,
+		LapseReason:  p.LapseReason,
+		ReinstatedAt: p.ReinstatedAt,
 		CreatedAt:    p.CreatedAt,
 		UpdatedAt:    p.UpdatedAt,
 	}
-
-	if maskAmounts {
-		resp.Premium = "***.**"
-		resp.Coverage = "***.**"
-	} else {
-		resp.Premium = p.Premium
-		resp.Coverage = p.Coverage
+	if p.Cancellation != nil {
+		resp.Cancellation = p.Cancellation.ToResponse()
 	}
 
 	return resp
@@ -69,6 +90,8 @@ func (p *Policy) ToResponse(maskAmounts bool, currency string) PolicyResponse {
 // CreatePolicyRequest represents the request body for creating a new policy
 type CreatePolicyRequest struct {
 	CustomerID   string    `json:"customerId"`
+	AgentID      string    `json:"agentId,omitempty"`
+	QuoteID      string    `json:"quoteId,omitempty"` // quote being bound, reported to pricing-engine
 	PolicyNumber string    `json:"policyNumber"`
 	Type         string    `json:"type"`
 	Premium      float64   `json:"premium"`
@@ -80,7 +103,63 @@ type CreatePolicyRequest struct {
 
 // UpdatePolicyRequest represents the request body for updating a policy
 type UpdatePolicyRequest struct {
-	Status    *string    `json:"status,omitempty"`
-	Premium   *float64   `json:"premium,omitempty"`
-	EndDate   *time.Time `json:"endDate,omitempty"`
+	Status      *string    `json:"status,omitempty"`
+	Premium     *float64   `json:"premium,omitempty"`
+	EndDate     *time.Time `json:"endDate,omitempty"`
+	AgentID     *string    `json:"agentId,omitempty"`     // empty string removes the agent
+	NonRenewing *bool      `json:"nonRenewing,omitempty"` // true lets the policy expire at its end date
+}
+
+// PolicyFilters narrows the back-office policy listing. Empty fields and a
+// zero ExpiringBefore leave the corresponding filter off.
+type PolicyFilters struct {
+	Type           string
+	Status         string
+	CustomerID     string
+	AgentID        string
+	ExpiringBefore time.Time // policies whose end date is before this time
+}
+
+// Matches checks if a policy matches the given filters
+func (p *Policy) Matches(filters PolicyFilters) bool {
+	if filters.Type != "" && p.Type != filters.Type {
+		return false
+	}
+	if filters.Status != "" && p.Status != filters.Status {
+		return false
+	}
+	if filters.CustomerID != "" && p.CustomerID != filters.CustomerID {
+		return false
+	}
+	if filters.AgentID != "" && p.AgentID != filters.AgentID {
+		return false
+	}
+	if !filters.ExpiringBefore.IsZero() && !p.EndDate.Before(filters.ExpiringBefore) {
+		return false
+	}
+	return true
+}
+
+// PolicyPage is one page of a policy listing
+type PolicyPage struct {
+	Data     []PolicyResponse `json:"data"`
+	Total    int              `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"pageSize"`
+	HasMore  bool             `json:"hasMore"`
+}
+
+// PolicyNumberSearch is the result of a policy number search, best matches
+// first: the exact number, then numbers it is the start of, then near
+// misses by fewest edits
+type PolicyNumberSearch struct {
+	Query   string              `json:"query"`
+	Matches []PolicyNumberMatch `json:"matches"`
+}
+
+// PolicyNumberMatch is a policy a policy number search found
+type PolicyNumberMatch struct {
+	Match    string         `json:"match"`              // exact, prefix or fuzzy
+	Distance int            `json:"distance,omitempty"` // edits between the query and a fuzzy match
+	Policy   PolicyResponse `json:"policy"`
 }
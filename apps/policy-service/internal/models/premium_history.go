@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Premium change kinds
+const (
+	PremiumIssued      = "issued"      // the premium the policy was written at
+	PremiumRenewal     = "renewal"     // a new term, at the same or a new premium
+	PremiumEndorsement = "endorsement" // a change to the premium within a term
+)
+
+// PremiumChange is one point of a policy's premium history, recorded as
+// the policy is written, renewed or endorsed
+type PremiumChange struct {
+	ID          string    `json:"id"`
+	PolicyID    string    `json:"policyId"`
+	CustomerID  string    `json:"customerId"`
+	Kind        string    `json:"kind"` // issued, renewal or endorsement
+	Premium     float64   `json:"premium"`
+	TermStart   time.Time `json:"termStart"`
+	TermEnd     time.Time `json:"termEnd"`
+	EffectiveAt time.Time `json:"effectiveAt"` // when the premium applies from
+	RecordedAt  time.Time `json:"recordedAt"`
+}
+
+// PremiumHistory is the response of GET /customers/{id}/premium-history:
+// the premium of each of a customer's policies over time
+type PremiumHistory struct {
+	CustomerID string                 `json:"customerId"`
+	Currency   string                 `json:"currency"`
+	Policies   []PolicyPremiumHistory `json:"policies"` // by start date
+}
+
+// PolicyPremiumHistory is the premium of one policy over time, oldest
+// first
+type PolicyPremiumHistory struct {
+	PolicyID     string         `json:"policyId"`
+	PolicyNumber string         `json:"policyNumber"`
+	Type         string         `json:"type"`
+	Status       string         `json:"status"`
+	Premium      any            `json:"premium" shape:"mask=api.maskAmounts"` // current premium; float64, or a string when masked
+	History      []PremiumPoint `json:"history"`
+}
+
+// PremiumPoint is a premium change with how it compares to the one before.
+// Change and ChangePercent are left out of the first point.
+type PremiumPoint struct {
+	Kind          string    `json:"kind"`
+	Premium       any       `json:"premium" shape:"mask=api.maskAmounts"`          // float64, or a string when masked
+	Change        any       `json:"change,omitempty" shape:"mask=api.maskAmounts"` // premium less the previous premium
+	ChangePercent *float64  `json:"changePercent,omitempty"`                       // to one decimal place
+	Annotation    string    `json:"annotation"`                                    // e.g. "Renewed, up 12.5%"
+	TermStart     time.Time `json:"termStart"`
+	TermEnd       time.Time `json:"termEnd"`
+	EffectiveAt   time.Time `json:"effectiveAt"`
+}
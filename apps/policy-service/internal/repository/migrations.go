@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/sirupsen/logrus"
+)
+
+// policyMigrations upgrade policies.json, oldest first. Append one when a
+// change to models.Policy would misread the files written before it.
+var policyMigrations = migrate.Set{}
+
+// logMigration records an upgrade of the data file at path
+func logMigration(logger *logrus.Logger, path string, result migrate.Result) {
+	if !result.Migrated() {
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"from":   result.From,
+		"to":     result.To,
+		"backup": result.Backup,
+	}).Infof("Migrated %s: %s", path, strings.Join(result.Applied, "; "))
+}
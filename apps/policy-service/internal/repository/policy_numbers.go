@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+)
+
+// How a policy number search matched a policy
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchFuzzy  = "fuzzy"
+)
+
+// NumberMatch is a policy found by a policy number search. Distance is the
+// number of edits between the query and the policy number, 0 unless the
+// match is fuzzy.
+type NumberMatch struct {
+	Policy   *models.Policy
+	Match    string
+	Distance int
+}
+
+// NormalizePolicyNumber returns the form policy numbers are compared in:
+// upper case letters and digits only, so "auto 2023-001" is AUTO2023001
+func NormalizePolicyNumber(number string) string {
+	var b strings.Builder
+	for _, r := range number {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// MatchPolicyNumber reports how the normalized query matches the
+// normalized policy number key: exactly, as a prefix of it, or within
+// maxEdits insertions, deletions or substitutions of it
+func MatchPolicyNumber(query, key string, maxEdits int) (match string, distance int, ok bool) {
+	switch {
+	case query == "":
+		return "", 0, false
+	case key == query:
+		return MatchExact, 0, true
+	case strings.HasPrefix(key, query):
+		return MatchPrefix, 0, true
+	}
+	if d := editDistance(query, key, maxEdits); d <= maxEdits {
+		return MatchFuzzy, d, true
+	}
+	return "", 0, false
+}
+
+// editDistance returns the Levenshtein distance between a and b, or
+// max+1 once it is known to be larger than max
+func editDistance(a, b string, max int) int {
+	if diff := len(a) - len(b); diff > max || -diff > max {
+		return max + 1
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		best := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			best = min(best, curr[j])
+		}
+		if best > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// numberIndex finds policy IDs by normalized policy number. Keys are kept
+// sorted, so prefix searches read a contiguous run of them. Policy numbers
+// are not unique, so a key can hold several IDs.
+type numberIndex struct {
+	ids  map[string][]string // policy IDs by key, sorted
+	keys []string            // every key, sorted
+	byID map[string]string   // key of each indexed policy
+}
+
+func newNumberIndex() *numberIndex {
+	return &numberIndex{ids: make(map[string][]string), byID: make(map[string]string)}
+}
+
+// put indexes policy under its current number, replacing the number it
+// was indexed under before
+func (x *numberIndex) put(policy *models.Policy) {
+	key := NormalizePolicyNumber(policy.PolicyNumber)
+	if old, ok := x.byID[policy.ID]; ok {
+		if old == key {
+			return
+		}
+		x.remove(policy.ID)
+	}
+	if key == "" {
+		return
+	}
+
+	ids := x.ids[key]
+	if len(ids) == 0 {
+		i := sort.SearchStrings(x.keys, key)
+		x.keys = append(x.keys, "")
+		copy(x.keys[i+1:], x.keys[i:])
+		x.keys[i] = key
+	}
+	i := sort.SearchStrings(ids, policy.ID)
+	ids = append(ids, "")
+	copy(ids[i+1:], ids[i:])
+	ids[i] = policy.ID
+	x.ids[key] = ids
+	x.byID[policy.ID] = key
+}
+
+// remove drops a policy from the index
+func (x *numberIndex) remove(policyID string) {
+	key, ok := x.byID[policyID]
+	if !ok {
+		return
+	}
+	delete(x.byID, policyID)
+
+	ids := x.ids[key]
+	if i := sort.SearchStrings(ids, policyID); i < len(ids) && ids[i] == policyID {
+		ids = append(ids[:i], ids[i+1:]...)
+	}
+	if len(ids) > 0 {
+		x.ids[key] = ids
+		return
+	}
+	delete(x.ids, key)
+	if i := sort.SearchStrings(x.keys, key); i < len(x.keys) && x.keys[i] == key {
+		x.keys = append(x.keys[:i], x.keys[i+1:]...)
+	}
+}
+
+// exact returns the IDs of the policies numbered number
+func (x *numberIndex) exact(number string) []string {
+	return x.ids[NormalizePolicyNumber(number)]
+}
+
+// keyMatch is an index key a search matched
+type keyMatch struct {
+	key      string
+	match    string
+	distance int
+}
+
+// search returns the keys matching query: the exact key and those it
+// prefixes, read from the sorted keys, then the others within maxEdits
+func (x *numberIndex) search(query string, maxEdits int) []keyMatch {
+	query = NormalizePolicyNumber(query)
+	if query == "" {
+		return nil
+	}
+
+	var matches []keyMatch
+	start := sort.SearchStrings(x.keys, query)
+	end := start
+	for ; end < len(x.keys) && strings.HasPrefix(x.keys[end], query); end++ {
+		match, _, _ := MatchPolicyNumber(query, x.keys[end], 0)
+		matches = append(matches, keyMatch{key: x.keys[end], match: match})
+	}
+	if maxEdits == 0 {
+		return matches
+	}
+	for i, key := range x.keys {
+		if i >= start && i < end {
+			continue
+		}
+		if match, distance, ok := MatchPolicyNumber(query, key, maxEdits); ok {
+			matches = append(matches, keyMatch{key: key, match: match, distance: distance})
+		}
+	}
+	return matches
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := NewRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	return repo
+}
+
+// numbers lists the policy numbers and matches a search found
+func numbers(matches []NumberMatch) string {
+	var found []string
+	for _, m := range matches {
+		found = append(found, m.Policy.PolicyNumber+" "+m.Match)
+	}
+	return strings.Join(found, ", ")
+}
+
+func TestPolicyNumberIndexFollowsWrites(t *testing.T) {
+	repo := newTestRepository(t)
+
+	if got := repo.GetPoliciesByNumber("auto 2024 001234"); len(got) != 1 || got[0].ID != "pol-001" {
+		t.Fatalf("Expected the sample policy pol-001 by number, got %v", got)
+	}
+
+	created, err := repo.CreatePolicy(models.CreatePolicyRequest{CustomerID: "cust-009", PolicyNumber: "AUTO-2024-001299", Type: "auto", Premium: 900})
+	if err != nil {
+		t.Fatalf("CreatePolicy failed: %v", err)
+	}
+	if got := numbers(repo.SearchPolicyNumbers("AUTO-2024-0012", 0)); got != "AUTO-2024-001234 prefix, AUTO-2024-001299 prefix" {
+		t.Errorf("Prefix search: got %s", got)
+	}
+
+	renumbered := *created
+	renumbered.PolicyNumber = "AUTO-2024-007777"
+	if _, err := repo.UpdatePolicy(&renumbered); err != nil {
+		t.Fatalf("UpdatePolicy failed: %v", err)
+	}
+	if got := repo.GetPoliciesByNumber("AUTO-2024-001299"); len(got) != 0 {
+		t.Errorf("The old number should no longer find the policy: %v", got)
+	}
+	if got := repo.GetPoliciesByNumber("AUTO-2024-007777"); len(got) != 1 || got[0].ID != created.ID {
+		t.Errorf("The new number should find %s: %v", created.ID, got)
+	}
+
+	shared := *created
+	shared.ID = "pol-100"
+	shared.PolicyNumber = "auto-2024-007777"
+	repo.UpdatePolicy(&shared) // not stored, so not indexed
+	if got := repo.GetPoliciesByNumber("AUTO-2024-007777"); len(got) != 1 {
+		t.Errorf("Only stored policies are indexed: %v", got)
+	}
+}
+
+func TestSearchPolicyNumbersWithinEdits(t *testing.T) {
+	repo := newTestRepository(t)
+
+	tests := []struct {
+		query    string
+		maxEdits int
+		want     string
+	}{
+		{"HOME-2024-005678", 0, "HOME-2024-005678 exact"},
+		{"HOME-2024-005687", 0, ""},
+		{"HOME-2024-005687", 2, "HOME-2024-005678 fuzzy"},
+		{"LIFE-2023-00912", 1, "LIFE-2023-009012 fuzzy"},
+		{"LIFE", 1, "LIFE-2023-009012 prefix"},
+		{"--", 2, ""},
+	}
+	for _, tt := range tests {
+		if got := numbers(repo.SearchPolicyNumbers(tt.query, tt.maxEdits)); got != tt.want {
+			t.Errorf("SearchPolicyNumbers(%q, %d): got %q, want %q", tt.query, tt.maxEdits, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"AUTO2023001", "AUTO2023001", 2, 0},
+		{"AUTO2023001", "AUTO2023011", 2, 1},
+		{"AUTO202301", "AUTO2023001", 2, 1},
+		{"AUTO2023001", "HOME2023001", 2, 3},
+		{"AUTO", "AUTO2023001", 2, 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b, tt.max); got != tt.want {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, got, tt.want)
+		}
+	}
+}
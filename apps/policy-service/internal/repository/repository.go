@@ -1,20 +1,31 @@
 package repository
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
 	"github.com/sirupsen/logrus"
 )
 
-// Repository provides data access for policies
+// Repository provides data access for policies. Register hooks with
+// OnCreate and OnUpdate to follow its writes.
 type Repository struct {
+	hooks.Hooks
+
 	policies    map[string]*models.Policy
+	comments    map[string]*models.Comment
+	premiums    map[string]*models.PremiumChange
+	numbers     *numberIndex     // policies by policy number
+	journal     *persist.Journal // nil unless Persist is called
+	pending     []hooks.Change   // written under mu, announced by unlock
 	mu          sync.RWMutex
 	logger      *logrus.Logger
 	nextID      int
@@ -24,6 +35,9 @@ type Repository struct {
 func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error) {
 	repo := &Repository{
 		policies: make(map[string]*models.Policy),
+		comments: make(map[string]*models.Comment),
+		premiums: make(map[string]*models.PremiumChange),
+		numbers:  newNumberIndex(),
 		logger:   logger,
 		nextID:   1,
 	}
@@ -41,23 +55,74 @@ func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error)
 	return repo, nil
 }
 
-// loadPolicies loads policies from a JSON file
-func (r *Repository) loadPolicies(filePath string) error {
-	data, err := os.ReadFile(filePath)
-	if err != nil {
+// Persist replaces the seed data with the state recovered from journal and
+// records every later change in it. Call it before the repository is used.
+func (r *Repository) Persist(journal *persist.Journal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := persist.Restore(journal, "policies", func(id string, policy *models.Policy) {
+		r.policies[id] = policy
+		r.numbers.put(policy)
+		var idNum int
+		fmt.Sscanf(id, "pol-%d", &idNum)
+		if idNum >= r.nextID {
+			r.nextID = idNum + 1
+		}
+	}, func(id string) {
+		delete(r.policies, id)
+		r.numbers.remove(id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "comments", func(id string, comment *models.Comment) {
+		r.comments[id] = comment
+	}, func(id string) {
+		delete(r.comments, id)
+	}); err != nil {
 		return err
 	}
+	if err := persist.Restore(journal, "premium_history", func(id string, change *models.PremiumChange) {
+		r.premiums[id] = change
+	}, func(id string) {
+		delete(r.premiums, id)
+	}); err != nil {
+		return err
+	}
+
+	r.journal = journal
+	return nil
+}
+
+// changed records a write made under the lock, for unlock to announce
+func (r *Repository) changed(op, collection, key string, value interface{}) {
+	r.pending = append(r.pending, hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+}
+
+// unlock releases the write lock and then runs the hooks of the writes
+// made under it, so hooks can use the repository
+func (r *Repository) unlock() {
+	changes := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	r.Notify(changes...)
+}
 
+// loadPolicies loads policies from a JSON file
+func (r *Repository) loadPolicies(filePath string) error {
 	var policies []*models.Policy
-	if err := json.Unmarshal(data, &policies); err != nil {
+	result, err := migrate.Load(filePath, policyMigrations, &policies)
+	if err != nil {
 		return err
 	}
+	logMigration(r.logger, filePath, result)
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	for _, policy := range policies {
 		r.policies[policy.ID] = policy
+		r.numbers.put(policy)
 		// Track highest ID for generating new IDs
 		var idNum int
 		fmt.Sscanf(policy.ID, "pol-%d", &idNum)
@@ -118,6 +183,7 @@ func (r *Repository) initializeSamplePolicies() {
 
 	for _, policy := range samplePolicies {
 		r.policies[policy.ID] = policy
+		r.numbers.put(policy)
 	}
 	r.nextID = 4
 }
@@ -150,18 +216,20 @@ func (r *Repository) GetPoliciesByCustomerID(customerID string) ([]*models.Polic
 	return customerPolicies, nil
 }
 
-// CreatePolicy creates a new policy
+// CreatePolicy creates a new policy, pending until its start date
 func (r *Repository) CreatePolicy(req models.CreatePolicyRequest) (*models.Policy, error) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
 	now := time.Now()
 	policy := &models.Policy{
 		ID:           fmt.Sprintf("pol-%03d", r.nextID),
 		CustomerID:   req.CustomerID,
+		AgentID:      req.AgentID,
+		QuoteID:      req.QuoteID,
 		PolicyNumber: req.PolicyNumber,
 		Type:         req.Type,
-		Status:       "active",
+		Status:       lifecycle.Initial(req.StartDate, now),
 		Premium:      req.Premium,
 		Coverage:     req.Coverage,
 		Deductible:   req.Deductible,
@@ -172,8 +240,13 @@ func (r *Repository) CreatePolicy(req models.CreatePolicyRequest) (*models.Polic
 		UpdatedAt:    now,
 	}
 
+	if err := r.journal.Put("policies", policy.ID, policy); err != nil {
+		return nil, err
+	}
 	r.policies[policy.ID] = policy
+	r.numbers.put(policy)
 	r.nextID++
+	r.changed(hooks.OpCreate, "policies", policy.ID, policy)
 
 	return policy, nil
 }
@@ -181,16 +254,50 @@ func (r *Repository) CreatePolicy(req models.CreatePolicyRequest) (*models.Polic
 // UpdatePolicy updates an existing policy
 func (r *Repository) UpdatePolicy(policy *models.Policy) (*models.Policy, error) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	defer r.unlock()
 
 	if _, exists := r.policies[policy.ID]; !exists {
 		return nil, fmt.Errorf("policy not found")
 	}
 
+	if err := r.journal.Put("policies", policy.ID, policy); err != nil {
+		return nil, err
+	}
 	r.policies[policy.ID] = policy
+	r.numbers.put(policy)
+	r.changed(hooks.OpUpdate, "policies", policy.ID, policy)
 	return policy, nil
 }
 
+// GetPoliciesByNumber returns the policies numbered policyNumber, compared
+// normalized, ordered by ID. Policy numbers should be unique, so more than
+// one policy is a data problem the consistency report also flags.
+func (r *Repository) GetPoliciesByNumber(policyNumber string) []*models.Policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var policies []*models.Policy
+	for _, id := range r.numbers.exact(policyNumber) {
+		policies = append(policies, r.policies[id])
+	}
+	return policies
+}
+
+// SearchPolicyNumbers returns the policies whose number query matches
+// exactly, is a prefix of, or is within maxEdits edits of
+func (r *Repository) SearchPolicyNumbers(query string, maxEdits int) []NumberMatch {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []NumberMatch
+	for _, found := range r.numbers.search(query, maxEdits) {
+		for _, id := range r.numbers.ids[found.key] {
+			matches = append(matches, NumberMatch{Policy: r.policies[id], Match: found.match, Distance: found.distance})
+		}
+	}
+	return matches
+}
+
 // GetAllPolicies returns all policies (for testing/admin purposes)
 func (r *Repository) GetAllPolicies() []*models.Policy {
 	r.mu.RLock()
@@ -203,3 +310,116 @@ func (r *Repository) GetAllPolicies() []*models.Policy {
 
 	return policies
 }
+
+// CreateComment stores a new comment
+func (r *Repository) CreateComment(comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.comments[comment.ID]; exists {
+		return fmt.Errorf("comment already exists")
+	}
+
+	if err := r.journal.Put("comments", comment.ID, comment); err != nil {
+		return err
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	r.changed(hooks.OpCreate, "comments", comment.ID, comment)
+	return nil
+}
+
+// GetComments returns copies of the comments on a policy, oldest first
+func (r *Repository) GetComments(policyID string) []*models.Comment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comments := []*models.Comment{}
+	for _, comment := range r.comments {
+		if comment.PolicyID == policyID {
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+
+	return comments
+}
+
+// GetComment returns a copy of a comment
+func (r *Repository) GetComment(commentID string) (*models.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	comment, exists := r.comments[commentID]
+	if !exists {
+		return nil, fmt.Errorf("comment not found")
+	}
+
+	copied := *comment
+	return &copied, nil
+}
+
+// UpdateComment replaces a stored comment
+func (r *Repository) UpdateComment(comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.comments[comment.ID]; !exists {
+		return fmt.Errorf("comment not found")
+	}
+
+	if err := r.journal.Put("comments", comment.ID, comment); err != nil {
+		return err
+	}
+	stored := *comment
+	r.comments[comment.ID] = &stored
+	r.changed(hooks.OpUpdate, "comments", comment.ID, comment)
+	return nil
+}
+
+// AddPremiumChange stores a new point of a policy's premium history
+func (r *Repository) AddPremiumChange(change *models.PremiumChange) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.premiums[change.ID]; exists {
+		return fmt.Errorf("premium change already exists")
+	}
+
+	if err := r.journal.Put("premium_history", change.ID, change); err != nil {
+		return err
+	}
+	stored := *change
+	r.premiums[change.ID] = &stored
+	r.changed(hooks.OpCreate, "premium_history", change.ID, change)
+	return nil
+}
+
+// GetPremiumChanges returns copies of a policy's premium history, oldest
+// first
+func (r *Repository) GetPremiumChanges(policyID string) []*models.PremiumChange {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	changes := []*models.PremiumChange{}
+	for _, change := range r.premiums {
+		if change.PolicyID == policyID {
+			copied := *change
+			changes = append(changes, &copied)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].EffectiveAt.Equal(changes[j].EffectiveAt) {
+			return changes[i].EffectiveAt.Before(changes[j].EffectiveAt)
+		}
+		return changes[i].RecordedAt.Before(changes[j].RecordedAt)
+	})
+
+	return changes
+}
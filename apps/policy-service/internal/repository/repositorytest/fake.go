@@ -0,0 +1,236 @@
+// Package repositorytest provides an in-memory PolicyStore for unit tests
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+)
+
+// FakeStore is an in-memory PolicyStore. Set Err to make every call fail,
+// e.g. to exercise a handler's error path.
+type FakeStore struct {
+	Err error
+
+	mu       sync.Mutex
+	policies map[string]*models.Policy
+	comments map[string]*models.Comment
+	nextID   int
+}
+
+var (
+	_ repository.PolicyStore  = (*FakeStore)(nil)
+	_ repository.CommentStore = (*FakeStore)(nil)
+)
+
+// NewFakeStore creates a fake holding the given policies
+func NewFakeStore(policies ...*models.Policy) *FakeStore {
+	f := &FakeStore{
+		policies: make(map[string]*models.Policy),
+		comments: make(map[string]*models.Comment),
+		nextID:   1,
+	}
+	for _, policy := range policies {
+		f.policies[policy.ID] = policy
+		var idNum int
+		fmt.Sscanf(policy.ID, "pol-%d", &idNum)
+		if idNum >= f.nextID {
+			f.nextID = idNum + 1
+		}
+	}
+	return f
+}
+
+// GetPolicyByID returns the stored policy
+func (f *FakeStore) GetPolicyByID(policyID string) (*models.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	policy, exists := f.policies[policyID]
+	if !exists {
+		return nil, fmt.Errorf("policy not found")
+	}
+	return policy, nil
+}
+
+// GetPoliciesByCustomerID returns the customer's policies ordered by ID
+func (f *FakeStore) GetPoliciesByCustomerID(customerID string) ([]*models.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	var result []*models.Policy
+	for _, policy := range f.sorted() {
+		if policy.CustomerID == customerID {
+			result = append(result, policy)
+		}
+	}
+	return result, nil
+}
+
+// CreatePolicy stores a new policy with the next sequential ID, pending
+// until its start date
+func (f *FakeStore) CreatePolicy(req models.CreatePolicyRequest) (*models.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	now := time.Now()
+	policy := &models.Policy{
+		ID:           fmt.Sprintf("pol-%03d", f.nextID),
+		CustomerID:   req.CustomerID,
+		AgentID:      req.AgentID,
+		QuoteID:      req.QuoteID,
+		PolicyNumber: req.PolicyNumber,
+		Type:         req.Type,
+		Status:       lifecycle.Initial(req.StartDate, now),
+		Premium:      req.Premium,
+		Coverage:     req.Coverage,
+		Deductible:   req.Deductible,
+		Currency:     "USD",
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	f.policies[policy.ID] = policy
+	f.nextID++
+	return policy, nil
+}
+
+// UpdatePolicy replaces a stored policy
+func (f *FakeStore) UpdatePolicy(policy *models.Policy) (*models.Policy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if _, exists := f.policies[policy.ID]; !exists {
+		return nil, fmt.Errorf("policy not found")
+	}
+	f.policies[policy.ID] = policy
+	return policy, nil
+}
+
+// GetAllPolicies returns every policy ordered by ID
+func (f *FakeStore) GetAllPolicies() []*models.Policy {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sorted()
+}
+
+// GetPoliciesByNumber returns the policies numbered policyNumber, compared
+// normalized, ordered by ID
+func (f *FakeStore) GetPoliciesByNumber(policyNumber string) []*models.Policy {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := repository.NormalizePolicyNumber(policyNumber)
+	var result []*models.Policy
+	for _, policy := range f.sorted() {
+		if key != "" && repository.NormalizePolicyNumber(policy.PolicyNumber) == key {
+			result = append(result, policy)
+		}
+	}
+	return result
+}
+
+// SearchPolicyNumbers matches query against every policy number, ordered
+// by ID
+func (f *FakeStore) SearchPolicyNumbers(query string, maxEdits int) []repository.NumberMatch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	query = repository.NormalizePolicyNumber(query)
+	var matches []repository.NumberMatch
+	for _, policy := range f.sorted() {
+		if match, distance, ok := repository.MatchPolicyNumber(query, repository.NormalizePolicyNumber(policy.PolicyNumber), maxEdits); ok {
+			matches = append(matches, repository.NumberMatch{Policy: policy, Match: match, Distance: distance})
+		}
+	}
+	return matches
+}
+
+func (f *FakeStore) sorted() []*models.Policy {
+	policies := make([]*models.Policy, 0, len(f.policies))
+	for _, policy := range f.policies {
+		policies = append(policies, policy)
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+	return policies
+}
+
+// CreateComment stores a copy of a new comment
+func (f *FakeStore) CreateComment(comment *models.Comment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	stored := *comment
+	f.comments[comment.ID] = &stored
+	return nil
+}
+
+// GetComments returns copies of the comments on a policy ordered by creation
+// time
+func (f *FakeStore) GetComments(policyID string) []*models.Comment {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	comments := []*models.Comment{}
+	for _, comment := range f.comments {
+		if comment.PolicyID == policyID {
+			copied := *comment
+			comments = append(comments, &copied)
+		}
+	}
+	sort.Slice(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+	return comments
+}
+
+// GetComment returns a copy of the stored comment
+func (f *FakeStore) GetComment(commentID string) (*models.Comment, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	comment, exists := f.comments[commentID]
+	if !exists {
+		return nil, fmt.Errorf("comment not found")
+	}
+	copied := *comment
+	return &copied, nil
+}
+
+// UpdateComment replaces the stored comment
+func (f *FakeStore) UpdateComment(comment *models.Comment) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	if _, exists := f.comments[comment.ID]; !exists {
+		return fmt.Errorf("comment not found")
+	}
+	stored := *comment
+	f.comments[comment.ID] = &stored
+	return nil
+}
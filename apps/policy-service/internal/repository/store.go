@@ -0,0 +1,42 @@
+package repository
+
+import "github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+
+// PolicyStore is the data access the policy service depends on. Repository
+// is the JSON-backed implementation; repositorytest provides an in-memory
+// fake for unit tests.
+type PolicyStore interface {
+	GetPolicyByID(policyID string) (*models.Policy, error)
+	GetPoliciesByCustomerID(customerID string) ([]*models.Policy, error)
+	CreatePolicy(req models.CreatePolicyRequest) (*models.Policy, error)
+	UpdatePolicy(policy *models.Policy) (*models.Policy, error)
+	GetAllPolicies() []*models.Policy
+	GetPoliciesByNumber(policyNumber string) []*models.Policy
+	SearchPolicyNumbers(query string, maxEdits int) []NumberMatch
+}
+
+var _ PolicyStore = (*Repository)(nil)
+
+// CommentStore is the data access the comment service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides an
+// in-memory fake for unit tests.
+type CommentStore interface {
+	GetPolicyByID(policyID string) (*models.Policy, error)
+	CreateComment(comment *models.Comment) error
+	GetComments(policyID string) []*models.Comment
+	GetComment(commentID string) (*models.Comment, error)
+	UpdateComment(comment *models.Comment) error
+}
+
+var _ CommentStore = (*Repository)(nil)
+
+// PremiumHistoryStore is the data access the premium history service
+// depends on. Repository is the JSON-backed implementation.
+type PremiumHistoryStore interface {
+	GetAllPolicies() []*models.Policy
+	GetPoliciesByCustomerID(customerID string) ([]*models.Policy, error)
+	AddPremiumChange(change *models.PremiumChange) error
+	GetPremiumChanges(policyID string) []*models.PremiumChange
+}
+
+var _ PremiumHistoryStore = (*Repository)(nil)
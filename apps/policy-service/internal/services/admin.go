@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultPageSize is used when a listing does not ask for a page size
+	DefaultPageSize = 50
+	// MaxPageSize caps how many policies one page can return
+	MaxPageSize = 500
+
+	// DefaultNumberSearchLimit is used when a policy number search does not
+	// ask for a limit
+	DefaultNumberSearchLimit = 10
+	// MaxNumberSearchLimit caps how many matches one search can return
+	MaxNumberSearchLimit = 50
+)
+
+// ListPolicies returns every policy matching the filters across all
+// customers, ordered by policy number. It backs the back-office views and
+// does not check ownership, so callers must restrict it to staff.
+func (s *PolicyService) ListPolicies(filters models.PolicyFilters) []models.PolicyResponse {
+	var matched []*models.Policy
+	for _, policy := range s.repo.GetAllPolicies() {
+		if policy.Matches(filters) {
+			matched = append(matched, policy)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].PolicyNumber != matched[j].PolicyNumber {
+			return matched[i].PolicyNumber < matched[j].PolicyNumber
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	// Apply currency based on feature flags
+	currency := s.flags.GetCurrency()
+	s.logger.WithFields(logrus.Fields{
+		"filters": filters,
+		"count":   len(matched),
+	}).Debug("Listing policies")
+
+	responses := make([]models.PolicyResponse, len(matched))
+	for i, policy := range matched {
+		responses[i] = policy.ToResponse(currency)
+	}
+	return responses
+}
+
+// ListPoliciesPage returns one page of ListPolicies. Pages are numbered from 1.
+func (s *PolicyService) ListPoliciesPage(filters models.PolicyFilters, page, pageSize int) (*models.PolicyPage, error) {
+	if page < 1 {
+		return nil, fmt.Errorf("page must be at least 1")
+	}
+	if pageSize < 1 || pageSize > MaxPageSize {
+		return nil, fmt.Errorf("pageSize must be between 1 and %d", MaxPageSize)
+	}
+
+	all := s.ListPolicies(filters)
+	start := (page - 1) * pageSize
+	if start > len(all) {
+		start = len(all)
+	}
+	end := start + pageSize
+	if end > len(all) {
+		end = len(all)
+	}
+
+	return &models.PolicyPage{
+		Data:     all[start:end],
+		Total:    len(all),
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  end < len(all),
+	}, nil
+}
+
+// GetPolicyByNumber returns the policy with the given number across all
+// customers, for call-center agents who only have the number. Numbers are
+// compared ignoring case, spaces and dashes. Like ListPolicies it does not
+// check ownership.
+func (s *PolicyService) GetPolicyByNumber(policyNumber string) (*models.PolicyResponse, error) {
+	policies := s.repo.GetPoliciesByNumber(policyNumber)
+	switch {
+	case len(policies) == 0:
+		return nil, fmt.Errorf("policy not found")
+	case len(policies) > 1:
+		return nil, fmt.Errorf("policy number %s is used by %d policies", policyNumber, len(policies))
+	}
+
+	response := policies[0].ToResponse(s.flags.GetCurrency())
+	return &response, nil
+}
+
+// SearchPolicyNumbers finds policies across all customers by a full or
+// partial policy number. Numbers read over the phone are often a character
+// off, so numbers within a few edits of the query match too: one edit for
+// queries of 4 to 8 letters and digits, two for longer ones. Shorter
+// queries only match exactly or as a prefix.
+func (s *PolicyService) SearchPolicyNumbers(query string, limit int) (*models.PolicyNumberSearch, error) {
+	normalized := repository.NormalizePolicyNumber(query)
+	if normalized == "" {
+		return nil, fmt.Errorf("q is required")
+	}
+	if limit < 1 || limit > MaxNumberSearchLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxNumberSearchLimit)
+	}
+
+	maxEdits := 0
+	switch {
+	case len(normalized) > 8:
+		maxEdits = 2
+	case len(normalized) >= 4:
+		maxEdits = 1
+	}
+	matches := s.repo.SearchPolicyNumbers(query, maxEdits)
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Match != b.Match {
+			return matchRank[a.Match] < matchRank[b.Match]
+		}
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.Policy.PolicyNumber != b.Policy.PolicyNumber {
+			return a.Policy.PolicyNumber < b.Policy.PolicyNumber
+		}
+		return a.Policy.ID < b.Policy.ID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	currency := s.flags.GetCurrency()
+	result := &models.PolicyNumberSearch{Query: query, Matches: make([]models.PolicyNumberMatch, len(matches))}
+	for i, match := range matches {
+		result.Matches[i] = models.PolicyNumberMatch{
+			Match:    match.Match,
+			Distance: match.Distance,
+			Policy:   match.Policy.ToResponse(currency),
+		}
+	}
+	s.logger.WithFields(logrus.Fields{
+		"query":   query,
+		"matches": len(result.Matches),
+	}).Debug("Searched policy numbers")
+	return result, nil
+}
+
+// matchRank orders policy number matches, best first
+var matchRank = map[string]int{
+	repository.MatchExact:  0,
+	repository.MatchPrefix: 1,
+	repository.MatchFuzzy:  2,
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+)
+
+func TestListPoliciesPage(t *testing.T) {
+	home := samplePolicy("pol-003", "cust-002")
+	home.Type = "home"
+	home.PolicyNumber = "HOME-001"
+	lapsed := samplePolicy("pol-002", "cust-001")
+	lapsed.Status = "lapsed"
+	lapsed.PolicyNumber = "AUTO-002"
+	lapsed.EndDate = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	first := samplePolicy("pol-001", "cust-001")
+	first.PolicyNumber = "AUTO-001"
+	service, _ := newTestService(home, lapsed, first)
+
+	tests := []struct {
+		name    string
+		filters models.PolicyFilters
+		wantIDs []string
+	}{
+		{"all, by policy number", models.PolicyFilters{}, []string{"pol-001", "pol-002", "pol-003"}},
+		{"type", models.PolicyFilters{Type: "home"}, []string{"pol-003"}},
+		{"status", models.PolicyFilters{Status: "lapsed"}, []string{"pol-002"}},
+		{"customer", models.PolicyFilters{CustomerID: "cust-001"}, []string{"pol-001", "pol-002"}},
+		{"expiring before", models.PolicyFilters{ExpiringBefore: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)}, []string{"pol-002"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.ListPoliciesPage(tt.filters, 1, 10)
+			if err != nil {
+				t.Fatalf("ListPoliciesPage failed: %v", err)
+			}
+			if page.Total != len(tt.wantIDs) || len(page.Data) != len(tt.wantIDs) {
+				t.Fatalf("Count mismatch: got %d (total %d), want %d", len(page.Data), page.Total, len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if page.Data[i].ID != id {
+					t.Errorf("Policy %d: got %s, want %s", i, page.Data[i].ID, id)
+				}
+			}
+		})
+	}
+
+	page, err := service.ListPoliciesPage(models.PolicyFilters{}, 2, 2)
+	if err != nil {
+		t.Fatalf("ListPoliciesPage failed: %v", err)
+	}
+	if len(page.Data) != 1 || page.Data[0].ID != "pol-003" || page.HasMore || page.Total != 3 {
+		t.Errorf("Second page mismatch: %+v", page)
+	}
+	if page, _ := service.ListPoliciesPage(models.PolicyFilters{}, 1, 2); !page.HasMore {
+		t.Error("First page should report more results")
+	}
+	if page, _ := service.ListPoliciesPage(models.PolicyFilters{}, 5, 2); len(page.Data) != 0 || page.Total != 3 {
+		t.Errorf("Page past the end should be empty: %+v", page)
+	}
+
+	for _, bad := range [][2]int{{0, 10}, {1, 0}, {1, MaxPageSize + 1}} {
+		if _, err := service.ListPoliciesPage(models.PolicyFilters{}, bad[0], bad[1]); err == nil {
+			t.Errorf("Expected error for page %d size %d", bad[0], bad[1])
+		}
+	}
+}
+
+func TestGetPolicyByNumber(t *testing.T) {
+	first := samplePolicy("pol-001", "cust-001")
+	first.PolicyNumber = "AUTO-2023-001"
+	second := samplePolicy("pol-002", "cust-002")
+	second.PolicyNumber = "HOME-2023-045"
+	reused := samplePolicy("pol-003", "cust-003")
+	reused.PolicyNumber = "home 2023 045"
+	service, _ := newTestService(first, second, reused)
+
+	policy, err := service.GetPolicyByNumber("auto-2023-001")
+	if err != nil || policy.ID != "pol-001" || policy.CustomerID != "cust-001" {
+		t.Errorf("Expected pol-001 by number, got %+v, err %v", policy, err)
+	}
+	if _, err := service.GetPolicyByNumber("AUTO-2023-002"); err == nil || err.Error() != "policy not found" {
+		t.Errorf("Expected policy not found, got %v", err)
+	}
+	if _, err := service.GetPolicyByNumber("HOME-2023-045"); err == nil || err.Error() != "policy number HOME-2023-045 is used by 2 policies" {
+		t.Errorf("Expected a shared number error, got %v", err)
+	}
+}
+
+func TestSearchPolicyNumbers(t *testing.T) {
+	var policies []*models.Policy
+	for i, number := range []string{"AUTO-2023-001", "AUTO-2023-112", "AUTO-2023-011", "HOME-2023-001", "AUTO-2024-003"} {
+		policy := samplePolicy(fmt.Sprintf("pol-%03d", i+1), "cust-001")
+		policy.PolicyNumber = number
+		policies = append(policies, policy)
+	}
+	service, _ := newTestService(policies...)
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []string // policy number and match
+	}{
+		{"prefixes before near misses", "AUTO-2023-01", 10, []string{"AUTO-2023-011 prefix", "AUTO-2023-001 fuzzy", "AUTO-2023-112 fuzzy"}},
+		{"exact", "auto 2023 001", 10, []string{"AUTO-2023-001 exact", "AUTO-2023-011 fuzzy", "AUTO-2024-003 fuzzy"}},
+		{"fewest edits first", "AUTO-2023-0012", 10, []string{"AUTO-2023-001 fuzzy", "AUTO-2023-011 fuzzy", "AUTO-2023-112 fuzzy"}},
+		{"short queries are not fuzzy", "HOM", 10, []string{"HOME-2023-001 prefix"}},
+		{"limit", "AUTO", 2, []string{"AUTO-2023-001 prefix", "AUTO-2023-011 prefix"}},
+		{"no match", "LIFE-2023-089", 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.SearchPolicyNumbers(tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("SearchPolicyNumbers failed: %v", err)
+			}
+			var got []string
+			for _, match := range result.Matches {
+				got = append(got, match.Policy.PolicyNumber+" "+match.Match)
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("Matches: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, bad := range []struct {
+		query   string
+		limit   int
+		wantErr string
+	}{
+		{" - ", 10, "q is required"},
+		{"AUTO", 0, "limit must be between 1 and 50"},
+		{"AUTO", MaxNumberSearchLimit + 1, "limit must be between 1 and 50"},
+	} {
+		if _, err := service.SearchPolicyNumbers(bad.query, bad.limit); err == nil || err.Error() != bad.wantErr {
+			t.Errorf("SearchPolicyNumbers(%q, %d): got %v, want %s", bad.query, bad.limit, err, bad.wantErr)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// RefundIssuer creates premium refunds in payments-service
+type RefundIssuer interface {
+	CreateRefund(ctx context.Context, policyID, customerID string, amount float64) (*clients.Refund, error)
+}
+
+var _ RefundIssuer = (*clients.PaymentsClient)(nil)
+
+// DefaultCancellationNotice is how far ahead the insurer must give notice
+// of a cancellation
+const DefaultCancellationNotice = 10 * 24 * time.Hour
+
+// CancelPolicy cancels a policy, records the premium earned up to the
+// effective date and, when asked, refunds the rest through payments-service.
+// An active policy cancelled from a later date is pending cancellation
+// until then; the status sweep completes it. The refund is issued when the
+// cancellation is requested. A failed refund does not undo the
+// cancellation; it is recorded on the policy for follow-up.
+func (s *PolicyService) CancelPolicy(ctx context.Context, policyID string, customerID string, req models.CancelPolicyRequest) (*models.PolicyResponse, error) {
+	policy, err := s.repo.GetPolicyByID(policyID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"policyId":   policyID,
+			"customerId": customerID,
+		}).Warn("Policy not found")
+		return nil, err
+	}
+
+	// Verify the policy belongs to the requesting customer. The insurer can
+	// cancel any policy.
+	initiatedBy := req.InitiatedBy
+	if initiatedBy == "" {
+		initiatedBy = models.InitiatedByCustomer
+	}
+	if initiatedBy != models.InitiatedByInsurer && policy.CustomerID != customerID {
+		s.logger.WithFields(logrus.Fields{
+			"policyId":   policyID,
+			"customerId": customerID,
+			"ownerId":    policy.CustomerID,
+		}).Warn("Unauthorized cancellation attempt")
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	switch policy.Status {
+	case models.StatusCancelled:
+		return nil, fmt.Errorf("policy already cancelled")
+	case models.StatusPendingCancellation:
+		return nil, fmt.Errorf("cancellation already pending")
+	}
+
+	reasonCode := req.ReasonCode
+	if reasonCode == "" {
+		if initiatedBy == models.InitiatedByInsurer {
+			return nil, fmt.Errorf("reasonCode is required for cancellations by the insurer")
+		}
+		reasonCode = models.CancelReasonCustomerRequest
+	}
+	if err := models.CheckCancelReason(initiatedBy, reasonCode); err != nil {
+		return nil, err
+	}
+
+	// The insurer must give notice before a policy in force stops covering
+	// the customer; customers can cancel straight away
+	now := time.Now()
+	notice := time.Duration(0)
+	if initiatedBy == models.InitiatedByInsurer && policy.Status == models.StatusActive {
+		notice = s.cancellationNotice
+	}
+	effective := now.Add(notice)
+	earliest := truncateDay(effective)
+	if req.EffectiveDate != nil {
+		effective = *req.EffectiveDate
+	}
+	if notice > 0 && effective.Before(earliest) {
+		return nil, fmt.Errorf("effectiveDate must give %d days notice (on or after %s)", int(notice.Hours()/24), earliest.Format("2006-01-02"))
+	}
+
+	cancellation, err := policy.Prorate(effective)
+	if err != nil {
+		return nil, err
+	}
+	cancellation.ReasonCode = reasonCode
+	cancellation.Reason = req.Reason
+	cancellation.InitiatedBy = initiatedBy
+	cancellation.RequestedAt = now
+
+	// Refuse a policy that is no longer in force before any refund is issued
+	change := lifecycle.Change{To: models.StatusCancelled, Trigger: lifecycle.TriggerCancel, Effective: cancellation.EffectiveDate, At: now}
+	if policy.Status == models.StatusActive && cancellation.EffectiveDate.After(now) {
+		change.To = models.StatusPendingCancellation
+	}
+	if err := s.lifecycle.Check(policy, change); err != nil {
+		return nil, err
+	}
+	if req.Refund && s.refunds == nil {
+		return nil, fmt.Errorf("refunds unavailable")
+	}
+
+	if req.Refund && cancellation.UnearnedPremium > 0 {
+		refund, err := s.refunds.CreateRefund(ctx, policy.ID, policy.CustomerID, cancellation.UnearnedPremium)
+		if err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"policyId": policy.ID,
+				"amount":   cancellation.UnearnedPremium,
+			}).Error("Failed to refund unearned premium")
+			cancellation.RefundStatus = models.RefundStatusFailed
+		} else {
+			cancellation.RefundPaymentID = refund.ID
+			cancellation.RefundStatus = models.RefundStatusPending
+		}
+	}
+
+	updated := *policy
+	updated.Cancellation = cancellation
+	if err := s.lifecycle.Fire(&updated, change, s.savePolicy); err != nil {
+		s.logger.WithError(err).WithField("policyId", policyID).Error("Failed to cancel policy")
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"policyId":        policyID,
+		"customerId":      customerID,
+		"status":          updated.Status,
+		"reasonCode":      cancellation.ReasonCode,
+		"initiatedBy":     cancellation.InitiatedBy,
+		"effectiveDate":   cancellation.EffectiveDate.Format("2006-01-02"),
+		"unearnedPremium": cancellation.UnearnedPremium,
+		"refundStatus":    cancellation.RefundStatus,
+	}).Info("Policy cancelled")
+
+	// Apply currency based on feature flags
+	currency := s.flags.GetCurrency()
+	response := updated.ToResponse(currency)
+	return &response, nil
+}
+
+// truncateDay drops the time of day, in UTC
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubRefunds records refund requests instead of calling payments-service
+type stubRefunds struct {
+	amounts []float64
+	err     error
+}
+
+func (s *stubRefunds) CreateRefund(ctx context.Context, policyID, customerID string, amount float64) (*clients.Refund, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.amounts = append(s.amounts, amount)
+	return &clients.Refund{ID: "pay-refund-1", Type: "refund", PolicyID: policyID, CustomerID: customerID, Amount: amount, Status: "pending"}, nil
+}
+
+func newRefundingService(refunds RefundIssuer, policies ...*models.Policy) (*PolicyService, *repositorytest.FakeStore) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(policies...)
+	return NewPolicyService(store, nil, refunds, nil, nil, nil, 0, logger), store
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &t
+}
+
+func TestProrate(t *testing.T) {
+	// 2024 is a leap year: the sample term is 366 days
+	tests := []struct {
+		name         string
+		effective    time.Time
+		wantUnused   int
+		wantUnearned float64
+		wantErr      string
+	}{
+		{"mid-term", *date(2024, 7, 1), 184, 628.42, ""},
+		{"first day", *date(2024, 1, 1), 366, 1250, ""},
+		{"before inception", *date(2023, 12, 1), 366, 1250, ""},
+		{"last day", *date(2024, 12, 31), 1, 3.42, ""},
+		{"time of day ignored", time.Date(2024, 7, 1, 18, 30, 0, 0, time.UTC), 184, 628.42, ""},
+		{"at end date", *date(2025, 1, 1), 0, 0, "cancellation date must be before the policy end date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := samplePolicy("pol-001", "cust-001").Prorate(tt.effective)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Error mismatch: got %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prorate failed: %v", err)
+			}
+			if c.TermDays != 366 || c.UnusedDays != tt.wantUnused || c.UnearnedPremium != tt.wantUnearned {
+				t.Errorf("Unexpected proration: %+v", c)
+			}
+			if !samePremium(c.EarnedPremium+c.UnearnedPremium, 1250) {
+				t.Errorf("Earned %.2f and unearned %.2f do not add up to the premium", c.EarnedPremium, c.UnearnedPremium)
+			}
+		})
+	}
+}
+
+func TestCancelPolicyRefundsUnearnedPremium(t *testing.T) {
+	refunds := &stubRefunds{}
+	service, store := newRefundingService(refunds, samplePolicy("pol-001", "cust-001"))
+
+	resp, err := service.CancelPolicy(context.Background(), "pol-001", "cust-001", models.CancelPolicyRequest{
+		EffectiveDate: date(2024, 7, 1),
+		Reason:        "sold vehicle",
+		Refund:        true,
+	})
+	if err != nil {
+		t.Fatalf("CancelPolicy failed: %v", err)
+	}
+	if resp.Status != "cancelled" || resp.Cancellation == nil || resp.Cancellation.UnearnedPremium != 628.42 {
+		t.Errorf("Unexpected cancellation response: %+v", resp)
+	}
+	if len(refunds.amounts) != 1 || refunds.amounts[0] != 628.42 {
+		t.Errorf("Expected one refund of 628.42, got %v", refunds.amounts)
+	}
+
+	stored, _ := store.GetPolicyByID("pol-001")
+	c := stored.Cancellation
+	if c == nil || c.RefundPaymentID != "pay-refund-1" || c.RefundStatus != models.RefundStatusPending || c.Reason != "sold vehicle" {
+		t.Errorf("Cancellation not persisted: %+v", c)
+	}
+
+	if _, err := service.CancelPolicy(context.Background(), "pol-001", "cust-001", models.CancelPolicyRequest{}); err == nil || err.Error() != "policy already cancelled" {
+		t.Errorf("Expected policy already cancelled, got %v", err)
+	}
+}
+
+func TestCancelPolicyKeepsCancellationWhenRefundFails(t *testing.T) {
+	service, store := newRefundingService(&stubRefunds{err: errors.New("payments-service returned status 500")}, samplePolicy("pol-001", "cust-001"))
+
+	if _, err := service.CancelPolicy(context.Background(), "pol-001", "cust-001", models.CancelPolicyRequest{EffectiveDate: date(2024, 7, 1), Refund: true}); err != nil {
+		t.Fatalf("CancelPolicy failed: %v", err)
+	}
+
+	stored, _ := store.GetPolicyByID("pol-001")
+	if stored.Status != "cancelled" || stored.Cancellation.RefundStatus != models.RefundStatusFailed || stored.Cancellation.RefundPaymentID != "" {
+		t.Errorf("Expected cancelled policy with failed refund, got %+v", stored.Cancellation)
+	}
+}
+
+func TestCancelPolicyRejections(t *testing.T) {
+	service, store := newTestService(samplePolicy("pol-001", "cust-001"))
+	ctx := context.Background()
+
+	if _, err := service.CancelPolicy(ctx, "pol-001", "cust-002", models.CancelPolicyRequest{}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+	if _, err := service.CancelPolicy(ctx, "pol-001", "cust-001", models.CancelPolicyRequest{EffectiveDate: date(2024, 7, 1), Refund: true}); err == nil || err.Error() != "refunds unavailable" {
+		t.Errorf("Expected refunds unavailable, got %v", err)
+	}
+
+	stored, _ := store.GetPolicyByID("pol-001")
+	if stored.Status != "active" || stored.Cancellation != nil {
+		t.Errorf("Rejected cancellation changed the policy: %+v", stored)
+	}
+}
+
+func TestCancelPolicyWithNotice(t *testing.T) {
+	today := truncateDay(time.Now())
+	current := samplePolicy("pol-001", "cust-001")
+	current.StartDate, current.EndDate = today.AddDate(0, 0, -100), today.AddDate(0, 0, 265)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(current)
+	service := NewPolicyService(store, nil, nil, nil, nil, nil, DefaultCancellationNotice, logger)
+	ctx := context.Background()
+
+	rejected := []struct {
+		name    string
+		req     models.CancelPolicyRequest
+		wantErr string
+	}{
+		{"insurer without reason", models.CancelPolicyRequest{InitiatedBy: models.InitiatedByInsurer}, "reasonCode is required for cancellations by the insurer"},
+		{"customer with insurer reason", models.CancelPolicyRequest{ReasonCode: models.CancelReasonFraud}, "reasonCode fraud cannot be given by the customer"},
+		{"short notice", models.CancelPolicyRequest{InitiatedBy: models.InitiatedByInsurer, ReasonCode: models.CancelReasonNonPayment, EffectiveDate: &today},
+			"effectiveDate must give 10 days notice (on or after " + today.AddDate(0, 0, 10).Format("2006-01-02") + ")"},
+	}
+	for _, tt := range rejected {
+		if _, err := service.CancelPolicy(ctx, "pol-001", "cust-001", tt.req); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.wantErr)
+		}
+	}
+
+	resp, err := service.CancelPolicy(ctx, "pol-001", "staff-001", models.CancelPolicyRequest{InitiatedBy: models.InitiatedByInsurer, ReasonCode: models.CancelReasonNonPayment})
+	if err != nil {
+		t.Fatalf("CancelPolicy failed: %v", err)
+	}
+	c := resp.Cancellation
+	if resp.Status != models.StatusPendingCancellation || c == nil || !c.EffectiveDate.Equal(today.AddDate(0, 0, 10)) || c.ReasonCode != models.CancelReasonNonPayment || c.InitiatedBy != models.InitiatedByInsurer || c.CancelledAt != nil {
+		t.Fatalf("Expected a cancellation pending for 10 days, got %+v %+v", resp, c)
+	}
+	if _, err := service.CancelPolicy(ctx, "pol-001", "cust-001", models.CancelPolicyRequest{}); err == nil || err.Error() != "cancellation already pending" {
+		t.Errorf("Expected cancellation already pending, got %v", err)
+	}
+
+	sweeper := NewGraceSweeper(store, DefaultGracePeriod, logger)
+	if result := sweeper.Sweep(time.Now()); len(result.Cancelled) != 0 {
+		t.Errorf("Cancellation took effect early: %+v", result)
+	}
+	if result := sweeper.Sweep(today.AddDate(0, 0, 10)); len(result.Cancelled) != 1 {
+		t.Errorf("Expected the cancellation to take effect, got %+v", result)
+	}
+	stored, _ := store.GetPolicyByID("pol-001")
+	if stored.Status != models.StatusCancelled || stored.Cancellation.CancelledAt == nil || stored.Cancellation.ReasonCode != models.CancelReasonNonPayment {
+		t.Errorf("Expected the policy cancelled at its effective date, got %+v %+v", stored, stored.Cancellation)
+	}
+}
+
+func TestCustomerCancellationDefaults(t *testing.T) {
+	service, _ := newTestService(samplePolicy("pol-001", "cust-001"))
+
+	resp, err := service.CancelPolicy(context.Background(), "pol-001", "cust-001", models.CancelPolicyRequest{EffectiveDate: date(2024, 7, 1)})
+	if err != nil {
+		t.Fatalf("CancelPolicy failed: %v", err)
+	}
+	c := resp.Cancellation
+	if resp.Status != models.StatusCancelled || c.ReasonCode != models.CancelReasonCustomerRequest || c.InitiatedBy != models.InitiatedByCustomer || c.CancelledAt == nil {
+		t.Errorf("Unexpected customer cancellation: %+v", c)
+	}
+}
+
+// samePremium compares money amounts to the cent
+func samePremium(a, b float64) bool {
+	d := a - b
+	return d < 0.005 && d > -0.005
+}
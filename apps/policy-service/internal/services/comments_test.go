@@ -0,0 +1,51 @@
+package services
+
+import (
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPolicyComments(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := NewCommentService(repositorytest.NewFakeStore(samplePolicy("pol-001", "cust-001")), logger)
+
+	staff := models.CommentAuthor{ID: "adm-001", Role: "admin"}
+	holder := models.CommentAuthor{ID: "cust-001", Role: models.CommentRoleCustomer}
+
+	note, err := service.AddComment("pol-001", staff, &models.CreateCommentRequest{Body: "Customer asked about adding a teen driver"})
+	if err != nil || note.Visibility != models.CommentInternal || note.PolicyID != "pol-001" {
+		t.Fatalf("Staff note: got %+v, %v", note, err)
+	}
+	shared, err := service.AddComment("pol-001", staff, &models.CreateCommentRequest{Body: "Renewal documents sent", Visibility: models.CommentCustomer})
+	if err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+
+	visible, err := service.GetComments("pol-001", holder)
+	if err != nil || len(visible) != 1 || visible[0].ID != shared.ID {
+		t.Errorf("Policyholder should only see the shared comment, got %+v, %v", visible, err)
+	}
+	if _, err := service.GetComments("pol-001", models.CommentAuthor{ID: "cust-002", Role: models.CommentRoleCustomer}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Other customer: got %v", err)
+	}
+	if _, err := service.GetComments("pol-404", staff); err == nil || err.Error() != "policy not found" {
+		t.Errorf("Unknown policy: got %v", err)
+	}
+
+	// Hiding a shared comment again is an edit like any other
+	edited, err := service.UpdateComment("pol-001", shared.ID, staff, &models.UpdateCommentRequest{Body: shared.Body, Visibility: models.CommentInternal})
+	if err != nil || len(edited.Edits) != 1 || edited.Edits[0].Visibility != models.CommentCustomer {
+		t.Fatalf("UpdateComment: got %+v, %v", edited, err)
+	}
+	if visible, _ := service.GetComments("pol-001", holder); len(visible) != 0 {
+		t.Errorf("Hidden comment still visible: %+v", visible)
+	}
+	if _, err := service.UpdateComment("pol-001", shared.ID, holder, &models.UpdateCommentRequest{Body: "edited"}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Edit by non-author: got %v", err)
+	}
+}
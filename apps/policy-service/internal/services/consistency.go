@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// CustomerLookup reads customers and their households from
+// customer-service. *clients.CustomerClient is the production
+// implementation.
+type CustomerLookup interface {
+	GetCustomer(ctx context.Context, customerID string) (*clients.Customer, error)
+	HouseholdMembers(ctx context.Context, customerID string) ([]string, error)
+}
+
+var _ CustomerLookup = (*clients.CustomerClient)(nil)
+
+// ConsistencyChecker cross-validates the references held by policies
+// against the services that own the referenced records
+type ConsistencyChecker struct {
+	repo      repository.PolicyStore
+	customers CustomerLookup
+	logger    *logrus.Logger
+}
+
+// NewConsistencyChecker creates a checker. customers may be nil when
+// customer-service is not configured; those checks are then reported as skipped.
+func NewConsistencyChecker(repo repository.PolicyStore, customers CustomerLookup, logger *logrus.Logger) *ConsistencyChecker {
+	return &ConsistencyChecker{
+		repo:      repo,
+		customers: customers,
+		logger:    logger,
+	}
+}
+
+// Check builds a consistency report for every stored policy
+func (c *ConsistencyChecker) Check(ctx context.Context) *models.ConsistencyReport {
+	report := models.NewConsistencyReport("policy-service")
+
+	policies := c.repo.GetAllPolicies()
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+	report.Checked = len(policies)
+
+	// Checks within this service
+	numbers := make(map[string]string, len(policies))
+	for _, policy := range policies {
+		if policy.PolicyNumber == "" {
+			continue
+		}
+		if first, ok := numbers[policy.PolicyNumber]; ok {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityWarning,
+				Check:     "policy.number_unique",
+				EntityID:  policy.ID,
+				Reference: first,
+				Message:   fmt.Sprintf("policy number %s is also used by another policy", policy.PolicyNumber),
+			})
+			continue
+		}
+		numbers[policy.PolicyNumber] = policy.ID
+	}
+
+	// References owned by customer-service
+	if c.customers == nil {
+		report.Add(models.ConsistencyIssue{
+			Severity: models.SeverityInfo,
+			Check:    "policy.customer_exists",
+			Message:  "customer-service is not configured; customer references were not checked",
+		})
+	} else {
+		c.checkCustomers(ctx, policies, report)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"checked":  report.Checked,
+		"errors":   report.Summary[models.SeverityError],
+		"warnings": report.Summary[models.SeverityWarning],
+	}).Info("Consistency check completed")
+
+	return report
+}
+
+// checkCustomers resolves each policyholder once. The check stops at the
+// first transport failure rather than reporting every policy as orphaned.
+func (c *ConsistencyChecker) checkCustomers(ctx context.Context, policies []*models.Policy, report *models.ConsistencyReport) {
+	found := make(map[string]bool)
+	for _, policy := range policies {
+		exists, seen := found[policy.CustomerID]
+		if !seen {
+			_, err := c.customers.GetCustomer(ctx, policy.CustomerID)
+			switch {
+			case err == nil:
+				exists = true
+			case err.Error() == "customer not found":
+				exists = false
+			default:
+				c.logger.WithError(err).Warn("Customer lookup failed, skipping remaining customer checks")
+				report.Add(models.ConsistencyIssue{
+					Severity: models.SeverityInfo,
+					Check:    "policy.customer_exists",
+					Message:  fmt.Sprintf("customer-service unavailable, remaining customer references not checked: %v", err),
+				})
+				return
+			}
+			found[policy.CustomerID] = exists
+		}
+
+		if !exists {
+			report.Add(models.ConsistencyIssue{
+				Severity:  models.SeverityError,
+				Check:     "policy.customer_exists",
+				EntityID:  policy.ID,
+				Reference: policy.CustomerID,
+				Message:   "policy is held by a customer that does not exist",
+			})
+		}
+	}
+}
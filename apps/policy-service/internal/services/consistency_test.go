@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// stubCustomers answers customer lookups from a set of known IDs, of which
+// those in verified have a verified email, those in kyc have the given KYC
+// status and those in households share policies with the listed members
+type stubCustomers struct {
+	known      map[string]bool
+	verified   map[string]bool
+	kyc        map[string]string
+	households map[string][]string
+	calls      int
+	err        error
+}
+
+func (s *stubCustomers) GetCustomer(ctx context.Context, customerID string) (*clients.Customer, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	if !s.known[customerID] {
+		return nil, errors.New("customer not found")
+	}
+	return &clients.Customer{ID: customerID, EmailVerified: s.verified[customerID], KYCStatus: s.kyc[customerID]}, nil
+}
+
+func (s *stubCustomers) HouseholdMembers(ctx context.Context, customerID string) ([]string, error) {
+	if _, err := s.GetCustomer(ctx, customerID); err != nil {
+		return nil, err
+	}
+	if members, ok := s.households[customerID]; ok {
+		return members, nil
+	}
+	return []string{customerID}, nil
+}
+
+func TestConsistencyCheck(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	first := samplePolicy("pol-001", "cust-001")
+	first.PolicyNumber = "AUTO-001"
+	second := samplePolicy("pol-002", "cust-001")
+	second.PolicyNumber = "AUTO-002"
+	orphan := samplePolicy("pol-003", "cust-404")
+	orphan.PolicyNumber = "AUTO-003"
+	reused := samplePolicy("pol-004", "cust-404")
+	reused.PolicyNumber = "AUTO-001"
+	store := repositorytest.NewFakeStore(first, second, orphan, reused)
+	customers := &stubCustomers{known: map[string]bool{"cust-001": true}}
+
+	report := NewConsistencyChecker(store, customers, logger).Check(context.Background())
+
+	if report.Checked != 4 {
+		t.Errorf("Checked mismatch: got %d, want 4", report.Checked)
+	}
+	if customers.calls != 2 {
+		t.Errorf("Expected one lookup per customer, got %d", customers.calls)
+	}
+	got := map[string][]string{}
+	for _, issue := range report.Issues {
+		got[issue.EntityID] = append(got[issue.EntityID], issue.Check)
+	}
+	if len(got["pol-001"]) > 0 || len(got["pol-002"]) > 0 {
+		t.Errorf("Consistent policies reported: %v", got)
+	}
+	if len(got["pol-003"]) != 1 || got["pol-003"][0] != "policy.customer_exists" {
+		t.Errorf("Issues for pol-003: got %v", got["pol-003"])
+	}
+	if len(got["pol-004"]) != 2 || got["pol-004"][0] != "policy.number_unique" || got["pol-004"][1] != "policy.customer_exists" {
+		t.Errorf("Issues for pol-004: got %v", got["pol-004"])
+	}
+	if report.Summary[models.SeverityError] != 2 || report.Summary[models.SeverityWarning] != 1 {
+		t.Errorf("Summary mismatch: %v", report.Summary)
+	}
+}
+
+func TestConsistencyCheckWithoutCustomerService(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(samplePolicy("pol-001", "cust-001"), samplePolicy("pol-002", "cust-002"))
+
+	for name, customers := range map[string]CustomerLookup{
+		"not configured": nil,
+		"unreachable":    &stubCustomers{err: errors.New("customer-service request failed: connection refused")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			report := NewConsistencyChecker(store, customers, logger).Check(context.Background())
+			if len(report.Issues) != 1 || report.Issues[0].Severity != models.SeverityInfo {
+				t.Errorf("Expected a single skipped-check notice, got %+v", report.Issues)
+			}
+		})
+	}
+}
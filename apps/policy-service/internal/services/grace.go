@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultGracePeriod is how long a policy past its end date can still be
+// reinstated before it lapses
+const DefaultGracePeriod = 30 * 24 * time.Hour
+
+// GraceSweeper keeps policy statuses in line with their dates: pending
+// policies become active at their start date, and at the end date active
+// policies expire or enter grace, then lapse once grace runs out, and
+// pending cancellations take effect at their effective date. It runs on a
+// schedule and can be triggered by staff.
+type GraceSweeper struct {
+	repo      repository.PolicyStore
+	period    time.Duration
+	lifecycle *lifecycle.Machine
+	mu        sync.Mutex // one sweep at a time
+	logger    *logrus.Logger
+}
+
+// NewGraceSweeper creates a sweeper. A zero period lapses policies as soon
+// as they pass their end date.
+func NewGraceSweeper(repo repository.PolicyStore, period time.Duration, logger *logrus.Logger) *GraceSweeper {
+	machine := lifecycle.Policies()
+	machine.OnTransition(logTransition(logger))
+	return &GraceSweeper{
+		repo:      repo,
+		period:    period,
+		lifecycle: machine,
+		logger:    logger,
+	}
+}
+
+// Sweep applies the status changes policies are due as of now
+func (g *GraceSweeper) Sweep(now time.Time) *models.GraceSweepResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	policies := g.repo.GetAllPolicies()
+	sort.Slice(policies, func(i, j int) bool { return policies[i].ID < policies[j].ID })
+
+	result := &models.GraceSweepResult{
+		Checked:      len(policies),
+		Activated:    []string{},
+		EnteredGrace: []string{},
+		Lapsed:       []string{},
+		Expired:      []string{},
+		Cancelled:    []string{},
+		SweptAt:      now,
+	}
+	for _, policy := range policies {
+		// Catch up one change at a time, so a policy left pending past its
+		// whole term still passes through each status on the way. Each change
+		// works on a copy so readers never see a half-applied transition.
+		current := policy
+		for {
+			change, ok := lifecycle.Due(current, now, g.period)
+			if !ok {
+				break
+			}
+			updated := *current
+			if err := g.lifecycle.Fire(&updated, change, g.save); err != nil {
+				g.logger.WithError(err).WithField("policyId", policy.ID).Error("Failed to apply policy status change")
+				break
+			}
+			current = &updated
+
+			switch updated.Status {
+			case models.StatusActive:
+				result.Activated = append(result.Activated, policy.ID)
+			case models.StatusGrace:
+				result.EnteredGrace = append(result.EnteredGrace, policy.ID)
+			case models.StatusLapsed:
+				result.Lapsed = append(result.Lapsed, policy.ID)
+			case models.StatusExpired:
+				result.Expired = append(result.Expired, policy.ID)
+			case models.StatusCancelled:
+				result.Cancelled = append(result.Cancelled, policy.ID)
+			}
+		}
+	}
+
+	g.logger.WithFields(logrus.Fields{
+		"checked":      result.Checked,
+		"activated":    len(result.Activated),
+		"enteredGrace": len(result.EnteredGrace),
+		"lapsed":       len(result.Lapsed),
+		"expired":      len(result.Expired),
+		"cancelled":    len(result.Cancelled),
+	}).Info("Policy status sweep completed")
+
+	return result
+}
+
+// save stores a policy changed by the lifecycle
+func (g *GraceSweeper) save(policy *models.Policy) error {
+	_, err := g.repo.UpdatePolicy(policy)
+	return err
+}
+
+// Run sweeps every interval until ctx is cancelled
+func (g *GraceSweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			g.Sweep(now)
+		}
+	}
+}
+
+// ReinstatePolicy restores cover for a policy in its grace period. Cover
+// continues from the old end date, so the policy is treated as never having
+// been out of force.
+func (s *PolicyService) ReinstatePolicy(policyID string, customerID string, req models.ReinstatePolicyRequest) (*models.PolicyResponse, error) {
+	policy, err := s.repo.GetPolicyByID(policyID)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"policyId":   policyID,
+			"customerId": customerID,
+		}).Warn("Policy not found")
+		return nil, err
+	}
+
+	// Verify the policy belongs to the requesting customer
+	if policy.CustomerID != customerID {
+		s.logger.WithFields(logrus.Fields{
+			"policyId":   policyID,
+			"customerId": customerID,
+			"ownerId":    policy.CustomerID,
+		}).Warn("Unauthorized reinstatement attempt")
+		return nil, fmt.Errorf("unauthorized")
+	}
+
+	now := time.Now()
+	switch {
+	case policy.Status == models.StatusLapsed:
+		return nil, fmt.Errorf("grace period has ended")
+	case policy.Status != models.StatusGrace:
+		return nil, fmt.Errorf("policy is not in grace")
+	case policy.GraceEndsAt != nil && !now.Before(*policy.GraceEndsAt):
+		return nil, fmt.Errorf("grace period has ended")
+	}
+
+	endDate := policy.EndDate.Add(policy.EndDate.Sub(policy.StartDate))
+	if req.EndDate != nil {
+		endDate = *req.EndDate
+	}
+	if !endDate.After(policy.EndDate) {
+		return nil, fmt.Errorf("endDate must be after the current end date")
+	}
+
+	// Reinstating renews the policy, so it no longer expires at the new end
+	updated := *policy
+	updated.RenewalDate = policy.EndDate
+	updated.EndDate = endDate
+	updated.NonRenewing = false
+	updated.ReinstatedAt = &now
+
+	change := lifecycle.Change{To: models.StatusActive, Trigger: lifecycle.TriggerReinstate, At: now}
+	if err := s.lifecycle.Fire(&updated, change, s.savePolicy); err != nil {
+		s.logger.WithError(err).WithField("policyId", policyID).Error("Failed to reinstate policy")
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"policyId":   policyID,
+		"customerId": customerID,
+		"coverFrom":  policy.EndDate.Format("2006-01-02"),
+		"endDate":    endDate.Format("2006-01-02"),
+	}).Info("Policy reinstated")
+
+	// Apply currency based on feature flags
+	currency := s.flags.GetCurrency()
+	response := updated.ToResponse(currency)
+	return &response, nil
+}
+
+// LapsePolicy lapses a policy in force for non-payment, when its premium
+// was taken back after it was paid. It is for staff and other services, so
+// the policy's owner is not checked.
+func (s *PolicyService) LapsePolicy(policyID string, req models.LapsePolicyRequest) (*models.PolicyResponse, error) {
+	policy, err := s.repo.GetPolicyByID(policyID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *policy
+	change := lifecycle.Change{To: models.StatusLapsed, Trigger: lifecycle.TriggerNonPayment, Reason: strings.TrimSpace(req.Reason)}
+	if err := s.lifecycle.Fire(&updated, change, s.savePolicy); err != nil {
+		s.logger.WithError(err).WithField("policyId", policyID).Warn("Failed to lapse policy for non-payment")
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"policyId":   policyID,
+		"customerId": policy.CustomerID,
+		"reason":     updated.LapseReason,
+	}).Info("Policy lapsed for non-payment")
+
+	response := updated.ToResponse(s.flags.GetCurrency())
+	return &response, nil
+}
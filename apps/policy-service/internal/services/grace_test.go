@@ -0,0 +1,187 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// termEnding returns a sample policy whose one-year term ends at end
+func termEnding(id, status string, end time.Time) *models.Policy {
+	p := samplePolicy(id, "cust-001")
+	p.Status = status
+	p.StartDate = end.AddDate(-1, 0, 0)
+	p.EndDate = end
+	return p
+}
+
+func TestGraceSweep(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	earlierGraceEnd := now.Add(-time.Hour)
+
+	inGrace := termEnding("pol-004", models.StatusGrace, now.AddDate(0, 0, -20))
+	inGrace.GraceEndsAt = &earlierGraceEnd
+
+	store := repositorytest.NewFakeStore(
+		termEnding("pol-001", models.StatusActive, now.AddDate(0, 0, 10)),  // still in term
+		termEnding("pol-002", models.StatusActive, now.AddDate(0, 0, -10)), // within 30 days of end
+		termEnding("pol-003", models.StatusActive, now.AddDate(0, 0, -45)), // past grace already
+		inGrace, // grace ran out an hour ago
+		termEnding("pol-005", models.StatusCancelled, now.AddDate(0, 0, -45)),
+	)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	sweeper := NewGraceSweeper(store, DefaultGracePeriod, logger)
+
+	result := sweeper.Sweep(now)
+	if result.Checked != 5 || len(result.EnteredGrace) != 1 || result.EnteredGrace[0] != "pol-002" {
+		t.Errorf("Unexpected grace transitions: %+v", result)
+	}
+	if len(result.Lapsed) != 2 || result.Lapsed[0] != "pol-003" || result.Lapsed[1] != "pol-004" {
+		t.Errorf("Unexpected lapses: %+v", result.Lapsed)
+	}
+
+	wantStatus := map[string]string{
+		"pol-001": models.StatusActive,
+		"pol-002": models.StatusGrace,
+		"pol-003": models.StatusLapsed,
+		"pol-004": models.StatusLapsed,
+		"pol-005": models.StatusCancelled,
+	}
+	for id, want := range wantStatus {
+		if p, _ := store.GetPolicyByID(id); p.Status != want {
+			t.Errorf("%s status: got %s, want %s", id, p.Status, want)
+		}
+	}
+
+	grace, _ := store.GetPolicyByID("pol-002")
+	if grace.GraceEndsAt == nil || !grace.GraceEndsAt.Equal(grace.EndDate.Add(DefaultGracePeriod)) {
+		t.Errorf("Grace end not recorded: %v", grace.GraceEndsAt)
+	}
+	lapsed, _ := store.GetPolicyByID("pol-004")
+	if lapsed.LapsedAt == nil || !lapsed.LapsedAt.Equal(earlierGraceEnd) || lapsed.GraceEndsAt != nil {
+		t.Errorf("Lapse should be dated at the end of grace: %+v", lapsed)
+	}
+
+	// A second sweep at the same time changes nothing
+	if again := sweeper.Sweep(now); len(again.EnteredGrace) != 0 || len(again.Lapsed) != 0 {
+		t.Errorf("Sweep is not idempotent: %+v", again)
+	}
+}
+
+func TestGraceSweepWithoutGracePeriodLapsesImmediately(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	store := repositorytest.NewFakeStore(termEnding("pol-001", models.StatusActive, now.AddDate(0, 0, -1)))
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	result := NewGraceSweeper(store, 0, logger).Sweep(now)
+	if len(result.Lapsed) != 1 || len(result.EnteredGrace) != 0 {
+		t.Errorf("Expected an immediate lapse, got %+v", result)
+	}
+}
+
+func TestReinstatePolicy(t *testing.T) {
+	end := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -5)
+	graceEnds := end.Add(DefaultGracePeriod)
+	grace := termEnding("pol-001", models.StatusGrace, end)
+	grace.GraceEndsAt = &graceEnds
+
+	service, store := newTestService(grace, termEnding("pol-002", models.StatusLapsed, end), termEnding("pol-003", models.StatusActive, end.AddDate(1, 0, 0)))
+
+	if _, err := service.ReinstatePolicy("pol-001", "cust-002", models.ReinstatePolicyRequest{}); err == nil || err.Error() != "unauthorized" {
+		t.Errorf("Expected unauthorized, got %v", err)
+	}
+	if _, err := service.ReinstatePolicy("pol-002", "cust-001", models.ReinstatePolicyRequest{}); err == nil || err.Error() != "grace period has ended" {
+		t.Errorf("Expected grace period has ended, got %v", err)
+	}
+	if _, err := service.ReinstatePolicy("pol-003", "cust-001", models.ReinstatePolicyRequest{}); err == nil || err.Error() != "policy is not in grace" {
+		t.Errorf("Expected policy is not in grace, got %v", err)
+	}
+	if _, err := service.ReinstatePolicy("pol-001", "cust-001", models.ReinstatePolicyRequest{EndDate: &end}); err == nil || err.Error() != "endDate must be after the current end date" {
+		t.Errorf("Expected endDate validation, got %v", err)
+	}
+
+	resp, err := service.ReinstatePolicy("pol-001", "cust-001", models.ReinstatePolicyRequest{})
+	if err != nil {
+		t.Fatalf("ReinstatePolicy failed: %v", err)
+	}
+	if resp.Status != models.StatusActive || !resp.RenewalDate.Equal(end) {
+		t.Errorf("Unexpected reinstated policy: %+v", resp)
+	}
+
+	// Cover continues from the old end date for another term of the same length
+	stored, _ := store.GetPolicyByID("pol-001")
+	if !stored.EndDate.Equal(end.Add(end.Sub(end.AddDate(-1, 0, 0)))) || stored.GraceEndsAt != nil || stored.ReinstatedAt == nil {
+		t.Errorf("Reinstatement not persisted: %+v", stored)
+	}
+}
+
+func TestLapsePolicyForNonPayment(t *testing.T) {
+	end := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 6, 0)
+	service, store := newTestService(termEnding("pol-001", models.StatusActive, end), termEnding("pol-002", models.StatusCancelled, end))
+
+	if _, err := service.LapsePolicy("pol-001", models.LapsePolicyRequest{}); err == nil {
+		t.Error("Expected a lapse without a reason to be refused")
+	}
+	if _, err := service.LapsePolicy("pol-002", models.LapsePolicyRequest{Reason: "chargeback lost"}); err == nil {
+		t.Error("Expected a cancelled policy not to lapse")
+	}
+	if _, err := service.LapsePolicy("pol-404", models.LapsePolicyRequest{Reason: "chargeback lost"}); err == nil {
+		t.Error("Expected an unknown policy to be refused")
+	}
+
+	// Lapses mid-term, without waiting for grace
+	resp, err := service.LapsePolicy("pol-001", models.LapsePolicyRequest{Reason: "premium payment pay-001 charged back"})
+	if err != nil {
+		t.Fatalf("LapsePolicy failed: %v", err)
+	}
+	if resp.Status != models.StatusLapsed || resp.LapseReason != "premium payment pay-001 charged back" {
+		t.Errorf("Unexpected lapsed policy: %+v", resp)
+	}
+	stored, _ := store.GetPolicyByID("pol-001")
+	if stored.LapsedAt == nil || !stored.LapsedAt.Before(end) || stored.GraceEndsAt != nil {
+		t.Errorf("Lapse not persisted: %+v", stored)
+	}
+}
+
+func TestGraceSweepFollowsPolicyDates(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	starting := termEnding("pol-001", models.StatusPending, now.AddDate(1, 0, -1)) // started yesterday
+	notStarted := termEnding("pol-002", models.StatusPending, now.AddDate(1, 0, 1))
+	forgotten := termEnding("pol-003", models.StatusPending, now.AddDate(0, 0, -45)) // whole term passed while pending
+	notRenewing := termEnding("pol-004", models.StatusGrace, now.AddDate(0, 0, -5))
+	notRenewing.NonRenewing = true
+
+	store := repositorytest.NewFakeStore(starting, notStarted, forgotten, notRenewing)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	result := NewGraceSweeper(store, DefaultGracePeriod, logger).Sweep(now)
+	if len(result.Activated) != 2 || result.Activated[0] != "pol-001" || result.Activated[1] != "pol-003" {
+		t.Errorf("Unexpected activations: %+v", result.Activated)
+	}
+	if len(result.Lapsed) != 1 || result.Lapsed[0] != "pol-003" || len(result.EnteredGrace) != 0 {
+		t.Errorf("A policy past grace should lapse in the same sweep: %+v", result)
+	}
+	if len(result.Expired) != 1 || result.Expired[0] != "pol-004" {
+		t.Errorf("Unexpected expiries: %+v", result.Expired)
+	}
+
+	wantStatus := map[string]string{
+		"pol-001": models.StatusActive,
+		"pol-002": models.StatusPending,
+		"pol-003": models.StatusLapsed,
+		"pol-004": models.StatusExpired,
+	}
+	for id, want := range wantStatus {
+		if p, _ := store.GetPolicyByID(id); p.Status != want {
+			t.Errorf("%s status: got %s, want %s", id, p.Status, want)
+		}
+	}
+}
@@ -0,0 +1,513 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxImportRows is the most rows an import file may hold
+const MaxImportRows = 100000
+
+// requiredImportFields are the policy fields every row must have
+var requiredImportFields = models.ImportFields[:7]
+
+// ImportService migrates books of business from legacy systems: it reads
+// CSV files of policies through a column mapping, validates every row and
+// creates the policies of the valid ones. Imports run in the background so
+// large files can be followed while they run, and are kept in memory with
+// their reports until the service restarts.
+type ImportService struct {
+	repo      repository.PolicyStore
+	customers CustomerLookup
+	logger    *logrus.Logger
+
+	ctx    context.Context // cancelled by Stop
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	imports map[string]*policyImport
+	nextID  int
+}
+
+// policyImport is an import and the outcomes of the rows read so far
+type policyImport struct {
+	models.PolicyImport
+	rows []models.ImportRow
+}
+
+// importRecord is a row of an import file
+type importRecord struct {
+	line   int
+	fields []string
+}
+
+// NewImportService creates an import service. customers may be nil when
+// customer-service is not configured; imports are then refused, as the
+// policyholders could not be checked.
+func NewImportService(repo repository.PolicyStore, customers CustomerLookup, logger *logrus.Logger) *ImportService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ImportService{
+		repo:      repo,
+		customers: customers,
+		logger:    logger,
+		ctx:       ctx,
+		cancel:    cancel,
+		imports:   make(map[string]*policyImport),
+		nextID:    1,
+	}
+}
+
+// Start reads data as CSV through mapping, nil reading the columns named
+// after the policy fields, and imports its rows in the background. A
+// mapping or file that cannot be read is refused before anything runs.
+func (s *ImportService) Start(data []byte, mapping *models.ImportMapping, opts models.ImportOptions) (*models.PolicyImport, error) {
+	if s.customers == nil {
+		return nil, fmt.Errorf("customer-service not configured")
+	}
+	if mapping == nil {
+		mapping = &models.ImportMapping{}
+	}
+	plan, err := newImportPlan(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mapping: %w", err)
+	}
+	records, err := plan.read(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	job := &policyImport{
+		PolicyImport: models.PolicyImport{
+			ID:        fmt.Sprintf("imp-%03d", s.nextID),
+			Status:    models.ImportRunning,
+			DryRun:    opts.DryRun,
+			FileName:  opts.FileName,
+			TotalRows: len(records),
+			StartedBy: opts.StartedBy,
+			StartedAt: time.Now(),
+		},
+		rows: []models.ImportRow{},
+	}
+	s.imports[job.ID] = job
+	s.nextID++
+	started := job.PolicyImport
+	s.mu.Unlock()
+
+	s.logger.WithFields(logrus.Fields{
+		"importId":  job.ID,
+		"rows":      len(records),
+		"dryRun":    opts.DryRun,
+		"startedBy": opts.StartedBy,
+	}).Info("Policy import started")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(job, plan, records)
+	}()
+	return &started, nil
+}
+
+// Get returns the progress of an import
+func (s *ImportService) Get(importID string) (*models.PolicyImport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.imports[importID]
+	if !exists {
+		return nil, fmt.Errorf("import not found")
+	}
+	progress := job.PolicyImport
+	return &progress, nil
+}
+
+// Report returns a finished import with the outcome of each row read
+func (s *ImportService) Report(importID string) (*models.ImportReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, exists := s.imports[importID]
+	if !exists {
+		return nil, fmt.Errorf("import not found")
+	}
+	if job.Status == models.ImportRunning {
+		return nil, fmt.Errorf("import is still running")
+	}
+	return &models.ImportReport{
+		Import: job.PolicyImport,
+		Rows:   append([]models.ImportRow(nil), job.rows...),
+	}, nil
+}
+
+// Stop interrupts the imports in flight and waits for them to stop, so
+// none writes policies once the repository is closed
+func (s *ImportService) Stop(ctx context.Context) error {
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run imports the rows in order. It stops at the first customer lookup
+// that fails in transport, or policy that cannot be saved, rather than
+// rejecting every row after it; the policies created until then are kept,
+// and importing the file again rejects them as already used numbers.
+func (s *ImportService) run(job *policyImport, plan *importPlan, records []importRecord) {
+	seen := make(map[string]int) // normalized policy number -> line that claimed it
+	customers := make(map[string]bool)
+	for _, record := range records {
+		if s.ctx.Err() != nil {
+			s.finish(job, "import interrupted by shutdown")
+			return
+		}
+
+		now := time.Now()
+		row, req, status := plan.parse(record, now)
+		if req.PolicyNumber != "" {
+			number := repository.NormalizePolicyNumber(req.PolicyNumber)
+			if line, repeated := seen[number]; repeated {
+				row.Errors = append(row.Errors, fmt.Sprintf("policy number %s is repeated from line %d", req.PolicyNumber, line))
+			} else if existing := s.repo.GetPoliciesByNumber(req.PolicyNumber); len(existing) > 0 {
+				row.Errors = append(row.Errors, fmt.Sprintf("policy number %s is already used by %s", req.PolicyNumber, existing[0].ID))
+			}
+		}
+		if req.CustomerID != "" {
+			exists, checked := customers[req.CustomerID]
+			if !checked {
+				_, err := s.customers.GetCustomer(s.ctx, req.CustomerID)
+				switch {
+				case err == nil:
+					exists = true
+				case err.Error() == "customer not found":
+					exists = false
+				default:
+					s.logger.WithError(err).WithField("importId", job.ID).Warn("Customer lookup failed, stopping policy import")
+					s.finish(job, fmt.Sprintf("customer-service unavailable at line %d: %v", record.line, err))
+					return
+				}
+				customers[req.CustomerID] = exists
+			}
+			if !exists {
+				row.Errors = append(row.Errors, fmt.Sprintf("customer %s does not exist", req.CustomerID))
+			}
+		}
+
+		switch {
+		case len(row.Errors) > 0:
+			row.Outcome = models.RowRejected
+		case job.DryRun:
+			row.Outcome = models.RowValid
+		default:
+			policy, err := s.create(req, status)
+			if err != nil {
+				s.logger.WithError(err).WithField("importId", job.ID).Error("Failed to save imported policy, stopping policy import")
+				s.finish(job, fmt.Sprintf("failed to save the policy at line %d: %v", record.line, err))
+				return
+			}
+			row.Outcome, row.PolicyID = models.RowImported, policy.ID
+		}
+		if row.Outcome != models.RowRejected {
+			seen[repository.NormalizePolicyNumber(req.PolicyNumber)] = record.line
+		}
+		s.record(job, row)
+	}
+	s.finish(job, "")
+}
+
+// create saves an imported policy in the status its dates put it in
+func (s *ImportService) create(req models.CreatePolicyRequest, status string) (*models.Policy, error) {
+	policy, err := s.repo.CreatePolicy(req)
+	if err != nil || policy.Status == status {
+		return policy, err
+	}
+	updated := *policy
+	updated.Status = status
+	return s.repo.UpdatePolicy(&updated)
+}
+
+// record adds a row's outcome to the import's progress
+func (s *ImportService) record(job *policyImport, row models.ImportRow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job.rows = append(job.rows, row)
+	job.ProcessedRows++
+	if row.Outcome == models.RowRejected {
+		job.Rejected++
+	} else {
+		job.Imported++
+	}
+}
+
+// finish ends an import, as failed when reason is set
+func (s *ImportService) finish(job *policyImport, reason string) {
+	s.mu.Lock()
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Status, job.Error = models.ImportDone, reason
+	if reason != "" {
+		job.Status = models.ImportFailed
+	}
+	progress := job.PolicyImport
+	s.mu.Unlock()
+
+	entry := s.logger.WithFields(logrus.Fields{
+		"importId": progress.ID,
+		"dryRun":   progress.DryRun,
+		"rows":     progress.ProcessedRows,
+		"imported": progress.Imported,
+		"rejected": progress.Rejected,
+	})
+	if reason != "" {
+		entry.WithField("reason", reason).Warn("Policy import failed")
+		return
+	}
+	entry.Info("Policy import completed")
+}
+
+// importPlan is a mapping resolved for reading files
+type importPlan struct {
+	headers    map[string]string            // policy field -> CSV header
+	values     map[string]map[string]string // policy field -> lower-cased legacy code -> value
+	dateFormat string
+	layout     string
+	thousands  string
+	columns    map[string]int // policy field -> column index, set by read
+}
+
+// newImportPlan checks a mapping and resolves it
+func newImportPlan(mapping *models.ImportMapping) (*importPlan, error) {
+	plan := &importPlan{
+		headers:    make(map[string]string, len(models.ImportFields)),
+		values:     make(map[string]map[string]string),
+		dateFormat: "YYYY-MM-DD",
+		thousands:  mapping.ThousandsSeparator,
+	}
+	for _, field := range models.ImportFields {
+		plan.headers[field] = field
+	}
+	for field, header := range mapping.Columns {
+		if _, known := plan.headers[field]; !known {
+			return nil, fmt.Errorf("unknown policy field %q in columns; fields are %s", field, strings.Join(models.ImportFields, ", "))
+		}
+		if strings.TrimSpace(header) == "" {
+			return nil, fmt.Errorf("no column named for %s", field)
+		}
+		plan.headers[field] = strings.TrimSpace(header)
+	}
+	for field, codes := range mapping.Values {
+		if _, known := plan.headers[field]; !known {
+			return nil, fmt.Errorf("unknown policy field %q in values", field)
+		}
+		plan.values[field] = make(map[string]string, len(codes))
+		for code, value := range codes {
+			plan.values[field][strings.ToLower(strings.TrimSpace(code))] = value
+		}
+	}
+
+	if mapping.DateFormat != "" {
+		plan.dateFormat = mapping.DateFormat
+	}
+	for _, token := range []string{"YYYY", "MM", "DD"} {
+		if strings.Count(plan.dateFormat, token) != 1 {
+			return nil, fmt.Errorf("dateFormat %q must hold YYYY, MM and DD once each", plan.dateFormat)
+		}
+	}
+	plan.layout = strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02").Replace(plan.dateFormat)
+
+	if strings.ContainsAny(plan.thousands, "0123456789.-+") {
+		return nil, fmt.Errorf("thousandsSeparator %q would change the amounts", plan.thousands)
+	}
+	return plan, nil
+}
+
+// read parses data as CSV with a header row, finding the column of each
+// mapped field. Every required field needs a column; an optional field
+// needs one only when the mapping names it.
+func (p *importPlan) read(data []byte) ([]importRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1 // short rows are reported per row
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, dup := index[strings.TrimSpace(name)]; !dup {
+			index[strings.TrimSpace(name)] = i
+		}
+	}
+
+	p.columns = make(map[string]int, len(p.headers))
+	for _, field := range models.ImportFields {
+		column, found := index[p.headers[field]]
+		if !found {
+			if field == p.headers[field] && !contains(requiredImportFields, field) {
+				p.columns[field] = -1
+				continue
+			}
+			return nil, fmt.Errorf("file has no %s column for %s", p.headers[field], field)
+		}
+		p.columns[field] = column
+	}
+
+	var records []importRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %v", err)
+		}
+		if len(records) == MaxImportRows {
+			return nil, fmt.Errorf("file has more than %d rows; split it into several imports", MaxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, importRecord{line: line, fields: fields})
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file has no rows")
+	}
+	return records, nil
+}
+
+// value returns the trimmed, translated value of field in record
+func (p *importPlan) value(record importRecord, field string) string {
+	column := p.columns[field]
+	if column < 0 || column >= len(record.fields) {
+		return ""
+	}
+	value := strings.TrimSpace(record.fields[column])
+	if translated, ok := p.values[field][strings.ToLower(value)]; ok {
+		return translated
+	}
+	return value
+}
+
+// parse validates a row and reads it as a policy and the status its dates
+// put it in as of now. A status in the file must agree with the dates.
+func (p *importPlan) parse(record importRecord, now time.Time) (models.ImportRow, models.CreatePolicyRequest, string) {
+	row := models.ImportRow{Line: record.line, PolicyNumber: p.value(record, "policyNumber")}
+	fail := func(format string, args ...interface{}) {
+		row.Errors = append(row.Errors, fmt.Sprintf(format, args...))
+	}
+	for _, field := range requiredImportFields {
+		if p.value(record, field) == "" {
+			fail("%s is required", field)
+		}
+	}
+
+	req := models.CreatePolicyRequest{
+		PolicyNumber: row.PolicyNumber,
+		CustomerID:   p.value(record, "customerId"),
+		AgentID:      p.value(record, "agentId"),
+		Type:         strings.ToLower(p.value(record, "type")),
+	}
+	if req.Type != "" && req.Type != "auto" && req.Type != "home" && req.Type != "life" {
+		fail("type %q is not one of auto, home, life", req.Type)
+	}
+
+	amount := func(field string) (float64, bool) {
+		value := p.value(record, field)
+		if value == "" {
+			return 0, false
+		}
+		digits := value
+		if p.thousands != "" {
+			digits = strings.ReplaceAll(value, p.thousands, "")
+		}
+		n, err := strconv.ParseFloat(digits, 64)
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			fail("%s %q is not a number", field, value)
+			return 0, false
+		}
+		return n, true
+	}
+	var ok bool
+	if req.Premium, ok = amount("premium"); ok && req.Premium <= 0 {
+		fail("premium must be positive")
+	}
+	if req.Coverage, ok = amount("coverage"); ok && req.Coverage <= 0 {
+		fail("coverage must be positive")
+	}
+	if req.Deductible, ok = amount("deductible"); ok && req.Deductible < 0 {
+		fail("deductible must not be negative")
+	} else if ok && req.Coverage > 0 && req.Deductible > req.Coverage {
+		fail("deductible must not be more than the coverage")
+	}
+
+	date := func(field string) time.Time {
+		value := p.value(record, field)
+		if value == "" {
+			return time.Time{}
+		}
+		t, err := time.Parse(p.layout, value)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, value); err != nil {
+				fail("%s %q is not a date in the format %s", field, value, p.dateFormat)
+			}
+		}
+		return t
+	}
+	req.StartDate, req.EndDate = date("startDate"), date("endDate")
+	if req.StartDate.IsZero() || req.EndDate.IsZero() {
+		return row, req, ""
+	}
+	if !req.EndDate.After(req.StartDate) {
+		fail("endDate must be after startDate")
+		return row, req, ""
+	}
+
+	status := lifecycle.Initial(req.StartDate, now)
+	if !now.Before(req.EndDate) {
+		status = models.StatusExpired
+	}
+	switch given := strings.ToLower(p.value(record, "status")); given {
+	case "", status:
+	case models.StatusPending, models.StatusActive, models.StatusExpired:
+		fail("status %s does not agree with the policy dates, which make it %s", given, status)
+	default:
+		fail("status %q cannot be imported; only pending, active and expired policies can", given)
+	}
+	return row, req, status
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
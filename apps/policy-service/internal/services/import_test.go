@@ -0,0 +1,213 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+// legacyMapping reads the export of a legacy policy administration system
+var legacyMapping = &models.ImportMapping{
+	Columns: map[string]string{
+		"policyNumber": "POLICY_NO",
+		"customerId":   "CUST",
+		"type":         "LOB",
+		"premium":      "PREM",
+		"coverage":     "LIMIT",
+		"deductible":   "DED",
+		"startDate":    "EFF",
+		"endDate":      "EXP",
+		"status":       "STATUS",
+	},
+	Values: map[string]map[string]string{
+		"type":   {"PA": "auto", "HO": "home"},
+		"status": {"A": "active", "X": "expired", "C": "cancelled"},
+	},
+	DateFormat:         "MM/DD/YYYY",
+	ThousandsSeparator: ",",
+}
+
+const legacyFile = `POLICY_NO,CUST,LOB,PREM,LIMIT,DED,EFF,EXP,STATUS
+LEG-1,cust-001,PA,"1,200.50","50,000",500,01/15/2020,01/15/2099,A
+LEG-2,cust-002,ho,900,250000,,03/01/2010,03/01/2011,X
+auto 001,cust-001,PA,100,1000,0,01/01/2020,01/01/2099,A
+LEG-1,cust-002,PA,100,1000,0,01/01/2020,01/01/2099,A
+LEG-5,cust-404,boat,abc,-5,10,13/01/2020,01/01/2019,C
+LEG-6,cust-001
+LEG-7,cust-002,PA,100,1000,2000,01/01/2020,01/01/2099,X
+`
+
+func newTestImporter(customers CustomerLookup) (*ImportService, *repositorytest.FakeStore) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	existing := samplePolicy("pol-001", "cust-001")
+	existing.PolicyNumber = "AUTO-001"
+	store := repositorytest.NewFakeStore(existing)
+	return NewImportService(store, customers, logger), store
+}
+
+// finishedReport waits for the imports in flight and returns the report
+func finishedReport(t *testing.T, s *ImportService, importID string) *models.ImportReport {
+	t.Helper()
+	s.wg.Wait()
+	report, err := s.Report(importID)
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	return report
+}
+
+func TestImportValidatesEveryRow(t *testing.T) {
+	customers := &stubCustomers{known: map[string]bool{"cust-001": true, "cust-002": true}}
+	s, store := newTestImporter(customers)
+
+	want := []struct {
+		line   int
+		errors string
+	}{
+		{2, ""},
+		{3, ""},
+		{4, "policy number auto 001 is already used by pol-001"},
+		{5, "policy number LEG-1 is repeated from line 2"},
+		{6, `type "boat" is not one of auto, home, life; premium "abc" is not a number; coverage must be positive; ` +
+			`startDate "13/01/2020" is not a date in the format MM/DD/YYYY; customer cust-404 does not exist`},
+		{7, "type is required; premium is required; coverage is required; startDate is required; endDate is required"},
+		{8, "deductible must not be more than the coverage; status expired does not agree with the policy dates, which make it active"},
+	}
+	check := func(report *models.ImportReport, accepted string) {
+		t.Helper()
+		if len(report.Rows) != len(want) {
+			t.Fatalf("Expected %d rows, got %+v", len(want), report.Rows)
+		}
+		for i, w := range want {
+			row := report.Rows[i]
+			outcome := models.RowRejected
+			if w.errors == "" {
+				outcome = accepted
+			}
+			if row.Line != w.line || row.Outcome != outcome || strings.Join(row.Errors, "; ") != w.errors {
+				t.Errorf("Row %d: got %+v, want %s with errors %q", i, row, outcome, w.errors)
+			}
+		}
+		if got := report.Import; got.Status != models.ImportDone || got.TotalRows != 7 || got.ProcessedRows != 7 || got.Imported != 2 || got.Rejected != 5 {
+			t.Errorf("Unexpected import %+v", got)
+		}
+	}
+
+	// A dry run reports what would happen and creates nothing
+	dryRun, err := s.Start([]byte(legacyFile), legacyMapping, models.ImportOptions{DryRun: true, FileName: "legacy.csv"})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	check(finishedReport(t, s, dryRun.ID), models.RowValid)
+	if n := len(store.GetAllPolicies()); n != 1 {
+		t.Fatalf("A dry run created policies: %d stored", n)
+	}
+	if customers.calls != 3 {
+		t.Errorf("Expected one lookup per customer, got %d", customers.calls)
+	}
+
+	run, err := s.Start([]byte(legacyFile), legacyMapping, models.ImportOptions{StartedBy: "staff-001"})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	report := finishedReport(t, s, run.ID)
+	check(report, models.RowImported)
+
+	active, err := store.GetPolicyByID(report.Rows[0].PolicyID)
+	if err != nil || active.PolicyNumber != "LEG-1" || active.Premium != 1200.50 || active.Coverage != 50000 || active.Status != models.StatusActive {
+		t.Errorf("Unexpected imported policy %+v, %v", active, err)
+	}
+	expired, err := store.GetPolicyByID(report.Rows[1].PolicyID)
+	if err != nil || expired.Type != "home" || expired.Status != models.StatusExpired || expired.StartDate.Format("2006-01-02") != "2010-03-01" {
+		t.Errorf("Unexpected imported policy %+v, %v", expired, err)
+	}
+
+	// Importing the file again finds its policies already there
+	again, _ := s.Start([]byte(legacyFile), legacyMapping, models.ImportOptions{})
+	if got := finishedReport(t, s, again.ID); got.Import.Imported != 0 || got.Rows[0].Errors[0] != "policy number LEG-1 is already used by "+active.ID {
+		t.Errorf("Expected every row rejected, got %+v", got)
+	}
+}
+
+func TestImportReadsPolicyExports(t *testing.T) {
+	s, store := newTestImporter(&stubCustomers{known: map[string]bool{"cust-001": true}})
+
+	export := "id,policyNumber,customerId,type,status,premium,coverage,deductible,currency,startDate,endDate\n" +
+		"pol-009,HOME-009,cust-001,home,pending,700,300000,1000,USD,2098-01-01T00:00:00Z,2099-01-01T00:00:00Z\n"
+	job, err := s.Start([]byte(export), nil, models.ImportOptions{})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	report := finishedReport(t, s, job.ID)
+	if len(report.Rows) != 1 || report.Rows[0].Outcome != models.RowImported {
+		t.Fatalf("Unexpected report %+v", report)
+	}
+	policy, _ := store.GetPolicyByID(report.Rows[0].PolicyID)
+	if policy.ID == "pol-009" || policy.Status != models.StatusPending || policy.Deductible != 1000 {
+		t.Errorf("Unexpected imported policy %+v", policy)
+	}
+}
+
+func TestImportRefusesUnreadableFiles(t *testing.T) {
+	header := "policyNumber,customerId,type,premium,coverage,startDate,endDate\n"
+	tests := []struct {
+		name    string
+		mapping *models.ImportMapping
+		file    string
+		wantErr string
+	}{
+		{"unknown field", &models.ImportMapping{Columns: map[string]string{"insured": "NAME"}}, header, `invalid mapping: unknown policy field "insured" in columns`},
+		{"blank column", &models.ImportMapping{Columns: map[string]string{"type": " "}}, header, "invalid mapping: no column named for type"},
+		{"unknown values", &models.ImportMapping{Values: map[string]map[string]string{"colour": {}}}, header, `invalid mapping: unknown policy field "colour" in values`},
+		{"date format", &models.ImportMapping{DateFormat: "DD.MM.YY"}, header, `invalid mapping: dateFormat "DD.MM.YY" must hold YYYY, MM and DD once each`},
+		{"separator", &models.ImportMapping{ThousandsSeparator: "."}, header, `invalid mapping: thousandsSeparator "." would change the amounts`},
+		{"empty", nil, "", "file is empty"},
+		{"missing column", nil, "policyNumber,customerId\n", "file has no type column for type"},
+		{"renamed column", &models.ImportMapping{Columns: map[string]string{"agentId": "AGENT"}}, header, "file has no AGENT column for agentId"},
+		{"no rows", nil, header, "file has no rows"},
+		{"bad quoting", nil, header + `"LEG-1,cust-001`, "invalid CSV"},
+	}
+	s, _ := newTestImporter(&stubCustomers{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Start([]byte(tt.file), tt.mapping, models.ImportOptions{}); err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error starting with %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	unconfigured, _ := newTestImporter(nil)
+	if _, err := unconfigured.Start([]byte(header), nil, models.ImportOptions{}); err == nil || err.Error() != "customer-service not configured" {
+		t.Errorf("Expected imports to need customer-service, got %v", err)
+	}
+}
+
+func TestImportStopsAtUnavailableServices(t *testing.T) {
+	file := "policyNumber,customerId,type,premium,coverage,startDate,endDate\n" +
+		"LEG-1,cust-001,auto,100,1000,2020-01-01,2099-01-01\n" +
+		"LEG-2,cust-001,auto,100,1000,2020-01-01,2099-01-01\n"
+
+	s, _ := newTestImporter(&stubCustomers{err: errors.New("connection refused")})
+	job, _ := s.Start([]byte(file), nil, models.ImportOptions{})
+	report := finishedReport(t, s, job.ID)
+	if report.Import.Status != models.ImportFailed || report.Import.Error != "customer-service unavailable at line 2: connection refused" || len(report.Rows) != 0 {
+		t.Errorf("Expected the import to stop at the first lookup, got %+v", report)
+	}
+
+	s, store := newTestImporter(&stubCustomers{known: map[string]bool{"cust-001": true}})
+	store.Err = errors.New("disk full")
+	job, _ = s.Start([]byte(file), nil, models.ImportOptions{})
+	if report := finishedReport(t, s, job.ID); report.Import.Status != models.ImportFailed || report.Import.Error != "failed to save the policy at line 2: disk full" {
+		t.Errorf("Expected the import to stop at the failed save, got %+v", report.Import)
+	}
+
+	if _, err := s.Report("imp-404"); err == nil || err.Error() != "import not found" {
+		t.Errorf("Expected an unknown import, got %v", err)
+	}
+}
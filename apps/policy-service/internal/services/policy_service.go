@@ -1,32 +1,105 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
 	"github.com/sirupsen/logrus"
 )
 
+// QuoteConverter reports bound quotes to pricing-engine
+type QuoteConverter interface {
+	ConvertQuote(ctx context.Context, quoteID, policyID string) (*clients.ConvertedQuote, error)
+}
+
+var _ QuoteConverter = (*clients.PricingClient)(nil)
+
+// IssuanceRules is the business rules a new policy must pass.
+// *rules.Engine is the production implementation.
+type IssuanceRules interface {
+	First(facts rules.Context) (rules.Match, bool)
+}
+
+var _ IssuanceRules = (*rules.Engine)(nil)
+
 // PolicyService handles business logic for policies
 type PolicyService struct {
-	repo   *repository.Repository
-	flags  *features.Flags
-	logger *logrus.Logger
+	repo      repository.PolicyStore
+	flags     *features.Flags
+	refunds   RefundIssuer
+	quotes    QuoteConverter
+	customers CustomerLookup
+	issuance  IssuanceRules
+	lifecycle *lifecycle.Machine
+	logger    *logrus.Logger
+
+	// cancellationNotice is how far ahead the insurer must give notice of
+	// cancelling an active policy
+	cancellationNotice time.Duration
 }
 
-// NewPolicyService creates a new policy service
-func NewPolicyService(repo *repository.Repository, flags *features.Flags, logger *logrus.Logger) *PolicyService {
+// NewPolicyService creates a new policy service. refunds may be nil when
+// payments-service is not configured; cancellations then cannot refund.
+// quotes may be nil when pricing-engine is not configured; bound quotes are
+// then not reported as converted. customers may be nil when customer-service
+// is not configured; policies then cannot be created while the
+// policies.requireVerifiedEmail or policies.requireVerifiedKYC flag is on,
+// and household listings only include the customer's own policies.
+// issuance may be nil; otherwise new policies matched by one of its policy
+// rules are refused. cancellationNotice is the notice the insurer gives of a cancellation; zero
+// lets it cancel straight away.
+func NewPolicyService(repo repository.PolicyStore, flags *features.Flags, refunds RefundIssuer, quotes QuoteConverter, customers CustomerLookup, issuance IssuanceRules, cancellationNotice time.Duration, logger *logrus.Logger) *PolicyService {
+	machine := lifecycle.Policies()
+	machine.OnTransition(logTransition(logger))
 	return &PolicyService{
-		repo:   repo,
-		flags:  flags,
-		logger: logger,
+		repo:      repo,
+		flags:     flags,
+		refunds:   refunds,
+		quotes:    quotes,
+		customers: customers,
+		issuance:  issuance,
+		lifecycle: machine,
+		logger:    logger,
+
+		cancellationNotice: cancellationNotice,
 	}
 }
 
-// GetPolicyByID retrieves a policy by ID and applies masking if needed
+// logTransition returns a hook that logs every saved status change.
+// Cancellations also record why and by whom they were asked for.
+func logTransition(logger *logrus.Logger) lifecycle.Hook {
+	return func(policy *models.Policy, from string, change lifecycle.Change) {
+		fields := logrus.Fields{
+			"event":     "policy.status_changed",
+			"policyId":  policy.ID,
+			"oldStatus": from,
+			"newStatus": policy.Status,
+			"trigger":   change.Trigger,
+			"effective": change.Effective.Format("2006-01-02"),
+		}
+		if c := policy.Cancellation; c != nil && (policy.Status == models.StatusCancelled || policy.Status == models.StatusPendingCancellation) {
+			fields["reasonCode"] = c.ReasonCode
+			fields["initiatedBy"] = c.InitiatedBy
+			fields["cancellationEffective"] = c.EffectiveDate.Format("2006-01-02")
+		}
+		logger.WithFields(fields).Info("Policy status changed")
+	}
+}
+
+// savePolicy stores a policy changed by the lifecycle
+func (s *PolicyService) savePolicy(policy *models.Policy) error {
+	_, err := s.repo.UpdatePolicy(policy)
+	return err
+}
+
+// GetPolicyByID retrieves a policy by ID
 func (s *PolicyService) GetPolicyByID(policyID string, customerID string) (*models.PolicyResponse, error) {
 	policy, err := s.repo.GetPolicyByID(policyID)
 	if err != nil {
@@ -47,21 +120,19 @@ func (s *PolicyService) GetPolicyByID(policyID string, customerID string) (*mode
 		return nil, fmt.Errorf("unauthorized")
 	}
 
-	// Apply masking and currency based on feature flags
-	maskAmounts := s.flags.ShouldMaskAmounts()
+	// Apply currency based on feature flags
 	currency := s.flags.GetCurrency()
 	s.logger.WithFields(logrus.Fields{
-		"policyId":    policyID,
-		"customerId":  customerID,
-		"maskAmounts": maskAmounts,
-		"currency":    currency,
+		"policyId":   policyID,
+		"customerId": customerID,
+		"currency":   currency,
 	}).Debug("Retrieving policy")
 
-	response := policy.ToResponse(maskAmounts, currency)
+	response := policy.ToResponse(currency)
 	return &response, nil
 }
 
-// GetPoliciesByCustomerID retrieves all policies for a customer with optional masking
+// GetPoliciesByCustomerID retrieves all policies for a customer
 func (s *PolicyService) GetPoliciesByCustomerID(customerID string) ([]models.PolicyResponse, error) {
 	policies, err := s.repo.GetPoliciesByCustomerID(customerID)
 	if err != nil {
@@ -69,26 +140,59 @@ func (s *PolicyService) GetPoliciesByCustomerID(customerID string) ([]models.Pol
 		return nil, err
 	}
 
-	// Apply masking and currency based on feature flags
-	maskAmounts := s.flags.ShouldMaskAmounts()
+	// Apply currency based on feature flags
 	currency := s.flags.GetCurrency()
 	s.logger.WithFields(logrus.Fields{
-		"customerId":  customerID,
-		"count":       len(policies),
-		"maskAmounts": maskAmounts,
-		"currency":    currency,
+		"customerId": customerID,
+		"count":      len(policies),
+		"currency":   currency,
 	}).Debug("Retrieving policies")
 
 	responses := make([]models.PolicyResponse, len(policies))
 	for i, policy := range policies {
-		responses[i] = policy.ToResponse(maskAmounts, currency)
+		responses[i] = policy.ToResponse(currency)
 	}
 
 	return responses, nil
 }
 
-// CreatePolicy creates a new policy for a customer
-func (s *PolicyService) CreatePolicy(customerID string, req models.CreatePolicyRequest) (*models.PolicyResponse, error) {
+// GetHouseholdPolicies retrieves the policies of every customer in the
+// customer's household, so a family sees the policies they share. Without a
+// customer lookup the household is unknown and only the customer's own
+// policies are returned.
+func (s *PolicyService) GetHouseholdPolicies(ctx context.Context, customerID string) ([]models.PolicyResponse, error) {
+	if s.customers == nil {
+		s.logger.WithField("customerId", customerID).Debug("Customer service not configured, listing own policies only")
+		return s.GetPoliciesByCustomerID(customerID)
+	}
+
+	members, err := s.customers.HouseholdMembers(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up household: %w", err)
+	}
+
+	responses := []models.PolicyResponse{}
+	for _, memberID := range members {
+		policies, err := s.GetPoliciesByCustomerID(memberID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, policies...)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId": customerID,
+		"members":    len(members),
+		"count":      len(responses),
+	}).Debug("Retrieving household policies")
+
+	return responses, nil
+}
+
+// CreatePolicy creates a new policy for a customer. A policy bound from a
+// quote is reported to pricing-engine so the quote counts as converted; a
+// failed report does not undo the policy.
+func (s *PolicyService) CreatePolicy(ctx context.Context, customerID string, req models.CreatePolicyRequest) (*models.PolicyResponse, error) {
 	// Use the customerID from the authenticated request
 	if req.CustomerID == "" {
 		req.CustomerID = customerID
@@ -103,6 +207,17 @@ func (s *PolicyService) CreatePolicy(customerID string, req models.CreatePolicyR
 		return nil, fmt.Errorf("unauthorized")
 	}
 
+	requireEmail, requireKYC := s.flags.RequireVerifiedEmail(), s.flags.RequireVerifiedKYC()
+	if requireEmail || requireKYC {
+		if err := s.checkCustomerVerified(ctx, customerID, requireEmail, requireKYC); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkIssuance(req); err != nil {
+		return nil, err
+	}
+
 	policy, err := s.repo.CreatePolicy(req)
 	if err != nil {
 		s.logger.WithField("customerId", customerID).Error("Failed to create policy")
@@ -115,13 +230,87 @@ func (s *PolicyService) CreatePolicy(customerID string, req models.CreatePolicyR
 		"type":       policy.Type,
 	}).Info("Policy created successfully")
 
-	// Apply masking and currency based on feature flags
-	maskAmounts := s.flags.ShouldMaskAmounts()
+	if policy.QuoteID != "" {
+		s.convertQuote(ctx, policy)
+	}
+
+	// Apply currency based on feature flags
 	currency := s.flags.GetCurrency()
-	response := policy.ToResponse(maskAmounts, currency)
+	response := policy.ToResponse(currency)
 	return &response, nil
 }
 
+// checkIssuance refuses a new policy matched by a policy rule
+func (s *PolicyService) checkIssuance(req models.CreatePolicyRequest) error {
+	if s.issuance == nil {
+		return nil
+	}
+
+	match, refused := s.issuance.First(rules.PolicyContext{
+		Type:       req.Type,
+		Premium:    req.Premium,
+		Coverage:   req.Coverage,
+		Deductible: req.Deductible,
+		TermDays:   int(req.EndDate.Sub(req.StartDate).Hours() / 24),
+		FromQuote:  req.QuoteID != "",
+		CustomerID: req.CustomerID,
+		AgentID:    req.AgentID,
+	})
+	if !refused {
+		return nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId": req.CustomerID,
+		"type":       req.Type,
+		"rule":       match.Rule,
+	}).Warn("Policy creation refused by business rule")
+	return fmt.Errorf("policy refused: %s", match.Reason)
+}
+
+// checkCustomerVerified returns an error unless customer-service has the
+// customer's email address and, when required, their identity as verified
+func (s *PolicyService) checkCustomerVerified(ctx context.Context, customerID string, email, kyc bool) error {
+	if s.customers == nil {
+		s.logger.WithField("customerId", customerID).Error("Customer service not configured, cannot check customer verification")
+		return fmt.Errorf("failed to check customer verification: customer-service not configured")
+	}
+
+	customer, err := s.customers.GetCustomer(ctx, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to check customer verification: %w", err)
+	}
+	if email && !customer.EmailVerified {
+		s.logger.WithField("customerId", customerID).Warn("Policy creation blocked, email not verified")
+		return fmt.Errorf("email not verified")
+	}
+	if kyc && customer.KYCStatus != "verified" {
+		s.logger.WithFields(logrus.Fields{
+			"customerId": customerID,
+			"kycStatus":  customer.KYCStatus,
+		}).Warn("Policy creation blocked, KYC not verified")
+		return fmt.Errorf("kyc not verified")
+	}
+	return nil
+}
+
+// convertQuote tells pricing-engine the policy's quote was bound
+func (s *PolicyService) convertQuote(ctx context.Context, policy *models.Policy) {
+	fields := logrus.Fields{
+		"policyId": policy.ID,
+		"quoteId":  policy.QuoteID,
+	}
+	if s.quotes == nil {
+		s.logger.WithFields(fields).Warn("Pricing engine not configured, quote conversion not recorded")
+		return
+	}
+	if _, err := s.quotes.ConvertQuote(ctx, policy.QuoteID, policy.ID); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("Failed to record quote conversion")
+		return
+	}
+	s.logger.WithFields(fields).Info("Quote conversion recorded")
+}
+
 // UpdatePolicy updates an existing policy
 func (s *PolicyService) UpdatePolicy(policyID string, customerID string, req models.UpdatePolicyRequest) (*models.PolicyResponse, error) {
 	// Get existing policy to verify ownership
@@ -144,23 +333,33 @@ func (s *PolicyService) UpdatePolicy(policyID string, customerID string, req mod
 		return nil, fmt.Errorf("unauthorized")
 	}
 
-	// Apply updates
+	// Apply updates to a copy so a refused status change leaves the policy
+	// as it was
 	now := time.Now()
-	if req.Status != nil {
-		policy.Status = *req.Status
-	}
+	updated := *policy
 	if req.Premium != nil {
-		policy.Premium = *req.Premium
+		updated.Premium = *req.Premium
 	}
 	if req.EndDate != nil {
-		policy.EndDate = *req.EndDate
+		updated.EndDate = *req.EndDate
 	}
-	policy.UpdatedAt = now
+	if req.AgentID != nil {
+		updated.AgentID = *req.AgentID
+	}
+	if req.NonRenewing != nil {
+		updated.NonRenewing = *req.NonRenewing
+	}
+	updated.UpdatedAt = now
 
-	// Update in repository
-	updatedPolicy, err := s.repo.UpdatePolicy(policy)
+	// A status change must be one the lifecycle allows on the updated dates
+	if req.Status != nil && *req.Status != policy.Status {
+		change := lifecycle.Change{To: *req.Status, Trigger: lifecycle.TriggerRequest, At: now}
+		err = s.lifecycle.Fire(&updated, change, s.savePolicy)
+	} else {
+		err = s.savePolicy(&updated)
+	}
 	if err != nil {
-		s.logger.WithField("policyId", policyID).Error("Failed to update policy")
+		s.logger.WithError(err).WithField("policyId", policyID).Error("Failed to update policy")
 		return nil, err
 	}
 
@@ -169,9 +368,8 @@ func (s *PolicyService) UpdatePolicy(policyID string, customerID string, req mod
 		"customerId": customerID,
 	}).Info("Policy updated successfully")
 
-	// Apply masking and currency based on feature flags
-	maskAmounts := s.flags.ShouldMaskAmounts()
+	// Apply currency based on feature flags
 	currency := s.flags.GetCurrency()
-	response := updatedPolicy.ToResponse(maskAmounts, currency)
+	response := updated.ToResponse(currency)
 	return &response, nil
 }
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestService(policies ...*models.Policy) (*PolicyService, *repositorytest.FakeStore) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(policies...)
+	return NewPolicyService(store, nil, nil, nil, nil, nil, 0, logger), store
+}
+
+func samplePolicy(id, customerID string) *models.Policy {
+	return &models.Policy{
+		ID:         id,
+		CustomerID: customerID,
+		Type:       "auto",
+		Status:     "active",
+		Premium:    1250,
+		Coverage:   50000,
+		StartDate:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestGetPolicyByID(t *testing.T) {
+	service, _ := newTestService(samplePolicy("pol-001", "cust-001"))
+
+	tests := []struct {
+		name       string
+		policyID   string
+		customerID string
+		wantErr    string
+	}{
+		{"owner", "pol-001", "cust-001", ""},
+		{"other customer", "pol-001", "cust-002", "unauthorized"},
+		{"missing", "pol-999", "cust-001", "policy not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := service.GetPolicyByID(tt.policyID, tt.customerID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Error mismatch: got %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPolicyByID failed: %v", err)
+			}
+			if policy.ID != tt.policyID || policy.Currency != "USD" {
+				t.Errorf("Unexpected policy: %+v", policy)
+			}
+		})
+	}
+}
+
+func TestCreatePolicyRejectsOtherCustomer(t *testing.T) {
+	service, store := newTestService()
+
+	_, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{CustomerID: "cust-002", Type: "auto", Premium: 900})
+	if err == nil || err.Error() != "unauthorized" {
+		t.Fatalf("Expected unauthorized, got %v", err)
+	}
+	if n := len(store.GetAllPolicies()); n != 0 {
+		t.Errorf("Rejected request stored %d policies", n)
+	}
+
+	created, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "home", Premium: 900})
+	if err != nil {
+		t.Fatalf("CreatePolicy failed: %v", err)
+	}
+	if created.CustomerID != "cust-001" || created.Status != "active" {
+		t.Errorf("Unexpected created policy: %+v", created)
+	}
+}
+
+// stubQuotes records the quote conversions it is sent
+type stubQuotes struct {
+	converted map[string]string // quote ID to policy ID
+	err       error
+}
+
+func (s *stubQuotes) ConvertQuote(ctx context.Context, quoteID, policyID string) (*clients.ConvertedQuote, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.converted[quoteID] = policyID
+	return &clients.ConvertedQuote{QuoteID: quoteID, Converted: true, PolicyID: policyID}, nil
+}
+
+func TestCreatePolicyReportsBoundQuote(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	quotes := &stubQuotes{converted: map[string]string{}}
+	service := NewPolicyService(repositorytest.NewFakeStore(), nil, nil, quotes, nil, nil, 0, logger)
+
+	bound, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "auto", Premium: 900, QuoteID: "Q-1c84e5d0"})
+	if err != nil {
+		t.Fatalf("CreatePolicy failed: %v", err)
+	}
+	if bound.QuoteID != "Q-1c84e5d0" || quotes.converted["Q-1c84e5d0"] != bound.ID {
+		t.Errorf("Quote conversion not reported: policy %+v, conversions %v", bound, quotes.converted)
+	}
+
+	// Policies without a quote report nothing; a failed report keeps the policy
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "home", Premium: 900}); err != nil || len(quotes.converted) != 1 {
+		t.Errorf("Unexpected conversions without a quote: %v, %v", quotes.converted, err)
+	}
+	quotes.err = errors.New("pricing-engine unavailable")
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "auto", Premium: 900, QuoteID: "Q-e95f3b18"}); err != nil {
+		t.Errorf("CreatePolicy should not fail when the conversion report does: %v", err)
+	}
+}
+
+func TestCreatePolicyFollowsIssuanceRules(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	issuance := rules.New(rules.Config{Domains: []string{rules.DomainPolicy}}, logger)
+	if err := issuance.Replace(rules.Set{Rules: []rules.Rule{{
+		Name:   "short-term-auto",
+		Domain: rules.DomainPolicy,
+		When:   "type == 'auto' && termDays < 180",
+		Action: rules.ActionReject,
+		Reason: "Auto policies run for at least six months",
+	}}}); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	service := NewPolicyService(repositorytest.NewFakeStore(), nil, nil, nil, nil, issuance, 0, logger)
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "auto", Premium: 400, StartDate: start, EndDate: start.AddDate(0, 3, 0)})
+	if err == nil || err.Error() != "policy refused: Auto policies run for at least six months" {
+		t.Errorf("Expected the three month auto policy to be refused, got %v", err)
+	}
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "auto", Premium: 900, StartDate: start, EndDate: start.AddDate(1, 0, 0)}); err != nil {
+		t.Errorf("Expected the yearly auto policy to be created, got %v", err)
+	}
+}
+
+func TestUpdatePolicy(t *testing.T) {
+	service, store := newTestService(samplePolicy("pol-001", "cust-001"))
+
+	// The term has ended but the policy was never in grace, so it cannot lapse
+	status := "lapsed"
+	premium := 1400.0
+	_, err := service.UpdatePolicy("pol-001", "cust-001", models.UpdatePolicyRequest{Status: &status, Premium: &premium})
+	var transitionErr *lifecycle.TransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.Reason != "policy has not been in grace" {
+		t.Fatalf("Expected a refused transition, got %v", err)
+	}
+	if stored, _ := store.GetPolicyByID("pol-001"); stored.Status != "active" || stored.Premium != 1250 {
+		t.Errorf("Refused update should change nothing: %+v", stored)
+	}
+
+	// A policy that is not renewing can expire once its term is over
+	status = "expired"
+	nonRenewing := true
+	updated, err := service.UpdatePolicy("pol-001", "cust-001", models.UpdatePolicyRequest{Status: &status, Premium: &premium, NonRenewing: &nonRenewing})
+	if err != nil {
+		t.Fatalf("UpdatePolicy failed: %v", err)
+	}
+	if updated.Status != "expired" || updated.Premium != 1400.0 || !updated.NonRenewing {
+		t.Errorf("Update not applied: %+v", updated)
+	}
+
+	stored, _ := store.GetPolicyByID("pol-001")
+	if stored.Status != "expired" {
+		t.Errorf("Update not persisted: %+v", stored)
+	}
+}
+
+func TestStoreErrorsPropagate(t *testing.T) {
+	service, store := newTestService(samplePolicy("pol-001", "cust-001"))
+	store.Err = errors.New("disk unavailable")
+
+	if _, err := service.GetPoliciesByCustomerID("cust-001"); err == nil {
+		t.Error("Expected store error from GetPoliciesByCustomerID")
+	}
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", models.CreatePolicyRequest{Type: "auto", Premium: 900}); err == nil {
+		t.Error("Expected store error from CreatePolicy")
+	}
+}
+
+func TestCreatePolicyRequiresVerifiedEmail(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+	customers := &stubCustomers{
+		known:    map[string]bool{"cust-001": true, "cust-008": true},
+		verified: map[string]bool{"cust-001": true},
+	}
+	store := repositorytest.NewFakeStore()
+	service := NewPolicyService(store, flags, nil, nil, customers, nil, 0, logger)
+	req := models.CreatePolicyRequest{Type: "home", Premium: 900}
+
+	// Off by default: unverified customers can take out policies
+	if _, err := service.CreatePolicy(context.Background(), "cust-008", req); err != nil || customers.calls != 0 {
+		t.Fatalf("CreatePolicy with the flag off: %v after %d lookups", err, customers.calls)
+	}
+
+	flags.SetRequireVerifiedEmail(true)
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", req); err != nil {
+		t.Errorf("Verified customer rejected: %v", err)
+	}
+	if _, err := service.CreatePolicy(context.Background(), "cust-008", req); err == nil || err.Error() != "email not verified" {
+		t.Errorf("Unverified customer: got %v", err)
+	}
+	if _, err := service.CreatePolicy(context.Background(), "cust-404", req); err == nil || !strings.HasPrefix(err.Error(), "failed to check customer verification") {
+		t.Errorf("Unknown customer: got %v", err)
+	}
+	if n := len(store.GetAllPolicies()); n != 2 {
+		t.Errorf("Expected 2 policies stored, got %d", n)
+	}
+
+	unchecked := NewPolicyService(store, flags, nil, nil, nil, nil, 0, logger)
+	if _, err := unchecked.CreatePolicy(context.Background(), "cust-001", req); err == nil {
+		t.Error("Expected error when verification cannot be checked")
+	}
+}
+
+func TestCreatePolicyRequiresVerifiedKYC(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+	customers := &stubCustomers{
+		known: map[string]bool{"cust-001": true, "cust-002": true, "cust-003": true},
+		kyc:   map[string]string{"cust-001": "verified", "cust-002": "pending"},
+	}
+	service := NewPolicyService(repositorytest.NewFakeStore(), flags, nil, nil, customers, nil, 0, logger)
+	req := models.CreatePolicyRequest{Type: "auto", Premium: 1200}
+
+	flags.SetRequireVerifiedKYC(true)
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", req); err != nil {
+		t.Errorf("Verified customer rejected: %v", err)
+	}
+	for _, customerID := range []string{"cust-002", "cust-003"} {
+		if _, err := service.CreatePolicy(context.Background(), customerID, req); err == nil || err.Error() != "kyc not verified" {
+			t.Errorf("%s: got %v", customerID, err)
+		}
+	}
+
+	// Both checks share one lookup
+	flags.SetRequireVerifiedEmail(true)
+	customers.calls = 0
+	if _, err := service.CreatePolicy(context.Background(), "cust-001", req); err == nil || err.Error() != "email not verified" || customers.calls != 1 {
+		t.Errorf("Unverified email with KYC: got %v after %d lookups", err, customers.calls)
+	}
+}
+
+func TestGetHouseholdPolicies(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	store := repositorytest.NewFakeStore(samplePolicy("pol-005", "cust-005"), samplePolicy("pol-007", "cust-007"), samplePolicy("pol-001", "cust-001"))
+	customers := &stubCustomers{
+		known:      map[string]bool{"cust-001": true, "cust-005": true, "cust-007": true},
+		households: map[string][]string{"cust-005": {"cust-005", "cust-007"}},
+	}
+	service := NewPolicyService(store, nil, nil, nil, customers, nil, 0, logger)
+
+	shared, err := service.GetHouseholdPolicies(context.Background(), "cust-005")
+	if err != nil || len(shared) != 2 {
+		t.Fatalf("Household policies: got %+v, %v", shared, err)
+	}
+	if own, err := service.GetHouseholdPolicies(context.Background(), "cust-001"); err != nil || len(own) != 1 || own[0].ID != "pol-001" {
+		t.Errorf("Customer outside a household: got %+v, %v", own, err)
+	}
+	if _, err := service.GetHouseholdPolicies(context.Background(), "cust-404"); err == nil || !strings.HasPrefix(err.Error(), "failed to look up household") {
+		t.Errorf("Unknown customer: got %v", err)
+	}
+
+	// Without customer-service the household is unknown
+	unlinked := NewPolicyService(store, nil, nil, nil, nil, nil, 0, logger)
+	if own, err := unlinked.GetHouseholdPolicies(context.Background(), "cust-005"); err != nil || len(own) != 1 {
+		t.Errorf("Without customer-service: got %+v, %v", own, err)
+	}
+}
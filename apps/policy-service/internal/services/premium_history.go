@@ -0,0 +1,218 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/sirupsen/logrus"
+)
+
+// ChangeFollower announces a repository's writes; *repository.Repository
+// is the production implementation
+type ChangeFollower interface {
+	OnCreate(collection string, fn hooks.Func)
+	OnUpdate(collection string, fn hooks.Func)
+}
+
+// PremiumHistoryService keeps the premium history of every policy, so
+// agents can explain a premium at renewal. Policies are followed as the
+// repository announces their writes: a term extended past its end date is
+// a renewal, and any other premium change an endorsement.
+type PremiumHistoryService struct {
+	repo   repository.PremiumHistoryStore
+	flags  *features.Flags
+	logger *logrus.Logger
+
+	mu   sync.Mutex
+	seen map[string]premiumTerm // policyID -> premium and term last recorded
+}
+
+// premiumTerm is what the history compares to tell what an update changed
+type premiumTerm struct {
+	premium            float64
+	termStart, termEnd time.Time
+	recorded           int // changes in the policy's history
+}
+
+// NewPremiumHistoryService creates a new premium history service
+func NewPremiumHistoryService(repo repository.PremiumHistoryStore, flags *features.Flags, logger *logrus.Logger) *PremiumHistoryService {
+	return &PremiumHistoryService{
+		repo:   repo,
+		flags:  flags,
+		logger: logger,
+		seen:   make(map[string]premiumTerm),
+	}
+}
+
+// Follow records the premium changes of the policies repo announces. Call
+// it once the repository has loaded, before it is written to. Policies
+// without a history yet, such as seeded ones, start theirs at the premium
+// they hold now.
+func (s *PremiumHistoryService) Follow(repo ChangeFollower) {
+	s.mu.Lock()
+	for _, policy := range s.repo.GetAllPolicies() {
+		changes := s.repo.GetPremiumChanges(policy.ID)
+		term := premiumTerm{premium: policy.Premium, termStart: termStart(policy, changes), termEnd: policy.EndDate, recorded: len(changes)}
+		if len(changes) == 0 {
+			s.record(policy, models.PremiumIssued, policy.StartDate, &term)
+		}
+		s.seen[policy.ID] = term
+	}
+	s.mu.Unlock()
+
+	repo.OnCreate("policies", s.policyChanged)
+	repo.OnUpdate("policies", s.policyChanged)
+}
+
+// termStart is when policy's current term started: at its last renewal,
+// or on its start date
+func termStart(policy *models.Policy, changes []*models.PremiumChange) time.Time {
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].Kind == models.PremiumRenewal {
+			return changes[i].TermStart
+		}
+	}
+	return policy.StartDate
+}
+
+// policyChanged records the premium change a write to a policy made, if
+// any
+func (s *PremiumHistoryService) policyChanged(change hooks.Change) {
+	policy := change.Value.(*models.Policy)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, known := s.seen[policy.ID]
+	term := previous
+	term.premium = policy.Premium
+	term.termEnd = policy.EndDate
+	switch {
+	case change.Op == hooks.OpCreate || !known:
+		term.termStart = policy.StartDate
+		s.record(policy, models.PremiumIssued, policy.StartDate, &term)
+	case policy.EndDate.After(previous.termEnd):
+		term.termStart = previous.termEnd
+		s.record(policy, models.PremiumRenewal, previous.termEnd, &term)
+	case policy.Premium != previous.premium:
+		s.record(policy, models.PremiumEndorsement, time.Now(), &term)
+	}
+	s.seen[policy.ID] = term
+}
+
+// record stores a point of a policy's premium history, in term, under
+// s.mu. A history that cannot be written to is logged rather than failing
+// the policy change.
+func (s *PremiumHistoryService) record(policy *models.Policy, kind string, effectiveAt time.Time, term *premiumTerm) {
+	change := &models.PremiumChange{
+		ID:          fmt.Sprintf("prm-%s-%d", policy.ID, term.recorded+1),
+		PolicyID:    policy.ID,
+		CustomerID:  policy.CustomerID,
+		Kind:        kind,
+		Premium:     policy.Premium,
+		TermStart:   term.termStart,
+		TermEnd:     term.termEnd,
+		EffectiveAt: effectiveAt,
+		RecordedAt:  time.Now(),
+	}
+	if err := s.repo.AddPremiumChange(change); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"policyId": policy.ID,
+			"kind":     kind,
+		}).Warn("Failed to record premium change")
+		return
+	}
+	term.recorded++
+}
+
+// GetPremiumHistory returns the premium of each of a customer's policies
+// over time, each change annotated with how it compares to the one before
+func (s *PremiumHistoryService) GetPremiumHistory(customerID string) (*models.PremiumHistory, error) {
+	policies, err := s.repo.GetPoliciesByCustomerID(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policies: %w", err)
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		if !policies[i].StartDate.Equal(policies[j].StartDate) {
+			return policies[i].StartDate.Before(policies[j].StartDate)
+		}
+		return policies[i].ID < policies[j].ID
+	})
+
+	history := &models.PremiumHistory{
+		CustomerID: customerID,
+		Currency:   s.flags.GetCurrency(),
+		Policies:   make([]models.PolicyPremiumHistory, 0, len(policies)),
+	}
+	for _, policy := range policies {
+		history.Policies = append(history.Policies, models.PolicyPremiumHistory{
+			PolicyID:     policy.ID,
+			PolicyNumber: policy.PolicyNumber,
+			Type:         policy.Type,
+			Status:       policy.Status,
+			Premium:      policy.Premium,
+			History:      premiumPoints(s.repo.GetPremiumChanges(policy.ID)),
+		})
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"customerId": customerID,
+		"policies":   len(history.Policies),
+	}).Debug("Retrieved premium history")
+
+	return history, nil
+}
+
+// premiumPoints annotates each change of a policy's history with how it
+// compares to the one before
+func premiumPoints(changes []*models.PremiumChange) []models.PremiumPoint {
+	points := make([]models.PremiumPoint, 0, len(changes))
+	for i, change := range changes {
+		point := models.PremiumPoint{
+			Kind:        change.Kind,
+			Premium:     change.Premium,
+			Annotation:  premiumAnnotation(change.Kind, nil),
+			TermStart:   change.TermStart,
+			TermEnd:     change.TermEnd,
+			EffectiveAt: change.EffectiveAt,
+		}
+		if i > 0 {
+			previous := changes[i-1].Premium
+			point.Change = math.Round((change.Premium-previous)*100) / 100
+			if previous != 0 {
+				percent := math.Round((change.Premium-previous)/previous*1000) / 10
+				point.ChangePercent = &percent
+			}
+			point.Annotation = premiumAnnotation(change.Kind, point.ChangePercent)
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// premiumAnnotation describes a premium change for an agent to read out,
+// such as "Renewed, up 12.5%"
+func premiumAnnotation(kind string, percent *float64) string {
+	action := map[string]string{
+		models.PremiumIssued:      "Issued",
+		models.PremiumRenewal:     "Renewed",
+		models.PremiumEndorsement: "Endorsed",
+	}[kind]
+	switch {
+	case percent == nil:
+		return action
+	case *percent > 0:
+		return fmt.Sprintf("%s, up %.1f%%", action, *percent)
+	case *percent < 0:
+		return fmt.Sprintf("%s, down %.1f%%", action, -*percent)
+	default:
+		return action + ", no change"
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+func TestPremiumHistoryFollowsRenewalsAndEndorsements(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := repository.NewRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	history := NewPremiumHistoryService(repo, nil, logger)
+	history.Follow(repo)
+
+	update := func(change func(policy *models.Policy)) {
+		t.Helper()
+		policy, err := repo.GetPolicyByID("pol-001")
+		if err != nil {
+			t.Fatalf("GetPolicyByID failed: %v", err)
+		}
+		updated := *policy
+		change(&updated)
+		if _, err := repo.UpdatePolicy(&updated); err != nil {
+			t.Fatalf("UpdatePolicy failed: %v", err)
+		}
+	}
+	renewedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	update(func(policy *models.Policy) {
+		policy.EndDate = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		policy.Premium = 1400
+	})
+	update(func(policy *models.Policy) { policy.Premium = 1330 })
+	update(func(policy *models.Policy) { policy.Status = models.StatusGrace })
+	if _, err := repo.CreatePolicy(models.CreatePolicyRequest{CustomerID: "customer-001", Type: "life", Premium: 600, StartDate: renewedAt.AddDate(1, 1, 0), EndDate: renewedAt.AddDate(11, 1, 0)}); err != nil {
+		t.Fatalf("CreatePolicy failed: %v", err)
+	}
+
+	got, err := history.GetPremiumHistory("customer-001")
+	if err != nil {
+		t.Fatalf("GetPremiumHistory failed: %v", err)
+	}
+	if got.Currency != "USD" || len(got.Policies) != 3 || got.Policies[0].PolicyID != "pol-001" || got.Policies[1].PolicyID != "pol-002" || got.Policies[2].Type != "life" {
+		t.Fatalf("Unexpected history %+v", got)
+	}
+
+	points := got.Policies[0].History
+	want := []struct {
+		kind       string
+		premium    float64
+		annotation string
+	}{
+		{models.PremiumIssued, 1250, "Issued"},
+		{models.PremiumRenewal, 1400, "Renewed, up 12.0%"},
+		{models.PremiumEndorsement, 1330, "Endorsed, down 5.0%"},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("Expected %d points, got %+v", len(want), points)
+	}
+	for i, w := range want {
+		if points[i].Kind != w.kind || points[i].Premium != w.premium || points[i].Annotation != w.annotation {
+			t.Errorf("Point %d: expected %s at %.2f (%s), got %+v", i, w.kind, w.premium, w.annotation, points[i])
+		}
+	}
+	if points[0].Change != nil || points[0].ChangePercent != nil {
+		t.Errorf("Expected no change on the first point, got %+v", points[0])
+	}
+	if points[1].Change != 150.0 || *points[1].ChangePercent != 12.0 || !points[1].EffectiveAt.Equal(renewedAt) {
+		t.Errorf("Unexpected renewal %+v", points[1])
+	}
+	if !points[2].TermStart.Equal(renewedAt) || points[2].Change != -70.0 {
+		t.Errorf("Expected the endorsement within the renewed term, got %+v", points[2])
+	}
+	if len(got.Policies[1].History) != 1 || len(got.Policies[2].History) != 1 || got.Policies[2].History[0].Kind != models.PremiumIssued {
+		t.Errorf("Expected a single issued point for the other policies, got %+v", got.Policies[1:])
+	}
+
+	if other, err := history.GetPremiumHistory("customer-404"); err != nil || len(other.Policies) != 0 {
+		t.Errorf("Expected an empty history for a customer without policies, got %+v, %v", other, err)
+	}
+}
@@ -0,0 +1,426 @@
+// Package app assembles the pricing engine from its repository, services,
+// handlers and routes. cmd/server serves it over HTTP; tests can mount the
+// same handler on an httptest server.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/admission"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/telematics"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/health"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the settings needed to assemble the service
+type Config struct {
+	DataPath      string
+	FeatureAPIKey string
+
+	// CustomerServiceURL is the base URL of customer-service. When empty the
+	// paperless billing discount trusts the quote request instead of the
+	// customer's recorded consent.
+	CustomerServiceURL string
+
+	// PolicyServiceURL is the base URL of policy-service. When empty the
+	// multi-policy discount trusts the quote request instead of the policies
+	// held across the customer's household.
+	PolicyServiceURL string
+
+	// PrecomputePremiums warms a cache of base premiums for every policy
+	// type, coverage tier, age band and risk score at startup, so most quotes
+	// skip those rules lookups.
+	PrecomputePremiums bool
+
+	// RulesReloadInterval is how often the pricing rules files are checked
+	// for changes and reloaded, refreshing the premium cache; zero loads
+	// them once at startup.
+	RulesReloadInterval time.Duration
+
+	// BusinessRulesFile holds the expressions of the business rules
+	// engine; its quote rules decline quote requests before they are
+	// priced. When empty business-rules.json in DataPath is used, and
+	// without that file quotes are not underwritten until rules are set
+	// through the admin API. BusinessRulesReloadInterval is how often the
+	// file is checked for changes; 0 loads it once at startup.
+	BusinessRulesFile           string
+	BusinessRulesReloadInterval time.Duration
+
+	// QuoteValidity is how long quotes stay valid before they must be
+	// requoted, by policy type. Its policy types must be in the pricing
+	// rules.
+	QuoteValidity services.QuoteValidity
+
+	// ShapingFile holds the field visibility rules of the responses, on
+	// top of their shape tags. When empty response-shaping.json in
+	// DataPath is used, and without that file the tags alone apply.
+	ShapingFile string
+
+	// PersistDir holds the write-ahead log and snapshot that keep the quote
+	// history across restarts. When empty quotes are kept in memory only.
+	// PersistFlushInterval is how often the log is folded into the snapshot.
+	PersistDir           string
+	PersistFlushInterval time.Duration
+
+	// Admission bounds quote bursts: a token bucket per X-API-Key, how many
+	// quotes are calculated at once and how many may queue. Zero fields use
+	// the admission package defaults.
+	Admission admission.Config
+
+	// Maintenance is the maintenance mode the service starts in
+	Maintenance maintenance.Config
+	// Faults is whether the service may inject faults for resilience
+	// testing; the rules are set at /admin/faults
+	Faults faults.Config
+
+	// SlowRequestThreshold logs requests that take at least this long as
+	// warnings; 0 disables the warning
+	SlowRequestThreshold time.Duration
+
+	// AccessLog receives one structured entry per request; when nil they
+	// are written to the service log
+	AccessLog *logrus.Logger
+	// ErrorReporter receives handler panics, such as a Sentry hub; nil only
+	// logs them
+	ErrorReporter telemetry.ErrorReporter
+}
+
+// App is an assembled pricing engine
+type App struct {
+	Handler http.Handler
+	Flags   *features.Flags
+
+	journal           *persist.Journal
+	stopAdmission     lifecycle.StopFunc
+	stopRules         lifecycle.StopFunc
+	stopBusinessRules lifecycle.StopFunc
+	logger            *logrus.Logger
+}
+
+// New wires the service together and loads its data from cfg.DataPath
+func New(cfg Config, logger *logrus.Logger) (*App, error) {
+	// Initialize CloudBees Feature Management
+	flags, err := features.Initialize(cfg.FeatureAPIKey, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize feature management: %w", err)
+	}
+
+	// Log feature flag status
+	logger.WithFields(logrus.Fields{
+		"dynamicRates": flags.IsDynamicRatesEnabled(),
+	}).Info("Feature flags initialized")
+
+	// Initialize repository
+	repo, err := repository.NewRepository(cfg.DataPath, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, fmt.Errorf("failed to initialize repository: %w", err)
+	}
+
+	if err := checkQuoteValidity(cfg.QuoteValidity, repo.GetPricingRules()); err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+
+	// Quote requests are underwritten by the quote rules of the business
+	// rules engine
+	businessRules, err := loadBusinessRules(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+	shaper, err := loadShaping(cfg, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, err
+	}
+
+	// A rate experiment can only quote from rules versions that are loaded
+	if experiment := flags.RateExperiment(); experiment != nil {
+		for _, variant := range experiment.Variants {
+			if _, err := repo.ForVersion(variant.RulesVersion); err != nil {
+				logger.WithError(err).WithFields(logrus.Fields{
+					"experimentId": experiment.ID,
+					"variant":      variant.Name,
+				}).Warn("Experiment variant will be quoted from current rules")
+			}
+		}
+	}
+
+	quoteRepo, err := repository.NewQuoteRepository(cfg.DataPath, logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, fmt.Errorf("failed to initialize quote history: %w", err)
+	}
+
+	var journal *persist.Journal
+	if cfg.PersistDir != "" {
+		journal, err = persist.Open(cfg.PersistDir, "pricing-engine", cfg.PersistFlushInterval, logger)
+		if err != nil {
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to open persisted state: %w", err)
+		}
+		if err := quoteRepo.Persist(journal); err != nil {
+			journal.Close()
+			features.Shutdown()
+			return nil, fmt.Errorf("failed to restore persisted state: %w", err)
+		}
+	}
+
+	// Driving scores for usage-based discounts come from a file until a
+	// telematics provider is integrated
+	telematicsProvider, err := telematics.NewFileProvider(filepath.Join(cfg.DataPath, "telematics.json"), logger)
+	if err != nil {
+		features.Shutdown()
+		return nil, fmt.Errorf("failed to load telematics scores: %w", err)
+	}
+
+	// Paperless billing consent is recorded in customer-service
+	var consentLookup services.ConsentLookup
+	if cfg.CustomerServiceURL != "" {
+		consentLookup = clients.NewCustomerClient(cfg.CustomerServiceURL, 5*time.Second)
+	}
+
+	// Households' policies are held in policy-service
+	var policyLookup services.HouseholdPolicyLookup
+	if cfg.PolicyServiceURL != "" {
+		policyLookup = clients.NewPolicyClient(cfg.PolicyServiceURL, 5*time.Second)
+	}
+
+	// Base premiums are precomputed before serving and again whenever the
+	// rules reload
+	var premiumCache *services.PremiumCache
+	if cfg.PrecomputePremiums {
+		premiumCache = services.NewPremiumCache(repo, logger)
+		premiumCache.Refresh()
+	}
+	var stopRules lifecycle.StopFunc
+	if cfg.RulesReloadInterval > 0 {
+		stopRules = lifecycle.Go(func(ctx context.Context) {
+			repo.WatchRules(ctx, cfg.RulesReloadInterval, func() {
+				logger.WithField("version", repo.Versions()[0]).Info("Reloaded pricing rules")
+				if premiumCache != nil {
+					premiumCache.Refresh()
+				}
+			})
+		})
+	}
+
+	// Quote requests are underwritten by the quote rules, reloaded whenever
+	// their file changes
+	var stopBusinessRules lifecycle.StopFunc
+	if cfg.BusinessRulesReloadInterval > 0 {
+		stopBusinessRules = lifecycle.Go(func(ctx context.Context) {
+			businessRules.Watch(ctx, cfg.BusinessRulesReloadInterval, func() {
+				logger.WithField("version", businessRules.Version()).Info("Reloaded business rules")
+			})
+		})
+	}
+
+	// Initialize services
+	experimentService := services.NewExperimentService(repo, flags, services.DefaultExperimentQuoteLimit, logger)
+	quoteHistoryService := services.NewQuoteHistoryService(quoteRepo, experimentService, logger)
+	// Quote IDs are numbered each day from sequences kept with the quote
+	// history, so they survive restarts along with it
+	quoteIDs := services.NewQuoteIDs(quoteRepo)
+	pricingService := services.NewPricingService(repo, flags, quoteHistoryService, quoteIDs, cfg.QuoteValidity, telematicsProvider, consentLookup, policyLookup, premiumCache, businessRules, logger)
+
+	// Quotes beyond a caller's rate or the free slots wait in a queue
+	quoteAdmission := admission.New(cfg.Admission, logger)
+	stopAdmission := lifecycle.Go(quoteAdmission.Run)
+
+	// Initialize handlers
+	maintenanceMode := maintenance.New(cfg.Maintenance, logger)
+	faultInjection := faults.New(cfg.Faults, logger)
+	healthHandler := health.NewHandler("pricing-engine", health.Combine(flags, maintenanceMode, faultInjection))
+	pricingHandler := handlers.NewPricingHandler(pricingService, quoteAdmission, logger)
+	experimentHandler := handlers.NewExperimentHandler(experimentService, logger)
+	quoteHistoryHandler := handlers.NewQuoteHistoryHandler(quoteHistoryService, logger)
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Apply global middleware
+	requestMetrics := telemetry.NewRegistry(nil)
+	panics := telemetry.NewPanicCounter()
+	router.Use(middleware.LoggingMiddleware(logger, middleware.LoggingOptions{
+		Metrics:       requestMetrics,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		AccessLog:     cfg.AccessLog,
+	}))
+	// Error messages are translated into the caller's Accept-Language,
+	// including those of recovered panics
+	router.Use(i18n.Middleware(i18n.Default()))
+	router.Use(middleware.RecoveryMiddleware(logger, middleware.RecoveryOptions{
+		Panics:   panics,
+		Reporter: cfg.ErrorReporter,
+	}))
+	// Turn callers away during maintenance before they are authenticated
+	router.Use(maintenanceMode.Middleware)
+	// Inject test faults ahead of authentication too, so every caller is
+	// faulted the same way
+	router.Use(faultInjection.Middleware)
+	router.Use(middleware.AuthMiddleware(logger))
+	// Quote callers are customers and agents, so only flag rules of the
+	// shaping policy tell them apart
+	router.Use(shaping.Middleware(shaper, func(r *http.Request) shaping.Viewer {
+		return shaping.Viewer{Flags: shaping.FlagsOn(flags.EnabledFlags())}
+	}))
+
+	// Setup CORS
+	corsHandler := middleware.NewCORS()
+
+	// Register routes
+	router.Handle("/healthz", healthHandler).Methods("GET")
+	router.Handle("/labels", i18n.LabelsHandler(i18n.Default())).Methods("GET")
+	collectors := []telemetry.Collector{requestMetrics, panics, quoteIDs, pricingService, quoteAdmission, businessRules, faultInjection}
+	if premiumCache != nil {
+		collectors = append(collectors, premiumCache)
+	}
+	router.Handle("/metrics", telemetry.Handler(collectors...)).Methods("GET")
+	rateLimited := middleware.RateLimitHeaders(quoteAdmission)
+	router.Handle("/quote", rateLimited(http.HandlerFunc(pricingHandler.GetQuote))).Methods("POST")
+	router.Handle("/quote/compare", rateLimited(http.HandlerFunc(pricingHandler.CompareQuotes))).Methods("POST")
+	router.HandleFunc("/quote/jobs/{id}", pricingHandler.GetQuoteJob).Methods("GET")
+	router.HandleFunc("/quote/{id}/convert", quoteHistoryHandler.ConvertQuote).Methods("POST")
+	router.Handle("/quotes/{id}/requote", rateLimited(http.HandlerFunc(pricingHandler.Requote))).Methods("POST")
+	router.HandleFunc("/rates", pricingHandler.GetRates).Methods("GET")
+	router.HandleFunc("/limits", pricingHandler.GetLimits).Methods("GET")
+	router.HandleFunc("/experiments/{id}/results", experimentHandler.GetResults).Methods("GET")
+	router.HandleFunc("/customers/{id}/quotes", quoteHistoryHandler.GetCustomerQuotes).Methods("GET")
+
+	// Pricing has no staff roles, so maintenance mode and the business
+	// rules are managed with the bypass token
+	router.Handle(maintenance.Path, maintenanceMode.RequireBypass(maintenanceMode.Handler())).Methods("GET", "PUT")
+	router.Handle(faults.Path, maintenanceMode.RequireBypass(faultInjection.Handler())).Methods("GET", "PUT")
+	router.Handle(rules.Path, maintenanceMode.RequireBypass(businessRules.Handler())).Methods("GET", "PUT")
+	router.Handle(rules.TestPath, maintenanceMode.RequireBypass(businessRules.TestHandler())).Methods("POST")
+
+	// Wrap router with CORS
+	return &App{
+		Handler:           corsHandler.Handler(router),
+		Flags:             flags,
+		journal:           journal,
+		stopAdmission:     stopAdmission,
+		stopRules:         stopRules,
+		stopBusinessRules: stopBusinessRules,
+		logger:            logger,
+	}, nil
+}
+
+// serverDrainTimeout is how long requests in flight get to finish on
+// shutdown
+const serverDrainTimeout = 30 * time.Second
+
+// RegisterShutdown registers the service's components with m in the order
+// they stop. server, when given, drains first, then the quote queue, so
+// requests in flight and quotes being calculated can still write to the
+// journal before it writes its final snapshot.
+func (a *App) RegisterShutdown(m *lifecycle.Manager, server lifecycle.StopFunc) {
+	if server != nil {
+		m.Register("http server", serverDrainTimeout, server)
+	}
+	m.Register("quote queue", 10*time.Second, a.stopAdmission)
+	if a.stopRules != nil {
+		m.Register("rules reload", 5*time.Second, a.stopRules)
+	}
+	if a.stopBusinessRules != nil {
+		m.Register("business rules reload", 5*time.Second, a.stopBusinessRules)
+	}
+	m.Register("persisted state", 10*time.Second, func(ctx context.Context) error {
+		return a.journal.Close()
+	})
+	m.Register("feature flags", 5*time.Second, func(ctx context.Context) error {
+		features.Shutdown()
+		return nil
+	})
+}
+
+// Close stops the service's components without an HTTP server, writing a
+// final snapshot of persisted state
+func (a *App) Close() {
+	m := lifecycle.New(a.logger)
+	a.RegisterShutdown(m, nil)
+	m.Shutdown(context.Background())
+}
+
+// checkQuoteValidity refuses validities set for policy types the pricing
+// rules do not rate, such as a misspelled one
+func checkQuoteValidity(validity services.QuoteValidity, rules *models.PricingRules) error {
+	for policyType := range validity.PolicyTypes {
+		if _, ok := rules.BaseRates[policyType]; !ok {
+			return fmt.Errorf("quote validity set for unknown policy type %s", policyType)
+		}
+	}
+	return nil
+}
+
+// loadBusinessRules reads the quote rules of the business rules engine. A
+// configured file must load; the default file may be missing.
+func loadBusinessRules(cfg Config, logger *logrus.Logger) (*rules.Engine, error) {
+	path := cfg.BusinessRulesFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "business-rules.json")
+	}
+	engine := rules.New(rules.Config{Path: path, Domains: []string{rules.DomainQuote}}, logger)
+	err := engine.Load()
+	if errors.Is(err, os.ErrNotExist) && cfg.BusinessRulesFile == "" {
+		logger.Warnf("No business rules in %s, quotes are not underwritten", path)
+		return engine, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load business rules: %w", err)
+	}
+	logger.WithFields(logrus.Fields{
+		"version": engine.Version(),
+		"rules":   engine.Len(),
+	}).Infof("Loaded business rules from %s", path)
+	return engine, nil
+}
+
+// loadShaping reads the response shaping policy and checks it against the
+// quote responses. A configured file must load; the default file may be
+// missing.
+func loadShaping(cfg Config, logger *logrus.Logger) (*shaping.Shaper, error) {
+	path := cfg.ShapingFile
+	if path == "" {
+		path = filepath.Join(cfg.DataPath, "response-shaping.json")
+	}
+	policy, err := shaping.Load(path)
+	if errors.Is(err, os.ErrNotExist) && cfg.ShapingFile == "" {
+		logger.Warnf("No response shaping policy in %s, shape tags alone apply", path)
+		policy = shaping.Default()
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load response shaping policy: %w", err)
+	} else {
+		logger.WithField("version", policy.Version).Infof("Loaded response shaping policy from %s", path)
+	}
+
+	shaper := shaping.New(policy, "pricing-engine")
+	if err := shaper.Check(models.Quote{}, models.QuoteComparison{}, models.CustomerQuoteHistory{}, models.Requote{}, models.DeclinedQuote{}); err != nil {
+		return nil, fmt.Errorf("invalid response shaping: %w", err)
+	}
+	return shaper, nil
+}
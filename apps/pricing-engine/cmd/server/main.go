@@ -7,36 +7,37 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/features"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/handlers"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/middleware"
-	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/admission"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/services"
-	"github.com/gorilla/mux"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/logging"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	// Initialize logger
-	logger := logrus.New()
-	logger.SetFormatter(&logrus.JSONFormatter{})
+	// Initialize logger from LOG_FORMAT, LOG_LEVEL, LOG_LEVELS and sampling settings
+	logger := logging.NewFromEnv()
 
-	// Configure log level from environment
-	logLevel := os.Getenv("LOG_LEVEL")
-	if logLevel == "" {
-		logLevel = "info"
-	}
-	level, err := logrus.ParseLevel(logLevel)
+	logger.Info("Starting Pricing Engine service...")
+
+	// Secrets come from SECRETS_PROVIDER: the environment by default, or
+	// mounted files, Vault or AWS Secrets Manager. Startup fails when one
+	// of SECRETS_REQUIRED does not resolve.
+	secretStore, err := secrets.Open(secrets.ConfigFromEnv(logger), logger)
 	if err != nil {
-		logger.Warnf("Invalid log level '%s', defaulting to info", logLevel)
-		level = logrus.InfoLevel
+		logger.WithError(err).Fatal("Failed to resolve secrets")
 	}
-	logger.SetLevel(level)
-
-	logger.Info("Starting Pricing Engine service...")
+	secrets.SetDefault(secretStore)
+	logger.WithField("provider", secretStore.Source()).Info("Secrets resolved")
 
 	// Get configuration from environment
 	port := os.Getenv("PORT")
@@ -50,75 +51,228 @@ func main() {
 		dataPath = filepath.Join("..", "..", "data", "seed")
 	}
 
-	cloudBeesAPIKey := os.Getenv("CLOUDBEES_FM_API_KEY")
+	cloudBeesAPIKey := secretStore.Lookup("CLOUDBEES_FM_API_KEY", "")
 	if cloudBeesAPIKey == "" {
 		logger.Warn("CLOUDBEES_FM_API_KEY not set, feature flags will use defaults")
 		// Use a placeholder for development
 		cloudBeesAPIKey = "dev-mode"
 	}
 
-	// Initialize CloudBees Feature Management
-	flags, err := features.Initialize(cloudBeesAPIKey, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize feature management")
+	// Paperless billing consent is checked in customer-service
+	customerServiceURL := os.Getenv("CUSTOMER_SERVICE_URL")
+	if customerServiceURL == "" {
+		logger.Warn("CUSTOMER_SERVICE_URL not set, paperless discount will trust the quote request")
 	}
-	defer features.Shutdown()
 
-	// Log feature flag status
-	logger.WithFields(logrus.Fields{
-		"dynamicRates": flags.IsDynamicRatesEnabled(),
-	}).Info("Feature flags initialized")
+	// Multi-policy eligibility is checked against household policies in
+	// policy-service
+	policyServiceURL := os.Getenv("POLICY_SERVICE_URL")
+	if policyServiceURL == "" {
+		logger.Warn("POLICY_SERVICE_URL not set, multi-policy discount will trust the quote request")
+	}
 
-	// Initialize repository
-	repo, err := repository.NewRepository(dataPath, logger)
-	if err != nil {
-		logger.WithError(err).Fatal("Failed to initialize repository")
+	// Persisted quote history, so quotes survive restarts
+	persistDir := os.Getenv("PERSIST_DIR")
+	if persistDir == "" {
+		logger.Warn("PERSIST_DIR not set, quote history will be lost on restart")
+	}
+	persistFlushInterval := time.Minute
+	if v := os.Getenv("PERSIST_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			persistFlushInterval = d
+		} else {
+			logger.Warnf("Invalid PERSIST_FLUSH_INTERVAL '%s', defaulting to %s", v, persistFlushInterval)
+		}
 	}
 
-	// Initialize services
-	pricingService := services.NewPricingService(repo, flags, logger)
+	// Requests at least this slow are logged as warnings
+	slowRequestThreshold := time.Second
+	if v := os.Getenv("HTTP_SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			slowRequestThreshold = d
+		} else {
+			logger.Warnf("Invalid HTTP_SLOW_REQUEST_THRESHOLD '%s', defaulting to %s", v, slowRequestThreshold)
+		}
+	}
+
+	// Precomputed base premiums and rules reloads
+	precomputePremiums := os.Getenv("PRICING_PRECOMPUTE") == "true"
+	rulesReloadInterval := 30 * time.Second
+	if v := os.Getenv("RULES_RELOAD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			rulesReloadInterval = d
+		} else {
+			logger.Warnf("Invalid RULES_RELOAD_INTERVAL '%s', defaulting to %s", v, rulesReloadInterval)
+		}
+	}
 
-	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler("pricing-engine")
-	pricingHandler := handlers.NewPricingHandler(pricingService, logger)
+	// Business rules engine expressions underwriting quotes; defaults to
+	// business-rules.json in DATA_PATH, checked for changes every
+	// BUSINESS_RULES_RELOAD_INTERVAL
+	businessRulesFile := os.Getenv("BUSINESS_RULES_FILE")
+	businessRulesReloadInterval := 30 * time.Second
+	if v := os.Getenv("BUSINESS_RULES_RELOAD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			businessRulesReloadInterval = d
+		} else {
+			logger.Warnf("Invalid BUSINESS_RULES_RELOAD_INTERVAL '%s', defaulting to %s", v, businessRulesReloadInterval)
+		}
+	}
 
-	// Setup router
-	router := mux.NewRouter()
+	// Field visibility rules on top of the shape tags; defaults to
+	// response-shaping.json in DATA_PATH
+	shapingFile := os.Getenv("SHAPING_FILE")
 
-	// Apply global middleware
-	router.Use(middleware.LoggingMiddleware(logger))
-	router.Use(middleware.AuthMiddleware(logger))
+	// How long quotes stay valid before they must be requoted, with
+	// overrides per policy type
+	quoteValidity := services.QuoteValidity{Default: services.DefaultQuoteValidity}
+	if v := os.Getenv("QUOTE_VALIDITY_DAYS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			quoteValidity.Default = time.Duration(n) * 24 * time.Hour
+		} else {
+			logger.Warnf("Invalid QUOTE_VALIDITY_DAYS '%s', defaulting to %d", v, int(services.DefaultQuoteValidity.Hours()/24))
+		}
+	}
+	if v := os.Getenv("QUOTE_VALIDITY_DAYS_BY_TYPE"); v != "" {
+		if parsed, err := services.ParseQuoteValidityDays(v); err == nil {
+			quoteValidity.PolicyTypes = parsed
+		} else {
+			logger.WithError(err).Warn("Invalid QUOTE_VALIDITY_DAYS_BY_TYPE, using QUOTE_VALIDITY_DAYS for every policy type")
+		}
+	}
 
-	// Setup CORS
-	corsHandler := middleware.NewCORS()
+	// Quote admission: a token bucket per X-API-Key and a bounded queue in
+	// front of a fixed number of quote slots
+	quoteAdmission := admission.Config{
+		Rate:        admission.DefaultRate,
+		Burst:       admission.DefaultBurst,
+		Concurrency: admission.DefaultConcurrency,
+		QueueSize:   admission.DefaultQueueSize,
+		QueueWait:   admission.DefaultQueueWait,
+		MaxDelay:    admission.DefaultMaxDelay,
+	}
+	if v := os.Getenv("QUOTE_RATE_PER_KEY"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			quoteAdmission.Rate = n
+			if n == 0 {
+				quoteAdmission.Rate = -1
+			}
+		} else {
+			logger.Warnf("Invalid QUOTE_RATE_PER_KEY '%s', defaulting to %g", v, quoteAdmission.Rate)
+		}
+	}
+	if v := os.Getenv("QUOTE_BURST"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			quoteAdmission.Burst = n
+		} else {
+			logger.Warnf("Invalid QUOTE_BURST '%s', defaulting to %d", v, quoteAdmission.Burst)
+		}
+	}
+	if v := os.Getenv("QUOTE_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			quoteAdmission.Concurrency = n
+		} else {
+			logger.Warnf("Invalid QUOTE_CONCURRENCY '%s', defaulting to %d", v, quoteAdmission.Concurrency)
+		}
+	}
+	if v := os.Getenv("QUOTE_QUEUE_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			quoteAdmission.QueueSize = n
+		} else {
+			logger.Warnf("Invalid QUOTE_QUEUE_SIZE '%s', defaulting to %d", v, quoteAdmission.QueueSize)
+		}
+	}
+	if v := os.Getenv("QUOTE_QUEUE_WAIT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			quoteAdmission.QueueWait = d
+			if d == 0 {
+				quoteAdmission.QueueWait = -1
+			}
+		} else {
+			logger.Warnf("Invalid QUOTE_QUEUE_WAIT '%s', defaulting to %s", v, quoteAdmission.QueueWait)
+		}
+	}
+	if v := os.Getenv("QUOTE_MAX_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			quoteAdmission.MaxDelay = d
+		} else {
+			logger.Warnf("Invalid QUOTE_MAX_DELAY '%s', defaulting to %s", v, quoteAdmission.MaxDelay)
+		}
+	}
 
-	// Register routes
-	router.Handle("/healthz", healthHandler).Methods("GET")
-	router.HandleFunc("/quote", pricingHandler.GetQuote).Methods("POST")
-	router.HandleFunc("/rates", pricingHandler.GetRates).Methods("GET")
+	// Access entries go to the service log unless ACCESS_LOG names a
+	// separate stream: stdout, stderr or a file path
+	accessLog, closeAccessLog, err := telemetry.OpenAccessLog(os.Getenv("ACCESS_LOG"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open access log")
+	}
+	defer closeAccessLog()
 
-	// Wrap router with CORS
-	handler := corsHandler.Handler(router)
+	// Assemble the service
+	application, err := app.New(app.Config{
+		DataPath:                    dataPath,
+		FeatureAPIKey:               cloudBeesAPIKey,
+		CustomerServiceURL:          customerServiceURL,
+		PolicyServiceURL:            policyServiceURL,
+		PersistDir:                  persistDir,
+		PersistFlushInterval:        persistFlushInterval,
+		PrecomputePremiums:          precomputePremiums,
+		RulesReloadInterval:         rulesReloadInterval,
+		BusinessRulesFile:           businessRulesFile,
+		BusinessRulesReloadInterval: businessRulesReloadInterval,
+		ShapingFile:                 shapingFile,
+		QuoteValidity:               quoteValidity,
+		Admission:                   quoteAdmission,
+		Maintenance:                 maintenance.ConfigFromEnv(logger),
+		Faults:                      faults.ConfigFromEnv(logger),
+		SlowRequestThreshold:        slowRequestThreshold,
+		AccessLog:                   accessLog,
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize service")
+	}
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%s", port),
-		Handler:      handler,
+		Handler:      application.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Drain requests in flight and stop the service's components in order
+	conns := lifecycle.TrackConnections(server)
+	shutdown := lifecycle.New(logger)
+	application.RegisterShutdown(shutdown, lifecycle.DrainServer(server, conns))
+
 	// Start server in a goroutine
 	go func() {
 		logger.Infof("Server listening on port %s", port)
 		logger.Info("API Endpoints:")
 		logger.Info("  GET  /healthz - Health check")
+		logger.Info("  GET  /labels - Status and type labels in the Accept-Language language")
+		logger.Info("  GET  /metrics - Request latency by route and quote admission (Prometheus)")
 		logger.Info("  POST /quote - Calculate insurance quote")
+		logger.Info("  POST /quote/compare - Compare quotes across coverage amounts")
+		logger.Info("  GET  /quote/jobs/{id} - Poll a queued quote or comparison")
+		logger.Info("  POST /quote/{id}/convert - Mark a quote as bound into a policy")
+		logger.Info("  POST /quotes/{id}/requote - Requote an expired quote against current rules")
 		logger.Info("  GET  /rates - Get current base rates")
+		logger.Info("  GET  /limits - The caller's remaining quote rate limit")
+		logger.Info("  GET  /experiments/{id}/results - Compare pricing experiment variants")
+		logger.Info("  GET  /customers/{id}/quotes - Customer quote history and conversion")
+		logger.Info("  GET  /admin/maintenance - Maintenance mode (X-Maintenance-Bypass)")
+		logger.Info("  PUT  /admin/maintenance - Turn maintenance mode on or off (X-Maintenance-Bypass)")
+		logger.Info("  GET  /admin/faults - Fault injection rules, when enabled (X-Maintenance-Bypass)")
+		logger.Info("  PUT  /admin/faults - Replace the fault injection rules (X-Maintenance-Bypass)")
+		logger.Info("  GET  /admin/rules - Business rules and their evaluation counts (X-Maintenance-Bypass)")
+		logger.Info("  PUT  /admin/rules - Replace the business rules (X-Maintenance-Bypass)")
+		logger.Info("  POST /admin/rules/test - Evaluate the quote rules against a quote request (X-Maintenance-Bypass)")
 		logger.Info("")
 		logger.Info("Feature Flags:")
-		logger.Infof("  pricing.dynamicRates: %v (enables real-time rate adjustments)", flags.IsDynamicRatesEnabled())
+		logger.Infof("  pricing.dynamicRates: %v (enables real-time rate adjustments)", application.Flags.IsDynamicRatesEnabled())
+		logger.Infof("  pricing.rateExperiment: %v (quotes a share of customers from candidate rules)", application.Flags.RateExperiment() != nil)
 
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.WithError(err).Fatal("Server failed to start")
@@ -130,14 +284,16 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
-
-	// Graceful shutdown with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	open := conns.Connections()
+	logger.WithFields(logrus.Fields{
+		"active":   open.Active,
+		"idle":     open.Idle,
+		"hijacked": open.Hijacked,
+	}).Info("Shutting down server...")
 
-	if err := server.Shutdown(ctx); err != nil {
-		logger.WithError(err).Error("Server forced to shutdown")
+	if err := shutdown.Shutdown(context.Background()).Err(); err != nil {
+		logger.WithError(err).Error("Shutdown incomplete")
+		return
 	}
 
 	logger.Info("Server stopped gracefully")
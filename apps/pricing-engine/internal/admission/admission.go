@@ -0,0 +1,459 @@
+// Package admission meters quote requests, so bursts from comparison sites
+// cannot starve the pricing engine. Each API key draws from its own token
+// bucket, quotes run on a fixed number of slots, and requests that cannot
+// run straight away wait in a bounded queue. A caller whose request is still
+// queued after a short wait gets a job to poll; one that does not fit is
+// told when to retry.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultRate is the sustained quotes per second allowed per API key
+	DefaultRate = 20
+	// DefaultBurst is how many quotes an idle API key may send at once
+	DefaultBurst = 40
+	// DefaultConcurrency is how many quotes are calculated at the same time
+	DefaultConcurrency = 8
+	// DefaultQueueSize is how many requests may wait for their rate or a slot
+	DefaultQueueSize = 500
+	// DefaultQueueWait is how long a queued request is held open before the
+	// caller is given a job to poll
+	DefaultQueueWait = 2 * time.Second
+	// DefaultMaxDelay is the longest an API key's requests may be delayed
+	// to keep it within its rate before further requests are rejected
+	DefaultMaxDelay = 10 * time.Second
+	// DefaultJobTTL is how long a finished job can still be polled
+	DefaultJobTTL = 10 * time.Minute
+)
+
+// Config bounds quote admission. Zero fields use the defaults. A negative
+// Rate removes the per-key limit; a negative QueueWait hands out a job as
+// soon as a request is queued.
+type Config struct {
+	Rate        float64
+	Burst       int
+	Concurrency int
+	QueueSize   int
+	QueueWait   time.Duration
+	MaxDelay    time.Duration
+	JobTTL      time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.Rate == 0 {
+		c.Rate = DefaultRate
+	}
+	if c.Burst <= 0 {
+		c.Burst = DefaultBurst
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultConcurrency
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+	if c.QueueWait == 0 {
+		c.QueueWait = DefaultQueueWait
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultMaxDelay
+	}
+	if c.JobTTL <= 0 {
+		c.JobTTL = DefaultJobTTL
+	}
+	return c
+}
+
+// Job states
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// Job is a request admitted by Submit. Result holds what the request's
+// function returned once Status is done; Error its error once failed.
+type Job struct {
+	ID          string      `json:"id"`
+	Status      string      `json:"status"`
+	Result      interface{} `json:"result,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	SubmittedAt time.Time   `json:"submittedAt"`
+	CompletedAt *time.Time  `json:"completedAt,omitempty"`
+}
+
+// Func calculates one quote request
+type Func func(ctx context.Context) (interface{}, error)
+
+// Rejection is the error Submit returns for a request it could not queue
+type Rejection struct {
+	// Reason is "rate" when the API key is too far over its rate, or
+	// "queue" when the queue is full
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (r *Rejection) Error() string {
+	if r.Reason == "rate" {
+		return "quote rate limit exceeded for this API key"
+	}
+	return "quote queue is full"
+}
+
+// job is a Job with what is needed to run it
+type job struct {
+	Job
+	ctx  context.Context
+	fn   Func
+	done chan struct{}
+}
+
+// bucket is a token bucket. Tokens may go negative: each request past the
+// burst reserves a future token and waits for it.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// stats are the admission counters reported as metrics
+type stats struct {
+	run         uint64
+	queued      uint64
+	rateLimited uint64
+	queueFull   uint64
+	waitSeconds float64
+	waited      uint64
+}
+
+// Controller admits quote requests. Run must be running for queued requests
+// to be calculated.
+type Controller struct {
+	cfg    Config
+	slots  chan struct{}
+	ready  chan *job
+	logger *logrus.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	jobs    map[string]*job
+	pending int
+	stats   stats
+}
+
+// New creates a controller with cfg's limits
+func New(cfg Config, logger *logrus.Logger) *Controller {
+	cfg = cfg.withDefaults()
+	return &Controller{
+		cfg:     cfg,
+		slots:   make(chan struct{}, cfg.Concurrency),
+		ready:   make(chan *job, cfg.QueueSize),
+		logger:  logger,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Submit admits fn for the caller holding key, "" for callers without an API
+// key, who are not rate limited. fn runs straight away when key is within
+// its rate, a slot is free and nothing is waiting for one. Otherwise it is
+// queued and Submit waits up to QueueWait for it to finish, returning the
+// job still queued or running if it has not. Requests that cannot be queued
+// return a *Rejection. A nil controller runs fn straight away.
+func (c *Controller) Submit(ctx context.Context, key string, fn Func) (Job, error) {
+	if c == nil {
+		result, err := fn(ctx)
+		return finished(Job{SubmittedAt: time.Now()}, result, err, time.Now()), nil
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	delay, ok := c.reserve(key, now)
+	if !ok {
+		c.stats.rateLimited++
+		c.mu.Unlock()
+		retryAfter := delay - c.cfg.MaxDelay
+		c.logger.WithField("retryAfter", retryAfter.String()).Warn("Quote request over its API key's rate")
+		return Job{}, &Rejection{Reason: "rate", RetryAfter: retryAfter}
+	}
+
+	if delay == 0 && len(c.ready) == 0 {
+		select {
+		case c.slots <- struct{}{}:
+			c.stats.run++
+			c.mu.Unlock()
+			defer func() { <-c.slots }()
+			result, err := fn(ctx)
+			return finished(Job{SubmittedAt: now}, result, err, c.now()), nil
+		default:
+		}
+	}
+
+	if c.pending >= c.cfg.QueueSize {
+		c.stats.queueFull++
+		c.refund(key)
+		c.mu.Unlock()
+		c.logger.WithField("queueSize", c.cfg.QueueSize).Warn("Quote queue full, rejecting request")
+		return Job{}, &Rejection{Reason: "queue", RetryAfter: time.Second}
+	}
+	j := &job{
+		Job:  Job{ID: uuid.New().String(), Status: StatusQueued, SubmittedAt: now},
+		ctx:  context.WithoutCancel(ctx),
+		fn:   fn,
+		done: make(chan struct{}),
+	}
+	c.jobs[j.ID] = j
+	c.pending++
+	c.stats.queued++
+	c.mu.Unlock()
+
+	if delay > 0 {
+		time.AfterFunc(delay, func() { c.ready <- j })
+	} else {
+		c.ready <- j
+	}
+
+	if c.cfg.QueueWait > 0 {
+		timer := time.NewTimer(c.cfg.QueueWait)
+		defer timer.Stop()
+		select {
+		case <-j.done:
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	current, _ := c.Job(j.ID)
+	return current, nil
+}
+
+// reserve takes a token from key's bucket, returning how long the request
+// must wait for it. A request that would wait longer than MaxDelay is
+// refused and reserves nothing. Callers hold mu.
+func (c *Controller) reserve(key string, now time.Time) (time.Duration, bool) {
+	if key == "" || c.cfg.Rate < 0 {
+		return 0, true
+	}
+
+	b, ok := c.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(c.cfg.Burst), last: now}
+		c.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(c.cfg.Burst), b.tokens+now.Sub(b.last).Seconds()*c.cfg.Rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	delay := time.Duration((1 - b.tokens) / c.cfg.Rate * float64(time.Second))
+	if delay > c.cfg.MaxDelay {
+		return delay, false
+	}
+	b.tokens--
+	return delay, true
+}
+
+// Limit is where an API key stands against its rate
+type Limit struct {
+	// Limit is the burst an idle key may send at once
+	Limit int
+	// Remaining is how many more requests may run without being delayed
+	Remaining int
+	// Rate is the sustained requests per second the bucket refills at
+	Rate float64
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Limit returns key's current limit, without reserving anything. It
+// reports false for callers that are not rate limited: those without an
+// API key, all callers when per-key limits are off, and a nil controller.
+func (c *Controller) Limit(key string) (Limit, bool) {
+	if c == nil || key == "" || c.cfg.Rate < 0 {
+		return Limit{}, false
+	}
+
+	limit := Limit{Limit: c.cfg.Burst, Remaining: c.cfg.Burst, Rate: c.cfg.Rate}
+	now := c.now()
+	c.mu.Lock()
+	b, ok := c.buckets[key]
+	var tokens float64
+	if ok {
+		tokens = math.Min(float64(c.cfg.Burst), b.tokens+now.Sub(b.last).Seconds()*c.cfg.Rate)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return limit, true
+	}
+
+	limit.Remaining = int(math.Max(0, math.Floor(tokens)))
+	limit.Reset = time.Duration((float64(c.cfg.Burst) - tokens) / c.cfg.Rate * float64(time.Second))
+	return limit, true
+}
+
+// refund returns the token reserved for a request that was not queued.
+// Callers hold mu.
+func (c *Controller) refund(key string) {
+	if b, ok := c.buckets[key]; ok {
+		b.tokens++
+	}
+}
+
+// Job returns a queued, running or recently finished job
+func (c *Controller) Job(id string) (Job, bool) {
+	if c == nil {
+		return Job{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	j, ok := c.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return j.Job, true
+}
+
+// Run calculates queued requests on the controller's slots until ctx ends,
+// and forgets finished jobs and idle API keys as they expire. Requests
+// still queued when it returns are dropped.
+func (c *Controller) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < c.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			c.mu.Lock()
+			dropped := c.pending
+			c.mu.Unlock()
+			if dropped > 0 {
+				c.logger.WithField("count", dropped).Warn("Dropped queued quote requests on shutdown")
+			}
+			return
+		case <-ticker.C:
+			c.sweep(c.now())
+		}
+	}
+}
+
+// work runs ready jobs one at a time, each on a slot
+func (c *Controller) work(ctx context.Context) {
+	for {
+		var j *job
+		select {
+		case <-ctx.Done():
+			return
+		case j = <-c.ready:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case c.slots <- struct{}{}:
+		}
+		c.execute(j)
+		<-c.slots
+	}
+}
+
+// execute runs a queued job and records its outcome
+func (c *Controller) execute(j *job) {
+	c.mu.Lock()
+	j.Status = StatusRunning
+	c.stats.waitSeconds += c.now().Sub(j.SubmittedAt).Seconds()
+	c.stats.waited++
+	c.mu.Unlock()
+
+	result, err := j.fn(j.ctx)
+
+	c.mu.Lock()
+	j.Job = finished(j.Job, result, err, c.now())
+	c.pending--
+	c.mu.Unlock()
+	close(j.done)
+}
+
+// finished records the outcome of a job's function, completed at completed
+func finished(job Job, result interface{}, err error, completed time.Time) Job {
+	job.CompletedAt = &completed
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		return job
+	}
+	job.Status = StatusDone
+	job.Result = result
+	return job
+}
+
+// sweep forgets jobs finished more than JobTTL ago and the buckets of API
+// keys idle long enough to have refilled, which start full when next used
+func (c *Controller) sweep(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, j := range c.jobs {
+		if j.CompletedAt != nil && now.Sub(*j.CompletedAt) > c.cfg.JobTTL {
+			delete(c.jobs, id)
+		}
+	}
+	for key, b := range c.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*c.cfg.Rate >= float64(c.cfg.Burst) {
+			delete(c.buckets, key)
+		}
+	}
+}
+
+// WritePrometheus writes admission metrics for GET /metrics in the
+// Prometheus text exposition format
+func (c *Controller) WritePrometheus(w io.Writer) {
+	c.mu.Lock()
+	s := c.stats
+	pending := c.pending
+	c.mu.Unlock()
+
+	fmt.Fprint(w, "# HELP pricing_quote_admissions_total Quote requests by admission result.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_admissions_total counter\n")
+	fmt.Fprintf(w, "pricing_quote_admissions_total{result=\"run\"} %d\n", s.run)
+	fmt.Fprintf(w, "pricing_quote_admissions_total{result=\"queued\"} %d\n", s.queued)
+	fmt.Fprintf(w, "pricing_quote_admissions_total{result=\"rate_limited\"} %d\n", s.rateLimited)
+	fmt.Fprintf(w, "pricing_quote_admissions_total{result=\"queue_full\"} %d\n", s.queueFull)
+	fmt.Fprint(w, "# HELP pricing_quote_queue_depth Quote requests waiting for their rate or a slot.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_queue_depth gauge\n")
+	fmt.Fprintf(w, "pricing_quote_queue_depth %d\n", pending)
+	fmt.Fprint(w, "# HELP pricing_quote_queue_capacity Quote requests the queue can hold.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_queue_capacity gauge\n")
+	fmt.Fprintf(w, "pricing_quote_queue_capacity %d\n", c.cfg.QueueSize)
+	fmt.Fprint(w, "# HELP pricing_quote_in_flight Quotes being calculated.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_in_flight gauge\n")
+	fmt.Fprintf(w, "pricing_quote_in_flight %d\n", len(c.slots))
+	fmt.Fprint(w, "# HELP pricing_quote_concurrency Quotes that may be calculated at the same time.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_concurrency gauge\n")
+	fmt.Fprintf(w, "pricing_quote_concurrency %d\n", c.cfg.Concurrency)
+	fmt.Fprint(w, "# HELP pricing_quote_queue_wait_seconds Time queued quote requests waited before being calculated.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_queue_wait_seconds summary\n")
+	fmt.Fprintf(w, "pricing_quote_queue_wait_seconds_sum %g\n", s.waitSeconds)
+	fmt.Fprintf(w, "pricing_quote_queue_wait_seconds_count %d\n", s.waited)
+}
@@ -0,0 +1,226 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestController(t *testing.T, cfg Config) *Controller {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	c := New(cfg, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return c
+}
+
+func quote(premium float64) Func {
+	return func(ctx context.Context) (interface{}, error) { return premium, nil }
+}
+
+func TestSubmitRunsStraightAway(t *testing.T) {
+	c := newTestController(t, Config{})
+
+	job, err := c.Submit(context.Background(), "aggregator", quote(680))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.Status != StatusDone || job.Result != 680.0 || job.ID != "" {
+		t.Errorf("Expected a finished job without an ID, got %+v", job)
+	}
+
+	job, _ = c.Submit(context.Background(), "aggregator", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("invalid policy type")
+	})
+	if job.Status != StatusFailed || job.Error != "invalid policy type" {
+		t.Errorf("Expected a failed job, got %+v", job)
+	}
+}
+
+func TestSubmitQueuesOverRateAndPolls(t *testing.T) {
+	c := newTestController(t, Config{Rate: 20, Burst: 1, QueueWait: -1})
+
+	if job, _ := c.Submit(context.Background(), "aggregator", quote(1)); job.Status != StatusDone {
+		t.Fatalf("Expected the first quote to run straight away, got %+v", job)
+	}
+
+	// Over the burst: reserved for 50ms from now and handed back to poll
+	job, err := c.Submit(context.Background(), "aggregator", quote(2))
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if job.Status != StatusQueued || job.ID == "" {
+		t.Fatalf("Expected a queued job, got %+v", job)
+	}
+
+	// Another key has its own bucket
+	if other, _ := c.Submit(context.Background(), "other-site", quote(3)); other.Status != StatusDone {
+		t.Errorf("Expected another API key to run straight away, got %+v", other)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		polled, ok := c.Job(job.ID)
+		if !ok {
+			t.Fatalf("Job %s not found", job.ID)
+		}
+		if polled.Status == StatusDone {
+			if polled.Result != 2.0 || polled.CompletedAt == nil {
+				t.Errorf("Unexpected finished job %+v", polled)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Job still %s", polled.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubmitWaitsForQueuedQuote(t *testing.T) {
+	c := newTestController(t, Config{Rate: 20, Burst: 1, QueueWait: time.Second})
+
+	c.Submit(context.Background(), "aggregator", quote(1))
+	job, err := c.Submit(context.Background(), "aggregator", quote(2))
+	if err != nil || job.Status != StatusDone || job.Result != 2.0 {
+		t.Errorf("Expected the queued quote within the wait, got %+v (%v)", job, err)
+	}
+}
+
+func TestSubmitRejectsFarOverRate(t *testing.T) {
+	c := newTestController(t, Config{Rate: 1, Burst: 1, MaxDelay: 1500 * time.Millisecond, QueueWait: -1})
+
+	c.Submit(context.Background(), "aggregator", quote(1))
+	if job, err := c.Submit(context.Background(), "aggregator", quote(2)); err != nil || job.Status != StatusQueued {
+		t.Fatalf("Expected a request within the delay to be queued, got %+v (%v)", job, err)
+	}
+
+	_, err := c.Submit(context.Background(), "aggregator", quote(3))
+	var rejected *Rejection
+	if !errors.As(err, &rejected) || rejected.Reason != "rate" || rejected.RetryAfter <= 0 {
+		t.Fatalf("Expected a rate rejection, got %v", err)
+	}
+
+	// Callers without an API key are not rate limited
+	if job, err := c.Submit(context.Background(), "", quote(4)); err != nil || job.Status != StatusDone {
+		t.Errorf("Expected an anonymous quote to run, got %+v (%v)", job, err)
+	}
+}
+
+func TestSubmitQueuesWhenSlotsAreBusyAndRejectsWhenFull(t *testing.T) {
+	c := newTestController(t, Config{Rate: -1, Concurrency: 1, QueueSize: 1, QueueWait: -1})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.Submit(context.Background(), "", func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return 1.0, nil
+		})
+	}()
+	<-started
+
+	queued, err := c.Submit(context.Background(), "", quote(2))
+	if err != nil || queued.Status != StatusQueued {
+		t.Fatalf("Expected a queued job while the slot is busy, got %+v (%v)", queued, err)
+	}
+
+	_, err = c.Submit(context.Background(), "", quote(3))
+	var rejected *Rejection
+	if !errors.As(err, &rejected) || rejected.Reason != "queue" {
+		t.Fatalf("Expected a full queue rejection, got %v", err)
+	}
+
+	var metrics bytes.Buffer
+	c.WritePrometheus(&metrics)
+	for _, want := range []string{
+		`pricing_quote_admissions_total{result="run"} 1`,
+		`pricing_quote_admissions_total{result="queued"} 1`,
+		`pricing_quote_admissions_total{result="queue_full"} 1`,
+		"pricing_quote_queue_depth 1",
+		"pricing_quote_in_flight 1",
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("Metrics missing %q:\n%s", want, metrics.String())
+		}
+	}
+
+	close(release)
+	wg.Wait()
+}
+
+func TestSweepForgetsExpiredJobs(t *testing.T) {
+	c := newTestController(t, Config{Rate: 20, Burst: 1, QueueWait: time.Second, JobTTL: time.Minute})
+
+	c.Submit(context.Background(), "aggregator", quote(1))
+	job, _ := c.Submit(context.Background(), "aggregator", quote(2))
+	if job.Status != StatusDone {
+		t.Fatalf("Expected a finished job, got %+v", job)
+	}
+
+	c.sweep(time.Now())
+	if _, ok := c.Job(job.ID); !ok {
+		t.Error("Expected a recent job to be kept")
+	}
+	c.sweep(time.Now().Add(2 * time.Minute))
+	if _, ok := c.Job(job.ID); ok {
+		t.Error("Expected an expired job to be forgotten")
+	}
+	if len(c.buckets) != 0 {
+		t.Errorf("Expected the idle key's bucket to be forgotten, got %d", len(c.buckets))
+	}
+}
+
+func TestLimitReportsRemainingWithoutReserving(t *testing.T) {
+	c := newTestController(t, Config{Rate: 10, Burst: 3, QueueWait: -1})
+
+	if limit, ok := c.Limit("aggregator"); !ok || limit.Limit != 3 || limit.Remaining != 3 || limit.Reset != 0 {
+		t.Fatalf("Expected an unused key to have its full burst, got %+v (%v)", limit, ok)
+	}
+
+	c.Submit(context.Background(), "aggregator", quote(1))
+	c.Submit(context.Background(), "aggregator", quote(2))
+	limit, _ := c.Limit("aggregator")
+	if limit.Remaining != 1 || limit.Rate != 10 || limit.Reset <= 100*time.Millisecond || limit.Reset > 200*time.Millisecond {
+		t.Errorf("Expected one request left and the bucket full within 200ms, got %+v", limit)
+	}
+	if again, _ := c.Limit("aggregator"); again.Remaining != 1 {
+		t.Errorf("Expected Limit not to reserve a token, got %+v", again)
+	}
+
+	// Queued requests leave nothing remaining
+	c.Submit(context.Background(), "aggregator", quote(3))
+	c.Submit(context.Background(), "aggregator", quote(4))
+	if limit, _ := c.Limit("aggregator"); limit.Remaining != 0 {
+		t.Errorf("Expected nothing remaining, got %+v", limit)
+	}
+
+	if _, ok := c.Limit(""); ok {
+		t.Error("Expected callers without an API key not to be limited")
+	}
+	if _, ok := newTestController(t, Config{Rate: -1}).Limit("aggregator"); ok {
+		t.Error("Expected no limit with per-key limits off")
+	}
+}
@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+)
+
+// These tests are the consumer half of the contracts in pkg/contracts. The
+// provider halves run in customer-service and policy-service.
+
+func TestCustomerClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("pricing-engine", "customer-service"))
+	client := NewCustomerClient(mock.URL, 5*time.Second)
+	ctx := context.Background()
+
+	consented, err := client.PaperlessConsent(ctx, "cust-001")
+	if err != nil || !consented {
+		t.Errorf("cust-001: got %v, %v", consented, err)
+	}
+
+	consented, err = client.PaperlessConsent(ctx, "cust-002")
+	if err != nil || consented {
+		t.Errorf("cust-002: got %v, %v", consented, err)
+	}
+
+	if _, err := client.PaperlessConsent(ctx, "cust-404"); err == nil || err.Error() != "customer not found" {
+		t.Errorf("Unknown customer: got %v", err)
+	}
+}
+
+func TestPolicyClientContract(t *testing.T) {
+	mock := contracts.NewMockProvider(t, contracts.MustLoad("pricing-engine", "policy-service"))
+	client := NewPolicyClient(mock.URL, 5*time.Second)
+	ctx := context.Background()
+
+	held, err := client.HouseholdHasActivePolicy(ctx, "cust-001")
+	if err != nil || !held {
+		t.Errorf("cust-001: got %v, %v", held, err)
+	}
+
+	held, err = client.HouseholdHasActivePolicy(ctx, "cust-404")
+	if err != nil || held {
+		t.Errorf("cust-404: got %v, %v", held, err)
+	}
+}
@@ -0,0 +1,76 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Consent is a consent record from customer-service
+type Consent struct {
+	Granted    bool       `json:"granted"`
+	RecordedAt *time.Time `json:"recordedAt,omitempty"`
+	Source     string     `json:"source,omitempty"`
+}
+
+// Preferences is the subset of a customer's preferences the pricing engine
+// reads
+type Preferences struct {
+	PaperlessBilling Consent `json:"paperlessBilling"`
+}
+
+// CustomerClient calls customer-service
+type CustomerClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewCustomerClient creates a new customer-service client
+func NewCustomerClient(baseURL string, timeout time.Duration) *CustomerClient {
+	return &CustomerClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetPreferences fetches a customer's communication preferences
+func (c *CustomerClient) GetPreferences(ctx context.Context, customerID string) (*Preferences, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/customers/"+url.PathEscape(customerID)+"/preferences", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("customer-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("customer not found")
+	default:
+		return nil, fmt.Errorf("customer-service returned status %d", resp.StatusCode)
+	}
+
+	var preferences Preferences
+	if err := json.NewDecoder(resp.Body).Decode(&preferences); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences: %w", err)
+	}
+	return &preferences, nil
+}
+
+// PaperlessConsent reports whether the customer has consent to paperless
+// billing on record
+func (c *CustomerClient) PaperlessConsent(ctx context.Context, customerID string) (bool, error) {
+	preferences, err := c.GetPreferences(ctx, customerID)
+	if err != nil {
+		return false, err
+	}
+	return preferences.PaperlessBilling.Granted, nil
+}
@@ -0,0 +1,72 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Policy is the subset of a policy-service policy the pricing engine reads
+type Policy struct {
+	ID         string `json:"id"`
+	CustomerID string `json:"customerId"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+}
+
+// PolicyClient calls policy-service
+type PolicyClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPolicyClient creates a new policy-service client
+func NewPolicyClient(baseURL string, timeout time.Duration) *PolicyClient {
+	return &PolicyClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetHouseholdPolicies fetches the policies held by everyone in the
+// customer's household, the customer included
+func (c *PolicyClient) GetHouseholdPolicies(ctx context.Context, customerID string) ([]Policy, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/policies?scope=household", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", customerID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("policy-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy-service returned status %d", resp.StatusCode)
+	}
+
+	var policies []Policy
+	if err := json.NewDecoder(resp.Body).Decode(&policies); err != nil {
+		return nil, fmt.Errorf("failed to decode policies: %w", err)
+	}
+	return policies, nil
+}
+
+// HouseholdHasActivePolicy reports whether anyone in the customer's
+// household holds an active policy
+func (c *PolicyClient) HouseholdHasActivePolicy(ctx context.Context, customerID string) (bool, error) {
+	policies, err := c.GetHouseholdPolicies(ctx, customerID)
+	if err != nil {
+		return false, err
+	}
+	for _, policy := range policies {
+		if policy.Status == "active" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,99 @@
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+)
+
+// KeyRateExperiment is the flag key, also used with the experiment ID to salt
+// assignment buckets
+const KeyRateExperiment = "pricing.rateExperiment"
+
+// variantControl mirrors models.VariantControl
+const variantControl = "control"
+
+// Experiment splits customers between the current rules and one or more
+// candidate rules versions, for example
+//
+//	{"id": "rates-2025", "variants": [{"name": "candidate", "rulesVersion": "2.0.0", "percentage": 20}]}
+//
+// quotes a stable 20% of customers from rules version 2.0.0. Everyone else is
+// in the control variant.
+type Experiment struct {
+	ID       string              `json:"id"`
+	Variants []ExperimentVariant `json:"variants"`
+}
+
+// ExperimentVariant is one treatment of an experiment
+type ExperimentVariant struct {
+	Name         string  `json:"name"`
+	RulesVersion string  `json:"rulesVersion"`
+	Percentage   float64 `json:"percentage"` // share of customers, 0-100
+}
+
+// ParseExperiment decodes and validates an experiment from JSON
+func ParseExperiment(data []byte) (*Experiment, error) {
+	var experiment Experiment
+	if err := json.Unmarshal(data, &experiment); err != nil {
+		return nil, fmt.Errorf("invalid experiment: %w", err)
+	}
+	if err := experiment.Validate(); err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// Validate checks the experiment has an ID and its variants are distinct and
+// share at most 100% of customers
+func (e *Experiment) Validate() error {
+	if e.ID == "" {
+		return fmt.Errorf("experiment id is required")
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment %s has no variants", e.ID)
+	}
+
+	total := 0.0
+	seen := make(map[string]bool, len(e.Variants))
+	for _, variant := range e.Variants {
+		if variant.Name == "" || variant.Name == variantControl {
+			return fmt.Errorf("variant name is required and cannot be %s", variantControl)
+		}
+		if seen[variant.Name] {
+			return fmt.Errorf("duplicate variant %s", variant.Name)
+		}
+		seen[variant.Name] = true
+		if variant.RulesVersion == "" {
+			return fmt.Errorf("variant %s needs a rules version", variant.Name)
+		}
+		if variant.Percentage <= 0 {
+			return fmt.Errorf("variant %s percentage must be greater than 0", variant.Name)
+		}
+		total += variant.Percentage
+	}
+	if total > 100 {
+		return fmt.Errorf("variant percentages add up to more than 100")
+	}
+
+	return nil
+}
+
+// Assign returns the variant a customer is in, or nil for control. The same
+// customer always gets the same variant for an experiment.
+func (e *Experiment) Assign(customerID string) *ExperimentVariant {
+	if e == nil || customerID == "" {
+		return nil
+	}
+
+	bucket := targeting.Bucket(KeyRateExperiment+"/"+e.ID, customerID)
+	threshold := 0.0
+	for i := range e.Variants {
+		threshold += e.Variants[i].Percentage
+		if bucket < threshold {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
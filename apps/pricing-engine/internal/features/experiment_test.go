@@ -0,0 +1,24 @@
+package features
+
+import "testing"
+
+func TestParseExperimentValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"no id", `{"variants": [{"name": "b", "rulesVersion": "2", "percentage": 10}]}`},
+		{"no variants", `{"id": "x"}`},
+		{"control name", `{"id": "x", "variants": [{"name": "control", "rulesVersion": "2", "percentage": 10}]}`},
+		{"no rules version", `{"id": "x", "variants": [{"name": "b", "percentage": 10}]}`},
+		{"over 100 percent", `{"id": "x", "variants": [{"name": "b", "rulesVersion": "2", "percentage": 60}, {"name": "c", "rulesVersion": "3", "percentage": 50}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseExperiment([]byte(tt.json)); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
@@ -5,14 +5,19 @@ import (
 	"strconv"
 	"sync"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
 	"github.com/sirupsen/logrus"
 )
 
 // Flags holds all feature flags for the application
 type Flags struct {
-	dynamicRates bool
-	mu           sync.RWMutex
-	logger       *logrus.Logger
+	dynamicRates   bool
+	rateExperiment *Experiment // when set, assigns customers to rules versions
+	mu             sync.RWMutex
+	logger         *logrus.Logger
+
+	impressions     *targeting.Recorder
+	stopImpressions func()
 }
 
 var flags *Flags
@@ -37,8 +42,22 @@ func Initialize(apiKey string, logger *logrus.Logger) (*Flags, error) {
 		}
 	}
 
+	// FEATURE_RATE_EXPERIMENT (optional) - JSON experiment, e.g.
+	// {"id": "rates-2025", "variants": [{"name": "candidate", "rulesVersion": "2.0.0", "percentage": 20}]}
+	if experimentStr := os.Getenv("FEATURE_RATE_EXPERIMENT"); experimentStr != "" {
+		experiment, err := ParseExperiment([]byte(experimentStr))
+		if err != nil {
+			logger.WithError(err).Warn("Invalid FEATURE_RATE_EXPERIMENT, ignoring")
+		} else {
+			flags.rateExperiment = experiment
+		}
+	}
+
+	flags.impressions, flags.stopImpressions = startImpressions(logger)
+
 	logger.WithFields(logrus.Fields{
-		"dynamicRates": flags.dynamicRates,
+		"dynamicRates":   flags.dynamicRates,
+		"rateExperiment": flags.rateExperiment != nil,
 	}).Info("Feature flags initialized")
 
 	if apiKey != "" && apiKey != "dev-mode" {
@@ -74,9 +93,76 @@ func (f *Flags) SetDynamicRates(enabled bool) {
 	f.logger.WithField("dynamicRates", enabled).Info("Feature flag updated")
 }
 
+// RateExperiment returns the running pricing experiment, or nil
+func (f *Flags) RateExperiment() *Experiment {
+	if f == nil {
+		return nil
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.rateExperiment
+}
+
+// AssignRateExperiment returns the running experiment and the variant the
+// customer is in, nil for control, and records the impression. The
+// experiment is nil when none is running.
+func (f *Flags) AssignRateExperiment(customerID string) (*Experiment, *ExperimentVariant) {
+	experiment := f.RateExperiment()
+	if experiment == nil || customerID == "" {
+		return nil, nil
+	}
+
+	variant := experiment.Assign(customerID)
+	name := variantControl
+	if variant != nil {
+		name = variant.Name
+	}
+	f.impressions.Record(targeting.Impression{
+		Flag:    KeyRateExperiment,
+		Variant: name,
+		UserID:  customerID,
+	})
+	return experiment, variant
+}
+
+// SetRateExperiment starts a pricing experiment, or stops it when nil (for
+// testing/admin purposes)
+func (f *Flags) SetRateExperiment(experiment *Experiment) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rateExperiment = experiment
+	if experiment == nil {
+		f.logger.Info("Rate experiment stopped")
+		return
+	}
+	f.logger.WithFields(logrus.Fields{
+		"experimentId": experiment.ID,
+		"variants":     len(experiment.Variants),
+	}).Info("Rate experiment updated")
+}
+
+// EnabledFlags returns the keys of the flags that are on, for the health
+// check. The rate experiment counts as on while one is running.
+func (f *Flags) EnabledFlags() []string {
+	var enabled []string
+	if f.IsDynamicRatesEnabled() {
+		enabled = append(enabled, "pricing.dynamicRates")
+	}
+	if f.RateExperiment() != nil {
+		enabled = append(enabled, KeyRateExperiment)
+	}
+	return enabled
+}
+
 // Shutdown gracefully shuts down the feature management system
 func Shutdown() {
 	if flags != nil {
+		if flags.stopImpressions != nil {
+			flags.stopImpressions()
+		}
 		flags.logger.Info("Feature management shutdown complete")
 	}
 }
@@ -145,9 +231,11 @@ To integrate with CloudBees Feature Management (Rox SDK), follow these steps:
 
 Feature Flags:
 - pricing.dynamicRates (default: false) - enable real-time rate adjustments
+- pricing.rateExperiment (default: none) - quote a share of customers from candidate rules versions
 
 Environment Variables (Current Implementation):
 - FEATURE_DYNAMIC_RATES: Set to "true" to enable dynamic pricing
+- FEATURE_RATE_EXPERIMENT: JSON experiment assigning customers to rules versions
 
 For more information, see: https://docs.cloudbees.com/docs/cloudbees-feature-management/latest/
 */
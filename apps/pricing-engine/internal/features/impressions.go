@@ -0,0 +1,63 @@
+package features
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultImpressionBuffer is how many flag impressions are kept in memory
+	DefaultImpressionBuffer = 10000
+
+	// DefaultImpressionFlushInterval is how often impressions go to the sink
+	DefaultImpressionFlushInterval = time.Minute
+)
+
+// Impressions returns the recorder of flag evaluations
+func (f *Flags) Impressions() *targeting.Recorder {
+	if f == nil {
+		return nil
+	}
+	return f.impressions
+}
+
+// startImpressions configures impression recording from the environment:
+// FLAG_IMPRESSIONS_BUFFER, FLAG_IMPRESSIONS_FLUSH_INTERVAL and
+// FLAG_IMPRESSIONS_SINK ("log", the default, or "none")
+func startImpressions(logger *logrus.Logger) (*targeting.Recorder, func()) {
+	capacity := DefaultImpressionBuffer
+	if v := os.Getenv("FLAG_IMPRESSIONS_BUFFER"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			capacity = n
+		} else {
+			logger.Warnf("Invalid FLAG_IMPRESSIONS_BUFFER '%s', defaulting to %d", v, capacity)
+		}
+	}
+
+	interval := DefaultImpressionFlushInterval
+	if v := os.Getenv("FLAG_IMPRESSIONS_FLUSH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			interval = d
+		} else {
+			logger.Warnf("Invalid FLAG_IMPRESSIONS_FLUSH_INTERVAL '%s', defaulting to %s", v, interval)
+		}
+	}
+
+	var sink targeting.Sink
+	switch v := os.Getenv("FLAG_IMPRESSIONS_SINK"); v {
+	case "", "log":
+		sink = &targeting.LogSink{Logger: logger}
+	case "none":
+	default:
+		logger.Warnf("Unknown FLAG_IMPRESSIONS_SINK '%s', defaulting to log", v)
+		sink = &targeting.LogSink{Logger: logger}
+	}
+
+	return targeting.Start(capacity, interval, sink, func(err error) {
+		logger.WithError(err).Warn("Failed to flush flag impressions")
+	})
+}
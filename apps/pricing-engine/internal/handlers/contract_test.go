@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts"
+	"github.com/sirupsen/logrus"
+)
+
+// TestPolicyServiceContract verifies pricing-engine still accepts the quote
+// conversions policy-service sends when a quote is bound. The consumer half
+// lives in policy-service.
+func TestPolicyServiceContract(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	dataPath := filepath.Join("..", "..", "..", "..", "data", "seed")
+	application, err := app.New(app.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to assemble service: %v", err)
+	}
+	defer application.Close()
+
+	// Provider states are checked against the same seed data the service loaded
+	quotes, err := repository.NewQuoteRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("Failed to load seed data: %v", err)
+	}
+
+	contracts.VerifyProvider(t, application.Handler, contracts.MustLoad("policy-service", "pricing-engine"), contracts.StateHandlers{
+		"quote Q-1c84e5d0 for cust-001 is not converted": func() error {
+			quote, err := quotes.GetQuote("Q-1c84e5d0")
+			if err != nil {
+				return err
+			}
+			if quote.CustomerID != "cust-001" || quote.Converted {
+				return fmt.Errorf("Q-1c84e5d0 is %+v", quote)
+			}
+			return nil
+		},
+		"quote Q-00000000 does not exist": func() error {
+			if _, err := quotes.GetQuote("Q-00000000"); err == nil {
+				return fmt.Errorf("Q-00000000 exists in seed data")
+			}
+			return nil
+		},
+	})
+}
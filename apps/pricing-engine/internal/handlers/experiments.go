@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/services"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ExperimentService is the business logic the experiment handler depends on.
+// *services.ExperimentService is the production implementation.
+type ExperimentService interface {
+	GetResults(experimentID string) (*models.ExperimentResults, error)
+}
+
+var _ ExperimentService = (*services.ExperimentService)(nil)
+
+// ExperimentHandler handles pricing experiment requests
+type ExperimentHandler struct {
+	service ExperimentService
+	logger  *logrus.Logger
+}
+
+// NewExperimentHandler creates a new experiment handler
+func NewExperimentHandler(service ExperimentService, logger *logrus.Logger) *ExperimentHandler {
+	return &ExperimentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// GetResults handles GET /experiments/{id}/results
+func (h *ExperimentHandler) GetResults(w http.ResponseWriter, r *http.Request) {
+	experimentID := mux.Vars(r)["id"]
+
+	results, err := h.service.GetResults(experimentID)
+	if err != nil {
+		h.logger.WithError(err).WithField("experimentId", experimentID).Warn("Failed to get experiment results")
+		respondWithError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, results)
+}
@@ -1,39 +0,0 @@
-package handlers
-
-import (
-	"encoding/json"
-	"net/http"
-	"time"
-)
-
-// HealthHandler handles health check requests
-type HealthHandler struct {
-	serviceName string
-}
-
-// NewHealthHandler creates a new health handler
-func NewHealthHandler(serviceName string) *HealthHandler {
-	return &HealthHandler{
-		serviceName: serviceName,
-	}
-}
-
-// HealthResponse represents the health check response
-type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Service   string    `json:"service"`
-}
-
-// ServeHTTP handles GET /healthz
-func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	response := HealthResponse{
-		Status:    "ok",
-		Timestamp: time.Now(),
-		Service:   h.serviceName,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
-}
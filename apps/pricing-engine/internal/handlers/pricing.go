@@ -1,25 +1,49 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/admission"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
-// PricingHandler handles pricing-related requests
+// PricingService is the business logic the pricing handler depends on.
+// *services.PricingService is the production implementation.
+type PricingService interface {
+	CalculateQuote(ctx context.Context, req *models.QuoteRequest) (*models.Quote, error)
+	CompareQuotes(ctx context.Context, req *models.QuoteComparisonRequest) (*models.QuoteComparison, error)
+	CheckRequote(quoteID string) error
+	Requote(ctx context.Context, quoteID string) (*models.Requote, error)
+	GetRates() *models.RatesResponse
+}
+
+var _ PricingService = (*services.PricingService)(nil)
+
+// PricingHandler handles pricing-related requests. Quotes and comparisons
+// are admitted through admission, which meters callers by their X-API-Key.
 type PricingHandler struct {
-	service *services.PricingService
-	logger  *logrus.Logger
+	service   PricingService
+	admission *admission.Controller
+	logger    *logrus.Logger
 }
 
-// NewPricingHandler creates a new pricing handler
-func NewPricingHandler(service *services.PricingService, logger *logrus.Logger) *PricingHandler {
+// NewPricingHandler creates a new pricing handler. admission may be nil to
+// calculate every quote straight away.
+func NewPricingHandler(service PricingService, admission *admission.Controller, logger *logrus.Logger) *PricingHandler {
 	return &PricingHandler{
-		service: service,
-		logger:  logger,
+		service:   service,
+		admission: admission,
+		logger:    logger,
 	}
 }
 
@@ -34,15 +58,119 @@ func (h *PricingHandler) GetQuote(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Calculate quote
-	quote, err := h.service.CalculateQuote(&req)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to calculate quote")
-		respondWithError(w, http.StatusBadRequest, err.Error())
+	job, err := h.admission.Submit(r.Context(), r.Header.Get("X-API-Key"), func(ctx context.Context) (interface{}, error) {
+		return declinedResult(h.service.CalculateQuote(ctx, &req))
+	})
+	h.respondAdmitted(w, r, job, err, "Failed to calculate quote")
+}
+
+// CompareQuotes handles POST /quote/compare
+func (h *PricingHandler) CompareQuotes(w http.ResponseWriter, r *http.Request) {
+	// Parse request body
+	var req models.QuoteComparisonRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.WithError(err).Warn("Invalid request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Calculate quotes
+	job, err := h.admission.Submit(r.Context(), r.Header.Get("X-API-Key"), func(ctx context.Context) (interface{}, error) {
+		return declinedResult(h.service.CompareQuotes(ctx, &req))
+	})
+	h.respondAdmitted(w, r, job, err, "Failed to compare quotes")
+}
+
+// Requote handles POST /quotes/{id}/requote, pricing an expired quote again
+// against the current rules. Quotes that cannot be requoted are refused
+// before the requote is admitted.
+func (h *PricingHandler) Requote(w http.ResponseWriter, r *http.Request) {
+	quoteID := mux.Vars(r)["id"]
+
+	if err := h.service.CheckRequote(quoteID); err != nil {
+		h.logger.WithError(err).WithField("quoteId", quoteID).Warn("Quote cannot be requoted")
+		switch err.Error() {
+		case "quote not found":
+			respondWithError(w, http.StatusNotFound, err.Error())
+		case "quote already converted", "quote has not expired", "quote was recorded without its request":
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Failed to requote")
+		}
 		return
 	}
 
-	// Return quote
-	respondWithJSON(w, http.StatusOK, quote)
+	job, err := h.admission.Submit(r.Context(), r.Header.Get("X-API-Key"), func(ctx context.Context) (interface{}, error) {
+		return declinedResult(h.service.Requote(ctx, quoteID))
+	})
+	h.respondAdmitted(w, r, job, err, "Failed to requote")
+}
+
+// declinedResult answers a request knocked out by the pricing rules with
+// its declined quote, as a result rather than an error
+func declinedResult(result interface{}, err error) (interface{}, error) {
+	var declined *services.DeclinedError
+	if errors.As(err, &declined) {
+		return declined.Quote, nil
+	}
+	return result, err
+}
+
+// GetQuoteJob handles GET /quote/jobs/{id}, polled by callers whose quote
+// or comparison was queued
+func (h *PricingHandler) GetQuoteJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.admission.Job(mux.Vars(r)["id"])
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Quote job not found")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, shaping.Apply(r, job))
+}
+
+// respondAdmitted answers a quote request as admission left it: the result
+// when it finished, 202 with the job to poll when it is still queued, or
+// 429 when it could not be queued
+func (h *PricingHandler) respondAdmitted(w http.ResponseWriter, r *http.Request, job admission.Job, err error, failure string) {
+	var rejected *admission.Rejection
+	if errors.As(err, &rejected) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rejected.RetryAfter.Seconds()))))
+		respondWithError(w, http.StatusTooManyRequests, rejected.Error())
+		return
+	}
+
+	switch job.Status {
+	case admission.StatusDone:
+		respondWithJSON(w, http.StatusOK, shaping.Apply(r, job.Result))
+	case admission.StatusFailed:
+		h.logger.WithField("error", job.Error).Error(failure)
+		respondWithError(w, http.StatusBadRequest, job.Error)
+	default:
+		w.Header().Set("Location", "/quote/jobs/"+job.ID)
+		respondWithJSON(w, http.StatusAccepted, shaping.Apply(r, job))
+	}
+}
+
+// quoteEndpoints are the endpoints that draw from an API key's quote bucket
+var quoteEndpoints = []string{"POST /quote", "POST /quote/compare", "POST /quotes/{id}/requote"}
+
+// GetLimits handles GET /limits, reporting the rate limits of the caller
+// identified by X-API-Key
+func (h *PricingHandler) GetLimits(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	response := models.LimitsResponse{Limits: []models.RateLimit{}, Timestamp: now}
+	if limit, ok := h.admission.Limit(r.Header.Get("X-API-Key")); ok {
+		reset := int(math.Ceil(limit.Reset.Seconds()))
+		response.Limits = append(response.Limits, models.RateLimit{
+			Bucket:        "quotes",
+			Endpoints:     quoteEndpoints,
+			Limit:         limit.Limit,
+			Remaining:     limit.Remaining,
+			RatePerSecond: limit.Rate,
+			ResetSeconds:  reset,
+			ResetAt:       now.Add(time.Duration(reset) * time.Second),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, response)
 }
 
 // GetRates handles GET /rates
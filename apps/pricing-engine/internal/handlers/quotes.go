@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// QuoteHistoryService is the business logic the quote history handler
+// depends on. *services.QuoteHistoryService is the production
+// implementation.
+type QuoteHistoryService interface {
+	ConvertQuote(quoteID, policyID string) (*models.QuoteRecord, error)
+	GetCustomerQuotes(customerID string) *models.CustomerQuoteHistory
+}
+
+var _ QuoteHistoryService = (*services.QuoteHistoryService)(nil)
+
+// QuoteHistoryHandler handles quote history and conversion requests
+type QuoteHistoryHandler struct {
+	service QuoteHistoryService
+	logger  *logrus.Logger
+}
+
+// NewQuoteHistoryHandler creates a new quote history handler
+func NewQuoteHistoryHandler(service QuoteHistoryService, logger *logrus.Logger) *QuoteHistoryHandler {
+	return &QuoteHistoryHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ConvertQuote handles POST /quote/{id}/convert, called by the bind flow
+// when a quote becomes a policy. The body, naming the policy, is optional.
+func (h *QuoteHistoryHandler) ConvertQuote(w http.ResponseWriter, r *http.Request) {
+	quoteID := mux.Vars(r)["id"]
+
+	var req models.ConvertQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.WithError(err).Warn("Invalid request body")
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	quote, err := h.service.ConvertQuote(quoteID, req.PolicyID)
+	if err != nil {
+		h.logger.WithError(err).WithField("quoteId", quoteID).Warn("Failed to convert quote")
+		switch err.Error() {
+		case "quote not found":
+			respondWithError(w, http.StatusNotFound, err.Error())
+		case "quote already converted":
+			respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Failed to convert quote")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, shaping.Apply(r, quote))
+}
+
+// GetCustomerQuotes handles GET /customers/{id}/quotes
+func (h *QuoteHistoryHandler) GetCustomerQuotes(w http.ResponseWriter, r *http.Request) {
+	customerID := mux.Vars(r)["id"]
+
+	respondWithJSON(w, http.StatusOK, shaping.Apply(r, h.service.GetCustomerQuotes(customerID)))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
 	"github.com/sirupsen/logrus"
 )
 
@@ -24,6 +25,7 @@ func AuthMiddleware(logger *logrus.Logger) func(http.Handler) http.Handler {
 
 			// Extract user ID from X-User-ID header (demo purposes)
 			userID := r.Header.Get("X-User-ID")
+			telemetry.SetIdentity(r.Context(), userID, "")
 			if userID == "" {
 				userID = "user-001" // Default for demo
 			}
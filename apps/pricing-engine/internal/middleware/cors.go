@@ -19,11 +19,17 @@ func NewCORS() *cors.Cors {
 			"Accept",
 			"Authorization",
 			"Content-Type",
+			"X-API-Key",
 			"X-CSRF-Token",
 			"X-User-ID",
 		},
 		ExposedHeaders: []string{
 			"Link",
+			"Location",
+			"Retry-After",
+			"X-RateLimit-Limit",
+			"X-RateLimit-Remaining",
+			"X-RateLimit-Reset",
 		},
 		AllowCredentials: true,
 		MaxAge:           300, // 5 minutes
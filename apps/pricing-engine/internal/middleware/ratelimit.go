@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/admission"
+)
+
+// Rate limit headers set on metered responses
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimits reports where an API key stands against its rate.
+// *admission.Controller is the production implementation.
+type RateLimits interface {
+	Limit(key string) (admission.Limit, bool)
+}
+
+// RateLimitHeaders tells callers identified by X-API-Key where they stand
+// against their rate: X-RateLimit-Limit is the burst, X-RateLimit-Remaining
+// the requests left before they are delayed, and X-RateLimit-Reset the
+// seconds until the burst is available again. The headers are set when the
+// response is written, so they count the request being answered. Callers
+// that are not rate limited get none.
+func RateLimitHeaders(limits RateLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("X-API-Key")
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&rateLimitResponse{ResponseWriter: w, limits: limits, key: key}, r)
+		})
+	}
+}
+
+// rateLimitResponse sets the rate limit headers before the status is written
+type rateLimitResponse struct {
+	http.ResponseWriter
+	limits  RateLimits
+	key     string
+	written bool
+}
+
+func (rw *rateLimitResponse) WriteHeader(code int) {
+	if !rw.written {
+		rw.written = true
+		if limit, ok := rw.limits.Limit(rw.key); ok {
+			h := rw.Header()
+			h.Set(HeaderRateLimitLimit, strconv.Itoa(limit.Limit))
+			h.Set(HeaderRateLimitRemaining, strconv.Itoa(limit.Remaining))
+			h.Set(HeaderRateLimitReset, strconv.Itoa(int(math.Ceil(limit.Reset.Seconds()))))
+		}
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *rateLimitResponse) Write(b []byte) (int, error) {
+	if !rw.written {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/admission"
+)
+
+type fixedLimits map[string]admission.Limit
+
+func (f fixedLimits) Limit(key string) (admission.Limit, bool) {
+	limit, ok := f[key]
+	return limit, ok
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	limits := fixedLimits{"aggregator": {Limit: 40, Remaining: 12, Rate: 20, Reset: 1400 * time.Millisecond}}
+	handler := RateLimitHeaders(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+
+	tests := []struct {
+		name   string
+		apiKey string
+		want   map[string]string
+	}{
+		{"limited key", "aggregator", map[string]string{HeaderRateLimitLimit: "40", HeaderRateLimitRemaining: "12", HeaderRateLimitReset: "2"}},
+		{"unlimited key", "partner", map[string]string{HeaderRateLimitLimit: "", HeaderRateLimitRemaining: "", HeaderRateLimitReset: ""}},
+		{"no key", "", map[string]string{HeaderRateLimitLimit: ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/quote", nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			for header, want := range tt.want {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("%s = %q, want %q", header, got, want)
+				}
+			}
+		})
+	}
+}
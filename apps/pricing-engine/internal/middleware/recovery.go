@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/sirupsen/logrus"
+)
+
+// RecoveryOptions configures RecoveryMiddleware
+type RecoveryOptions struct {
+	// Panics counts recovered panics by route; nil counts nothing
+	Panics *telemetry.PanicCounter
+	// Reporter receives every recovered panic, such as a Sentry hub; nil
+	// only logs them
+	Reporter telemetry.ErrorReporter
+}
+
+// RecoveryMiddleware answers a request whose handler panicked with a 500
+// carrying the request ID, instead of the server dropping the connection.
+// The panic is logged with its stack, counted and passed to the reporter.
+// It must be installed with Router.Use right after LoggingMiddleware, so
+// the request is still logged, with its 500.
+func RecoveryMiddleware(logger *logrus.Logger, opts RecoveryOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				if telemetry.IsAbort(value) {
+					panic(value)
+				}
+
+				err := telemetry.Recovered(value)
+				requestID := w.Header().Get(telemetry.HeaderRequestID)
+				route := routeTemplate(r)
+				logger.WithError(err).WithFields(logrus.Fields{
+					"method":     r.Method,
+					"path":       r.URL.Path,
+					"route":      route,
+					"request_id": requestID,
+					"stack":      string(err.Stack),
+				}).Error("Recovered from handler panic")
+
+				if opts.Panics != nil {
+					opts.Panics.Inc(r.Method, route)
+				}
+				if opts.Reporter != nil {
+					opts.Reporter.CaptureException(r.Context(), err, map[string]string{
+						"request_id": requestID,
+						"method":     r.Method,
+						"route":      route,
+					})
+				}
+
+				// A response already under way cannot be replaced
+				if rw, ok := w.(*responseWriter); ok && rw.written {
+					return
+				}
+				respondPanic(w, requestID)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// respondPanic writes the 500 for a recovered panic in the handlers'
+// {"error": ...} shape, with the request ID to quote when reporting it
+func respondPanic(w http.ResponseWriter, requestID string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]string{
+		"error":     "Internal server error",
+		"requestId": requestID,
+	})
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// VariantControl is the experiment variant quoted from the current rules
+const VariantControl = "control"
+
+// ExperimentAssignment records which variant of a pricing experiment a quote
+// was priced under
+type ExperimentAssignment struct {
+	ExperimentID string `json:"experimentId"`
+	Variant      string `json:"variant"`
+	RulesVersion string `json:"rulesVersion"`
+}
+
+// ExperimentResults compares the variants of a pricing experiment
+type ExperimentResults struct {
+	ExperimentID string            `json:"experimentId"`
+	Active       bool              `json:"active"` // whether new quotes are still being assigned
+	Variants     []*VariantResults `json:"variants"`
+	GeneratedAt  time.Time         `json:"generatedAt"`
+}
+
+// VariantResults summarizes the quotes priced under one variant
+type VariantResults struct {
+	Variant        string              `json:"variant"`
+	RulesVersion   string              `json:"rulesVersion"`
+	Quotes         int                 `json:"quotes"`
+	Conversions    int                 `json:"conversions"`
+	ConversionRate float64             `json:"conversionRate"` // conversions / quotes
+	Premium        PremiumDistribution `json:"premium"`
+}
+
+// PremiumDistribution describes the final premiums of a set of quotes
+type PremiumDistribution struct {
+	Mean   float64 `json:"mean"`
+	Min    float64 `json:"min"`
+	Median float64 `json:"median"`
+	P90    float64 `json:"p90"`
+	Max    float64 `json:"max"`
+}
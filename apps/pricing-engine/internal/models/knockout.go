@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// QuoteStatusDeclined is the status of a quote request knocked out by the
+// pricing rules
+const QuoteStatusDeclined = "declined"
+
+// KnockoutRule declines quote requests for a risk that is not written at
+// all, such as an age and risk score combination or an excluded territory,
+// before they are priced. A request is knocked out when it matches every
+// condition the rule sets; unset conditions match anything. States and
+// ZipPrefixes together are one territory condition, matched by either.
+type KnockoutRule struct {
+	ID          string   `json:"id"`
+	Reason      string   `json:"reason"`                // shown to the caller
+	PolicyTypes []string `json:"policyTypes,omitempty"` // empty matches every policy type
+
+	MinAge       int      `json:"minAge,omitempty"`
+	MaxAge       int      `json:"maxAge,omitempty"`
+	MinRiskScore int      `json:"minRiskScore,omitempty"`
+	MaxRiskScore int      `json:"maxRiskScore,omitempty"`
+	MinCoverage  int      `json:"minCoverage,omitempty"`
+	MaxCoverage  int      `json:"maxCoverage,omitempty"`
+	States       []string `json:"states,omitempty"`
+	ZipPrefixes  []string `json:"zipPrefixes,omitempty"` // first three digits of the ZIP code
+}
+
+// Validate validates a KnockoutRule. A rule must set at least one
+// condition besides its policy types, so a typo cannot stop a whole line of
+// business being quoted.
+func (k *KnockoutRule) Validate() error {
+	if k.ID == "" {
+		return fmt.Errorf("knockout rule has no id")
+	}
+	if k.Reason == "" {
+		return fmt.Errorf("knockout rule %s has no reason", k.ID)
+	}
+	if k.MinAge == 0 && k.MaxAge == 0 && k.MinRiskScore == 0 && k.MaxRiskScore == 0 &&
+		k.MinCoverage == 0 && k.MaxCoverage == 0 && len(k.States) == 0 && len(k.ZipPrefixes) == 0 {
+		return fmt.Errorf("knockout rule %s sets no conditions", k.ID)
+	}
+	return nil
+}
+
+// Matches reports whether the rule knocks out req at coverageAmount. req's
+// state and ZIP code must already be normalized.
+func (k *KnockoutRule) Matches(req *QuoteRequest, coverageAmount int) bool {
+	if len(k.PolicyTypes) > 0 && !contains(k.PolicyTypes, req.PolicyType) {
+		return false
+	}
+	if !within(req.CustomerAge, k.MinAge, k.MaxAge) || !within(req.RiskScore, k.MinRiskScore, k.MaxRiskScore) ||
+		!within(coverageAmount, k.MinCoverage, k.MaxCoverage) {
+		return false
+	}
+	if len(k.States) == 0 && len(k.ZipPrefixes) == 0 {
+		return true
+	}
+	if contains(k.States, req.State) && req.State != "" {
+		return true
+	}
+	return len(req.ZipCode) >= 3 && contains(k.ZipPrefixes, req.ZipCode[:3])
+}
+
+// within reports whether n is at least low and at most high, a zero bound
+// being unset
+func within(n, low, high int) bool {
+	return (low == 0 || n >= low) && (high == 0 || n <= high)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeclinedQuote is returned instead of a quote when the pricing rules knock
+// the request out. It has no premium and is not kept in the quote history.
+type DeclinedQuote struct {
+	Status       string          `json:"status"` // always declined
+	PolicyType   string          `json:"policyType"`
+	CustomerID   string          `json:"customerId,omitempty"`
+	AgentID      string          `json:"agentId,omitempty"`
+	Reasons      []DeclineReason `json:"reasons"`
+	RulesVersion string          `json:"rulesVersion"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// DeclineReason is a knockout rule that declined a request, and the
+// coverage amounts it declined: one for a quote, possibly several for a
+// comparison
+type DeclineReason struct {
+	Rule            string `json:"rule"`
+	Reason          string `json:"reason"`
+	CoverageAmounts []int  `json:"coverageAmounts"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// RateLimit is where the caller stands against one of its rate limits
+type RateLimit struct {
+	Bucket        string    `json:"bucket"`
+	Endpoints     []string  `json:"endpoints"`
+	Limit         int       `json:"limit"`
+	Remaining     int       `json:"remaining"`
+	RatePerSecond float64   `json:"ratePerSecond"`
+	ResetSeconds  int       `json:"resetSeconds"`
+	ResetAt       time.Time `json:"resetAt"`
+}
+
+// LimitsResponse represents the response for GET /limits. Limits is empty
+// for callers that are not rate limited.
+type LimitsResponse struct {
+	Limits    []RateLimit `json:"limits"`
+	Timestamp time.Time   `json:"timestamp"`
+}
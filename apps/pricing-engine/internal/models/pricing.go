@@ -1,18 +1,53 @@
 package models
 
-import "time"
+import (
+	"strconv"
+	"strings"
+	"time"
+)
 
 // QuoteRequest represents a request for an insurance quote
 type QuoteRequest struct {
-	PolicyType     string  `json:"policyType" validate:"required,oneof=auto home life"`
-	CoverageAmount int     `json:"coverageAmount" validate:"required,min=1"`
-	CustomerAge    int     `json:"customerAge" validate:"required,min=18,max=120"`
-	RiskScore      int     `json:"riskScore" validate:"required,min=1,max=5"`
-	CustomerID     string  `json:"customerId,omitempty"`
-	MultiPolicy    bool    `json:"multiPolicy,omitempty"`
-	LoyaltyYears   int     `json:"loyaltyYears,omitempty"`
-	PaperlessBill  bool    `json:"paperlessBill,omitempty"`
-	ClaimsHistory  int     `json:"claimsHistory,omitempty"`
+	PolicyType     string        `json:"policyType" validate:"required,oneof=auto home life"`
+	CoverageAmount int           `json:"coverageAmount" validate:"required,min=1"`
+	CustomerAge    int           `json:"customerAge" validate:"required,min=18,max=120"`
+	RiskScore      int           `json:"riskScore" validate:"required,min=1,max=5"`
+	CustomerID     string        `json:"customerId,omitempty"`
+	AgentID        string        `json:"agentId,omitempty"` // agent or broker requesting the quote
+	MultiPolicy    bool          `json:"multiPolicy,omitempty"`
+	LoyaltyYears   int           `json:"loyaltyYears,omitempty"`
+	PaperlessBill  bool          `json:"paperlessBill,omitempty"` // only used when customer consent cannot be checked
+	ClaimsHistory  int           `json:"claimsHistory,omitempty"`
+	State          string        `json:"state,omitempty"`    // two-letter US state code of the insured risk
+	ZipCode        string        `json:"zipCode,omitempty"`  // five-digit ZIP or ZIP+4; requires state
+	Vehicle        *VehicleInfo  `json:"vehicle,omitempty"`  // auto only
+	Property       *PropertyInfo `json:"property,omitempty"` // home only
+}
+
+// VehicleInfo describes the insured vehicle on an auto quote
+type VehicleInfo struct {
+	Year          int    `json:"year"`
+	Make          string `json:"make"`
+	Model         string `json:"model"`
+	AnnualMileage int    `json:"annualMileage"`
+}
+
+// Construction types accepted on home quotes, from most to least fire
+// resistant
+const (
+	ConstructionFireResistive  = "fire_resistive"
+	ConstructionMasonry        = "masonry"
+	ConstructionJoistedMasonry = "joisted_masonry"
+	ConstructionFrame          = "frame"
+)
+
+// PropertyInfo describes the insured dwelling on a home quote
+type PropertyInfo struct {
+	YearBuilt        int    `json:"yearBuilt"`
+	ConstructionType string `json:"constructionType"`
+	// ProtectionClass is the ISO public protection class, 1 (best fire
+	// protection) to 10 (none)
+	ProtectionClass int `json:"protectionClass"`
 }
 
 // Quote represents an insurance quote response
@@ -20,6 +55,7 @@ type Quote struct {
 	QuoteID        string    `json:"quoteId"`
 	PolicyType     string    `json:"policyType"`
 	CoverageAmount int       `json:"coverageAmount"`
+	AgentID        string    `json:"agentId,omitempty"`
 	BaseRate       float64   `json:"baseRate"`
 	AdjustedRate   float64   `json:"adjustedRate"`
 	Discount       float64   `json:"discount"`
@@ -27,6 +63,33 @@ type Quote struct {
 	ValidUntil     time.Time `json:"validUntil"`
 	CreatedAt      time.Time `json:"createdAt"`
 	Factors        *Factors  `json:"factors,omitempty"`
+	// Experiment is set when the customer is enrolled in a pricing experiment
+	Experiment *ExperimentAssignment `json:"experiment,omitempty"`
+	// RulesVersion is the pricing rules version the quote was priced from
+	RulesVersion string `json:"rulesVersion,omitempty"`
+	// RequoteOf is the expired quote this one was requoted from
+	RequoteOf string `json:"requoteOf,omitempty"`
+}
+
+// MaxComparisonLevels caps how many coverage amounts one comparison may price
+const MaxComparisonLevels = 5
+
+// QuoteComparisonRequest represents a request to price one customer at
+// several coverage amounts, e.g. good/better/best options. The base
+// request's coverageAmount is ignored.
+type QuoteComparisonRequest struct {
+	Base            QuoteRequest `json:"base"`
+	CoverageAmounts []int        `json:"coverageAmounts"`
+}
+
+// QuoteComparison represents quotes for each requested coverage amount, in
+// request order
+type QuoteComparison struct {
+	PolicyType string    `json:"policyType"`
+	CustomerID string    `json:"customerId,omitempty"`
+	AgentID    string    `json:"agentId,omitempty"`
+	Quotes     []*Quote  `json:"quotes"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 // Factors represents the breakdown of pricing factors
@@ -36,7 +99,23 @@ type Factors struct {
 	AgeMultiplier      float64 `json:"ageMultiplier"`
 	RiskMultiplier     float64 `json:"riskMultiplier"`
 	DynamicMultiplier  float64 `json:"dynamicMultiplier,omitempty"`
-	DiscountAmount     float64 `json:"discountAmount"`
+	// TerritoryMultiplier is the geographic factor, and Territory the rules
+	// entry it came from: "zip:941", "state:CA" or "default"
+	TerritoryMultiplier float64 `json:"territoryMultiplier"`
+	Territory           string  `json:"territory,omitempty"`
+	VehicleMultiplier   float64 `json:"vehicleMultiplier"`  // 1.0 without vehicle details
+	PropertyMultiplier  float64 `json:"propertyMultiplier"` // 1.0 without property details
+	// DrivingScore is the telematics score the quote was priced with, if any,
+	// and TelematicsDiscount the share of premium it took off
+	DrivingScore       *int    `json:"drivingScore,omitempty"`
+	TelematicsDiscount float64 `json:"telematicsDiscount,omitempty"`
+	// PaperlessDiscount is the share of premium taken off for paperless
+	// billing consent on record
+	PaperlessDiscount float64 `json:"paperlessDiscount,omitempty"`
+	// MultiPolicyDiscount is the share of premium taken off because the
+	// customer's household already holds an active policy
+	MultiPolicyDiscount float64 `json:"multiPolicyDiscount,omitempty"`
+	DiscountAmount      float64 `json:"discountAmount"`
 }
 
 // Rate represents base rates for a policy type
@@ -44,6 +123,7 @@ type Rate struct {
 	PolicyType string             `json:"policyType"`
 	BaseRate   float64            `json:"baseRate"`
 	Coverage   map[string]float64 `json:"coverage"`
+	Territory  *TerritoryRates    `json:"territory,omitempty"`
 }
 
 // PricingRules represents the complete pricing rules structure
@@ -51,6 +131,7 @@ type PricingRules struct {
 	BaseRates      map[string]PolicyRates `json:"baseRates"`
 	Discounts      Discounts              `json:"discounts"`
 	DynamicPricing DynamicPricing         `json:"dynamicPricing"`
+	Knockouts      []KnockoutRule         `json:"knockouts,omitempty"` // risks declined before they are priced
 	Metadata       Metadata               `json:"metadata"`
 }
 
@@ -60,6 +141,40 @@ type PolicyRates struct {
 	Coverage       map[string]float64 `json:"coverage"`
 	AgeMultiplier  map[string]float64 `json:"ageMultiplier"`
 	RiskMultiplier map[string]float64 `json:"riskMultiplier"`
+	Territory      *TerritoryRates    `json:"territory,omitempty"` // nil rates every territory at 1.0
+	Vehicle        *VehicleRates      `json:"vehicle,omitempty"`   // auto only
+	Property       *PropertyRates     `json:"property,omitempty"`  // home only
+}
+
+// VehicleRates are the auto rating factors for the insured vehicle. Age and
+// mileage tables are keyed by inclusive bands such as "4-7" and "13+".
+// Makes are keyed in lower case; unlisted makes and bands rate at 1.0.
+type VehicleRates struct {
+	Age           map[string]float64 `json:"age"` // vehicle age in years
+	AnnualMileage map[string]float64 `json:"annualMileage"`
+	Make          map[string]float64 `json:"make,omitempty"`
+}
+
+// PropertyRates are the home rating factors for the insured dwelling. The
+// age table is keyed by bands like VehicleRates; unlisted entries rate at
+// 1.0.
+type PropertyRates struct {
+	Age              map[string]float64 `json:"age"`              // dwelling age in years
+	ConstructionType map[string]float64 `json:"constructionType"` // keyed by construction type
+	ProtectionClass  map[string]float64 `json:"protectionClass"`  // keyed "1" to "10"
+}
+
+// TerritoryDefault labels quotes rated at a policy type's default territory
+// multiplier
+const TerritoryDefault = "default"
+
+// TerritoryRates are the geographic multipliers for a policy type. A ZIP
+// prefix takes precedence over its state; territories not listed use Default,
+// and a zero Default means 1.0.
+type TerritoryRates struct {
+	Default     float64            `json:"default"`
+	States      map[string]float64 `json:"states,omitempty"`
+	ZipPrefixes map[string]float64 `json:"zipPrefixes,omitempty"` // first three digits of the ZIP code
 }
 
 // Discounts represents available discounts
@@ -68,6 +183,9 @@ type Discounts struct {
 	LoyaltyYears    map[string]float64 `json:"loyaltyYears"`
 	LowRisk         float64            `json:"lowRisk"`
 	PaperlessBilling float64           `json:"paperlessBilling"`
+	// Telematics maps driving score bands ("90-100") to discount rates for
+	// auto quotes; scores outside every band get none
+	Telematics map[string]float64 `json:"telematics,omitempty"`
 }
 
 // DynamicPricing represents dynamic pricing configuration
@@ -95,3 +213,35 @@ type RatesResponse struct {
 	Rates     []Rate    `json:"rates"`
 	Timestamp time.Time `json:"timestamp"`
 }
+
+// BandValue returns the value of the band containing n in a table keyed by
+// inclusive ranges ("4-7") and open upper bands ("13+"), and whether one
+// matched. Keys that do not parse are ignored.
+func BandValue(bands map[string]float64, n int) (float64, bool) {
+	for band, value := range bands {
+		if low, open := strings.CutSuffix(band, "+"); open {
+			if lowValue, err := strconv.Atoi(low); err == nil && n >= lowValue {
+				return value, true
+			}
+			continue
+		}
+
+		low, high, found := strings.Cut(band, "-")
+		if !found {
+			continue
+		}
+		lowValue, err := strconv.Atoi(low)
+		if err != nil {
+			continue
+		}
+		highValue, err := strconv.Atoi(high)
+		if err != nil {
+			continue
+		}
+		if n >= lowValue && n <= highValue {
+			return value, true
+		}
+	}
+
+	return 0, false
+}
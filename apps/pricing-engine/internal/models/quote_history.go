@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// QuoteRecord is a quote kept in the quote history, and whether it was later
+// bound into a policy
+type QuoteRecord struct {
+	QuoteID        string                `json:"quoteId"`
+	CustomerID     string                `json:"customerId,omitempty"` // empty for anonymous quotes
+	AgentID        string                `json:"agentId,omitempty"`
+	PolicyType     string                `json:"policyType"`
+	CoverageAmount int                   `json:"coverageAmount"`
+	FinalPremium   float64               `json:"finalPremium"`
+	Experiment     *ExperimentAssignment `json:"experiment,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	ValidUntil     time.Time             `json:"validUntil"`
+	Converted      bool                  `json:"converted"`
+	PolicyID       string                `json:"policyId,omitempty"`    // policy the quote was bound into
+	ConvertedAt    *time.Time            `json:"convertedAt,omitempty"` // set when converted
+
+	// RulesVersion, Request and Factors are what the quote was priced from,
+	// kept so it can be requoted once it expires. Quotes recorded before
+	// they were kept have none.
+	RulesVersion string        `json:"rulesVersion,omitempty"`
+	Request      *QuoteRequest `json:"request,omitempty"`
+	Factors      *Factors      `json:"factors,omitempty"`
+	RequoteOf    string        `json:"requoteOf,omitempty"` // expired quote this one was requoted from
+}
+
+// ConvertQuoteRequest is the optional body of POST /quote/{id}/convert
+type ConvertQuoteRequest struct {
+	PolicyID string `json:"policyId,omitempty"`
+}
+
+// CustomerQuoteHistory lists a customer's quotes, newest first, and how many
+// of them became policies
+type CustomerQuoteHistory struct {
+	CustomerID      string         `json:"customerId"`
+	Quotes          []*QuoteRecord `json:"quotes"`
+	TotalQuotes     int            `json:"totalQuotes"`
+	ConvertedQuotes int            `json:"convertedQuotes"`
+	ConversionRate  float64        `json:"conversionRate"` // converted / total, 0 without quotes
+}
@@ -0,0 +1,35 @@
+package models
+
+// Requote is the response of POST /quotes/{id}/requote: an expired quote
+// priced again against the current rules, next to the original
+type Requote struct {
+	Original *QuoteRecord `json:"original"`
+	Quote    *Quote       `json:"quote"`
+	// PremiumChange is the requoted final premium less the original's
+	PremiumChange float64   `json:"premiumChange"`
+	Rules         RulesDiff `json:"rules"`
+	// FactorChanges lists the pricing factors that differ between the
+	// original quote and the requote, such as a multiplier whose band moved
+	FactorChanges []RuleChange `json:"factorChanges"`
+}
+
+// RulesDiff compares the rules version a quote was priced from with the one
+// it was requoted from
+type RulesDiff struct {
+	FromVersion string `json:"fromVersion,omitempty"` // empty for quotes recorded without it
+	ToVersion   string `json:"toVersion"`
+	// Changes lists the rules of the quote's policy type, its discounts and
+	// dynamic pricing that differ between the versions. Compared is false,
+	// and Changes empty, when FromVersion is no longer loaded.
+	Compared bool         `json:"compared"`
+	Changes  []RuleChange `json:"changes"`
+}
+
+// RuleChange is one value that differs, named by its path in the rules or
+// factors, such as "baseRates.auto.coverage.500000". From or To is missing
+// when the value was added or removed.
+type RuleChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
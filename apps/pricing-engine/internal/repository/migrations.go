@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/sirupsen/logrus"
+)
+
+// quoteMigrations upgrade quotes.json, oldest first. Append one when a
+// change to models.QuoteRecord would misread the files written before it.
+// The pricing rules are configuration, edited by hand, and are not migrated.
+var quoteMigrations = migrate.Set{}
+
+// logMigration records an upgrade of the data file at path
+func logMigration(logger *logrus.Logger, path string, result migrate.Result) {
+	if !result.Migrated() {
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"from":   result.From,
+		"to":     result.To,
+		"backup": result.Backup,
+	}).Infof("Migrated %s: %s", path, strings.Join(result.Applied, "; "))
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/sirupsen/logrus"
+)
+
+// QuoteRepository keeps the quote history in memory, seeded from quotes.json
+// when present. Register hooks with OnCreate and OnUpdate to follow its
+// writes. It also hands out the daily sequence numbers of new quote IDs.
+type QuoteRepository struct {
+	hooks.Hooks
+
+	quotes    map[string]*models.QuoteRecord
+	sequences map[string]int   // last sequence number reserved each day
+	journal   *persist.Journal // nil unless Persist is called
+	pending   []hooks.Change   // written under mu, announced by unlock
+	mu        sync.RWMutex
+	logger    *logrus.Logger
+}
+
+var _ QuoteStore = (*QuoteRepository)(nil)
+
+// NewQuoteRepository creates a quote repository and loads past quotes from
+// dataPath. A missing quotes.json starts an empty history.
+func NewQuoteRepository(dataPath string, logger *logrus.Logger) (*QuoteRepository, error) {
+	repo := &QuoteRepository{
+		quotes:    make(map[string]*models.QuoteRecord),
+		sequences: make(map[string]int),
+		logger:    logger,
+	}
+
+	quotesPath := filepath.Join(dataPath, "quotes.json")
+	if err := repo.loadQuotes(quotesPath); err != nil {
+		return nil, fmt.Errorf("failed to load quotes: %w", err)
+	}
+
+	logger.Infof("Loaded %d quotes from %s", len(repo.quotes), dataPath)
+
+	return repo, nil
+}
+
+// Persist replaces the seed quotes with the history recovered from journal
+// and records every later change in it. Call it before the repository is
+// used.
+func (r *QuoteRepository) Persist(journal *persist.Journal) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := persist.Restore(journal, "quotes", func(id string, quote *models.QuoteRecord) {
+		r.quotes[id] = quote
+	}, func(id string) {
+		delete(r.quotes, id)
+	}); err != nil {
+		return err
+	}
+	if err := persist.Restore(journal, "quote_sequences", func(day string, last int) {
+		r.sequences[day] = last
+	}, func(day string) {
+		delete(r.sequences, day)
+	}); err != nil {
+		return err
+	}
+	for id := range r.quotes {
+		r.noteQuoteID(id)
+	}
+
+	r.journal = journal
+	return nil
+}
+
+// loadQuotes loads quote records from a JSON file
+func (r *QuoteRepository) loadQuotes(filePath string) error {
+	var quotes []*models.QuoteRecord
+	result, err := migrate.Load(filePath, quoteMigrations, &quotes)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	logMigration(r.logger, filePath, result)
+
+	for _, quote := range quotes {
+		if _, exists := r.quotes[quote.QuoteID]; exists {
+			return fmt.Errorf("duplicate quote %s", quote.QuoteID)
+		}
+		r.quotes[quote.QuoteID] = quote
+		r.noteQuoteID(quote.QuoteID)
+	}
+
+	return nil
+}
+
+// ReserveQuoteSequence reserves the next n sequence numbers of day, a
+// YYYYMMDD date, and returns the first. Reservations are journaled, so
+// numbers are never handed out twice across restarts, and start after any
+// stored quote's.
+func (r *QuoteRepository) ReserveQuoteSequence(day string, n int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := r.sequences[day] + n
+	if err := r.journal.Put("quote_sequences", day, last); err != nil {
+		return 0, err
+	}
+	first := r.sequences[day] + 1
+	r.sequences[day] = last
+	return first, nil
+}
+
+// noteQuoteID raises the sequence of the day in a quote ID of the form
+// Q-YYYYMMDD-NNNNNN to at least its number. Other IDs, such as the short
+// ones quotes had before, are ignored.
+func (r *QuoteRepository) noteQuoteID(id string) {
+	parts := strings.Split(id, "-")
+	if len(parts) != 3 || parts[0] != "Q" || len(parts[1]) != 8 {
+		return
+	}
+	if _, err := strconv.Atoi(parts[1]); err != nil {
+		return
+	}
+	seq, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return
+	}
+	if seq > r.sequences[parts[1]] {
+		r.sequences[parts[1]] = seq
+	}
+}
+
+// changed records a write made under the lock, for unlock to announce
+func (r *QuoteRepository) changed(op, collection, key string, value interface{}) {
+	r.pending = append(r.pending, hooks.Change{Op: op, Collection: collection, Key: key, Value: value})
+}
+
+// unlock releases the write lock and then runs the hooks of the writes
+// made under it, so hooks can use the repository
+func (r *QuoteRepository) unlock() {
+	changes := r.pending
+	r.pending = nil
+	r.mu.Unlock()
+	r.Notify(changes...)
+}
+
+// SaveQuote stores a new quote record
+func (r *QuoteRepository) SaveQuote(quote *models.QuoteRecord) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.quotes[quote.QuoteID]; exists {
+		return fmt.Errorf("quote already exists")
+	}
+	if err := r.journal.Put("quotes", quote.QuoteID, quote); err != nil {
+		return err
+	}
+	stored := *quote
+	r.quotes[quote.QuoteID] = &stored
+	r.changed(hooks.OpCreate, "quotes", quote.QuoteID, quote)
+	return nil
+}
+
+// GetQuote returns a copy of a quote record
+func (r *QuoteRepository) GetQuote(quoteID string) (*models.QuoteRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quote, exists := r.quotes[quoteID]
+	if !exists {
+		return nil, fmt.Errorf("quote not found")
+	}
+	copied := *quote
+	return &copied, nil
+}
+
+// GetQuotesByCustomerID returns copies of the customer's quotes, newest
+// first
+func (r *QuoteRepository) GetQuotesByCustomerID(customerID string) []*models.QuoteRecord {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	quotes := []*models.QuoteRecord{}
+	for _, quote := range r.quotes {
+		if quote.CustomerID == customerID {
+			copied := *quote
+			quotes = append(quotes, &copied)
+		}
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		if !quotes[i].CreatedAt.Equal(quotes[j].CreatedAt) {
+			return quotes[i].CreatedAt.After(quotes[j].CreatedAt)
+		}
+		return quotes[i].QuoteID < quotes[j].QuoteID
+	})
+	return quotes
+}
+
+// UpdateQuote replaces a stored quote record
+func (r *QuoteRepository) UpdateQuote(quote *models.QuoteRecord) error {
+	r.mu.Lock()
+	defer r.unlock()
+
+	if _, exists := r.quotes[quote.QuoteID]; !exists {
+		return fmt.Errorf("quote not found")
+	}
+	if err := r.journal.Put("quotes", quote.QuoteID, quote); err != nil {
+		return err
+	}
+	stored := *quote
+	r.quotes[quote.QuoteID] = &stored
+	r.changed(hooks.OpUpdate, "quotes", quote.QuoteID, quote)
+	return nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/persist"
+	"github.com/sirupsen/logrus"
+)
+
+func TestQuoteHooksFollowWrites(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := NewQuoteRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("NewQuoteRepository failed: %v", err)
+	}
+
+	var seen []string
+	repo.OnCreate("quotes", func(c hooks.Change) {
+		// Hooks run once the write lock is released, so they can read back
+		stored, err := repo.GetQuote(c.Key)
+		if err != nil {
+			t.Errorf("Hook could not read quote %s: %v", c.Key, err)
+			return
+		}
+		seen = append(seen, "created "+stored.QuoteID)
+	})
+	repo.OnUpdate(hooks.AnyCollection, func(c hooks.Change) {
+		if quote := c.Value.(*models.QuoteRecord); quote.Converted {
+			seen = append(seen, "converted "+c.Key)
+		}
+	})
+
+	quote := &models.QuoteRecord{QuoteID: "quote-001", CustomerID: "cust-001", PolicyType: "auto"}
+	if err := repo.SaveQuote(quote); err != nil {
+		t.Fatalf("SaveQuote failed: %v", err)
+	}
+	if err := repo.SaveQuote(quote); err == nil {
+		t.Fatal("Expected a second save of the quote to be refused")
+	}
+	quote.Converted = true
+	if err := repo.UpdateQuote(quote); err != nil {
+		t.Fatalf("UpdateQuote failed: %v", err)
+	}
+	if err := repo.UpdateQuote(&models.QuoteRecord{QuoteID: "quote-404"}); err == nil {
+		t.Fatal("Expected an update of an unknown quote to be refused")
+	}
+
+	if len(seen) != 2 || seen[0] != "created quote-001" || seen[1] != "converted quote-001" {
+		t.Errorf("Hooks saw %v, want the create and the update only", seen)
+	}
+}
+
+func TestQuotesMigrateOnLoad(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// A quote history written before quotes were typed by policyType
+	dataPath := t.TempDir()
+	path := filepath.Join(dataPath, "quotes.json")
+	if err := os.WriteFile(path, []byte(`[{"quoteId": "quote-001", "type": "home", "finalPremium": 912.5}]`), 0o644); err != nil {
+		t.Fatalf("Failed to write quotes: %v", err)
+	}
+
+	defer func(set migrate.Set) { quoteMigrations = set }(quoteMigrations)
+	quoteMigrations = migrate.Set{{Version: 1, Name: "rename type to policyType", Up: migrate.Records(func(record map[string]interface{}) error {
+		record["policyType"] = record["type"]
+		delete(record, "type")
+		return nil
+	})}}
+
+	repo, err := NewQuoteRepository(dataPath, logger)
+	if err != nil {
+		t.Fatalf("NewQuoteRepository failed: %v", err)
+	}
+	quote, err := repo.GetQuote("quote-001")
+	if err != nil || quote.PolicyType != "home" || quote.FinalPremium != 912.5 {
+		t.Errorf("Unexpected migrated quote %+v, %v", quote, err)
+	}
+	if backups, _ := filepath.Glob(path + ".v0-*.bak"); len(backups) != 1 {
+		t.Errorf("Expected one backup of the unmigrated file, got %v", backups)
+	}
+
+	// A file from a newer pricing-engine is refused rather than misread
+	quoteMigrations = nil
+	if _, err := NewQuoteRepository(dataPath, logger); err == nil || !strings.Contains(err.Error(), "newer than the 0 this service knows") {
+		t.Errorf("Expected the migrated file to be refused by an older service, got %v", err)
+	}
+}
+
+func TestQuoteSequenceSurvivesRestarts(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// Seed quotes with today's IDs move the sequence past them
+	dataPath := t.TempDir()
+	seed := `[{"quoteId": "Q-20260309-000042"}, {"quoteId": "Q-7f3a9c21"}]`
+	if err := os.WriteFile(filepath.Join(dataPath, "quotes.json"), []byte(seed), 0o644); err != nil {
+		t.Fatalf("Failed to write quotes: %v", err)
+	}
+	persistDir := t.TempDir()
+	open := func() (*QuoteRepository, *persist.Journal) {
+		repo, err := NewQuoteRepository(dataPath, logger)
+		if err != nil {
+			t.Fatalf("NewQuoteRepository failed: %v", err)
+		}
+		journal, err := persist.Open(persistDir, "pricing-engine", 0, logger)
+		if err != nil {
+			t.Fatalf("Open failed: %v", err)
+		}
+		if err := repo.Persist(journal); err != nil {
+			t.Fatalf("Persist failed: %v", err)
+		}
+		return repo, journal
+	}
+
+	repo, journal := open()
+	if first, err := repo.ReserveQuoteSequence("20260309", 100); err != nil || first != 43 {
+		t.Fatalf("Expected the block to start after the seed quote, got %d, %v", first, err)
+	}
+	if first, _ := repo.ReserveQuoteSequence("20260310", 100); first != 1 {
+		t.Errorf("Expected a new day to start at 1, got %d", first)
+	}
+	journal.Close()
+
+	repo, journal = open()
+	defer journal.Close()
+	if first, err := repo.ReserveQuoteSequence("20260309", 100); err != nil || first != 143 {
+		t.Errorf("Expected the block after the one reserved before the restart, got %d, %v", first, err)
+	}
+}
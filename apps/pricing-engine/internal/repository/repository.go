@@ -1,11 +1,16 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
 	"github.com/sirupsen/logrus"
@@ -15,13 +20,19 @@ import (
 type Repository struct {
 	pricingRules *models.PricingRules
 	mu           sync.RWMutex
+	dataPath     string
 	logger       *logrus.Logger
+
+	// alternates are other rules versions, keyed by metadata version, that
+	// pricing experiments can quote from
+	alternates map[string]*Repository
 }
 
 // NewRepository creates a new repository and loads pricing rules from JSON file
 func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error) {
 	repo := &Repository{
-		logger: logger,
+		dataPath: dataPath,
+		logger:   logger,
 	}
 
 	// Load pricing rules
@@ -31,9 +42,136 @@ func NewRepository(dataPath string, logger *logrus.Logger) (*Repository, error)
 
 	logger.Infof("Loaded pricing rules from %s (version: %s)", dataPath, repo.pricingRules.Metadata.Version)
 
+	// Load alternate rules versions for experiments
+	if err := repo.loadAlternates(filepath.Join(dataPath, "pricing-rules-*.json")); err != nil {
+		return nil, fmt.Errorf("failed to load alternate pricing rules: %w", err)
+	}
+
 	return repo, nil
 }
 
+// Reload re-reads the rules files the repository was loaded from,
+// replacing the current and alternate versions together. When any file
+// fails to load, the rules in use are kept.
+func (r *Repository) Reload() error {
+	fresh, err := NewRepository(r.dataPath, r.logger)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pricingRules = fresh.pricingRules
+	r.alternates = fresh.alternates
+	return nil
+}
+
+// WatchRules reloads the rules whenever a rules file changes, is added or
+// is removed, checking every interval until ctx ends. onReload, when not
+// nil, is called after each successful reload.
+func (r *Repository) WatchRules(ctx context.Context, interval time.Duration, onReload func()) {
+	last := r.rulesFingerprint()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := r.rulesFingerprint()
+			if current == last {
+				continue
+			}
+			last = current
+			if err := r.Reload(); err != nil {
+				r.logger.WithError(err).Warn("Failed to reload pricing rules, keeping current rules")
+				continue
+			}
+			if onReload != nil {
+				onReload()
+			}
+		}
+	}
+}
+
+// rulesFingerprint identifies the rules files on disk by name, size and
+// modification time
+func (r *Repository) rulesFingerprint() string {
+	files, _ := filepath.Glob(filepath.Join(r.dataPath, "pricing-rules*.json"))
+	var fingerprint strings.Builder
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			fmt.Fprintf(&fingerprint, "%s:%d:%d;", filepath.Base(file), info.Size(), info.ModTime().UnixNano())
+		}
+	}
+	return fingerprint.String()
+}
+
+// loadAlternates loads every rules file matching pattern as an alternate
+// version. Each must declare a version distinct from the others.
+func (r *Repository) loadAlternates(pattern string) error {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+
+	r.alternates = make(map[string]*Repository, len(files))
+	for _, file := range files {
+		alternate := &Repository{logger: r.logger}
+		if err := alternate.loadPricingRules(file); err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(file), err)
+		}
+
+		version := alternate.pricingRules.Metadata.Version
+		if version == "" {
+			return fmt.Errorf("%s: metadata version is required", filepath.Base(file))
+		}
+		if _, exists := r.alternates[version]; exists || version == r.pricingRules.Metadata.Version {
+			return fmt.Errorf("%s: duplicate rules version %s", filepath.Base(file), version)
+		}
+
+		r.alternates[version] = alternate
+		r.logger.Infof("Loaded alternate pricing rules from %s (version: %s)", filepath.Base(file), version)
+	}
+
+	return nil
+}
+
+// Versions returns the current rules version followed by the alternates in
+// sorted order
+func (r *Repository) Versions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alternates := make([]string, 0, len(r.alternates))
+	for version := range r.alternates {
+		alternates = append(alternates, version)
+	}
+	sort.Strings(alternates)
+
+	if r.pricingRules == nil {
+		return alternates
+	}
+	return append([]string{r.pricingRules.Metadata.Version}, alternates...)
+}
+
+// ForVersion returns a store that prices from the given rules version. The
+// current version returns the repository itself.
+func (r *Repository) ForVersion(version string) (PricingStore, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.pricingRules != nil && r.pricingRules.Metadata.Version == version {
+		return r, nil
+	}
+	alternate, exists := r.alternates[version]
+	if !exists {
+		return nil, fmt.Errorf("rules version %s not found", version)
+	}
+
+	return alternate, nil
+}
+
 // loadPricingRules loads pricing rules from a JSON file
 func (r *Repository) loadPricingRules(filePath string) error {
 	data, err := os.ReadFile(filePath)
@@ -45,6 +183,9 @@ func (r *Repository) loadPricingRules(filePath string) error {
 	if err := json.Unmarshal(data, &rules); err != nil {
 		return err
 	}
+	if err := validateKnockouts(rules.Knockouts); err != nil {
+		return err
+	}
 
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -54,6 +195,22 @@ func (r *Repository) loadPricingRules(filePath string) error {
 	return nil
 }
 
+// validateKnockouts checks every knockout rule and that their IDs are
+// unique
+func validateKnockouts(knockouts []models.KnockoutRule) error {
+	seen := make(map[string]bool, len(knockouts))
+	for i := range knockouts {
+		if err := knockouts[i].Validate(); err != nil {
+			return err
+		}
+		if seen[knockouts[i].ID] {
+			return fmt.Errorf("knockout rule %s is listed more than once", knockouts[i].ID)
+		}
+		seen[knockouts[i].ID] = true
+	}
+	return nil
+}
+
 // GetPricingRules returns the pricing rules
 func (r *Repository) GetPricingRules() *models.PricingRules {
 	r.mu.RLock()
@@ -119,19 +276,8 @@ func (r *Repository) GetAgeMultiplier(policyType string, age int) (float64, erro
 	}
 
 	// Determine age range
-	var ageRange string
-	switch {
-	case age >= 18 && age <= 24:
-		ageRange = "18-24"
-	case age >= 25 && age <= 34:
-		ageRange = "25-34"
-	case age >= 35 && age <= 49:
-		ageRange = "35-49"
-	case age >= 50 && age <= 64:
-		ageRange = "50-64"
-	case age >= 65:
-		ageRange = "65+"
-	default:
+	ageRange := AgeBand(age)
+	if ageRange == "" {
 		return 1.0, nil // Default multiplier
 	}
 
@@ -156,6 +302,30 @@ func (r *Repository) GetAgeMultiplier(policyType string, age int) (float64, erro
 	return 1.0, nil // Default multiplier
 }
 
+// AgeBands are the age ranges customers are rated by, each with the
+// youngest age in it
+var AgeBands = []struct {
+	Band string
+	From int
+}{
+	{"18-24", 18},
+	{"25-34", 25},
+	{"35-49", 35},
+	{"50-64", 50},
+	{"65+", 65},
+}
+
+// AgeBand returns the age range an age is rated in, or "" under 18
+func AgeBand(age int) string {
+	band := ""
+	for _, b := range AgeBands {
+		if age >= b.From {
+			band = b.Band
+		}
+	}
+	return band
+}
+
 // GetRiskMultiplier returns the risk multiplier for a given policy type and risk score
 func (r *Repository) GetRiskMultiplier(policyType string, riskScore int) (float64, error) {
 	r.mu.RLock()
@@ -179,6 +349,107 @@ func (r *Repository) GetRiskMultiplier(policyType string, riskScore int) (float6
 	return multiplier, nil
 }
 
+// GetTerritoryMultiplier returns the territory multiplier for a given policy
+// type and location, and the territory it matched. state and zipCode are
+// expected to be validated and normalized; either may be empty.
+func (r *Repository) GetTerritoryMultiplier(policyType, state, zipCode string) (float64, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.pricingRules == nil {
+		return 0, "", fmt.Errorf("pricing rules not loaded")
+	}
+
+	rates, exists := r.pricingRules.BaseRates[policyType]
+	if !exists {
+		return 0, "", fmt.Errorf("policy type %s not found", policyType)
+	}
+
+	if rates.Territory == nil {
+		return 1.0, models.TerritoryDefault, nil
+	}
+
+	if len(zipCode) >= 3 {
+		if zipMultiplier, exists := rates.Territory.ZipPrefixes[zipCode[:3]]; exists {
+			return zipMultiplier, "zip:" + zipCode[:3], nil
+		}
+	}
+	if stateMultiplier, exists := rates.Territory.States[state]; exists {
+		return stateMultiplier, "state:" + state, nil
+	}
+	if rates.Territory.Default > 0 {
+		return rates.Territory.Default, models.TerritoryDefault, nil
+	}
+
+	return 1.0, models.TerritoryDefault, nil
+}
+
+// GetVehicleMultiplier returns the combined vehicle age, mileage and make
+// multiplier for a given policy type. Policy types without vehicle rates
+// return 1.0.
+func (r *Repository) GetVehicleMultiplier(policyType string, vehicleAge, annualMileage int, vehicleMake string) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.pricingRules == nil {
+		return 0, fmt.Errorf("pricing rules not loaded")
+	}
+
+	rates, exists := r.pricingRules.BaseRates[policyType]
+	if !exists {
+		return 0, fmt.Errorf("policy type %s not found", policyType)
+	}
+	if rates.Vehicle == nil {
+		return 1.0, nil
+	}
+
+	multiplier := bandMultiplier(rates.Vehicle.Age, vehicleAge) * bandMultiplier(rates.Vehicle.AnnualMileage, annualMileage)
+	if makeMultiplier, exists := rates.Vehicle.Make[strings.ToLower(vehicleMake)]; exists {
+		multiplier *= makeMultiplier
+	}
+
+	return multiplier, nil
+}
+
+// GetPropertyMultiplier returns the combined dwelling age, construction and
+// protection class multiplier for a given policy type. Policy types without
+// property rates return 1.0.
+func (r *Repository) GetPropertyMultiplier(policyType string, dwellingAge int, constructionType string, protectionClass int) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.pricingRules == nil {
+		return 0, fmt.Errorf("pricing rules not loaded")
+	}
+
+	rates, exists := r.pricingRules.BaseRates[policyType]
+	if !exists {
+		return 0, fmt.Errorf("policy type %s not found", policyType)
+	}
+	if rates.Property == nil {
+		return 1.0, nil
+	}
+
+	multiplier := bandMultiplier(rates.Property.Age, dwellingAge)
+	if constructionMultiplier, exists := rates.Property.ConstructionType[constructionType]; exists {
+		multiplier *= constructionMultiplier
+	}
+	if classMultiplier, exists := rates.Property.ProtectionClass[strconv.Itoa(protectionClass)]; exists {
+		multiplier *= classMultiplier
+	}
+
+	return multiplier, nil
+}
+
+// bandMultiplier returns the multiplier of the band containing value, or 1.0
+// when none does
+func bandMultiplier(bands map[string]float64, value int) float64 {
+	if multiplier, found := models.BandValue(bands, value); found {
+		return multiplier
+	}
+	return 1.0
+}
+
 // GetDiscounts returns the discounts configuration
 func (r *Repository) GetDiscounts() *models.Discounts {
 	r.mu.RLock()
@@ -218,6 +489,7 @@ func (r *Repository) GetAllRates() []models.Rate {
 			PolicyType: policyType,
 			BaseRate:   policyRates.Base,
 			Coverage:   policyRates.Coverage,
+			Territory:  policyRates.Territory,
 		})
 	}
 
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testRules = `{
+  "baseRates": {
+    "auto": {
+      "base": 800,
+      "territory": {
+        "default": 0.9,
+        "states": {"CA": 1.2},
+        "zipPrefixes": {"941": 1.3}
+      },
+      "vehicle": {
+        "age": {"0-3": 1.2, "4-10": 1.0, "11+": 0.8},
+        "annualMileage": {"0-10000": 0.9, "10001+": 1.1},
+        "make": {"tesla": 1.25}
+      }
+    },
+    "home": {
+      "base": 1200,
+      "property": {
+        "age": {"0-20": 0.9, "21+": 1.2},
+        "constructionType": {"frame": 1.1},
+        "protectionClass": {"9": 1.4}
+      }
+    },
+    "life": {"base": 500}
+  },
+  "metadata": {"version": "test"}
+}`
+
+func newTestRepository(t *testing.T) *Repository {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pricing-rules.json"), []byte(testRules), 0o644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := NewRepository(dir, logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	return repo
+}
+
+func TestGetTerritoryMultiplier(t *testing.T) {
+	repo := newTestRepository(t)
+
+	tests := []struct {
+		policyType, state, zipCode string
+		multiplier                 float64
+		territory                  string
+	}{
+		{"auto", "CA", "94102", 1.3, "zip:941"},
+		{"auto", "CA", "90012", 1.2, "state:CA"},
+		{"auto", "OH", "43004", 0.9, "default"},
+		{"auto", "", "", 0.9, "default"},
+		{"life", "CA", "94102", 1.0, "default"},
+	}
+	for _, tt := range tests {
+		multiplier, territory, err := repo.GetTerritoryMultiplier(tt.policyType, tt.state, tt.zipCode)
+		if err != nil {
+			t.Fatalf("GetTerritoryMultiplier(%s, %s, %s) failed: %v", tt.policyType, tt.state, tt.zipCode, err)
+		}
+		if multiplier != tt.multiplier || territory != tt.territory {
+			t.Errorf("GetTerritoryMultiplier(%s, %s, %s) = %.2f, %s; want %.2f, %s", tt.policyType, tt.state, tt.zipCode, multiplier, territory, tt.multiplier, tt.territory)
+		}
+	}
+
+	if _, _, err := repo.GetTerritoryMultiplier("boat", "CA", ""); err == nil {
+		t.Error("Expected an unknown policy type to fail")
+	}
+}
+
+func TestVehicleAndPropertyMultipliers(t *testing.T) {
+	repo := newTestRepository(t)
+
+	vehicleTests := []struct {
+		age, mileage int
+		make         string
+		want         float64
+	}{
+		{2, 8000, "Tesla", 1.2 * 0.9 * 1.25},
+		{3, 10001, "Honda", 1.2 * 1.1},
+		{15, 500, "", 0.8 * 0.9},
+	}
+	for _, tt := range vehicleTests {
+		got, err := repo.GetVehicleMultiplier("auto", tt.age, tt.mileage, tt.make)
+		if err != nil || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("GetVehicleMultiplier(%d, %d, %s) = %.4f, %v; want %.4f", tt.age, tt.mileage, tt.make, got, err, tt.want)
+		}
+	}
+
+	if got, err := repo.GetPropertyMultiplier("home", 40, "frame", 9); err != nil || math.Abs(got-1.2*1.1*1.4) > 1e-9 {
+		t.Errorf("GetPropertyMultiplier = %.4f, %v", got, err)
+	}
+	if got, err := repo.GetPropertyMultiplier("home", 5, "masonry", 3); err != nil || got != 0.9 {
+		t.Errorf("Unlisted construction and class should rate at 1.0: %.4f, %v", got, err)
+	}
+	if got, err := repo.GetVehicleMultiplier("life", 2, 8000, "Tesla"); err != nil || got != 1.0 {
+		t.Errorf("Policy types without vehicle rates should rate at 1.0: %.4f, %v", got, err)
+	}
+}
+
+func TestReloadKeepsRulesOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rules string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "pricing-rules.json"), []byte(rules), 0o644); err != nil {
+			t.Fatalf("Failed to write rules: %v", err)
+		}
+	}
+	write(testRules)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := NewRepository(dir, logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	fingerprint := repo.rulesFingerprint()
+
+	write(strings.Replace(testRules, `"base": 800`, `"base": 850`, 1))
+	if repo.rulesFingerprint() == fingerprint {
+		t.Error("Expected the fingerprint to change with the rules file")
+	}
+	if err := repo.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if base, _ := repo.GetBaseRateForPolicy("auto"); base != 850 {
+		t.Errorf("Expected the reloaded base rate, got %.0f", base)
+	}
+
+	write("{not json")
+	if err := repo.Reload(); err == nil {
+		t.Error("Expected invalid rules to fail to reload")
+	}
+	if base, _ := repo.GetBaseRateForPolicy("auto"); base != 850 {
+		t.Errorf("Expected the rules in use to be kept, got %.0f", base)
+	}
+}
+
+func TestLoadRejectsInvalidKnockouts(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	for knockouts, want := range map[string]string{
+		`[{"id": "any-auto", "reason": "Not written", "policyTypes": ["auto"]}]`:                       "knockout rule any-auto sets no conditions",
+		`[{"id": "young", "maxAge": 20}]`:                                                              "knockout rule young has no reason",
+		`[{"id": "young", "reason": "a", "maxAge": 20}, {"id": "young", "reason": "b", "maxAge": 19}]`: "knockout rule young is listed more than once",
+	} {
+		dir := t.TempDir()
+		rules := strings.Replace(testRules, `"metadata":`, `"knockouts": `+knockouts+`, "metadata":`, 1)
+		if err := os.WriteFile(filepath.Join(dir, "pricing-rules.json"), []byte(rules), 0o644); err != nil {
+			t.Fatalf("Failed to write rules: %v", err)
+		}
+		if _, err := NewRepository(dir, logger); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q, got %v", want, err)
+		}
+	}
+}
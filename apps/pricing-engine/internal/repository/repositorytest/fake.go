@@ -0,0 +1,170 @@
+// Package repositorytest provides a PricingStore with fixed factors for unit
+// tests
+package repositorytest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+)
+
+// FakeStore returns the same coverage, age and risk multiplier for every
+// request so premiums are easy to predict. Zero multipliers are treated as
+// 1.0. Set Err to make every rate lookup fail.
+type FakeStore struct {
+	BaseRates          map[string]float64
+	CoverageMultiplier float64
+	// CoverageMultipliers overrides CoverageMultiplier for specific amounts
+	CoverageMultipliers map[int]float64
+	AgeMultiplier       float64
+	RiskMultiplier      float64
+	// TerritoryMultipliers are keyed by state; other states get
+	// TerritoryMultiplier
+	TerritoryMultiplier  float64
+	TerritoryMultipliers map[string]float64
+	VehicleMultiplier    float64
+	PropertyMultiplier   float64
+	Discounts            models.Discounts
+	DynamicPricing       models.DynamicPricing
+	Knockouts            []models.KnockoutRule
+	Err                  error
+	// Alternates are other rules versions, keyed by version. The fake's own
+	// version is FakeVersion.
+	Alternates map[string]*FakeStore
+}
+
+// FakeVersion is the rules version a FakeStore reports for itself
+const FakeVersion = "fake"
+
+var _ repository.PricingStore = (*FakeStore)(nil)
+
+// NewFakeStore creates a fake with the given base rate per policy type,
+// neutral multipliers and no discounts
+func NewFakeStore(baseRates map[string]float64) *FakeStore {
+	return &FakeStore{BaseRates: baseRates}
+}
+
+// GetPricingRules assembles the fake's factors into a rules document
+func (f *FakeStore) GetPricingRules() *models.PricingRules {
+	rules := &models.PricingRules{
+		BaseRates:      make(map[string]models.PolicyRates, len(f.BaseRates)),
+		Discounts:      f.Discounts,
+		DynamicPricing: f.DynamicPricing,
+		Knockouts:      f.Knockouts,
+		Metadata:       models.Metadata{Version: FakeVersion},
+	}
+	for policyType, base := range f.BaseRates {
+		rates := models.PolicyRates{Base: base, Coverage: make(map[string]float64, len(f.CoverageMultipliers))}
+		for amount, multiplier := range f.CoverageMultipliers {
+			rates.Coverage[strconv.Itoa(amount)] = multiplier
+		}
+		rules.BaseRates[policyType] = rates
+	}
+	return rules
+}
+
+// GetBaseRateForPolicy returns the configured base rate
+func (f *FakeStore) GetBaseRateForPolicy(policyType string) (float64, error) {
+	if f.Err != nil {
+		return 0, f.Err
+	}
+	base, exists := f.BaseRates[policyType]
+	if !exists {
+		return 0, fmt.Errorf("policy type %s not found", policyType)
+	}
+	return base, nil
+}
+
+// GetCoverageMultiplier returns the fixed coverage multiplier
+func (f *FakeStore) GetCoverageMultiplier(policyType string, coverageAmount int) (float64, error) {
+	if multiplier, exists := f.CoverageMultipliers[coverageAmount]; exists {
+		return f.multiplier(policyType, multiplier)
+	}
+	return f.multiplier(policyType, f.CoverageMultiplier)
+}
+
+// GetAgeMultiplier returns the fixed age multiplier
+func (f *FakeStore) GetAgeMultiplier(policyType string, age int) (float64, error) {
+	return f.multiplier(policyType, f.AgeMultiplier)
+}
+
+// GetRiskMultiplier returns the fixed risk multiplier
+func (f *FakeStore) GetRiskMultiplier(policyType string, riskScore int) (float64, error) {
+	return f.multiplier(policyType, f.RiskMultiplier)
+}
+
+// GetTerritoryMultiplier returns the multiplier configured for the state,
+// ignoring the ZIP code, or the fixed territory multiplier
+func (f *FakeStore) GetTerritoryMultiplier(policyType, state, zipCode string) (float64, string, error) {
+	if multiplier, exists := f.TerritoryMultipliers[state]; exists {
+		value, err := f.multiplier(policyType, multiplier)
+		return value, "state:" + state, err
+	}
+	value, err := f.multiplier(policyType, f.TerritoryMultiplier)
+	return value, models.TerritoryDefault, err
+}
+
+// GetVehicleMultiplier returns the fixed vehicle multiplier
+func (f *FakeStore) GetVehicleMultiplier(policyType string, vehicleAge, annualMileage int, vehicleMake string) (float64, error) {
+	return f.multiplier(policyType, f.VehicleMultiplier)
+}
+
+// GetPropertyMultiplier returns the fixed property multiplier
+func (f *FakeStore) GetPropertyMultiplier(policyType string, dwellingAge int, constructionType string, protectionClass int) (float64, error) {
+	return f.multiplier(policyType, f.PropertyMultiplier)
+}
+
+// GetDiscounts returns the configured discounts
+func (f *FakeStore) GetDiscounts() *models.Discounts {
+	return &f.Discounts
+}
+
+// GetDynamicPricing returns the configured dynamic pricing
+func (f *FakeStore) GetDynamicPricing() *models.DynamicPricing {
+	return &f.DynamicPricing
+}
+
+// GetAllRates returns the base rates ordered by policy type
+func (f *FakeStore) GetAllRates() []models.Rate {
+	rates := make([]models.Rate, 0, len(f.BaseRates))
+	for policyType, base := range f.BaseRates {
+		rates = append(rates, models.Rate{PolicyType: policyType, BaseRate: base})
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].PolicyType < rates[j].PolicyType })
+	return rates
+}
+
+// Versions returns FakeVersion followed by the alternates in sorted order
+func (f *FakeStore) Versions() []string {
+	versions := make([]string, 0, len(f.Alternates))
+	for version := range f.Alternates {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return append([]string{FakeVersion}, versions...)
+}
+
+// ForVersion returns the fake itself for FakeVersion, otherwise the alternate
+func (f *FakeStore) ForVersion(version string) (repository.PricingStore, error) {
+	if version == FakeVersion {
+		return f, nil
+	}
+	alternate, exists := f.Alternates[version]
+	if !exists {
+		return nil, fmt.Errorf("rules version %s not found", version)
+	}
+	return alternate, nil
+}
+
+func (f *FakeStore) multiplier(policyType string, value float64) (float64, error) {
+	if _, err := f.GetBaseRateForPolicy(policyType); err != nil {
+		return 0, err
+	}
+	if value == 0 {
+		return 1.0, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+
+// PricingStore is the rating data the pricing service depends on.
+// Repository is the JSON-backed implementation; repositorytest provides a
+// fake with fixed factors for unit tests.
+type PricingStore interface {
+	GetPricingRules() *models.PricingRules
+	GetBaseRateForPolicy(policyType string) (float64, error)
+	GetCoverageMultiplier(policyType string, coverageAmount int) (float64, error)
+	GetAgeMultiplier(policyType string, age int) (float64, error)
+	GetRiskMultiplier(policyType string, riskScore int) (float64, error)
+	GetTerritoryMultiplier(policyType, state, zipCode string) (float64, string, error)
+	GetVehicleMultiplier(policyType string, vehicleAge, annualMileage int, vehicleMake string) (float64, error)
+	GetPropertyMultiplier(policyType string, dwellingAge int, constructionType string, protectionClass int) (float64, error)
+	GetDiscounts() *models.Discounts
+	GetDynamicPricing() *models.DynamicPricing
+	GetAllRates() []models.Rate
+
+	// Versions lists the loaded rules versions, current first; ForVersion
+	// prices from one of them
+	Versions() []string
+	ForVersion(version string) (PricingStore, error)
+}
+
+var _ PricingStore = (*Repository)(nil)
+
+// QuoteStore is the quote history the quote services depend on.
+// QuoteRepository is the in-memory implementation.
+type QuoteStore interface {
+	SaveQuote(quote *models.QuoteRecord) error
+	GetQuote(quoteID string) (*models.QuoteRecord, error)
+	GetQuotesByCustomerID(customerID string) []*models.QuoteRecord
+	UpdateQuote(quote *models.QuoteRecord) error
+}
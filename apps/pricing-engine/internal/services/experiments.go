@@ -0,0 +1,215 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultExperimentQuoteLimit is how many experiment quotes are kept for
+// results
+const DefaultExperimentQuoteLimit = 50000
+
+// experimentQuote is a quote priced under an experiment, and whether it was
+// bound
+type experimentQuote struct {
+	assignment models.ExperimentAssignment
+	premium    float64
+	converted  bool
+}
+
+// ExperimentService tracks quotes priced under pricing experiments and
+// compares conversion and premiums per variant. Quotes are kept in memory;
+// past the limit the oldest are dropped.
+type ExperimentService struct {
+	repo   repository.PricingStore
+	flags  *features.Flags
+	limit  int
+	logger *logrus.Logger
+
+	mu     sync.Mutex
+	quotes map[string]*experimentQuote
+	order  []string // quote IDs, oldest first
+}
+
+// NewExperimentService creates an experiment service keeping up to limit
+// quotes
+func NewExperimentService(repo repository.PricingStore, flags *features.Flags, limit int, logger *logrus.Logger) *ExperimentService {
+	return &ExperimentService{
+		repo:   repo,
+		flags:  flags,
+		limit:  limit,
+		logger: logger,
+		quotes: make(map[string]*experimentQuote),
+	}
+}
+
+// RecordQuote tracks a quote that carries an experiment assignment. Other
+// quotes are ignored. A nil service ignores every quote.
+func (s *ExperimentService) RecordQuote(quote *models.Quote) {
+	if s == nil || quote.Experiment == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.quotes[quote.QuoteID]; exists {
+		return
+	}
+	if s.limit > 0 && len(s.order) >= s.limit {
+		delete(s.quotes, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.quotes[quote.QuoteID] = &experimentQuote{
+		assignment: *quote.Experiment,
+		premium:    quote.FinalPremium,
+	}
+	s.order = append(s.order, quote.QuoteID)
+}
+
+// ConvertQuote marks an experiment quote as bound into a policy. Converting
+// twice has no further effect.
+func (s *ExperimentService) ConvertQuote(quoteID string) (*models.ExperimentAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	quote, exists := s.quotes[quoteID]
+	if !exists {
+		return nil, fmt.Errorf("quote not found")
+	}
+
+	if !quote.converted {
+		quote.converted = true
+		s.logger.WithFields(logrus.Fields{
+			"quoteId":      quoteID,
+			"experimentId": quote.assignment.ExperimentID,
+			"variant":      quote.assignment.Variant,
+		}).Info("Experiment quote converted")
+	}
+
+	assignment := quote.assignment
+	return &assignment, nil
+}
+
+// GetResults compares the variants of an experiment. Control comes first,
+// then the configured variants, then any variants only seen in past quotes.
+func (s *ExperimentService) GetResults(experimentID string) (*models.ExperimentResults, error) {
+	running := s.flags.RateExperiment()
+	active := running != nil && running.ID == experimentID
+
+	// Collect premiums and conversions per variant
+	type tally struct {
+		rulesVersion string
+		premiums     []float64
+		conversions  int
+	}
+	tallies := make(map[string]*tally)
+	var order []string
+	add := func(variant, rulesVersion string) *tally {
+		t, exists := tallies[variant]
+		if !exists {
+			t = &tally{rulesVersion: rulesVersion}
+			tallies[variant] = t
+			order = append(order, variant)
+		}
+		return t
+	}
+
+	if active {
+		add(models.VariantControl, s.repo.Versions()[0])
+		for _, variant := range running.Variants {
+			add(variant.Name, variant.RulesVersion)
+		}
+	}
+
+	s.mu.Lock()
+	for _, quoteID := range s.order {
+		quote := s.quotes[quoteID]
+		if quote.assignment.ExperimentID != experimentID {
+			continue
+		}
+		t := add(quote.assignment.Variant, quote.assignment.RulesVersion)
+		t.premiums = append(t.premiums, quote.premium)
+		if quote.converted {
+			t.conversions++
+		}
+	}
+	s.mu.Unlock()
+
+	if len(order) == 0 {
+		return nil, fmt.Errorf("experiment not found")
+	}
+
+	// Control first, the rest in the order they were added
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i] == models.VariantControl && order[j] != models.VariantControl
+	})
+
+	results := &models.ExperimentResults{
+		ExperimentID: experimentID,
+		Active:       active,
+		Variants:     make([]*models.VariantResults, 0, len(order)),
+		GeneratedAt:  time.Now(),
+	}
+	for _, variant := range order {
+		t := tallies[variant]
+		variantResults := &models.VariantResults{
+			Variant:      variant,
+			RulesVersion: t.rulesVersion,
+			Quotes:       len(t.premiums),
+			Conversions:  t.conversions,
+			Premium:      premiumDistribution(t.premiums),
+		}
+		if variantResults.Quotes > 0 {
+			variantResults.ConversionRate = roundTo(float64(t.conversions)/float64(variantResults.Quotes), 4)
+		}
+		results.Variants = append(results.Variants, variantResults)
+	}
+
+	return results, nil
+}
+
+// premiumDistribution summarizes premiums, rounded to the cent. Percentiles
+// use the nearest rank.
+func premiumDistribution(premiums []float64) models.PremiumDistribution {
+	if len(premiums) == 0 {
+		return models.PremiumDistribution{}
+	}
+
+	sorted := append([]float64(nil), premiums...)
+	sort.Float64s(sorted)
+
+	total := 0.0
+	for _, premium := range sorted {
+		total += premium
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return sorted[rank]
+	}
+
+	return models.PremiumDistribution{
+		Mean:   roundTo(total/float64(len(sorted)), 2),
+		Min:    roundTo(sorted[0], 2),
+		Median: roundTo(percentile(0.5), 2),
+		P90:    roundTo(percentile(0.9), 2),
+		Max:    roundTo(sorted[len(sorted)-1], 2),
+	}
+}
+
+// roundTo rounds value to the given number of decimal places
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/features"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func newExperimentTestServices(t *testing.T, experiment *features.Experiment) (*PricingService, *ExperimentService, *features.Flags) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_DYNAMIC_RATES", "false")
+	t.Setenv("FEATURE_RATE_EXPERIMENT", "")
+	t.Setenv("FLAG_IMPRESSIONS_SINK", "none")
+	flags, err := features.Initialize("dev-mode", logger)
+	if err != nil {
+		t.Fatalf("Failed to initialize flags: %v", err)
+	}
+	flags.SetRateExperiment(experiment)
+
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000})
+	store.Alternates = map[string]*repositorytest.FakeStore{
+		"candidate-v2": repositorytest.NewFakeStore(map[string]float64{"auto": 1200}),
+	}
+
+	quoteRepo, err := repository.NewQuoteRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create quote repository: %v", err)
+	}
+
+	experiments := NewExperimentService(store, flags, DefaultExperimentQuoteLimit, logger)
+	quotes := NewQuoteHistoryService(quoteRepo, experiments, logger)
+	return NewPricingService(store, flags, quotes, nil, QuoteValidity{}, nil, nil, nil, nil, nil, logger), experiments, flags
+}
+
+func TestRateExperimentQuotesFromAssignedRules(t *testing.T) {
+	pricing, experiments, _ := newExperimentTestServices(t, &features.Experiment{
+		ID:       "rates-test",
+		Variants: []features.ExperimentVariant{{Name: "candidate", RulesVersion: "candidate-v2", Percentage: 50}},
+	})
+
+	quoteFor := func(customerID string) *models.Quote {
+		t.Helper()
+		quote, err := pricing.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: customerID})
+		if err != nil {
+			t.Fatalf("CalculateQuote failed: %v", err)
+		}
+		return quote
+	}
+
+	premiums := map[string]float64{models.VariantControl: 1000, "candidate": 1200}
+	versions := map[string]string{models.VariantControl: repositorytest.FakeVersion, "candidate": "candidate-v2"}
+	counts := map[string]int{}
+	var converted []string
+	for i := 0; i < 40; i++ {
+		quote := quoteFor(fmt.Sprintf("cust-%03d", i))
+		if quote.Experiment == nil || quote.Experiment.ExperimentID != "rates-test" {
+			t.Fatalf("Quote should carry the experiment assignment: %+v", quote.Experiment)
+		}
+		variant := quote.Experiment.Variant
+		if quote.FinalPremium != premiums[variant] || quote.Experiment.RulesVersion != versions[variant] {
+			t.Errorf("Variant %s quoted %.2f from %s", variant, quote.FinalPremium, quote.Experiment.RulesVersion)
+		}
+		counts[variant]++
+
+		// Bind every fourth quote
+		if i%4 == 0 {
+			if _, err := experiments.ConvertQuote(quote.QuoteID); err != nil {
+				t.Fatalf("ConvertQuote failed: %v", err)
+			}
+			converted = append(converted, variant)
+		}
+	}
+	if counts[models.VariantControl] == 0 || counts["candidate"] == 0 {
+		t.Fatalf("Expected both variants to be assigned: %v", counts)
+	}
+
+	// Assignment is stable per customer; quotes without a customer are not enrolled
+	if first, again := quoteFor("cust-007"), quoteFor("cust-007"); first.Experiment.Variant != again.Experiment.Variant {
+		t.Errorf("Customer switched variants: %s then %s", first.Experiment.Variant, again.Experiment.Variant)
+	}
+	if quote := quoteFor(""); quote.Experiment != nil {
+		t.Errorf("Anonymous quote should not be enrolled: %+v", quote.Experiment)
+	}
+
+	results, err := experiments.GetResults("rates-test")
+	if err != nil {
+		t.Fatalf("GetResults failed: %v", err)
+	}
+	if !results.Active || len(results.Variants) != 2 || results.Variants[0].Variant != models.VariantControl {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+
+	conversions := map[string]int{}
+	for _, variant := range converted {
+		conversions[variant]++
+	}
+	total := 0
+	for _, variant := range results.Variants {
+		total += variant.Quotes
+		if variant.Conversions != conversions[variant.Variant] {
+			t.Errorf("%s conversions: got %d, want %d", variant.Variant, variant.Conversions, conversions[variant.Variant])
+		}
+		if variant.Premium.Min != premiums[variant.Variant] || variant.Premium.Max != premiums[variant.Variant] {
+			t.Errorf("%s premium distribution: %+v", variant.Variant, variant.Premium)
+		}
+	}
+	if total != 42 {
+		t.Errorf("Quotes tracked: got %d, want 42", total)
+	}
+}
+
+func TestRateExperimentFallsBackWithoutRules(t *testing.T) {
+	pricing, experiments, flags := newExperimentTestServices(t, &features.Experiment{
+		ID:       "rates-missing",
+		Variants: []features.ExperimentVariant{{Name: "candidate", RulesVersion: "not-loaded", Percentage: 100}},
+	})
+
+	quote, err := pricing.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: "cust-001"})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	if quote.Experiment.Variant != models.VariantControl || quote.FinalPremium != 1000 {
+		t.Errorf("Expected a control quote from current rules, got %s at %.2f", quote.Experiment.Variant, quote.FinalPremium)
+	}
+
+	// Results outlive the experiment; unknown experiments and quotes are not found
+	flags.SetRateExperiment(nil)
+	results, err := experiments.GetResults("rates-missing")
+	if err != nil || results.Active || results.Variants[0].Quotes != 1 {
+		t.Errorf("Unexpected results after stopping: %+v, %v", results, err)
+	}
+	if _, err := experiments.GetResults("rates-404"); err == nil || err.Error() != "experiment not found" {
+		t.Errorf("Unknown experiment: got %v", err)
+	}
+	if _, err := experiments.ConvertQuote("Q-404"); err == nil || err.Error() != "quote not found" {
+		t.Errorf("Unknown quote: got %v", err)
+	}
+}
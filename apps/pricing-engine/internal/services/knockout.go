@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Quote outcomes counted in metrics
+const (
+	outcomeQuoted   = "quoted"
+	outcomeDeclined = "declined"
+)
+
+// DeclinedError is returned for a quote request knocked out by the pricing
+// rules. Quote is the declined result to answer the caller with.
+type DeclinedError struct {
+	Quote *models.DeclinedQuote
+}
+
+func (e *DeclinedError) Error() string {
+	reasons := make([]string, 0, len(e.Quote.Reasons))
+	for _, reason := range e.Quote.Reasons {
+		reasons = append(reasons, reason.Reason)
+	}
+	return "quote declined: " + strings.Join(reasons, "; ")
+}
+
+// knockout declines req when a knockout rule of rules matches it at any of
+// coverageAmounts, listing every rule that matched
+func (s *PricingService) knockout(rules *models.PricingRules, req *models.QuoteRequest, coverageAmounts []int) error {
+	var reasons []models.DeclineReason
+	for i := range rules.Knockouts {
+		rule := &rules.Knockouts[i]
+		var declined []int
+		for _, coverageAmount := range coverageAmounts {
+			if rule.Matches(req, coverageAmount) {
+				declined = append(declined, coverageAmount)
+			}
+		}
+		if len(declined) > 0 {
+			reasons = append(reasons, models.DeclineReason{Rule: rule.ID, Reason: rule.Reason, CoverageAmounts: declined})
+		}
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	ruleIDs := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		ruleIDs = append(ruleIDs, reason.Rule)
+	}
+	s.outcomes.declined(req.PolicyType, ruleIDs...)
+	s.logger.WithFields(logrus.Fields{
+		"policyType":   req.PolicyType,
+		"rules":        ruleIDs,
+		"rulesVersion": rules.Metadata.Version,
+	}).Info("Quote knocked out by pricing rules")
+
+	return &DeclinedError{Quote: &models.DeclinedQuote{
+		Status:       models.QuoteStatusDeclined,
+		PolicyType:   req.PolicyType,
+		CustomerID:   req.CustomerID,
+		AgentID:      req.AgentID,
+		Reasons:      reasons,
+		RulesVersion: rules.Metadata.Version,
+		CreatedAt:    time.Now(),
+	}}
+}
+
+// quoteOutcomes counts quote requests quoted and declined by policy type,
+// and the knockout rules that declined them
+type quoteOutcomes struct {
+	mu        sync.Mutex
+	requests  map[[2]string]int64 // policy type, outcome
+	knockouts map[[2]string]int64 // policy type, rule
+}
+
+func newQuoteOutcomes() *quoteOutcomes {
+	return &quoteOutcomes{
+		requests:  make(map[[2]string]int64),
+		knockouts: make(map[[2]string]int64),
+	}
+}
+
+// quoted counts a request that was priced
+func (o *quoteOutcomes) quoted(policyType string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests[[2]string{policyType, outcomeQuoted}]++
+}
+
+// declined counts a request that was declined, by the knockout rules
+// given or, without any, by an underwriting rule
+func (o *quoteOutcomes) declined(policyType string, knockouts ...string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests[[2]string{policyType, outcomeDeclined}]++
+	for _, rule := range knockouts {
+		o.knockouts[[2]string{policyType, rule}]++
+	}
+}
+
+// WritePrometheus writes quote outcomes for GET /metrics in the Prometheus
+// text exposition format
+func (s *PricingService) WritePrometheus(w io.Writer) {
+	s.outcomes.mu.Lock()
+	defer s.outcomes.mu.Unlock()
+
+	fmt.Fprint(w, "# HELP pricing_quote_outcomes_total Quote requests priced (quoted) or refused by a knockout or underwriting rule (declined).\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_outcomes_total counter\n")
+	for _, key := range sortedKeys(s.outcomes.requests) {
+		fmt.Fprintf(w, "pricing_quote_outcomes_total{policy_type=%q,outcome=%q} %d\n", key[0], key[1], s.outcomes.requests[key])
+	}
+	fmt.Fprint(w, "# HELP pricing_quote_knockouts_total Quote requests declined by each knockout rule of the pricing rules.\n")
+	fmt.Fprint(w, "# TYPE pricing_quote_knockouts_total counter\n")
+	for _, key := range sortedKeys(s.outcomes.knockouts) {
+		fmt.Fprintf(w, "pricing_quote_knockouts_total{policy_type=%q,rule=%q} %d\n", key[0], key[1], s.outcomes.knockouts[key])
+	}
+}
+
+func sortedKeys(counts map[[2]string]int64) [][2]string {
+	keys := make([][2]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository/repositorytest"
+)
+
+func TestKnockoutRulesDeclineQuotes(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "home": 1500})
+	store.Knockouts = []models.KnockoutRule{
+		{ID: "auto-young-high-risk", Reason: "Young high-risk drivers are not quoted", PolicyTypes: []string{"auto"}, MaxAge: 20, MinRiskScore: 5},
+		{ID: "home-coastal", Reason: "Coastal homes are not written", PolicyTypes: []string{"home"}, ZipPrefixes: []string{"330"}},
+		{ID: "home-large", Reason: "Homes over 1,000,000 are referred", PolicyTypes: []string{"home"}, MinCoverage: 1000001},
+	}
+	service := newTestService(store)
+	ctx := context.Background()
+
+	_, err := service.CalculateQuote(ctx, &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 100000, CustomerAge: 19, RiskScore: 5, CustomerID: "cust-001"})
+	var declined *DeclinedError
+	if !errors.As(err, &declined) {
+		t.Fatalf("Expected the quote to be declined, got %v", err)
+	}
+	if err.Error() != "quote declined: Young high-risk drivers are not quoted" {
+		t.Errorf("Unexpected error %q", err)
+	}
+	quote := declined.Quote
+	if quote.Status != models.QuoteStatusDeclined || quote.CustomerID != "cust-001" || quote.RulesVersion != repositorytest.FakeVersion {
+		t.Errorf("Unexpected declined quote %+v", quote)
+	}
+	if len(quote.Reasons) != 1 || quote.Reasons[0].Rule != "auto-young-high-risk" || len(quote.Reasons[0].CoverageAmounts) != 1 {
+		t.Errorf("Unexpected reasons %+v", quote.Reasons)
+	}
+
+	// Every condition of a rule must match
+	if _, err := service.CalculateQuote(ctx, &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 100000, CustomerAge: 19, RiskScore: 4}); err != nil {
+		t.Errorf("Expected a lower risk score to be quoted, got %v", err)
+	}
+
+	// A comparison is declined with every rule and level that matched
+	_, err = service.CompareQuotes(ctx, &models.QuoteComparisonRequest{
+		Base:            models.QuoteRequest{PolicyType: "home", CustomerAge: 45, RiskScore: 2, State: "FL", ZipCode: "33010"},
+		CoverageAmounts: []int{500000, 1200000, 1500000},
+	})
+	if !errors.As(err, &declined) {
+		t.Fatalf("Expected the comparison to be declined, got %v", err)
+	}
+	reasons := declined.Quote.Reasons
+	if len(reasons) != 2 || reasons[0].Rule != "home-coastal" || len(reasons[0].CoverageAmounts) != 3 ||
+		reasons[1].Rule != "home-large" || len(reasons[1].CoverageAmounts) != 2 || reasons[1].CoverageAmounts[0] != 1200000 {
+		t.Errorf("Unexpected reasons %+v", reasons)
+	}
+
+	var metrics strings.Builder
+	service.WritePrometheus(&metrics)
+	for _, line := range []string{
+		`pricing_quote_outcomes_total{policy_type="auto",outcome="declined"} 1`,
+		`pricing_quote_outcomes_total{policy_type="auto",outcome="quoted"} 1`,
+		`pricing_quote_outcomes_total{policy_type="home",outcome="declined"} 1`,
+		`pricing_quote_knockouts_total{policy_type="home",rule="home-coastal"} 1`,
+		`pricing_quote_knockouts_total{policy_type="home",rule="home-large"} 1`,
+	} {
+		if !strings.Contains(metrics.String(), line+"\n") {
+			t.Errorf("Expected %s in metrics:\n%s", line, metrics.String())
+		}
+	}
+}
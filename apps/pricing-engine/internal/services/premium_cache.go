@@ -0,0 +1,201 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// maxRiskScore is the highest risk score a quote may declare; scores run
+// from 1
+const maxRiskScore = 5
+
+// PremiumCache holds the base premium of every combination of policy type,
+// coverage tier, age band and risk score in each loaded rules version, so
+// quotes can skip the rules lookups that do not depend on the customer.
+// Coverage tiers are the amounts listed in the rules; other amounts are
+// looked up as before. Refresh rebuilds it, and must be called whenever the
+// rules reload.
+type PremiumCache struct {
+	repo   repository.PricingStore
+	logger *logrus.Logger
+
+	mu    sync.RWMutex
+	grids map[string]map[premiumKey]*premiumRow // by rules version
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// premiumKey identifies a row of the grid
+type premiumKey struct {
+	policyType string
+	ageBand    string
+	riskScore  int
+}
+
+// premiumRow holds the coverage-independent factors of a row and the base
+// premium at each coverage tier
+type premiumRow struct {
+	baseRate       float64
+	ageMultiplier  float64
+	riskMultiplier float64
+	coverage       map[int]premiumCell
+}
+
+// premiumCell is the base premium at one coverage tier, before territory,
+// vehicle, property and dynamic factors and discounts
+type premiumCell struct {
+	coverageMultiplier float64
+	basePremium        float64
+}
+
+// cell returns the premium at a coverage amount when it is a tier. A nil
+// row has no tiers.
+func (r *premiumRow) cell(coverageAmount int) (premiumCell, bool) {
+	if r == nil {
+		return premiumCell{}, false
+	}
+	cell, ok := r.coverage[coverageAmount]
+	return cell, ok
+}
+
+// NewPremiumCache creates an empty cache over repo's rules versions
+func NewPremiumCache(repo repository.PricingStore, logger *logrus.Logger) *PremiumCache {
+	return &PremiumCache{
+		repo:   repo,
+		logger: logger,
+		grids:  make(map[string]map[premiumKey]*premiumRow),
+	}
+}
+
+// Refresh precomputes the grid of every loaded rules version, replacing the
+// previous grids. A version whose rules cannot be priced is logged and left
+// out, so its quotes are looked up as before.
+func (c *PremiumCache) Refresh() {
+	started := time.Now()
+	grids := make(map[string]map[premiumKey]*premiumRow)
+	cells := 0
+	for _, version := range c.repo.Versions() {
+		store, err := c.repo.ForVersion(version)
+		if err != nil {
+			continue
+		}
+		grid, err := buildPremiumGrid(store)
+		if err != nil {
+			c.logger.WithError(err).WithField("rulesVersion", version).Warn("Failed to precompute premiums, quoting from rules")
+			continue
+		}
+		grids[version] = grid
+		for _, row := range grid {
+			cells += len(row.coverage)
+		}
+	}
+
+	c.mu.Lock()
+	c.grids = grids
+	c.mu.Unlock()
+
+	c.logger.WithFields(logrus.Fields{
+		"versions": len(grids),
+		"cells":    cells,
+		"duration": time.Since(started).String(),
+	}).Info("Precomputed premiums")
+}
+
+// buildPremiumGrid prices every row and coverage tier of store's rules
+func buildPremiumGrid(store repository.PricingStore) (map[premiumKey]*premiumRow, error) {
+	rules := store.GetPricingRules()
+	if rules == nil {
+		return nil, fmt.Errorf("pricing rules not loaded")
+	}
+
+	grid := make(map[premiumKey]*premiumRow)
+	for policyType, rates := range rules.BaseRates {
+		tiers := make([]int, 0, len(rates.Coverage))
+		for amount := range rates.Coverage {
+			if n, err := strconv.Atoi(amount); err == nil && n > 0 {
+				tiers = append(tiers, n)
+			}
+		}
+
+		baseRate, err := store.GetBaseRateForPolicy(policyType)
+		if err != nil {
+			return nil, err
+		}
+		for _, band := range repository.AgeBands {
+			ageMultiplier, err := store.GetAgeMultiplier(policyType, band.From)
+			if err != nil {
+				return nil, err
+			}
+			for riskScore := 1; riskScore <= maxRiskScore; riskScore++ {
+				riskMultiplier, err := store.GetRiskMultiplier(policyType, riskScore)
+				if err != nil {
+					return nil, err
+				}
+
+				row := &premiumRow{
+					baseRate:       baseRate,
+					ageMultiplier:  ageMultiplier,
+					riskMultiplier: riskMultiplier,
+					coverage:       make(map[int]premiumCell, len(tiers)),
+				}
+				for _, amount := range tiers {
+					coverageMultiplier, err := store.GetCoverageMultiplier(policyType, amount)
+					if err != nil {
+						return nil, err
+					}
+					// Multiplied in the order quoteAtCoverage uses, so cached
+					// and looked-up premiums are identical
+					row.coverage[amount] = premiumCell{
+						coverageMultiplier: coverageMultiplier,
+						basePremium:        baseRate * coverageMultiplier * ageMultiplier * riskMultiplier,
+					}
+				}
+				grid[premiumKey{policyType: policyType, ageBand: band.Band, riskScore: riskScore}] = row
+			}
+		}
+	}
+	return grid, nil
+}
+
+// row returns the precomputed row for a rules version, policy type, age and
+// risk score. A nil cache has no rows.
+func (c *PremiumCache) row(rulesVersion, policyType string, age, riskScore int) (*premiumRow, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	row, ok := c.grids[rulesVersion][premiumKey{policyType: policyType, ageBand: repository.AgeBand(age), riskScore: riskScore}]
+	c.mu.RUnlock()
+	return row, ok
+}
+
+// record counts a quote priced from the cache or from the rules. A nil
+// cache counts nothing.
+func (c *PremiumCache) record(hit bool) {
+	if c == nil {
+		return
+	}
+	if hit {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+}
+
+// WritePrometheus writes cache lookups for GET /metrics in the Prometheus
+// text exposition format
+func (c *PremiumCache) WritePrometheus(w io.Writer) {
+	fmt.Fprint(w, "# HELP pricing_premium_cache_lookups_total Quotes priced from precomputed premiums (hit) or from the rules (miss).\n")
+	fmt.Fprint(w, "# TYPE pricing_premium_cache_lookups_total counter\n")
+	fmt.Fprintf(w, "pricing_premium_cache_lookups_total{result=\"hit\"} %d\n", c.hits.Load())
+	fmt.Fprintf(w, "pricing_premium_cache_lookups_total{result=\"miss\"} %d\n", c.misses.Load())
+}
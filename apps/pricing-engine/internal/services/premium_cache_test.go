@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+const cacheTestRules = `{
+  "baseRates": {
+    "auto": {
+      "base": 800,
+      "coverage": {"250000": 1.0, "500000": 1.35},
+      "ageMultiplier": {"18-24": 1.8, "25-34": 1.2, "35-49": 1.0, "50-64": 0.9, "65+": 1.1},
+      "riskMultiplier": {"1": 0.8, "2": 0.9, "3": 1.0, "4": 1.3, "5": 1.7},
+      "territory": {"default": 1.0, "states": {"CA": 1.2}}
+    },
+    "home": {
+      "base": 1200,
+      "coverage": {"500000": 1.0, "750000": 1.25},
+      "ageMultiplier": {"18-34": 1.1, "35-49": 1.0, "50-64": 0.95, "65+": 1.05},
+      "riskMultiplier": {"1": 0.85, "3": 1.0, "5": 1.5}
+    }
+  },
+  "discounts": {"lowRisk": 0.05, "loyaltyYears": {"3": 0.05}},
+  "metadata": {"version": "cache-test"}
+}`
+
+func newCacheTestRepository(t *testing.T, rules string) (*repository.Repository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeRules(t, dir, rules)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	repo, err := repository.NewRepository(dir, logger)
+	if err != nil {
+		t.Fatalf("NewRepository failed: %v", err)
+	}
+	return repo, dir
+}
+
+func writeRules(t *testing.T, dir, rules string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "pricing-rules.json"), []byte(rules), 0o644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+}
+
+func TestPremiumCacheMatchesRules(t *testing.T) {
+	repo, _ := newCacheTestRepository(t, cacheTestRules)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cache := NewPremiumCache(repo, logger)
+	cache.Refresh()
+
+	uncached := NewPricingService(repo, nil, nil, nil, QuoteValidity{}, nil, nil, nil, nil, nil, logger)
+	cached := NewPricingService(repo, nil, nil, nil, QuoteValidity{}, nil, nil, nil, cache, nil, logger)
+
+	requests := 0
+	for _, policyType := range []string{"auto", "home"} {
+		// 300000 and 1000000 are not coverage tiers and are looked up as before
+		for _, coverage := range []int{250000, 300000, 500000, 750000, 1000000} {
+			for _, age := range []int{18, 24, 30, 45, 64, 90} {
+				for riskScore := 1; riskScore <= 5; riskScore++ {
+					req := models.QuoteRequest{PolicyType: policyType, CoverageAmount: coverage, CustomerAge: age, RiskScore: riskScore, State: "CA", LoyaltyYears: 3}
+					want, err := uncached.CalculateQuote(context.Background(), &req)
+					if err != nil {
+						t.Fatalf("CalculateQuote failed: %v", err)
+					}
+					got, err := cached.CalculateQuote(context.Background(), &req)
+					if err != nil {
+						t.Fatalf("Cached CalculateQuote failed: %v", err)
+					}
+					if got.FinalPremium != want.FinalPremium || got.BaseRate != want.BaseRate || !reflect.DeepEqual(got.Factors, want.Factors) {
+						t.Errorf("%+v: cached quote %+v differs from %+v", req, got.Factors, want.Factors)
+					}
+					requests++
+				}
+			}
+		}
+	}
+
+	// Two tiers per policy type hit for every age and risk score
+	if hits, misses := cache.hits.Load(), cache.misses.Load(); hits != 2*2*6*5 || hits+misses != uint64(requests) {
+		t.Errorf("Got %d hits and %d misses over %d quotes", hits, misses, requests)
+	}
+
+	var metrics strings.Builder
+	cache.WritePrometheus(&metrics)
+	if !strings.Contains(metrics.String(), `pricing_premium_cache_lookups_total{result="hit"} 120`) {
+		t.Errorf("Unexpected metrics:\n%s", metrics.String())
+	}
+}
+
+func TestPremiumCacheRefreshesWithReloadedRules(t *testing.T) {
+	repo, dir := newCacheTestRepository(t, cacheTestRules)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cache := NewPremiumCache(repo, logger)
+	cache.Refresh()
+	service := NewPricingService(repo, nil, nil, nil, QuoteValidity{}, nil, nil, nil, cache, nil, logger)
+
+	req := models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 3}
+	before, err := service.CalculateQuote(context.Background(), &req)
+	if err != nil || before.BaseRate != 800 {
+		t.Fatalf("Expected a base premium of 800, got %+v (%v)", before, err)
+	}
+
+	writeRules(t, dir, strings.Replace(cacheTestRules, `"base": 800`, `"base": 900`, 1))
+	if err := repo.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	cache.Refresh()
+
+	after, err := service.CalculateQuote(context.Background(), &req)
+	if err != nil || after.BaseRate != 900 {
+		t.Errorf("Expected the reloaded base premium of 900, got %+v (%v)", after, err)
+	}
+}
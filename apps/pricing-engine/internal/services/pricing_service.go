@@ -1,114 +1,516 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/clients"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/features"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
 	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
-	"github.com/google/uuid"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/telematics"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
 	"github.com/sirupsen/logrus"
 )
 
+// ConsentLookup reports whether a customer has consented to paperless
+// billing. *clients.CustomerClient is the production implementation.
+type ConsentLookup interface {
+	PaperlessConsent(ctx context.Context, customerID string) (bool, error)
+}
+
+var _ ConsentLookup = (*clients.CustomerClient)(nil)
+
+// HouseholdPolicyLookup reports whether anyone in a customer's household
+// already holds an active policy. *clients.PolicyClient is the production
+// implementation.
+type HouseholdPolicyLookup interface {
+	HouseholdHasActivePolicy(ctx context.Context, customerID string) (bool, error)
+}
+
+var _ HouseholdPolicyLookup = (*clients.PolicyClient)(nil)
+
+// Underwriting is the business rules a quote request must pass before it
+// is priced. *rules.Engine is the production implementation.
+type Underwriting interface {
+	First(facts rules.Context) (rules.Match, bool)
+}
+
+var _ Underwriting = (*rules.Engine)(nil)
+
 // PricingService handles pricing calculations
 type PricingService struct {
-	repo   *repository.Repository
-	flags  *features.Flags
-	logger *logrus.Logger
+	repo         repository.PricingStore
+	flags        *features.Flags
+	quotes       *QuoteHistoryService
+	ids          *QuoteIDs
+	validity     QuoteValidity
+	telematics   telematics.Provider
+	consent      ConsentLookup
+	policies     HouseholdPolicyLookup
+	premiums     *PremiumCache
+	underwriting Underwriting
+	outcomes     *quoteOutcomes
+	logger       *logrus.Logger
 }
 
-// NewPricingService creates a new pricing service
-func NewPricingService(repo *repository.Repository, flags *features.Flags, logger *logrus.Logger) *PricingService {
+// NewPricingService creates a new pricing service. Every quote issued gets
+// an ID from ids, stays valid for its policy type's validity and is
+// recorded in the quote history, and auto quotes get a usage-based discount
+// from the telematics provider's driving score. The paperless billing
+// discount follows the customer's recorded consent, and the multi-policy
+// discount the policies held across the customer's household; without the
+// matching lookup each falls back to the request's paperlessBill or
+// multiPolicy. Base premiums come from premiums when it holds them.
+// Requests knocked out by the knockout rules of the pricing rules, or
+// declined by a quote rule of underwriting, are not priced.
+// Quotes, provider, consent, policies, premiums and underwriting may be nil,
+// and a nil ids numbers quotes in memory.
+func NewPricingService(repo repository.PricingStore, flags *features.Flags, quotes *QuoteHistoryService, ids *QuoteIDs, validity QuoteValidity, provider telematics.Provider, consent ConsentLookup, policies HouseholdPolicyLookup, premiums *PremiumCache, underwriting Underwriting, logger *logrus.Logger) *PricingService {
+	if ids == nil {
+		ids = NewQuoteIDs(nil)
+	}
 	return &PricingService{
-		repo:   repo,
-		flags:  flags,
-		logger: logger,
+		repo:         repo,
+		flags:        flags,
+		quotes:       quotes,
+		ids:          ids,
+		validity:     validity,
+		telematics:   provider,
+		consent:      consent,
+		policies:     policies,
+		premiums:     premiums,
+		underwriting: underwriting,
+		outcomes:     newQuoteOutcomes(),
+		logger:       logger,
 	}
 }
 
 // CalculateQuote calculates an insurance quote based on the request
-func (s *PricingService) CalculateQuote(req *models.QuoteRequest) (*models.Quote, error) {
-	// Validate request
-	if err := s.validateRequest(req); err != nil {
+func (s *PricingService) CalculateQuote(ctx context.Context, req *models.QuoteRequest) (*models.Quote, error) {
+	quote, factors, err := s.price(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.quotes.RecordQuote(req, quote)
+
+	s.logger.WithFields(logrus.Fields{
+		"quoteId":      quote.QuoteID,
+		"policyType":   req.PolicyType,
+		"finalPremium": quote.FinalPremium,
+		"dynamicRates": s.flags.IsDynamicRatesEnabled(),
+		"rulesVersion": factors.rulesVersion,
+	}).Info("Quote calculated")
+
+	return quote, nil
+}
+
+// CompareQuotes prices the base request at each coverage amount. The base
+// rate, age, risk and dynamic factors do not depend on coverage, so they are
+// looked up once and shared by every quote.
+func (s *PricingService) CompareQuotes(ctx context.Context, req *models.QuoteComparisonRequest) (*models.QuoteComparison, error) {
+	if err := s.validateComparison(req); err != nil {
 		return nil, err
 	}
 
-	// Get base rate
-	baseRate, err := s.repo.GetBaseRateForPolicy(req.PolicyType)
+	base := req.Base
+	base.CoverageAmount = req.CoverageAmounts[0]
+	normalizeLocation(&base)
+	if err := s.validateRequest(&base); err != nil {
+		return nil, err
+	}
+	// Every level must pass underwriting before any is quoted
+	for _, coverageAmount := range req.CoverageAmounts {
+		level := base
+		level.CoverageAmount = coverageAmount
+		if err := s.underwrite(&level); err != nil {
+			return nil, err
+		}
+	}
+
+	factors, err := s.customerFactors(ctx, &base, req.CoverageAmounts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get base rate: %w", err)
+		return nil, err
 	}
 
-	// Get coverage multiplier
-	coverageMultiplier, err := s.repo.GetCoverageMultiplier(req.PolicyType, req.CoverageAmount)
+	comparison := &models.QuoteComparison{
+		PolicyType: base.PolicyType,
+		CustomerID: base.CustomerID,
+		AgentID:    base.AgentID,
+		Quotes:     make([]*models.Quote, 0, len(req.CoverageAmounts)),
+		CreatedAt:  time.Now(),
+	}
+	for _, coverageAmount := range req.CoverageAmounts {
+		quote, err := s.quoteAtCoverage(&base, factors, coverageAmount)
+		if err != nil {
+			return nil, err
+		}
+		level := base
+		level.CoverageAmount = coverageAmount
+		s.quotes.RecordQuote(&level, quote)
+		comparison.Quotes = append(comparison.Quotes, quote)
+	}
+	s.outcomes.quoted(base.PolicyType)
+
+	s.logger.WithFields(logrus.Fields{
+		"policyType":   base.PolicyType,
+		"levels":       len(comparison.Quotes),
+		"dynamicRates": s.flags.IsDynamicRatesEnabled(),
+		"rulesVersion": factors.rulesVersion,
+	}).Info("Quote comparison calculated")
+
+	return comparison, nil
+}
+
+// price validates and underwrites a quote request and prices it at its
+// coverage amount
+func (s *PricingService) price(ctx context.Context, req *models.QuoteRequest) (*models.Quote, *customerFactors, error) {
+	normalizeLocation(req)
+	if err := s.validateRequest(req); err != nil {
+		return nil, nil, err
+	}
+	if err := s.underwrite(req); err != nil {
+		return nil, nil, err
+	}
+
+	factors, err := s.customerFactors(ctx, req, []int{req.CoverageAmount})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get coverage multiplier: %w", err)
+		return nil, nil, err
 	}
 
-	// Get age multiplier
-	ageMultiplier, err := s.repo.GetAgeMultiplier(req.PolicyType, req.CustomerAge)
+	quote, err := s.quoteAtCoverage(req, factors, req.CoverageAmount)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get age multiplier: %w", err)
+		return nil, nil, err
+	}
+	s.outcomes.quoted(req.PolicyType)
+	return quote, factors, nil
+}
+
+// customerFactors holds the pricing factors that depend on the customer and
+// policy type but not on the coverage amount, and the rules they came from
+type customerFactors struct {
+	store             repository.PricingStore
+	rulesVersion      string
+	experiment        *models.ExperimentAssignment
+	baseRate          float64
+	ageMultiplier     float64
+	riskMultiplier    float64
+	territoryFactor   float64
+	territory         string
+	vehicleFactor     float64
+	propertyFactor    float64
+	dynamicMultiplier float64
+	// premiums is the precomputed row for the customer's policy type, age
+	// band and risk score, nil when the cache does not hold it
+	premiums *premiumRow
+	// drivingScore is set when the telematics provider has scored the
+	// customer; telematicsDiscount is the share of premium it takes off
+	drivingScore       *int
+	telematicsDiscount float64
+	// paperlessDiscount is the share of premium taken off for paperless
+	// billing
+	paperlessDiscount float64
+	// multiPolicyDiscount is the share of premium taken off because the
+	// household already holds a policy
+	multiPolicyDiscount float64
+}
+
+// customerFactors picks the rules version for the customer and looks up the
+// coverage-independent factors for a request, unless the version's knockout
+// rules decline it at one of coverageAmounts
+func (s *PricingService) customerFactors(ctx context.Context, req *models.QuoteRequest, coverageAmounts []int) (*customerFactors, error) {
+	store, rulesVersion, experiment := s.rulesFor(req.CustomerID)
+	if err := s.knockout(store.GetPricingRules(), req, coverageAmounts); err != nil {
+		return nil, err
+	}
+
+	// Base rate, age and risk multipliers come precomputed when the cache
+	// holds this rules version
+	row, cached := s.premiums.row(rulesVersion, req.PolicyType, req.CustomerAge, req.RiskScore)
+	baseRate, ageMultiplier, riskMultiplier := 0.0, 0.0, 0.0
+	if cached {
+		baseRate, ageMultiplier, riskMultiplier = row.baseRate, row.ageMultiplier, row.riskMultiplier
+	} else {
+		var err error
+
+		// Get base rate
+		baseRate, err = store.GetBaseRateForPolicy(req.PolicyType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get base rate: %w", err)
+		}
+
+		// Get age multiplier
+		ageMultiplier, err = store.GetAgeMultiplier(req.PolicyType, req.CustomerAge)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get age multiplier: %w", err)
+		}
+
+		// Get risk multiplier
+		riskMultiplier, err = store.GetRiskMultiplier(req.PolicyType, req.RiskScore)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get risk multiplier: %w", err)
+		}
 	}
 
-	// Get risk multiplier
-	riskMultiplier, err := s.repo.GetRiskMultiplier(req.PolicyType, req.RiskScore)
+	// Get territory multiplier
+	territoryFactor, territory, err := store.GetTerritoryMultiplier(req.PolicyType, req.State, req.ZipCode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get risk multiplier: %w", err)
+		return nil, fmt.Errorf("failed to get territory multiplier: %w", err)
 	}
 
-	// Calculate base premium
-	basePremium := baseRate * coverageMultiplier * ageMultiplier * riskMultiplier
+	// Get vehicle and property multipliers
+	vehicleFactor, propertyFactor := 1.0, 1.0
+	currentYear := time.Now().Year()
+	if vehicle := req.Vehicle; vehicle != nil {
+		vehicleFactor, err = store.GetVehicleMultiplier(req.PolicyType, max(currentYear-vehicle.Year, 0), vehicle.AnnualMileage, vehicle.Make)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get vehicle multiplier: %w", err)
+		}
+	}
+	if property := req.Property; property != nil {
+		propertyFactor, err = store.GetPropertyMultiplier(req.PolicyType, currentYear-property.YearBuilt, property.ConstructionType, property.ProtectionClass)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get property multiplier: %w", err)
+		}
+	}
 
 	// Apply dynamic pricing if enabled
 	dynamicMultiplier := 1.0
 	if s.flags.IsDynamicRatesEnabled() {
-		dynamicMultiplier = s.calculateDynamicMultiplier(req)
+		dynamicMultiplier = s.calculateDynamicMultiplier(store, req)
+	}
+
+	drivingScore, telematicsDiscount := s.telematicsDiscount(ctx, store, req)
+
+	return &customerFactors{
+		store:             store,
+		rulesVersion:      rulesVersion,
+		experiment:        experiment,
+		baseRate:          baseRate,
+		ageMultiplier:     ageMultiplier,
+		riskMultiplier:    riskMultiplier,
+		territoryFactor:   territoryFactor,
+		territory:         territory,
+		vehicleFactor:     vehicleFactor,
+		propertyFactor:    propertyFactor,
+		dynamicMultiplier: dynamicMultiplier,
+		premiums:          row,
+
+		drivingScore:       drivingScore,
+		telematicsDiscount: telematicsDiscount,
+
+		paperlessDiscount:   s.paperlessDiscount(ctx, store, req),
+		multiPolicyDiscount: s.multiPolicyDiscount(ctx, store, req),
+	}, nil
+}
+
+// multiPolicyDiscount returns the multi-policy discount rate for a quote.
+// With a policy lookup the discount needs a customer ID whose household,
+// the customer included, already holds an active policy, and
+// req.MultiPolicy is ignored; lookup failures are logged and the quote is
+// priced without the discount.
+func (s *PricingService) multiPolicyDiscount(ctx context.Context, store repository.PricingStore, req *models.QuoteRequest) float64 {
+	discounts := store.GetDiscounts()
+	if discounts == nil {
+		return 0
+	}
+	if s.policies == nil {
+		if req.MultiPolicy {
+			return discounts.MultiPolicy
+		}
+		return 0
+	}
+	if req.CustomerID == "" {
+		return 0
+	}
+
+	held, err := s.policies.HouseholdHasActivePolicy(ctx, req.CustomerID)
+	if err != nil {
+		s.logger.WithError(err).WithField("customerId", req.CustomerID).Warn("Failed to get household policies, quoting without multi-policy discount")
+		return 0
+	}
+	if !held {
+		if req.MultiPolicy {
+			s.logger.WithField("customerId", req.CustomerID).Info("Multi-policy discount requested without an active household policy")
+		}
+		return 0
+	}
+	return discounts.MultiPolicy
+}
+
+// paperlessDiscount returns the paperless billing discount rate for a quote.
+// With a consent lookup the discount needs a customer ID with consent on
+// record, and req.PaperlessBill is ignored; lookup failures are logged and
+// the quote is priced without the discount.
+func (s *PricingService) paperlessDiscount(ctx context.Context, store repository.PricingStore, req *models.QuoteRequest) float64 {
+	discounts := store.GetDiscounts()
+	if discounts == nil {
+		return 0
+	}
+	if s.consent == nil {
+		if req.PaperlessBill {
+			return discounts.PaperlessBilling
+		}
+		return 0
+	}
+	if req.CustomerID == "" {
+		return 0
+	}
+
+	consented, err := s.consent.PaperlessConsent(ctx, req.CustomerID)
+	if err != nil {
+		s.logger.WithError(err).WithField("customerId", req.CustomerID).Warn("Failed to get paperless consent, quoting without paperless discount")
+		return 0
+	}
+	if !consented {
+		if req.PaperlessBill {
+			s.logger.WithField("customerId", req.CustomerID).Info("Paperless discount requested without recorded consent")
+		}
+		return 0
+	}
+	return discounts.PaperlessBilling
+}
+
+// telematicsDiscount returns the customer's driving score and the usage-based
+// discount rate it earns on an auto quote. Without a provider, customer ID or
+// score it returns nil and 0. Provider failures are logged and the quote is
+// priced without the discount.
+func (s *PricingService) telematicsDiscount(ctx context.Context, store repository.PricingStore, req *models.QuoteRequest) (*int, float64) {
+	if s.telematics == nil || req.PolicyType != "auto" || req.CustomerID == "" {
+		return nil, 0
+	}
+
+	score, err := s.telematics.DrivingScore(ctx, req.CustomerID)
+	if err != nil {
+		s.logger.WithError(err).WithField("customerId", req.CustomerID).Warn("Failed to get driving score, quoting without telematics discount")
+		return nil, 0
+	}
+	if score == nil {
+		return nil, 0
+	}
+
+	drivingScore := score.DrivingScore
+	discounts := store.GetDiscounts()
+	if discounts == nil {
+		return &drivingScore, 0
+	}
+	rate, _ := models.BandValue(discounts.Telematics, drivingScore)
+	return &drivingScore, rate
+}
+
+// rulesFor returns the rules a customer is quoted from. Outside a rate
+// experiment, or without a customer ID, that is the current version and the
+// assignment is nil. A variant whose rules version is not loaded falls back
+// to the current version and is reported as control.
+func (s *PricingService) rulesFor(customerID string) (repository.PricingStore, string, *models.ExperimentAssignment) {
+	current := s.repo.Versions()[0]
+
+	experiment, variant := s.flags.AssignRateExperiment(customerID)
+	if experiment == nil {
+		return s.repo, current, nil
+	}
+
+	assignment := &models.ExperimentAssignment{
+		ExperimentID: experiment.ID,
+		Variant:      models.VariantControl,
+		RulesVersion: current,
+	}
+	if variant == nil {
+		return s.repo, current, assignment
+	}
+
+	store, err := s.repo.ForVersion(variant.RulesVersion)
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"experimentId": experiment.ID,
+			"variant":      variant.Name,
+		}).Warn("Experiment rules version unavailable, quoting from current rules")
+		return s.repo, current, assignment
 	}
 
-	adjustedRate := basePremium * dynamicMultiplier
+	assignment.Variant = variant.Name
+	assignment.RulesVersion = variant.RulesVersion
+	return store, variant.RulesVersion, assignment
+}
+
+// quoteAtCoverage builds a quote for req at the given coverage amount from
+// factors already looked up for the customer
+func (s *PricingService) quoteAtCoverage(req *models.QuoteRequest, factors *customerFactors, coverageAmount int) (*models.Quote, error) {
+	// Get coverage multiplier and the premium before location, vehicle and
+	// property, precomputed for the rules' coverage tiers
+	cell, cached := factors.premiums.cell(coverageAmount)
+	s.premiums.record(cached)
+	coverageMultiplier, premium := cell.coverageMultiplier, cell.basePremium
+	if !cached {
+		var err error
+		coverageMultiplier, err = factors.store.GetCoverageMultiplier(req.PolicyType, coverageAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get coverage multiplier: %w", err)
+		}
+		premium = factors.baseRate * coverageMultiplier * factors.ageMultiplier * factors.riskMultiplier
+	}
+
+	// Calculate base premium
+	basePremium := premium * factors.territoryFactor * factors.vehicleFactor * factors.propertyFactor
+
+	adjustedRate := basePremium * factors.dynamicMultiplier
 
 	// Calculate discounts
-	discount := s.calculateDiscount(req, adjustedRate)
+	discount := s.calculateDiscount(factors, req, adjustedRate)
 
 	// Calculate final premium
 	finalPremium := adjustedRate - discount
 
+	quoteID, err := s.ids.Next()
+	if err != nil {
+		return nil, err
+	}
+
 	// Create quote
-	quote := &models.Quote{
-		QuoteID:        generateQuoteID(),
+	var experiment *models.ExperimentAssignment
+	if factors.experiment != nil {
+		assignment := *factors.experiment
+		experiment = &assignment
+	}
+	now := time.Now()
+	return &models.Quote{
+		QuoteID:        quoteID,
 		PolicyType:     req.PolicyType,
-		CoverageAmount: req.CoverageAmount,
+		CoverageAmount: coverageAmount,
+		AgentID:        req.AgentID,
 		BaseRate:       basePremium,
 		AdjustedRate:   adjustedRate,
 		Discount:       discount,
 		FinalPremium:   finalPremium,
-		ValidUntil:     time.Now().Add(30 * 24 * time.Hour), // Valid for 30 days
-		CreatedAt:      time.Now(),
+		ValidUntil:     now.Add(s.validity.For(req.PolicyType)),
+		CreatedAt:      now,
+		RulesVersion:   factors.rulesVersion,
 		Factors: &models.Factors{
-			BaseMultiplier:     baseRate,
-			CoverageMultiplier: coverageMultiplier,
-			AgeMultiplier:      ageMultiplier,
-			RiskMultiplier:     riskMultiplier,
-			DynamicMultiplier:  dynamicMultiplier,
-			DiscountAmount:     discount,
+			BaseMultiplier:      factors.baseRate,
+			CoverageMultiplier:  coverageMultiplier,
+			AgeMultiplier:       factors.ageMultiplier,
+			RiskMultiplier:      factors.riskMultiplier,
+			DynamicMultiplier:   factors.dynamicMultiplier,
+			TerritoryMultiplier: factors.territoryFactor,
+			Territory:           factors.territory,
+			VehicleMultiplier:   factors.vehicleFactor,
+			PropertyMultiplier:  factors.propertyFactor,
+			DrivingScore:        factors.drivingScore,
+			TelematicsDiscount:  factors.telematicsDiscount,
+			PaperlessDiscount:   factors.paperlessDiscount,
+			MultiPolicyDiscount: factors.multiPolicyDiscount,
+			DiscountAmount:      discount,
 		},
-	}
-
-	s.logger.WithFields(logrus.Fields{
-		"quoteId":      quote.QuoteID,
-		"policyType":   req.PolicyType,
-		"finalPremium": quote.FinalPremium,
-		"dynamicRates": s.flags.IsDynamicRatesEnabled(),
-	}).Info("Quote calculated")
-
-	return quote, nil
+		Experiment: experiment,
+	}, nil
 }
 
 // calculateDynamicMultiplier calculates dynamic pricing adjustments
-func (s *PricingService) calculateDynamicMultiplier(req *models.QuoteRequest) float64 {
-	dynamicPricing := s.repo.GetDynamicPricing()
+func (s *PricingService) calculateDynamicMultiplier(store repository.PricingStore, req *models.QuoteRequest) float64 {
+	dynamicPricing := store.GetDynamicPricing()
 	if dynamicPricing == nil || !dynamicPricing.Enabled {
 		return 1.0
 	}
@@ -140,18 +542,16 @@ func (s *PricingService) calculateDynamicMultiplier(req *models.QuoteRequest) fl
 }
 
 // calculateDiscount calculates the total discount based on request parameters
-func (s *PricingService) calculateDiscount(req *models.QuoteRequest, adjustedRate float64) float64 {
-	discounts := s.repo.GetDiscounts()
+func (s *PricingService) calculateDiscount(factors *customerFactors, req *models.QuoteRequest, adjustedRate float64) float64 {
+	discounts := factors.store.GetDiscounts()
 	if discounts == nil {
 		return 0
 	}
 
 	totalDiscount := 0.0
 
-	// Multi-policy discount
-	if req.MultiPolicy {
-		totalDiscount += adjustedRate * discounts.MultiPolicy
-	}
+	// Multi-policy discount, checked against the household's policies
+	totalDiscount += adjustedRate * factors.multiPolicyDiscount
 
 	// Loyalty discount
 	if req.LoyaltyYears > 0 {
@@ -164,17 +564,19 @@ func (s *PricingService) calculateDiscount(req *models.QuoteRequest, adjustedRat
 		totalDiscount += adjustedRate * discounts.LowRisk
 	}
 
-	// Paperless billing discount
-	if req.PaperlessBill {
-		totalDiscount += adjustedRate * discounts.PaperlessBilling
-	}
+	// Paperless billing discount, checked against recorded consent
+	totalDiscount += adjustedRate * factors.paperlessDiscount
+
+	// Usage-based (telematics) discount
+	totalDiscount += adjustedRate * factors.telematicsDiscount
 
 	s.logger.WithFields(logrus.Fields{
-		"multiPolicy":   req.MultiPolicy,
-		"loyaltyYears":  req.LoyaltyYears,
-		"paperlessBill": req.PaperlessBill,
-		"riskScore":     req.RiskScore,
-		"totalDiscount": totalDiscount,
+		"multiPolicyDiscount": factors.multiPolicyDiscount,
+		"loyaltyYears":        req.LoyaltyYears,
+		"paperlessDiscount":   factors.paperlessDiscount,
+		"riskScore":           req.RiskScore,
+		"telematicsDiscount":  factors.telematicsDiscount,
+		"totalDiscount":       totalDiscount,
 	}).Debug("Discount calculated")
 
 	return totalDiscount
@@ -207,6 +609,45 @@ func (s *PricingService) GetRates() *models.RatesResponse {
 	}
 }
 
+// underwrite refuses a request declined by a quote rule
+func (s *PricingService) underwrite(req *models.QuoteRequest) error {
+	if s.underwriting == nil {
+		return nil
+	}
+
+	facts := rules.QuoteContext{
+		PolicyType:     req.PolicyType,
+		CoverageAmount: req.CoverageAmount,
+		CustomerAge:    req.CustomerAge,
+		RiskScore:      req.RiskScore,
+		ClaimsHistory:  req.ClaimsHistory,
+		LoyaltyYears:   req.LoyaltyYears,
+		State:          req.State,
+		ZipCode:        req.ZipCode,
+	}
+	if req.Vehicle != nil {
+		facts.VehicleYear = req.Vehicle.Year
+		facts.AnnualMileage = req.Vehicle.AnnualMileage
+	}
+	if req.Property != nil {
+		facts.YearBuilt = req.Property.YearBuilt
+		facts.ConstructionType = req.Property.ConstructionType
+		facts.ProtectionClass = req.Property.ProtectionClass
+	}
+	match, declined := s.underwriting.First(facts)
+	if !declined {
+		return nil
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"policyType":     req.PolicyType,
+		"coverageAmount": req.CoverageAmount,
+		"rule":           match.Rule,
+	}).Info("Quote declined by underwriting rule")
+	s.outcomes.declined(req.PolicyType)
+	return fmt.Errorf("quote declined: %s", match.Reason)
+}
+
 // validateRequest validates the quote request
 func (s *PricingService) validateRequest(req *models.QuoteRequest) error {
 	if req.PolicyType != "auto" && req.PolicyType != "home" && req.PolicyType != "life" {
@@ -225,15 +666,127 @@ func (s *PricingService) validateRequest(req *models.QuoteRequest) error {
 		return fmt.Errorf("risk score must be between 1 and 5")
 	}
 
+	if req.ZipCode != "" && req.State == "" {
+		return fmt.Errorf("state is required when zip code is given")
+	}
+
+	if req.State != "" && !usStates[req.State] {
+		return fmt.Errorf("unknown territory: state %s", req.State)
+	}
+
+	if req.ZipCode != "" && !zipCodePattern.MatchString(req.ZipCode) {
+		return fmt.Errorf("zip code must be 5 digits or ZIP+4")
+	}
+
+	if req.Vehicle != nil {
+		if req.PolicyType != "auto" {
+			return fmt.Errorf("vehicle details only apply to auto policies")
+		}
+		if err := validateVehicle(req.Vehicle); err != nil {
+			return err
+		}
+	}
+
+	if req.Property != nil {
+		if req.PolicyType != "home" {
+			return fmt.Errorf("property details only apply to home policies")
+		}
+		if err := validateProperty(req.Property); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// Helper functions
+// validateVehicle validates the vehicle on an auto quote
+func validateVehicle(vehicle *models.VehicleInfo) error {
+	if vehicle.Year < 1900 || vehicle.Year > time.Now().Year()+1 {
+		return fmt.Errorf("vehicle year must be between 1900 and next year")
+	}
+
+	if strings.TrimSpace(vehicle.Make) == "" || strings.TrimSpace(vehicle.Model) == "" {
+		return fmt.Errorf("vehicle make and model are required")
+	}
 
-func generateQuoteID() string {
-	return "Q-" + uuid.New().String()[:8]
+	if vehicle.AnnualMileage < 0 || vehicle.AnnualMileage > maxAnnualMileage {
+		return fmt.Errorf("annual mileage must be between 0 and %d", maxAnnualMileage)
+	}
+
+	return nil
 }
 
+// validateProperty validates the dwelling on a home quote
+func validateProperty(property *models.PropertyInfo) error {
+	if property.YearBuilt < 1700 || property.YearBuilt > time.Now().Year() {
+		return fmt.Errorf("year built must be between 1700 and this year")
+	}
+
+	switch property.ConstructionType {
+	case models.ConstructionFireResistive, models.ConstructionMasonry, models.ConstructionJoistedMasonry, models.ConstructionFrame:
+	default:
+		return fmt.Errorf("construction type must be fire_resistive, masonry, joisted_masonry, or frame")
+	}
+
+	if property.ProtectionClass < 1 || property.ProtectionClass > 10 {
+		return fmt.Errorf("protection class must be between 1 and 10")
+	}
+
+	return nil
+}
+
+// maxAnnualMileage is the highest annual mileage an auto quote may declare
+const maxAnnualMileage = 150000
+
+// normalizeLocation trims the request's state and ZIP code and upper-cases
+// the state so rules lookups match
+func normalizeLocation(req *models.QuoteRequest) {
+	req.State = strings.ToUpper(strings.TrimSpace(req.State))
+	req.ZipCode = strings.TrimSpace(req.ZipCode)
+}
+
+// zipCodePattern matches five-digit ZIP and ZIP+4 codes
+var zipCodePattern = regexp.MustCompile(`^[0-9]{5}(-[0-9]{4})?$`)
+
+// usStates are the territories that can be rated: the 50 states, DC and
+// Puerto Rico
+var usStates = map[string]bool{
+	"AL": true, "AK": true, "AZ": true, "AR": true, "CA": true, "CO": true, "CT": true, "DE": true,
+	"DC": true, "FL": true, "GA": true, "HI": true, "ID": true, "IL": true, "IN": true, "IA": true,
+	"KS": true, "KY": true, "LA": true, "ME": true, "MD": true, "MA": true, "MI": true, "MN": true,
+	"MS": true, "MO": true, "MT": true, "NE": true, "NV": true, "NH": true, "NJ": true, "NM": true,
+	"NY": true, "NC": true, "ND": true, "OH": true, "OK": true, "OR": true, "PA": true, "PR": true,
+	"RI": true, "SC": true, "SD": true, "TN": true, "TX": true, "UT": true, "VT": true, "VA": true,
+	"WA": true, "WV": true, "WI": true, "WY": true,
+}
+
+// validateComparison validates the coverage amounts of a comparison request.
+// The rest of the base request is validated like a single quote.
+func (s *PricingService) validateComparison(req *models.QuoteComparisonRequest) error {
+	if len(req.CoverageAmounts) == 0 {
+		return fmt.Errorf("at least one coverage amount is required")
+	}
+
+	if len(req.CoverageAmounts) > models.MaxComparisonLevels {
+		return fmt.Errorf("at most %d coverage amounts can be compared", models.MaxComparisonLevels)
+	}
+
+	seen := make(map[int]bool, len(req.CoverageAmounts))
+	for _, coverageAmount := range req.CoverageAmounts {
+		if coverageAmount <= 0 {
+			return fmt.Errorf("coverage amount must be greater than 0")
+		}
+		if seen[coverageAmount] {
+			return fmt.Errorf("coverage amount %d is listed more than once", coverageAmount)
+		}
+		seen[coverageAmount] = true
+	}
+
+	return nil
+}
+
+// Helper functions
+
 func getCurrentQuarter() string {
 	month := time.Now().Month()
 	switch {
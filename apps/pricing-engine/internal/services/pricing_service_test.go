@@ -0,0 +1,461 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository/repositorytest"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/telematics"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/rules"
+	"github.com/sirupsen/logrus"
+)
+
+func newTestService(store *repositorytest.FakeStore) *PricingService {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewPricingService(store, nil, nil, nil, QuoteValidity{}, nil, nil, nil, nil, nil, logger)
+}
+
+func TestCalculateQuoteAppliesFactorsAndDiscounts(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000})
+	store.CoverageMultiplier = 1.5
+	store.RiskMultiplier = 1.2
+	store.Discounts = models.Discounts{
+		MultiPolicy:      0.10,
+		LoyaltyYears:     map[string]float64{"3": 0.05, "5": 0.08},
+		PaperlessBilling: 0.02,
+	}
+	service := newTestService(store)
+
+	quote, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{
+		PolicyType:     "auto",
+		CoverageAmount: 100000,
+		CustomerAge:    40,
+		RiskScore:      3,
+		MultiPolicy:    true,
+		LoyaltyYears:   4,
+	})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+
+	// 1000 x 1.5 x 1.0 x 1.2 = 1800; 10% multi-policy + 5% loyalty = 270 off
+	if math.Abs(quote.BaseRate-1800) > 0.001 || math.Abs(quote.Discount-270) > 0.001 || math.Abs(quote.FinalPremium-1530) > 0.001 {
+		t.Errorf("Unexpected quote: base %.2f, discount %.2f, final %.2f", quote.BaseRate, quote.Discount, quote.FinalPremium)
+	}
+	if quote.Factors.DynamicMultiplier != 1.0 {
+		t.Errorf("Dynamic rates should be off without flags, got %.2f", quote.Factors.DynamicMultiplier)
+	}
+}
+
+func TestCalculateQuoteValidation(t *testing.T) {
+	service := newTestService(repositorytest.NewFakeStore(map[string]float64{"auto": 1000}))
+
+	tests := []struct {
+		name string
+		req  models.QuoteRequest
+	}{
+		{"unknown type", models.QuoteRequest{PolicyType: "boat", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1}},
+		{"no coverage", models.QuoteRequest{PolicyType: "auto", CustomerAge: 30, RiskScore: 1}},
+		{"underage", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 17, RiskScore: 1}},
+		{"risk out of range", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 6}},
+		{"zip without state", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, ZipCode: "94102"}},
+		{"unknown state", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, State: "ZZ"}},
+		{"malformed zip", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, State: "CA", ZipCode: "941"}},
+		{"vehicle on home", models.QuoteRequest{PolicyType: "home", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, Vehicle: &models.VehicleInfo{Year: 2020, Make: "Toyota", Model: "Camry"}}},
+		{"vehicle without model", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, Vehicle: &models.VehicleInfo{Year: 2020, Make: "Toyota"}}},
+		{"vehicle mileage", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, Vehicle: &models.VehicleInfo{Year: 2020, Make: "Toyota", Model: "Camry", AnnualMileage: -1}}},
+		{"property on auto", models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, Property: &models.PropertyInfo{YearBuilt: 1990, ConstructionType: "frame", ProtectionClass: 3}}},
+		{"unknown construction", models.QuoteRequest{PolicyType: "home", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, Property: &models.PropertyInfo{YearBuilt: 1990, ConstructionType: "straw", ProtectionClass: 3}}},
+		{"protection class", models.QuoteRequest{PolicyType: "home", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1, Property: &models.PropertyInfo{YearBuilt: 1990, ConstructionType: "frame", ProtectionClass: 11}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CalculateQuote(context.Background(), &tt.req); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestCalculateQuoteStoreError(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000})
+	store.Err = errors.New("rules unavailable")
+
+	_, err := newTestService(store).CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 1, CustomerAge: 30, RiskScore: 1})
+	if err == nil {
+		t.Fatal("Expected store error")
+	}
+	if !errors.Is(err, store.Err) {
+		t.Errorf("Store error not wrapped: %v", err)
+	}
+}
+
+func TestCompareQuotesPricesEachCoverageAmount(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"home": 1000})
+	store.CoverageMultipliers = map[int]float64{250000: 1.0, 400000: 1.35, 500000: 1.55}
+	store.AgeMultiplier = 1.1
+	store.Discounts = models.Discounts{MultiPolicy: 0.10}
+	service := newTestService(store)
+
+	comparison, err := service.CompareQuotes(context.Background(), &models.QuoteComparisonRequest{
+		Base: models.QuoteRequest{
+			PolicyType:  "home",
+			CustomerAge: 40,
+			RiskScore:   2,
+			AgentID:     "agent-001",
+			MultiPolicy: true,
+		},
+		CoverageAmounts: []int{250000, 400000, 500000},
+	})
+	if err != nil {
+		t.Fatalf("CompareQuotes failed: %v", err)
+	}
+	if len(comparison.Quotes) != 3 || comparison.AgentID != "agent-001" {
+		t.Fatalf("Unexpected comparison: %+v", comparison)
+	}
+
+	// 1000 x coverage x 1.1, less 10% multi-policy, in request order
+	for i, want := range []struct {
+		coverage int
+		final    float64
+	}{{250000, 990}, {400000, 1336.5}, {500000, 1534.5}} {
+		quote := comparison.Quotes[i]
+		if quote.CoverageAmount != want.coverage || math.Abs(quote.FinalPremium-want.final) > 0.001 {
+			t.Errorf("Quote %d: coverage %d, final %.2f; want %d, %.2f", i, quote.CoverageAmount, quote.FinalPremium, want.coverage, want.final)
+		}
+	}
+
+	// Each option matches the single quote for the same request
+	single, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 400000, CustomerAge: 40, RiskScore: 2, MultiPolicy: true})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	if math.Abs(single.FinalPremium-comparison.Quotes[1].FinalPremium) > 0.001 {
+		t.Errorf("Comparison and single quote disagree: %.2f vs %.2f", comparison.Quotes[1].FinalPremium, single.FinalPremium)
+	}
+}
+
+func TestCompareQuotesValidation(t *testing.T) {
+	service := newTestService(repositorytest.NewFakeStore(map[string]float64{"auto": 1000}))
+	base := models.QuoteRequest{PolicyType: "auto", CustomerAge: 30, RiskScore: 1}
+
+	tests := []struct {
+		name    string
+		base    models.QuoteRequest
+		amounts []int
+	}{
+		{"no amounts", base, nil},
+		{"too many amounts", base, []int{1, 2, 3, 4, 5, 6}},
+		{"non-positive amount", base, []int{100000, 0}},
+		{"duplicate amount", base, []int{100000, 100000}},
+		{"invalid base", models.QuoteRequest{PolicyType: "boat", CustomerAge: 30, RiskScore: 1}, []int{100000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CompareQuotes(context.Background(), &models.QuoteComparisonRequest{Base: tt.base, CoverageAmounts: tt.amounts}); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestCalculateQuoteAppliesTerritory(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"home": 1000})
+	store.TerritoryMultipliers = map[string]float64{"FL": 1.6}
+	service := newTestService(store)
+
+	quote, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 500000, CustomerAge: 40, RiskScore: 2, State: " fl ", ZipCode: "33101"})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	if math.Abs(quote.FinalPremium-1600) > 0.001 || quote.Factors.TerritoryMultiplier != 1.6 || quote.Factors.Territory != "state:FL" {
+		t.Errorf("Unexpected territory rating: final %.2f, factors %+v", quote.FinalPremium, quote.Factors)
+	}
+
+	// Unlisted states and quotes without a location use the default
+	for _, state := range []string{"OH", ""} {
+		quote, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 500000, CustomerAge: 40, RiskScore: 2, State: state})
+		if err != nil {
+			t.Fatalf("CalculateQuote failed: %v", err)
+		}
+		if quote.FinalPremium != 1000 || quote.Factors.Territory != models.TerritoryDefault {
+			t.Errorf("State %q: final %.2f, territory %s", state, quote.FinalPremium, quote.Factors.Territory)
+		}
+	}
+}
+
+func TestCalculateQuoteAppliesVehicleAndProperty(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "home": 1000})
+	store.VehicleMultiplier = 1.2
+	store.PropertyMultiplier = 0.9
+	service := newTestService(store)
+
+	auto, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{
+		PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2,
+		Vehicle: &models.VehicleInfo{Year: 2022, Make: "Tesla", Model: "Model 3", AnnualMileage: 12000},
+	})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	if math.Abs(auto.FinalPremium-1200) > 0.001 || auto.Factors.VehicleMultiplier != 1.2 || auto.Factors.PropertyMultiplier != 1.0 {
+		t.Errorf("Unexpected auto rating: final %.2f, factors %+v", auto.FinalPremium, auto.Factors)
+	}
+
+	home, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{
+		PolicyType: "home", CoverageAmount: 500000, CustomerAge: 40, RiskScore: 2,
+		Property: &models.PropertyInfo{YearBuilt: 1995, ConstructionType: models.ConstructionMasonry, ProtectionClass: 4},
+	})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	if math.Abs(home.FinalPremium-900) > 0.001 || home.Factors.PropertyMultiplier != 0.9 || home.Factors.VehicleMultiplier != 1.0 {
+		t.Errorf("Unexpected home rating: final %.2f, factors %+v", home.FinalPremium, home.Factors)
+	}
+}
+
+// stubTelematics serves fixed driving scores, failing for customerErr
+type stubTelematics struct {
+	scores      map[string]int
+	customerErr string
+}
+
+func (p *stubTelematics) DrivingScore(ctx context.Context, customerID string) (*telematics.Score, error) {
+	if customerID == p.customerErr {
+		return nil, errors.New("provider unavailable")
+	}
+	score, exists := p.scores[customerID]
+	if !exists {
+		return nil, nil
+	}
+	return &telematics.Score{CustomerID: customerID, DrivingScore: score}, nil
+}
+
+func TestCalculateQuoteAppliesTelematicsDiscount(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "home": 1000})
+	store.Discounts = models.Discounts{Telematics: map[string]float64{"90-100": 0.15, "70-89": 0.05}}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := NewPricingService(store, nil, nil, nil, QuoteValidity{}, &stubTelematics{
+		scores:      map[string]int{"cust-safe": 94, "cust-ok": 75, "cust-risky": 40},
+		customerErr: "cust-down",
+	}, nil, nil, nil, nil, logger)
+
+	quoteFor := func(policyType, customerID string) *models.Quote {
+		t.Helper()
+		quote, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: policyType, CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: customerID})
+		if err != nil {
+			t.Fatalf("CalculateQuote failed: %v", err)
+		}
+		return quote
+	}
+
+	tests := []struct {
+		customerID string
+		final      float64
+		score      int // 0 when no score is surfaced
+	}{
+		{"cust-safe", 850, 94},
+		{"cust-ok", 950, 75},
+		{"cust-risky", 1000, 40}, // scored, but below every band
+		{"cust-new", 1000, 0},    // no score yet
+		{"cust-down", 1000, 0},   // provider errors do not fail the quote
+	}
+	for _, tt := range tests {
+		quote := quoteFor("auto", tt.customerID)
+		score := 0
+		if quote.Factors.DrivingScore != nil {
+			score = *quote.Factors.DrivingScore
+		}
+		if math.Abs(quote.FinalPremium-tt.final) > 0.001 || score != tt.score {
+			t.Errorf("%s: final %.2f, driving score %d", tt.customerID, quote.FinalPremium, score)
+		}
+	}
+
+	// Telematics only rates auto quotes
+	if home := quoteFor("home", "cust-safe"); home.FinalPremium != 1000 || home.Factors.DrivingScore != nil {
+		t.Errorf("Home quote used telematics: final %.2f, factors %+v", home.FinalPremium, home.Factors)
+	}
+}
+
+// stubConsent serves fixed paperless consent, failing for customerErr
+type stubConsent struct {
+	consented   map[string]bool
+	customerErr string
+}
+
+func (c *stubConsent) PaperlessConsent(ctx context.Context, customerID string) (bool, error) {
+	if customerID == c.customerErr {
+		return false, errors.New("customer-service unavailable")
+	}
+	return c.consented[customerID], nil
+}
+
+func TestCalculateQuoteChecksPaperlessConsent(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"home": 1000})
+	store.Discounts = models.Discounts{PaperlessBilling: 0.03}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := NewPricingService(store, nil, nil, nil, QuoteValidity{}, nil, &stubConsent{
+		consented:   map[string]bool{"cust-paperless": true},
+		customerErr: "cust-down",
+	}, nil, nil, nil, logger)
+
+	tests := []struct {
+		customerID    string
+		paperlessBill bool
+		final         float64
+	}{
+		{"cust-paperless", false, 970}, // consent on record is enough
+		{"cust-paperless", true, 970},
+		{"cust-paper", true, 1000}, // asked for, but never consented
+		{"", true, 1000},           // anonymous quotes cannot be checked
+		{"cust-down", true, 1000},  // lookup failures do not fail the quote
+	}
+	for _, tt := range tests {
+		quote, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: tt.customerID, PaperlessBill: tt.paperlessBill})
+		if err != nil {
+			t.Fatalf("CalculateQuote failed: %v", err)
+		}
+		if math.Abs(quote.FinalPremium-tt.final) > 0.001 {
+			t.Errorf("%q paperlessBill=%v: final %.2f, want %.2f", tt.customerID, tt.paperlessBill, quote.FinalPremium, tt.final)
+		}
+	}
+
+	// Without a consent lookup the request is taken at its word
+	quote, err := newTestService(store).CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, PaperlessBill: true})
+	if err != nil || math.Abs(quote.FinalPremium-970) > 0.001 || quote.Factors.PaperlessDiscount != 0.03 {
+		t.Errorf("Unchecked paperless quote: %+v, %v", quote, err)
+	}
+}
+
+// stubHouseholdPolicies serves fixed household policy holdings, failing for
+// customerErr
+type stubHouseholdPolicies struct {
+	held        map[string]bool
+	customerErr string
+}
+
+func (p *stubHouseholdPolicies) HouseholdHasActivePolicy(ctx context.Context, customerID string) (bool, error) {
+	if customerID == p.customerErr {
+		return false, errors.New("policy-service unavailable")
+	}
+	return p.held[customerID], nil
+}
+
+func TestCalculateQuoteChecksHouseholdPolicies(t *testing.T) {
+	store := repositorytest.NewFakeStore(map[string]float64{"home": 1000})
+	store.Discounts = models.Discounts{MultiPolicy: 0.1}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	service := NewPricingService(store, nil, nil, nil, QuoteValidity{}, nil, nil, &stubHouseholdPolicies{
+		held:        map[string]bool{"cust-family": true},
+		customerErr: "cust-down",
+	}, nil, nil, logger)
+
+	tests := []struct {
+		customerID  string
+		multiPolicy bool
+		final       float64
+	}{
+		{"cust-family", false, 900}, // a household policy is enough
+		{"cust-family", true, 900},
+		{"cust-single", true, 1000}, // asked for, but nothing held
+		{"", true, 1000},            // anonymous quotes cannot be checked
+		{"cust-down", true, 1000},   // lookup failures do not fail the quote
+	}
+	for _, tt := range tests {
+		quote, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: tt.customerID, MultiPolicy: tt.multiPolicy})
+		if err != nil {
+			t.Fatalf("CalculateQuote failed: %v", err)
+		}
+		if math.Abs(quote.FinalPremium-tt.final) > 0.001 {
+			t.Errorf("%q multiPolicy=%v: final %.2f, want %.2f", tt.customerID, tt.multiPolicy, quote.FinalPremium, tt.final)
+		}
+	}
+
+	// Without a policy lookup the request is taken at its word
+	quote, err := newTestService(store).CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "home", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, MultiPolicy: true})
+	if err != nil || math.Abs(quote.FinalPremium-900) > 0.001 || quote.Factors.MultiPolicyDiscount != 0.1 {
+		t.Errorf("Unchecked multi-policy quote: %+v, %v", quote, err)
+	}
+}
+
+func TestQuotesDeclinedByUnderwritingRules(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	underwriting := rules.New(rules.Config{Domains: []string{rules.DomainQuote}}, logger)
+	if err := underwriting.Replace(rules.Set{Rules: []rules.Rule{{
+		Name:   "life-jumbo",
+		Domain: rules.DomainQuote,
+		When:   "policyType == 'life' && coverageAmount > 1000000",
+		Action: rules.ActionDecline,
+		Reason: "Life cover above 1,000,000 is written by referral",
+	}}}); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "life": 500})
+	service := NewPricingService(store, nil, nil, nil, QuoteValidity{}, nil, nil, nil, nil, underwriting, logger)
+
+	_, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "life", CoverageAmount: 2000000, CustomerAge: 40, RiskScore: 2})
+	if err == nil || err.Error() != "quote declined: Life cover above 1,000,000 is written by referral" {
+		t.Errorf("Expected the jumbo life quote to be declined, got %v", err)
+	}
+	if _, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 2000000, CustomerAge: 40, RiskScore: 2}); err != nil {
+		t.Errorf("Expected the auto quote through, got %v", err)
+	}
+
+	_, err = service.CompareQuotes(context.Background(), &models.QuoteComparisonRequest{
+		Base:            models.QuoteRequest{PolicyType: "life", CustomerAge: 40, RiskScore: 2},
+		CoverageAmounts: []int{500000, 2000000},
+	})
+	if err == nil || err.Error() != "quote declined: Life cover above 1,000,000 is written by referral" {
+		t.Errorf("Expected a comparison with a declined level to be declined, got %v", err)
+	}
+}
+
+func BenchmarkCalculateQuote(b *testing.B) {
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "home": 1200})
+	store.CoverageMultiplier = 1.5
+	store.TerritoryMultipliers = map[string]float64{"CA": 1.2}
+	store.VehicleMultiplier = 1.1
+	store.Discounts = models.Discounts{MultiPolicy: 0.10, LoyaltyYears: map[string]float64{"3": 0.05, "5": 0.08}}
+	service := newTestService(store)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := service.CalculateQuote(context.Background(), &models.QuoteRequest{
+			PolicyType: "auto", CoverageAmount: 100000, CustomerAge: 18 + i%60, RiskScore: 1 + i%5,
+			MultiPolicy: i%2 == 0, LoyaltyYears: i % 7, State: "CA", ZipCode: "94105",
+			Vehicle: &models.VehicleInfo{Year: 2021, Make: "Honda", Model: "Civic", AnnualMileage: 12000},
+		})
+		if err != nil {
+			b.Fatalf("CalculateQuote failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkCompareQuotes(b *testing.B) {
+	store := repositorytest.NewFakeStore(map[string]float64{"home": 1000})
+	store.CoverageMultipliers = map[int]float64{250000: 1.0, 400000: 1.35, 500000: 1.55}
+	service := newTestService(store)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := service.CompareQuotes(context.Background(), &models.QuoteComparisonRequest{
+			Base:            models.QuoteRequest{PolicyType: "home", CustomerAge: 40, RiskScore: 1 + i%5},
+			CoverageAmounts: []int{250000, 400000, 500000},
+		})
+		if err != nil {
+			b.Fatalf("CompareQuotes failed: %v", err)
+		}
+	}
+}
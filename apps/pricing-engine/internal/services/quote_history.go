@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/sirupsen/logrus"
+)
+
+// QuoteHistoryService keeps every quote issued and tracks which of them were
+// bound into policies, so quote abandonment can be measured per customer.
+// Quotes priced under an experiment are also passed to the experiment
+// service.
+type QuoteHistoryService struct {
+	repo        repository.QuoteStore
+	experiments *ExperimentService
+	logger      *logrus.Logger
+}
+
+// NewQuoteHistoryService creates a new quote history service. experiments
+// may be nil when experiment results are not tracked.
+func NewQuoteHistoryService(repo repository.QuoteStore, experiments *ExperimentService, logger *logrus.Logger) *QuoteHistoryService {
+	return &QuoteHistoryService{
+		repo:        repo,
+		experiments: experiments,
+		logger:      logger,
+	}
+}
+
+// RecordQuote adds a quote issued for req to the history, with the request
+// and factors it was priced from. A quote that cannot be stored is logged
+// rather than failing the quote. A nil service records nothing.
+func (s *QuoteHistoryService) RecordQuote(req *models.QuoteRequest, quote *models.Quote) {
+	if s == nil {
+		return
+	}
+
+	request := *req
+	var factors *models.Factors
+	if quote.Factors != nil {
+		copied := *quote.Factors
+		factors = &copied
+	}
+	record := &models.QuoteRecord{
+		QuoteID:        quote.QuoteID,
+		CustomerID:     req.CustomerID,
+		AgentID:        quote.AgentID,
+		PolicyType:     quote.PolicyType,
+		CoverageAmount: quote.CoverageAmount,
+		FinalPremium:   quote.FinalPremium,
+		Experiment:     quote.Experiment,
+		CreatedAt:      quote.CreatedAt,
+		ValidUntil:     quote.ValidUntil,
+		RulesVersion:   quote.RulesVersion,
+		Request:        &request,
+		Factors:        factors,
+		RequoteOf:      quote.RequoteOf,
+	}
+	if err := s.repo.SaveQuote(record); err != nil {
+		s.logger.WithError(err).WithField("quoteId", quote.QuoteID).Error("Failed to record quote")
+	}
+
+	s.experiments.RecordQuote(quote)
+}
+
+// ConvertQuote marks a quote as bound into policyID, which may be empty when
+// the caller does not know it. Converting again into the same policy has no
+// further effect; a quote cannot be bound into two different policies.
+func (s *QuoteHistoryService) ConvertQuote(quoteID, policyID string) (*models.QuoteRecord, error) {
+	quote, err := s.repo.GetQuote(quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.Converted {
+		if policyID != "" && quote.PolicyID != "" && policyID != quote.PolicyID {
+			return nil, fmt.Errorf("quote already converted")
+		}
+		return quote, nil
+	}
+
+	now := time.Now()
+	quote.Converted = true
+	quote.PolicyID = policyID
+	quote.ConvertedAt = &now
+	if err := s.repo.UpdateQuote(quote); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"quoteId":    quoteID,
+		"customerId": quote.CustomerID,
+		"policyId":   policyID,
+	}).Info("Quote converted")
+
+	// Experiment results may no longer hold the quote once it aged out
+	if quote.Experiment != nil && s.experiments != nil {
+		if _, err := s.experiments.ConvertQuote(quoteID); err != nil {
+			s.logger.WithError(err).WithField("quoteId", quoteID).Debug("Conversion not counted in experiment results")
+		}
+	}
+
+	return quote, nil
+}
+
+// GetQuote returns a quote of the history. A nil service holds no quotes.
+func (s *QuoteHistoryService) GetQuote(quoteID string) (*models.QuoteRecord, error) {
+	if s == nil {
+		return nil, fmt.Errorf("quote not found")
+	}
+	return s.repo.GetQuote(quoteID)
+}
+
+// GetCustomerQuotes returns a customer's quote history and conversion rate.
+// Customers who were never quoted get an empty history.
+func (s *QuoteHistoryService) GetCustomerQuotes(customerID string) *models.CustomerQuoteHistory {
+	quotes := s.repo.GetQuotesByCustomerID(customerID)
+
+	history := &models.CustomerQuoteHistory{
+		CustomerID:  customerID,
+		Quotes:      quotes,
+		TotalQuotes: len(quotes),
+	}
+	for _, quote := range quotes {
+		if quote.Converted {
+			history.ConvertedQuotes++
+		}
+	}
+	if history.TotalQuotes > 0 {
+		history.ConversionRate = roundTo(float64(history.ConvertedQuotes)/float64(history.TotalQuotes), 4)
+	}
+
+	return history
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func newQuoteHistoryTestServices(t *testing.T) (*PricingService, *QuoteHistoryService) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	quoteRepo, err := repository.NewQuoteRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create quote repository: %v", err)
+	}
+	quotes := NewQuoteHistoryService(quoteRepo, nil, logger)
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "home": 1500})
+	return NewPricingService(store, nil, quotes, nil, QuoteValidity{}, nil, nil, nil, nil, nil, logger), quotes
+}
+
+func TestQuoteHistoryTracksConversion(t *testing.T) {
+	pricing, quotes := newQuoteHistoryTestServices(t)
+	ctx := context.Background()
+
+	single, err := pricing.CalculateQuote(ctx, &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: "cust-001"})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	comparison, err := pricing.CompareQuotes(ctx, &models.QuoteComparisonRequest{
+		Base:            models.QuoteRequest{PolicyType: "home", CustomerAge: 40, RiskScore: 2, CustomerID: "cust-001"},
+		CoverageAmounts: []int{500000, 750000},
+	})
+	if err != nil {
+		t.Fatalf("CompareQuotes failed: %v", err)
+	}
+	if _, err := pricing.CalculateQuote(ctx, &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 30, RiskScore: 1, CustomerID: "cust-002"}); err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+
+	// Every quote is kept, each comparison level included
+	history := quotes.GetCustomerQuotes("cust-001")
+	if history.TotalQuotes != 3 || history.ConvertedQuotes != 0 || history.ConversionRate != 0 {
+		t.Fatalf("Unexpected history before binding: %+v", history)
+	}
+
+	converted, err := quotes.ConvertQuote(comparison.Quotes[1].QuoteID, "pol-101")
+	if err != nil {
+		t.Fatalf("ConvertQuote failed: %v", err)
+	}
+	if !converted.Converted || converted.PolicyID != "pol-101" || converted.ConvertedAt == nil || converted.CoverageAmount != 750000 {
+		t.Errorf("Unexpected converted quote: %+v", converted)
+	}
+
+	// Converting into the same policy again is harmless, into another is not
+	if _, err := quotes.ConvertQuote(comparison.Quotes[1].QuoteID, "pol-101"); err != nil {
+		t.Errorf("Repeated conversion failed: %v", err)
+	}
+	if _, err := quotes.ConvertQuote(comparison.Quotes[1].QuoteID, "pol-102"); err == nil || err.Error() != "quote already converted" {
+		t.Errorf("Conversion into a second policy: got %v", err)
+	}
+	if _, err := quotes.ConvertQuote("Q-404", ""); err == nil || err.Error() != "quote not found" {
+		t.Errorf("Unknown quote: got %v", err)
+	}
+
+	history = quotes.GetCustomerQuotes("cust-001")
+	if history.TotalQuotes != 3 || history.ConvertedQuotes != 1 || history.ConversionRate != 0.3333 {
+		t.Errorf("Unexpected history after binding: %+v", history)
+	}
+	for _, quote := range history.Quotes {
+		if quote.QuoteID == single.QuoteID && quote.Converted {
+			t.Errorf("Unbound quote reported as converted: %+v", quote)
+		}
+	}
+
+	if history := quotes.GetCustomerQuotes("cust-404"); history.TotalQuotes != 0 || history.Quotes == nil {
+		t.Errorf("Expected an empty history for an unquoted customer: %+v", history)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySequences reserves sequence numbers in memory, recording each
+// reservation
+type memorySequences struct {
+	mu           sync.Mutex
+	last         map[string]int
+	reservations int
+}
+
+func (s *memorySequences) ReserveQuoteSequence(day string, n int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		s.last = map[string]int{}
+	}
+	s.reservations++
+	first := s.last[day] + 1
+	s.last[day] += n
+	return first, nil
+}
+
+func TestQuoteIDsAreUniqueUnderConcurrency(t *testing.T) {
+	store := &memorySequences{}
+	ids := NewQuoteIDs(store)
+	ids.now = func() time.Time { return time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC) }
+
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				id, err := ids.Next()
+				if err != nil {
+					t.Errorf("Next failed: %v", err)
+					return
+				}
+				mu.Lock()
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 400 || !seen["Q-20260309-000001"] || !seen["Q-20260309-000400"] {
+		t.Errorf("Expected 400 distinct IDs numbered 1 to 400, got %d", len(seen))
+	}
+	if store.reservations != 4 {
+		t.Errorf("Expected 4 blocks reserved, got %d", store.reservations)
+	}
+
+	var metrics bytes.Buffer
+	ids.WritePrometheus(&metrics)
+	if !strings.Contains(metrics.String(), "pricing_quotes_issued_total 400\n") {
+		t.Errorf("Unexpected metrics:\n%s", metrics.String())
+	}
+}
+
+func TestQuoteIDsRestartEachDay(t *testing.T) {
+	store := &memorySequences{last: map[string]int{"20260309": 250}}
+	ids := NewQuoteIDs(store)
+	now := time.Date(2026, 3, 9, 23, 59, 0, 0, time.UTC)
+	ids.now = func() time.Time { return now }
+
+	var got []string
+	for _, at := range []time.Duration{0, 0, 2 * time.Minute} {
+		now = now.Add(at)
+		id, err := ids.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		got = append(got, id)
+	}
+
+	// A second instance sharing the store continues after the block reserved
+	restarted := NewQuoteIDs(store)
+	restarted.now = func() time.Time { return time.Date(2026, 3, 9, 23, 59, 30, 0, time.UTC) }
+	id, err := restarted.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	got = append(got, id)
+
+	want := []string{"Q-20260309-000251", "Q-20260309-000252", "Q-20260310-000001", "Q-20260309-000351"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Got IDs %v, want %v", got, want)
+	}
+}
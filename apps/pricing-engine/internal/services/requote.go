@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// CheckRequote reports why a quote cannot be requoted, if it cannot: it is
+// unknown, was bound into a policy, has not expired yet or was recorded
+// without the request it was priced from
+func (s *PricingService) CheckRequote(quoteID string) error {
+	_, err := s.requoteSource(quoteID)
+	return err
+}
+
+// Requote prices an expired quote again from the request it was issued
+// for, against the current rules, and records the new quote as a requote
+// of it. The original is kept unchanged, and returned with the rules and
+// factors that changed between the two.
+func (s *PricingService) Requote(ctx context.Context, quoteID string) (*models.Requote, error) {
+	original, err := s.requoteSource(quoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := *original.Request
+	quote, factors, err := s.price(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	quote.RequoteOf = original.QuoteID
+	s.quotes.RecordQuote(&req, quote)
+
+	requote := &models.Requote{
+		Original:      original,
+		Quote:         quote,
+		PremiumChange: roundTo(quote.FinalPremium-original.FinalPremium, 2),
+		Rules:         s.rulesDiff(req.PolicyType, original.RulesVersion, factors.rulesVersion),
+		FactorChanges: diffValues(original.Factors, quote.Factors),
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"quoteId":       quote.QuoteID,
+		"requoteOf":     original.QuoteID,
+		"policyType":    req.PolicyType,
+		"finalPremium":  quote.FinalPremium,
+		"premiumChange": requote.PremiumChange,
+		"rulesVersion":  factors.rulesVersion,
+	}).Info("Quote requoted")
+
+	return requote, nil
+}
+
+// requoteSource returns the quote to requote, or why it cannot be
+func (s *PricingService) requoteSource(quoteID string) (*models.QuoteRecord, error) {
+	original, err := s.quotes.GetQuote(quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if original.Converted {
+		return nil, fmt.Errorf("quote already converted")
+	}
+	if time.Now().Before(original.ValidUntil) {
+		return nil, fmt.Errorf("quote has not expired")
+	}
+	if original.Request == nil {
+		return nil, fmt.Errorf("quote was recorded without its request")
+	}
+	return original, nil
+}
+
+// rulesDiff compares the rules a quote of policyType was priced from with
+// those it was requoted from. Only loaded rules versions can be compared.
+func (s *PricingService) rulesDiff(policyType, fromVersion, toVersion string) models.RulesDiff {
+	diff := models.RulesDiff{FromVersion: fromVersion, ToVersion: toVersion, Changes: []models.RuleChange{}}
+	if fromVersion == "" {
+		return diff
+	}
+	from, err := s.repo.ForVersion(fromVersion)
+	if err != nil {
+		return diff
+	}
+	to, err := s.repo.ForVersion(toVersion)
+	if err != nil {
+		return diff
+	}
+
+	diff.Compared = true
+	diff.Changes = diffValues(quotedRules(from.GetPricingRules(), policyType), quotedRules(to.GetPricingRules(), policyType))
+	return diff
+}
+
+// quotedRules is the part of the rules a quote of policyType is priced from
+func quotedRules(rules *models.PricingRules, policyType string) map[string]interface{} {
+	return map[string]interface{}{
+		"baseRates":      map[string]interface{}{policyType: rules.BaseRates[policyType]},
+		"discounts":      rules.Discounts,
+		"dynamicPricing": rules.DynamicPricing,
+	}
+}
+
+// diffValues lists the values that differ between the JSON encodings of
+// from and to, by their dotted path, in path order
+func diffValues(from, to interface{}) []models.RuleChange {
+	before, after := flattenJSON(from), flattenJSON(to)
+
+	paths := make([]string, 0, len(before)+len(after))
+	for path := range before {
+		paths = append(paths, path)
+	}
+	for path := range after {
+		if _, ok := before[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	changes := []models.RuleChange{}
+	for _, path := range paths {
+		if !reflect.DeepEqual(before[path], after[path]) {
+			changes = append(changes, models.RuleChange{Path: path, From: before[path], To: after[path]})
+		}
+	}
+	return changes
+}
+
+// flattenJSON maps the dotted path of every value in the JSON encoding of
+// v to the value. Arrays are values of their own.
+func flattenJSON(v interface{}) map[string]interface{} {
+	values := make(map[string]interface{})
+	data, err := json.Marshal(v)
+	if err != nil {
+		return values
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return values
+	}
+
+	var walk func(prefix string, value interface{})
+	walk = func(prefix string, value interface{}) {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			if value != nil && prefix != "" {
+				values[prefix] = value
+			}
+			return
+		}
+		for key, child := range object {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			walk(key, child)
+		}
+	}
+	walk("", decoded)
+	return values
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/internal/repository/repositorytest"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequoteRefreshesExpiredQuotes(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	quoteRepo, err := repository.NewQuoteRepository(t.TempDir(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create quote repository: %v", err)
+	}
+	quotes := NewQuoteHistoryService(quoteRepo, nil, logger)
+	store := repositorytest.NewFakeStore(map[string]float64{"auto": 1000, "home": 1500})
+	store.Alternates = map[string]*repositorytest.FakeStore{
+		"2025.2": repositorytest.NewFakeStore(map[string]float64{"auto": 1200, "home": 1500}),
+	}
+	validity := QuoteValidity{PolicyTypes: map[string]time.Duration{"home": 10 * 24 * time.Hour}}
+	pricing := NewPricingService(store, nil, quotes, nil, validity, nil, nil, nil, nil, nil, logger)
+	ctx := context.Background()
+
+	// Validity follows the policy type, and a quote still valid is kept
+	auto, err := pricing.CalculateQuote(ctx, &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: "cust-001"})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	home, err := pricing.CalculateQuote(ctx, &models.QuoteRequest{PolicyType: "home", CoverageAmount: 500000, CustomerAge: 40, RiskScore: 2, CustomerID: "cust-001"})
+	if err != nil {
+		t.Fatalf("CalculateQuote failed: %v", err)
+	}
+	if auto.ValidUntil.Sub(auto.CreatedAt) != DefaultQuoteValidity || home.ValidUntil.Sub(home.CreatedAt) != 10*24*time.Hour {
+		t.Errorf("Unexpected validity: auto until %s, home until %s", auto.ValidUntil, home.ValidUntil)
+	}
+	if err := pricing.CheckRequote(auto.QuoteID); err == nil || err.Error() != "quote has not expired" {
+		t.Errorf("Expected a valid quote to be refused, got %v", err)
+	}
+
+	// A quote priced from earlier rules that has since expired
+	expired := &models.QuoteRecord{
+		QuoteID:        "Q-20250301-000007",
+		CustomerID:     "cust-001",
+		PolicyType:     "auto",
+		CoverageAmount: 250000,
+		FinalPremium:   1200,
+		CreatedAt:      time.Now().AddDate(0, -2, 0),
+		ValidUntil:     time.Now().AddDate(0, -1, 0),
+		RulesVersion:   "2025.2",
+		Request:        &models.QuoteRequest{PolicyType: "auto", CoverageAmount: 250000, CustomerAge: 40, RiskScore: 2, CustomerID: "cust-001"},
+		Factors:        &models.Factors{BaseMultiplier: 1200, CoverageMultiplier: 1, AgeMultiplier: 1, RiskMultiplier: 1, DynamicMultiplier: 1, TerritoryMultiplier: 1, Territory: "default", VehicleMultiplier: 1, PropertyMultiplier: 1},
+	}
+	if err := quoteRepo.SaveQuote(expired); err != nil {
+		t.Fatalf("SaveQuote failed: %v", err)
+	}
+
+	requote, err := pricing.Requote(ctx, expired.QuoteID)
+	if err != nil {
+		t.Fatalf("Requote failed: %v", err)
+	}
+	if requote.Quote.RequoteOf != expired.QuoteID || requote.Quote.FinalPremium != 1000 || requote.PremiumChange != -200 {
+		t.Errorf("Unexpected requote %+v, premium change %v", requote.Quote, requote.PremiumChange)
+	}
+	rules := requote.Rules
+	if rules.FromVersion != "2025.2" || rules.ToVersion != repositorytest.FakeVersion || !rules.Compared {
+		t.Errorf("Unexpected rules diff %+v", rules)
+	}
+	if len(rules.Changes) != 1 || rules.Changes[0].Path != "baseRates.auto.base" || rules.Changes[0].From != 1200.0 || rules.Changes[0].To != 1000.0 {
+		t.Errorf("Expected the base rate change only, got %+v", rules.Changes)
+	}
+	if len(requote.FactorChanges) != 1 || requote.FactorChanges[0].Path != "baseMultiplier" {
+		t.Errorf("Expected the base multiplier change only, got %+v", requote.FactorChanges)
+	}
+
+	// The original is kept for comparison, and the requote recorded beside it
+	original, _ := quotes.GetQuote(expired.QuoteID)
+	if original.FinalPremium != 1200 || original.RulesVersion != "2025.2" {
+		t.Errorf("Expected the original unchanged, got %+v", original)
+	}
+	recorded, err := quotes.GetQuote(requote.Quote.QuoteID)
+	if err != nil || recorded.RequoteOf != expired.QuoteID || recorded.Request == nil || recorded.RulesVersion != repositorytest.FakeVersion {
+		t.Errorf("Unexpected recorded requote %+v, %v", recorded, err)
+	}
+
+	// Bound quotes and quotes recorded without their request are refused
+	if _, err := quotes.ConvertQuote(expired.QuoteID, "pol-101"); err != nil {
+		t.Fatalf("ConvertQuote failed: %v", err)
+	}
+	if err := pricing.CheckRequote(expired.QuoteID); err == nil || err.Error() != "quote already converted" {
+		t.Errorf("Expected a converted quote to be refused, got %v", err)
+	}
+	legacy := &models.QuoteRecord{QuoteID: "Q-7f3a9c21", PolicyType: "auto", ValidUntil: time.Now().AddDate(-1, 0, 0)}
+	if err := quoteRepo.SaveQuote(legacy); err != nil {
+		t.Fatalf("SaveQuote failed: %v", err)
+	}
+	if _, err := pricing.Requote(ctx, legacy.QuoteID); err == nil || err.Error() != "quote was recorded without its request" {
+		t.Errorf("Expected a quote without its request to be refused, got %v", err)
+	}
+	if err := pricing.CheckRequote("Q-404"); err == nil || err.Error() != "quote not found" {
+		t.Errorf("Expected an unknown quote, got %v", err)
+	}
+}
+
+func TestParseQuoteValidityDays(t *testing.T) {
+	validity, err := ParseQuoteValidityDays("auto=14, life = 60")
+	if err != nil || len(validity) != 2 || validity["auto"] != 14*24*time.Hour || validity["life"] != 60*24*time.Hour {
+		t.Errorf("Unexpected validity %v, %v", validity, err)
+	}
+	for _, invalid := range []string{"auto", "auto=0", "auto=2w", "=7", "auto=7,auto=14"} {
+		if _, err := ParseQuoteValidityDays(invalid); err == nil {
+			t.Errorf("Expected %q to be refused", invalid)
+		}
+	}
+}
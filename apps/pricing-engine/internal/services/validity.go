@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultQuoteValidity is how long quotes stay valid when neither their
+// policy type nor the service sets a validity
+const DefaultQuoteValidity = 30 * 24 * time.Hour
+
+// QuoteValidity is how long quotes stay valid before they must be
+// requoted, by policy type
+type QuoteValidity struct {
+	Default     time.Duration            // zero uses DefaultQuoteValidity
+	PolicyTypes map[string]time.Duration // overrides Default for a policy type
+}
+
+// For returns how long a quote for policyType stays valid
+func (v QuoteValidity) For(policyType string) time.Duration {
+	if d, ok := v.PolicyTypes[policyType]; ok {
+		return d
+	}
+	if v.Default > 0 {
+		return v.Default
+	}
+	return DefaultQuoteValidity
+}
+
+// ParseQuoteValidityDays parses per policy type validities in days, such as
+// "auto=14,life=60"
+func ParseQuoteValidityDays(s string) (map[string]time.Duration, error) {
+	validity := make(map[string]time.Duration)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		policyType, days, ok := strings.Cut(entry, "=")
+		policyType = strings.TrimSpace(policyType)
+		if !ok || policyType == "" {
+			return nil, fmt.Errorf("invalid quote validity %q, want policyType=days", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid quote validity %q, days must be a positive whole number", entry)
+		}
+		if _, exists := validity[policyType]; exists {
+			return nil, fmt.Errorf("quote validity for %s is set more than once", policyType)
+		}
+		validity[policyType] = time.Duration(n) * 24 * time.Hour
+	}
+	return validity, nil
+}
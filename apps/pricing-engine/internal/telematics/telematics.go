@@ -0,0 +1,76 @@
+// Package telematics is the integration point for usage-based insurance
+// providers that score customers' driving. FileProvider serves scores from a
+// JSON file until a provider is contracted.
+package telematics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Score is a customer's driving score from a telematics provider
+type Score struct {
+	CustomerID    string    `json:"customerId"`
+	DrivingScore  int       `json:"drivingScore"` // 0 (worst) to 100 (best)
+	TripsRecorded int       `json:"tripsRecorded"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Provider returns driving scores. DrivingScore returns nil without an
+// error when the customer has no score yet.
+type Provider interface {
+	DrivingScore(ctx context.Context, customerID string) (*Score, error)
+}
+
+// FileProvider serves driving scores loaded from a JSON array of Score
+type FileProvider struct {
+	scores map[string]*Score
+}
+
+var _ Provider = (*FileProvider)(nil)
+
+// NewFileProvider loads scores from path. A missing file gives a provider
+// with no scores.
+func NewFileProvider(path string, logger *logrus.Logger) (*FileProvider, error) {
+	provider := &FileProvider{scores: make(map[string]*Score)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Infof("No telematics scores at %s, telematics discounts disabled", path)
+		return provider, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var scores []*Score
+	if err := json.Unmarshal(data, &scores); err != nil {
+		return nil, err
+	}
+	for _, score := range scores {
+		if score.DrivingScore < 0 || score.DrivingScore > 100 {
+			return nil, fmt.Errorf("driving score for %s must be between 0 and 100", score.CustomerID)
+		}
+		provider.scores[score.CustomerID] = score
+	}
+
+	logger.Infof("Loaded %d telematics scores from %s", len(provider.scores), path)
+
+	return provider, nil
+}
+
+// DrivingScore returns the customer's score, or nil when there is none
+func (p *FileProvider) DrivingScore(ctx context.Context, customerID string) (*Score, error) {
+	score, exists := p.scores[customerID]
+	if !exists {
+		return nil, nil
+	}
+	copied := *score
+	return &copied, nil
+}
@@ -0,0 +1,231 @@
+// Package app assembles the search service from its index, indexer,
+// handlers and routes. cmd/server serves it over HTTP; tests can mount the
+// same handler on an httptest server.
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/auth"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/handlers"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/index"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/health"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// Config holds the settings needed to assemble the service
+type Config struct {
+	// ClaimsServiceURL, CustomerServiceURL and PolicyServiceURL are the
+	// services whose entities are indexed. An empty URL leaves that
+	// service's entities out of search.
+	ClaimsServiceURL   string
+	CustomerServiceURL string
+	PolicyServiceURL   string
+
+	// JWTSecret signs the staff token used to list every customer's
+	// claims and policies from claims-service and policy-service. JWT_SECRET
+	// in the default secret store takes its place when it resolves, so
+	// rotations take effect without a restart.
+	JWTSecret string
+
+	// ReindexInterval is how often the index is refreshed from the
+	// services; zero disables the scheduled refresh (staff can still
+	// trigger one).
+	ReindexInterval time.Duration
+
+	// ShapingFile holds the field visibility rules of the responses, on
+	// top of their shape tags. When empty the tags alone apply.
+	ShapingFile string
+
+	// Maintenance is the maintenance mode the service starts in
+	Maintenance maintenance.Config
+	// Faults is whether the service may inject faults for resilience
+	// testing; the rules are set at /admin/faults
+	Faults faults.Config
+
+	// SlowRequestThreshold logs requests that take at least this long as
+	// warnings; 0 disables the warning
+	SlowRequestThreshold time.Duration
+
+	// AccessLog receives one structured entry per request; when nil they
+	// are written to the service log
+	AccessLog *logrus.Logger
+	// ErrorReporter receives handler panics, such as a Sentry hub; nil only
+	// logs them
+	ErrorReporter telemetry.ErrorReporter
+}
+
+// App is an assembled search service
+type App struct {
+	Handler http.Handler
+
+	stopIndexer lifecycle.StopFunc
+	index       index.Index
+	logger      *logrus.Logger
+}
+
+// New wires the service together and builds the index from the services
+func New(cfg Config, logger *logrus.Logger) (*App, error) {
+	shaper, err := loadShaping(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := index.NewBleve()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize index: %w", err)
+	}
+
+	// Initialize clients for the services that own the indexed entities.
+	// Claims and policies are read with a short-lived staff token.
+	jwtManager := auth.NewRotatingJWTManager(secrets.Default().Func("JWT_SECRET", cfg.JWTSecret), time.Minute)
+	token := func() (string, error) {
+		return jwtManager.GenerateWithRole("search-service", "", "admin")
+	}
+	var claimLister services.ClaimLister
+	if cfg.ClaimsServiceURL != "" {
+		claimLister = clients.NewClaimsClient(cfg.ClaimsServiceURL, token, 10*time.Second)
+	}
+	var customerLister services.CustomerLister
+	if cfg.CustomerServiceURL != "" {
+		customerLister = clients.NewCustomerClient(cfg.CustomerServiceURL, 10*time.Second)
+	}
+	var policyLister services.PolicyLister
+	if cfg.PolicyServiceURL != "" {
+		policyLister = clients.NewPolicyClient(cfg.PolicyServiceURL, token, 10*time.Second)
+	}
+
+	// Initialize services. The first pass runs before serving so searches
+	// never see an empty index.
+	indexer := services.NewIndexer(idx, claimLister, customerLister, policyLister, logger)
+	searchService := services.NewSearchService(idx, logger)
+
+	indexer.Reindex(context.Background())
+	var stopIndexer lifecycle.StopFunc
+	if cfg.ReindexInterval > 0 {
+		stopIndexer = lifecycle.Go(func(ctx context.Context) {
+			indexer.Run(ctx, cfg.ReindexInterval)
+		})
+	}
+
+	// Initialize handlers
+	maintenanceMode := maintenance.New(cfg.Maintenance, logger)
+	faultInjection := faults.New(cfg.Faults, logger)
+	healthHandler := health.NewHandler("search-service", health.Combine(maintenanceMode, faultInjection))
+	searchHandler := handlers.NewSearchHandler(searchService, indexer, logger)
+
+	// Setup router
+	router := mux.NewRouter()
+
+	// Apply global middleware
+	requestMetrics := telemetry.NewRegistry(nil)
+	panics := telemetry.NewPanicCounter()
+	router.Use(middleware.LoggingMiddleware(logger, middleware.LoggingOptions{
+		Metrics:       requestMetrics,
+		SlowThreshold: cfg.SlowRequestThreshold,
+		AccessLog:     cfg.AccessLog,
+	}))
+	// Error messages are translated into the caller's Accept-Language,
+	// including those of recovered panics
+	router.Use(i18n.Middleware(i18n.Default()))
+	router.Use(middleware.RecoveryMiddleware(logger, middleware.RecoveryOptions{
+		Panics:   panics,
+		Reporter: cfg.ErrorReporter,
+	}))
+	// Turn callers away during maintenance before they are authenticated
+	router.Use(maintenanceMode.Middleware)
+	// Inject test faults ahead of authentication too, so every caller is
+	// faulted the same way
+	router.Use(faultInjection.Middleware)
+	router.Use(middleware.AuthMiddleware(logger))
+	// Results are shaped for the role identify or RequireRole verified
+	router.Use(shaping.Middleware(shaper, func(r *http.Request) shaping.Viewer {
+		return shaping.Viewer{Role: middleware.GetRole(r)}
+	}))
+
+	// Setup CORS
+	corsHandler := middleware.NewCORS()
+
+	// Register routes. Search is shared by customers and staff, who see
+	// different results.
+	identify := middleware.IdentifyRole(logger)
+	router.Handle("/healthz", healthHandler).Methods("GET")
+	router.Handle("/labels", i18n.LabelsHandler(i18n.Default())).Methods("GET")
+	router.Handle("/metrics", telemetry.Handler(requestMetrics, panics, faultInjection)).Methods("GET")
+	router.Handle("/search", identify(http.HandlerFunc(searchHandler.Search))).Methods("GET")
+
+	// Back-office routes for staff, authorized by JWT role
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireRole(logger, "admin", "adjuster"))
+	admin.HandleFunc("/search/reindex", searchHandler.Reindex).Methods("POST")
+	admin.Handle("/maintenance", middleware.RequireRole(logger, "admin")(maintenanceMode.Handler())).Methods("GET", "PUT")
+	admin.Handle("/faults", middleware.RequireRole(logger, "admin")(faultInjection.Handler())).Methods("GET", "PUT")
+
+	// Wrap router with CORS
+	return &App{
+		Handler:     corsHandler.Handler(router),
+		stopIndexer: stopIndexer,
+		index:       idx,
+		logger:      logger,
+	}, nil
+}
+
+// loadShaping reads the response shaping policy, when one is configured,
+// and checks it against the search responses
+func loadShaping(cfg Config, logger *logrus.Logger) (*shaping.Shaper, error) {
+	policy := shaping.Default()
+	if cfg.ShapingFile != "" {
+		var err error
+		if policy, err = shaping.Load(cfg.ShapingFile); err != nil {
+			return nil, fmt.Errorf("failed to load response shaping policy: %w", err)
+		}
+		logger.WithField("version", policy.Version).Infof("Loaded response shaping policy from %s", cfg.ShapingFile)
+	}
+
+	shaper := shaping.New(policy, "search-service")
+	if err := shaper.Check(models.SearchResponse{}, models.ReindexResult{}); err != nil {
+		return nil, fmt.Errorf("invalid response shaping: %w", err)
+	}
+	return shaper, nil
+}
+
+// serverDrainTimeout is how long requests in flight get to finish on
+// shutdown
+const serverDrainTimeout = 30 * time.Second
+
+// RegisterShutdown registers the service's components with m in the order
+// they stop. server, when given, drains first, then the scheduled refresh
+// stops before the index it writes to is released.
+func (a *App) RegisterShutdown(m *lifecycle.Manager, server lifecycle.StopFunc) {
+	if server != nil {
+		m.Register("http server", serverDrainTimeout, server)
+	}
+	if a.stopIndexer != nil {
+		m.Register("index refresh", 10*time.Second, a.stopIndexer)
+	}
+	m.Register("search index", 5*time.Second, func(ctx context.Context) error {
+		return a.index.Close()
+	})
+}
+
+// Close stops the scheduled refresh and releases the index
+func (a *App) Close() {
+	m := lifecycle.New(a.logger)
+	a.RegisterShutdown(m, nil)
+	m.Shutdown(context.Background())
+}
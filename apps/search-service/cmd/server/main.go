@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/logging"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	// Initialize logger from LOG_FORMAT, LOG_LEVEL, LOG_LEVELS and sampling settings
+	logger := logging.NewFromEnv()
+
+	logger.Info("Starting Search Service...")
+
+	// Secrets come from SECRETS_PROVIDER: the environment by default, or
+	// mounted files, Vault or AWS Secrets Manager. Startup fails when one
+	// of SECRETS_REQUIRED does not resolve.
+	secretStore, err := secrets.Open(secrets.ConfigFromEnv(logger), logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to resolve secrets")
+	}
+	secrets.SetDefault(secretStore)
+	logger.WithField("provider", secretStore.Source()).Info("Secrets resolved")
+
+	// Get configuration from environment
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8006"
+	}
+
+	// Services whose entities are indexed
+	claimsServiceURL := os.Getenv("CLAIMS_SERVICE_URL")
+	if claimsServiceURL == "" {
+		logger.Warn("CLAIMS_SERVICE_URL not set, claims will not be searchable")
+	}
+
+	customerServiceURL := os.Getenv("CUSTOMER_SERVICE_URL")
+	if customerServiceURL == "" {
+		logger.Warn("CUSTOMER_SERVICE_URL not set, customers will not be searchable")
+	}
+
+	policyServiceURL := os.Getenv("POLICY_SERVICE_URL")
+	if policyServiceURL == "" {
+		logger.Warn("POLICY_SERVICE_URL not set, policies will not be searchable")
+	}
+
+	jwtSecret := secretStore.Lookup("JWT_SECRET", "")
+	if jwtSecret == "" {
+		jwtSecret = "dev-secret-key-change-in-production"
+		logger.Warn("JWT_SECRET not set, using default (not secure for production)")
+	}
+
+	// Field visibility rules on top of the shape tags
+	shapingFile := os.Getenv("SHAPING_FILE")
+
+	reindexInterval := time.Minute
+	if v := os.Getenv("SEARCH_REINDEX_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			reindexInterval = d
+		} else {
+			logger.Warnf("Invalid SEARCH_REINDEX_INTERVAL '%s', defaulting to %s", v, reindexInterval)
+		}
+	}
+
+	// Requests at least this slow are logged as warnings
+	slowRequestThreshold := time.Second
+	if v := os.Getenv("HTTP_SLOW_REQUEST_THRESHOLD"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			slowRequestThreshold = d
+		} else {
+			logger.Warnf("Invalid HTTP_SLOW_REQUEST_THRESHOLD '%s', defaulting to %s", v, slowRequestThreshold)
+		}
+	}
+
+	// Access entries go to the service log unless ACCESS_LOG names a
+	// separate stream: stdout, stderr or a file path
+	accessLog, closeAccessLog, err := telemetry.OpenAccessLog(os.Getenv("ACCESS_LOG"))
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to open access log")
+	}
+	defer closeAccessLog()
+
+	// Assemble the service
+	application, err := app.New(app.Config{
+		ClaimsServiceURL:     claimsServiceURL,
+		CustomerServiceURL:   customerServiceURL,
+		PolicyServiceURL:     policyServiceURL,
+		JWTSecret:            jwtSecret,
+		ReindexInterval:      reindexInterval,
+		ShapingFile:          shapingFile,
+		Maintenance:          maintenance.ConfigFromEnv(logger),
+		Faults:               faults.ConfigFromEnv(logger),
+		SlowRequestThreshold: slowRequestThreshold,
+		AccessLog:            accessLog,
+	}, logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to initialize service")
+	}
+
+	// Create HTTP server
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%s", port),
+		Handler:      application.Handler,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// Drain requests in flight and stop the service's components in order
+	conns := lifecycle.TrackConnections(server)
+	shutdown := lifecycle.New(logger)
+	application.RegisterShutdown(shutdown, lifecycle.DrainServer(server, conns))
+
+	// Start server in a goroutine
+	go func() {
+		logger.Infof("Server listening on port %s", port)
+		logger.Info("API Endpoints:")
+		logger.Info("  GET    /healthz - Health check")
+		logger.Info("  GET    /labels - Status and type labels in the Accept-Language language")
+		logger.Info("  GET    /metrics - Request latency by route (Prometheus)")
+		logger.Info("  GET    /search - Search claims, customers and policies")
+		logger.Info("         Query params: q, type, limit")
+		logger.Info("  POST   /admin/search/reindex - Refresh the index now (admin/adjuster JWT)")
+		logger.Info("  GET    /admin/maintenance - Maintenance mode (admin JWT)")
+		logger.Info("  PUT    /admin/maintenance - Turn maintenance mode on or off (admin JWT)")
+		logger.Info("  GET    /admin/faults - Fault injection rules, when enabled (admin JWT)")
+		logger.Info("  PUT    /admin/faults - Replace the fault injection rules (admin JWT)")
+
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.WithError(err).Fatal("Server failed to start")
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shut down the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	open := conns.Connections()
+	logger.WithFields(logrus.Fields{
+		"active":   open.Active,
+		"idle":     open.Idle,
+		"hijacked": open.Hijacked,
+	}).Info("Shutting down server...")
+
+	if err := shutdown.Shutdown(context.Background()).Err(); err != nil {
+		logger.WithError(err).Error("Shutdown incomplete")
+		return
+	}
+
+	logger.Info("Server stopped gracefully")
+}
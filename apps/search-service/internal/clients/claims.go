@@ -0,0 +1,67 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Claim is the subset of a claims-service claim the search service indexes
+type Claim struct {
+	ID          string `json:"id"`
+	PolicyID    string `json:"policyId"`
+	CustomerID  string `json:"customerId"`
+	ClaimNumber string `json:"claimNumber"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// ClaimsClient calls claims-service. Only staff see every customer's
+// claims, so requests carry a staff token from token.
+type ClaimsClient struct {
+	baseURL    string
+	token      TokenSource
+	httpClient *http.Client
+}
+
+// NewClaimsClient creates a new claims-service client
+func NewClaimsClient(baseURL string, token TokenSource, timeout time.Duration) *ClaimsClient {
+	return &ClaimsClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// ListClaims fetches every claim
+func (c *ClaimsClient) ListClaims(ctx context.Context) ([]Claim, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign claims-service token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/claims", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", serviceUserID)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("claims-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("claims-service returned status %d", resp.StatusCode)
+	}
+
+	var claims []Claim
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode claims: %w", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,57 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Customer is the subset of a customer-service customer the search service
+// indexes
+type Customer struct {
+	ID        string `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Email     string `json:"email"`
+}
+
+// CustomerClient calls customer-service
+type CustomerClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewCustomerClient creates a new customer-service client
+func NewCustomerClient(baseURL string, timeout time.Duration) *CustomerClient {
+	return &CustomerClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// ListCustomers fetches every customer
+func (c *CustomerClient) ListCustomers(ctx context.Context) ([]Customer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/customers", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", serviceUserID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("customer-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("customer-service returned status %d", resp.StatusCode)
+	}
+
+	var customers []Customer
+	if err := json.NewDecoder(resp.Body).Decode(&customers); err != nil {
+		return nil, fmt.Errorf("failed to decode customers: %w", err)
+	}
+	return customers, nil
+}
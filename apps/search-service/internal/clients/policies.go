@@ -0,0 +1,101 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// serviceUserID identifies the search service to the services it reads
+const serviceUserID = "search-service"
+
+// policyPageSize is the largest page policy-service serves
+const policyPageSize = 500
+
+// Policy is the subset of a policy-service policy the search service indexes
+type Policy struct {
+	ID           string `json:"id"`
+	CustomerID   string `json:"customerId"`
+	PolicyNumber string `json:"policyNumber"`
+	Type         string `json:"type"`
+	Status       string `json:"status"`
+}
+
+// policyPage is one page of the back-office policy listing
+type policyPage struct {
+	Data    []Policy `json:"data"`
+	HasMore bool     `json:"hasMore"`
+}
+
+// TokenSource returns a bearer token for back-office routes
+type TokenSource func() (string, error)
+
+// PolicyClient calls policy-service. Listing every customer's policies is a
+// back-office route, so requests carry a staff token from token.
+type PolicyClient struct {
+	baseURL    string
+	token      TokenSource
+	httpClient *http.Client
+}
+
+// NewPolicyClient creates a new policy-service client
+func NewPolicyClient(baseURL string, token TokenSource, timeout time.Duration) *PolicyClient {
+	return &PolicyClient{
+		baseURL:    baseURL,
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// ListPolicies fetches every policy, a page at a time
+func (c *PolicyClient) ListPolicies(ctx context.Context) ([]Policy, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign policy-service token: %w", err)
+	}
+
+	var policies []Policy
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("page", strconv.Itoa(page))
+		query.Set("pageSize", strconv.Itoa(policyPageSize))
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/admin/policies?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		result, err := c.getPage(req)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, result.Data...)
+		if !result.HasMore {
+			return policies, nil
+		}
+	}
+}
+
+// getPage sends one listing request
+func (c *PolicyClient) getPage(req *http.Request) (*policyPage, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("policy-service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("policy-service returned status %d", resp.StatusCode)
+	}
+
+	var page policyPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode policies: %w", err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/middleware"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/services"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorResponse represents an error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// Searcher answers searches for a caller.
+// *services.SearchService is the production implementation.
+type Searcher interface {
+	Search(userID, role, text string, types []string, limit int) (*models.SearchResponse, error)
+}
+
+// Reindexer refreshes the index from the services.
+// *services.Indexer is the production implementation.
+type Reindexer interface {
+	Reindex(ctx context.Context) *models.ReindexResult
+}
+
+var (
+	_ Searcher  = (*services.SearchService)(nil)
+	_ Reindexer = (*services.Indexer)(nil)
+)
+
+// SearchHandler handles search requests
+type SearchHandler struct {
+	searcher  Searcher
+	reindexer Reindexer
+	logger    *logrus.Logger
+}
+
+// NewSearchHandler creates a new search handler
+func NewSearchHandler(searcher Searcher, reindexer Reindexer, logger *logrus.Logger) *SearchHandler {
+	return &SearchHandler{
+		searcher:  searcher,
+		reindexer: reindexer,
+		logger:    logger,
+	}
+}
+
+// Search handles GET /search - finds claims, customers and policies.
+// Query params: q (required), type (comma-separated entity types), limit.
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var types []string
+	if value := query.Get("type"); value != "" {
+		types = strings.Split(value, ",")
+	}
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			h.respondError(w, http.StatusBadRequest, "bad_request", "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	response, err := h.searcher.Search(middleware.GetUserID(r), middleware.GetRole(r), query.Get("q"), types, limit)
+	if err != nil {
+		switch msg := err.Error(); {
+		case msg == "q is required", strings.HasPrefix(msg, "invalid type: "), strings.HasPrefix(msg, "limit must be"):
+			h.respondError(w, http.StatusBadRequest, "bad_request", msg)
+		default:
+			h.respondError(w, http.StatusInternalServerError, "internal_error", "Search failed")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(shaping.Apply(r, response)); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// Reindex handles POST /admin/search/reindex - refreshes the index now
+func (h *SearchHandler) Reindex(w http.ResponseWriter, r *http.Request) {
+	result := h.reindexer.Reindex(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(shaping.Apply(r, result)); err != nil {
+		h.logger.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// respondError writes an ErrorResponse
+func (h *SearchHandler) respondError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   code,
+		Message: message,
+	})
+}
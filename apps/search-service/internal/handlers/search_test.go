@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// stubSearcher records the search it was asked for and returns canned
+// results so handler tests cover only the HTTP mapping
+type stubSearcher struct {
+	err   error
+	types []string
+	limit int
+}
+
+func (s *stubSearcher) Search(userID, role, text string, types []string, limit int) (*models.SearchResponse, error) {
+	s.types, s.limit = types, limit
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.SearchResponse{Query: text, Results: []models.Result{}}, nil
+}
+
+type stubReindexer struct{}
+
+func (stubReindexer) Reindex(ctx context.Context) *models.ReindexResult {
+	return &models.ReindexResult{Indexed: map[string]int{models.TypeClaim: 3}}
+}
+
+func TestSearchStatusMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{"found", "?q=doe&type=claim,policy&limit=5", nil, http.StatusOK, ""},
+		{"bad limit", "?q=doe&limit=none", nil, http.StatusBadRequest, "bad_request"},
+		{"zero limit", "?q=doe&limit=0", nil, http.StatusBadRequest, "bad_request"},
+		{"missing query", "", errors.New("q is required"), http.StatusBadRequest, "bad_request"},
+		{"unknown type", "?q=doe&type=invoice", errors.New("invalid type: invoice"), http.StatusBadRequest, "bad_request"},
+		{"index failure", "?q=doe", errors.New("search failed: closed"), http.StatusInternalServerError, "internal_error"},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &stubSearcher{err: tt.err}
+			handler := NewSearchHandler(searcher, stubReindexer{}, logger)
+
+			rec := httptest.NewRecorder()
+			handler.Search(rec, httptest.NewRequest("GET", "/search"+tt.query, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantError != "" {
+				var body ErrorResponse
+				json.NewDecoder(rec.Body).Decode(&body)
+				if body.Error != tt.wantError {
+					t.Errorf("Error code mismatch: got %q, want %q", body.Error, tt.wantError)
+				}
+				return
+			}
+			if len(searcher.types) != 2 || searcher.types[1] != "policy" || searcher.limit != 5 {
+				t.Errorf("Search called with types %v, limit %d", searcher.types, searcher.limit)
+			}
+		})
+	}
+}
+
+func TestReindexReturnsResult(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	handler := NewSearchHandler(&stubSearcher{}, stubReindexer{}, logger)
+
+	rec := httptest.NewRecorder()
+	handler.Reindex(rec, httptest.NewRequest("POST", "/admin/search/reindex", nil))
+
+	var body models.ReindexResult
+	json.NewDecoder(rec.Body).Decode(&body)
+	if rec.Code != http.StatusOK || body.Indexed[models.TypeClaim] != 3 {
+		t.Errorf("Unexpected response: %d %+v", rec.Code, body)
+	}
+}
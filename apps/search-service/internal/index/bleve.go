@@ -0,0 +1,169 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// bleveDocument is the shape of a document inside the Bleve index
+type bleveDocument struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	CustomerID string   `json:"customerId"`
+	Title      string   `json:"title"`
+	Text       []string `json:"text"`
+	Keywords   []string `json:"keywords"`
+}
+
+// storedFields are read back from matching documents to build results
+var storedFields = []string{"type", "id", "customerId", "title"}
+
+// Bleve is an in-memory Bleve index. It holds nothing across restarts; the
+// indexer rebuilds it from the services on startup.
+type Bleve struct {
+	index bleve.Index
+}
+
+var _ Index = (*Bleve)(nil)
+
+// NewBleve creates an empty in-memory index
+func NewBleve() (*Bleve, error) {
+	idx, err := bleve.NewMemOnly(newMapping())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index: %w", err)
+	}
+	return &Bleve{index: idx}, nil
+}
+
+// newMapping analyzes text word by word and keeps types, owners and
+// keywords whole, so they can be filtered and matched exactly
+func newMapping() mapping.IndexMapping {
+	exact := bleve.NewTextFieldMapping()
+	exact.Analyzer = keyword.Name
+
+	text := bleve.NewTextFieldMapping()
+	text.Analyzer = standard.Name
+	text.Store = false
+
+	stored := bleve.NewTextFieldMapping()
+	stored.Index = false
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("type", exact)
+	doc.AddFieldMappingsAt("customerId", exact)
+	doc.AddFieldMappingsAt("keywords", exact)
+	doc.AddFieldMappingsAt("text", text)
+	doc.AddFieldMappingsAt("id", stored)
+	doc.AddFieldMappingsAt("title", stored)
+
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	return m
+}
+
+// Put adds documents, replacing any with the same key
+func (b *Bleve) Put(docs ...models.Document) error {
+	batch := b.index.NewBatch()
+	for _, doc := range docs {
+		keywords := make([]string, len(doc.Keywords))
+		for i, kw := range doc.Keywords {
+			keywords[i] = strings.ToLower(kw)
+		}
+		if err := batch.Index(doc.Key(), bleveDocument{
+			Type:       doc.Type,
+			ID:         doc.ID,
+			CustomerID: doc.CustomerID,
+			Title:      doc.Title,
+			Text:       doc.Text,
+			Keywords:   keywords,
+		}); err != nil {
+			return fmt.Errorf("failed to index %s: %w", doc.Key(), err)
+		}
+	}
+	return b.index.Batch(batch)
+}
+
+// Delete removes documents by key
+func (b *Bleve) Delete(keys ...string) error {
+	batch := b.index.NewBatch()
+	for _, key := range keys {
+		batch.Delete(key)
+	}
+	return b.index.Batch(batch)
+}
+
+// Search matches every word of the query text, or the whole text as an
+// identifier or identifier prefix, within the query's owner and types
+func (b *Bleve) Search(q models.Query) ([]models.Result, int, error) {
+	text := strings.TrimSpace(q.Text)
+	lowered := strings.ToLower(text)
+
+	words := bleve.NewMatchQuery(text)
+	words.SetField("text")
+	words.SetOperator(query.MatchQueryOperatorAnd)
+
+	whole := bleve.NewTermQuery(lowered)
+	whole.SetField("keywords")
+	whole.SetBoost(2)
+
+	prefix := bleve.NewPrefixQuery(lowered)
+	prefix.SetField("keywords")
+
+	must := []query.Query{bleve.NewDisjunctionQuery(words, whole, prefix)}
+	if q.CustomerID != "" {
+		owner := bleve.NewTermQuery(q.CustomerID)
+		owner.SetField("customerId")
+		must = append(must, owner)
+	}
+	if len(q.Types) > 0 {
+		types := make([]query.Query, len(q.Types))
+		for i, entityType := range q.Types {
+			term := bleve.NewTermQuery(entityType)
+			term.SetField("type")
+			types[i] = term
+		}
+		must = append(must, bleve.NewDisjunctionQuery(types...))
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	req := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(must...), limit, 0, false)
+	req.Fields = storedFields
+
+	res, err := b.index.Search(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search failed: %w", err)
+	}
+
+	results := make([]models.Result, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		results = append(results, models.Result{
+			Type:       stringField(hit.Fields, "type"),
+			ID:         stringField(hit.Fields, "id"),
+			CustomerID: stringField(hit.Fields, "customerId"),
+			Title:      stringField(hit.Fields, "title"),
+			Score:      hit.Score,
+		})
+	}
+	return results, int(res.Total), nil
+}
+
+// Close releases the index
+func (b *Bleve) Close() error {
+	return b.index.Close()
+}
+
+// stringField reads a stored field from a search hit
+func stringField(fields map[string]interface{}, name string) string {
+	value, _ := fields[name].(string)
+	return value
+}
@@ -0,0 +1,107 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+)
+
+func newTestIndex(t *testing.T) *Bleve {
+	t.Helper()
+	idx, err := NewBleve()
+	if err != nil {
+		t.Fatalf("NewBleve failed: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+
+	err = idx.Put(
+		models.Document{Type: models.TypeClaim, ID: "clm-001", CustomerID: "cust-001", Title: "CLM-2024-001", Text: []string{"CLM-2024-001", "auto", "Rear-ended at a traffic light"}, Keywords: []string{"clm-001", "CLM-2024-001"}},
+		models.Document{Type: models.TypeClaim, ID: "clm-002", CustomerID: "cust-002", Title: "CLM-2024-002", Text: []string{"CLM-2024-002", "home", "Water damage from a burst pipe"}, Keywords: []string{"clm-002", "CLM-2024-002"}},
+		models.Document{Type: models.TypeCustomer, ID: "cust-001", CustomerID: "cust-001", Title: "John Doe", Text: []string{"John Doe", "john.doe@example.com"}, Keywords: []string{"cust-001", "john.doe@example.com"}},
+		models.Document{Type: models.TypePolicy, ID: "pol-001", CustomerID: "cust-001", Title: "AUTO-2024-001", Text: []string{"AUTO-2024-001", "auto"}, Keywords: []string{"pol-001", "AUTO-2024-001"}},
+	)
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	return idx
+}
+
+func resultKeys(results []models.Result) []string {
+	keys := make([]string, len(results))
+	for i, r := range results {
+		keys[i] = r.Type + ":" + r.ID
+	}
+	return keys
+}
+
+func TestBleveSearch(t *testing.T) {
+	idx := newTestIndex(t)
+
+	tests := []struct {
+		name  string
+		query models.Query
+		want  []string
+	}{
+		{"description words", models.Query{Text: "burst pipe"}, []string{"claim:clm-002"}},
+		{"every word must match", models.Query{Text: "burst light"}, []string{}},
+		{"name", models.Query{Text: "doe"}, []string{"customer:cust-001"}},
+		{"whole email", models.Query{Text: "John.Doe@example.com"}, []string{"customer:cust-001"}},
+		{"email prefix", models.Query{Text: "john.d"}, []string{"customer:cust-001"}},
+		{"policy number prefix", models.Query{Text: "AUTO-2024-0"}, []string{"policy:pol-001"}},
+		{"owner", models.Query{Text: "water", CustomerID: "cust-001"}, []string{}},
+		{"types", models.Query{Text: "auto", Types: []string{models.TypeClaim}}, []string{"claim:clm-001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, total, err := idx.Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			got := resultKeys(results)
+			if total != len(tt.want) || len(got) != len(tt.want) {
+				t.Fatalf("Results: got %v (total %d), want %v", got, total, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Results: got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBleveReturnsStoredFields(t *testing.T) {
+	idx := newTestIndex(t)
+
+	results, _, err := idx.Search(models.Query{Text: "clm-2024-001"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Search: got %v, %v", results, err)
+	}
+	r := results[0]
+	if r.Type != models.TypeClaim || r.ID != "clm-001" || r.CustomerID != "cust-001" || r.Title != "CLM-2024-001" || r.Score <= 0 {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+}
+
+func TestBleveDeleteAndReplace(t *testing.T) {
+	idx := newTestIndex(t)
+
+	if err := idx.Delete("claim:clm-002", "claim:unknown"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if results, _, _ := idx.Search(models.Query{Text: "pipe"}); len(results) != 0 {
+		t.Errorf("Deleted document still matches: %v", results)
+	}
+
+	renamed := models.Document{Type: models.TypeCustomer, ID: "cust-001", CustomerID: "cust-001", Title: "John Smith", Text: []string{"John Smith"}, Keywords: []string{"cust-001"}}
+	if err := idx.Put(renamed); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if results, _, _ := idx.Search(models.Query{Text: "doe"}); len(results) != 0 {
+		t.Errorf("Replaced document still matches its old name: %v", results)
+	}
+	if results, _, _ := idx.Search(models.Query{Text: "smith"}); len(results) != 1 || results[0].Title != "John Smith" {
+		t.Errorf("Replaced document: got %v", results)
+	}
+}
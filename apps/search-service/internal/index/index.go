@@ -0,0 +1,24 @@
+// Package index stores searchable documents. Bleve is the in-process
+// implementation; an external engine such as Elasticsearch can be used
+// instead by implementing Index.
+package index
+
+import "github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+
+// DefaultLimit is used when a query does not ask for a number of results
+const DefaultLimit = 20
+
+// MaxLimit caps how many results one query can return
+const MaxLimit = 100
+
+// Index is a full-text index of documents
+type Index interface {
+	// Put adds documents, replacing any with the same key
+	Put(docs ...models.Document) error
+	// Delete removes documents by key; unknown keys are ignored
+	Delete(keys ...string) error
+	// Search returns the best matches for q, ranked by score, and how many
+	// documents matched in total
+	Search(q models.Query) ([]models.Result, int, error)
+	Close() error
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/auth"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRequireRole(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	manager := auth.NewJWTManager("test-secret", time.Hour)
+	token := func(role string) string {
+		signed, err := manager.GenerateWithRole("user-001", "staff@example.com", role)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return "Bearer " + signed
+	}
+	forged, _ := auth.NewJWTManager("other-secret", time.Hour).GenerateWithRole("user-001", "staff@example.com", "admin")
+
+	handler := RequireRole(logger, "admin", "adjuster")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{"admin", token("admin"), http.StatusNoContent},
+		{"adjuster", token("adjuster"), http.StatusNoContent},
+		{"customer", token(""), http.StatusForbidden},
+		{"wrong secret", "Bearer " + forged, http.StatusUnauthorized},
+		{"missing", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin/search/reindex", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Status mismatch: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestIdentifyRole(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	signed, err := auth.NewJWTManager("test-secret", time.Hour).GenerateWithRole("adj-001", "staff@example.com", "adjuster")
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	forged, _ := auth.NewJWTManager("other-secret", time.Hour).GenerateWithRole("adj-001", "staff@example.com", "admin")
+
+	var gotUser, gotRole string
+	handler := AuthMiddleware(logger)(IdentifyRole(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotRole = GetUserID(r), GetRole(r)
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUser      string
+		wantRole      string
+	}{
+		{"staff token", "Bearer " + signed, http.StatusNoContent, "adj-001", "adjuster"},
+		{"customer header", "", http.StatusNoContent, "cust-002", ""},
+		{"wrong secret", "Bearer " + forged, http.StatusUnauthorized, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser, gotRole = "", ""
+			req := httptest.NewRequest("GET", "/search?q=auto", nil)
+			req.Header.Set("X-User-ID", "cust-002")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus || gotUser != tt.wantUser || gotRole != tt.wantRole {
+				t.Errorf("Got %d as %q (%q), want %d as %q (%q)", rec.Code, gotUser, gotRole, tt.wantStatus, tt.wantUser, tt.wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Entity types that can be searched
+const (
+	TypeClaim    = "claim"
+	TypeCustomer = "customer"
+	TypePolicy   = "policy"
+)
+
+// ValidateType checks if an entity type is searchable
+func ValidateType(entityType string) bool {
+	switch entityType {
+	case TypeClaim, TypeCustomer, TypePolicy:
+		return true
+	}
+	return false
+}
+
+// Document is one entity as it is indexed. Text is matched word by word;
+// Keywords hold identifiers such as policy numbers and email addresses,
+// which also match whole or by prefix.
+type Document struct {
+	Type       string
+	ID         string
+	CustomerID string // the customer the entity belongs to, for permission filtering
+	Title      string
+	Text       []string
+	Keywords   []string
+}
+
+// Key identifies a document in the index
+func (d Document) Key() string {
+	return d.Type + ":" + d.ID
+}
+
+// Query is one search. An empty CustomerID searches every customer's
+// entities; otherwise only that customer's are matched.
+type Query struct {
+	Text       string
+	Types      []string // entity types to include; empty includes all
+	CustomerID string
+	Limit      int
+}
+
+// Result is one matching entity, tagged with its type
+type Result struct {
+	Type       string  `json:"type"`
+	ID         string  `json:"id"`
+	CustomerID string  `json:"customerId"`
+	Title      string  `json:"title"`
+	Score      float64 `json:"score"`
+}
+
+// SearchResponse is the body returned by GET /search
+type SearchResponse struct {
+	Query   string   `json:"query"`
+	Total   int      `json:"total"` // matches before the limit was applied
+	Results []Result `json:"results"`
+}
+
+// ReindexResult reports what one reindex pass changed. A source that could
+// not be read keeps its documents from the previous pass.
+type ReindexResult struct {
+	Indexed   map[string]int `json:"indexed"` // documents per entity type
+	Removed   int            `json:"removed"`
+	Failed    []string       `json:"failed"` // entity types whose source could not be read
+	IndexedAt time.Time      `json:"indexedAt"`
+}
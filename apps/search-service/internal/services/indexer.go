@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/index"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// ClaimLister lists claims from claims-service
+type ClaimLister interface {
+	ListClaims(ctx context.Context) ([]clients.Claim, error)
+}
+
+// CustomerLister lists customers from customer-service
+type CustomerLister interface {
+	ListCustomers(ctx context.Context) ([]clients.Customer, error)
+}
+
+// PolicyLister lists policies from policy-service
+type PolicyLister interface {
+	ListPolicies(ctx context.Context) ([]clients.Policy, error)
+}
+
+var (
+	_ ClaimLister    = (*clients.ClaimsClient)(nil)
+	_ CustomerLister = (*clients.CustomerClient)(nil)
+	_ PolicyLister   = (*clients.PolicyClient)(nil)
+)
+
+// source lists the documents of one entity type
+type source struct {
+	entityType string
+	documents  func(ctx context.Context) ([]models.Document, error)
+}
+
+// Indexer keeps the index in step with the services that own the indexed
+// entities. Each pass reads every entity, indexes it and removes documents
+// for entities that no longer exist. It runs on a schedule and can be
+// triggered by staff.
+type Indexer struct {
+	index   index.Index
+	sources []source
+	mu      sync.Mutex                 // one pass at a time
+	keys    map[string]map[string]bool // indexed keys by entity type
+	logger  *logrus.Logger
+}
+
+// NewIndexer creates an indexer. Any lister may be nil when its service is
+// not configured; its entities are then not searchable.
+func NewIndexer(idx index.Index, claims ClaimLister, customers CustomerLister, policies PolicyLister, logger *logrus.Logger) *Indexer {
+	i := &Indexer{
+		index:  idx,
+		keys:   make(map[string]map[string]bool),
+		logger: logger,
+	}
+	if claims != nil {
+		i.sources = append(i.sources, source{models.TypeClaim, func(ctx context.Context) ([]models.Document, error) {
+			list, err := claims.ListClaims(ctx)
+			if err != nil {
+				return nil, err
+			}
+			docs := make([]models.Document, len(list))
+			for n, claim := range list {
+				docs[n] = claimDocument(claim)
+			}
+			return docs, nil
+		}})
+	}
+	if customers != nil {
+		i.sources = append(i.sources, source{models.TypeCustomer, func(ctx context.Context) ([]models.Document, error) {
+			list, err := customers.ListCustomers(ctx)
+			if err != nil {
+				return nil, err
+			}
+			docs := make([]models.Document, len(list))
+			for n, customer := range list {
+				docs[n] = customerDocument(customer)
+			}
+			return docs, nil
+		}})
+	}
+	if policies != nil {
+		i.sources = append(i.sources, source{models.TypePolicy, func(ctx context.Context) ([]models.Document, error) {
+			list, err := policies.ListPolicies(ctx)
+			if err != nil {
+				return nil, err
+			}
+			docs := make([]models.Document, len(list))
+			for n, policy := range list {
+				docs[n] = policyDocument(policy)
+			}
+			return docs, nil
+		}})
+	}
+	return i
+}
+
+// Reindex reads every source and brings the index up to date
+func (i *Indexer) Reindex(ctx context.Context) *models.ReindexResult {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	result := &models.ReindexResult{
+		Indexed:   make(map[string]int),
+		Failed:    []string{},
+		IndexedAt: time.Now(),
+	}
+	for _, src := range i.sources {
+		docs, err := src.documents(ctx)
+		if err == nil {
+			err = i.index.Put(docs...)
+		}
+		if err != nil {
+			i.logger.WithError(err).WithField("type", src.entityType).Error("Failed to index entities")
+			result.Failed = append(result.Failed, src.entityType)
+			continue
+		}
+
+		current := make(map[string]bool, len(docs))
+		for _, doc := range docs {
+			current[doc.Key()] = true
+		}
+		var removed []string
+		for key := range i.keys[src.entityType] {
+			if !current[key] {
+				removed = append(removed, key)
+			}
+		}
+		if err := i.index.Delete(removed...); err != nil {
+			// Keep the old keys so the next pass tries again
+			i.logger.WithError(err).WithField("type", src.entityType).Error("Failed to remove deleted entities")
+			for _, key := range removed {
+				current[key] = true
+			}
+		} else {
+			result.Removed += len(removed)
+		}
+
+		i.keys[src.entityType] = current
+		result.Indexed[src.entityType] = len(docs)
+	}
+
+	i.logger.WithFields(logrus.Fields{
+		"indexed": result.Indexed,
+		"removed": result.Removed,
+		"failed":  result.Failed,
+	}).Info("Search index refreshed")
+
+	return result
+}
+
+// Run reindexes every interval until ctx is cancelled
+func (i *Indexer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			i.Reindex(ctx)
+		}
+	}
+}
+
+// claimDocument indexes a claim by its number and description
+func claimDocument(claim clients.Claim) models.Document {
+	return models.Document{
+		Type:       models.TypeClaim,
+		ID:         claim.ID,
+		CustomerID: claim.CustomerID,
+		Title:      claim.ClaimNumber,
+		Text:       []string{claim.ClaimNumber, claim.Type, claim.Description},
+		Keywords:   []string{claim.ID, claim.ClaimNumber},
+	}
+}
+
+// customerDocument indexes a customer by name and email address
+func customerDocument(customer clients.Customer) models.Document {
+	name := strings.TrimSpace(customer.FirstName + " " + customer.LastName)
+	return models.Document{
+		Type:       models.TypeCustomer,
+		ID:         customer.ID,
+		CustomerID: customer.ID,
+		Title:      name,
+		Text:       []string{name, customer.Email},
+		Keywords:   []string{customer.ID, customer.Email},
+	}
+}
+
+// policyDocument indexes a policy by its number
+func policyDocument(policy clients.Policy) models.Document {
+	return models.Document{
+		Type:       models.TypePolicy,
+		ID:         policy.ID,
+		CustomerID: policy.CustomerID,
+		Title:      policy.PolicyNumber,
+		Text:       []string{policy.PolicyNumber, policy.Type},
+		Keywords:   []string{policy.ID, policy.PolicyNumber},
+	}
+}
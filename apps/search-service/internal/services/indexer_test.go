@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/clients"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/index"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// stubLister returns whatever its fields hold at the time of the call
+type stubLister struct {
+	claims    []clients.Claim
+	customers []clients.Customer
+	policies  []clients.Policy
+	err       error
+}
+
+func (s *stubLister) ListClaims(ctx context.Context) ([]clients.Claim, error) {
+	return s.claims, s.err
+}
+
+func (s *stubLister) ListCustomers(ctx context.Context) ([]clients.Customer, error) {
+	return s.customers, s.err
+}
+
+func (s *stubLister) ListPolicies(ctx context.Context) ([]clients.Policy, error) {
+	return s.policies, s.err
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestBleve(t *testing.T) *index.Bleve {
+	t.Helper()
+	idx, err := index.NewBleve()
+	if err != nil {
+		t.Fatalf("NewBleve failed: %v", err)
+	}
+	t.Cleanup(func() { idx.Close() })
+	return idx
+}
+
+func count(t *testing.T, idx index.Index, text string) int {
+	t.Helper()
+	_, total, err := idx.Search(models.Query{Text: text})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	return total
+}
+
+func TestReindexIndexesAndRemoves(t *testing.T) {
+	idx := newTestBleve(t)
+	claims := &stubLister{claims: []clients.Claim{
+		{ID: "clm-001", CustomerID: "cust-001", ClaimNumber: "CLM-2024-001", Type: "auto", Description: "Cracked windshield"},
+		{ID: "clm-002", CustomerID: "cust-002", ClaimNumber: "CLM-2024-002", Type: "home", Description: "Hail damage to roof"},
+	}}
+	customers := &stubLister{customers: []clients.Customer{
+		{ID: "cust-001", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com"},
+	}}
+	indexer := NewIndexer(idx, claims, customers, nil, testLogger())
+
+	result := indexer.Reindex(context.Background())
+	if result.Indexed[models.TypeClaim] != 2 || result.Indexed[models.TypeCustomer] != 1 || len(result.Failed) != 0 {
+		t.Fatalf("First pass: %+v", result)
+	}
+	if count(t, idx, "windshield") != 1 || count(t, idx, "john.doe@example.com") != 1 {
+		t.Fatal("Indexed entities should be searchable")
+	}
+
+	claims.claims = claims.claims[:1]
+	result = indexer.Reindex(context.Background())
+	if result.Removed != 1 {
+		t.Errorf("Removed: got %d, want 1", result.Removed)
+	}
+	if count(t, idx, "hail") != 0 {
+		t.Error("Deleted claim should no longer be searchable")
+	}
+}
+
+func TestReindexKeepsDocumentsOfFailedSource(t *testing.T) {
+	idx := newTestBleve(t)
+	policies := &stubLister{policies: []clients.Policy{
+		{ID: "pol-001", CustomerID: "cust-001", PolicyNumber: "AUTO-2024-001", Type: "auto"},
+	}}
+	indexer := NewIndexer(idx, nil, nil, policies, testLogger())
+	indexer.Reindex(context.Background())
+
+	policies.err = errors.New("policy-service unavailable")
+	result := indexer.Reindex(context.Background())
+	if len(result.Failed) != 1 || result.Failed[0] != models.TypePolicy || result.Removed != 0 {
+		t.Fatalf("Failed pass: %+v", result)
+	}
+	if count(t, idx, "AUTO-2024-001") != 1 {
+		t.Error("Policies from the previous pass should stay searchable")
+	}
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/index"
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// staffRoles can search every customer's entities
+var staffRoles = map[string]bool{
+	"admin":    true,
+	"adjuster": true,
+}
+
+// SearchService answers searches, limited to what the caller may see
+type SearchService struct {
+	index  index.Index
+	logger *logrus.Logger
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(idx index.Index, logger *logrus.Logger) *SearchService {
+	return &SearchService{
+		index:  idx,
+		logger: logger,
+	}
+}
+
+// Search finds entities matching text. Staff, identified by role, search
+// every customer; anyone else only finds their own customer record, claims
+// and policies. A limit of 0 returns index.DefaultLimit results.
+func (s *SearchService) Search(userID, role, text string, types []string, limit int) (*models.SearchResponse, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("q is required")
+	}
+	for _, entityType := range types {
+		if !models.ValidateType(entityType) {
+			return nil, fmt.Errorf("invalid type: %s", entityType)
+		}
+	}
+	if limit < 0 || limit > index.MaxLimit {
+		return nil, fmt.Errorf("limit must be between 1 and %d", index.MaxLimit)
+	}
+
+	q := models.Query{Text: text, Types: types, Limit: limit}
+	if !staffRoles[role] {
+		q.CustomerID = userID
+	}
+
+	results, total, err := s.index.Search(q)
+	if err != nil {
+		s.logger.WithError(err).WithField("query", text).Error("Search failed")
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"userId": userID,
+		"role":   role,
+		"types":  types,
+		"total":  total,
+	}).Debug("Search completed")
+
+	return &models.SearchResponse{
+		Query:   text,
+		Total:   total,
+		Results: results,
+	}, nil
+}
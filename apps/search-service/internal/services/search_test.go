@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/internal/models"
+)
+
+func TestSearchFiltersByCaller(t *testing.T) {
+	idx := newTestBleve(t)
+	idx.Put(
+		models.Document{Type: models.TypeClaim, ID: "clm-001", CustomerID: "cust-001", Text: []string{"Flooded basement"}},
+		models.Document{Type: models.TypeClaim, ID: "clm-002", CustomerID: "cust-002", Text: []string{"Flooded garage"}},
+	)
+	service := NewSearchService(idx, testLogger())
+
+	tests := []struct {
+		name   string
+		userID string
+		role   string
+		want   int
+	}{
+		{"customer", "cust-001", "", 1},
+		{"other customer", "cust-003", "", 0},
+		{"adjuster", "adj-001", "adjuster", 2},
+		{"admin", "admin-001", "admin", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.Search(tt.userID, tt.role, "flooded", nil, 0)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if response.Total != tt.want || len(response.Results) != tt.want {
+				t.Errorf("Results: got %d, want %d", response.Total, tt.want)
+			}
+			for _, r := range response.Results {
+				if tt.role == "" && r.CustomerID != tt.userID {
+					t.Errorf("Customer %s found %s's %s", tt.userID, r.CustomerID, r.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchValidation(t *testing.T) {
+	service := NewSearchService(newTestBleve(t), testLogger())
+
+	tests := []struct {
+		name    string
+		text    string
+		types   []string
+		limit   int
+		wantErr string
+	}{
+		{"blank query", "  ", nil, 0, "q is required"},
+		{"unknown type", "doe", []string{"invoice"}, 0, "invalid type: invoice"},
+		{"limit too high", "doe", nil, 101, "limit must be between 1 and 100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Search("cust-001", "", tt.text, tt.types, tt.limit)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Error mismatch: got %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
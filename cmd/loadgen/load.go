@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result is what one scenario measured
+type Result struct {
+	Scenario string
+	Requests int           // requests that got a response or failed
+	Errors   int           // responses other than 200 and 201, and failed requests
+	Elapsed  time.Duration // how long the scenario ran
+	P50      time.Duration // latency percentiles of successful requests
+	P95      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+	Statuses map[int]int    // error responses by status code
+	Failures map[string]int // requests without a response, by error
+}
+
+// Throughput is the requests completed per second
+func (r *Result) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// ErrorSummary lists the errors by status code and failure, most common first
+func (r *Result) ErrorSummary() string {
+	type count struct {
+		what string
+		n    int
+	}
+	var counts []count
+	for status, n := range r.Statuses {
+		counts = append(counts, count{fmt.Sprintf("%d %s", status, http.StatusText(status)), n})
+	}
+	for failure, n := range r.Failures {
+		counts = append(counts, count{failure, n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].n != counts[j].n {
+			return counts[i].n > counts[j].n
+		}
+		return counts[i].what < counts[j].what
+	})
+
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		parts[i] = fmt.Sprintf("%d x %s", c.n, c.what)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// worker is what one worker measured, merged into the Result at the end
+type worker struct {
+	latencies []time.Duration
+	requests  int
+	statuses  map[int]int
+	failures  map[string]int
+}
+
+// Run sends scenario's requests from concurrency workers, each waiting for
+// its response before sending the next, until duration has passed or ctx
+// is cancelled
+func Run(ctx context.Context, client *http.Client, scenario Scenario, concurrency int, duration time.Duration) *Result {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	workers := make([]*worker, concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range workers {
+		w := &worker{statuses: make(map[int]int), failures: make(map[string]int)}
+		workers[i] = w
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for n := 0; ctx.Err() == nil; n++ {
+				w.send(ctx, client, scenario, id, n)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	result := &Result{
+		Scenario: scenario.Name,
+		Elapsed:  time.Since(start),
+		Statuses: make(map[int]int),
+		Failures: make(map[string]int),
+	}
+	var latencies []time.Duration
+	for _, w := range workers {
+		result.Requests += w.requests
+		latencies = append(latencies, w.latencies...)
+		for status, n := range w.statuses {
+			result.Statuses[status] += n
+			result.Errors += n
+		}
+		for failure, n := range w.failures {
+			result.Failures[failure] += n
+			result.Errors += n
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.P50 = percentile(latencies, 50)
+	result.P95 = percentile(latencies, 95)
+	result.P99 = percentile(latencies, 99)
+	if len(latencies) > 0 {
+		result.Max = latencies[len(latencies)-1]
+	}
+	return result
+}
+
+func (w *worker) send(ctx context.Context, client *http.Client, scenario Scenario, id, n int) {
+	req, err := scenario.Request(id, n)
+	if err != nil {
+		w.requests++
+		w.failures[err.Error()]++
+		return
+	}
+
+	began := time.Now()
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		// Requests cut off by the end of the run are not counted
+		if ctx.Err() != nil {
+			return
+		}
+		w.requests++
+		w.failures[failureReason(err)]++
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	elapsed := time.Since(began)
+
+	// A quote answered with 202 was queued rather than calculated, so only
+	// 200 and 201 count as served
+	w.requests++
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		w.statuses[resp.StatusCode]++
+		return
+	}
+	w.latencies = append(w.latencies, elapsed)
+}
+
+// failureReason groups failed requests by cause rather than by URL
+func failureReason(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "Client.Timeout"), strings.Contains(msg, "deadline exceeded"):
+		return "timeout"
+	case strings.Contains(msg, "connection refused"):
+		return "connection refused"
+	case strings.Contains(msg, "connection reset"):
+		return "connection reset"
+	default:
+		return msg
+	}
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
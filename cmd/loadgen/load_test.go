@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPercentileUsesNearestRank(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 200; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 100 * time.Millisecond},
+		{95, 190 * time.Millisecond},
+		{99, 198 * time.Millisecond},
+		{100, 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(latencies, tt.p); got != tt.want {
+			t.Errorf("p%.0f = %s, want %s", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(latencies[:1], 99); got != time.Millisecond {
+		t.Errorf("p99 of one request = %s, want that request", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("p50 of no requests = %s, want 0", got)
+	}
+}
+
+func TestRunCountsErrorsApartFromLatencies(t *testing.T) {
+	var mu sync.Mutex
+	descriptions := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-User-ID") != "cust-001" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Description string `json:"description"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if descriptions[body.Description] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		descriptions[body.Description] = true
+		if len(descriptions)%10 == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	scenarios, err := SelectScenarios("create-claim", Targets{ClaimsURL: server.URL, UserID: "cust-001", PolicyID: "pol-001", ClaimType: "accident"})
+	if err != nil {
+		t.Fatalf("SelectScenarios failed: %v", err)
+	}
+	result := Run(context.Background(), server.Client(), scenarios[0], 4, 200*time.Millisecond)
+
+	if result.Requests == 0 {
+		t.Fatal("Expected requests to be sent")
+	}
+	if result.Statuses[http.StatusConflict] != 0 {
+		t.Errorf("Claims were filed as duplicates %d times, want unique descriptions", result.Statuses[http.StatusConflict])
+	}
+	if result.Errors != result.Statuses[http.StatusInternalServerError] || result.Errors == 0 {
+		t.Errorf("Expected only the 500s as errors, got %d errors: %s", result.Errors, result.ErrorSummary())
+	}
+	if result.P50 <= 0 || result.P50 > result.P95 || result.P95 > result.P99 || result.P99 > result.Max {
+		t.Errorf("Percentiles out of order: p50 %s, p95 %s, p99 %s, max %s", result.P50, result.P95, result.P99, result.Max)
+	}
+	if !strings.Contains(result.ErrorSummary(), "x 500 Internal Server Error") {
+		t.Errorf("Unexpected error summary %q", result.ErrorSummary())
+	}
+}
+
+func TestSelectScenariosRejectsUnknownNames(t *testing.T) {
+	if _, err := SelectScenarios("quote,bogus", Targets{}); err == nil {
+		t.Error("Expected an unknown scenario to be refused")
+	}
+	scenarios, err := SelectScenarios("list-claims, quote", Targets{})
+	if err != nil {
+		t.Fatalf("SelectScenarios failed: %v", err)
+	}
+	if len(scenarios) != 2 || scenarios[0].Name != "list-claims" || scenarios[1].Name != "quote" {
+		t.Errorf("Unexpected scenarios %v", scenarios)
+	}
+}
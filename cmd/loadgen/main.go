@@ -0,0 +1,140 @@
+// Command loadgen puts synthetic load on the pricing and claims hot paths
+// of a running InsuranceStack and reports latency percentiles per request
+// type, so performance regressions show up before a release.
+//
+// Usage:
+//
+//	loadgen [-scenarios quote,create-claim,list-claims] [-concurrency N] [-duration D]
+//	        [-pricing URL] [-claims URL] [-max-p99 D]
+//
+// Each scenario runs for -duration in turn, with -concurrency workers
+// sending requests back to back.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("loadgen", flag.ExitOnError)
+	pricingURL := fs.String("pricing", envOr("PRICING_SERVICE_URL", "http://localhost:8003"), "base URL of pricing-engine (default: $PRICING_SERVICE_URL)")
+	claimsURL := fs.String("claims", envOr("CLAIMS_SERVICE_URL", "http://localhost:8002"), "base URL of claims-service (default: $CLAIMS_SERVICE_URL)")
+	names := fs.String("scenarios", strings.Join(ScenarioNames, ","), "comma-separated scenarios to run")
+	concurrency := fs.Int("concurrency", 10, "workers sending requests at once")
+	duration := fs.Duration("duration", 30*time.Second, "how long each scenario runs")
+	timeout := fs.Duration("timeout", 5*time.Second, "time allowed for each request")
+	apiKey := fs.String("api-key", "", "X-API-Key sent with quotes; pricing-engine meters quotes per key")
+	userID := fs.String("user", "cust-001", "customer who files and lists claims (X-User-ID)")
+	policyID := fs.String("policy", "pol-001", "policy claims are filed against")
+	claimType := fs.String("claim-type", "accident", "type of the claims filed; must suit the policy's line")
+	token := fs.String("token", "", "staff JWT sent when listing claims, to list every claim instead of the customer's")
+	maxP99 := fs.Duration("max-p99", 0, "fail if any scenario's p99 latency is above this (0 disables)")
+	fs.Parse(args)
+
+	if *concurrency < 1 {
+		return fmt.Errorf("-concurrency must be at least 1")
+	}
+	if *duration <= 0 {
+		return fmt.Errorf("-duration must be greater than zero")
+	}
+
+	targets := Targets{
+		PricingURL: strings.TrimRight(*pricingURL, "/"),
+		ClaimsURL:  strings.TrimRight(*claimsURL, "/"),
+		APIKey:     *apiKey,
+		UserID:     *userID,
+		PolicyID:   *policyID,
+		ClaimType:  *claimType,
+		Token:      *token,
+	}
+	scenarios, err := SelectScenarios(*names, targets)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C stops the scenario running and reports what was measured
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := &http.Client{
+		Timeout: *timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        *concurrency,
+			MaxIdleConnsPerHost: *concurrency,
+		},
+	}
+
+	var results []*Result
+	for _, scenario := range scenarios {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("Running %s for %s with %d workers\n", scenario.Name, *duration, *concurrency)
+		results = append(results, Run(ctx, client, scenario, *concurrency, *duration))
+	}
+
+	printResults(results)
+
+	var slow []string
+	for _, r := range results {
+		if *maxP99 > 0 && r.P99 > *maxP99 {
+			slow = append(slow, fmt.Sprintf("%s p99 %s", r.Scenario, round(r.P99)))
+		}
+	}
+	if len(slow) > 0 {
+		return fmt.Errorf("over -max-p99 %s: %s", *maxP99, strings.Join(slow, ", "))
+	}
+	return nil
+}
+
+func printResults(results []*Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSCENARIO\tREQUESTS\tERRORS\tREQ/S\tP50\tP95\tP99\tMAX")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\n",
+			r.Scenario, r.Requests, r.Errors, r.Throughput(),
+			round(r.P50), round(r.P95), round(r.P99), round(r.Max))
+	}
+	w.Flush()
+
+	for _, r := range results {
+		if r.Errors == 0 {
+			continue
+		}
+		fmt.Printf("%s errors: %s\n", r.Scenario, r.ErrorSummary())
+	}
+}
+
+// round trims latencies to a readable precision for the report
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= 100*time.Millisecond:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
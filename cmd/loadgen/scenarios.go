@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ScenarioNames are the scenarios loadgen knows, in the order they run
+var ScenarioNames = []string{"quote", "create-claim", "list-claims"}
+
+// Targets are the services under load and who the requests act as
+type Targets struct {
+	PricingURL string
+	ClaimsURL  string
+	APIKey     string
+	UserID     string
+	PolicyID   string
+	ClaimType  string
+	Token      string
+}
+
+// Scenario is one kind of request to put load on
+type Scenario struct {
+	Name string
+	// Request builds the n-th request a worker sends
+	Request func(worker, n int) (*http.Request, error)
+}
+
+// SelectScenarios returns the named scenarios, given as a comma-separated
+// list of ScenarioNames
+func SelectScenarios(names string, t Targets) ([]Scenario, error) {
+	// Each claim's description ends in a word unique to the run, worker and
+	// request, so filed claims never look like duplicates of one another
+	run := fmt.Sprintf("%x", time.Now().UnixNano())
+
+	var scenarios []Scenario
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "quote":
+			scenarios = append(scenarios, Scenario{Name: "quote", Request: t.quote})
+		case "create-claim":
+			scenarios = append(scenarios, Scenario{Name: "create-claim", Request: func(worker, n int) (*http.Request, error) {
+				return t.createClaim(run, worker, n)
+			}})
+		case "list-claims":
+			scenarios = append(scenarios, Scenario{Name: "list-claims", Request: t.listClaims})
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown scenario %q (want one of %s)", name, strings.Join(ScenarioNames, ", "))
+		}
+	}
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenarios selected")
+	}
+	return scenarios, nil
+}
+
+// quote prices an auto policy, varying the applicant so quotes are
+// calculated rather than served from a cache
+func (t Targets) quote(worker, n int) (*http.Request, error) {
+	body := map[string]interface{}{
+		"policyType":     "auto",
+		"coverageAmount": 50000 + 10000*(n%20),
+		"customerAge":    18 + (worker+n)%60,
+		"riskScore":      1 + n%5,
+		"state":          "CA",
+		"zipCode":        "94105",
+	}
+	req, err := jsonRequest(http.MethodPost, t.PricingURL+"/quote", body)
+	if err != nil {
+		return nil, err
+	}
+	if t.APIKey != "" {
+		req.Header.Set("X-API-Key", t.APIKey)
+	}
+	return req, nil
+}
+
+// createClaim files a new claim on the target policy
+func (t Targets) createClaim(run string, worker, n int) (*http.Request, error) {
+	body := map[string]interface{}{
+		"policyId":    t.PolicyID,
+		"customerId":  t.UserID,
+		"type":        t.ClaimType,
+		"amount":      250 + 25*(n%40),
+		"description": fmt.Sprintf("Loadgen %sw%dn%d", run, worker, n),
+	}
+	req, err := jsonRequest(http.MethodPost, t.ClaimsURL+"/claims", body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", t.UserID)
+	return req, nil
+}
+
+// listClaims lists the customer's claims, or every claim with a staff token
+func (t Targets) listClaims(worker, n int) (*http.Request, error) {
+	target := t.ClaimsURL + "/claims"
+	if t.Token == "" {
+		target += "?customerId=" + url.QueryEscape(t.UserID)
+	}
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-User-ID", t.UserID)
+	if t.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.Token)
+	}
+	return req, nil
+}
+
+func jsonRequest(method, target string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequest(method, target, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
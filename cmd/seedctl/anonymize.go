@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Anonymizer replaces personal data in a dataset with pseudonyms. Each
+// pseudonym is derived from an HMAC of the original value, so the same
+// name, email, phone or address always maps to the same replacement under
+// one key, and the original cannot be recovered without the key.
+//
+// IDs are kept as they are, so policies, claims and payments still reference
+// their customers. City, state and country are kept, and postal codes keep
+// their first three characters, so pricing territories and currencies stay
+// realistic.
+type Anonymizer struct {
+	key []byte
+}
+
+// NewAnonymizer creates an anonymizer. Exports made with the same key get
+// the same pseudonyms.
+func NewAnonymizer(key []byte) *Anonymizer {
+	return &Anonymizer{key: key}
+}
+
+// Dataset returns an anonymized copy of ds
+func (a *Anonymizer) Dataset(ds *Dataset) *Dataset {
+	out := &Dataset{
+		Customers: make([]Customer, len(ds.Customers)),
+		Policies:  append([]Policy(nil), ds.Policies...),
+		Claims:    make([]Claim, len(ds.Claims)),
+		Payments:  append([]Payment(nil), ds.Payments...),
+	}
+	for i, c := range ds.Customers {
+		out.Customers[i] = a.customer(c)
+	}
+	for i, c := range ds.Claims {
+		if c.LossLocation != nil {
+			loc := *c.LossLocation
+			if loc.Street != "" {
+				loc.Street = a.street(loc.Country, loc.Street)
+			}
+			loc.PostalCode = a.postalCode(loc.PostalCode)
+			c.LossLocation = &loc
+		}
+		out.Claims[i] = c
+	}
+	return out
+}
+
+func (a *Anonymizer) customer(c Customer) Customer {
+	loc := localeFor(c.Address.Country)
+	first := loc.firstNames[a.pick("first-name", c.FirstName, len(loc.firstNames))]
+	last := loc.lastNames[a.pick("last-name", c.LastName, len(loc.lastNames))]
+
+	c.FirstName = first
+	c.LastName = last
+	if c.Email != "" {
+		// The digest keeps distinct addresses distinct when names collide
+		c.Email = fmt.Sprintf("%s.%s.%s@example.com", emailPart(first), emailPart(last), a.digest("email", c.Email)[:8])
+	}
+	if c.Phone != "" {
+		// The generator's numbers are in ranges reserved for fiction, so a
+		// pseudonym never reaches a real subscriber
+		c.Phone = fmt.Sprintf(loc.phone, a.sum("phone", c.Phone)%10000)
+	}
+	if c.Address.Street != "" {
+		c.Address.Street = a.street(c.Address.Country, c.Address.Street)
+	}
+	c.Address.ZipCode = a.postalCode(c.Address.ZipCode)
+	return c
+}
+
+// street replaces a street address with a house number and street from the
+// country's pool
+func (a *Anonymizer) street(country, street string) string {
+	loc := localeFor(country)
+	sum := a.sum("street", street)
+	return fmt.Sprintf("%d %s", 1+sum%250, loc.streets[(sum/250)%uint64(len(loc.streets))])
+}
+
+// postalCode keeps the first three characters, which locate the area, and
+// replaces the rest while keeping its shape
+func (a *Anonymizer) postalCode(code string) string {
+	if len(code) <= 3 {
+		return code
+	}
+	return code[:3] + a.replaceChars("postal-code", code, code[3:])
+}
+
+// replaceChars swaps each digit for a digit and each letter for a letter,
+// chosen by the HMAC of the whole original value
+func (a *Anonymizer) replaceChars(kind, original, s string) string {
+	sum := a.mac(kind, original)
+	var b strings.Builder
+	for i, r := range s {
+		n := int(sum[i%len(sum)])
+		switch {
+		case unicode.IsDigit(r):
+			b.WriteByte(byte('0' + n%10))
+		case unicode.IsUpper(r):
+			b.WriteByte(byte('A' + n%26))
+		case unicode.IsLower(r):
+			b.WriteByte(byte('a' + n%26))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pick chooses an index in [0, n) for a value
+func (a *Anonymizer) pick(kind, value string, n int) int {
+	return int(a.sum(kind, value) % uint64(n))
+}
+
+func (a *Anonymizer) sum(kind, value string) uint64 {
+	return binary.BigEndian.Uint64(a.mac(kind, value))
+}
+
+func (a *Anonymizer) digest(kind, value string) string {
+	return hex.EncodeToString(a.mac(kind, value))
+}
+
+// mac hashes a value, normalized so case and surrounding space do not
+// change its pseudonym. The kind keeps equal values in different fields,
+// such as a first and last name, from sharing a hash.
+func (a *Anonymizer) mac(kind, value string) []byte {
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(kind + ":" + strings.ToLower(strings.TrimSpace(value))))
+	return h.Sum(nil)
+}
+
+// localeFor returns the name and address pools for a country, falling back
+// to the first locale for countries the generator does not cover
+func localeFor(country string) locale {
+	for _, loc := range locales {
+		if loc.country == country {
+			return loc
+		}
+	}
+	return locales[0]
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeReplacesPersonalData(t *testing.T) {
+	ds, err := Generate(testConfig())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	anon := NewAnonymizer([]byte("test-key")).Dataset(ds)
+
+	if problems := Validate(anon); len(problems) > 0 {
+		t.Fatalf("Anonymized dataset has %d problems, first: %s", len(problems), problems[0])
+	}
+	if !reflect.DeepEqual(anon.Policies, ds.Policies) || !reflect.DeepEqual(anon.Payments, ds.Payments) {
+		t.Error("Policies and payments hold no personal data and should be unchanged")
+	}
+
+	emails := make(map[string]bool)
+	for i, c := range anon.Customers {
+		original := ds.Customers[i]
+		if c.ID != original.ID || c.Address.City != original.Address.City || c.RiskScore != original.RiskScore {
+			t.Errorf("Customer %s: non-personal fields changed", original.ID)
+		}
+		if c.Email == original.Email || c.Phone == original.Phone || c.Address.Street == original.Address.Street {
+			t.Errorf("Customer %s kept personal data: %+v", original.ID, c)
+		}
+		if !strings.HasSuffix(c.Email, "@example.com") || emails[c.Email] {
+			t.Errorf("Customer %s: email %q should be a unique example.com address", c.ID, c.Email)
+		}
+		emails[c.Email] = true
+		if c.Address.ZipCode[:3] != original.Address.ZipCode[:3] || len(c.Address.ZipCode) != len(original.Address.ZipCode) {
+			t.Errorf("Customer %s: postal code %q should keep the area of %q", c.ID, c.Address.ZipCode, original.Address.ZipCode)
+		}
+	}
+	if reflect.DeepEqual(ds.Customers[0], anon.Customers[0]) {
+		t.Error("Anonymize should not modify its input")
+	}
+}
+
+func TestAnonymizeIsStable(t *testing.T) {
+	ds := &Dataset{Customers: []Customer{
+		{ID: "cust-001", FirstName: "Sarah", LastName: "Chen", Email: "sarah.chen@insurancestack.com", Phone: "+44-20-7946-0958", Address: Address{Street: "221B Baker Street", City: "London", ZipCode: "NW1 6XE", Country: "UK"}},
+		{ID: "cust-002", FirstName: "sarah ", LastName: "Chen", Email: "SARAH.CHEN@insurancestack.com", Phone: "+44-20-7946-0958", Address: Address{Street: "221B Baker Street", City: "London", ZipCode: "NW1 6XE", Country: "UK"}},
+	}}
+
+	first := NewAnonymizer([]byte("key-a")).Dataset(ds)
+	again := NewAnonymizer([]byte("key-a")).Dataset(ds)
+	if !reflect.DeepEqual(first, again) {
+		t.Fatal("The same key should give the same pseudonyms")
+	}
+
+	// The same person's details map to the same pseudonyms wherever they
+	// appear, ignoring case and surrounding space
+	a, b := first.Customers[0], first.Customers[1]
+	if a.FirstName != b.FirstName || a.Email != b.Email || a.Phone != b.Phone || a.Address != b.Address {
+		t.Errorf("Equal values got different pseudonyms: %+v and %+v", a, b)
+	}
+
+	other := NewAnonymizer([]byte("key-b")).Dataset(ds)
+	if other.Customers[0].Email == a.Email {
+		t.Error("A different key should give different pseudonyms")
+	}
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// GeneratorConfig controls the size and shape of a generated dataset
+type GeneratorConfig struct {
+	Customers int
+	Policies  int
+	Claims    int
+	Seed      int64
+	AsOf      time.Time
+}
+
+// locale holds the name and address pools for one customer country
+type locale struct {
+	country    string
+	phone      string
+	firstNames []string
+	lastNames  []string
+	cities     []struct{ city, state, zip string }
+	streets    []string
+}
+
+var locales = []locale{
+	{
+		country:    "US",
+		phone:      "+1-555-%04d",
+		firstNames: []string{"James", "Emma", "Olivia", "Liam", "Ava", "Noah", "Mia", "Ethan", "Sophia", "Mason"},
+		lastNames:  []string{"Wilson", "Brown", "Johnson", "Miller", "Davis", "Taylor", "Anderson", "Thomas", "Moore", "Clark"},
+		cities: []struct{ city, state, zip string }{
+			{"San Francisco", "CA", "94102"}, {"Austin", "TX", "78701"}, {"Chicago", "IL", "60601"},
+			{"Seattle", "WA", "98101"}, {"Boston", "MA", "02108"}, {"Denver", "CO", "80202"},
+		},
+		streets: []string{"Main Street", "Oak Avenue", "Maple Drive", "Pine Street", "Cedar Lane", "Elm Street"},
+	},
+	{
+		country:    "UK",
+		phone:      "+44-20-7946-%04d",
+		firstNames: []string{"Oliver", "Amelia", "George", "Isla", "Harry", "Grace", "Jack", "Poppy"},
+		lastNames:  []string{"Smith", "Jones", "Williams", "Evans", "Roberts", "Walker", "Wright", "Hughes"},
+		cities: []struct{ city, state, zip string }{
+			{"London", "", "NW1 6XE"}, {"Manchester", "", "M1 1AE"}, {"Bristol", "", "BS1 4DJ"},
+		},
+		streets: []string{"Baker Street", "High Street", "Station Road", "Church Lane"},
+	},
+	{
+		country:    "FR",
+		phone:      "+33-1-42-86-%04d",
+		firstNames: []string{"Louis", "Camille", "Hugo", "Chloé", "Lucas", "Léa"},
+		lastNames:  []string{"Dubois", "Martin", "Bernard", "Lefèvre", "Moreau", "Laurent"},
+		cities: []struct{ city, state, zip string }{
+			{"Paris", "", "75008"}, {"Lyon", "", "69002"}, {"Marseille", "", "13001"},
+		},
+		streets: []string{"Rue de Rivoli", "Avenue des Champs-Élysées", "Rue de la République"},
+	},
+	{
+		country:    "DE",
+		phone:      "+49-30-1234-%04d",
+		firstNames: []string{"Lukas", "Anna", "Felix", "Lena", "Jonas", "Marie"},
+		lastNames:  []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Wagner"},
+		cities: []struct{ city, state, zip string }{
+			{"Berlin", "", "10115"}, {"Munich", "", "80331"}, {"Hamburg", "", "20095"},
+		},
+		streets: []string{"Hauptstraße", "Bahnhofstraße", "Gartenstraße"},
+	},
+}
+
+// productLine describes how policies of one type are priced and claimed against
+type productLine struct {
+	prefix      string
+	base        float64
+	coverages   []int
+	deductibles []int
+	termYears   int
+	claimTypes  []string
+}
+
+var productLines = map[string]productLine{
+	"auto": {
+		prefix:      "AUTO",
+		base:        800,
+		coverages:   []int{250000, 300000, 400000, 500000},
+		deductibles: []int{500, 750, 1000},
+		termYears:   1,
+		claimTypes:  []string{"accident", "theft", "damage"},
+	},
+	"home": {
+		prefix:      "HOME",
+		base:        1200,
+		coverages:   []int{500000, 650000, 750000, 1000000, 1200000},
+		deductibles: []int{1000, 1500, 2000},
+		termYears:   1,
+		claimTypes:  []string{"damage", "theft"},
+	},
+	"life": {
+		prefix:      "LIFE",
+		base:        500,
+		coverages:   []int{250000, 500000, 750000, 1000000},
+		deductibles: []int{0},
+		termYears:   20,
+	},
+}
+
+// policyMix weights the product lines picked for new policies
+var policyMix = []string{"auto", "auto", "auto", "home", "home", "life"}
+
+var claimDescriptions = map[string][]string{
+	"accident": {
+		"Rear-end collision at a traffic light. Damage to rear bumper and trunk.",
+		"Side-swipe on the motorway while changing lanes. Driver-side doors damaged.",
+		"Hit a parked car while reversing out of a parking space.",
+		"Minor fender bender in a supermarket car park.",
+	},
+	"theft": {
+		"Vehicle stereo and navigation system stolen overnight.",
+		"Burglary - laptop, jewelry and camera equipment taken.",
+		"Bicycle stolen from locked garage.",
+	},
+	"damage": {
+		"Water damage from burst pipe in basement. Flooring and drywall affected.",
+		"Windshield cracked by flying debris on the highway.",
+		"Roof tiles dislodged during storm. Water ingress in attic.",
+		"Kitchen fire from faulty appliance. Cabinets and ceiling damaged.",
+	},
+}
+
+// rejectionCodes mirror the claims-service rejection taxonomy
+var rejectionCodes = []string{"not_covered", "late_filing", "fraud_suspected", "insufficient_docs", "duplicate"}
+
+var riskMultipliers = map[int]float64{1: 0.8, 2: 1.0, 3: 1.3, 4: 1.6, 5: 2.0}
+
+// generator builds datasets from a seeded source so runs are reproducible
+type generator struct {
+	cfg GeneratorConfig
+	rnd *rand.Rand
+
+	policyNumbers map[string]int
+	claimSeq      map[int]int
+}
+
+// Generate builds a dataset with coherent cross-references: every policy
+// belongs to a generated customer, every claim is filed against a policy of
+// the same customer within its term, and payments cover each policy premium
+// and each approved claim.
+func Generate(cfg GeneratorConfig) (*Dataset, error) {
+	if cfg.Customers <= 0 {
+		return nil, fmt.Errorf("customers must be positive")
+	}
+	if cfg.Policies < cfg.Customers {
+		return nil, fmt.Errorf("policies must be at least the number of customers")
+	}
+	if cfg.Claims < 0 {
+		return nil, fmt.Errorf("claims must not be negative")
+	}
+	if cfg.AsOf.IsZero() {
+		cfg.AsOf = time.Now()
+	}
+	cfg.AsOf = cfg.AsOf.UTC().Truncate(time.Minute)
+
+	g := &generator{
+		cfg:           cfg,
+		rnd:           rand.New(rand.NewSource(cfg.Seed)),
+		policyNumbers: make(map[string]int),
+		claimSeq:      make(map[int]int),
+	}
+
+	ds := &Dataset{}
+	for i := 1; i <= cfg.Customers; i++ {
+		ds.Customers = append(ds.Customers, g.customer(i))
+	}
+
+	// Every customer holds at least one policy; the remainder are spread randomly
+	for i := 1; i <= cfg.Policies; i++ {
+		owner := ds.Customers[(i-1)%len(ds.Customers)]
+		if i > len(ds.Customers) {
+			owner = ds.Customers[g.rnd.Intn(len(ds.Customers))]
+		}
+		ds.Policies = append(ds.Policies, g.policy(i, owner))
+	}
+
+	claimable := make([]Policy, 0, len(ds.Policies))
+	for _, policy := range ds.Policies {
+		if len(productLines[policy.Type].claimTypes) > 0 && policy.StartDate.Before(cfg.AsOf.AddDate(0, 0, -7)) {
+			claimable = append(claimable, policy)
+		}
+	}
+	if cfg.Claims > 0 && len(claimable) == 0 {
+		return nil, fmt.Errorf("no auto or home policies old enough to claim against")
+	}
+	owners := make(map[string]Customer, len(ds.Customers))
+	for _, customer := range ds.Customers {
+		owners[customer.ID] = customer
+	}
+	for i := 1; i <= cfg.Claims; i++ {
+		policy := claimable[g.rnd.Intn(len(claimable))]
+		ds.Claims = append(ds.Claims, g.claim(i, policy, owners[policy.CustomerID]))
+	}
+
+	for _, policy := range ds.Policies {
+		ds.Payments = append(ds.Payments, g.premiumPayment(len(ds.Payments)+1, policy))
+	}
+	for _, claim := range ds.Claims {
+		if claim.Status == "approved" {
+			ds.Payments = append(ds.Payments, g.payoutPayment(len(ds.Payments)+1, claim))
+		}
+	}
+
+	return ds, nil
+}
+
+func (g *generator) customer(n int) Customer {
+	loc := locales[g.rnd.Intn(len(locales))]
+	first := loc.firstNames[g.rnd.Intn(len(loc.firstNames))]
+	last := loc.lastNames[g.rnd.Intn(len(loc.lastNames))]
+	city := loc.cities[g.rnd.Intn(len(loc.cities))]
+
+	created := g.between(g.cfg.AsOf.AddDate(-3, 0, 0), g.cfg.AsOf.AddDate(0, -1, 0))
+	dob := g.cfg.AsOf.AddDate(-(18 + g.rnd.Intn(60)), -g.rnd.Intn(12), -g.rnd.Intn(28))
+
+	return Customer{
+		ID:          fmt.Sprintf("cust-%03d", n),
+		Email:       fmt.Sprintf("%s.%s.%d@example.com", emailPart(first), emailPart(last), n),
+		FirstName:   first,
+		LastName:    last,
+		Phone:       fmt.Sprintf(loc.phone, g.rnd.Intn(10000)),
+		DateOfBirth: dob.Format("2006-01-02"),
+		Address: Address{
+			Street:  fmt.Sprintf("%d %s", 1+g.rnd.Intn(250), loc.streets[g.rnd.Intn(len(loc.streets))]),
+			City:    city.city,
+			State:   city.state,
+			ZipCode: city.zip,
+			Country: loc.country,
+		},
+		RiskScore: 1 + g.rnd.Intn(5),
+		CreatedAt: created,
+		UpdatedAt: g.between(created, g.cfg.AsOf),
+	}
+}
+
+func (g *generator) policy(n int, owner Customer) Policy {
+	policyType := policyMix[g.rnd.Intn(len(policyMix))]
+	line := productLines[policyType]
+
+	coverage := line.coverages[g.rnd.Intn(len(line.coverages))]
+	start := g.between(owner.CreatedAt, g.cfg.AsOf).Truncate(24 * time.Hour)
+	end := start.AddDate(line.termYears, 0, 0)
+	renewal := start.AddDate(1, 0, 0)
+
+	status := "active"
+	switch {
+	case end.Before(g.cfg.AsOf):
+		status = "lapsed"
+	case g.rnd.Float64() < 0.05:
+		status = "cancelled"
+	}
+
+	issued := start.Add(time.Duration(8+g.rnd.Intn(9)) * time.Hour)
+
+	return Policy{
+		ID:           fmt.Sprintf("pol-%03d", n),
+		PolicyNumber: g.policyNumber(line.prefix, start.Year()),
+		CustomerID:   owner.ID,
+		Type:         policyType,
+		Status:       status,
+		Premium:      premium(line, coverage, owner.RiskScore),
+		Coverage:     coverage,
+		Deductible:   line.deductibles[g.rnd.Intn(len(line.deductibles))],
+		StartDate:    start,
+		EndDate:      end,
+		RenewalDate:  renewal,
+		CreatedAt:    issued,
+		UpdatedAt:    issued,
+	}
+}
+
+func (g *generator) claim(n int, policy Policy, owner Customer) Claim {
+	line := productLines[policy.Type]
+	claimType := line.claimTypes[g.rnd.Intn(len(line.claimTypes))]
+	descriptions := claimDescriptions[claimType]
+
+	latest := policy.EndDate
+	if latest.After(g.cfg.AsOf) {
+		latest = g.cfg.AsOf
+	}
+	submitted := g.between(policy.StartDate.AddDate(0, 0, 7), latest)
+
+	// Losses are reported within a few days, near the policyholder's home
+	incident := submitted.Add(-time.Duration(g.rnd.Intn(5*24)) * time.Hour)
+	if incident.Before(policy.StartDate) {
+		incident = policy.StartDate
+	}
+	location := &Location{
+		City:       owner.Address.City,
+		State:      owner.Address.State,
+		PostalCode: owner.Address.ZipCode,
+		Country:    owner.Address.Country,
+	}
+
+	// Claims are capped by coverage; most are small relative to it
+	amount := math.Round((float64(policy.Deductible)+g.rnd.Float64()*math.Min(25000, float64(policy.Coverage)/10))/100) * 100
+	if amount <= 0 {
+		amount = 100
+	}
+
+	claim := Claim{
+		ID:            fmt.Sprintf("claim-%03d", n),
+		ClaimNumber:   g.claimNumber(submitted.Year()),
+		PolicyID:      policy.ID,
+		CustomerID:    policy.CustomerID,
+		Type:          claimType,
+		Amount:        amount,
+		Description:   descriptions[g.rnd.Intn(len(descriptions))],
+		IncidentDate:  &incident,
+		LossLocation:  location,
+		SubmittedDate: submitted,
+		CreatedAt:     submitted,
+		UpdatedAt:     submitted,
+	}
+
+	// Older claims have usually been decided; recent ones are still open
+	age := g.cfg.AsOf.Sub(submitted)
+	switch {
+	case age < 3*24*time.Hour:
+		claim.Status = "submitted"
+	case age < 14*24*time.Hour && g.rnd.Float64() < 0.6:
+		claim.Status = "under_review"
+		reviewed := submitted.Add(time.Duration(1+g.rnd.Intn(48)) * time.Hour)
+		claim.UpdatedAt = reviewed
+	default:
+		reviewed := submitted.Add(time.Duration(24+g.rnd.Intn(24*7)) * time.Hour)
+		claim.ReviewedDate = &reviewed
+		claim.UpdatedAt = reviewed
+		if g.rnd.Float64() < 0.8 {
+			claim.Status = "approved"
+			claim.ApprovedDate = &reviewed
+		} else {
+			claim.Status = "rejected"
+			claim.RejectionCodes = []string{rejectionCodes[g.rnd.Intn(len(rejectionCodes))]}
+		}
+	}
+
+	return claim
+}
+
+func (g *generator) premiumPayment(n int, policy Policy) Payment {
+	created := policy.CreatedAt.Add(15 * time.Minute)
+	payment := Payment{
+		ID:            fmt.Sprintf("pay-%03d", n),
+		Type:          "premium",
+		PolicyID:      policy.ID,
+		CustomerID:    policy.CustomerID,
+		Amount:        policy.Premium,
+		PaymentMethod: g.paymentMethod(),
+		CreatedAt:     created,
+		UpdatedAt:     created,
+	}
+
+	if g.rnd.Float64() < 0.05 {
+		payment.Status = "failed"
+		return payment
+	}
+	processed := created.Add(15 * time.Minute)
+	payment.Status = "completed"
+	payment.ProcessedDate = &processed
+	payment.UpdatedAt = processed
+	return payment
+}
+
+func (g *generator) payoutPayment(n int, claim Claim) Payment {
+	claimID := claim.ID
+	created := *claim.ApprovedDate
+	payment := Payment{
+		ID:            fmt.Sprintf("pay-%03d", n),
+		Type:          "payout",
+		PolicyID:      claim.PolicyID,
+		ClaimID:       &claimID,
+		CustomerID:    claim.CustomerID,
+		Amount:        claim.Amount,
+		PaymentMethod: "bank_transfer",
+		CreatedAt:     created,
+		UpdatedAt:     created,
+	}
+
+	// Payouts settle within a few days of approval
+	processed := created.Add(time.Duration(24+g.rnd.Intn(72)) * time.Hour)
+	if processed.After(g.cfg.AsOf) {
+		payment.Status = "pending"
+		return payment
+	}
+	payment.Status = "completed"
+	payment.ProcessedDate = &processed
+	payment.UpdatedAt = processed
+	return payment
+}
+
+func (g *generator) policyNumber(prefix string, year int) string {
+	key := fmt.Sprintf("%s-%d", prefix, year)
+	g.policyNumbers[key]++
+	return fmt.Sprintf("%s-%03d", key, g.policyNumbers[key])
+}
+
+func (g *generator) claimNumber(year int) string {
+	g.claimSeq[year]++
+	return fmt.Sprintf("CLM-%d-%05d", year, g.claimSeq[year])
+}
+
+func (g *generator) paymentMethod() string {
+	methods := []string{"credit_card", "debit_card", "bank_transfer"}
+	return methods[g.rnd.Intn(len(methods))]
+}
+
+// between returns a random minute-aligned time in [from, to)
+func (g *generator) between(from, to time.Time) time.Time {
+	if !to.After(from) {
+		return from
+	}
+	offset := time.Duration(g.rnd.Int63n(int64(to.Sub(from))))
+	return from.Add(offset).Truncate(time.Minute)
+}
+
+// premium applies the coverage and risk factors to the line's base rate,
+// rounded to the nearest 10 like the hand-written fixtures
+func premium(line productLine, coverage, riskScore int) float64 {
+	coverageFactor := math.Sqrt(float64(coverage) / float64(line.coverages[0]))
+	raw := line.base * coverageFactor * riskMultipliers[riskScore]
+	return math.Round(raw/10) * 10
+}
+
+// emailPart lower-cases a name and strips characters that are unsafe in addresses
+func emailPart(name string) string {
+	replacer := strings.NewReplacer("é", "e", "è", "e", "ç", "c", "ü", "u", "ö", "o", "ä", "a", "ß", "ss", " ", "")
+	return replacer.Replace(strings.ToLower(name))
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testConfig() GeneratorConfig {
+	return GeneratorConfig{
+		Customers: 20,
+		Policies:  35,
+		Claims:    25,
+		Seed:      42,
+		AsOf:      time.Date(2024, 12, 15, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestGenerateProducesConsistentDataset(t *testing.T) {
+	ds, err := Generate(testConfig())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(ds.Customers) != 20 || len(ds.Policies) != 35 || len(ds.Claims) != 25 {
+		t.Fatalf("Unexpected counts: %d customers, %d policies, %d claims",
+			len(ds.Customers), len(ds.Policies), len(ds.Claims))
+	}
+	if problems := Validate(ds); len(problems) > 0 {
+		t.Errorf("Generated dataset has %d problems, first: %s", len(problems), problems[0])
+	}
+
+	owners := make(map[string]bool)
+	for _, p := range ds.Policies {
+		owners[p.CustomerID] = true
+	}
+	if len(owners) != len(ds.Customers) {
+		t.Errorf("Every customer should hold a policy: %d of %d do", len(owners), len(ds.Customers))
+	}
+
+	policies := make(map[string]Policy)
+	for _, p := range ds.Policies {
+		policies[p.ID] = p
+	}
+	for _, c := range ds.Claims {
+		policy := policies[c.PolicyID]
+		if policy.Type == "life" {
+			t.Errorf("Claim %s filed against life policy %s", c.ID, policy.ID)
+		}
+		if c.SubmittedDate.Before(policy.StartDate) || c.SubmittedDate.After(policy.EndDate) {
+			t.Errorf("Claim %s submitted outside the term of policy %s", c.ID, policy.ID)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	first, err := Generate(testConfig())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	second, err := Generate(testConfig())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Same seed and as-of date should produce identical datasets")
+	}
+}
+
+func TestGenerateRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*GeneratorConfig)
+	}{
+		{"no customers", func(c *GeneratorConfig) { c.Customers = 0 }},
+		{"fewer policies than customers", func(c *GeneratorConfig) { c.Policies = c.Customers - 1 }},
+		{"negative claims", func(c *GeneratorConfig) { c.Claims = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+			if _, err := Generate(cfg); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestValidateReportsBrokenReferences(t *testing.T) {
+	ds, err := Generate(testConfig())
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	ds.Claims[0].PolicyID = "pol-missing"
+	ds.Payments[0].CustomerID = "cust-missing"
+	early := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	ds.Claims[1].IncidentDate = &early
+
+	problems := Validate(ds)
+	for _, want := range []string{
+		"claim claim-001 references unknown policy pol-missing",
+		"payment pay-001 customer cust-missing does not own policy pol-001",
+		"claim claim-002 incident 2000-01-01 is outside policy " + ds.Claims[1].PolicyID + " term",
+	} {
+		found := false
+		for _, problem := range problems {
+			if problem == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected problem %q, got %v", want, problems)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Command seedctl generates synthetic fixture datasets for InsuranceStack and
+// resets demo and test environments back to their baseline data.
+//
+// Usage:
+//
+//	seedctl generate [-customers N] [-policies N] [-claims N] [-seed N] [-as-of DATE] [-dir PATH]
+//	seedctl validate [-dir PATH]
+//	seedctl export -out PATH [-anonymize] [-key KEY] [-dir PATH]
+//	seedctl reset [-dir PATH]
+//
+// Services read the seed directory at startup, so restart them after
+// generating, exporting or resetting data.
+package main
+
+import (
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "generate":
+		err = runGenerate(os.Args[2:])
+	case "validate":
+		err = runValidate(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	case "reset":
+		err = runReset(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "seedctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `seedctl manages InsuranceStack seed data
+
+Commands:
+  generate   write a synthetic dataset of customers, policies, claims and payments
+  validate   check a seed directory for broken cross-references
+  export     copy a dataset to another seed directory, optionally anonymized
+  reset      restore the fixtures that were in place before the first generate
+
+Run "seedctl <command> -h" for command flags.
+`)
+}
+
+// defaultDataPath mirrors the services: DATA_PATH, falling back to data/seed
+func defaultDataPath() string {
+	if dir := os.Getenv("DATA_PATH"); dir != "" {
+		return dir
+	}
+	return filepath.Join("data", "seed")
+}
+
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	dir := fs.String("dir", defaultDataPath(), "seed directory to write")
+	customers := fs.Int("customers", 25, "number of customers")
+	policies := fs.Int("policies", 40, "number of policies (at least one per customer)")
+	claims := fs.Int("claims", 15, "number of claims")
+	seed := fs.Int64("seed", 1, "random seed; the same seed and as-of date produce the same dataset")
+	asOf := fs.String("as-of", "", "reference date (YYYY-MM-DD) that generated history leads up to (default: today)")
+	dryRun := fs.Bool("dry-run", false, "generate and validate without writing files")
+	fs.Parse(args)
+
+	cfg := GeneratorConfig{
+		Customers: *customers,
+		Policies:  *policies,
+		Claims:    *claims,
+		Seed:      *seed,
+	}
+	if *asOf != "" {
+		t, err := time.Parse("2006-01-02", *asOf)
+		if err != nil {
+			return fmt.Errorf("invalid -as-of date %q: %w", *asOf, err)
+		}
+		cfg.AsOf = t
+	}
+
+	ds, err := Generate(cfg)
+	if err != nil {
+		return err
+	}
+	if problems := Validate(ds); len(problems) > 0 {
+		return fmt.Errorf("generated dataset is inconsistent: %s", problems[0])
+	}
+
+	summary := fmt.Sprintf("%d customers, %d policies, %d claims, %d payments",
+		len(ds.Customers), len(ds.Policies), len(ds.Claims), len(ds.Payments))
+	if *dryRun {
+		fmt.Printf("Generated %s (dry run, nothing written)\n", summary)
+		return nil
+	}
+
+	if err := NewDirSink(*dir).Write(ds); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s to %s\n", summary, *dir)
+	return nil
+}
+
+func runValidate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	dir := fs.String("dir", defaultDataPath(), "seed directory to check")
+	fs.Parse(args)
+
+	ds, err := LoadDataset(*dir)
+	if err != nil {
+		return err
+	}
+
+	problems := Validate(ds)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problems found in %s", len(problems), *dir)
+	}
+	fmt.Printf("%s is consistent: %d customers, %d policies, %d claims, %d payments\n",
+		*dir, len(ds.Customers), len(ds.Policies), len(ds.Claims), len(ds.Payments))
+	return nil
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("dir", defaultDataPath(), "seed directory to read")
+	out := fs.String("out", "", "seed directory to write (required)")
+	anonymize := fs.Bool("anonymize", false, "replace names, emails, phones and addresses with stable pseudonyms")
+	key := fs.String("key", os.Getenv("SEEDCTL_ANONYMIZE_KEY"), "secret for -anonymize; exports with the same key get the same pseudonyms (default: $SEEDCTL_ANONYMIZE_KEY, or random)")
+	fs.Parse(args)
+
+	if *out == "" {
+		return fmt.Errorf("-out is required")
+	}
+	if filepath.Clean(*out) == filepath.Clean(*dir) {
+		return fmt.Errorf("-out must differ from -dir")
+	}
+
+	ds, err := LoadDataset(*dir)
+	if err != nil {
+		return err
+	}
+
+	if *anonymize {
+		secret := []byte(*key)
+		if len(secret) == 0 {
+			secret = make([]byte, 32)
+			if _, err := rand.Read(secret); err != nil {
+				return fmt.Errorf("failed to generate key: %w", err)
+			}
+			fmt.Println("No -key given: pseudonyms are random for this export; pass -key to repeat them")
+		}
+		ds = NewAnonymizer(secret).Dataset(ds)
+	}
+	if problems := Validate(ds); len(problems) > 0 {
+		return fmt.Errorf("exported dataset is inconsistent: %s", problems[0])
+	}
+
+	if err := NewDirSink(*out).Write(ds); err != nil {
+		return err
+	}
+	mode := ""
+	if *anonymize {
+		mode = " anonymized"
+	}
+	fmt.Printf("Exported%s %d customers, %d policies, %d claims, %d payments from %s to %s\n",
+		mode, len(ds.Customers), len(ds.Policies), len(ds.Claims), len(ds.Payments), *dir, *out)
+	return nil
+}
+
+func runReset(args []string) error {
+	fs := flag.NewFlagSet("reset", flag.ExitOnError)
+	dir := fs.String("dir", defaultDataPath(), "seed directory to restore")
+	fs.Parse(args)
+
+	if err := NewDirSink(*dir).Reset(); err != nil {
+		return err
+	}
+	fmt.Printf("Restored baseline fixtures in %s\n", *dir)
+	return nil
+}
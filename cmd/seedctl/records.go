@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The record types below mirror the JSON layout of data/seed so generated
+// files load into every service unchanged.
+
+// Address is a customer postal address
+type Address struct {
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	ZipCode string `json:"zipCode"`
+	Country string `json:"country"`
+}
+
+// Customer is a row in customers.json
+type Customer struct {
+	ID          string    `json:"id"`
+	Email       string    `json:"email"`
+	FirstName   string    `json:"firstName"`
+	LastName    string    `json:"lastName"`
+	Phone       string    `json:"phone"`
+	DateOfBirth string    `json:"dateOfBirth"`
+	Address     Address   `json:"address"`
+	RiskScore   int       `json:"riskScore"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	// Set by customer-service; carried through exports unchanged
+	Preferences     json.RawMessage `json:"preferences,omitempty"`
+	EmailVerified   bool            `json:"emailVerified"`
+	EmailVerifiedAt *time.Time      `json:"emailVerifiedAt,omitempty"`
+}
+
+// Policy is a row in policies.json
+type Policy struct {
+	ID           string    `json:"id"`
+	PolicyNumber string    `json:"policyNumber"`
+	CustomerID   string    `json:"customerId"`
+	QuoteID      string    `json:"quoteId,omitempty"`
+	Type         string    `json:"type"`
+	Status       string    `json:"status"`
+	Premium      float64   `json:"premium"`
+	Coverage     int       `json:"coverage"`
+	Deductible   int       `json:"deductible"`
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate"`
+	RenewalDate  time.Time `json:"renewalDate"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Claim is a row in claims.json
+type Claim struct {
+	ID             string     `json:"id"`
+	ClaimNumber    string     `json:"claimNumber"`
+	PolicyID       string     `json:"policyId"`
+	CustomerID     string     `json:"customerId"`
+	Type           string     `json:"type"`
+	Status         string     `json:"status"`
+	Amount         float64    `json:"amount"`
+	Description    string     `json:"description"`
+	SubmittedDate  time.Time  `json:"submittedDate"`
+	ReviewedDate   *time.Time `json:"reviewedDate"`
+	ApprovedDate   *time.Time `json:"approvedDate"`
+	RejectionCodes []string   `json:"rejectionCodes,omitempty"` // rejected claims only
+	IncidentDate   *time.Time `json:"incidentDate,omitempty"`
+	LossLocation   *Location  `json:"lossLocation,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// Location is where a claimed loss happened
+type Location struct {
+	Street     string `json:"street,omitempty"`
+	City       string `json:"city"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Country    string `json:"country"`
+}
+
+// Payment is a row in payments.json
+type Payment struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	PolicyID      string     `json:"policyId"`
+	ClaimID       *string    `json:"claimId"`
+	CustomerID    string     `json:"customerId"`
+	Amount        float64    `json:"amount"`
+	Status        string     `json:"status"`
+	PaymentMethod string     `json:"paymentMethod"`
+	ProcessedDate *time.Time `json:"processedDate"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// Dataset is a coherent set of customers and the records that reference them
+type Dataset struct {
+	Customers []Customer
+	Policies  []Policy
+	Claims    []Claim
+	Payments  []Payment
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Seed files owned by seedctl. Other fixtures in the seed directory
+// (pricing rules, users, accounts, ...) are left untouched.
+const (
+	customersFile = "customers.json"
+	policiesFile  = "policies.json"
+	claimsFile    = "claims.json"
+	paymentsFile  = "payments.json"
+)
+
+var managedFiles = []string{customersFile, policiesFile, claimsFile, paymentsFile}
+
+// schemaVersions is the schema version of the records seedctl generates for
+// each managed file, the latest migration of the service that owns it (see
+// pkg/migrate). Keep it in step with those migrations: a file written at an
+// older version would be migrated again on load. Files at version 0 are
+// written as bare arrays, as the fixtures are.
+var schemaVersions = map[string]int{
+	customersFile: 0,
+	policiesFile:  0,
+	claimsFile:    0,
+	paymentsFile:  0,
+}
+
+// versionedFile is a fixture wrapped in the schema version it was written in
+type versionedFile struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// baselineDir holds the pristine fixtures captured before the first write
+const baselineDir = ".baseline"
+
+// Sink is a destination for generated datasets. The services currently load
+// their data from the seed directory at startup, so DirSink is the only
+// implementation; a database-backed sink can satisfy the same interface.
+type Sink interface {
+	Write(ds *Dataset) error
+	Reset() error
+}
+
+// DirSink writes datasets as JSON fixtures into a seed directory
+type DirSink struct {
+	dir string
+}
+
+// NewDirSink creates a sink for the given seed directory
+func NewDirSink(dir string) *DirSink {
+	return &DirSink{dir: dir}
+}
+
+// Write replaces the managed fixtures with the dataset. The existing fixtures
+// are saved to the baseline directory the first time so Reset can restore them.
+func (s *DirSink) Write(ds *Dataset) error {
+	if err := s.captureBaseline(); err != nil {
+		return err
+	}
+
+	files := map[string]interface{}{
+		customersFile: ds.Customers,
+		policiesFile:  ds.Policies,
+		claimsFile:    ds.Claims,
+		paymentsFile:  ds.Payments,
+	}
+	for _, name := range managedFiles {
+		var v interface{} = files[name]
+		if version := schemaVersions[name]; version > 0 {
+			data, err := json.Marshal(files[name])
+			if err != nil {
+				return fmt.Errorf("failed to encode %s: %w", name, err)
+			}
+			v = versionedFile{SchemaVersion: version, Data: data}
+		}
+		if err := writeJSON(filepath.Join(s.dir, name), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset restores the managed fixtures from the baseline directory
+func (s *DirSink) Reset() error {
+	baseline := filepath.Join(s.dir, baselineDir)
+	if _, err := os.Stat(baseline); os.IsNotExist(err) {
+		return fmt.Errorf("no baseline in %s: nothing has been generated into this directory", s.dir)
+	}
+
+	for _, name := range managedFiles {
+		if err := copyFile(filepath.Join(baseline, name), filepath.Join(s.dir, name)); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s *DirSink) captureBaseline() error {
+	baseline := filepath.Join(s.dir, baselineDir)
+	if _, err := os.Stat(baseline); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(baseline, 0755); err != nil {
+		return fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+	for _, name := range managedFiles {
+		src := filepath.Join(s.dir, name)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			// A fresh directory has an empty baseline
+			if err := writeJSON(filepath.Join(baseline, name), []interface{}{}); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := copyFile(src, filepath.Join(baseline, name)); err != nil {
+			return fmt.Errorf("failed to capture baseline %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// LoadDataset reads the managed fixtures from a seed directory, of any
+// schema version. Validation only needs fields every version has.
+func LoadDataset(dir string) (*Dataset, error) {
+	ds := &Dataset{}
+	targets := map[string]interface{}{
+		customersFile: &ds.Customers,
+		policiesFile:  &ds.Policies,
+		claimsFile:    &ds.Claims,
+		paymentsFile:  &ds.Payments,
+	}
+	for _, name := range managedFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := json.Unmarshal(unwrap(data), targets[name]); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+	return ds, nil
+}
+
+// unwrap returns the records of a fixture, without the schema version of a
+// versioned one
+func unwrap(data []byte) []byte {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return data
+	}
+	var file versionedFile
+	if err := json.Unmarshal(trimmed, &file); err != nil || file.Data == nil {
+		return data
+	}
+	return file.Data
+}
+
+// writeJSON writes v with the same two-space indentation as the fixtures.
+// The file is written to a temporary path and renamed so services never
+// observe a partially written fixture.
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	data = append(data, '\n')
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,89 @@
+package main
+
+import "fmt"
+
+// Validate checks a dataset for broken cross-references and returns one
+// message per problem found
+func Validate(ds *Dataset) []string {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	customers := make(map[string]bool, len(ds.Customers))
+	for _, c := range ds.Customers {
+		if customers[c.ID] {
+			report("duplicate customer id %s", c.ID)
+		}
+		customers[c.ID] = true
+	}
+
+	policies := make(map[string]Policy, len(ds.Policies))
+	policyNumbers := make(map[string]bool, len(ds.Policies))
+	for _, p := range ds.Policies {
+		if _, exists := policies[p.ID]; exists {
+			report("duplicate policy id %s", p.ID)
+		}
+		policies[p.ID] = p
+		if policyNumbers[p.PolicyNumber] {
+			report("duplicate policy number %s", p.PolicyNumber)
+		}
+		policyNumbers[p.PolicyNumber] = true
+		if !customers[p.CustomerID] {
+			report("policy %s references unknown customer %s", p.ID, p.CustomerID)
+		}
+		if p.EndDate.Before(p.StartDate) {
+			report("policy %s ends before it starts", p.ID)
+		}
+	}
+
+	claims := make(map[string]Claim, len(ds.Claims))
+	for _, c := range ds.Claims {
+		if _, exists := claims[c.ID]; exists {
+			report("duplicate claim id %s", c.ID)
+		}
+		claims[c.ID] = c
+		policy, ok := policies[c.PolicyID]
+		if !ok {
+			report("claim %s references unknown policy %s", c.ID, c.PolicyID)
+			continue
+		}
+		if c.CustomerID != policy.CustomerID {
+			report("claim %s customer %s does not own policy %s", c.ID, c.CustomerID, c.PolicyID)
+		}
+		if c.IncidentDate != nil {
+			if c.IncidentDate.Before(policy.StartDate) || c.IncidentDate.After(policy.EndDate) {
+				report("claim %s incident %s is outside policy %s term", c.ID, c.IncidentDate.Format("2006-01-02"), c.PolicyID)
+			}
+			if c.IncidentDate.After(c.SubmittedDate) {
+				report("claim %s incident is after its submission", c.ID)
+			}
+		}
+	}
+
+	payments := make(map[string]bool, len(ds.Payments))
+	for _, p := range ds.Payments {
+		if payments[p.ID] {
+			report("duplicate payment id %s", p.ID)
+		}
+		payments[p.ID] = true
+		policy, ok := policies[p.PolicyID]
+		if !ok {
+			report("payment %s references unknown policy %s", p.ID, p.PolicyID)
+			continue
+		}
+		if p.CustomerID != policy.CustomerID {
+			report("payment %s customer %s does not own policy %s", p.ID, p.CustomerID, p.PolicyID)
+		}
+		if p.ClaimID != nil {
+			claim, ok := claims[*p.ClaimID]
+			if !ok {
+				report("payment %s references unknown claim %s", p.ID, *p.ClaimID)
+			} else if claim.PolicyID != p.PolicyID {
+				report("payment %s claim %s belongs to policy %s", p.ID, claim.ID, claim.PolicyID)
+			}
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,9 @@
+// Package e2e runs end-to-end scenarios against all InsuranceStack services
+// booted in-process. Each test gets its own copy of the seed data and its own
+// httptest servers, then drives the services over HTTP the way the UI does
+// and checks that the records each service holds agree with one another.
+//
+// Run from this directory with:
+//
+//	go test ./...
+package e2e
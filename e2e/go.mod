@@ -0,0 +1,99 @@
+module github.com/CB-InsuranceStack/InsuranceStack/e2e
+
+go 1.21
+
+require (
+	github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/apps/search-service v0.0.0
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/faults v0.0.0
+	github.com/golang-jwt/jwt/v5 v5.2.0
+	github.com/sirupsen/logrus v1.9.3
+)
+
+require (
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/health v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/logging v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/persist v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/retention v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/rules v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/signing v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting v0.0.0 // indirect
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry v0.0.0 // indirect
+	github.com/RoaringBitmap/roaring v1.9.3 // indirect
+	github.com/bits-and-blooms/bitset v1.12.0 // indirect
+	github.com/blevesearch/bleve/v2 v2.4.2 // indirect
+	github.com/blevesearch/bleve_index_api v1.1.10 // indirect
+	github.com/blevesearch/geo v0.1.20 // indirect
+	github.com/blevesearch/go-faiss v1.0.20 // indirect
+	github.com/blevesearch/go-porterstemmer v1.0.3 // indirect
+	github.com/blevesearch/gtreap v0.1.1 // indirect
+	github.com/blevesearch/mmap-go v1.0.4 // indirect
+	github.com/blevesearch/scorch_segment_api/v2 v2.2.15 // indirect
+	github.com/blevesearch/segment v0.9.1 // indirect
+	github.com/blevesearch/snowballstem v0.9.0 // indirect
+	github.com/blevesearch/upsidedown_store_api v1.0.2 // indirect
+	github.com/blevesearch/vellum v1.0.10 // indirect
+	github.com/blevesearch/zapx/v11 v11.3.10 // indirect
+	github.com/blevesearch/zapx/v12 v12.3.10 // indirect
+	github.com/blevesearch/zapx/v13 v13.3.10 // indirect
+	github.com/blevesearch/zapx/v14 v14.3.10 // indirect
+	github.com/blevesearch/zapx/v15 v15.3.13 // indirect
+	github.com/blevesearch/zapx/v16 v16.1.5 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/expr-lang/expr v1.16.9 // indirect
+	github.com/golang/geo v0.0.0-20210211234256-740aa86cb551 // indirect
+	github.com/golang/protobuf v1.3.2 // indirect
+	github.com/golang/snappy v0.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gorilla/mux v1.8.1 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+	github.com/json-iterator/go v0.0.0-20171115153421-f7279a603ede // indirect
+	github.com/mschoch/smat v0.2.0 // indirect
+	github.com/redis/go-redis/v9 v9.7.3 // indirect
+	github.com/rs/cors v1.10.1 // indirect
+	go.etcd.io/bbolt v1.3.7 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+)
+
+replace (
+	github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service => ../apps/claims-service
+	github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service => ../apps/customer-service
+	github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service => ../apps/payments-service
+	github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service => ../apps/policy-service
+	github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine => ../apps/pricing-engine
+	github.com/CB-InsuranceStack/InsuranceStack/apps/search-service => ../apps/search-service
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting => ../pkg/alerting
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/blobstore => ../pkg/blobstore
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/contracts => ../pkg/contracts
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/faults => ../pkg/faults
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/health => ../pkg/health
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks => ../pkg/hooks
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n => ../pkg/i18n
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/lifecycle => ../pkg/lifecycle
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/logging => ../pkg/logging
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/maintenance => ../pkg/maintenance
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/migrate => ../pkg/migrate
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/persist => ../pkg/persist
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/retention => ../pkg/retention
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/rules => ../pkg/rules
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/secrets => ../pkg/secrets
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/shaping => ../pkg/shaping
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/signing => ../pkg/signing
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/targeting => ../pkg/targeting
+	github.com/CB-InsuranceStack/InsuranceStack/pkg/telemetry => ../pkg/telemetry
+)
@@ -0,0 +1,513 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	claims "github.com/CB-InsuranceStack/InsuranceStack/apps/claims-service/app"
+	customers "github.com/CB-InsuranceStack/InsuranceStack/apps/customer-service/app"
+	payments "github.com/CB-InsuranceStack/InsuranceStack/apps/payments-service/app"
+	policies "github.com/CB-InsuranceStack/InsuranceStack/apps/policy-service/app"
+	pricing "github.com/CB-InsuranceStack/InsuranceStack/apps/pricing-engine/app"
+	search "github.com/CB-InsuranceStack/InsuranceStack/apps/search-service/app"
+	"github.com/CB-InsuranceStack/InsuranceStack/pkg/faults"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// seedDir is the repository's fixture directory, relative to this package
+var seedDir = filepath.Join("..", "data", "seed")
+
+// environment is a full set of services sharing one copy of the seed data
+type environment struct {
+	Pricing   *service
+	Policies  *service
+	Claims    *service
+	Customers *service
+	Payments  *service
+	Search    *service
+}
+
+// service is one running service and a JSON client for it
+type service struct {
+	t      *testing.T
+	name   string
+	server *httptest.Server
+}
+
+// startEnvironment boots every service in-process against a temporary copy
+// of the seed data. Feature flags are pinned off so scenarios do not depend
+// on the caller's environment; tests that need a flag set it before calling.
+func startEnvironment(t *testing.T) *environment {
+	t.Helper()
+
+	for _, flag := range []string{"FEATURE_AUTO_APPROVAL", "FEATURE_DYNAMIC_RATES", "FEATURE_INSTANT_PAYOUTS", "FEATURE_MASK_AMOUNTS", "FEATURE_MAINTENANCE_MODE", "FEATURE_FAULT_INJECTION"} {
+		if _, set := os.LookupEnv(flag); !set {
+			t.Setenv(flag, "false")
+		}
+	}
+
+	dataPath := copySeedData(t)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	if os.Getenv("E2E_VERBOSE") != "" {
+		logger.SetOutput(os.Stderr)
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+
+	env := &environment{}
+	// Faults can only be injected when a scenario turns the flag on
+	faultConfig := faults.ConfigFromEnv(logger)
+
+	// Services start after the services they reference
+	customerApp, err := customers.New(customers.Config{DataPath: dataPath, FeatureAPIKey: "dev-mode", Faults: faultConfig}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start customer-service: %v", err)
+	}
+	env.Customers = serve(t, "customer-service", customerApp.Handler, customerApp.Close)
+
+	// pricing-engine checks household policies in policy-service, which
+	// reports bound quotes back, so the policy address is reserved before
+	// either starts
+	policyServer := httptest.NewUnstartedServer(nil)
+	policyURL := "http://" + policyServer.Listener.Addr().String()
+
+	pricingApp, err := pricing.New(pricing.Config{
+		DataPath:           dataPath,
+		FeatureAPIKey:      "dev-mode",
+		CustomerServiceURL: env.Customers.server.URL,
+		PolicyServiceURL:   policyURL,
+		Faults:             faultConfig,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start pricing-engine: %v", err)
+	}
+	env.Pricing = serve(t, "pricing-engine", pricingApp.Handler, pricingApp.Close)
+
+	// policy-service refunds through payments-service, which reads policies
+	// back, so the payments address is reserved before either starts
+	paymentsServer := httptest.NewUnstartedServer(nil)
+	paymentsURL := "http://" + paymentsServer.Listener.Addr().String()
+
+	policyApp, err := policies.New(policies.Config{
+		DataPath:           dataPath,
+		FeatureAPIKey:      "dev-mode",
+		CustomerServiceURL: env.Customers.server.URL,
+		PaymentsServiceURL: paymentsURL,
+		PricingServiceURL:  env.Pricing.server.URL,
+		GracePeriod:        30 * 24 * time.Hour,
+		CancellationNotice: 10 * 24 * time.Hour,
+		Faults:             faultConfig,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start policy-service: %v", err)
+	}
+	env.Policies = start(t, policyServer, "policy-service", policyApp.Handler, policyApp.Close)
+
+	claimsApp, err := claims.New(claims.Config{
+		DataPath:           dataPath,
+		FeatureAPIKey:      "dev-mode",
+		SSEHeartbeat:       time.Second,
+		PolicyServiceURL:   env.Policies.server.URL,
+		PaymentsServiceURL: paymentsURL,
+		Faults:             faultConfig,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start claims-service: %v", err)
+	}
+	env.Claims = serve(t, "claims-service", claimsApp.Handler, claimsApp.Close)
+
+	paymentsApp, err := payments.New(payments.Config{
+		DataPath:           dataPath,
+		FeatureAPIKey:      "dev-mode",
+		PolicyServiceURL:   env.Policies.server.URL,
+		ClaimsServiceURL:   env.Claims.server.URL,
+		CustomerServiceURL: env.Customers.server.URL,
+		JWTSecret:          "dev-secret-key-change-in-production",
+		Faults:             faultConfig,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start payments-service: %v", err)
+	}
+	env.Payments = start(t, paymentsServer, "payments-service", paymentsApp.Handler, paymentsApp.Close)
+
+	// search-service indexes once on startup; scenarios that change data
+	// refresh it through the reindex endpoint
+	searchApp, err := search.New(search.Config{
+		ClaimsServiceURL:   env.Claims.server.URL,
+		CustomerServiceURL: env.Customers.server.URL,
+		PolicyServiceURL:   env.Policies.server.URL,
+		JWTSecret:          "dev-secret-key-change-in-production",
+		Faults:             faultConfig,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start search-service: %v", err)
+	}
+	env.Search = serve(t, "search-service", searchApp.Handler, searchApp.Close)
+
+	return env
+}
+
+// startPolicyService boots policy-service alone with its changes persisted
+// in stateDir. Stopping it writes the final snapshot, as a shutdown would.
+func startPolicyService(t *testing.T, dataPath, stateDir string) (svc *service, stop func()) {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	policyApp, err := policies.New(policies.Config{
+		DataPath:      dataPath,
+		FeatureAPIKey: "dev-mode",
+		GracePeriod:   30 * 24 * time.Hour,
+		PersistDir:    stateDir,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to start policy-service: %v", err)
+	}
+	server := httptest.NewServer(policyApp.Handler)
+	return &service{t: t, name: "policy-service", server: server}, func() {
+		server.Close()
+		policyApp.Close()
+	}
+}
+
+func serve(t *testing.T, name string, handler http.Handler, closeApp func()) *service {
+	return start(t, httptest.NewUnstartedServer(nil), name, handler, closeApp)
+}
+
+// start serves handler on a server whose address may already be known to
+// other services
+func start(t *testing.T, server *httptest.Server, name string, handler http.Handler, closeApp func()) *service {
+	server.Config.Handler = handler
+	server.Start()
+	t.Cleanup(func() {
+		server.Close()
+		closeApp()
+	})
+	return &service{t: t, name: name, server: server}
+}
+
+// copySeedData copies the fixtures into a per-test directory so scenarios
+// never touch the repository's data
+func copySeedData(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	entries, err := os.ReadDir(seedDir)
+	if err != nil {
+		t.Fatalf("Failed to read seed data: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(seedDir, entry.Name()))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", entry.Name(), err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), data, 0644); err != nil {
+			t.Fatalf("Failed to copy %s: %v", entry.Name(), err)
+		}
+	}
+	return dir
+}
+
+// do sends a JSON request as userID and decodes a successful JSON response
+// into out. It returns the status code so callers can assert on failures.
+func (s *service) do(method, path, userID string, body, out interface{}) int {
+	s.t.Helper()
+	return s.send(method, path, userID, "", body, out)
+}
+
+// doAsStaff is do for back-office routes, authorized by a JWT carrying role
+func (s *service) doAsStaff(method, path, role string, body, out interface{}) int {
+	s.t.Helper()
+	return s.send(method, path, "", staffToken(s.t, role), body, out)
+}
+
+func (s *service) send(method, path, userID, token string, body, out interface{}) int {
+	s.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("Failed to encode %s %s body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, s.server.URL+path, reader)
+	if err != nil {
+		s.t.Fatalf("Failed to build %s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.server.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s %s %s failed: %v", s.name, method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			s.t.Fatalf("Failed to decode %s %s %s response: %v", s.name, method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// mustDo is do for calls that are expected to succeed with the given status
+func (s *service) mustDo(method, path, userID string, body, out interface{}, wantStatus int) {
+	s.t.Helper()
+	if status := s.do(method, path, userID, body, out); status != wantStatus {
+		s.t.Fatalf("%s %s %s: got status %d, want %d", s.name, method, path, status, wantStatus)
+	}
+}
+
+// downloadAsStaff fetches a back-office file as a JWT carrying role, for
+// responses that are not JSON
+func (s *service) downloadAsStaff(path, role string) (int, http.Header, []byte) {
+	s.t.Helper()
+	req, err := http.NewRequest("GET", s.server.URL+path, nil)
+	if err != nil {
+		s.t.Fatalf("Failed to build GET %s: %v", path, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+staffToken(s.t, role))
+
+	resp, err := s.server.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s GET %s failed: %v", s.name, path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.t.Fatalf("Failed to read %s GET %s response: %v", s.name, path, err)
+	}
+	return resp.StatusCode, resp.Header, body
+}
+
+// uploadAsStaff posts files as multipart/form-data, one file part per
+// entry of files, as a JWT carrying role, and decodes a successful JSON
+// response into out
+func (s *service) uploadAsStaff(path, role string, files map[string]string, out interface{}) int {
+	s.t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile(name, name)
+		if err != nil {
+			s.t.Fatalf("Failed to build POST %s upload: %v", path, err)
+		}
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	req, err := http.NewRequest("POST", s.server.URL+path, &body)
+	if err != nil {
+		s.t.Fatalf("Failed to build POST %s: %v", path, err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+staffToken(s.t, role))
+
+	resp, err := s.server.Client().Do(req)
+	if err != nil {
+		s.t.Fatalf("%s POST %s failed: %v", s.name, path, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			s.t.Fatalf("Failed to decode %s POST %s response: %v", s.name, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// staffToken signs a token with the services' development JWT secret
+func staffToken(t *testing.T, role string) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"userId": "staff-e2e",
+		"email":  "staff@insurancestack.com",
+		"role":   role,
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte("dev-secret-key-change-in-production"))
+	if err != nil {
+		t.Fatalf("Failed to sign staff token: %v", err)
+	}
+	return token
+}
+
+// Response shapes read by the scenarios. Only the fields the assertions use
+// are declared.
+
+type customer struct {
+	ID          string `json:"id"`
+	DateOfBirth string `json:"dateOfBirth"`
+	RiskScore   int    `json:"riskScore"`
+}
+
+type quote struct {
+	QuoteID        string  `json:"quoteId"`
+	PolicyType     string  `json:"policyType"`
+	CoverageAmount int     `json:"coverageAmount"`
+	FinalPremium   float64 `json:"finalPremium"`
+	Factors        struct {
+		PaperlessDiscount   float64 `json:"paperlessDiscount"`
+		MultiPolicyDiscount float64 `json:"multiPolicyDiscount"`
+	} `json:"factors"`
+}
+
+type quoteHistory struct {
+	Quotes []struct {
+		QuoteID   string `json:"quoteId"`
+		Converted bool   `json:"converted"`
+		PolicyID  string `json:"policyId"`
+	} `json:"quotes"`
+	ConvertedQuotes int `json:"convertedQuotes"`
+}
+
+type policy struct {
+	ID           string  `json:"id"`
+	CustomerID   string  `json:"customerId"`
+	AgentID      string  `json:"agentId"`
+	PolicyNumber string  `json:"policyNumber"`
+	Type         string  `json:"type"`
+	Status       string  `json:"status"`
+	Premium      float64 `json:"premium"`
+	Coverage     float64 `json:"coverage"`
+
+	GraceEndsAt  *time.Time `json:"graceEndsAt"`
+	ReinstatedAt *time.Time `json:"reinstatedAt"`
+
+	Cancellation *struct {
+		EffectiveDate   time.Time  `json:"effectiveDate"`
+		ReasonCode      string     `json:"reasonCode"`
+		InitiatedBy     string     `json:"initiatedBy"`
+		CancelledAt     *time.Time `json:"cancelledAt"`
+		UnearnedPremium float64    `json:"unearnedPremium"`
+		RefundPaymentID string     `json:"refundPaymentId"`
+		RefundStatus    string     `json:"refundStatus"`
+	} `json:"cancellation"`
+}
+
+type policyImport struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	TotalRows int    `json:"totalRows"`
+	Imported  int    `json:"imported"`
+	Rejected  int    `json:"rejected"`
+}
+
+type claim struct {
+	ID         string  `json:"id"`
+	PolicyID   string  `json:"policyId"`
+	CustomerID string  `json:"customerId"`
+	Status     string  `json:"status"`
+	Amount     float64 `json:"amount"`
+}
+
+type settlementOffer struct {
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+	Status string  `json:"status"`
+}
+
+type payment struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`
+	AgentID    string  `json:"agentId"`
+	PolicyID   string  `json:"policyId"`
+	ClaimID    string  `json:"claimId"`
+	CustomerID string  `json:"customerId"`
+	Amount     float64 `json:"amount"`
+	Status     string  `json:"status"`
+}
+
+type claimTimeline struct {
+	Entries []struct {
+		Type      string `json:"type"`
+		PaymentID string `json:"paymentId"`
+	} `json:"entries"`
+	Warnings []string `json:"warnings"`
+}
+
+type agent struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type commissionStatement struct {
+	Month           string  `json:"month"`
+	TotalPremium    float64 `json:"totalPremium"`
+	TotalCommission float64 `json:"totalCommission"`
+	Commissions     []struct {
+		PaymentID string  `json:"paymentId"`
+		Amount    float64 `json:"amount"`
+	} `json:"commissions"`
+}
+
+type holdRecheck struct {
+	Released  []string `json:"released"`
+	Rejected  []string `json:"rejected"`
+	StillHeld []string `json:"stillHeld"`
+}
+
+type consistencyReport struct {
+	Service string                   `json:"service"`
+	Checked int                      `json:"checked"`
+	Summary map[string]int           `json:"summary"`
+	Issues  []map[string]interface{} `json:"issues"`
+}
+
+type searchResults struct {
+	Total   int `json:"total"`
+	Results []struct {
+		Type       string `json:"type"`
+		ID         string `json:"id"`
+		CustomerID string `json:"customerId"`
+	} `json:"results"`
+}
+
+type impressionSummary struct {
+	Total uint64 `json:"total"`
+	Flags map[string]struct {
+		Variants map[string]struct {
+			Impressions uint64 `json:"impressions"`
+			Users       int    `json:"users"`
+		} `json:"variants"`
+	} `json:"flags"`
+}
+
+type maintenanceStatus struct {
+	Enabled           bool   `json:"enabled"`
+	Message           string `json:"message"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds"`
+}
+
+type faultStatus struct {
+	Enabled bool          `json:"enabled"`
+	Rules   []faults.Rule `json:"rules"`
+}
+
+type healthCheck struct {
+	Features []string `json:"features"`
+}
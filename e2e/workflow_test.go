@@ -0,0 +1,1030 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPolicyLifecycle walks a customer from quote to claim payout and checks
+// every service agrees on the result
+func TestPolicyLifecycle(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-001"
+
+	// Look up the customer the quote is priced for
+	var cust customer
+	env.Customers.mustDo("GET", "/customers/"+customerID, customerID, nil, &cust, http.StatusOK)
+	dob, err := time.Parse("2006-01-02", cust.DateOfBirth)
+	if err != nil {
+		t.Fatalf("Invalid date of birth %q: %v", cust.DateOfBirth, err)
+	}
+
+	// Quote
+	var q quote
+	env.Pricing.mustDo("POST", "/quote", customerID, map[string]interface{}{
+		"policyType":     "auto",
+		"coverageAmount": 300000,
+		"customerAge":    int(time.Since(dob).Hours() / 24 / 365),
+		"riskScore":      cust.RiskScore,
+		"customerId":     customerID,
+	}, &q, http.StatusOK)
+	if q.FinalPremium <= 0 {
+		t.Fatalf("Quote has no premium: %+v", q)
+	}
+
+	// Bind the quoted cover as a policy
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	var bound policy
+	env.Policies.mustDo("POST", "/policies", customerID, map[string]interface{}{
+		"policyNumber": "AUTO-E2E-001",
+		"quoteId":      q.QuoteID,
+		"type":         q.PolicyType,
+		"premium":      q.FinalPremium,
+		"coverage":     q.CoverageAmount,
+		"deductible":   500,
+		"startDate":    start,
+		"endDate":      start.AddDate(1, 0, 0),
+	}, &bound, http.StatusCreated)
+	if bound.CustomerID != customerID || bound.Status != "active" {
+		t.Fatalf("Unexpected bound policy: %+v", bound)
+	}
+
+	// Binding converts the quote in the customer's quote history
+	var history quoteHistory
+	env.Pricing.mustDo("GET", "/customers/"+customerID+"/quotes", customerID, nil, &history, http.StatusOK)
+	converted := false
+	for _, record := range history.Quotes {
+		if record.QuoteID == q.QuoteID {
+			converted = record.Converted && record.PolicyID == bound.ID
+		}
+	}
+	if !converted || history.ConvertedQuotes == 0 {
+		t.Errorf("Quote %s not converted into %s: %+v", q.QuoteID, bound.ID, history)
+	}
+
+	// Pay the premium
+	var premium payment
+	env.Payments.mustDo("POST", "/payments", customerID, map[string]interface{}{
+		"policyId":   bound.ID,
+		"customerId": customerID,
+		"amount":     bound.Premium,
+	}, &premium, http.StatusCreated)
+	env.Payments.mustDo("PUT", "/payments/"+premium.ID+"/process", customerID, nil, &premium, http.StatusOK)
+	if premium.Status != "completed" {
+		t.Fatalf("Premium payment status: got %s, want completed", premium.Status)
+	}
+
+	// File a claim against the new policy
+	var filed claim
+	env.Claims.mustDo("POST", "/claims", customerID, map[string]interface{}{
+		"policyId":    bound.ID,
+		"customerId":  customerID,
+		"type":        "accident",
+		"amount":      3200,
+		"description": "Rear-end collision in stop-and-go traffic",
+	}, &filed, http.StatusCreated)
+	if filed.Status != "under_review" {
+		t.Fatalf("New claim status: got %s, want under_review", filed.Status)
+	}
+
+	// Approve it
+	var approved claim
+	env.Claims.mustDo("PUT", "/claims/"+filed.ID+"/status", customerID, map[string]interface{}{
+		"status": "approved",
+		"notes":  "Approved by e2e adjuster",
+	}, &approved, http.StatusOK)
+
+	// Settle it: the adjuster offers less than claimed and the customer accepts
+	var offer settlementOffer
+	if status := env.Claims.doAsStaff("POST", "/claims/"+approved.ID+"/offers", "adjuster", map[string]interface{}{
+		"amount": 2950,
+		"notes":  "Claimed amount less the policy excess",
+	}, &offer); status != http.StatusCreated {
+		t.Fatalf("Settlement offer by an adjuster: got status %d, want 201", status)
+	}
+	payoutRequest := map[string]interface{}{"amount": offer.Amount}
+	if status := env.Claims.doAsStaff("POST", "/claims/"+approved.ID+"/payouts", "adjuster", payoutRequest, nil); status != http.StatusConflict {
+		t.Fatalf("Payout before the offer is accepted: got status %d, want 409", status)
+	}
+	env.Claims.mustDo("POST", "/claims/"+approved.ID+"/offers/"+offer.ID+"/accept", customerID, nil, &offer, http.StatusOK)
+	if offer.Status != "accepted" {
+		t.Fatalf("Settlement offer status: got %s, want accepted", offer.Status)
+	}
+
+	// Pay it out through claims-service, the only holder of payments:write
+	direct := map[string]interface{}{"claimId": approved.ID, "customerId": approved.CustomerID, "amount": offer.Amount}
+	if status := env.Payments.do("POST", "/payouts", customerID, direct, nil); status != http.StatusUnauthorized {
+		t.Fatalf("Payout by the customer: got status %d, want 401", status)
+	}
+	if status := env.Payments.doAsStaff("POST", "/payouts", "adjuster", direct, nil); status != http.StatusForbidden {
+		t.Fatalf("Payout by an adjuster straight to payments-service: got status %d, want 403", status)
+	}
+	if status := env.Claims.do("POST", "/claims/"+approved.ID+"/payouts", customerID, payoutRequest, nil); status != http.StatusForbidden {
+		t.Fatalf("Payout by the customer through claims-service: got status %d, want 403", status)
+	}
+	overpaid := map[string]interface{}{"amount": approved.Amount}
+	if status := env.Claims.doAsStaff("POST", "/claims/"+approved.ID+"/payouts", "adjuster", overpaid, nil); status != http.StatusBadRequest {
+		t.Fatalf("Payout above the accepted offer: got status %d, want 400", status)
+	}
+	var payout payment
+	if status := env.Claims.doAsStaff("POST", "/claims/"+approved.ID+"/payouts", "adjuster", payoutRequest, &payout); status != http.StatusCreated {
+		t.Fatalf("Payout by an adjuster: got status %d, want 201", status)
+	}
+	env.Payments.mustDo("PUT", "/payments/"+payout.ID+"/process", customerID, nil, &payout, http.StatusOK)
+
+	// Cross-service consistency: re-read everything from its owning service
+	var storedPolicy policy
+	env.Policies.mustDo("GET", "/policies/"+bound.ID, customerID, nil, &storedPolicy, http.StatusOK)
+	if !samePremium(storedPolicy.Premium, q.FinalPremium) {
+		t.Errorf("Policy premium %.2f does not match quote %.2f", storedPolicy.Premium, q.FinalPremium)
+	}
+
+	var storedClaim claim
+	env.Claims.mustDo("GET", "/claims/"+filed.ID, customerID, nil, &storedClaim, http.StatusOK)
+	if storedClaim.Status != "approved" {
+		t.Errorf("Claim status: got %s, want approved", storedClaim.Status)
+	}
+	if storedClaim.PolicyID != storedPolicy.ID || storedClaim.CustomerID != storedPolicy.CustomerID {
+		t.Errorf("Claim %+v does not belong to policy %+v", storedClaim, storedPolicy)
+	}
+
+	var ledger []payment
+	env.Payments.mustDo("GET", "/payments", customerID, nil, &ledger, http.StatusOK)
+	var premiums, payouts []payment
+	for _, p := range ledger {
+		switch {
+		case p.Type == "premium" && p.PolicyID == storedPolicy.ID:
+			premiums = append(premiums, p)
+		case p.Type == "payout" && p.ClaimID == storedClaim.ID:
+			payouts = append(payouts, p)
+		}
+	}
+
+	if len(premiums) != 1 || premiums[0].Status != "completed" || !samePremium(premiums[0].Amount, storedPolicy.Premium) {
+		t.Errorf("Expected one completed premium of %.2f for %s, got %+v", storedPolicy.Premium, storedPolicy.ID, premiums)
+	}
+	if len(payouts) != 1 || payouts[0].Status != "completed" || payouts[0].Amount != offer.Amount {
+		t.Errorf("Expected one completed payout of the accepted %.2f for %s, got %+v", offer.Amount, storedClaim.ID, payouts)
+	}
+	if len(payouts) == 1 && payouts[0].CustomerID != storedPolicy.CustomerID {
+		t.Errorf("Payout went to %s, policyholder is %s", payouts[0].CustomerID, storedPolicy.CustomerID)
+	}
+
+	// The claim timeline merges the claim's history, settlement included, with its payout
+	var timeline claimTimeline
+	env.Claims.mustDo("GET", "/claims/"+filed.ID+"/timeline", customerID, nil, &timeline, http.StatusOK)
+	var types []string
+	for _, entry := range timeline.Entries {
+		types = append(types, entry.Type)
+	}
+	wantTypes := []string{"claim.created", "claim.status_changed", "claim.offer_made", "claim.offer_accepted", "payout.requested", "payout.completed"}
+	if len(timeline.Warnings) != 0 || strings.Join(types, ",") != strings.Join(wantTypes, ",") {
+		t.Errorf("Timeline entries: got %v (warnings %v), want %v", types, timeline.Warnings, wantTypes)
+	}
+}
+
+// TestFinalizedClaimCannotBeReopened checks the adjuster workflow refuses to
+// change a decided claim, so a payout cannot be followed by a rejection
+func TestFinalizedClaimCannotBeReopened(t *testing.T) {
+	env := startEnvironment(t)
+
+	var filed claim
+	env.Claims.mustDo("POST", "/claims", "cust-001", map[string]interface{}{
+		"policyId":    "pol-001",
+		"customerId":  "cust-001",
+		"type":        "damage",
+		"amount":      1800,
+		"description": "Hail damage to bonnet and roof",
+	}, &filed, http.StatusCreated)
+
+	env.Claims.mustDo("PUT", "/claims/"+filed.ID+"/status", "cust-001", map[string]interface{}{"status": "approved"}, nil, http.StatusOK)
+
+	if status := env.Claims.do("PUT", "/claims/"+filed.ID+"/status", "cust-001", map[string]interface{}{"status": "rejected", "rejectionCodes": []string{"duplicate"}}, nil); status == http.StatusOK {
+		t.Error("Approved claim should not be re-decided")
+	}
+}
+
+// TestPoliciesAreScopedToTheirOwner checks a policy bound by one customer is
+// not visible to another
+func TestPoliciesAreScopedToTheirOwner(t *testing.T) {
+	env := startEnvironment(t)
+
+	var bound policy
+	env.Policies.mustDo("POST", "/policies", "cust-001", map[string]interface{}{
+		"policyNumber": "HOME-E2E-001",
+		"type":         "home",
+		"premium":      1500,
+		"coverage":     500000,
+		"deductible":   1000,
+		"startDate":    time.Now().UTC(),
+		"endDate":      time.Now().UTC().AddDate(1, 0, 0),
+	}, &bound, http.StatusCreated)
+
+	if status := env.Policies.do("GET", "/policies/"+bound.ID, "cust-002", nil, nil); status != http.StatusForbidden {
+		t.Errorf("Other customer reading policy: got status %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// TestSeedDataIsConsistentAcrossServices checks the fixtures every service
+// loads reference each other correctly, as seen through the APIs
+func TestSeedDataIsConsistentAcrossServices(t *testing.T) {
+	env := startEnvironment(t)
+
+	var allClaims []claim
+	if status := env.Claims.doAsStaff("GET", "/claims", "adjuster", nil, &allClaims); status != http.StatusOK {
+		t.Fatalf("Listing claims as staff: got status %d, want %d", status, http.StatusOK)
+	}
+	claimsByID := make(map[string]claim, len(allClaims))
+	for _, c := range allClaims {
+		claimsByID[c.ID] = c
+
+		// The claimant must be able to read the policy the claim is filed against
+		if status := env.Policies.do("GET", "/policies/"+c.PolicyID, c.CustomerID, nil, nil); status != http.StatusOK {
+			t.Errorf("Claim %s: policy %s not readable by claimant %s (status %d)", c.ID, c.PolicyID, c.CustomerID, status)
+		}
+		// ...and the claim itself, which no other customer may read
+		if status := env.Claims.do("GET", "/claims/"+c.ID, c.CustomerID, nil, nil); status != http.StatusOK {
+			t.Errorf("Claim %s not readable by claimant %s (status %d)", c.ID, c.CustomerID, status)
+		}
+		if status := env.Claims.do("GET", "/claims/"+c.ID, "cust-nobody", nil, nil); status != http.StatusForbidden {
+			t.Errorf("Claim %s: got status %d reading as another customer, want %d", c.ID, status, http.StatusForbidden)
+		}
+	}
+
+	var ledger []payment
+	env.Payments.mustDo("GET", "/payments", "", nil, &ledger, http.StatusOK)
+	for _, p := range ledger {
+		if status := env.Customers.do("GET", "/customers/"+p.CustomerID, p.CustomerID, nil, nil); status != http.StatusOK {
+			t.Errorf("Payment %s: customer %s not found (status %d)", p.ID, p.CustomerID, status)
+		}
+		if p.PolicyID != "" {
+			if status := env.Policies.do("GET", "/policies/"+p.PolicyID, p.CustomerID, nil, nil); status != http.StatusOK {
+				t.Errorf("Payment %s: policy %s not readable by payer %s (status %d)", p.ID, p.PolicyID, p.CustomerID, status)
+			}
+		}
+		if p.Type == "payout" {
+			c, ok := claimsByID[p.ClaimID]
+			if !ok {
+				t.Errorf("Payout %s references unknown claim %s", p.ID, p.ClaimID)
+				continue
+			}
+			if c.CustomerID != p.CustomerID {
+				t.Errorf("Payout %s paid to %s, claimant is %s", p.ID, p.CustomerID, c.CustomerID)
+			}
+		}
+	}
+}
+
+// TestConsistencyReportsAreClean checks each service's own referential
+// integrity report agrees the seed data has no broken references, and that
+// the reports are closed to customers
+func TestConsistencyReportsAreClean(t *testing.T) {
+	env := startEnvironment(t)
+
+	for _, svc := range []*service{env.Policies, env.Claims, env.Payments} {
+		var report consistencyReport
+		if status := svc.doAsStaff("GET", "/admin/consistency-report", "admin", nil, &report); status != http.StatusOK {
+			t.Fatalf("%s consistency report: got status %d, want %d", svc.name, status, http.StatusOK)
+		}
+		if report.Service != svc.name || report.Checked == 0 {
+			t.Errorf("%s report did not check anything: %+v", svc.name, report)
+		}
+		if report.Summary["error"] != 0 || report.Summary["info"] != 0 {
+			t.Errorf("%s reported problems on seed data: %+v", svc.name, report.Issues)
+		}
+
+		if status := svc.doAsStaff("GET", "/admin/consistency-report", "customer", nil, nil); status != http.StatusForbidden {
+			t.Errorf("%s report for a customer: got status %d, want %d", svc.name, status, http.StatusForbidden)
+		}
+	}
+}
+
+// TestCancellationRefundsUnearnedPremium cancels a policy half way through
+// its term and checks the refund payments-service records matches the
+// unearned premium policy-service computed
+func TestCancellationRefundsUnearnedPremium(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-001"
+
+	start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -100)
+	var bound policy
+	env.Policies.mustDo("POST", "/policies", customerID, map[string]interface{}{
+		"policyNumber": "AUTO-E2E-002",
+		"type":         "auto",
+		"premium":      1000,
+		"coverage":     50000,
+		"deductible":   500,
+		"startDate":    start,
+		"endDate":      start.AddDate(0, 0, 200),
+	}, &bound, http.StatusCreated)
+
+	effective := start.AddDate(0, 0, 50).Format("2006-01-02")
+	var cancelled policy
+	env.Policies.mustDo("DELETE", "/policies/"+bound.ID+"?refund=true&reason=moved+abroad&effectiveDate="+effective, customerID, nil, &cancelled, http.StatusOK)
+	c := cancelled.Cancellation
+	if cancelled.Status != "cancelled" || c == nil || c.UnearnedPremium != 750 || c.RefundStatus != "pending" || c.ReasonCode != "customer_request" || c.InitiatedBy != "customer" {
+		t.Fatalf("Unexpected cancellation: %+v %+v", cancelled, c)
+	}
+
+	var refund payment
+	env.Payments.mustDo("GET", "/payments/"+c.RefundPaymentID, customerID, nil, &refund, http.StatusOK)
+	if refund.Type != "refund" || refund.PolicyID != bound.ID || refund.CustomerID != customerID || refund.Amount != 750 {
+		t.Errorf("Refund does not match the cancellation: %+v", refund)
+	}
+
+	if status := env.Policies.do("DELETE", "/policies/"+bound.ID, customerID, nil, nil); status != http.StatusConflict {
+		t.Errorf("Cancelling twice: got status %d, want %d", status, http.StatusConflict)
+	}
+}
+
+// TestInsurerCancellationGivesNotice checks staff cancelling an active
+// policy leave it in force, pending cancellation, for the notice period
+func TestInsurerCancellationGivesNotice(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-001"
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var bound policy
+	env.Policies.mustDo("POST", "/policies", customerID, map[string]interface{}{
+		"policyNumber": "AUTO-E2E-010",
+		"type":         "auto",
+		"premium":      1000,
+		"coverage":     50000,
+		"deductible":   500,
+		"startDate":    today.AddDate(0, 0, -30),
+		"endDate":      today.AddDate(0, 0, 335),
+	}, &bound, http.StatusCreated)
+
+	if status := env.Policies.doAsStaff("DELETE", "/policies/"+bound.ID+"?reasonCode=non_payment&effectiveDate="+today.Format("2006-01-02"), "admin", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Cancelling without notice: got status %d, want %d", status, http.StatusBadRequest)
+	}
+
+	var pending policy
+	if status := env.Policies.doAsStaff("DELETE", "/policies/"+bound.ID+"?reasonCode=non_payment", "admin", nil, &pending); status != http.StatusOK {
+		t.Fatalf("Insurer cancellation: got status %d", status)
+	}
+	c := pending.Cancellation
+	if pending.Status != "pending_cancellation" || c == nil || !c.EffectiveDate.Equal(today.AddDate(0, 0, 10)) || c.InitiatedBy != "insurer" || c.CancelledAt != nil {
+		t.Fatalf("Unexpected pending cancellation: %+v %+v", pending, c)
+	}
+
+	var current policy
+	env.Policies.mustDo("GET", "/policies/"+bound.ID, customerID, nil, &current, http.StatusOK)
+	if current.Status != "pending_cancellation" || current.Cancellation == nil || current.Cancellation.ReasonCode != "non_payment" {
+		t.Errorf("Customer should see the pending cancellation: %+v", current)
+	}
+	if status := env.Policies.do("DELETE", "/policies/"+bound.ID, customerID, nil, nil); status != http.StatusConflict {
+		t.Errorf("Cancelling a pending cancellation: got status %d, want %d", status, http.StatusConflict)
+	}
+}
+
+// TestClaimsDuringGraceWaitForReinstatement checks a claim on a policy in
+// its grace period is held until the policy is reinstated
+func TestClaimsDuringGraceWaitForReinstatement(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-001"
+
+	end := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -5)
+	var bound policy
+	env.Policies.mustDo("POST", "/policies", customerID, map[string]interface{}{
+		"policyNumber": "AUTO-E2E-003",
+		"type":         "auto",
+		"premium":      1000,
+		"coverage":     50000,
+		"deductible":   500,
+		"startDate":    end.AddDate(-1, 0, 0),
+		"endDate":      end,
+	}, &bound, http.StatusCreated)
+
+	if status := env.Policies.doAsStaff("POST", "/admin/policies/grace-sweep", "admin", nil, nil); status != http.StatusOK {
+		t.Fatalf("Grace sweep: got status %d, want %d", status, http.StatusOK)
+	}
+	var lapsing policy
+	env.Policies.mustDo("GET", "/policies/"+bound.ID, customerID, nil, &lapsing, http.StatusOK)
+	if lapsing.Status != "grace" || lapsing.GraceEndsAt == nil {
+		t.Fatalf("Policy past its end date should be in grace: %+v", lapsing)
+	}
+
+	var filed claim
+	env.Claims.mustDo("POST", "/claims", customerID, map[string]interface{}{
+		"policyId":     bound.ID,
+		"customerId":   customerID,
+		"type":         "accident",
+		"amount":       2500,
+		"description":  "Rear-ended at a junction",
+		"incidentDate": time.Now().UTC().AddDate(0, 0, -2),
+	}, &filed, http.StatusCreated)
+	if filed.Status != "pending_payment" {
+		t.Fatalf("Claim during grace: got status %s, want pending_payment", filed.Status)
+	}
+
+	var reinstated policy
+	env.Policies.mustDo("POST", "/policies/"+bound.ID+"/reinstate", customerID, nil, &reinstated, http.StatusOK)
+	if reinstated.Status != "active" || reinstated.ReinstatedAt == nil {
+		t.Fatalf("Unexpected reinstatement: %+v", reinstated)
+	}
+
+	var recheck holdRecheck
+	if status := env.Claims.doAsStaff("POST", "/admin/claims/held/recheck", "adjuster", nil, &recheck); status != http.StatusOK {
+		t.Fatalf("Held claim recheck: got status %d, want %d", status, http.StatusOK)
+	}
+	if len(recheck.Released) != 1 || recheck.Released[0] != filed.ID {
+		t.Errorf("Expected the held claim to be released: %+v", recheck)
+	}
+
+	var released claim
+	env.Claims.mustDo("GET", "/claims/"+filed.ID, customerID, nil, &released, http.StatusOK)
+	if released.Status != "under_review" {
+		t.Errorf("Released claim: got status %s, want under_review", released.Status)
+	}
+}
+
+// TestAgentEarnsCommissionOnPremiums checks a premium paid on a policy sold
+// by an agent shows up on the agent's monthly commission statement
+func TestAgentEarnsCommissionOnPremiums(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-001"
+
+	var registered agent
+	if status := env.Payments.doAsStaff("POST", "/agents", "admin", map[string]interface{}{
+		"name":           "E2E Brokerage",
+		"licenseNumber":  "E2E-0001",
+		"commissionRate": 0.15,
+	}, &registered); status != http.StatusCreated {
+		t.Fatalf("Register agent: got status %d, want %d", status, http.StatusCreated)
+	}
+
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	var bound policy
+	env.Policies.mustDo("POST", "/policies", customerID, map[string]interface{}{
+		"policyNumber": "HOME-E2E-004",
+		"agentId":      registered.ID,
+		"type":         "home",
+		"premium":      1200,
+		"coverage":     250000,
+		"deductible":   1000,
+		"startDate":    start,
+		"endDate":      start.AddDate(1, 0, 0),
+	}, &bound, http.StatusCreated)
+	if bound.AgentID != registered.ID {
+		t.Fatalf("Policy should record its agent: %+v", bound)
+	}
+
+	var premium payment
+	env.Payments.mustDo("POST", "/payments", customerID, map[string]interface{}{
+		"policyId":   bound.ID,
+		"customerId": customerID,
+		"amount":     1200,
+	}, &premium, http.StatusCreated)
+	if premium.AgentID != registered.ID {
+		t.Fatalf("Premium should credit the policy's agent: %+v", premium)
+	}
+	env.Payments.mustDo("PUT", "/payments/"+premium.ID+"/process", customerID, nil, nil, http.StatusOK)
+
+	month := time.Now().UTC().Format("2006-01")
+	var statement commissionStatement
+	if status := env.Payments.doAsStaff("GET", "/agents/"+registered.ID+"/commissions?month="+month, "admin", nil, &statement); status != http.StatusOK {
+		t.Fatalf("Commission statement: got status %d, want %d", status, http.StatusOK)
+	}
+	if len(statement.Commissions) != 1 || statement.Commissions[0].PaymentID != premium.ID || statement.TotalCommission != 180 {
+		t.Errorf("Unexpected commission statement: %+v", statement)
+	}
+
+	if status := env.Payments.doAsStaff("GET", "/agents/"+registered.ID+"/commissions", "adjuster", nil, nil); status != http.StatusForbidden {
+		t.Errorf("Commission statement for an adjuster: got status %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// TestFlagImpressionsMeasureExposure checks filing a claim is counted as
+// one auto-approval impression for the claimant
+func TestFlagImpressionsMeasureExposure(t *testing.T) {
+	env := startEnvironment(t)
+
+	env.Claims.mustDo("POST", "/claims", "cust-001", map[string]interface{}{
+		"policyId":    "pol-001",
+		"customerId":  "cust-001",
+		"type":        "damage",
+		"amount":      900,
+		"description": "Cracked windscreen from road debris",
+	}, nil, http.StatusCreated)
+
+	var summary impressionSummary
+	if status := env.Claims.doAsStaff("GET", "/admin/flags/impressions/summary?flag=claims.autoApproval", "adjuster", nil, &summary); status != http.StatusOK {
+		t.Fatalf("Impressions summary: got status %d, want %d", status, http.StatusOK)
+	}
+	disabled := summary.Flags["claims.autoApproval"].Variants["false"]
+	if disabled.Impressions != 1 || disabled.Users != 1 {
+		t.Errorf("Expected one auto-approval impression for one user, got %+v", summary)
+	}
+
+	if status := env.Claims.doAsStaff("GET", "/admin/flags/impressions/summary", "customer", nil, nil); status != http.StatusForbidden {
+		t.Errorf("Impressions summary for a customer: got status %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// TestPaperlessDiscountFollowsConsent checks pricing-engine discounts
+// paperless billing only while customer-service has the customer's consent
+// on record, whatever the quote request claims
+func TestPaperlessDiscountFollowsConsent(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-001" // consented to paperless billing in the seed data
+
+	quoteRequest := map[string]interface{}{
+		"policyType":     "home",
+		"coverageAmount": 250000,
+		"customerAge":    40,
+		"riskScore":      2,
+		"customerId":     customerID,
+		"paperlessBill":  true,
+	}
+	var consented quote
+	env.Pricing.mustDo("POST", "/quote", customerID, quoteRequest, &consented, http.StatusOK)
+	if consented.Factors.PaperlessDiscount <= 0 {
+		t.Fatalf("Expected a paperless discount with consent on record, got %+v", consented.Factors)
+	}
+
+	env.Customers.mustDo("PUT", "/customers/"+customerID+"/preferences", customerID, map[string]interface{}{
+		"emailOptIn":       true,
+		"paperlessBilling": false,
+		"source":           "phone",
+	}, nil, http.StatusOK)
+
+	var withdrawn quote
+	env.Pricing.mustDo("POST", "/quote", customerID, quoteRequest, &withdrawn, http.StatusOK)
+	if withdrawn.Factors.PaperlessDiscount != 0 || withdrawn.FinalPremium <= consented.FinalPremium {
+		t.Errorf("Paperless discount kept after consent was withdrawn: %.2f then %.2f, factors %+v", consented.FinalPremium, withdrawn.FinalPremium, withdrawn.Factors)
+	}
+}
+
+// TestHouseholdSharesPoliciesAndMultiPolicyDiscount checks that customers
+// who live together see each other's policies and earn the multi-policy
+// discount on the strength of a housemate's policy
+func TestHouseholdSharesPoliciesAndMultiPolicyDiscount(t *testing.T) {
+	env := startEnvironment(t)
+	const customerID = "cust-003"  // holds one active life policy
+	const housemateID = "cust-004" // holds one active auto policy
+
+	quoteRequest := map[string]interface{}{
+		"policyType":     "home",
+		"coverageAmount": 250000,
+		"customerAge":    40,
+		"riskScore":      2,
+		"customerId":     "cust-005", // lapsed policy only, seeded in hh-001 with cust-007
+		"multiPolicy":    true,
+	}
+	var viaHousemate quote
+	env.Pricing.mustDo("POST", "/quote", "cust-005", quoteRequest, &viaHousemate, http.StatusOK)
+	if viaHousemate.Factors.MultiPolicyDiscount <= 0 {
+		t.Fatalf("Expected cust-005 to earn the discount through housemate cust-007, got %+v", viaHousemate.Factors)
+	}
+
+	var household struct {
+		ID        string   `json:"id"`
+		MemberIDs []string `json:"memberIds"`
+	}
+	env.Customers.mustDo("POST", "/households", customerID, map[string]interface{}{
+		"name":       "Dubois-Garcia household",
+		"customerId": customerID,
+	}, &household, http.StatusCreated)
+	env.Customers.mustDo("POST", "/households/"+household.ID+"/members", housemateID, map[string]interface{}{
+		"customerId": housemateID,
+	}, &household, http.StatusOK)
+	if len(household.MemberIDs) != 2 {
+		t.Fatalf("Expected two members, got %+v", household)
+	}
+
+	var shared []policy
+	env.Policies.mustDo("GET", "/policies?scope=household", customerID, nil, &shared, http.StatusOK)
+	owners := map[string]bool{}
+	for _, p := range shared {
+		owners[p.CustomerID] = true
+	}
+	if len(owners) != 2 || !owners[customerID] || !owners[housemateID] {
+		t.Errorf("Household overview should list both members' policies, got %+v", shared)
+	}
+
+	// Leaving the household takes the housemate's policies out of view
+	env.Customers.mustDo("DELETE", "/households/"+household.ID+"/members/"+housemateID, housemateID, nil, nil, http.StatusOK)
+	var own []policy
+	env.Policies.mustDo("GET", "/policies?scope=household", customerID, nil, &own, http.StatusOK)
+	for _, p := range own {
+		if p.CustomerID != customerID {
+			t.Errorf("Former housemate's policy still listed: %+v", p)
+		}
+	}
+
+	// A customer without an active policy anywhere in their household gets
+	// no multi-policy discount, whatever the request claims
+	env.Customers.mustDo("DELETE", "/households/hh-001/members/cust-007", "cust-007", nil, nil, http.StatusOK)
+	var single quote
+	env.Pricing.mustDo("POST", "/quote", "cust-005", quoteRequest, &single, http.StatusOK)
+	if single.Factors.MultiPolicyDiscount != 0 || single.FinalPremium <= viaHousemate.FinalPremium {
+		t.Errorf("Multi-policy discount kept after the household split: %.2f then %.2f, factors %+v", viaHousemate.FinalPremium, single.FinalPremium, single.Factors)
+	}
+}
+
+// TestPolicyChangesSurviveRestart checks policy-service recovers created
+// and cancelled policies from its persisted state after a restart
+func TestPolicyChangesSurviveRestart(t *testing.T) {
+	dataPath, stateDir := copySeedData(t), t.TempDir()
+	const customerID = "cust-001"
+
+	policies, stop := startPolicyService(t, dataPath, stateDir)
+	start := time.Now().UTC().Truncate(24 * time.Hour)
+	newPolicy := map[string]interface{}{
+		"policyNumber": "HOME-E2E-PERSIST",
+		"type":         "home",
+		"premium":      1200,
+		"coverage":     250000,
+		"deductible":   1000,
+		"startDate":    start,
+		"endDate":      start.AddDate(1, 0, 0),
+	}
+	var created, cancelled policy
+	policies.mustDo("POST", "/policies", customerID, newPolicy, &created, http.StatusCreated)
+	policies.mustDo("DELETE", "/policies/"+created.ID+"?reason=sold+the+house", customerID, nil, &cancelled, http.StatusOK)
+	stop()
+
+	policies, stop = startPolicyService(t, dataPath, stateDir)
+	defer stop()
+
+	var recovered policy
+	policies.mustDo("GET", "/policies/"+created.ID, customerID, nil, &recovered, http.StatusOK)
+	if recovered.Status != "cancelled" || recovered.Cancellation == nil || recovered.Premium != 1200 {
+		t.Errorf("Policy not recovered as cancelled: %+v", recovered)
+	}
+
+	// New policies must not reuse the IDs of recovered ones
+	newPolicy["policyNumber"] = "HOME-E2E-PERSIST-2"
+	var next policy
+	policies.mustDo("POST", "/policies", customerID, newPolicy, &next, http.StatusCreated)
+	if next.ID == created.ID {
+		t.Errorf("Recovered policy ID %s was reused", created.ID)
+	}
+}
+
+// TestSearchIsScopedToTheCaller checks search-service finds claims,
+// customers and policies from the other services, limits customers to their
+// own records and picks up new claims once reindexed
+func TestSearchIsScopedToTheCaller(t *testing.T) {
+	env := startEnvironment(t)
+
+	// Water damage from a burst pipe is cust-002's claim
+	var own, other, staff searchResults
+	env.Search.mustDo("GET", "/search?q=burst+pipe", "cust-002", nil, &own, http.StatusOK)
+	if own.Total != 1 || own.Results[0].Type != "claim" || own.Results[0].CustomerID != "cust-002" {
+		t.Errorf("Owner search: got %+v", own)
+	}
+	env.Search.mustDo("GET", "/search?q=burst+pipe", "cust-001", nil, &other, http.StatusOK)
+	if other.Total != 0 {
+		t.Errorf("Other customer found %+v", other.Results)
+	}
+
+	// Staff search every customer, here by policy number prefix
+	if status := env.Search.doAsStaff("GET", "/search?q=AUTO-2023&type=policy", "adjuster", nil, &staff); status != http.StatusOK {
+		t.Fatalf("Staff search: got status %d", status)
+	}
+	owners := map[string]bool{}
+	for _, r := range staff.Results {
+		if r.Type != "policy" {
+			t.Errorf("Type filter returned a %s", r.Type)
+		}
+		owners[r.CustomerID] = true
+	}
+	if len(owners) < 2 {
+		t.Errorf("Staff search should span customers: got %+v", staff.Results)
+	}
+
+	// Customers find themselves by email
+	var self searchResults
+	env.Search.mustDo("GET", "/search?q=demo@insurancestack.com", "cust-001", nil, &self, http.StatusOK)
+	if self.Total != 1 || self.Results[0].Type != "customer" || self.Results[0].ID != "cust-001" {
+		t.Errorf("Email search: got %+v", self)
+	}
+
+	// A new claim is searchable after a reindex, which only staff can trigger
+	var filed claim
+	env.Claims.mustDo("POST", "/claims", "cust-001", map[string]interface{}{
+		"policyId":    "pol-001",
+		"customerId":  "cust-001",
+		"type":        "damage",
+		"amount":      900,
+		"description": "Deer strike on a country lane",
+	}, &filed, http.StatusCreated)
+
+	if status := env.Search.do("POST", "/admin/search/reindex", "cust-001", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("Customer reindex: got status %d, want %d", status, http.StatusUnauthorized)
+	}
+	if status := env.Search.doAsStaff("POST", "/admin/search/reindex", "admin", nil, nil); status != http.StatusOK {
+		t.Fatalf("Reindex: got status %d", status)
+	}
+
+	var found searchResults
+	env.Search.mustDo("GET", "/search?q=deer", "cust-001", nil, &found, http.StatusOK)
+	if found.Total != 1 || found.Results[0].ID != filed.ID {
+		t.Errorf("New claim search: got %+v", found)
+	}
+}
+
+// TestMaintenanceModeDrainsTraffic checks an admin can put claims-service
+// into maintenance, turning callers away with 503 and Retry-After while the
+// health check keeps answering, and reopen it
+func TestMaintenanceModeDrainsTraffic(t *testing.T) {
+	env := startEnvironment(t)
+
+	on := map[string]interface{}{"enabled": true, "message": "Migrating claims", "retryAfterSeconds": 120}
+	if status := env.Claims.doAsStaff("PUT", "/admin/maintenance", "adjuster", on, nil); status != http.StatusForbidden {
+		t.Errorf("Adjuster maintenance switch: got status %d, want %d", status, http.StatusForbidden)
+	}
+	var mode maintenanceStatus
+	if status := env.Claims.doAsStaff("PUT", "/admin/maintenance", "admin", on, &mode); status != http.StatusOK || !mode.Enabled {
+		t.Fatalf("Maintenance on: got status %d, mode %+v", status, mode)
+	}
+
+	resp, err := env.Claims.server.Client().Get(env.Claims.server.URL + "/claims?customerId=cust-001")
+	if err != nil {
+		t.Fatalf("GET /claims failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "120" {
+		t.Errorf("Claims during maintenance: got status %d, Retry-After %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	var healthz healthCheck
+	env.Claims.mustDo("GET", "/healthz", "", nil, &healthz, http.StatusOK)
+	if len(healthz.Features) != 1 || healthz.Features[0] != "system.maintenanceMode" {
+		t.Errorf("Expected the health check to report maintenance, got %v", healthz.Features)
+	}
+	if status := env.Policies.do("GET", "/policies", "cust-001", nil, nil); status != http.StatusOK {
+		t.Errorf("Other services should keep serving, policies got status %d", status)
+	}
+
+	env.Claims.doAsStaff("PUT", "/admin/maintenance", "admin", map[string]interface{}{"enabled": false}, nil)
+	if status := env.Claims.do("GET", "/claims?customerId=cust-001", "cust-001", nil, nil); status != http.StatusOK {
+		t.Errorf("Claims after maintenance: got status %d, want %d", status, http.StatusOK)
+	}
+}
+
+// TestInjectedFaultsOpenTheCircuitBreaker fails payments-service's payment
+// listing and checks claim timelines fail open while claims-service's
+// circuit breaker stops calling it
+func TestInjectedFaultsOpenTheCircuitBreaker(t *testing.T) {
+	t.Setenv("FEATURE_FAULT_INJECTION", "true")
+	env := startEnvironment(t)
+
+	rules := map[string]interface{}{
+		"rules": []map[string]interface{}{{"route": "/payments", "methods": []string{"GET"}, "percent": 100, "status": http.StatusServiceUnavailable}},
+	}
+	if status := env.Payments.doAsStaff("PUT", "/admin/faults", "adjuster", rules, nil); status != http.StatusForbidden {
+		t.Errorf("Adjuster fault rules: got status %d, want %d", status, http.StatusForbidden)
+	}
+	var set faultStatus
+	if status := env.Payments.doAsStaff("PUT", "/admin/faults", "admin", rules, &set); status != http.StatusOK || !set.Enabled || len(set.Rules) != 1 {
+		t.Fatalf("Fault rules: got status %d, %+v", status, set)
+	}
+	if status := env.Claims.doAsStaff("GET", "/admin/faults", "admin", nil, &set); status != http.StatusOK || len(set.Rules) != 0 {
+		t.Errorf("Claims should have no rules of its own, got status %d, %+v", status, set)
+	}
+
+	// Five failures open the breaker; the timelines after it are answered
+	// without calling payments-service
+	for i := 0; i < 8; i++ {
+		var timeline claimTimeline
+		env.Claims.mustDo("GET", "/claims/claim-001/timeline", "cust-001", nil, &timeline, http.StatusOK)
+		if len(timeline.Warnings) == 0 {
+			t.Fatalf("Timeline %d: expected a warning without payouts, got %+v", i+1, timeline)
+		}
+	}
+
+	_, _, claimsMetrics := env.Claims.downloadAsStaff("/metrics", "admin")
+	open := `claims_circuit_breaker_state{downstream="payments-service",route="GET /payments",tenant="",state="open"} 1`
+	if !strings.Contains(string(claimsMetrics), open) {
+		t.Errorf("Expected the payments breaker open, got:\n%s", claimsMetrics)
+	}
+	_, _, paymentsMetrics := env.Payments.downloadAsStaff("/metrics", "admin")
+	if !strings.Contains(string(paymentsMetrics), `faults_injected_total{fault="error",route="/payments"} 5`) {
+		t.Errorf("Expected payments-service to fail only the 5 calls before the breaker opened, got:\n%s", paymentsMetrics)
+	}
+
+	var healthz healthCheck
+	env.Payments.mustDo("GET", "/healthz", "", nil, &healthz, http.StatusOK)
+	if len(healthz.Features) != 1 || healthz.Features[0] != "system.faultInjection" {
+		t.Errorf("Expected the health check to report fault injection, got %v", healthz.Features)
+	}
+	if status := env.Payments.doAsStaff("PUT", "/admin/faults", "admin", map[string]interface{}{"rules": []interface{}{}}, nil); status != http.StatusOK {
+		t.Errorf("Clearing fault rules: got status %d", status)
+	}
+	if status := env.Payments.do("GET", "/payments", "cust-001", nil, nil); status != http.StatusOK {
+		t.Errorf("Payments after the rules are cleared: got status %d, want %d", status, http.StatusOK)
+	}
+}
+
+// TestFaultInjectionIsOffByDefault checks the fault injection endpoint is
+// not there without the flag
+func TestFaultInjectionIsOffByDefault(t *testing.T) {
+	env := startEnvironment(t)
+
+	rules := map[string]interface{}{"rules": []map[string]interface{}{{"percent": 100, "status": http.StatusInternalServerError}}}
+	if status := env.Payments.doAsStaff("PUT", "/admin/faults", "admin", rules, nil); status != http.StatusNotFound {
+		t.Errorf("Fault rules without the flag: got status %d, want %d", status, http.StatusNotFound)
+	}
+	if status := env.Payments.do("GET", "/payments", "cust-001", nil, nil); status != http.StatusOK {
+		t.Errorf("Payments without fault injection: got status %d, want %d", status, http.StatusOK)
+	}
+}
+
+// TestCustomersTrackOnlyTheirOwnClaims checks /my/claims lists the caller's
+// claims without the fields adjusters work with, and hides other customers'
+func TestCustomersTrackOnlyTheirOwnClaims(t *testing.T) {
+	env := startEnvironment(t)
+
+	// An adjuster picks up one of cust-001's claims
+	env.Claims.mustDo("PUT", "/claims/claim-007/assignment", "adj-001", map[string]interface{}{
+		"adjusterId": "adj-001",
+		"queue":      "auto",
+	}, nil, http.StatusOK)
+	env.Claims.mustDo("POST", "/claims/claim-007/escalate", "adj-001", map[string]interface{}{
+		"reason": "Possible staged collision",
+	}, nil, http.StatusOK)
+
+	var mine []map[string]interface{}
+	env.Claims.mustDo("GET", "/my/claims", "cust-001", nil, &mine, http.StatusOK)
+	ids := map[interface{}]bool{}
+	for _, c := range mine {
+		ids[c["id"]] = true
+		for _, internal := range []string{"assignedTo", "queue", "escalated", "approvalReason", "duplicateOf", "version", "customerId"} {
+			if _, ok := c[internal]; ok {
+				t.Errorf("Claim %v shows internal field %s", c["id"], internal)
+			}
+		}
+	}
+	if len(mine) != 2 || !ids["claim-001"] || !ids["claim-007"] {
+		t.Errorf("Expected cust-001's claims claim-001 and claim-007, got %v", ids)
+	}
+
+	var one map[string]interface{}
+	env.Claims.mustDo("GET", "/my/claims/claim-007", "cust-001", nil, &one, http.StatusOK)
+	if one["status"] == nil || one["statusSince"] == nil || one["assignedTo"] != nil {
+		t.Errorf("Unexpected customer claim: %v", one)
+	}
+
+	// Another customer's claim is not found rather than forbidden
+	if status := env.Claims.do("GET", "/my/claims/claim-002", "cust-001", nil, nil); status != http.StatusNotFound {
+		t.Errorf("Other customer's claim: got status %d, want %d", status, http.StatusNotFound)
+	}
+}
+
+func TestResponsesAreShapedForTheCaller(t *testing.T) {
+	env := startEnvironment(t)
+
+	env.Claims.mustDo("PUT", "/claims/claim-007/assignment", "adj-001", map[string]interface{}{
+		"adjusterId": "adj-001",
+		"queue":      "auto",
+	}, nil, http.StatusOK)
+
+	// Claim work queues are shown to staff only, by shape tag
+	var asCustomer, asStaff map[string]interface{}
+	env.Claims.mustDo("GET", "/claims/claim-007", "cust-001", nil, &asCustomer, http.StatusOK)
+	if status := env.Claims.doAsStaff("GET", "/claims/claim-007", "adjuster", nil, &asStaff); status != http.StatusOK {
+		t.Fatalf("Staff claim lookup: got status %d", status)
+	}
+	if _, ok := asCustomer["queue"]; ok || asCustomer["status"] == nil {
+		t.Errorf("Customer sees the claim queue: %v", asCustomer)
+	}
+	if asStaff["queue"] != "auto" || asStaff["assignedTo"] != "adj-001" {
+		t.Errorf("Staff should see the claim assignment: %v", asStaff)
+	}
+
+	// The seed shaping policy keeps the bound quote of a policy for staff
+	var policy map[string]interface{}
+	env.Policies.mustDo("GET", "/policies/pol-001", "cust-001", nil, &policy, http.StatusOK)
+	if _, ok := policy["quoteId"]; ok {
+		t.Errorf("Customer sees the policy's quote: %v", policy)
+	}
+	var page struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if status := env.Policies.doAsStaff("GET", "/admin/policies?customerId=cust-001", "admin", nil, &page); status != http.StatusOK {
+		t.Fatalf("Admin policy list: got status %d", status)
+	}
+	quoteIDs := map[interface{}]interface{}{}
+	for _, p := range page.Data {
+		quoteIDs[p["id"]] = p["quoteId"]
+	}
+	if quoteIDs["pol-001"] == nil {
+		t.Errorf("Admin should see the policy's quote: %v", page.Data)
+	}
+}
+
+func TestClaimsExportToAcord(t *testing.T) {
+	env := startEnvironment(t)
+
+	status, header, body := env.Claims.downloadAsStaff("/exports/acord?status=approved", "adjuster")
+	if status != http.StatusOK || header.Get("Content-Type") != "application/xml" || header.Get("X-Acord-Rejected") != "0" {
+		t.Fatalf("ACORD export: got status %d, headers %v: %s", status, header, body)
+	}
+	var doc struct {
+		Notifications []struct {
+			PolicyNumber string `xml:"Policy>PolicyNumber"`
+			ClaimNumber  string `xml:"ClaimsOccurrence>ItemIdInfo>InsurerId"`
+			Status       string `xml:"ClaimsOccurrence>ClaimStatusCd"`
+		} `xml:"ClaimsSvcRq>ClaimsNotificationAddRq"`
+	}
+	if err := xml.Unmarshal(body, &doc); err != nil {
+		t.Fatalf("ACORD export is not XML: %v", err)
+	}
+	if len(doc.Notifications) == 0 {
+		t.Fatal("Expected the approved seed claims in the export")
+	}
+	for _, n := range doc.Notifications {
+		if n.PolicyNumber == "" || n.ClaimNumber == "" || n.Status != "closed" {
+			t.Errorf("Unexpected notification %+v", n)
+		}
+	}
+
+	status, header, body = env.Claims.downloadAsStaff("/claims/claim-001/acord?format=edi", "admin")
+	if status != http.StatusOK || !strings.HasPrefix(string(body), "HDR*") || !strings.Contains(string(body), "CLM*CLM-2024-00123*claim-001*") {
+		t.Errorf("EDI export: got status %d, headers %v: %s", status, header, body)
+	}
+	if status := env.Claims.do("GET", "/exports/acord", "cust-001", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("Customer ACORD export: got status %d, want %d", status, http.StatusUnauthorized)
+	}
+}
+
+func TestLegacyPoliciesImport(t *testing.T) {
+	env := startEnvironment(t)
+
+	files := map[string]string{
+		"file": "POLICY_NO,HOLDER,LOB,ANNUAL_PREMIUM,SUM_INSURED,EFFECTIVE,EXPIRY\n" +
+			"LEGACY-0001,cust-002,HO,\"1,150.00\",\"320,000\",15/01/2020,15/01/2099\n" +
+			"LEGACY-0002,cust-003,PA,640,50000,01/06/2015,01/06/2016\n" +
+			"LEGACY-0003,cust-404,PA,640,50000,01/06/2020,01/06/2099\n",
+		"mapping": `{
+			"columns": {"policyNumber": "POLICY_NO", "customerId": "HOLDER", "type": "LOB", "premium": "ANNUAL_PREMIUM",
+				"coverage": "SUM_INSURED", "startDate": "EFFECTIVE", "endDate": "EXPIRY"},
+			"values": {"type": {"HO": "home", "PA": "auto"}},
+			"dateFormat": "DD/MM/YYYY",
+			"thousandsSeparator": ","
+		}`,
+	}
+	run := func(query string) policyImport {
+		t.Helper()
+		var job policyImport
+		if status := env.Policies.uploadAsStaff("/admin/policies/import"+query, "admin", files, &job); status != http.StatusAccepted {
+			t.Fatalf("Start import: got status %d, want %d", status, http.StatusAccepted)
+		}
+		for deadline := time.Now().Add(5 * time.Second); job.Status == "running"; time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("Import %s still running: %+v", job.ID, job)
+			}
+			if status := env.Policies.doAsStaff("GET", "/admin/policies/import/"+job.ID, "admin", nil, &job); status != http.StatusOK {
+				t.Fatalf("Import progress: got status %d", status)
+			}
+		}
+		if job.Status != "done" || job.TotalRows != 3 || job.Imported != 2 || job.Rejected != 1 {
+			t.Fatalf("Unexpected finished import %+v", job)
+		}
+		return job
+	}
+
+	// A dry run validates the file and creates nothing
+	run("?dryRun=true")
+	if status := env.Policies.doAsStaff("GET", "/admin/policies/by-number/LEGACY-0001", "admin", nil, nil); status != http.StatusNotFound {
+		t.Fatalf("A dry run created policies: lookup got status %d", status)
+	}
+
+	job := run("")
+	status, header, body := env.Policies.downloadAsStaff("/admin/policies/import/"+job.ID+"/report", "adjuster")
+	if status != http.StatusOK || header.Get("Content-Type") != "text/csv" {
+		t.Fatalf("Import report: got status %d, headers %v: %s", status, header, body)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil || len(rows) != 4 {
+		t.Fatalf("Unexpected import report %q: %v", body, err)
+	}
+	if rows[3][2] != "rejected" || rows[3][4] != "customer cust-404 does not exist" {
+		t.Errorf("Expected the unknown customer's row rejected, got %v", rows[3])
+	}
+
+	// Imported policies belong to their holders like any other
+	var imported, expired policy
+	env.Policies.mustDo("GET", "/policies/"+rows[1][3], "cust-002", nil, &imported, http.StatusOK)
+	env.Policies.mustDo("GET", "/policies/"+rows[2][3], "cust-003", nil, &expired, http.StatusOK)
+	if imported.PolicyNumber != "LEGACY-0001" || imported.Type != "home" || imported.Status != "active" || expired.Status != "expired" {
+		t.Errorf("Unexpected imported policies %+v, %+v", imported, expired)
+	}
+	if status := env.Policies.uploadAsStaff("/admin/policies/import", "customer", files, nil); status != http.StatusForbidden {
+		t.Errorf("Customer import: got status %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// samePremium compares money amounts to the cent
+func samePremium(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
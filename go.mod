@@ -0,0 +1,3 @@
+module github.com/CB-InsuranceStack/InsuranceStack
+
+go 1.21
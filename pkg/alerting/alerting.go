@@ -0,0 +1,147 @@
+// Package alerting raises alerts on business thresholds, such as the depth
+// of a webhook dead-letter queue or the share of payouts failing, for teams
+// without a Prometheus alertmanager. Each service registers its metrics
+// and an Evaluator reads them on a schedule, checks them against the rules
+// of a thresholds file and sends an alert to webhooks when a rule starts
+// or stops firing. Like the business rules, one thresholds file can serve
+// every service: a service evaluates only the rules of its own metrics.
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Path is where services serve the alerts endpoint
+const Path = "/admin/alerts"
+
+// DefaultInterval is how often metrics are evaluated when the service
+// configures no interval
+const DefaultInterval = time.Minute
+
+// Severities of a rule
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// States of a rule, and of the alerts sent when it changes
+const (
+	StateOK      = "ok"
+	StatePending = "pending" // breached, for less than the rule's for
+	StateFiring  = "firing"
+)
+
+// Set is a thresholds file
+type Set struct {
+	Version string `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule fires when its metric is above Above or below Below for at least
+// For. With Change set the rule checks how much the metric has changed
+// over that window instead of its value, so a growing backlog can alert
+// before it is large.
+type Rule struct {
+	Name     string   `json:"name"`
+	Metric   string   `json:"metric"` // <service>.<metric>, such as claims.open_claims
+	Above    *float64 `json:"above,omitempty"`
+	Below    *float64 `json:"below,omitempty"`
+	Change   string   `json:"change,omitempty"` // window, such as "24h"
+	For      string   `json:"for,omitempty"`    // such as "5m"; empty fires on the first breach
+	Severity string   `json:"severity,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+
+	change time.Duration
+	hold   time.Duration
+}
+
+// Service is the service the rule's metric belongs to
+func (r Rule) Service() string {
+	service, _, _ := strings.Cut(r.Metric, ".")
+	return service
+}
+
+// breached reports whether value is past either threshold
+func (r Rule) breached(value float64) bool {
+	return r.Above != nil && value > *r.Above || r.Below != nil && value < *r.Below
+}
+
+// Load reads and validates a thresholds file. A file that cannot be read
+// is returned as is, so callers can tell a missing file apart.
+func Load(path string) (*Set, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid alert thresholds in %s: %w", path, err)
+	}
+	if err := set.validate(); err != nil {
+		return nil, fmt.Errorf("invalid alert thresholds in %s: %w", path, err)
+	}
+	return &set, nil
+}
+
+// validate checks every rule has a unique name, a metric, a threshold and
+// valid durations, and parses the durations
+func (s *Set) validate() error {
+	names := make(map[string]bool, len(s.Rules))
+	for i := range s.Rules {
+		rule := &s.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %s is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if service, metric, ok := strings.Cut(rule.Metric, "."); !ok || service == "" || metric == "" {
+			return fmt.Errorf("rule %s metric %q must be <service>.<metric>", rule.Name, rule.Metric)
+		}
+		if rule.Above == nil && rule.Below == nil {
+			return fmt.Errorf("rule %s needs above or below", rule.Name)
+		}
+		switch rule.Severity {
+		case "":
+			rule.Severity = SeverityWarning
+		case SeverityWarning, SeverityCritical:
+		default:
+			return fmt.Errorf("rule %s severity %q must be %s or %s", rule.Name, rule.Severity, SeverityWarning, SeverityCritical)
+		}
+
+		var err error
+		if rule.change, err = parseDuration(rule.Change); err != nil {
+			return fmt.Errorf("rule %s change: %w", rule.Name, err)
+		}
+		if rule.Change != "" && rule.change == 0 {
+			return fmt.Errorf("rule %s change must be greater than zero", rule.Name)
+		}
+		if rule.hold, err = parseDuration(rule.For); err != nil {
+			return fmt.Errorf("rule %s for: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseDuration reads a duration that may be empty, for zero, but not
+// negative
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%s is negative", value)
+	}
+	return d, nil
+}
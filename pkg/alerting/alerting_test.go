@@ -0,0 +1,295 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestEvaluator(cfg Config) *Evaluator {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return New(cfg, logger)
+}
+
+func writeThresholds(t *testing.T, path, thresholds string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(thresholds), 0o644); err != nil {
+		t.Fatalf("Failed to write thresholds: %v", err)
+	}
+}
+
+// recorder keeps the alerts it is sent, failing while fail is set
+type recorder struct {
+	mu     sync.Mutex
+	fail   bool
+	alerts []Alert
+}
+
+func (r *recorder) Notify(ctx context.Context, alert Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail {
+		return errors.New("receiver down")
+	}
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func (r *recorder) sent() []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Alert(nil), r.alerts...)
+}
+
+func TestSeedThresholds(t *testing.T) {
+	path := filepath.Join("..", "..", "data", "seed", "alerts.json")
+	constant := func() (float64, error) { return 0, nil }
+	for service, metrics := range map[string][]string{
+		"claims":   {"webhook_dlq_depth", "open_claims"},
+		"payments": {"payout_failure_rate"},
+	} {
+		cfg := Config{Service: service, Path: path, Metrics: map[string]Metric{}}
+		for _, name := range metrics {
+			cfg.Metrics[name] = constant
+		}
+		evaluator := newTestEvaluator(cfg)
+		if err := evaluator.Load(); err != nil {
+			t.Fatalf("Load for %s failed: %v", service, err)
+		}
+		if evaluator.Len() == 0 {
+			t.Errorf("Expected rules for %s", service)
+		}
+	}
+}
+
+func TestLoadRefusesInvalidRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"no name", `[{"metric": "claims.open_claims", "above": 1}]`, "rule 1 has no name"},
+		{"duplicate", `[{"name": "a", "metric": "claims.open_claims", "above": 1}, {"name": "a", "metric": "claims.open_claims", "above": 2}]`, "rule a is defined twice"},
+		{"bare metric", `[{"name": "a", "metric": "open_claims", "above": 1}]`, `must be <service>.<metric>`},
+		{"no threshold", `[{"name": "a", "metric": "claims.open_claims"}]`, "needs above or below"},
+		{"severity", `[{"name": "a", "metric": "claims.open_claims", "above": 1, "severity": "page"}]`, `severity "page"`},
+		{"for", `[{"name": "a", "metric": "claims.open_claims", "above": 1, "for": "soon"}]`, "rule a for"},
+		{"change", `[{"name": "a", "metric": "claims.open_claims", "above": 1, "change": "0s"}]`, "change must be greater than zero"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeThresholds(t, path, `{"version": "test", "rules": `+tt.rules+`}`)
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file to be returned as is, got %v", err)
+	}
+}
+
+func TestLoadKeepsOnlyTheServicesRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	writeThresholds(t, path, `{"version": "1", "rules": [
+		{"name": "backlog", "metric": "claims.open_claims", "above": 10},
+		{"name": "payouts", "metric": "payments.payout_failure_rate", "above": 0.1}
+	]}`)
+	evaluator := newTestEvaluator(Config{Service: "claims", Path: path, Metrics: map[string]Metric{
+		"open_claims": func() (float64, error) { return 0, nil },
+	}})
+	if err := evaluator.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if status := evaluator.Status(); status.Version != "1" || len(status.Rules) != 1 || status.Rules[0].Name != "backlog" || status.Rules[0].State != StateOK {
+		t.Errorf("Expected only the claims rule, got %+v", status)
+	}
+
+	writeThresholds(t, path, `{"version": "2", "rules": [{"name": "dlq", "metric": "claims.webhook_dlq_depth", "above": 10}]}`)
+	if err := evaluator.Load(); err == nil || !strings.Contains(err.Error(), "unknown metric claims.webhook_dlq_depth (want one of claims.open_claims)") {
+		t.Errorf("Expected an unknown metric of the service to fail the load, got %v", err)
+	}
+	if evaluator.Status().Version != "1" {
+		t.Error("Expected the current rules to be kept")
+	}
+}
+
+func TestEvaluateFiresAfterForAndResolves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	writeThresholds(t, path, `{"version": "1", "rules": [
+		{"name": "dlq", "metric": "claims.webhook_dlq_depth", "above": 100, "for": "5m", "severity": "critical", "summary": "DLQ is deep"},
+		{"name": "idle", "metric": "claims.webhook_dlq_depth", "below": 0}
+	]}`)
+	depth := 0.0
+	notifier := &recorder{}
+	evaluator := newTestEvaluator(Config{Service: "claims", Path: path, Notifier: notifier, Metrics: map[string]Metric{
+		"webhook_dlq_depth": func() (float64, error) { return depth, nil },
+	}})
+	if err := evaluator.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	ctx := context.Background()
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	depth = 150
+	evaluator.Evaluate(ctx, start)
+	if st := evaluator.Status().Rules[0]; st.State != StatePending || *st.Value != 150 || !st.Since.Equal(start) {
+		t.Errorf("Expected the rule pending from the first breach, got %+v", st)
+	}
+	evaluator.Evaluate(ctx, start.Add(4*time.Minute))
+	if len(notifier.sent()) != 0 {
+		t.Fatalf("Expected no alert before the rule's for, got %+v", notifier.sent())
+	}
+
+	notifier.fail = true
+	evaluator.Evaluate(ctx, start.Add(5*time.Minute))
+	if st := evaluator.Status(); st.Rules[0].State != StateFiring || st.Unsent != 1 {
+		t.Errorf("Expected the rule firing with its alert unsent, got %+v", st)
+	}
+	notifier.fail = false
+	evaluator.Evaluate(ctx, start.Add(6*time.Minute))
+	sent := notifier.sent()
+	if len(sent) != 1 {
+		t.Fatalf("Expected the firing alert to be sent once on retry, got %+v", sent)
+	}
+	firing := sent[0]
+	if firing.Type != AlertFiring || firing.Rule != "dlq" || firing.Service != "claims" || firing.Value != 150 || *firing.Above != 100 ||
+		firing.Severity != SeverityCritical || !firing.Since.Equal(start) || !firing.At.Equal(start.Add(5*time.Minute)) || firing.ID == "" {
+		t.Errorf("Unexpected firing alert %+v", firing)
+	}
+
+	depth = 20
+	evaluator.Evaluate(ctx, start.Add(7*time.Minute))
+	sent = notifier.sent()
+	if len(sent) != 2 || sent[1].Type != AlertResolved || sent[1].Value != 20 || !sent[1].Since.Equal(start) || sent[1].ID == firing.ID {
+		t.Fatalf("Expected a resolved alert, got %+v", sent)
+	}
+	if st := evaluator.Status().Rules[0]; st.State != StateOK || st.Since != nil {
+		t.Errorf("Expected the rule back to ok, got %+v", st)
+	}
+
+	var metrics strings.Builder
+	evaluator.WritePrometheus(&metrics)
+	if !strings.Contains(metrics.String(), `alerts_firing{rule="dlq",severity="critical"} 0`) {
+		t.Errorf("Expected the rule in alerts_firing, got:\n%s", metrics.String())
+	}
+}
+
+func TestEvaluateChangeOverWindow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	writeThresholds(t, path, `{"version": "1", "rules": [{"name": "growth", "metric": "claims.open_claims", "change": "24h", "above": 50}]}`)
+	open := 100.0
+	notifier := &recorder{}
+	evaluator := newTestEvaluator(Config{Service: "claims", Path: path, Notifier: notifier, Metrics: map[string]Metric{
+		"open_claims": func() (float64, error) { return open, nil },
+	}})
+	if err := evaluator.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	ctx := context.Background()
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for hour := 0; hour <= 23; hour++ {
+		open = 100 + float64(hour)*5
+		evaluator.Evaluate(ctx, start.Add(time.Duration(hour)*time.Hour))
+	}
+	if st := evaluator.Status().Rules[0]; st.Value != nil || st.State != StateOK {
+		t.Errorf("Expected no change before a day of history, got %+v", st)
+	}
+
+	open = 160
+	evaluator.Evaluate(ctx, start.Add(24*time.Hour))
+	sent := notifier.sent()
+	if len(sent) != 1 || sent[0].Type != AlertFiring || sent[0].Value != 60 || sent[0].Change != "24h" {
+		t.Fatalf("Expected growth of 60 in a day to fire, got %+v", sent)
+	}
+
+	open = 170
+	evaluator.Evaluate(ctx, start.Add(26*time.Hour))
+	if st := evaluator.Status().Rules[0]; *st.Value != 60 || st.State != StateFiring {
+		t.Errorf("Expected the change against two hours past the window start, got %+v", st)
+	}
+	if history := evaluator.samples["open_claims"]; len(history) > 24 {
+		t.Errorf("Expected samples older than the window to be dropped, kept %d", len(history))
+	}
+}
+
+func TestEvaluateKeepsStateWhenAMetricFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	writeThresholds(t, path, `{"version": "1", "rules": [{"name": "payouts", "metric": "payments.payout_failure_rate", "above": 0.1}]}`)
+	var readErr error
+	evaluator := newTestEvaluator(Config{Service: "payments", Path: path, Metrics: map[string]Metric{
+		"payout_failure_rate": func() (float64, error) { return 0.5, readErr },
+	}})
+	if err := evaluator.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	now := time.Now()
+	evaluator.Evaluate(context.Background(), now)
+	if st := evaluator.Status().Rules[0]; st.State != StateFiring {
+		t.Fatalf("Expected the rule to fire at once without a for, got %+v", st)
+	}
+
+	readErr = errors.New("store unavailable")
+	evaluator.Evaluate(context.Background(), now.Add(time.Minute))
+	if st := evaluator.Status().Rules[0]; st.State != StateFiring || st.Error != "store unavailable" || !st.EvaluatedAt.Equal(now) {
+		t.Errorf("Expected the rule to stay firing with the read error, got %+v", st)
+	}
+}
+
+func TestWebhooksSignAlerts(t *testing.T) {
+	var mu sync.Mutex
+	var got []*http.Request
+	var bodies []Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		json.NewDecoder(r.Body).Decode(&alert)
+		mu.Lock()
+		got = append(got, r)
+		bodies = append(bodies, alert)
+		mu.Unlock()
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	notifier := &Webhooks{URLs: []string{server.URL + "/hook", server.URL + "/down"}, Signer: fixedSigner("sha256=abc")}
+	above := 100.0
+	alert := Alert{ID: "dlq-1-firing", Type: AlertFiring, Rule: "dlq", Metric: "claims.webhook_dlq_depth", Value: 150, Above: &above}
+	err := notifier.Notify(context.Background(), alert)
+	if err == nil || !strings.Contains(err.Error(), "/down: unexpected status 502") || strings.Contains(err.Error(), "/hook") {
+		t.Errorf("Expected only the failing URL in the error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("Expected both URLs to be called, got %d", len(got))
+	}
+	if r := got[0]; r.Method != http.MethodPost || r.Header.Get("X-Event-Type") != AlertFiring || r.Header.Get("X-Event-ID") != "dlq-1-firing" || r.Header.Get("X-Webhook-Signature") != "sha256=abc" {
+		t.Errorf("Unexpected request headers %v", r.Header)
+	}
+	if bodies[0].Rule != "dlq" || bodies[0].Value != 150 || *bodies[0].Above != 100 {
+		t.Errorf("Unexpected alert body %+v", bodies[0])
+	}
+}
+
+// fixedSigner signs every body with the same signature
+type fixedSigner string
+
+func (s fixedSigner) Sign(body []byte) string { return string(s) }
@@ -0,0 +1,393 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxUnsent bounds the alerts kept for another try after their webhooks
+// failed; the oldest are dropped first
+const maxUnsent = 100
+
+// Metric reads the current value of one of a service's metrics
+type Metric func() (float64, error)
+
+// Types of the alerts sent
+const (
+	AlertFiring   = "alert.firing"
+	AlertResolved = "alert.resolved"
+)
+
+// Alert is sent when a rule starts firing and when it stops. ID is the
+// same for every try at sending an alert, so receivers can drop repeats.
+type Alert struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Rule     string    `json:"rule"`
+	Service  string    `json:"service"`
+	Metric   string    `json:"metric"`
+	Severity string    `json:"severity"`
+	Summary  string    `json:"summary,omitempty"`
+	Value    float64   `json:"value"` // the metric, or its change over Change
+	Above    *float64  `json:"above,omitempty"`
+	Below    *float64  `json:"below,omitempty"`
+	Change   string    `json:"change,omitempty"`
+	Since    time.Time `json:"since"` // when the rule was first breached
+	At       time.Time `json:"at"`
+}
+
+// Notifier sends alerts, such as to webhooks. Notify returns an error when
+// the alert was not delivered; it is tried again at the next evaluation.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// Config is a service's metrics and where its rules come from
+type Config struct {
+	// Service names the service's metrics in rules: claims evaluates the
+	// rules of claims.* metrics. Rules of other services in the file are
+	// checked but not kept.
+	Service string
+	// Path is the thresholds file; empty evaluates no rules
+	Path string
+	// Metrics are the service's metrics, by name without the service. A
+	// rule of the service naming another metric fails the load.
+	Metrics map[string]Metric
+	// Notifier sends alerts; nil only logs them
+	Notifier Notifier
+}
+
+// Evaluator checks a service's metrics against its rules. It is safe for
+// concurrent use; the zero value is not, use New.
+type Evaluator struct {
+	cfg    Config
+	logger *logrus.Logger
+
+	mu       sync.Mutex
+	version  string
+	loadedAt time.Time
+	rules    []Rule
+	states   map[string]*ruleState // by rule name, kept across reloads
+	samples  map[string][]sample   // by metric, for rules on its change
+	unsent   []Alert               // alerts whose notification failed
+}
+
+// ruleState is where a rule stands after its last evaluation
+type ruleState struct {
+	state       string
+	value       *float64
+	since       time.Time // when the current breach began
+	evaluatedAt time.Time
+	err         string
+}
+
+// sample is a metric's value at one evaluation
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// New creates an evaluator without rules; Load reads the thresholds file
+func New(cfg Config, logger *logrus.Logger) *Evaluator {
+	return &Evaluator{
+		cfg:     cfg,
+		logger:  logger,
+		states:  make(map[string]*ruleState),
+		samples: make(map[string][]sample),
+	}
+}
+
+// Load reads the thresholds file, replacing the rules when every rule
+// checks. A file that cannot be read is returned as is, so callers can
+// tell a missing file apart; otherwise the current rules are kept on
+// error. Rules that stay keep their state, so reloading does not resend
+// their alerts.
+func (e *Evaluator) Load() error {
+	set, err := Load(e.cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	var rules []Rule
+	for _, rule := range set.Rules {
+		if rule.Service() != e.cfg.Service {
+			continue
+		}
+		if _, ok := e.cfg.Metrics[metricName(rule)]; !ok {
+			return fmt.Errorf("invalid alert thresholds in %s: rule %s reads unknown metric %s (want one of %s)", e.cfg.Path, rule.Name, rule.Metric, strings.Join(e.metricNames(), ", "))
+		}
+		rules = append(rules, rule)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.version = set.Version
+	e.loadedAt = time.Now()
+	e.rules = rules
+	states := make(map[string]*ruleState, len(rules))
+	for _, rule := range rules {
+		if st, ok := e.states[rule.Name]; ok {
+			states[rule.Name] = st
+		} else {
+			states[rule.Name] = &ruleState{state: StateOK}
+		}
+	}
+	e.states = states
+	return nil
+}
+
+// metricNames are the service's metrics in rules' form, sorted
+func (e *Evaluator) metricNames() []string {
+	names := make([]string, 0, len(e.cfg.Metrics))
+	for name := range e.cfg.Metrics {
+		names = append(names, e.cfg.Service+"."+name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// metricName is a rule's metric without its service
+func metricName(rule Rule) string {
+	_, name, _ := strings.Cut(rule.Metric, ".")
+	return name
+}
+
+// Len is how many rules the evaluator checks
+func (e *Evaluator) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.rules)
+}
+
+// Run evaluates the rules every interval until ctx is done, reloading the
+// thresholds file first whenever it has changed. A file that fails to
+// load is logged and the current rules kept.
+func (e *Evaluator) Run(ctx context.Context, interval time.Duration) {
+	last := fingerprint(e.cfg.Path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if current := fingerprint(e.cfg.Path); current != last {
+				last = current
+				if err := e.Load(); err != nil {
+					e.logger.WithError(err).Warn("Failed to reload alert thresholds, keeping current rules")
+				} else {
+					e.logger.WithField("rules", e.Len()).Info("Reloaded alert thresholds")
+				}
+			}
+			e.Evaluate(ctx, time.Now())
+		}
+	}
+}
+
+// fingerprint identifies the thresholds file on disk by size and
+// modification time; empty when it is missing
+func fingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d-%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Evaluate reads each metric the rules need once and moves each rule on
+// at now: a breached rule is pending until it has been breached for its
+// for, then fires, and resolves once it is no longer breached. Firing and
+// resolving send an alert; alerts that could not be sent before are tried
+// again first. A metric that cannot be read leaves its rules as they were.
+func (e *Evaluator) Evaluate(ctx context.Context, now time.Time) {
+	e.mu.Lock()
+	rules := e.rules
+	e.mu.Unlock()
+
+	values := make(map[string]float64)
+	failures := make(map[string]error)
+	for _, rule := range rules {
+		name := metricName(rule)
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if _, ok := failures[name]; ok {
+			continue
+		}
+		value, err := e.cfg.Metrics[name]()
+		if err != nil {
+			failures[name] = err
+			e.logger.WithError(err).WithField("metric", rule.Metric).Warn("Failed to read alert metric")
+			continue
+		}
+		values[name] = value
+	}
+
+	e.mu.Lock()
+	e.record(rules, values, now)
+	var raised []Alert
+	for _, rule := range rules {
+		st, ok := e.states[rule.Name]
+		if !ok {
+			// Reloaded away while its metric was read
+			continue
+		}
+		name := metricName(rule)
+		if err, ok := failures[name]; ok {
+			st.err = err.Error()
+			continue
+		}
+		value := values[name]
+		if rule.change > 0 {
+			before, ok := e.sampleAt(name, now.Add(-rule.change))
+			if !ok {
+				// Not enough history yet to tell the change
+				st.err = ""
+				continue
+			}
+			value -= before
+		}
+		st.err = ""
+		st.value = &value
+		st.evaluatedAt = now
+
+		if !rule.breached(value) {
+			if st.state == StateFiring {
+				raised = append(raised, newAlert(rule, AlertResolved, value, st.since, now))
+			}
+			st.state, st.since = StateOK, time.Time{}
+			continue
+		}
+		if st.state == StateOK {
+			st.state, st.since = StatePending, now
+		}
+		if st.state == StatePending && now.Sub(st.since) >= rule.hold {
+			st.state = StateFiring
+			raised = append(raised, newAlert(rule, AlertFiring, value, st.since, now))
+		}
+	}
+	pending := append(e.unsent, raised...)
+	e.unsent = nil
+	e.mu.Unlock()
+
+	for _, alert := range raised {
+		e.log(alert)
+	}
+	if e.cfg.Notifier == nil {
+		return
+	}
+	var failed []Alert
+	for _, alert := range pending {
+		if err := e.cfg.Notifier.Notify(ctx, alert); err != nil {
+			e.logger.WithError(err).WithFields(logrus.Fields{
+				"rule":    alert.Rule,
+				"alertId": alert.ID,
+			}).Warn("Failed to send alert, retrying at the next evaluation")
+			failed = append(failed, alert)
+		}
+	}
+	if len(failed) > 0 {
+		e.mu.Lock()
+		e.unsent = append(failed, e.unsent...)
+		if len(e.unsent) > maxUnsent {
+			e.unsent = e.unsent[len(e.unsent)-maxUnsent:]
+		}
+		e.mu.Unlock()
+	}
+}
+
+// record keeps the values of the metrics rules read the change of, back to
+// the longest window; the caller holds e.mu
+func (e *Evaluator) record(rules []Rule, values map[string]float64, now time.Time) {
+	windows := make(map[string]time.Duration)
+	for _, rule := range rules {
+		if name := metricName(rule); rule.change > windows[name] {
+			windows[name] = rule.change
+		}
+	}
+	for name := range e.samples {
+		if _, ok := windows[name]; !ok {
+			delete(e.samples, name)
+		}
+	}
+	for name, window := range windows {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		history := append(e.samples[name], sample{at: now, value: value})
+		// Keep the newest sample at least window old, and those after it
+		cutoff := now.Add(-window)
+		drop := 0
+		for drop+1 < len(history) && !history[drop+1].at.After(cutoff) {
+			drop++
+		}
+		e.samples[name] = history[drop:]
+	}
+}
+
+// sampleAt is the metric's newest value recorded at or before t; the
+// caller holds e.mu
+func (e *Evaluator) sampleAt(name string, t time.Time) (float64, bool) {
+	history := e.samples[name]
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].at.After(t) {
+			return history[i].value, true
+		}
+	}
+	return 0, false
+}
+
+// newAlert describes a rule firing or resolving
+func newAlert(rule Rule, alertType string, value float64, since, at time.Time) Alert {
+	return Alert{
+		ID:       fmt.Sprintf("%s-%d-%s", rule.Name, since.UnixNano(), strings.TrimPrefix(alertType, "alert.")),
+		Type:     alertType,
+		Rule:     rule.Name,
+		Service:  rule.Service(),
+		Metric:   rule.Metric,
+		Severity: rule.Severity,
+		Summary:  rule.Summary,
+		Value:    value,
+		Above:    rule.Above,
+		Below:    rule.Below,
+		Change:   rule.Change,
+		Since:    since,
+		At:       at,
+	}
+}
+
+// log writes an alert to the service log: critical alerts as errors and
+// warnings as warnings when they fire, and either as info when resolved
+func (e *Evaluator) log(alert Alert) {
+	entry := e.logger.WithFields(logrus.Fields{
+		"rule":     alert.Rule,
+		"metric":   alert.Metric,
+		"value":    alert.Value,
+		"severity": alert.Severity,
+		"alertId":  alert.ID,
+	})
+	switch {
+	case alert.Type == AlertResolved:
+		entry.Infof("Alert resolved: %s", alert.Rule)
+	case alert.Severity == SeverityCritical:
+		entry.Errorf("Alert firing: %s", describe(alert))
+	default:
+		entry.Warnf("Alert firing: %s", describe(alert))
+	}
+}
+
+// describe is an alert's summary, or its rule when it has none
+func describe(alert Alert) string {
+	if alert.Summary != "" {
+		return alert.Summary
+	}
+	return alert.Rule
+}
@@ -0,0 +1,7 @@
+module github.com/CB-InsuranceStack/InsuranceStack/pkg/alerting
+
+go 1.21
+
+require github.com/sirupsen/logrus v1.9.3
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,92 @@
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Status is the current rules and where each stands, as served by
+// GET /admin/alerts
+type Status struct {
+	Service  string       `json:"service"`
+	Version  string       `json:"version"`
+	LoadedAt *time.Time   `json:"loadedAt,omitempty"`
+	Rules    []RuleStatus `json:"rules"`
+	Unsent   int          `json:"unsent"` // alerts waiting to be sent again
+}
+
+// RuleStatus is a rule and its state after the last evaluation
+type RuleStatus struct {
+	Rule
+	State       string     `json:"state"`
+	Value       *float64   `json:"value,omitempty"` // the metric, or its change over Change
+	Since       *time.Time `json:"since,omitempty"` // when the current breach began
+	EvaluatedAt *time.Time `json:"evaluatedAt,omitempty"`
+	Error       string     `json:"error,omitempty"` // why the metric could not be read
+}
+
+// Status returns the current rules in file order
+func (e *Evaluator) Status() Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	status := Status{Service: e.cfg.Service, Version: e.version, Rules: []RuleStatus{}, Unsent: len(e.unsent)}
+	if !e.loadedAt.IsZero() {
+		loadedAt := e.loadedAt
+		status.LoadedAt = &loadedAt
+	}
+	for _, rule := range e.rules {
+		st := e.states[rule.Name]
+		rs := RuleStatus{Rule: rule, State: st.state, Error: st.err}
+		if st.value != nil {
+			value := *st.value
+			rs.Value = &value
+		}
+		if !st.since.IsZero() {
+			since := st.since
+			rs.Since = &since
+		}
+		if !st.evaluatedAt.IsZero() {
+			evaluatedAt := st.evaluatedAt
+			rs.EvaluatedAt = &evaluatedAt
+		}
+		status.Rules = append(status.Rules, rs)
+	}
+	return status
+}
+
+// Handler serves GET /admin/alerts with the current rules and their
+// states. Guard it with the service's staff role check.
+func (e *Evaluator) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(e.Status())
+	})
+}
+
+// WritePrometheus writes whether each rule is firing as alerts_firing in
+// the Prometheus text exposition format
+func (e *Evaluator) WritePrometheus(w io.Writer) {
+	rules := e.Status().Rules
+
+	fmt.Fprint(w, "# HELP alerts_firing Whether each alert rule is firing, by rule and severity.\n")
+	fmt.Fprint(w, "# TYPE alerts_firing gauge\n")
+	for _, rule := range rules {
+		firing := 0
+		if rule.State == StateFiring {
+			firing = 1
+		}
+		fmt.Fprintf(w, "alerts_firing{rule=\"%s\",severity=\"%s\"} %d\n", escapeLabel(rule.Name), escapeLabel(rule.Severity), firing)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,77 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// signatureHeader carries the signatures of an alert body, as the
+// services' other webhooks do
+const signatureHeader = "X-Webhook-Signature"
+
+// Signer signs webhook bodies; signing.Keys is one
+type Signer interface {
+	Sign(body []byte) string
+}
+
+// Webhooks POSTs each alert as JSON to every URL, with X-Event-Type and
+// X-Event-ID headers and, with a Signer, an X-Webhook-Signature header
+type Webhooks struct {
+	URLs   []string
+	Signer Signer       // nil sends alerts unsigned
+	Client *http.Client // nil uses a client with a 10 second timeout
+}
+
+var _ Notifier = (*Webhooks)(nil)
+
+// Notify sends alert to every URL, returning the failures, if any. A
+// response other than 2xx is a failure.
+func (w *Webhooks) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var errs []error
+	for _, url := range w.URLs {
+		if err := w.send(ctx, client, url, alert, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send POSTs one alert body to url
+func (w *Webhooks) send(ctx context.Context, client *http.Client, url string, alert Alert, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", alert.Type)
+	req.Header.Set("X-Event-ID", alert.ID)
+	if w.Signer != nil {
+		if signature := w.Signer.Sign(body); signature != "" {
+			req.Header.Set(signatureHeader, signature)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Package blobstore keeps the files the services are sent, such as claim
+// documents and identity documents, outside their record stores: on local
+// disk or in an S3 bucket. Every store enforces the same limits on what it
+// accepts, a maximum size and the content types allowed, and hands out
+// signed URLs that download an object without credentials until they
+// expire.
+package blobstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Backends, as named in BLOBSTORE_BACKEND
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// DefaultURLTTL is how long a signed URL is valid when the configuration
+// sets no other
+const DefaultURLTTL = 15 * time.Minute
+
+// MaxURLTTL is the longest a signed URL may be valid, as S3 allows
+const MaxURLTTL = 7 * 24 * time.Hour
+
+// ErrNotFound is returned for a key the store does not hold
+var ErrNotFound = errors.New("blob not found")
+
+// Object describes a stored blob
+type Object struct {
+	Key         string
+	ContentType string
+	Size        int64
+	UpdatedAt   time.Time
+}
+
+// Store keeps blobs by key. Keys are slash-separated paths such as
+// "claims/claim-001/doc-1734000000000000000"; Put replaces the blob at an
+// existing key. SignedURL returns a URL that downloads the blob at key
+// until ttl has passed, as an attachment named fileName, or inline when
+// fileName is empty.
+type Store interface {
+	Put(ctx context.Context, key, contentType string, content []byte) error
+	Get(ctx context.Context, key string) (*Object, []byte, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(key string, ttl time.Duration, fileName string) (string, error)
+}
+
+// Limits is what a store accepts. Content types are matched without their
+// parameters, exactly or by a "type/*" wildcard; none allows any type. A
+// zero MaxSize allows any size.
+type Limits struct {
+	MaxSize      int64
+	ContentTypes []string
+}
+
+// LimitError is returned by Put for content its store's limits refuse
+type LimitError struct {
+	Key    string
+	Reason string
+}
+
+func (e *LimitError) Error() string {
+	return e.Reason
+}
+
+// Check returns a *LimitError when content of contentType and size is
+// outside l
+func (l Limits) Check(key, contentType string, size int64) error {
+	if l.MaxSize > 0 && size > l.MaxSize {
+		return &LimitError{Key: key, Reason: fmt.Sprintf("content of %d bytes exceeds the %d byte limit", size, l.MaxSize)}
+	}
+	if len(l.ContentTypes) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return &LimitError{Key: key, Reason: fmt.Sprintf("invalid content type %q", contentType)}
+	}
+	for _, allowed := range l.ContentTypes {
+		if mediaType == allowed || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return nil
+		}
+	}
+	return &LimitError{Key: key, Reason: fmt.Sprintf("content type %s is not accepted (want %s)", mediaType, strings.Join(l.ContentTypes, ", "))}
+}
+
+// CheckKey refuses keys that are empty, absolute, or that step out of the
+// store with "." or ".." segments
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
+
+// Config selects and configures a store
+type Config struct {
+	Backend string        // BLOBSTORE_BACKEND: local or s3; empty for none
+	URLTTL  time.Duration // BLOBSTORE_URL_TTL, how long signed URLs are valid
+	Local   Local
+	S3      S3
+}
+
+// ConfigFromEnv reads the store's configuration from the environment. The
+// local store's signing key is a secret and is left for the caller to set
+// from BLOBSTORE_SIGNING_KEY.
+func ConfigFromEnv(logger *logrus.Logger) Config {
+	cfg := Config{
+		Backend: strings.ToLower(strings.TrimSpace(os.Getenv("BLOBSTORE_BACKEND"))),
+		URLTTL:  DefaultURLTTL,
+		Local: Local{
+			Dir:     os.Getenv("BLOBSTORE_DIR"),
+			BaseURL: os.Getenv("BLOBSTORE_PUBLIC_URL"),
+		},
+		S3: S3{
+			Bucket:          os.Getenv("BLOBSTORE_S3_BUCKET"),
+			Region:          os.Getenv("AWS_REGION"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		},
+	}
+	if cfg.S3.Region == "" {
+		cfg.S3.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if v := os.Getenv("BLOBSTORE_S3_PATH_STYLE"); v != "" {
+		if pathStyle, err := strconv.ParseBool(v); err == nil {
+			cfg.S3.PathStyle = pathStyle
+		} else {
+			logger.Warnf("Invalid BLOBSTORE_S3_PATH_STYLE '%s', defaulting to false", v)
+		}
+	}
+	if v := os.Getenv("BLOBSTORE_URL_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 && d <= MaxURLTTL {
+			cfg.URLTTL = d
+		} else {
+			logger.Warnf("Invalid BLOBSTORE_URL_TTL '%s', defaulting to %s", v, DefaultURLTTL)
+		}
+	}
+	return cfg
+}
+
+// Open returns the store cfg names, accepting content within limits. An
+// empty backend returns nil: the service keeps content in its own store.
+func Open(cfg Config, limits Limits) (Store, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case BackendLocal:
+		if cfg.Local.Dir == "" {
+			return nil, fmt.Errorf("local blob store needs BLOBSTORE_DIR")
+		}
+		local := cfg.Local
+		local.Limits = limits
+		if err := os.MkdirAll(local.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create blob store directory: %w", err)
+		}
+		return &local, nil
+	case BackendS3:
+		if cfg.S3.Bucket == "" || cfg.S3.Region == "" || cfg.S3.AccessKeyID == "" || cfg.S3.SecretAccessKey == "" {
+			return nil, fmt.Errorf("s3 blob store needs BLOBSTORE_S3_BUCKET, AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		s3 := cfg.S3
+		s3.Limits = limits
+		return &s3, nil
+	default:
+		return nil, fmt.Errorf("unknown blob store backend %q (want %s or %s)", cfg.Backend, BackendLocal, BackendS3)
+	}
+}
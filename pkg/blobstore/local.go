@@ -0,0 +1,217 @@
+package blobstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local keeps blobs as files under Dir: the content at objects/<key> and
+// its description at meta/<key>. Several instances of a service share a
+// store by mounting the same Dir. Signed URLs point at BaseURL, which must
+// route to Local's ServeHTTP, and are signed with SigningKey.
+type Local struct {
+	Dir        string // BLOBSTORE_DIR
+	BaseURL    string // BLOBSTORE_PUBLIC_URL, such as http://localhost:8002/blobs
+	SigningKey string // BLOBSTORE_SIGNING_KEY; no signed URLs without one
+	Limits     Limits
+}
+
+// Put writes content at key, replacing any blob there. The content and its
+// description are each written to a temporary file and renamed into place,
+// so a reader never sees either half written.
+func (l *Local) Put(ctx context.Context, key, contentType string, content []byte) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	if err := l.Limits.Check(key, contentType, int64(len(content))); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(Object{Key: key, ContentType: contentType, Size: int64(len(content)), UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode blob %s: %w", key, err)
+	}
+	if err := writeFile(l.path("objects", key), content); err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	if err := writeFile(l.path("meta", key), meta); err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get reads the blob at key
+func (l *Local) Get(ctx context.Context, key string) (*Object, []byte, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := os.ReadFile(l.path("meta", key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+	var object Object
+	if err := json.Unmarshal(meta, &object); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode blob %s: %w", key, err)
+	}
+	content, err := os.ReadFile(l.path("objects", key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+	return &object, content, nil
+}
+
+// Delete removes the blob at key. Deleting a missing blob is not an error.
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	for _, path := range []string{l.path("meta", key), l.path("objects", key)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete blob %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// SignedURL returns BaseURL/<key> with the expiry, the file name and an
+// HMAC-SHA256 of the three in its query
+func (l *Local) SignedURL(key string, ttl time.Duration, fileName string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	if l.BaseURL == "" || l.SigningKey == "" {
+		return "", fmt.Errorf("local blob store needs BLOBSTORE_PUBLIC_URL and BLOBSTORE_SIGNING_KEY to sign URLs")
+	}
+	if ttl <= 0 || ttl > MaxURLTTL {
+		return "", fmt.Errorf("signed URL lifetime must be between 0 and %s", MaxURLTTL)
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {l.sign(key, expires, fileName)}}
+	if fileName != "" {
+		query.Set("filename", fileName)
+	}
+	return strings.TrimRight(l.BaseURL, "/") + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// ServeHTTP serves the blobs of signed URLs: GET or HEAD of the base URL's
+// path followed by the key. Requests with an expired or wrong signature are
+// refused with 403.
+func (l *Local) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, l.PathPrefix())
+	if !ok || CheckKey(key) != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	expires, fileName := query.Get("expires"), query.Get("filename")
+	expiry, err := strconv.ParseInt(expires, 10, 64)
+	if l.SigningKey == "" || err != nil || time.Now().Unix() > expiry ||
+		!hmac.Equal([]byte(query.Get("signature")), []byte(l.sign(key, expires, fileName))) {
+		http.Error(w, "signature invalid or expired", http.StatusForbidden)
+		return
+	}
+
+	object, content, err := l.Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to read blob", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Cache-Control", "private, no-store")
+	if fileName != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(content)
+	}
+}
+
+// PathPrefix is the path of BaseURL, ending in a slash: where ServeHTTP
+// must be routed
+func (l *Local) PathPrefix() string {
+	if base, err := url.Parse(l.BaseURL); err == nil && base.Path != "" {
+		return strings.TrimRight(base.Path, "/") + "/"
+	}
+	return "/"
+}
+
+// sign is the hex HMAC-SHA256 of a signed URL's key, expiry and file name
+func (l *Local) sign(key, expires, fileName string) string {
+	mac := hmac.New(sha256.New, []byte(l.SigningKey))
+	mac.Write([]byte(key + "\n" + expires + "\n" + fileName))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path is where the part of the blob at key is kept
+func (l *Local) path(part, key string) string {
+	return filepath.Join(l.Dir, part, filepath.FromSlash(key))
+}
+
+// writeFile writes data to path through a temporary file in its directory
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// escapeKey escapes each segment of key for a URL path
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,311 @@
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3 keeps blobs as the objects of an S3 bucket, or of a bucket on an
+// S3-compatible service such as MinIO or LocalStack. Requests are signed
+// with AWS Signature Version 4, and signed URLs are presigned GETs.
+type S3 struct {
+	Bucket          string // BLOBSTORE_S3_BUCKET
+	Region          string // AWS_REGION, or AWS_DEFAULT_REGION
+	AccessKeyID     string // AWS_ACCESS_KEY_ID
+	SecretAccessKey string // AWS_SECRET_ACCESS_KEY
+	SessionToken    string // AWS_SESSION_TOKEN, for temporary credentials; none when empty
+	Endpoint        string // AWS_ENDPOINT_URL; the regional endpoint when empty
+	// PathStyle addresses the bucket in the path, endpoint/bucket/key,
+	// rather than as the host, bucket.endpoint/key, as MinIO and LocalStack
+	// need
+	PathStyle bool         // BLOBSTORE_S3_PATH_STYLE
+	Limits    Limits       // what Put accepts
+	Client    *http.Client // http.DefaultClient when nil
+}
+
+// s3Service is the S3 signing name
+const s3Service = "s3"
+
+// emptyHash is the SHA-256 of an empty body
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Put uploads content as the object at key
+func (s *S3) Put(ctx context.Context, key, contentType string, content []byte) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	if err := s.Limits.Check(key, contentType, int64(len(content))); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, contentType, content)
+	if err != nil {
+		return fmt.Errorf("failed to write blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("s3 returned %d %s writing blob %s", resp.StatusCode, s3ErrorCode(resp.Body), key)
+	}
+	return nil
+}
+
+// Get downloads the object at key
+func (s *S3) Get(ctx context.Context, key string) (*Object, []byte, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, "", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("s3 returned %d %s reading blob %s", resp.StatusCode, s3ErrorCode(resp.Body), key)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read blob %s: %w", key, err)
+	}
+	object := &Object{Key: key, ContentType: resp.Header.Get("Content-Type"), Size: int64(len(content))}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.UpdatedAt = modified
+	}
+	return object, content, nil
+}
+
+// Delete removes the object at key. Deleting a missing object is not an
+// error.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, "", nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("s3 returned %d %s deleting blob %s", resp.StatusCode, s3ErrorCode(resp.Body), key)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object at key
+func (s *S3) SignedURL(key string, ttl time.Duration, fileName string) (string, error) {
+	if err := CheckKey(key); err != nil {
+		return "", err
+	}
+	if ttl <= 0 || ttl > MaxURLTTL {
+		return "", fmt.Errorf("signed URL lifetime must be between 0 and %s", MaxURLTTL)
+	}
+	return s.presign(key, ttl, fileName, time.Now().UTC())
+}
+
+// presign returns the URL of a GET of key signed at now
+func (s *S3) presign(key string, ttl time.Duration, fileName string, now time.Time) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.Region + "/" + s3Service + "/aws4_request"
+
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.AccessKeyID + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(ttl / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if s.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	if fileName != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		uriEncode(u.Path, false),
+		canonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		"UNSIGNED-PAYLOAD",
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(amzDate, scope, canonicalRequest))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+// do sends a request for the object at key, signed in its headers
+func (s *S3) do(ctx context.Context, method, key, contentType string, body []byte) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, body, time.Now().UTC())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// objectURL is where the object at key is addressed
+func (s *S3) objectURL(key string) (*url.URL, error) {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + s3Service + "." + s.Region + ".amazonaws.com"
+	}
+	u, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", s.Endpoint)
+	}
+	if s.PathStyle {
+		u.Path += "/" + s.Bucket + "/" + key
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return u, nil
+}
+
+// sign adds the Signature Version 4 headers for body sent at now
+func (s *S3) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	scope := now.Format("20060102") + "/" + s.Region + "/" + s3Service + "/aws4_request"
+	payloadHash := emptyHash
+	if len(body) > 0 {
+		payloadHash = hashHex(body)
+	}
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	// Every header set above is signed, in lower case and sorted; Host is
+	// sent by the client from the URL
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if req.Header.Get("Content-Type") != "" {
+		signed = append(signed, "content-type")
+	}
+	if s.SessionToken != "" {
+		signed = append(signed, "x-amz-security-token")
+	}
+	sort.Strings(signed)
+	var canonicalHeaders strings.Builder
+	for _, header := range signed {
+		value := req.Header.Get(header)
+		if header == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(header + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+s.signature(amzDate, scope, canonicalRequest))
+}
+
+// signature signs a canonical request made at amzDate within scope
+func (s *S3) signature(amzDate, scope, canonicalRequest string) string {
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), scope[:8])
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// canonicalQuery encodes query sorted by key, as Signature Version 4 signs
+// it
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes every byte of s but the unreserved characters,
+// and slashes unless encodeSlash, as Signature Version 4 does
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3ErrorCode reads the code of an S3 error response, such as AccessDenied
+func s3ErrorCode(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 4096))
+	start := bytes.Index(data, []byte("<Code>"))
+	end := bytes.Index(data, []byte("</Code>"))
+	if start < 0 || end < start {
+		return ""
+	}
+	return string(data[start+len("<Code>") : end])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
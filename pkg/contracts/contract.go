@@ -0,0 +1,92 @@
+// Package contracts holds the consumer-driven contracts between InsuranceStack
+// services and the helpers both sides use to check them.
+//
+// A contract is owned by the consumer and lists the interactions it relies
+// on: the request it sends and the parts of the response it reads. Consumers
+// run their clients against NewMockProvider, which only answers the recorded
+// interactions; providers replay the same interactions against their real
+// router with VerifyProvider. Because both test suites read the contract
+// files in this package, a payload change that breaks either side fails CI
+// for both services.
+package contracts
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed pacts/*.json
+var pacts embed.FS
+
+// Contract is the set of interactions a consumer expects from a provider
+type Contract struct {
+	Consumer     string        `json:"consumer"`
+	Provider     string        `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request/response pair the consumer depends on
+type Interaction struct {
+	Description   string   `json:"description"`
+	ProviderState string   `json:"providerState,omitempty"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+// Request is the request the consumer sends
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Response is the response the consumer expects. Body is an example: the
+// provider must return every field in it with the same JSON type, and may
+// return additional fields. Fields listed in Exact must match the example
+// value as well as its type.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	Exact   []string          `json:"exact,omitempty"`
+}
+
+// Load returns the contract between a consumer and a provider
+func Load(consumer, provider string) (*Contract, error) {
+	name := fmt.Sprintf("pacts/%s-%s.json", consumer, provider)
+	data, err := pacts.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("no contract between %s and %s", consumer, provider)
+	}
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if contract.Consumer != consumer || contract.Provider != provider {
+		return nil, fmt.Errorf("%s declares %s -> %s", name, contract.Consumer, contract.Provider)
+	}
+	return &contract, nil
+}
+
+// MustLoad is like Load but panics on error, for use in test setup
+func MustLoad(consumer, provider string) *Contract {
+	contract, err := Load(consumer, provider)
+	if err != nil {
+		panic(err)
+	}
+	return contract
+}
+
+// Interaction returns the interaction with the given description
+func (c *Contract) Interaction(description string) (Interaction, bool) {
+	for _, interaction := range c.Interactions {
+		if interaction.Description == description {
+			return interaction, true
+		}
+	}
+	return Interaction{}, false
+}
@@ -0,0 +1,129 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLoadKnownContracts(t *testing.T) {
+	tests := []struct {
+		consumer string
+		provider string
+	}{
+		{"claims-service", "policy-service"},
+		{"claims-service", "payments-service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			contract, err := Load(tt.consumer, tt.provider)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if len(contract.Interactions) == 0 {
+				t.Error("Contract should declare interactions")
+			}
+			for _, interaction := range contract.Interactions {
+				if interaction.Description == "" || interaction.Request.Method == "" || interaction.Response.Status == 0 {
+					t.Errorf("Incomplete interaction: %+v", interaction)
+				}
+			}
+		})
+	}
+
+	if _, err := Load("claims-service", "unknown-service"); err == nil {
+		t.Error("Expected error for unknown contract")
+	}
+}
+
+func TestMatchBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		expected     string
+		actual       string
+		exact        []string
+		wantProblems int
+	}{
+		{"identical", `{"id":"pol-001","amount":100}`, `{"id":"pol-001","amount":100}`, nil, 0},
+		{"extra fields ignored", `{"id":"pol-001"}`, `{"id":"pol-001","premium":1250}`, nil, 0},
+		{"type match only", `{"id":"pol-001","amount":100}`, `{"id":"pol-777","amount":9}`, nil, 0},
+		{"missing field", `{"id":"pol-001","status":"active"}`, `{"id":"pol-001"}`, nil, 1},
+		{"wrong type", `{"amount":100}`, `{"amount":"100"}`, nil, 1},
+		{"exact mismatch", `{"type":"payout"}`, `{"type":"premium"}`, []string{"type"}, 1},
+		{"nested object", `{"address":{"city":"Paris"}}`, `{"address":{"zip":"75008"}}`, nil, 1},
+		{"array elements", `[{"id":"a","type":"auto"}]`, `[{"id":"b","type":"auto"},{"id":"c","type":"home"}]`, []string{"[].type"}, 1},
+		{"empty array", `[{"id":"a"}]`, `[]`, nil, 1},
+		{"no expected body", ``, `anything`, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := MatchBody(json.RawMessage(tt.expected), json.RawMessage(tt.actual), tt.exact)
+			if len(problems) != tt.wantProblems {
+				t.Errorf("Problem count mismatch: got %d, want %d (%v)", len(problems), tt.wantProblems, problems)
+			}
+		})
+	}
+}
+
+// TestMockProviderSatisfiesOwnContract checks the two halves agree: a
+// provider that replays the mock's responses passes verification
+func TestMockProviderSatisfiesOwnContract(t *testing.T) {
+	contract := MustLoad("claims-service", "payments-service")
+	mock := NewMockProvider(t, contract)
+
+	proxy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequest(r.Method, mock.URL+r.URL.RequestURI(), r.Body)
+		req.Header = r.Header
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Mock request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	})
+
+	VerifyProvider(t, proxy, contract, StateHandlers{
+		"payout pay-002 exists for claim claim-001": func() error { return nil },
+		"payment pay-999 does not exist":            func() error { return nil },
+	})
+}
+
+func TestRequestMatches(t *testing.T) {
+	want := Request{
+		Method:  "POST",
+		Path:    "/payouts",
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    json.RawMessage(`{"claimId":"claim-001","amount":4500}`),
+	}
+
+	tests := []struct {
+		name string
+		body string
+		ct   string
+		want bool
+	}{
+		{"same body different key order", `{"amount":4500,"claimId":"claim-001"}`, "application/json", true},
+		{"different value", `{"claimId":"claim-001","amount":10}`, "application/json", false},
+		{"missing header", `{"claimId":"claim-001","amount":4500}`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest("POST", "http://example/payouts", strings.NewReader(tt.body))
+			if tt.ct != "" {
+				r.Header.Set("Content-Type", tt.ct)
+			}
+			if got := requestMatches(want, r, []byte(tt.body)); got != tt.want {
+				t.Errorf("requestMatches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
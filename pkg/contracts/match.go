@@ -0,0 +1,115 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// MatchBody compares an actual JSON body against the example from a contract
+// and returns one message per mismatch. Objects match if every expected key
+// is present with a matching value; extra keys are ignored. Arrays match if
+// every element matches the first expected element. Scalars match on JSON
+// type unless their path (for example "status" or "items[].type") is listed
+// in exact.
+func MatchBody(expected, actual json.RawMessage, exact []string) []string {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	var want, got interface{}
+	if err := json.Unmarshal(expected, &want); err != nil {
+		return []string{fmt.Sprintf("contract body is not valid JSON: %v", err)}
+	}
+	if err := json.Unmarshal(actual, &got); err != nil {
+		return []string{fmt.Sprintf("response body is not valid JSON: %v", err)}
+	}
+
+	exactPaths := make(map[string]bool, len(exact))
+	for _, path := range exact {
+		exactPaths[path] = true
+	}
+
+	var problems []string
+	matchValue("", want, got, exactPaths, &problems)
+	return problems
+}
+
+func matchValue(path string, want, got interface{}, exact map[string]bool, problems *[]string) {
+	if jsonType(want) != jsonType(got) {
+		*problems = append(*problems, fmt.Sprintf("%s: expected %s, got %s", displayPath(path), jsonType(want), jsonType(got)))
+		return
+	}
+
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g := got.(map[string]interface{})
+		keys := make([]string, 0, len(w))
+		for key := range w {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := joinPath(path, key)
+			value, ok := g[key]
+			if !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: missing", child))
+				continue
+			}
+			matchValue(child, w[key], value, exact, problems)
+		}
+
+	case []interface{}:
+		g := got.([]interface{})
+		if len(w) == 0 {
+			return
+		}
+		if len(g) == 0 {
+			*problems = append(*problems, fmt.Sprintf("%s: expected at least one element", displayPath(path)))
+			return
+		}
+		// Elements share the shape of the first example element, so exact
+		// paths address every element as name[]
+		for _, value := range g {
+			matchValue(path+"[]", w[0], value, exact, problems)
+		}
+
+	default:
+		if exact[path] && want != got {
+			*problems = append(*problems, fmt.Sprintf("%s: expected %v, got %v", displayPath(path), want, got))
+		}
+	}
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
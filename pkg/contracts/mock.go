@@ -0,0 +1,122 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// MockProvider is a stub provider for consumer tests. It answers only the
+// requests recorded in the contract and fails the test if the consumer sends
+// anything else or never exercises one of the interactions.
+type MockProvider struct {
+	URL string
+
+	t         *testing.T
+	contract  *Contract
+	server    *httptest.Server
+	exercised map[string]bool
+	mu        sync.Mutex
+}
+
+// NewMockProvider starts a mock provider for the contract. The server is
+// closed and unexercised interactions are reported when the test ends.
+func NewMockProvider(t *testing.T, contract *Contract) *MockProvider {
+	t.Helper()
+
+	m := &MockProvider{
+		t:         t,
+		contract:  contract,
+		exercised: make(map[string]bool),
+	}
+	m.server = httptest.NewServer(http.HandlerFunc(m.serve))
+	m.URL = m.server.URL
+
+	t.Cleanup(func() {
+		m.server.Close()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, interaction := range contract.Interactions {
+			if !m.exercised[interaction.Description] {
+				t.Errorf("%s never exercised contract interaction %q with %s",
+					contract.Consumer, interaction.Description, contract.Provider)
+			}
+		}
+	})
+
+	return m
+}
+
+func (m *MockProvider) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		m.t.Errorf("mock %s: failed to read request body: %v", m.contract.Provider, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	for _, interaction := range m.contract.Interactions {
+		if !requestMatches(interaction.Request, r, body) {
+			continue
+		}
+
+		m.mu.Lock()
+		m.exercised[interaction.Description] = true
+		m.mu.Unlock()
+
+		for key, value := range interaction.Response.Headers {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(interaction.Response.Status)
+		if len(interaction.Response.Body) > 0 {
+			w.Write(interaction.Response.Body)
+		}
+		return
+	}
+
+	m.t.Errorf("mock %s: no contract interaction matches %s %s %s",
+		m.contract.Provider, r.Method, r.URL.RequestURI(), bytes.TrimSpace(body))
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// requestMatches reports whether a live request is the one described in the
+// contract. Recorded headers must be present; the query and body must be
+// semantically equal to the recorded ones.
+func requestMatches(want Request, r *http.Request, body []byte) bool {
+	if r.Method != want.Method || r.URL.Path != want.Path {
+		return false
+	}
+
+	wantQuery, err := url.ParseQuery(want.Query)
+	if err != nil || !reflect.DeepEqual(normalizeQuery(wantQuery), normalizeQuery(r.URL.Query())) {
+		return false
+	}
+
+	for key, value := range want.Headers {
+		if r.Header.Get(key) != value {
+			return false
+		}
+	}
+
+	if len(want.Body) == 0 {
+		return len(bytes.TrimSpace(body)) == 0
+	}
+	var wantBody, gotBody interface{}
+	if json.Unmarshal(want.Body, &wantBody) != nil || json.Unmarshal(body, &gotBody) != nil {
+		return false
+	}
+	return reflect.DeepEqual(wantBody, gotBody)
+}
+
+func normalizeQuery(values url.Values) url.Values {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
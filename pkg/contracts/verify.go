@@ -0,0 +1,67 @@
+package contracts
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// StateHandlers prepare the provider for an interaction, keyed by the
+// interaction's providerState
+type StateHandlers map[string]func() error
+
+// VerifyProvider replays every interaction in the contract against the
+// provider's real HTTP handler and checks the response satisfies what the
+// consumer expects. Each interaction runs as a subtest.
+func VerifyProvider(t *testing.T, handler http.Handler, contract *Contract, states StateHandlers) {
+	t.Helper()
+
+	for _, interaction := range contract.Interactions {
+		interaction := interaction
+		t.Run(interaction.Description, func(t *testing.T) {
+			if interaction.ProviderState != "" {
+				setup, ok := states[interaction.ProviderState]
+				if !ok {
+					t.Fatalf("%s has no handler for provider state %q", contract.Provider, interaction.ProviderState)
+				}
+				if err := setup(); err != nil {
+					t.Fatalf("Failed to set up provider state %q: %v", interaction.ProviderState, err)
+				}
+			}
+
+			target := interaction.Request.Path
+			if interaction.Request.Query != "" {
+				target += "?" + interaction.Request.Query
+			}
+			req := httptest.NewRequest(interaction.Request.Method, target, bytes.NewReader(interaction.Request.Body))
+			for key, value := range interaction.Request.Headers {
+				req.Header.Set(key, value)
+			}
+			if len(interaction.Request.Body) > 0 && req.Header.Get("Content-Type") == "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != interaction.Response.Status {
+				t.Fatalf("Status mismatch for %s %s: got %d, want %d (body: %s)",
+					interaction.Request.Method, target, rec.Code, interaction.Response.Status,
+					strings.TrimSpace(rec.Body.String()))
+			}
+
+			for key, value := range interaction.Response.Headers {
+				// Media type parameters such as charset are not part of the contract
+				if got := rec.Header().Get(key); !strings.HasPrefix(got, value) {
+					t.Errorf("Header %s mismatch: got %q, want %q", key, got, value)
+				}
+			}
+
+			for _, problem := range MatchBody(interaction.Response.Body, rec.Body.Bytes(), interaction.Response.Exact) {
+				t.Errorf("Response body breaks contract with %s: %s", contract.Consumer, problem)
+			}
+		})
+	}
+}
@@ -0,0 +1,431 @@
+// Package faults injects faults into a service's responses, so the retries
+// and circuit breakers of its callers can be tried out in a test stack.
+// Rules delay, fail or drop a share of the requests to a route. Injection
+// is off unless FEATURE_FAULT_INJECTION is on, and is refused in the
+// production environment whatever the flag says.
+package faults
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FlagKey is the feature flag reporting fault injection in /healthz
+const FlagKey = "system.faultInjection"
+
+// Path is where each service serves the fault injection endpoint
+const Path = "/admin/faults"
+
+// Header is set on responses a fault was injected into, naming the faults
+const Header = "X-Fault-Injected"
+
+// MaxLatency bounds the delay a rule may add to a request
+const MaxLatency = time.Minute
+
+// Faults a rule injects
+const (
+	FaultLatency = "latency"
+	FaultError   = "error"
+	FaultDrop    = "drop"
+)
+
+// Exempt are the paths never faulted: health checks, so orchestrators keep
+// the instance, metrics, and the fault injection endpoint itself
+var Exempt = []string{"/healthz", "/metrics", Path}
+
+// productionEnvironments are the environment names injection is refused in
+var productionEnvironments = []string{"production", "prod"}
+
+// Config is whether a service may inject faults
+type Config struct {
+	Enabled     bool   // FEATURE_FAULT_INJECTION
+	Environment string // CLOUDBEES_FM_ENVIRONMENT; injection is refused in production
+}
+
+// ConfigFromEnv reads FEATURE_FAULT_INJECTION and CLOUDBEES_FM_ENVIRONMENT.
+// An invalid FEATURE_FAULT_INJECTION is logged as a warning and left off.
+func ConfigFromEnv(logger *logrus.Logger) Config {
+	cfg := Config{Environment: os.Getenv("CLOUDBEES_FM_ENVIRONMENT")}
+	if v := os.Getenv("FEATURE_FAULT_INJECTION"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Enabled = enabled
+		} else {
+			logger.Warnf("Invalid FEATURE_FAULT_INJECTION '%s', defaulting to false", v)
+		}
+	}
+	return cfg
+}
+
+// Rule injects faults into a share of the requests to a route. Latency
+// delays the request before it is handled; Status answers it with an error
+// instead, and Drop closes the connection without a response. Latency can
+// be combined with either.
+type Rule struct {
+	// Route is a route template, such as /claims/{id}/payouts, where each
+	// {name} matches one path segment; empty matches every route
+	Route string `json:"route,omitempty"`
+	// Methods narrow the rule to these methods; empty matches every method
+	Methods []string `json:"methods,omitempty"`
+	// Percent is the share of the matching requests faulted, above 0 and
+	// at most 100
+	Percent float64 `json:"percent"`
+	Latency string  `json:"latency,omitempty"` // such as "2s", at most MaxLatency
+	Status  int     `json:"status,omitempty"`  // 400 to 599
+	Drop    bool    `json:"drop,omitempty"`
+
+	latency time.Duration
+}
+
+// check validates the rule and parses its latency
+func (r *Rule) check() error {
+	if r.Route != "" && !strings.HasPrefix(r.Route, "/") {
+		return fmt.Errorf("route %q must start with /", r.Route)
+	}
+	if r.Percent <= 0 || r.Percent > 100 {
+		return fmt.Errorf("percent must be above 0 and at most 100")
+	}
+	if r.Latency == "" && r.Status == 0 && !r.Drop {
+		return fmt.Errorf("needs latency, status or drop")
+	}
+	if r.Status != 0 && r.Drop {
+		return fmt.Errorf("cannot both answer with status and drop")
+	}
+	if r.Status != 0 && (r.Status < 400 || r.Status > 599) {
+		return fmt.Errorf("status %d must be from 400 to 599", r.Status)
+	}
+	r.latency = 0
+	if r.Latency != "" {
+		latency, err := time.ParseDuration(r.Latency)
+		if err != nil {
+			return fmt.Errorf("latency: %w", err)
+		}
+		if latency <= 0 || latency > MaxLatency {
+			return fmt.Errorf("latency must be above 0 and at most %s", MaxLatency)
+		}
+		r.latency = latency
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a request
+func (r *Rule) matches(method, path string) bool {
+	if len(r.Methods) > 0 {
+		found := false
+		for _, m := range r.Methods {
+			if strings.EqualFold(m, method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return r.Route == "" || matchRoute(r.Route, path)
+}
+
+// matchRoute reports whether path fits the route template, segment by
+// segment
+func matchRoute(route, path string) bool {
+	want := strings.Split(strings.Trim(route, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i, segment := range want {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if got[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// faults names the faults the rule injects, in the order they happen
+func (r *Rule) faults() []string {
+	var faults []string
+	if r.latency > 0 {
+		faults = append(faults, FaultLatency)
+	}
+	if r.Status != 0 {
+		faults = append(faults, FaultError)
+	}
+	if r.Drop {
+		faults = append(faults, FaultDrop)
+	}
+	return faults
+}
+
+// Status is the current rules, as served by the fault injection endpoint
+type Status struct {
+	Enabled   bool       `json:"enabled"`
+	Rules     []Rule     `json:"rules"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"` // when the rules were last set
+}
+
+// Update is the body of PUT /admin/faults; empty rules stop injecting
+type Update struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Injector injects the faults of its rules into a service's responses. It
+// is safe for concurrent use; the zero value is not, use New.
+type Injector struct {
+	enabled bool
+	logger  *logrus.Logger
+	roll    func() float64 // from 0 up to 100
+
+	mu        sync.RWMutex
+	rules     []Rule
+	updatedAt time.Time
+	injected  map[injectedKey]int64
+}
+
+// injectedKey counts injected faults by the rule's route and the fault
+type injectedKey struct {
+	route, fault string
+}
+
+// New creates the fault injector of a service, without rules. It is
+// disabled unless cfg enables it outside production.
+func New(cfg Config, logger *logrus.Logger) *Injector {
+	i := &Injector{
+		logger:   logger,
+		roll:     func() float64 { return rand.Float64() * 100 },
+		injected: make(map[injectedKey]int64),
+	}
+	switch {
+	case cfg.Enabled && production(cfg.Environment):
+		logger.Errorf("Fault injection refused in the %s environment", cfg.Environment)
+	case cfg.Enabled:
+		i.enabled = true
+		logger.Warnf("Fault injection enabled, rules set at %s will fail requests", Path)
+	}
+	return i
+}
+
+// production reports whether env names a production environment
+func production(env string) bool {
+	for _, name := range productionEnvironments {
+		if strings.EqualFold(strings.TrimSpace(env), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether the service may inject faults
+func (i *Injector) Enabled() bool {
+	return i.enabled
+}
+
+// Status returns the current rules
+func (i *Injector) Status() Status {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	status := Status{Enabled: i.enabled, Rules: append([]Rule{}, i.rules...)}
+	if !i.updatedAt.IsZero() {
+		updatedAt := i.updatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	return status
+}
+
+// SetRules replaces the rules when every rule checks; nil stops
+// injecting. It fails when the injector is disabled.
+func (i *Injector) SetRules(rules []Rule) error {
+	if !i.enabled {
+		return fmt.Errorf("fault injection is disabled")
+	}
+	checked := make([]Rule, len(rules))
+	for n, rule := range rules {
+		if err := rule.check(); err != nil {
+			return fmt.Errorf("rule %d: %w", n+1, err)
+		}
+		checked[n] = rule
+	}
+
+	i.mu.Lock()
+	i.rules = checked
+	i.updatedAt = time.Now()
+	i.mu.Unlock()
+	i.logger.WithField("rules", len(checked)).Warn("Fault injection rules updated")
+	return nil
+}
+
+// EnabledFlags reports FlagKey while the service has rules to inject, so
+// the faults show in the health check with the service's other flags
+func (i *Injector) EnabledFlags() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	if len(i.rules) > 0 {
+		return []string{FlagKey}
+	}
+	return nil
+}
+
+// pick returns the first rule matching the request when the request is in
+// its share, and whether there was one
+func (i *Injector) pick(r *http.Request) (Rule, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, rule := range i.rules {
+		if rule.matches(r.Method, r.URL.Path) {
+			return rule, i.roll() < rule.Percent
+		}
+	}
+	return Rule{}, false
+}
+
+// count records the faults injected by a rule
+func (i *Injector) count(rule Rule, faults []string) {
+	route := rule.Route
+	if route == "" {
+		route = "*"
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, fault := range faults {
+		i.injected[injectedKey{route: route, fault: fault}]++
+	}
+}
+
+// Middleware injects the faults of the first rule matching each request,
+// into the rule's share of them. Exempt paths are never faulted. A dropped
+// request panics with http.ErrAbortHandler, so the server closes the
+// connection; the service's recovery middleware lets it through.
+func (i *Injector) Middleware(next http.Handler) http.Handler {
+	if !i.enabled {
+		return next
+	}
+	exempt := make(map[string]bool, len(Exempt))
+	for _, path := range Exempt {
+		exempt[path] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if exempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rule, ok := i.pick(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		faults := rule.faults()
+		i.count(rule, faults)
+		i.logger.WithFields(logrus.Fields{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"route":  rule.Route,
+			"faults": strings.Join(faults, ","),
+		}).Debug("Injecting fault")
+
+		if rule.latency > 0 {
+			timer := time.NewTimer(rule.latency)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				// The caller gave up waiting
+				timer.Stop()
+				return
+			}
+		}
+		if rule.Drop {
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set(Header, strings.Join(faults, ","))
+		if rule.Status != 0 {
+			respondError(w, rule.Status, "Injected fault")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Handler serves GET /admin/faults with the current rules, and PUT to
+// replace them. While injection is disabled it answers 404. Guard it with
+// the service's admin role check.
+func (i *Injector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !i.enabled {
+			respondError(w, http.StatusNotFound, "Fault injection is disabled")
+			return
+		}
+		if r.Method == http.MethodPut {
+			var update Update
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			if err := i.SetRules(update.Rules); err != nil {
+				respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(i.Status())
+	})
+}
+
+// WritePrometheus writes the faults injected as faults_injected_total in
+// the Prometheus text exposition format. It writes nothing while injection
+// is disabled.
+func (i *Injector) WritePrometheus(w io.Writer) {
+	if !i.enabled {
+		return
+	}
+	i.mu.RLock()
+	keys := make([]injectedKey, 0, len(i.injected))
+	counts := make(map[injectedKey]int64, len(i.injected))
+	for key, count := range i.injected {
+		keys = append(keys, key)
+		counts[key] = count
+	}
+	i.mu.RUnlock()
+	sort.Slice(keys, func(a, b int) bool {
+		if keys[a].route != keys[b].route {
+			return keys[a].route < keys[b].route
+		}
+		return keys[a].fault < keys[b].fault
+	})
+
+	fmt.Fprint(w, "# HELP faults_injected_total Faults injected into requests, by rule route and fault.\n")
+	fmt.Fprint(w, "# TYPE faults_injected_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(w, "faults_injected_total{fault=\"%s\",route=\"%s\"} %d\n", escapeLabel(key.fault), escapeLabel(key.route), counts[key])
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,258 @@
+package faults
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestInjector(cfg Config) *Injector {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return New(cfg, logger)
+}
+
+func noContent(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func serve(handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestInjectionIsRefusedUnlessEnabledOutsideProduction(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{"off by default", Config{}, false},
+		{"enabled", Config{Enabled: true, Environment: "staging"}, true},
+		{"enabled without an environment", Config{Enabled: true}, true},
+		{"production", Config{Enabled: true, Environment: "production"}, false},
+		{"prod", Config{Enabled: true, Environment: " Prod "}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := newTestInjector(tt.cfg)
+			if injector.Enabled() != tt.want {
+				t.Fatalf("Expected enabled %v, got %v", tt.want, injector.Enabled())
+			}
+			err := injector.SetRules([]Rule{{Percent: 100, Status: http.StatusServiceUnavailable}})
+			if (err == nil) != tt.want {
+				t.Fatalf("Expected SetRules to succeed only when enabled, got %v", err)
+			}
+			if tt.want {
+				return
+			}
+			if rec := serve(injector.Middleware(http.HandlerFunc(noContent)), http.MethodGet, "/claims"); rec.Code != http.StatusNoContent {
+				t.Errorf("Expected requests through a disabled injector, got %d", rec.Code)
+			}
+			if rec := serve(injector.Handler(), http.MethodGet, Path); rec.Code != http.StatusNotFound {
+				t.Errorf("Expected 404 from a disabled endpoint, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestSetRulesRefusesInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"no fault", Rule{Percent: 50}, "needs latency, status or drop"},
+		{"no percent", Rule{Status: 503}, "percent must be above 0"},
+		{"over 100 percent", Rule{Percent: 120, Status: 503}, "percent must be above 0"},
+		{"status and drop", Rule{Percent: 50, Status: 503, Drop: true}, "cannot both"},
+		{"success status", Rule{Percent: 50, Status: 200}, "must be from 400 to 599"},
+		{"bad latency", Rule{Percent: 50, Latency: "soon"}, "latency"},
+		{"too much latency", Rule{Percent: 50, Latency: "2m"}, "at most 1m0s"},
+		{"relative route", Rule{Route: "claims", Percent: 50, Status: 503}, "must start with /"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			injector := newTestInjector(Config{Enabled: true})
+			err := injector.SetRules([]Rule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "rule 1: ") {
+				t.Errorf("Expected an error containing %q, got %v", tt.want, err)
+			}
+			if len(injector.Status().Rules) != 0 {
+				t.Errorf("Expected no rules after a refused update, got %+v", injector.Status().Rules)
+			}
+		})
+	}
+}
+
+func TestMiddlewareInjectsErrorsByRouteAndMethod(t *testing.T) {
+	injector := newTestInjector(Config{Enabled: true})
+	if err := injector.SetRules([]Rule{
+		{Route: "/payouts", Methods: []string{"post"}, Percent: 100, Status: http.StatusServiceUnavailable},
+		{Route: "/payments/{id}", Percent: 100, Status: http.StatusTooManyRequests},
+		{Percent: 100, Status: http.StatusInternalServerError},
+	}); err != nil {
+		t.Fatalf("SetRules failed: %v", err)
+	}
+	handler := injector.Middleware(http.HandlerFunc(noContent))
+
+	rec := serve(handler, http.MethodPost, "/payouts")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get(Header) != FaultError {
+		t.Fatalf("Expected an injected 503, got %d with %q", rec.Code, rec.Header().Get(Header))
+	}
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body["error"] != "Injected fault" {
+		t.Errorf("Unexpected error body %v, %v", body, err)
+	}
+
+	cases := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/payments/pay-001", http.StatusTooManyRequests},
+		{http.MethodGet, "/payments/pay-001/disputes", http.StatusInternalServerError},
+		{http.MethodGet, "/payouts", http.StatusInternalServerError}, // the first rule is for POST only
+		{http.MethodGet, "/healthz", http.StatusNoContent},
+		{http.MethodGet, "/metrics", http.StatusNoContent},
+		{http.MethodPut, Path, http.StatusNoContent},
+	}
+	for _, c := range cases {
+		if rec := serve(handler, c.method, c.path); rec.Code != c.want {
+			t.Errorf("%s %s: expected %d, got %d", c.method, c.path, c.want, rec.Code)
+		}
+	}
+
+	if flags := injector.EnabledFlags(); len(flags) != 1 || flags[0] != FlagKey {
+		t.Errorf("Expected %s while rules are set, got %v", FlagKey, flags)
+	}
+	if err := injector.SetRules(nil); err != nil {
+		t.Fatalf("Clearing rules failed: %v", err)
+	}
+	if rec := serve(handler, http.MethodPost, "/payouts"); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected requests through once the rules are cleared, got %d", rec.Code)
+	}
+	if flags := injector.EnabledFlags(); len(flags) != 0 {
+		t.Errorf("Expected no flags without rules, got %v", flags)
+	}
+}
+
+func TestMiddlewareFaultsItsShareOfRequests(t *testing.T) {
+	injector := newTestInjector(Config{Enabled: true})
+	if err := injector.SetRules([]Rule{{Route: "/claims", Percent: 25, Status: http.StatusBadGateway}}); err != nil {
+		t.Fatalf("SetRules failed: %v", err)
+	}
+	rolls := []float64{10, 30, 24.9, 99}
+	injector.roll = func() float64 {
+		roll := rolls[0]
+		rolls = rolls[1:]
+		return roll
+	}
+	handler := injector.Middleware(http.HandlerFunc(noContent))
+
+	var got []int
+	for range []int{1, 2, 3, 4} {
+		got = append(got, serve(handler, http.MethodGet, "/claims").Code)
+	}
+	want := []int{http.StatusBadGateway, http.StatusNoContent, http.StatusBadGateway, http.StatusNoContent}
+	for n := range want {
+		if got[n] != want[n] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+
+	var metrics bytes.Buffer
+	injector.WritePrometheus(&metrics)
+	if !strings.Contains(metrics.String(), `faults_injected_total{fault="error",route="/claims"} 2`) {
+		t.Errorf("Expected 2 injected errors in metrics, got:\n%s", metrics.String())
+	}
+}
+
+func TestMiddlewareDelaysAndDropsRequests(t *testing.T) {
+	injector := newTestInjector(Config{Enabled: true})
+	if err := injector.SetRules([]Rule{
+		{Route: "/slow", Percent: 100, Latency: "50ms"},
+		{Route: "/dropped", Percent: 100, Drop: true},
+	}); err != nil {
+		t.Fatalf("SetRules failed: %v", err)
+	}
+	server := httptest.NewServer(injector.Middleware(http.HandlerFunc(noContent)))
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.Get(server.URL + "/slow")
+	if err != nil {
+		t.Fatalf("Delayed request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get(Header) != FaultLatency || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("Expected a handled request after 50ms, got %d with %q after %s", resp.StatusCode, resp.Header.Get(Header), time.Since(start))
+	}
+
+	if resp, err := http.Get(server.URL + "/dropped"); err == nil {
+		resp.Body.Close()
+		t.Errorf("Expected the connection dropped, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandlerGetsAndReplacesRules(t *testing.T) {
+	injector := newTestInjector(Config{Enabled: true})
+	handler := injector.Handler()
+
+	rec := serve(handler, http.MethodGet, Path)
+	var status Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET failed: %d, %v", rec.Code, err)
+	}
+	if !status.Enabled || len(status.Rules) != 0 || status.UpdatedAt != nil {
+		t.Errorf("Expected an enabled injector without rules, got %+v", status)
+	}
+
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, Path, strings.NewReader(body)))
+		return rec
+	}
+	rec = put(`{"rules": [{"route": "/payouts", "percent": 50, "latency": "2s", "status": 503}]}`)
+	status = Status{}
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("PUT failed: %d, %v", rec.Code, err)
+	}
+	if len(status.Rules) != 1 || status.Rules[0].Latency != "2s" || status.UpdatedAt == nil {
+		t.Errorf("Expected the rule back, got %+v", status)
+	}
+
+	for _, body := range []string{`{`, `{"rules": [{"percent": 50}]}`} {
+		if rec := put(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+	if len(injector.Status().Rules) != 1 {
+		t.Errorf("Expected a refused update to keep the rules, got %+v", injector.Status().Rules)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	t.Setenv("FEATURE_FAULT_INJECTION", "true")
+	t.Setenv("CLOUDBEES_FM_ENVIRONMENT", "staging")
+	if cfg := ConfigFromEnv(logger); !cfg.Enabled || cfg.Environment != "staging" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+
+	t.Setenv("FEATURE_FAULT_INJECTION", "sometimes")
+	if cfg := ConfigFromEnv(logger); cfg.Enabled {
+		t.Errorf("Expected an invalid flag to leave injection off, got %+v", cfg)
+	}
+}
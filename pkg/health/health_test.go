@@ -0,0 +1,70 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stubFlags is a FlagSource returning fixed keys
+type stubFlags []string
+
+func (s stubFlags) EnabledFlags() []string { return s }
+
+func TestHandler(t *testing.T) {
+	Version, Commit, BuildTime = "1.4.0", "abc1234", "2026-10-01T09:00:00Z"
+	defer func() { Version, Commit, BuildTime = "dev", "unknown", "" }()
+
+	handler := NewHandler("claims-service", stubFlags{"claims.autoApproval", "api.maskAmounts"})
+	started := handler.started
+	handler.now = func() time.Time { return started.Add(90*time.Minute + 1500*time.Millisecond) }
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("Got %d with %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var got Response
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got.Status != "ok" || got.Service != "claims-service" || got.Version != "1.4.0" || got.Commit != "abc1234" || got.BuildTime != "2026-10-01T09:00:00Z" {
+		t.Errorf("Unexpected build information: %+v", got)
+	}
+	if got.Uptime != "1h30m1s" || got.UptimeSeconds != 5401 || !got.StartedAt.Equal(started) {
+		t.Errorf("Unexpected uptime %q (%ds) since %s", got.Uptime, got.UptimeSeconds, got.StartedAt)
+	}
+	if want := []string{"api.maskAmounts", "claims.autoApproval"}; !reflect.DeepEqual(got.Features, want) {
+		t.Errorf("Features: got %v, want %v", got.Features, want)
+	}
+}
+
+func TestHandlerWithoutFlags(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler("search-service", nil).ServeHTTP(rec, httptest.NewRequest("GET", "/healthz", nil))
+
+	var got map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if features, ok := got["features"].([]interface{}); !ok || len(features) != 0 {
+		t.Errorf("Expected an empty features list, got %v", got["features"])
+	}
+	if _, ok := got["buildTime"]; ok {
+		t.Errorf("Expected no buildTime for a local build, got %v", got["buildTime"])
+	}
+	if got["version"] != "dev" || got["commit"] != "unknown" {
+		t.Errorf("Unexpected defaults: %v", got)
+	}
+}
+
+func TestCombineFlagSources(t *testing.T) {
+	handler := NewHandler("search-service", Combine(nil, stubFlags{"system.maintenanceMode"}, stubFlags{"api.maskAmounts"}))
+	if got, want := handler.Check().Features, []string{"api.maskAmounts", "system.maintenanceMode"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Features: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/CB-InsuranceStack/InsuranceStack/pkg/hooks
+
+go 1.21
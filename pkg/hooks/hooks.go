@@ -0,0 +1,90 @@
+// Package hooks lets code observe the writes a service's repository makes.
+// A repository embeds Hooks and announces each record it creates, updates
+// or deletes, so event publishers, caches and indexes can follow changes
+// without every caller of the repository remembering to tell them.
+package hooks
+
+import "sync"
+
+// Operations a change can be
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// AnyCollection registers a hook for changes to every collection
+const AnyCollection = ""
+
+// Change is one record a repository wrote
+type Change struct {
+	Op         string
+	Collection string      // e.g. "claims", as used for the persist journal
+	Key        string      // ID of the record
+	Value      interface{} // the record as written; nil for deletes
+}
+
+// Func is called with a change after it is stored. Value is only valid
+// while the hook runs: copy it to keep it, and do not modify it.
+type Func func(Change)
+
+type hook struct {
+	op         string
+	collection string
+	fn         Func
+}
+
+// Hooks is the set of callbacks observing a repository. The zero value has
+// none and is ready to use. Hooks may be registered at any time, but are
+// usually registered while the service is wired up.
+type Hooks struct {
+	mu    sync.RWMutex
+	hooks []hook
+}
+
+// OnCreate registers fn to run after a record of collection is created
+func (h *Hooks) OnCreate(collection string, fn Func) {
+	h.register(OpCreate, collection, fn)
+}
+
+// OnUpdate registers fn to run after a record of collection is updated
+func (h *Hooks) OnUpdate(collection string, fn Func) {
+	h.register(OpUpdate, collection, fn)
+}
+
+// OnDelete registers fn to run after a record of collection is deleted
+func (h *Hooks) OnDelete(collection string, fn Func) {
+	h.register(OpDelete, collection, fn)
+}
+
+func (h *Hooks) register(op, collection string, fn Func) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, hook{op: op, collection: collection, fn: fn})
+}
+
+// Notify runs the hooks registered for each change, in the order the
+// changes are given and then the order the hooks were registered. The
+// repository calls it once a change is stored and its own lock is released,
+// so a hook may read from or write to the repository.
+func (h *Hooks) Notify(changes ...Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	h.mu.RLock()
+	registered := h.hooks
+	h.mu.RUnlock()
+
+	for _, change := range changes {
+		for _, hk := range registered {
+			if hk.op != change.Op {
+				continue
+			}
+			if hk.collection != AnyCollection && hk.collection != change.Collection {
+				continue
+			}
+			hk.fn(change)
+		}
+	}
+}
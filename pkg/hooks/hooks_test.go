@@ -0,0 +1,56 @@
+package hooks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNotifyRunsMatchingHooksInOrder(t *testing.T) {
+	var h Hooks
+	var calls []string
+	record := func(name string) Func {
+		return func(c Change) {
+			calls = append(calls, name+":"+c.Op+":"+c.Collection+":"+c.Key)
+		}
+	}
+	h.OnCreate("claims", record("claims-created"))
+	h.OnUpdate("claims", record("claims-updated"))
+	h.OnCreate(AnyCollection, record("any-created"))
+	h.OnDelete("households", record("households-deleted"))
+
+	h.Notify(
+		Change{Op: OpCreate, Collection: "claims", Key: "claim-001"},
+		Change{Op: OpUpdate, Collection: "claims", Key: "claim-001"},
+		Change{Op: OpCreate, Collection: "comments", Key: "comment-001"},
+		Change{Op: OpDelete, Collection: "claims", Key: "claim-002"},
+		Change{Op: OpDelete, Collection: "households", Key: "hh-001"},
+	)
+
+	want := []string{
+		"claims-created:create:claims:claim-001",
+		"any-created:create:claims:claim-001",
+		"claims-updated:update:claims:claim-001",
+		"any-created:create:comments:comment-001",
+		"households-deleted:delete:households:hh-001",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Hooks ran as %v, want %v", calls, want)
+	}
+}
+
+func TestHooksMayRegisterWhileNotifying(t *testing.T) {
+	var h Hooks
+	late := 0
+	h.OnCreate("claims", func(Change) {
+		h.OnCreate("claims", func(Change) { late++ })
+	})
+
+	h.Notify(Change{Op: OpCreate, Collection: "claims", Key: "claim-001"})
+	if late != 0 {
+		t.Errorf("Expected a hook registered during a change to wait for the next one, ran %d times", late)
+	}
+	h.Notify(Change{Op: OpCreate, Collection: "claims", Key: "claim-002"})
+	if late != 1 {
+		t.Errorf("Expected the late hook to run once, ran %d times", late)
+	}
+}
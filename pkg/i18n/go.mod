@@ -0,0 +1,3 @@
+module github.com/CB-InsuranceStack/InsuranceStack/pkg/i18n
+
+go 1.21
@@ -0,0 +1,268 @@
+// Package i18n translates the services' user-facing strings. Error
+// messages are written in English throughout the code base and compared by
+// their text, so the catalogs are keyed by the English message: a response
+// is translated on its way out, after the handler has written it, and the
+// code that builds and checks messages never changes.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language messages are written in, used when a
+// request asks for none of the supported languages
+const DefaultLanguage = "en"
+
+// Languages are the languages with a catalog
+var Languages = []string{"en", "fr", "de", "es"}
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+// placeholder is a {name} or {name|modifier} in a catalog message
+var placeholder = regexp.MustCompile(`\{(\w+)(?:\|(\w+))?\}`)
+
+// Catalog holds the messages and labels of every supported language
+type Catalog struct {
+	languages map[string]*language
+}
+
+// language is one catalog file
+type language struct {
+	messages map[string]string // English message to translation
+	patterns []pattern         // messages with placeholders, most specific first
+	labels   map[string]string // enum value to label
+}
+
+// pattern is a catalog message with placeholders, such as
+// "{field} is required"
+type pattern struct {
+	source      string
+	match       *regexp.Regexp
+	names       []string
+	translation string
+}
+
+// catalogFile is the layout of catalogs/<language>.json
+type catalogFile struct {
+	Messages map[string]string `json:"messages"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// defaultCatalog is built once from the embedded catalogs
+var defaultCatalog = mustLoad()
+
+// Default returns the catalog built from the embedded catalog files
+func Default() *Catalog {
+	return defaultCatalog
+}
+
+func mustLoad() *Catalog {
+	c, err := load()
+	if err != nil {
+		panic(fmt.Sprintf("invalid i18n catalogs: %v", err))
+	}
+	return c
+}
+
+// load reads and checks the embedded catalogs
+func load() (*Catalog, error) {
+	c := &Catalog{languages: make(map[string]*language, len(Languages))}
+	for _, lang := range Languages {
+		name := path.Join("catalogs", lang+".json")
+		data, err := catalogFiles.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		var file catalogFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		l := &language{messages: make(map[string]string), labels: file.Labels}
+		for source, translation := range file.Messages {
+			if !placeholder.MatchString(source) {
+				l.messages[source] = translation
+				continue
+			}
+			p, err := compile(source, translation)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			l.patterns = append(l.patterns, p)
+		}
+		// The pattern with the most fixed text wins, so "step {n} is
+		// invalid: {problems}" is tried before "{field} is required"
+		sort.Slice(l.patterns, func(i, j int) bool {
+			li, lj := literalLength(l.patterns[i].source), literalLength(l.patterns[j].source)
+			if li != lj {
+				return li > lj
+			}
+			return l.patterns[i].source < l.patterns[j].source
+		})
+		c.languages[lang] = l
+	}
+
+	for value := range c.languages[DefaultLanguage].labels {
+		for _, lang := range Languages {
+			if _, ok := c.languages[lang].labels[value]; !ok {
+				return nil, fmt.Errorf("catalogs/%s.json has no label for %s", lang, value)
+			}
+		}
+	}
+	return c, nil
+}
+
+// compile turns a catalog message with placeholders into a pattern that
+// matches the messages it stands for
+func compile(source, translation string) (pattern, error) {
+	p := pattern{source: source, translation: translation}
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range placeholder.FindAllStringSubmatchIndex(source, -1) {
+		expr.WriteString(regexp.QuoteMeta(source[last:loc[0]]))
+		expr.WriteString("(.+?)")
+		p.names = append(p.names, source[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(source[last:]))
+	expr.WriteString("$")
+	p.match = regexp.MustCompile(expr.String())
+
+	for _, m := range placeholder.FindAllStringSubmatch(translation, -1) {
+		if !contains(p.names, m[1]) {
+			return p, fmt.Errorf("translation of %q uses {%s}, which the message does not have", source, m[1])
+		}
+		if m[2] != "" && m[2] != "label" && m[2] != "list" {
+			return p, fmt.Errorf("translation of %q uses unknown modifier %s (must be label or list)", source, m[2])
+		}
+	}
+	return p, nil
+}
+
+// Translate returns message in lang. Messages the catalog does not have,
+// and every message in DefaultLanguage, are returned as they are.
+func (c *Catalog) Translate(lang, message string) string {
+	l, ok := c.languages[lang]
+	if !ok || lang == DefaultLanguage {
+		return message
+	}
+	if translation, ok := l.messages[message]; ok {
+		return translation
+	}
+	for _, p := range l.patterns {
+		m := p.match.FindStringSubmatch(message)
+		if m == nil {
+			continue
+		}
+		values := make(map[string]string, len(p.names))
+		for i, name := range p.names {
+			values[name] = m[i+1]
+		}
+		return placeholder.ReplaceAllStringFunc(p.translation, func(token string) string {
+			parts := placeholder.FindStringSubmatch(token)
+			value := values[parts[1]]
+			switch parts[2] {
+			case "label":
+				return c.Label(lang, value)
+			case "list":
+				items := strings.Split(value, "; ")
+				for i, item := range items {
+					items[i] = c.Translate(lang, item)
+				}
+				return strings.Join(items, "; ")
+			}
+			return value
+		})
+	}
+	return message
+}
+
+// Label returns the display label of an enum value, such as a claim or
+// policy status, in lang. Values without a label are returned as they are.
+func (c *Catalog) Label(lang, value string) string {
+	if l, ok := c.languages[lang]; ok {
+		if label, ok := l.labels[value]; ok {
+			return label
+		}
+	}
+	if label, ok := c.languages[DefaultLanguage].labels[value]; ok {
+		return label
+	}
+	return value
+}
+
+// Labels returns every enum label in lang, keyed by value
+func (c *Catalog) Labels(lang string) map[string]string {
+	labels := make(map[string]string, len(c.languages[DefaultLanguage].labels))
+	for value := range c.languages[DefaultLanguage].labels {
+		labels[value] = c.Label(lang, value)
+	}
+	return labels
+}
+
+// Negotiate picks the supported language an Accept-Language header
+// prefers. Regional tags count for their language, so "fr-CA" is "fr";
+// without a supported language the result is DefaultLanguage.
+func Negotiate(acceptLanguage string) string {
+	best, bestQ := DefaultLanguage, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, q := parseRange(part)
+		if q <= bestQ {
+			continue
+		}
+		if tag == "*" {
+			best, bestQ = DefaultLanguage, q
+			continue
+		}
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			tag = tag[:i]
+		}
+		if contains(Languages, tag) {
+			best, bestQ = tag, q
+		}
+	}
+	return best
+}
+
+// parseRange reads one "tag;q=0.8" entry of an Accept-Language header. An
+// entry without a weight has weight 1; one that does not parse, weight 0.
+func parseRange(part string) (string, float64) {
+	fields := strings.Split(part, ";")
+	tag := strings.ToLower(strings.TrimSpace(fields[0]))
+	if tag == "" {
+		return "", 0
+	}
+	q := 1.0
+	for _, param := range fields[1:] {
+		param = strings.TrimSpace(param)
+		if !strings.HasPrefix(param, "q=") {
+			continue
+		}
+		if _, err := fmt.Sscanf(param[2:], "%g", &q); err != nil || q < 0 || q > 1 {
+			return tag, 0
+		}
+	}
+	return tag, q
+}
+
+// literalLength is the length of a catalog message without its placeholders
+func literalLength(source string) int {
+	return len(placeholder.ReplaceAllString(source, ""))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package i18n
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header, want string
+	}{
+		{"", "en"},
+		{"fr", "fr"},
+		{"fr-CA,fr;q=0.9,en;q=0.8", "fr"},
+		{"de_DE", "de"},
+		{"it,es;q=0.5", "es"},
+		{"ja", "en"},
+		{"en;q=0.4,es;q=0.7", "es"},
+		{"*", "en"},
+		{"fr;q=0", "en"},
+		{"es;q=abc,de", "de"},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	c := Default()
+	tests := []struct {
+		lang, message, want string
+	}{
+		{"fr", "Claim not found", "Sinistre introuvable"},
+		{"de", "Policy ID is required", "Vertrags-ID ist erforderlich"},
+		{"es", "adjusterId is required", "adjusterId es obligatorio"},
+		{"fr", "cannot change claim status from approved to under_review", "impossible de passer le sinistre de « Accepté » à « En cours d'examen »"},
+		{"es", "step 1 is invalid: policyId is required; description is required", "el paso 1 no es válido: policyId es obligatorio; description es obligatorio"},
+		{"de", "request body exceeds the 1048576 byte limit", "Der Anfragetext überschreitet das Limit von 1048576 Byte"},
+		{"fr", "a message the catalog does not have", "a message the catalog does not have"},
+		{"en", "Claim not found", "Claim not found"},
+		{"it", "Claim not found", "Claim not found"},
+	}
+	for _, tt := range tests {
+		if got := c.Translate(tt.lang, tt.message); got != tt.want {
+			t.Errorf("Translate(%s, %q) = %q, want %q", tt.lang, tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsCoverTheSameMessages(t *testing.T) {
+	c := Default()
+	reference := c.languages["fr"]
+	for _, lang := range Languages[1:] {
+		l := c.languages[lang]
+		if len(l.messages) != len(reference.messages) || len(l.patterns) != len(reference.patterns) {
+			t.Errorf("%s has %d messages and %d patterns, fr has %d and %d", lang, len(l.messages), len(l.patterns), len(reference.messages), len(reference.patterns))
+		}
+		for message := range reference.messages {
+			if _, ok := l.messages[message]; !ok {
+				t.Errorf("%s does not translate %q", lang, message)
+			}
+		}
+	}
+}
+
+func TestLabel(t *testing.T) {
+	c := Default()
+	if got := c.Label("de", "under_review"); got != "In Prüfung" {
+		t.Errorf("Unexpected label %q", got)
+	}
+	if got := c.Label("it", "under_review"); got != "Under review" {
+		t.Errorf("Expected the English label for an unsupported language, got %q", got)
+	}
+	if got := c.Label("fr", "unheard_of"); got != "unheard_of" {
+		t.Errorf("Expected an unknown value back as it is, got %q", got)
+	}
+}
+
+func TestMiddlewareTranslatesErrorBodies(t *testing.T) {
+	handler := Middleware(Default())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/claim":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Claim not found"})
+		case "/policy":
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]string{"error": "forbidden", "message": "You do not have access to this policy"})
+		case "/ok":
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(map[string]string{"status": "Claim not found"})
+		}
+		if lang := FromContext(r.Context()); lang != "es" {
+			t.Errorf("Expected es in the request context, got %s", lang)
+		}
+	}))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"/claim", http.StatusNotFound, `{"error":"Reclamación no encontrada"}` + "\n"},
+		{"/policy", http.StatusForbidden, `{"error":"forbidden","message":"No tiene acceso a esta póliza"}` + "\n"},
+		{"/ok", http.StatusOK, `{"status":"Claim not found"}` + "\n"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("Accept-Language", "es-MX,es;q=0.9")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus || rec.Body.String() != tt.wantBody {
+			t.Errorf("GET %s = %d %q, want %d %q", tt.path, rec.Code, rec.Body.String(), tt.wantStatus, tt.wantBody)
+		}
+		if rec.Header().Get("Vary") != "Accept-Language" {
+			t.Errorf("GET %s: expected Vary: Accept-Language, got %q", tt.path, rec.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestLabelsHandler(t *testing.T) {
+	req := httptest.NewRequest("GET", "/labels", nil)
+	req.Header.Set("Accept-Language", "fr")
+	rec := httptest.NewRecorder()
+	LabelsHandler(Default()).ServeHTTP(rec, req)
+
+	var body struct {
+		Language string            `json:"language"`
+		Labels   map[string]string `json:"labels"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode labels: %v", err)
+	}
+	if body.Language != "fr" || body.Labels["grace"] != "En délai de grâce" || rec.Header().Get("Content-Language") != "fr" {
+		t.Errorf("Unexpected labels %+v", body)
+	}
+}
@@ -0,0 +1,176 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// languageKey is the request context key of the negotiated language
+type languageKey struct{}
+
+// FromContext returns the language negotiated for a request by Middleware,
+// or DefaultLanguage outside it
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// errorFields are the fields of the services' JSON error bodies that carry
+// a message: {"error": message}, or {"error": code, "message": message}
+var errorFields = []string{"message", "error"}
+
+// Middleware negotiates the language of each request from its
+// Accept-Language header and translates the message of JSON error
+// responses into it. Error responses are held back until the handler
+// returns so the body can be rewritten; every other response passes
+// straight through.
+func Middleware(c *Catalog) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lang := Negotiate(r.Header.Get("Accept-Language"))
+			w.Header().Add("Vary", "Accept-Language")
+			r = r.WithContext(context.WithValue(r.Context(), languageKey{}, lang))
+			if lang == DefaultLanguage {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tw := &translatingWriter{ResponseWriter: w}
+			next.ServeHTTP(tw, r)
+			tw.finish(c, lang)
+		})
+	}
+}
+
+// translatingWriter holds back JSON error responses for translation
+type translatingWriter struct {
+	http.ResponseWriter
+	status    int
+	written   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (tw *translatingWriter) WriteHeader(code int) {
+	if tw.written {
+		return
+	}
+	tw.written = true
+	if code >= 400 && isJSON(tw.Header().Get("Content-Type")) {
+		tw.status = code
+		tw.buffering = true
+		return
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *translatingWriter) Write(b []byte) (int, error) {
+	if !tw.written {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.buffering {
+		return tw.body.Write(b)
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher so streaming responses work through the
+// middleware. A held back error response is sent when the handler returns.
+func (tw *translatingWriter) Flush() {
+	if tw.buffering {
+		return
+	}
+	if !tw.written {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker so WebSocket upgrades work through the
+// middleware
+func (tw *translatingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := tw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	tw.written = true
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (tw *translatingWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+// finish sends a held back error response, translated into lang
+func (tw *translatingWriter) finish(c *Catalog, lang string) {
+	if !tw.buffering {
+		return
+	}
+	body := tw.body.Bytes()
+	if translated, ok := translateBody(c, lang, body); ok {
+		body = translated
+		tw.Header().Set("Content-Language", lang)
+	}
+	if tw.Header().Get("Content-Length") != "" {
+		tw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	tw.ResponseWriter.WriteHeader(tw.status)
+	tw.ResponseWriter.Write(body)
+}
+
+// translateBody translates the message of a JSON error body, reporting
+// false when the body is not a JSON object or carries no message
+func translateBody(c *Catalog, lang string, body []byte) ([]byte, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false
+	}
+	for _, name := range errorFields {
+		var message string
+		if raw, ok := fields[name]; !ok || json.Unmarshal(raw, &message) != nil {
+			continue
+		}
+		translated := c.Translate(lang, message)
+		if translated == message {
+			return nil, false
+		}
+		fields[name], _ = json.Marshal(translated)
+		out, err := json.Marshal(fields)
+		if err != nil {
+			return nil, false
+		}
+		return append(out, '\n'), true
+	}
+	return nil, false
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/json"
+}
+
+// LabelsHandler serves the enum labels, such as claim and policy statuses,
+// in the language the request negotiates
+func LabelsHandler(c *Catalog) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Language", lang)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"language": lang,
+			"labels":   c.Labels(lang),
+		})
+	})
+}
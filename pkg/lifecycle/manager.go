@@ -0,0 +1,182 @@
+// Package lifecycle stops a service's components in order on shutdown, so
+// requests in flight, background jobs and event deliveries finish instead
+// of being killed mid-flight.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StopFunc stops a component. It should return once the component has
+// finished its work, or as soon as ctx ends.
+type StopFunc func(ctx context.Context) error
+
+// Result is the outcome of stopping one component
+type Result struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Report lists the outcome of stopping each component, in stop order
+type Report struct {
+	Results []Result
+}
+
+// Failed returns the components that returned an error or did not stop in
+// time
+func (r Report) Failed() []Result {
+	var failed []Result
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Err joins the failures into one error, or returns nil when every
+// component stopped
+func (r Report) Err() error {
+	var errs []error
+	for _, result := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", result.Name, result.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// stopGrace is how long a component whose context has ended still has to
+// report its own error
+const stopGrace = 100 * time.Millisecond
+
+type component struct {
+	name    string
+	timeout time.Duration
+	stop    StopFunc
+}
+
+// Manager holds the components to stop on shutdown
+type Manager struct {
+	logger     *logrus.Logger
+	mu         sync.Mutex
+	components []component
+	stopped    bool
+	report     Report
+}
+
+// New creates a manager that logs each component as it stops
+func New(logger *logrus.Logger) *Manager {
+	return &Manager{logger: logger}
+}
+
+// Register adds a component. Components stop in the order they were
+// registered, each given up to timeout; a timeout of 0 waits as long as the
+// context passed to Shutdown allows.
+func (m *Manager) Register(name string, timeout time.Duration, stop StopFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.components = append(m.components, component{name: name, timeout: timeout, stop: stop})
+}
+
+// Shutdown stops every component in order. A component that fails or runs
+// past its timeout is reported and the next one is stopped anyway; a
+// component still running past its timeout is left behind. Later calls
+// return the first call's report.
+func (m *Manager) Shutdown(ctx context.Context) Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return m.report
+	}
+	m.stopped = true
+
+	for _, c := range m.components {
+		start := time.Now()
+		err := c.run(ctx)
+		result := Result{Name: c.name, Duration: time.Since(start), Err: err}
+		m.report.Results = append(m.report.Results, result)
+
+		entry := m.logger.WithFields(logrus.Fields{
+			"component":   c.name,
+			"duration_ms": float64(result.Duration.Microseconds()) / 1000,
+		})
+		if err != nil {
+			entry.WithError(err).Error("Component failed to stop")
+		} else {
+			entry.Info("Component stopped")
+		}
+	}
+	return m.report
+}
+
+// run stops the component, giving up when its timeout or ctx ends first
+func (c component) run(ctx context.Context) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic while stopping: %v", r)
+			}
+		}()
+		done <- c.stop(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return c.timedOut(ctx, err)
+	case <-ctx.Done():
+	}
+
+	// A component that gives up when ctx ends usually says why; give it a
+	// moment to do so
+	select {
+	case err := <-done:
+		return c.timedOut(ctx, err)
+	case <-time.After(stopGrace):
+		return c.timedOut(ctx, ctx.Err())
+	}
+}
+
+// timedOut replaces the bare context error of a component that gave up
+// with one naming its timeout
+func (c component) timedOut(ctx context.Context, err error) error {
+	if err == nil || err != ctx.Err() {
+		return err
+	}
+	if c.timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("did not stop within %s", c.timeout)
+	}
+	return fmt.Errorf("did not stop: %w", err)
+}
+
+// Go runs fn in a new goroutine. The returned StopFunc cancels fn's context
+// and waits for fn to return.
+func Go(fn func(ctx context.Context)) StopFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+	return func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestShutdownStopsComponentsInOrder(t *testing.T) {
+	m := New(quietLogger())
+	var order []string
+	for _, name := range []string{"streams", "http server", "storage"} {
+		name := name
+		m.Register(name, time.Second, func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	report := m.Shutdown(context.Background())
+
+	if got := strings.Join(order, ","); got != "streams,http server,storage" {
+		t.Errorf("Expected components stopped in registration order, got %s", got)
+	}
+	if len(report.Results) != 3 || report.Err() != nil {
+		t.Errorf("Expected 3 clean results, got %+v", report)
+	}
+	if again := m.Shutdown(context.Background()); len(again.Results) != 3 || len(order) != 3 {
+		t.Errorf("Expected a second Shutdown to return the first report without stopping again")
+	}
+}
+
+func TestShutdownReportsFailuresAndContinues(t *testing.T) {
+	m := New(quietLogger())
+	m.Register("webhooks", 20*time.Millisecond, func(ctx context.Context) error {
+		select {} // ignores ctx
+	})
+	m.Register("recheck", time.Second, func(ctx context.Context) error {
+		return errors.New("store unavailable")
+	})
+	m.Register("flags", time.Second, func(ctx context.Context) error {
+		panic("boom")
+	})
+	stopped := false
+	m.Register("storage", time.Second, func(ctx context.Context) error {
+		stopped = true
+		return nil
+	})
+
+	report := m.Shutdown(context.Background())
+
+	if !stopped {
+		t.Fatal("Expected components after a failure to still be stopped")
+	}
+	failed := report.Failed()
+	if len(failed) != 3 {
+		t.Fatalf("Expected 3 failures, got %+v", failed)
+	}
+	expected := []string{
+		"webhooks: did not stop within 20ms",
+		"recheck: store unavailable",
+		"flags: panic while stopping: boom",
+	}
+	if got := report.Err().Error(); got != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected report error:\n%s", got)
+	}
+}
+
+func TestGoCancelsAndWaits(t *testing.T) {
+	finished := false
+	stop := Go(func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+	})
+
+	m := New(quietLogger())
+	m.Register("sweeper", time.Second, stop)
+	if err := m.Shutdown(context.Background()).Err(); err != nil {
+		t.Fatalf("Expected a clean stop, got %v", err)
+	}
+	if !finished {
+		t.Error("Expected Shutdown to wait for the goroutine to return")
+	}
+
+	stuck := Go(func(ctx context.Context) { select {} })
+	m = New(quietLogger())
+	m.Register("indexer", 10*time.Millisecond, stuck)
+	if err := m.Shutdown(context.Background()).Err(); err == nil || err.Error() != "indexer: did not stop within 10ms" {
+		t.Errorf("Expected a timeout for a goroutine ignoring its context, got %v", err)
+	}
+}
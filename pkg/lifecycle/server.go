@@ -0,0 +1,94 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// Connections counts an HTTP server's open connections
+type Connections struct {
+	// Active connections are reading or serving a request
+	Active int
+	// Idle connections are kept alive between requests
+	Idle int
+	// Hijacked counts connections taken over by a handler, such as
+	// WebSockets. The server no longer tracks them, so Shutdown neither
+	// waits for nor closes them.
+	Hijacked int
+}
+
+// ConnTracker follows the state of a server's connections
+type ConnTracker struct {
+	mu       sync.Mutex
+	states   map[net.Conn]http.ConnState
+	hijacked int
+}
+
+// TrackConnections starts tracking server's connections. It must be called
+// before the server starts; a ConnState hook already set on the server
+// still runs.
+func TrackConnections(server *http.Server) *ConnTracker {
+	t := &ConnTracker{states: make(map[net.Conn]http.ConnState)}
+	next := server.ConnState
+	server.ConnState = func(conn net.Conn, state http.ConnState) {
+		t.track(conn, state)
+		if next != nil {
+			next(conn, state)
+		}
+	}
+	return t
+}
+
+func (t *ConnTracker) track(conn net.Conn, state http.ConnState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch state {
+	case http.StateClosed:
+		delete(t.states, conn)
+	case http.StateHijacked:
+		delete(t.states, conn)
+		t.hijacked++
+	default:
+		t.states[conn] = state
+	}
+}
+
+// Connections returns the current counts
+func (t *ConnTracker) Connections() Connections {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := Connections{Hijacked: t.hijacked}
+	for _, state := range t.states {
+		if state == http.StateIdle {
+			counts.Idle++
+		} else {
+			counts.Active++
+		}
+	}
+	return counts
+}
+
+// DrainServer stops server accepting connections and waits for the
+// requests in flight to finish. When ctx ends first, the remaining
+// connections are closed and the error says how many were cut off; conns
+// may be nil when the count is not wanted.
+func DrainServer(server *http.Server, conns *ConnTracker) StopFunc {
+	return func(ctx context.Context) error {
+		err := server.Shutdown(ctx)
+		if err == nil {
+			return nil
+		}
+		var open Connections
+		if conns != nil {
+			open = conns.Connections()
+		}
+		server.Close()
+		if conns != nil {
+			return fmt.Errorf("closed %d connections with requests in flight: %w", open.Active, err)
+		}
+		return fmt.Errorf("closed connections with requests in flight: %w", err)
+	}
+}
@@ -0,0 +1,75 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDrainServerWaitsForRequestsInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	conns := TrackConnections(server.Config)
+	server.Start()
+	defer server.Close()
+
+	status := make(chan int, 1)
+	go func() {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	<-started
+
+	if open := conns.Connections(); open.Active != 1 {
+		t.Fatalf("Expected 1 active connection, got %+v", open)
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := DrainServer(server.Config, conns)(context.Background()); err != nil {
+		t.Fatalf("Expected a clean drain, got %v", err)
+	}
+	if got := <-status; got != http.StatusNoContent {
+		t.Errorf("Expected the request in flight to finish with 204, got %d", got)
+	}
+}
+
+func TestDrainServerClosesConnectionsPastDeadline(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	conns := TrackConnections(server.Config)
+	server.Start()
+	defer server.Close()
+
+	go func() {
+		if resp, err := http.Get(server.URL); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := DrainServer(server.Config, conns)(ctx)
+	if err == nil || !strings.HasPrefix(err.Error(), "closed 1 connections with requests in flight") {
+		t.Errorf("Expected the stuck connection to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package logging
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// packageLevel is a level for the packages matching a path
+type packageLevel struct {
+	path  string
+	level logrus.Level
+}
+
+// filter is a formatter that drops entries below their package's level or
+// thinned out by sampling, and formats the rest with base. Dropped entries
+// format to nothing, which the logger writes as nothing.
+type filter struct {
+	base     logrus.Formatter
+	level    logrus.Level
+	packages []packageLevel
+	// levels caches the level for each calling function
+	levels sync.Map
+
+	sampling Sampling
+	mu       sync.Mutex
+	window   time.Time
+	counts   map[sampleKey]int
+	now      func() time.Time
+}
+
+type sampleKey struct {
+	level   logrus.Level
+	message string
+}
+
+func newFilter(base logrus.Formatter, cfg Config) *filter {
+	f := &filter{
+		base:     base,
+		level:    cfg.Level,
+		sampling: cfg.Sampling,
+		counts:   make(map[sampleKey]int),
+		now:      time.Now,
+	}
+	if f.sampling.Interval <= 0 {
+		f.sampling.Interval = time.Second
+	}
+	for path, level := range cfg.PackageLevels {
+		f.packages = append(f.packages, packageLevel{path: path, level: level})
+	}
+	// The longest, most specific path matches first
+	sort.Slice(f.packages, func(i, j int) bool {
+		return len(f.packages[i].path) > len(f.packages[j].path)
+	})
+	return f
+}
+
+// mostVerbose returns the most verbose level any package logs at, which the
+// logger itself must allow
+func (f *filter) mostVerbose() logrus.Level {
+	level := f.level
+	for _, p := range f.packages {
+		if p.level > level {
+			level = p.level
+		}
+	}
+	return level
+}
+
+// Format implements logrus.Formatter
+func (f *filter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.enabled(entry) || !f.sample(entry) {
+		return nil, nil
+	}
+	if entry.Caller != nil {
+		// The caller was only recorded to find the package; keep it out of
+		// the line
+		stripped := *entry
+		stripped.Caller = nil
+		entry = &stripped
+	}
+	return f.base.Format(entry)
+}
+
+// enabled reports whether the entry is at or above its package's level
+func (f *filter) enabled(entry *logrus.Entry) bool {
+	if len(f.packages) == 0 || entry.Caller == nil {
+		return entry.Level <= f.level
+	}
+	fn := entry.Caller.Function
+	if cached, ok := f.levels.Load(fn); ok {
+		return entry.Level <= cached.(logrus.Level)
+	}
+	level := f.levelFor(packagePath(fn))
+	f.levels.Store(fn, level)
+	return entry.Level <= level
+}
+
+// levelFor returns the level of the longest configured path that is the
+// package's import path or its trailing elements
+func (f *filter) levelFor(pkg string) logrus.Level {
+	for _, p := range f.packages {
+		if pkg == p.path || strings.HasSuffix(pkg, "/"+p.path) {
+			return p.level
+		}
+	}
+	return f.level
+}
+
+// sample reports whether the entry survives sampling. Within each interval
+// the first entries with a level and message are kept, then every
+// Thereafter-th one.
+func (f *filter) sample(entry *logrus.Entry) bool {
+	if !f.sampling.enabled() || entry.Level < logrus.InfoLevel {
+		return true
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	if now.Sub(f.window) >= f.sampling.Interval {
+		f.window = now
+		f.counts = make(map[sampleKey]int)
+	}
+	key := sampleKey{level: entry.Level, message: entry.Message}
+	f.counts[key]++
+	n := f.counts[key]
+	if n <= f.sampling.First {
+		return true
+	}
+	return f.sampling.Thereafter > 0 && (n-f.sampling.First)%f.sampling.Thereafter == 0
+}
+
+// packagePath returns the import path of the package a function belongs
+// to, such as "github.com/org/repo/internal/features" for
+// "github.com/org/repo/internal/features.(*Flags).Currency"
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
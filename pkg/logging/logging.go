@@ -0,0 +1,180 @@
+// Package logging builds the services' loggers: JSON or text output, a
+// level per package and sampling of high-frequency lines, configured from
+// the environment.
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats accepted by Config.Format
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config selects how a logger writes
+type Config struct {
+	// Format is FormatJSON (the default) or FormatText
+	Format string
+	// Level applies to packages without a level of their own
+	Level logrus.Level
+	// PackageLevels overrides Level for packages, keyed by import path or
+	// its trailing elements, such as "features" or "internal/middleware"
+	PackageLevels map[string]logrus.Level
+	// Sampling thins out repeated debug and info lines; the zero value logs
+	// every line
+	Sampling Sampling
+}
+
+// Sampling keeps the first First lines with the same level and message in
+// each Interval, then every Thereafter-th one. Warnings and errors are never
+// sampled.
+type Sampling struct {
+	First      int
+	Thereafter int
+	// Interval defaults to 1s
+	Interval time.Duration
+}
+
+// enabled reports whether any lines are dropped
+func (s Sampling) enabled() bool {
+	return s.First > 0 || s.Thereafter > 0
+}
+
+// New creates a logger writing to stderr
+func New(cfg Config) (*logrus.Logger, error) {
+	base, err := formatter(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+	return build(cfg, base), nil
+}
+
+// formatter returns the formatter for a Config.Format
+func formatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", FormatJSON:
+		return &logrus.JSONFormatter{}, nil
+	case FormatText:
+		return &logrus.TextFormatter{FullTimestamp: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+func build(cfg Config, base logrus.Formatter) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(cfg.Level)
+	if len(cfg.PackageLevels) == 0 && !cfg.Sampling.enabled() {
+		logger.SetFormatter(base)
+		return logger
+	}
+
+	f := newFilter(base, cfg)
+	if len(cfg.PackageLevels) > 0 {
+		// Entries must reach the filter to be judged by their package, and
+		// only the caller says which package logged them
+		logger.SetLevel(f.mostVerbose())
+		logger.SetReportCaller(true)
+	}
+	logger.SetFormatter(f)
+	return logger
+}
+
+// ParseLevels parses per-package levels written as
+// "package=level,package=level", such as "features=debug,middleware=warn"
+func ParseLevels(s string) (map[string]logrus.Level, error) {
+	levels := make(map[string]logrus.Level)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		pkg, name, ok := strings.Cut(pair, "=")
+		pkg = strings.Trim(strings.TrimSpace(pkg), "/")
+		if !ok || pkg == "" {
+			return nil, fmt.Errorf("invalid package level %q, expected package=level", pair)
+		}
+		level, err := logrus.ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, fmt.Errorf("invalid level for package %s: %w", pkg, err)
+		}
+		levels[pkg] = level
+	}
+	return levels, nil
+}
+
+// NewFromEnv creates a logger configured by LOG_FORMAT, LOG_LEVEL,
+// LOG_LEVELS, LOG_SAMPLE_FIRST and LOG_SAMPLE_THEREAFTER. An invalid
+// setting is logged as a warning and left at its default, so a typo never
+// stops a service from starting.
+func NewFromEnv() *logrus.Logger {
+	cfg := Config{Level: logrus.InfoLevel}
+	var warnings []string
+
+	format := strings.ToLower(os.Getenv("LOG_FORMAT"))
+	base, err := formatter(format)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("Invalid log format '%s', defaulting to json", format))
+		base = &logrus.JSONFormatter{}
+	}
+	if name := os.Getenv("LOG_LEVEL"); name != "" {
+		if level, err := logrus.ParseLevel(name); err == nil {
+			cfg.Level = level
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Invalid log level '%s', defaulting to info", name))
+		}
+	}
+	if spec := os.Getenv("LOG_LEVELS"); spec != "" {
+		if levels, err := ParseLevels(spec); err == nil {
+			cfg.PackageLevels = levels
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Invalid LOG_LEVELS, ignoring package levels: %v", err))
+		}
+	}
+	for _, setting := range []struct {
+		name  string
+		value *int
+	}{
+		{"LOG_SAMPLE_FIRST", &cfg.Sampling.First},
+		{"LOG_SAMPLE_THEREAFTER", &cfg.Sampling.Thereafter},
+	} {
+		raw := os.Getenv(setting.name)
+		if raw == "" {
+			continue
+		}
+		if n, err := strconv.Atoi(raw); err == nil && n >= 0 {
+			*setting.value = n
+		} else {
+			warnings = append(warnings, fmt.Sprintf("Invalid %s '%s', defaulting to 0", setting.name, raw))
+		}
+	}
+
+	logger := build(cfg, base)
+	for _, warning := range warnings {
+		logger.Warn(warning)
+	}
+	if len(cfg.PackageLevels) > 0 {
+		logger.WithField("levels", formatLevels(cfg.PackageLevels)).Info("Package log levels configured")
+	}
+	return logger
+}
+
+// formatLevels writes levels back in the LOG_LEVELS form, sorted so the
+// line reads the same on every start
+func formatLevels(levels map[string]logrus.Level) string {
+	pairs := make([]string, 0, len(levels))
+	for pkg, level := range levels {
+		pairs = append(pairs, pkg+"="+level.String())
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,180 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lines decodes the JSON lines written to buf
+func lines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var out []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestNewFormats(t *testing.T) {
+	for _, tc := range []struct {
+		format string
+		check  func(string) bool
+	}{
+		{"", func(s string) bool { return strings.HasPrefix(s, `{"`) }},
+		{FormatJSON, func(s string) bool { return strings.HasPrefix(s, `{"`) }},
+		{FormatText, func(s string) bool { return strings.Contains(s, `msg="Claim submitted"`) }},
+	} {
+		logger, err := New(Config{Format: tc.format, Level: logrus.InfoLevel})
+		if err != nil {
+			t.Fatalf("Format %q: %v", tc.format, err)
+		}
+		var buf bytes.Buffer
+		logger.SetOutput(&buf)
+		logger.Info("Claim submitted")
+		if !tc.check(buf.String()) {
+			t.Errorf("Format %q wrote %q", tc.format, buf.String())
+		}
+	}
+
+	if _, err := New(Config{Format: "xml"}); err == nil || err.Error() != `unknown log format "xml"` {
+		t.Errorf("Expected an unknown format error, got %v", err)
+	}
+}
+
+func TestPackageLevels(t *testing.T) {
+	levels, err := ParseLevels("logging=debug, other/pkg=error")
+	if err != nil {
+		t.Fatalf("ParseLevels: %v", err)
+	}
+	logger, err := New(Config{Level: logrus.WarnLevel, PackageLevels: levels})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	// This test lives in the logging package, which logs at debug
+	logger.Debug("Debug from logging")
+	logger.WithField("claimId", "claim-001").Info("Info from logging")
+
+	got := lines(t, &buf)
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 lines at the package's debug level, got %d: %s", len(got), buf.String())
+	}
+	if _, ok := got[0]["func"]; ok {
+		t.Errorf("Expected the caller to be left out of the line, got %v", got[0])
+	}
+	if got[1]["claimId"] != "claim-001" {
+		t.Errorf("Expected fields to be kept, got %v", got[1])
+	}
+
+	f := logger.Formatter.(*filter)
+	for pkg, want := range map[string]logrus.Level{
+		"github.com/org/repo/other/pkg":   logrus.ErrorLevel,
+		"github.com/org/repo/pkg":         logrus.WarnLevel,
+		"github.com/org/repo/another/pkg": logrus.WarnLevel,
+	} {
+		if got := f.levelFor(pkg); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, pkg, got)
+		}
+	}
+
+	for _, spec := range []string{"features", "=debug", "features=loud"} {
+		if _, err := ParseLevels(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestPackagePath(t *testing.T) {
+	for fn, want := range map[string]string{
+		"github.com/org/repo/internal/features.(*Flags).Currency": "github.com/org/repo/internal/features",
+		"github.com/org/repo/internal/services.NewIndexer.func1":  "github.com/org/repo/internal/services",
+		"main.main": "main",
+	} {
+		if got := packagePath(fn); got != want {
+			t.Errorf("packagePath(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
+
+func TestSampling(t *testing.T) {
+	logger, err := New(Config{Level: logrus.DebugLevel, Sampling: Sampling{First: 2, Thereafter: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	now := time.Date(2024, 12, 21, 10, 0, 0, 0, time.UTC)
+	f := logger.Formatter.(*filter)
+	f.now = func() time.Time { return now }
+
+	for i := 0; i < 8; i++ {
+		logger.Debug("Currency determined by user country")
+		logger.Warn("Policy service unavailable")
+	}
+	count := func(msg string) int {
+		n := 0
+		for _, entry := range lines(t, &buf) {
+			if entry["msg"] == msg {
+				n++
+			}
+		}
+		return n
+	}
+	// Lines 1, 2, 5 and 8 are kept
+	if got := count("Currency determined by user country"); got != 4 {
+		t.Errorf("Expected 4 sampled debug lines, got %d", got)
+	}
+	if got := count("Policy service unavailable"); got != 8 {
+		t.Errorf("Expected every warning, got %d", got)
+	}
+
+	buf.Reset()
+	now = now.Add(time.Second)
+	logger.Debug("Currency determined by user country")
+	if got := count("Currency determined by user country"); got != 1 {
+		t.Errorf("Expected the next interval to start counting again, got %d", got)
+	}
+}
+
+func TestNewFromEnvFallsBackOnInvalidSettings(t *testing.T) {
+	t.Setenv("LOG_FORMAT", "yaml")
+	t.Setenv("LOG_LEVEL", "loud")
+	t.Setenv("LOG_LEVELS", "features")
+	t.Setenv("LOG_SAMPLE_FIRST", "-1")
+
+	logger := NewFromEnv()
+	if _, ok := logger.Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("Expected JSON output, got %T", logger.Formatter)
+	}
+	if logger.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected info level, got %s", logger.GetLevel())
+	}
+
+	t.Setenv("LOG_FORMAT", "TEXT")
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("LOG_LEVELS", "")
+	t.Setenv("LOG_SAMPLE_FIRST", "100")
+	t.Setenv("LOG_SAMPLE_THEREAFTER", "100")
+	logger = NewFromEnv()
+	f, ok := logger.Formatter.(*filter)
+	if !ok {
+		t.Fatalf("Expected a sampling formatter, got %T", logger.Formatter)
+	}
+	if _, ok := f.base.(*logrus.TextFormatter); !ok || f.sampling.First != 100 || f.sampling.Thereafter != 100 {
+		t.Errorf("Expected text output sampled 100/100, got %T %+v", f.base, f.sampling)
+	}
+}
@@ -0,0 +1,260 @@
+// Package maintenance lets operators drain a service during data
+// migrations. While the system.maintenanceMode flag is on, every route
+// except health checks and metrics answers 503 with a Retry-After header;
+// requests carrying the bypass token still go through, so operators can
+// check the service before reopening it.
+package maintenance
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FlagKey is the feature flag reporting maintenance mode in /healthz
+const FlagKey = "system.maintenanceMode"
+
+// BypassHeader carries the bypass token on requests allowed through during
+// maintenance
+const BypassHeader = "X-Maintenance-Bypass"
+
+// Path is where each service serves the maintenance endpoint
+const Path = "/admin/maintenance"
+
+// DefaultMessage is returned to callers turned away without a message of
+// the operator's own
+const DefaultMessage = "The service is down for maintenance, try again later"
+
+// DefaultRetryAfter is how long callers are told to wait when no wait is
+// given
+const DefaultRetryAfter = 5 * time.Minute
+
+// Exempt are the paths always served: health checks, so orchestrators keep
+// the instance, metrics, and the maintenance endpoint itself
+var Exempt = []string{"/healthz", "/metrics", Path}
+
+// Config is the mode a service starts in
+type Config struct {
+	Enabled     bool          // FEATURE_MAINTENANCE_MODE
+	Message     string        // MAINTENANCE_MESSAGE; DefaultMessage when empty
+	RetryAfter  time.Duration // MAINTENANCE_RETRY_AFTER; DefaultRetryAfter when zero
+	BypassToken string        // MAINTENANCE_BYPASS_TOKEN; no bypass when empty
+}
+
+// ConfigFromEnv reads FEATURE_MAINTENANCE_MODE, MAINTENANCE_MESSAGE,
+// MAINTENANCE_RETRY_AFTER and MAINTENANCE_BYPASS_TOKEN. An invalid setting
+// is logged as a warning and left at its default.
+func ConfigFromEnv(logger *logrus.Logger) Config {
+	cfg := Config{
+		Message:     os.Getenv("MAINTENANCE_MESSAGE"),
+		BypassToken: os.Getenv("MAINTENANCE_BYPASS_TOKEN"),
+	}
+	if v := os.Getenv("FEATURE_MAINTENANCE_MODE"); v != "" {
+		if enabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Enabled = enabled
+		} else {
+			logger.Warnf("Invalid FEATURE_MAINTENANCE_MODE '%s', defaulting to false", v)
+		}
+	}
+	if v := os.Getenv("MAINTENANCE_RETRY_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.RetryAfter = d
+		} else {
+			logger.Warnf("Invalid MAINTENANCE_RETRY_AFTER '%s', defaulting to %s", v, DefaultRetryAfter)
+		}
+	}
+	return cfg
+}
+
+// Status is the current mode, as served by the maintenance endpoint
+type Status struct {
+	Enabled           bool       `json:"enabled"`
+	Message           string     `json:"message"`
+	RetryAfterSeconds int        `json:"retryAfterSeconds"`
+	Since             *time.Time `json:"since,omitempty"` // when maintenance began
+}
+
+// Mode is whether a service is in maintenance. It is safe for concurrent
+// use; the zero value is not, use New.
+type Mode struct {
+	mu         sync.RWMutex
+	enabled    bool
+	message    string
+	retryAfter time.Duration
+	since      time.Time
+	bypass     []byte
+	logger     *logrus.Logger
+}
+
+// New creates the maintenance mode of a service
+func New(cfg Config, logger *logrus.Logger) *Mode {
+	m := &Mode{
+		bypass: []byte(cfg.BypassToken),
+		logger: logger,
+	}
+	m.set(cfg.Enabled, cfg.Message, cfg.RetryAfter, time.Now())
+	if cfg.Enabled {
+		logger.WithField("retryAfter", m.retryAfter).Warn("Starting in maintenance mode")
+	}
+	return m
+}
+
+// Enabled reports whether the service is in maintenance
+func (m *Mode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// Status returns the current mode
+func (m *Mode) Status() Status {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	status := Status{
+		Enabled:           m.enabled,
+		Message:           m.message,
+		RetryAfterSeconds: seconds(m.retryAfter),
+	}
+	if m.enabled {
+		since := m.since
+		status.Since = &since
+	}
+	return status
+}
+
+// Set turns maintenance on or off. An empty message and a zero retryAfter
+// fall back to the defaults.
+func (m *Mode) Set(enabled bool, message string, retryAfter time.Duration) {
+	m.set(enabled, message, retryAfter, time.Now())
+	m.logger.WithFields(logrus.Fields{
+		"enabled":    enabled,
+		"retryAfter": m.Status().RetryAfterSeconds,
+	}).Warn("Maintenance mode updated")
+}
+
+func (m *Mode) set(enabled bool, message string, retryAfter time.Duration, now time.Time) {
+	if message == "" {
+		message = DefaultMessage
+	}
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if enabled && !m.enabled {
+		m.since = now
+	}
+	m.enabled = enabled
+	m.message = message
+	m.retryAfter = retryAfter
+}
+
+// EnabledFlags reports FlagKey while the service is in maintenance, so the
+// mode shows in the health check with the service's other flags
+func (m *Mode) EnabledFlags() []string {
+	if m.Enabled() {
+		return []string{FlagKey}
+	}
+	return nil
+}
+
+// Bypassed reports whether r carries the bypass token
+func (m *Mode) Bypassed(r *http.Request) bool {
+	if len(m.bypass) == 0 {
+		return false
+	}
+	token := r.Header.Get(BypassHeader)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), m.bypass) == 1
+}
+
+// Middleware answers 503 to every request while the service is in
+// maintenance, except on Exempt paths and for requests that carry the
+// bypass token
+func (m *Mode) Middleware(next http.Handler) http.Handler {
+	exempt := make(map[string]bool, len(Exempt))
+	for _, path := range Exempt {
+		exempt[path] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.Enabled() || exempt[r.URL.Path] || m.Bypassed(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		status := m.Status()
+		w.Header().Set("Retry-After", strconv.Itoa(status.RetryAfterSeconds))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":             "maintenance",
+			"message":           status.Message,
+			"retryAfterSeconds": status.RetryAfterSeconds,
+		})
+	})
+}
+
+// RequireBypass only lets through requests carrying the bypass token. It
+// guards the maintenance endpoint of services without staff roles.
+func (m *Mode) RequireBypass(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.Bypassed(r) {
+			respondError(w, http.StatusForbidden, "Maintenance bypass token required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Update is the body of PUT /admin/maintenance
+type Update struct {
+	Enabled           *bool  `json:"enabled"`
+	Message           string `json:"message,omitempty"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds,omitempty"`
+}
+
+// Handler serves GET /admin/maintenance with the current mode, and PUT to
+// change it. Guard it with the service's admin role check.
+func (m *Mode) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var update Update
+			if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			if update.Enabled == nil {
+				respondError(w, http.StatusBadRequest, "enabled is required")
+				return
+			}
+			if update.RetryAfterSeconds < 0 {
+				respondError(w, http.StatusBadRequest, "retryAfterSeconds must not be negative")
+				return
+			}
+			m.Set(*update.Enabled, update.Message, time.Duration(update.RetryAfterSeconds)*time.Second)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(m.Status())
+	})
+}
+
+func respondError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// seconds rounds d up to whole seconds, as Retry-After takes
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,158 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestMode(cfg Config) *Mode {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return New(cfg, logger)
+}
+
+func TestMiddlewareTurnsAwayAllButHealthAndBypass(t *testing.T) {
+	mode := newTestMode(Config{BypassToken: "drain-2026"})
+	handler := mode.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set(BypassHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/claims", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected requests to pass outside maintenance, got %d", rec.Code)
+	}
+
+	mode.Set(true, "Migrating claims to the new schema", 90*time.Second)
+	rec := serve("/claims", "")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "90" {
+		t.Fatalf("Expected 503 with Retry-After 90, got %d and %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode 503 body: %v", err)
+	}
+	if body["error"] != "maintenance" || body["message"] != "Migrating claims to the new schema" || body["retryAfterSeconds"] != float64(90) {
+		t.Errorf("Unexpected 503 body %v", body)
+	}
+
+	for _, path := range []string{"/healthz", "/metrics", Path} {
+		if rec := serve(path, ""); rec.Code != http.StatusNoContent {
+			t.Errorf("Expected %s to be served during maintenance, got %d", path, rec.Code)
+		}
+	}
+	if rec := serve("/admin/dlq", "drain-2026"); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected the bypass token through, got %d", rec.Code)
+	}
+	if rec := serve("/claims", "guess"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected a wrong token to be turned away, got %d", rec.Code)
+	}
+
+	mode.Set(false, "", 0)
+	if rec := serve("/claims", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected requests to pass once maintenance is over, got %d", rec.Code)
+	}
+}
+
+func TestNoBypassWithoutToken(t *testing.T) {
+	mode := newTestMode(Config{Enabled: true})
+	req := httptest.NewRequest(http.MethodGet, "/policies", nil)
+	req.Header.Set(BypassHeader, "")
+	if mode.Bypassed(req) {
+		t.Error("Expected no bypass when no token is configured")
+	}
+	if got := mode.EnabledFlags(); len(got) != 1 || got[0] != FlagKey {
+		t.Errorf("EnabledFlags = %v, want %s", got, FlagKey)
+	}
+	if status := mode.Status(); status.Message != DefaultMessage || status.RetryAfterSeconds != 300 || status.Since == nil {
+		t.Errorf("Unexpected default status %+v", status)
+	}
+}
+
+func TestHandlerUpdatesMode(t *testing.T) {
+	mode := newTestMode(Config{})
+	handler := mode.Handler()
+	put := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, Path, strings.NewReader(body)))
+		return rec
+	}
+
+	for _, body := range []string{`{`, `{"message": "no state"}`, `{"enabled": true, "retryAfterSeconds": -1}`} {
+		if rec := put(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected %s to be refused, got %d", body, rec.Code)
+		}
+	}
+	if mode.Enabled() {
+		t.Fatal("Expected refused updates to leave maintenance off")
+	}
+
+	rec := put(`{"enabled": true, "message": "Back at 02:00 UTC", "retryAfterSeconds": 1800}`)
+	var status Status
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("PUT failed: %d, %v", rec.Code, err)
+	}
+	if !status.Enabled || status.Message != "Back at 02:00 UTC" || status.RetryAfterSeconds != 1800 || status.Since == nil {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	since := *status.Since
+
+	// Updating the message keeps the start of the maintenance window
+	put(`{"enabled": true, "message": "Back at 03:00 UTC"}`)
+	if status := mode.Status(); !status.Since.Equal(since) || status.RetryAfterSeconds != 300 {
+		t.Errorf("Expected the window to run on from %s with the default wait, got %+v", since, status)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil || status.Message != "Back at 03:00 UTC" {
+		t.Errorf("GET returned %+v (%v)", status, err)
+	}
+}
+
+func TestRequireBypass(t *testing.T) {
+	mode := newTestMode(Config{BypassToken: "drain-2026"})
+	handler := mode.RequireBypass(mode.Handler())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Path, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without the token, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, Path, nil)
+	req.Header.Set(BypassHeader, "drain-2026")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected the token to be accepted, got %d", rec.Code)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("FEATURE_MAINTENANCE_MODE", "true")
+	t.Setenv("MAINTENANCE_MESSAGE", "Migrating payments")
+	t.Setenv("MAINTENANCE_RETRY_AFTER", "soon")
+	t.Setenv("MAINTENANCE_BYPASS_TOKEN", "drain-2026")
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	cfg := ConfigFromEnv(logger)
+	if !cfg.Enabled || cfg.Message != "Migrating payments" || cfg.RetryAfter != 0 || cfg.BypassToken != "drain-2026" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+}
@@ -0,0 +1,255 @@
+// Package migrate upgrades the JSON data files of the services'
+// repositories as their models change. A versioned file wraps its data in
+// the schema version it was written in:
+//
+//	{"schemaVersion": 2, "data": [...]}
+//
+// A file without the wrapper, as the seed fixtures were first written, is
+// at version 0. Each file has a Set of migrations, one per version. Load
+// runs those a file has not had yet, in order, keeps a backup of the file
+// as it was and writes it back at the latest version before decoding it.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// versionKey marks a versioned data file
+const versionKey = "schemaVersion"
+
+// Migration upgrades the data of a file from the version before Version to
+// Version. Up receives the data decoded into interface{} values, numbers
+// as json.Number so they are written back exactly, and returns it
+// upgraded.
+type Migration struct {
+	Version int
+	Name    string // what changed, e.g. "split name into firstName and lastName"
+	Up      func(data interface{}) (interface{}, error)
+}
+
+// Set is the migrations of one data file, numbered 1, 2, 3 in the order
+// they run. A file's latest version is its number of migrations.
+type Set []Migration
+
+// Latest returns the version the set upgrades files to
+func (s Set) Latest() int {
+	return len(s)
+}
+
+// validate checks the migrations are numbered in order and can run
+func (s Set) validate() error {
+	for i, m := range s {
+		if m.Version != i+1 {
+			return fmt.Errorf("migration %d is numbered %d; versions must run 1, 2, 3 in order", i+1, m.Version)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("migration %d (%s) has no Up", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// Result reports what Load did to a file
+type Result struct {
+	From    int      // version the file was at
+	To      int      // version it is at now
+	Applied []string // names of the migrations run, in order
+	Backup  string   // copy of the file as it was, when migrations ran
+}
+
+// Migrated reports whether the file was upgraded
+func (r Result) Migrated() bool {
+	return len(r.Applied) > 0
+}
+
+// versioned is the form of a data file with a schema version
+type versioned struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Records returns an Up that applies fn to each object of a file holding a
+// JSON array, such as renaming a field or filling one in
+func Records(fn func(record map[string]interface{}) error) func(interface{}) (interface{}, error) {
+	return func(data interface{}) (interface{}, error) {
+		records, ok := data.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("data is not an array")
+		}
+		for i, r := range records {
+			record, ok := r.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %d is not an object", i)
+			}
+			if err := fn(record); err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+		}
+		return records, nil
+	}
+}
+
+// Load reads the data file at path, upgrades it with the migrations of set
+// it has not had and decodes its data into v. When migrations run, the file
+// as it was is first copied beside it as <path>.v<from>-<UTC time>.bak, and
+// the upgraded file replaces it at set's latest version. A file newer than
+// set knows is refused, as is one a migration fails on; neither is changed.
+// A missing file returns the os.ReadFile error.
+func Load(path string, set Set, v interface{}) (Result, error) {
+	if err := set.validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid migrations for %s: %w", path, err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Result{}, err
+	}
+	version, data, err := parse(raw)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid data file %s: %w", path, err)
+	}
+
+	result := Result{From: version, To: version}
+	if version > set.Latest() {
+		return result, fmt.Errorf("%s is at schema version %d, newer than the %d this service knows", path, version, set.Latest())
+	}
+	if version < set.Latest() {
+		if data, err = upgrade(path, set[version:], data, &result); err != nil {
+			return result, err
+		}
+		if result.Backup, err = backup(path, raw, version, time.Now()); err != nil {
+			return result, fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		if err := write(path, set.Latest(), data); err != nil {
+			return result, fmt.Errorf("failed to write migrated %s: %w", path, err)
+		}
+		result.To = set.Latest()
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return result, fmt.Errorf("invalid data file %s: %w", path, err)
+	}
+	return result, nil
+}
+
+// Read decodes the data of the file at path into v, whatever its version,
+// and returns the version. It is for services that read a file another
+// service owns and migrates, and only use fields every version has.
+func Read(path string, v interface{}) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	version, data, err := parse(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid data file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return version, fmt.Errorf("invalid data file %s: %w", path, err)
+	}
+	return version, nil
+}
+
+// parse splits a data file into its version and data
+func parse(raw []byte) (int, json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return 0, trimmed, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &fields); err != nil {
+		return 0, nil, err
+	}
+	if _, ok := fields[versionKey]; !ok {
+		return 0, trimmed, nil
+	}
+
+	var file versioned
+	if err := json.Unmarshal(trimmed, &file); err != nil {
+		return 0, nil, fmt.Errorf("schemaVersion is not a number: %w", err)
+	}
+	if file.SchemaVersion < 0 {
+		return 0, nil, fmt.Errorf("schemaVersion %d is negative", file.SchemaVersion)
+	}
+	if len(file.Data) == 0 {
+		return 0, nil, fmt.Errorf("schema version %d file has no data", file.SchemaVersion)
+	}
+	return file.SchemaVersion, file.Data, nil
+}
+
+// upgrade runs migrations over data in order, recording each in result
+func upgrade(path string, migrations Set, data json.RawMessage, result *Result) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid data file %s: %w", path, err)
+	}
+
+	for _, m := range migrations {
+		var err error
+		if value, err = m.Up(value); err != nil {
+			return nil, fmt.Errorf("migration %d (%s) of %s failed: %w", m.Version, m.Name, path, err)
+		}
+		result.Applied = append(result.Applied, m.Name)
+	}
+	return json.Marshal(value)
+}
+
+// backup copies raw beside path, under a name no earlier backup has
+func backup(path string, raw []byte, version int, now time.Time) (string, error) {
+	name := fmt.Sprintf("%s.v%d-%s.bak", path, version, now.UTC().Format("20060102T150405Z"))
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(raw); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return "", err
+	}
+	return name, file.Close()
+}
+
+// write replaces the file at path with data at version, with the same
+// two-space indentation as the fixtures. The file is written to a
+// temporary path and renamed, so a crash leaves the old file or the new.
+func write(path string, version int, data json.RawMessage) error {
+	out, err := json.MarshalIndent(versioned{SchemaVersion: version, Data: data}, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append(out, '\n')
+
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(out); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
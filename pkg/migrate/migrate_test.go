@@ -0,0 +1,164 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// customer is the current model of the records in the test file
+type customer struct {
+	ID        string `json:"id"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Country   string `json:"country"`
+	Balance   int64  `json:"balance"`
+}
+
+// customerMigrations split the name, then fill in a country
+var customerMigrations = Set{
+	{Version: 1, Name: "split name into firstName and lastName", Up: Records(func(record map[string]interface{}) error {
+		name, _ := record["name"].(string)
+		first, last, _ := strings.Cut(name, " ")
+		record["firstName"], record["lastName"] = first, last
+		delete(record, "name")
+		return nil
+	})},
+	{Version: 2, Name: "default country to US", Up: Records(func(record map[string]interface{}) error {
+		if _, ok := record["country"]; !ok {
+			record["country"] = "US"
+		}
+		return nil
+	})},
+}
+
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "customers.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("Failed to write data file: %v", err)
+	}
+	return path
+}
+
+func TestLoadMigratesUnversionedFiles(t *testing.T) {
+	original := `[{"id": "cust-001", "name": "Ada Lovelace", "balance": 9007199254740993}]`
+	path := writeFile(t, original)
+
+	var customers []customer
+	result, err := Load(path, customerMigrations, &customers)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if result.From != 0 || result.To != 2 || len(result.Applied) != 2 || result.Applied[1] != "default country to US" {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	want := customer{ID: "cust-001", FirstName: "Ada", LastName: "Lovelace", Country: "US", Balance: 9007199254740993}
+	if len(customers) != 1 || customers[0] != want {
+		t.Errorf("Got %+v, want %+v", customers, want)
+	}
+
+	// The file as it was is kept, and the file is rewritten at version 2
+	if backup, err := os.ReadFile(result.Backup); err != nil || string(backup) != original {
+		t.Errorf("Backup %s holds %q, %v", result.Backup, backup, err)
+	}
+	if !strings.HasPrefix(filepath.Base(result.Backup), "customers.json.v0-") {
+		t.Errorf("Unexpected backup name %s", result.Backup)
+	}
+	data, _ := os.ReadFile(path)
+	var file struct {
+		SchemaVersion int                      `json:"schemaVersion"`
+		Data          []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil || file.SchemaVersion != 2 || file.Data[0]["name"] != nil {
+		t.Errorf("Unexpected migrated file %s: %v", data, err)
+	}
+	if !strings.Contains(string(data), `"balance": 9007199254740993`) {
+		t.Errorf("Numbers should be written back exactly: %s", data)
+	}
+
+	// A file at the latest version loads as it is
+	again, err := Load(path, customerMigrations, &customers)
+	if err != nil || again.Migrated() || again.From != 2 || again.Backup != "" {
+		t.Errorf("Expected nothing to run, got %+v, %v", again, err)
+	}
+	if rewritten, _ := os.ReadFile(path); string(rewritten) != string(data) {
+		t.Errorf("A current file should not be rewritten")
+	}
+}
+
+func TestLoadRunsOnlyMissingMigrations(t *testing.T) {
+	path := writeFile(t, `{"schemaVersion": 1, "data": [{"id": "cust-002", "firstName": "Grace", "lastName": "Hopper", "country": "GB"}]}`)
+
+	var customers []customer
+	result, err := Load(path, customerMigrations, &customers)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if result.From != 1 || len(result.Applied) != 1 || customers[0].Country != "GB" || customers[0].FirstName != "Grace" {
+		t.Errorf("Unexpected migration %+v of %+v", result, customers)
+	}
+
+	var unversioned []customer
+	if result, err := Load(writeFile(t, `[{"id": "cust-003"}]`), nil, &unversioned); err != nil || result.Migrated() || len(unversioned) != 1 {
+		t.Errorf("A file without migrations should load as it is: %+v, %v", result, err)
+	}
+}
+
+func TestLoadRefusesFilesItCannotMigrate(t *testing.T) {
+	failing := Set{{Version: 1, Name: "needs ids", Up: Records(func(record map[string]interface{}) error {
+		if record["id"] == nil {
+			return errors.New("no id")
+		}
+		return nil
+	})}}
+
+	tests := []struct {
+		name     string
+		contents string
+		set      Set
+		wantErr  string
+	}{
+		{"newer", `{"schemaVersion": 3, "data": []}`, customerMigrations, "is at schema version 3, newer than the 2 this service knows"},
+		{"failing migration", `[{"id": "a"}, {"name": "b"}]`, failing, "migration 1 (needs ids) of "},
+		{"not records", `{"id": "a"}`, failing, "data is not an array"},
+		{"no data", `{"schemaVersion": 1}`, customerMigrations, "has no data"},
+		{"bad version", `{"schemaVersion": "2", "data": []}`, customerMigrations, "schemaVersion is not a number"},
+		{"misnumbered", `[]`, Set{{Version: 2, Name: "skip", Up: Records(nil)}}, "migration 1 is numbered 2"},
+		{"no up", `[]`, Set{{Version: 1, Name: "empty"}}, "migration 1 (empty) has no Up"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.contents)
+			var v interface{}
+			if _, err := Load(path, tt.set, &v); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+			if data, _ := os.ReadFile(path); string(data) != tt.contents {
+				t.Errorf("A refused file should be left alone, got %s", data)
+			}
+			if backups, _ := filepath.Glob(path + ".*.bak"); len(backups) > 0 {
+				t.Errorf("A refused file should not be backed up: %v", backups)
+			}
+		})
+	}
+
+	var v interface{}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json"), nil, &v); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file error, got %v", err)
+	}
+}
+
+func TestReadAnyVersion(t *testing.T) {
+	var customers []customer
+	version, err := Read(writeFile(t, `{"schemaVersion": 7, "data": [{"id": "cust-004"}]}`), &customers)
+	if err != nil || version != 7 || customers[0].ID != "cust-004" {
+		t.Errorf("Read: got version %d, %+v, %v", version, customers, err)
+	}
+	if version, err := Read(writeFile(t, `[{"id": "cust-005"}]`), &customers); err != nil || version != 0 || customers[0].ID != "cust-005" {
+		t.Errorf("Read: got version %d, %+v, %v", version, customers, err)
+	}
+}
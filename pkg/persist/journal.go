@@ -0,0 +1,407 @@
+// Package persist makes the services' in-memory JSON repositories durable.
+// A Journal appends every change to a write-ahead log, periodically folds
+// the log into a snapshot, and on startup recovers the state from the last
+// snapshot plus the log written since.
+package persist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Operations recorded in the write-ahead log
+const (
+	OpPut    = "put"
+	OpDelete = "delete"
+	OpBatch  = "batch"
+)
+
+// Record is one write-ahead log entry: a record stored under, or removed
+// from, a key of a collection, or a batch of those committed together
+type Record struct {
+	Op         string          `json:"op"`
+	Collection string          `json:"collection"`
+	Key        string          `json:"key"`
+	Value      json.RawMessage `json:"value,omitempty"`
+	Records    []Record        `json:"records,omitempty"` // the changes of a batch
+}
+
+// Batch collects changes to several keys for Commit, which records them
+// all or none. The zero value is an empty batch.
+type Batch struct {
+	records []Record
+	err     error // first value that could not be encoded
+}
+
+// Put adds value, encoded as JSON now, as the new value of key. Changing
+// value later does not change the batch.
+func (b *Batch) Put(collection, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("failed to encode %s %s: %w", collection, key, err)
+		}
+		return
+	}
+	b.records = append(b.records, Record{Op: OpPut, Collection: collection, Key: key, Value: data})
+}
+
+// Delete adds the removal of key
+func (b *Batch) Delete(collection, key string) {
+	b.records = append(b.records, Record{Op: OpDelete, Collection: collection, Key: key})
+}
+
+// Len returns the number of changes in the batch
+func (b *Batch) Len() int {
+	return len(b.records)
+}
+
+// Journal records a repository's changes as collection/key records. It
+// keeps the latest value of every key it has seen so snapshots never read
+// the repository. A deleted key is kept as a tombstone so records loaded
+// from seed data stay deleted after a restart.
+//
+// A nil Journal ignores every call, so repositories can journal
+// unconditionally whether or not persistence is configured.
+type Journal struct {
+	snapshotPath string
+	walPath      string
+	logger       *logrus.Logger
+
+	mu     sync.Mutex
+	state  map[string]map[string]json.RawMessage // collection -> key -> value, nil when deleted
+	wal    *os.File
+	writes int // records appended since the last snapshot
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// Open recovers the journal named name from dir, creating dir if needed, and
+// flushes a snapshot every flushInterval until Close. A flushInterval of 0
+// only snapshots on Close.
+func Open(dir, name string, flushInterval time.Duration, logger *logrus.Logger) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create persistence directory: %w", err)
+	}
+
+	j := &Journal{
+		snapshotPath: filepath.Join(dir, name+".snapshot.json"),
+		walPath:      filepath.Join(dir, name+".wal"),
+		logger:       logger,
+		state:        make(map[string]map[string]json.RawMessage),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	if err := j.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	replayed, err := j.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(j.walPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	j.wal = wal
+	j.writes = replayed
+
+	logger.WithFields(logrus.Fields{
+		"snapshot": j.snapshotPath,
+		"replayed": replayed,
+	}).Info("Recovered persisted state")
+
+	go j.run(flushInterval)
+	return j, nil
+}
+
+// loadSnapshot reads the last snapshot, if there is one
+func (j *Journal) loadSnapshot() error {
+	data, err := os.ReadFile(j.snapshotPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if err := json.Unmarshal(data, &j.state); err != nil {
+		return fmt.Errorf("failed to decode snapshot %s: %w", j.snapshotPath, err)
+	}
+	// Tombstones are written as null
+	for _, collection := range j.state {
+		for key, value := range collection {
+			if string(value) == "null" {
+				collection[key] = nil
+			}
+		}
+	}
+	return nil
+}
+
+// replay applies the write-ahead log on top of the snapshot and returns how
+// many records it held. A torn last record, left by a crash mid-write, is
+// dropped and cut from the log; a bad record anywhere else is an error.
+func (j *Journal) replay() (int, error) {
+	file, err := os.OpenFile(j.walPath, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open write-ahead log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	count := 0
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return count, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read write-ahead log: %w", err)
+		}
+
+		var record Record
+		if decodeErr := json.Unmarshal(bytes.TrimSpace(line), &record); decodeErr != nil || err == io.EOF {
+			if _, peekErr := reader.Peek(1); peekErr != io.EOF {
+				return 0, fmt.Errorf("corrupt write-ahead log %s at offset %d", j.walPath, offset)
+			}
+			j.logger.WithField("offset", offset).Warn("Dropping incomplete record at the end of the write-ahead log")
+			if err := file.Truncate(offset); err != nil {
+				return 0, fmt.Errorf("failed to truncate write-ahead log: %w", err)
+			}
+			return count, nil
+		}
+
+		j.apply(record)
+		offset += int64(len(line))
+		count++
+	}
+}
+
+// apply updates the in-memory state with a record
+func (j *Journal) apply(record Record) {
+	if record.Op == OpBatch {
+		for _, change := range record.Records {
+			j.apply(change)
+		}
+		return
+	}
+	collection, ok := j.state[record.Collection]
+	if !ok {
+		collection = make(map[string]json.RawMessage)
+		j.state[record.Collection] = collection
+	}
+	if record.Op == OpDelete {
+		collection[record.Key] = nil
+		return
+	}
+	collection[record.Key] = record.Value
+}
+
+// Put records value, encoded as JSON, as the current value of key
+func (j *Journal) Put(collection, key string, value interface{}) error {
+	if j == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s %s: %w", collection, key, err)
+	}
+	return j.append(Record{Op: OpPut, Collection: collection, Key: key, Value: data})
+}
+
+// Delete records that key was removed
+func (j *Journal) Delete(collection, key string) error {
+	if j == nil {
+		return nil
+	}
+	return j.append(Record{Op: OpDelete, Collection: collection, Key: key})
+}
+
+// Commit records the changes of batch as one write-ahead log entry, so
+// after a crash either all of them are recovered or none are. An empty
+// batch records nothing, and a batch of one change is recorded as that
+// change.
+func (j *Journal) Commit(batch *Batch) error {
+	if j == nil {
+		return nil
+	}
+	if batch.err != nil {
+		return batch.err
+	}
+	switch len(batch.records) {
+	case 0:
+		return nil
+	case 1:
+		return j.append(batch.records[0])
+	}
+	return j.append(Record{Op: OpBatch, Records: batch.records})
+}
+
+// append writes a record to the log and syncs it before applying it, so a
+// change is never reported as saved unless it survives a crash
+func (j *Journal) append(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal record: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.wal == nil {
+		return fmt.Errorf("failed to write journal record: journal is closed")
+	}
+	if _, err := j.wal.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal record: %w", err)
+	}
+	if err := j.wal.Sync(); err != nil {
+		return fmt.Errorf("failed to sync write-ahead log: %w", err)
+	}
+	j.apply(record)
+	j.writes++
+	return nil
+}
+
+// Collection returns the recovered records of a collection by key. A nil
+// value means the key was deleted.
+func (j *Journal) Collection(collection string) map[string]json.RawMessage {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	records := make(map[string]json.RawMessage, len(j.state[collection]))
+	for key, value := range j.state[collection] {
+		records[key] = value
+	}
+	return records
+}
+
+// Restore decodes the recovered records of a collection, calling put for
+// each stored value and remove for each deleted key
+func Restore[T any](j *Journal, collection string, put func(key string, value T), remove func(key string)) error {
+	for key, data := range j.Collection(collection) {
+		if data == nil {
+			remove(key)
+			continue
+		}
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return fmt.Errorf("failed to decode %s %s: %w", collection, key, err)
+		}
+		put(key, value)
+	}
+	return nil
+}
+
+// Flush writes the current state to the snapshot and empties the log. The
+// snapshot is written to a temporary file and renamed into place, so a
+// crash leaves either the old snapshot and log or the new snapshot.
+func (j *Journal) Flush() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.wal == nil || j.writes == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(j.state)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	tmpPath := j.snapshotPath + ".tmp"
+	if err := writeSynced(tmpPath, data); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmpPath, j.snapshotPath); err != nil {
+		return fmt.Errorf("failed to replace snapshot: %w", err)
+	}
+
+	if err := j.wal.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate write-ahead log: %w", err)
+	}
+	j.logger.WithField("records", j.writes).Debug("Flushed snapshot")
+	j.writes = 0
+	return nil
+}
+
+// writeSynced writes data to path and syncs it to disk
+func writeSynced(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// run flushes every interval until Close
+func (j *Journal) run(interval time.Duration) {
+	defer close(j.done)
+	if interval <= 0 {
+		<-j.stop
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := j.Flush(); err != nil {
+				j.logger.WithError(err).Error("Failed to flush snapshot")
+			}
+		case <-j.stop:
+			return
+		}
+	}
+}
+
+// Close stops the periodic flush, writes a final snapshot and closes the
+// log. Changes made after Close fail.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	var err error
+	j.once.Do(func() {
+		close(j.stop)
+		<-j.done
+		err = j.Flush()
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if closeErr := j.wal.Close(); err == nil {
+			err = closeErr
+		}
+		j.wal = nil
+	})
+	return err
+}
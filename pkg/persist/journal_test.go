@@ -0,0 +1,193 @@
+package persist
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type customer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func testLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// restore reads the customers collection back as a map
+func restore(t *testing.T, j *Journal) (map[string]customer, []string) {
+	t.Helper()
+	stored := map[string]customer{}
+	var deleted []string
+	err := Restore(j, "customers", func(key string, value customer) {
+		stored[key] = value
+	}, func(key string) {
+		deleted = append(deleted, key)
+	})
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	return stored, deleted
+}
+
+func TestJournalRecoversSnapshotAndLog(t *testing.T) {
+	dir := t.TempDir()
+
+	j, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if err := j.Put("customers", "cust-001", customer{ID: "cust-001", Name: "Ada"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := j.Put("customers", "cust-002", customer{ID: "cust-002", Name: "Grace"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := j.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "svc.wal")); err != nil || info.Size() != 0 {
+		t.Errorf("Flush should empty the log: %v, %v", info, err)
+	}
+
+	// Changes after the snapshot only reach the log, as after a crash
+	j.Put("customers", "cust-001", customer{ID: "cust-001", Name: "Ada Lovelace"})
+	j.Delete("customers", "cust-002")
+	j.Delete("customers", "cust-seed")
+	j.wal.Close()
+
+	recovered, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	stored, deleted := restore(t, recovered)
+	if len(stored) != 1 || stored["cust-001"].Name != "Ada Lovelace" {
+		t.Errorf("Unexpected recovered customers: %+v", stored)
+	}
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 tombstones, got %v", deleted)
+	}
+
+	// Tombstones survive a snapshot too
+	if err := recovered.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	again, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Reopen after snapshot failed: %v", err)
+	}
+	defer again.Close()
+	if _, deleted := restore(t, again); len(deleted) != 2 {
+		t.Errorf("Tombstones lost by snapshot: %v", deleted)
+	}
+}
+
+func TestJournalDropsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	j.Put("customers", "cust-001", customer{ID: "cust-001", Name: "Ada"})
+	j.wal.Write([]byte(`{"op":"put","collection":"customers","key":"cust-002","val`))
+	j.wal.Close()
+
+	recovered, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Torn record should be dropped, got %v", err)
+	}
+	if stored, _ := restore(t, recovered); len(stored) != 1 {
+		t.Errorf("Unexpected recovered customers: %+v", stored)
+	}
+	// New records go after the last complete one
+	recovered.Put("customers", "cust-003", customer{ID: "cust-003"})
+	recovered.wal.Close()
+
+	reopened, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	defer reopened.Close()
+	if stored, _ := restore(t, reopened); len(stored) != 2 {
+		t.Errorf("Unexpected customers after torn record: %+v", stored)
+	}
+}
+
+func TestJournalRejectsCorruptLog(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := "not json\n" + `{"op":"put","collection":"customers","key":"cust-001","value":{}}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "svc.wal"), []byte(corrupt), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(dir, "svc", 0, testLogger()); err == nil {
+		t.Error("Expected a corrupt record before the end of the log to fail recovery")
+	}
+}
+
+func TestJournalCommitsBatchesWhole(t *testing.T) {
+	dir := t.TempDir()
+	j, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	j.Put("customers", "cust-001", customer{ID: "cust-001", Name: "Ada"})
+
+	var batch Batch
+	moved := customer{ID: "cust-002", Name: "Grace"}
+	batch.Put("customers", "cust-002", moved)
+	batch.Delete("customers", "cust-001")
+	moved.Name = "changed after Put"
+	if err := j.Commit(&batch); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	// A batch cut short by a crash is dropped whole
+	j.wal.Write([]byte(`{"op":"batch","records":[{"op":"put","collection":"customers","key":"cust-003","value":{}},{"op":"del`))
+	j.wal.Close()
+
+	recovered, err := Open(dir, "svc", 0, testLogger())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer recovered.Close()
+	stored, deleted := restore(t, recovered)
+	if len(stored) != 1 || stored["cust-002"].Name != "Grace" || len(deleted) != 1 || deleted[0] != "cust-001" {
+		t.Errorf("Unexpected recovered customers %+v, deleted %v", stored, deleted)
+	}
+
+	var unencodable Batch
+	unencodable.Put("customers", "cust-004", func() {})
+	unencodable.Put("customers", "cust-005", customer{ID: "cust-005"})
+	if err := recovered.Commit(&unencodable); err == nil {
+		t.Error("Expected a batch with a value that cannot be encoded to be refused")
+	}
+	if _, ok := recovered.Collection("customers")["cust-005"]; ok {
+		t.Error("A refused batch should record nothing")
+	}
+}
+
+func TestNilJournal(t *testing.T) {
+	var j *Journal
+	if err := j.Put("customers", "cust-001", customer{}); err != nil {
+		t.Errorf("Put on nil journal: %v", err)
+	}
+	if err := j.Delete("customers", "cust-001"); err != nil {
+		t.Errorf("Delete on nil journal: %v", err)
+	}
+	var batch Batch
+	batch.Put("customers", "cust-001", customer{})
+	if err := j.Commit(&batch); err != nil {
+		t.Errorf("Commit on nil journal: %v", err)
+	}
+	if stored, deleted := restore(t, j); len(stored) != 0 || len(deleted) != 0 {
+		t.Errorf("Nil journal restored %v, %v", stored, deleted)
+	}
+	if err := j.Close(); err != nil {
+		t.Errorf("Close on nil journal: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Package retention decides when closed records leave a service's live
+// store for its archive. Each entity type, such as claims or payments, has
+// a rule: records in one of the rule's closed statuses are archived once
+// they have been closed for the rule's number of years. The rules are
+// configuration, loaded from retention.json, so the schedule can follow the
+// law without a release.
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Entity types with a retention rule
+const (
+	Claims   = "claims"
+	Payments = "payments"
+)
+
+// Rule is when records of one entity type are archived
+type Rule struct {
+	ArchiveAfterYears int      `json:"archiveAfterYears"`
+	Statuses          []string `json:"statuses"` // closed statuses the years count from
+}
+
+// Policy is the retention rule of each entity type
+type Policy struct {
+	Version string          `json:"version"`
+	Rules   map[string]Rule `json:"rules"` // by entity type
+}
+
+// Default returns the policy used when none is configured: no rules, so
+// every record is kept live
+func Default() *Policy {
+	return &Policy{Version: "none", Rules: map[string]Rule{}}
+}
+
+// Load reads and validates a retention policy file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid retention policy in %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid retention policy in %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// validate checks every rule has a period and the statuses it applies to
+func (p *Policy) validate() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("no rules")
+	}
+	for entity, rule := range p.Rules {
+		if rule.ArchiveAfterYears <= 0 {
+			return fmt.Errorf("%s rule archiveAfterYears must be greater than zero", entity)
+		}
+		if len(rule.Statuses) == 0 {
+			return fmt.Errorf("%s rule has no statuses", entity)
+		}
+		for _, status := range rule.Statuses {
+			if status == "" {
+				return fmt.Errorf("%s rule has an empty status", entity)
+			}
+		}
+	}
+	return nil
+}
+
+// Rule returns the rule of an entity type, and whether there is one
+func (p *Policy) Rule(entity string) (Rule, bool) {
+	rule, ok := p.Rules[entity]
+	return rule, ok
+}
+
+// Due reports whether a record that entered status at closedAt is archived
+// at now: the status is one of the rule's and the record has been closed
+// for at least ArchiveAfterYears calendar years
+func (r Rule) Due(status string, closedAt, now time.Time) bool {
+	if !r.covers(status) || closedAt.IsZero() {
+		return false
+	}
+	return !closedAt.After(r.Cutoff(now))
+}
+
+// Cutoff returns the latest close time archived at now
+func (r Rule) Cutoff(now time.Time) time.Time {
+	return now.AddDate(-r.ArchiveAfterYears, 0, 0)
+}
+
+func (r Rule) covers(status string) bool {
+	for _, s := range r.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package retention
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDueSeedPolicy(t *testing.T) {
+	policy, err := Load(filepath.Join("..", "..", "data", "seed", "retention.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		entity   string
+		status   string
+		closedAt time.Time
+		want     bool
+	}{
+		{Claims, "approved", time.Date(2019, 10, 14, 12, 0, 0, 0, time.UTC), true},
+		{Claims, "rejected", time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), true},
+		{Claims, "approved", time.Date(2019, 10, 14, 12, 0, 1, 0, time.UTC), false},
+		{Claims, "under_review", time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{Claims, "approved", time.Time{}, false},
+		{Payments, "refunded", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{Payments, "pending", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		rule, ok := policy.Rule(tt.entity)
+		if !ok {
+			t.Fatalf("Expected a %s rule in the seed policy", tt.entity)
+		}
+		if got := rule.Due(tt.status, tt.closedAt, now); got != tt.want {
+			t.Errorf("%s Due(%q, %s) = %v, want %v", tt.entity, tt.status, tt.closedAt, got, tt.want)
+		}
+	}
+
+	if _, ok := Default().Rule(Claims); ok {
+		t.Error("Expected the default policy to keep every record live")
+	}
+}
+
+func TestLoadRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no rules", `{"rules": {}}`, "no rules"},
+		{"no period", `{"rules": {"claims": {"statuses": ["approved"]}}}`, "claims rule archiveAfterYears must be greater than zero"},
+		{"no statuses", `{"rules": {"claims": {"archiveAfterYears": 7}}}`, "claims rule has no statuses"},
+		{"empty status", `{"rules": {"payments": {"archiveAfterYears": 7, "statuses": [""]}}}`, "payments rule has an empty status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "retention.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write policy: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
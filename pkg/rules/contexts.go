@@ -0,0 +1,86 @@
+package rules
+
+// Domains of the decisions rules make
+const (
+	DomainClaim  = "claim"  // new claims, evaluated by claims-service
+	DomainQuote  = "quote"  // quote requests, evaluated by pricing-engine
+	DomainPolicy = "policy" // new policies, evaluated by policy-service
+)
+
+// Actions a rule takes when it matches
+const (
+	ActionApprove = "approve" // claim: approve without an adjuster
+	ActionReview  = "review"  // claim: send to an adjuster
+	ActionReject  = "reject"  // claim: reject with a code; policy: refuse to issue
+	ActionDecline = "decline" // quote: refuse to quote
+)
+
+// Context is the facts a domain's rules are evaluated against. Each
+// domain has its own context type, so expressions are type-checked against
+// its fields when rules load.
+type Context interface {
+	Domain() string
+}
+
+// ClaimContext is what claim rules read of a claim as it is filed
+type ClaimContext struct {
+	Type         string  `json:"type" expr:"type"`
+	SubType      string  `json:"subType" expr:"subType"`
+	Amount       float64 `json:"amount" expr:"amount"`
+	Catastrophe  bool    `json:"catastrophe" expr:"catastrophe"` // tagged to a catastrophe event
+	PolicyType   string  `json:"policyType" expr:"policyType"`   // empty when the policy is unknown
+	PolicyStatus string  `json:"policyStatus" expr:"policyStatus"`
+	CustomerID   string  `json:"customerId" expr:"customerId"`
+	RecentClaims int     `json:"recentClaims" expr:"recentClaims"` // claims filed in the year before; counted only when a rule reads it
+}
+
+// Domain implements Context
+func (ClaimContext) Domain() string { return DomainClaim }
+
+// QuoteContext is what quote rules read of a quote request. Vehicle and
+// property fields are zero on quotes without them.
+type QuoteContext struct {
+	PolicyType       string `json:"policyType" expr:"policyType"`
+	CoverageAmount   int    `json:"coverageAmount" expr:"coverageAmount"`
+	CustomerAge      int    `json:"customerAge" expr:"customerAge"`
+	RiskScore        int    `json:"riskScore" expr:"riskScore"`
+	ClaimsHistory    int    `json:"claimsHistory" expr:"claimsHistory"`
+	LoyaltyYears     int    `json:"loyaltyYears" expr:"loyaltyYears"`
+	State            string `json:"state" expr:"state"`
+	ZipCode          string `json:"zipCode" expr:"zipCode"`
+	VehicleYear      int    `json:"vehicleYear" expr:"vehicleYear"`
+	AnnualMileage    int    `json:"annualMileage" expr:"annualMileage"`
+	YearBuilt        int    `json:"yearBuilt" expr:"yearBuilt"`
+	ConstructionType string `json:"constructionType" expr:"constructionType"`
+	ProtectionClass  int    `json:"protectionClass" expr:"protectionClass"`
+}
+
+// Domain implements Context
+func (QuoteContext) Domain() string { return DomainQuote }
+
+// PolicyContext is what policy rules read of a policy as it is created
+type PolicyContext struct {
+	Type       string  `json:"type" expr:"type"`
+	Premium    float64 `json:"premium" expr:"premium"`
+	Coverage   float64 `json:"coverage" expr:"coverage"`
+	Deductible float64 `json:"deductible" expr:"deductible"`
+	TermDays   int     `json:"termDays" expr:"termDays"`   // from start to end date
+	FromQuote  bool    `json:"fromQuote" expr:"fromQuote"` // bound from a pricing-engine quote
+	CustomerID string  `json:"customerId" expr:"customerId"`
+	AgentID    string  `json:"agentId" expr:"agentId"` // empty for direct business
+}
+
+// Domain implements Context
+func (PolicyContext) Domain() string { return DomainPolicy }
+
+// domain is how rules of one domain are checked
+type domain struct {
+	env     Context  // zero context the expressions are type-checked against
+	actions []string // actions its rules may take
+}
+
+var domains = map[string]domain{
+	DomainClaim:  {env: ClaimContext{}, actions: []string{ActionApprove, ActionReview, ActionReject}},
+	DomainQuote:  {env: QuoteContext{}, actions: []string{ActionDecline}},
+	DomainPolicy: {env: PolicyContext{}, actions: []string{ActionReject}},
+}
@@ -0,0 +1,10 @@
+module github.com/CB-InsuranceStack/InsuranceStack/pkg/rules
+
+go 1.21
+
+require (
+	github.com/expr-lang/expr v1.16.9
+	github.com/sirupsen/logrus v1.9.3
+)
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -0,0 +1,238 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Status is the current rules and how each has fared, as served by
+// GET /admin/rules
+type Status struct {
+	Version  string       `json:"version"`
+	Source   string       `json:"source"` // the rules file, or "api" after PUT
+	LoadedAt *time.Time   `json:"loadedAt,omitempty"`
+	Rules    []RuleStatus `json:"rules"`
+}
+
+// RuleStatus is a rule and its counts since the service started
+type RuleStatus struct {
+	Rule
+	Evaluations       uint64  `json:"evaluations"`
+	Matches           uint64  `json:"matches"`
+	Errors            uint64  `json:"errors"`
+	EvaluationSeconds float64 `json:"evaluationSeconds"` // total time spent evaluating it
+}
+
+// Status returns the current rules, in evaluation order by domain
+func (e *Engine) Status() Status {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	status := Status{Version: e.version, Source: e.source, Rules: []RuleStatus{}}
+	if !e.loadedAt.IsZero() {
+		loadedAt := e.loadedAt
+		status.LoadedAt = &loadedAt
+	}
+	for _, domain := range e.domains() {
+		for _, rule := range e.rules[domain] {
+			// Evaluations are counted before their result, so read them
+			// last to never count fewer evaluations than results
+			matches, errors := rule.stats.matches.Load(), rule.stats.errors.Load()
+			status.Rules = append(status.Rules, RuleStatus{
+				Rule:              rule.Rule,
+				Evaluations:       rule.stats.evaluations.Load(),
+				Matches:           matches,
+				Errors:            errors,
+				EvaluationSeconds: time.Duration(rule.stats.nanos.Load()).Seconds(),
+			})
+		}
+	}
+	return status
+}
+
+// domains are the domains with rules, sorted; the caller holds e.mu
+func (e *Engine) domains() []string {
+	names := make([]string, 0, len(e.rules))
+	for name := range e.rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Handler serves GET /admin/rules with the current rules and their counts,
+// and PUT to replace them. A replaced set lasts until the rules file next
+// changes. Guard it with the service's admin role check.
+func (e *Engine) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			var set Set
+			if err := json.NewDecoder(r.Body).Decode(&set); err != nil {
+				respondError(w, http.StatusBadRequest, "Invalid request body")
+				return
+			}
+			if err := e.Replace(set); err != nil {
+				respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		respondJSON(w, http.StatusOK, e.Status())
+	})
+}
+
+// TestRequest is the body of POST /admin/rules/test: facts of a domain and,
+// optionally, draft rules to evaluate instead of the current ones
+type TestRequest struct {
+	Domain  string          `json:"domain"`
+	Context json.RawMessage `json:"context"`
+	Rules   []Rule          `json:"rules,omitempty"` // domain defaults to the request's
+}
+
+// TestResult is what each rule made of the facts, and the rule that would
+// decide
+type TestResult struct {
+	Domain  string       `json:"domain"`
+	Match   *Match       `json:"match"` // null when no rule matches
+	Results []RuleResult `json:"results"`
+}
+
+// RuleResult is one rule's verdict on the facts
+type RuleResult struct {
+	Rule    string `json:"rule"`
+	Matched bool   `json:"matched"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Test evaluates every rule of the request's domain against its facts,
+// not only up to the first match, and without counting the evaluations
+func (e *Engine) Test(req TestRequest) (*TestResult, error) {
+	if !e.serves(req.Domain) {
+		return nil, fmt.Errorf("this service evaluates %v rules, not %q", e.cfg.Domains, req.Domain)
+	}
+	facts, err := decodeContext(req.Domain, req.Context)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s context: %w", req.Domain, err)
+	}
+
+	var rules []*compiled
+	if req.Rules == nil {
+		e.mu.RLock()
+		rules = e.rules[req.Domain]
+		e.mu.RUnlock()
+	} else {
+		for i := range req.Rules {
+			if req.Rules[i].Domain == "" {
+				req.Rules[i].Domain = req.Domain
+			}
+		}
+		byDomain, err := e.compile(Set{Rules: req.Rules}, true)
+		if err != nil {
+			return nil, err
+		}
+		rules = byDomain[req.Domain]
+		if len(rules) != len(req.Rules) {
+			return nil, fmt.Errorf("draft rules must all be %s rules", req.Domain)
+		}
+	}
+
+	result := &TestResult{Domain: req.Domain, Results: make([]RuleResult, 0, len(rules))}
+	for _, rule := range rules {
+		matched, err := rule.eval(facts)
+		outcome := RuleResult{Rule: rule.Name, Matched: matched}
+		if err != nil {
+			outcome.Error = err.Error()
+		}
+		if matched && result.Match == nil {
+			match := rule.match()
+			result.Match = &match
+		}
+		result.Results = append(result.Results, outcome)
+	}
+	return result, nil
+}
+
+// TestHandler serves POST /admin/rules/test. Guard it with the service's
+// admin role check.
+func (e *Engine) TestHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req TestRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		result, err := e.Test(req)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondJSON(w, http.StatusOK, result)
+	})
+}
+
+// decodeContext reads facts of the domain, refusing fields its context
+// does not have
+func decodeContext(domain string, data json.RawMessage) (Context, error) {
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	switch domain {
+	case DomainClaim:
+		var facts ClaimContext
+		err := decoder.Decode(&facts)
+		return facts, err
+	case DomainQuote:
+		var facts QuoteContext
+		err := decoder.Decode(&facts)
+		return facts, err
+	default:
+		var facts PolicyContext
+		err := decoder.Decode(&facts)
+		return facts, err
+	}
+}
+
+// WritePrometheus writes each rule's counts as
+// business_rule_evaluations_total and business_rule_evaluation_seconds_total
+// in the Prometheus text exposition format
+func (e *Engine) WritePrometheus(w io.Writer) {
+	rules := e.Status().Rules
+
+	fmt.Fprint(w, "# HELP business_rule_evaluations_total Business rule evaluations by domain, rule and result.\n")
+	fmt.Fprint(w, "# TYPE business_rule_evaluations_total counter\n")
+	for _, rule := range rules {
+		labels := fmt.Sprintf(`domain="%s",rule="%s"`, escapeLabel(rule.Domain), escapeLabel(rule.Name))
+		fmt.Fprintf(w, "business_rule_evaluations_total{%s,result=\"match\"} %d\n", labels, rule.Matches)
+		fmt.Fprintf(w, "business_rule_evaluations_total{%s,result=\"miss\"} %d\n", labels, rule.Evaluations-rule.Matches-rule.Errors)
+		fmt.Fprintf(w, "business_rule_evaluations_total{%s,result=\"error\"} %d\n", labels, rule.Errors)
+	}
+	fmt.Fprint(w, "# HELP business_rule_evaluation_seconds_total Time spent evaluating business rules, by domain and rule.\n")
+	fmt.Fprint(w, "# TYPE business_rule_evaluation_seconds_total counter\n")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "business_rule_evaluation_seconds_total{domain=\"%s\",rule=\"%s\"} %s\n", escapeLabel(rule.Domain), escapeLabel(rule.Name), strconv.FormatFloat(rule.EvaluationSeconds, 'g', -1, 64))
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func respondJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func respondError(w http.ResponseWriter, status int, message string) {
+	respondJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,361 @@
+// Package rules evaluates business rules: expressions over a typed
+// context per domain (claim, quote, policy) that decide what happens to a
+// claim, a quote request or a new policy. Rules are configuration, loaded
+// from a rules file that is reloaded when it changes or replaced through
+// the admin API, so limits can change without a release. Expressions use
+// the expr language and are type-checked against their domain's context
+// when they load; a rule set that does not check is refused whole.
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+	"github.com/sirupsen/logrus"
+)
+
+// Path is where services serve the rules endpoint, and TestPath the
+// endpoint that evaluates rules without acting on them
+const (
+	Path     = "/admin/rules"
+	TestPath = "/admin/rules/test"
+)
+
+// Set is a rules file, or the body of PUT /admin/rules
+type Set struct {
+	Version string `json:"version"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule is an action and the expression that triggers it. Rules of a
+// domain are evaluated in order and the first whose expression is true
+// decides.
+type Rule struct {
+	Name        string `json:"name"`
+	Domain      string `json:"domain"`
+	Description string `json:"description,omitempty"`
+	When        string `json:"when"` // boolean expression over the domain's context
+	Action      string `json:"action"`
+	Reason      string `json:"reason,omitempty"` // given to the caller; "rule <name>" when empty
+	Code        string `json:"code,omitempty"`   // machine-readable reason, such as a claim rejection code
+}
+
+// Match is the rule that decided
+type Match struct {
+	Rule   string `json:"rule"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	Code   string `json:"code,omitempty"`
+}
+
+// Config is where a service's rules come from and which it evaluates
+type Config struct {
+	// Path is the rules file; empty starts without rules, set through
+	// the admin API
+	Path string
+	// Domains are the domains the service evaluates. Rules of other
+	// domains in the file are checked but not kept.
+	Domains []string
+	// Validate checks each rule of the service's domains beyond its
+	// expression and action, such as a claim rejection code; nil for none
+	Validate func(Rule) error
+}
+
+// Engine holds a service's rules and evaluates them. It is safe for
+// concurrent use; the zero value is not, use New.
+type Engine struct {
+	cfg    Config
+	logger *logrus.Logger
+
+	mu       sync.RWMutex
+	version  string
+	source   string // the file the rules came from, or "api"
+	loadedAt time.Time
+	rules    map[string][]*compiled // by domain, in evaluation order
+	stats    map[string]*stats      // by domain and rule name, kept across reloads
+}
+
+// compiled is a rule ready to evaluate
+type compiled struct {
+	Rule
+	program *vm.Program
+	reads   map[string]bool // context fields the expression reads
+	stats   *stats
+}
+
+// stats counts a rule's evaluations
+type stats struct {
+	evaluations atomic.Uint64
+	matches     atomic.Uint64
+	errors      atomic.Uint64
+	nanos       atomic.Int64
+}
+
+// New creates an engine without rules; Load reads the rules file
+func New(cfg Config, logger *logrus.Logger) *Engine {
+	return &Engine{
+		cfg:    cfg,
+		logger: logger,
+		rules:  make(map[string][]*compiled),
+		stats:  make(map[string]*stats),
+	}
+}
+
+// Load reads the rules file, replacing the rules when every rule checks.
+// A file that cannot be read is returned as is, so callers can tell a
+// missing file apart; otherwise the current rules are kept on error.
+func (e *Engine) Load() error {
+	data, err := os.ReadFile(e.cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	var set Set
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("invalid business rules in %s: %w", e.cfg.Path, err)
+	}
+	byDomain, err := e.compile(set, false)
+	if err != nil {
+		return fmt.Errorf("invalid business rules in %s: %w", e.cfg.Path, err)
+	}
+	e.install(set.Version, e.cfg.Path, byDomain)
+	return nil
+}
+
+// Replace installs set, as the admin API does. Unlike the file, set may
+// only hold rules of the service's domains.
+func (e *Engine) Replace(set Set) error {
+	byDomain, err := e.compile(set, true)
+	if err != nil {
+		return err
+	}
+	e.install(set.Version, "api", byDomain)
+	e.logger.WithFields(logrus.Fields{
+		"version": set.Version,
+		"rules":   len(set.Rules),
+	}).Warn("Business rules replaced through the admin API")
+	return nil
+}
+
+// Watch reloads the rules file whenever it changes, until ctx is done. A
+// file that fails to load is logged and the current rules kept. onReload,
+// when not nil, is called after each reload.
+func (e *Engine) Watch(ctx context.Context, interval time.Duration, onReload func()) {
+	last := fingerprint(e.cfg.Path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := fingerprint(e.cfg.Path)
+			if current == last {
+				continue
+			}
+			last = current
+			if err := e.Load(); err != nil {
+				e.logger.WithError(err).Warn("Failed to reload business rules, keeping current rules")
+				continue
+			}
+			if onReload != nil {
+				onReload()
+			}
+		}
+	}
+}
+
+// fingerprint identifies the rules file on disk by size and modification
+// time; empty when it is missing
+func fingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// Version is the version of the current rules
+func (e *Engine) Version() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.version
+}
+
+// Len is how many rules the engine evaluates
+func (e *Engine) Len() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	n := 0
+	for _, rules := range e.rules {
+		n += len(rules)
+	}
+	return n
+}
+
+// Reads reports whether any rule of the domain reads field, so callers can
+// skip working out facts no rule reads
+func (e *Engine) Reads(domain, field string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, rule := range e.rules[domain] {
+		if rule.reads[field] {
+			return true
+		}
+	}
+	return false
+}
+
+// First evaluates the rules of facts' domain in order and returns the first
+// that matches. A rule whose expression fails is logged, counted as an
+// error and passed over.
+func (e *Engine) First(facts Context) (Match, bool) {
+	e.mu.RLock()
+	rules := e.rules[facts.Domain()]
+	e.mu.RUnlock()
+
+	for _, rule := range rules {
+		start := time.Now()
+		matched, err := rule.eval(facts)
+		rule.stats.nanos.Add(int64(time.Since(start)))
+		rule.stats.evaluations.Add(1)
+		if err != nil {
+			rule.stats.errors.Add(1)
+			e.logger.WithError(err).WithFields(logrus.Fields{
+				"domain": rule.Domain,
+				"rule":   rule.Name,
+			}).Warn("Business rule failed to evaluate, skipping it")
+			continue
+		}
+		if matched {
+			rule.stats.matches.Add(1)
+			return rule.match(), true
+		}
+	}
+	return Match{}, false
+}
+
+func (r *compiled) eval(facts Context) (bool, error) {
+	out, err := expr.Run(r.program, facts)
+	if err != nil {
+		return false, err
+	}
+	matched, _ := out.(bool)
+	return matched, nil
+}
+
+func (r *compiled) match() Match {
+	reason := r.Reason
+	if reason == "" {
+		reason = "rule " + r.Name
+	}
+	return Match{Rule: r.Name, Action: r.Action, Reason: reason, Code: r.Code}
+}
+
+// install swaps in compiled rules, carrying over the counts of rules that
+// kept their name
+func (e *Engine) install(version, source string, byDomain map[string][]*compiled) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, rules := range byDomain {
+		for _, rule := range rules {
+			key := rule.Domain + "/" + rule.Name
+			if e.stats[key] == nil {
+				e.stats[key] = &stats{}
+			}
+			rule.stats = e.stats[key]
+		}
+	}
+	e.version = version
+	e.source = source
+	e.loadedAt = time.Now().UTC()
+	e.rules = byDomain
+}
+
+// compile checks every rule of set and compiles those of the service's
+// domains. strict refuses rules of other domains instead of dropping them.
+func (e *Engine) compile(set Set, strict bool) (map[string][]*compiled, error) {
+	byDomain := make(map[string][]*compiled)
+	seen := make(map[string]bool, len(set.Rules))
+	for i, rule := range set.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if seen[rule.Name] {
+			return nil, fmt.Errorf("duplicate rule %s", rule.Name)
+		}
+		seen[rule.Name] = true
+
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if !e.serves(rule.Domain) {
+			if strict {
+				return nil, fmt.Errorf("rule %s is a %s rule, this service evaluates %v", rule.Name, rule.Domain, e.cfg.Domains)
+			}
+			continue
+		}
+		if e.cfg.Validate != nil {
+			if err := e.cfg.Validate(rule); err != nil {
+				return nil, fmt.Errorf("rule %s %w", rule.Name, err)
+			}
+		}
+		byDomain[rule.Domain] = append(byDomain[rule.Domain], c)
+	}
+	return byDomain, nil
+}
+
+// compileRule checks a rule's domain and action and type-checks its
+// expression against the domain's context
+func compileRule(rule Rule) (*compiled, error) {
+	d, ok := domains[rule.Domain]
+	if !ok {
+		return nil, fmt.Errorf("rule %s has unknown domain %q (want %s, %s or %s)", rule.Name, rule.Domain, DomainClaim, DomainQuote, DomainPolicy)
+	}
+	if !contains(d.actions, rule.Action) {
+		return nil, fmt.Errorf("rule %s has unknown action %q for %s rules (want %v)", rule.Name, rule.Action, rule.Domain, d.actions)
+	}
+	if rule.When == "" {
+		return nil, fmt.Errorf("rule %s has no expression", rule.Name)
+	}
+	program, err := expr.Compile(rule.When, expr.Env(d.env), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+	}
+
+	reads := make(map[string]bool)
+	node := program.Node()
+	ast.Walk(&node, identifiers(reads))
+	return &compiled{Rule: rule, program: program, reads: reads, stats: &stats{}}, nil
+}
+
+// identifiers collects the names an expression reads
+type identifiers map[string]bool
+
+func (ids identifiers) Visit(node *ast.Node) {
+	if n, ok := (*node).(*ast.IdentifierNode); ok {
+		ids[n.Value] = true
+	}
+}
+
+func (e *Engine) serves(domain string) bool {
+	return contains(e.cfg.Domains, domain)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,282 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var allDomains = []string{DomainClaim, DomainQuote, DomainPolicy}
+
+func newTestEngine(cfg Config) *Engine {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return New(cfg, logger)
+}
+
+func writeRules(t *testing.T, path, rules string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+}
+
+func TestFirstSeedRules(t *testing.T) {
+	engine := newTestEngine(Config{
+		Path:    filepath.Join("..", "..", "data", "seed", "business-rules.json"),
+		Domains: allDomains,
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		facts Context
+		rule  string // empty when no rule matches
+	}{
+		{"lapsed policy", ClaimContext{Type: "damage", Amount: 200, PolicyType: "auto", PolicyStatus: "lapsed"}, "claim-lapsed-policy"},
+		{"windshield", ClaimContext{Type: "damage", SubType: "windshield", Amount: 1200, PolicyType: "auto", PolicyStatus: "active"}, "claim-auto-glass"},
+		{"frequent claimant", ClaimContext{Type: "damage", Amount: 200, RecentClaims: 3}, "claim-frequent-claimant"},
+		{"large claim", ClaimContext{Type: "damage", Amount: 25000}, ""},
+		{"life at 80", QuoteContext{PolicyType: "life", CustomerAge: 80, CoverageAmount: 100000}, "quote-life-entry-age"},
+		{"life at 79", QuoteContext{PolicyType: "life", CustomerAge: 79, CoverageAmount: 100000}, ""},
+		{"unprotected frame home", QuoteContext{PolicyType: "home", ConstructionType: "frame", ProtectionClass: 10}, "quote-unprotected-frame-home"},
+		{"deductible above coverage", PolicyContext{Type: "auto", Coverage: 500, Deductible: 1000, TermDays: 365}, "policy-deductible-above-coverage"},
+		{"five year term", PolicyContext{Type: "life", Coverage: 50000, TermDays: 1827}, "policy-term-too-long"},
+		{"one year term", PolicyContext{Type: "auto", Coverage: 50000, Deductible: 500, TermDays: 365}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := engine.First(tt.facts)
+			if ok != (tt.rule != "") || match.Rule != tt.rule {
+				t.Fatalf("First = %+v, %v; want rule %q", match, ok, tt.rule)
+			}
+			if ok && match.Reason == "" {
+				t.Errorf("Expected the seed rule %s to give a reason", tt.rule)
+			}
+		})
+	}
+
+	if !engine.Reads(DomainClaim, "recentClaims") || engine.Reads(DomainQuote, "recentClaims") {
+		t.Error("Expected only the claim rules to read recentClaims")
+	}
+}
+
+func TestLoadRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no name", `{"rules": [{"domain": "quote", "when": "true", "action": "decline"}]}`, "rule 1 has no name"},
+		{"duplicate", `{"rules": [{"name": "a", "domain": "quote", "when": "true", "action": "decline"}, {"name": "a", "domain": "policy", "when": "true", "action": "reject"}]}`, "duplicate rule a"},
+		{"unknown domain", `{"rules": [{"name": "a", "domain": "payment", "when": "true", "action": "reject"}]}`, `rule a has unknown domain "payment"`},
+		{"unknown action", `{"rules": [{"name": "a", "domain": "quote", "when": "true", "action": "approve"}]}`, `rule a has unknown action "approve" for quote rules`},
+		{"no expression", `{"rules": [{"name": "a", "domain": "quote", "action": "decline"}]}`, "rule a has no expression"},
+		{"unknown field", `{"rules": [{"name": "a", "domain": "quote", "when": "amount > 5", "action": "decline"}]}`, "unknown name amount"},
+		{"not boolean", `{"rules": [{"name": "a", "domain": "quote", "when": "customerAge + 1", "action": "decline"}]}`, "expected bool"},
+		{"other domain still checked", `{"rules": [{"name": "a", "domain": "policy", "when": "premium >", "action": "reject"}]}`, "rule a:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "business-rules.json")
+			writeRules(t, path, tt.json)
+			engine := newTestEngine(Config{Path: path, Domains: []string{DomainQuote}})
+			if err := engine.Load(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	engine := newTestEngine(Config{Path: filepath.Join(t.TempDir(), "missing.json"), Domains: allDomains})
+	if err := engine.Load(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected a missing file to be reported as such, got %v", err)
+	}
+}
+
+func TestValidateChecksServedRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "business-rules.json")
+	writeRules(t, path, `{"rules": [
+		{"name": "no-code", "domain": "claim", "when": "amount > 100", "action": "reject"},
+		{"name": "old-homes", "domain": "quote", "when": "yearBuilt < 1900", "action": "decline"}
+	]}`)
+	validate := func(rule Rule) error {
+		if rule.Action == ActionReject && rule.Code == "" {
+			return errors.New("must reject with a code")
+		}
+		return nil
+	}
+
+	quotes := newTestEngine(Config{Path: path, Domains: []string{DomainQuote}, Validate: validate})
+	if err := quotes.Load(); err != nil {
+		t.Fatalf("Expected claim rules to be dropped by a quote engine, got %v", err)
+	}
+	if quotes.Len() != 1 {
+		t.Errorf("Expected only the quote rule to be kept, got %d rules", quotes.Len())
+	}
+
+	claims := newTestEngine(Config{Path: path, Domains: []string{DomainClaim}, Validate: validate})
+	if err := claims.Load(); err == nil || !strings.Contains(err.Error(), "rule no-code must reject with a code") {
+		t.Errorf("Expected the claim rule to fail validation, got %v", err)
+	}
+}
+
+func TestReplaceRefusesOtherDomains(t *testing.T) {
+	engine := newTestEngine(Config{Domains: []string{DomainPolicy}})
+	err := engine.Replace(Set{Rules: []Rule{{Name: "a", Domain: DomainQuote, When: "true", Action: ActionDecline}}})
+	if err == nil || !strings.Contains(err.Error(), "rule a is a quote rule") {
+		t.Errorf("Expected quote rules to be refused, got %v", err)
+	}
+}
+
+func TestFirstSkipsFailingRules(t *testing.T) {
+	engine := newTestEngine(Config{Domains: []string{DomainQuote}})
+	err := engine.Replace(Set{Version: "t", Rules: []Rule{
+		{Name: "bad-modulo", Domain: DomainQuote, When: "customerAge % riskScore == 0", Action: ActionDecline},
+		{Name: "all", Domain: DomainQuote, When: "true", Action: ActionDecline},
+	}})
+	if err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+
+	match, ok := engine.First(QuoteContext{PolicyType: "auto"})
+	if !ok || match.Rule != "all" || match.Reason != "rule all" {
+		t.Fatalf("Expected the failing rule to be skipped, got %+v, %v", match, ok)
+	}
+	status := engine.Status()
+	if status.Version != "t" || status.Source != "api" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if got := status.Rules[0]; got.Evaluations != 1 || got.Errors != 1 || got.Matches != 0 {
+		t.Errorf("Expected one error on bad-modulo, got %+v", got)
+	}
+	if got := status.Rules[1]; got.Evaluations != 1 || got.Matches != 1 {
+		t.Errorf("Expected one match on all, got %+v", got)
+	}
+}
+
+func TestWatchReloadsChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "business-rules.json")
+	writeRules(t, path, `{"version": "1", "rules": [{"name": "a", "domain": "quote", "when": "true", "action": "decline"}]}`)
+	engine := newTestEngine(Config{Path: path, Domains: []string{DomainQuote}})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	engine.First(QuoteContext{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan struct{}, 1)
+	go engine.Watch(ctx, 10*time.Millisecond, func() { reloaded <- struct{}{} })
+	time.Sleep(50 * time.Millisecond) // let Watch note the file as it was
+
+	writeRules(t, path, `{"version": "2", "rules": [{"name": "a", "domain": "quote", "when": "false", "action": "decline"}, {"name": "b", "domain": "quote", "when": "riskScore > 4", "action": "decline"}]}`)
+	select {
+	case <-reloaded:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the changed file to be reloaded")
+	}
+	if engine.Version() != "2" || engine.Len() != 2 {
+		t.Fatalf("Expected version 2 with two rules, got %s with %d", engine.Version(), engine.Len())
+	}
+	if _, ok := engine.First(QuoteContext{RiskScore: 3}); ok {
+		t.Error("Expected the reloaded rules to be evaluated")
+	}
+	if got := engine.Status().Rules[0]; got.Name != "a" || got.Evaluations != 2 {
+		t.Errorf("Expected rule a to keep its count across the reload, got %+v", got)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	engine := newTestEngine(Config{Domains: []string{DomainPolicy}})
+	serve := func(handler http.Handler, method, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, Path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(engine.Handler(), http.MethodPut, `{"version": "2026.2", "rules": [{"name": "cheap", "domain": "policy", "when": "premium < 100", "action": "reject", "reason": "Premium below the minimum"}]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected PUT to replace the rules, got %d: %s", rec.Code, rec.Body)
+	}
+	if rec := serve(engine.Handler(), http.MethodPut, `{"rules": [{"name": "x", "domain": "policy", "when": "premium", "action": "reject"}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a non-boolean expression to be refused, got %d", rec.Code)
+	}
+	engine.First(PolicyContext{Premium: 50})
+
+	var status Status
+	if err := json.NewDecoder(serve(engine.Handler(), http.MethodGet, "").Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if status.Version != "2026.2" || len(status.Rules) != 1 || status.Rules[0].Matches != 1 {
+		t.Errorf("Expected the replaced rule with one match, got %+v", status)
+	}
+
+	rec = serve(engine.TestHandler(), http.MethodPost, `{"domain": "policy", "context": {"premium": 80}}`)
+	var result TestResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode test result: %v", err)
+	}
+	if result.Match == nil || result.Match.Reason != "Premium below the minimum" || len(result.Results) != 1 {
+		t.Errorf("Expected the current rule to match, got %+v", result)
+	}
+
+	rec = serve(engine.TestHandler(), http.MethodPost, `{"domain": "policy", "context": {"premium": 80, "termDays": 30}, "rules": [
+		{"name": "short", "when": "termDays < 90", "action": "reject"},
+		{"name": "cheap", "when": "premium < 50", "action": "reject"}
+	]}`)
+	result = TestResult{}
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode test result: %v", err)
+	}
+	if result.Match == nil || result.Match.Rule != "short" || len(result.Results) != 2 || result.Results[1].Matched {
+		t.Errorf("Expected the draft rules to be evaluated, got %+v", result)
+	}
+	if got := engine.Status().Rules[0]; got.Evaluations != 1 {
+		t.Errorf("Expected tests not to be counted, got %d evaluations", got.Evaluations)
+	}
+
+	for _, body := range []string{
+		`{"domain": "claim", "context": {}}`,
+		`{"domain": "policy", "context": {"premium": 80, "amount": 5}}`,
+		`{"domain": "policy", "context": {}, "rules": [{"name": "q", "domain": "quote", "when": "true", "action": "decline"}]}`,
+	} {
+		if rec := serve(engine.TestHandler(), http.MethodPost, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	engine := newTestEngine(Config{Domains: []string{DomainQuote}})
+	if err := engine.Replace(Set{Rules: []Rule{{Name: "old-cars", Domain: DomainQuote, When: "vehicleYear > 0 && vehicleYear < 1980", Action: ActionDecline}}}); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	engine.First(QuoteContext{VehicleYear: 1975})
+	engine.First(QuoteContext{VehicleYear: 2020})
+	engine.First(QuoteContext{})
+
+	var out strings.Builder
+	engine.WritePrometheus(&out)
+	for _, want := range []string{
+		`business_rule_evaluations_total{domain="quote",rule="old-cars",result="match"} 1`,
+		`business_rule_evaluations_total{domain="quote",rule="old-cars",result="miss"} 2`,
+		`business_rule_evaluations_total{domain="quote",rule="old-cars",result="error"} 0`,
+		`business_rule_evaluation_seconds_total{domain="quote",rule="old-cars"}`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected %s in:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWS reads secrets from one AWS Secrets Manager secret whose value is a
+// JSON object, each a key of it: JWT_SECRET is the key "JWT_SECRET" of
+// SecretID. Requests are signed with AWS Signature Version 4.
+type AWS struct {
+	Region          string       // AWS_REGION, or AWS_DEFAULT_REGION
+	SecretID        string       // AWS_SECRET_ID, the secret's name or ARN
+	AccessKeyID     string       // AWS_ACCESS_KEY_ID
+	SecretAccessKey string       // AWS_SECRET_ACCESS_KEY
+	SessionToken    string       // AWS_SESSION_TOKEN, for temporary credentials; none when empty
+	Endpoint        string       // AWS_ENDPOINT_URL, such as LocalStack; the regional endpoint when empty
+	Client          *http.Client // http.DefaultClient when nil
+}
+
+// awsService is the Secrets Manager signing name
+const awsService = "secretsmanager"
+
+// Fetch reads the current version of the secret and returns its key name.
+// A missing secret, or a key that is missing or not a string, is
+// ErrNotFound.
+func (a *AWS) Fetch(ctx context.Context, name string) (string, error) {
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + awsService + "." + a.Region + ".amazonaws.com"
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": a.SecretID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode secrets manager request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build secrets manager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	a.sign(req, body, time.Now().UTC())
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", a.SecretID, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Type string `json:"__type"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		if strings.HasSuffix(failure.Type, "ResourceNotFoundException") {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secrets manager returned %d %s for %s", resp.StatusCode, failure.Type, a.SecretID)
+	}
+
+	var secret struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode secret %s: %w", a.SecretID, err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret.SecretString), &values); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object: %w", a.SecretID, err)
+	}
+	value, ok := values[name].(string)
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// sign adds the Signature Version 4 headers for body sent at now
+func (a *AWS) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+
+	// Every header set above is signed, in lower case and sorted; Host is
+	// sent by the client from the URL
+	signed := []string{"content-type", "host", "x-amz-date", "x-amz-target"}
+	if a.SessionToken != "" {
+		signed = append(signed, "x-amz-security-token")
+	}
+	sort.Strings(signed)
+	var canonicalHeaders strings.Builder
+	for _, header := range signed {
+		value := req.Header.Get(header)
+		if header == "host" {
+			value = req.URL.Host
+		}
+		canonicalHeaders.WriteString(header + ":" + strings.TrimSpace(value) + "\n")
+	}
+	signedHeaders := strings.Join(signed, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(body),
+	}, "\n")
+
+	scope := date + "/" + a.Region + "/" + awsService + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex([]byte(canonicalRequest))
+	signature := hex.EncodeToString(hmacSHA256(signingKey(a.SecretAccessKey, date, a.Region, awsService), stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+a.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// signingKey derives the Signature Version 4 key for a day, region and
+// service
+func signingKey(secretAccessKey, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalQuery encodes query sorted by key, as Signature Version 4 signs it
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
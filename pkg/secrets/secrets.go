@@ -0,0 +1,303 @@
+// Package secrets resolves the services' secrets, such as JWT_SECRET and
+// API keys, from a provider: the environment, mounted files, HashiCorp
+// Vault or AWS Secrets Manager. Values are cached and fetched again lazily
+// once they are older than the refresh interval, so a rotated secret takes
+// effect without a restart.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Providers, as named in SECRETS_PROVIDER
+const (
+	ProviderEnv   = "env"
+	ProviderFile  = "file"
+	ProviderVault = "vault"
+	ProviderAWS   = "aws"
+)
+
+// DefaultRefreshInterval is how long values from files, Vault and AWS
+// Secrets Manager are used before they are fetched again
+const DefaultRefreshInterval = 5 * time.Minute
+
+// DefaultFetchTimeout bounds each fetch from a provider
+const DefaultFetchTimeout = 10 * time.Second
+
+// ErrNotFound is returned for a secret the provider does not hold
+var ErrNotFound = errors.New("secret not found")
+
+// Provider fetches secrets by name, such as "JWT_SECRET"
+type Provider interface {
+	Fetch(ctx context.Context, name string) (string, error)
+}
+
+// Env reads secrets from environment variables of the same name
+type Env struct{}
+
+// Fetch returns the environment variable name. An unset or empty variable
+// is ErrNotFound.
+func (Env) Fetch(_ context.Context, name string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+// File reads each secret from a file of the same name in Dir, as Docker and
+// Kubernetes mount them
+type File struct {
+	Dir string
+}
+
+// Fetch returns the contents of Dir/name, without the trailing newline. A
+// missing or empty file is ErrNotFound.
+func (f File) Fetch(_ context.Context, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(f.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Config is the provider a service reads its secrets from
+type Config struct {
+	Provider        string        // SECRETS_PROVIDER; ProviderEnv when empty
+	Dir             string        // SECRETS_DIR, for ProviderFile; /run/secrets when empty
+	RefreshInterval time.Duration // SECRETS_REFRESH_INTERVAL; DefaultRefreshInterval when zero
+	Required        []string      // SECRETS_REQUIRED; JWT_SECRET when empty, except for ProviderEnv
+	Vault           Vault         // VAULT_*, for ProviderVault
+	AWS             AWS           // AWS_*, for ProviderAWS
+}
+
+// ConfigFromEnv reads SECRETS_PROVIDER, SECRETS_DIR,
+// SECRETS_REFRESH_INTERVAL, SECRETS_REQUIRED and the settings of the Vault
+// and AWS providers. An invalid interval is logged as a warning and left at
+// its default.
+func ConfigFromEnv(logger *logrus.Logger) Config {
+	cfg := Config{
+		Provider: strings.ToLower(strings.TrimSpace(os.Getenv("SECRETS_PROVIDER"))),
+		Dir:      os.Getenv("SECRETS_DIR"),
+		Vault: Vault{
+			Addr:      os.Getenv("VAULT_ADDR"),
+			Token:     os.Getenv("VAULT_TOKEN"),
+			Namespace: os.Getenv("VAULT_NAMESPACE"),
+			Mount:     os.Getenv("VAULT_MOUNT"),
+			Path:      os.Getenv("VAULT_SECRET_PATH"),
+		},
+		AWS: AWS{
+			Region:          os.Getenv("AWS_REGION"),
+			SecretID:        os.Getenv("AWS_SECRET_ID"),
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		},
+	}
+	if cfg.AWS.Region == "" {
+		cfg.AWS.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	for _, name := range strings.Split(os.Getenv("SECRETS_REQUIRED"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Required = append(cfg.Required, name)
+		}
+	}
+	if v := os.Getenv("SECRETS_REFRESH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.RefreshInterval = d
+		} else {
+			logger.Warnf("Invalid SECRETS_REFRESH_INTERVAL '%s', defaulting to %s", v, DefaultRefreshInterval)
+		}
+	}
+	return cfg
+}
+
+// Open creates the store cfg names and checks that its required secrets
+// resolve, so a service with a missing or unreadable secret fails at
+// startup rather than on its first request. Secrets from the environment
+// are read on every lookup; those from other providers are cached for the
+// refresh interval.
+func Open(cfg Config, logger *logrus.Logger) (*Store, error) {
+	var provider Provider
+	switch cfg.Provider {
+	case "", ProviderEnv:
+		store := New(ProviderEnv, Env{}, 0, logger)
+		return store, require(store, cfg.Required)
+	case ProviderFile:
+		if cfg.Dir == "" {
+			cfg.Dir = "/run/secrets"
+		}
+		provider = File{Dir: cfg.Dir}
+	case ProviderVault:
+		if cfg.Vault.Addr == "" || cfg.Vault.Token == "" {
+			return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required for the vault provider")
+		}
+		provider = &cfg.Vault
+	case ProviderAWS:
+		if cfg.AWS.Region == "" || cfg.AWS.SecretID == "" || cfg.AWS.AccessKeyID == "" || cfg.AWS.SecretAccessKey == "" {
+			return nil, fmt.Errorf("AWS_REGION, AWS_SECRET_ID, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for the aws provider")
+		}
+		provider = &cfg.AWS
+	default:
+		return nil, fmt.Errorf("unknown secrets provider %q", cfg.Provider)
+	}
+
+	interval := cfg.RefreshInterval
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	required := cfg.Required
+	if len(required) == 0 {
+		required = []string{"JWT_SECRET"}
+	}
+	store := New(cfg.Provider, provider, interval, logger)
+	return store, require(store, required)
+}
+
+// require checks that every one of names resolves
+func require(store *Store, names []string) error {
+	var unresolved []string
+	for _, name := range names {
+		if _, err := store.Get(name); err != nil {
+			store.logger.WithError(err).WithField("secret", name).Error("Required secret did not resolve")
+			unresolved = append(unresolved, name)
+		}
+	}
+	if len(unresolved) > 0 {
+		return fmt.Errorf("required secrets did not resolve from %s: %s", store.source, strings.Join(unresolved, ", "))
+	}
+	return nil
+}
+
+// cached is a value and when it was fetched
+type cached struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// Store resolves secrets from a provider, caching them for the refresh
+// interval. It is safe for concurrent use.
+type Store struct {
+	source   string
+	provider Provider
+	interval time.Duration
+	logger   *logrus.Logger
+
+	mu     sync.Mutex
+	values map[string]cached
+	now    func() time.Time
+}
+
+// New creates a store reading from provider, named source in logs and
+// errors. Values are used for interval before they are fetched again; with
+// no interval every lookup fetches.
+func New(source string, provider Provider, interval time.Duration, logger *logrus.Logger) *Store {
+	return &Store{
+		source:   source,
+		provider: provider,
+		interval: interval,
+		logger:   logger,
+		values:   make(map[string]cached),
+		now:      time.Now,
+	}
+}
+
+// Source is the provider the store reads from, such as "vault"
+func (s *Store) Source() string {
+	return s.source
+}
+
+// Get returns the secret name. A value older than the refresh interval is
+// fetched again on lookup; when that fetch fails the previous value is kept
+// and fetched again after another interval, so a provider outage does not
+// take the service down with it. A secret the provider does not hold is
+// ErrNotFound.
+func (s *Store) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, known := s.values[name]
+	if known && s.interval > 0 && s.now().Sub(previous.fetchedAt) < s.interval {
+		return previous.value, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultFetchTimeout)
+	defer cancel()
+	value, err := s.provider.Fetch(ctx, name)
+	switch {
+	case err == nil:
+		if known && value != previous.value {
+			s.logger.WithFields(logrus.Fields{"secret": name, "source": s.source}).Info("Secret rotated")
+		}
+		if s.interval > 0 {
+			s.values[name] = cached{value: value, fetchedAt: s.now()}
+		}
+		return value, nil
+	case errors.Is(err, ErrNotFound):
+		delete(s.values, name)
+		return "", ErrNotFound
+	case known:
+		s.logger.WithError(err).WithFields(logrus.Fields{"secret": name, "source": s.source}).Warn("Failed to refresh secret, keeping the previous value")
+		s.values[name] = cached{value: previous.value, fetchedAt: s.now()}
+		return previous.value, nil
+	default:
+		return "", err
+	}
+}
+
+// Lookup returns the secret name, or fallback when it does not resolve
+func (s *Store) Lookup(name, fallback string) string {
+	value, err := s.Get(name)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			s.logger.WithError(err).WithField("secret", name).Warn("Failed to read secret")
+		}
+		return fallback
+	}
+	return value
+}
+
+// Func returns a function that looks the secret name up on every call, for
+// keys that must follow rotation such as the JWT signing secret
+func (s *Store) Func(name, fallback string) func() string {
+	return func() string {
+		return s.Lookup(name, fallback)
+	}
+}
+
+var (
+	defaultMu    sync.RWMutex
+	defaultStore = New(ProviderEnv, Env{}, 0, logrus.StandardLogger())
+)
+
+// Default is the store set with SetDefault, reading the environment until
+// one is set. Packages that read secrets outside a service's assembly, such
+// as its JWT middleware, look them up here.
+func Default() *Store {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultStore
+}
+
+// SetDefault makes store the one Default returns
+func SetDefault(store *Store) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultStore = store
+}
@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fakeProvider serves values, counting fetches, or fails with err
+type fakeProvider struct {
+	values  map[string]string
+	err     error
+	fetches int
+}
+
+func (f *fakeProvider) Fetch(_ context.Context, name string) (string, error) {
+	f.fetches++
+	if f.err != nil {
+		return "", f.err
+	}
+	value, ok := f.values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestRotatedSecretsAreFetchedLazily(t *testing.T) {
+	provider := &fakeProvider{values: map[string]string{"JWT_SECRET": "first"}}
+	store := New("fake", provider, time.Minute, quietLogger())
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	key := store.Func("JWT_SECRET", "dev-default")
+	if got := key(); got != "first" {
+		t.Fatalf("Expected first, got %q", got)
+	}
+
+	// The rotated value is only fetched once the cached one is a minute old
+	provider.values["JWT_SECRET"] = "second"
+	now = now.Add(30 * time.Second)
+	if got := key(); got != "first" || provider.fetches != 1 {
+		t.Fatalf("Expected the cached value, got %q after %d fetches", got, provider.fetches)
+	}
+	now = now.Add(31 * time.Second)
+	if got := key(); got != "second" || provider.fetches != 2 {
+		t.Fatalf("Expected the rotated value, got %q after %d fetches", got, provider.fetches)
+	}
+
+	// An outage keeps the last value, and is retried after another interval
+	provider.err = errors.New("connection refused")
+	now = now.Add(2 * time.Minute)
+	if got := key(); got != "second" {
+		t.Fatalf("Expected the previous value during an outage, got %q", got)
+	}
+	if got := key(); got != "second" || provider.fetches != 3 {
+		t.Fatalf("Expected no retry within the interval, got %q after %d fetches", got, provider.fetches)
+	}
+
+	if got := store.Lookup("CLOUDBEES_FM_API_KEY", "none"); got != "none" {
+		t.Errorf("Expected the fallback for an unreadable secret, got %q", got)
+	}
+	provider.err = nil
+	if _, err := store.Get("MISSING"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestOpenRequiresSecretsToResolve(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "JWT_SECRET"), []byte("from-a-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Open(Config{Provider: ProviderFile, Dir: dir}, quietLogger())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if got, _ := store.Get("JWT_SECRET"); got != "from-a-file" {
+		t.Errorf("Expected the file's value without its newline, got %q", got)
+	}
+
+	_, err = Open(Config{Provider: ProviderFile, Dir: dir, Required: []string{"JWT_SECRET", "WEBHOOK_SECRET", "EMAIL_INTAKE_TOKEN"}}, quietLogger())
+	if err == nil || err.Error() != "required secrets did not resolve from file: WEBHOOK_SECRET, EMAIL_INTAKE_TOKEN" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if _, err := Open(Config{Provider: ProviderFile, Dir: t.TempDir()}, quietLogger()); err == nil {
+		t.Error("Expected JWT_SECRET to be required from files by default")
+	}
+
+	// The environment keeps the services' development defaults
+	t.Setenv("JWT_SECRET", "")
+	if _, err := Open(Config{}, quietLogger()); err != nil {
+		t.Errorf("Expected nothing required from the environment by default, got %v", err)
+	}
+	if _, err := Open(Config{Provider: "keychain"}, quietLogger()); err == nil || err.Error() != `unknown secrets provider "keychain"` {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestVaultReadsKeysOfOneSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/data/insurancestack/prod" || r.Header.Get("X-Vault-Token") != "s.token" || r.Header.Get("X-Vault-Namespace") != "claims" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"JWT_SECRET":"vault-secret","PORT":8002},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+
+	vault := &Vault{Addr: server.URL + "/", Token: "s.token", Namespace: "claims", Mount: "kv", Path: "/insurancestack/prod"}
+	if got, err := vault.Fetch(context.Background(), "JWT_SECRET"); err != nil || got != "vault-secret" {
+		t.Fatalf("Expected vault-secret, got %q, %v", got, err)
+	}
+	if _, err := vault.Fetch(context.Background(), "PORT"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a non-string key to be ErrNotFound, got %v", err)
+	}
+	vault.Token = "s.expired"
+	if _, err := vault.Fetch(context.Background(), "JWT_SECRET"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a refused token to be an error, got %v", err)
+	}
+}
+
+func TestAWSSignsGetSecretValue(t *testing.T) {
+	// The signing key example of the Signature Version 4 documentation
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	if got := hex.EncodeToString(key); got != "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d" {
+		t.Fatalf("Unexpected signing key %s", got)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&req)
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") ||
+			!strings.Contains(auth, "/eu-west-1/secretsmanager/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=") ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			t.Errorf("Unexpected request headers %v", r.Header)
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		if req.SecretId != "insurancestack/prod" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"JWT_SECRET":"aws-secret"}`})
+	}))
+	defer server.Close()
+
+	aws := &AWS{Region: "eu-west-1", SecretID: "insurancestack/prod", AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session", Endpoint: server.URL}
+	if got, err := aws.Fetch(context.Background(), "JWT_SECRET"); err != nil || got != "aws-secret" {
+		t.Fatalf("Expected aws-secret, got %q, %v", got, err)
+	}
+	aws.SecretID = "insurancestack/staging"
+	if _, err := aws.Fetch(context.Background(), "JWT_SECRET"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a missing secret to be ErrNotFound, got %v", err)
+	}
+}
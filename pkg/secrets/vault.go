@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Vault reads secrets from one secret of a HashiCorp Vault KV version 2
+// engine, each a key of it: JWT_SECRET is the key "JWT_SECRET" of
+// Mount/Path.
+type Vault struct {
+	Addr      string       // VAULT_ADDR, such as https://vault.internal:8200
+	Token     string       // VAULT_TOKEN
+	Namespace string       // VAULT_NAMESPACE, for Vault Enterprise; none when empty
+	Mount     string       // VAULT_MOUNT; "secret" when empty
+	Path      string       // VAULT_SECRET_PATH; "insurancestack" when empty
+	Client    *http.Client // http.DefaultClient when nil
+}
+
+// vaultResponse is the body of a KV version 2 read
+type vaultResponse struct {
+	Data struct {
+		Data map[string]interface{} `json:"data"`
+	} `json:"data"`
+}
+
+// Fetch reads the latest version of the secret and returns its key name.
+// A key that is missing or not a string is ErrNotFound.
+func (v *Vault) Fetch(ctx context.Context, name string) (string, error) {
+	mount, path := v.Mount, v.Path
+	if mount == "" {
+		mount = "secret"
+	}
+	if path == "" {
+		path = "insurancestack"
+	}
+	url := strings.TrimRight(v.Addr, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.Trim(path, "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault secret %s/%s: %w", mount, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %d for %s/%s", resp.StatusCode, mount, path)
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode vault secret %s/%s: %w", mount, path, err)
+	}
+	value, ok := body.Data.Data[name].(string)
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
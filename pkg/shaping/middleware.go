@@ -0,0 +1,43 @@
+package shaping
+
+import (
+	"context"
+	"net/http"
+)
+
+// shaperKey is the request context key of the Shaper installed by
+// Middleware
+type shaperKey struct{}
+
+// requestShaper is a Shaper and how to tell who a request's caller is
+type requestShaper struct {
+	shaper *Shaper
+	viewer func(r *http.Request) Viewer
+}
+
+// fallback shapes responses outside Middleware, by struct tags alone
+var fallback = New(Default(), "")
+
+// Middleware makes s available to the handlers of every request, shaping
+// their responses for the caller viewer returns. viewer is called with the
+// request the handler has, so a role verified by middleware further down
+// the chain, such as on a subrouter, is the one used.
+func Middleware(s *Shaper, viewer func(r *http.Request) Viewer) func(http.Handler) http.Handler {
+	rs := &requestShaper{shaper: s, viewer: viewer}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), shaperKey{}, rs)))
+		})
+	}
+}
+
+// Apply shapes v for the caller of r. Outside Middleware v is shaped by
+// its struct tags for a caller without a role or flags, so fields kept for
+// staff are left out.
+func Apply[T any](r *http.Request, v T) T {
+	rs, ok := r.Context().Value(shaperKey{}).(*requestShaper)
+	if !ok {
+		return Shape(fallback, v, Viewer{})
+	}
+	return Shape(rs.shaper, v, rs.viewer(r))
+}
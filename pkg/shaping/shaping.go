@@ -0,0 +1,446 @@
+// Package shaping decides which fields of a response each caller sees.
+// The fields of the services' response types declare their visibility in a
+// shape struct tag: the roles that may see them, and the feature flag that
+// masks them. A policy file adds or replaces rules per service, so a field
+// can be hidden without a release. Shaping copies what it changes, so the
+// records a service holds are never touched.
+package shaping
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The text a masked value is replaced with: MaskedAmount for numbers,
+// Masked for anything else
+const (
+	MaskedAmount = "***.**"
+	Masked       = "***"
+)
+
+// Viewer is the caller a response is shaped for
+type Viewer struct {
+	Role  string          // role of the caller's token; "" for customers identified by X-User-ID
+	Flags map[string]bool // feature flags by key, such as api.maskAmounts
+}
+
+// FlagsOn returns the Flags of a Viewer from the keys of the flags that
+// are on, as the services' flag packages list them for the health check
+func FlagsOn(keys []string) map[string]bool {
+	on := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		on[key] = true
+	}
+	return on
+}
+
+// Rule is the visibility of one field
+type Rule struct {
+	Roles []string `json:"roles,omitempty"` // the only roles that see the field; empty means every caller
+	Mask  string   `json:"mask,omitempty"`  // feature flag whose being on masks the field for every caller
+}
+
+// visibleTo reports whether the viewer's role may see the field
+func (r Rule) visibleTo(v Viewer) bool {
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, role := range r.Roles {
+		if role == v.Role {
+			return true
+		}
+	}
+	return false
+}
+
+// maskedFor reports whether the field is masked for the viewer
+func (r Rule) maskedFor(v Viewer) bool {
+	return r.Mask != "" && v.Flags[r.Mask]
+}
+
+// ParseTag reads the rule of a shape struct tag: comma-separated options
+// roles=admin|adjuster and mask=<flag key>
+func ParseTag(tag string) (Rule, error) {
+	var rule Rule
+	for _, option := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(option), "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("invalid shape option %q", option)
+		}
+		switch key {
+		case "roles":
+			for _, role := range strings.Split(value, "|") {
+				if role == "" {
+					return Rule{}, fmt.Errorf("empty role in %q", option)
+				}
+				rule.Roles = append(rule.Roles, role)
+			}
+		case "mask":
+			rule.Mask = value
+		default:
+			return Rule{}, fmt.Errorf("unknown shape option %q", key)
+		}
+	}
+	return rule, nil
+}
+
+// Fields is the rule of each field of a type, by JSON field name
+type Fields map[string]Rule
+
+// Policy is the rules configured for each service's response types. A
+// rule replaces the field's shape tag.
+type Policy struct {
+	Version  string                       `json:"version"`
+	Services map[string]map[string]Fields `json:"services"` // by service, then Go type such as models.Claim
+}
+
+// Default returns the policy used when none is configured: no rules, so
+// responses are shaped by their struct tags alone
+func Default() *Policy {
+	return &Policy{Version: "none", Services: map[string]map[string]Fields{}}
+}
+
+// Load reads and validates a shaping policy file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid shaping policy in %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid shaping policy in %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// validate checks every rule restricts or masks its field
+func (p *Policy) validate() error {
+	if len(p.Services) == 0 {
+		return fmt.Errorf("no services")
+	}
+	for service, types := range p.Services {
+		if len(types) == 0 {
+			return fmt.Errorf("%s has no types", service)
+		}
+		for typeName, fields := range types {
+			for name, rule := range fields {
+				if len(rule.Roles) == 0 && rule.Mask == "" {
+					return fmt.Errorf("%s %s.%s rule has neither roles nor mask", service, typeName, name)
+				}
+				for _, role := range rule.Roles {
+					if role == "" {
+						return fmt.Errorf("%s %s.%s rule has an empty role", service, typeName, name)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Shaper shapes one service's responses. It is safe for concurrent use.
+type Shaper struct {
+	rules map[string]Fields // by Go type
+
+	mu      sync.RWMutex
+	structs map[reflect.Type]*structInfo
+	shaped  map[reflect.Type]bool
+}
+
+// structInfo is what shaping needs to know of a struct type
+type structInfo struct {
+	fields   []fieldInfo     // exported fields with a rule or that may hold shaped values
+	names    map[string]bool // JSON names of every exported field
+	problems []string        // invalid shape tags
+}
+
+// fieldInfo is one struct field shaping visits
+type fieldInfo struct {
+	index  int
+	typ    reflect.Type
+	rule   Rule
+	ruled  bool
+	hidden bool // the field's shape tag is invalid, so it is left out for everyone
+}
+
+// New returns a Shaper applying the struct tags and the policy's rules
+// for service
+func New(policy *Policy, service string) *Shaper {
+	rules := policy.Services[service]
+	if rules == nil {
+		rules = map[string]Fields{}
+	}
+	return &Shaper{
+		rules:   rules,
+		structs: make(map[reflect.Type]*structInfo),
+		shaped:  make(map[reflect.Type]bool),
+	}
+}
+
+// Shape returns v as the viewer may see it. Fields restricted to other
+// roles are zeroed, so with omitempty they are left out of the JSON. Masked
+// strings and interface values read Masked, or MaskedAmount for numbers;
+// masked fields of any other type cannot hold the mask and are zeroed
+// instead, so amounts that may be masked are declared as any. Values are
+// copied wherever a field changes and shared everywhere else.
+func Shape[T any](s *Shaper, v T, viewer Viewer) T {
+	in := reflect.ValueOf(&v).Elem()
+	out := reflect.New(in.Type()).Elem()
+	out.Set(s.shape(in, viewer))
+	return *out.Addr().Interface().(*T)
+}
+
+// Check reports the invalid shape tags of the types of values and of the
+// types they hold, and the policy rules naming a type or field not among
+// them. Services check their response types at startup.
+func (s *Shaper) Check(values ...any) error {
+	seen := make(map[reflect.Type]bool)
+	for _, v := range values {
+		if t := reflect.TypeOf(v); t != nil {
+			s.collect(t, seen)
+		}
+	}
+
+	var problems []string
+	byName := make(map[string]reflect.Type, len(seen))
+	for t := range seen {
+		byName[t.String()] = t
+		problems = append(problems, s.info(t).problems...)
+	}
+	for typeName, fields := range s.rules {
+		t, ok := byName[typeName]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a response type", typeName))
+			continue
+		}
+		names := s.info(t).names
+		for name := range fields {
+			if !names[name] {
+				problems = append(problems, fmt.Sprintf("%s has no field %s", typeName, name))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return errors.New(strings.Join(problems, "; "))
+}
+
+// collect adds the struct types t holds to seen
+func (s *Shaper) collect(t reflect.Type, seen map[reflect.Type]bool) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		s.collect(t.Elem(), seen)
+	case reflect.Struct:
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		for _, f := range s.info(t).fields {
+			s.collect(f.typ, seen)
+		}
+	}
+}
+
+// shape returns v shaped for the viewer, or v itself when nothing in its
+// type is shaped
+func (s *Shaper) shape(v reflect.Value, viewer Viewer) reflect.Value {
+	if !s.isShaped(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(s.shape(v.Elem(), viewer))
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(s.shape(v.Elem(), viewer))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(s.shape(v.Index(i), viewer))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(s.shape(v.Index(i), viewer))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), s.shape(iter.Value(), viewer))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for _, f := range s.info(v.Type()).fields {
+			field := out.Field(f.index)
+			switch {
+			case f.hidden || !f.rule.visibleTo(viewer):
+				field.SetZero()
+			case f.rule.maskedFor(viewer):
+				mask(field)
+			default:
+				field.Set(s.shape(field, viewer))
+			}
+		}
+		return out
+	}
+	return v
+}
+
+// mask replaces the value of a field with its mask text. Empty values stay
+// empty, so omitempty still leaves them out.
+func mask(field reflect.Value) {
+	text := reflect.TypeOf(Masked)
+	switch {
+	case field.Kind() == reflect.String:
+		if field.Len() > 0 {
+			field.SetString(Masked)
+		}
+	case field.Kind() == reflect.Interface && text.AssignableTo(field.Type()):
+		if field.IsNil() {
+			return
+		}
+		switch field.Elem().Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			field.Set(reflect.ValueOf(MaskedAmount))
+		default:
+			field.Set(reflect.ValueOf(Masked))
+		}
+	default:
+		field.SetZero()
+	}
+}
+
+// isShaped reports whether values of type t may need shaping: t holds a
+// field with a rule, or an interface value
+func (s *Shaper) isShaped(t reflect.Type) bool {
+	s.mu.RLock()
+	shaped, ok := s.shaped[t]
+	s.mu.RUnlock()
+	if ok {
+		return shaped
+	}
+
+	shaped = s.reaches(t, make(map[reflect.Type]bool))
+	s.mu.Lock()
+	s.shaped[t] = shaped
+	s.mu.Unlock()
+	return shaped
+}
+
+// reaches reports whether a field with a rule, or an interface value, can
+// be reached from t. Types already being visited are skipped, so the
+// answer is complete for t itself; only that answer is cached.
+func (s *Shaper) reaches(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return s.reaches(t.Elem(), visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			return false
+		}
+		visiting[t] = true
+		for _, f := range s.info(t).fields {
+			if f.ruled || f.hidden || s.reaches(f.typ, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// info returns the fields of struct type t that shaping visits
+func (s *Shaper) info(t reflect.Type) *structInfo {
+	s.mu.RLock()
+	info, ok := s.structs[t]
+	s.mu.RUnlock()
+	if ok {
+		return info
+	}
+
+	info = &structInfo{names: make(map[string]bool)}
+	rules := s.rules[t.String()]
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := jsonName(sf)
+		if !sf.IsExported() || name == "-" {
+			continue
+		}
+		info.names[name] = true
+
+		f := fieldInfo{index: i, typ: sf.Type}
+		if tag, ok := sf.Tag.Lookup("shape"); ok {
+			rule, err := ParseTag(tag)
+			if err != nil {
+				info.problems = append(info.problems, fmt.Sprintf("%s.%s: %v", t, sf.Name, err))
+				f.hidden = true
+			}
+			f.rule, f.ruled = rule, true
+		}
+		if rule, ok := rules[name]; ok {
+			f.rule, f.ruled, f.hidden = rule, true, false
+		}
+		if f.ruled || holdsValues(sf.Type) {
+			info.fields = append(info.fields, f)
+		}
+	}
+
+	s.mu.Lock()
+	s.structs[t] = info
+	s.mu.Unlock()
+	return info
+}
+
+// holdsValues reports whether a field of type t can hold shaped values
+func holdsValues(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// jsonName returns the name a field is encoded under
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,256 @@
+package shaping
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type account struct {
+	ID       string  `json:"id"`
+	Owner    string  `json:"owner"`
+	Balance  any     `json:"balance" shape:"mask=api.maskAmounts"`
+	Limit    float64 `json:"limit" shape:"mask=api.maskAmounts"`
+	Manager  string  `json:"manager,omitempty" shape:"roles=admin|adjuster"`
+	RiskNote string  `json:"riskNote,omitempty"`
+	Notes    []note  `json:"notes,omitempty"`
+	internal string
+}
+
+type note struct {
+	Text   string `json:"text"`
+	Author string `json:"author,omitempty" shape:"roles=admin"`
+}
+
+type page struct {
+	Data  []*account     `json:"data"`
+	ByID  map[string]any `json:"byId,omitempty"`
+	Total int            `json:"total"`
+}
+
+func sample() *account {
+	return &account{
+		ID:       "acc-001",
+		Owner:    "cust-001",
+		Balance:  1250.5,
+		Limit:    5000,
+		Manager:  "adm-001",
+		RiskNote: "Frequent chargebacks",
+		Notes:    []note{{Text: "Called customer", Author: "adm-001"}},
+		internal: "kept",
+	}
+}
+
+var (
+	customer    = Viewer{}
+	adjuster    = Viewer{Role: "adjuster"}
+	maskedAdmin = Viewer{Role: "admin", Flags: FlagsOn([]string{"api.maskAmounts"})}
+)
+
+func TestShapeHidesFieldsFromOtherRoles(t *testing.T) {
+	s := New(Default(), "accounts")
+	in := sample()
+
+	got := Shape(s, in, customer)
+	if got.Manager != "" || got.Notes[0].Author != "" {
+		t.Errorf("Customer sees staff fields: %+v", got)
+	}
+	if got.ID != "acc-001" || got.Balance != 1250.5 || got.Notes[0].Text != "Called customer" || got.internal != "kept" {
+		t.Errorf("Customer lost visible fields: %+v", got)
+	}
+
+	got = Shape(s, in, adjuster)
+	if got.Manager != "adm-001" || got.Notes[0].Author != "" {
+		t.Errorf("Adjuster should see the manager but not note authors: %+v", got)
+	}
+
+	if in.Manager != "adm-001" || in.Notes[0].Author != "adm-001" {
+		t.Errorf("Shape changed its input: %+v", in)
+	}
+}
+
+func TestShapeMasksWhileTheFlagIsOn(t *testing.T) {
+	s := New(Default(), "accounts")
+
+	got := Shape(s, *sample(), maskedAdmin)
+	if got.Balance != MaskedAmount {
+		t.Errorf("Balance: got %v, want %s", got.Balance, MaskedAmount)
+	}
+	if got.Limit != 0 {
+		t.Errorf("A float field cannot hold the mask and should be zeroed, got %v", got.Limit)
+	}
+	if got.Manager != "adm-001" || got.Notes[0].Author != "adm-001" {
+		t.Errorf("Admin should see staff fields: %+v", got)
+	}
+
+	text := Shape(s, account{Balance: "1,250.50"}, maskedAdmin)
+	if text.Balance != Masked {
+		t.Errorf("Text balance: got %v, want %s", text.Balance, Masked)
+	}
+	if empty := Shape(s, account{}, maskedAdmin); empty.Balance != nil {
+		t.Errorf("An empty balance should stay empty, got %v", empty.Balance)
+	}
+	if plain := Shape(s, *sample(), adjuster); plain.Balance != 1250.5 {
+		t.Errorf("Balance without the flag: got %v", plain.Balance)
+	}
+}
+
+func TestShapeReachesNestedValues(t *testing.T) {
+	s := New(Default(), "accounts")
+	in := page{
+		Data:  []*account{sample(), nil},
+		ByID:  map[string]any{"acc-001": sample(), "count": 1},
+		Total: 2,
+	}
+
+	got := Shape(s, in, customer)
+	if got.Data[0].Manager != "" || got.Data[1] != nil || got.Total != 2 {
+		t.Errorf("Unexpected shaped page: %+v", got)
+	}
+	if inMap := got.ByID["acc-001"].(*account); inMap.Manager != "" || got.ByID["count"] != 1 {
+		t.Errorf("Map values should be shaped: %+v", got.ByID)
+	}
+	if in.Data[0].Manager != "adm-001" || in.ByID["acc-001"].(*account).Manager != "adm-001" {
+		t.Error("Shape changed the page it was given")
+	}
+
+	var boxed any = sample()
+	if shaped := Shape(s, boxed, customer).(*account); shaped.Manager != "" {
+		t.Errorf("Interface value not shaped: %+v", shaped)
+	}
+	if Shape[any](s, nil, customer) != nil {
+		t.Error("A nil interface should stay nil")
+	}
+}
+
+func TestPolicyRulesReplaceShapeTags(t *testing.T) {
+	policy := &Policy{Version: "test", Services: map[string]map[string]Fields{
+		"accounts": {"shaping.account": {
+			"riskNote": {Roles: []string{"admin"}},
+			"manager":  {Mask: "api.maskAmounts"},
+		}},
+	}}
+	s := New(policy, "accounts")
+
+	got := Shape(s, sample(), adjuster)
+	if got.RiskNote != "" {
+		t.Errorf("The policy keeps risk notes for admins: %+v", got)
+	}
+	if got.Manager != "adm-001" {
+		t.Errorf("The policy rule replaces the manager's roles tag: %+v", got)
+	}
+	if masked := Shape(s, sample(), maskedAdmin); masked.Manager != Masked || masked.RiskNote == "" {
+		t.Errorf("Unexpected shaping for a masked admin: %+v", masked)
+	}
+
+	if other := Shape(New(policy, "payments"), sample(), adjuster); other.RiskNote == "" {
+		t.Error("Another service's rules should not apply")
+	}
+}
+
+func TestLoadSeedPolicy(t *testing.T) {
+	policy, err := Load(filepath.Join("..", "..", "data", "seed", "response-shaping.json"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if policy.Version == "" || len(policy.Services["policy-service"]) == 0 {
+		t.Errorf("Expected policy-service rules in the seed policy: %+v", policy)
+	}
+}
+
+func TestLoadRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name, json, wantErr string
+	}{
+		{"not json", `{`, "unexpected end of JSON input"},
+		{"no services", `{"services": {}}`, "no services"},
+		{"no types", `{"services": {"claims-service": {}}}`, "claims-service has no types"},
+		{"empty rule", `{"services": {"claims-service": {"models.Claim": {"queue": {}}}}}`, "claims-service models.Claim.queue rule has neither roles nor mask"},
+		{"empty role", `{"services": {"claims-service": {"models.Claim": {"queue": {"roles": [""]}}}}}`, "claims-service models.Claim.queue rule has an empty role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "response-shaping.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0o644); err != nil {
+				t.Fatalf("Failed to write policy: %v", err)
+			}
+			if _, err := Load(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestParseTagRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		tag, wantErr string
+	}{
+		{"roles", `invalid shape option "roles"`},
+		{"roles=admin||adjuster", `empty role in "roles=admin||adjuster"`},
+		{"hide=true", `unknown shape option "hide"`},
+	}
+	for _, tt := range tests {
+		if _, err := ParseTag(tt.tag); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("ParseTag(%q): got %v, want %s", tt.tag, err, tt.wantErr)
+		}
+	}
+
+	rule, err := ParseTag("roles=admin|adjuster, mask=api.maskAmounts")
+	if err != nil || len(rule.Roles) != 2 || rule.Mask != "api.maskAmounts" {
+		t.Errorf("Unexpected rule %+v, err %v", rule, err)
+	}
+}
+
+type badTag struct {
+	Secret string `json:"secret" shape:"role=admin"`
+}
+
+func TestCheckReportsProblems(t *testing.T) {
+	if err := New(Default(), "accounts").Check(page{}); err != nil {
+		t.Errorf("Check of valid tags: %v", err)
+	}
+
+	policy := &Policy{Services: map[string]map[string]Fields{
+		"accounts": {
+			"shaping.account": {"nickname": {Roles: []string{"admin"}}},
+			"shaping.invoice": {"total": {Mask: "api.maskAmounts"}},
+		},
+	}}
+	err := New(policy, "accounts").Check(page{}, badTag{})
+	want := `shaping.account has no field nickname; shaping.badTag.Secret: unknown shape option "role"; shaping.invoice is not a response type`
+	if err == nil || err.Error() != want {
+		t.Errorf("Check: got %v, want %s", err, want)
+	}
+
+	// A field whose tag cannot be read is left out for everyone
+	if got := Shape(New(Default(), "accounts"), badTag{Secret: "s3cret"}, maskedAdmin); got.Secret != "" {
+		t.Errorf("Field with an invalid tag was shown: %+v", got)
+	}
+}
+
+func TestApplyShapesForTheRequestCaller(t *testing.T) {
+	s := New(Default(), "accounts")
+	handler := Middleware(s, func(r *http.Request) Viewer {
+		return Viewer{Role: r.Header.Get("X-Role")}
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(Apply(r, sample()).Manager))
+	}))
+
+	for role, want := range map[string]string{"": "", "adjuster": "adm-001", "agent": ""} {
+		req := httptest.NewRequest("GET", "/accounts/acc-001", nil)
+		req.Header.Set("X-Role", role)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Body.String() != want {
+			t.Errorf("Role %q sees manager %q, want %q", role, rec.Body.String(), want)
+		}
+	}
+
+	// Without the middleware responses are shaped for a customer
+	if got := Apply(httptest.NewRequest("GET", "/", nil), sample()); got.Manager != "" {
+		t.Errorf("Apply outside Middleware shows staff fields: %+v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package signing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRotatedKeysVerifyEitherWay(t *testing.T) {
+	body := []byte(`{"type":"claim.approved"}`)
+	if got := Sign("s3cret", body); !strings.HasPrefix(got, "sha256=") || len(got) != len("sha256=")+64 {
+		t.Fatalf("Unexpected signature %q", got)
+	}
+
+	old := ParseKeys("old-secret")
+	rotating := ParseKeys(" new-secret, old-secret ,")
+	if len(rotating) != 2 || rotating[0] != "new-secret" {
+		t.Fatalf("Unexpected keys %q", rotating)
+	}
+	renewed := Keys{"new-secret"}
+
+	// A receiver holding either secret verifies a sender holding both, and
+	// the other way round
+	for _, tc := range []struct {
+		name     string
+		sender   Keys
+		receiver Keys
+		want     bool
+	}{
+		{"old sender, rotating receiver", old, rotating, true},
+		{"rotating sender, old receiver", rotating, old, true},
+		{"rotating sender, renewed receiver", rotating, renewed, true},
+		{"old sender, renewed receiver", old, renewed, false},
+		{"unsigned", nil, rotating, false},
+	} {
+		if got := tc.receiver.Verify(body, tc.sender.Sign(body)); got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if rotating.Verify([]byte(`{"type":"claim.denied"}`), rotating.Sign(body)) {
+		t.Error("Expected a changed body to be refused")
+	}
+}
+
+func TestEqualAny(t *testing.T) {
+	if !EqualAny("token-b", "token-a", "token-b") || EqualAny("token-c", "token-a", "token-b") || EqualAny("", "") {
+		t.Error("Unexpected EqualAny result")
+	}
+}
+
+func TestMiddlewareRefusesUnsignedBodies(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	keys := Keys{"gateway-2026", "gateway-2025"}
+	var received string
+	handler := Middleware(keys, logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	body := `{"paymentId":"pay-001"}`
+	for _, tc := range []struct {
+		name      string
+		signature string
+		want      int
+	}{
+		{"signed with the old secret", Sign("gateway-2025", []byte(body)), http.StatusNoContent},
+		{"signed with an unknown secret", Sign("guess", []byte(body)), http.StatusUnauthorized},
+		{"unsigned", "", http.StatusUnauthorized},
+	} {
+		received = ""
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/gateway", strings.NewReader(body))
+		if tc.signature != "" {
+			req.Header.Set(Header, tc.signature)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, rec.Code, tc.want)
+		}
+		if tc.want == http.StatusNoContent && received != body {
+			t.Errorf("%s: handler read %q", tc.name, received)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package targeting
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Impression records one flag evaluation: who saw which variant, and when
+type Impression struct {
+	Flag       string            `json:"flag"`
+	Variant    string            `json:"variant"`
+	UserID     string            `json:"userId,omitempty"`
+	TenantID   string            `json:"tenantId,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// BoolVariant names the variant of a boolean flag
+func BoolVariant(enabled bool) string {
+	return strconv.FormatBool(enabled)
+}
+
+// Sink receives batches of impressions on each flush. Implementations must
+// not keep the slice after returning. A Kafka producer, for example,
+// publishes each impression to a topic.
+type Sink interface {
+	Write(ctx context.Context, impressions []Impression) error
+}
+
+// LogSink writes each impression as a structured log entry
+type LogSink struct {
+	Logger *logrus.Logger
+}
+
+// Write logs the batch at info level
+func (s *LogSink) Write(ctx context.Context, impressions []Impression) error {
+	for _, imp := range impressions {
+		s.Logger.WithFields(logrus.Fields{
+			"flag":       imp.Flag,
+			"variant":    imp.Variant,
+			"userId":     imp.UserID,
+			"tenantId":   imp.TenantID,
+			"attributes": imp.Attributes,
+			"timestamp":  imp.Timestamp,
+		}).Info("Flag impression")
+	}
+	return nil
+}
+
+// Recorder keeps the most recent impressions in a ring buffer and forwards
+// them to a sink in batches. Recording never blocks on the sink: when the
+// buffer wraps before a flush, the oldest unflushed impressions are dropped
+// and counted.
+type Recorder struct {
+	mu       sync.Mutex
+	buf      []Impression
+	seq      uint64 // impressions recorded so far
+	flushed  uint64 // seq at the last flush
+	dropped  uint64
+	totals   map[string]map[string]uint64 // flag -> variant -> count
+	since    time.Time
+	sink     Sink
+	flushing sync.Mutex
+}
+
+// NewRecorder creates a recorder holding up to capacity impressions. sink
+// may be nil to keep impressions in memory only.
+func NewRecorder(capacity int, sink Sink) *Recorder {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Recorder{
+		buf:    make([]Impression, capacity),
+		totals: make(map[string]map[string]uint64),
+		since:  time.Now(),
+		sink:   sink,
+	}
+}
+
+// Start creates a recorder that flushes to sink every interval until the
+// returned stop function is called, which also flushes what remains
+func Start(capacity int, interval time.Duration, sink Sink, onError func(error)) (*Recorder, func()) {
+	recorder := NewRecorder(capacity, sink)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		recorder.Run(interval, stop, onError)
+		close(done)
+	}()
+
+	var once sync.Once
+	return recorder, func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+}
+
+// Record adds an impression. A nil recorder ignores it.
+func (r *Recorder) Record(imp Impression) {
+	if r == nil {
+		return
+	}
+	if imp.Timestamp.IsZero() {
+		imp.Timestamp = time.Now()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sink == nil {
+		r.flushed = r.seq
+	} else if r.seq-r.flushed >= uint64(len(r.buf)) {
+		r.dropped++
+		r.flushed++
+	}
+	r.buf[r.seq%uint64(len(r.buf))] = imp
+	r.seq++
+
+	variants, ok := r.totals[imp.Flag]
+	if !ok {
+		variants = make(map[string]uint64)
+		r.totals[imp.Flag] = variants
+	}
+	variants[imp.Variant]++
+}
+
+// Flush sends the impressions recorded since the last flush to the sink.
+// Impressions the sink rejects are not retried.
+func (r *Recorder) Flush(ctx context.Context) error {
+	if r == nil || r.sink == nil {
+		return nil
+	}
+	r.flushing.Lock()
+	defer r.flushing.Unlock()
+
+	r.mu.Lock()
+	pending := make([]Impression, 0, r.seq-r.flushed)
+	for i := r.flushed; i < r.seq; i++ {
+		pending = append(pending, r.buf[i%uint64(len(r.buf))])
+	}
+	r.flushed = r.seq
+	r.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	return r.sink.Write(ctx, pending)
+}
+
+// Run flushes every interval until stop is closed, then flushes once more.
+// onError, if set, is called with failed flushes.
+func (r *Recorder) Run(interval time.Duration, stop <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			if err := r.Flush(context.Background()); err != nil && onError != nil {
+				onError(err)
+			}
+			return
+		case <-ticker.C:
+			if err := r.Flush(context.Background()); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Recent returns the impressions still in the buffer, oldest first
+func (r *Recorder) Recent() []Impression {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	start := uint64(0)
+	if r.seq > uint64(len(r.buf)) {
+		start = r.seq - uint64(len(r.buf))
+	}
+	recent := make([]Impression, 0, r.seq-start)
+	for i := start; i < r.seq; i++ {
+		recent = append(recent, r.buf[i%uint64(len(r.buf))])
+	}
+	return recent
+}
+
+// Summary aggregates impressions for exposure reporting
+type Summary struct {
+	Since   time.Time               `json:"since"`
+	Total   uint64                  `json:"total"`
+	Dropped uint64                  `json:"dropped"` // lost before reaching the sink
+	Window  int                     `json:"window"`  // impressions still buffered
+	Flags   map[string]*FlagSummary `json:"flags"`
+}
+
+// FlagSummary is the exposure of one flag
+type FlagSummary struct {
+	Variants map[string]*VariantSummary `json:"variants"`
+}
+
+// VariantSummary is the exposure of one flag variant. Impressions count
+// every evaluation since Since; Users and Tenants count distinct IDs within
+// the buffered window, as the buffer is all that is remembered of them.
+type VariantSummary struct {
+	Impressions uint64    `json:"impressions"`
+	Users       int       `json:"users"`
+	Tenants     []string  `json:"tenants,omitempty"`
+	LastSeen    time.Time `json:"lastSeen,omitempty"`
+}
+
+// Summary reports impressions per flag and variant
+func (r *Recorder) Summary() *Summary {
+	summary := &Summary{Flags: make(map[string]*FlagSummary)}
+	if r == nil {
+		return summary
+	}
+
+	recent := r.Recent()
+
+	r.mu.Lock()
+	summary.Since = r.since
+	summary.Total = r.seq
+	summary.Dropped = r.dropped
+	for flag, variants := range r.totals {
+		fs := &FlagSummary{Variants: make(map[string]*VariantSummary, len(variants))}
+		for variant, count := range variants {
+			fs.Variants[variant] = &VariantSummary{Impressions: count}
+		}
+		summary.Flags[flag] = fs
+	}
+	r.mu.Unlock()
+
+	summary.Window = len(recent)
+	users := make(map[string]map[string]bool)
+	tenants := make(map[string]map[string]bool)
+	for _, imp := range recent {
+		vs := summary.Flags[imp.Flag].Variants[imp.Variant]
+		key := imp.Flag + "\x00" + imp.Variant
+		if imp.UserID != "" {
+			if users[key] == nil {
+				users[key] = make(map[string]bool)
+			}
+			users[key][imp.UserID] = true
+		}
+		if imp.TenantID != "" {
+			if tenants[key] == nil {
+				tenants[key] = make(map[string]bool)
+			}
+			tenants[key][imp.TenantID] = true
+		}
+		if imp.Timestamp.After(vs.LastSeen) {
+			vs.LastSeen = imp.Timestamp
+		}
+	}
+	for flag, fs := range summary.Flags {
+		for variant, vs := range fs.Variants {
+			key := flag + "\x00" + variant
+			vs.Users = len(users[key])
+			for tenant := range tenants[key] {
+				vs.Tenants = append(vs.Tenants, tenant)
+			}
+			sort.Strings(vs.Tenants)
+		}
+	}
+	return summary
+}
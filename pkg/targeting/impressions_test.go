@@ -0,0 +1,116 @@
+package targeting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySink collects flushed batches
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Impression
+}
+
+func (s *memorySink) Write(ctx context.Context, impressions []Impression) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batch := make([]Impression, len(impressions))
+	copy(batch, impressions)
+	s.batches = append(s.batches, batch)
+	return nil
+}
+
+func (s *memorySink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, batch := range s.batches {
+		n += len(batch)
+	}
+	return n
+}
+
+func TestRecorderFlushesEachImpressionOnce(t *testing.T) {
+	sink := &memorySink{}
+	recorder := NewRecorder(4, sink)
+
+	for i := 0; i < 3; i++ {
+		recorder.Record(Impression{Flag: "claims.autoApproval", Variant: "true", UserID: fmt.Sprintf("cust-%d", i)})
+	}
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	recorder.Record(Impression{Flag: "claims.autoApproval", Variant: "false", UserID: "cust-9"})
+	if err := recorder.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+
+	if len(sink.batches) != 2 || len(sink.batches[0]) != 3 || len(sink.batches[1]) != 1 {
+		t.Fatalf("Unexpected batches: %+v", sink.batches)
+	}
+	if sink.batches[1][0].Timestamp.IsZero() {
+		t.Error("Record should stamp impressions")
+	}
+
+	// Overrunning the buffer between flushes drops the oldest
+	for i := 0; i < 6; i++ {
+		recorder.Record(Impression{Flag: "claims.autoApproval", Variant: "true", UserID: "cust-0"})
+	}
+	recorder.Flush(context.Background())
+	if got := len(sink.batches[2]); got != 4 {
+		t.Errorf("Batch after overrun: got %d impressions, want 4", got)
+	}
+	if summary := recorder.Summary(); summary.Dropped != 2 || summary.Total != 10 {
+		t.Errorf("Summary counts: total=%d dropped=%d, want 10 and 2", summary.Total, summary.Dropped)
+	}
+}
+
+func TestRecorderSummary(t *testing.T) {
+	recorder := NewRecorder(100, nil)
+	for _, imp := range []Impression{
+		{Flag: "payments.instantPayouts", Variant: "true", UserID: "cust-001", TenantID: "partner-a"},
+		{Flag: "payments.instantPayouts", Variant: "true", UserID: "cust-001"},
+		{Flag: "payments.instantPayouts", Variant: "false", UserID: "cust-002"},
+		{Flag: "payments.instantPayouts", Variant: "false", UserID: "cust-003"},
+		{Flag: "claims.autoApproval", Variant: "false", UserID: "cust-002"},
+	} {
+		recorder.Record(imp)
+	}
+
+	summary := recorder.Summary()
+	if summary.Total != 5 || summary.Window != 5 || summary.Dropped != 0 {
+		t.Errorf("Unexpected totals: %+v", summary)
+	}
+	on := summary.Flags["payments.instantPayouts"].Variants["true"]
+	if on.Impressions != 2 || on.Users != 1 || len(on.Tenants) != 1 || on.Tenants[0] != "partner-a" {
+		t.Errorf("Unexpected enabled variant: %+v", on)
+	}
+	off := summary.Flags["payments.instantPayouts"].Variants["false"]
+	if off.Impressions != 2 || off.Users != 2 {
+		t.Errorf("Unexpected disabled variant: %+v", off)
+	}
+	if summary.Flags["claims.autoApproval"].Variants["false"].Impressions != 1 {
+		t.Errorf("Flags mixed up: %+v", summary.Flags)
+	}
+}
+
+func TestRecorderRunFlushesOnStop(t *testing.T) {
+	sink := &memorySink{}
+	recorder := NewRecorder(10, sink)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		recorder.Run(time.Hour, stop, nil)
+		close(done)
+	}()
+
+	recorder.Record(Impression{Flag: "claims.autoApproval", Variant: "true"})
+	close(stop)
+	<-done
+	if sink.count() != 1 {
+		t.Errorf("Pending impression not flushed on stop")
+	}
+}
@@ -0,0 +1,206 @@
+// Package targeting decides boolean feature flags per user. It adds what the
+// services' environment-variable flags cannot express on their own: stable
+// percentage rollouts, attribute rules and per-tenant overrides.
+//
+// A Rollout is usually loaded from JSON, for example
+//
+//	{"percentage": 5, "rules": [{"attribute": "country", "operator": "in", "values": ["USA"]}]}
+//
+// enables a flag for a stable 5% of US customers. The same user always lands
+// in the same bucket for a flag, so raising the percentage only ever adds
+// users.
+//
+// A Recorder keeps impressions of those evaluations so exposure to each
+// variant can be measured.
+package targeting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Common attribute names. Services may use others.
+const (
+	AttributeCountry    = "country"
+	AttributeRiskScore  = "riskScore"
+	AttributePolicyType = "policyType"
+)
+
+// Rule operators
+const (
+	OperatorIn    = "in"
+	OperatorNotIn = "not_in"
+	OperatorLT    = "lt"
+	OperatorLTE   = "lte"
+	OperatorGT    = "gt"
+	OperatorGTE   = "gte"
+)
+
+// Context describes who a flag is evaluated for
+type Context struct {
+	UserID     string
+	TenantID   string
+	Attributes map[string]string
+}
+
+// Rule matches one attribute of the context. String operators compare
+// case-insensitively; numeric operators use the first value.
+type Rule struct {
+	Attribute string   `json:"attribute"`
+	Operator  string   `json:"operator"`
+	Values    []string `json:"values"`
+}
+
+// Rollout is the targeting for one flag. Evaluation order:
+//  1. a tenant listed in Tenants gets that value;
+//  2. a context failing any rule gets Default;
+//  3. a user whose bucket falls under Percentage gets true;
+//  4. everyone else gets Default.
+type Rollout struct {
+	Default    bool            `json:"default"`
+	Percentage float64         `json:"percentage"` // 0-100
+	Rules      []Rule          `json:"rules,omitempty"`
+	Tenants    map[string]bool `json:"tenants,omitempty"`
+}
+
+// Parse decodes and validates a rollout from JSON
+func Parse(data []byte) (*Rollout, error) {
+	var rollout Rollout
+	if err := json.Unmarshal(data, &rollout); err != nil {
+		return nil, fmt.Errorf("invalid rollout: %w", err)
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, err
+	}
+	return &rollout, nil
+}
+
+// Validate checks the percentage and rules are well formed
+func (r *Rollout) Validate() error {
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100")
+	}
+	for _, rule := range r.Rules {
+		if rule.Attribute == "" {
+			return fmt.Errorf("rule attribute is required")
+		}
+		if len(rule.Values) == 0 {
+			return fmt.Errorf("rule for %s has no values", rule.Attribute)
+		}
+		switch rule.Operator {
+		case OperatorIn, OperatorNotIn:
+		case OperatorLT, OperatorLTE, OperatorGT, OperatorGTE:
+			if _, err := strconv.ParseFloat(rule.Values[0], 64); err != nil {
+				return fmt.Errorf("rule for %s needs a numeric value", rule.Attribute)
+			}
+		default:
+			return fmt.Errorf("unknown operator: %s", rule.Operator)
+		}
+	}
+	return nil
+}
+
+// Enabled evaluates the rollout of flag for ctx. flag salts the bucket so
+// separate flags roll out to different users.
+func (r *Rollout) Enabled(flag string, ctx Context) bool {
+	if r == nil {
+		return false
+	}
+	if enabled, ok := r.Tenants[ctx.TenantID]; ok && ctx.TenantID != "" {
+		return enabled
+	}
+	for _, rule := range r.Rules {
+		if !rule.Matches(ctx.Attributes) {
+			return r.Default
+		}
+	}
+	if ctx.UserID != "" && Bucket(flag, ctx.UserID) < r.Percentage {
+		return true
+	}
+	return r.Default
+}
+
+// Attributes lists the attributes the rollout's rules read, so callers only
+// look up what is needed
+func (r *Rollout) Attributes() []string {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(r.Rules))
+	var attributes []string
+	for _, rule := range r.Rules {
+		if !seen[rule.Attribute] {
+			seen[rule.Attribute] = true
+			attributes = append(attributes, rule.Attribute)
+		}
+	}
+	return attributes
+}
+
+// Matches reports whether the attributes satisfy the rule. A missing
+// attribute never matches, so unknown users are not targeted by accident.
+func (rule Rule) Matches(attributes map[string]string) bool {
+	value, ok := attributes[rule.Attribute]
+	if !ok || value == "" {
+		return false
+	}
+
+	switch rule.Operator {
+	case OperatorIn, OperatorNotIn:
+		found := false
+		for _, candidate := range rule.Values {
+			if strings.EqualFold(candidate, value) {
+				found = true
+				break
+			}
+		}
+		return found == (rule.Operator == OperatorIn)
+	}
+
+	actual, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	limit, err := strconv.ParseFloat(rule.Values[0], 64)
+	if err != nil {
+		return false
+	}
+	switch rule.Operator {
+	case OperatorLT:
+		return actual < limit
+	case OperatorLTE:
+		return actual <= limit
+	case OperatorGT:
+		return actual > limit
+	case OperatorGTE:
+		return actual >= limit
+	}
+	return false
+}
+
+// Bucket places a user in [0, 100) for a flag using a stable hash of both
+func Bucket(flag, userID string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(flag))
+	h.Write([]byte{0})
+	h.Write([]byte(userID))
+	return float64(h.Sum32()%10000) / 100
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the caller's tenant, for flag
+// evaluations further down the request
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant set by WithTenant, if any
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
@@ -0,0 +1,92 @@
+package targeting
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestPercentageRolloutIsStable(t *testing.T) {
+	rollout := &Rollout{Percentage: 5}
+
+	enabled := 0
+	const users = 10000
+	for i := 0; i < users; i++ {
+		ctx := Context{UserID: fmt.Sprintf("cust-%05d", i)}
+		first := rollout.Enabled("payments.instantPayouts", ctx)
+		if first != rollout.Enabled("payments.instantPayouts", ctx) {
+			t.Fatalf("Evaluation for %s is not stable", ctx.UserID)
+		}
+		if first {
+			enabled++
+		}
+	}
+	if share := float64(enabled) / users * 100; math.Abs(share-5) > 1 {
+		t.Errorf("Rollout share: got %.2f%%, want about 5%%", share)
+	}
+
+	// Raising the percentage only adds users
+	wider := &Rollout{Percentage: 25}
+	for i := 0; i < users; i++ {
+		ctx := Context{UserID: fmt.Sprintf("cust-%05d", i)}
+		if rollout.Enabled("payments.instantPayouts", ctx) && !wider.Enabled("payments.instantPayouts", ctx) {
+			t.Fatalf("%s dropped out when the rollout widened", ctx.UserID)
+		}
+	}
+
+	if rollout.Enabled("payments.instantPayouts", Context{}) {
+		t.Error("Anonymous context should get the default")
+	}
+}
+
+func TestRulesAndTenantOverrides(t *testing.T) {
+	rollout, err := Parse([]byte(`{
+		"percentage": 100,
+		"rules": [
+			{"attribute": "country", "operator": "in", "values": ["usa", "canada"]},
+			{"attribute": "riskScore", "operator": "lt", "values": ["50"]}
+		],
+		"tenants": {"partner-a": true, "partner-b": false}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  Context
+		want bool
+	}{
+		{"matching", Context{UserID: "u1", Attributes: map[string]string{"country": "USA", "riskScore": "20"}}, true},
+		{"risky", Context{UserID: "u1", Attributes: map[string]string{"country": "USA", "riskScore": "80"}}, false},
+		{"other country", Context{UserID: "u1", Attributes: map[string]string{"country": "UK", "riskScore": "20"}}, false},
+		{"unknown attributes", Context{UserID: "u1"}, false},
+		{"tenant forced on", Context{UserID: "u1", TenantID: "partner-a"}, true},
+		{"tenant forced off", Context{UserID: "u1", TenantID: "partner-b", Attributes: map[string]string{"country": "USA", "riskScore": "20"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rollout.Enabled("payments.instantPayouts", tt.ctx); got != tt.want {
+				t.Errorf("Enabled: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if attrs := rollout.Attributes(); len(attrs) != 2 || attrs[0] != "country" || attrs[1] != "riskScore" {
+		t.Errorf("Attributes mismatch: %v", attrs)
+	}
+}
+
+func TestParseRejectsInvalidRollouts(t *testing.T) {
+	for _, data := range []string{
+		`{"percentage": 120}`,
+		`{"percentage": 5, "rules": [{"attribute": "country", "operator": "like", "values": ["US"]}]}`,
+		`{"percentage": 5, "rules": [{"attribute": "riskScore", "operator": "lt", "values": ["low"]}]}`,
+		`{"percentage": 5, "rules": [{"attribute": "country", "operator": "in"}]}`,
+		`not json`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Expected error for %s", data)
+		}
+	}
+}
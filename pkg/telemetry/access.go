@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HeaderRequestID carries a request's ID. An incoming ID is kept, so one ID
+// follows a request from the gateway through the services.
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength bounds incoming request IDs so a caller cannot bloat
+// every log line
+const maxRequestIDLength = 128
+
+// RequestID returns the request's X-Request-ID when it is 1 to 128
+// printable ASCII characters, and a new random ID otherwise
+func RequestID(r *http.Request) string {
+	id := r.Header.Get(HeaderRequestID)
+	if id == "" || len(id) > maxRequestIDLength {
+		return newID(16)
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return newID(16)
+		}
+	}
+	return id
+}
+
+// Identity records who made a request. The logging middleware creates one
+// before the request reaches authentication, which fills it in with
+// SetIdentity, so the access entry written after the response names the
+// caller.
+type Identity struct {
+	userID string
+	role   string
+	mu     sync.Mutex
+}
+
+type identityKey struct{}
+
+// NewIdentityContext returns a context carrying an empty Identity
+func NewIdentityContext(ctx context.Context) (context.Context, *Identity) {
+	id := &Identity{}
+	return context.WithValue(ctx, identityKey{}, id), id
+}
+
+// SetIdentity records the caller of the request ctx belongs to. It does
+// nothing when ctx carries no Identity.
+func SetIdentity(ctx context.Context, userID, role string) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	if !ok {
+		return
+	}
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	id.userID = userID
+	id.role = role
+}
+
+// Get returns the recorded user and role; both are empty for anonymous
+// requests
+func (id *Identity) Get() (userID, role string) {
+	id.mu.Lock()
+	defer id.mu.Unlock()
+	return id.userID, id.role
+}
+
+// OpenAccessLog returns a JSON logger writing access entries to dest:
+// "stdout", "stderr" or a file path, which is opened for appending. An
+// empty dest returns a nil logger, meaning access entries stay in the
+// service log. Call close on shutdown.
+func OpenAccessLog(dest string) (logger *logrus.Logger, close func() error, err error) {
+	var out io.Writer
+	close = func() error { return nil }
+
+	switch dest {
+	case "":
+		return nil, close, nil
+	case "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		file, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open access log: %w", err)
+		}
+		out, close = file, file.Close
+	}
+
+	logger = logrus.New()
+	logger.SetOutput(out)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.SetLevel(logrus.InfoLevel)
+	return logger, close, nil
+}
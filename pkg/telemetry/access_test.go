@@ -0,0 +1,79 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDKeepsUsableIncomingIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"gateway ID", "req-7f3a9c", true},
+		{"missing", "", false},
+		{"contains a space", "req 7f3a9c", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/claims", nil)
+			if tt.incoming != "" {
+				r.Header.Set(HeaderRequestID, tt.incoming)
+			}
+			id := RequestID(r)
+			if tt.keep && id != tt.incoming {
+				t.Errorf("Expected incoming ID %q kept, got %q", tt.incoming, id)
+			}
+			if !tt.keep && (id == tt.incoming || len(id) != 32) {
+				t.Errorf("Expected a generated ID, got %q", id)
+			}
+		})
+	}
+}
+
+func TestSetIdentityFillsTheRequestIdentity(t *testing.T) {
+	// Without an Identity in the context, SetIdentity is a no-op
+	SetIdentity(context.Background(), "cust-001", "")
+
+	ctx, id := NewIdentityContext(context.Background())
+	SetIdentity(context.WithValue(ctx, traceKey{}, Trace{}), "adj-001", "adjuster")
+
+	if userID, role := id.Get(); userID != "adj-001" || role != "adjuster" {
+		t.Errorf("Identity mismatch: got %q, %q", userID, role)
+	}
+}
+
+func TestOpenAccessLogAppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+
+	for i := 0; i < 2; i++ {
+		logger, closeLog, err := OpenAccessLog(path)
+		if err != nil {
+			t.Fatalf("Failed to open access log: %v", err)
+		}
+		logger.WithField("status", 200).Info("HTTP request")
+		if err := closeLog(); err != nil {
+			t.Fatalf("Failed to close access log: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read access log: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("Expected 2 appended entries, got %d:\n%s", lines, data)
+	}
+
+	if logger, _, err := OpenAccessLog(""); logger != nil || err != nil {
+		t.Errorf("Empty destination should use the service log, got %v, %v", logger, err)
+	}
+}
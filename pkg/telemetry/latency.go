@@ -0,0 +1,150 @@
+// Package telemetry records per-route request latency and carries trace
+// context through the services' request logs.
+package telemetry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are histogram upper bounds in seconds, from 5ms to 10s
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// RouteLatency is the latency histogram of one route, method and status code
+type RouteLatency struct {
+	Method string
+	Route  string // the route template, such as /claims/{id}
+	Code   int
+	Count  uint64
+	Sum    time.Duration
+	// Buckets holds cumulative counts, one per bound in the registry's
+	// buckets; Count is the +Inf bucket
+	Buckets []uint64
+}
+
+type routeKey struct {
+	method string
+	route  string
+	code   int
+}
+
+// Registry keeps a latency histogram per route. Label routes with their
+// template rather than the raw path, so /claims/{id} is one series instead
+// of one per claim.
+type Registry struct {
+	buckets []float64
+	routes  map[routeKey]*RouteLatency
+	mu      sync.Mutex
+}
+
+// NewRegistry creates a registry with the given bucket bounds in seconds,
+// or DefaultBuckets when none are given
+func NewRegistry(buckets []float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+	return &Registry{
+		buckets: bounds,
+		routes:  make(map[routeKey]*RouteLatency),
+	}
+}
+
+// Observe records one request
+func (reg *Registry) Observe(method, route string, code int, d time.Duration) {
+	key := routeKey{method: method, route: route, code: code}
+	seconds := d.Seconds()
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	series, ok := reg.routes[key]
+	if !ok {
+		series = &RouteLatency{
+			Method:  method,
+			Route:   route,
+			Code:    code,
+			Buckets: make([]uint64, len(reg.buckets)),
+		}
+		reg.routes[key] = series
+	}
+	series.Count++
+	series.Sum += d
+	for i, bound := range reg.buckets {
+		if seconds <= bound {
+			series.Buckets[i]++
+		}
+	}
+}
+
+// Routes returns a copy of every histogram, sorted by route, method and code
+func (reg *Registry) Routes() []RouteLatency {
+	reg.mu.Lock()
+	routes := make([]RouteLatency, 0, len(reg.routes))
+	for _, series := range reg.routes {
+		copied := *series
+		copied.Buckets = append([]uint64(nil), series.Buckets...)
+		routes = append(routes, copied)
+	}
+	reg.mu.Unlock()
+
+	sort.Slice(routes, func(i, j int) bool {
+		a, b := routes[i], routes[j]
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		return a.Code < b.Code
+	})
+	return routes
+}
+
+// WritePrometheus writes the histograms as http_request_duration_seconds in
+// the Prometheus text exposition format
+func (reg *Registry) WritePrometheus(w io.Writer) {
+	fmt.Fprint(w, "# HELP http_request_duration_seconds Request latency by route template, method and status code.\n")
+	fmt.Fprint(w, "# TYPE http_request_duration_seconds histogram\n")
+	for _, series := range reg.Routes() {
+		labels := fmt.Sprintf(`method="%s",route="%s",code="%d"`, escapeLabel(series.Method), escapeLabel(series.Route), series.Code)
+		for i, bound := range reg.buckets {
+			fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, strconv.FormatFloat(bound, 'g', -1, 64), series.Buckets[i])
+		}
+		fmt.Fprintf(w, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, series.Count)
+		fmt.Fprintf(w, "http_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(series.Sum.Seconds(), 'g', -1, 64))
+		fmt.Fprintf(w, "http_request_duration_seconds_count{%s} %d\n", labels, series.Count)
+	}
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+// Collector writes metrics in the Prometheus text exposition format
+type Collector interface {
+	WritePrometheus(w io.Writer)
+}
+
+var _ Collector = (*Registry)(nil)
+
+// Handler serves GET /metrics from one or more collectors
+func Handler(collectors ...Collector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.WriteHeader(http.StatusOK)
+		for _, c := range collectors {
+			c.WritePrometheus(w)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryObserveBucketsByRoute(t *testing.T) {
+	reg := NewRegistry([]float64{0.1, 1})
+	reg.Observe("GET", "/claims/{id}", 200, 50*time.Millisecond)
+	reg.Observe("GET", "/claims/{id}", 200, 500*time.Millisecond)
+	reg.Observe("GET", "/claims/{id}", 200, 2*time.Second)
+	reg.Observe("GET", "/claims/{id}", 404, 10*time.Millisecond)
+
+	routes := reg.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("Series count mismatch: got %d, want 2", len(routes))
+	}
+
+	ok := routes[0]
+	if ok.Code != 200 || ok.Count != 3 {
+		t.Fatalf("Unexpected first series: %+v", ok)
+	}
+	if ok.Buckets[0] != 1 || ok.Buckets[1] != 2 {
+		t.Errorf("Cumulative buckets mismatch: got %v, want [1 2]", ok.Buckets)
+	}
+	if ok.Sum != 2550*time.Millisecond {
+		t.Errorf("Sum mismatch: got %s", ok.Sum)
+	}
+	if routes[1].Code != 404 || routes[1].Count != 1 {
+		t.Errorf("Unexpected second series: %+v", routes[1])
+	}
+}
+
+func TestHandlerWritesPrometheusHistogram(t *testing.T) {
+	reg := NewRegistry([]float64{0.25})
+	reg.Observe("PUT", "/claims/{id}/status", 200, 100*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Content type mismatch: got %q", got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE http_request_duration_seconds histogram\n",
+		`http_request_duration_seconds_bucket{method="PUT",route="/claims/{id}/status",code="200",le="0.25"} 1` + "\n",
+		`http_request_duration_seconds_bucket{method="PUT",route="/claims/{id}/status",code="200",le="+Inf"} 1` + "\n",
+		`http_request_duration_seconds_sum{method="PUT",route="/claims/{id}/status",code="200"} 0.1` + "\n",
+		`http_request_duration_seconds_count{method="PUT",route="/claims/{id}/status",code="200"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Missing %q in:\n%s", want, body)
+		}
+	}
+}
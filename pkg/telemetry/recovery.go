@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// PanicError is a panic recovered from a request handler
+type PanicError struct {
+	// Value is what the handler panicked with
+	Value interface{}
+	// Stack is the goroutine's stack where the panic was recovered
+	Stack []byte
+}
+
+// Recovered wraps a value returned by recover, capturing the stack. Call it
+// from the deferred function that recovered, so the stack still shows where
+// the panic happened.
+func Recovered(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value when the handler panicked with an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ErrorReporter sends recovered panics to an error tracker. It follows
+// Sentry's model of an exception plus tags, so a Sentry hub fits in a few
+// lines:
+//
+//	func (s sentryReporter) CaptureException(ctx context.Context, err error, tags map[string]string) {
+//		hub := s.hub.Clone()
+//		hub.Scope().SetTags(tags)
+//		hub.CaptureException(err)
+//	}
+//
+// Tags carry the request's request_id, method and route. Reporters are
+// called on the request's goroutine and should not block.
+type ErrorReporter interface {
+	CaptureException(ctx context.Context, err error, tags map[string]string)
+}
+
+type panicKey struct {
+	method string
+	route  string
+}
+
+// PanicCounter counts recovered panics by route
+type PanicCounter struct {
+	counts map[panicKey]uint64
+	mu     sync.Mutex
+}
+
+var _ Collector = (*PanicCounter)(nil)
+
+// NewPanicCounter creates a counter with no panics recorded
+func NewPanicCounter() *PanicCounter {
+	return &PanicCounter{counts: make(map[panicKey]uint64)}
+}
+
+// Inc records one panic on a route template
+func (c *PanicCounter) Inc(method, route string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[panicKey{method: method, route: route}]++
+}
+
+// Total returns the number of panics recorded on every route
+func (c *PanicCounter) Total() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var total uint64
+	for _, n := range c.counts {
+		total += n
+	}
+	return total
+}
+
+// WritePrometheus writes the counts as http_panics_total in the Prometheus
+// text exposition format
+func (c *PanicCounter) WritePrometheus(w io.Writer) {
+	c.mu.Lock()
+	keys := make([]panicKey, 0, len(c.counts))
+	counts := make(map[panicKey]uint64, len(c.counts))
+	for key, n := range c.counts {
+		keys = append(keys, key)
+		counts[key] = n
+	}
+	c.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	fmt.Fprint(w, "# HELP http_panics_total Handler panics recovered and answered with 500, by route template and method.\n")
+	fmt.Fprint(w, "# TYPE http_panics_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(w, "http_panics_total{method=\"%s\",route=\"%s\"} %d\n", escapeLabel(key.method), escapeLabel(key.route), counts[key])
+	}
+}
+
+// IsAbort reports whether a recovered value is http.ErrAbortHandler, which
+// handlers panic with to abort a response on purpose. It is not an error and
+// should be panicked with again for the server to handle.
+func IsAbort(value interface{}) bool {
+	err, ok := value.(error)
+	return ok && errors.Is(err, http.ErrAbortHandler)
+}
@@ -0,0 +1,56 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRecoveredKeepsValueAndStack(t *testing.T) {
+	cause := errors.New("nil policy")
+	var err *PanicError
+	func() {
+		defer func() { err = Recovered(recover()) }()
+		panic(cause)
+	}()
+
+	if err.Error() != "panic: nil policy" || !errors.Is(err, cause) {
+		t.Errorf("Unexpected error %q", err)
+	}
+	if !strings.Contains(string(err.Stack), "TestRecoveredKeepsValueAndStack") {
+		t.Errorf("Expected the stack to show where the panic happened:\n%s", err.Stack)
+	}
+	if (&PanicError{Value: "boom"}).Unwrap() != nil {
+		t.Error("Expected a non-error value to unwrap to nil")
+	}
+
+	if !IsAbort(http.ErrAbortHandler) || !IsAbort(fmt.Errorf("abort: %w", http.ErrAbortHandler)) {
+		t.Error("Expected http.ErrAbortHandler to be an abort")
+	}
+	if IsAbort(cause) || IsAbort("boom") {
+		t.Error("Expected other values not to be aborts")
+	}
+}
+
+func TestPanicCounterWritePrometheus(t *testing.T) {
+	c := NewPanicCounter()
+	c.Inc("POST", "/claims")
+	c.Inc("GET", "/claims/{id}")
+	c.Inc("POST", "/claims")
+
+	var out strings.Builder
+	c.WritePrometheus(&out)
+
+	expected := "# HELP http_panics_total Handler panics recovered and answered with 500, by route template and method.\n" +
+		"# TYPE http_panics_total counter\n" +
+		"http_panics_total{method=\"POST\",route=\"/claims\"} 2\n" +
+		"http_panics_total{method=\"GET\",route=\"/claims/{id}\"} 1\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s", out.String())
+	}
+	if c.Total() != 3 {
+		t.Errorf("Expected 3 panics, got %d", c.Total())
+	}
+}
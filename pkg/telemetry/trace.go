@@ -0,0 +1,119 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// Trace propagation headers. traceparent is W3C Trace Context; the X-B3
+// headers are Zipkin's B3 format. Jaeger accepts both.
+const (
+	HeaderTraceparent = "traceparent"
+	HeaderB3TraceID   = "X-B3-TraceId"
+	HeaderB3SpanID    = "X-B3-SpanId"
+	HeaderB3ParentID  = "X-B3-ParentSpanId"
+	HeaderB3Sampled   = "X-B3-Sampled"
+)
+
+// Trace places one request in a distributed trace. IDs are lowercase hex,
+// 32 characters for the trace and 16 for spans.
+type Trace struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string // the caller's span; empty when this request started the trace
+	Sampled      bool
+}
+
+// FromRequest continues the trace a request arrived with, reading
+// traceparent first and then B3. The request gets a new span whose parent
+// is the caller's span. A request without valid trace headers starts a new,
+// sampled trace.
+func FromRequest(r *http.Request) Trace {
+	if traceID, parentID, sampled, ok := parseTraceparent(r.Header.Get(HeaderTraceparent)); ok {
+		return Trace{TraceID: traceID, SpanID: newID(8), ParentSpanID: parentID, Sampled: sampled}
+	}
+	if traceID, ok := parseHexID(r.Header.Get(HeaderB3TraceID), 32, true); ok {
+		parentID, _ := parseHexID(r.Header.Get(HeaderB3SpanID), 16, false)
+		sampled := r.Header.Get(HeaderB3Sampled) != "0"
+		return Trace{TraceID: traceID, SpanID: newID(8), ParentSpanID: parentID, Sampled: sampled}
+	}
+	return Trace{TraceID: newID(16), SpanID: newID(8), Sampled: true}
+}
+
+// Inject sets traceparent and B3 headers so a downstream call continues
+// this trace as a child of this span
+func (t Trace) Inject(h http.Header) {
+	flags, sampled := "00", "0"
+	if t.Sampled {
+		flags, sampled = "01", "1"
+	}
+	h.Set(HeaderTraceparent, "00-"+t.TraceID+"-"+t.SpanID+"-"+flags)
+	h.Set(HeaderB3TraceID, t.TraceID)
+	h.Set(HeaderB3SpanID, t.SpanID)
+	h.Set(HeaderB3Sampled, sampled)
+	h.Del(HeaderB3ParentID)
+}
+
+type traceKey struct{}
+
+// NewContext returns a context carrying t
+func NewContext(ctx context.Context, t Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+// FromContext returns the trace stored by NewContext
+func FromContext(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceKey{}).(Trace)
+	return t, ok
+}
+
+// parseTraceparent reads a version 00 traceparent header:
+// 00-<32 hex trace ID>-<16 hex parent span ID>-<2 hex flags>
+func parseTraceparent(value string) (traceID, parentID string, sampled, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[3]) != 2 {
+		return "", "", false, false
+	}
+	if traceID, ok = parseHexID(parts[1], 32, false); !ok {
+		return "", "", false, false
+	}
+	if parentID, ok = parseHexID(parts[2], 16, false); !ok {
+		return "", "", false, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return "", "", false, false
+	}
+	return traceID, parentID, flags[0]&1 == 1, true
+}
+
+// parseHexID validates a hex ID of size characters. With pad set, shorter
+// IDs of half the size are left-padded with zeros, as B3 allows 64-bit
+// trace IDs. An all-zero ID is invalid.
+func parseHexID(value string, size int, pad bool) (string, bool) {
+	id := strings.ToLower(strings.TrimSpace(value))
+	if pad && len(id) == size/2 {
+		id = strings.Repeat("0", size/2) + id
+	}
+	if len(id) != size || strings.Trim(id, "0") == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// newID returns a random hex ID of n bytes
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on supported platforms; a fixed non-zero
+		// ID keeps the trace valid if it ever does
+		b[n-1] = 1
+	}
+	return hex.EncodeToString(b)
+}
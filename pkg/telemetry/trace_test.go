@@ -0,0 +1,101 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		wantTraceID string
+		wantParent  string
+		wantSampled bool
+	}{
+		{
+			name:        "traceparent",
+			headers:     map[string]string{HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantParent:  "00f067aa0ba902b7",
+			wantSampled: true,
+		},
+		{
+			name:        "traceparent not sampled",
+			headers:     map[string]string{HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantParent:  "00f067aa0ba902b7",
+			wantSampled: false,
+		},
+		{
+			name: "b3 with a 64-bit trace ID",
+			headers: map[string]string{
+				HeaderB3TraceID: "A3CE929D0E0E4736",
+				HeaderB3SpanID:  "00f067aa0ba902b7",
+			},
+			wantTraceID: "0000000000000000a3ce929d0e0e4736",
+			wantParent:  "00f067aa0ba902b7",
+			wantSampled: true,
+		},
+		{
+			name: "traceparent wins over b3",
+			headers: map[string]string{
+				HeaderTraceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				HeaderB3TraceID:   "a3ce929d0e0e4736a3ce929d0e0e4736",
+			},
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantParent:  "00f067aa0ba902b7",
+			wantSampled: true,
+		},
+		{
+			name:        "all-zero trace ID starts a new trace",
+			headers:     map[string]string{HeaderTraceparent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+			wantSampled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/claims", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			trace := FromRequest(r)
+			if tt.wantTraceID != "" && trace.TraceID != tt.wantTraceID {
+				t.Errorf("Trace ID mismatch: got %q, want %q", trace.TraceID, tt.wantTraceID)
+			}
+			if len(trace.TraceID) != 32 || len(trace.SpanID) != 16 {
+				t.Errorf("Malformed IDs: %+v", trace)
+			}
+			if trace.ParentSpanID != tt.wantParent {
+				t.Errorf("Parent span mismatch: got %q, want %q", trace.ParentSpanID, tt.wantParent)
+			}
+			if trace.SpanID == tt.wantParent {
+				t.Error("The request should get its own span")
+			}
+			if trace.Sampled != tt.wantSampled {
+				t.Errorf("Sampled mismatch: got %v, want %v", trace.Sampled, tt.wantSampled)
+			}
+		})
+	}
+}
+
+func TestInjectContinuesTrace(t *testing.T) {
+	parent := Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}
+
+	r := httptest.NewRequest(http.MethodGet, "/policies/pol-001", nil)
+	parent.Inject(r.Header)
+	child := FromRequest(r)
+
+	if child.TraceID != parent.TraceID || child.ParentSpanID != parent.SpanID || !child.Sampled {
+		t.Errorf("Child does not continue the trace: got %+v", child)
+	}
+
+	ctx := NewContext(context.Background(), child)
+	if got, ok := FromContext(ctx); !ok || got != child {
+		t.Errorf("Context round trip mismatch: got %+v", got)
+	}
+}